@@ -0,0 +1,375 @@
+/*
+# Mock Generator
+
+This binary generates mocks of the interfaces a package declares in `interfaces.go`, so applications embedding ReGo can
+unit test their own logic without live tenants. Each package runs it with `go generate`:
+
+	//go:generate go run ../../cmd/mockgen
+
+The mocks of `pkg/okta` are written to `pkg/okta/oktamock/mocks.go`; each method calls its `<Method>Func` field when set,
+returns zero values otherwise, and records its calls:
+
+	users := &oktamock.UserService{
+		GetUserFunc: func(id string) (*okta.User, error) { return &okta.User{ID: id}, nil },
+	}
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// cmd/mockgen/main.go
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ### Generator Structs
+// ---------------------------------------------------------------------
+
+// generator holds the package whose interfaces are mocked
+type generator struct {
+	fset       *token.FileSet
+	pkg        string            // Name of the package, e.g. `okta`
+	importPath string            // Import path of the package
+	imports    map[string]string // Imports of the source file, by name
+	used       map[string]bool   // Imports used by the mocks, by name
+}
+
+// method is a method of an interface
+type method struct {
+	name     string
+	params   []string // Types of the parameters
+	results  []string // Types of the results
+	variadic bool     // Whether the last parameter is variadic
+}
+
+// END OF GENERATOR STRUCTS
+//---------------------------------------------------------------------
+
+func main() {
+	source := flag.String("source", "interfaces.go", "File declaring the interfaces to mock")
+	out := flag.String("out", "", "File to write the mocks to; `<pkg>mock/mocks.go` when empty")
+	flag.Parse()
+
+	if err := generate(*source, *out); err != nil {
+		log.Fatalf("mockgen: %v", err)
+	}
+}
+
+// generate writes the mocks of the interfaces declared in `source`
+func generate(source, out string) error {
+	dir, err := filepath.Abs(filepath.Dir(source))
+	if err != nil {
+		return err
+	}
+	root, importPath, err := moduleOf(dir)
+	if err != nil {
+		return err
+	}
+
+	g := &generator{fset: token.NewFileSet(), importPath: importPath, imports: map[string]string{}, used: map[string]bool{}}
+	file, err := parser.ParseFile(g.fset, source, nil, 0)
+	if err != nil {
+		return err
+	}
+	g.pkg = file.Name.Name
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := filepath.Base(path)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		g.imports[name] = path
+	}
+
+	body := &bytes.Buffer{}
+	names := []string{}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			it, ok := ts.Type.(*ast.InterfaceType)
+			if !ok || !ts.Name.IsExported() {
+				continue
+			}
+			methods, err := g.methods(it)
+			if err != nil {
+				return fmt.Errorf("%s: %w", ts.Name.Name, err)
+			}
+			g.writeMock(body, ts.Name.Name, methods)
+			names = append(names, ts.Name.Name)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no interfaces declared in %s", source)
+	}
+
+	if out == "" {
+		out = filepath.Join(filepath.Dir(source), g.pkg+"mock", "mocks.go")
+	}
+	abs, err := filepath.Abs(out)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return err
+	}
+	src := g.header(filepath.Base(source), filepath.ToSlash(rel), filepath.Base(filepath.Dir(abs))) + body.String()
+
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		return fmt.Errorf("formatting mocks: %w\n%s", err, src)
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(out, formatted, 0644)
+}
+
+// moduleOf returns the root of the module of a directory, and the import path of the directory
+func moduleOf(dir string) (string, string, error) {
+	for root := dir; ; root = filepath.Dir(root) {
+		data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+					rel, err := filepath.Rel(root, dir)
+					if err != nil {
+						return "", "", err
+					}
+					return root, strings.TrimSuffix(strings.TrimSpace(module)+"/"+filepath.ToSlash(rel), "/."), nil
+				}
+			}
+			return "", "", fmt.Errorf("no module declared in %s", filepath.Join(root, "go.mod"))
+		}
+		if root == filepath.Dir(root) {
+			return "", "", errors.New("no go.mod found")
+		}
+	}
+}
+
+// methods returns the methods of an interface, with the types of the package qualified
+func (g *generator) methods(it *ast.InterfaceType) ([]method, error) {
+	methods := []method{}
+	for _, field := range it.Methods.List {
+		ft, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			return nil, errors.New("embedded interfaces are not supported")
+		}
+		m := method{name: field.Names[0].Name}
+		for _, p := range fieldTypes(ft.Params) {
+			if ellipsis, ok := p.(*ast.Ellipsis); ok {
+				m.variadic = true
+				p = ellipsis.Elt
+				m.params = append(m.params, "..."+g.typeString(p))
+				continue
+			}
+			m.params = append(m.params, g.typeString(p))
+		}
+		for _, r := range fieldTypes(ft.Results) {
+			m.results = append(m.results, g.typeString(r))
+		}
+		methods = append(methods, m)
+	}
+	return methods, nil
+}
+
+// fieldTypes returns the type of each parameter of a list, repeating the types of grouped parameters, e.g. `a, b string`
+func fieldTypes(fields *ast.FieldList) []ast.Expr {
+	types := []ast.Expr{}
+	if fields == nil {
+		return types
+	}
+	for _, f := range fields.List {
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			types = append(types, f.Type)
+		}
+	}
+	return types
+}
+
+// typeString prints a type as used outside of its package, e.g. `*Users` as `*okta.Users`
+func (g *generator) typeString(expr ast.Expr) string {
+	buf := &bytes.Buffer{}
+	printer.Fprint(buf, g.fset, g.qualify(expr))
+	return buf.String()
+}
+
+// qualify qualifies the exported identifiers of a type with the package, noting the imports it uses
+func (g *generator) qualify(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if e.IsExported() {
+			g.used[g.pkg] = true
+			return &ast.SelectorExpr{X: ast.NewIdent(g.pkg), Sel: ast.NewIdent(e.Name)}
+		}
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			g.used[x.Name] = true
+			return &ast.SelectorExpr{X: ast.NewIdent(x.Name), Sel: ast.NewIdent(e.Sel.Name)}
+		}
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: g.qualify(e.X)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: e.Len, Elt: g.qualify(e.Elt)}
+	case *ast.MapType:
+		return &ast.MapType{Key: g.qualify(e.Key), Value: g.qualify(e.Value)}
+	case *ast.ChanType:
+		return &ast.ChanType{Dir: e.Dir, Value: g.qualify(e.Value)}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: g.qualify(e.Elt)}
+	case *ast.IndexExpr:
+		return &ast.IndexExpr{X: g.qualify(e.X), Index: g.qualify(e.Index)}
+	case *ast.FuncType:
+		ft := &ast.FuncType{Params: &ast.FieldList{}}
+		for _, p := range fieldTypes(e.Params) {
+			ft.Params.List = append(ft.Params.List, &ast.Field{Type: g.qualify(p)})
+		}
+		if e.Results != nil {
+			ft.Results = &ast.FieldList{}
+			for _, r := range fieldTypes(e.Results) {
+				ft.Results.List = append(ft.Results.List, &ast.Field{Type: g.qualify(r)})
+			}
+		}
+		return ft
+	}
+	return expr
+}
+
+// header writes the package clause, imports and the call record shared by the mocks
+func (g *generator) header(source, path, mockPkg string) string {
+	std, modules := []string{strconv.Quote("sync")}, []string{}
+	for name := range g.used {
+		path := g.importPath
+		if name != g.pkg {
+			path = g.imports[name]
+		}
+		spec := strconv.Quote(path)
+		if filepath.Base(path) != name {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			modules = append(modules, spec)
+			continue
+		}
+		std = append(std, spec)
+	}
+	sort.Strings(std)
+	sort.Strings(modules)
+	imports := strings.Join(std, "\n")
+	if len(modules) > 0 {
+		imports += "\n\n" + strings.Join(modules, "\n")
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "// Code generated by mockgen from %s; DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(b, "// %s\n", path)
+	fmt.Fprintf(b, "package %s\n\nimport (\n%s\n)\n\n", mockPkg, imports)
+	b.WriteString(`// Call is a call received by a mock
+type Call struct {
+	Method string        // Name of the method called
+	Args   []interface{} // Arguments of the call
+}
+
+// calls records the calls received by a mock
+type calls struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+func (c *calls) record(method string, args ...interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls received, in order; only those of ` + "`method`" + ` when set
+func (c *calls) Calls(method ...string) []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	received := []Call{}
+	for _, call := range c.calls {
+		if len(method) == 0 || call.Method == method[0] {
+			received = append(received, call)
+		}
+	}
+	return received
+}
+`)
+	return b.String()
+}
+
+// writeMock writes the mock of an interface
+func (g *generator) writeMock(b *bytes.Buffer, name string, methods []method) {
+	fmt.Fprintf(b, "\n// %s is a mock of `%s.%s`; methods without a func return zero values\n", name, g.pkg, name)
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	for _, m := range methods {
+		fmt.Fprintf(b, "%sFunc func(%s) %s\n", m.name, strings.Join(m.params, ", "), results(m.results, false))
+	}
+	b.WriteString("\ncalls\n}\n\n")
+	fmt.Fprintf(b, "var _ %s.%s = (*%s)(nil)\n", g.pkg, name, name)
+	g.used[g.pkg] = true
+
+	for _, m := range methods {
+		params, args := []string{}, []string{}
+		for i, p := range m.params {
+			params = append(params, fmt.Sprintf("a%d %s", i, p))
+			args = append(args, fmt.Sprintf("a%d", i))
+		}
+		call := strings.Join(args, ", ")
+		if m.variadic {
+			call += "..."
+		}
+
+		fmt.Fprintf(b, "\nfunc (m *%s) %s(%s) %s {\n", name, m.name, strings.Join(params, ", "), results(m.results, true))
+		fmt.Fprintf(b, "m.record(%s)\n", strings.Join(append([]string{strconv.Quote(m.name)}, args...), ", "))
+		fmt.Fprintf(b, "if m.%sFunc != nil {\n", m.name)
+		if len(m.results) == 0 {
+			fmt.Fprintf(b, "m.%sFunc(%s)\n}\n}\n", m.name, call)
+			continue
+		}
+		fmt.Fprintf(b, "return m.%sFunc(%s)\n}\nreturn\n}\n", m.name, call)
+	}
+}
+
+// results prints the results of a method, named `r0`, `r1`, ... so methods without a func return zero values
+func results(types []string, named bool) string {
+	if len(types) == 0 {
+		return ""
+	}
+	if !named {
+		if len(types) == 1 {
+			return types[0]
+		}
+		return "(" + strings.Join(types, ", ") + ")"
+	}
+	fields := []string{}
+	for i, t := range types {
+		fields = append(fields, fmt.Sprintf("r%d %s", i, t))
+	}
+	return "(" + strings.Join(fields, ", ") + ")"
+}
@@ -0,0 +1,163 @@
+// cmd/rego/clients.go
+package main
+
+import (
+	"github.com/gemini-oss/rego/pkg/backupify"
+	"github.com/gemini-oss/rego/pkg/common/config"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/docusign"
+	"github.com/gemini-oss/rego/pkg/drift"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/jamf"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/orchestrators"
+	"github.com/gemini-oss/rego/pkg/slack"
+	"github.com/gemini-oss/rego/pkg/snipeit"
+)
+
+// clients are created on first use, after the profile has been loaded
+type clients struct {
+	backupify *backupify.Client
+	docusign  *docusign.Client
+	google    *google.Client
+	jamf      *jamf.Client
+	okta      *okta.Client
+	slack     *slack.Client
+	snipeit   *snipeit.Client
+}
+
+func (a *app) backupify() *backupify.Client {
+	if a.clients.backupify == nil {
+		a.clients.backupify = backupify.NewClient(a.opts.Verbosity)
+		a.dryRun(a.clients.backupify.HTTP)
+	}
+	return a.clients.backupify
+}
+
+// dryRun puts a client into dry-run mode when `--dry-run` is given, recording its mutations in the shared plan
+func (a *app) dryRun(hc *requests.Client) {
+	if a.plan != nil {
+		hc.EnableDryRun(a.plan)
+	}
+}
+
+func (a *app) docusign() *docusign.Client {
+	if a.clients.docusign == nil {
+		a.clients.docusign = docusign.NewClient(a.opts.Verbosity)
+		a.dryRun(a.clients.docusign.HTTP)
+	}
+	return a.clients.docusign
+}
+
+// google creates a service account client, impersonating `GOOGLE_SUBJECT` (a super admin) when it is set
+func (a *app) google() (*google.Client, error) {
+	if a.clients.google == nil {
+		ac := google.AuthCredentials{
+			CICD: true,
+			Type: google.SERVICE_ACCOUNT,
+			Scopes: []string{
+				"Admin SDK API",
+				"Google Drive API",
+				"Gmail API",
+			},
+			Subject: config.GetEnv("GOOGLE_SUBJECT"),
+		}
+		g, err := google.NewClient(ac, a.opts.Verbosity)
+		if err != nil {
+			return nil, err
+		}
+		a.dryRun(g.HTTP)
+		a.clients.google = g
+	}
+	return a.clients.google, nil
+}
+
+func (a *app) jamf() *jamf.Client {
+	if a.clients.jamf == nil {
+		a.clients.jamf = jamf.NewClient(a.opts.Verbosity)
+		a.dryRun(a.clients.jamf.HTTP)
+	}
+	return a.clients.jamf
+}
+
+func (a *app) okta() *okta.Client {
+	if a.clients.okta == nil {
+		a.clients.okta = okta.NewClient(a.opts.Verbosity)
+		a.dryRun(a.clients.okta.HTTP)
+	}
+	return a.clients.okta
+}
+
+func (a *app) slack() *slack.Client {
+	if a.clients.slack == nil {
+		a.clients.slack = slack.NewClient(a.opts.Verbosity)
+		a.dryRun(a.clients.slack.HTTP)
+	}
+	return a.clients.slack
+}
+
+func (a *app) snipeit() *snipeit.Client {
+	if a.clients.snipeit == nil {
+		a.clients.snipeit = snipeit.NewClient(a.opts.Verbosity)
+		a.dryRun(a.clients.snipeit.HTTP)
+	}
+	return a.clients.snipeit
+}
+
+// orchestrator builds an orchestrator from every service configured in the environment/profile
+// - Services are detected by their credential variable, since the client constructors exit when one is missing
+func (a *app) orchestrator() (*orchestrators.Client, error) {
+	c := &orchestrators.Client{
+		Log:  log.NewLogger("{orchestrator}", a.opts.Verbosity),
+		Plan: a.plan,
+	}
+
+	if config.GetEnv("BACKUPIFY_NODE_URL") != "" {
+		c.Backupify = a.backupify()
+	}
+	if config.GetEnv("DOCUSIGN_ACCESS_TOKEN") != "" {
+		c.DocuSign = a.docusign()
+	}
+	if config.GetEnv("GOOGLE_SERVICE_ACCOUNT") != "" {
+		g, err := a.google()
+		if err != nil {
+			return nil, err
+		}
+		c.Google = g
+	}
+	if config.GetEnv("JSS_URL") != "" {
+		c.Jamf = a.jamf()
+	}
+	if config.GetEnv("OKTA_API_TOKEN") != "" {
+		c.Okta = a.okta()
+	}
+	if config.GetEnv("SLACK_API_TOKEN") != "" {
+		c.Slack = a.slack()
+	}
+	if config.GetEnv("SNIPEIT_TOKEN") != "" {
+		c.SnipeIT = a.snipeit()
+	}
+
+	return c, nil
+}
+
+// detector builds a drift detector from the Okta and Google clients configured in the environment/profile
+func (a *app) detector() (*drift.Detector, error) {
+	d := &drift.Detector{
+		Log: log.NewLogger("{drift}", a.opts.Verbosity),
+	}
+
+	if config.GetEnv("GOOGLE_SERVICE_ACCOUNT") != "" {
+		g, err := a.google()
+		if err != nil {
+			return nil, err
+		}
+		d.Google = g
+	}
+	if config.GetEnv("OKTA_API_TOKEN") != "" {
+		d.Okta = a.okta()
+	}
+
+	return d, nil
+}
@@ -0,0 +1,546 @@
+// cmd/rego/commands.go
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/api"
+	"github.com/gemini-oss/rego/pkg/backupify"
+	"github.com/gemini-oss/rego/pkg/common/config"
+	"github.com/gemini-oss/rego/pkg/drift"
+	"github.com/gemini-oss/rego/pkg/jamf"
+	"github.com/gemini-oss/rego/pkg/orchestrators"
+	"github.com/gemini-oss/rego/pkg/reports"
+	"github.com/gemini-oss/rego/pkg/snipeit"
+)
+
+// command is a leaf of the command tree, e.g. `okta users list`
+type command struct {
+	Path        string                 // Words that select the command, e.g. `okta users list`
+	Args        string                 // Usage of the positional arguments, e.g. `<user-id>`
+	MinArgs     int                    // Minimum number of positional arguments
+	Summary     string                 // One-line description of the command
+	Destructive bool                   // Prompt for confirmation unless `--yes` is given
+	Columns     []string               // Default columns of table/csv output
+	Flags       func(fs *flag.FlagSet) // Registers command-specific flags
+	Run         func(a *app, args []string) error
+}
+
+// findCommand returns the command whose path is the longest prefix of `words`, and the number of words it consumed
+func findCommand(words []string) (*command, int) {
+	var found *command
+	depth := 0
+	for _, cmd := range commands {
+		path := strings.Fields(cmd.Path)
+		if len(path) > len(words) || len(path) <= depth {
+			continue
+		}
+		if strings.Join(words[:len(path)], " ") == cmd.Path {
+			found, depth = cmd, len(path)
+		}
+	}
+	return found, depth
+}
+
+var commands = []*command{
+	// ### Okta
+	{
+		Path:    "okta users list",
+		Summary: "List Okta users",
+		Columns: []string{"id", "status", "profile.login", "profile.email", "lastLogin"},
+		Flags: func(fs *flag.FlagSet) {
+			fs.Bool("active", false, "Only list active users")
+		},
+		Run: func(a *app, args []string) error {
+			if a.boolFlag("active") {
+				users, err := a.okta().ListActiveUsers()
+				if err != nil {
+					return err
+				}
+				return a.render(users)
+			}
+			users, err := a.okta().ListAllUsers()
+			if err != nil {
+				return err
+			}
+			return a.render(users)
+		},
+	},
+	{
+		Path:    "okta users get",
+		Args:    "<user-id|login>",
+		MinArgs: 1,
+		Summary: "Get an Okta user",
+		Columns: []string{"id", "status", "profile.login", "profile.email", "profile.title", "profile.department", "lastLogin"},
+		Run: func(a *app, args []string) error {
+			user, err := a.okta().GetUser(args[0])
+			if err != nil {
+				return err
+			}
+			return a.render(user)
+		},
+	},
+	{
+		Path:        "okta users deactivate",
+		Args:        "<user-id|login>",
+		MinArgs:     1,
+		Summary:     "Deactivate an Okta user",
+		Destructive: true,
+		Run: func(a *app, args []string) error {
+			if err := a.okta().DeactivateUser(args[0]); err != nil {
+				return err
+			}
+			a.done("deactivated", args[0])
+			return nil
+		},
+	},
+	{
+		Path:        "okta users clear-sessions",
+		Args:        "<user-id|login>",
+		MinArgs:     1,
+		Summary:     "Clear all sessions of an Okta user",
+		Destructive: true,
+		Run: func(a *app, args []string) error {
+			if err := a.okta().ClearUserSessions(args[0]); err != nil {
+				return err
+			}
+			a.done("cleared sessions of", args[0])
+			return nil
+		},
+	},
+	{
+		Path:    "okta groups list",
+		Summary: "List Okta groups",
+		Columns: []string{"id", "type", "profile.name", "profile.description"},
+		Run: func(a *app, args []string) error {
+			groups, err := a.okta().ListAllGroups()
+			if err != nil {
+				return err
+			}
+			return a.render(groups)
+		},
+	},
+
+	// ### Google
+	{
+		Path:    "google users list",
+		Summary: "List Google Workspace users",
+		Columns: []string{"id", "primaryEmail", "name.fullName", "orgUnitPath", "suspended", "lastLoginTime"},
+		Run: func(a *app, args []string) error {
+			g, err := a.google()
+			if err != nil {
+				return err
+			}
+			users, err := g.Users().ListAllUsers()
+			if err != nil {
+				return err
+			}
+			return a.render(users.Users)
+		},
+	},
+	{
+		Path:    "google users get",
+		Args:    "<email>",
+		MinArgs: 1,
+		Summary: "Get a Google Workspace user",
+		Columns: []string{"id", "primaryEmail", "name.fullName", "orgUnitPath", "suspended", "isAdmin", "lastLoginTime"},
+		Run: func(a *app, args []string) error {
+			g, err := a.google()
+			if err != nil {
+				return err
+			}
+			user, err := g.Users().GetUser(args[0])
+			if err != nil {
+				return err
+			}
+			return a.render(user)
+		},
+	},
+	{
+		Path:        "google users suspend",
+		Args:        "<email>",
+		MinArgs:     1,
+		Summary:     "Suspend a Google Workspace user",
+		Destructive: true,
+		Run: func(a *app, args []string) error {
+			g, err := a.google()
+			if err != nil {
+				return err
+			}
+			if _, err := g.Users().SuspendUser(args[0]); err != nil {
+				return err
+			}
+			a.done("suspended", args[0])
+			return nil
+		},
+	},
+	{
+		Path:        "google drive transfer",
+		Args:        "<from-email> <to-email>",
+		MinArgs:     2,
+		Summary:     "Transfer a user's Drive and Calendar data to another user",
+		Destructive: true,
+		Columns:     []string{"id", "oldOwnerUserId", "newOwnerUserId", "overallTransferStatusCode"},
+		Run: func(a *app, args []string) error {
+			g, err := a.google()
+			if err != nil {
+				return err
+			}
+			from, err := g.Users().GetUser(args[0])
+			if err != nil {
+				return err
+			}
+			to, err := g.Users().GetUser(args[1])
+			if err != nil {
+				return err
+			}
+			transfer, err := g.DataTransfer().TransferData(from.ID, to.ID)
+			if err != nil {
+				return err
+			}
+			return a.render(transfer)
+		},
+	},
+
+	// ### Jamf
+	{
+		Path:    "jamf computers list",
+		Summary: "List Jamf computers",
+		Columns: []string{"id", "general.name", "hardware.serialNumber", "hardware.model", "operatingSystem.version", "userAndLocation.email", "general.lastContactTime"},
+		Run: func(a *app, args []string) error {
+			sections := []string{jamf.Section.General, jamf.Section.Hardware, jamf.Section.OperatingSystem, jamf.Section.UserAndLocation}
+			computers, err := a.jamf().Devices().Sections(sections).ListAllComputers()
+			if err != nil {
+				return err
+			}
+			if computers.Results == nil {
+				return a.render([]*jamf.Computer{})
+			}
+			return a.render(*computers.Results)
+		},
+	},
+
+	// ### Slack
+	{
+		Path:    "slack users list",
+		Summary: "List Slack members",
+		Columns: []string{"id", "name", "real_name", "profile.email", "deleted", "is_admin", "is_bot"},
+		Run: func(a *app, args []string) error {
+			users, err := a.slack().ListUsers()
+			if err != nil {
+				return err
+			}
+			return a.render(users.Members)
+		},
+	},
+	{
+		Path:        "slack users deactivate",
+		Args:        "<email>",
+		MinArgs:     1,
+		Summary:     "Deactivate a Slack member (requires SCIM)",
+		Destructive: true,
+		Run: func(a *app, args []string) error {
+			member, err := a.slack().LookupUserByEmail(args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.slack().DeactivateUser(member.ID); err != nil {
+				return err
+			}
+			a.done("deactivated", args[0])
+			return nil
+		},
+	},
+
+	// ### Snipe-IT
+	{
+		Path:    "snipeit assets list",
+		Summary: "List Snipe-IT assets",
+		Columns: []string{"id", "asset_tag", "serial", "name", "model.name", "status_label.name", "assigned_to.email"},
+		Run: func(a *app, args []string) error {
+			assets, err := a.snipeit().Assets().GetAllAssets()
+			if err != nil {
+				return err
+			}
+			if assets.Rows == nil {
+				return a.render([]*snipeit.Hardware{})
+			}
+			return a.render(*assets.Rows)
+		},
+	},
+
+	// ### Backupify
+	{
+		Path:    "backupify export",
+		Args:    "<email>",
+		MinArgs: 1,
+		Summary: "Export a user's Backupify backups",
+		Flags: func(fs *flag.FlagSet) {
+			fs.String("app", string(backupify.GoogleDrive), "Application to export {GoogleDrive, GoogleMail, GoogleTeamDrives}")
+		},
+		Run: func(a *app, args []string) error {
+			user, err := a.backupify().Users().GetUserByEmail(backupify.AppType(a.flag("app")), args[0])
+			if err != nil {
+				return err
+			}
+			exports, err := a.backupify().Exports().ExportUser(user)
+			if err != nil {
+				return err
+			}
+			return a.render(exports)
+		},
+	},
+
+	// ### Reports
+	{
+		Path:    "reports access-review",
+		Summary: "Generate access-review artifacts (admins, app assignments, privileged groups, dormant accounts)",
+		Columns: []string{"source", "dataset", "records", "collected_at", "error"},
+		Flags: func(fs *flag.FlagSet) {
+			fs.String("period", "", "Review period, e.g. 2024-Q3 (default: the current quarter)")
+			fs.Int("dormant-days", reports.DefaultDormantDays, "Days without a sign-in before an account is dormant")
+			fs.String("privileged-groups", "", "Comma-separated glob patterns of privileged group names, e.g. *-admins,Security")
+			fs.Bool("google-sheet", false, "Save the review to a new Google Sheet")
+		},
+		Run: func(a *app, args []string) error {
+			cfg := reports.AccessReviewConfig{
+				Period:    a.flag("period"),
+				Collector: config.GetEnv("USER"),
+			}
+			cfg.DormantDays, _ = strconv.Atoi(a.flag("dormant-days"))
+			if groups := a.flag("privileged-groups"); groups != "" {
+				cfg.PrivilegedGroups = strings.Split(groups, ",")
+			}
+			review := reports.NewAccessReview(cfg)
+
+			c, err := a.orchestrator()
+			if err != nil {
+				return err
+			}
+			// Failed collections are recorded in the evidence, and reported once the artifacts are written
+			if c.Okta != nil {
+				review.CollectOkta(c.Okta)
+			}
+			if c.Google != nil {
+				review.CollectGoogle(c.Google)
+			}
+			if c.Slack != nil {
+				review.CollectSlack(c.Slack)
+			}
+
+			if a.boolFlag("google-sheet") {
+				if c.Google == nil {
+					return fmt.Errorf("--google-sheet requires a Google service account")
+				}
+				sheet, err := review.SaveToGoogleSheet(c.Google)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(a.out, "Saved to", sheet.SpreadsheetURL)
+			}
+
+			if a.opts.Output != "" {
+				if err := review.Export(a.opts.Output); err != nil {
+					return err
+				}
+				fmt.Fprintln(a.out, "Exported to", a.opts.Output)
+			} else if err := a.render(review.Evidence); err != nil {
+				return err
+			}
+
+			if !review.Complete() {
+				return fmt.Errorf("%s is incomplete; see the errors in its evidence", review.Title())
+			}
+			return nil
+		},
+	},
+
+	// ### Drift
+	{
+		Path:    "drift plan",
+		Args:    "<state-file>",
+		MinArgs: 1,
+		Summary: "Compare a desired state file (.yaml/.json) against Okta and Google, and list the changes which remediate the drift",
+		Columns: []string{"provider", "kind", "resource", "subject", "action", "actual", "desired"},
+		Flags: func(fs *flag.FlagSet) {
+			fs.String("out", "", "Save the plan to a JSON file, to apply later with drift apply --plan")
+			fs.Bool("fail-on-drift", false, "Exit with an error when any drift is found")
+		},
+		Run: func(a *app, args []string) error {
+			state, err := drift.LoadState(args[0])
+			if err != nil {
+				return err
+			}
+			d, err := a.detector()
+			if err != nil {
+				return err
+			}
+
+			plan := d.Plan(state)
+			if out := a.flag("out"); out != "" {
+				if err := plan.Save(out); err != nil {
+					return err
+				}
+				fmt.Fprintln(a.out, "Saved plan to", out)
+			}
+			if err := a.renderDrift(plan); err != nil {
+				return err
+			}
+
+			if len(plan.Errors) > 0 {
+				return fmt.Errorf("%d resource(s) could not be compared", len(plan.Errors))
+			}
+			if a.boolFlag("fail-on-drift") && !plan.InSync() {
+				return fmt.Errorf("found %d drift(s)", len(plan.Drift))
+			}
+			return nil
+		},
+	},
+	{
+		Path:    "drift apply",
+		Args:    "[state-file]",
+		Summary: "Remediate the drift from a desired state file, or from a plan saved with drift plan --out",
+		Columns: []string{"provider", "kind", "resource", "subject", "action", "status", "error"},
+		Flags: func(fs *flag.FlagSet) {
+			fs.String("plan", "", "Apply a plan saved with drift plan --out instead of planning again; its statuses are updated in place")
+		},
+		Run: func(a *app, args []string) error {
+			d, err := a.detector()
+			if err != nil {
+				return err
+			}
+
+			var plan *drift.Report
+			if path := a.flag("plan"); path != "" {
+				if plan, err = drift.LoadReport(path); err != nil {
+					return err
+				}
+			} else if len(args) == 0 {
+				return fmt.Errorf("drift apply: expected a state file or --plan")
+			} else {
+				state, err := drift.LoadState(args[0])
+				if err != nil {
+					return err
+				}
+				plan = d.Plan(state)
+			}
+
+			pending := len(plan.Pending())
+			if pending == 0 {
+				return a.renderDrift(plan)
+			}
+			fmt.Fprint(a.out, plan.String())
+			if !a.opts.DryRun && !a.opts.Yes && !a.ask(fmt.Sprintf("Apply %d change(s)?", pending)) {
+				return fmt.Errorf("aborted")
+			}
+
+			err = d.Apply(plan, drift.AutoApprove)
+			if path := a.flag("plan"); path != "" && !a.opts.DryRun {
+				if saveErr := plan.Save(path); saveErr != nil {
+					err = errors.Join(err, saveErr)
+				}
+			}
+			if renderErr := a.renderDrift(plan); renderErr != nil {
+				return renderErr
+			}
+			return err
+		},
+	},
+
+	// ### Server
+	{
+		Path:    "serve",
+		Summary: "Serve rego operations over an authenticated HTTP API; tokens are read from $REGO_API_TOKENS (name:secret:scope,scope;...)",
+		Flags: func(fs *flag.FlagSet) {
+			fs.String("addr", ":8443", "Address to listen on")
+			fs.String("cert", "", "TLS certificate file; the API is served over plain HTTP without -cert and -key")
+			fs.String("key", "", "TLS private key file")
+		},
+		Run: func(a *app, args []string) error {
+			c, err := a.orchestrator()
+			if err != nil {
+				return err
+			}
+
+			s := api.NewServer(a.flag("addr"), c, a.opts.Verbosity)
+			if err := s.ParseTokens(config.GetEnv("REGO_API_TOKENS")); err != nil {
+				return err
+			}
+
+			// On a signal, running requests are drained before the clients are closed, flushing their caches
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			closed := make(chan error, 1)
+			go func() {
+				<-ctx.Done()
+				shutdown, cancel := context.WithTimeout(context.Background(), time.Minute)
+				defer cancel()
+				closed <- errors.Join(s.Shutdown(shutdown), c.Close(shutdown))
+			}()
+
+			listen := s.ListenAndServe
+			if a.flag("cert") != "" || a.flag("key") != "" {
+				listen = func() error { return s.ListenAndServeTLS(a.flag("cert"), a.flag("key")) }
+			}
+			if err := listen(); err != nil {
+				return errors.Join(err, c.Close(context.Background()))
+			}
+			return <-closed
+		},
+	},
+
+	// ### Orchestrators
+	{
+		Path:        "offboard",
+		Args:        "<email>",
+		MinArgs:     1,
+		Summary:     "Offboard a user across every configured service",
+		Destructive: true,
+		Flags: func(fs *flag.FlagSet) {
+			fs.String("transfer-to", "", "Email of the user who receives the Drive/Calendar data")
+			fs.String("suspended-ou", "", "Organizational unit to move the Google user into")
+			fs.String("skip", "", "Comma-separated steps to skip, e.g. `jamf.lock,slack.deactivate`")
+			fs.Bool("steps-only", false, "List the steps without running them (--dry-run runs them without making changes)")
+			fs.Bool("stop-on-error", false, "Stop at the first failed step")
+		},
+		Run: func(a *app, args []string) error {
+			c, err := a.orchestrator()
+			if err != nil {
+				return err
+			}
+
+			cfg := &orchestrators.OffboardingConfig{
+				TransferTo:  a.flag("transfer-to"),
+				SuspendedOU: a.flag("suspended-ou"),
+				WorkflowOptions: orchestrators.WorkflowOptions{
+					Retries:     2,
+					DryRun:      a.boolFlag("steps-only"),
+					StopOnError: a.boolFlag("stop-on-error"),
+				},
+			}
+			if skip := a.flag("skip"); skip != "" {
+				cfg.Skip = strings.Split(skip, ",")
+			}
+
+			report := c.Offboard(args[0], cfg)
+			if a.opts.Format == "table" && a.opts.Output == "" {
+				fmt.Fprint(a.out, report.String())
+			} else if err := a.render(report.Results); err != nil {
+				return err
+			}
+			if !report.Succeeded() {
+				return fmt.Errorf("%d step(s) failed", len(report.Failed()))
+			}
+			return nil
+		},
+	},
+}
@@ -0,0 +1,239 @@
+/*
+# ReGo CLI
+
+This binary exposes the ReGo library as subcommands, so one-off tasks don't require writing Go:
+
+	rego okta users list --format json
+	rego google drive transfer departed@example.com manager@example.com
+	rego --profile staging backupify export departed@example.com --app GoogleMail
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// cmd/rego/main.go
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gemini-oss/rego/pkg/common/config"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+// Options shared by every command
+type globalOptions struct {
+	Profile   string // Name of the profile to load credentials from
+	Format    string // Output format {table, json, csv}
+	Output    string // File to export results to {.csv, .xlsx}
+	Columns   string // Comma-separated columns to display in table/csv output
+	Verbosity int    // Log level of the clients
+	Yes       bool   // Skip confirmation prompts for destructive commands
+	DryRun    bool   // Record mutating requests instead of sending them, and print the plan
+}
+
+// app is the state passed to every command
+type app struct {
+	opts    globalOptions
+	in      *bufio.Reader
+	out     io.Writer
+	command *command
+	flags   *flag.FlagSet
+	clients clients
+	plan    *requests.Plan // Mutations recorded by the clients during a dry run
+}
+
+func main() {
+	a := &app{
+		in:  bufio.NewReader(os.Stdin),
+		out: os.Stdout,
+	}
+
+	if err := a.run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintln(os.Stderr, "rego:", err)
+		os.Exit(1)
+	}
+}
+
+func (a *app) run(args []string) error {
+	// Global flags may precede the command, e.g. `rego --profile staging okta users list`
+	global := flag.NewFlagSet("rego", flag.ContinueOnError)
+	a.globalFlags(global)
+	global.Usage = func() { a.usage("") }
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+	args = global.Args()
+
+	words := []string{}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		words = append(words, arg)
+	}
+
+	cmd, depth := findCommand(words)
+	if cmd == nil {
+		a.usage(strings.Join(words, " "))
+		if len(words) == 0 {
+			return flag.ErrHelp
+		}
+		return fmt.Errorf("unknown command %q", strings.Join(words, " "))
+	}
+
+	// ...or follow it, mixed with the command's own flags and arguments
+	fs := flag.NewFlagSet("rego "+cmd.Path, flag.ContinueOnError)
+	a.command, a.flags = cmd, fs
+	a.globalFlags(fs)
+	if cmd.Flags != nil {
+		cmd.Flags(fs)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: rego %s %s\n\n%s\n\nFlags:\n", cmd.Path, cmd.Args, cmd.Summary)
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args[depth:])
+	if err != nil {
+		return err
+	}
+	if len(positional) < cmd.MinArgs {
+		fs.Usage()
+		return fmt.Errorf("%s: expected %s", cmd.Path, cmd.Args)
+	}
+
+	if err := loadProfile(a.opts.Profile); err != nil {
+		return err
+	}
+
+	if a.opts.DryRun {
+		a.plan = requests.NewPlan()
+	} else if cmd.Destructive && !a.confirm(cmd, positional) {
+		return fmt.Errorf("aborted")
+	}
+
+	err = cmd.Run(a, positional)
+	if a.plan != nil {
+		fmt.Fprintf(a.out, "\n[dry run] %s", a.plan.String())
+	}
+	return err
+}
+
+// globalFlags registers the options shared by every command, defaulting to any values already parsed
+func (a *app) globalFlags(fs *flag.FlagSet) {
+	profile := a.opts.Profile
+	if profile == "" {
+		profile = config.GetEnv("REGO_PROFILE")
+	}
+	format := a.opts.Format
+	if format == "" {
+		format = "table"
+	}
+	verbosity := a.opts.Verbosity
+	if verbosity == 0 {
+		verbosity = log.WARNING
+	}
+
+	fs.StringVar(&a.opts.Profile, "profile", profile, "Profile to load credentials from (~/.config/rego/<profile>.env), or $REGO_PROFILE")
+	fs.StringVar(&a.opts.Format, "format", format, "Output format {table, json, csv}")
+	fs.StringVar(&a.opts.Output, "output", a.opts.Output, "Export results to a file {.csv, .xlsx}")
+	fs.StringVar(&a.opts.Columns, "columns", a.opts.Columns, "Comma-separated columns to display (table/csv output)")
+	fs.IntVar(&a.opts.Verbosity, "verbosity", verbosity, "Log level {0: trace, 1: debug, 2: info, 3: warning, 4: error}")
+	fs.BoolVar(&a.opts.Yes, "yes", a.opts.Yes, "Skip confirmation prompts for destructive commands")
+	fs.BoolVar(&a.opts.DryRun, "dry-run", a.opts.DryRun, "Print the requests a command would make instead of changing anything")
+}
+
+// parseInterspersed parses flags that appear before, between or after positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// flag returns the value of a flag of the running command
+func (a *app) flag(name string) string {
+	if f := a.flags.Lookup(name); f != nil {
+		return f.Value.String()
+	}
+	return ""
+}
+
+func (a *app) boolFlag(name string) bool {
+	return a.flag(name) == "true"
+}
+
+// done reports the outcome of a mutating command, e.g. `done("deactivated", email)`
+func (a *app) done(action, subject string) {
+	if a.opts.DryRun {
+		fmt.Fprintln(a.out, "[dry run] Would have", action, subject)
+		return
+	}
+	fmt.Fprintln(a.out, strings.ToUpper(action[:1])+action[1:], subject)
+}
+
+// confirm prompts before running a destructive command, unless `--yes` was given
+func (a *app) confirm(cmd *command, args []string) bool {
+	if a.opts.Yes {
+		return true
+	}
+
+	return a.ask(fmt.Sprintf("About to run `rego %s %s`: %s\nContinue?", cmd.Path, strings.Join(args, " "), cmd.Summary))
+}
+
+// ask prompts for a yes/no answer, defaulting to no
+func (a *app) ask(question string) bool {
+	fmt.Fprintf(a.out, "%s [y/N] ", question)
+	answer, _ := a.in.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// usage prints the commands matching a prefix
+func (a *app) usage(prefix string) {
+	fmt.Fprintln(os.Stderr, "Usage: rego [flags] <command> [arguments] [flags]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+
+	matched := []*command{}
+	for _, cmd := range commands {
+		if strings.HasPrefix(cmd.Path, prefix) {
+			matched = append(matched, cmd)
+		}
+	}
+	if len(matched) == 0 {
+		matched = commands
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].Path < matched[j].Path })
+
+	tw := tabwriter.NewWriter(os.Stderr, 0, 0, 3, ' ', 0)
+	for _, cmd := range matched {
+		fmt.Fprintf(tw, "  %s\t%s\n", strings.TrimSpace(cmd.Path+" "+cmd.Args), cmd.Summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(os.Stderr, "\nRun `rego <command> -h` for the flags of a command.")
+}
@@ -0,0 +1,134 @@
+// cmd/rego/output.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gemini-oss/rego/pkg/common/exporters"
+	"github.com/gemini-oss/rego/pkg/drift"
+)
+
+// Maximum width of a table cell before it is truncated
+const maxCellWidth = 60
+
+/*
+ * # Render the result of a command
+ * - `--output` exports the result to a CSV/XLSX file
+ * - `--format json` prints the result as-is; `table` and `csv` flatten it into columns
+ * - `--columns` (or the command's default columns) selects the columns of table/csv output
+ */
+func (a *app) render(result interface{}) error {
+	if a.opts.Output != "" {
+		name := strings.TrimSuffix(filepath.Base(a.opts.Output), filepath.Ext(a.opts.Output))
+		if err := exporters.Export(a.opts.Output, exporters.Sheet{Name: name, Data: result}); err != nil {
+			return err
+		}
+		fmt.Fprintln(a.out, "Exported to", a.opts.Output)
+		return nil
+	}
+
+	switch a.opts.Format {
+	case "json":
+		b, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(a.out, string(b))
+		return nil
+	case "csv", "table":
+		table, err := exporters.NewTable(result)
+		if err != nil {
+			return err
+		}
+		table = selectColumns(table, a.columns())
+		if a.opts.Format == "csv" {
+			return writeCSV(a, table)
+		}
+		return writeTable(a, table)
+	default:
+		return fmt.Errorf("unsupported format %q", a.opts.Format)
+	}
+}
+
+// columns returns the columns requested by `--columns`, falling back to the command's defaults
+func (a *app) columns() []string {
+	if a.opts.Columns != "" {
+		columns := []string{}
+		for _, c := range strings.Split(a.opts.Columns, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				columns = append(columns, c)
+			}
+		}
+		return columns
+	}
+	if a.command != nil {
+		return a.command.Columns
+	}
+	return nil
+}
+
+// selectColumns keeps the given columns of a table, in order; unknown columns are ignored
+func selectColumns(table *exporters.Table, columns []string) *exporters.Table {
+	if len(columns) == 0 {
+		return table
+	}
+
+	index := []int{}
+	selected := &exporters.Table{}
+	for _, c := range columns {
+		if i := slices.Index(table.Headers, c); i >= 0 {
+			index = append(index, i)
+			selected.Headers = append(selected.Headers, c)
+		}
+	}
+	if len(index) == 0 {
+		return table
+	}
+
+	for _, row := range table.Rows {
+		cells := make([]exporters.Cell, len(index))
+		for j, i := range index {
+			cells[j] = row[i]
+		}
+		selected.Rows = append(selected.Rows, cells)
+	}
+	return selected
+}
+
+func writeTable(a *app, table *exporters.Table) error {
+	tw := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(table.Headers, "\t")))
+	for _, row := range table.Rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = truncate(strings.ReplaceAll(cell.String(), "\n", " "), maxCellWidth)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeCSV(a *app, table *exporters.Table) error {
+	return table.WriteCSV(a.out)
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+// renderDrift prints a drift report as a plan in table format, or its drift in any other format
+func (a *app) renderDrift(report *drift.Report) error {
+	if a.opts.Format == "table" && a.opts.Output == "" {
+		fmt.Fprint(a.out, report.String())
+		return nil
+	}
+	return a.render(report.Drift)
+}
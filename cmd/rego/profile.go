@@ -0,0 +1,70 @@
+// cmd/rego/profile.go
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gemini-oss/rego/pkg/common/config"
+)
+
+// profileDir returns the directory holding profiles: `$REGO_CONFIG_DIR`, or `~/.config/rego`
+func profileDir() (string, error) {
+	if dir := config.GetEnv("REGO_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rego"), nil
+}
+
+/*
+ * # Load a profile into the environment
+ * - A profile is a `<name>.env` file of `KEY=VALUE` lines (e.g. `OKTA_API_TOKEN=...`), read by the clients as usual
+ * - Values in the profile override variables already set in the environment
+ * - Blank lines and lines starting with `#` are ignored, and values may be quoted
+ */
+func loadProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	dir, err := profileDir()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, name+".env")
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("profile %q: %w", name, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		if err := os.Setenv(strings.TrimSpace(key), value); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
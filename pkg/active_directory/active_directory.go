@@ -13,13 +13,16 @@ This package initializes all the methods which interact with {Active Directory/L
 package active_directory
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"time"
 
 	"github.com/gemini-oss/rego/pkg/common/cache"
 	"github.com/gemini-oss/rego/pkg/common/config"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
 	"github.com/gemini-oss/rego/pkg/common/log"
 
 	"github.com/go-ldap/ldap/v3"
@@ -39,6 +42,47 @@ func (c *Client) BuildDN(endpoint string, identifiers ...string) string {
 	return url
 }
 
+/*
+ * # Close the {Active Directory,LDAP} Client
+ * Closes the LDAP connection, then writes the cache to disk
+ * - `ctx` is accepted so the client closes like the HTTP clients; LDAP has no requests to drain once its connection is closed
+ */
+func (c *Client) Close(ctx context.Context) error {
+	var err error
+	if c.LDAP != nil {
+		err = c.LDAP.Close()
+	}
+	return errors.Join(err, c.Cache.Flush())
+}
+
+// Name returns the name of the service, `active_directory`
+func (c *Client) Name() string {
+	return "active_directory"
+}
+
+// Endpoint returns the URL of the LDAP server the client is bound to, e.g. `ldaps://dc.example.com:636`
+func (c *Client) Endpoint() string {
+	return c.Server
+}
+
+/*
+ * # Check the Health of the {Active Directory,LDAP} Client
+ * Asks the server which account the connection is bound as, so it is reachable and the bind still valid
+ * - LDAP requests cannot be cancelled, so `ctx` is only checked before the request is sent
+ */
+func (c *Client) HealthCheck(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c.LDAP == nil {
+		return errors.New("active_directory: not connected")
+	}
+	if _, err := c.LDAP.WhoAmI(nil); err != nil {
+		return ldapError(err)
+	}
+	return nil
+}
+
 /*
   - # Generate {Active Directory,LDAP} Client
   - @param logger *log.Logger
@@ -120,6 +164,40 @@ func NewClient(verbosity int) *Client {
 	}
 }
 
+/*
+ * # Classify an LDAP error
+ * - Records the name of the result code (e.g. `No Such Object`) on an `APIError`, classified like those of HTTP APIs
+ * - Errors without a result code, e.g. a closed connection, are returned as they are
+ */
+func ldapError(err error) error {
+	var ldapErr *ldap.Error
+	if !errors.As(err, &ldapErr) {
+		return err
+	}
+
+	var class error
+	switch ldapErr.ResultCode {
+	case ldap.LDAPResultNoSuchObject:
+		class = rerrors.ErrNotFound
+	case ldap.LDAPResultEntryAlreadyExists:
+		class = rerrors.ErrConflict
+	case ldap.LDAPResultInsufficientAccessRights:
+		class = rerrors.ErrForbidden
+	case ldap.LDAPResultInvalidCredentials, ldap.LDAPResultInappropriateAuthentication:
+		class = rerrors.ErrUnauthorized
+	case ldap.LDAPResultBusy, ldap.LDAPResultUnavailable, ldap.LDAPResultUnwillingToPerform, ldap.LDAPResultTimeLimitExceeded, ldap.ErrorNetwork:
+		class = rerrors.ErrUnavailable
+	case ldap.LDAPResultInvalidDNSyntax, ldap.LDAPResultFilterError, ldap.LDAPResultConstraintViolation, ldap.LDAPResultObjectClassViolation:
+		class = rerrors.ErrInvalid
+	}
+
+	message := ldapErr.Error()
+	if ldapErr.Err != nil {
+		message = ldapErr.Err.Error()
+	}
+	return &rerrors.APIError{Provider: "active_directory", Code: ldap.LDAPResultCodeMap[ldapErr.ResultCode], Message: message, Class: class}
+}
+
 /*
  * Perform a generic request to the Active Directory Server
  */
@@ -143,7 +221,7 @@ func do[T Slice[E], E any](c *Client, filter string, attributes *[]Attribute) (T
 	// sr, err := c.LDAP.Search(searchRequest)
 	sr, err := c.LDAP.SearchWithPaging(searchRequest, 1000)
 	if err != nil {
-		return *new(T), fmt.Errorf(ldap.LDAPResultCodeMap[err.(*ldap.Error).ResultCode])
+		return *new(T), ldapError(err)
 	}
 
 	// Process each LDAP entry
@@ -0,0 +1,120 @@
+// Code generated by mockgen from interfaces.go; DO NOT EDIT.
+
+// pkg/active_directory/activedirectorymock/mocks.go
+package activedirectorymock
+
+import (
+	"sync"
+
+	"github.com/gemini-oss/rego/pkg/active_directory"
+)
+
+// Call is a call received by a mock
+type Call struct {
+	Method string        // Name of the method called
+	Args   []interface{} // Arguments of the call
+}
+
+// calls records the calls received by a mock
+type calls struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+func (c *calls) record(method string, args ...interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls received, in order; only those of `method` when set
+func (c *calls) Calls(method ...string) []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	received := []Call{}
+	for _, call := range c.calls {
+		if len(method) == 0 || call.Method == method[0] {
+			received = append(received, call)
+		}
+	}
+	return received
+}
+
+// GroupService is a mock of `active_directory.GroupService`; methods without a func return zero values
+type GroupService struct {
+	ListAllGroupsFunc func() (*active_directory.Groups, error)
+
+	calls
+}
+
+var _ active_directory.GroupService = (*GroupService)(nil)
+
+func (m *GroupService) ListAllGroups() (r0 *active_directory.Groups, r1 error) {
+	m.record("ListAllGroups")
+	if m.ListAllGroupsFunc != nil {
+		return m.ListAllGroupsFunc()
+	}
+	return
+}
+
+// UserService is a mock of `active_directory.UserService`; methods without a func return zero values
+type UserService struct {
+	ListAllAdminsFunc             func() (*active_directory.Users, error)
+	ListAllUsersFunc              func() (*active_directory.Users, error)
+	ActiveUsersFunc               func() (*active_directory.Users, error)
+	DisabledUsersFunc             func() (*active_directory.Users, error)
+	PasswordNeverExpiresUsersFunc func() (*active_directory.Users, error)
+	MemberOfFunc                  func(string) (*active_directory.Users, error)
+
+	calls
+}
+
+var _ active_directory.UserService = (*UserService)(nil)
+
+func (m *UserService) ListAllAdmins() (r0 *active_directory.Users, r1 error) {
+	m.record("ListAllAdmins")
+	if m.ListAllAdminsFunc != nil {
+		return m.ListAllAdminsFunc()
+	}
+	return
+}
+
+func (m *UserService) ListAllUsers() (r0 *active_directory.Users, r1 error) {
+	m.record("ListAllUsers")
+	if m.ListAllUsersFunc != nil {
+		return m.ListAllUsersFunc()
+	}
+	return
+}
+
+func (m *UserService) ActiveUsers() (r0 *active_directory.Users, r1 error) {
+	m.record("ActiveUsers")
+	if m.ActiveUsersFunc != nil {
+		return m.ActiveUsersFunc()
+	}
+	return
+}
+
+func (m *UserService) DisabledUsers() (r0 *active_directory.Users, r1 error) {
+	m.record("DisabledUsers")
+	if m.DisabledUsersFunc != nil {
+		return m.DisabledUsersFunc()
+	}
+	return
+}
+
+func (m *UserService) PasswordNeverExpiresUsers() (r0 *active_directory.Users, r1 error) {
+	m.record("PasswordNeverExpiresUsers")
+	if m.PasswordNeverExpiresUsersFunc != nil {
+		return m.PasswordNeverExpiresUsersFunc()
+	}
+	return
+}
+
+func (m *UserService) MemberOf(a0 string) (r0 *active_directory.Users, r1 error) {
+	m.record("MemberOf", a0)
+	if m.MemberOfFunc != nil {
+		return m.MemberOfFunc(a0)
+	}
+	return
+}
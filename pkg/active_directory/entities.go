@@ -49,90 +49,90 @@ type Users []*User
 // User represents an AD user with detailed fields (AKA: Contact)
 // https://learn.microsoft.com/en-us/windows/win32/adschema/c-user
 type User struct {
-	AccountExpires             string   `ldap:"accountExpires"`             // https://learn.microsoft.com/en-us/windows/win32/adschema/a-accountexpires
-	AdminCount                 int      `ldap:"adminCount"`                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-admincount
-	AltSecurityIdentities      []string `ldap:"altSecurityIdentities"`      // https://learn.microsoft.com/en-us/windows/win32/adschema/a-altsecurityidentities
-	BadPasswordTime            int64    `ldap:"badPasswordTime"`            // https://learn.microsoft.com/en-us/windows/win32/adschema/a-badpasswordtime
-	BadPwdCount                int      `ldap:"badPwdCount"`                // https://learn.microsoft.com/en-us/windows/win32/adschema/a-badpwdcount
-	City                       string   `ldap:"l"`                          // https://learn.microsoft.com/en-us/windows/win32/adschema/a-l
-	CodePage                   int      `ldap:"codePage"`                   // https://learn.microsoft.com/en-us/windows/win32/adschema/a-codepage
-	CommonName                 string   `ldap:"cn"`                         // https://learn.microsoft.com/en-us/windows/win32/adschema/a-cn
-	Country                    string   `ldap:"c"`                          // https://learn.microsoft.com/en-us/windows/win32/adschema/a-c
-	CountryCode                int      `ldap:"countryCode"`                // https://learn.microsoft.com/en-us/windows/win32/adschema/a-countrycode
-	Department                 string   `ldap:"department"`                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-department
-	DistinguishedName          string   `ldap:"dn"`                         // https://learn.microsoft.com/en-us/windows/win32/adschema/a-distinguishedName
-	DSCorePropagationData      string   `ldap:"dSCorePropagationData"`      // https://learn.microsoft.com/en-us/windows/win32/adschema/a-dscorepropagationdata
-	DisplayName                string   `ldap:"displayName"`                // https://learn.microsoft.com/en-us/windows/win32/adschema/a-displayname
-	Division                   string   `ldap:"division"`                   // https://learn.microsoft.com/en-us/windows/win32/adschema/a-division
-	EmployeeID                 string   `ldap:"employeeID"`                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-employeeid
-	EmployeeNumber             string   `ldap:"employeeNumber"`             // https://learn.microsoft.com/en-us/windows/win32/adschema/a-employeenumber
-	GivenName                  string   `ldap:"givenName"`                  // https://learn.microsoft.com/en-us/windows/win32/adschema/a-givenname
-	InstanceType               int      `ldap:"instanceType"`               // https://learn.microsoft.com/en-us/windows/win32/adschema/a-instancetype
-	LastLogoff                 int64    `ldap:"lastLogoff"`                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-lastlogoff
-	LastLogon                  int64    `ldap:"lastLogon"`                  // https://learn.microsoft.com/en-us/windows/win32/adschema/a-lastlogon
-	LastLogonTimestamp         string   `ldap:"lastLogonTimestamp"`         // https://learn.microsoft.com/en-us/windows/win32/adschema/a-lastlogontimestamp
-	Manager                    string   `ldap:"manager"`                    // https://learn.microsoft.com/en-us/windows/win32/adschema/a-manager
-	Mail                       string   `ldap:"mail"`                       // https://learn.microsoft.com/en-us/windows/win32/adschema/a-mail
-	MemberOf                   []string `ldap:"memberOf"`                   // https://learn.microsoft.com/en-us/windows/win32/adschema/a-memberof
-	Mobile                     string   `ldap:"mobile"`                     // https://learn.microsoft.com/en-us/windows/win32/adschema/a-mobile
-	Name                       string   `ldap:"name"`                       // https://learn.microsoft.com/en-us/windows/win32/adschema/a-name
-	ObjectCategory             string   `ldap:"objectCategory"`             // https://learn.microsoft.com/en-us/windows/win32/adschema/a-objectcategory
-	ObjectClass                string   `ldap:"objectClass"`                // https://learn.microsoft.com/en-us/windows/win32/adschema/a-objectclass
-	ObjectGUID                 []byte   `ldap:"objectGUID"`                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-objectguid
-	ObjectSID                  []byte   `ldap:"objectSid"`                  // https://learn.microsoft.com/en-us/windows/win32/adschema/a-objectsid
-	PhysicalDeliveryOfficeName string   `ldap:"physicalDeliveryOfficeName"` // https://learn.microsoft.com/en-us/windows/win32/adschema/a-physicaldeliveryofficename
-	PostalCode                 string   `ldap:"postalCode"`                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-postalcode
-	PwdLastSet                 int64    `ldap:"pwdLastSet"`                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-pwdlastset
-	ReplPropertyMetaData       string   `ldap:"replPropertyMetaData"`       // https://learn.microsoft.com/en-us/windows/win32/adschema/a-replpropertymetadata
-	SAMAccountName             string   `ldap:"sAMAccountName"`             // https://learn.microsoft.com/en-us/windows/win32/adschema/a-samaccountname
-	SAMAccountType             int      `ldap:"sAMAccountType"`             // https://learn.microsoft.com/en-us/windows/win32/adschema/a-samaccounttype
-	SN                         string   `ldap:"sn"`                         // https://learn.microsoft.com/en-us/windows/win32/adschema/a-sn
-	StreetAddress              string   `ldap:"streetAddress"`              // https://learn.microsoft.com/en-us/windows/win32/adschema/a-streetaddress
-	TelephoneNumber            string   `ldap:"telephoneNumber"`            // https://learn.microsoft.com/en-us/windows/win32/adschema/a-telephonenumber
-	Title                      string   `ldap:"title"`                      // https://learn.microsoft.com/en-us/windows/win32/adschema/a-title
-	UserAccountControl         int      `ldap:"userAccountControl"`         // https://learn.microsoft.com/en-us/windows/win32/adschema/a-useraccountcontrol
-	UserPrincipalName          string   `ldap:"userPrincipalName"`          // https://learn.microsoft.com/en-us/windows/win32/adschema/a-userprincipalname
-	USNChanged                 int64    `ldap:"uSNChanged"`                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-usnchanged
-	USNCreated                 int64    `ldap:"uSNCreated"`                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-usncreated
-	WhenChanged                string   `ldap:"whenChanged"`                // https://learn.microsoft.com/en-us/windows/win32/adschema/a-whenchanged
-	WhenCreated                string   `ldap:"whenCreated"`                // https://learn.microsoft.com/en-us/windows/win32/adschema/a-whencreated
+	AccountExpires             string   `ldap:"accountExpires" json:"accountExpires,omitempty"`                         // https://learn.microsoft.com/en-us/windows/win32/adschema/a-accountexpires
+	AdminCount                 int      `ldap:"adminCount" json:"adminCount,omitempty"`                                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-admincount
+	AltSecurityIdentities      []string `ldap:"altSecurityIdentities" json:"altSecurityIdentities,omitempty"`           // https://learn.microsoft.com/en-us/windows/win32/adschema/a-altsecurityidentities
+	BadPasswordTime            int64    `ldap:"badPasswordTime" json:"badPasswordTime,omitempty"`                       // https://learn.microsoft.com/en-us/windows/win32/adschema/a-badpasswordtime
+	BadPwdCount                int      `ldap:"badPwdCount" json:"badPwdCount,omitempty"`                               // https://learn.microsoft.com/en-us/windows/win32/adschema/a-badpwdcount
+	City                       string   `ldap:"l" json:"l,omitempty"`                                                   // https://learn.microsoft.com/en-us/windows/win32/adschema/a-l
+	CodePage                   int      `ldap:"codePage" json:"codePage,omitempty"`                                     // https://learn.microsoft.com/en-us/windows/win32/adschema/a-codepage
+	CommonName                 string   `ldap:"cn" json:"cn,omitempty"`                                                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-cn
+	Country                    string   `ldap:"c" json:"c,omitempty"`                                                   // https://learn.microsoft.com/en-us/windows/win32/adschema/a-c
+	CountryCode                int      `ldap:"countryCode" json:"countryCode,omitempty"`                               // https://learn.microsoft.com/en-us/windows/win32/adschema/a-countrycode
+	Department                 string   `ldap:"department" json:"department,omitempty"`                                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-department
+	DistinguishedName          string   `ldap:"dn" json:"dn,omitempty"`                                                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-distinguishedName
+	DSCorePropagationData      string   `ldap:"dSCorePropagationData" json:"dSCorePropagationData,omitempty"`           // https://learn.microsoft.com/en-us/windows/win32/adschema/a-dscorepropagationdata
+	DisplayName                string   `ldap:"displayName" json:"displayName,omitempty"`                               // https://learn.microsoft.com/en-us/windows/win32/adschema/a-displayname
+	Division                   string   `ldap:"division" json:"division,omitempty"`                                     // https://learn.microsoft.com/en-us/windows/win32/adschema/a-division
+	EmployeeID                 string   `ldap:"employeeID" json:"employeeID,omitempty"`                                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-employeeid
+	EmployeeNumber             string   `ldap:"employeeNumber" json:"employeeNumber,omitempty"`                         // https://learn.microsoft.com/en-us/windows/win32/adschema/a-employeenumber
+	GivenName                  string   `ldap:"givenName" json:"givenName,omitempty"`                                   // https://learn.microsoft.com/en-us/windows/win32/adschema/a-givenname
+	InstanceType               int      `ldap:"instanceType" json:"instanceType,omitempty"`                             // https://learn.microsoft.com/en-us/windows/win32/adschema/a-instancetype
+	LastLogoff                 int64    `ldap:"lastLogoff" json:"lastLogoff,omitempty"`                                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-lastlogoff
+	LastLogon                  int64    `ldap:"lastLogon" json:"lastLogon,omitempty"`                                   // https://learn.microsoft.com/en-us/windows/win32/adschema/a-lastlogon
+	LastLogonTimestamp         string   `ldap:"lastLogonTimestamp" json:"lastLogonTimestamp,omitempty"`                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-lastlogontimestamp
+	Manager                    string   `ldap:"manager" json:"manager,omitempty"`                                       // https://learn.microsoft.com/en-us/windows/win32/adschema/a-manager
+	Mail                       string   `ldap:"mail" json:"mail,omitempty"`                                             // https://learn.microsoft.com/en-us/windows/win32/adschema/a-mail
+	MemberOf                   []string `ldap:"memberOf" json:"memberOf,omitempty"`                                     // https://learn.microsoft.com/en-us/windows/win32/adschema/a-memberof
+	Mobile                     string   `ldap:"mobile" json:"mobile,omitempty"`                                         // https://learn.microsoft.com/en-us/windows/win32/adschema/a-mobile
+	Name                       string   `ldap:"name" json:"name,omitempty"`                                             // https://learn.microsoft.com/en-us/windows/win32/adschema/a-name
+	ObjectCategory             string   `ldap:"objectCategory" json:"objectCategory,omitempty"`                         // https://learn.microsoft.com/en-us/windows/win32/adschema/a-objectcategory
+	ObjectClass                string   `ldap:"objectClass" json:"objectClass,omitempty"`                               // https://learn.microsoft.com/en-us/windows/win32/adschema/a-objectclass
+	ObjectGUID                 []byte   `ldap:"objectGUID" json:"objectGUID,omitempty"`                                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-objectguid
+	ObjectSID                  []byte   `ldap:"objectSid" json:"objectSid,omitempty"`                                   // https://learn.microsoft.com/en-us/windows/win32/adschema/a-objectsid
+	PhysicalDeliveryOfficeName string   `ldap:"physicalDeliveryOfficeName" json:"physicalDeliveryOfficeName,omitempty"` // https://learn.microsoft.com/en-us/windows/win32/adschema/a-physicaldeliveryofficename
+	PostalCode                 string   `ldap:"postalCode" json:"postalCode,omitempty"`                                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-postalcode
+	PwdLastSet                 int64    `ldap:"pwdLastSet" json:"pwdLastSet,omitempty"`                                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-pwdlastset
+	ReplPropertyMetaData       string   `ldap:"replPropertyMetaData" json:"replPropertyMetaData,omitempty"`             // https://learn.microsoft.com/en-us/windows/win32/adschema/a-replpropertymetadata
+	SAMAccountName             string   `ldap:"sAMAccountName" json:"sAMAccountName,omitempty"`                         // https://learn.microsoft.com/en-us/windows/win32/adschema/a-samaccountname
+	SAMAccountType             int      `ldap:"sAMAccountType" json:"sAMAccountType,omitempty"`                         // https://learn.microsoft.com/en-us/windows/win32/adschema/a-samaccounttype
+	SN                         string   `ldap:"sn" json:"sn,omitempty"`                                                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-sn
+	StreetAddress              string   `ldap:"streetAddress" json:"streetAddress,omitempty"`                           // https://learn.microsoft.com/en-us/windows/win32/adschema/a-streetaddress
+	TelephoneNumber            string   `ldap:"telephoneNumber" json:"telephoneNumber,omitempty"`                       // https://learn.microsoft.com/en-us/windows/win32/adschema/a-telephonenumber
+	Title                      string   `ldap:"title" json:"title,omitempty"`                                           // https://learn.microsoft.com/en-us/windows/win32/adschema/a-title
+	UserAccountControl         int      `ldap:"userAccountControl" json:"userAccountControl,omitempty"`                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-useraccountcontrol
+	UserPrincipalName          string   `ldap:"userPrincipalName" json:"userPrincipalName,omitempty"`                   // https://learn.microsoft.com/en-us/windows/win32/adschema/a-userprincipalname
+	USNChanged                 int64    `ldap:"uSNChanged" json:"uSNChanged,omitempty"`                                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-usnchanged
+	USNCreated                 int64    `ldap:"uSNCreated" json:"uSNCreated,omitempty"`                                 // https://learn.microsoft.com/en-us/windows/win32/adschema/a-usncreated
+	WhenChanged                string   `ldap:"whenChanged" json:"whenChanged,omitempty"`                               // https://learn.microsoft.com/en-us/windows/win32/adschema/a-whenchanged
+	WhenCreated                string   `ldap:"whenCreated" json:"whenCreated,omitempty"`                               // https://learn.microsoft.com/en-us/windows/win32/adschema/a-whencreated
 }
 
 type Groups []*Group
 
 type Group struct {
-	DN          string    `ldap:"dn,omitempty"`
-	CommonName  string    `ldap:"commonName,omitempty"`
-	Description string    `ldap:"description,omitempty"`
-	Members     []string  `ldap:"members,omitempty"`
-	ManagedBy   string    `ldap:"managedBy,omitempty"`
-	WhenCreated time.Time `ldap:"whenCreated,omitempty"`
-	WhenChanged time.Time `ldap:"whenChanged,omitempty"`
+	DN          string    `ldap:"dn,omitempty" json:"dn,omitempty"`
+	CommonName  string    `ldap:"commonName,omitempty" json:"commonName,omitempty"`
+	Description string    `ldap:"description,omitempty" json:"description,omitempty"`
+	Members     []string  `ldap:"members,omitempty" json:"members,omitempty"`
+	ManagedBy   string    `ldap:"managedBy,omitempty" json:"managedBy,omitempty"`
+	WhenCreated time.Time `ldap:"whenCreated,omitempty" json:"whenCreated,omitempty"`
+	WhenChanged time.Time `ldap:"whenChanged,omitempty" json:"whenChanged,omitempty"`
 }
 
 type Computers []*Computer
 
 // Computer represents an AD computer account
 type Computer struct {
-	DN                string    `ldap:"dn,omitempty"`
-	CommonName        string    `ldap:"cn,omitempty"`
-	SAMAccountName    string    `ldap:"sAMAccountName,omitempty"`
-	DistinguishedName string    `ldap:"distinguishedName,omitempty"`
-	OperatingSystem   string    `ldap:"operatingSystem,omitempty"`
-	WhenCreated       time.Time `ldap:"whenCreated,omitempty"`
-	WhenChanged       time.Time `ldap:"whenChanged,omitempty"`
+	DN                string    `ldap:"dn,omitempty" json:"dn,omitempty"`
+	CommonName        string    `ldap:"cn,omitempty" json:"cn,omitempty"`
+	SAMAccountName    string    `ldap:"sAMAccountName,omitempty" json:"sAMAccountName,omitempty"`
+	DistinguishedName string    `ldap:"distinguishedName,omitempty" json:"distinguishedName,omitempty"`
+	OperatingSystem   string    `ldap:"operatingSystem,omitempty" json:"operatingSystem,omitempty"`
+	WhenCreated       time.Time `ldap:"whenCreated,omitempty" json:"whenCreated,omitempty"`
+	WhenChanged       time.Time `ldap:"whenChanged,omitempty" json:"whenChanged,omitempty"`
 }
 
 type OUs []*OrganizationalUnit
 
 // OrganizationalUnit represents an AD Organizational Unit
 type OrganizationalUnit struct {
-	DN                string    `ldap:"dn,omitempty"`
-	Name              string    `ldap:"name,omitempty"`
-	DistinguishedName string    `ldap:"distinguishedName,omitempty"`
-	Description       string    `ldap:"description,omitempty"`
-	WhenCreated       time.Time `ldap:"whenCreated,omitempty"`
-	WhenChanged       time.Time `ldap:"whenChanged,omitempty"`
+	DN                string    `ldap:"dn,omitempty" json:"dn,omitempty"`
+	Name              string    `ldap:"name,omitempty" json:"name,omitempty"`
+	DistinguishedName string    `ldap:"distinguishedName,omitempty" json:"distinguishedName,omitempty"`
+	Description       string    `ldap:"description,omitempty" json:"description,omitempty"`
+	WhenCreated       time.Time `ldap:"whenCreated,omitempty" json:"whenCreated,omitempty"`
+	WhenChanged       time.Time `ldap:"whenChanged,omitempty" json:"whenChanged,omitempty"`
 }
 
 // END OF ACTIVE DIRECTORY OBJECT CLASS ENTITIES
@@ -0,0 +1,35 @@
+/*
+# Active Directory Interfaces
+
+This package contains the interfaces of each Active Directory resource, which the `Client` satisfies. Code depending on an
+interface rather than the `Client` can be unit tested with the mocks of `pkg/active_directory/activedirectorymock`
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/active_directory/interfaces.go
+package active_directory
+
+//go:generate go run ../../cmd/mockgen -out activedirectorymock/mocks.go
+
+// GroupService lists groups
+type GroupService interface {
+	ListAllGroups() (*Groups, error)
+}
+
+// UserService lists users by status, role and group
+type UserService interface {
+	ListAllAdmins() (*Users, error)
+	ListAllUsers() (*Users, error)
+	ActiveUsers() (*Users, error)
+	DisabledUsers() (*Users, error)
+	PasswordNeverExpiresUsers() (*Users, error)
+	MemberOf(group string) (*Users, error)
+}
+
+var (
+	_ GroupService = (*Client)(nil)
+	_ UserService  = (*Client)(nil)
+)
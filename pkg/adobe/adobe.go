@@ -0,0 +1,273 @@
+/*
+# Adobe
+
+This package initializes all the methods for functions which interact with the Adobe User Management API:
+https://adobe-apiplatform.github.io/umapi-documentation/en/
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/adobe/adobe.go
+package adobe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/config"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/ratelimit"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+const (
+	BaseURL = "https://usermanagement.adobe.io/v2/usermanagement" // https://adobe-apiplatform.github.io/umapi-documentation/en/api/
+	IMSURL  = "https://ims-na1.adobelogin.com"                    // https://developer.adobe.com/developer-console/docs/guides/authentication/ServerToServerAuthentication/
+	Scopes  = "openid,AdobeID,user_management_sdk"                // https://developer.adobe.com/developer-console/docs/guides/authentication/ServerToServerAuthentication/implementation/
+)
+
+const (
+	AdobeAction    = "%s/action/%s"              // https://adobe-apiplatform.github.io/umapi-documentation/en/api/ActionsCmds.html
+	AdobeGroups    = "%s/groups/%s"              // https://adobe-apiplatform.github.io/umapi-documentation/en/api/getUserGroups.html
+	AdobeOrgUsers  = "%s/organizations/%s/users" // https://adobe-apiplatform.github.io/umapi-documentation/en/api/getUser.html
+	AdobeUsers     = "%s/users/%s"               // https://adobe-apiplatform.github.io/umapi-documentation/en/api/getUsersWithPage.html
+	IMSAccessToken = "%s/ims/token/v3"           // https://developer.adobe.com/developer-console/docs/guides/authentication/ServerToServerAuthentication/implementation/
+)
+
+// BuildURL builds a URL for a given resource and identifiers, scoped to the organization.
+func (c *Client) BuildURL(endpoint string, identifiers ...string) string {
+	url := fmt.Sprintf(endpoint, c.BaseURL, c.OrgID)
+	for _, id := range identifiers {
+		url = fmt.Sprintf("%s/%s", url, id)
+	}
+	c.Log.Debug("url:", url)
+	return url
+}
+
+// Close closes the HTTP client (see `requests.Client.Close`), then flushes the cache (see `cache.Cache.Flush`)
+func (c *Client) Close(ctx context.Context) error {
+	return errors.Join(c.HTTP.Close(ctx), c.Cache.Flush())
+}
+
+// Name returns the name of the service, `adobe`
+func (c *Client) Name() string {
+	return "adobe"
+}
+
+// Endpoint returns the base URL of the API the client sends its requests to
+func (c *Client) Endpoint() string {
+	return c.BaseURL
+}
+
+/*
+ * # Check the Health of the Adobe Client
+ * Gets the first page of groups, so the API is reachable and the credential authorized
+ * /v2/usermanagement/groups/{orgId}/0
+ */
+func (c *Client) HealthCheck(ctx context.Context) error {
+	_, _, err := c.HTTP.DoRequestContext(ctx, "GET", c.BuildURL(AdobeGroups, "0"), nil, nil)
+	return rerrors.WithProvider(err, "adobe")
+}
+
+// UseCache() enables caching for the next method call.
+func (c *Client) UseCache() *Client {
+	c.Cache.Enabled = true
+	return c
+}
+
+/*
+ * SetCache stores an Adobe API response in the cache
+ */
+func (c *Client) SetCache(key string, value interface{}, duration time.Duration) {
+	// Convert value to a byte slice and cache it
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.Log.Error("Error marshalling cache data:", err)
+		return
+	}
+	c.Cache.Set(key, data, duration)
+}
+
+/*
+ * GetCache retrieves an Adobe API response from the cache
+ */
+func (c *Client) GetCache(key string, target interface{}) bool {
+	data, found := c.Cache.Get(key)
+	if !found || !c.Cache.Enabled {
+		return false
+	}
+
+	err := json.Unmarshal(data, target)
+	if err != nil {
+		c.Log.Error("Error unmarshalling cache data:", err)
+		return false
+	}
+	return true
+}
+
+/*
+ * # Create a new Adobe IMS access token using OAuth Server-to-Server credentials
+ * /ims/token/v3
+ * - https://developer.adobe.com/developer-console/docs/guides/authentication/ServerToServerAuthentication/implementation/
+ */
+func GetToken(imsURL, clientID, clientSecret string) (*Token, error) {
+	url := fmt.Sprintf(IMSAccessToken, imsURL)
+
+	headers := requests.Headers{
+		"Accept":       requests.JSON,
+		"Content-Type": requests.FormURLEncoded,
+	}
+
+	hc := requests.NewClient(nil, headers, nil, requests.WithUserAgent("adobe"))
+	hc.BodyType = requests.FormURLEncoded
+
+	payload := TokenRequest{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		GrantType:    "client_credentials",
+		Scope:        Scopes,
+	}
+
+	_, body, err := hc.DoRequest("POST", url, nil, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &Token{}
+	err = json.Unmarshal(body, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+/*
+  - # Generate Adobe Client
+  - @param verbosity int
+  - @return *Client
+  - Example:
+
+```go
+
+	a := adobe.NewClient(log.DEBUG)
+
+```
+*/
+func NewClient(verbosity int) *Client {
+	log := log.NewLogger("{adobe}", verbosity)
+
+	orgID := config.GetEnv("ADOBE_ORG_ID") // {ORG_ID}@AdobeOrg
+	if len(orgID) == 0 {
+		log.Fatal("ADOBE_ORG_ID is not set")
+	}
+
+	clientID := config.GetEnv("ADOBE_CLIENT_ID")
+	if len(clientID) == 0 {
+		log.Fatal("ADOBE_CLIENT_ID is not set")
+	}
+
+	clientSecret := config.GetEnv("ADOBE_CLIENT_SECRET")
+	if len(clientSecret) == 0 {
+		log.Fatal("ADOBE_CLIENT_SECRET is not set")
+	}
+
+	imsURL := config.GetEnv("ADOBE_IMS_URL")
+	if len(imsURL) == 0 {
+		imsURL = IMSURL
+	}
+	imsURL = strings.TrimSuffix(imsURL, "/")
+
+	token, err := GetToken(imsURL, clientID, clientSecret)
+	if err != nil {
+		log.Fatal(fmt.Sprintf("Failed to generate Adobe IMS token: %v", err))
+	}
+
+	headers := requests.Headers{
+		"Authorization": "Bearer " + token.AccessToken,
+		"X-Api-Key":     clientID,
+		"Accept":        requests.JSON,
+		"Content-Type":  requests.JSON,
+	}
+
+	encryptionKey := []byte(config.GetEnv("REGO_ENCRYPTION_KEY"))
+	if len(encryptionKey) == 0 {
+		log.Fatal("REGO_ENCRYPTION_KEY is not set")
+	}
+
+	cache, err := cache.NewCache(encryptionKey, "rego_cache_adobe.gob", 1000000)
+	if err != nil {
+		panic(err)
+	}
+
+	// https://adobe-apiplatform.github.io/umapi-documentation/en/api/getUsersWithPage.html#throttling-limits
+	rl := ratelimit.NewRateLimiter(25, 1*time.Minute)
+	rl.Log.Verbosity = verbosity
+
+	httpClient := requests.NewClient(nil, headers, rl, requests.WithUserAgent("adobe"))
+	httpClient.BodyType = requests.JSON
+
+	return &Client{
+		BaseURL: BaseURL,
+		OrgID:   orgID,
+		HTTP:    httpClient,
+		Log:     log,
+		Cache:   cache,
+	}
+}
+
+/*
+ * Perform a generic request to the Adobe User Management API
+ */
+func do[T any](c *Client, method string, url string, query interface{}, data interface{}) (T, error) {
+	var result T
+	res, body, err := c.HTTP.DoRequest(method, url, query, data)
+	if err != nil {
+		return *new(T), rerrors.WithProvider(err, "adobe")
+	}
+
+	c.Log.Println("Response Status:", res.Status)
+	c.Log.Debug("Response Body:", string(body))
+
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return *new(T), fmt.Errorf("unmarshalling error: %w", err)
+	}
+
+	return result, nil
+}
+
+/*
+ * Generically perform a paginated request to the Adobe User Management API
+ * - Adobe pages are addressed by a zero-based page number in the path, with `lastPage` on each response
+ */
+func doPaginated[T PaginatedResponse[E], E any](c *Client, method string, endpoint string, suffix ...string) (*[]*E, error) {
+	results := make([]*E, 0)
+
+	for page := 0; ; page++ {
+		identifiers := append([]string{strconv.Itoa(page)}, suffix...)
+		url := c.BuildURL(endpoint, identifiers...)
+
+		p, err := do[T](c, method, url, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, p.Elements()...)
+
+		if p.IsLastPage() {
+			break
+		}
+	}
+
+	return &results, nil
+}
@@ -0,0 +1,156 @@
+// Code generated by mockgen from interfaces.go; DO NOT EDIT.
+
+// pkg/adobe/adobemock/mocks.go
+package adobemock
+
+import (
+	"sync"
+
+	"github.com/gemini-oss/rego/pkg/adobe"
+)
+
+// Call is a call received by a mock
+type Call struct {
+	Method string        // Name of the method called
+	Args   []interface{} // Arguments of the call
+}
+
+// calls records the calls received by a mock
+type calls struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+func (c *calls) record(method string, args ...interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls received, in order; only those of `method` when set
+func (c *calls) Calls(method ...string) []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	received := []Call{}
+	for _, call := range c.calls {
+		if len(method) == 0 || call.Method == method[0] {
+			received = append(received, call)
+		}
+	}
+	return received
+}
+
+// GroupService is a mock of `adobe.GroupService`; methods without a func return zero values
+type GroupService struct {
+	ListAllGroupsFunc       func() (*adobe.Groups, error)
+	ListProductProfilesFunc func() (*adobe.Groups, error)
+	ListMembersFunc         func(string) (*adobe.Users, error)
+	LicenseReportFunc       func() (*adobe.LicenseReport, error)
+
+	calls
+}
+
+var _ adobe.GroupService = (*GroupService)(nil)
+
+func (m *GroupService) ListAllGroups() (r0 *adobe.Groups, r1 error) {
+	m.record("ListAllGroups")
+	if m.ListAllGroupsFunc != nil {
+		return m.ListAllGroupsFunc()
+	}
+	return
+}
+
+func (m *GroupService) ListProductProfiles() (r0 *adobe.Groups, r1 error) {
+	m.record("ListProductProfiles")
+	if m.ListProductProfilesFunc != nil {
+		return m.ListProductProfilesFunc()
+	}
+	return
+}
+
+func (m *GroupService) ListMembers(a0 string) (r0 *adobe.Users, r1 error) {
+	m.record("ListMembers", a0)
+	if m.ListMembersFunc != nil {
+		return m.ListMembersFunc(a0)
+	}
+	return
+}
+
+func (m *GroupService) LicenseReport() (r0 *adobe.LicenseReport, r1 error) {
+	m.record("LicenseReport")
+	if m.LicenseReportFunc != nil {
+		return m.LicenseReportFunc()
+	}
+	return
+}
+
+// UserService is a mock of `adobe.UserService`; methods without a func return zero values
+type UserService struct {
+	ListAllUsersFunc     func() (*adobe.Users, error)
+	GetUserFunc          func(string) (*adobe.User, error)
+	ActionFunc           func(adobe.Actions) (*adobe.ActionResult, error)
+	AddToGroupsFunc      func(string, ...string) (*adobe.ActionResult, error)
+	RemoveFromGroupsFunc func(string, ...string) (*adobe.ActionResult, error)
+	RemoveFromOrgFunc    func(string, bool) (*adobe.ActionResult, error)
+	OffboardUserFunc     func(string) error
+
+	calls
+}
+
+var _ adobe.UserService = (*UserService)(nil)
+
+func (m *UserService) ListAllUsers() (r0 *adobe.Users, r1 error) {
+	m.record("ListAllUsers")
+	if m.ListAllUsersFunc != nil {
+		return m.ListAllUsersFunc()
+	}
+	return
+}
+
+func (m *UserService) GetUser(a0 string) (r0 *adobe.User, r1 error) {
+	m.record("GetUser", a0)
+	if m.GetUserFunc != nil {
+		return m.GetUserFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) Action(a0 adobe.Actions) (r0 *adobe.ActionResult, r1 error) {
+	m.record("Action", a0)
+	if m.ActionFunc != nil {
+		return m.ActionFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) AddToGroups(a0 string, a1 ...string) (r0 *adobe.ActionResult, r1 error) {
+	m.record("AddToGroups", a0, a1)
+	if m.AddToGroupsFunc != nil {
+		return m.AddToGroupsFunc(a0, a1...)
+	}
+	return
+}
+
+func (m *UserService) RemoveFromGroups(a0 string, a1 ...string) (r0 *adobe.ActionResult, r1 error) {
+	m.record("RemoveFromGroups", a0, a1)
+	if m.RemoveFromGroupsFunc != nil {
+		return m.RemoveFromGroupsFunc(a0, a1...)
+	}
+	return
+}
+
+func (m *UserService) RemoveFromOrg(a0 string, a1 bool) (r0 *adobe.ActionResult, r1 error) {
+	m.record("RemoveFromOrg", a0, a1)
+	if m.RemoveFromOrgFunc != nil {
+		return m.RemoveFromOrgFunc(a0, a1)
+	}
+	return
+}
+
+func (m *UserService) OffboardUser(a0 string) (r0 error) {
+	m.record("OffboardUser", a0)
+	if m.OffboardUserFunc != nil {
+		return m.OffboardUserFunc(a0)
+	}
+	return
+}
@@ -0,0 +1,203 @@
+/*
+# Adobe - Entities [Structs]
+
+This package contains many structs for handling responses from the Adobe User Management API:
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/adobe/entities.go
+package adobe
+
+import (
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/normalize"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+// ### Adobe Client Entities
+// ---------------------------------------------------------------------
+type Client struct {
+	BaseURL string           // BaseURL is the base URL for Adobe User Management API requests.
+	OrgID   string           // OrgID is the Adobe organization ID, e.g. `12345@AdobeOrg`.
+	HTTP    *requests.Client // HTTP is the client used to make HTTP requests.
+	Log     *log.Logger      // Log is the logger used to log messages.
+	Cache   *cache.Cache     // Cache is the cache used to store responses from the Adobe API.
+}
+
+type TokenRequest struct {
+	ClientID     string `url:"client_id"`     // The client ID of the OAuth Server-to-Server credential.
+	ClientSecret string `url:"client_secret"` // The client secret of the OAuth Server-to-Server credential.
+	GrantType    string `url:"grant_type"`    // Always `client_credentials`.
+	Scope        string `url:"scope"`         // Comma-separated list of scopes.
+}
+
+type Token struct {
+	AccessToken string `json:"access_token,omitempty"` // The IMS access token.
+	ExpiresIn   int    `json:"expires_in,omitempty"`   // Lifetime of the token in seconds.
+	TokenType   string `json:"token_type,omitempty"`   // Always `bearer`.
+}
+
+// PaginatedResponse is an interface for Adobe API responses involving pagination
+type PaginatedResponse[E any] interface {
+	Elements() []*E
+	IsLastPage() bool
+}
+
+// Page contains the pagination properties included with every Adobe list response.
+type Page struct {
+	LastPage bool   `json:"lastPage,omitempty"` // True if this is the last page of results.
+	Result   string `json:"result,omitempty"`   // The result of the request {success, error.*}
+	Message  string `json:"message,omitempty"`  // A message describing an error, if any.
+}
+
+func (p Page) IsLastPage() bool {
+	return p.LastPage
+}
+
+// END OF ADOBE CLIENT ENTITIES
+//---------------------------------------------------------------------
+
+// ### Adobe User Structs
+// ---------------------------------------------------------------------
+type UserList struct {
+	Users []*User `json:"users,omitempty"` // The users in this page of results.
+	Page
+}
+
+func (u UserList) Elements() []*User {
+	return u.Users
+}
+
+type UserResponse struct {
+	User   *User  `json:"user,omitempty"`   // The requested user.
+	Result string `json:"result,omitempty"` // The result of the request.
+}
+
+type Users []*User
+
+type User struct {
+	AdminRoles []string `json:"adminRoles,omitempty"` // The admin roles held by the user {org, deployment, support, productAdmin, userGroupAdmin}
+	Country    string   `json:"country,omitempty"`    // The user's country code.
+	Domain     string   `json:"domain,omitempty"`     // The domain of the user's email address.
+	Email      string   `json:"email,omitempty"`      // The user's email address.
+	FirstName  string   `json:"firstname,omitempty"`  // The user's first name.
+	Groups     []string `json:"groups,omitempty"`     // The product profiles and user groups the user belongs to.
+	ID         string   `json:"id,omitempty"`         // The user's ID.
+	LastName   string   `json:"lastname,omitempty"`   // The user's last name.
+	Status     string   `json:"status,omitempty"`     // The status of the user {active, disabled, locked, removed}
+	Type       string   `json:"type,omitempty"`       // The identity type of the user {adobeID, enterpriseID, federatedID}
+	Username   string   `json:"username,omitempty"`   // The user's username.
+}
+
+// AccountStatus returns the normalized status of the user
+func (u *User) AccountStatus() normalize.Status {
+	switch u.Status {
+	case "active":
+		return normalize.StatusActive
+	case "locked":
+		return normalize.StatusLocked
+	case "disabled":
+		return normalize.StatusSuspended
+	case "removed":
+		return normalize.StatusDeprovisioned
+	}
+	return normalize.StatusUnknown
+}
+
+// END OF ADOBE USER STRUCTS
+//---------------------------------------------------------------------
+
+// ### Adobe Group Structs
+// ---------------------------------------------------------------------
+type GroupList struct {
+	Groups []*Group `json:"groups,omitempty"` // The groups in this page of results.
+	Page
+}
+
+func (g GroupList) Elements() []*Group {
+	return g.Groups
+}
+
+type Groups []*Group
+
+type Group struct {
+	AdminGroupName        string `json:"adminGroupName,omitempty"`        // The name of the admin group for the product profile.
+	GroupID               int    `json:"groupId,omitempty"`               // The ID of the group.
+	GroupName             string `json:"groupName,omitempty"`             // The name of the group.
+	LicenseQuota          string `json:"licenseQuota,omitempty"`          // The license quota of the product profile, or `UNLIMITED`.
+	MemberCount           int    `json:"memberCount,omitempty"`           // The number of members in the group.
+	ProductName           string `json:"productName,omitempty"`           // The name of the product the profile belongs to.
+	ProductProfileName    string `json:"productProfileName,omitempty"`    // The name of the product profile.
+	Type                  string `json:"type,omitempty"`                  // The type of the group {PRODUCT_PROFILE, USER_GROUP, SYSADMIN_GROUP, ...}
+	UserGroupAdminGroupID string `json:"userGroupAdminGroupId,omitempty"` // The ID of the admin group for the user group.
+}
+
+// END OF ADOBE GROUP STRUCTS
+//---------------------------------------------------------------------
+
+// ### Adobe Action Structs
+// ---------------------------------------------------------------------
+type Actions []*Action
+
+// Action is a single command targeting a user in the organization.
+type Action struct {
+	User      string    `json:"user"`                // The email or username of the user to act on.
+	RequestID string    `json:"requestID,omitempty"` // An optional caller-supplied ID echoed back in errors.
+	Do        []Command `json:"do"`                  // The commands to execute for the user.
+}
+
+// Command is one step of an Action. Only one field should be set per command.
+type Command struct {
+	Add           *GroupCommand  `json:"add,omitempty"`           // Add the user to product profiles/user groups.
+	Remove        *GroupCommand  `json:"remove,omitempty"`        // Remove the user from product profiles/user groups.
+	RemoveFromOrg *RemoveCommand `json:"removeFromOrg,omitempty"` // Remove the user from the organization.
+}
+
+type GroupCommand struct {
+	Group []string `json:"group,omitempty"` // The names of the product profiles or user groups.
+}
+
+type RemoveCommand struct {
+	DeleteAccount bool `json:"deleteAccount"` // Delete the account as well (Enterprise/Federated IDs only).
+}
+
+type ActionResult struct {
+	Completed           int            `json:"completed"`                     // The number of actions that completed.
+	CompletedInTestMode int            `json:"completedInTestMode,omitempty"` // The number of actions that completed in test mode.
+	NotCompleted        int            `json:"notCompleted"`                  // The number of actions that did not complete.
+	Result              string         `json:"result,omitempty"`              // The overall result {success, partial, error}
+	Errors              []*ActionError `json:"errors,omitempty"`              // Errors for actions that did not complete.
+	Warnings            []*ActionError `json:"warnings,omitempty"`            // Warnings for actions that completed.
+}
+
+type ActionError struct {
+	Index     int    `json:"index,omitempty"`     // The index of the action in the request.
+	Step      int    `json:"step,omitempty"`      // The index of the command within the action.
+	RequestID string `json:"requestID,omitempty"` // The caller-supplied ID of the action.
+	Message   string `json:"message,omitempty"`   // A description of the error.
+	User      string `json:"user,omitempty"`      // The user the action targeted.
+	ErrorCode string `json:"errorCode,omitempty"` // The error code.
+}
+
+// END OF ADOBE ACTION STRUCTS
+//---------------------------------------------------------------------
+
+// ### Adobe License Structs
+// ---------------------------------------------------------------------
+type LicenseReport []*LicenseUsage
+
+// LicenseUsage summarizes seat consumption for a single product profile.
+type LicenseUsage struct {
+	Product        string // The product the profile belongs to.
+	ProductProfile string // The name of the product profile.
+	Quota          int    // The license quota; -1 when unlimited.
+	Assigned       int    // The number of members assigned to the profile.
+	Available      int    // Quota minus Assigned; -1 when unlimited.
+}
+
+// END OF ADOBE LICENSE STRUCTS
+//---------------------------------------------------------------------
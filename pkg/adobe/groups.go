@@ -0,0 +1,133 @@
+/*
+# Adobe - Groups
+
+This package contains all the methods to interact with the Adobe User Management API for product profiles and user groups:
+https://adobe-apiplatform.github.io/umapi-documentation/en/api/group.html
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/adobe/groups.go
+package adobe
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GroupClient for chaining methods
+type GroupClient struct {
+	*Client
+}
+
+// Entry point for group-related operations
+func (c *Client) Groups() *GroupClient {
+	return &GroupClient{
+		Client: c,
+	}
+}
+
+/*
+ * # List all product profiles and user groups in the organization
+ * /v2/usermanagement/groups/{orgId}/{page}
+ * - https://adobe-apiplatform.github.io/umapi-documentation/en/api/getUserGroups.html
+ */
+func (c *GroupClient) ListAllGroups() (*Groups, error) {
+	url := c.BuildURL(AdobeGroups)
+
+	var cache Groups
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	groups, err := doPaginated[GroupList](c.Client, "GET", AdobeGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	result := Groups(*groups)
+	c.SetCache(url, result, 30*time.Minute)
+	return &result, nil
+}
+
+/*
+ * # List all product profiles in the organization
+ * /v2/usermanagement/groups/{orgId}/{page}
+ * - https://adobe-apiplatform.github.io/umapi-documentation/en/api/getUserGroups.html
+ */
+func (c *GroupClient) ListProductProfiles() (*Groups, error) {
+	groups, err := c.ListAllGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := Groups{}
+	for _, g := range *groups {
+		if g.Type == "PRODUCT_PROFILE" {
+			profiles = append(profiles, g)
+		}
+	}
+
+	return &profiles, nil
+}
+
+/*
+ * # List all members of a product profile or user group
+ * /v2/usermanagement/users/{orgId}/{page}/{groupName}
+ * - https://adobe-apiplatform.github.io/umapi-documentation/en/api/getUsersByGroup.html
+ */
+func (c *GroupClient) ListMembers(groupName string) (*Users, error) {
+	groupName = url.PathEscape(groupName) // Group names may contain spaces
+	url := c.BuildURL(AdobeUsers, groupName)
+
+	var cache Users
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	users, err := doPaginated[UserList](c.Client, "GET", AdobeUsers, groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := Users(*users)
+	c.SetCache(url, result, 30*time.Minute)
+	return &result, nil
+}
+
+/*
+ * # Generate a license usage report
+ * - Compares the license quota of each product profile against the number of assigned members
+ */
+func (c *GroupClient) LicenseReport() (*LicenseReport, error) {
+	profiles, err := c.ListProductProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	report := LicenseReport{}
+	for _, p := range *profiles {
+		usage := &LicenseUsage{
+			Product:        p.ProductName,
+			ProductProfile: p.GroupName,
+			Quota:          -1,
+			Assigned:       p.MemberCount,
+			Available:      -1,
+		}
+
+		if quota, err := strconv.Atoi(p.LicenseQuota); err == nil {
+			usage.Quota = quota
+			usage.Available = quota - p.MemberCount
+		} else if !strings.EqualFold(p.LicenseQuota, "UNLIMITED") && p.LicenseQuota != "" {
+			c.Log.Warning("unrecognized license quota for", p.GroupName, ":", p.LicenseQuota)
+		}
+
+		report = append(report, usage)
+	}
+
+	return &report, nil
+}
@@ -0,0 +1,39 @@
+/*
+# Adobe Interfaces
+
+This package contains the interfaces of each Adobe resource, which the resource clients satisfy. Code depending on an
+interface rather than the clients can be unit tested with the mocks of `pkg/adobe/adobemock`
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/adobe/interfaces.go
+package adobe
+
+//go:generate go run ../../cmd/mockgen
+
+// GroupService reads user groups and product profiles
+type GroupService interface {
+	ListAllGroups() (*Groups, error)
+	ListProductProfiles() (*Groups, error)
+	ListMembers(groupName string) (*Users, error)
+	LicenseReport() (*LicenseReport, error)
+}
+
+// UserService manages users and their memberships
+type UserService interface {
+	ListAllUsers() (*Users, error)
+	GetUser(email string) (*User, error)
+	Action(actions Actions) (*ActionResult, error)
+	AddToGroups(email string, groups ...string) (*ActionResult, error)
+	RemoveFromGroups(email string, groups ...string) (*ActionResult, error)
+	RemoveFromOrg(email string, deleteAccount bool) (*ActionResult, error)
+	OffboardUser(email string) error
+}
+
+var (
+	_ GroupService = (*GroupClient)(nil)
+	_ UserService  = (*UserClient)(nil)
+)
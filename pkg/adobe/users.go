@@ -0,0 +1,160 @@
+/*
+# Adobe - Users
+
+This package contains all the methods to interact with the Adobe User Management API for users:
+https://adobe-apiplatform.github.io/umapi-documentation/en/api/user.html
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/adobe/users.go
+package adobe
+
+import (
+	"fmt"
+	"time"
+)
+
+// UserClient for chaining methods
+type UserClient struct {
+	*Client
+}
+
+// Entry point for user-related operations
+func (c *Client) Users() *UserClient {
+	return &UserClient{
+		Client: c,
+	}
+}
+
+/*
+ * # List all users in the organization
+ * /v2/usermanagement/users/{orgId}/{page}
+ * - https://adobe-apiplatform.github.io/umapi-documentation/en/api/getUsersWithPage.html
+ */
+func (c *UserClient) ListAllUsers() (*Users, error) {
+	url := c.BuildURL(AdobeUsers)
+
+	var cache Users
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	users, err := doPaginated[UserList](c.Client, "GET", AdobeUsers)
+	if err != nil {
+		return nil, err
+	}
+
+	result := Users(*users)
+	c.SetCache(url, result, 30*time.Minute)
+	return &result, nil
+}
+
+/*
+ * # Get a user by email address
+ * /v2/usermanagement/organizations/{orgId}/users/{userString}
+ * - https://adobe-apiplatform.github.io/umapi-documentation/en/api/getUser.html
+ */
+func (c *UserClient) GetUser(email string) (*User, error) {
+	url := c.BuildURL(AdobeOrgUsers, email)
+
+	var cache User
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	res, err := do[UserResponse](c.Client, "GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if res.User == nil {
+		return nil, fmt.Errorf("user %s not found", email)
+	}
+
+	c.SetCache(url, res.User, 5*time.Minute)
+	return res.User, nil
+}
+
+/*
+ * # Execute a batch of actions against users in the organization
+ * - Up to 10 users may be targeted per request
+ * /v2/usermanagement/action/{orgId}
+ * - https://adobe-apiplatform.github.io/umapi-documentation/en/api/ActionsCmds.html
+ */
+func (c *UserClient) Action(actions Actions) (*ActionResult, error) {
+	url := c.BuildURL(AdobeAction)
+
+	result, err := do[ActionResult](c.Client, "POST", url, nil, actions)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.NotCompleted > 0 {
+		for _, e := range result.Errors {
+			c.Log.Error(fmt.Sprintf("action failed for %s: [%s] %s", e.User, e.ErrorCode, e.Message))
+		}
+		return &result, fmt.Errorf("%d of %d action(s) not completed", result.NotCompleted, len(actions))
+	}
+
+	return &result, nil
+}
+
+/*
+ * # Add a user to one or more product profiles or user groups
+ * /v2/usermanagement/action/{orgId}
+ * - https://adobe-apiplatform.github.io/umapi-documentation/en/api/ActionsCmds.html#addRemoveAttr
+ */
+func (c *UserClient) AddToGroups(email string, groups ...string) (*ActionResult, error) {
+	c.Log.Printf("Adding %s to %v", email, groups)
+	return c.Action(Actions{
+		{User: email, Do: []Command{{Add: &GroupCommand{Group: groups}}}},
+	})
+}
+
+/*
+ * # Remove a user from one or more product profiles or user groups
+ * /v2/usermanagement/action/{orgId}
+ * - https://adobe-apiplatform.github.io/umapi-documentation/en/api/ActionsCmds.html#addRemoveAttr
+ */
+func (c *UserClient) RemoveFromGroups(email string, groups ...string) (*ActionResult, error) {
+	c.Log.Printf("Removing %s from %v", email, groups)
+	return c.Action(Actions{
+		{User: email, Do: []Command{{Remove: &GroupCommand{Group: groups}}}},
+	})
+}
+
+/*
+ * # Remove a user from the organization
+ * - `deleteAccount` additionally deletes Enterprise and Federated ID accounts
+ * /v2/usermanagement/action/{orgId}
+ * - https://adobe-apiplatform.github.io/umapi-documentation/en/api/ActionsCmds.html#removeFromOrg
+ */
+func (c *UserClient) RemoveFromOrg(email string, deleteAccount bool) (*ActionResult, error) {
+	c.Log.Printf("Removing %s from the organization", email)
+	return c.Action(Actions{
+		{User: email, Do: []Command{{RemoveFromOrg: &RemoveCommand{DeleteAccount: deleteAccount}}}},
+	})
+}
+
+/*
+ * # Offboard a user by email
+ * - Removes the user from every product profile they are assigned to (reclaiming their seats) and then from the organization
+ */
+func (c *UserClient) OffboardUser(email string) error {
+	user, err := c.GetUser(email)
+	if err != nil {
+		return err
+	}
+
+	commands := []Command{}
+	if len(user.Groups) > 0 {
+		commands = append(commands, Command{Remove: &GroupCommand{Group: user.Groups}})
+	}
+	commands = append(commands, Command{RemoveFromOrg: &RemoveCommand{DeleteAccount: false}})
+
+	c.Log.Printf("Offboarding %s from %d group(s)", email, len(user.Groups))
+	_, err = c.Action(Actions{{User: email, Do: commands}})
+	return err
+}
@@ -0,0 +1,325 @@
+/*
+# API
+
+This package serves ReGo operations (listing users, running offboarding, fetching reports and drift plans) over an
+authenticated JSON HTTP API, so that systems not written in Go (ticketing, chatbots, ...) can trigger workflows:
+
+	s := api.NewServer(":8443", orchestrator, log.INFO)
+	s.AddToken("service-desk", os.Getenv("SERVICE_DESK_TOKEN"), api.ScopeRead, api.ScopeOffboard)
+	s.ListenAndServeTLS("/etc/rego/tls.crt", "/etc/rego/tls.key")
+
+	curl -H "Authorization: Bearer $TOKEN" https://rego.example.com/v1/okta/users?status=ACTIVE
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/api/api.go
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/orchestrators"
+)
+
+// Maximum size of a request body
+const MaxBodySize = 1 << 20 // 1 MiB
+
+// Scope grants a token access to a group of endpoints
+type Scope string
+
+const (
+	ScopeRead     Scope = "read"     // List and get users
+	ScopeReports  Scope = "reports"  // Generate reports and drift plans
+	ScopeOffboard Scope = "offboard" // Run offboarding workflows
+)
+
+// Token authenticates a caller of the API
+type Token struct {
+	Name   string   // Name of the caller, recorded in the audit log
+	Scopes []Scope  // Endpoints the caller may use
+	digest [32]byte // SHA-256 of the secret, so secrets are compared in constant time regardless of length
+}
+
+// Server serves the API
+type Server struct {
+	Addr         string                // Address to listen on, e.g. `:8443`
+	Log          *log.Logger           // Logger for the server, including the audit log of every request
+	Orchestrator *orchestrators.Client // Clients of the services exposed by the API; services without a client respond 501
+	TLSConfig    *tls.Config           // Optional TLS configuration used by `ListenAndServeTLS`, e.g. to require client certificates
+	mux          *http.ServeMux
+	tokens       []*Token
+	offboarding  sync.Mutex // Offboarding runs one user at a time, so concurrent requests for a user don't interleave their steps
+	serving      sync.Mutex // Guards `server` and `closed` between `ListenAndServe` and `Shutdown`
+	server       *http.Server
+	closed       bool
+}
+
+/*
+ * # Generate an API Server
+ * - Tokens must be added (see `AddToken` and `ParseTokens`) before the server will start
+ */
+func NewServer(addr string, c *orchestrators.Client, verbosity int) *Server {
+	s := &Server{
+		Addr:         addr,
+		Log:          log.NewLogger("{api}", verbosity),
+		Orchestrator: c,
+		mux:          http.NewServeMux(),
+	}
+	s.routes()
+	return s
+}
+
+// AddToken authorizes a bearer token for the given scopes
+func (s *Server) AddToken(name, secret string, scopes ...Scope) error {
+	if name == "" || secret == "" {
+		return fmt.Errorf("a token requires a name and a secret")
+	}
+	if len(secret) < 32 {
+		return fmt.Errorf("token %s: secret must be at least 32 characters", name)
+	}
+	s.tokens = append(s.tokens, &Token{Name: name, Scopes: scopes, digest: sha256.Sum256([]byte(secret))})
+	return nil
+}
+
+/*
+ * # Parse Tokens
+ * Parses tokens from a specification such as `$REGO_API_TOKENS`: `name:secret:scope,scope;name:secret:scope`
+ * - The name ends at the first `:` and the scopes start after the last, so a secret may contain `:` (but not `;`)
+ */
+func (s *Server) ParseTokens(spec string) error {
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, rest, _ := strings.Cut(entry, ":")
+		i := strings.LastIndex(rest, ":")
+		if i < 0 {
+			return fmt.Errorf("invalid token %q: expected name:secret:scopes", name)
+		}
+		secret, list := rest[:i], rest[i+1:]
+
+		scopes := []Scope{}
+		for _, scope := range strings.Split(list, ",") {
+			switch scope := Scope(strings.TrimSpace(scope)); scope {
+			case ScopeRead, ScopeReports, ScopeOffboard:
+				scopes = append(scopes, scope)
+			default:
+				return fmt.Errorf("token %s: unknown scope %q", name, scope)
+			}
+		}
+		if err := s.AddToken(name, secret, scopes...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeHTTP serves the API routes
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe is `ListenAndServeTLS` over plain HTTP, for a server behind a TLS-terminating proxy
+func (s *Server) ListenAndServe() error {
+	return s.serve("HTTP", func(server *http.Server) error {
+		return server.ListenAndServe()
+	})
+}
+
+/*
+ * # Listen and Serve TLS
+ * Starts serving the API over HTTPS on `Addr`, blocking until the server is shut down; it returns at once after `Shutdown`
+ * - `certFile` and `keyFile` may be empty if `TLSConfig` provides the certificates
+ */
+func (s *Server) ListenAndServeTLS(certFile, keyFile string) error {
+	return s.serve("HTTPS", func(server *http.Server) error {
+		return server.ListenAndServeTLS(certFile, keyFile)
+	})
+}
+
+// serve runs the server with `listen` until it is shut down
+func (s *Server) serve(scheme string, listen func(server *http.Server) error) error {
+	if len(s.tokens) == 0 {
+		return fmt.Errorf("no API tokens configured")
+	}
+
+	s.serving.Lock()
+	if s.closed {
+		s.serving.Unlock()
+		return nil
+	}
+	server := &http.Server{
+		Addr:              s.Addr,
+		Handler:           s,
+		TLSConfig:         s.TLSConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	s.server = server
+	s.serving.Unlock()
+
+	s.Log.Printf("Serving the API over %s on %s", scheme, s.Addr)
+	if err := listen(server); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Shutdown gracefully stops the server, waiting for running requests (including workflows) to finish
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.serving.Lock()
+	s.closed = true
+	server := s.server
+	s.serving.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
+
+// authenticate returns the token of a request, or nil
+func (s *Server) authenticate(r *http.Request) *Token {
+	secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || secret == "" {
+		return nil
+	}
+
+	digest := sha256.Sum256([]byte(secret))
+	var found *Token
+	for _, token := range s.tokens {
+		if subtle.ConstantTimeCompare(digest[:], token.digest[:]) == 1 {
+			found = token
+		}
+	}
+	return found
+}
+
+// handle registers an endpoint which requires a token with `scope`, and records each request in the audit log
+func (s *Server) handle(pattern string, scope Scope, handler func(w http.ResponseWriter, r *http.Request) error) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		token := s.authenticate(r)
+		switch {
+		case token == nil:
+			s.Log.Warningf("Rejected %s %s from %s: missing or invalid token", r.Method, r.URL.Path, r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="rego"`)
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid token"))
+			return
+		case !slices.Contains(token.Scopes, scope):
+			s.Log.Warningf("Rejected %s %s from %s: token %s lacks the %s scope", r.Method, r.URL.Path, r.RemoteAddr, token.Name, scope)
+			writeError(w, http.StatusForbidden, fmt.Errorf("token lacks the %s scope", scope))
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, MaxBodySize)
+		r = r.WithContext(context.WithValue(r.Context(), tokenKey{}, token))
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		if err := handler(rec, r); err != nil {
+			status := statusOf(err)
+			if rec.written {
+				s.Log.Errorf("%s %s failed after responding: %v", r.Method, r.URL.Path, err)
+			} else {
+				writeError(rec, status, err)
+			}
+		}
+
+		s.Log.Printf("%s %s %d by %s in %s", r.Method, r.URL.Path, rec.status, token.Name, time.Since(start).Round(time.Millisecond))
+	})
+}
+
+type tokenKey struct{}
+
+// TokenFrom returns the token which authenticated a request
+func TokenFrom(r *http.Request) *Token {
+	token, _ := r.Context().Value(tokenKey{}).(*Token)
+	return token
+}
+
+// Error is an error with the HTTP status it is served with
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// statusOf returns the status an error is served with, passing provider errors through by their class
+func statusOf(err error) int {
+	var e *Error
+	switch {
+	case errors.As(err, &e):
+		return e.Status
+	case errors.Is(err, rerrors.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, rerrors.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, rerrors.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, rerrors.ErrUnavailable):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func errorf(status int, format string, v ...interface{}) error {
+	return &Error{Status: status, Message: fmt.Sprintf(format, v...)}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// decode reads a JSON request body, rejecting unknown fields
+func decode(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return errorf(http.StatusBadRequest, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+// statusRecorder records the status of a response for the audit log
+type statusRecorder struct {
+	http.ResponseWriter
+	status  int
+	written bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status, r.written = status, true
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.written = true
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,259 @@
+// pkg/api/handlers.go
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gemini-oss/rego/pkg/common/enum"
+	"github.com/gemini-oss/rego/pkg/drift"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/orchestrators"
+	"github.com/gemini-oss/rego/pkg/reports"
+)
+
+// ### API Structs
+// ---------------------------------------------------------------------
+
+// OffboardRequest is the body of `POST /v1/offboard`
+type OffboardRequest struct {
+	Email       string   `json:"email"`                 // Email of the user to offboard
+	TransferTo  string   `json:"transferTo,omitempty"`  // Email of the user who receives the Drive/Calendar data
+	SuspendedOU string   `json:"suspendedOU,omitempty"` // Organizational unit to move the Google user into
+	Skip        []string `json:"skip,omitempty"`        // Names of steps to skip, e.g. `jamf.lock`
+	StepsOnly   bool     `json:"stepsOnly,omitempty"`   // List the steps without running them
+	StopOnError bool     `json:"stopOnError,omitempty"` // Stop at the first failed step
+}
+
+// WorkflowResponse is the outcome of a workflow
+type WorkflowResponse struct {
+	Succeeded bool                  `json:"succeeded"` // Whether every step succeeded (or was skipped)
+	Failed    int                   `json:"failed"`    // Number of failed steps
+	Report    *orchestrators.Report `json:"report"`    // Result of each step
+}
+
+// END OF API STRUCTS
+//---------------------------------------------------------------------
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /v1/health", s.health)
+
+	s.handle("GET /v1/okta/users", ScopeRead, s.listOktaUsers)
+	s.handle("GET /v1/okta/users/{id}", ScopeRead, s.getOktaUser)
+	s.handle("GET /v1/google/users", ScopeRead, s.listGoogleUsers)
+	s.handle("GET /v1/google/users/{key}", ScopeRead, s.getGoogleUser)
+
+	s.handle("POST /v1/offboard", ScopeOffboard, s.offboard)
+
+	s.handle("GET /v1/reports/access-review", ScopeReports, s.accessReview)
+	s.handle("POST /v1/drift/plan", ScopeReports, s.driftPlan)
+}
+
+/*
+ * # Health
+ * GET /v1/health
+ * - Unauthenticated; lists the configured services, for load balancers and monitoring
+ */
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	services := []string{}
+	c := s.Orchestrator
+	for name, configured := range map[string]bool{
+		"active_directory": c.ActiveDirectory != nil,
+		"backupify":        c.Backupify != nil,
+		"docusign":         c.DocuSign != nil,
+		"google":           c.Google != nil,
+		"jamf":             c.Jamf != nil,
+		"okta":             c.Okta != nil,
+		"slack":            c.Slack != nil,
+		"snipeit":          c.SnipeIT != nil,
+	} {
+		if configured {
+			services = append(services, name)
+		}
+	}
+	sort.Strings(services)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "services": services})
+}
+
+/*
+ * # List Okta Users
+ * GET /v1/okta/users?status=ACTIVE
+ */
+func (s *Server) listOktaUsers(w http.ResponseWriter, r *http.Request) error {
+	if s.Orchestrator.Okta == nil {
+		return errorf(http.StatusNotImplemented, "okta is not configured")
+	}
+	status := okta.Status(strings.ToUpper(r.URL.Query().Get("status")))
+	if err := enum.Check(status, okta.Statuses...); err != nil {
+		return errorf(http.StatusBadRequest, "%v", err)
+	}
+	var users *okta.Users
+	var err error
+	if status == okta.ACTIVE {
+		users, err = s.Orchestrator.Okta.WithContext(r.Context()).ListActiveUsers()
+	} else {
+		users, err = s.Orchestrator.Okta.WithContext(r.Context()).ListAllUsers()
+	}
+	if err != nil {
+		return err
+	}
+
+	if status != "" && status != okta.ACTIVE {
+		filtered := okta.Users{}
+		for _, user := range *users {
+			if user.Status == status {
+				filtered = append(filtered, user)
+			}
+		}
+		users = &filtered
+	}
+	return writeJSON(w, http.StatusOK, users)
+}
+
+/*
+ * # Get an Okta User
+ * GET /v1/okta/users/{id}
+ * - `id` is a user ID or login
+ */
+func (s *Server) getOktaUser(w http.ResponseWriter, r *http.Request) error {
+	if s.Orchestrator.Okta == nil {
+		return errorf(http.StatusNotImplemented, "okta is not configured")
+	}
+	user, err := s.Orchestrator.Okta.WithContext(r.Context()).GetUser(r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	if user.ID == "" {
+		return errorf(http.StatusNotFound, "user %s not found", r.PathValue("id"))
+	}
+	return writeJSON(w, http.StatusOK, user)
+}
+
+/*
+ * # List Google Users
+ * GET /v1/google/users
+ */
+func (s *Server) listGoogleUsers(w http.ResponseWriter, r *http.Request) error {
+	if s.Orchestrator.Google == nil {
+		return errorf(http.StatusNotImplemented, "google is not configured")
+	}
+	users, err := s.Orchestrator.Google.WithContext(r.Context()).Users().ListAllUsers()
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, users.Users)
+}
+
+/*
+ * # Get a Google User
+ * GET /v1/google/users/{key}
+ * - `key` is a user ID or primary email
+ */
+func (s *Server) getGoogleUser(w http.ResponseWriter, r *http.Request) error {
+	if s.Orchestrator.Google == nil {
+		return errorf(http.StatusNotImplemented, "google is not configured")
+	}
+	user, err := s.Orchestrator.Google.WithContext(r.Context()).Users().GetUser(r.PathValue("key"))
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, user)
+}
+
+/*
+ * # Offboard a User
+ * POST /v1/offboard
+ * - Runs synchronously and responds with the report of the workflow; 200 when it succeeded, 502 when a step failed
+ */
+func (s *Server) offboard(w http.ResponseWriter, r *http.Request) error {
+	req := &OffboardRequest{}
+	if err := decode(r, req); err != nil {
+		return err
+	}
+	if !strings.Contains(req.Email, "@") {
+		return errorf(http.StatusBadRequest, "email is required")
+	}
+
+	cfg := &orchestrators.OffboardingConfig{
+		TransferTo:  req.TransferTo,
+		SuspendedOU: req.SuspendedOU,
+		Skip:        req.Skip,
+		WorkflowOptions: orchestrators.WorkflowOptions{
+			Retries:     2,
+			DryRun:      req.StepsOnly,
+			StopOnError: req.StopOnError,
+		},
+	}
+
+	s.offboarding.Lock()
+	report := s.Orchestrator.WithContext(r.Context()).Offboard(req.Email, cfg)
+	s.offboarding.Unlock()
+
+	response := &WorkflowResponse{Succeeded: report.Succeeded(), Failed: len(report.Failed()), Report: report}
+	status := http.StatusOK
+	if !response.Succeeded {
+		status = http.StatusBadGateway
+	}
+	return writeJSON(w, status, response)
+}
+
+/*
+ * # Access Review
+ * GET /v1/reports/access-review?period=2024-Q3&dormantDays=90&privilegedGroups=*-admins,Security
+ * - Failed collections are recorded in the evidence of the review, rather than failing the request
+ */
+func (s *Server) accessReview(w http.ResponseWriter, r *http.Request) error {
+	q := r.URL.Query()
+	cfg := reports.AccessReviewConfig{
+		Period:    q.Get("period"),
+		Collector: "api:" + TokenFrom(r).Name,
+	}
+	if days := q.Get("dormantDays"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return errorf(http.StatusBadRequest, "dormantDays must be a positive integer")
+		}
+		cfg.DormantDays = n
+	}
+	if groups := q.Get("privilegedGroups"); groups != "" {
+		cfg.PrivilegedGroups = strings.Split(groups, ",")
+	}
+
+	review := reports.NewAccessReview(cfg)
+	c := s.Orchestrator.WithContext(r.Context())
+	if c.Okta != nil {
+		review.CollectOkta(c.Okta)
+	}
+	if c.Google != nil {
+		review.CollectGoogle(c.Google)
+	}
+	if c.Slack != nil {
+		review.CollectSlack(c.Slack)
+	}
+	return writeJSON(w, http.StatusOK, review)
+}
+
+/*
+ * # Drift Plan
+ * POST /v1/drift/plan
+ * - The body is a desired state (see `drift.State`) as JSON; the response is the drift report
+ */
+func (s *Server) driftPlan(w http.ResponseWriter, r *http.Request) error {
+	state := &drift.State{}
+	if err := decode(r, state); err != nil {
+		return err
+	}
+
+	c := s.Orchestrator.WithContext(r.Context())
+	d := &drift.Detector{
+		Log:    s.Log,
+		Google: c.Google,
+		Okta:   c.Okta,
+		Slack:  c.Slack,
+	}
+
+	return writeJSON(w, http.StatusOK, d.Plan(state))
+}
@@ -0,0 +1,227 @@
+/*
+# Automox
+
+This package initializes all the methods for functions which interact with the Automox API:
+https://developer.automox.com/openapi/axconsole/overview/
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/automox/automox.go
+package automox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/config"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/ratelimit"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+const (
+	BaseURL = "https://console.automox.com/api" // https://developer.automox.com/openapi/axconsole/overview/
+)
+
+const (
+	AutomoxDevices      = "%s/servers"      // https://developer.automox.com/openapi/axconsole/operation/getDevices/
+	AutomoxPolicies     = "%s/policies"     // https://developer.automox.com/openapi/axconsole/operation/getPolicies/
+	AutomoxServerGroups = "%s/servergroups" // https://developer.automox.com/openapi/axconsole/operation/getServerGroups/
+)
+
+// BuildURL builds a URL for a given resource and identifiers.
+func (c *Client) BuildURL(endpoint string, identifiers ...string) string {
+	url := fmt.Sprintf(endpoint, c.BaseURL)
+	for _, id := range identifiers {
+		url = fmt.Sprintf("%s/%s", url, id)
+	}
+	c.Log.Debug("url:", url)
+	return url
+}
+
+// Close closes the HTTP client (see `requests.Client.Close`), then flushes the cache (see `cache.Cache.Flush`)
+func (c *Client) Close(ctx context.Context) error {
+	return errors.Join(c.HTTP.Close(ctx), c.Cache.Flush())
+}
+
+// Name returns the name of the service, `automox`
+func (c *Client) Name() string {
+	return "automox"
+}
+
+// Endpoint returns the base URL of the API the client sends its requests to
+func (c *Client) Endpoint() string {
+	return c.BaseURL
+}
+
+/*
+ * # Check the Health of the Automox Client
+ * Gets a server group of the organization, so the API is reachable and the key valid
+ * /api/servergroups
+ */
+func (c *Client) HealthCheck(ctx context.Context) error {
+	_, _, err := c.HTTP.DoRequestContext(ctx, "GET", c.BuildURL(AutomoxServerGroups), &Query{OrgID: c.OrgID, Limit: 1}, nil)
+	return rerrors.WithProvider(err, "automox")
+}
+
+// UseCache() enables caching for the next method call.
+func (c *Client) UseCache() *Client {
+	c.Cache.Enabled = true
+	return c
+}
+
+/*
+ * SetCache stores an Automox API response in the cache
+ */
+func (c *Client) SetCache(key string, value interface{}, duration time.Duration) {
+	// Convert value to a byte slice and cache it
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.Log.Error("Error marshalling cache data:", err)
+		return
+	}
+	c.Cache.Set(key, data, duration)
+}
+
+/*
+ * GetCache retrieves an Automox API response from the cache
+ */
+func (c *Client) GetCache(key string, target interface{}) bool {
+	data, found := c.Cache.Get(key)
+	if !found || !c.Cache.Enabled {
+		return false
+	}
+
+	err := json.Unmarshal(data, target)
+	if err != nil {
+		c.Log.Error("Error unmarshalling cache data:", err)
+		return false
+	}
+	return true
+}
+
+/*
+  - # Generate Automox Client
+  - @param verbosity int
+  - @return *Client
+  - Example:
+
+```go
+
+	a := automox.NewClient(log.DEBUG)
+
+```
+*/
+func NewClient(verbosity int) *Client {
+	log := log.NewLogger("{automox}", verbosity)
+
+	url := config.GetEnv("AUTOMOX_BASE_URL")
+	if len(url) == 0 {
+		url = BaseURL
+	}
+	url = strings.TrimSuffix(url, "/")
+
+	orgID := config.GetEnv("AUTOMOX_ORG_ID")
+	if len(orgID) == 0 {
+		log.Fatal("AUTOMOX_ORG_ID is not set")
+	}
+
+	token := config.GetEnv("AUTOMOX_API_KEY")
+	if len(token) == 0 {
+		log.Fatal("AUTOMOX_API_KEY is not set")
+	}
+
+	headers := requests.Headers{
+		"Authorization": "Bearer " + token,
+		"Accept":        requests.JSON,
+		"Content-Type":  requests.JSON,
+	}
+
+	encryptionKey := []byte(config.GetEnv("REGO_ENCRYPTION_KEY"))
+	if len(encryptionKey) == 0 {
+		log.Fatal("REGO_ENCRYPTION_KEY is not set")
+	}
+
+	cache, err := cache.NewCache(encryptionKey, "rego_cache_automox.gob", 1000000)
+	if err != nil {
+		panic(err)
+	}
+
+	// https://developer.automox.com/developer-portal/rate_limits/
+	rl := ratelimit.NewRateLimiter(60, 1*time.Minute)
+	rl.Log.Verbosity = verbosity
+
+	httpClient := requests.NewClient(nil, headers, rl, requests.WithUserAgent("automox"))
+	httpClient.BodyType = requests.JSON
+
+	return &Client{
+		BaseURL: url,
+		OrgID:   orgID,
+		HTTP:    httpClient,
+		Log:     log,
+		Cache:   cache,
+	}
+}
+
+/*
+ * Perform a generic request to the Automox API
+ */
+func do[T any](c *Client, method string, url string, query interface{}, data interface{}) (T, error) {
+	var result T
+	res, body, err := c.HTTP.DoRequest(method, url, query, data)
+	if err != nil {
+		return *new(T), rerrors.WithProvider(err, "automox")
+	}
+
+	c.Log.Println("Response Status:", res.Status)
+	c.Log.Debug("Response Body:", string(body))
+
+	// Action endpoints respond with `204 No Content`
+	if len(body) == 0 {
+		return result, nil
+	}
+
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return *new(T), fmt.Errorf("unmarshalling error: %w", err)
+	}
+
+	return result, nil
+}
+
+/*
+ * Generically perform a paginated request to the Automox API
+ * - Automox list endpoints return a bare JSON array; pages are zero-based and the last page holds fewer than `limit` items
+ */
+func doPaginated[E any](c *Client, method string, url string, query *Query) (*[]*E, error) {
+	results := make([]*E, 0)
+
+	q := *query
+	if q.Limit == 0 {
+		q.Limit = 500
+	}
+
+	for q.Page = 0; ; q.Page++ {
+		page, err := do[[]*E](c, method, url, &q, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, page...)
+
+		if len(page) < q.Limit {
+			break
+		}
+	}
+
+	return &results, nil
+}
@@ -0,0 +1,183 @@
+// Code generated by mockgen from interfaces.go; DO NOT EDIT.
+
+// pkg/automox/automoxmock/mocks.go
+package automoxmock
+
+import (
+	"sync"
+
+	"github.com/gemini-oss/rego/pkg/automox"
+)
+
+// Call is a call received by a mock
+type Call struct {
+	Method string        // Name of the method called
+	Args   []interface{} // Arguments of the call
+}
+
+// calls records the calls received by a mock
+type calls struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+func (c *calls) record(method string, args ...interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls received, in order; only those of `method` when set
+func (c *calls) Calls(method ...string) []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	received := []Call{}
+	for _, call := range c.calls {
+		if len(method) == 0 || call.Method == method[0] {
+			received = append(received, call)
+		}
+	}
+	return received
+}
+
+// DeviceService is a mock of `automox.DeviceService`; methods without a func return zero values
+type DeviceService struct {
+	ListAllDevicesFunc     func() (*automox.Devices, error)
+	GetDeviceFunc          func(int) (*automox.Device, error)
+	GetDeviceBySerialFunc  func(string) (*automox.Device, error)
+	ListPackagesFunc       func(int) (*automox.Packages, error)
+	ListPendingPatchesFunc func(int) (*automox.Packages, error)
+	PatchStatusFunc        func() ([]*automox.PatchStatus, error)
+
+	calls
+}
+
+var _ automox.DeviceService = (*DeviceService)(nil)
+
+func (m *DeviceService) ListAllDevices() (r0 *automox.Devices, r1 error) {
+	m.record("ListAllDevices")
+	if m.ListAllDevicesFunc != nil {
+		return m.ListAllDevicesFunc()
+	}
+	return
+}
+
+func (m *DeviceService) GetDevice(a0 int) (r0 *automox.Device, r1 error) {
+	m.record("GetDevice", a0)
+	if m.GetDeviceFunc != nil {
+		return m.GetDeviceFunc(a0)
+	}
+	return
+}
+
+func (m *DeviceService) GetDeviceBySerial(a0 string) (r0 *automox.Device, r1 error) {
+	m.record("GetDeviceBySerial", a0)
+	if m.GetDeviceBySerialFunc != nil {
+		return m.GetDeviceBySerialFunc(a0)
+	}
+	return
+}
+
+func (m *DeviceService) ListPackages(a0 int) (r0 *automox.Packages, r1 error) {
+	m.record("ListPackages", a0)
+	if m.ListPackagesFunc != nil {
+		return m.ListPackagesFunc(a0)
+	}
+	return
+}
+
+func (m *DeviceService) ListPendingPatches(a0 int) (r0 *automox.Packages, r1 error) {
+	m.record("ListPendingPatches", a0)
+	if m.ListPendingPatchesFunc != nil {
+		return m.ListPendingPatchesFunc(a0)
+	}
+	return
+}
+
+func (m *DeviceService) PatchStatus() (r0 []*automox.PatchStatus, r1 error) {
+	m.record("PatchStatus")
+	if m.PatchStatusFunc != nil {
+		return m.PatchStatusFunc()
+	}
+	return
+}
+
+// PolicyService is a mock of `automox.PolicyService`; methods without a func return zero values
+type PolicyService struct {
+	ListAllPoliciesFunc   func() (*automox.Policies, error)
+	GetPolicyFunc         func(int) (*automox.Policy, error)
+	UpdatePolicyFunc      func(*automox.Policy) error
+	AssignToGroupFunc     func(int, int) error
+	UnassignFromGroupFunc func(int, int) error
+	RunPolicyFunc         func(int) error
+	RunPolicyOnDeviceFunc func(int, int) error
+	ListServerGroupsFunc  func() (*automox.ServerGroups, error)
+
+	calls
+}
+
+var _ automox.PolicyService = (*PolicyService)(nil)
+
+func (m *PolicyService) ListAllPolicies() (r0 *automox.Policies, r1 error) {
+	m.record("ListAllPolicies")
+	if m.ListAllPoliciesFunc != nil {
+		return m.ListAllPoliciesFunc()
+	}
+	return
+}
+
+func (m *PolicyService) GetPolicy(a0 int) (r0 *automox.Policy, r1 error) {
+	m.record("GetPolicy", a0)
+	if m.GetPolicyFunc != nil {
+		return m.GetPolicyFunc(a0)
+	}
+	return
+}
+
+func (m *PolicyService) UpdatePolicy(a0 *automox.Policy) (r0 error) {
+	m.record("UpdatePolicy", a0)
+	if m.UpdatePolicyFunc != nil {
+		return m.UpdatePolicyFunc(a0)
+	}
+	return
+}
+
+func (m *PolicyService) AssignToGroup(a0 int, a1 int) (r0 error) {
+	m.record("AssignToGroup", a0, a1)
+	if m.AssignToGroupFunc != nil {
+		return m.AssignToGroupFunc(a0, a1)
+	}
+	return
+}
+
+func (m *PolicyService) UnassignFromGroup(a0 int, a1 int) (r0 error) {
+	m.record("UnassignFromGroup", a0, a1)
+	if m.UnassignFromGroupFunc != nil {
+		return m.UnassignFromGroupFunc(a0, a1)
+	}
+	return
+}
+
+func (m *PolicyService) RunPolicy(a0 int) (r0 error) {
+	m.record("RunPolicy", a0)
+	if m.RunPolicyFunc != nil {
+		return m.RunPolicyFunc(a0)
+	}
+	return
+}
+
+func (m *PolicyService) RunPolicyOnDevice(a0 int, a1 int) (r0 error) {
+	m.record("RunPolicyOnDevice", a0, a1)
+	if m.RunPolicyOnDeviceFunc != nil {
+		return m.RunPolicyOnDeviceFunc(a0, a1)
+	}
+	return
+}
+
+func (m *PolicyService) ListServerGroups() (r0 *automox.ServerGroups, r1 error) {
+	m.record("ListServerGroups")
+	if m.ListServerGroupsFunc != nil {
+		return m.ListServerGroupsFunc()
+	}
+	return
+}
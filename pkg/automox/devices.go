@@ -0,0 +1,170 @@
+/*
+# Automox - Devices
+
+This package contains all the methods to interact with Automox devices (servers):
+https://developer.automox.com/openapi/axconsole/tag/Devices/
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/automox/devices.go
+package automox
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeviceClient for chaining methods
+type DeviceClient struct {
+	*Client
+}
+
+// Entry point for device-related operations
+func (c *Client) Devices() *DeviceClient {
+	return &DeviceClient{
+		Client: c,
+	}
+}
+
+/*
+ * # List all devices in the organization
+ * /api/servers
+ * - https://developer.automox.com/openapi/axconsole/operation/getDevices/
+ */
+func (c *DeviceClient) ListAllDevices() (*Devices, error) {
+	url := c.BuildURL(AutomoxDevices)
+
+	var cache Devices
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	devices, err := doPaginated[Device](c.Client, "GET", url, &Query{OrgID: c.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := Devices(*devices)
+	c.SetCache(url, result, 30*time.Minute)
+	return &result, nil
+}
+
+/*
+ * # Get a device by ID
+ * /api/servers/{id}
+ * - https://developer.automox.com/openapi/axconsole/operation/getServer/
+ */
+func (c *DeviceClient) GetDevice(id int) (*Device, error) {
+	url := c.BuildURL(AutomoxDevices, strconv.Itoa(id))
+
+	var cache Device
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	device, err := do[Device](c.Client, "GET", url, &Query{OrgID: c.OrgID}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetCache(url, device, 5*time.Minute)
+	return &device, nil
+}
+
+/*
+ * # Get a device by serial number
+ * /api/servers
+ * - https://developer.automox.com/openapi/axconsole/operation/getDevices/
+ */
+func (c *DeviceClient) GetDeviceBySerial(serial string) (*Device, error) {
+	devices, err := c.ListAllDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range *devices {
+		if strings.EqualFold(d.SerialNumber, serial) {
+			return d, nil
+		}
+	}
+
+	return nil, fmt.Errorf("device with serial number %s not found", serial)
+}
+
+/*
+ * # List the software packages on a device
+ * /api/servers/{id}/packages
+ * - https://developer.automox.com/openapi/axconsole/operation/getDevicePackages/
+ */
+func (c *DeviceClient) ListPackages(id int) (*Packages, error) {
+	url := c.BuildURL(AutomoxDevices, strconv.Itoa(id), "packages")
+
+	var cache Packages
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	packages, err := doPaginated[Package](c.Client, "GET", url, &Query{OrgID: c.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := Packages(*packages)
+	c.SetCache(url, result, 30*time.Minute)
+	return &result, nil
+}
+
+/*
+ * # List the patches waiting to be installed on a device
+ * /api/servers/{id}/packages
+ * - https://developer.automox.com/openapi/axconsole/operation/getDevicePackages/
+ */
+func (c *DeviceClient) ListPendingPatches(id int) (*Packages, error) {
+	packages, err := c.ListPackages(id)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := Packages{}
+	for _, p := range *packages {
+		if !p.Installed && !p.Ignored {
+			pending = append(pending, p)
+		}
+	}
+
+	return &pending, nil
+}
+
+/*
+ * # Summarize the patch status of every device in the organization
+ * /api/servers
+ * - https://developer.automox.com/openapi/axconsole/operation/getDevices/
+ */
+func (c *DeviceClient) PatchStatus() ([]*PatchStatus, error) {
+	devices, err := c.ListAllDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*PatchStatus, 0, len(*devices))
+	for _, d := range *devices {
+		statuses = append(statuses, &PatchStatus{
+			DeviceID:       d.ID,
+			Name:           d.Name,
+			SerialNumber:   d.SerialNumber,
+			OS:             strings.TrimSpace(fmt.Sprintf("%s %s", d.OSName, d.OSVersion)),
+			Compliant:      d.Compliant,
+			PendingPatches: d.PendingPatches,
+			NeedsReboot:    d.NeedsReboot,
+			LastPatched:    d.LastUpdateTime.Time,
+			LastSeen:       d.LastRefreshTime.Time,
+		})
+	}
+
+	return statuses, nil
+}
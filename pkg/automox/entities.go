@@ -0,0 +1,173 @@
+/*
+# Automox - Entities [Structs]
+
+This package contains many structs for handling responses from the Automox API:
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/automox/entities.go
+package automox
+
+import (
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/normalize"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+// ### Automox Client Entities
+// ---------------------------------------------------------------------
+type Client struct {
+	BaseURL string           // BaseURL is the base URL for Automox API requests.
+	OrgID   string           // OrgID is the ID of the Automox organization, sent as `o` on every request.
+	HTTP    *requests.Client // HTTP is the client used to make HTTP requests.
+	Log     *log.Logger      // Log is the logger used to log messages.
+	Cache   *cache.Cache     // Cache is the cache used to store responses from the Automox API.
+}
+
+/*
+ * Query Parameters for Automox list endpoints
+ * https://developer.automox.com/openapi/axconsole/operation/getDevices/#!in=query
+ */
+type Query struct {
+	OrgID   string `url:"o"`                 // The organization ID.
+	GroupID string `url:"groupId,omitempty"` // Filter devices by server group.
+	Limit   int    `url:"limit,omitempty"`   // The number of results per page. Maximum: 500.
+	Page    int    `url:"page,omitempty"`    // The zero-based page of results to return.
+}
+
+// END OF AUTOMOX CLIENT ENTITIES
+//---------------------------------------------------------------------
+
+// ### Automox Device Structs
+// ---------------------------------------------------------------------
+type Devices []*Device
+
+type Device struct {
+	AgentVersion     string          `json:"agent_version,omitempty"`        // The version of the Automox agent.
+	Compliant        bool            `json:"compliant"`                      // Whether the device is compliant with all of its policies.
+	Connected        bool            `json:"connected"`                      // Whether the agent is currently connected.
+	CreateTime       normalize.Time  `json:"create_time,omitempty"`          // The date the device was added.
+	Details          *DeviceDetails  `json:"detail,omitempty"`               // Hardware details of the device.
+	ID               int             `json:"id,omitempty"`                   // The ID of the device.
+	IPAddrs          []string        `json:"ip_addrs,omitempty"`             // The public IP addresses of the device.
+	IPAddrsPrivate   []string        `json:"ip_addrs_private,omitempty"`     // The private IP addresses of the device.
+	IsCompatible     bool            `json:"is_compatible"`                  // Whether the device is compatible with Automox.
+	LastDisconnect   normalize.Time  `json:"last_disconnect_time,omitempty"` // The last time the agent disconnected.
+	LastLoggedInUser string          `json:"last_logged_in_user,omitempty"`  // The last user to log in to the device.
+	LastRefreshTime  normalize.Time  `json:"last_refresh_time,omitempty"`    // The last time the device reported its inventory.
+	LastUpdateTime   normalize.Time  `json:"last_update_time,omitempty"`     // The last time the device was patched.
+	Name             string          `json:"name,omitempty"`                 // The hostname of the device.
+	NeedsReboot      bool            `json:"needs_reboot"`                   // Whether the device needs a reboot to complete patching.
+	OSFamily         string          `json:"os_family,omitempty"`            // The OS family {Windows, Mac, Linux}
+	OSName           string          `json:"os_name,omitempty"`              // The name of the OS.
+	OSVersion        string          `json:"os_version,omitempty"`           // The version of the OS.
+	PendingPatches   int             `json:"pending_patches"`                // The number of patches waiting to be installed.
+	PolicyStatus     []*PolicyStatus `json:"policy_status,omitempty"`        // The result of each policy assigned to the device.
+	SerialNumber     string          `json:"serial_number,omitempty"`        // The serial number of the device.
+	ServerGroupID    int             `json:"server_group_id,omitempty"`      // The ID of the server group the device belongs to.
+	Status           *DeviceStatus   `json:"status,omitempty"`               // The status of the device.
+	Tags             []string        `json:"tags,omitempty"`                 // The tags applied to the device.
+}
+
+type DeviceDetails struct {
+	Model  string `json:"MODEL,omitempty"`  // The hardware model.
+	Serial string `json:"SERIAL,omitempty"` // The hardware serial number.
+	Vendor string `json:"VENDOR,omitempty"` // The hardware vendor.
+}
+
+type DeviceStatus struct {
+	AgentStatus  string `json:"agent_status,omitempty"`  // The status of the agent {connected, disconnected}
+	DeviceStatus string `json:"device_status,omitempty"` // The status of the device {ready, not-ready, refreshing, ...}
+	PolicyStatus string `json:"policy_status,omitempty"` // The overall policy status {compliant, non-compliant, pending, ...}
+}
+
+type PolicyStatus struct {
+	CreateTime     string `json:"create_time,omitempty"`      // The time the policy last ran.
+	ID             int    `json:"id,omitempty"`               // The ID of the status record.
+	PolicyID       int    `json:"policy_id,omitempty"`        // The ID of the policy.
+	PolicyName     string `json:"policy_name,omitempty"`      // The name of the policy.
+	PolicyTypeName string `json:"policy_type_name,omitempty"` // The type of policy {patch, custom, required_software}
+	Result         string `json:"result,omitempty"`           // The raw result of the last run.
+	Status         int    `json:"status"`                     // The status of the last run (1 = compliant).
+}
+
+type Packages []*Package
+
+type Package struct {
+	CVEs        []string `json:"cves,omitempty"`         // The CVEs addressed by the package.
+	DisplayName string   `json:"display_name,omitempty"` // The display name of the package.
+	ID          int      `json:"id,omitempty"`           // The ID of the package.
+	Ignored     bool     `json:"ignored"`                // Whether the package is ignored.
+	Installed   bool     `json:"installed"`              // Whether the package is installed.
+	Name        string   `json:"name,omitempty"`         // The name of the package.
+	OSName      string   `json:"os_name,omitempty"`      // The OS the package applies to.
+	Severity    string   `json:"severity,omitempty"`     // The severity of the patch {critical, high, medium, low, none}
+	SoftwareID  int      `json:"software_id,omitempty"`  // The ID of the software the package belongs to.
+	Version     string   `json:"version,omitempty"`      // The version of the package.
+}
+
+// PatchStatus summarizes the patch compliance of a single device, suitable for merging into a device inventory.
+type PatchStatus struct {
+	DeviceID       int       `json:"deviceId" csv:"device_id"`                 // The Automox ID of the device.
+	Name           string    `json:"name" csv:"name"`                          // The hostname of the device.
+	SerialNumber   string    `json:"serialNumber" csv:"serial_number"`         // The serial number of the device.
+	OS             string    `json:"os" csv:"os"`                              // The OS name and version.
+	Compliant      bool      `json:"compliant" csv:"compliant"`                // Whether the device is compliant with all of its policies.
+	PendingPatches int       `json:"pendingPatches" csv:"pending_patches"`     // The number of patches waiting to be installed.
+	NeedsReboot    bool      `json:"needsReboot" csv:"needs_reboot"`           // Whether the device needs a reboot to complete patching.
+	LastPatched    time.Time `json:"lastPatched,omitempty" csv:"last_patched"` // The last time the device was patched.
+	LastSeen       time.Time `json:"lastSeen,omitempty" csv:"last_seen"`       // The last time the device reported its inventory.
+}
+
+// END OF AUTOMOX DEVICE STRUCTS
+//---------------------------------------------------------------------
+
+// ### Automox Policy Structs
+// ---------------------------------------------------------------------
+type Policies []*Policy
+
+type Policy struct {
+	Configuration  map[string]interface{} `json:"configuration,omitempty"`    // The type-specific configuration of the policy.
+	CreateTime     string                 `json:"create_time,omitempty"`      // The date the policy was created.
+	ID             int                    `json:"id,omitempty"`               // The ID of the policy.
+	Name           string                 `json:"name,omitempty"`             // The name of the policy.
+	Notes          string                 `json:"notes,omitempty"`            // Notes about the policy.
+	OrganizationID int                    `json:"organization_id,omitempty"`  // The ID of the organization.
+	PolicyTypeName string                 `json:"policy_type_name,omitempty"` // The type of policy {patch, custom, required_software}
+	ScheduleDays   int                    `json:"schedule_days,omitempty"`    // Bitmask of the days the policy runs.
+	ScheduleTime   string                 `json:"schedule_time,omitempty"`    // The time of day the policy runs.
+	ServerCount    int                    `json:"server_count,omitempty"`     // The number of devices the policy applies to.
+	ServerGroups   []int                  `json:"server_groups"`              // The IDs of the server groups the policy is assigned to.
+}
+
+// PolicyAction is the payload for running a policy immediately.
+type PolicyAction struct {
+	Action   string `json:"action"`             // The action to take {remediateAll, remediateServer}
+	ServerID int    `json:"serverId,omitempty"` // The device to remediate, when action is `remediateServer`.
+}
+
+// END OF AUTOMOX POLICY STRUCTS
+//---------------------------------------------------------------------
+
+// ### Automox Server Group Structs
+// ---------------------------------------------------------------------
+type ServerGroups []*ServerGroup
+
+type ServerGroup struct {
+	ID                  int    `json:"id,omitempty"`                     // The ID of the group.
+	Name                string `json:"name,omitempty"`                   // The name of the group.
+	Notes               string `json:"notes,omitempty"`                  // Notes about the group.
+	ParentServerGroupID int    `json:"parent_server_group_id,omitempty"` // The ID of the parent group.
+	Policies            []int  `json:"policies,omitempty"`               // The IDs of the policies assigned to the group.
+	RefreshInterval     int    `json:"refresh_interval,omitempty"`       // How often devices in the group refresh, in minutes.
+	ServerCount         int    `json:"server_count,omitempty"`           // The number of devices in the group.
+}
+
+// END OF AUTOMOX SERVER GROUP STRUCTS
+//---------------------------------------------------------------------
@@ -0,0 +1,42 @@
+/*
+# Automox Interfaces
+
+This package contains the interfaces of each Automox resource, which the resource clients satisfy. Code depending on an
+interface rather than the clients can be unit tested with the mocks of `pkg/automox/automoxmock`
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/automox/interfaces.go
+package automox
+
+//go:generate go run ../../cmd/mockgen
+
+// DeviceService reads devices, their packages and patches
+type DeviceService interface {
+	ListAllDevices() (*Devices, error)
+	GetDevice(id int) (*Device, error)
+	GetDeviceBySerial(serial string) (*Device, error)
+	ListPackages(id int) (*Packages, error)
+	ListPendingPatches(id int) (*Packages, error)
+	PatchStatus() ([]*PatchStatus, error)
+}
+
+// PolicyService manages policies and the groups they are assigned to
+type PolicyService interface {
+	ListAllPolicies() (*Policies, error)
+	GetPolicy(id int) (*Policy, error)
+	UpdatePolicy(policy *Policy) error
+	AssignToGroup(policyID, groupID int) error
+	UnassignFromGroup(policyID, groupID int) error
+	RunPolicy(policyID int) error
+	RunPolicyOnDevice(policyID, deviceID int) error
+	ListServerGroups() (*ServerGroups, error)
+}
+
+var (
+	_ DeviceService = (*DeviceClient)(nil)
+	_ PolicyService = (*PolicyClient)(nil)
+)
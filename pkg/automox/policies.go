@@ -0,0 +1,175 @@
+/*
+# Automox - Policies
+
+This package contains all the methods to interact with Automox policies:
+https://developer.automox.com/openapi/axconsole/tag/Policies/
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/automox/policies.go
+package automox
+
+import (
+	"slices"
+	"strconv"
+	"time"
+)
+
+// PolicyClient for chaining methods
+type PolicyClient struct {
+	*Client
+}
+
+// Entry point for policy-related operations
+func (c *Client) Policies() *PolicyClient {
+	return &PolicyClient{
+		Client: c,
+	}
+}
+
+/*
+ * # List all policies in the organization
+ * /api/policies
+ * - https://developer.automox.com/openapi/axconsole/operation/getPolicies/
+ */
+func (c *PolicyClient) ListAllPolicies() (*Policies, error) {
+	url := c.BuildURL(AutomoxPolicies)
+
+	var cache Policies
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	policies, err := doPaginated[Policy](c.Client, "GET", url, &Query{OrgID: c.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := Policies(*policies)
+	c.SetCache(url, result, 30*time.Minute)
+	return &result, nil
+}
+
+/*
+ * # Get a policy by ID
+ * /api/policies/{id}
+ * - https://developer.automox.com/openapi/axconsole/operation/getPolicy/
+ */
+func (c *PolicyClient) GetPolicy(id int) (*Policy, error) {
+	url := c.BuildURL(AutomoxPolicies, strconv.Itoa(id))
+
+	policy, err := do[Policy](c.Client, "GET", url, &Query{OrgID: c.OrgID}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+/*
+ * # Update a policy
+ * /api/policies/{id}
+ * - https://developer.automox.com/openapi/axconsole/operation/updatePolicy/
+ */
+func (c *PolicyClient) UpdatePolicy(policy *Policy) error {
+	url := c.BuildURL(AutomoxPolicies, strconv.Itoa(policy.ID))
+
+	_, err := do[interface{}](c.Client, "PUT", url, &Query{OrgID: c.OrgID}, policy)
+	return err
+}
+
+/*
+ * # Assign a policy to a server group
+ * - Policies apply to devices through their server groups
+ * /api/policies/{id}
+ * - https://developer.automox.com/openapi/axconsole/operation/updatePolicy/
+ */
+func (c *PolicyClient) AssignToGroup(policyID, groupID int) error {
+	policy, err := c.GetPolicy(policyID)
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(policy.ServerGroups, groupID) {
+		c.Log.Debugf("Policy %d is already assigned to group %d", policyID, groupID)
+		return nil
+	}
+
+	c.Log.Printf("Assigning policy %d to group %d", policyID, groupID)
+	policy.ServerGroups = append(policy.ServerGroups, groupID)
+	return c.UpdatePolicy(policy)
+}
+
+/*
+ * # Unassign a policy from a server group
+ * /api/policies/{id}
+ * - https://developer.automox.com/openapi/axconsole/operation/updatePolicy/
+ */
+func (c *PolicyClient) UnassignFromGroup(policyID, groupID int) error {
+	policy, err := c.GetPolicy(policyID)
+	if err != nil {
+		return err
+	}
+
+	i := slices.Index(policy.ServerGroups, groupID)
+	if i < 0 {
+		c.Log.Debugf("Policy %d is not assigned to group %d", policyID, groupID)
+		return nil
+	}
+
+	c.Log.Printf("Unassigning policy %d from group %d", policyID, groupID)
+	policy.ServerGroups = slices.Delete(policy.ServerGroups, i, i+1)
+	return c.UpdatePolicy(policy)
+}
+
+/*
+ * # Run a policy now on every device it applies to
+ * /api/policies/{id}/action
+ * - https://developer.automox.com/openapi/axconsole/operation/executePolicy/
+ */
+func (c *PolicyClient) RunPolicy(policyID int) error {
+	url := c.BuildURL(AutomoxPolicies, strconv.Itoa(policyID), "action")
+
+	c.Log.Printf("Running policy %d on all devices", policyID)
+	_, err := do[interface{}](c.Client, "POST", url, &Query{OrgID: c.OrgID}, &PolicyAction{Action: "remediateAll"})
+	return err
+}
+
+/*
+ * # Run a policy now on a single device
+ * /api/policies/{id}/action
+ * - https://developer.automox.com/openapi/axconsole/operation/executePolicy/
+ */
+func (c *PolicyClient) RunPolicyOnDevice(policyID, deviceID int) error {
+	url := c.BuildURL(AutomoxPolicies, strconv.Itoa(policyID), "action")
+
+	c.Log.Printf("Running policy %d on device %d", policyID, deviceID)
+	_, err := do[interface{}](c.Client, "POST", url, &Query{OrgID: c.OrgID}, &PolicyAction{Action: "remediateServer", ServerID: deviceID})
+	return err
+}
+
+/*
+ * # List all server groups in the organization
+ * /api/servergroups
+ * - https://developer.automox.com/openapi/axconsole/operation/getServerGroups/
+ */
+func (c *PolicyClient) ListServerGroups() (*ServerGroups, error) {
+	url := c.BuildURL(AutomoxServerGroups)
+
+	var cache ServerGroups
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	groups, err := doPaginated[ServerGroup](c.Client, "GET", url, &Query{OrgID: c.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := ServerGroups(*groups)
+	c.SetCache(url, result, 30*time.Minute)
+	return &result, nil
+}
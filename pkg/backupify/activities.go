@@ -43,7 +43,7 @@ func (c *ActivityClient) GetActivities(appType AppType) (*Activities, error) {
 
 	activities, err := do[ActivitiesResponse](c.Client, "POST", url, nil, activitiesPayload)
 	if err != nil {
-		c.Log.Fatal(err)
+		return nil, err
 	}
 
 	c.SetCache(url, activities.Activities, 5*time.Minute)
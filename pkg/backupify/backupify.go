@@ -13,14 +13,19 @@ https://www.backupify.com/
 package backupify
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/gemini-oss/rego/pkg/common/cache"
 	"github.com/gemini-oss/rego/pkg/common/config"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
 	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/options"
 	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/version"
 )
 
 const (
@@ -33,16 +38,27 @@ const (
 	serviceSnapshots    = "%s/serviceSnaps"
 )
 
-var (
-	kilobyte    float64 = 1024
-	megabyte    float64 = 1024 * kilobyte
-	gigabyte    float64 = 1024 * megabyte
-	terabyte    float64 = 1024 * gigabyte
-	GoogleDrive AppType = "GoogleDrive"
-	SharedDrive AppType = "GoogleTeamDrives"
-	GoogleMail  AppType = "GoogleMail"
+const (
+	GoogleCalendar AppType = "GoogleCalendar"   // Google Calendar
+	GoogleContacts AppType = "GoogleContacts"   // Google Contacts
+	GoogleDrive    AppType = "GoogleDrive"      // My Drive of each user
+	SharedDrive    AppType = "GoogleTeamDrives" // Shared drives
+	GoogleMail     AppType = "GoogleMail"       // Gmail
+)
+
+const (
+	WEBUI_V1 APIVersion = "v1" // WebUI serving the export actions at the path of the customer, e.g. /{customerID}/restoreExportAction
 )
 
+// APIVersions is every version of the Backupify WebUI a client can be pinned to, with `options.WithAPIVersion`
+var APIVersions = []APIVersion{WEBUI_V1}
+
+// DefaultAPIVersion is the version of the WebUI of clients which are not pinned to one
+const DefaultAPIVersion = WEBUI_V1
+
+// AppTypes is every type of Backupify application
+var AppTypes = []AppType{GoogleCalendar, GoogleContacts, GoogleDrive, SharedDrive, GoogleMail}
+
 // BuildURL builds a URL for a given resource and identifiers.
 func (c *Client) BuildURL(endpoint string, identifiers ...string) string {
 	url := fmt.Sprintf(endpoint, c.BaseURL)
@@ -54,10 +70,48 @@ func (c *Client) BuildURL(endpoint string, identifiers ...string) string {
 
 // UseCache() enables caching for the next method call.
 func (c *Client) UseCache() *Client {
-	c.Cache.Enabled = true
+	c.Cache.Enable()
 	return c
 }
 
+/*
+ * # With Context
+ * Returns a copy of the client whose requests are bound to `ctx`
+ * - Cancelling `ctx` stops paginated lists between pages and aborts the request in flight
+ */
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cc := *c
+	cc.HTTP = c.HTTP.WithContext(ctx)
+	return &cc
+}
+
+// Close closes the HTTP client (see `requests.Client.Close`), then flushes the cache (see `cache.Cache.Flush`)
+func (c *Client) Close(ctx context.Context) error {
+	return errors.Join(c.HTTP.Close(ctx), c.Cache.Flush())
+}
+
+// Name returns the name of the service, `backupify`
+func (c *Client) Name() string {
+	return "backupify"
+}
+
+// Endpoint returns the base URL of the API the client sends its requests to
+func (c *Client) Endpoint() string {
+	return c.BaseURL
+}
+
+/*
+ * # Check the Health of the Backupify Client
+ * Gets the first Gmail user, so the WebUI is reachable and its session valid
+ * /{customerID}/customerServices
+ */
+func (c *Client) HealthCheck(ctx context.Context) error {
+	payload := usersPayload(GoogleMail)
+	payload.Length = 1
+	_, err := do[Users](c.WithContext(ctx), "POST", c.BuildURL(customerServices), nil, payload)
+	return err
+}
+
 /*
  * SetCache stores an Backupify response in the cache
  */
@@ -90,7 +144,8 @@ func (c *Client) GetCache(key string, target interface{}) bool {
 
 /*
   - # Generate Backupify Client
-  - @param logger *log.Logger
+  - @param verbosity int
+  - @param opts ...options.Option
   - @return *Client
   - Example:
 
@@ -100,17 +155,28 @@ func (c *Client) GetCache(key string, target interface{}) bool {
 
 ```
 */
-func NewClient(verbosity int) *Client {
-	log := log.NewLogger("{backupify}", verbosity)
+func NewClient(verbosity int, opts ...options.Option) *Client {
+	o := options.New(opts...)
+	log := o.Log("{backupify}", verbosity)
 
-	nodeURL := config.GetEnv("BACKUPIFY_NODE_URL")
-	if len(nodeURL) == 0 {
-		log.Fatal("BACKUPIFY_NODE_URL is not set")
+	v, err := version.Pin(o.APIVersion, DefaultAPIVersion, APIVersions...)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	customerID := config.GetEnv("BACKUPIFY_CUSTOMER_ID")
-	if len(customerID) == 0 {
-		log.Fatal("BACKUPIFY_CUSTOMER_ID is not set")
+	url := o.BaseURL
+	if url == "" {
+		nodeURL := config.GetEnv("BACKUPIFY_NODE_URL")
+		if len(nodeURL) == 0 {
+			log.Fatal("BACKUPIFY_NODE_URL is not set")
+		}
+
+		customerID := config.GetEnv("BACKUPIFY_CUSTOMER_ID")
+		if len(customerID) == 0 {
+			log.Fatal("BACKUPIFY_CUSTOMER_ID is not set")
+		}
+
+		url = fmt.Sprintf(backupifyBaseURL, nodeURL, customerID)
 	}
 
 	token := config.GetEnv("BACKUPIFY_EXPORT_TOKEN")
@@ -123,24 +189,18 @@ func NewClient(verbosity int) *Client {
 		log.Fatal("BACKUPIFY_PHPSESSID is not set")
 	}
 
-	url := fmt.Sprintf(backupifyBaseURL, nodeURL, customerID)
-
 	headers := requests.Headers{
 		"Cookie":           "PHPSESSID=" + phpSessID,
 		"Accept":           requests.All,
 		"X-Requested-With": "XMLHttpRequest",
 	}
-	httpClient := requests.NewClient(nil, headers, nil)
+	httpClient := requests.NewClient(o.HTTPClient, headers, o.RateLimiter, requests.WithCache(o.Cache), requests.WithUserAgent("backupify", o.UserAgent), requests.WithRetry(o.Retry), requests.WithCircuitBreaker(o.Breaker), requests.WithRateLimitBuckets(o.RateLimits))
 	httpClient.BodyType = requests.FormURLEncoded
+	httpClient.Reauthenticate = renewSession
 
-	encryptionKey := []byte(config.GetEnv("REGO_ENCRYPTION_KEY"))
-	if len(encryptionKey) == 0 {
-		log.Fatal("REGO_ENCRYPTION_KEY is not set")
-	}
-
-	cache, err := cache.NewCache(encryptionKey, "rego_cache_backupify.gob", 1000000)
-	if err != nil {
-		panic(err)
+	cache := o.Cache
+	if cache == nil {
+		cache = newCache(log, o.CacheBackend)
 	}
 
 	return &Client{
@@ -148,27 +208,45 @@ func NewClient(verbosity int) *Client {
 		HTTP:        httpClient,
 		Log:         log,
 		Cache:       cache,
+		Version:     v,
 		exportToken: token,
 	}
 }
 
 /*
- * Perform a generic request to the Backupify WebUI
+ * Sends the session of `BACKUPIFY_PHPSESSID` once it has been renewed in the environment
+ * - The WebUI signs in through SSO, so a new session cannot be opened by the client itself
  */
-func do[T any](c *Client, method string, url string, query interface{}, data interface{}) (T, error) {
-	var result T
-	res, body, err := c.HTTP.DoRequest(method, url, query, data)
-	if err != nil {
-		return *new(T), err
+func renewSession(c *requests.Client) error {
+	session := config.GetEnv("BACKUPIFY_PHPSESSID")
+	if session == "" || c.Headers["Cookie"] == "PHPSESSID="+session {
+		return errors.New("the session expired, and BACKUPIFY_PHPSESSID holds no other")
 	}
+	c.Headers["Cookie"] = "PHPSESSID=" + session
+	return nil
+}
 
-	c.Log.Println("Response Status:", res.Status)
-	c.Log.Debug("Response Body:", string(body))
+// newCache opens the encrypted cache of the client, with `REGO_ENCRYPTION_KEY`, storing its items in `backend` when set
+func newCache(log *log.Logger, backend cache.Backend) *cache.Cache {
+	encryptionKey := []byte(config.GetEnv("REGO_ENCRYPTION_KEY"))
+	if len(encryptionKey) == 0 {
+		log.Fatal("REGO_ENCRYPTION_KEY is not set")
+	}
 
-	err = json.Unmarshal(body, &result)
+	c, err := cache.NewCache(encryptionKey, "rego_cache_backupify.gob", 1000000, backend)
 	if err != nil {
-		return *new(T), fmt.Errorf("unmarshalling error: %w", err)
+		panic(err)
 	}
+	return c
+}
 
+/*
+ * Perform a generic request to the Backupify WebUI
+ */
+func do[T any](c *Client, method string, url string, query interface{}, data interface{}) (T, error) {
+	result, err := requests.Do[T](c.HTTP, method, url, query, data)
+	if err != nil {
+		return *new(T), rerrors.WithProvider(err, "backupify")
+	}
 	return result, nil
 }
@@ -0,0 +1,183 @@
+// Code generated by mockgen from interfaces.go; DO NOT EDIT.
+
+// pkg/backupify/backupifymock/mocks.go
+package backupifymock
+
+import (
+	"sync"
+
+	"github.com/gemini-oss/rego/pkg/backupify"
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/pipeline"
+)
+
+// Call is a call received by a mock
+type Call struct {
+	Method string        // Name of the method called
+	Args   []interface{} // Arguments of the call
+}
+
+// calls records the calls received by a mock
+type calls struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+func (c *calls) record(method string, args ...interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls received, in order; only those of `method` when set
+func (c *calls) Calls(method ...string) []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	received := []Call{}
+	for _, call := range c.calls {
+		if len(method) == 0 || call.Method == method[0] {
+			received = append(received, call)
+		}
+	}
+	return received
+}
+
+// ActivityService is a mock of `backupify.ActivityService`; methods without a func return zero values
+type ActivityService struct {
+	GetActivitiesFunc func(backupify.AppType) (*backupify.Activities, error)
+
+	calls
+}
+
+var _ backupify.ActivityService = (*ActivityService)(nil)
+
+func (m *ActivityService) GetActivities(a0 backupify.AppType) (r0 *backupify.Activities, r1 error) {
+	m.record("GetActivities", a0)
+	if m.GetActivitiesFunc != nil {
+		return m.GetActivitiesFunc(a0)
+	}
+	return
+}
+
+// ExportService is a mock of `backupify.ExportService`; methods without a func return zero values
+type ExportService struct {
+	ExportUsersFunc              func(*backupify.Users) error
+	ExportUserFunc               func(*backupify.User) (*backupify.Exports, error)
+	DownloadAvailableExportsFunc func(*backupify.Activities) [][]string
+	DownloadExportFunc           func(*backupify.Item, *backupify.Export) ([]string, error)
+	DeleteExportFunc             func(*backupify.Item, *backupify.Export) error
+	ExportSourceFunc             func(*backupify.Item, *backupify.Export) *pipeline.Source
+
+	calls
+}
+
+var _ backupify.ExportService = (*ExportService)(nil)
+
+func (m *ExportService) ExportUsers(a0 *backupify.Users) (r0 error) {
+	m.record("ExportUsers", a0)
+	if m.ExportUsersFunc != nil {
+		return m.ExportUsersFunc(a0)
+	}
+	return
+}
+
+func (m *ExportService) ExportUser(a0 *backupify.User) (r0 *backupify.Exports, r1 error) {
+	m.record("ExportUser", a0)
+	if m.ExportUserFunc != nil {
+		return m.ExportUserFunc(a0)
+	}
+	return
+}
+
+func (m *ExportService) DownloadAvailableExports(a0 *backupify.Activities) (r0 [][]string) {
+	m.record("DownloadAvailableExports", a0)
+	if m.DownloadAvailableExportsFunc != nil {
+		return m.DownloadAvailableExportsFunc(a0)
+	}
+	return
+}
+
+func (m *ExportService) DownloadExport(a0 *backupify.Item, a1 *backupify.Export) (r0 []string, r1 error) {
+	m.record("DownloadExport", a0, a1)
+	if m.DownloadExportFunc != nil {
+		return m.DownloadExportFunc(a0, a1)
+	}
+	return
+}
+
+func (m *ExportService) DeleteExport(a0 *backupify.Item, a1 *backupify.Export) (r0 error) {
+	m.record("DeleteExport", a0, a1)
+	if m.DeleteExportFunc != nil {
+		return m.DeleteExportFunc(a0, a1)
+	}
+	return
+}
+
+func (m *ExportService) ExportSource(a0 *backupify.Item, a1 *backupify.Export) (r0 *pipeline.Source) {
+	m.record("ExportSource", a0, a1)
+	if m.ExportSourceFunc != nil {
+		return m.ExportSourceFunc(a0, a1)
+	}
+	return
+}
+
+// SnapshotService is a mock of `backupify.SnapshotService`; methods without a func return zero values
+type SnapshotService struct {
+	GetSnapshotDatesFunc func(backupify.AppType, *backupify.User) (*backupify.Snapshots, error)
+
+	calls
+}
+
+var _ backupify.SnapshotService = (*SnapshotService)(nil)
+
+func (m *SnapshotService) GetSnapshotDates(a0 backupify.AppType, a1 *backupify.User) (r0 *backupify.Snapshots, r1 error) {
+	m.record("GetSnapshotDates", a0, a1)
+	if m.GetSnapshotDatesFunc != nil {
+		return m.GetSnapshotDatesFunc(a0, a1)
+	}
+	return
+}
+
+// UserService is a mock of `backupify.UserService`; methods without a func return zero values
+type UserService struct {
+	GetAllUsersFunc       func(backupify.AppType) (*backupify.Users, error)
+	IterUsersFunc         func(backupify.AppType) iterator.Seq[*backupify.User]
+	GetUserByEmailFunc    func(backupify.AppType, string) (*backupify.User, error)
+	UserStorageReportFunc func(*backupify.Users) map[string]backupify.UserCounts
+
+	calls
+}
+
+var _ backupify.UserService = (*UserService)(nil)
+
+func (m *UserService) GetAllUsers(a0 backupify.AppType) (r0 *backupify.Users, r1 error) {
+	m.record("GetAllUsers", a0)
+	if m.GetAllUsersFunc != nil {
+		return m.GetAllUsersFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) IterUsers(a0 backupify.AppType) (r0 iterator.Seq[*backupify.User]) {
+	m.record("IterUsers", a0)
+	if m.IterUsersFunc != nil {
+		return m.IterUsersFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) GetUserByEmail(a0 backupify.AppType, a1 string) (r0 *backupify.User, r1 error) {
+	m.record("GetUserByEmail", a0, a1)
+	if m.GetUserByEmailFunc != nil {
+		return m.GetUserByEmailFunc(a0, a1)
+	}
+	return
+}
+
+func (m *UserService) UserStorageReport(a0 *backupify.Users) (r0 map[string]backupify.UserCounts) {
+	m.record("UserStorageReport", a0)
+	if m.UserStorageReportFunc != nil {
+		return m.UserStorageReportFunc(a0)
+	}
+	return
+}
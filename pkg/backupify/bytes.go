@@ -0,0 +1,162 @@
+/*
+# Backupify - Bytes
+
+This package parses the storage sizes displayed by Datto's Backupify WebUI, e.g. `1.5 GB`
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/backupify/bytes.go
+package backupify
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a storage size in bytes
+type ByteSize float64
+
+// ByteUnits selects whether `KB`, `MB`, ... are powers of 1000 or 1024
+type ByteUnits int
+
+const (
+	Decimal ByteUnits = iota // 1 KB = 1000 bytes (SI)
+	Binary                   // 1 KB = 1024 bytes (IEC `KiB`, as displayed by some tools)
+)
+
+// ErrInvalidSize is returned for sizes that are not a number followed by an optional unit
+var ErrInvalidSize = errors.New("backupify: invalid size")
+
+/*
+ * # Parse Byte Size
+ * Parses a size such as `512 bytes`, `1.5 GB` or `2TB` into bytes
+ * - `KB`, `MB`, `GB`, `TB` and `PB` are scaled by `units`; `KiB`, `MiB`, ... are always binary
+ * - A size without a unit is in bytes
+ * - Commas are thousands separators and must group the digits in threes, e.g. `1,024`; `1,5 GB` is rejected, not read as 15 GB
+ * - Parsing a valid size does not allocate, so it can run over every user of large tenants
+ */
+func ParseByteSize(s string, units ByteUnits) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+
+	// Split the number from its unit, with or without a space between them
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.' || s[i] == ',' || (i == 0 && (s[i] == '-' || s[i] == '+'))) {
+		i++
+	}
+	number, unit := s[:i], strings.TrimSpace(s[i:])
+
+	value, ok := parseNumber(number)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSize, s)
+	}
+	multiplier, ok := unitMultiplier(unit, units)
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown unit %q", ErrInvalidSize, unit)
+	}
+	return ByteSize(value * multiplier), nil
+}
+
+// parseNumber parses a number whose whole part may be grouped in threes by commas, e.g. `1,024.5`
+func parseNumber(number string) (float64, bool) {
+	if strings.IndexByte(number, ',') < 0 {
+		value, err := strconv.ParseFloat(number, 64)
+		return value, err == nil
+	}
+
+	sign := 1.0
+	switch {
+	case strings.HasPrefix(number, "-"):
+		sign, number = -1, number[1:]
+	case strings.HasPrefix(number, "+"):
+		number = number[1:]
+	}
+	whole, fraction := number, ""
+	if i := strings.IndexByte(number, '.'); i >= 0 {
+		whole, fraction = number[:i], number[i:]
+	}
+
+	// The digits are accumulated rather than copied without the commas, so parsing does not allocate
+	var value float64
+	group, rest, more := strings.Cut(whole, ",")
+	if len(group) == 0 || len(group) > 3 {
+		return 0, false
+	}
+	for {
+		for i := 0; i < len(group); i++ {
+			if group[i] < '0' || group[i] > '9' {
+				return 0, false
+			}
+			value = value*10 + float64(group[i]-'0')
+		}
+		if !more {
+			break
+		}
+		group, rest, more = strings.Cut(rest, ",")
+		if len(group) != 3 {
+			return 0, false
+		}
+	}
+
+	if fraction != "" {
+		f, err := strconv.ParseFloat(fraction, 64)
+		if err != nil {
+			return 0, false
+		}
+		value += f
+	}
+	return sign * value, true
+}
+
+// unitMultiplier returns the number of bytes of a unit
+func unitMultiplier(unit string, units ByteUnits) (float64, bool) {
+	base := 1000.0
+	if units == Binary {
+		base = 1024
+	}
+
+	switch {
+	case unit == "" || strings.EqualFold(unit, "b") || strings.EqualFold(unit, "byte") || strings.EqualFold(unit, "bytes"):
+		return 1, true
+	case len(unit) == 2 && (unit[1] == 'B' || unit[1] == 'b'):
+	case len(unit) == 3 && (unit[1] == 'i' || unit[1] == 'I') && (unit[2] == 'B' || unit[2] == 'b'):
+		base = 1024 // IEC units are binary whatever the selection, e.g. `GiB`
+	default:
+		return 0, false
+	}
+
+	// Scale by the prefix, e.g. the `G` of `GB`
+	multiplier := 1.0
+	for _, prefix := range "kmgtp" {
+		multiplier *= base
+		if unit[0]|0x20 == byte(prefix) {
+			return multiplier, true
+		}
+	}
+	return 0, false
+}
+
+/*
+ * # Convert Used Bytes
+ * Sets `UsedBytesFloat` of each user from the size displayed in `UsedBytes`
+ * - Users whose size does not parse are left at zero, and reported in the returned error
+ */
+func ConvertUsedBytes(users *Users, units ByteUnits) error {
+	var errs []error
+	for _, user := range users.Data {
+		if user.UsedBytes == "" {
+			continue
+		}
+		size, err := ParseByteSize(user.UsedBytes, units)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", user.Email, err))
+			continue
+		}
+		user.UsedBytesFloat = float64(size)
+	}
+	return errors.Join(errs...)
+}
@@ -13,22 +13,35 @@ package backupify
 
 import (
 	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/enum"
 	"github.com/gemini-oss/rego/pkg/common/log"
 	"github.com/gemini-oss/rego/pkg/common/requests"
 )
 
 // ### Backupify Client Structs
 // ---------------------------------------------------------------------
+// Client is safe for concurrent use by multiple goroutines; derive per-call settings with `WithContext`
 type Client struct {
 	BaseURL     string           // BaseURL is the base URL for Backupify requests.
 	HTTP        *requests.Client // HTTPClient is the client used to make HTTP requests.
 	Error       string           // Error is the error message returned from the Backupify WebUI.
 	Log         *log.Logger      // Log is the logger used to log messages.
 	Cache       *cache.Cache     // Cache is the cache used to store responses from the Backupify WebUI.
+	Version     APIVersion       // Version is the version of the Backupify WebUI the client is pinned to.
 	exportToken string           // exportToken is the token used to export data from Backupify.
 }
 
 type AppType string // AppType is the type of Backupify application.
+
+func (t AppType) MarshalText() ([]byte, error)     { return enum.Marshal(t, AppTypes...) }
+func (t *AppType) UnmarshalText(text []byte) error { return enum.Unmarshal(t, text, AppTypes...) }
+
+// APIVersion is the version of the Backupify WebUI; its URLs are not versioned, so each version names the endpoints the client was verified against
+type APIVersion string
+
+func (v APIVersion) MarshalText() ([]byte, error)     { return enum.Marshal(v, APIVersions...) }
+func (v *APIVersion) UnmarshalText(text []byte) error { return enum.Unmarshal(v, text, APIVersions...) }
+
 // END OF BACKUPIFY CLIENT STRUCTS
 //----------------------------------------------------------------------
 
@@ -69,7 +82,7 @@ type Item struct {
 
 type Run struct {
 	ActionType            string      `json:"actionType,omitempty"`            // Type of action, e.g., Export or Restore
-	AppType               string      `json:"appType,omitempty"`               // Application type involved
+	AppType               AppType     `json:"appType,omitempty"`               // Application type involved
 	CompletedAt           int64       `json:"completedAt,omitempty"`           // Completion timestamp
 	CreatedAt             int64       `json:"createdAt,omitempty"`             // Creation timestamp
 	CustomerId            int         `json:"customerId,omitempty"`            // ID of the customer
@@ -125,11 +138,11 @@ type Export struct {
 }
 
 type ResponseData struct {
-	Action     string `json:"action,omitempty"`     // Action taken, e.g., "Export"
-	AppType    string `json:"appType,omitempty"`    // Type of application involved, e.g., "GoogleDrive"
-	CustomerId int    `json:"customerId,omitempty"` // Numeric ID of the customer
-	ID         int    `json:"id,omitempty"`         // Numeric ID associated with the responseData
-	Status     string `json:"status,omitempty"`     // Current status, e.g., "started"
+	Action     string  `json:"action,omitempty"`     // Action taken, e.g., "Export"
+	AppType    AppType `json:"appType,omitempty"`    // Type of application involved, e.g., "GoogleDrive"
+	CustomerId int     `json:"customerId,omitempty"` // Numeric ID of the customer
+	ID         int     `json:"id,omitempty"`         // Numeric ID associated with the responseData
+	Status     string  `json:"status,omitempty"`     // Current status, e.g., "started"
 }
 
 type ExportPayload struct {
@@ -163,7 +176,7 @@ func (u *Users) Map() map[string]*User {
 }
 
 type User struct {
-	AppType        string      `json:"appType,omitempty"`        // Type of the application
+	AppType        AppType     `json:"appType,omitempty"`        // Type of the application
 	CreatedAt      int64       `json:"createdAt,omitempty"`      // Creation timestamp
 	CustomerId     int         `json:"customerId,omitempty"`     // ID of the customer
 	Deleted        bool        `json:"deleted,omitempty"`        // Deletion flag
@@ -15,14 +15,19 @@ https://{node-url}.backupify.com/{customerID}/{service}/export
 package backupify
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/gemini-oss/rego/pkg/common/pipeline"
+	"github.com/gemini-oss/rego/pkg/common/pool"
 	"github.com/gemini-oss/rego/pkg/common/requests"
+	ss "github.com/gemini-oss/rego/pkg/common/starstruct"
 )
 
 // ExportClient for chaining methods
@@ -38,17 +43,17 @@ func (c *Client) Exports() *ExportClient {
 }
 
 type ExportQuery struct {
-	Type    string `json:"type"`    // Type of query. e.g. 'export'
-	AppType string `json:"appType"` // Type of application. e.g. 'GoogleDrive'
-	ID      int    `json:"id"`      // ID of the export
-	EXT     string `json:"ext"`     // Extension of the file. e.g. 'zip'
+	Type    string  `url:"type"`    // Type of query. e.g. 'export'
+	AppType AppType `url:"appType"` // Type of application. e.g. 'GoogleDrive'
+	ID      int     `url:"id"`      // ID of the export
+	EXT     string  `url:"ext"`     // Extension of the file. e.g. 'zip'
 }
 
 func (c *ExportClient) ExportUsers(users *Users) error {
 	for _, user := range users.Data {
 		_, err := c.ExportUser(user)
 		if err != nil {
-			c.Log.Fatal(err)
+			return err
 		}
 	}
 	return nil
@@ -62,7 +67,7 @@ func (c *ExportClient) ExportUser(user *User) (*Exports, error) {
 	for _, snapshot := range user.Snapshots {
 		export, err := c.generateExport(c.exportToken, user.ID, snapshot.ID)
 		if err != nil {
-			c.Log.Fatal(err)
+			return nil, err
 		}
 		exports = append(exports, export)
 	}
@@ -83,10 +88,10 @@ func (c *ExportClient) generateExport(token string, userID int, snapshotID int64
 		Services:           []interface{}{userID},
 	}
 
-	c.HTTP.Headers["Accept"] = requests.All
+	c.HTTP.SetHeader("Accept", requests.All)
 	export, err := do[Export](c.Client, "POST", url, nil, exportPayload)
 	if err != nil {
-		c.Log.Fatal(err)
+		return nil, err
 	}
 
 	c.Log.Println("Export started: ", export.ResponseData.ID)
@@ -98,7 +103,7 @@ func (c *Client) CheckExportFilters(activities *Activities) {
 		switch filters := activity.Run.Description.Filters.(type) {
 		case map[string]interface{}:
 			if isDeleted, ok := filters["isDeleted"].(string); ok {
-				fmt.Printf("Activity %s has filter isDeleted with value: %s\n", activity.Status, isDeleted)
+				c.Log.Debugf("Activity %s has filter isDeleted with value: %s", activity.Status, isDeleted)
 			}
 		case []interface{}:
 		default:
@@ -113,58 +118,60 @@ func (c *ExportClient) DownloadAvailableExports(activities *Activities) [][]stri
 	var exportReports = make([][]string, 1, len(activities.Export.Items)+1)
 	exportReports[0] = []string{"Service Email", "Snapshot ID", "URL", "Download Path", "File Name", "Export ID", "Downloaded At"}
 
-	// Buffered channel to limit concurrent downloads
-	sem := make(chan struct{}, 5)
-
+	// Downloads stop at the first failure
 	var mu sync.Mutex
-	var wg sync.WaitGroup
-	for _, activity := range activities.Export.Items {
-		wg.Add(1)
-		go func(activity *Item) {
-			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			if activity.Status == "completed" && activity.Export.Status == "Download" {
-				export := &Export{
-					ResponseData: ResponseData{
-						AppType: activity.Run.AppType,
-						ID:      activity.Run.ID,
-					},
-				}
-				report, err := c.DownloadExport(activity, export)
-				if err != nil {
-					c.Log.Fatal(err)
-				}
-				mu.Lock()
-				exportReports = append(exportReports, report)
-				mu.Unlock()
-			} else if activity.Status == "in progress" {
-				c.Log.Println("Activity is in progress. Skipping...")
+	err := pool.Each(c.HTTP.Context(), activities.Export.Items, pool.Options{Workers: 5, StopOnError: true}, func(_ context.Context, activity *Item) error {
+		if activity.Status == "completed" && activity.Export.Status == "Download" {
+			export := &Export{
+				ResponseData: ResponseData{
+					AppType: activity.Run.AppType,
+					ID:      activity.Run.ID,
+				},
+			}
+			report, err := c.DownloadExport(activity, export)
+			if err != nil {
+				return err
 			}
-		}(activity)
+			mu.Lock()
+			exportReports = append(exportReports, report)
+			mu.Unlock()
+		} else if activity.Status == "in progress" {
+			c.Log.Println("Activity is in progress. Skipping...")
+		}
+		return nil
+	})
+	if err != nil {
+		c.Log.Error(err)
 	}
-	wg.Wait()
 
 	return exportReports
 }
 
-func (c *ExportClient) DownloadExport(activity *Item, export *Export) ([]string, error) {
-	url := c.BuildURL(download)
-	query := ExportQuery{
+// exportURL returns the URL the zip of a completed export is downloaded from
+func (c *ExportClient) exportURL(export *Export) (string, error) {
+	query, err := ss.QueryValues(ExportQuery{
 		Type:    "export",
 		AppType: export.ResponseData.AppType,
 		ID:      export.ResponseData.ID,
 		EXT:     "zip",
+	})
+	if err != nil {
+		return "", err
 	}
+	return c.BuildURL(download) + "?" + query.Encode(), nil
+}
 
-	url = fmt.Sprintf("%s?type=%s&appType=%s&id=%d&ext=%s", url, query.Type, query.AppType, query.ID, query.EXT)
+func (c *ExportClient) DownloadExport(activity *Item, export *Export) ([]string, error) {
+	url, err := c.exportURL(export)
+	if err != nil {
+		return nil, err
+	}
 	c.Log.Println("Downloading Export for: ", activity.Run.Description.Services[0].ServiceEmail, "Snapshot ID: ", activity.Run.Description.Snapshot, "Export ID: ", export.ResponseData.ID)
 	c.Log.Debug(url)
 
 	pwd, err := os.Getwd()
 	if err != nil {
-		c.Log.Fatal(err)
+		return nil, err
 	}
 
 	downloadPath := filepath.Join(pwd, fmt.Sprintf(
@@ -179,12 +186,12 @@ func (c *ExportClient) DownloadExport(activity *Item, export *Export) ([]string,
 		activity.Run.AppType,
 		activity.Run.Description.Snapshot,
 		export.ResponseData.ID,
-		query.EXT,
+		"zip",
 	)
 
 	err = c.HTTP.DownloadFile(url, downloadPath, fileName, false)
 	if err != nil {
-		c.Log.Fatal(err)
+		return nil, err
 	}
 
 	downloadReport := []string{
@@ -204,14 +211,52 @@ func (c *ExportClient) DeleteExport(activity *Item, export *Export) error {
 
 	deleteQuery := DeletePayload{
 		Type:    "export",
-		AppType: AppType(export.ResponseData.AppType),
+		AppType: export.ResponseData.AppType,
 		ID:      export.ResponseData.ID,
 	}
 
 	_, err := do[Export](c.Client, "POST", url, deleteQuery, nil)
 	if err != nil {
-		c.Log.Fatal(err)
+		return err
 	}
 
 	return nil
 }
+
+/*
+ * # Export Source
+ * Streams a completed export into object storage with `pipeline.Pipeline`, instead of downloading it to disk
+ * - The export is keyed like `DownloadExport` lays it out on disk: `backupify/<appType>/<serviceEmail>/<file>.zip`
+ */
+func (c *ExportClient) ExportSource(activity *Item, export *Export) *pipeline.Source {
+	email := activity.Run.Description.Services[0].ServiceEmail
+
+	src := &pipeline.Source{
+		Key: fmt.Sprintf(
+			"backupify/%s/%s/%s-%s-snap_%d-exp_%d.zip",
+			activity.Run.AppType,
+			email,
+			strings.Split(email, "@")[0],
+			activity.Run.AppType,
+			activity.Run.Description.Snapshot,
+			export.ResponseData.ID,
+		),
+		ContentType: requests.ZIP,
+	}
+	src.Open = func(ctx context.Context) (io.ReadCloser, error) {
+		url, err := c.exportURL(export)
+		if err != nil {
+			return nil, err
+		}
+		c.Log.Println("Streaming Export for: ", email, "Snapshot ID: ", activity.Run.Description.Snapshot, "Export ID: ", export.ResponseData.ID)
+		resp, err := c.HTTP.OpenDownload(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		if resp.ContentLength > 0 {
+			src.Size = resp.ContentLength
+		}
+		return resp.Body, nil
+	}
+	return src
+}
@@ -0,0 +1,55 @@
+/*
+# Backupify Interfaces
+
+This package contains the interfaces of each Backupify resource, which the resource clients satisfy. Code depending on an
+interface rather than the clients can be unit tested with the mocks of `pkg/backupify/backupifymock`
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/backupify/interfaces.go
+package backupify
+
+import (
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/pipeline"
+)
+
+//go:generate go run ../../cmd/mockgen
+
+// ActivityService lists the activities (exports and restores) of an application
+type ActivityService interface {
+	GetActivities(appType AppType) (*Activities, error)
+}
+
+// ExportService starts, downloads and deletes exports
+type ExportService interface {
+	ExportUsers(users *Users) error
+	ExportUser(user *User) (*Exports, error)
+	DownloadAvailableExports(activities *Activities) [][]string
+	DownloadExport(activity *Item, export *Export) ([]string, error)
+	DeleteExport(activity *Item, export *Export) error
+	ExportSource(activity *Item, export *Export) *pipeline.Source
+}
+
+// SnapshotService lists the snapshots of users
+type SnapshotService interface {
+	GetSnapshotDates(appType AppType, user *User) (*Snapshots, error)
+}
+
+// UserService lists protected users
+type UserService interface {
+	GetAllUsers(appType AppType) (*Users, error)
+	IterUsers(appType AppType) iterator.Seq[*User]
+	GetUserByEmail(appType AppType, email string) (*User, error)
+	UserStorageReport(users *Users) map[string]UserCounts
+}
+
+var (
+	_ ActivityService = (*ActivityClient)(nil)
+	_ ExportService   = (*ExportClient)(nil)
+	_ SnapshotService = (*SnapshotClient)(nil)
+	_ UserService     = (*UserClient)(nil)
+)
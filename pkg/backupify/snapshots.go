@@ -52,7 +52,7 @@ func (c *SnapshotClient) GetSnapshotDates(appType AppType, user *User) (*Snapsho
 
 	snapshots, err := do[Snapshots](c.Client, "POST", url, nil, snapshotsPayload)
 	if err != nil {
-		c.Log.Fatal(err)
+		return nil, err
 	}
 
 	c.SetCache(cacheKey, snapshots, 24*time.Hour)
@@ -16,8 +16,9 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/iterator"
 )
 
 // UserClient for chaining methods
@@ -43,7 +44,61 @@ func (c *UserClient) GetAllUsers(appType AppType) (*Users, error) {
 		return &cache, nil
 	}
 
-	userPayload := UserPayload{
+	var allUsers Users
+	users, err := iterator.Collect(c.iterUsers(appType, &allUsers))
+	if err != nil {
+		return nil, err
+	}
+	allUsers.Data = users
+
+	c.SetCache(cache_key, allUsers, 6*time.Hour)
+	return &allUsers, nil
+}
+
+// IterUsers() lazily iterates all users from Backupify, requesting each page as the previous one is consumed.
+func (c *UserClient) IterUsers(appType AppType) iterator.Seq[*User] {
+	return c.iterUsers(appType, nil)
+}
+
+// iterUsers() pages through the users with the offset of the DataTables payload, recording the totals of each page in `totals`.
+func (c *UserClient) iterUsers(appType AppType, totals *Users) iterator.Seq[*User] {
+	url := c.BuildURL(customerServices)
+
+	return iterator.Pages(func(cursor string) ([]*User, string, error) {
+		userPayload := usersPayload(appType)
+		if cursor != "" {
+			start, err := strconv.Atoi(cursor)
+			if err != nil {
+				return nil, "", err
+			}
+			userPayload.Start = start
+		}
+
+		c.Log.Printf("Getting users %d-%d from Backupify %s...", userPayload.Start, userPayload.Start+userPayload.Length-1, appType)
+		users, err := do[Users](c.Client, "POST", url, nil, userPayload)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := ConvertUsedBytes(&users, Decimal); err != nil {
+			c.Log.Warning(err)
+		}
+		if totals != nil {
+			totals.Draw = users.Draw
+			totals.RecordsTotal = users.RecordsTotal
+			totals.RecordsFiltered = users.RecordsFiltered
+		}
+
+		next := userPayload.Start + len(users.Data)
+		if len(users.Data) == 0 || next >= users.RecordsTotal {
+			return users.Data, "", nil
+		}
+		return users.Data, strconv.Itoa(next), nil
+	})
+}
+
+// usersPayload() returns the DataTables payload of the first page of users.
+func usersPayload(appType AppType) UserPayload {
+	return UserPayload{
 		Draw: "1",
 		Columns: []Column{
 			{
@@ -101,33 +156,22 @@ func (c *UserClient) GetAllUsers(appType AppType) (*Users, error) {
 		},
 		AppType: appType,
 	}
+}
 
-	var allUsers Users
-	for {
-		c.Log.Printf("Getting users %d-%d from Backupify %s...", userPayload.Start, userPayload.Start+userPayload.Length-1, appType)
-		users, err := do[Users](c.Client, "POST", url, nil, userPayload)
-		if err != nil {
-			c.Log.Fatal(err)
-		}
+// GetUserByEmail() retrieves a single Backupify user by email address.
+func (c *UserClient) GetUserByEmail(appType AppType, email string) (*User, error) {
+	users, err := c.GetAllUsers(appType)
+	if err != nil {
+		return nil, err
+	}
 
-		remainingUsers := users.RecordsTotal - userPayload.Length
-		if remainingUsers < userPayload.Length {
-			userPayload.Length = remainingUsers
-		}
-		if userPayload.Start <= users.RecordsTotal {
-			userPayload.Start += userPayload.Length
-		} else {
-			allUsers.Draw = users.Draw
-			allUsers.RecordsTotal = users.RecordsTotal
-			allUsers.RecordsFiltered = users.RecordsFiltered
-			break
+	for _, user := range users.Data {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
 		}
-		allUsers.Data = append(allUsers.Data, users.Data...)
 	}
-	c.convertUserBytes(&allUsers, false)
 
-	c.SetCache(cache_key, allUsers, 6*time.Hour)
-	return &allUsers, nil
+	return nil, fmt.Errorf("backupify %s user with email %s not found", appType, email)
 }
 
 // Initialize a map to count users and sum storage by the first letter of their email
@@ -145,51 +189,6 @@ func (c *UserClient) UserStorageReport(users *Users) map[string]UserCounts {
 	return userCountsByLetter
 }
 
-func (c *UserClient) convertUserBytes(users *Users, useBinary bool) {
-	var wg sync.WaitGroup
-	var kilobyte float64
-	if !useBinary {
-		kilobyte = 1000 // Decimal unit (powers of 1000)
-	}
-
-	// Cascading definitions properly reflect the choice of kilobyte
-	megabyte = kilobyte * kilobyte
-	gigabyte = megabyte * kilobyte
-	terabyte = gigabyte * kilobyte
-
-	for _, user := range users.Data {
-		wg.Add(1)
-		go func(user *User) {
-			defer wg.Done()
-
-			// Extract the numeric part from the string (before the first space)
-			usedBytes, err := strconv.ParseFloat(user.UsedBytes[:strings.Index(user.UsedBytes, " ")], 64)
-			if err != nil {
-				fmt.Printf("Error converting used bytes for user %s: %v\n", user.Name, err)
-				return
-			}
-
-			// Calculate the UsedBytesFloat based on the unit found and the predefined variables
-			switch {
-			case strings.Contains(user.UsedBytes, "bytes"):
-				user.UsedBytesFloat = usedBytes
-			case strings.Contains(user.UsedBytes, "KB"):
-				user.UsedBytesFloat = usedBytes * kilobyte
-			case strings.Contains(user.UsedBytes, "MB"):
-				user.UsedBytesFloat = usedBytes * megabyte
-			case strings.Contains(user.UsedBytes, "GB"):
-				user.UsedBytesFloat = usedBytes * gigabyte
-			case strings.Contains(user.UsedBytes, "TB"):
-				user.UsedBytesFloat = usedBytes * terabyte
-			}
-
-			fmt.Printf("Converted %s to %.2f bytes for user %s\n", user.UsedBytes, user.UsedBytesFloat, user.Name)
-		}(user)
-	}
-
-	wg.Wait()
-}
-
 func (c *UserClient) filterUsersBySize(users *Users, size float64) *Users {
 	var filteredUsers Users
 	for _, user := range users.Data {
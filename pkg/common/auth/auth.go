@@ -12,6 +12,9 @@ This package initializes methods for functions which need special authentication
 package auth
 
 import (
+	"sync"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/jwt"
 )
@@ -19,3 +22,62 @@ import (
 type JWTConfig *jwt.Config
 
 type OAuthConfig *oauth2.Config
+
+/*
+ * # Renewable Token Source
+ * Reuses a token until it expires, like `oauth2.ReuseTokenSource`, and mints a new one on `Renew`
+ * - Tokens can be revoked or rotated before they expire; renewing replaces a token which an API rejected
+ */
+type RenewableTokenSource struct {
+	mutex sync.Mutex
+	token *oauth2.Token
+	mint  func() (*oauth2.Token, error)
+}
+
+// NewRenewableTokenSource returns a source which starts with `token` (which may be nil) and mints tokens with `mint`
+func NewRenewableTokenSource(token *oauth2.Token, mint func() (*oauth2.Token, error)) *RenewableTokenSource {
+	return &RenewableTokenSource{token: token, mint: mint}
+}
+
+// Token returns the current token, minting a new one when it has expired
+func (s *RenewableTokenSource) Token() (*oauth2.Token, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.token.Valid() {
+		return s.token, nil
+	}
+	return s.renew()
+}
+
+// Renew mints a new token, even if the current one has not expired
+func (s *RenewableTokenSource) Renew() (*oauth2.Token, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.renew()
+}
+
+func (s *RenewableTokenSource) renew() (*oauth2.Token, error) {
+	token, err := s.mint()
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+/*
+ * # Reauthenticate
+ * Renews the token, and sends it as the bearer token of `c`; set it as the client's `Reauthenticate` hook:
+ *
+ *	hc.Reauthenticate = source.Reauthenticate
+ */
+func (s *RenewableTokenSource) Reauthenticate(c *requests.Client) error {
+	token, err := s.Renew()
+	if err != nil {
+		return err
+	}
+	if _, ok := c.Headers["Authorization"]; ok {
+		c.Headers["Authorization"] = token.Type() + " " + token.AccessToken
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+/*
+# Breaker
+
+This package implements circuit breakers, which stop sending requests to a host once it keeps failing, so a degraded
+API (e.g. Okta or Google during an incident) is given time to recover instead of being hammered by retries, and rate
+limits are not burned on requests which are bound to fail:
+
+```go
+
+	b := breaker.New(5, 30*time.Second)
+	c := requests.NewClient(nil, headers, nil, requests.WithCircuitBreaker(b))
+
+```
+
+Each host has its own circuit:
+  - Closed: requests are sent; `Threshold` consecutive failures open the circuit
+  - Open: requests fail at once with `ErrOpen`, until `Cooldown` has passed
+  - Half-open: `Probes` requests are sent; if they succeed the circuit closes, and the first failure opens it again
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/breaker/breaker.go
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/log"
+)
+
+// ErrOpen is matched (with `errors.Is`) by requests rejected by an open circuit; they also match `errors.ErrUnavailable`
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of the circuit of a host
+type State string
+
+const (
+	CLOSED    State = "closed"    // Requests are sent
+	OPEN      State = "open"      // Requests are rejected until the cooldown has passed
+	HALF_OPEN State = "half-open" // Probes are sent to find out whether the host recovered
+)
+
+// Breaker holds a circuit per host (or any other key)
+type Breaker struct {
+	Threshold int                              // Consecutive failures which open a circuit; 5 when zero
+	Cooldown  time.Duration                    // How long an open circuit rejects requests before probing the host; 30 seconds when zero
+	Probes    int                              // Requests sent while half-open, which must all succeed to close the circuit; 1 when zero
+	IsFailure func(err error) bool             // Whether an error counts against the host; every error but a cancelled or expired context when nil
+	OnChange  func(key string, from, to State) // Called on each transition of a circuit, e.g. to alert on an outage; it must not call the breaker; optional
+	Log       *log.Logger                      // Logger for the breaker
+
+	mutex    sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit is the state of the circuit of a key
+type circuit struct {
+	state      State
+	failures   int       // Consecutive failures while closed
+	opened     time.Time // When the circuit last opened
+	probing    int       // Probes in flight while half-open
+	probed     int       // Probes which succeeded while half-open
+	generation int       // Incremented on each transition, so the outcome of a request sent in a previous state is ignored
+}
+
+// New returns a breaker opening the circuit of a host after `threshold` consecutive failures, for `cooldown`
+func New(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		Threshold: threshold,
+		Cooldown:  cooldown,
+		Log:       log.NewLogger("{breaker}", log.INFO),
+	}
+}
+
+/*
+ * # Allow a Request
+ * Returns an error wrapping `ErrOpen` when the circuit of `key` rejects the request, or the function reporting its outcome
+ * - The outcome must be reported once the request completes: nil on success, or the error it failed with
+ * - Errors which are not failures (see `IsFailure`) leave the circuit unchanged
+ */
+func (b *Breaker) Allow(key string) (func(err error), error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c := b.circuit(key)
+	if c.state == OPEN {
+		if wait := time.Until(c.opened.Add(b.cooldown())); wait > 0 {
+			return nil, fmt.Errorf("%w for %s, retrying in %v: %w", ErrOpen, key, wait.Round(time.Second), rerrors.ErrUnavailable)
+		}
+		b.transition(key, c, HALF_OPEN)
+	}
+	if c.state == HALF_OPEN {
+		if c.probing >= b.probes() {
+			return nil, fmt.Errorf("%w for %s, while probing it: %w", ErrOpen, key, rerrors.ErrUnavailable)
+		}
+		c.probing++
+	}
+
+	generation := c.generation
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { b.report(key, generation, err) })
+	}, nil
+}
+
+// State returns the state of the circuit of `key`
+func (b *Breaker) State(key string) State {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c := b.circuit(key)
+	if c.state == OPEN && !time.Now().Before(c.opened.Add(b.cooldown())) {
+		return HALF_OPEN
+	}
+	return c.state
+}
+
+// Reset closes the circuit of `key`, e.g. once an outage of the provider is known to be over
+func (b *Breaker) Reset(key string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c := b.circuit(key)
+	if c.state != CLOSED {
+		b.transition(key, c, CLOSED)
+	}
+	c.failures = 0
+}
+
+// report records the outcome of a request allowed in `generation` of the circuit of `key`
+func (b *Breaker) report(key string, generation int, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c := b.circuit(key)
+	if c.generation != generation {
+		return
+	}
+	failed := err != nil && b.isFailure(err)
+
+	switch c.state {
+	case CLOSED:
+		switch {
+		case failed:
+			c.failures++
+			if c.failures >= b.threshold() {
+				b.transition(key, c, OPEN)
+			}
+		case err == nil:
+			c.failures = 0
+		}
+	case HALF_OPEN:
+		c.probing--
+		switch {
+		case failed:
+			b.transition(key, c, OPEN)
+		case err == nil:
+			c.probed++
+			if c.probed >= b.probes() {
+				b.transition(key, c, CLOSED)
+			}
+		}
+	}
+}
+
+// transition moves the circuit of `key` to state `to`
+func (b *Breaker) transition(key string, c *circuit, to State) {
+	from := c.state
+	c.state = to
+	c.generation++
+	c.failures, c.probing, c.probed = 0, 0, 0
+	if to == OPEN {
+		c.opened = time.Now()
+	}
+
+	switch to {
+	case OPEN:
+		b.logger().Warningf("Circuit of %s opened; rejecting its requests for %v", key, b.cooldown())
+	case CLOSED:
+		b.logger().Printf("Circuit of %s closed", key)
+	default:
+		b.logger().Debugf("Circuit of %s is %s", key, to)
+	}
+	if b.OnChange != nil {
+		b.OnChange(key, from, to)
+	}
+}
+
+// circuit returns the circuit of `key`, creating it closed
+func (b *Breaker) circuit(key string) *circuit {
+	if b.circuits == nil {
+		b.circuits = map[string]*circuit{}
+	}
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{state: CLOSED}
+		b.circuits[key] = c
+	}
+	return c
+}
+
+func (b *Breaker) isFailure(err error) bool {
+	if b.IsFailure != nil {
+		return b.IsFailure(err)
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+func (b *Breaker) threshold() int {
+	if b.Threshold <= 0 {
+		return 5
+	}
+	return b.Threshold
+}
+
+func (b *Breaker) cooldown() time.Duration {
+	if b.Cooldown <= 0 {
+		return 30 * time.Second
+	}
+	return b.Cooldown
+}
+
+func (b *Breaker) probes() int {
+	if b.Probes <= 0 {
+		return 1
+	}
+	return b.Probes
+}
+
+func (b *Breaker) logger() *log.Logger {
+	if b.Log == nil {
+		b.Log = log.NewLogger("{breaker}", log.INFO)
+	}
+	return b.Log
+}
@@ -0,0 +1,420 @@
+// pkg/common/cache/backend.go
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/redis"
+)
+
+/*
+ * # Backend
+ * Stores the items of a cache outside of it, so they survive the process (`DiskBackend`) or are shared by several
+ * (`RedisBackend`):
+ *
+ * ```go
+ *	backend, err := cache.NewRedisBackend("redis://:password@redis:6379/0")
+ *	c, err := cache.NewCache(encryptionKey, "rego_cache_okta.gob", backend)
+ * ```
+ *
+ * - Items are encrypted by the cache before they are stored, so a backend only ever holds ciphertext
+ * - Every `NewCache` without a backend of its own uses the one of `SetDefaultBackend`, or of `REGO_CACHE_URL`
+ * - Implementations must be safe for concurrent use
+ */
+type Backend interface {
+	Load(ctx context.Context, key string) (CacheItem, bool, error) // Returns the item of `key`, if one is stored
+	Store(ctx context.Context, key string, item CacheItem) error   // Stores `item` under `key`, until it expires
+	Delete(ctx context.Context, key string) error                  // Removes the item of `key`, if one is stored
+
+	// Scan calls `fn` with each item whose key starts with `prefix`, until it returns false; `fn` may delete the item
+	Scan(ctx context.Context, prefix string, fn func(key string, item CacheItem) bool) error
+}
+
+var defaultBackend atomic.Pointer[Backend]
+
+// SetDefaultBackend stores the caches opened without a backend of their own in `b`, e.g. at startup; nil restores `REGO_CACHE_URL`
+func SetDefaultBackend(b Backend) {
+	if b == nil {
+		defaultBackend.Store(nil)
+		return
+	}
+	defaultBackend.Store(&b)
+}
+
+/*
+ * # Open a Backend
+ * Returns the backend of `rawURL`, as set in `REGO_CACHE_URL`:
+ * - `redis://` or `rediss://`, e.g. `redis://:password@redis:6379/0`, for a `RedisBackend`
+ * - `file:///var/cache/rego`, or a path, for a `DiskBackend`
+ * - `memory:` for a `MemoryBackend` shared by every cache of the process
+ */
+func OpenBackend(rawURL string) (Backend, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache URL: %w", err)
+	}
+	switch u.Scheme {
+	case "redis", "rediss":
+		return NewRedisBackend(rawURL)
+	case "file":
+		return NewDiskBackend(u.Path)
+	case "memory":
+		return sharedMemory, nil
+	case "":
+		return NewDiskBackend(rawURL)
+	default:
+		return nil, fmt.Errorf("invalid cache URL %q: want redis://, rediss://, file:// or memory:", u.Redacted())
+	}
+}
+
+// backendOf returns the default backend, if one is set
+func backendOf() (Backend, error) {
+	if b := defaultBackend.Load(); b != nil {
+		return *b, nil
+	}
+	if rawURL := os.Getenv("REGO_CACHE_URL"); rawURL != "" {
+		return OpenBackend(rawURL)
+	}
+	return nil, nil
+}
+
+/*
+ * # Memory Backend
+ * A `Backend` held in memory, which several caches of the process may share, e.g. in tests
+ * - Expired items are dropped as the backend grows
+ */
+type MemoryBackend struct {
+	mu      sync.Mutex
+	items   map[string]CacheItem
+	sweepAt int
+}
+
+// sharedMemory is the backend of `memory:`
+var sharedMemory = NewMemoryBackend()
+
+// NewMemoryBackend returns an empty backend held in memory
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{items: map[string]CacheItem{}, sweepAt: 1024}
+}
+
+// Load returns the item of `key`, if one is stored
+func (m *MemoryBackend) Load(ctx context.Context, key string) (CacheItem, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item, ok := m.items[key]
+	return item, ok, nil
+}
+
+// Store stores `item` under `key`, dropping the expired items once the backend doubled in size since it last did
+func (m *MemoryBackend) Store(ctx context.Context, key string, item CacheItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items[key] = item
+	if len(m.items) >= m.sweepAt {
+		now := time.Now()
+		for k, v := range m.items {
+			if now.After(v.until()) {
+				delete(m.items, k)
+			}
+		}
+		m.sweepAt = max(2*len(m.items), 1024)
+	}
+	return nil
+}
+
+// Delete removes the item of `key`, if one is stored
+func (m *MemoryBackend) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, key)
+	return nil
+}
+
+// Scan calls `fn` with each item whose key starts with `prefix`, until it returns false
+func (m *MemoryBackend) Scan(ctx context.Context, prefix string, fn func(key string, item CacheItem) bool) error {
+	m.mu.Lock()
+	matched := map[string]CacheItem{}
+	for key, item := range m.items {
+		if strings.HasPrefix(key, prefix) {
+			matched[key] = item
+		}
+	}
+	m.mu.Unlock()
+
+	for key, item := range matched {
+		if !fn(key, item) {
+			break
+		}
+	}
+	return nil
+}
+
+/*
+ * # Disk Backend
+ * A `Backend` storing each item in a file of a directory, so the cache survives the process, and is shared by the
+ * processes of the host
+ * - Files are named by the SHA-256 of their key, which they hold with their item, and replaced atomically, so a reader
+ *   never sees a partial item
+ */
+type DiskBackend struct {
+	Dir string // Directory of the items
+}
+
+// NewDiskBackend returns a backend storing its items in `dir`, which is created if needed
+func NewDiskBackend(dir string) (*DiskBackend, error) {
+	if dir == "" {
+		return nil, errors.New("the cache directory is not set")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("creating the cache directory: %w", err)
+	}
+	return &DiskBackend{Dir: dir}, nil
+}
+
+// diskItem is the content of an item's file
+type diskItem struct {
+	Key  string
+	Item CacheItem
+}
+
+// Load returns the item of `key`, if one is stored
+func (d *DiskBackend) Load(ctx context.Context, key string) (CacheItem, bool, error) {
+	stored, ok, err := d.read(d.path(key))
+	if err != nil || !ok {
+		return CacheItem{}, false, err
+	}
+	return stored.Item, true, nil
+}
+
+// read reads the item of a file, if it exists
+func (d *DiskBackend) read(path string) (diskItem, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return diskItem{}, false, nil
+		}
+		return diskItem{}, false, err
+	}
+	var stored diskItem
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&stored); err != nil {
+		return diskItem{}, false, fmt.Errorf("reading cached item: %w", err)
+	}
+	return stored, true, nil
+}
+
+// Store writes `item` to the file of `key`
+func (d *DiskBackend) Store(ctx context.Context, key string, item CacheItem) error {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(diskItem{Key: key, Item: item}); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(d.Dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buffer.Bytes()); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), d.path(key)); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// Delete removes the file of `key`, if one is stored
+func (d *DiskBackend) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(d.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Scan calls `fn` with each item whose key starts with `prefix`, until it returns false; each file of the directory is read
+func (d *DiskBackend) Scan(ctx context.Context, prefix string, fn func(key string, item CacheItem) bool) error {
+	paths, err := filepath.Glob(filepath.Join(d.Dir, "*.gob"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// Files removed, or being replaced, since they were listed are skipped
+		stored, ok, err := d.read(path)
+		if err != nil || !ok || !strings.HasPrefix(stored.Key, prefix) {
+			continue
+		}
+		if !fn(stored.Key, stored.Item) {
+			break
+		}
+	}
+	return nil
+}
+
+func (d *DiskBackend) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.Dir, hex.EncodeToString(sum[:])+".gob")
+}
+
+/*
+ * # Redis Backend
+ * A `Backend` stored in Redis, so every process connected to it shares the cache, e.g. the workers of a sync
+ * - Items are JSON strings of their data, expiration and tags, e.g. under `rego:cache:okta:users`, which Redis drops once
+ *   they are no longer retained
+ */
+type RedisBackend struct {
+	Client *redis.Client // Connection to the server
+	Prefix string        // Prefix of the keys; `DefaultRedisPrefix` when empty
+}
+
+// DefaultRedisPrefix prefixes the keys of the items in Redis
+const DefaultRedisPrefix = "rego:cache:"
+
+// NewRedisBackend returns a backend stored in the server of `rawURL`, e.g. `redis://:password@localhost:6379/0`
+func NewRedisBackend(rawURL string) (*RedisBackend, error) {
+	client, err := redis.NewClient(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisBackend{Client: client}, nil
+}
+
+// Load returns the item of `key`, retained for as long as Redis holds it
+func (r *RedisBackend) Load(ctx context.Context, key string) (CacheItem, bool, error) {
+	k := r.key(key)
+	replies, err := r.Client.Do(ctx, []string{"GET", k}, []string{"PTTL", k})
+	if err != nil {
+		return CacheItem{}, false, err
+	}
+	return decodeRedis(replies[0], replies[1])
+}
+
+// Store stores `item` under `key`, until it is no longer retained; an item past it is deleted
+func (r *RedisBackend) Store(ctx context.Context, key string, item CacheItem) error {
+	until := item.until()
+	if !until.After(time.Now()) {
+		return r.Delete(ctx, key)
+	}
+	value, err := json.Marshal(redisItem{Data: item.Data, Expires: item.Expires.UnixMilli(), Tags: item.Tags})
+	if err != nil {
+		return err
+	}
+	_, err = r.Client.Do(ctx, []string{"SET", r.key(key), string(value), "PXAT", strconv.FormatInt(until.UnixMilli(), 10)})
+	return err
+}
+
+// Scan calls `fn` with each item whose key starts with `prefix`, found with `SCAN`, until it returns false
+func (r *RedisBackend) Scan(ctx context.Context, prefix string, fn func(key string, item CacheItem) bool) error {
+	pattern := redisPattern.Replace(r.key(prefix)) + "*"
+	cursor := "0"
+	for {
+		replies, err := r.Client.Do(ctx, []string{"SCAN", cursor, "MATCH", pattern, "COUNT", "1000"})
+		if err != nil {
+			return err
+		}
+		reply, ok := replies[0].([]interface{})
+		if !ok || len(reply) != 2 {
+			return fmt.Errorf("redis: unexpected reply to SCAN: %v", replies[0])
+		}
+		cursor, _ = reply[0].(string)
+		keys, _ := reply[1].([]interface{})
+
+		commands := [][]string{}
+		for _, k := range keys {
+			k, _ := k.(string)
+			commands = append(commands, []string{"GET", k}, []string{"PTTL", k})
+		}
+		if len(commands) > 0 {
+			items, err := r.Client.Do(ctx, commands...)
+			if err != nil {
+				return err
+			}
+			for i := range keys {
+				item, ok, err := decodeRedis(items[2*i], items[2*i+1])
+				if err != nil || !ok {
+					continue
+				}
+				if !fn(strings.TrimPrefix(keys[i].(string), r.key("")), item) {
+					return nil
+				}
+			}
+		}
+		if cursor == "0" || cursor == "" {
+			return nil
+		}
+	}
+}
+
+// redisItem is the JSON of an item in Redis
+type redisItem struct {
+	Data    string   `json:"data"`
+	Expires int64    `json:"expires"` // Unix milliseconds
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// redisPattern escapes the characters of a key which `SCAN` would match as a pattern
+var redisPattern = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+// decodeRedis decodes the replies to the `GET` and `PTTL` of an item
+func decodeRedis(value interface{}, pttl interface{}) (CacheItem, bool, error) {
+	v, ok := value.(string)
+	if !ok {
+		return CacheItem{}, false, nil
+	}
+	ttl, _ := pttl.(int64)
+	if ttl < 0 {
+		return CacheItem{}, false, nil
+	}
+
+	var stored redisItem
+	if err := json.Unmarshal([]byte(v), &stored); err != nil {
+		return CacheItem{}, false, fmt.Errorf("reading cached item: %w", err)
+	}
+	return CacheItem{
+		Data:    stored.Data,
+		Expires: time.UnixMilli(stored.Expires),
+		Retain:  time.Now().Add(time.Duration(ttl) * time.Millisecond),
+		Tags:    stored.Tags,
+	}, true, nil
+}
+
+// Delete removes the item of `key`, if one is stored
+func (r *RedisBackend) Delete(ctx context.Context, key string) error {
+	_, err := r.Client.Do(ctx, []string{"DEL", r.key(key)})
+	return err
+}
+
+// Close closes the connection to the server
+func (r *RedisBackend) Close() error {
+	return r.Client.Close()
+}
+
+func (r *RedisBackend) key(key string) string {
+	if r.Prefix == "" {
+		return DefaultRedisPrefix + key
+	}
+	return r.Prefix + key
+}
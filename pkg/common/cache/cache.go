@@ -4,16 +4,19 @@ package cache
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/gob"
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gemini-oss/rego/pkg/common/crypt"
+	"github.com/gemini-oss/rego/pkg/common/metrics"
 )
 
 var (
@@ -21,21 +24,37 @@ var (
 )
 
 type Cache struct {
-	accessList      []string             // Least Recently Used (LRU) list
-	accessMap       map[string]int       // Least Recently Used (LRU) map
-	data            map[string]CacheItem // Cache data
-	encryptionKey   []byte               // Encryption key
-	hashes          map[string]string    // SHA-256 hashes of the data
-	inMemory        bool                 // Defines if the cache is memory-based
-	maxItems        int                  // Maximum number of items in the cache
-	mutex           sync.RWMutex         // Mutex for thread safety
-	persistencePath string               // Path to the file for disk-based cache
-	Enabled         bool                 // Defines if the cache is enabled
+	accessList      []string                 // Least Recently Used (LRU) list
+	accessMap       map[string]int           // Least Recently Used (LRU) map
+	data            map[string]CacheItem     // Cache data
+	encryptionKey   []byte                   // Encryption key
+	previousKeys    [][]byte                 // Keys the items may still be encrypted with, since the key was rotated
+	keyMutex        sync.RWMutex             // Mutex of the keys, which `Rotate` replaces
+	hashes          map[string]string        // SHA-256 hashes of the data
+	inMemory        bool                     // Defines if the cache is memory-based
+	maxItems        int                      // Maximum number of items in the cache
+	mutex           sync.RWMutex             // Mutex for thread safety
+	persistencePath string                   // Path to the file for disk-based cache
+	backend         Backend                  // Stores the items in place of `data`, when set
+	staleWindows    map[string]time.Duration // Time the items of each key are served stale by `Fetch`, set with `StaleWhileRevalidate`
+	refreshing      map[string]bool          // Keys being refreshed in the background by `Fetch`
+	refreshes       sync.WaitGroup           // Refreshes in the background, which `Flush` waits for
+	Enabled         bool                     // Defines if the cache is enabled
 }
 
 type CacheItem struct {
 	Data    string
 	Expires time.Time
+	Retain  time.Time // Kept until then once expired, to be served stale while `Fetch` refreshes it; not kept when zero
+	Tags    []string  // Tags the item is invalidated by with `InvalidateTag`, e.g. `okta:group:00g1`; stored unencrypted
+}
+
+// until returns the time the item may be dropped
+func (i CacheItem) until() time.Time {
+	if i.Retain.After(i.Expires) {
+		return i.Retain
+	}
+	return i.Expires
 }
 
 // CacheOptions defines options for creating a new cache
@@ -44,8 +63,19 @@ type CacheOptions struct {
 	PersistencePath string
 	InMemory        bool
 	MaxItems        int
+	Backend         Backend // Stores the items, e.g. a `RedisBackend`; the default backend, if any, when nil
+	PreviousKeys    PreviousKeys
 }
 
+/*
+ * # New Cache
+ * Opens an encrypted cache, configured by the type of each argument:
+ * - `[]byte` is the encryption key, `string` the name of the cache's file in the temp directory, e.g. `rego_cache_okta.gob`
+ * - `bool` keeps the cache in memory, `int` caps its items (1000 by default)
+ * - `Backend` stores the items in it rather than in memory or the file, e.g. so they are shared by several processes;
+ *   the name of the cache then namespaces its keys
+ * - `PreviousKeys` are the keys the cache was encrypted with before, `REGO_PREVIOUS_ENCRYPTION_KEYS` when not given
+ */
 func NewCache(args ...interface{}) (*Cache, error) {
 	gob.Register([]byte{})
 
@@ -65,6 +95,10 @@ func NewCache(args ...interface{}) (*Cache, error) {
 			opts.InMemory = v
 		case int:
 			opts.MaxItems = v
+		case Backend:
+			opts.Backend = v
+		case PreviousKeys:
+			opts.PreviousKeys = v
 		default:
 			// Handle unknown option
 			if v != nil {
@@ -79,6 +113,16 @@ func NewCache(args ...interface{}) (*Cache, error) {
 		return nil, err
 	}
 
+	if opts.PreviousKeys == nil {
+		opts.PreviousKeys = previousKeysOf()
+	}
+
+	if opts.Backend == nil && !opts.InMemory {
+		if opts.Backend, err = backendOf(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize Cache with options
 	c := &Cache{
 		data:            make(map[string]CacheItem),
@@ -86,12 +130,14 @@ func NewCache(args ...interface{}) (*Cache, error) {
 		accessList:      make([]string, 0, opts.MaxItems),
 		accessMap:       make(map[string]int),
 		encryptionKey:   opts.EncryptionKey,
+		previousKeys:    opts.PreviousKeys,
 		persistencePath: opts.PersistencePath,
 		inMemory:        opts.InMemory,
 		maxItems:        opts.MaxItems,
+		backend:         opts.Backend,
 	}
 
-	if !opts.InMemory && opts.PersistencePath != "" {
+	if opts.Backend == nil && !opts.InMemory && opts.PersistencePath != "" {
 		if err := c.loadFromDisk(); err != nil {
 			return nil, err
 		}
@@ -102,18 +148,49 @@ func NewCache(args ...interface{}) (*Cache, error) {
 
 // Encrypts data using the AES-GCM (256) algorithm
 func (c *Cache) encrypt(data []byte) (string, error) {
+	c.keyMutex.RLock()
+	defer c.keyMutex.RUnlock()
 	return crypt.EncryptAES(data, c.encryptionKey)
 }
 
-// Decrypts data using the AES-GCM (256) algorithm
-func (c *Cache) decrypt(data string) ([]byte, error) {
-	return crypt.DecryptAES(data, c.encryptionKey)
+// Decrypts data using the AES-GCM (256) algorithm, with the previous keys when the current one fails; `stale` reports
+// whether the data was encrypted with a previous key
+func (c *Cache) decrypt(data string) (decrypted []byte, stale bool, err error) {
+	c.keyMutex.RLock()
+	defer c.keyMutex.RUnlock()
+
+	decrypted, err = crypt.DecryptAES(data, c.encryptionKey)
+	if err == nil {
+		return decrypted, false, nil
+	}
+	for _, key := range c.previousKeys {
+		if decrypted, prevErr := crypt.DecryptAES(data, key); prevErr == nil {
+			return decrypted, true, nil
+		}
+	}
+	return nil, false, err
 }
 
-func (c *Cache) Set(key string, value interface{}, duration time.Duration) error {
+// Enable enables the cache; unlike setting `Enabled`, it is safe while the cache is in use by other goroutines
+func (c *Cache) Enable() {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
+	c.Enabled = true
+}
+
+// IsEnabled returns whether the cache is enabled
+func (c *Cache) IsEnabled() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.Enabled
+}
+
+func (c *Cache) Set(key string, value interface{}, duration time.Duration) error {
+	return c.SetTagged(key, value, duration)
+}
 
+// SetTagged stores `value` under `key` like `Set`, tagged with `tags`, so `InvalidateTag` can remove it with related items
+func (c *Cache) SetTagged(key string, value interface{}, duration time.Duration, tags ...string) error {
 	serializedValue, err := c.serializeWithGob(value)
 	if err != nil {
 		return err
@@ -124,10 +201,21 @@ func (c *Cache) Set(key string, value interface{}, duration time.Duration) error
 		return err
 	}
 
+	expires := time.Now().Add(duration)
+	retain := expires.Add(c.staleWindow(key))
+	if c.backend != nil {
+		return c.backend.Store(context.Background(), c.keyOf(key), CacheItem{Data: encryptedValue, Expires: expires, Retain: retain, Tags: tags})
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
 	hash := sha256Hash(serializedValue)
 	if existingKey, exists := c.hashes[hash]; exists {
 		existingItem := c.data[existingKey]
-		existingItem.Expires = time.Now().Add(duration)
+		existingItem.Expires = expires
+		existingItem.Retain = retain
+		existingItem.Tags = mergeTags(existingItem.Tags, tags)
 		c.data[existingKey] = existingItem
 		c.updateAccess(existingKey)
 		return nil
@@ -135,7 +223,9 @@ func (c *Cache) Set(key string, value interface{}, duration time.Duration) error
 
 	c.data[key] = CacheItem{
 		Data:    encryptedValue,
-		Expires: time.Now().Add(duration),
+		Expires: expires,
+		Retain:  retain,
+		Tags:    tags,
 	}
 	c.hashes[hash] = key
 	c.updateAccess(key)
@@ -147,32 +237,128 @@ func (c *Cache) Set(key string, value interface{}, duration time.Duration) error
 	return nil
 }
 
-func (c *Cache) Get(key string) ([]byte, bool) {
+func (c *Cache) Get(key string) (value []byte, found bool) {
+	defer func() { c.observe(found) }()
+
+	d, fresh, _ := c.load(key)
+	if !fresh {
+		return nil, false
+	}
+	return c.open(key, d)
+}
+
+// open decrypts and decodes the value of an item, re-encrypting it if it was encrypted with a previous key
+func (c *Cache) open(key string, d CacheItem) ([]byte, bool) {
+	decryptedValue, stale, err := c.decrypt(d.Data)
+	if err != nil {
+		return nil, false
+	}
+	if stale {
+		c.reencrypt(key, d, decryptedValue)
+	}
+
+	var result []byte
+	if err := c.deserializeWithGob(decryptedValue, &result); err != nil {
+		return nil, false
+	}
+
+	return result, true
+}
+
+// load returns the item of `key` and whether it is fresh, extending its expiration upon access; an expired item is only
+// found while it is retained to be served stale
+func (c *Cache) load(key string) (d CacheItem, fresh bool, found bool) {
+	now := time.Now()
+	if c.backend != nil {
+		ctx := context.Background()
+		d, exists, err := c.backend.Load(ctx, c.keyOf(key))
+		if err != nil || !exists {
+			return CacheItem{}, false, false
+		}
+		if now.After(d.Expires) {
+			if now.Before(d.Retain) {
+				return d, false, true
+			}
+			c.backend.Delete(ctx, c.keyOf(key))
+			return CacheItem{}, false, false
+		}
+		d = extend(d)
+		c.backend.Store(ctx, c.keyOf(key), d)
+		return d, true, true
+	}
+
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
 	d, exists := c.data[key]
-	if !exists || time.Now().After(d.Expires) {
-		return nil, false
+	if !exists {
+		return CacheItem{}, false, false
+	}
+	if now.After(d.Expires) {
+		return d, false, now.Before(d.Retain)
 	}
 
 	// Update the expiration time upon access
-	d.Expires = d.Expires.Add(1 * time.Minute)
+	d = extend(d)
 	c.data[key] = d
 
 	c.updateAccess(key)
+	return d, true, true
+}
 
-	decryptedValue, err := c.decrypt(d.Data)
-	if err != nil {
-		return nil, false
+// extend extends the expiration of an item upon access, with the time it is retained after
+func extend(d CacheItem) CacheItem {
+	if !d.Retain.IsZero() {
+		d.Retain = d.Retain.Add(1 * time.Minute)
 	}
+	d.Expires = d.Expires.Add(1 * time.Minute)
+	return d
+}
 
-	var result []byte
-	if err := c.deserializeWithGob(decryptedValue, &result); err != nil {
-		return nil, false
+// keyOf namespaces `key` with the name of the cache in its backend, so the caches of several clients may share one
+func (c *Cache) keyOf(key string) string {
+	if c.persistencePath == "" {
+		return key
 	}
+	return c.name() + ":" + key
+}
 
-	return result, true
+// name returns the name of the cache, e.g. `okta` for `rego_cache_okta.gob`
+func (c *Cache) name() string {
+	return strings.TrimSuffix(strings.TrimPrefix(filepath.Base(c.persistencePath), "rego_cache_"), ".gob")
+}
+
+// observe records a lookup as a hit or a miss, labelled with the name of the cache, e.g. `okta` for `rego_cache_okta.gob`
+func (c *Cache) observe(found bool) {
+	name := "memory"
+	if (c.backend != nil || !c.inMemory) && c.persistencePath != "" {
+		name = c.name()
+	}
+	label := metrics.Label{Name: "cache", Value: name}
+	if found {
+		metrics.Add(metrics.CacheHitsTotal, 1, label)
+	} else {
+		metrics.Add(metrics.CacheMissesTotal, 1, label)
+	}
+}
+
+// Flush writes the cache to disk, e.g. before the process exits, keeping the expirations `Get` extended since the last `Set`
+// - A cache with a backend is written through to it, so there is nothing to flush
+// - Refreshes `Fetch` started in the background are waited for, so their values are flushed too
+func (c *Cache) Flush() error {
+	if c == nil {
+		return nil
+	}
+	c.refreshes.Wait()
+	if c.backend != nil {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.persistencePath == "" {
+		return nil
+	}
+	return c.persistToDisk()
 }
 
 func (c *Cache) serializeWithGob(data interface{}) ([]byte, error) {
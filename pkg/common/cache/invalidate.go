@@ -0,0 +1,122 @@
+// pkg/common/cache/invalidate.go
+package cache
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// Delete removes the item of `key`, e.g. once a mutation made it stale
+func (c *Cache) Delete(key string) error {
+	if c == nil {
+		return nil
+	}
+	if c.backend != nil {
+		return c.backend.Delete(context.Background(), c.keyOf(key))
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.data[key]; !ok {
+		return nil
+	}
+	c.remove(key)
+	return c.persistToDisk()
+}
+
+/*
+ * # Invalidate by Prefix
+ * Removes the items whose key starts with `prefix`, returning how many, e.g. every cached GET of a group after its
+ * membership changed:
+ *
+ * ```go
+ *	c.Cache.InvalidateByPrefix("https://example.okta.com/api/v1/groups/00g1")
+ * ```
+ */
+func (c *Cache) InvalidateByPrefix(prefix string) (int, error) {
+	return c.invalidate(prefix, func(CacheItem) bool { return true })
+}
+
+/*
+ * # Invalidate by Tag
+ * Removes the items tagged (with `SetTagged` or `Fetch`) with any of `tags`, returning how many
+ * - Every item of the cache is scanned, so prefer `InvalidateByPrefix` for the items whose keys share one
+ */
+func (c *Cache) InvalidateTag(tags ...string) (int, error) {
+	return c.invalidate("", func(item CacheItem) bool {
+		for _, tag := range tags {
+			if slices.Contains(item.Tags, tag) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// invalidate removes the items whose key starts with `prefix` and which `match`, returning how many
+func (c *Cache) invalidate(prefix string, match func(CacheItem) bool) (int, error) {
+	if c == nil {
+		return 0, nil
+	}
+	if c.backend != nil {
+		ctx := context.Background()
+		removed := 0
+		var err error
+		scanErr := c.backend.Scan(ctx, c.keyOf(prefix), func(key string, item CacheItem) bool {
+			if !match(item) {
+				return true
+			}
+			if err = c.backend.Delete(ctx, key); err != nil {
+				return false
+			}
+			removed++
+			return true
+		})
+		if scanErr != nil {
+			return removed, scanErr
+		}
+		return removed, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	removed := 0
+	for key, item := range c.data {
+		if strings.HasPrefix(key, prefix) && match(item) {
+			c.remove(key)
+			removed++
+		}
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, c.persistToDisk()
+}
+
+// remove removes `key` from the data, its hash and the access list; the cache must be locked
+func (c *Cache) remove(key string) {
+	delete(c.data, key)
+	for hash, k := range c.hashes {
+		if k == key {
+			delete(c.hashes, hash)
+		}
+	}
+	if idx, found := c.accessMap[key]; found {
+		c.accessList = append(c.accessList[:idx], c.accessList[idx+1:]...)
+		delete(c.accessMap, key)
+		for i := idx; i < len(c.accessList); i++ {
+			c.accessMap[c.accessList[i]] = i
+		}
+	}
+}
+
+// mergeTags returns the tags of both lists, once each
+func mergeTags(tags []string, more []string) []string {
+	for _, tag := range more {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,62 @@
+// pkg/common/cache/keys.go
+package cache
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/gemini-oss/rego/pkg/common/crypt"
+)
+
+// PreviousKeys are the keys a cache was encrypted with before its key was rotated, newest first
+// - Items encrypted with one of them are still read, and re-encrypted with the current key as they are
+type PreviousKeys [][]byte
+
+/*
+ * # Rotate the Encryption Key
+ * Encrypts the items of the cache with `key` from now on, keeping the current key to read the items encrypted with it
+ * - Items are re-encrypted as they are read, so rotating a large or shared cache is not held up by re-encrypting it
+ *   all at once; the previous key must be kept (e.g. in `REGO_PREVIOUS_ENCRYPTION_KEYS`) until they all were
+ */
+func (c *Cache) Rotate(key []byte) error {
+	if err := crypt.ValidPassphrase(key); err != nil {
+		return err
+	}
+
+	c.keyMutex.Lock()
+	defer c.keyMutex.Unlock()
+	c.previousKeys = append(PreviousKeys{c.encryptionKey}, c.previousKeys...)
+	c.encryptionKey = key
+	return nil
+}
+
+// reencrypt replaces the item of `key`, decrypted with a previous key, with one encrypted with the current key
+func (c *Cache) reencrypt(key string, item CacheItem, decrypted []byte) {
+	encrypted, err := c.encrypt(decrypted)
+	if err != nil {
+		return
+	}
+	item.Data = encrypted
+
+	if c.backend != nil {
+		c.backend.Store(context.Background(), c.keyOf(key), item)
+		return
+	}
+
+	// The item is written to disk with the next `Set` or `Flush`, like the expirations extended by `Get`
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.data[key]; ok {
+		c.data[key] = item
+	}
+}
+
+// previousKeysOf returns the keys of `REGO_PREVIOUS_ENCRYPTION_KEYS`, separated by whitespace, newest first
+func previousKeysOf() PreviousKeys {
+	var keys PreviousKeys
+	for _, key := range strings.Fields(os.Getenv("REGO_PREVIOUS_ENCRYPTION_KEYS")) {
+		keys = append(keys, []byte(key))
+	}
+	return keys
+}
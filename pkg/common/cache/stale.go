@@ -0,0 +1,87 @@
+// pkg/common/cache/stale.go
+package cache
+
+import (
+	"time"
+)
+
+/*
+ * # Stale While Revalidate
+ * Serves the item of `key` for `window` after it expires, while `Fetch` refreshes it in the background, e.g. so a
+ * large list of users is returned at once when it is slightly stale, rather than after it is listed again
+ * - Applies to the items set from now on; zero stops serving the key stale
+ */
+func (c *Cache) StaleWhileRevalidate(key string, window time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.staleWindows == nil {
+		c.staleWindows = map[string]time.Duration{}
+	}
+	if window <= 0 {
+		delete(c.staleWindows, key)
+		return
+	}
+	c.staleWindows[key] = window
+}
+
+// staleWindow returns the time the items of `key` are served stale
+func (c *Cache) staleWindow(key string) time.Duration {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.staleWindows[key]
+}
+
+/*
+ * # Fetch
+ * Returns the value of `key`, or caches the value of `refresh` for `duration` when it is missing
+ * - An expired value still within the key's `StaleWhileRevalidate` window is returned at once, while `refresh` runs in
+ *   the background; only one refresh of a key runs at a time, and a failed one leaves the stale value until the next
+ * - The value is tagged with `tags`, like `SetTagged`
+ */
+func (c *Cache) Fetch(key string, duration time.Duration, refresh func() ([]byte, error), tags ...string) ([]byte, error) {
+	if d, fresh, found := c.load(key); found {
+		if value, ok := c.open(key, d); ok {
+			if !fresh {
+				c.revalidate(key, duration, refresh, tags)
+			}
+			c.observe(true)
+			return value, nil
+		}
+	}
+	c.observe(false)
+
+	value, err := refresh()
+	if err != nil {
+		return nil, err
+	}
+	c.SetTagged(key, value, duration, tags...)
+	return value, nil
+}
+
+// revalidate refreshes the value of `key` in the background, unless it is already being refreshed
+func (c *Cache) revalidate(key string, duration time.Duration, refresh func() ([]byte, error), tags []string) {
+	c.mutex.Lock()
+	if c.refreshing[key] {
+		c.mutex.Unlock()
+		return
+	}
+	if c.refreshing == nil {
+		c.refreshing = map[string]bool{}
+	}
+	c.refreshing[key] = true
+	c.refreshes.Add(1)
+	c.mutex.Unlock()
+
+	go func() {
+		defer c.refreshes.Done()
+		defer func() {
+			c.mutex.Lock()
+			delete(c.refreshing, key)
+			c.mutex.Unlock()
+		}()
+
+		if value, err := refresh(); err == nil {
+			c.SetTagged(key, value, duration, tags...)
+		}
+	}()
+}
@@ -0,0 +1,202 @@
+/*
+# Checkpoint
+
+This package persists the progress of incremental (delta) syncs, so scheduled jobs only pull what changed since their
+previous run instead of dumping every directory each time:
+
+```go
+
+	store := checkpoint.NewFileStore("rego_checkpoints.json")
+
+	// Directories: users updated since the previous successful run (every user on the first run)
+	err := checkpoint.Delta(store, "okta-users", time.Minute, func(since time.Time) error {
+		return iterator.ForEach(o.IterUsersUpdatedSince(since), upsert)
+	})
+
+	// Logs: events after the last one processed, even when the previous run stopped part way
+	err = checkpoint.Run(store, "okta-system-log", func(cp *checkpoint.Checkpoint) error {
+		return iterator.ForEach(o.TailLogs(cp, okta.LogQuery{}), process)
+	})
+
+```
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/checkpoint/checkpoint.go
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Checkpoint is the position a sync resumes from
+type Checkpoint struct {
+	Time   time.Time `json:"time"`             // Changes up to this time were synced; zero before the first sync
+	Cursor string    `json:"cursor,omitempty"` // Identifies the last item synced at `Time`, e.g. the UUID of a System Log event
+}
+
+// IsZero returns whether nothing was synced yet
+func (c Checkpoint) IsZero() bool {
+	return c.Time.IsZero() && c.Cursor == ""
+}
+
+func (c Checkpoint) equal(o Checkpoint) bool {
+	return c.Time.Equal(o.Time) && c.Cursor == o.Cursor
+}
+
+// Store persists checkpoints by name
+type Store interface {
+	Load(name string) (Checkpoint, error) // Returns the zero checkpoint when none was saved
+	Save(name string, cp Checkpoint) error
+}
+
+/*
+ * # Run
+ * Runs the sync `name` from its checkpoint, which `sync` advances as it progresses
+ * - The checkpoint is saved whenever it advanced, even when `sync` fails, so the next run resumes after what was synced
+ */
+func Run(store Store, name string, sync func(cp *Checkpoint) error) error {
+	cp, err := store.Load(name)
+	if err != nil {
+		return fmt.Errorf("loading checkpoint %s: %w", name, err)
+	}
+
+	previous := cp
+	err = sync(&cp)
+	if !cp.equal(previous) {
+		if serr := store.Save(name, cp); serr != nil {
+			return errors.Join(err, fmt.Errorf("saving checkpoint %s: %w", name, serr))
+		}
+	}
+	return err
+}
+
+/*
+ * # Delta
+ * Runs the sync `name` of a directory with the time of its previous successful run, minus `overlap`; `since` is zero on
+ * the first run, for a full sync
+ * - The time the run started is saved only when `sync` succeeds, so a failed run is retried in full next time
+ * - `overlap` covers changes committed late by the API, and clock skew; items may be synced twice, never missed
+ */
+func Delta(store Store, name string, overlap time.Duration, sync func(since time.Time) error) error {
+	return Run(store, name, func(cp *Checkpoint) error {
+		started := time.Now().UTC()
+
+		var since time.Time
+		if !cp.Time.IsZero() {
+			since = cp.Time.Add(-overlap)
+		}
+		if err := sync(since); err != nil {
+			return err
+		}
+
+		*cp = Checkpoint{Time: started}
+		return nil
+	})
+}
+
+// ### Stores
+// ---------------------------------------------------------------------
+
+// MemoryStore keeps checkpoints in memory, e.g. for tests or long-running processes
+type MemoryStore struct {
+	mutex       sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+// NewMemoryStore returns an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{checkpoints: map[string]Checkpoint{}}
+}
+
+func (s *MemoryStore) Load(name string) (Checkpoint, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.checkpoints[name], nil
+}
+
+func (s *MemoryStore) Save(name string, cp Checkpoint) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.checkpoints[name] = cp
+	return nil
+}
+
+// FileStore keeps checkpoints in a JSON file, replaced atomically on each save
+type FileStore struct {
+	mutex sync.Mutex
+	path  string
+}
+
+// NewFileStore returns a store of the checkpoints in the file at `path`, created on the first save
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) Load(name string) (Checkpoint, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	checkpoints, err := s.read()
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	return checkpoints[name], nil
+}
+
+func (s *FileStore) Save(name string, cp Checkpoint) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	checkpoints, err := s.read()
+	if err != nil {
+		return err
+	}
+	checkpoints[name] = cp
+
+	data, err := json.MarshalIndent(checkpoints, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// read returns the checkpoints of the file, or none when it does not exist yet
+func (s *FileStore) read() (map[string]Checkpoint, error) {
+	checkpoints := map[string]Checkpoint{}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoints, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &checkpoints); err != nil {
+		return nil, fmt.Errorf("reading checkpoints from %s: %w", s.path, err)
+	}
+	return checkpoints, nil
+}
+
+// END OF STORES
+//---------------------------------------------------------------------
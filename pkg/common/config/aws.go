@@ -0,0 +1,190 @@
+// pkg/common/config/aws.go
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+ * # AWS Secrets Manager
+ * A `Provider` reading the fields of a secret of AWS Secrets Manager, whose value is a JSON object, e.g.
+ * `{"OKTA_API_TOKEN": "..."}`
+ * - Credentials not set are read from `AWS_ACCESS_KEY_ID`, `AWS_SECRET_ACCESS_KEY`, `AWS_SESSION_TOKEN` and `AWS_REGION`
+ *   (e.g. as exported by a CI/CD runner assuming a role with OIDC) each time the secret is read, so rotated credentials
+ *   are picked up
+ * - https://docs.aws.amazon.com/secretsmanager/latest/apireference/API_GetSecretValue.html
+ */
+type AWSSecretsManager struct {
+	SecretID        string        // Name or ARN of the secret
+	VersionStage    string        // Stage of the version read; `AWSCURRENT` when empty
+	Region          string        // Region of the secret, e.g. `us-east-1`
+	AccessKeyID     string        // Access key of the requests
+	SecretAccessKey string        // Secret of `AccessKeyID`
+	SessionToken    string        // Token of temporary credentials; none when empty
+	Endpoint        string        // Endpoint of the service; `https://secretsmanager.{Region}.amazonaws.com` when empty
+	TTL             time.Duration // Time the secret is cached; 5 minutes when zero
+	HTTP            *http.Client  // Client of the requests; one with a 10 second timeout when nil
+
+	cache secretCache
+}
+
+// awsCredentials are the credentials a request is signed with
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Region          string
+}
+
+// AWSSecretsManagerFromEnvironment returns the secret of `REGO_AWS_SECRET_ID`, or nil when it is not set
+func AWSSecretsManagerFromEnvironment() (*AWSSecretsManager, error) {
+	id := os.Getenv("REGO_AWS_SECRET_ID")
+	if id == "" {
+		return nil, nil
+	}
+	return &AWSSecretsManager{SecretID: id, VersionStage: os.Getenv("REGO_AWS_SECRET_STAGE")}, nil
+}
+
+// Lookup returns the field `key` of the secret, reading it again once it has been cached for `TTL`
+func (a *AWSSecretsManager) Lookup(ctx context.Context, key string) (string, bool, error) {
+	return a.cache.lookup(ctx, key, a.TTL, a.read)
+}
+
+// read reads the fields of the secret
+func (a *AWSSecretsManager) read(ctx context.Context) (map[string]string, error) {
+	credentials := awsCredentials{a.AccessKeyID, a.SecretAccessKey, a.SessionToken, a.Region}
+	for field, env := range map[*string]string{
+		&credentials.AccessKeyID:     "AWS_ACCESS_KEY_ID",
+		&credentials.SecretAccessKey: "AWS_SECRET_ACCESS_KEY",
+		&credentials.SessionToken:    "AWS_SESSION_TOKEN",
+		&credentials.Region:          "AWS_REGION",
+	} {
+		if *field == "" {
+			*field = os.Getenv(env)
+		}
+	}
+	if credentials.Region == "" || credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return nil, fmt.Errorf("aws secrets manager: the region and credentials of %s are not set", a.SecretID)
+	}
+
+	input := map[string]string{"SecretId": a.SecretID}
+	if a.VersionStage != "" {
+		input["VersionStage"] = a.VersionStage
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := a.Endpoint
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + credentials.Region + ".amazonaws.com"
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("aws secrets manager: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	credentials.sign(req, body)
+
+	client := a.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("aws secrets manager: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&failure)
+		return nil, fmt.Errorf("aws secrets manager: reading %s: %d %s %s", a.SecretID, resp.StatusCode, failure.Type, failure.Message)
+	}
+
+	var output struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&output); err != nil {
+		return nil, fmt.Errorf("aws secrets manager: decoding %s: %w", a.SecretID, err)
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(output.SecretString), &object); err != nil {
+		return nil, fmt.Errorf("aws secrets manager: %s is not a JSON object of variables", a.SecretID)
+	}
+	return secretFields(object)
+}
+
+/*
+ * # Sign a Request
+ * Signs a request to Secrets Manager with AWS Signature Version 4, like the uploads of `pipeline.S3`
+ * https://docs.aws.amazon.com/IAM/latest/UserGuide/create-signed-request.html
+ */
+func (a awsCredentials) sign(req *http.Request, body []byte) {
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	date := stamp[:8]
+	payload := sha256.Sum256(body)
+
+	req.Header.Set("X-Amz-Date", stamp)
+	if a.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonical := strings.Builder{}
+	for _, name := range names {
+		fmt.Fprintf(&canonical, "%s:%s\n", name, headers[name])
+	}
+	signed := strings.Join(names, ";")
+
+	request := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonical.String(),
+		signed,
+		hex.EncodeToString(payload[:]),
+	}, "\n")
+	scope := fmt.Sprintf("%s/%s/secretsmanager/aws4_request", date, a.Region)
+	hashed := sha256.Sum256([]byte(request))
+	toSign := strings.Join([]string{"AWS4-HMAC-SHA256", stamp, scope, hex.EncodeToString(hashed[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+a.SecretAccessKey), date)
+	for _, part := range []string{a.Region, "secretsmanager", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", a.AccessKeyID, scope, signed, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -2,13 +2,13 @@
 package config
 
 import (
-	"os"
+	"context"
 	"strconv"
 )
 
-// Get environment variable (no default value)
+// Get environment variable, or else the secret of a provider (no default value); `Lookup` reports a provider's errors
 func GetEnv(key string) string {
-	if value, exists := os.LookupEnv(key); exists {
+	if value, exists, _ := Lookup(context.Background(), key); exists {
 		return value
 	}
 	return ""
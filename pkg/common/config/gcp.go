@@ -0,0 +1,123 @@
+// pkg/common/config/gcp.go
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+/*
+ * # GCP Secret Manager
+ * A `Provider` reading the fields of a secret version of Google Cloud Secret Manager, whose value is a JSON object, e.g.
+ * `{"OKTA_API_TOKEN": "..."}`
+ * - Authenticates with the Application Default Credentials unless `TokenSource` is set, e.g. the workload identity of
+ *   a CI/CD runner, so no service account key is held by it
+ * - https://cloud.google.com/secret-manager/docs/reference/rest/v1/projects.secrets.versions/access
+ */
+type GCPSecretManager struct {
+	Name        string             // Resource name of the secret, e.g. `projects/my-project/secrets/rego`, with `/versions/{version}` to pin one
+	TokenSource oauth2.TokenSource // Tokens of the requests; the Application Default Credentials when nil
+	Endpoint    string             // Endpoint of the API; `https://secretmanager.googleapis.com` when empty
+	TTL         time.Duration      // Time the secret is cached; 5 minutes when zero
+	HTTP        *http.Client       // Client of the requests; one with a 10 second timeout when nil
+
+	cache  secretCache
+	tokens oauth2.TokenSource // Guarded by the cache
+}
+
+// GCPSecretManagerFromEnvironment returns the secret of `REGO_GCP_SECRET`, or nil when it is not set
+func GCPSecretManagerFromEnvironment() (*GCPSecretManager, error) {
+	name := os.Getenv("REGO_GCP_SECRET")
+	if name == "" {
+		return nil, nil
+	}
+	if parts := strings.Split(strings.Trim(name, "/"), "/"); len(parts) < 4 || parts[0] != "projects" || parts[2] != "secrets" {
+		return nil, fmt.Errorf("invalid REGO_GCP_SECRET %q: want projects/{project}/secrets/{secret}", name)
+	}
+	return &GCPSecretManager{Name: name}, nil
+}
+
+// Lookup returns the field `key` of the secret, reading it again once it has been cached for `TTL`
+func (g *GCPSecretManager) Lookup(ctx context.Context, key string) (string, bool, error) {
+	return g.cache.lookup(ctx, key, g.TTL, g.read)
+}
+
+// read reads the fields of the secret
+func (g *GCPSecretManager) read(ctx context.Context) (map[string]string, error) {
+	if g.tokens == nil {
+		g.tokens = g.TokenSource
+	}
+	if g.tokens == nil {
+		// The source outlives this read, so it refreshes its tokens without its context
+		source, err := google.DefaultTokenSource(context.Background(), "https://www.googleapis.com/auth/cloud-platform")
+		if err != nil {
+			return nil, fmt.Errorf("gcp secret manager: %w", err)
+		}
+		g.tokens = source
+	}
+	token, err := g.tokens.Token()
+	if err != nil {
+		return nil, fmt.Errorf("gcp secret manager: %w", err)
+	}
+
+	name := strings.Trim(g.Name, "/")
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	endpoint := g.Endpoint
+	if endpoint == "" {
+		endpoint = "https://secretmanager.googleapis.com"
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(endpoint, "/")+"/v1/"+escapePath(name)+":access", nil)
+	if err != nil {
+		return nil, fmt.Errorf("gcp secret manager: %w", err)
+	}
+	token.SetAuthHeader(req)
+
+	client := g.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gcp secret manager: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&failure)
+		return nil, fmt.Errorf("gcp secret manager: reading %s: %d %s", name, resp.StatusCode, failure.Error.Message)
+	}
+
+	var version struct {
+		Payload struct {
+			Data string `json:"data"` // Base64
+		} `json:"payload"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return nil, fmt.Errorf("gcp secret manager: decoding %s: %w", name, err)
+	}
+	data, err := base64.StdEncoding.DecodeString(version.Payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("gcp secret manager: decoding %s: %w", name, err)
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("gcp secret manager: %s is not a JSON object of variables", name)
+	}
+	return secretFields(object)
+}
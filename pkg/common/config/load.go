@@ -0,0 +1,172 @@
+// pkg/common/config/load.go
+package config
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors of `Load`, which each variable's error wraps
+var (
+	ErrMissing = errors.New("missing config variable") // A required variable is not set
+	ErrInvalid = errors.New("invalid config variable") // A variable does not parse as its field's type
+)
+
+/*
+ * # Load
+ * Sets the fields of the struct `target` points to from their variables, read like `GetEnv` from the environment, or
+ * else the providers:
+ *
+ * ```go
+ *	var settings struct {
+ *		Host        string        `env:"DUO_API_HOST,required"`
+ *		Timeout     time.Duration `env:"DUO_TIMEOUT" default:"30s"`
+ *		Concurrency int           `env:"DUO_CONCURRENCY" default:"4"`
+ *	}
+ *	if err := config.Load(&settings); err != nil {
+ *		log.Fatal(err) // Every missing and invalid variable, e.g. `missing config variable: DUO_API_HOST is not set`
+ *	}
+ * ```
+ *
+ * - An unset (or empty) variable leaves its field with its `default`, or else its value; a `required` one is reported
+ * - Fields may be strings, bools, numbers, `time.Duration`, `[]byte`, comma-separated slices of them, or implement
+ *   `encoding.TextUnmarshaler`; a struct field tagged `envPrefix:"SANDBOX_"` is loaded with its variables prefixed
+ * - A target implementing `Validate() error` is validated once its fields are set, with its error reported too
+ * - Invalid values are not repeated in the errors, as a variable may hold a secret
+ */
+func Load(target interface{}) error {
+	return LoadPrefix(target, "")
+}
+
+// LoadPrefix loads `target` like `Load`, with its variables prefixed by `prefix`, e.g. `OKTA_SANDBOX_`
+func LoadPrefix(target interface{}, prefix string) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: Load wants a pointer to a struct, not %T", target)
+	}
+
+	errs := load(context.Background(), v.Elem(), prefix)
+	if validator, ok := target.(interface{ Validate() error }); ok {
+		if err := validator.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// load sets the fields of the struct `v`, returning the error of each variable
+func load(ctx context.Context, v reflect.Value, prefix string) []error {
+	var errs []error
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if nested, ok := field.Tag.Lookup("envPrefix"); ok && field.Type.Kind() == reflect.Struct {
+			errs = append(errs, load(ctx, v.Field(i), prefix+nested)...)
+			continue
+		}
+		tag, ok := field.Tag.Lookup("env")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		name = prefix + name
+		value, found, err := Lookup(ctx, name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reading %s: %w", name, err))
+			continue
+		}
+		if !found || value == "" {
+			def, hasDefault := field.Tag.Lookup("default")
+			switch {
+			case hasDefault:
+				value = def
+			case slices.Contains(strings.Split(options, ","), "required"):
+				errs = append(errs, fmt.Errorf("%w: %s is not set", ErrMissing, name))
+				continue
+			default:
+				continue
+			}
+		}
+
+		if err := setField(v.Field(i), value); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %v", ErrInvalid, name, err))
+		}
+	}
+	return errs
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setField parses `value` into the field `f`
+func setField(f reflect.Value, value string) error {
+	if unmarshaler, ok := f.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+	if f.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("want a duration, e.g. 30s")
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("want true or false")
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("want an integer of %d bits", f.Type().Bits())
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("want an unsigned integer of %d bits", f.Type().Bits())
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, f.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("want a number")
+		}
+		f.SetFloat(n)
+	case reflect.Slice:
+		if f.Type().Elem().Kind() == reflect.Uint8 {
+			f.SetBytes([]byte(value))
+			return nil
+		}
+		items := reflect.MakeSlice(f.Type(), 0, strings.Count(value, ",")+1)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			elem := reflect.New(f.Type().Elem()).Elem()
+			if err := setField(elem, item); err != nil {
+				return err
+			}
+			items = reflect.Append(items, elem)
+		}
+		f.Set(items)
+	default:
+		return fmt.Errorf("unsupported field type %s", f.Type())
+	}
+	return nil
+}
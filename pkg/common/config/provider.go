@@ -0,0 +1,158 @@
+// pkg/common/config/provider.go
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+/*
+ * # Provider
+ * A store of secrets, e.g. `OKTA_API_TOKEN` or `GOOGLE_SERVICE_ACCOUNT`, which `GetEnv` reads the variables missing
+ * from the environment from:
+ *
+ * ```go
+ *	config.SetProviders(&config.Vault{Addr: "https://vault.example.com:8200", Mount: "secret", Path: "rego", ...})
+ *	token := config.GetEnv("OKTA_API_TOKEN")
+ * ```
+ *
+ * - Without `SetProviders`, the stores set in the environment are read, in order: the Vault of `REGO_VAULT_PATH`, the
+ *   AWS Secrets Manager secret of `REGO_AWS_SECRET_ID` and the GCP Secret Manager secret of `REGO_GCP_SECRET`
+ * - Implementations must be safe for concurrent use, and should cache their secrets, as a variable may be read often
+ */
+type Provider interface {
+	Lookup(ctx context.Context, key string) (string, bool, error) // Returns the value of `key`, if the store holds it
+}
+
+var (
+	providersMu  sync.Mutex
+	providers    []Provider
+	providersSet bool
+)
+
+// SetProviders reads the variables missing from the environment from `p`, in order; none restores the ones of the environment
+func SetProviders(p ...Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers, providersSet = p, len(p) > 0
+}
+
+// providersOf returns the providers set, or else the ones of the environment
+func providersOf() ([]Provider, error) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if providersSet {
+		return providers, nil
+	}
+
+	var errs []error
+	found := []Provider{}
+	if vault, err := VaultFromEnvironment(); err != nil {
+		errs = append(errs, err)
+	} else if vault != nil {
+		found = append(found, vault)
+	}
+	if aws, err := AWSSecretsManagerFromEnvironment(); err != nil {
+		errs = append(errs, err)
+	} else if aws != nil {
+		found = append(found, aws)
+	}
+	if gcp, err := GCPSecretManagerFromEnvironment(); err != nil {
+		errs = append(errs, err)
+	} else if gcp != nil {
+		found = append(found, gcp)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	providers, providersSet = found, true
+	return providers, nil
+}
+
+/*
+ * # Lookup
+ * Returns the value of `key` in the environment, or else in the first provider holding it
+ * - The environment always wins, so a variable can be overridden locally without changing the store
+ */
+func Lookup(ctx context.Context, key string) (string, bool, error) {
+	if value, exists := os.LookupEnv(key); exists {
+		return value, true, nil
+	}
+
+	all, err := providersOf()
+	if err != nil {
+		return "", false, err
+	}
+	for _, p := range all {
+		value, ok, err := p.Lookup(ctx, key)
+		if err != nil {
+			return "", false, err
+		}
+		if ok {
+			return value, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+/*
+ * # Secret Cache
+ * The fields of a provider's secret, read again once they have been cached for a TTL (5 minutes when zero)
+ * - A failed read is reported for 30 seconds before it is retried, so an unreachable store does not hold every lookup
+ * - Reads are serialized, so the state of a provider's `read` (e.g. a token) is guarded by the cache
+ */
+type secretCache struct {
+	mu        sync.Mutex
+	fields    map[string]string
+	fetchedAt time.Time
+	failedAt  time.Time
+	err       error
+}
+
+// secretRetry is the time a failed read is reported before the store is asked again
+const secretRetry = 30 * time.Second
+
+// lookup returns the field `key`, reading the fields with `read` when they are missing or older than `ttl`
+func (s *secretCache) lookup(ctx context.Context, key string, ttl time.Duration, read func(context.Context) (map[string]string, error)) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ttl == 0 {
+		ttl = 5 * time.Minute
+	}
+	if s.fields == nil || time.Since(s.fetchedAt) >= ttl {
+		if s.err != nil && time.Since(s.failedAt) < secretRetry {
+			return "", false, s.err
+		}
+		fields, err := read(ctx)
+		if err != nil {
+			s.err, s.failedAt = err, time.Now()
+			return "", false, err
+		}
+		s.fields, s.fetchedAt, s.err = fields, time.Now(), nil
+	}
+
+	value, ok := s.fields[key]
+	return value, ok, nil
+}
+
+// secretFields returns the fields of a secret's JSON object; structured fields (e.g. a service account) are returned as JSON
+func secretFields(object map[string]interface{}) (map[string]string, error) {
+	fields := map[string]string{}
+	for field, value := range object {
+		if s, ok := value.(string); ok {
+			fields[field] = s
+			continue
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[field] = string(data)
+	}
+	return fields, nil
+}
@@ -0,0 +1,246 @@
+// pkg/common/config/vault.go
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+/*
+ * # Vault
+ * A `Provider` reading the fields of a secret of a HashiCorp Vault KV v2 engine, e.g. `OKTA_API_TOKEN` of `secret/rego`
+ * - Authenticates with an AppRole when `RoleID` is set, or else with `Token`
+ * - The token's lease is renewed once two thirds of it have passed, and an AppRole logs in again once it cannot be
+ * - The secret is cached like the ones of every provider (see `secretCache`)
+ */
+type Vault struct {
+	Addr         string        // Address of the server, e.g. `https://vault.example.com:8200`
+	Namespace    string        // Namespace of the secret (Vault Enterprise); the root namespace when empty
+	Mount        string        // Mount of the KV v2 engine, e.g. `secret`
+	Path         string        // Path of the secret in the engine, whose fields are the variables, e.g. `rego/okta`
+	Token        string        // Token to authenticate with, unless `RoleID` is set
+	RoleID       string        // Role of the AppRole to log in with
+	SecretID     string        // Secret ID of the AppRole
+	AppRoleMount string        // Mount of the AppRole auth method; `approle` when empty
+	TTL          time.Duration // Time the secret is cached; 5 minutes when zero
+	HTTP         *http.Client  // Client of the requests; one with a 10 second timeout when nil
+
+	cache   secretCache
+	token   string    // Guarded by the cache, like the lease
+	expires time.Time // Time the token expires; never when zero
+	renewAt time.Time // Time the token's lease is renewed; never when zero
+}
+
+/*
+ * # Vault from Environment
+ * Returns the Vault of `REGO_VAULT_PATH`, e.g. `secret/rego` (the engine's mount, then the secret's path), or nil when
+ * it is not set
+ * - `VAULT_ADDR`, `VAULT_NAMESPACE` and `VAULT_TOKEN` are read like the Vault CLI does
+ * - `VAULT_ROLE_ID` and `VAULT_SECRET_ID` log in with an AppRole instead, mounted at `VAULT_APPROLE_MOUNT`
+ */
+func VaultFromEnvironment() (*Vault, error) {
+	path := os.Getenv("REGO_VAULT_PATH")
+	if path == "" {
+		return nil, nil
+	}
+	mount, secret, ok := strings.Cut(strings.Trim(path, "/"), "/")
+	if !ok || secret == "" {
+		return nil, fmt.Errorf("invalid REGO_VAULT_PATH %q: want {mount}/{path}, e.g. secret/rego", path)
+	}
+
+	v := &Vault{
+		Addr:         os.Getenv("VAULT_ADDR"),
+		Namespace:    os.Getenv("VAULT_NAMESPACE"),
+		Mount:        mount,
+		Path:         secret,
+		Token:        os.Getenv("VAULT_TOKEN"),
+		RoleID:       os.Getenv("VAULT_ROLE_ID"),
+		SecretID:     os.Getenv("VAULT_SECRET_ID"),
+		AppRoleMount: os.Getenv("VAULT_APPROLE_MOUNT"),
+	}
+	if v.Addr == "" {
+		return nil, fmt.Errorf("REGO_VAULT_PATH is set, but VAULT_ADDR is not")
+	}
+	if v.Token == "" && v.RoleID == "" {
+		return nil, fmt.Errorf("REGO_VAULT_PATH is set, but neither VAULT_TOKEN nor VAULT_ROLE_ID is")
+	}
+	return v, nil
+}
+
+// Lookup returns the field `key` of the secret, reading it again once it has been cached for `TTL`
+func (v *Vault) Lookup(ctx context.Context, key string) (string, bool, error) {
+	return v.cache.lookup(ctx, key, v.TTL, v.read)
+}
+
+// read reads the fields of the secret, logging in again once if the token was revoked
+func (v *Vault) read(ctx context.Context) (map[string]string, error) {
+	if err := v.authenticate(ctx); err != nil {
+		return nil, err
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	path := "/v1/" + url.PathEscape(v.Mount) + "/data/" + escapePath(v.Path)
+	status, err := v.call(ctx, "GET", path, nil, &body)
+	if status == http.StatusForbidden && v.RoleID != "" {
+		v.token = ""
+		if err := v.authenticate(ctx); err != nil {
+			return nil, err
+		}
+		_, err = v.call(ctx, "GET", path, nil, &body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return secretFields(body.Data.Data)
+}
+
+// authenticate logs in, or renews the token's lease, when it is due
+func (v *Vault) authenticate(ctx context.Context) error {
+	now := time.Now()
+	switch {
+	case v.token == "", !v.expires.IsZero() && !now.Before(v.expires):
+		return v.login(ctx)
+	case !v.renewAt.IsZero() && !now.Before(v.renewAt):
+		if err := v.renew(ctx); err != nil {
+			if v.RoleID == "" {
+				return err
+			}
+			// A token past its maximum TTL can no longer be renewed, only replaced
+			return v.login(ctx)
+		}
+	}
+	return nil
+}
+
+// vaultAuth is the `auth` of a login or renewal
+type vaultAuth struct {
+	Auth struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int64  `json:"lease_duration"` // Seconds
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+}
+
+// login logs in with the AppRole, or else looks up the lease of the token
+func (v *Vault) login(ctx context.Context) error {
+	if v.RoleID == "" {
+		var body struct {
+			Data struct {
+				TTL       int64 `json:"ttl"` // Seconds; 0 for a token which never expires
+				Renewable bool  `json:"renewable"`
+			} `json:"data"`
+		}
+		v.token = v.Token
+		if _, err := v.call(ctx, "GET", "/v1/auth/token/lookup-self", nil, &body); err != nil {
+			v.token = ""
+			return err
+		}
+		v.lease(body.Data.TTL, body.Data.Renewable)
+		return nil
+	}
+
+	mount := v.AppRoleMount
+	if mount == "" {
+		mount = "approle"
+	}
+	v.token = ""
+	var body vaultAuth
+	credentials := map[string]string{"role_id": v.RoleID, "secret_id": v.SecretID}
+	if _, err := v.call(ctx, "POST", "/v1/auth/"+escapePath(mount)+"/login", credentials, &body); err != nil {
+		return err
+	}
+	v.token = body.Auth.ClientToken
+	v.lease(body.Auth.LeaseDuration, body.Auth.Renewable)
+	return nil
+}
+
+// renew renews the lease of the token
+func (v *Vault) renew(ctx context.Context) error {
+	var body vaultAuth
+	if _, err := v.call(ctx, "POST", "/v1/auth/token/renew-self", map[string]string{}, &body); err != nil {
+		return err
+	}
+	v.lease(body.Auth.LeaseDuration, body.Auth.Renewable)
+	return nil
+}
+
+// lease records the lease of the token, of `seconds` (none when 0)
+func (v *Vault) lease(seconds int64, renewable bool) {
+	v.expires, v.renewAt = time.Time{}, time.Time{}
+	if seconds <= 0 {
+		return
+	}
+	duration := time.Duration(seconds) * time.Second
+	v.expires = time.Now().Add(duration)
+	if renewable {
+		v.renewAt = time.Now().Add(duration * 2 / 3)
+	}
+}
+
+// call sends a request to the server, decoding its response into `result`; returns the status of the response
+func (v *Vault) call(ctx context.Context, method string, path string, payload interface{}, result interface{}) (int, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(v.Addr, "/")+path, body)
+	if err != nil {
+		return 0, fmt.Errorf("vault: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if v.token != "" {
+		req.Header.Set("X-Vault-Token", v.token)
+	}
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	client := v.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var failure struct {
+			Errors []string `json:"errors"`
+		}
+		json.NewDecoder(resp.Body).Decode(&failure)
+		return resp.StatusCode, fmt.Errorf("vault: %s %s: %d %s", method, path, resp.StatusCode, strings.Join(failure.Errors, "; "))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return resp.StatusCode, fmt.Errorf("vault: decoding %s: %w", path, err)
+	}
+	return resp.StatusCode, nil
+}
+
+// escapePath escapes each segment of a path
+func escapePath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
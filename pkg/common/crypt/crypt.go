@@ -3,19 +3,14 @@ package crypt
 
 import (
 	"bytes"
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"io"
 	"math"
 	"strings"
 	"unicode"
-
-	"golang.org/x/crypto/argon2"
 )
 
 const (
@@ -40,35 +35,22 @@ func generateSalt() ([]byte, error) {
 	return salt, nil
 }
 
-// deriveKey uses Argon2 to derive a key from a passphrase and a salt
-func deriveKey(passphrase, salt []byte) []byte {
-	return argon2.IDKey(passphrase, salt, 1, 64*1024, 4, 32)
-}
-
-// EncryptAES encrypts data using AES-GCM with a passphrase
+// EncryptAES encrypts data using AES-GCM with a passphrase, derived with Argon2, returning its salt, nonce and
+// ciphertext in base64
 func EncryptAES(data []byte, passphrase []byte) (string, error) {
 	salt, err := generateSalt()
 	if err != nil {
 		return "", err
 	}
-	key := deriveKey(passphrase, salt)
-
-	block, err := aes.NewCipher(key)
+	key, err := DeriveKey(passphrase, salt, Argon2ID)
 	if err != nil {
 		return "", err
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	encrypted, err := Encrypt(data, key, nil)
 	if err != nil {
 		return "", err
 	}
-
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", err
-	}
-
-	encrypted := gcm.Seal(nonce, nonce, data, nil)
 	encryptedDataWithSalt := append(salt, encrypted...)
 	return base64.StdEncoding.EncodeToString(encryptedDataWithSalt), nil
 }
@@ -85,25 +67,11 @@ func DecryptAES(data string, passphrase []byte) ([]byte, error) {
 	}
 
 	salt, encryptedData := encryptedDataWithSalt[:saltSize], encryptedDataWithSalt[saltSize:]
-	key := deriveKey(passphrase, salt)
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, err
-	}
-
-	gcm, err := cipher.NewGCM(block)
+	key, err := DeriveKey(passphrase, salt, Argon2ID)
 	if err != nil {
 		return nil, err
 	}
-
-	nonceSize := gcm.NonceSize()
-	if len(encryptedData) < nonceSize {
-		return nil, errors.New("encrypted data is too short")
-	}
-
-	nonce, ciphertext := encryptedData[:nonceSize], encryptedData[nonceSize:]
-	return gcm.Open(nil, nonce, ciphertext, nil)
+	return Decrypt(encryptedData, key, nil)
 }
 
 func ValidPassphrase(passphrase []byte) error {
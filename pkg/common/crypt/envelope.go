@@ -0,0 +1,223 @@
+// pkg/common/crypt/envelope.go
+package crypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KeySize is the size of the keys of `Encrypt` and `Decrypt`, for AES-256
+const KeySize = 32
+
+// ErrInvalidCiphertext is returned when a ciphertext or envelope is too short or malformed to be decrypted
+var ErrInvalidCiphertext = errors.New("crypt: invalid ciphertext")
+
+// KDF is a function deriving keys from passphrases
+type KDF byte
+
+const (
+	Argon2ID KDF = iota + 1 // Argon2id, with 64 MiB of memory; the KDF of `EncryptAES`
+	Scrypt                  // scrypt, with N=32768, r=8 and p=1
+)
+
+/*
+ * # DeriveKey
+ * Derives a key of `KeySize` bytes from a passphrase and a salt, e.g. of `NewSalt`
+ */
+func DeriveKey(passphrase, salt []byte, kdf KDF) ([]byte, error) {
+	switch kdf {
+	case Argon2ID:
+		return argon2.IDKey(passphrase, salt, 1, 64*1024, 4, KeySize), nil
+	case Scrypt:
+		return scrypt.Key(passphrase, salt, 1<<15, 8, 1, KeySize)
+	default:
+		return nil, fmt.Errorf("crypt: unknown KDF %d", kdf)
+	}
+}
+
+// NewSalt returns a random salt for `DeriveKey`
+func NewSalt() ([]byte, error) {
+	return generateSalt()
+}
+
+// NewKey returns a random key of `KeySize` bytes, e.g. a data key
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+/*
+ * # Encrypt
+ * Encrypts `plaintext` with AES-GCM, returning its random nonce followed by the ciphertext
+ * - `key` must be 16, 24 or 32 bytes long, e.g. of `NewKey` or `DeriveKey`
+ * - `additionalData`, which may be nil, is authenticated but not encrypted; the same must be given to `Decrypt`
+ */
+func Encrypt(plaintext, key, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Decrypt decrypts a ciphertext of `Encrypt`, failing when it, or its additional data, was modified
+func Decrypt(ciphertext, key, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, ErrInvalidCiphertext
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+/*
+ * # KeyWrapper
+ * Encrypts the data keys of envelopes with a key-encryption key, e.g. a `MasterKey`, a `PassphraseKey`, or a KMS
+ */
+type KeyWrapper interface {
+	WrapKey(dataKey []byte) ([]byte, error)
+	UnwrapKey(wrapped []byte) ([]byte, error)
+}
+
+// MasterKey wraps data keys with AES-GCM under itself, a key of `KeySize` bytes
+type MasterKey []byte
+
+func (k MasterKey) WrapKey(dataKey []byte) ([]byte, error) {
+	return Encrypt(dataKey, k, nil)
+}
+
+func (k MasterKey) UnwrapKey(wrapped []byte) ([]byte, error) {
+	return Decrypt(wrapped, k, nil)
+}
+
+/*
+ * # PassphraseKey
+ * Wraps data keys with a key derived from a passphrase, e.g. `REGO_ENCRYPTION_KEY`, with a new salt for each key
+ * - `KDF` defaults to `Argon2ID`; the KDF and salt are stored with the wrapped key, so it may change between versions
+ */
+type PassphraseKey struct {
+	Passphrase []byte
+	KDF        KDF
+}
+
+func (k PassphraseKey) WrapKey(dataKey []byte) ([]byte, error) {
+	kdf := k.KDF
+	if kdf == 0 {
+		kdf = Argon2ID
+	}
+	salt, err := generateSalt()
+	if err != nil {
+		return nil, err
+	}
+	key, err := DeriveKey(k.Passphrase, salt, kdf)
+	if err != nil {
+		return nil, err
+	}
+
+	header := append([]byte{byte(kdf)}, salt...)
+	wrapped, err := Encrypt(dataKey, key, header)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, wrapped...), nil
+}
+
+func (k PassphraseKey) UnwrapKey(wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 1+saltSize {
+		return nil, ErrInvalidCiphertext
+	}
+	header := wrapped[:1+saltSize]
+	key, err := DeriveKey(k.Passphrase, header[1:], KDF(header[0]))
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(wrapped[1+saltSize:], key, header)
+}
+
+// envelopeMagic starts each envelope, with the version of its format
+var envelopeMagic = []byte("RGE\x01")
+
+/*
+ * # Seal
+ * Encrypts `plaintext` under a new data key, which is wrapped by `kek` and stored with the ciphertext, so data can be
+ * re-keyed by rewrapping its data key alone:
+ *
+ * ```go
+ *	envelope, err := crypt.Seal(session, crypt.PassphraseKey{Passphrase: []byte(config.GetEnv("REGO_ENCRYPTION_KEY"))})
+ *	session, err = crypt.Open(envelope, kek)
+ * ```
+ *
+ * - The envelope holds `RGE\x01`, the length of the wrapped key (2 bytes, big-endian), the wrapped key, then the
+ *   ciphertext of `Encrypt`, which authenticates everything before it
+ */
+func Seal(plaintext []byte, kek KeyWrapper) ([]byte, error) {
+	dataKey, err := NewKey()
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := kek.WrapKey(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("crypt: wrapping the data key: %w", err)
+	}
+	if len(wrapped) > 0xFFFF {
+		return nil, fmt.Errorf("crypt: wrapped data key of %d bytes is too long", len(wrapped))
+	}
+
+	header := make([]byte, 0, len(envelopeMagic)+2+len(wrapped))
+	header = append(header, envelopeMagic...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(wrapped)))
+	header = append(header, wrapped...)
+
+	ciphertext, err := Encrypt(plaintext, dataKey, header)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, ciphertext...), nil
+}
+
+// Open decrypts an envelope of `Seal`, unwrapping its data key with `kek`
+func Open(envelope []byte, kek KeyWrapper) ([]byte, error) {
+	n := len(envelopeMagic)
+	if len(envelope) < n+2 || !bytes.Equal(envelope[:n], envelopeMagic) {
+		return nil, ErrInvalidCiphertext
+	}
+	end := n + 2 + int(binary.BigEndian.Uint16(envelope[n:]))
+	if len(envelope) < end {
+		return nil, ErrInvalidCiphertext
+	}
+
+	dataKey, err := kek.UnwrapKey(envelope[n+2 : end])
+	if err != nil {
+		return nil, fmt.Errorf("crypt: unwrapping the data key: %w", err)
+	}
+	return Decrypt(envelope[end:], dataKey, envelope[:end])
+}
@@ -0,0 +1,331 @@
+/*
+# Diff
+
+This package compares two versions of a resource (before and after a change, or desired and actual state) and reports
+their field-level differences, redacting sensitive fields so the differences can be logged and shared:
+
+	changes := diff.Compare(before, after, diff.Options{Ignore: []string{"lastLogin"}})
+	fmt.Print(changes)
+	// ~ profile.department: "Engineering" -> "Sales"
+	// + profile.title: "Manager"
+	// ~ credentials.password: [REDACTED] -> [REDACTED]
+
+Resources are compared through their JSON representation, so fields are named (and omitted) as the provider's API
+names them.
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/diff/diff.go
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Value reported in place of sensitive fields
+const Redacted = "[REDACTED]"
+
+// Field names which are always redacted, matched against each segment of a path without case, `_` or `-`
+var SensitiveFields = []string{"password", "secret", "token", "apikey", "privatekey", "credential", "passphrase", "pin", "recoveryquestion", "answer"}
+
+// Op is the kind of difference in a field
+type Op string
+
+const (
+	Added   Op = "add"    // The field is only set after
+	Removed Op = "remove" // The field is only set before
+	Changed Op = "change" // The field is set in both, to different values
+)
+
+// ### Diff Structs
+// ---------------------------------------------------------------------
+
+// Options configures a comparison
+type Options struct {
+	Ignore    []string // Paths which are not compared, e.g. `lastLogin`; `*` matches a single segment, e.g. `*.etag`
+	Sensitive []string // Field names redacted in addition to `SensitiveFields`, e.g. `ssn`
+	Partial   bool     // Only compare the fields set after, as for a PATCH; missing fields are assumed to be unchanged
+}
+
+// Change is a difference in a single field
+type Change struct {
+	Path     string      `json:"path"`               // Dotted path of the field, e.g. `profile.department` or `emails[0].address`
+	Op       Op          `json:"op"`                 // Kind of difference
+	Before   interface{} `json:"before,omitempty"`   // Value before, or nil if it was unset
+	After    interface{} `json:"after,omitempty"`    // Value after, or nil if it is unset
+	Redacted bool        `json:"redacted,omitempty"` // Whether the values were redacted
+}
+
+// Changes is the result of a comparison, sorted by path
+type Changes []*Change
+
+// END OF DIFF STRUCTS
+//---------------------------------------------------------------------
+
+/*
+ * # Compare two versions of a resource
+ * - Nested objects are compared field by field, and arrays element by element (or as a whole with `opts.Partial`)
+ * - Values which cannot be encoded to JSON are reported as a single change of the whole resource
+ */
+func Compare[T any](before, after T, opts Options) Changes {
+	return Values(before, after, opts)
+}
+
+// Values compares two values of any type, e.g. a typed resource and the payload of a request updating it
+func Values(before, after interface{}, opts Options) Changes {
+	changes := Changes{}
+	opts.compare(&changes, "", Normalize(before), Normalize(after))
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func (opts Options) compare(changes *Changes, prefix string, before, after interface{}) {
+	if prefix != "" && opts.ignored(prefix) {
+		return
+	}
+
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+
+	switch {
+	case afterIsMap && (beforeIsMap || before == nil):
+		for _, key := range keys(beforeMap, afterMap, opts.Partial) {
+			b, inBefore := beforeMap[key]
+			a, inAfter := afterMap[key]
+			if !inBefore && !inAfter {
+				continue
+			}
+			opts.compare(changes, join(prefix, key), b, a)
+		}
+	case beforeIsMap && after == nil && !opts.Partial:
+		for _, key := range keys(beforeMap, nil, false) {
+			opts.compare(changes, join(prefix, key), beforeMap[key], nil)
+		}
+	case beforeIsList && afterIsList && !opts.Partial:
+		for i := 0; i < len(beforeList) || i < len(afterList); i++ {
+			var b, a interface{}
+			if i < len(beforeList) {
+				b = beforeList[i]
+			}
+			if i < len(afterList) {
+				a = afterList[i]
+			}
+			opts.compare(changes, fmt.Sprintf("%s[%d]", prefix, i), b, a)
+		}
+	case !reflect.DeepEqual(before, after):
+		change := &Change{Path: prefix, Op: Changed, Before: before, After: after}
+		switch {
+		case before == nil:
+			change.Op = Added
+		case after == nil:
+			change.Op = Removed
+		}
+		opts.redact(change)
+		*changes = append(*changes, change)
+	}
+}
+
+// keys returns the keys to compare, in order
+func keys(before, after map[string]interface{}, partial bool) []string {
+	set := map[string]bool{}
+	for k := range after {
+		set[k] = true
+	}
+	if !partial {
+		for k := range before {
+			set[k] = true
+		}
+	}
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// ignored reports whether a path matches one of the ignored patterns
+func (opts Options) ignored(p string) bool {
+	for _, pattern := range opts.Ignore {
+		if ok, _ := path.Match(strings.ReplaceAll(pattern, ".", "/"), strings.ReplaceAll(p, ".", "/")); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSensitive reports whether any segment of a path names a sensitive field, e.g. `credentials.password.value`
+func (opts Options) IsSensitive(p string) bool {
+	normalize := strings.NewReplacer("_", "", "-", "")
+	for _, field := range strings.Split(p, ".") {
+		if i := strings.Index(field, "["); i >= 0 {
+			field = field[:i]
+		}
+		field = normalize.Replace(strings.ToLower(field))
+		if field == "" {
+			continue
+		}
+
+		for _, names := range [][]string{SensitiveFields, opts.Sensitive} {
+			for _, name := range names {
+				name = normalize.Replace(strings.ToLower(name))
+				if field == name || (len(name) > 3 && strings.Contains(field, name)) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (opts Options) redact(change *Change) {
+	if !opts.IsSensitive(change.Path) {
+		return
+	}
+	change.Redacted = true
+	if change.Before != nil {
+		change.Before = Redacted
+	}
+	if change.After != nil {
+		change.After = Redacted
+	}
+}
+
+/*
+ * # Redact sensitive fields
+ * Returns the generic JSON representation of a value with every sensitive field replaced by `Redacted`, e.g. to log
+ * the payload of a mutation
+ */
+func Redact(v interface{}, opts Options) interface{} {
+	return opts.redactValue("", Normalize(v))
+}
+
+func (opts Options) redactValue(prefix string, v interface{}) interface{} {
+	if prefix != "" && v != nil && opts.IsSensitive(prefix) {
+		return Redacted
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			out[key] = opts.redactValue(join(prefix, key), value)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = opts.redactValue(fmt.Sprintf("%s[%d]", prefix, i), value)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+/*
+ * # Normalize a value
+ * Converts a value into its generic JSON representation (maps, slices, strings, float64s, bools and nil)
+ * - `json.RawMessage` and `[]byte` holding JSON are decoded; other bytes are kept as a string
+ * - Values which cannot be encoded are returned unchanged
+ */
+func Normalize(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	var b []byte
+	switch v := v.(type) {
+	case json.RawMessage:
+		b = v
+	case []byte:
+		if !json.Valid(v) {
+			return string(v)
+		}
+		b = v
+	default:
+		var err error
+		if b, err = json.Marshal(v); err != nil {
+			return v
+		}
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return string(b)
+	}
+	return generic
+}
+
+// Empty reports whether there are no differences
+func (c Changes) Empty() bool {
+	return len(c) == 0
+}
+
+// Paths returns the paths of the changed fields
+func (c Changes) Paths() []string {
+	paths := make([]string, 0, len(c))
+	for _, change := range c {
+		paths = append(paths, change.Path)
+	}
+	return paths
+}
+
+// String renders the changes one per line: `+` added, `-` removed, `~` changed
+func (c Changes) String() string {
+	var sb strings.Builder
+	for _, change := range c {
+		sb.WriteString(change.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func (c *Change) String() string {
+	field := c.Path
+	if field == "" {
+		field = "(value)"
+	}
+	switch c.Op {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", field, Format(c.After))
+	case Removed:
+		return fmt.Sprintf("- %s: %s", field, Format(c.Before))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", field, Format(c.Before), Format(c.After))
+	}
+}
+
+// Format renders a value for a diff: strings are quoted, unset values are `<unset>`, and everything else is JSON
+func Format(v interface{}) string {
+	if v == nil {
+		return "<unset>"
+	}
+	if s, ok := v.(string); ok {
+		if s == Redacted {
+			return s
+		}
+		return fmt.Sprintf("%q", s)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
@@ -0,0 +1,76 @@
+/*
+# Enum
+
+This package validates the typed string enums of provider models, e.g. `okta.Status` or `backupify.AppType`, so a value
+outside of an enum fails when it is decoded from (or encoded to) an API payload instead of silently matching nothing:
+
+```go
+
+	type Status string
+
+	const (
+		ACTIVE    Status = "ACTIVE"
+		SUSPENDED Status = "SUSPENDED"
+	)
+
+	var statuses = []Status{ACTIVE, SUSPENDED}
+
+	func (s Status) MarshalText() ([]byte, error)     { return enum.Marshal(s, statuses...) }
+	func (s *Status) UnmarshalText(text []byte) error { return enum.Unmarshal(s, text, statuses...) }
+
+```
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/enum/enum.go
+package enum
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInvalid is matched (with `errors.Is`) by every value outside of its enum
+var ErrInvalid = errors.New("invalid enum value")
+
+// Valid returns whether `v` is one of `values`; the empty value of an absent field is always valid
+func Valid[E ~string](v E, values ...E) bool {
+	return v == "" || slices.Contains(values, v)
+}
+
+// Check returns an error wrapping `ErrInvalid`, naming the type of `v` and its values, unless `v` is valid
+func Check[E ~string](v E, values ...E) error {
+	if Valid(v, values...) {
+		return nil
+	}
+	return fmt.Errorf("%w: %T %q is not one of %q", ErrInvalid, v, string(v), values)
+}
+
+// Marshal encodes `v` as text, if it is valid
+func Marshal[E ~string](v E, values ...E) ([]byte, error) {
+	if err := Check(v, values...); err != nil {
+		return nil, err
+	}
+	return []byte(v), nil
+}
+
+// Unmarshal decodes `text` into `v` as the value of `values` it matches, ignoring case; `v` is left unchanged when none does
+func Unmarshal[E ~string](v *E, text []byte, values ...E) error {
+	decoded := E(text)
+	for _, value := range values {
+		if strings.EqualFold(string(value), string(decoded)) {
+			decoded = value
+			break
+		}
+	}
+	if err := Check(decoded, values...); err != nil {
+		return err
+	}
+	*v = decoded
+	return nil
+}
@@ -4,6 +4,9 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 )
 
 type CustomError struct {
@@ -18,3 +21,125 @@ func (e *CustomError) Error() string {
 func New(code int, msg string) error {
 	return &CustomError{code, errors.New(msg)}
 }
+
+// Classes of errors shared by every provider, so callers can branch on them with `errors.Is` regardless of the
+// service which returned them
+var (
+	ErrNotFound     = errors.New("not found")           // The resource does not exist
+	ErrConflict     = errors.New("conflict")            // The resource already exists, or is in a conflicting state
+	ErrForbidden    = errors.New("forbidden")           // The credentials lack the permission (or scope) for the request
+	ErrUnauthorized = errors.New("unauthorized")        // The credentials are missing, invalid or expired
+	ErrRateLimited  = errors.New("rate limited")        // The request was throttled; it can be retried later
+	ErrInvalid      = errors.New("invalid request")     // The request was rejected as malformed
+	ErrUnavailable  = errors.New("service unavailable") // The service failed or is down; it can be retried later
+)
+
+// APIError is an error response from a provider's API, classified into one of the shared error classes
+type APIError struct {
+	Provider   string        // Service which returned the error, e.g. `okta`; empty when the client does not know
+	StatusCode int           // HTTP status code of the response; 0 for errors reported in a successful response (e.g. Slack)
+	Code       string        // Provider-specific error code, e.g. `E0000007`, `user_not_found`
+	Message    string        // Error message of the provider, or the body of the response
+	Body       []byte        // Body of the response, as it was received, e.g. to decode details the provider adds
+	RetryAfter time.Duration // Time the provider asked to wait before retrying, e.g. from `Retry-After`; zero when unknown
+	Class      error         // One of the shared error classes, or nil when the error fits none of them
+}
+
+func (e *APIError) Error() string {
+	parts := []string{}
+	if e.Provider != "" {
+		parts = append(parts, e.Provider+":")
+	}
+	if e.StatusCode != 0 {
+		parts = append(parts, fmt.Sprintf("%d", e.StatusCode))
+	}
+	if e.Code != "" {
+		parts = append(parts, e.Code)
+	}
+	if len(parts) == 0 {
+		return e.Message
+	}
+	if e.Message != "" {
+		parts = append(parts, "-", e.Message)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Unwrap exposes the class of the error to `errors.Is`
+func (e *APIError) Unwrap() error {
+	return e.Class
+}
+
+/*
+ * # Classify an HTTP status code
+ * - Returns nil for successful responses and for statuses which fit no class
+ */
+func ClassOf(status int) error {
+	switch {
+	case status == http.StatusNotFound, status == http.StatusGone:
+		return ErrNotFound
+	case status == http.StatusConflict, status == http.StatusPreconditionFailed:
+		return ErrConflict
+	case status == http.StatusForbidden:
+		return ErrForbidden
+	case status == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status == http.StatusBadRequest, status == http.StatusUnprocessableEntity:
+		return ErrInvalid
+	case status >= http.StatusInternalServerError:
+		return ErrUnavailable
+	default:
+		return nil
+	}
+}
+
+// NewAPIError returns an error for a failed response, classified by its status code
+func NewAPIError(provider string, status int, code, message string) *APIError {
+	return &APIError{
+		Provider:   provider,
+		StatusCode: status,
+		Code:       code,
+		Message:    message,
+		Class:      ClassOf(status),
+	}
+}
+
+// Class returns the shared class of an error, or nil when it has none
+func Class(err error) error {
+	for _, class := range []error{ErrNotFound, ErrConflict, ErrForbidden, ErrUnauthorized, ErrRateLimited, ErrInvalid, ErrUnavailable} {
+		if errors.Is(err, class) {
+			return class
+		}
+	}
+	return nil
+}
+
+// Retryable reports whether a request which failed with the error may succeed if sent again later
+func Retryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUnavailable)
+}
+
+// RetryAfter returns the time the provider asked to wait before the request which failed with the error is retried
+func RetryAfter(err error) (time.Duration, bool) {
+	if apiErr, ok := AsAPIError(err); ok && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// AsAPIError returns the API error within an error chain, if there is one
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	ok := errors.As(err, &apiErr)
+	return apiErr, ok
+}
+
+// WithProvider records the provider of the API error within an error chain, if there is one, and returns the error
+func WithProvider(err error, provider string) error {
+	if apiErr, ok := AsAPIError(err); ok && apiErr.Provider == "" {
+		apiErr.Provider = provider
+	}
+	return err
+}
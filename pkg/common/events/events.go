@@ -0,0 +1,298 @@
+/*
+# Events
+
+This package is an in-process publish/subscribe bus for events normalized across providers, so that producers
+(webhook receivers, log pollers, orchestrators) are decoupled from consumers (notifiers, SIEM forwarders, workflows):
+
+	bus := events.NewBus(log.INFO)
+	bus.Subscribe("siem", "*", forwardToSIEM)
+	bus.Subscribe("offboarding", events.UserDeactivated, func(e *events.Event) error {
+		...
+	})
+	bus.Publish(&events.Event{Type: events.UserDeactivated, Source: "okta", Subjects: []string{"user@example.com"}})
+	defer bus.Close(ctx)
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/events/events.go
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+)
+
+var ErrClosed = errors.New("event bus is closed")
+
+// Normalized event types, shared by every producer
+const (
+	UserCreated        = "user.created"
+	UserActivated      = "user.activated"
+	UserDeactivated    = "user.deactivated"
+	UserSuspended      = "user.suspended"
+	UserDeleted        = "user.deleted"
+	UserOnboarded      = "user.onboarded"
+	UserOffboarded     = "user.offboarded"
+	GroupMemberAdded   = "group.member_added"
+	GroupMemberRemoved = "group.member_removed"
+	DeviceEnrolled     = "device.enrolled"
+	DeviceUnenrolled   = "device.unenrolled"
+	DeviceCheckedIn    = "device.checked_in"
+	WorkflowCompleted  = "workflow.completed"
+	WorkflowFailed     = "workflow.failed"
+	CredentialStale    = "credential.stale"
+	CredentialExpiring = "credential.expiring"
+	CredentialExpired  = "credential.expired"
+)
+
+// Number of events buffered for each subscriber before `Publish` blocks
+const DefaultQueueSize = 256
+
+// ### Events Structs
+// ---------------------------------------------------------------------
+
+// Event is something which happened in a provider (or in ReGo itself), normalized across providers
+type Event struct {
+	ID       string      `json:"id"`                 // Unique identifier of the event; generated by `Publish` when empty
+	Type     string      `json:"type"`               // Normalized type, e.g. `user.deactivated`; provider-specific types are `<source>.<type>`
+	Source   string      `json:"source"`             // Producer of the event, e.g. `okta`, `jamf`, `orchestrators`
+	Time     time.Time   `json:"time"`               // Time the event occurred; set by `Publish` when zero
+	Actor    string      `json:"actor,omitempty"`    // Who (or what) caused the event, if known
+	Subjects []string    `json:"subjects,omitempty"` // Identifiers of the users/devices the event is about (emails, serial numbers)
+	Data     interface{} `json:"data,omitempty"`     // Payload of the producer, e.g. a `*webhooks.Event` or `*orchestrators.Report`
+}
+
+// Handler consumes events; errors are logged by the bus
+type Handler func(*Event) error
+
+// Publisher is implemented by `Bus`, for producers which only publish
+type Publisher interface {
+	Publish(events ...*Event) error
+}
+
+// Bus delivers published events to matching subscribers
+type Bus struct {
+	Log       *log.Logger // Logger for the bus
+	QueueSize int         // Events buffered per subscription; applies to subscriptions made after it is set
+
+	mutex       sync.RWMutex
+	subscribers []*Subscription
+	closed      bool
+	publishing  sync.WaitGroup // Publishes in progress
+	workers     sync.WaitGroup // Delivery goroutines of every subscription
+}
+
+// Subscription is a consumer of the bus; each subscription receives events in order, on its own goroutine
+type Subscription struct {
+	Name    string // Name of the subscriber, for logs
+	Pattern string // Event types the subscriber receives; see `Subscribe`
+	handler Handler
+	bus     *Bus
+	queue   chan *Event
+	done    chan struct{} // Closed by `Unsubscribe` or `Close`
+	once    sync.Once
+}
+
+// END OF EVENTS STRUCTS
+//---------------------------------------------------------------------
+
+// NewBus returns a bus without any subscribers
+func NewBus(verbosity int) *Bus {
+	return &Bus{
+		Log:       log.NewLogger("{events}", verbosity),
+		QueueSize: DefaultQueueSize,
+	}
+}
+
+/*
+ * # Subscribe to events
+ * - `*` matches every event
+ * - `user.*` matches every event with a type prefix
+ * - `user.deactivated` matches a single event type
+ * Events are delivered asynchronously; a slow handler only delays its own subscription, until its queue is full and
+ * `Publish` waits for it to catch up.
+ */
+func (b *Bus) Subscribe(name, pattern string, handler Handler) (*Subscription, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("subscription %s has no handler", name)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	size := b.QueueSize
+	if size <= 0 {
+		size = DefaultQueueSize
+	}
+	s := &Subscription{
+		Name:    name,
+		Pattern: pattern,
+		handler: handler,
+		bus:     b,
+		queue:   make(chan *Event, size),
+		done:    make(chan struct{}),
+	}
+	b.subscribers = append(b.subscribers, s)
+
+	b.workers.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// Unsubscribe stops delivering new events to the subscription; events already queued are still handled
+func (s *Subscription) Unsubscribe() {
+	b := s.bus
+	b.mutex.Lock()
+	for i, sub := range b.subscribers {
+		if sub == s {
+			b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+			break
+		}
+	}
+	b.mutex.Unlock()
+	s.stop()
+}
+
+func (s *Subscription) stop() {
+	s.once.Do(func() { close(s.done) })
+}
+
+/*
+ * # Publish events
+ * - Fills in the ID and time of events which lack them
+ * - Returns once every matching subscription has queued the events, not once they are handled
+ */
+func (b *Bus) Publish(events ...*Event) error {
+	b.mutex.RLock()
+	if b.closed {
+		b.mutex.RUnlock()
+		return ErrClosed
+	}
+	subscribers := append([]*Subscription{}, b.subscribers...)
+	b.publishing.Add(1)
+	b.mutex.RUnlock()
+	defer b.publishing.Done()
+
+	for _, e := range events {
+		if e == nil {
+			continue
+		}
+		if e.ID == "" {
+			e.ID = newID()
+		}
+		if e.Time.IsZero() {
+			e.Time = time.Now().UTC()
+		}
+
+		b.Log.Debugf("Publishing %s [%s] from %s", e.Type, e.ID, e.Source)
+		for _, s := range subscribers {
+			if !Matches(s.Pattern, e.Type) {
+				continue
+			}
+			select {
+			case s.queue <- e:
+			case <-s.done:
+			}
+		}
+	}
+	return nil
+}
+
+// Matches reports whether an event type matches a subscription pattern
+func Matches(pattern, eventType string) bool {
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasSuffix(pattern, "*"):
+		return strings.HasPrefix(eventType, strings.TrimSuffix(pattern, "*"))
+	default:
+		return pattern == eventType
+	}
+}
+
+/*
+ * # Close the bus
+ * - Rejects further publishes and subscriptions with `ErrClosed`
+ * - Waits for every queued event to be handled, or for `ctx` to be done
+ */
+func (b *Bus) Close(ctx context.Context) error {
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return nil
+	}
+	b.closed = true
+	subscribers := b.subscribers
+	b.subscribers = nil
+	b.mutex.Unlock()
+
+	// Publishes in progress may still be waiting on full queues, so subscriptions keep running until they return
+	drained := make(chan struct{})
+	go func() {
+		b.publishing.Wait()
+		for _, s := range subscribers {
+			s.stop()
+		}
+		b.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("closing event bus: %w", ctx.Err())
+	}
+}
+
+// run delivers the events of a subscription until it is stopped, then handles what remains in its queue
+func (s *Subscription) run() {
+	defer s.bus.workers.Done()
+	for {
+		select {
+		case e := <-s.queue:
+			s.deliver(e)
+		case <-s.done:
+			for {
+				select {
+				case e := <-s.queue:
+					s.deliver(e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver hands an event to the handler, so that an error or panic in one subscriber does not affect the others
+func (s *Subscription) deliver(e *Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.bus.Log.Errorf("Subscriber %s panicked on %s [%s]: %v", s.Name, e.Type, e.ID, r)
+		}
+	}()
+	if err := s.handler(e); err != nil {
+		s.bus.Log.Errorf("Subscriber %s failed on %s [%s]: %v", s.Name, e.Type, e.ID, err)
+	}
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
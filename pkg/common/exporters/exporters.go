@@ -0,0 +1,416 @@
+// pkg/common/exporters/exporters.go
+package exporters
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/normalize"
+)
+
+// Sheet is a named result set; `Data` is a slice of structs (or pointers to structs), or a single struct
+type Sheet struct {
+	Name string
+	Data interface{}
+}
+
+// Table is a result set flattened into a header row and typed cells
+type Table struct {
+	Headers []string
+	Rows    [][]Cell
+}
+
+// Cell is a single value of a table, keeping its type so XLSX output can format it
+type Cell struct {
+	Kind  CellKind
+	Value interface{} // string, float64, bool or time.Time, depending on the kind
+}
+
+type CellKind int
+
+const (
+	KindString CellKind = iota
+	KindNumber
+	KindBool
+	KindTime
+)
+
+// Separator used to join slices of scalar values into a single cell
+var ListSeparator = "; "
+
+// TimeFormat used to write times to CSV
+var TimeFormat = time.RFC3339
+
+/*
+ * # Export a result set to a file
+ * - The format is chosen by the extension of `path` {.csv, .xlsx}
+ * - XLSX files get one worksheet per sheet
+ * - CSV files only hold one sheet; with multiple sheets, one file is written per sheet as `<name>-<sheet>.csv`
+ */
+func Export(path string, sheets ...Sheet) error {
+	if len(sheets) == 0 {
+		return fmt.Errorf("no sheets to export")
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".xlsx":
+		return writeFile(path, func(w io.Writer) error { return WriteXLSX(w, sheets...) })
+	case ".csv":
+		if len(sheets) == 1 {
+			return writeFile(path, func(w io.Writer) error { return WriteCSV(w, sheets[0].Data) })
+		}
+		base := strings.TrimSuffix(path, filepath.Ext(path))
+		for i, sheet := range sheets {
+			name := sheet.Name
+			if name == "" {
+				name = fmt.Sprintf("Sheet%d", i+1)
+			}
+			if err := writeFile(fmt.Sprintf("%s-%s.csv", base, name), func(w io.Writer) error { return WriteCSV(w, sheet.Data) }); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported export format: %q", ext)
+	}
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteCSV writes a result set to `w` as CSV, with a header row
+func WriteCSV(w io.Writer, data interface{}) error {
+	table, err := NewTable(data)
+	if err != nil {
+		return err
+	}
+	return table.WriteCSV(w)
+}
+
+// WriteCSV writes the table to `w` as CSV, with a header row; text which starts like a formula is quoted (see `Cell.Text`)
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Headers); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = cell.Text()
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Format is a format result sets are written in
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+/*
+ * # Write a slice of structs
+ * Writes `rows` to `w` in `format`, e.g. an audit report of Okta users or an inventory of Google devices:
+ *
+ * ```go
+ *	type row struct {
+ *		Email  string    `csv:"Email,order=1"`
+ *		Status string    `csv:"Status,order=2"`
+ *		Seen   time.Time `csv:"Last Seen"`
+ *	}
+ *	err := exporters.Write(w, exporters.XLSX, rows)
+ * ```
+ *
+ * - Columns are named and ordered by the tags of `T`, like `NewTable`
+ * - XLSX workbooks hold a single worksheet, `Sheet1`; see `WriteXLSX` for several
+ */
+func Write[T any](w io.Writer, format Format, rows []T) error {
+	switch format {
+	case CSV:
+		return WriteCSV(w, rows)
+	case XLSX:
+		return WriteXLSX(w, Sheet{Name: "Sheet1", Data: rows})
+	}
+	return fmt.Errorf("unsupported export format: %q", format)
+}
+
+/*
+ * # Flatten a result set into a table
+ * - Column names come from the `csv` tag, then the `json` tag, then the field name; fields tagged `-` are skipped
+ * - Columns are ordered like their fields, unless the `csv` tag sets their `order`, e.g. `csv:"Email,order=1"`
+ * - Nested structs are flattened into `parent.child` columns; embedded structs are flattened without a prefix
+ * - Slices of scalars are joined with `ListSeparator`; maps and slices of structs are written as JSON
+ */
+func NewTable(data interface{}) (*Table, error) {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return &Table{}, nil
+		}
+		v = v.Elem()
+	}
+
+	var rows []reflect.Value
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			rows = append(rows, v.Index(i))
+		}
+	case reflect.Struct:
+		rows = append(rows, v)
+	default:
+		return nil, fmt.Errorf("expected a slice of structs or a struct, got %v", v.Kind())
+	}
+
+	elem := v.Type()
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		elem = elem.Elem()
+	}
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a slice of structs, got a slice of %v", elem.Kind())
+	}
+
+	columns := columnsOf(elem, "", nil, map[reflect.Type]bool{})
+	table := &Table{Headers: make([]string, len(columns))}
+	for i, c := range columns {
+		table.Headers[i] = c.name
+	}
+
+	for _, row := range rows {
+		cells := make([]Cell, len(columns))
+		for i, c := range columns {
+			cells[i] = newCell(fieldByIndex(row, c.index))
+		}
+		table.Rows = append(table.Rows, cells)
+	}
+
+	return table, nil
+}
+
+type column struct {
+	name  string
+	index []int
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	normalizeTimeType = reflect.TypeOf(normalize.Time{})
+)
+
+// isTime returns whether values of `t` are written as a single time cell, rather than flattened
+func isTime(t reflect.Type) bool {
+	return t == timeType || t == normalizeTimeType
+}
+
+// columnsOf walks the exported fields of a struct type, flattening nested structs
+// - Recursive types are not flattened again, and are written as JSON instead
+// - Fields with an `order` (see `columnOrder`) come first, by their order; the others follow in declaration order
+func columnsOf(t reflect.Type, prefix string, index []int, parents map[reflect.Type]bool) []column {
+	parents[t] = true
+	defer delete(parents, t)
+
+	type group struct {
+		order   int
+		ordered bool
+		columns []column
+	}
+	groups := []group{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := columnName(field)
+		if name == "-" {
+			continue
+		}
+
+		idx := append(append([]int{}, index...), i)
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		g := group{}
+		g.order, g.ordered = columnOrder(field)
+		switch {
+		case ft.Kind() == reflect.Struct && !isTime(ft) && !parents[ft]:
+			if field.Anonymous && field.Tag.Get("csv") == "" && field.Tag.Get("json") == "" {
+				g.columns = columnsOf(ft, prefix, idx, parents)
+			} else {
+				g.columns = columnsOf(ft, prefix+name+".", idx, parents)
+			}
+		default:
+			g.columns = []column{{name: prefix + name, index: idx}}
+		}
+		groups = append(groups, g)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].ordered != groups[j].ordered {
+			return groups[i].ordered
+		}
+		return groups[i].order < groups[j].order
+	})
+
+	columns := []column{}
+	for _, g := range groups {
+		columns = append(columns, g.columns...)
+	}
+	return columns
+}
+
+func columnName(field reflect.StructField) string {
+	for _, key := range []string{"csv", "json"} {
+		if tag := strings.Split(field.Tag.Get(key), ",")[0]; tag != "" {
+			return tag
+		}
+	}
+	return field.Name
+}
+
+// columnOrder returns the position of a field's column, set with the `order` option of its `csv` tag, e.g. `csv:"Email,order=1"`
+func columnOrder(field reflect.StructField) (int, bool) {
+	for _, option := range strings.Split(field.Tag.Get("csv"), ",")[1:] {
+		if value, ok := strings.CutPrefix(option, "order="); ok {
+			if order, err := strconv.Atoi(value); err == nil {
+				return order, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// fieldByIndex follows a field index through nil pointers, returning an invalid value if any are nil
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+func newCell(v reflect.Value) Cell {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return Cell{Kind: KindString, Value: ""}
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return Cell{Kind: KindString, Value: ""}
+	}
+
+	if v.Type() == normalizeTimeType {
+		v = v.FieldByName("Time")
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return Cell{Kind: KindString, Value: ""}
+		}
+		return Cell{Kind: KindTime, Value: t}
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return Cell{Kind: KindBool, Value: v.Bool()}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Cell{Kind: KindNumber, Value: float64(v.Int())}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Cell{Kind: KindNumber, Value: float64(v.Uint())}
+	case reflect.Float32, reflect.Float64:
+		return Cell{Kind: KindNumber, Value: v.Float()}
+	case reflect.String:
+		return Cell{Kind: KindString, Value: v.String()}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return Cell{Kind: KindString, Value: ""}
+		}
+		if isScalar(v.Type().Elem()) {
+			values := make([]string, v.Len())
+			for i := range values {
+				values[i] = newCell(v.Index(i)).String()
+			}
+			return Cell{Kind: KindString, Value: strings.Join(values, ListSeparator)}
+		}
+	}
+
+	// Maps, slices of structs and anything else are written as JSON
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return Cell{Kind: KindString, Value: fmt.Sprint(v.Interface())}
+	}
+	return Cell{Kind: KindString, Value: string(b)}
+}
+
+func isScalar(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if isTime(t) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Interface:
+		return false
+	}
+	return true
+}
+
+// String formats the cell for text output
+func (c Cell) String() string {
+	switch c.Kind {
+	case KindNumber:
+		return strconv.FormatFloat(c.Value.(float64), 'f', -1, 64)
+	case KindBool:
+		return strconv.FormatBool(c.Value.(bool))
+	case KindTime:
+		return c.Value.(time.Time).Format(TimeFormat)
+	}
+	return fmt.Sprint(c.Value)
+}
+
+// Text formats the cell for a spreadsheet, prefixing text which starts like a formula (`=`, `+`, `-`, `@`) with `'`,
+// so values from the APIs, e.g. a user's name, are never evaluated when the export is opened
+func (c Cell) Text() string {
+	s := c.String()
+	if c.Kind == KindString && s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
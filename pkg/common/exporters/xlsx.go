@@ -0,0 +1,208 @@
+// pkg/common/exporters/xlsx.go
+package exporters
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cell styles defined in `styles.xml`
+const (
+	styleDefault  = 0
+	styleHeader   = 1 // Bold
+	styleDateTime = 2 // yyyy-mm-dd hh:mm:ss
+)
+
+// Excel's epoch for date serial numbers (accounting for the 1900 leap year bug)
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+const contentTypesXML = xmlHeader + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`%s</Types>`
+
+const rootRelsXML = xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const stylesXML = xmlHeader + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`</styleSheet>`
+
+/*
+ * # Write result sets to `w` as an XLSX workbook
+ * - Each sheet becomes a worksheet with a bold, frozen header row
+ * - Numbers and booleans keep their type, and times are written as Excel dates
+ */
+func WriteXLSX(w io.Writer, sheets ...Sheet) error {
+	if len(sheets) == 0 {
+		return fmt.Errorf("no sheets to export")
+	}
+
+	names := sheetNames(sheets)
+	zw := zip.NewWriter(w)
+
+	var overrides, workbookSheets, workbookRels strings.Builder
+	for i, name := range names {
+		n := i + 1
+		fmt.Fprintf(&overrides, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&workbookSheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(name), n, n)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+	}
+	fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(names)+1)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", fmt.Sprintf(contentTypesXML, overrides.String())},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", xmlHeader + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` + workbookSheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + workbookRels.String() + `</Relationships>`},
+		{"xl/styles.xml", stylesXML},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+
+	for i, sheet := range sheets {
+		table, err := NewTable(sheet.Data)
+		if err != nil {
+			return fmt.Errorf("sheet %q: %w", names[i], err)
+		}
+
+		fw, err := zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+		if err != nil {
+			return err
+		}
+		if err := writeWorksheet(fw, table); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func writeWorksheet(w io.Writer, table *Table) error {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	b.WriteString(`<sheetData>`)
+
+	b.WriteString(`<row r="1">`)
+	for col, header := range table.Headers {
+		writeCell(&b, cellRef(col, 1), Cell{Kind: KindString, Value: header}, styleHeader)
+	}
+	b.WriteString(`</row>`)
+
+	for i, row := range table.Rows {
+		r := i + 2
+		fmt.Fprintf(&b, `<row r="%d">`, r)
+		for col, cell := range row {
+			writeCell(&b, cellRef(col, r), cell, styleDefault)
+		}
+		b.WriteString(`</row>`)
+	}
+
+	b.WriteString(`</sheetData>`)
+	if len(table.Headers) > 0 {
+		fmt.Fprintf(&b, `<autoFilter ref="A1:%s"/>`, cellRef(len(table.Headers)-1, len(table.Rows)+1))
+	}
+	b.WriteString(`</worksheet>`)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeCell(b *strings.Builder, ref string, c Cell, style int) {
+	switch c.Kind {
+	case KindNumber:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, c.String())
+	case KindBool:
+		v := 0
+		if c.Value.(bool) {
+			v = 1
+		}
+		fmt.Fprintf(b, `<c r="%s" s="%d" t="b"><v>%d</v></c>`, ref, style, v)
+	case KindTime:
+		serial := c.Value.(time.Time).UTC().Sub(excelEpoch).Hours() / 24
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleDateTime, strconv.FormatFloat(serial, 'f', -1, 64))
+	default:
+		s := c.Text()
+		if s == "" && style == styleDefault {
+			return
+		}
+		fmt.Fprintf(b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(s))
+	}
+}
+
+// cellRef converts a zero-based column and one-based row into an A1 reference, e.g. (27, 3) -> AB3
+func cellRef(col, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row)
+}
+
+// sheetNames returns valid, unique worksheet names: at most 31 characters, without `[]:*?/\`
+func sheetNames(sheets []Sheet) []string {
+	replacer := strings.NewReplacer("[", "(", "]", ")", ":", "-", "*", "-", "?", "", "/", "-", `\`, "-")
+	seen := map[string]bool{}
+	names := make([]string, len(sheets))
+
+	for i, sheet := range sheets {
+		base := strings.TrimSpace(replacer.Replace(sheet.Name))
+		if base == "" {
+			base = fmt.Sprintf("Sheet%d", i+1)
+		}
+		if len([]rune(base)) > 31 {
+			base = string([]rune(base)[:31])
+		}
+
+		name := base
+		for n := 2; seen[strings.ToLower(name)]; n++ {
+			suffix := fmt.Sprintf(" (%d)", n)
+			runes := []rune(base)
+			if len(runes)+len(suffix) > 31 {
+				runes = runes[:31-len(suffix)]
+			}
+			name = string(runes) + suffix
+		}
+
+		seen[strings.ToLower(name)] = true
+		names[i] = name
+	}
+	return names
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,162 @@
+/*
+# Iterator
+
+This package provides lazy sequences over paginated list endpoints, so callers can process items as pages arrive,
+stop early, and never hold an entire directory in memory. A `Seq` has the shape of Go 1.23's `iter.Seq2[V, error]`,
+so modules on Go 1.23+ can range over it directly; earlier modules call it with a callback or use `ForEach`:
+
+	for user, err := range client.IterUsers() {
+		if err != nil { ... }
+		if user.Status == "SUSPENDED" { break } // No further pages are requested
+	}
+
+	err := iterator.ForEach(client.IterUsers(), func(user *okta.User) error { ... })
+
+Consumers which write items in bulk, e.g. to a database or CSV, can process and discard a page at a time with `OnPage`:
+
+	err := iterator.OnPage(client.IterUsers(), 200, func(page []*okta.User) error { return db.Insert(page) })
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/iterator/iterator.go
+package iterator
+
+// Seq is a lazy sequence of items; an error is yielded once, as the last element, when a page fails
+type Seq[V any] func(yield func(V, error) bool)
+
+// Fetch requests the page at `cursor` ("" for the first page), returning its items and the cursor of the next page ("" after the last)
+type Fetch[V any] func(cursor string) (items []V, next string, err error)
+
+/*
+ * # Pages
+ * Returns a sequence of the items of each page, requesting pages only as they are consumed
+ * - Requesting stops when the consumer stops, or after the first error
+ */
+func Pages[V any](fetch Fetch[V]) Seq[V] {
+	return func(yield func(V, error) bool) {
+		cursor := ""
+		for {
+			items, next, err := fetch(cursor)
+			if err != nil {
+				var zero V
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if next == "" || next == cursor {
+				return
+			}
+			cursor = next
+		}
+	}
+}
+
+// Error returns a sequence which yields only an error, e.g. for invalid queries
+func Error[V any](err error) Seq[V] {
+	return func(yield func(V, error) bool) {
+		var zero V
+		yield(zero, err)
+	}
+}
+
+// ForEach calls `fn` with each item, stopping at the first error of the sequence or of `fn`
+func ForEach[V any](seq Seq[V], fn func(V) error) error {
+	var err error
+	seq(func(v V, e error) bool {
+		if e != nil {
+			err = e
+			return false
+		}
+		err = fn(v)
+		return err == nil
+	})
+	return err
+}
+
+// Collect returns every item of a sequence, or the first error
+func Collect[V any](seq Seq[V]) ([]V, error) {
+	items := []V{}
+	err := ForEach(seq, func(v V) error {
+		items = append(items, v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Filter returns the items of a sequence which match `keep`
+func Filter[V any](seq Seq[V], keep func(V) bool) Seq[V] {
+	return func(yield func(V, error) bool) {
+		seq(func(v V, err error) bool {
+			if err != nil {
+				return yield(v, err)
+			}
+			if !keep(v) {
+				return true
+			}
+			return yield(v, nil)
+		})
+	}
+}
+
+// Take returns at most the first `n` items of a sequence; no further pages are requested
+func Take[V any](seq Seq[V], n int) Seq[V] {
+	return func(yield func(V, error) bool) {
+		if n <= 0 {
+			return
+		}
+		taken := 0
+		seq(func(v V, err error) bool {
+			if !yield(v, err) || err != nil {
+				return false
+			}
+			taken++
+			return taken < n
+		})
+	}
+}
+
+/*
+ * # On Page
+ * Calls `fn` with the items of a sequence in pages of at most `size`, so each page can be processed and discarded
+ * - At most one page is held in memory: the slice is reused, so `fn` must not retain it
+ * - Stops at the first error of the sequence or of `fn`; the items before an error of the sequence are passed to `fn` first
+ */
+func OnPage[V any](seq Seq[V], size int, fn func(page []V) error) error {
+	if size <= 0 {
+		size = 100
+	}
+
+	page := make([]V, 0, size)
+	var err error
+	seq(func(v V, e error) bool {
+		if e != nil {
+			err = e
+			return false
+		}
+		page = append(page, v)
+		if len(page) < size {
+			return true
+		}
+		err = fn(page)
+		page = page[:0]
+		return err == nil
+	})
+
+	if len(page) > 0 {
+		if ferr := fn(page); ferr != nil && err == nil {
+			err = ferr
+		}
+	}
+	clear(page[:cap(page)]) // Release the items of the last page
+	return err
+}
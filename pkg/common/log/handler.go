@@ -0,0 +1,89 @@
+// pkg/common/log/handler.go
+package log
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+)
+
+/*
+ * # Handler
+ * Receives the entries of a Logger in place of its output, so consumers can route rego's logs through their own
+ * logging implementation, e.g. `log/slog`, zap or a log aggregator
+ * - Entries below the Logger's `Verbosity` are not handled
+ */
+type Handler interface {
+	Handle(level int, prefix string, message string)
+}
+
+// HandlerFunc adapts a function to a Handler
+type HandlerFunc func(level int, prefix string, message string)
+
+func (f HandlerFunc) Handle(level int, prefix string, message string) {
+	f(level, prefix, message)
+}
+
+/*
+ * # NewHandlerLogger
+ * - creates a new Logger with the specified prefix, which writes each entry to `h` rather than stdout and `rego.log`
+ */
+func NewHandlerLogger(prefix string, verbosity int, h Handler) *Logger {
+	// If verbosity is not set, set it to INFO
+	if verbosity == 0 {
+		verbosity = INFO
+	}
+
+	return &Logger{
+		prefix:    prefix,
+		logger:    log.New(io.Discard, "", 0),
+		Verbosity: verbosity,
+		handler:   h,
+	}
+}
+
+// SetHandler routes the entries of the logger to `h`; a nil handler restores its output
+func (l *Logger) SetHandler(h Handler) {
+	l.handler = h
+}
+
+/*
+ * # SlogHandler
+ * Returns a Handler which writes entries to a `log/slog` Logger, with the prefix as the `logger` attribute
+ * - TRACE is written below `slog.LevelDebug`, and FATAL and PANIC above `slog.LevelError`
+ * - The attributes of structured entries are passed on to `s`, so its handler (e.g. `slog.JSONHandler`) encodes them
+ */
+func SlogHandler(s *slog.Logger) Handler {
+	return slogHandler{s}
+}
+
+type slogHandler struct {
+	logger *slog.Logger
+}
+
+func (h slogHandler) Handle(level int, prefix string, message string) {
+	h.HandleAttrs(level, prefix, message, nil)
+}
+
+func (h slogHandler) HandleAttrs(level int, prefix string, message string, attrs []slog.Attr) {
+	h.logger.LogAttrs(context.Background(), SlogLevel(level), message, append([]slog.Attr{slog.String("logger", prefix)}, attrs...)...)
+}
+
+// SlogLevel returns the `log/slog` level of a log level
+func SlogLevel(level int) slog.Level {
+	switch level {
+	case TRACE:
+		return slog.LevelDebug - 4
+	case DEBUG:
+		return slog.LevelDebug
+	case INFO:
+		return slog.LevelInfo
+	case WARNING:
+		return slog.LevelWarn
+	case ERROR:
+		return slog.LevelError
+	default:
+		return slog.LevelError + 4
+	}
+}
@@ -0,0 +1,176 @@
+// pkg/common/log/levels.go
+package log
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+/*
+ * # Levels
+ * Levels set by the name of the loggers (their prefix without braces, e.g. `okta` of `{okta}`), which replace the
+ * verbosity they were created with while the program runs:
+ *
+ * ```go
+ *	log.SetLevel("okta", log.DEBUG)
+ *	log.SetLevels("okta=DEBUG,google=INFO,*=WARNING") // Or with `REGO_LOG_LEVELS`, read at startup
+ *	stop := log.ReloadOnSIGHUP("/etc/rego/log_levels", nil)
+ * ```
+ *
+ * - `*` is the level of every logger without a level of its own
+ * - The request logs of every client are written by the `requests` logger
+ */
+var levels atomic.Pointer[map[string]int]
+
+var levelsMu sync.Mutex // Serializes the updates of `levels`
+
+func init() {
+	if spec := os.Getenv("REGO_LOG_LEVELS"); spec != "" {
+		if err := SetLevels(spec); err != nil {
+			fmt.Fprintln(os.Stderr, "rego: ignoring REGO_LOG_LEVELS:", err)
+		}
+	}
+}
+
+// SetLevel sets the level of the loggers named `name`, or of every logger without a level of its own for `*`
+func SetLevel(name string, level int) {
+	updateLevels(func(m map[string]int) { m[strings.ToLower(name)] = level })
+}
+
+// ResetLevel restores the verbosity the loggers named `name` were created with
+func ResetLevel(name string) {
+	updateLevels(func(m map[string]int) { delete(m, strings.ToLower(name)) })
+}
+
+// Levels returns the levels set, by the names of their loggers
+func Levels() map[string]int {
+	m := map[string]int{}
+	if current := levels.Load(); current != nil {
+		for name, level := range *current {
+			m[name] = level
+		}
+	}
+	return m
+}
+
+/*
+ * # SetLevels
+ * Replaces the levels set with the ones of `spec`, e.g. `okta=DEBUG,google=INFO`, separated by commas or lines
+ * - Nothing is changed when an entry is invalid
+ */
+func SetLevels(spec string) error {
+	parsed := map[string]int{}
+	for _, entry := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if entry = strings.TrimSpace(entry); entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid log level %q: want {logger}={level}, e.g. okta=DEBUG", entry)
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return err
+		}
+		parsed[strings.ToLower(strings.TrimSpace(name))] = level
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	levels.Store(&parsed)
+	return nil
+}
+
+// ParseLevel returns the level of its name, e.g. `DEBUG` or `warning`, or of its number
+func ParseLevel(s string) (int, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	for level := TRACE; level <= PANIC; level++ {
+		if LogLevel(level, false) == s {
+			return level, nil
+		}
+	}
+	if s == "WARN" {
+		return WARNING, nil
+	}
+	if level, err := strconv.Atoi(s); err == nil && level >= TRACE && level <= PANIC {
+		return level, nil
+	}
+	return 0, fmt.Errorf("invalid log level %q: want TRACE, DEBUG, INFO, WARNING, ERROR, FATAL or PANIC", s)
+}
+
+/*
+ * # ReloadOnSIGHUP
+ * Sets the levels from the file at `path` (see `SetLevels`) each time the process receives SIGHUP, e.g. after
+ * `kill -HUP`, so a long-running sync can be debugged without a restart
+ * - The errors of reading and parsing the file are passed to `onError`, when set; the levels are then left as they were
+ * - Returns a function which stops reloading
+ */
+func ReloadOnSIGHUP(path string, onError func(error)) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				spec, err := os.ReadFile(path)
+				if err == nil {
+					err = SetLevels(string(spec))
+				}
+				if err != nil && onError != nil {
+					onError(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
+
+// updateLevels replaces the levels with a copy changed by `change`
+func updateLevels(change func(map[string]int)) {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	m := Levels()
+	change(m)
+	levels.Store(&m)
+}
+
+/*
+ * # log.Level
+ * - returns the level of the logger: the one set for its name with `SetLevel`, or else for `*`, or else its `Verbosity`
+ */
+func (l *Logger) Level() int {
+	if current := levels.Load(); current != nil && len(*current) > 0 {
+		if level, ok := (*current)[strings.ToLower(strings.Trim(l.prefix, "{}"))]; ok {
+			return level
+		}
+		if level, ok := (*current)["*"]; ok {
+			return level
+		}
+	}
+	return l.Verbosity
+}
+
+/*
+ * # log.Enabled
+ * - reports whether entries at `level` are written, e.g. before building an expensive DEBUG entry
+ */
+func (l *Logger) Enabled(level int) bool {
+	return level >= l.Level()
+}
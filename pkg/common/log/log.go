@@ -5,9 +5,9 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"os"
-	"path/filepath"
-	"runtime"
+	"strings"
 	"time"
 )
 
@@ -98,7 +98,10 @@ type Logger struct {
 	prefix    string         // prefix to write at beginning of each log line
 	logger    *log.Logger    // standard logger
 	out       io.WriteCloser // destination for output
-	Verbosity int            // log level {TRACE, DEBUG, INFO, WARNING, ERROR, FATAL, PANIC}
+	Verbosity int            // log level {TRACE, DEBUG, INFO, WARNING, ERROR, FATAL, PANIC}, unless one is set for its name with `SetLevel`
+	JSON      bool           // write each entry as a JSON object, e.g. for Splunk or Datadog; see `REGO_LOG_FORMAT`
+	handler   Handler        // receives entries in place of the output, when set
+	attrs     []slog.Attr    // attributes of every entry, added with `With`
 }
 
 /*
@@ -106,9 +109,8 @@ type Logger struct {
 - logs formatted message at specified level
 */
 func (l *Logger) logf(level int, format string, v ...interface{}) {
-	if level >= l.Verbosity {
-		l.logger.SetPrefix(l.getPrefix(level))
-		l.logger.Printf(format, v...)
+	if l.Enabled(level) {
+		l.emit(level, Redact(fmt.Sprintf(format, v...)), nil, 3)
 	}
 }
 
@@ -117,9 +119,8 @@ func (l *Logger) logf(level int, format string, v ...interface{}) {
  * - logs message at specified level
  */
 func (l *Logger) log(level int, v ...interface{}) {
-	if level >= l.Verbosity {
-		l.logger.SetPrefix(l.getPrefix(level))
-		l.logger.Println(v...)
+	if l.Enabled(level) {
+		l.emit(level, Redact(strings.TrimSuffix(fmt.Sprintln(v...), "\n")), nil, 3)
 	}
 }
 
@@ -272,13 +273,7 @@ func (l *Logger) Delete() error {
 	return nil
 }
 
-func (l *Logger) getPrefix(level int) string {
-	// Get the caller's filename and line number
-	_, path, line, _ := runtime.Caller(3)
-
-	// Get just the filename
-	file := filepath.Base(path)
-
+func (l *Logger) getPrefix(level int, file string, line int) string {
 	// Formatted current timestamp
 	timestamp := time.Now().Format("2006/01/02 03:04:05 PM")
 
@@ -323,16 +318,23 @@ func (l *Logger) SetNewFile(logFilePath string) {
 /*
  * # NewLogger
  * - creates a new Logger with the specified prefix
+ * - `REGO_LOG_FORMAT=json` writes its entries as JSON objects
+ * - Entries are written to stdout and `./rego.log`, or the file of `SetDefaultFile`, e.g. a `RotatingFile`
  */
 func NewLogger(prefix string, verbosity int) *Logger {
-	LOG_FILE := "./rego.log"
-	logFile, err := os.OpenFile(LOG_FILE, os.O_APPEND|os.O_RDWR|os.O_CREATE, 0644)
-	if err != nil {
-		log.Panic(err)
+	var logger *log.Logger
+	var logFile io.WriteCloser
+	if w := defaultFile.Load(); w != nil {
+		logger = log.New(io.MultiWriter(os.Stdout, *w), "", 0)
+	} else {
+		LOG_FILE := "./rego.log"
+		file, err := os.OpenFile(LOG_FILE, os.O_APPEND|os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			log.Panic(err)
+		}
+		logger = log.New(io.MultiWriter(os.Stdout, file), "", 0)
+		logFile = file
 	}
-	logOut := io.MultiWriter(os.Stdout, logFile)
-
-	logger := log.New(logOut, "", 0)
 
 	// If verbosity is not set, set it to INFO
 	if verbosity == 0 {
@@ -345,5 +347,6 @@ func NewLogger(prefix string, verbosity int) *Logger {
 		logger:    logger,
 		out:       logFile,
 		Verbosity: verbosity,
+		JSON:      strings.EqualFold(os.Getenv("REGO_LOG_FORMAT"), "json"),
 	}
 }
@@ -0,0 +1,148 @@
+// pkg/common/log/redact.go
+package log
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// Redacted replaces the secrets masked in the logs
+const Redacted = "[REDACTED]"
+
+// DefaultRedactedHeaders are the headers whose values are masked by the default redactor
+var DefaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+	"X-Csrf-Token",
+}
+
+// DefaultRedactedFields are the body fields (JSON, form or query) whose values are masked by the default redactor
+var DefaultRedactedFields = []string{
+	"password",
+	"client_secret",
+	"secret",
+	"access_token",
+	"refresh_token",
+	"id_token",
+	"token",
+	"api_key",
+	"apikey",
+	"private_key",
+	"assertion",
+	"client_assertion",
+}
+
+var (
+	// credentials matches the credentials of an authorization scheme, e.g. Okta's `SSWS` tokens
+	credentials = regexp.MustCompile(`\b(SSWS|Bearer|Basic)(\s+)[A-Za-z0-9\-._~+/]{8,}=*`)
+
+	// jwt matches JSON Web Tokens, e.g. a Google assertion, wherever they appear
+	jwt = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+)
+
+/*
+ * # Redactor
+ * Masks secrets in the entries of every Logger before they are written or handled, so DEBUG and TRACE logs of requests
+ * and responses can be shared
+ * - The credentials of the `SSWS`, `Bearer` and `Basic` schemes, and JSON Web Tokens, are always masked
+ * - The values of `Headers` are masked, whether written as `Name: value` or as an `http.Header`
+ * - The values of `Fields` are masked in JSON (`"password":"..."`), form and query (`password=...`) bodies
+ */
+type Redactor struct {
+	headers *regexp.Regexp
+	json    *regexp.Regexp
+	form    *regexp.Regexp
+	names   map[string]bool // Canonical headers
+}
+
+// NewRedactor returns a redactor masking the values of `headers` and `fields`, matched case-insensitively
+func NewRedactor(headers []string, fields []string) *Redactor {
+	r := &Redactor{names: map[string]bool{}}
+	if len(headers) > 0 {
+		quoted := make([]string, len(headers))
+		for i, h := range headers {
+			quoted[i] = regexp.QuoteMeta(h)
+			r.names[http.CanonicalHeaderKey(h)] = true
+		}
+		r.headers = regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)(:\s*\[?)[^\[\]\r\n]+`)
+	}
+	if len(fields) > 0 {
+		quoted := make([]string, len(fields))
+		for i, f := range fields {
+			quoted[i] = regexp.QuoteMeta(f)
+		}
+		names := strings.Join(quoted, "|")
+		r.json = regexp.MustCompile(`(?i)("(?:` + names + `)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+		r.form = regexp.MustCompile(`(?i)(^|[?&\s])(` + names + `)=[^&\s"]*`)
+	}
+	return r
+}
+
+// Redact returns `s` with its secrets masked
+func (r *Redactor) Redact(s string) string {
+	if r == nil {
+		return s
+	}
+	if r.headers != nil {
+		s = r.headers.ReplaceAllString(s, "${1}${2}"+Redacted)
+	}
+	s = credentials.ReplaceAllString(s, "${1}${2}"+Redacted)
+	s = jwt.ReplaceAllString(s, Redacted)
+	if r.json != nil {
+		s = r.json.ReplaceAllString(s, `${1}"`+Redacted+`"`)
+		s = r.form.ReplaceAllString(s, "${1}${2}="+Redacted)
+	}
+	return s
+}
+
+// RedactHeader returns a copy of `h` with the values of the redacted headers, and the credentials of the others, masked
+func (r *Redactor) RedactHeader(h http.Header) http.Header {
+	redacted := h.Clone()
+	if r == nil {
+		return redacted
+	}
+	for name, values := range redacted {
+		for i, v := range values {
+			if r.names[http.CanonicalHeaderKey(name)] {
+				values[i] = Redacted
+			} else {
+				values[i] = r.Redact(v)
+			}
+		}
+	}
+	return redacted
+}
+
+var redactor atomic.Pointer[Redactor]
+
+func init() {
+	redactor.Store(NewRedactor(DefaultRedactedHeaders, DefaultRedactedFields))
+}
+
+/*
+ * # SetRedactor
+ * Sets the redactor of every Logger, e.g. to mask the fields of a provider's API in addition to the defaults:
+ *
+ *	log.SetRedactor(log.NewRedactor(log.DefaultRedactedHeaders, append(log.DefaultRedactedFields, "recovery_answer")))
+ *
+ * - A nil redactor writes the entries as they are, which should only be done locally
+ */
+func SetRedactor(r *Redactor) {
+	redactor.Store(r)
+}
+
+// Redact returns `s` with its secrets masked by the redactor of the Loggers
+func Redact(s string) string {
+	return redactor.Load().Redact(s)
+}
+
+// RedactHeader returns a copy of `h` masked by the redactor of the Loggers, e.g. to log the headers of a request
+func RedactHeader(h http.Header) http.Header {
+	return redactor.Load().RedactHeader(h)
+}
@@ -0,0 +1,246 @@
+// pkg/common/log/rotate.go
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+ * # Rotating File
+ * A log file which is rotated once it grows past `MaxSize`, or once it was opened `Interval` ago, so a long-running
+ * daemon needs no logrotate:
+ *
+ * ```go
+ *	f := &log.RotatingFile{Path: "/var/log/rego/rego.log", MaxSize: 100 << 20, Interval: 24 * time.Hour, MaxBackups: 7, Compress: true}
+ *	defer f.Close()
+ *	log.SetDefaultFile(f) // Every Logger created after, e.g. by the clients, writes to it
+ * ```
+ *
+ * - Rotated files are renamed with the time they were rotated, e.g. `rego-20240102T150405.000.log`, and compressed with
+ *   gzip in the background when `Compress` is set; a file rotated within the same millisecond as an earlier one takes
+ *   the next free millisecond, so no rotated file is ever replaced and the names still sort by rotation
+ * - The file is safe for concurrent use, so several loggers may share it; it is opened on its first write
+ */
+type RotatingFile struct {
+	Path       string        // File the entries are written to
+	MaxSize    int64         // Bytes the file may hold before it is rotated; no limit when zero
+	Interval   time.Duration // Time after which the file is rotated, since it was opened, e.g. `24 * time.Hour`; never when zero
+	MaxBackups int           // Rotated files kept, removing the oldest first; every one when zero
+	MaxAge     time.Duration // Age past which rotated files are removed; never when zero
+	Compress   bool          // Compresses rotated files with gzip
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	opened  time.Time
+	pending sync.WaitGroup // Compressions and removals of rotated files
+}
+
+// rotatedFormat is the timestamp of rotated files, sortable by their names
+const rotatedFormat = "20060102T150405.000"
+
+// Write writes an entry to the file, rotating it first when the entry would take it past `MaxSize`, or it is too old
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	tooLarge := f.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.MaxSize
+	tooOld := f.Interval > 0 && time.Since(f.opened) >= f.Interval
+	if tooLarge || tooOld {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file, even if it is below `MaxSize` and `Interval`
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+	return f.rotate()
+}
+
+// Close closes the file, once the rotated files are compressed
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending.Wait()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens the file for appending, continuing an existing one
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size, f.opened = file, info.Size(), time.Now()
+	return nil
+}
+
+// rotate renames the file with the current time and opens a new one; the file must be open
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	rotated := f.rotatedName(time.Now())
+	if err := os.Rename(f.Path, rotated); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.pending.Add(1)
+	go func() {
+		defer f.pending.Done()
+		if f.Compress {
+			compress(rotated)
+		}
+		f.prune()
+	}()
+	return nil
+}
+
+// rotatedName returns the name of a file rotated at `at`, or at the first later millisecond no rotated file is named by
+func (f *RotatingFile) rotatedName(at time.Time) string {
+	ext := filepath.Ext(f.Path)
+	for {
+		name := strings.TrimSuffix(f.Path, ext) + "-" + at.Format(rotatedFormat) + ext
+		if !exists(name) && !exists(name+".gz") {
+			return name
+		}
+		at = at.Add(time.Millisecond)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return !os.IsNotExist(err)
+}
+
+// compress replaces a rotated file with its gzip; the file is kept as it is when it cannot be compressed
+func compress(path string) {
+	src, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path + ".gz")
+		return
+	}
+	os.Remove(path)
+}
+
+// prune removes the rotated files past `MaxBackups` or `MaxAge`
+func (f *RotatingFile) prune() {
+	if f.MaxBackups <= 0 && f.MaxAge <= 0 {
+		return
+	}
+	ext := filepath.Ext(f.Path)
+	matches, err := filepath.Glob(strings.TrimSuffix(f.Path, ext) + "-*" + ext + "*")
+	if err != nil {
+		return
+	}
+
+	// Names sort by the time they were rotated, so the newest come first once reversed; a file being compressed is
+	// listed once, as its gzip is not complete
+	rotated := []string{}
+	for _, m := range matches {
+		if name := strings.TrimSuffix(m, ".gz"); strings.HasSuffix(name, ext) && (len(rotated) == 0 || rotated[len(rotated)-1] != name) {
+			rotated = append(rotated, name)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(rotated)))
+
+	for i, name := range rotated {
+		expired := false
+		if f.MaxAge > 0 {
+			info, err := os.Stat(name)
+			if err != nil {
+				info, err = os.Stat(name + ".gz")
+			}
+			if err == nil && time.Since(info.ModTime()) > f.MaxAge {
+				expired = true
+			}
+		}
+		if (f.MaxBackups > 0 && i >= f.MaxBackups) || expired {
+			os.Remove(name)
+			os.Remove(name + ".gz")
+		}
+	}
+}
+
+var defaultFile atomic.Pointer[io.Writer]
+
+// SetDefaultFile writes the entries of the Loggers created after, with stdout, to `w` rather than `./rego.log`; nil restores it
+func SetDefaultFile(w io.Writer) {
+	if w == nil {
+		defaultFile.Store(nil)
+		return
+	}
+	defaultFile.Store(&w)
+}
+
+/*
+ * # log.SetRotatingFile
+ * - writes the entries of the logger to stdout and `f`, in place of its file, e.g. a `RotatingFile` shared by several
+ *   loggers; closing the logger leaves `f` open
+ */
+func (l *Logger) SetRotatingFile(f *RotatingFile) {
+	l.Close()
+	l.logger = log.New(io.MultiWriter(os.Stdout, f), "", 0)
+	l.out = nil
+}
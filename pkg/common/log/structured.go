@@ -0,0 +1,110 @@
+// pkg/common/log/structured.go
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+/*
+ * # AttrHandler
+ * A Handler which receives the attributes of structured entries (see `LogAttrs` and `With`), rather than them being
+ * written into the message
+ */
+type AttrHandler interface {
+	Handler
+	HandleAttrs(level int, prefix string, message string, attrs []slog.Attr)
+}
+
+/*
+ * # log.With
+ * - returns a Logger writing to the same output, which adds `attrs` to each of its entries, e.g. the service of a client
+ */
+func (l *Logger) With(attrs ...slog.Attr) *Logger {
+	child := *l
+	child.attrs = append(slices.Clip(l.attrs), attrs...)
+	return &child
+}
+
+/*
+ * # log.LogAttrs
+ * - logs a structured entry at the specified level, with `attrs` as fields of its JSON object, or `key=value` pairs
+ *   after its message
+ */
+func (l *Logger) LogAttrs(level int, message string, attrs ...slog.Attr) {
+	if l.Enabled(level) {
+		l.emit(level, Redact(message), attrs, 2)
+	}
+}
+
+// emit writes an entry, of the caller `skip` frames above it
+func (l *Logger) emit(level int, message string, attrs []slog.Attr, skip int) {
+	attrs = redactAttrs(append(slices.Clip(l.attrs), attrs...))
+	if h, ok := l.handler.(AttrHandler); ok {
+		h.HandleAttrs(level, l.prefix, message, attrs)
+		return
+	}
+	if l.handler != nil {
+		l.handler.Handle(level, l.prefix, message+formatAttrs(attrs))
+		return
+	}
+
+	_, path, line, _ := runtime.Caller(skip)
+	file := filepath.Base(path)
+
+	if l.JSON {
+		l.writeJSON(level, message, attrs, fmt.Sprintf("%s:%d", file, line))
+		return
+	}
+	l.logger.SetPrefix(l.getPrefix(level, file, line))
+	l.logger.Print(message + formatAttrs(attrs))
+}
+
+/*
+ * Writes an entry as a JSON object, e.g.
+ * `{"time":"...","level":"DEBUG","msg":"Request","logger":"okta","caller":"debug.go:19","service":"okta","status":200}`
+ */
+func (l *Logger) writeJSON(level int, message string, attrs []slog.Attr, caller string) {
+	h := slog.NewJSONHandler(l.logger.Writer(), &slog.HandlerOptions{
+		Level: slog.LevelDebug - 8,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.LevelKey {
+				return slog.String(slog.LevelKey, LogLevel(level, false))
+			}
+			return a
+		},
+	})
+
+	r := slog.NewRecord(time.Now(), SlogLevel(level), message, 0)
+	r.AddAttrs(slog.String("logger", strings.Trim(l.prefix, "{}")), slog.String("caller", caller))
+	r.AddAttrs(attrs...)
+	h.Handle(context.Background(), r)
+}
+
+// formatAttrs returns the `key=value` pairs of attributes, each after a space
+func formatAttrs(attrs []slog.Attr) string {
+	b := strings.Builder{}
+	for _, a := range attrs {
+		b.WriteString(" " + a.String())
+	}
+	return b.String()
+}
+
+// redactAttrs masks the secrets of the string values of attributes, like the ones of messages
+func redactAttrs(attrs []slog.Attr) []slog.Attr {
+	for i, a := range attrs {
+		switch a.Value.Kind() {
+		case slog.KindString:
+			attrs[i] = slog.String(a.Key, Redact(a.Value.String()))
+		case slog.KindAny:
+			attrs[i] = slog.String(a.Key, Redact(fmt.Sprint(a.Value.Any())))
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,89 @@
+/*
+# Metrics
+
+This package records the metrics of rego's clients (requests, rate limiting and caching), through a pluggable `Recorder`
+so they can be exported to any monitoring system; `Prometheus` exposes them in the Prometheus text exposition format:
+
+```go
+
+	p := metrics.NewPrometheus()
+	metrics.SetRecorder(p)
+	http.Handle("/metrics", p)
+
+```
+
+Nothing is recorded until a recorder is set.
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/metrics/metrics.go
+package metrics
+
+import (
+	"sync/atomic"
+)
+
+// Metrics recorded by rego
+const (
+	RequestsTotal         = "rego_requests_total"           // Requests sent, by `host`, `method` and `status` (`error` when no response was received)
+	RequestDuration       = "rego_request_duration_seconds" // Time waiting on the API for each request, by `host` and `method`
+	RateLimitWaitsTotal   = "rego_ratelimit_waits_total"    // Sleeps of a rate limiter holding a request back, by `limiter` (`ratelimiter` or `queue`)
+	RateLimitWaitDuration = "rego_ratelimit_wait_seconds"   // Duration of each sleep of a rate limiter, by `limiter`
+	CacheHitsTotal        = "rego_cache_hits_total"         // Lookups found in a cache, by `cache`
+	CacheMissesTotal      = "rego_cache_misses_total"       // Lookups missing from a cache, or expired, by `cache`
+)
+
+// Help describes each metric recorded by rego, for the exporters which document them
+var Help = map[string]string{
+	RequestsTotal:         "Number of requests sent to the APIs.",
+	RequestDuration:       "Time spent waiting on the APIs for a response.",
+	RateLimitWaitsTotal:   "Number of times a rate limiter held a request back.",
+	RateLimitWaitDuration: "Time a rate limiter held requests back.",
+	CacheHitsTotal:        "Number of lookups found in a cache.",
+	CacheMissesTotal:      "Number of lookups missing from a cache, or expired.",
+}
+
+// Label is a dimension of a metric, e.g. the host of a request
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Recorder receives the metrics; it is called concurrently
+type Recorder interface {
+	Add(name string, value float64, labels ...Label)     // Adds `value` to a counter
+	Observe(name string, value float64, labels ...Label) // Records `value` in a histogram, e.g. a duration in seconds
+}
+
+var recorder atomic.Pointer[Recorder]
+
+// SetRecorder records the metrics of every client with `r`, e.g. at startup; nil stops recording
+func SetRecorder(r Recorder) {
+	if r == nil {
+		recorder.Store(nil)
+		return
+	}
+	recorder.Store(&r)
+}
+
+// Enabled reports whether a recorder is set, so callers can skip the work of measuring otherwise
+func Enabled() bool {
+	return recorder.Load() != nil
+}
+
+// Add adds `value` to the counter `name` of the recorder, if one is set
+func Add(name string, value float64, labels ...Label) {
+	if r := recorder.Load(); r != nil {
+		(*r).Add(name, value, labels...)
+	}
+}
+
+// Observe records `value` in the histogram `name` of the recorder, if one is set
+func Observe(name string, value float64, labels ...Label) {
+	if r := recorder.Load(); r != nil {
+		(*r).Observe(name, value, labels...)
+	}
+}
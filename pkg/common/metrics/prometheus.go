@@ -0,0 +1,175 @@
+// pkg/common/metrics/prometheus.go
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds of the histograms, in seconds, from a fast API call to a long rate limit window
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Prometheus is a recorder keeping the metrics in memory, and exposing them in the Prometheus text exposition format
+type Prometheus struct {
+	Buckets []float64 // Upper bounds of the histograms; `DefaultBuckets` when empty
+
+	mutex      sync.Mutex
+	counters   map[string]map[string]float64    // Value of each counter, by name and labels
+	histograms map[string]map[string]*histogram // Observations of each histogram, by name and labels
+}
+
+// histogram counts the observations of a histogram in each bucket
+type histogram struct {
+	counts []uint64 // Observations at most each bucket's bound, not cumulated
+	count  uint64
+	sum    float64
+}
+
+// NewPrometheus returns an empty Prometheus recorder
+func NewPrometheus() *Prometheus {
+	return &Prometheus{
+		counters:   map[string]map[string]float64{},
+		histograms: map[string]map[string]*histogram{},
+	}
+}
+
+func (p *Prometheus) Add(name string, value float64, labels ...Label) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.counters == nil {
+		p.counters = map[string]map[string]float64{}
+	}
+	if p.counters[name] == nil {
+		p.counters[name] = map[string]float64{}
+	}
+	p.counters[name][format(labels)] += value
+}
+
+func (p *Prometheus) Observe(name string, value float64, labels ...Label) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.histograms == nil {
+		p.histograms = map[string]map[string]*histogram{}
+	}
+	if p.histograms[name] == nil {
+		p.histograms[name] = map[string]*histogram{}
+	}
+	key := format(labels)
+	h, ok := p.histograms[name][key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(p.buckets()))}
+		p.histograms[name][key] = h
+	}
+	if i, _ := slices.BinarySearch(p.buckets(), value); i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += value
+}
+
+/*
+ * # Write Metrics
+ * Writes every metric in the Prometheus text exposition format, e.g.
+ *
+ *	rego_requests_total{host="example.okta.com",method="GET",status="200"} 42
+ *	rego_ratelimit_wait_seconds_sum{limiter="okta"} 12.5
+ */
+func (p *Prometheus) WriteMetrics(w io.Writer) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var sb strings.Builder
+	for _, name := range sortedKeys(p.counters) {
+		header(&sb, name, "counter")
+		for _, labels := range sortedKeys(p.counters[name]) {
+			fmt.Fprintf(&sb, "%s%s %s\n", name, labels, number(p.counters[name][labels]))
+		}
+	}
+	for _, name := range sortedKeys(p.histograms) {
+		header(&sb, name, "histogram")
+		for _, labels := range sortedKeys(p.histograms[name]) {
+			h := p.histograms[name][labels]
+			cumulative := uint64(0)
+			for i, bound := range p.buckets() {
+				cumulative += h.counts[i]
+				fmt.Fprintf(&sb, "%s_bucket%s %d\n", name, withLabel(labels, "le", number(bound)), cumulative)
+			}
+			fmt.Fprintf(&sb, "%s_bucket%s %d\n", name, withLabel(labels, "le", "+Inf"), h.count)
+			fmt.Fprintf(&sb, "%s_sum%s %s\n", name, labels, number(h.sum))
+			fmt.Fprintf(&sb, "%s_count%s %d\n", name, labels, h.count)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// ServeHTTP serves the metrics, e.g. on `/metrics`
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteMetrics(w)
+}
+
+func (p *Prometheus) buckets() []float64 {
+	if len(p.Buckets) == 0 {
+		return DefaultBuckets
+	}
+	return p.Buckets
+}
+
+// header writes the `HELP` and `TYPE` lines of a metric
+func header(sb *strings.Builder, name, kind string) {
+	if help, ok := Help[name]; ok {
+		fmt.Fprintf(sb, "# HELP %s %s\n", name, help)
+	}
+	fmt.Fprintf(sb, "# TYPE %s %s\n", name, kind)
+}
+
+// format formats labels as `{name="value",...}`, sorted by name, so the same labels always make the same series
+func format(labels []Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	sorted := slices.Clone(labels)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	parts := make([]string, len(sorted))
+	for i, l := range sorted {
+		parts[i] = l.Name + "=" + strconv.Quote(l.Value)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// withLabel appends a label to formatted labels
+func withLabel(labels, name, value string) string {
+	label := name + "=" + strconv.Quote(value)
+	if labels == "" {
+		return "{" + label + "}"
+	}
+	return strings.TrimSuffix(labels, "}") + "," + label + "}"
+}
+
+func number(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,149 @@
+/*
+# Normalize
+
+This package holds the types provider models share, so their results flow into the exporters, the Sheets publisher and
+the storage layer without per-provider conversions:
+  - `Time` decodes the timestamps of every provider into a `time.Time`, whatever their layout
+  - `Status` is the status of an account, mapped from each provider's own values by their `AccountStatus` methods
+
+```go
+
+	users, _ := g.Users().ListAllUsers()
+	for _, u := range users.Users {
+		if u.AccountStatus().Active() && u.LastLoginTime.Before(cutoff) {
+			stale = append(stale, u)
+		}
+	}
+
+```
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/normalize/normalize.go
+package normalize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ### Time
+// ---------------------------------------------------------------------
+
+// Layouts are the timestamp layouts used by the supported providers, tried in order
+var Layouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05.999999999-0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+/*
+ * # Parse
+ * Parses a provider timestamp with any of `Layouts`
+ * - Empty values, and times at or before the Unix epoch, are the zero time; Google reports accounts which never signed
+ *   in with the epoch
+ */
+func Parse(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range Layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			if t.Unix() <= 0 {
+				return time.Time{}, nil
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", value)
+}
+
+// Time is a provider timestamp; it decodes from any of `Layouts` and encodes as RFC-3339, or `null` when zero
+type Time struct {
+	time.Time
+}
+
+// NewTime returns `t` as a provider timestamp
+func NewTime(t time.Time) Time {
+	return Time{Time: t}
+}
+
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Time.Format(time.RFC3339Nano))
+}
+
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		t.Time = time.Time{}
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("timestamp must be a string: %w", err)
+	}
+	return t.UnmarshalText([]byte(value))
+}
+
+// MarshalText encodes the time as RFC-3339, or an empty string when zero, e.g. for CSV cells and query parameters
+func (t Time) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *Time) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+// String returns the time as RFC-3339, or an empty string when zero
+func (t Time) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Time.Format(time.RFC3339)
+}
+
+// END OF TIME
+//---------------------------------------------------------------------
+
+// ### Status
+// ---------------------------------------------------------------------
+
+// Status is the status of an account, common to every provider
+type Status string
+
+const (
+	StatusActive        Status = "active"        // The account can sign in
+	StatusPending       Status = "pending"       // The account was created, but not activated yet
+	StatusLocked        Status = "locked"        // The account is temporarily blocked, e.g. locked out or recovering its password
+	StatusSuspended     Status = "suspended"     // The account was disabled, and can be restored
+	StatusDeprovisioned Status = "deprovisioned" // The account was deactivated or deleted
+	StatusUnknown       Status = "unknown"       // The provider reported a status without an equivalent
+)
+
+// Active returns whether the account can sign in
+func (s Status) Active() bool {
+	return s == StatusActive
+}
+
+// Disabled returns whether the account was suspended or deprovisioned, rather than only blocked or pending
+func (s Status) Disabled() bool {
+	return s == StatusSuspended || s == StatusDeprovisioned
+}
+
+// END OF STATUS
+//---------------------------------------------------------------------
@@ -0,0 +1,153 @@
+// pkg/common/notify/email.go
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Base URL of Microsoft Graph
+const GraphAPI = "https://graph.microsoft.com/v1.0"
+
+// ### Email Structs
+// ---------------------------------------------------------------------
+
+/*
+ * EmailConfig configures emails sent through Gmail (or any sender of RFC 5322 messages)
+ * - `Send` delivers the message, e.g. with a Google client impersonating `From`:
+ *   func(_ context.Context, from string, raw []byte) error { _, err := g.Gmail().SendMessage(from, raw); return err }
+ */
+type EmailConfig struct {
+	From string                                                   // Sender of the emails
+	To   []string                                                 // Recipients of the emails
+	Send func(ctx context.Context, from string, raw []byte) error // Delivers a message
+}
+
+// Email sends messages as plain-text emails
+type Email struct {
+	config EmailConfig
+	now    func() time.Time
+}
+
+// GraphConfig configures emails sent through Microsoft Graph
+type GraphConfig struct {
+	Token      string       // Access token with the `Mail.Send` permission
+	From       string       // Mailbox the emails are sent from
+	To         []string     // Recipients of the emails
+	BaseURL    string       // Base URL of Graph; `GraphAPI` when empty
+	HTTPClient *http.Client // Client used for requests; `http.DefaultClient` when nil
+}
+
+// Graph sends messages as plain-text emails through Microsoft Graph
+type Graph struct {
+	config GraphConfig
+}
+
+type graphRecipient struct {
+	EmailAddress struct {
+		Address string `json:"address"`
+	} `json:"emailAddress"`
+}
+
+type graphMessage struct {
+	Subject string `json:"subject"`
+	Body    struct {
+		ContentType string `json:"contentType"`
+		Content     string `json:"content"`
+	} `json:"body"`
+	ToRecipients []*graphRecipient `json:"toRecipients"`
+}
+
+// END OF EMAIL STRUCTS
+//---------------------------------------------------------------------
+
+// NewEmail returns a notifier sending emails with `cfg.Send`
+func NewEmail(cfg EmailConfig) *Email {
+	return &Email{config: cfg, now: time.Now}
+}
+
+// Notify sends the message; the subject is its title, prefixed with its severity unless informational
+func (e *Email) Notify(ctx context.Context, m *Message) error {
+	switch {
+	case e.config.Send == nil:
+		return errors.New("email: no sender configured")
+	case e.config.From == "" || len(e.config.To) == 0:
+		return errors.New("email: no sender or recipients configured")
+	}
+
+	if err := e.config.Send(ctx, e.config.From, e.MIME(m)); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	return nil
+}
+
+// MIME renders the message as an RFC 5322 email, with a quoted-printable UTF-8 body
+func (e *Email) MIME(m *Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", e.config.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.config.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject(m)))
+	fmt.Fprintf(&b, "Date: %s\r\n", e.now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(&b)
+	w.Write([]byte(strings.ReplaceAll(m.Text(), "\n", "\r\n")))
+	w.Close()
+	return b.Bytes()
+}
+
+// subject prefixes the title with the severity, e.g. `[FAILURE] offboarding failed for user@example.com`
+func subject(m *Message) string {
+	if m.severity() == Info {
+		return m.Title
+	}
+	return fmt.Sprintf("[%s] %s", strings.ToUpper(string(m.severity())), m.Title)
+}
+
+// NewGraph returns a notifier sending emails through Microsoft Graph
+func NewGraph(cfg GraphConfig) *Graph {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = GraphAPI
+	}
+	return &Graph{config: cfg}
+}
+
+/*
+ * # Send an Email through Microsoft Graph
+ * /users/{from}/sendMail
+ * https://learn.microsoft.com/en-us/graph/api/user-sendmail
+ */
+func (g *Graph) Notify(ctx context.Context, m *Message) error {
+	switch {
+	case g.config.Token == "":
+		return errors.New("graph: no token configured")
+	case g.config.From == "" || len(g.config.To) == 0:
+		return errors.New("graph: no sender or recipients configured")
+	}
+
+	message := graphMessage{Subject: subject(m)}
+	message.Body.ContentType = "Text"
+	message.Body.Content = m.Text()
+	for _, to := range g.config.To {
+		r := &graphRecipient{}
+		r.EmailAddress.Address = to
+		message.ToRecipients = append(message.ToRecipients, r)
+	}
+
+	u := fmt.Sprintf("%s/users/%s/sendMail", strings.TrimRight(g.config.BaseURL, "/"), url.PathEscape(g.config.From))
+	payload := map[string]interface{}{"message": message, "saveToSentItems": false}
+	if _, err := postJSON(ctx, g.config.HTTPClient, u, http.Header{"Authorization": {"Bearer " + g.config.Token}}, payload); err != nil {
+		return fmt.Errorf("graph: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+/*
+# Notify
+
+This package sends notifications (success and failure summaries of orchestrators, scheduled jobs, reports, ...) to
+Slack, Microsoft Teams, email (through Gmail or Microsoft Graph) and PagerDuty, behind a single interface, with messages
+rendered from templates:
+
+	n := notify.All(
+		notify.NewSlack(notify.SlackConfig{WebhookURL: url}),
+		notify.AtLeast(notify.Failure, notify.NewPagerDuty(notify.PagerDutyConfig{RoutingKey: key})),
+	)
+	o.Notifier = n // Summaries of every workflow
+	s.OnComplete = notify.JobHook(n, true) // Failures of scheduled jobs
+
+	m, _ := notify.Render(notify.Template{Title: "Drift in {{.Group}}", Body: "{{len .Changes}} change(s)"}, data)
+	n.Notify(ctx, m)
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/notify/notify.go
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/scheduler"
+)
+
+// Severity is how urgent a notification is
+type Severity string
+
+const (
+	Info    Severity = "info"    // Something happened, e.g. a report was generated
+	Success Severity = "success" // A job or workflow succeeded
+	Warning Severity = "warning" // Something needs attention, e.g. a credential is expiring
+	Failure Severity = "failure" // A job or workflow failed
+)
+
+// rank orders the severities, least urgent first
+var rank = map[Severity]int{
+	Info:    0,
+	Success: 1,
+	Warning: 2,
+	Failure: 3,
+}
+
+// ### Notify Structs
+// ---------------------------------------------------------------------
+
+// Field is a labelled value shown with a message, e.g. `Subject: user@example.com`
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Message is a notification, rendered by each notifier in the format of its service
+type Message struct {
+	Title    string   `json:"title"`              // Short summary, e.g. `offboarding failed for user@example.com`
+	Body     string   `json:"body,omitempty"`     // Details, in plain text
+	Severity Severity `json:"severity"`           // How urgent the message is; `Info` when empty
+	Fields   []Field  `json:"fields,omitempty"`   // Labelled values shown with the message
+	Link     string   `json:"link,omitempty"`     // URL with more details, e.g. a report
+	Source   string   `json:"source,omitempty"`   // What sent the message, e.g. `orchestrators`
+	DedupKey string   `json:"dedupKey,omitempty"` // Groups repeated messages about the same thing, e.g. a PagerDuty incident
+}
+
+// Template renders messages with `text/template`; the data is the value passed to `Render`
+type Template struct {
+	Title    string   // Template of the title
+	Body     string   // Template of the body
+	Severity Severity // Severity of the message
+	Fields   []Field  // Fields of the message; their values are templates too
+}
+
+// Notifier sends messages to a service
+type Notifier interface {
+	Notify(ctx context.Context, m *Message) error
+}
+
+// NotifierFunc adapts a function to a Notifier
+type NotifierFunc func(ctx context.Context, m *Message) error
+
+// END OF NOTIFY STRUCTS
+//---------------------------------------------------------------------
+
+// Notify calls the function
+func (f NotifierFunc) Notify(ctx context.Context, m *Message) error {
+	return f(ctx, m)
+}
+
+/*
+ * # Render a Message
+ * Executes the templates of `t` with `data`
+ * - Templates may call `join` (strings.Join), `upper`, `lower`, `round` (a duration to the millisecond) and `since`
+ *   (the duration since a time, to the second)
+ */
+func Render(t Template, data interface{}) (*Message, error) {
+	execute := func(name, text string) (string, error) {
+		if text == "" {
+			return "", nil
+		}
+		tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+		if err != nil {
+			return "", fmt.Errorf("parsing %s template: %w", name, err)
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return "", fmt.Errorf("rendering %s template: %w", name, err)
+		}
+		return strings.TrimSpace(b.String()), nil
+	}
+
+	m := &Message{Severity: t.Severity}
+	var err error
+	if m.Title, err = execute("title", t.Title); err != nil {
+		return nil, err
+	}
+	if m.Body, err = execute("body", t.Body); err != nil {
+		return nil, err
+	}
+	for _, f := range t.Fields {
+		value, err := execute(f.Name, f.Value)
+		if err != nil {
+			return nil, err
+		}
+		m.Fields = append(m.Fields, Field{Name: f.Name, Value: value})
+	}
+	return m, nil
+}
+
+var funcs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"round": func(d time.Duration) time.Duration { return d.Round(time.Millisecond) },
+	"since": func(t time.Time) time.Duration { return time.Since(t).Round(time.Second) },
+}
+
+// All sends each message to every notifier, returning their errors joined
+func All(notifiers ...Notifier) Notifier {
+	return NotifierFunc(func(ctx context.Context, m *Message) error {
+		errs := []error{}
+		for _, n := range notifiers {
+			if n == nil {
+				continue
+			}
+			if err := n.Notify(ctx, m); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// AtLeast only passes on messages of at least the given severity, e.g. paging only on failures
+func AtLeast(severity Severity, n Notifier) Notifier {
+	return NotifierFunc(func(ctx context.Context, m *Message) error {
+		if rank[m.severity()] < rank[severity] {
+			return nil
+		}
+		return n.Notify(ctx, m)
+	})
+}
+
+// severity returns the severity of the message, `Info` when unset
+func (m *Message) severity() Severity {
+	if _, ok := rank[m.Severity]; ok {
+		return m.Severity
+	}
+	return Info
+}
+
+// Text renders the message as plain text: the title, the body, then one line per field and the link
+func (m *Message) Text() string {
+	lines := []string{m.Title}
+	if m.Body != "" {
+		lines = append(lines, "", m.Body)
+	}
+	if len(m.Fields) > 0 || m.Link != "" {
+		lines = append(lines, "")
+	}
+	for _, f := range m.Fields {
+		lines = append(lines, fmt.Sprintf("%s: %s", f.Name, f.Value))
+	}
+	if m.Link != "" {
+		lines = append(lines, m.Link)
+	}
+	return strings.Join(lines, "\n")
+}
+
+/*
+ * # Notify of Scheduled Jobs
+ * Returns a hook for `scheduler.Scheduler.OnComplete` which sends a summary of each run
+ * - When `failuresOnly` is set, only failed runs are sent
+ */
+func JobHook(n Notifier, failuresOnly bool) func(scheduler.JobStatus) error {
+	return func(status scheduler.JobStatus) error {
+		m := &Message{
+			Title:    fmt.Sprintf("%s succeeded", status.Name),
+			Severity: Success,
+			Source:   "scheduler",
+			DedupKey: "scheduler/" + status.Name,
+			Fields: []Field{
+				{Name: "Duration", Value: status.LastDuration.Round(time.Millisecond).String()},
+				{Name: "Failures", Value: fmt.Sprintf("%d of %d runs", status.Failures, status.Runs)},
+			},
+		}
+		if status.LastError != "" {
+			m.Title = fmt.Sprintf("%s failed", status.Name)
+			m.Body = status.LastError
+			m.Severity = Failure
+		} else if failuresOnly {
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return n.Notify(ctx, m)
+	}
+}
+
+// postJSON posts a JSON payload, failing on any status but 2xx
+func postJSON(ctx context.Context, c *http.Client, url string, header http.Header, payload interface{}) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if c == nil {
+		c = http.DefaultClient
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return body, fmt.Errorf("%s: %s", resp.Status, body)
+	}
+	return body, nil
+}
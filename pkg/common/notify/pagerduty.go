@@ -0,0 +1,101 @@
+// pkg/common/notify/pagerduty.go
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Endpoint of the PagerDuty Events API v2
+const PagerDutyEvents = "https://events.pagerduty.com/v2/enqueue" // https://developer.pagerduty.com/docs/events-api-v2/trigger-events/
+
+// ### PagerDuty Structs
+// ---------------------------------------------------------------------
+
+// PagerDutyConfig configures the PagerDuty service alerts are sent to
+type PagerDutyConfig struct {
+	RoutingKey string       // Integration key of an Events API v2 integration of the service
+	URL        string       // Endpoint of the Events API; `PagerDutyEvents` when empty
+	HTTPClient *http.Client // Client used for requests; `http.DefaultClient` when nil
+}
+
+// PagerDuty triggers (and resolves) PagerDuty alerts
+type PagerDuty struct {
+	config PagerDutyConfig
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"` // {trigger, resolve}
+	DedupKey    string            `json:"dedup_key,omitempty"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+	Links       []pagerDutyLink   `json:"links,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"` // {critical, error, warning, info}
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// END OF PAGERDUTY STRUCTS
+//---------------------------------------------------------------------
+
+// NewPagerDuty returns a notifier sending alerts to PagerDuty; usually wrapped in `AtLeast(Failure, ...)`
+func NewPagerDuty(cfg PagerDutyConfig) *PagerDuty {
+	if cfg.URL == "" {
+		cfg.URL = PagerDutyEvents
+	}
+	return &PagerDuty{config: cfg}
+}
+
+/*
+ * # Send an Alert
+ * Triggers an alert for the message
+ * - A successful message with a `DedupKey` resolves the alert of the same key instead, e.g. once a failed job recovers
+ * - Alerts are deduplicated by `DedupKey`, so repeated failures of the same job update a single incident
+ */
+func (p *PagerDuty) Notify(ctx context.Context, m *Message) error {
+	if p.config.RoutingKey == "" {
+		return errors.New("pagerduty: no routing key configured")
+	}
+
+	event := pagerDutyEvent{RoutingKey: p.config.RoutingKey, DedupKey: m.DedupKey}
+	if m.severity() == Success {
+		if m.DedupKey == "" {
+			return nil
+		}
+		event.EventAction = "resolve"
+	} else {
+		severities := map[Severity]string{Info: "info", Warning: "warning", Failure: "error"}
+		source := m.Source
+		if source == "" {
+			source = "rego"
+		}
+
+		event.EventAction = "trigger"
+		event.Payload = &pagerDutyPayload{Summary: m.Title, Source: source, Severity: severities[m.severity()], CustomDetails: map[string]string{}}
+		if m.Body != "" {
+			event.Payload.CustomDetails["details"] = m.Body
+		}
+		for _, f := range m.Fields {
+			event.Payload.CustomDetails[f.Name] = f.Value
+		}
+		if m.Link != "" {
+			event.Links = []pagerDutyLink{{Href: m.Link, Text: "Details"}}
+		}
+	}
+
+	if _, err := postJSON(ctx, p.config.HTTPClient, p.config.URL, nil, event); err != nil {
+		return fmt.Errorf("pagerduty: %w", err)
+	}
+	return nil
+}
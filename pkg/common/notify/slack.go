@@ -0,0 +1,121 @@
+// pkg/common/notify/slack.go
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Base URL of the Slack Web API
+const SlackAPI = "https://slack.com/api"
+
+// emoji prefixes the title of a message by severity, in Slack and Teams
+var emoji = map[Severity]string{
+	Info:    "ℹ️",
+	Success: "✅",
+	Warning: "⚠️",
+	Failure: "🚨",
+}
+
+// ### Slack Structs
+// ---------------------------------------------------------------------
+
+/*
+ * SlackConfig configures where Slack messages are posted
+ * - Either an incoming webhook, or a bot token (`chat:write`) and a channel
+ */
+type SlackConfig struct {
+	WebhookURL string       // URL of an incoming webhook
+	Token      string       // Bot token, e.g. `xoxb-...`
+	Channel    string       // Channel to post to with the token, e.g. `#it-alerts` or a channel ID
+	BaseURL    string       // Base URL of the Web API; `SlackAPI` when empty
+	HTTPClient *http.Client // Client used for requests; `http.DefaultClient` when nil
+}
+
+// Slack posts messages to a Slack channel
+type Slack struct {
+	config SlackConfig
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type   string       `json:"type"`
+	Text   *slackText   `json:"text,omitempty"`
+	Fields []*slackText `json:"fields,omitempty"`
+}
+
+type slackMessage struct {
+	Channel string        `json:"channel,omitempty"`
+	Text    string        `json:"text"` // Fallback for notifications
+	Blocks  []*slackBlock `json:"blocks"`
+}
+
+// END OF SLACK STRUCTS
+//---------------------------------------------------------------------
+
+// NewSlack returns a notifier posting to Slack
+func NewSlack(cfg SlackConfig) *Slack {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = SlackAPI
+	}
+	return &Slack{config: cfg}
+}
+
+// Notify posts the message, with its fields as a section of `mrkdwn` fields
+func (s *Slack) Notify(ctx context.Context, m *Message) error {
+	title := fmt.Sprintf("%s *%s*", emoji[m.severity()], m.Title)
+	message := slackMessage{
+		Channel: s.config.Channel,
+		Text:    fmt.Sprintf("%s %s", emoji[m.severity()], m.Title),
+		Blocks:  []*slackBlock{{Type: "section", Text: &slackText{Type: "mrkdwn", Text: title}}},
+	}
+	if m.Body != "" {
+		message.Blocks = append(message.Blocks, &slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: m.Body}})
+	}
+	if len(m.Fields) > 0 {
+		fields := &slackBlock{Type: "section"}
+		for _, f := range m.Fields {
+			fields.Fields = append(fields.Fields, &slackText{Type: "mrkdwn", Text: fmt.Sprintf("*%s*\n%s", f.Name, f.Value)})
+		}
+		message.Blocks = append(message.Blocks, fields)
+	}
+	if m.Link != "" {
+		message.Blocks = append(message.Blocks, &slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: fmt.Sprintf("<%s|Details>", m.Link)}})
+	}
+
+	switch {
+	case s.config.WebhookURL != "":
+		_, err := postJSON(ctx, s.config.HTTPClient, s.config.WebhookURL, nil, message)
+		if err != nil {
+			return fmt.Errorf("slack: %w", err)
+		}
+		return nil
+	case s.config.Token != "" && s.config.Channel != "":
+		body, err := postJSON(ctx, s.config.HTTPClient, strings.TrimRight(s.config.BaseURL, "/")+"/chat.postMessage", http.Header{"Authorization": {"Bearer " + s.config.Token}}, message)
+		if err != nil {
+			return fmt.Errorf("slack: %w", err)
+		}
+		// The Web API reports failures in the body of a `200 OK`
+		result := struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}{}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return fmt.Errorf("slack: %w", err)
+		}
+		if !result.OK {
+			return fmt.Errorf("slack: %s", result.Error)
+		}
+		return nil
+	default:
+		return errors.New("slack: neither a webhook URL nor a token and channel is configured")
+	}
+}
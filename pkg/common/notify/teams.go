@@ -0,0 +1,89 @@
+// pkg/common/notify/teams.go
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ### Teams Structs
+// ---------------------------------------------------------------------
+
+// TeamsConfig configures the Microsoft Teams channel messages are posted to
+type TeamsConfig struct {
+	WebhookURL string       // URL of an incoming webhook (or of a Workflows "post to a channel when a webhook request is received" flow)
+	HTTPClient *http.Client // Client used for requests; `http.DefaultClient` when nil
+}
+
+// Teams posts messages to a Microsoft Teams channel, as Adaptive Cards
+type Teams struct {
+	config TeamsConfig
+}
+
+type teamsMessage struct {
+	Type        string             `json:"type"`
+	Attachments []*teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string     `json:"contentType"`
+	Content     *teamsCard `json:"content"`
+}
+
+// https://adaptivecards.io/explorer/AdaptiveCard.html
+type teamsCard struct {
+	Schema  string                   `json:"$schema"`
+	Type    string                   `json:"type"`
+	Version string                   `json:"version"`
+	Body    []map[string]interface{} `json:"body"`
+	Actions []map[string]interface{} `json:"actions,omitempty"`
+}
+
+// END OF TEAMS STRUCTS
+//---------------------------------------------------------------------
+
+// NewTeams returns a notifier posting to Microsoft Teams
+func NewTeams(cfg TeamsConfig) *Teams {
+	return &Teams{config: cfg}
+}
+
+// Notify posts the message as an Adaptive Card, with its fields as a fact set
+func (t *Teams) Notify(ctx context.Context, m *Message) error {
+	if t.config.WebhookURL == "" {
+		return errors.New("teams: no webhook URL configured")
+	}
+
+	colors := map[Severity]string{Info: "Default", Success: "Good", Warning: "Warning", Failure: "Attention"}
+	card := &teamsCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body: []map[string]interface{}{
+			{"type": "TextBlock", "text": fmt.Sprintf("%s %s", emoji[m.severity()], m.Title), "weight": "Bolder", "size": "Medium", "color": colors[m.severity()], "wrap": true},
+		},
+	}
+	if m.Body != "" {
+		card.Body = append(card.Body, map[string]interface{}{"type": "TextBlock", "text": m.Body, "wrap": true})
+	}
+	if len(m.Fields) > 0 {
+		facts := []map[string]string{}
+		for _, f := range m.Fields {
+			facts = append(facts, map[string]string{"title": f.Name, "value": f.Value})
+		}
+		card.Body = append(card.Body, map[string]interface{}{"type": "FactSet", "facts": facts})
+	}
+	if m.Link != "" {
+		card.Actions = []map[string]interface{}{{"type": "Action.OpenUrl", "title": "Details", "url": m.Link}}
+	}
+
+	message := teamsMessage{
+		Type:        "message",
+		Attachments: []*teamsAttachment{{ContentType: "application/vnd.microsoft.card.adaptive", Content: card}},
+	}
+	if _, err := postJSON(ctx, t.config.HTTPClient, t.config.WebhookURL, nil, message); err != nil {
+		return fmt.Errorf("teams: %w", err)
+	}
+	return nil
+}
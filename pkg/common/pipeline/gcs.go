@@ -0,0 +1,191 @@
+// pkg/common/pipeline/gcs.go
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Google Cloud Storage requires every chunk of a resumable upload but the last to be a multiple of this size
+const GCSChunkSize = 256 << 10 // 256 KiB
+
+// ### GCS Structs
+// ---------------------------------------------------------------------
+
+// GCSConfig configures a Google Cloud Storage bucket
+type GCSConfig struct {
+	Bucket     string       // Name of the bucket
+	Endpoint   string       // Base URL of the JSON API; `https://storage.googleapis.com` when empty
+	Token      string       // OAuth 2.0 access token, sent as a bearer token; optional when `HTTPClient` is already authorized
+	HTTPClient *http.Client // Client used for requests, e.g. from `oauth2.Config.Client`; `http.DefaultClient` when nil
+}
+
+// GCS is a Google Cloud Storage bucket
+type GCS struct {
+	config GCSConfig
+}
+
+type gcsUpload struct {
+	store   *GCS
+	key     string
+	session string    // URI of the resumable upload session
+	offset  int64     // Bytes committed before the current part
+	sent    int       // Number of the last part sent
+	digest  hash.Hash // MD5 of the parts sent
+	object  *gcsObject
+}
+
+type gcsObject struct {
+	Generation string `json:"generation"`
+	Size       string `json:"size"`
+	MD5Hash    string `json:"md5Hash"`
+}
+
+// END OF GCS STRUCTS
+//---------------------------------------------------------------------
+
+/*
+ * # Google Cloud Storage
+ * Returns the bucket of `cfg`, which receives uploads as resumable uploads, one chunk per part
+ * https://cloud.google.com/storage/docs/performing-resumable-uploads
+ */
+func NewGCS(cfg GCSConfig) (*GCS, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("gcs: no bucket configured")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://storage.googleapis.com"
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &GCS{config: cfg}, nil
+}
+
+// Location returns `gs://<bucket>`
+func (g *GCS) Location() string {
+	return "gs://" + g.config.Bucket
+}
+
+// Begin initiates a resumable upload session
+func (g *GCS) Begin(ctx context.Context, key, contentType string) (Upload, error) {
+	u := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=resumable&name=%s", g.config.Endpoint, url.PathEscape(g.config.Bucket), url.QueryEscape(key))
+	req, err := g.request(ctx, http.MethodPost, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Upload-Content-Type", contentType)
+
+	resp, _, err := send(g.config.HTTPClient, req, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	session := resp.Header.Get("Location")
+	if session == "" {
+		return nil, errors.New("gcs returned no upload session")
+	}
+
+	return &gcsUpload{store: g, key: key, session: session, digest: md5.New()}, nil
+}
+
+/*
+ * # Upload a Part
+ * Sends the part as the next chunk of the session; the last chunk declares the total size, which completes the object
+ * - A retried part is sent again from the same offset, since the offset only advances once a part is acknowledged
+ */
+func (u *gcsUpload) Part(ctx context.Context, number int, data []byte, last bool) error {
+	if !last && len(data)%GCSChunkSize != 0 {
+		return fmt.Errorf("part %d is %d bytes, which is not a multiple of %d", number, len(data), GCSChunkSize)
+	}
+	if number != u.sent+1 {
+		return fmt.Errorf("part %d sent after part %d", number, u.sent)
+	}
+
+	total := "*"
+	if last {
+		total = fmt.Sprint(u.offset + int64(len(data)))
+	}
+	contentRange := fmt.Sprintf("bytes %d-%d/%s", u.offset, u.offset+int64(len(data))-1, total)
+	if len(data) == 0 {
+		contentRange = fmt.Sprintf("bytes */%s", total)
+	}
+
+	req, err := u.store.request(ctx, http.MethodPut, u.session, data)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Range", contentRange)
+
+	expected := []int{http.StatusPermanentRedirect}
+	if last {
+		expected = []int{http.StatusOK, http.StatusCreated}
+	}
+	_, body, err := send(u.store.config.HTTPClient, req, expected...)
+	if err != nil {
+		return err
+	}
+
+	if last {
+		object := &gcsObject{}
+		if err := json.Unmarshal(body, object); err != nil {
+			return fmt.Errorf("decoding object: %w", err)
+		}
+		u.object = object
+	}
+	u.sent = number
+	u.offset += int64(len(data))
+	u.digest.Write(data)
+	return nil
+}
+
+// Complete compares the size and MD5 digest of the object, which the last part completed, against the parts sent
+func (u *gcsUpload) Complete(ctx context.Context) (string, error) {
+	if u.object == nil {
+		return "", errors.New("last part was never sent")
+	}
+	if u.object.Size != fmt.Sprint(u.offset) {
+		return u.object.Generation, fmt.Errorf("size is %s, expected %d", u.object.Size, u.offset)
+	}
+	if expected := base64.StdEncoding.EncodeToString(u.digest.Sum(nil)); u.object.MD5Hash != expected {
+		return u.object.Generation, fmt.Errorf("md5Hash is %s, expected %s", u.object.MD5Hash, expected)
+	}
+	return u.object.Generation, nil
+}
+
+// Abort cancels the upload session, or deletes the object if the last part completed it
+func (u *gcsUpload) Abort(ctx context.Context) error {
+	if u.object != nil {
+		req, err := u.store.request(ctx, http.MethodDelete, fmt.Sprintf("%s/storage/v1/b/%s/o/%s", u.store.config.Endpoint, url.PathEscape(u.store.config.Bucket), url.PathEscape(u.key)), nil)
+		if err != nil {
+			return err
+		}
+		_, _, err = send(u.store.config.HTTPClient, req, http.StatusNoContent, http.StatusNotFound)
+		return err
+	}
+
+	req, err := u.store.request(ctx, http.MethodDelete, u.session, nil)
+	if err != nil {
+		return err
+	}
+	// A cancelled session answers `499 Client Closed Request`
+	_, _, err = send(u.store.config.HTTPClient, req, 499, http.StatusNoContent, http.StatusNotFound)
+	return err
+}
+
+func (g *GCS) request(ctx context.Context, method, u string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if g.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.config.Token)
+	}
+	return req, nil
+}
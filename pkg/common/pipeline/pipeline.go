@@ -0,0 +1,339 @@
+/*
+# Pipeline
+
+This package streams large exports (Backupify artifacts, Vault exports, System Log archives, ...) from providers straight
+into object storage (Amazon S3, Google Cloud Storage, Azure Blob Storage), without staging them on disk:
+  - Each source is read in parts, which are uploaded with the multipart API of the store, so memory stays bounded
+  - Every part carries its MD5 digest, which the store verifies; the completed object is checked against its size and
+    digest where the store reports them
+  - A manifest (`manifest.json`) listing every object with its size and SHA-256 digest is written alongside the exports
+
+	store, _ := pipeline.NewS3(pipeline.S3Config{Bucket: "rego-exports", Region: "us-east-1"})
+	p := pipeline.New(store, pipeline.Options{Prefix: "backupify/2024-08-15/"}, log.INFO)
+	manifest, err := p.Run(ctx,
+		b.Exports().ExportSource(activity, export),
+		o.LogArchive("okta/system-log.jsonl", okta.LogQuery{Since: since}),
+		pipeline.FromURL("vault/export-1.zip", signedURL, nil),
+	)
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/pipeline/pipeline.go
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/pool"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+const (
+	DefaultPartSize = 16 << 20        // 16 MiB; a multiple of 256 KiB, as Google Cloud Storage requires
+	MinPartSize     = 5 << 20         // 5 MiB, the smallest part Amazon S3 accepts (except the last)
+	DefaultWorkers  = 4               // Number of sources uploaded at once when `Options.Workers` is not set
+	DefaultManifest = "manifest.json" // Name of the manifest, relative to the prefix
+)
+
+// ### Pipeline Structs
+// ---------------------------------------------------------------------
+
+// Options configures a pipeline
+type Options struct {
+	Prefix     string           // Prepended to the key of every object, e.g. `exports/2024-08-15/`
+	PartSize   int              // Size of each uploaded part; `DefaultPartSize` when zero, and never below `MinPartSize`
+	Workers    int              // Maximum number of sources uploaded at once; `DefaultWorkers` when zero
+	Manifest   string           // Name of the manifest; `DefaultManifest` when empty
+	NoManifest bool             // Skip writing the manifest
+	Now        func() time.Time // Clock used for timestamps; defaults to `time.Now`
+	RetrySleep retry.Time       // Sleeps between retries of a part; defaults to `retry.RealTime`
+}
+
+// Source is an export to stream into the store
+type Source struct {
+	Key         string                                           // Key of the object, relative to the prefix
+	ContentType string                                           // Content type of the object; `application/octet-stream` when empty
+	Size        int64                                            // Expected size in bytes, checked once uploaded; zero when unknown
+	Open        func(ctx context.Context) (io.ReadCloser, error) // Opens the export for reading
+}
+
+// Object is an export written to the store
+type Object struct {
+	Key         string    `json:"key"`                   // Key of the object in the store
+	ContentType string    `json:"contentType"`           // Content type of the object
+	Size        int64     `json:"size"`                  // Size in bytes
+	Parts       int       `json:"parts"`                 // Number of parts it was uploaded in
+	SHA256      string    `json:"sha256,omitempty"`      // Hex SHA-256 digest of the content
+	MD5         string    `json:"md5,omitempty"`         // Hex MD5 digest of the content
+	ETag        string    `json:"etag,omitempty"`        // Entity tag (or generation) reported by the store
+	StartedAt   time.Time `json:"startedAt"`             // When the upload started
+	CompletedAt time.Time `json:"completedAt,omitempty"` // When the upload completed
+	Error       string    `json:"error,omitempty"`       // Why the upload failed; empty when it succeeded
+}
+
+// Manifest lists the objects written by a run of the pipeline
+type Manifest struct {
+	Store     string    `json:"store"`     // Location of the store, e.g. `s3://rego-exports`
+	Prefix    string    `json:"prefix"`    // Prefix of every key
+	CreatedAt time.Time `json:"createdAt"` // When the run completed
+	Objects   []*Object `json:"objects"`   // Every object, in the order of the sources
+}
+
+// Pipeline streams sources into a store
+type Pipeline struct {
+	Store   Store       // Destination of the exports
+	Options Options     // Configuration of the pipeline
+	Log     *log.Logger // Logger for the pipeline
+}
+
+// END OF PIPELINE STRUCTS
+//---------------------------------------------------------------------
+
+/*
+ * # Generate a Pipeline
+ * - Streams sources into `store`, configured by `opts`
+ */
+func New(store Store, opts Options, verbosity int) *Pipeline {
+	if opts.PartSize <= 0 {
+		opts.PartSize = DefaultPartSize
+	}
+	if opts.PartSize < MinPartSize {
+		opts.PartSize = MinPartSize
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.Manifest == "" {
+		opts.Manifest = DefaultManifest
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.RetrySleep == nil {
+		opts.RetrySleep = retry.RealTime{}
+	}
+
+	return &Pipeline{
+		Store:   store,
+		Options: opts,
+		Log:     log.NewLogger("{pipeline}", verbosity),
+	}
+}
+
+/*
+ * # Run the Pipeline
+ * Streams every source into the store, then writes the manifest
+ * - A failed upload is aborted, recorded in the manifest with its error, and the other sources are still uploaded
+ * - Returns the manifest, and the failures joined into one error
+ */
+func (p *Pipeline) Run(ctx context.Context, sources ...*Source) (*Manifest, error) {
+	results := pool.Map(ctx, sources, pool.Options{Workers: p.Options.Workers}, func(ctx context.Context, src *Source) (*Object, error) {
+		return p.Upload(ctx, src)
+	})
+
+	manifest := &Manifest{
+		Store:   p.Store.Location(),
+		Prefix:  p.Options.Prefix,
+		Objects: []*Object{},
+	}
+	errs := []error{}
+	for _, result := range results {
+		object := result.Value
+		if object == nil {
+			object = &Object{}
+			if result.Item != nil {
+				object.Key = p.key(result.Item.Key)
+			}
+		}
+		if result.Err != nil {
+			object.Error = result.Err.Error()
+			errs = append(errs, fmt.Errorf("%s: %w", object.Key, result.Err))
+		}
+		manifest.Objects = append(manifest.Objects, object)
+	}
+	manifest.CreatedAt = p.Options.Now()
+
+	if !p.Options.NoManifest {
+		if err := p.WriteManifest(ctx, manifest); err != nil {
+			errs = append(errs, fmt.Errorf("manifest: %w", err))
+		}
+	}
+
+	return manifest, errors.Join(errs...)
+}
+
+/*
+ * # Upload a Source
+ * Reads the source in parts and uploads them to the store, retrying each part with backoff
+ * - The upload is aborted if the source cannot be read, a part is rejected, or the size does not match `Source.Size`
+ * - The object is returned even when the upload fails, with what was uploaded so far
+ */
+func (p *Pipeline) Upload(ctx context.Context, src *Source) (*Object, error) {
+	if src == nil || src.Open == nil {
+		return nil, errors.New("source has nothing to open")
+	}
+
+	object := &Object{
+		Key:       p.key(src.Key),
+		StartedAt: p.Options.Now(),
+	}
+
+	reader, err := src.Open(ctx)
+	if err != nil {
+		return object, fmt.Errorf("opening source: %w", err)
+	}
+	defer reader.Close()
+
+	// Sources may only learn their content type once opened
+	object.ContentType = src.ContentType
+	if object.ContentType == "" {
+		object.ContentType = "application/octet-stream"
+	}
+
+	upload, err := p.Store.Begin(ctx, object.Key, object.ContentType)
+	if err != nil {
+		return object, fmt.Errorf("beginning upload: %w", err)
+	}
+
+	p.Log.Printf("Uploading %s to %s", object.Key, p.Store.Location())
+	sha, sum := sha256.New(), md5.New()
+	if err := p.parts(ctx, upload, io.TeeReader(reader, io.MultiWriter(sha, sum)), object); err != nil {
+		return object, p.abort(upload, object, err)
+	}
+
+	object.SHA256 = hex.EncodeToString(sha.Sum(nil))
+	object.MD5 = hex.EncodeToString(sum.Sum(nil))
+	if src.Size > 0 && object.Size != src.Size {
+		return object, p.abort(upload, object, fmt.Errorf("read %d bytes, expected %d", object.Size, src.Size))
+	}
+
+	etag, err := upload.Complete(ctx)
+	if err != nil {
+		return object, p.abort(upload, object, fmt.Errorf("completing upload: %w", err))
+	}
+	object.ETag = etag
+	object.CompletedAt = p.Options.Now()
+	p.Log.Printf("Uploaded %s (%d bytes in %d parts)", object.Key, object.Size, object.Parts)
+	return object, nil
+}
+
+// parts reads `r` one part ahead, so the store is told which part is the last
+func (p *Pipeline) parts(ctx context.Context, upload Upload, r io.Reader, object *Object) error {
+	current, err := readPart(r, p.Options.PartSize)
+	if err != nil {
+		return fmt.Errorf("reading source: %w", err)
+	}
+
+	for number := 1; ; number++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		next := []byte{}
+		if len(current) == p.Options.PartSize {
+			if next, err = readPart(r, p.Options.PartSize); err != nil {
+				return fmt.Errorf("reading source: %w", err)
+			}
+		}
+		last := len(next) == 0
+
+		data := current
+		err := retry.RetryContext(ctx, func() error {
+			return upload.Part(ctx, number, data, last)
+		}, p.Options.RetrySleep)
+		if err != nil {
+			return fmt.Errorf("uploading part %d: %w", number, err)
+		}
+		object.Parts = number
+		object.Size += int64(len(current))
+
+		if last {
+			return nil
+		}
+		current = next
+	}
+}
+
+// readPart reads up to `size` bytes; fewer only at the end of `r`
+func readPart(r io.Reader, size int) ([]byte, error) {
+	buf := make([]byte, size)
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return buf[:n], err
+}
+
+// abort aborts a failed upload, keeping the error which caused it
+func (p *Pipeline) abort(upload Upload, object *Object, cause error) error {
+	p.Log.Errorf("Upload of %s failed: %v", object.Key, cause)
+	// The context may be what failed, so the abort gets one of its own
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := upload.Abort(ctx); err != nil {
+		return errors.Join(cause, fmt.Errorf("aborting upload: %w", err))
+	}
+	return cause
+}
+
+// key prepends the prefix to a key
+func (p *Pipeline) key(key string) string {
+	if p.Options.Prefix == "" {
+		return key
+	}
+	return path.Join(p.Options.Prefix, key)
+}
+
+/*
+ * # Write the Manifest
+ * Writes the manifest as indented JSON to `Options.Manifest`, under the prefix
+ */
+func (p *Pipeline) WriteManifest(ctx context.Context, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = p.Upload(ctx, &Source{
+		Key:         p.Options.Manifest,
+		ContentType: "application/json",
+		Size:        int64(len(data)),
+		Open: func(context.Context) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		},
+	})
+	return err
+}
+
+/*
+ * # Verify an Object
+ * Reads `r` (e.g. the object downloaded from the store) and checks it against the size and SHA-256 digest in the manifest
+ */
+func (o *Object) Verify(r io.Reader) error {
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return err
+	}
+	if n != o.Size {
+		return fmt.Errorf("size is %d, expected %d", n, o.Size)
+	}
+	if digest := hex.EncodeToString(h.Sum(nil)); digest != o.SHA256 {
+		return fmt.Errorf("digest is %s, expected %s", digest, o.SHA256)
+	}
+	return nil
+}
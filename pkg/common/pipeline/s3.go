@@ -0,0 +1,339 @@
+// pkg/common/pipeline/s3.go
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/config"
+)
+
+// ### S3 Structs
+// ---------------------------------------------------------------------
+
+/*
+ * S3Config configures an Amazon S3 bucket (or an S3-compatible store, through `Endpoint`)
+ * - Credentials not set are read from `AWS_ACCESS_KEY_ID`, `AWS_SECRET_ACCESS_KEY`, `AWS_SESSION_TOKEN` and `AWS_REGION`
+ */
+type S3Config struct {
+	Bucket          string       // Name of the bucket
+	Region          string       // Region of the bucket, e.g. `us-east-1`
+	Endpoint        string       // Base URL of the store; `https://s3.<region>.amazonaws.com` when empty
+	AccessKeyID     string       // Access key ID
+	SecretAccessKey string       // Secret access key
+	SessionToken    string       // Session token of temporary credentials; optional
+	NoETagCheck     bool         // Skip comparing the ETag of completed objects, whose ETags are not MD5-based under SSE-KMS
+	HTTPClient      *http.Client // Client used for requests; `http.DefaultClient` when nil
+}
+
+// S3 is an Amazon S3 bucket, addressed path-style
+type S3 struct {
+	config S3Config
+	now    func() time.Time
+}
+
+type s3Upload struct {
+	store     *S3
+	key       string
+	uploadID  string
+	etags     map[int]string // ETag of each part, by part number
+	digests   map[int][]byte // MD5 digest of each part, by part number
+	completed bool           // Whether the object was completed (but may have failed verification)
+}
+
+type s3Initiate struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type s3CompletePart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type s3Complete struct {
+	XMLName xml.Name         `xml:"CompleteMultipartUpload"`
+	Parts   []s3CompletePart `xml:"Part"`
+}
+
+type s3CompleteResult struct {
+	XMLName xml.Name
+	ETag    string `xml:"ETag"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// END OF S3 STRUCTS
+//---------------------------------------------------------------------
+
+/*
+ * # Amazon S3
+ * Returns the bucket of `cfg`, which receives uploads through the multipart upload API
+ * https://docs.aws.amazon.com/AmazonS3/latest/userguide/mpuoverview.html
+ */
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.AccessKeyID == "" {
+		cfg.AccessKeyID = config.GetEnv("AWS_ACCESS_KEY_ID")
+	}
+	if cfg.SecretAccessKey == "" {
+		cfg.SecretAccessKey = config.GetEnv("AWS_SECRET_ACCESS_KEY")
+	}
+	if cfg.SessionToken == "" {
+		cfg.SessionToken = config.GetEnv("AWS_SESSION_TOKEN")
+	}
+	if cfg.Region == "" {
+		cfg.Region = config.GetEnv("AWS_REGION")
+	}
+
+	switch {
+	case cfg.Bucket == "":
+		return nil, errors.New("s3: no bucket configured")
+	case cfg.Region == "":
+		return nil, errors.New("s3: no region configured")
+	case cfg.AccessKeyID == "" || cfg.SecretAccessKey == "":
+		return nil, errors.New("s3: no credentials configured")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+
+	return &S3{config: cfg, now: time.Now}, nil
+}
+
+// Location returns `s3://<bucket>`
+func (s *S3) Location() string {
+	return "s3://" + s.config.Bucket
+}
+
+// Begin creates a multipart upload
+func (s *S3) Begin(ctx context.Context, key, contentType string) (Upload, error) {
+	req, err := s.request(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, http.Header{"Content-Type": {contentType}})
+	if err != nil {
+		return nil, err
+	}
+	_, body, err := send(s.config.HTTPClient, req, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	result := s3Initiate{}
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("decoding upload: %w", err)
+	}
+	if result.UploadID == "" {
+		return nil, errors.New("s3 returned no upload ID")
+	}
+
+	return &s3Upload{store: s, key: key, uploadID: result.UploadID, etags: map[int]string{}, digests: map[int][]byte{}}, nil
+}
+
+// Part uploads a part; S3 verifies it against its `Content-MD5`
+func (u *s3Upload) Part(ctx context.Context, number int, data []byte, last bool) error {
+	digest := md5.Sum(data)
+	query := url.Values{"partNumber": {fmt.Sprint(number)}, "uploadId": {u.uploadID}}
+	req, err := u.store.request(ctx, http.MethodPut, u.key, query, data, http.Header{"Content-MD5": {base64.StdEncoding.EncodeToString(digest[:])}})
+	if err != nil {
+		return err
+	}
+	resp, _, err := send(u.store.config.HTTPClient, req, http.StatusOK)
+	if err != nil {
+		return err
+	}
+
+	u.etags[number] = resp.Header.Get("ETag")
+	u.digests[number] = digest[:]
+	return nil
+}
+
+/*
+ * # Complete the Upload
+ * - The ETag of a multipart object is the MD5 digest of the digests of its parts, followed by the number of parts, which
+ *   is compared against the digests sent
+ */
+func (u *s3Upload) Complete(ctx context.Context) (string, error) {
+	numbers := []int{}
+	for n := range u.etags {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	payload := s3Complete{}
+	digests := []byte{}
+	for _, n := range numbers {
+		payload.Parts = append(payload.Parts, s3CompletePart{PartNumber: n, ETag: u.etags[n]})
+		digests = append(digests, u.digests[n]...)
+	}
+	data, err := xml.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := u.store.request(ctx, http.MethodPost, u.key, url.Values{"uploadId": {u.uploadID}}, data, http.Header{"Content-Type": {"application/xml"}})
+	if err != nil {
+		return "", err
+	}
+	_, body, err := send(u.store.config.HTTPClient, req, http.StatusOK)
+	if err != nil {
+		return "", err
+	}
+
+	// S3 may report a failure in the body of a `200 OK`
+	result := s3CompleteResult{}
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("decoding completed upload: %w", err)
+	}
+	if result.XMLName.Local == "Error" {
+		return "", fmt.Errorf("%s: %s", result.Code, result.Message)
+	}
+
+	u.completed = true
+	etag := strings.Trim(result.ETag, `"`)
+	sum := md5.Sum(digests)
+	if expected := fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), len(numbers)); !u.store.config.NoETagCheck && etag != expected {
+		return etag, fmt.Errorf("etag is %s, expected %s", etag, expected)
+	}
+	return etag, nil
+}
+
+// Abort aborts the multipart upload, deleting its parts; or deletes the object, if it was completed
+func (u *s3Upload) Abort(ctx context.Context) error {
+	query := url.Values{"uploadId": {u.uploadID}}
+	if u.completed {
+		query = nil
+	}
+	req, err := u.store.request(ctx, http.MethodDelete, u.key, query, nil, nil)
+	if err != nil {
+		return err
+	}
+	_, _, err = send(u.store.config.HTTPClient, req, http.StatusNoContent, http.StatusNotFound)
+	return err
+}
+
+// request creates a request for `key`, signed with AWS Signature Version 4
+func (s *S3) request(ctx context.Context, method, key string, query url.Values, body []byte, header http.Header) (*http.Request, error) {
+	u, err := url.Parse(s.config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	base := strings.TrimRight(u.Path, "/")
+	u.Path = fmt.Sprintf("%s/%s/%s", base, s.config.Bucket, key)
+	u.RawPath = fmt.Sprintf("%s/%s/%s", awsEscape(base, true), awsEscape(s.config.Bucket, false), awsEscape(key, true))
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	s.sign(req, body)
+	return req, nil
+}
+
+/*
+ * # Sign a Request
+ * https://docs.aws.amazon.com/IAM/latest/UserGuide/create-signed-request.html
+ */
+func (s *S3) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	stamp := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payload := sha256.Sum256(body)
+
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payload[:]))
+	if s.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonical := strings.Builder{}
+	for _, name := range names {
+		fmt.Fprintf(&canonical, "%s:%s\n", name, headers[name])
+	}
+	signed := strings.Join(names, ";")
+
+	request := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonical.String(),
+		signed,
+		hex.EncodeToString(payload[:]),
+	}, "\n")
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, s.config.Region)
+	hashed := sha256.Sum256([]byte(request))
+	toSign := strings.Join([]string{"AWS4-HMAC-SHA256", stamp, scope, hex.EncodeToString(hashed[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	for _, part := range []string{s.config.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.config.AccessKeyID, scope, signed, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery encodes a query with its keys sorted, as AWS signs it
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := []string{}
+	for _, k := range keys {
+		values := append([]string{}, query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, awsEscape(k, false)+"="+awsEscape(v, false))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// awsEscape percent-encodes everything but unreserved characters (and `/`, in a path)
+func awsEscape(s string, path bool) string {
+	b := strings.Builder{}
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case c == '/' && path:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
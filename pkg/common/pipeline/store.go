@@ -0,0 +1,51 @@
+// pkg/common/pipeline/store.go
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Store is an object store which accepts multipart uploads
+type Store interface {
+	Location() string                                                   // Location of the store, e.g. `s3://bucket`
+	Begin(ctx context.Context, key, contentType string) (Upload, error) // Begins the upload of an object
+}
+
+/*
+ * # Upload
+ * An object being uploaded in parts, numbered from 1 and uploaded in order
+ * - `Part` may be retried with the same number and data
+ * - `last` is set on the final part, which may be smaller than the others (or empty, for an empty object)
+ */
+type Upload interface {
+	Part(ctx context.Context, number int, data []byte, last bool) error
+	Complete(ctx context.Context) (string, error) // Completes the object, verifying it where the store allows; returns its ETag
+	Abort(ctx context.Context) error              // Discards the parts uploaded so far, or the object if it failed verification
+}
+
+// send performs a request, returning the body of a response with one of the `expected` status codes
+func send(c *http.Client, req *http.Request, expected ...int) (*http.Response, []byte, error) {
+	if c == nil {
+		c = http.DefaultClient
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, nil, err
+	}
+	for _, code := range expected {
+		if resp.StatusCode == code {
+			return resp, body, nil
+		}
+	}
+	return resp, body, fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, body)
+}
@@ -0,0 +1,134 @@
+// pkg/common/policy/opa.go
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ### OPA Structs
+// ---------------------------------------------------------------------
+
+// OPAConfig configures an Open Policy Agent server
+type OPAConfig struct {
+	URL        string       // Base URL of the server, e.g. `http://localhost:8181`
+	Path       string       // Path of the rule (or package) evaluated, e.g. `rego/authz`
+	Token      string       // Bearer token of the server; optional
+	HTTPClient *http.Client // Client used for requests; `http.DefaultClient` when nil
+}
+
+// OPA evaluates inputs with an Open Policy Agent server
+type OPA struct {
+	config OPAConfig
+}
+
+type opaResponse struct {
+	Result *json.RawMessage `json:"result"`
+}
+
+// opaVerdict is a package result; rules named `allow`, `deny` and `warn` are read from it
+type opaVerdict struct {
+	Allow *bool    `json:"allow"`
+	Deny  []string `json:"deny"`
+	Warn  []string `json:"warn"`
+}
+
+// END OF OPA STRUCTS
+//---------------------------------------------------------------------
+
+/*
+ * # Open Policy Agent
+ * Returns a policy which queries the Data API of an OPA server with each input
+ * https://www.openpolicyagent.org/docs/latest/rest-api/#get-a-document-with-input
+ * - The rule at `Path` may be a boolean (whether the operation is allowed), or an object with any of:
+ *   - `allow`: whether the operation is allowed
+ *   - `deny`: reasons to deny the operation
+ *   - `warn`: reasons to flag the operation
+ * - An undefined rule abstains
+ */
+func NewOPA(cfg OPAConfig) (*OPA, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("opa: no URL configured")
+	}
+	if cfg.Path == "" {
+		return nil, errors.New("opa: no path configured")
+	}
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	cfg.Path = strings.ReplaceAll(strings.Trim(cfg.Path, "/"), ".", "/")
+	return &OPA{config: cfg}, nil
+}
+
+// Evaluate queries the server with the input
+func (o *OPA) Evaluate(ctx context.Context, in *Input) (*Decision, error) {
+	data, err := json.Marshal(map[string]interface{}{"input": in})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v1/data/%s", o.config.URL, o.config.Path), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+o.config.Token)
+	}
+
+	c := o.config.HTTPClient
+	if c == nil {
+		c = http.DefaultClient
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("opa: %s: %s", resp.Status, body)
+	}
+
+	result := opaResponse{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("opa: decoding result: %w", err)
+	}
+	if result.Result == nil {
+		return nil, nil
+	}
+	return decide(*result.Result)
+}
+
+// decide converts the result of a rule to a decision
+func decide(raw json.RawMessage) (*Decision, error) {
+	allowed := false
+	if err := json.Unmarshal(raw, &allowed); err == nil {
+		if allowed {
+			return nil, nil
+		}
+		return &Decision{Effect: Deny, Reasons: []string{"not allowed"}}, nil
+	}
+
+	verdict := opaVerdict{}
+	if err := json.Unmarshal(raw, &verdict); err != nil {
+		return nil, fmt.Errorf("opa: result is neither a boolean nor an object of allow, deny and warn: %s", raw)
+	}
+
+	switch {
+	case len(verdict.Deny) > 0:
+		return &Decision{Effect: Deny, Reasons: verdict.Deny}, nil
+	case verdict.Allow != nil && !*verdict.Allow:
+		return &Decision{Effect: Deny, Reasons: append([]string{"not allowed"}, verdict.Warn...)}, nil
+	case len(verdict.Warn) > 0:
+		return &Decision{Effect: Warn, Reasons: verdict.Warn}, nil
+	}
+	return nil, nil
+}
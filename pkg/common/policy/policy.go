@@ -0,0 +1,295 @@
+/*
+# Policy
+
+This package checks mutating operations and generated reports against user-supplied policies before they happen, e.g.:
+  - block an offboarding while its subject is in a legal hold group
+  - flag (or block) requests which add members to groups granting admin scopes
+  - keep reports with sensitive columns from being exported
+Policies are Go predicates, or rules evaluated by an Open Policy Agent server (`NewOPA`).
+
+	engine := policy.NewEngine(log.INFO)
+	engine.Add("legal-hold", policy.LegalHold(inHoldGroup))
+	engine.Add("admin-groups", policy.ProtectedGroups(policy.Warn, adminGroupIDs...))
+	opa, _ := policy.NewOPA(policy.OPAConfig{URL: "http://localhost:8181", Path: "rego/authz"})
+	engine.Add("opa", opa)
+
+	o.EnforcePolicy(engine)                          // Workflows, and every mutating request of the orchestrator's clients
+	o.Okta.HTTP.Authorize = engine.Authorizer("okta") // Or the requests of a single client
+	err := engine.CheckReport(ctx, title, sheets)     // Reports, before they are exported
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/policy/policy.go
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gemini-oss/rego/pkg/common/exporters"
+	"github.com/gemini-oss/rego/pkg/common/log"
+)
+
+// ErrDenied is matched (with `errors.Is`) by every denial
+var ErrDenied = errors.New("denied by policy")
+
+// Actions evaluated by the engine
+const (
+	ActionRequest  = "request"   // A mutating request to a provider
+	ActionReport   = "report"    // A report about to be exported
+	ActionWorkflow = "workflow." // Prefix of a workflow about to run, e.g. `workflow.offboarding`
+)
+
+// Effect is the outcome of a policy
+type Effect string
+
+const (
+	Allow Effect = "allow" // The operation may proceed
+	Warn  Effect = "warn"  // The operation may proceed, but is flagged
+	Deny  Effect = "deny"  // The operation is blocked
+)
+
+// rank orders the effects, least severe first
+var rank = map[Effect]int{Allow: 0, Warn: 1, Deny: 2}
+
+// ### Policy Structs
+// ---------------------------------------------------------------------
+
+// Input is an operation to evaluate
+type Input struct {
+	Action   string      `json:"action"`             // What is about to happen; see the `Action*` constants
+	Subject  string      `json:"subject,omitempty"`  // Who (or what) it happens to, e.g. the email of the user offboarded, or a report title
+	Provider string      `json:"provider,omitempty"` // Service the request is sent to, e.g. `okta`
+	Method   string      `json:"method,omitempty"`   // Method of the request
+	URL      string      `json:"url,omitempty"`      // URL of the request
+	Data     interface{} `json:"data,omitempty"`     // Payload of the request, steps of the workflow, or sheets of the report
+}
+
+// Decision is the verdict of a policy on an input
+type Decision struct {
+	Policy  string   `json:"policy"`            // Name the policy was added with
+	Effect  Effect   `json:"effect"`            // Outcome of the policy
+	Reasons []string `json:"reasons,omitempty"` // Why the operation was flagged or denied
+}
+
+// Result is the verdict of every policy on an input
+type Result struct {
+	Input     *Input      `json:"input"`
+	Effect    Effect      `json:"effect"`    // Most severe effect of the decisions
+	Decisions []*Decision `json:"decisions"` // Decisions which warned or denied
+}
+
+// DeniedError is returned for an operation a policy denied
+type DeniedError struct {
+	Result *Result
+}
+
+// Policy decides whether an operation may proceed; a nil decision abstains
+type Policy interface {
+	Evaluate(ctx context.Context, in *Input) (*Decision, error)
+}
+
+// Func adapts a function to a Policy
+type Func func(ctx context.Context, in *Input) (*Decision, error)
+
+// Engine evaluates inputs against every policy added to it
+type Engine struct {
+	Log      *log.Logger
+	FailOpen bool // Treat a policy which fails to evaluate as a warning instead of a denial
+
+	mutex    sync.RWMutex
+	names    []string
+	policies map[string]Policy
+}
+
+// END OF POLICY STRUCTS
+//---------------------------------------------------------------------
+
+// Evaluate calls the function
+func (f Func) Evaluate(ctx context.Context, in *Input) (*Decision, error) {
+	return f(ctx, in)
+}
+
+func (e *DeniedError) Error() string {
+	reasons := []string{}
+	for _, d := range e.Result.Decisions {
+		if d.Effect != Deny {
+			continue
+		}
+		if len(d.Reasons) == 0 {
+			reasons = append(reasons, d.Policy)
+		}
+		for _, r := range d.Reasons {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", d.Policy, r))
+		}
+	}
+	action := e.Result.Input.Action
+	if e.Result.Input.Subject != "" {
+		action += " " + e.Result.Input.Subject
+	}
+	return fmt.Sprintf("%s %s (%s)", action, ErrDenied, strings.Join(reasons, "; "))
+}
+
+// Is matches `ErrDenied`
+func (e *DeniedError) Is(target error) bool {
+	return target == ErrDenied
+}
+
+// NewEngine returns an engine without any policies, which allows everything
+func NewEngine(verbosity int) *Engine {
+	return &Engine{
+		Log:      log.NewLogger("{policy}", verbosity),
+		policies: map[string]Policy{},
+	}
+}
+
+// Add adds (or replaces) a named policy; policies are evaluated in the order they were first added
+func (e *Engine) Add(name string, p Policy) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if _, ok := e.policies[name]; !ok {
+		e.names = append(e.names, name)
+	}
+	e.policies[name] = p
+}
+
+/*
+ * # Evaluate an Input
+ * Returns the verdict of every policy; the operation should only proceed when `Result.Effect` is not `Deny`
+ * - A policy which fails to evaluate denies the operation, unless the engine fails open
+ * - Warnings and denials are logged
+ */
+func (e *Engine) Evaluate(ctx context.Context, in *Input) *Result {
+	e.mutex.RLock()
+	names := append([]string{}, e.names...)
+	policies := map[string]Policy{}
+	for name, p := range e.policies {
+		policies[name] = p
+	}
+	e.mutex.RUnlock()
+
+	result := &Result{Input: in, Effect: Allow, Decisions: []*Decision{}}
+	for _, name := range names {
+		d, err := policies[name].Evaluate(ctx, in)
+		if err != nil {
+			d = &Decision{Effect: Deny, Reasons: []string{fmt.Sprintf("evaluation failed: %v", err)}}
+			if e.FailOpen {
+				d.Effect = Warn
+			}
+		}
+		if d == nil || d.Effect == Allow || d.Effect == "" {
+			continue
+		}
+
+		d.Policy = name
+		result.Decisions = append(result.Decisions, d)
+		if rank[d.Effect] > rank[result.Effect] {
+			result.Effect = d.Effect
+		}
+		e.Log.Warningf("[%s] %s %s %s: %s", name, d.Effect, in.Action, in.Subject+in.URL, strings.Join(d.Reasons, "; "))
+	}
+	return result
+}
+
+// Check evaluates an input, returning a `*DeniedError` if it is denied
+func (e *Engine) Check(ctx context.Context, in *Input) error {
+	result := e.Evaluate(ctx, in)
+	if result.Effect == Deny {
+		return &DeniedError{Result: result}
+	}
+	return nil
+}
+
+/*
+ * # Authorize Requests
+ * Returns a hook for `requests.Client.Authorize`, which checks every mutating request of a client before it is sent
+ */
+func (e *Engine) Authorizer(provider string) func(method, url string, data interface{}) error {
+	return func(method, url string, data interface{}) error {
+		return e.Check(context.Background(), &Input{Action: ActionRequest, Provider: provider, Method: method, URL: url, Data: data})
+	}
+}
+
+// CheckReport checks a report before it is exported; the input holds the data of each sheet, by name
+func (e *Engine) CheckReport(ctx context.Context, title string, sheets []exporters.Sheet) error {
+	data := map[string]interface{}{}
+	for _, s := range sheets {
+		data[s.Name] = s.Data
+	}
+	return e.Check(ctx, &Input{Action: ActionReport, Subject: title, Data: data})
+}
+
+// Predicate returns a policy with the given effect for the inputs `match` returns true for
+func Predicate(effect Effect, reason string, match func(in *Input) bool) Policy {
+	return Func(func(_ context.Context, in *Input) (*Decision, error) {
+		if !match(in) {
+			return nil, nil
+		}
+		return &Decision{Effect: effect, Reasons: []string{reason}}, nil
+	})
+}
+
+/*
+ * # Legal Hold
+ * Denies workflows (offboarding by default) whose subject is on legal hold
+ * - `held` reports whether a subject is on hold, e.g. by checking the members of a legal hold group
+ */
+func LegalHold(held func(ctx context.Context, subject string) (bool, error), workflows ...string) Policy {
+	if len(workflows) == 0 {
+		workflows = []string{"offboarding"}
+	}
+
+	return Func(func(ctx context.Context, in *Input) (*Decision, error) {
+		matched := false
+		for _, w := range workflows {
+			matched = matched || in.Action == ActionWorkflow+w
+		}
+		if !matched || in.Subject == "" {
+			return nil, nil
+		}
+
+		onHold, err := held(ctx, in.Subject)
+		if err != nil || !onHold {
+			return nil, err
+		}
+		return &Decision{Effect: Deny, Reasons: []string{fmt.Sprintf("%s is on legal hold", in.Subject)}}, nil
+	})
+}
+
+/*
+ * # Protected Groups
+ * Flags (or denies) requests which add members to the given groups, e.g. those granting admin roles or scopes
+ * - Matches `PUT`/`POST` requests to `.../groups/<group>/...`, as Okta and Google Workspace add members
+ */
+func ProtectedGroups(effect Effect, groups ...string) Policy {
+	protected := map[string]bool{}
+	for _, g := range groups {
+		protected[strings.ToLower(g)] = true
+	}
+
+	return Func(func(_ context.Context, in *Input) (*Decision, error) {
+		if in.Action != ActionRequest || (in.Method != "PUT" && in.Method != "POST") {
+			return nil, nil
+		}
+		u, err := url.Parse(in.URL)
+		if err != nil {
+			return nil, nil
+		}
+
+		segments := strings.Split(u.Path, "/")
+		for i := 0; i < len(segments)-2; i++ {
+			if segments[i] == "groups" && protected[strings.ToLower(segments[i+1])] {
+				return &Decision{Effect: effect, Reasons: []string{fmt.Sprintf("adds a member to protected group %s", segments[i+1])}}, nil
+			}
+		}
+		return nil, nil
+	})
+}
@@ -0,0 +1,428 @@
+/*
+# Pool
+
+This package fans bulk operations (fetching pages, looking up users, downloading exports, ...) out to a bounded number
+of workers, with per-item results, context cancellation, and awareness of a provider's rate limiter:
+
+	results := pool.Map(ctx, users, pool.Options{Workers: 10, RateLimiter: c.HTTP.RateLimiter},
+		func(ctx context.Context, user *okta.User) (*okta.Roles, error) {
+			return c.GetUserRoles(user.ID)
+		})
+	for _, r := range results {
+		if r.Err != nil { ... }
+	}
+
+The partitions of a large directory can be paginated concurrently, and consumed as a single sequence, with `Merge`:
+
+	users := pool.Merge(ctx, []iterator.Seq[*okta.User]{staged, active, suspended}, pool.Options{Workers: 3})
+
+The pages of a list paginated by offset can be requested concurrently, and yielded in order, with `Pages`:
+
+	users := pool.Pages(ctx, 100, pool.Options{Workers: 5}, func(ctx context.Context, offset, limit int) ([]*User, error) { ... })
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/pool/pool.go
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/ratelimit"
+)
+
+// Number of workers used when `Options.Workers` is not set
+const DefaultWorkers = 10
+
+// ### Pool Structs
+// ---------------------------------------------------------------------
+
+// Options configures how work is fanned out
+type Options struct {
+	Workers     int                    // Maximum number of items processed at once; `DefaultWorkers` when zero
+	RateLimiter *ratelimit.RateLimiter // Workers hold back while the limiter is throttling, instead of piling up requests; optional
+	StopOnError bool                   // Cancel the remaining items after the first error
+	Ordered     bool                   // `Merge` yields the items of each sequence after those of the sequences before it
+}
+
+// Result is the outcome of a single item
+type Result[T, R any] struct {
+	Index int   // Position of the item in the input
+	Item  T     // The item
+	Value R     // Value returned for the item
+	Err   error // Error returned for the item, or the context's error if it was never processed
+}
+
+// Group runs functions on a bounded number of workers, like `errgroup.Group` with a limit
+type Group struct {
+	opts    Options
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	sem     chan struct{}
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+	errs    []error
+	skipped bool
+}
+
+// END OF POOL STRUCTS
+//---------------------------------------------------------------------
+
+/*
+ * # Generate a Group
+ * - The returned context is cancelled when `Wait` returns, or after the first error with `opts.StopOnError`
+ */
+func NewGroup(ctx context.Context, opts Options) (*Group, context.Context) {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{
+		opts:   opts,
+		ctx:    ctx,
+		cancel: cancel,
+		sem:    make(chan struct{}, opts.Workers),
+	}, ctx
+}
+
+/*
+ * # Run a function on the next free worker
+ * - Blocks while every worker is busy
+ * - Once the group's context is done, `fn` is no longer run and Go returns false
+ * - A panic in `fn` is recovered and returned as its error
+ */
+func (g *Group) Go(fn func(ctx context.Context) error) bool {
+	select {
+	case g.sem <- struct{}{}:
+	case <-g.ctx.Done():
+		g.skip()
+		return false
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer func() { <-g.sem }()
+
+		g.throttle()
+		if g.ctx.Err() != nil {
+			g.skip()
+			return
+		}
+		if err := call(g.ctx, fn); err != nil {
+			g.mutex.Lock()
+			g.errs = append(g.errs, err)
+			g.mutex.Unlock()
+			if g.opts.StopOnError {
+				g.cancel(err)
+			}
+		}
+	}()
+	return true
+}
+
+// Wait blocks until every function has returned, then returns their joined errors
+// - When functions were skipped because the parent context was cancelled, and none failed, the context's error is returned
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	defer g.cancel(context.Canceled)
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if len(g.errs) == 0 && g.skipped {
+		return context.Cause(g.ctx)
+	}
+	return errors.Join(g.errs...)
+}
+
+func (g *Group) skip() {
+	g.mutex.Lock()
+	g.skipped = true
+	g.mutex.Unlock()
+}
+
+// throttle holds a worker back while the rate limiter would make its requests wait
+func (g *Group) throttle() {
+	if g.opts.RateLimiter == nil {
+		return
+	}
+	for {
+		wait := g.opts.RateLimiter.Throttled()
+		if wait <= 0 {
+			return
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-g.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+func call(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+/*
+ * # Map items to values on a bounded number of workers
+ * - Results are returned in the order of `items`, whether or not each succeeded
+ * - Items which were never processed (because the context was cancelled) carry the context's error
+ */
+func Map[T, R any](ctx context.Context, items []T, opts Options, fn func(ctx context.Context, item T) (R, error)) []Result[T, R] {
+	results := make([]Result[T, R], len(items))
+	ran := make([]bool, len(items))
+
+	g, ctx := NewGroup(ctx, opts)
+	for i, item := range items {
+		results[i] = Result[T, R]{Index: i, Item: item}
+		g.Go(func(ctx context.Context) error {
+			ran[i] = true
+			value, err := fn(ctx, item)
+			results[i].Value, results[i].Err = value, err
+			return err
+		})
+	}
+	g.Wait()
+
+	for i := range results {
+		if !ran[i] {
+			results[i].Err = context.Cause(ctx)
+		}
+	}
+	return results
+}
+
+// Each runs `fn` for every item on a bounded number of workers, returning the joined errors
+func Each[T any](ctx context.Context, items []T, opts Options, fn func(ctx context.Context, item T) error) error {
+	g, _ := NewGroup(ctx, opts)
+	for _, item := range items {
+		g.Go(func(ctx context.Context) error {
+			return fn(ctx, item)
+		})
+	}
+	return g.Wait()
+}
+
+// Number of items of a sequence buffered by `Merge` with `Options.Ordered`, before its worker waits for its turn
+const orderedBuffer = 1000
+
+/*
+ * # Merge sequences on a bounded number of workers
+ * Consumes each sequence, e.g. the pages of one partition of a directory, on the next free worker, yielding their items
+ * as they arrive
+ * - Items of different sequences are interleaved; each sequence keeps its own order
+ * - With `opts.Ordered`, the items are yielded in the order of `seqs` instead: the sequences consumed ahead of their turn
+ *   are buffered, up to `orderedBuffer` items each
+ * - The first error cancels the other sequences, and is yielded last; stopping early cancels them as well
+ */
+func Merge[V any](ctx context.Context, seqs []iterator.Seq[V], opts Options) iterator.Seq[V] {
+	if opts.Ordered {
+		return ordered(ctx, seqs, opts)
+	}
+	return func(yield func(V, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		opts.StopOnError = true
+		g, _ := NewGroup(ctx, opts)
+		items := make(chan V)
+		done := make(chan error, 1)
+		go func() {
+			for _, seq := range seqs {
+				if !g.Go(func(ctx context.Context) error { return send(ctx, seq, items) }) {
+					break
+				}
+			}
+			done <- g.Wait()
+			close(items)
+		}()
+
+		for item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err := <-done; err != nil {
+			var zero V
+			yield(zero, err)
+		}
+	}
+}
+
+/*
+ * Merges sequences like `Merge`, yielding their items in order
+ * - Sequences are started in order, so the one being yielded always holds a worker, and those ahead of it wait on their
+ *   full buffers without starving it
+ */
+func ordered[V any](ctx context.Context, seqs []iterator.Seq[V], opts Options) iterator.Seq[V] {
+	return func(yield func(V, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		opts.StopOnError = true
+		g, _ := NewGroup(ctx, opts)
+		buffers := make([]chan V, len(seqs))
+		closers := make([]func(), len(seqs))
+		for i := range buffers {
+			buffers[i] = make(chan V, orderedBuffer)
+			closers[i] = sync.OnceFunc(func() { close(buffers[i]) })
+		}
+		done := make(chan error, 1)
+		go func() {
+			for i, seq := range seqs {
+				if !g.Go(func(ctx context.Context) error {
+					defer closers[i]()
+					return send(ctx, seq, buffers[i])
+				}) {
+					break
+				}
+			}
+			// Sequences skipped once the context was cancelled are closed too
+			err := g.Wait()
+			for _, closer := range closers {
+				closer()
+			}
+			done <- err
+		}()
+
+		for _, buffer := range buffers {
+			for item := range buffer {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+		if err := <-done; err != nil {
+			var zero V
+			yield(zero, err)
+		}
+	}
+}
+
+/*
+ * # Fetch pages on a bounded number of workers
+ * Requests the pages of a list paginated by offset, e.g. Backupify's `start` and `length`, up to `opts.Workers` at a
+ * time, and yields their items in order
+ * - The list ends with a page of fewer than `size` items; the pages requested beyond it are cancelled and discarded
+ * - Workers hold back while `opts.RateLimiter` is throttling
+ * - The first error cancels the other requests, and is yielded after the items of the pages before it
+ */
+func Pages[V any](ctx context.Context, size int, opts Options, fetch func(ctx context.Context, offset, limit int) ([]V, error)) iterator.Seq[V] {
+	type page struct {
+		items []V
+		err   error
+	}
+	if size <= 0 {
+		size = 100
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+
+	return func(yield func(V, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		g, gctx := NewGroup(ctx, opts)
+		defer func() {
+			cancel()
+			g.Wait()
+		}()
+
+		pending := []chan page{}
+		requested := 0
+		request := func() {
+			offset := requested * size
+			requested++
+			result := make(chan page, 1)
+			pending = append(pending, result)
+			ran := g.Go(func(ctx context.Context) error {
+				var p page
+				defer func() {
+					if r := recover(); r != nil {
+						p.err = fmt.Errorf("panic: %v", r)
+					}
+					result <- p
+				}()
+				p.items, p.err = fetch(ctx, offset, size)
+				return nil
+			})
+			if !ran {
+				result <- page{err: context.Cause(gctx)}
+			}
+		}
+
+		for {
+			for len(pending) < opts.Workers {
+				request()
+			}
+			p := <-pending[0]
+			pending = pending[1:]
+			if p.err != nil {
+				var zero V
+				yield(zero, p.err)
+				return
+			}
+			for _, item := range p.items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(p.items) < size {
+				return
+			}
+		}
+	}
+}
+
+// send consumes a sequence into `items`, until it ends, fails, or `ctx` is done
+func send[V any](ctx context.Context, seq iterator.Seq[V], items chan<- V) error {
+	var err error
+	seq(func(item V, e error) bool {
+		if e != nil {
+			err = e
+			return false
+		}
+		select {
+		case items <- item:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+	return err
+}
+
+// Values returns the values of the successful results, in order
+func Values[T, R any](results []Result[T, R]) []R {
+	values := []R{}
+	for _, r := range results {
+		if r.Err == nil {
+			values = append(values, r.Value)
+		}
+	}
+	return values
+}
+
+// FirstError returns the error of the first failed result, in input order
+func FirstError[T, R any](results []Result[T, R]) error {
+	for _, r := range results {
+		if r.Err != nil {
+			return r.Err
+		}
+	}
+	return nil
+}
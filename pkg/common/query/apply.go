@@ -0,0 +1,159 @@
+// pkg/common/query/apply.go
+package query
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+)
+
+/*
+ * # Apply
+ * Returns the items of `seq` which match the query, sorted, limited and with only its selected fields
+ * - Without an order, items are yielded as pages arrive and no further pages are requested after the limit
+ * - With an order, every item is read before the first is yielded
+ */
+func Apply[V any](seq iterator.Seq[V], q *Query) iterator.Seq[V] {
+	if q == nil {
+		return seq
+	}
+
+	if len(q.Where) > 0 {
+		seq = iterator.Filter(seq, func(v V) bool {
+			doc, err := document(v)
+			if err != nil {
+				return false
+			}
+			for _, p := range q.Where {
+				if !p.match(doc) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+	if len(q.Order) > 0 {
+		seq = sorted(seq, q.Order)
+	}
+	if q.Limit > 0 {
+		seq = iterator.Take(seq, q.Limit)
+	}
+	if len(q.Fields) > 0 {
+		seq = project(seq, q.Fields)
+	}
+	return seq
+}
+
+// ApplySlice returns the items of a list which match the query, e.g. of a cached `ListAll...`, as `Apply` does
+func ApplySlice[V any](items []V, q *Query) ([]V, error) {
+	return iterator.Collect(Apply(iterator.Pages(func(string) ([]V, string, error) {
+		return items, "", nil
+	}), q))
+}
+
+// sorted reads every item of `seq` and yields them in `orders`; an error of `seq` is yielded in place of the items
+func sorted[V any](seq iterator.Seq[V], orders []Order) iterator.Seq[V] {
+	return func(yield func(V, error) bool) {
+		items, err := iterator.Collect(seq)
+		if err != nil {
+			var zero V
+			yield(zero, err)
+			return
+		}
+
+		docs := make([]interface{}, len(items))
+		for i, item := range items {
+			docs[i], _ = document(item)
+		}
+		indexes := make([]int, len(items))
+		for i := range indexes {
+			indexes[i] = i
+		}
+		sort.SliceStable(indexes, func(i, j int) bool {
+			return less(docs[indexes[i]], docs[indexes[j]], orders)
+		})
+
+		for _, i := range indexes {
+			if !yield(items[i], nil) {
+				return
+			}
+		}
+	}
+}
+
+// less returns whether document `a` sorts before `b`; items missing a field sort last
+func less(a, b interface{}, orders []Order) bool {
+	for _, o := range orders {
+		va, aok := lookup(a, string(o.Field))
+		vb, bok := lookup(b, string(o.Field))
+		if !aok || !bok || va == nil || vb == nil {
+			if (aok && va != nil) != (bok && vb != nil) {
+				return aok && va != nil
+			}
+			continue
+		}
+
+		c, ok := order(va, vb)
+		if !ok || c == 0 {
+			continue
+		}
+		if o.Desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	return false
+}
+
+// project yields a copy of each item of `seq` with only `fields`
+func project[V any](seq iterator.Seq[V], fields []string) iterator.Seq[V] {
+	return func(yield func(V, error) bool) {
+		seq(func(v V, err error) bool {
+			if err != nil {
+				return yield(v, err)
+			}
+			selected, err := Project(v, fields)
+			if err != nil {
+				return yield(v, err)
+			}
+			return yield(selected, nil)
+		})
+	}
+}
+
+// Project returns a copy of `item` with only `fields`, e.g. `"id", "profile.email"`
+func Project[V any](item V, fields []string) (V, error) {
+	var selected V
+	doc, err := document(item)
+	if err != nil {
+		return selected, err
+	}
+
+	kept := map[string]interface{}{}
+	for _, field := range fields {
+		value, found := lookup(doc, field)
+		if !found {
+			continue
+		}
+		keys := strings.Split(field, ".")
+		m := kept
+		for _, key := range keys[:len(keys)-1] {
+			next, ok := m[key].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				m[key] = next
+			}
+			m = next
+		}
+		m[keys[len(keys)-1]] = value
+	}
+
+	data, err := json.Marshal(kept)
+	if err != nil {
+		return selected, err
+	}
+	err = json.Unmarshal(data, &selected)
+	return selected, err
+}
@@ -0,0 +1,168 @@
+// pkg/common/query/parse.go
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+/*
+ * # Parse
+ * Returns the predicates of a SCIM (RFC-7644) filter expression, e.g. `status eq "ACTIVE" and (a pr or b sw "x")`
+ * - The expression is a conjunction of comparisons and parenthesized disjunctions, as written by `Expression`, or a
+ *   disjunction of comparisons
+ * - Values are JSON: quoted strings, numbers, `true`, `false` or `null`
+ */
+func Parse(expr string) ([]Predicate, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+
+	var predicates []Predicate
+	connective := ""
+	for {
+		term, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, term)
+		if p.done() {
+			break
+		}
+
+		word := strings.ToLower(p.next())
+		if word != "and" && word != "or" {
+			return nil, fmt.Errorf("parsing %q: expected `and` or `or`, got %q", expr, word)
+		}
+		if connective != "" && word != connective {
+			return nil, fmt.Errorf("parsing %q: `and` and `or` must be grouped with parentheses", expr)
+		}
+		connective = word
+	}
+
+	if connective == "or" {
+		return []Predicate{Or(predicates...)}, nil
+	}
+	return predicates, nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// keyword consumes the next token if it is `word`, ignoring case
+func (p *parser) keyword(word string) bool {
+	if strings.EqualFold(p.peek(), word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// term parses a comparison, or a parenthesized disjunction
+func (p *parser) term() (Predicate, error) {
+	if p.peek() != "(" {
+		return p.comparison()
+	}
+	p.next()
+
+	var alts []Predicate
+	for {
+		alt, err := p.term()
+		if err != nil {
+			return Predicate{}, err
+		}
+		alts = append(alts, alt)
+		if p.peek() == ")" {
+			p.next()
+			if len(alts) == 1 {
+				return alts[0], nil
+			}
+			return Or(alts...), nil
+		}
+		if !p.keyword("or") {
+			return Predicate{}, fmt.Errorf("expected `or` or `)`, got %q", p.peek())
+		}
+	}
+}
+
+// comparison parses `field op value`, or `field pr`
+func (p *parser) comparison() (Predicate, error) {
+	field, op := p.next(), Op(strings.ToLower(p.next()))
+	if field == "" || field == "(" || field == ")" {
+		return Predicate{}, fmt.Errorf("expected a field, got %q", field)
+	}
+
+	switch op {
+	case Present:
+		return Predicate{Field: Field(field), Op: Present}, nil
+	case Eq, Ne, Gt, Ge, Lt, Le, StartsWith, Contains:
+	default:
+		return Predicate{}, fmt.Errorf("unsupported operator %q of %s", op, field)
+	}
+
+	raw := p.next()
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return Predicate{}, fmt.Errorf("invalid value %s of %s: %w", raw, field, err)
+	}
+	return Predicate{Field: Field(field), Op: op, Value: value}, nil
+}
+
+// tokenize splits an expression into parentheses, quoted strings and words
+func tokenize(expr string) ([]string, error) {
+	var tokens []string
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '"':
+			j := i + 1
+			for ; j < len(runes) && runes[j] != '"'; j++ {
+				if runes[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string in %q", expr)
+			}
+			tokens = append(tokens, string(runes[i:j+1]))
+			i = j + 1
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && runes[j] != '(' && runes[j] != ')' {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		}
+	}
+	return tokens, nil
+}
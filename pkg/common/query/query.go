@@ -0,0 +1,363 @@
+/*
+# Query
+
+This package provides a small query language for list endpoints: filter predicates, field selection, sorting and a
+limit, written the same way for any resource:
+
+```go
+
+	users, err := o.ListUsers(
+		query.Where(okta.UserStatus.Eq("ACTIVE"), okta.UserEmail.StartsWith("a")),
+		query.Select("id", "profile.email"),
+		query.OrderBy(okta.UserEmail),
+	)
+
+```
+
+Fields are the JSON paths of the resource, e.g. `profile.email`. Endpoints push the parts of a query their API supports
+down to its query parameters (see `Split`), and `Apply` evaluates the rest client-side, so any sequence can be queried:
+
+```go
+
+	admins := query.Apply(g.Users().IterUsers(nil), query.New(query.Where(query.Field("isAdmin").Eq(true))))
+
+```
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/query/query.go
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Op is the comparison of a predicate, named after its SCIM (RFC-7644) operator
+type Op string
+
+const (
+	Eq         Op = "eq" // Equal
+	Ne         Op = "ne" // Not equal
+	Gt         Op = "gt" // Greater than
+	Ge         Op = "ge" // Greater than or equal
+	Lt         Op = "lt" // Less than
+	Le         Op = "le" // Less than or equal
+	StartsWith Op = "sw" // Starts with
+	Contains   Op = "co" // Contains
+	Present    Op = "pr" // Has a value
+)
+
+// Field is the JSON path of a field of a resource, e.g. `profile.email`
+type Field string
+
+func (f Field) Eq(v interface{}) Predicate    { return Predicate{Field: f, Op: Eq, Value: v} }
+func (f Field) Ne(v interface{}) Predicate    { return Predicate{Field: f, Op: Ne, Value: v} }
+func (f Field) Gt(v interface{}) Predicate    { return Predicate{Field: f, Op: Gt, Value: v} }
+func (f Field) Ge(v interface{}) Predicate    { return Predicate{Field: f, Op: Ge, Value: v} }
+func (f Field) Lt(v interface{}) Predicate    { return Predicate{Field: f, Op: Lt, Value: v} }
+func (f Field) Le(v interface{}) Predicate    { return Predicate{Field: f, Op: Le, Value: v} }
+func (f Field) StartsWith(s string) Predicate { return Predicate{Field: f, Op: StartsWith, Value: s} }
+func (f Field) Contains(s string) Predicate   { return Predicate{Field: f, Op: Contains, Value: s} }
+func (f Field) Present() Predicate            { return Predicate{Field: f, Op: Present} }
+
+// Predicate is a condition on a field of a resource, or with `Any`, a disjunction of conditions
+type Predicate struct {
+	Field Field       // Field compared
+	Op    Op          // Comparison
+	Value interface{} // Value compared with; unused by `Present`
+	Any   []Predicate // Matches when any of these match, in place of the comparison
+}
+
+// Or returns a predicate matching when any of `predicates` match
+func Or(predicates ...Predicate) Predicate {
+	return Predicate{Any: predicates}
+}
+
+// Order sorts by a field
+type Order struct {
+	Field Field
+	Desc  bool // Descending rather than ascending
+}
+
+// Query holds the conditions, fields, order and limit of a list
+type Query struct {
+	Where  []Predicate // Items must match every predicate
+	Fields []string    // Fields kept in each item; every field when empty
+	Order  []Order     // Sort keys, by precedence; the order of the API when empty
+	Limit  int         // Maximum number of items; no limit when zero
+}
+
+// Option configures a query
+type Option func(*Query)
+
+// New returns the query configured by `opts`, applied in order
+func New(opts ...Option) *Query {
+	q := &Query{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(q)
+		}
+	}
+	return q
+}
+
+// Where keeps the items matching every predicate
+func Where(predicates ...Predicate) Option {
+	return func(q *Query) {
+		q.Where = append(q.Where, predicates...)
+	}
+}
+
+// Select keeps only `fields` in each item, e.g. `"id", "profile.email"`; the other fields are left at their zero value
+func Select(fields ...string) Option {
+	return func(q *Query) {
+		q.Fields = append(q.Fields, fields...)
+	}
+}
+
+// OrderBy sorts the items by `field`, ascending; later orders break ties
+func OrderBy(field Field) Option {
+	return func(q *Query) {
+		q.Order = append(q.Order, Order{Field: field})
+	}
+}
+
+// OrderByDesc sorts the items by `field`, descending; later orders break ties
+func OrderByDesc(field Field) Option {
+	return func(q *Query) {
+		q.Order = append(q.Order, Order{Field: field, Desc: true})
+	}
+}
+
+// Limit keeps at most the first `n` items
+func Limit(n int) Option {
+	return func(q *Query) {
+		q.Limit = n
+	}
+}
+
+/*
+ * # Split
+ * Returns the predicates of the query which `supported` accepts, for an endpoint to push down to its API, and a copy
+ * of the query with the others, which `Apply` evaluates client-side
+ * - A disjunction is pushed down only when each of its predicates is supported
+ */
+func (q *Query) Split(supported func(Predicate) bool) (remote []Predicate, local *Query) {
+	local = &Query{Fields: q.Fields, Order: q.Order, Limit: q.Limit}
+	for _, p := range q.Where {
+		if p.supported(supported) {
+			remote = append(remote, p)
+		} else {
+			local.Where = append(local.Where, p)
+		}
+	}
+	return remote, local
+}
+
+func (p Predicate) supported(supported func(Predicate) bool) bool {
+	if p.Any == nil {
+		return supported(p)
+	}
+	for _, alt := range p.Any {
+		if !alt.supported(supported) {
+			return false
+		}
+	}
+	return len(p.Any) > 0
+}
+
+/*
+ * # Expression
+ * Returns the predicate as a SCIM (RFC-7644) filter expression, e.g. `status eq "ACTIVE"`, which APIs such as Okta's
+ * `search` accept
+ */
+func (p Predicate) Expression() string {
+	if p.Any != nil {
+		alts := make([]string, len(p.Any))
+		for i, alt := range p.Any {
+			alts[i] = alt.Expression()
+		}
+		return "(" + strings.Join(alts, " or ") + ")"
+	}
+	if p.Op == Present {
+		return fmt.Sprintf("%s pr", p.Field)
+	}
+	value, err := json.Marshal(p.Value)
+	if err != nil {
+		value = []byte(fmt.Sprintf("%q", fmt.Sprint(p.Value)))
+	}
+	return fmt.Sprintf("%s %s %s", p.Field, p.Op, value)
+}
+
+// Expression joins the predicates as a SCIM (RFC-7644) filter expression
+func Expression(predicates []Predicate) string {
+	exprs := make([]string, len(predicates))
+	for i, p := range predicates {
+		exprs[i] = p.Expression()
+	}
+	return strings.Join(exprs, " and ")
+}
+
+/*
+ * # Match
+ * Returns whether `item` satisfies the predicate; its fields are resolved by their JSON names
+ * - Strings are compared exactly; times (RFC-3339) and numbers by their value
+ * - A field holding a list matches when any of its elements match
+ */
+func (p Predicate) Match(item interface{}) bool {
+	doc, err := document(item)
+	if err != nil {
+		return false
+	}
+	return p.match(doc)
+}
+
+func (p Predicate) match(doc interface{}) bool {
+	if p.Any != nil {
+		for _, alt := range p.Any {
+			if alt.match(doc) {
+				return true
+			}
+		}
+		return false
+	}
+
+	value, found := lookup(doc, string(p.Field))
+	if p.Op == Present {
+		return found && value != nil && value != ""
+	}
+	if p.Op == Ne {
+		return !Predicate{Field: p.Field, Op: Eq, Value: p.Value}.match(doc)
+	}
+	if !found {
+		return false
+	}
+
+	expected, err := document(p.Value)
+	if err != nil {
+		return false
+	}
+	if values, ok := value.([]interface{}); ok {
+		for _, v := range values {
+			if compare(v, p.Op, expected) {
+				return true
+			}
+		}
+		return false
+	}
+	return compare(value, p.Op, expected)
+}
+
+// compare applies `op` to a value of a field and the value of the predicate, both decoded from JSON
+func compare(actual interface{}, op Op, expected interface{}) bool {
+	switch op {
+	case Eq:
+		if c, ok := order(actual, expected); ok {
+			return c == 0
+		}
+		return reflect.DeepEqual(actual, expected)
+	case StartsWith, Contains:
+		a, aok := actual.(string)
+		e, eok := expected.(string)
+		if !aok || !eok {
+			return false
+		}
+		if op == StartsWith {
+			return strings.HasPrefix(a, e)
+		}
+		return strings.Contains(a, e)
+	}
+
+	c, ok := order(actual, expected)
+	if !ok {
+		return false
+	}
+	switch op {
+	case Gt:
+		return c > 0
+	case Ge:
+		return c >= 0
+	case Lt:
+		return c < 0
+	case Le:
+		return c <= 0
+	}
+	return false
+}
+
+// order returns -1, 0 or 1 as `a` is before, equal to or after `b`, and false when they cannot be ordered
+func order(a, b interface{}) (int, bool) {
+	switch a := a.(type) {
+	case float64:
+		b, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		b, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		ta, aerr := time.Parse(time.RFC3339Nano, a)
+		tb, berr := time.Parse(time.RFC3339Nano, b)
+		if aerr == nil && berr == nil {
+			return ta.Compare(tb), true
+		}
+		return strings.Compare(a, b), true
+	case bool:
+		b, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a == b:
+			return 0, true
+		case b:
+			return -1, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+// document returns `v` decoded from its JSON encoding, so fields are resolved by their JSON names
+func document(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// lookup returns the value at the dotted `path` of a decoded document
+func lookup(doc interface{}, path string) (interface{}, bool) {
+	value := doc
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
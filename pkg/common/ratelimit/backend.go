@@ -0,0 +1,87 @@
+// pkg/common/ratelimit/backend.go
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/*
+ * # Backend
+ * Stores the windows of rate limiters shared by several processes, e.g. the workers of a sync which together must stay
+ * under an org-wide limit:
+ *
+ *	backend, err := ratelimit.NewRedisBackend("redis://:password@redis:6379/0")
+ *	rl := ratelimit.NewRateLimiter(600, time.Minute, ratelimit.Okta, backend, "okta:example")
+ *
+ * - Each window is named by the key of its rate limiters; the processes sharing a key share its quota
+ * - A rate limiter whose backend fails falls back to counting its own requests, so an outage slows nothing down
+ */
+type Backend interface {
+	// Take counts a request in the window of `key`, allowing `limit` requests per `interval`, and returns how long to wait
+	// before sending it; a request which must wait is not counted
+	Take(ctx context.Context, key string, limit int, interval time.Duration) (time.Duration, error)
+	// Update records the window of `key` as reported by the API: `used` requests of it were sent, until `reset` if known
+	Update(ctx context.Context, key string, used int, reset time.Time) error
+}
+
+// MemoryBackend is a `Backend` shared by the rate limiters of a single process, e.g. of several clients of one API
+type MemoryBackend struct {
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+// window is a count of requests, until its reset
+type window struct {
+	used  int
+	reset time.Time
+}
+
+// NewMemoryBackend returns an empty backend held in memory
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{windows: map[string]*window{}}
+}
+
+// Take counts a request in the window of `key`, starting a window of `interval` when there is none
+func (m *MemoryBackend) Take(ctx context.Context, key string, limit int, interval time.Duration) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w := m.current(key, interval)
+	if w.used >= limit {
+		return time.Until(w.reset), nil
+	}
+	w.used++
+	return 0, nil
+}
+
+// Update records the requests used in the window of `key`, which never decrease within a window
+func (m *MemoryBackend) Update(ctx context.Context, key string, used int, reset time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w, ok := m.windows[key]
+	if !ok || !time.Now().Before(w.reset) {
+		if reset.IsZero() {
+			return nil
+		}
+		w = &window{}
+		m.windows[key] = w
+	}
+	w.used = max(w.used, used)
+	if !reset.IsZero() {
+		w.reset = reset
+	}
+	return nil
+}
+
+// current returns the window of `key`, starting a new one once it reset
+func (m *MemoryBackend) current(key string, interval time.Duration) *window {
+	w, ok := m.windows[key]
+	if !ok || !time.Now().Before(w.reset) {
+		w = &window{reset: time.Now().Add(interval)}
+		m.windows[key] = w
+	}
+	return w
+}
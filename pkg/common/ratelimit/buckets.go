@@ -0,0 +1,150 @@
+// pkg/common/ratelimit/buckets.go
+package ratelimit
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+/*
+ * # Buckets
+ * Rate limiters of the endpoint families of an API, e.g. Okta's `/api/v1/users` and `/api/v1/logs`, or Google's Drive and
+ * Directory APIs, each with its own quota and reset:
+ *
+ *	buckets := ratelimit.NewBuckets(ratelimit.NewRateLimiter(600, time.Minute)).
+ *		Add("/api/v1/logs", ratelimit.NewRateLimiter(120, time.Minute)).
+ *		Add("GET /api/v1/users/*", ratelimit.NewRateLimiter(2000, time.Minute))
+ *
+ * - A pattern is a path, optionally preceded by a method; `*` matches any one segment
+ * - A pattern matches its path and the paths below it; the most specific pattern matching a request wins, then the first added
+ * - Requests matching no pattern share the default rate limiter, if any
+ */
+type Buckets struct {
+	Default *RateLimiter // Rate limiter of the requests matching no bucket; optional
+
+	mu      sync.RWMutex
+	buckets []*namedBucket
+}
+
+// namedBucket is a rate limiter of the requests matching a pattern
+type namedBucket struct {
+	pattern  string
+	method   string   // Method of the requests, or "" for any
+	segments []string // Segments of the path, `*` matching any
+	limiter  *RateLimiter
+}
+
+// NewBuckets returns buckets whose unmatched requests are limited by `fallback`, which may be nil
+func NewBuckets(fallback *RateLimiter) *Buckets {
+	return &Buckets{Default: fallback}
+}
+
+// Add limits the requests matching `pattern` with `limiter`, e.g. `GET /api/v1/users/*`; a pattern added again is replaced
+func (b *Buckets) Add(pattern string, limiter *RateLimiter) *Buckets {
+	method, path, found := strings.Cut(strings.TrimSpace(pattern), " ")
+	if !found {
+		method, path = "", method
+	}
+	bucket := &namedBucket{
+		pattern:  pattern,
+		method:   strings.ToUpper(method),
+		segments: splitPath(path),
+		limiter:  limiter,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, existing := range b.buckets {
+		if existing.pattern == pattern {
+			b.buckets[i] = bucket
+			return b
+		}
+	}
+	b.buckets = append(b.buckets, bucket)
+	return b
+}
+
+// For returns the pattern of the bucket of `r` and its rate limiter, or "" and the default rate limiter when none matches
+func (b *Buckets) For(r *http.Request) (string, *RateLimiter) {
+	segments := splitPath(r.URL.Path)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var best *namedBucket
+	for _, bucket := range b.buckets {
+		if bucket.matches(r.Method, segments) && (best == nil || bucket.moreSpecific(best)) {
+			best = bucket
+		}
+	}
+	if best == nil {
+		return "", b.Default
+	}
+	return best.pattern, best.limiter
+}
+
+// Limiter returns the rate limiter of `r`, or nil when none applies
+func (b *Buckets) Limiter(r *http.Request) *RateLimiter {
+	_, limiter := b.For(r)
+	return limiter
+}
+
+// Stop terminates the internal timers of every rate limiter of the buckets
+func (b *Buckets) Stop() {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, bucket := range b.buckets {
+		if bucket.limiter != nil {
+			bucket.limiter.Stop()
+		}
+	}
+	if b.Default != nil {
+		b.Default.Stop()
+	}
+}
+
+// matches reports whether the bucket's pattern matches a request of `method` to the path of `segments`
+func (nb *namedBucket) matches(method string, segments []string) bool {
+	if nb.method != "" && nb.method != method {
+		return false
+	}
+	if len(segments) < len(nb.segments) {
+		return false
+	}
+	for i, segment := range nb.segments {
+		if segment != "*" && segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// moreSpecific reports whether the bucket's pattern is more specific than `other`'s: longer, with fewer wildcards, or with a method
+func (nb *namedBucket) moreSpecific(other *namedBucket) bool {
+	if len(nb.segments) != len(other.segments) {
+		return len(nb.segments) > len(other.segments)
+	}
+	if wildcards, others := nb.wildcards(), other.wildcards(); wildcards != others {
+		return wildcards < others
+	}
+	return nb.method != "" && other.method == ""
+}
+
+func (nb *namedBucket) wildcards() int {
+	n := 0
+	for _, segment := range nb.segments {
+		if segment == "*" {
+			n++
+		}
+	}
+	return n
+}
+
+// splitPath returns the segments of a path, ignoring its leading and trailing slashes
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
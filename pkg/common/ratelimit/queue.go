@@ -0,0 +1,254 @@
+// pkg/common/ratelimit/queue.go
+package ratelimit
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/crypt"
+	"github.com/gemini-oss/rego/pkg/common/log"
+)
+
+// Queue holds requests back before they are sent, per rate limit bucket, so bursts degrade to slower throughput instead of `429`s
+// - Each bucket learns its limit from the `X-Rate-Limit-Limit`, `X-Rate-Limit-Remaining` and `X-Rate-Limit-Reset` headers
+// - Once half of a bucket's window is spent, its remaining requests are spread evenly until the window resets
+// - A bucket which is exhausted, or was answered with a `429`, holds its requests until the window resets
+type Queue struct {
+	Bucket      func(r *http.Request) string // Names the bucket of a request; every request shares one bucket when nil
+	Concurrency int                          // Requests in flight at once, across every bucket; unlimited when zero
+	Log         *log.Logger                  // Logger for the queue
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	slots   chan struct{}
+	once    sync.Once
+}
+
+// bucket is the state of a rate limit window, as last reported by the API
+type bucket struct {
+	limit     int       // Requests allowed in the window; unknown when zero
+	remaining int       // Requests remaining in the window, counting those sent since the last response
+	reset     time.Time // End of the window
+	next      time.Time // Earliest time the next request may be sent, when pacing
+	probing   bool      // A request is in flight to learn the limit, which the others wait for
+	seen      bool      // A response was received, whether or not it reported a limit
+}
+
+// probeWait is how often requests check whether the limit of their bucket was learned
+const probeWait = 25 * time.Millisecond
+
+// NewQueue returns a queue allowing `concurrency` requests in flight at once, bucketed by `bucket`
+func NewQueue(concurrency int, bucket func(r *http.Request) string) *Queue {
+	return &Queue{
+		Bucket:      bucket,
+		Concurrency: concurrency,
+		Log:         log.NewLogger("{ratelimit}", log.INFO),
+	}
+}
+
+// Client returns a copy of `hc` which sends its requests through the queue; a nil `hc` copies `http.DefaultClient`
+func (q *Queue) Client(hc *http.Client) *http.Client {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	queued := *hc
+	queued.Transport = &queueTransport{queue: q, next: hc.Transport}
+	return &queued
+}
+
+type queueTransport struct {
+	queue *Queue
+	next  http.RoundTripper
+}
+
+func (t *queueTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return t.queue.send(req, next)
+}
+
+// send waits for the bucket of the request and a free slot, then sends it and records the rate limit of the response
+func (q *Queue) send(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	name := ""
+	if q.Bucket != nil {
+		name = q.Bucket(req)
+	}
+	if err := q.wait(req, name); err != nil {
+		return nil, err
+	}
+
+	release, err := q.acquire(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		release()
+		q.update(name, nil)
+		return nil, err
+	}
+	q.update(name, resp)
+
+	// The slot is held until the body is read, since the API counts the request as in flight until then
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// wait blocks until the bucket may send another request, or the request is cancelled
+func (q *Queue) wait(req *http.Request, name string) error {
+	for {
+		delay := q.reserve(name)
+		if delay <= 0 {
+			return nil
+		}
+		q.logger().Tracef("Holding a request of %q for %v\n", name, delay)
+
+		start := time.Now()
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			observeWait("queue", start)
+			return req.Context().Err()
+		case <-timer.C:
+		}
+		observeWait("queue", start)
+	}
+}
+
+// reserve counts a request against its bucket, returning zero, or how long to wait before trying again
+func (q *Queue) reserve(name string) time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	b := q.bucket(name)
+	now := time.Now()
+	if b.limit <= 0 {
+		if !b.seen {
+			if b.probing {
+				return probeWait
+			}
+			b.probing = true
+		}
+		return 0
+	}
+	if !now.Before(b.reset) {
+		// The window reset; its full limit is assumed until a response reports otherwise, for another minute-long window
+		b.remaining = b.limit
+		b.reset = now.Add(time.Minute)
+		b.next = time.Time{}
+	}
+
+	if b.remaining <= 0 {
+		return b.reset.Sub(now) + jitter()
+	}
+	if b.remaining < b.limit/2 {
+		if now.Before(b.next) {
+			return b.next.Sub(now)
+		}
+		b.next = now.Add(b.reset.Sub(now) / time.Duration(b.remaining))
+	}
+	b.remaining--
+	return 0
+}
+
+// acquire takes a slot for a request in flight, returning the func which frees it
+func (q *Queue) acquire(req *http.Request) (func(), error) {
+	if q.Concurrency <= 0 {
+		return func() {}, nil
+	}
+	q.once.Do(func() { q.slots = make(chan struct{}, q.Concurrency) })
+
+	select {
+	case q.slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-q.slots }) }, nil
+}
+
+// update records the rate limit reported by a response; a nil response only ends the probe of the bucket
+// - The reset is reported in whole seconds, so the window is assumed to end with that second
+func (q *Queue) update(name string, resp *http.Response) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	b := q.bucket(name)
+	b.probing = false
+	if resp == nil {
+		return
+	}
+	b.seen = true
+	if limit, err := strconv.Atoi(resp.Header.Get("X-Rate-Limit-Limit")); err == nil {
+		b.limit = limit
+	}
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-Rate-Limit-Remaining")); err == nil {
+		b.remaining = remaining
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-Rate-Limit-Reset"), 10, 64); err == nil {
+		b.reset = time.Unix(reset+1, 0)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		b.remaining = 0
+		if retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			b.reset = time.Now().Add(time.Duration(retryAfter) * time.Second)
+		} else if !b.reset.After(time.Now()) {
+			b.reset = time.Now().Add(time.Minute)
+		}
+		if b.limit <= 0 {
+			b.limit = 1
+		}
+		q.logger().Warningf("Rate limited in %q; holding its requests until %s", name, b.reset.Format(time.RFC3339))
+	}
+}
+
+// bucket returns the state of a bucket, adding it if needed; the queue must be locked
+func (q *Queue) bucket(name string) *bucket {
+	if q.buckets == nil {
+		q.buckets = map[string]*bucket{}
+	}
+	b, ok := q.buckets[name]
+	if !ok {
+		b = &bucket{}
+		q.buckets[name] = b
+	}
+	return b
+}
+
+// queueLog logs for queues without a logger of their own
+var queueLog = log.NewLogger("{ratelimit}", log.INFO)
+
+func (q *Queue) logger() *log.Logger {
+	if q.Log == nil {
+		return queueLog
+	}
+	return q.Log
+}
+
+// jitter spreads the requests released at the end of a window over a second
+func jitter() time.Duration {
+	ms, err := crypt.SecureRandomInt(1000)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// releaseBody frees the slot of its request once it is closed
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -2,18 +2,20 @@
 package ratelimit
 
 import (
+	"context"
 	"net/http"
-	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gemini-oss/rego/pkg/common/crypt"
 	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/metrics"
 )
 
 // RateLimiter struct defines the fields for the rate limiter
 type RateLimiter struct {
 	stopChan       chan struct{} // Channel to stop the rate limiter
+	stopOnce       sync.Once     // Closes `stopChan` once, however many clients sharing the rate limiter stop it
 	mu             sync.Mutex    // Mutex to lock the rate limiter
 	Available      int           // Available requests remaining
 	Limit          int           // Total requests allowed in the interval
@@ -24,10 +26,16 @@ type RateLimiter struct {
 	RetryAfter     int           // Retry after time
 	TimeUntilReset time.Duration // Time until the rate limiter resets
 	UsesRetryAfter bool          // Flag to check if the rate limiter uses a retry after value
+	Profile        Profile       // How the API reports its rate limits; the common headers are recognized when zero
+	Backend        Backend       // Shares the window with the other processes using the same `Key`; counted locally when nil
+	Key            string        // Key of the window in `Backend`; the name of the `Profile`, or `default`, when empty
 	Log            *log.Logger   // Logger for the rate limiter
+
+	holdUntil  time.Time // Requests are held back until then, after a `Retry-After` or a rejection
+	rejections int       // Consecutive rejections, doubling the hold of each
 }
 
-// NewRateLimiter creates a new RateLimiter instance with the given parameters
+// NewRateLimiter creates a new RateLimiter instance with the given parameters: its limit (`int`), interval (`time.Duration`), `Profile`, `Backend` and key (`string`)
 func NewRateLimiter(args ...interface{}) *RateLimiter {
 	rl := &RateLimiter{
 		stopChan: make(chan struct{}),
@@ -41,6 +49,13 @@ func NewRateLimiter(args ...interface{}) *RateLimiter {
 			rl.Available = v
 		case time.Duration:
 			rl.Interval = v
+		case Profile:
+			rl.Profile = v
+			rl.ResetHeaders = v.ServerCounts
+		case Backend:
+			rl.Backend = v
+		case string:
+			rl.Key = v
 		default:
 			rl.Log.Warning("Unsupported argument type in NewRateLimiter")
 		}
@@ -54,13 +69,17 @@ func NewRateLimiter(args ...interface{}) *RateLimiter {
 // Start begins the rate limiter's internal timer
 func (rl *RateLimiter) Start() {
 	rl.Log.Debug("Starting Rate Limiter")
+	tickerInterval := rl.Interval
+	if tickerInterval == 0 {
+		tickerInterval = 1 * time.Minute
+	}
+	// The first window is set before returning, so it never overwrites one learned from the first response
+	rl.mu.Lock()
+	rl.ResetTimestamp = time.Now().Add(tickerInterval).Unix()
+	rl.mu.Unlock()
+
 	go func() {
-		tickerInterval := rl.Interval
-		if tickerInterval == 0 {
-			tickerInterval = 1 * time.Minute
-		}
 		ticker := time.NewTicker(tickerInterval)
-		rl.ResetTimestamp = time.Now().Add(tickerInterval).Unix()
 		defer ticker.Stop()
 
 		for {
@@ -82,9 +101,47 @@ func (rl *RateLimiter) Start() {
 
 // Throttle requests based on the remaining available rate limit.
 func (rl *RateLimiter) Wait() {
+	rl.WaitContext(context.Background())
+}
+
+// WaitContext throttles requests like `Wait`, but stops waiting once `ctx` is done, returning `ctx.Err()`
+func (rl *RateLimiter) WaitContext(ctx context.Context) error {
 	for {
 		rl.mu.Lock()
 
+		// Hold requests back while the API asked to, or rejected the previous ones.
+		if hold := time.Until(rl.holdUntil); hold > 0 {
+			rl.mu.Unlock()
+			if err := rl.performWait(ctx, hold); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Without a known limit, requests are only held back by the API.
+		if rl.Limit <= 0 {
+			rl.mu.Unlock()
+			return nil
+		}
+
+		// A shared window is counted by the backend, keeping the same headroom as a local one.
+		if rl.Backend != nil {
+			limit, interval, key := max(rl.Limit-rl.Limit/10, 1), rl.interval(), rl.key()
+			rl.mu.Unlock()
+			wait, err := rl.Backend.Take(ctx, key, limit, interval)
+			if err == nil {
+				if wait <= 0 {
+					return nil
+				}
+				if err := rl.performWait(ctx, wait); err != nil {
+					return err
+				}
+				continue
+			}
+			rl.Log.Warning("Rate limiter backend failed, counting requests locally: ", err)
+			rl.mu.Lock()
+		}
+
 		// Calculate the time until the next reset.
 		timeUntilReset := time.Until(time.Unix(rl.ResetTimestamp, 0))
 
@@ -92,14 +149,16 @@ func (rl *RateLimiter) Wait() {
 		if timeUntilReset <= 0 {
 			rl.resetAvailableLimit()
 			rl.mu.Unlock()
-			return
+			return nil
 		}
 
 		// Determine if a wait is needed based on the available requests.
 		if rl.shouldWait() {
 			waitDuration := rl.calculateWaitDuration(timeUntilReset)
 			rl.mu.Unlock()
-			rl.performWait(waitDuration)
+			if err := rl.performWait(ctx, waitDuration); err != nil {
+				return err
+			}
 			continue
 		}
 
@@ -108,10 +167,47 @@ func (rl *RateLimiter) Wait() {
 			rl.decrementAvailable()
 		}
 		rl.mu.Unlock()
-		return
+		return nil
 	}
 }
 
+// WaitHold waits while requests are held back after a `Retry-After` or a rejection, without counting a request against the limit
+func (rl *RateLimiter) WaitHold(ctx context.Context) error {
+	rl.mu.Lock()
+	hold := time.Until(rl.holdUntil)
+	rl.mu.Unlock()
+	if hold <= 0 {
+		return nil
+	}
+	return rl.performWait(ctx, hold)
+}
+
+// Window returns the limit, the requests available and the reset (Unix seconds) of the current window
+func (rl *RateLimiter) Window() (limit int, available int, reset int64) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.Limit, rl.Available, rl.ResetTimestamp
+}
+
+// Throttled returns how long `Wait` would currently pause, or zero if requests may proceed
+// - Unlike `Wait`, it does not count a request against the limit, so callers can hold back work before issuing requests
+func (rl *RateLimiter) Throttled() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if hold := time.Until(rl.holdUntil); hold > 0 {
+		return hold
+	}
+	if rl.Limit <= 0 {
+		return 0
+	}
+	timeUntilReset := time.Until(time.Unix(rl.ResetTimestamp, 0))
+	if timeUntilReset <= 0 || !rl.shouldWait() {
+		return 0
+	}
+	return rl.calculateWaitDuration(timeUntilReset)
+}
+
 // resetAvailableLimit resets the available requests and requests count.
 func (rl *RateLimiter) resetAvailableLimit() {
 	if rl.Available < rl.Limit {
@@ -147,10 +243,25 @@ func (rl *RateLimiter) calculateWaitDuration(timeUntilReset time.Duration) time.
 	return scaledWait + time.Duration(randomIncrement)*time.Millisecond
 }
 
-// performWait sleeps for the specified duration.
-func (rl *RateLimiter) performWait(duration time.Duration) {
+// performWait sleeps for the specified duration, or until `ctx` is done.
+func (rl *RateLimiter) performWait(ctx context.Context, duration time.Duration) error {
 	rl.Log.Tracef("Waiting for %v\n", duration)
-	time.Sleep(duration)
+	defer observeWait("ratelimiter", time.Now())
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// observeWait records a sleep of the `limiter` which started at `start`
+func observeWait(limiter string, start time.Time) {
+	label := metrics.Label{Name: "limiter", Value: limiter}
+	metrics.Add(metrics.RateLimitWaitsTotal, 1, label)
+	metrics.Observe(metrics.RateLimitWaitDuration, time.Since(start).Seconds(), label)
 }
 
 // decrementAvailable decrements the available requests and increments the request count.
@@ -160,59 +271,110 @@ func (rl *RateLimiter) decrementAvailable() {
 	rl.Log.Debug("Rate limiter updated: Limit=", rl.Limit, ", Available=", rl.Available)
 }
 
-// Stop terminates the rate limiter's internal timer
+// Stop terminates the rate limiter's internal timer; requests may still wait on the rate limiter, which resets on its own
 func (rl *RateLimiter) Stop() {
-	rl.Log.Debug("Stopping Rate Limiter")
-	close(rl.stopChan)
+	rl.stopOnce.Do(func() {
+		if rl.stopChan != nil {
+			close(rl.stopChan)
+		}
+	})
 }
 
+/*
+ * # Update from Headers
+ * Learns the window of the rate limit from the headers of a response, as declared by the rate limiter's `Profile`
+ * - A `Retry-After` holds the following requests back until it expires
+ */
 func (rl *RateLimiter) UpdateFromHeaders(headers http.Header) {
-	// Add a check for nil headers
 	if headers == nil {
-		return // or handle this case as needed
+		return
 	}
-
-	// Log the start of the update process.
 	rl.Log.Trace("Updating Rate Limiter from headers")
 
-	// Lock the RateLimiter to ensure thread-safe access to its fields.
 	rl.mu.Lock()
-	// Defer the unlocking so it's done automatically at the end of the function.
-	defer rl.mu.Unlock()
+	limit, remaining, reset := rl.Profile.window(headers)
+	if !reset.IsZero() {
+		rl.ResetTimestamp = reset.Unix()
+	}
+	if wait := RetryAfter(headers); wait > 0 {
+		rl.RetryAfter = int(wait.Seconds())
+		rl.hold(wait)
+	}
+	if limit >= 0 {
+		rl.Limit = limit
+		rl.Available = limit // Reset the available limit when the main limit changes.
+	}
+	if remaining >= 0 {
+		rl.Available = remaining
+	}
+	rl.Log.Debug("Rate limiter updated: Limit=", rl.Limit, ", Available=", rl.Available)
+	backend, key, used := rl.Backend, rl.key(), rl.Limit-remaining
+	rl.mu.Unlock()
 
-	// Try to get the "X-Rate-Limit-Reset" header.
-	if resetHeader := headers.Get("X-Rate-Limit-Reset"); resetHeader != "" {
-		// If the header is present and can be parsed to an int64, update the ResetTimestamp.
-		if reset, err := strconv.ParseInt(resetHeader, 10, 64); err == nil {
-			rl.ResetTimestamp = reset
+	// The window reported by the API accounts for the requests of every process
+	if backend != nil && remaining >= 0 && used >= 0 {
+		if err := backend.Update(context.Background(), key, used, reset); err != nil {
+			rl.Log.Warning("Rate limiter backend failed to record the window: ", err)
 		}
 	}
+}
 
-	// Try to get the "Retry-After" header.
-	if retryAfterHeader := headers.Get("Retry-After"); retryAfterHeader != "" {
-		// If the header is present and can be parsed to an int, update the RetryAfter.
-		if retryAfter, err := strconv.Atoi(retryAfterHeader); err == nil {
-			rl.RetryAfter = retryAfter
-		}
+// key returns the key of the rate limiter's window in its backend
+func (rl *RateLimiter) key() string {
+	switch {
+	case rl.Key != "":
+		return rl.Key
+	case rl.Profile.Name != "":
+		return rl.Profile.Name
+	default:
+		return "default"
 	}
+}
 
-	// Try to get the "X-Rate-Limit-Limit" header.
-	if limitHeader := headers.Get("X-Rate-Limit-Limit"); limitHeader != "" {
-		// If the header is present and can be parsed to an int, update the Limit and Available.
-		if limit, err := strconv.Atoi(limitHeader); err == nil {
-			rl.Limit = limit
-			rl.Available = limit // Reset the available limit when the main limit changes.
-		}
+// interval returns the length of the rate limiter's window, a minute when unset
+func (rl *RateLimiter) interval() time.Duration {
+	if rl.Interval <= 0 {
+		return time.Minute
+	}
+	return rl.Interval
+}
+
+/*
+ * # Update from an Error
+ * Records a response rejected by the rate limit of the API, a `429` or an error body holding a reason of the
+ * `Profile`'s quota errors (e.g. Google's `rateLimitExceeded`), holding the following requests back
+ * - The hold is the response's `Retry-After`, or else the profile's `QuotaBackoff`, doubled while rejections repeat
+ */
+func (rl *RateLimiter) UpdateFromError(status int, headers http.Header, body []byte) {
+	if !rl.Profile.rejected(status, body) {
+		return
 	}
 
-	// Try to get the "X-Rate-Limit-Remaining" header.
-	if remainingHeader := headers.Get("X-Rate-Limit-Remaining"); remainingHeader != "" {
-		// If the header is present and can be parsed to an int, update the Available.
-		if remaining, err := strconv.Atoi(remainingHeader); err == nil {
-			rl.Available = remaining
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	wait := RetryAfter(headers)
+	if wait <= 0 {
+		base := rl.Profile.QuotaBackoff
+		if base <= 0 {
+			base = DefaultQuotaBackoff
+		}
+		// A rejection long after the last hold starts over
+		if time.Since(rl.holdUntil) > base<<rl.rejections {
+			rl.rejections = 0
+		}
+		wait = min(base<<rl.rejections, MaxQuotaBackoff)
+		if wait < MaxQuotaBackoff {
+			rl.rejections++
 		}
 	}
+	rl.hold(wait)
+	rl.Log.Warningf("Rate limited (%d): holding requests for %v", status, wait)
+}
 
-	// Log the updated state of the Rate Limiter.
-	rl.Log.Debug("Rate limiter updated: Limit=", rl.Limit, ", Available=", rl.Available)
+// hold holds requests back for `wait`, unless they already are for longer
+func (rl *RateLimiter) hold(wait time.Duration) {
+	if until := time.Now().Add(wait); until.After(rl.holdUntil) {
+		rl.holdUntil = until
+	}
 }
@@ -0,0 +1,116 @@
+// pkg/common/ratelimit/redis.go
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/redis"
+)
+
+/*
+ * # Redis Backend
+ * A `Backend` stored in Redis, so every process connected to it shares the windows of its rate limiters
+ * - Windows are counters expiring at their reset, e.g. `rego:ratelimit:okta`; each request is counted atomically in a
+ *   `MULTI` transaction, and each window reported by an API is recorded by a script
+ */
+type RedisBackend struct {
+	Client *redis.Client // Connection to the server
+	Prefix string        // Prefix of the keys; `DefaultRedisPrefix` when empty
+}
+
+// DefaultRedisPrefix prefixes the keys of the windows in Redis
+const DefaultRedisPrefix = "rego:ratelimit:"
+
+// NewRedisBackend returns a backend stored in the server of `rawURL`, e.g. `redis://:password@localhost:6379/0`
+func NewRedisBackend(rawURL string) (*RedisBackend, error) {
+	client, err := redis.NewClient(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisBackend{Client: client}, nil
+}
+
+// Take counts a request in the window of `key`, starting a window of `interval` when there is none
+func (r *RedisBackend) Take(ctx context.Context, key string, limit int, interval time.Duration) (time.Duration, error) {
+	k := r.key(key)
+	replies, err := r.Client.Do(ctx,
+		[]string{"MULTI"},
+		[]string{"SET", k, "0", "PX", strconv.FormatInt(interval.Milliseconds(), 10), "NX"},
+		[]string{"INCR", k},
+		[]string{"PTTL", k},
+		[]string{"EXEC"},
+	)
+	if err != nil {
+		return 0, err
+	}
+	results, ok := replies[len(replies)-1].([]interface{})
+	if !ok || len(results) != 3 {
+		return 0, fmt.Errorf("redis: unexpected reply to EXEC: %v", replies[len(replies)-1])
+	}
+	used, _ := results[1].(int64)
+	ttl, _ := results[2].(int64)
+
+	// A window recorded by `Update` without its reset expires with the interval
+	if ttl < 0 {
+		ttl = interval.Milliseconds()
+		if _, err := r.Client.Do(ctx, []string{"PEXPIRE", k, strconv.FormatInt(ttl, 10)}); err != nil {
+			return 0, err
+		}
+	}
+	if used <= int64(limit) {
+		return 0, nil
+	}
+	if _, err := r.Client.Do(ctx, []string{"DECR", k}); err != nil {
+		return 0, err
+	}
+	return time.Duration(ttl) * time.Millisecond, nil
+}
+
+/*
+ * updateScript raises the window of KEYS[1] to ARGV[1] requests, and moves its reset to ARGV[2] (Unix milliseconds)
+ * - Without a reset (0), only an existing window is raised, keeping its expiry
+ * - Run as a script, so no request counted by another process between the read and the write is lost
+ */
+const updateScript = `
+local current = tonumber(redis.call('GET', KEYS[1]) or '-1')
+local used = tonumber(ARGV[1])
+local reset = tonumber(ARGV[2])
+if current < 0 and reset == 0 then
+	return 0
+end
+if used > current then
+	if reset == 0 then
+		redis.call('SET', KEYS[1], ARGV[1], 'KEEPTTL')
+	else
+		redis.call('SET', KEYS[1], ARGV[1], 'PXAT', ARGV[2])
+	end
+elseif reset ~= 0 then
+	redis.call('PEXPIREAT', KEYS[1], ARGV[2])
+end
+return 1
+`
+
+// Update records the requests used in the window of `key`, which never decrease within a window
+func (r *RedisBackend) Update(ctx context.Context, key string, used int, reset time.Time) error {
+	var resetAt int64
+	if !reset.IsZero() {
+		resetAt = reset.UnixMilli()
+	}
+	_, err := r.Client.Do(ctx, []string{"EVAL", updateScript, "1", r.key(key), strconv.Itoa(used), strconv.FormatInt(resetAt, 10)})
+	return err
+}
+
+// Close closes the connection to the server
+func (r *RedisBackend) Close() error {
+	return r.Client.Close()
+}
+
+func (r *RedisBackend) key(key string) string {
+	if r.Prefix == "" {
+		return DefaultRedisPrefix + key
+	}
+	return r.Prefix + key
+}
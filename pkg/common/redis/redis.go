@@ -0,0 +1,253 @@
+/*
+# Redis
+
+This package is a minimal Redis client, speaking RESP over a single connection, for the state rego shares between
+processes (rate limit windows, cached responses):
+
+```go
+
+	client, err := redis.NewClient("redis://:password@localhost:6379/0")
+	replies, err := client.Do(ctx, []string{"INCR", "key"}, []string{"PTTL", "key"})
+
+```
+
+Commands are pipelined; a `MULTI` ... `EXEC` pipeline runs them atomically.
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/redis/redis.go
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client is a connection to a Redis server, dialed as it is first used and redialed after a failure
+type Client struct {
+	Addr     string        // Address of the server, e.g. `localhost:6379`
+	Username string        // User of the ACL to authenticate as; the default user when empty
+	Password string        // Password to authenticate with; none when empty
+	DB       int           // Database of the keys
+	TLS      *tls.Config   // Connects with TLS when set
+	Timeout  time.Duration // Time each pipeline may take, unless its context has a deadline; 5 seconds when zero
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// Error is an error reply of the server, e.g. `WRONGTYPE ...`
+type Error string
+
+func (e Error) Error() string {
+	return "redis: " + string(e)
+}
+
+/*
+ * # New Client
+ * Returns a client of the server of `rawURL`, e.g. `redis://:password@localhost:6379/0`, connecting as it is first used
+ * - `rediss://` connects with TLS, verifying the server's certificate
+ */
+func NewClient(rawURL string) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("invalid Redis URL %q: want redis:// or rediss://", u.Redacted())
+	}
+
+	c := &Client{Addr: u.Host}
+	if u.Port() == "" {
+		c.Addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.Username = u.User.Username()
+		c.Password, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if c.DB, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid Redis database %q: %w", db, err)
+		}
+	}
+	if u.Scheme == "rediss" {
+		c.TLS = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	}
+	return c, nil
+}
+
+/*
+ * # Do
+ * Sends `commands` in a pipeline, and returns their replies: strings, integers (`int64`), arrays, nil, or `Error`s
+ * - An error reply is also returned as the error, after every reply was read
+ */
+func (c *Client) Do(ctx context.Context, commands ...[]string) ([]interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		if err := c.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+	replies, err := c.exchange(ctx, commands)
+	if err != nil {
+		// The connection is redialed for the next pipeline, since its replies may be out of step
+		var replyErr Error
+		if !errors.As(err, &replyErr) {
+			c.conn.Close()
+			c.conn, c.rd = nil, nil
+		}
+		return replies, err
+	}
+	return replies, nil
+}
+
+// Close closes the connection to the server
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.rd = nil, nil
+	return err
+}
+
+// connect dials the server, then authenticates and selects the database
+func (c *Client) connect(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: c.timeout()}
+	var conn net.Conn
+	var err error
+	if c.TLS != nil {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: c.TLS}).DialContext(ctx, "tcp", c.Addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", c.Addr)
+	}
+	if err != nil {
+		return fmt.Errorf("redis: connecting to %s: %w", c.Addr, err)
+	}
+	c.conn, c.rd = conn, bufio.NewReader(conn)
+
+	setup := [][]string{}
+	if c.Password != "" {
+		if c.Username != "" {
+			setup = append(setup, []string{"AUTH", c.Username, c.Password})
+		} else {
+			setup = append(setup, []string{"AUTH", c.Password})
+		}
+	}
+	if c.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.DB)})
+	}
+	if len(setup) == 0 {
+		return nil
+	}
+	if _, err := c.exchange(ctx, setup); err != nil {
+		conn.Close()
+		c.conn, c.rd = nil, nil
+		return err
+	}
+	return nil
+}
+
+// exchange writes `commands` and reads a reply to each
+func (c *Client) exchange(ctx context.Context, commands [][]string) ([]interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(c.timeout())
+	}
+	c.conn.SetDeadline(deadline)
+
+	var b strings.Builder
+	for _, command := range commands {
+		fmt.Fprintf(&b, "*%d\r\n", len(command))
+		for _, arg := range command {
+			fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+		}
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	replies := make([]interface{}, len(commands))
+	var replyErr error
+	for i := range commands {
+		reply, err := c.read()
+		if err != nil {
+			return nil, fmt.Errorf("redis: %w", err)
+		}
+		if e, ok := reply.(Error); ok && replyErr == nil {
+			replyErr = e
+		}
+		replies[i] = reply
+	}
+	return replies, replyErr
+}
+
+// read reads a reply
+func (c *Client) read() (interface{}, error) {
+	line, err := c.rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return Error(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.rd, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		elements := make([]interface{}, n)
+		for i := range elements {
+			if elements[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return elements, nil
+	default:
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+}
+
+func (c *Client) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 5 * time.Second
+	}
+	return c.Timeout
+}
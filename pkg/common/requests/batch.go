@@ -0,0 +1,77 @@
+// pkg/common/requests/batch.go
+package requests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gemini-oss/rego/pkg/common/pool"
+)
+
+// BatchRequest is a request of a batch, sent like `DoRequest`
+type BatchRequest struct {
+	Method string
+	URL    string
+	Query  interface{}
+	Data   interface{}
+}
+
+// BatchResult is the outcome of a request of a batch
+type BatchResult[T any] struct {
+	Index    int            // Position of the request in the batch
+	Request  BatchRequest   // The request
+	Response *http.Response // Response of the request; nil when it failed without one
+	Value    T              // The JSON body of the response, decoded; left zero by an empty body
+	Err      error          // Error of the request, or of decoding its body; the error which stopped the batch if it was never sent
+}
+
+/*
+ * # Batch
+ * Sends the requests of a batch on `opts.Workers` workers, e.g. to add many users to a group, returning the result of
+ * each in their order:
+ *
+ * ```go
+ *	results := requests.Batch[*User](c.HTTP, batch, pool.Options{Workers: 5})
+ *	if err := requests.BatchErr(results); err != nil { ... }
+ * ```
+ *
+ * - Each request is retried, rate limited and authorized like one of `DoRequest`
+ * - Workers hold back while the client's `RateLimiter` is throttling, unless `opts` sets another limiter
+ * - With `opts.StopOnError`, the first failure cancels the requests in flight, and those not yet sent
+ * - `T` may be `[]byte` for the bodies as they are
+ */
+func Batch[T any](c *Client, reqs []BatchRequest, opts pool.Options) []BatchResult[T] {
+	if opts.RateLimiter == nil {
+		opts.RateLimiter = c.RateLimiter
+	}
+
+	results := pool.Map(c.Context(), reqs, opts, func(ctx context.Context, req BatchRequest) (BatchResult[T], error) {
+		r := BatchResult[T]{Request: req}
+		resp, body, err := c.DoRequestContext(ctx, req.Method, req.URL, req.Query, req.Data)
+		r.Response, r.Err = resp, err
+		if err == nil {
+			r.Value, r.Err = decode[T](req.Method, req.URL, body)
+		}
+		return r, r.Err
+	})
+
+	batch := make([]BatchResult[T], len(results))
+	for i, result := range results {
+		batch[i] = result.Value
+		batch[i].Index, batch[i].Request, batch[i].Err = i, result.Item, result.Err
+	}
+	return batch
+}
+
+// BatchErr returns the errors of the failed requests of a batch, each with its request, or nil when none failed
+func BatchErr[T any](results []BatchResult[T]) error {
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("request %d (%s %s): %w", r.Index, r.Request.Method, r.Request.URL, r.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
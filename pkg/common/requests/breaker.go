@@ -0,0 +1,34 @@
+// pkg/common/requests/breaker.go
+package requests
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gemini-oss/rego/pkg/common/breaker"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+)
+
+/*
+ * # With Circuit Breaker
+ * Consults `b` before each request, keyed by host, so a host which keeps failing is left alone until it recovers
+ * - Network failures and the statuses of `DefaultRetryStatuses` (server errors, timeouts and `429`s) count against a host
+ * - A request rejected by an open circuit fails with an error matching `breaker.ErrOpen`, and is not retried
+ * - `b` may be shared between clients, e.g. those of an orchestrator calling the same API
+ */
+func WithCircuitBreaker(b *breaker.Breaker) Option {
+	return func(c *Client) {
+		c.Breaker = b
+	}
+}
+
+// outcome returns the error a response (or its absence) reports to the circuit breaker; nil when the host served it
+func outcome(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	if slices.Contains(DefaultRetryStatuses, resp.StatusCode) {
+		return rerrors.NewAPIError("", resp.StatusCode, "", resp.Status)
+	}
+	return nil
+}
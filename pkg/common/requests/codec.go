@@ -0,0 +1,70 @@
+// pkg/common/requests/codec.go
+package requests
+
+import (
+	"encoding/json"
+	"io"
+	"sync/atomic"
+)
+
+/*
+ * # JSON Codec
+ * Encodes the JSON payloads of requests and decodes the JSON of responses, so a faster implementation (e.g. go-json or
+ * sonic) can replace `encoding/json` during full-tenant syncs, where decoding dominates CPU
+ * - Implementations must accept the same `json` struct tags, and honor `json.Marshaler` and `encoding.TextUnmarshaler`
+ *
+ * ```go
+ *
+ *	type goJSON struct{}
+ *
+ *	func (goJSON) Marshal(v interface{}) ([]byte, error)      { return gojson.Marshal(v) }
+ *	func (goJSON) Unmarshal(data []byte, v interface{}) error { return gojson.Unmarshal(data, v) }
+ *	func (goJSON) NewDecoder(r io.Reader) requests.Decoder    { return gojson.NewDecoder(r) }
+ *
+ *	requests.SetCodec(goJSON{})
+ *
+ * ```
+ */
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	NewDecoder(r io.Reader) Decoder
+}
+
+// Decoder reads JSON values from a stream, like `json.Decoder`; decoders which also read tokens stream arrays element by element
+type Decoder interface {
+	Decode(v interface{}) error
+	More() bool
+}
+
+// tokenDecoder is a decoder which reads tokens, like `json.Decoder`
+type tokenDecoder interface {
+	Decoder
+	Token() (json.Token, error)
+}
+
+// StdCodec is the codec of `encoding/json`, used unless `SetCodec` sets another
+type StdCodec struct{}
+
+func (StdCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (StdCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (StdCodec) NewDecoder(r io.Reader) Decoder             { return json.NewDecoder(r) }
+
+var codec atomic.Pointer[Codec]
+
+// SetCodec sets the codec of every client, e.g. at startup; a nil codec restores `StdCodec`
+func SetCodec(c Codec) {
+	if c == nil {
+		codec.Store(nil)
+		return
+	}
+	codec.Store(&c)
+}
+
+// JSONCodec returns the codec set with `SetCodec`
+func JSONCodec() Codec {
+	if c := codec.Load(); c != nil {
+		return *c
+	}
+	return StdCodec{}
+}
@@ -0,0 +1,169 @@
+// pkg/common/requests/conditional.go
+package requests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NotModifiedHeader is set on responses rebuilt from the cache after the API answered `304 Not Modified`
+const NotModifiedHeader = "X-Rego-Not-Modified"
+
+const (
+	conditionalTTL     = 24 * time.Hour // How long a validated response is kept for revalidation, from its last `304`
+	maxConditionalBody = 8 << 20        // Bodies larger than this are not cached, so huge exports are still streamed
+)
+
+// WithConditionalRequests caches the GET responses carrying an `ETag` or `Last-Modified`, and revalidates them with
+// `If-None-Match` or `If-Modified-Since`, so an unchanged resource costs a `304` instead of its full body
+// - Each `304` keeps the cached response for another day, with the validators the API sent with it
+func WithConditionalRequests() Option {
+	return func(client *Client) {
+		client.Conditional = true
+	}
+}
+
+// validated is a response cached with the validators it was served with
+type validated struct {
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// WithSubject names the subject of the client's credentials, e.g. `okta:example` or the user a service account
+// impersonates, so the responses shared by `WithConditionalRequests` and `WithSingleflight` are scoped to it rather than
+// to a bearer token which changes with each renewal
+func WithSubject(subject string) Option {
+	return func(c *Client) {
+		c.Subject = subject
+	}
+}
+
+// credentials identifies the subject of a request's credentials: the `Subject` of the client, or its static
+// `Authorization` header
+func (c *Client) credentials(authorization string) string {
+	if c.Subject != "" {
+		authorization = "subject " + c.Subject
+	}
+	sum := sha256.Sum256([]byte(authorization))
+	return hex.EncodeToString(sum[:8])
+}
+
+// conditionalKey scopes the cached response of a request to its credentials, so clients of other subjects never share it
+func (c *Client) conditionalKey(req *http.Request) string {
+	return "conditional:" + c.credentials(req.Header.Get("Authorization")) + ":" + req.URL.String()
+}
+
+// revalidate sets the validators of the cached response of a GET request, returning it, or nil if there is none
+func (c *Client) revalidate(req *http.Request) *validated {
+	if !c.Conditional || req.Method != http.MethodGet || c.Cache == nil {
+		return nil
+	}
+	data, found := c.Cache.Get(c.conditionalKey(req))
+	if !found {
+		return nil
+	}
+	cached := &validated{}
+	if err := json.Unmarshal(data, cached); err != nil || cached.URL != req.URL.String() {
+		return nil
+	}
+
+	etag, modified := cached.Header.Get("ETag"), cached.Header.Get("Last-Modified")
+	if etag == "" && modified == "" {
+		return nil
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if modified != "" {
+		req.Header.Set("If-Modified-Since", modified)
+	}
+	return cached
+}
+
+// notModified rebuilds the response of a `304` from the cache, with the headers of the `304` taking precedence, and
+// caches it again so it is kept as long as the API confirms it
+func (c *Client) notModified(req *http.Request, resp *http.Response, cached *validated) *http.Response {
+	header := cached.Header.Clone()
+	for key, values := range resp.Header {
+		header[key] = values
+	}
+
+	refreshed := validated{URL: cached.URL, Header: header.Clone(), Body: cached.Body}
+	if data, err := json.Marshal(refreshed); err == nil {
+		if err := c.Cache.Set(c.conditionalKey(req), data, conditionalTTL); err != nil {
+			c.Log.Debug("Caching revalidated response:", err)
+		}
+	}
+	header.Set(NotModifiedHeader, "true")
+
+	resp.Body.Close()
+	resp.Status = "200 OK"
+	resp.StatusCode = http.StatusOK
+	resp.Header = header
+	resp.Body = io.NopCloser(bytes.NewReader(cached.Body))
+	resp.ContentLength = int64(len(cached.Body))
+	return resp
+}
+
+// validate wraps the body of a successful GET response, caching it once it is read in full if it carries validators
+func (c *Client) validate(req *http.Request, resp *http.Response) {
+	if !c.Conditional || req.Method != http.MethodGet || c.Cache == nil {
+		return
+	}
+	if resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" {
+		return
+	}
+	if resp.ContentLength > maxConditionalBody || strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return
+	}
+	resp.Body = &validatingBody{ReadCloser: resp.Body, client: c, key: c.conditionalKey(req), url: req.URL.String(), header: resp.Header}
+}
+
+// validatingBody copies what is read of a body, caching it when it is closed after being read in full
+type validatingBody struct {
+	io.ReadCloser
+	client   *Client
+	key      string
+	url      string
+	header   http.Header
+	buffer   bytes.Buffer
+	complete bool
+	overflow bool
+}
+
+func (b *validatingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if !b.overflow {
+		b.buffer.Write(p[:n])
+		b.overflow = b.buffer.Len() > maxConditionalBody
+	}
+	if err == io.EOF {
+		b.complete = true
+	}
+	return n, err
+}
+
+func (b *validatingBody) Close() error {
+	// Decoders stop after the value, before the end of the body; what little remains is read to cache the body whole
+	if !b.complete && !b.overflow {
+		remaining, err := io.ReadAll(io.LimitReader(b.ReadCloser, int64(maxConditionalBody-b.buffer.Len()+1)))
+		b.buffer.Write(remaining)
+		b.complete = err == nil && b.buffer.Len() <= maxConditionalBody
+	}
+	if b.complete && !b.overflow {
+		data, err := json.Marshal(validated{URL: b.url, Header: b.header, Body: b.buffer.Bytes()})
+		if err == nil {
+			if err := b.client.Cache.Set(b.key, data, conditionalTTL); err != nil {
+				b.client.Log.Debug("Caching validated response:", err)
+			}
+		}
+	}
+	return b.ReadCloser.Close()
+}
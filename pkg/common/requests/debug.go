@@ -0,0 +1,38 @@
+// pkg/common/requests/debug.go
+package requests
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+)
+
+/*
+ * Logs a request sent at `sent`, and the headers of its response, at DEBUG, as its body is logged by the callers
+ * - The entries are structured, so a JSON logger (see `REGO_LOG_FORMAT`) writes their service, method, endpoint, status
+ *   and duration (in nanoseconds) as fields; the status is `error` when no response was received
+ * - Credentials are masked by the redactor of the logs (see `log.SetRedactor`), e.g. the `Authorization` header, Okta's
+ *   `SSWS` tokens and the fields of `log.DefaultRedactedFields` in the query
+ */
+func (c *Client) debug(req *http.Request, resp *http.Response, err error, sent time.Time) {
+	if c.Log == nil || !c.Log.Enabled(log.DEBUG) {
+		return
+	}
+
+	attrs := []slog.Attr{}
+	if c.UserAgent != "" {
+		attrs = append(attrs, slog.String("service", c.UserAgent))
+	}
+	attrs = append(attrs, slog.String("method", req.Method), slog.String("endpoint", req.URL.Path))
+	c.Log.LogAttrs(log.DEBUG, "Request: "+req.Method+" "+req.URL.String(), append(attrs, slog.Any("headers", log.RedactHeader(req.Header)))...)
+
+	attrs = append(attrs, slog.Duration("duration", time.Since(sent)))
+	if resp == nil {
+		c.Log.LogAttrs(log.DEBUG, "Response: error", append(attrs, slog.String("status", "error"), slog.Any("error", err))...)
+		return
+	}
+	c.Log.LogAttrs(log.DEBUG, "Response: "+strconv.Itoa(resp.StatusCode), append(attrs, slog.Int("status", resp.StatusCode), slog.Any("headers", log.RedactHeader(resp.Header)))...)
+}
@@ -0,0 +1,48 @@
+// pkg/common/requests/do.go
+package requests
+
+import (
+	"context"
+	"fmt"
+)
+
+/*
+ * # Do
+ * Sends a request like `DoRequest`, returning its JSON body decoded into `T`, for the APIs rego has no package for:
+ *
+ * ```go
+ *	c := requests.NewClient(nil, headers, nil)
+ *	user, err := requests.Do[*User](c, "GET", "https://api.example.com/users/1", nil, nil)
+ * ```
+ *
+ * - The errors are the ones of `DoRequest`, e.g. `errors.Is(err, errors.ErrNotFound)`, or of decoding the body
+ * - An empty body leaves `T` zero; `T` may be `[]byte` for the body as it is
+ */
+func Do[T any](c *Client, method string, url string, query interface{}, data interface{}, opts ...CallOption) (T, error) {
+	_, body, err := c.DoRequest(method, url, query, data, opts...)
+	if err != nil {
+		return *new(T), err
+	}
+	return decode[T](method, url, body)
+}
+
+// DoContext sends a request like `Do`, canceled with `ctx`
+func DoContext[T any](ctx context.Context, c *Client, method string, url string, query interface{}, data interface{}, opts ...CallOption) (T, error) {
+	return Do[T](c.WithContext(ctx), method, url, query, data, opts...)
+}
+
+// decode returns the body of a response decoded into `T`, or as it is for `[]byte`
+func decode[T any](method, url string, body []byte) (T, error) {
+	var v T
+	if len(body) == 0 {
+		return v, nil
+	}
+	if raw, ok := any(&v).(*[]byte); ok {
+		*raw = body
+		return v, nil
+	}
+	if err := JSONCodec().Unmarshal(body, &v); err != nil {
+		return *new(T), fmt.Errorf("unmarshalling response of %s %s: %w", method, url, err)
+	}
+	return v, nil
+}
@@ -2,6 +2,7 @@
 package requests
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -26,7 +27,16 @@ type DownloadMetadata struct {
 	Checksum      string    // For checksum validation (optional)
 }
 
-func (c *Client) DownloadFile(url, directory, filename string, allowDuplicates bool) error {
+// DownloadFile downloads `url` to `directory`, resuming a partial download; the download is bounded by the client's `Timeouts.Download`, unless `opts` set its own
+func (c *Client) DownloadFile(url, directory, filename string, allowDuplicates bool, opts ...CallOption) error {
+	done, err := c.begin()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	c, release := c.bindDownload(opts)
+	defer release()
 
 	cacheKey := "download_meta_" + filename
 
@@ -50,7 +60,7 @@ func (c *Client) DownloadFile(url, directory, filename string, allowDuplicates b
 	// Use a HEAD request to fetch headers for filename extraction
 	// https://developer.mozilla.org/en-US/docs/web/http/methods/head
 	req, _ := c.CreateRequest("HEAD", url)
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.roundTrip(req)
 	if err != nil {
 		return fmt.Errorf("error performing HEAD request: %w", err)
 	}
@@ -106,7 +116,7 @@ func (c *Client) DownloadFile(url, directory, filename string, allowDuplicates b
 		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", bytesReceived))
 	}
 
-	resp, err = c.httpClient.Do(req)
+	resp, err = c.roundTrip(req)
 	if err != nil {
 		return fmt.Errorf("error performing request: %w", err)
 	}
@@ -151,6 +161,31 @@ func (c *Client) DownloadFile(url, directory, filename string, allowDuplicates b
 	return nil
 }
 
+/*
+ * # Open a Download
+ * Performs a `GET` with the client's headers, and returns the response so its body can be streamed, e.g. into object storage
+ * - The caller must close the body
+ */
+func (c *Client) OpenDownload(ctx context.Context, url string) (*http.Response, error) {
+	req, err := c.CreateRequest("GET", url)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.roundTrip(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("error performing request: %w", err)
+	}
+	if limiter := c.rateLimiterOf(req); limiter != nil {
+		limiter.UpdateFromHeaders(resp.Header)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("error downloading %s: %s", url, resp.Status)
+	}
+	return resp, nil
+}
+
 func findLatestDownload(directory, filename string) (string, int64, error) {
 	baseName := strings.TrimSuffix(filename, filepath.Ext(filename))
 	extension := filepath.Ext(filename)
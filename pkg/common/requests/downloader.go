@@ -0,0 +1,466 @@
+// pkg/common/requests/downloader.go
+package requests
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+// ErrChecksumMismatch is returned by a download whose content does not match its checksum
+var ErrChecksumMismatch = errors.New("requests: checksum mismatch")
+
+/*
+ * # Download
+ * A streamed download, e.g. of a Backupify export or a Zoom recording, sent with `Client.Download` or `Client.DownloadToFile`
+ * - The content is written as it arrives, so downloads of any size are never held in memory
+ * - A download interrupted by a transient failure is resumed where it stopped with a `Range` request, per the client's
+ *   retry policy; when a server ignores the range and resends the whole content, a file is rewritten from its first
+ *   byte, and a writer is only resumed if the bytes it holds match the content resent
+ * - The checksum is verified once the content is complete, from `Checksum` or else the response's `Repr-Digest`,
+ *   `Digest`, `X-Goog-Hash` or `Content-MD5` header
+ */
+type Download struct {
+	URL      string         // URL of the content
+	Offset   int64          // Bytes of the content the destination already holds, which are requested no more
+	Checksum string         // Expected checksum of the whole content, as `algorithm:hex` with `sha256`, `sha1` or `md5`
+	Progress func(Progress) // Called as the content is written, at most once per MiB, and once it is complete
+}
+
+// Progress is the progress of a download
+type Progress struct {
+	Received int64         // Bytes of the content the destination holds, including the `Offset`
+	Total    int64         // Size of the content, or -1 when unknown
+	Elapsed  time.Duration // Time since the download started
+}
+
+// Percent returns the percentage of the content received, or -1 when its size is unknown
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return -1
+	}
+	return 100 * float64(p.Received) / float64(p.Total)
+}
+
+// DownloadResult describes a completed download
+type DownloadResult struct {
+	Written  int64  // Bytes written by the download, excluding the `Offset` unless the file was rewritten from its first byte
+	Size     int64  // Size of the content, or -1 when unknown
+	Checksum string // Checksum the content was verified with, as `algorithm:hex`; empty when none was available
+}
+
+// progressEvery is how many bytes are written between calls of `Download.Progress`
+const progressEvery = 1 << 20
+
+/*
+ * # Download to a Writer
+ * Streams the content of `d.URL` to `w`, bounded by the client's `Timeouts.Download` unless `opts` set its own
+ * - When resuming from `d.Offset`, the bytes `w` already holds cannot be read back, so the checksum is not verified, and
+ *   a server which ignores the range fails the download rather than append its content to a different prefix
+ */
+func (c *Client) Download(d Download, w io.Writer, opts ...CallOption) (*DownloadResult, error) {
+	return c.download(d, w, nil, nil, opts)
+}
+
+/*
+ * # Download to a File
+ * Streams the content of `d.URL` to the file at `path`, creating it and its directory, and resuming from its size when
+ * it already holds part of the content
+ * - The part already downloaded is read back to verify the checksum of the whole file
+ * - A server which ignores the range resends the whole content, which replaces the part already downloaded
+ * - A file which does not match its checksum is removed, so the next download starts over
+ */
+func (c *Client) DownloadToFile(d Download, path string, opts ...CallOption) (*DownloadResult, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	d.Offset = offset
+
+	existing := func(w io.Writer) error {
+		part, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer part.Close()
+		_, err = io.CopyN(w, part, offset)
+		return err
+	}
+	restart := func() error {
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		_, err := f.Seek(0, io.SeekStart)
+		return err
+	}
+	result, err := c.download(d, f, existing, restart, opts)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		return nil, closeErr
+	}
+	if errors.Is(err, ErrChecksumMismatch) {
+		os.Remove(path)
+	}
+	return result, err
+}
+
+// bindDownload binds a copy of the client to a download, bounded as a whole by the client's `Timeouts.Download` or `opts`
+func (c *Client) bindDownload(opts []CallOption) (*Client, context.CancelFunc) {
+	c, release := c.bind(resolve(c.Timeouts.Download, DefaultTimeouts.Download), opts)
+	if c.timeout <= 0 {
+		return c, release
+	}
+	ctx, cancel := context.WithTimeoutCause(c.Context(), c.timeout, ErrTimeout)
+	return c.WithContext(ctx), func() {
+		cancel()
+		release()
+	}
+}
+
+/*
+ * Sends `d`, writing its content to `w`
+ * - `existing` writes the `d.Offset` bytes `w` already holds, when they can be read back
+ * - `restart` empties `w`, when it can be rewritten from the first byte of the content
+ */
+func (c *Client) download(d Download, w io.Writer, existing func(w io.Writer) error, restart func() error, opts []CallOption) (*DownloadResult, error) {
+	done, err := c.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	c, release := c.bindDownload(opts)
+	defer release()
+
+	dl := &downloading{client: c, d: d, w: w, existing: existing, restart: restart, received: d.Offset, size: -1, started: time.Now()}
+	if restart == nil {
+		dl.written = sha256.New()
+	}
+	if err := c.logAttempts(c.retryPolicy(http.MethodGet), http.MethodGet, d.URL).Retry(c.Context(), dl.attempt, retry.RealTime{}); err != nil {
+		return nil, err
+	}
+	return dl.verify()
+}
+
+// downloading is the state of a download, across its attempts
+type downloading struct {
+	client   *Client
+	d        Download
+	w        io.Writer
+	existing func(w io.Writer) error
+	restart  func() error
+	written  hash.Hash // SHA-256 of the bytes written to a writer which cannot be restarted, to compare with a resent content
+
+	received int64 // Bytes the destination holds, including the offset
+	size     int64 // Size of the content, or -1 when unknown
+	started  time.Time
+	reported int64 // Bytes received at the last progress report
+
+	checked   bool      // The checksum was chosen, on the first response
+	algorithm string    // Algorithm of the checksum, e.g. `sha256`
+	expected  []byte    // Expected checksum, nil when none is verified
+	hash      hash.Hash // Hash of the content from its first byte, nil once it cannot be verified
+	hashed    int64     // Bytes of the content hashed
+}
+
+// attempt requests the content from the bytes received so far, and writes it until it is complete
+func (dl *downloading) attempt() error {
+	c := dl.client
+	req, err := c.CreateRequest(http.MethodGet, dl.d.URL)
+	if err != nil {
+		return retry.Permanent(err)
+	}
+	if dl.received > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", dl.received))
+	}
+
+	resp, err := c.roundTrip(req)
+	if err != nil {
+		return c.timedOut(req, err)
+	}
+	defer resp.Body.Close()
+	if limiter := c.rateLimiterOf(req); limiter != nil {
+		limiter.UpdateFromHeaders(resp.Header)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if resp.ContentLength >= 0 {
+			dl.size = resp.ContentLength
+		}
+		// The range was ignored, so the whole content is resent
+		if dl.received > 0 {
+			if err := dl.resent(resp.Body); err != nil {
+				return err
+			}
+		}
+	case http.StatusPartialContent:
+		start, total, ok := contentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != dl.received {
+			return retry.Permanent(fmt.Errorf("downloading %s: unexpected Content-Range %q from byte %d", dl.d.URL, resp.Header.Get("Content-Range"), dl.received))
+		}
+		dl.size = total
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The destination already holds the whole content
+		if _, total, ok := contentRange(resp.Header.Get("Content-Range")); ok && total == dl.received {
+			dl.size = total
+			dl.checksum(resp)
+			dl.report(true)
+			return nil
+		}
+		fallthrough
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return rerrors.NewAPIError("", resp.StatusCode, "", string(body))
+	}
+	if err := dl.checksum(resp); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if _, werr := dl.w.Write(buf[:n]); werr != nil {
+				return retry.Permanent(fmt.Errorf("writing download: %w", werr))
+			}
+			dl.hashWriter().Write(buf[:n])
+			if dl.written != nil {
+				dl.written.Write(buf[:n])
+			}
+			dl.received += int64(n)
+			dl.report(false)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return c.timedOut(req, err)
+		}
+	}
+	if dl.size >= 0 && dl.received < dl.size {
+		return io.ErrUnexpectedEOF
+	}
+	dl.report(true)
+	return nil
+}
+
+/*
+ * Handles a response resending the whole content, after the range of the bytes received was ignored
+ * - A destination which can be restarted is emptied, and the content is written again from its first byte
+ * - Otherwise the bytes written by earlier attempts are read from the body, and must match; the bytes held before the
+ *   download started cannot be compared, so the download fails
+ */
+func (dl *downloading) resent(body io.Reader) error {
+	if dl.restart != nil {
+		if err := dl.restart(); err != nil {
+			return retry.Permanent(fmt.Errorf("restarting the download: %w", err))
+		}
+		dl.d.Offset, dl.received, dl.reported = 0, 0, 0
+		if dl.expected != nil {
+			dl.hash, dl.hashed = newHash(dl.algorithm), 0
+		}
+		return nil
+	}
+	if dl.d.Offset > 0 {
+		return retry.Permanent(fmt.Errorf("downloading %s: the range was ignored, and the %d bytes the writer held cannot be compared with the content", dl.d.URL, dl.d.Offset))
+	}
+
+	resent := sha256.New()
+	if _, err := io.CopyN(resent, body, dl.received); err != nil {
+		return err
+	}
+	if !bytes.Equal(resent.Sum(nil), dl.written.Sum(nil)) {
+		return retry.Permanent(fmt.Errorf("downloading %s: the content changed between attempts", dl.d.URL))
+	}
+	return nil
+}
+
+// checksum chooses the checksum of the content on the first response, and starts hashing it when it can be verified
+func (dl *downloading) checksum(resp *http.Response) error {
+	if dl.checked {
+		return nil
+	}
+	dl.checked = true
+
+	var err error
+	if dl.d.Checksum != "" {
+		dl.algorithm, dl.expected, err = parseChecksum(dl.d.Checksum)
+		if err != nil {
+			return retry.Permanent(err)
+		}
+	} else {
+		dl.algorithm, dl.expected = digestOf(resp)
+	}
+	if dl.expected == nil {
+		return nil
+	}
+
+	dl.hash = newHash(dl.algorithm)
+	// Resuming, the bytes the destination holds are hashed first, if they can be read back
+	if dl.d.Offset > 0 && resp.StatusCode != http.StatusOK {
+		if dl.existing == nil {
+			dl.hash = nil
+			return nil
+		}
+		if err := dl.existing(dl.hash); err != nil {
+			return retry.Permanent(fmt.Errorf("reading the partial download: %w", err))
+		}
+		dl.hashed = dl.d.Offset
+	}
+	return nil
+}
+
+// hashWriter returns the writer hashing the content, counting the bytes hashed
+func (dl *downloading) hashWriter() io.Writer {
+	if dl.hash == nil {
+		return io.Discard
+	}
+	return writerFunc(func(p []byte) (int, error) {
+		dl.hashed += int64(len(p))
+		return dl.hash.Write(p)
+	})
+}
+
+// report calls `Download.Progress` once a MiB was written since the last call, or once the download is complete
+func (dl *downloading) report(complete bool) {
+	if dl.d.Progress == nil || (!complete && dl.received-dl.reported < progressEvery) {
+		return
+	}
+	dl.reported = dl.received
+	dl.d.Progress(Progress{Received: dl.received, Total: dl.size, Elapsed: time.Since(dl.started)})
+}
+
+// verify compares the checksum of the content, once it was hashed in full
+func (dl *downloading) verify() (*DownloadResult, error) {
+	result := &DownloadResult{Written: dl.received - dl.d.Offset, Size: dl.size}
+	if dl.hash == nil || dl.hashed != dl.received {
+		return result, nil
+	}
+	sum := dl.hash.Sum(nil)
+	if string(sum) != string(dl.expected) {
+		return nil, fmt.Errorf("%w: %s of %s is %x, want %x", ErrChecksumMismatch, dl.algorithm, dl.d.URL, sum, dl.expected)
+	}
+	result.Checksum = dl.algorithm + ":" + hex.EncodeToString(sum)
+	return result, nil
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+func newHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case "sha256":
+		return sha256.New()
+	case "sha1":
+		return sha1.New()
+	default:
+		return md5.New()
+	}
+}
+
+// parseChecksum parses an `algorithm:hex` checksum
+func parseChecksum(checksum string) (string, []byte, error) {
+	algorithm, digest, ok := strings.Cut(checksum, ":")
+	algorithm = strings.ToLower(strings.ReplaceAll(algorithm, "-", ""))
+	if !ok || (algorithm != "sha256" && algorithm != "sha1" && algorithm != "md5") {
+		return "", nil, fmt.Errorf("invalid checksum %q: want sha256, sha1 or md5 as `algorithm:hex`", checksum)
+	}
+	sum, err := hex.DecodeString(digest)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid checksum %q: %w", checksum, err)
+	}
+	return algorithm, sum, nil
+}
+
+/*
+ * Returns the strongest checksum of the content in the headers of `resp`, if any
+ * - `Repr-Digest` (RFC 9530), `Digest` (RFC 3230) and Google Cloud Storage's `X-Goog-Hash` describe the whole content
+ * - `Content-MD5` only describes the body, so it is used for a response with the whole content
+ */
+func digestOf(resp *http.Response) (string, []byte) {
+	digests := map[string][]byte{}
+	for _, header := range []string{"Repr-Digest", "Digest", "X-Goog-Hash"} {
+		for _, value := range resp.Header.Values(header) {
+			for _, entry := range strings.Split(value, ",") {
+				name, encoded, ok := strings.Cut(strings.TrimSpace(entry), "=")
+				if !ok {
+					continue
+				}
+				name = strings.ToLower(strings.ReplaceAll(name, "-", ""))
+				if sum, err := base64.StdEncoding.DecodeString(strings.Trim(encoded, ":")); err == nil && digests[name] == nil {
+					digests[name] = sum
+				}
+			}
+		}
+	}
+	if md5sum := resp.Header.Get("Content-MD5"); md5sum != "" && resp.StatusCode == http.StatusOK && digests["md5"] == nil {
+		if sum, err := base64.StdEncoding.DecodeString(md5sum); err == nil {
+			digests["md5"] = sum
+		}
+	}
+	for _, algorithm := range []string{"sha256", "sha1", "md5"} {
+		if sum, ok := digests[algorithm]; ok {
+			return algorithm, sum
+		}
+	}
+	return "", nil
+}
+
+// contentRange parses the first byte and the size of a `Content-Range`, e.g. `bytes 100-199/200` or `bytes */200`; the size is -1 when unknown
+func contentRange(value string) (start int64, total int64, ok bool) {
+	spec, found := strings.CutPrefix(value, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	byteRange, size, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, false
+	}
+	total = -1
+	if size != "*" {
+		var err error
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	if byteRange == "*" {
+		return 0, total, true
+	}
+	first, _, found := strings.Cut(byteRange, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, total, true
+}
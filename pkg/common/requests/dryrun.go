@@ -0,0 +1,221 @@
+// pkg/common/requests/dryrun.go
+package requests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/diff"
+)
+
+// Header set on the synthetic responses returned for requests which were planned instead of sent
+const DryRunHeader = "X-Rego-Dry-Run"
+
+/*
+ * PlannedRequest
+ * A mutating request which was recorded instead of being sent
+ */
+type PlannedRequest struct {
+	Method  string         `json:"method"`            // HTTP method of the request
+	URL     string         `json:"url"`               // Full URL of the request, including its query
+	Payload interface{}    `json:"payload,omitempty"` // Body of the request, as it would have been encoded to JSON
+	Diff    []*FieldChange `json:"diff,omitempty"`    // Fields of the resource which the request would change (PUT/PATCH only)
+	Time    time.Time      `json:"time"`              // Time the request was planned
+}
+
+/*
+ * FieldChange
+ * A single field of a resource which a planned request would change
+ */
+type FieldChange struct {
+	Field  string      `json:"field"`  // Dotted path of the field, e.g. `profile.department`
+	Before interface{} `json:"before"` // Current value of the field, or nil if it is unset
+	After  interface{} `json:"after"`  // Value the field would be set to
+}
+
+/*
+ * Plan
+ * The mutations recorded by one or more clients in dry-run mode
+ */
+type Plan struct {
+	mutex    sync.Mutex
+	requests []*PlannedRequest
+}
+
+// NewPlan returns an empty plan
+func NewPlan() *Plan {
+	return &Plan{}
+}
+
+// Add records a planned request
+func (p *Plan) Add(r *PlannedRequest) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.requests = append(p.requests, r)
+}
+
+// Requests returns the planned requests, in the order they were made
+func (p *Plan) Requests() []*PlannedRequest {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]*PlannedRequest{}, p.requests...)
+}
+
+// Len returns the number of planned requests
+func (p *Plan) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.requests)
+}
+
+// Since returns the requests planned after the first `n`, e.g. those made by a single workflow step
+func (p *Plan) Since(n int) []*PlannedRequest {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if n >= len(p.requests) {
+		return nil
+	}
+	return append([]*PlannedRequest{}, p.requests[n:]...)
+}
+
+// MarshalJSON encodes the plan as its list of requests
+func (p *Plan) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Requests())
+}
+
+// String renders the plan as a human-readable list of requests and field changes
+func (p *Plan) String() string {
+	requests := p.Requests()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d planned request(s)\n", len(requests))
+	for _, r := range requests {
+		sb.WriteString(r.String())
+	}
+	return sb.String()
+}
+
+// String renders the request, followed by its field changes
+func (r *PlannedRequest) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s\n", r.Method, r.URL)
+	if len(r.Diff) == 0 {
+		return sb.String()
+	}
+
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	for _, change := range r.Diff {
+		fmt.Fprintf(w, "  %s\t%v\t->\t%v\n", change.Field, diff.Format(change.Before), diff.Format(change.After))
+	}
+	w.Flush()
+	return sb.String()
+}
+
+/*
+ * # Enable Dry-Run Mode
+ * - Mutating requests (anything but GET/HEAD/OPTIONS, unless overridden by `IsMutation`) are logged and recorded in the plan instead of being sent
+ * - Planned requests return a `200 OK` with a `null` body (or `DryRunBody`), so typed responses decode to their zero values
+ * - PUT/PATCH requests are diffed against the current state of the resource, fetched with a GET on the same URL
+ * - Pass the same plan to several clients to collect the mutations of a whole run; a new plan is created when `plan` is nil
+ */
+func (c *Client) EnableDryRun(plan *Plan) *Plan {
+	if plan == nil {
+		plan = NewPlan()
+	}
+	c.DryRun = true
+	c.Plan = plan
+	return plan
+}
+
+// DisableDryRun sends mutating requests again; the plan recorded so far is kept
+func (c *Client) DisableDryRun() {
+	c.DryRun = false
+}
+
+// isMutation reports whether a request changes state on the server
+func (c *Client) isMutation(method, url string) bool {
+	if c.IsMutation != nil {
+		return c.IsMutation(method, url)
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// plan records a mutating request instead of sending it
+func (c *Client) plan(req *http.Request, data interface{}) (*http.Response, []byte, error) {
+	planned := &PlannedRequest{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Payload: diff.Redact(data, diff.Options{}),
+		Time:    time.Now(),
+	}
+
+	if req.Method == http.MethodPut || req.Method == http.MethodPatch {
+		if after, ok := diff.Normalize(data).(map[string]interface{}); ok {
+			planned.Diff = c.diff(planned.URL, after)
+		}
+	}
+
+	if c.Plan != nil {
+		c.Plan.Add(planned)
+	}
+	c.Log.Printf("[DRY RUN] %s %s", planned.Method, planned.URL)
+	for _, change := range planned.Diff {
+		c.Log.Printf("[DRY RUN]   %s: %s -> %s", change.Field, diff.Format(change.Before), diff.Format(change.After))
+	}
+
+	body := c.DryRunBody
+	if body == nil {
+		body = []byte("null")
+	}
+	resp := &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{DryRunHeader: {"true"}, "Content-Type": {JSON}},
+		Body:          io.NopCloser(strings.NewReader(string(body))),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+	return resp, body, nil
+}
+
+// diff fetches the current state of a resource and compares it to the fields of a payload
+// - When the resource cannot be fetched, every field of the payload is reported as a change from unset
+func (c *Client) diff(url string, after map[string]interface{}) []*FieldChange {
+	before := map[string]interface{}{}
+	if _, body, err := c.do(http.MethodGet, url, nil, nil); err == nil {
+		if current, ok := diff.Normalize(json.RawMessage(body)).(map[string]interface{}); ok {
+			before = current
+		}
+	} else {
+		c.Log.Debugf("[DRY RUN] unable to fetch %s for a diff: %v", url, err)
+	}
+
+	return Diff(before, after)
+}
+
+/*
+ * # Diff
+ * Compares the fields set in `after` to their values in `before`, descending into nested objects
+ * - Only fields present in `after` are reported; fields missing from the payload are assumed to be left unchanged
+ * - Sensitive fields (passwords, secrets, tokens, ...) are redacted
+ */
+func Diff(before, after map[string]interface{}) []*FieldChange {
+	changes := []*FieldChange{}
+	for _, change := range diff.Values(before, after, diff.Options{Partial: true}) {
+		changes = append(changes, &FieldChange{Field: change.Path, Before: change.Before, After: change.After})
+	}
+	return changes
+}
@@ -0,0 +1,134 @@
+// pkg/common/requests/flight.go
+package requests
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+
+	ss "github.com/gemini-oss/rego/pkg/common/starstruct"
+)
+
+/*
+ * # With Singleflight
+ * Collapses identical GET requests in flight into one, whose response is shared by every caller, e.g. when concurrent
+ * orchestrations list all users at once
+ * - Requests are identical when their URL, query, headers and credentials (see `WithSubject`) are; the copies of
+ *   the client (`WithContext`) share theirs
+ * - A caller which joined a request stops waiting when its own context is done, and sends its own request when the
+ *   shared one was cancelled by the context of the caller which sent it
+ * - Responses revalidated from the cache (see `WithConditionalRequests`) are shared like those of the API
+ */
+func WithSingleflight() Option {
+	return func(c *Client) {
+		c.Singleflight = true
+	}
+}
+
+// flights holds the GET requests in flight of a client and of its copies, by key
+type flights struct {
+	mutex sync.Mutex
+	calls map[string]*flight
+}
+
+// flight is a request in flight, and its result once `done` is closed
+type flight struct {
+	done   chan struct{}
+	shared int // Callers which joined the request
+	resp   *http.Response
+	body   []byte
+	err    error
+}
+
+// flightKey returns the key of a GET request sent with `Singleflight`, or false if it is not collapsed
+func (c *Client) flightKey(method string, url string, query interface{}) (string, bool) {
+	if !c.Singleflight || c.flights == nil || method != http.MethodGet {
+		return "", false
+	}
+	values, err := ss.QueryValues(query)
+	if err != nil {
+		return "", false
+	}
+
+	// The headers and credentials are hashed, so the credentials of other subjects never share a response
+	if c.auth != nil {
+		c.auth.mutex.RLock()
+		defer c.auth.mutex.RUnlock()
+	}
+	h := sha256.New()
+	keys := make([]string, 0, len(c.Headers))
+	for key := range c.Headers {
+		if key != "Authorization" {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s: %s\n", key, c.Headers[key])
+	}
+	return fmt.Sprintf("%s %s?%s %s %s", method, url, values.Encode(), hex.EncodeToString(h.Sum(nil)[:8]), c.credentials(c.Headers["Authorization"])), true
+}
+
+// collapse sends a request with `send` unless an identical one is in flight, whose result it waits for instead
+func (c *Client) collapse(key string, send func() (*http.Response, []byte, error)) (*http.Response, []byte, error) {
+	f, ctx := c.flights, c.Context()
+	f.mutex.Lock()
+	if f.calls == nil {
+		f.calls = map[string]*flight{}
+	}
+	if call, ok := f.calls[key]; ok {
+		call.shared++
+		f.mutex.Unlock()
+		c.Log.Debug("Sharing the response of", key)
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+		if isCanceled(call.err) && ctx.Err() == nil {
+			return send()
+		}
+		return call.share()
+	}
+
+	call := &flight{done: make(chan struct{})}
+	f.calls[key] = call
+	f.mutex.Unlock()
+
+	call.resp, call.body, call.err = send()
+
+	f.mutex.Lock()
+	delete(f.calls, key)
+	shared := call.shared
+	f.mutex.Unlock()
+	close(call.done)
+
+	if shared == 0 {
+		return call.resp, call.body, call.err
+	}
+	return call.share()
+}
+
+// share returns a copy of the result of a request, so callers do not see what the others change of the response
+func (call *flight) share() (*http.Response, []byte, error) {
+	body := bytes.Clone(call.body)
+	if call.resp == nil {
+		return nil, body, call.err
+	}
+	resp := *call.resp
+	resp.Header = call.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return &resp, body, call.err
+}
+
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
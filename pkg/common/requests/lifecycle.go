@@ -0,0 +1,80 @@
+// pkg/common/requests/lifecycle.go
+package requests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrClosed is returned by the requests of a client after it was closed with `Close`
+var ErrClosed = errors.New("requests: client is closed")
+
+// lifecycle tracks the requests in flight of a client and of its copies (`WithContext`), so `Close` can drain them
+type lifecycle struct {
+	mutex    sync.Mutex
+	closed   bool
+	inflight sync.WaitGroup
+}
+
+// begin counts a request in flight until the returned function is called, or fails with `ErrClosed` once the client is closed
+func (c *Client) begin() (func(), error) {
+	if c.life == nil {
+		return func() {}, nil
+	}
+	c.life.mutex.Lock()
+	defer c.life.mutex.Unlock()
+	if c.life.closed {
+		return nil, ErrClosed
+	}
+	c.life.inflight.Add(1)
+	return sync.OnceFunc(c.life.inflight.Done), nil
+}
+
+/*
+ * # Close the Client
+ * Rejects further requests with `ErrClosed`, and waits for those in flight, including the streamed bodies which are not
+ * closed yet, or for `ctx` to be done
+ * - Stops the timer of the rate limiter, and writes the cache to disk
+ * - Closing a client closes its copies (`WithContext`), which share its requests
+ */
+func (c *Client) Close(ctx context.Context) error {
+	var drained error
+	if c.life != nil {
+		c.life.mutex.Lock()
+		c.life.closed = true
+		c.life.mutex.Unlock()
+
+		done := make(chan struct{})
+		go func() {
+			c.life.inflight.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			drained = fmt.Errorf("closing client with requests in flight: %w", ctx.Err())
+		}
+	}
+
+	if c.RateLimiter != nil {
+		c.RateLimiter.Stop()
+	}
+	if c.RateLimits != nil {
+		c.RateLimits.Stop()
+	}
+	return errors.Join(drained, c.Cache.Flush())
+}
+
+// inflightBody ends a streamed request once its body is closed
+type inflightBody struct {
+	io.ReadCloser
+	done func()
+}
+
+func (b *inflightBody) Close() error {
+	defer b.done()
+	return b.ReadCloser.Close()
+}
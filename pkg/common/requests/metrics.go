@@ -0,0 +1,29 @@
+// pkg/common/requests/metrics.go
+package requests
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/metrics"
+)
+
+/*
+ * Records a request sent at `sent`, see `metrics.RequestsTotal` and `metrics.RequestDuration`
+ * - The status is `error` when no response was received, e.g. on a reset connection or a timeout
+ * - Each attempt of a retried request is recorded
+ */
+func observe(req *http.Request, resp *http.Response, sent time.Time) {
+	if !metrics.Enabled() {
+		return
+	}
+	host := metrics.Label{Name: "host", Value: req.URL.Host}
+	method := metrics.Label{Name: "method", Value: req.Method}
+	status := metrics.Label{Name: "status", Value: "error"}
+	if resp != nil {
+		status.Value = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.Add(metrics.RequestsTotal, 1, host, method, status)
+	metrics.Observe(metrics.RequestDuration, time.Since(sent).Seconds(), host, method)
+}
@@ -0,0 +1,65 @@
+// pkg/common/requests/middleware.go
+package requests
+
+import (
+	"net/http"
+)
+
+// RoundTripperFunc adapts a function to an `http.RoundTripper`, e.g. to write a `Middleware` inline
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+/*
+ * # Middleware
+ * Wraps the sending of each request of a client, e.g. to sign it, refresh its credentials or log it, calling `next` to send it
+ * - Each attempt of a retried request goes through the middleware; requests planned in dry-run mode do not
+ * - A middleware may modify the request before calling `next`, since each attempt is a new request
+ * - An error it returns is retried like a network failure, unless it is marked with `retry.Permanent`
+ */
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// WithMiddleware sends the client's requests through `mw`, in order: the first sees each request first and each response last
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) {
+		c.Use(mw...)
+	}
+}
+
+// Use appends `mw` to the middleware of the client, after (so inside of) those registered before; it must not be called while the client sends requests
+func (c *Client) Use(mw ...Middleware) {
+	c.middleware = append(c.middleware[:len(c.middleware):len(c.middleware)], mw...)
+}
+
+// BeforeRequest returns a middleware calling `fn` on each request before it is sent; an error fails the request without sending it
+func BeforeRequest(fn func(req *http.Request) error) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := fn(req); err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// AfterResponse returns a middleware calling `fn` on each response, or the error which stopped it, before the client reads it; its results replace them
+func AfterResponse(fn func(req *http.Request, resp *http.Response, err error) (*http.Response, error)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			return fn(req, resp, err)
+		})
+	}
+}
+
+// roundTrip sends `req` with the HTTP client, through the middleware of the client
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	var rt http.RoundTripper = RoundTripperFunc(c.httpClient.Do)
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		rt = c.middleware[i](rt)
+	}
+	return rt.RoundTrip(req)
+}
@@ -0,0 +1,229 @@
+// pkg/common/requests/multipart.go
+package requests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+/*
+ * # Multipart
+ * A `multipart/form-data` body (RFC 7578), e.g. a Jira attachment, a Jamf package or a Slack file, sent as the `data` of
+ * `DoRequest` or `DoStream` whatever the client's `BodyType`:
+ *
+ *	body := requests.NewMultipart().
+ *		Field("channels", "C123").
+ *		File("file", "/tmp/report.csv")
+ *	resp, body, err := client.DoRequest("POST", url, nil, body)
+ *
+ * - Files are streamed as the request is sent, so they are never held in memory
+ * - Files are reopened for each attempt, so the upload can be retried; a part read from an `io.Reader` cannot be resent
+ * - The `Content-Length` is set when the size of every part is known, as some APIs refuse chunked uploads
+ */
+type Multipart struct {
+	boundary string
+	parts    []Part
+}
+
+/*
+ * # Part
+ * A part of a `Multipart` body
+ * - `Open` returns the content of the part for each attempt of the request, and `Size` its length, or -1 when unknown
+ * - A part without `Filename` is a form field
+ */
+type Part struct {
+	Field       string
+	Filename    string
+	ContentType string
+	Size        int64
+	Open        func() (io.ReadCloser, error)
+}
+
+// NewMultipart returns an empty multipart body, with a random boundary
+func NewMultipart() *Multipart {
+	return &Multipart{boundary: multipart.NewWriter(io.Discard).Boundary()}
+}
+
+// Add adds a part to the body
+func (m *Multipart) Add(p Part) *Multipart {
+	m.parts = append(m.parts, p)
+	return m
+}
+
+// Field adds a form field to the body
+func (m *Multipart) Field(name, value string) *Multipart {
+	return m.Add(Part{Field: name, Size: int64(len(value)), Open: func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(value)), nil
+	}})
+}
+
+// File adds the file at `path` to the body, named after its base name; its type is guessed from its extension
+func (m *Multipart) File(field, path string) *Multipart {
+	size := int64(-1)
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+	return m.Add(Part{
+		Field:       field,
+		Filename:    filepath.Base(path),
+		ContentType: contentTypeOf(path),
+		Size:        size,
+		Open: func() (io.ReadCloser, error) {
+			return os.Open(path)
+		},
+	})
+}
+
+// Bytes adds a file with `content` to the body
+func (m *Multipart) Bytes(field, filename string, content []byte) *Multipart {
+	return m.Add(Part{
+		Field:       field,
+		Filename:    filename,
+		ContentType: contentTypeOf(filename),
+		Size:        int64(len(content)),
+		Open: func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(content)), nil
+		},
+	})
+}
+
+// Reader adds a file read from `r` to the body, of unknown size; since `r` is read once, the request cannot be retried
+func (m *Multipart) Reader(field, filename string, r io.Reader) *Multipart {
+	var read atomic.Bool
+	return m.Add(Part{
+		Field:       field,
+		Filename:    filename,
+		ContentType: contentTypeOf(filename),
+		Size:        -1,
+		Open: func() (io.ReadCloser, error) {
+			if read.Swap(true) {
+				return nil, fmt.Errorf("multipart part %q was already sent, and cannot be read again", filename)
+			}
+			return io.NopCloser(r), nil
+		},
+	})
+}
+
+// ContentType returns the `Content-Type` of the body, with its boundary
+func (m *Multipart) ContentType() string {
+	return MultipartFormData + "; boundary=" + m.boundary
+}
+
+/*
+ * Sets the body of `req` to a stream of the parts, which are opened first so a missing file fails before sending
+ * - The parts are written as the transport reads the body, and closed once written or once the transport closes the body
+ */
+func (m *Multipart) apply(req *http.Request) error {
+	readers := make([]io.ReadCloser, 0, len(m.parts))
+	closeAll := func() {
+		for _, r := range readers {
+			r.Close()
+		}
+	}
+	for _, p := range m.parts {
+		r, err := p.Open()
+		if err != nil {
+			closeAll()
+			return fmt.Errorf("opening multipart part %q: %w", p.Field, err)
+		}
+		readers = append(readers, r)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer closeAll()
+		w := multipart.NewWriter(pw)
+		w.SetBoundary(m.boundary)
+		for i, p := range m.parts {
+			part, err := w.CreatePart(p.header())
+			if err == nil {
+				_, err = io.Copy(part, readers[i])
+			}
+			if err != nil {
+				pw.CloseWithError(fmt.Errorf("writing multipart part %q: %w", p.Field, err))
+				return
+			}
+		}
+		pw.CloseWithError(w.Close())
+	}()
+
+	req.Body = pr
+	req.ContentLength = m.length()
+	req.Header.Set("Content-Type", m.ContentType())
+	return nil
+}
+
+// length returns the length of the body, or -1 when the size of a part is unknown
+func (m *Multipart) length() int64 {
+	var counted countingWriter
+	w := multipart.NewWriter(&counted)
+	w.SetBoundary(m.boundary)
+	for _, p := range m.parts {
+		if p.Size < 0 {
+			return -1
+		}
+		w.CreatePart(p.header())
+		counted += countingWriter(p.Size)
+	}
+	w.Close()
+	return int64(counted)
+}
+
+// header returns the MIME header of the part
+func (p Part) header() textproto.MIMEHeader {
+	h := make(textproto.MIMEHeader)
+	if p.Filename == "" {
+		h.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": p.Field}))
+		return h
+	}
+	h.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": p.Field, "filename": p.Filename}))
+	contentType := p.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	h.Set("Content-Type", contentType)
+	return h
+}
+
+// MarshalJSON describes the parts of the body, e.g. in the plan of a dry run, without their content
+func (m *Multipart) MarshalJSON() ([]byte, error) {
+	parts := map[string]interface{}{}
+	for _, p := range m.parts {
+		if p.Filename == "" {
+			if r, err := p.Open(); err == nil {
+				value, _ := io.ReadAll(r)
+				r.Close()
+				parts[p.Field] = string(value)
+			}
+			continue
+		}
+		parts[p.Field] = map[string]interface{}{"filename": p.Filename, "contentType": p.ContentType, "size": p.Size}
+	}
+	return json.Marshal(parts)
+}
+
+// contentTypeOf guesses the type of a file from its extension
+func contentTypeOf(filename string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(filename)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// countingWriter counts the bytes written to it
+type countingWriter int64
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	*c += countingWriter(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,172 @@
+// pkg/common/requests/pagination.go
+package requests
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+)
+
+// Iterator steps through the items of a paginated list, e.g. `for it.Next() { use(it.Value()) }`, then checks `Err()`
+type Iterator[V any] interface {
+	Next() bool // Advances to the next item, requesting the next page once the current one is consumed; false when done or failed
+	Value() V   // The current item
+	Err() error // The error which stopped the iteration, if any
+}
+
+/*
+ * # Page Iterator
+ * Iterates over the items of a paginated list endpoint, requesting each page only once the previous one is consumed
+ * - Pages are fetched with an `iterator.Fetch`, so the same fetch backs a `PageIterator` or an `iterator.Seq` (see `Seq`)
+ * - Adapters cover the common schemes: `OffsetPages` (e.g. Backupify's `start` and `length`), `CursorPages` (e.g.
+ *   Google's `pageToken`), and `LinkPages` (e.g. Okta's `Link: <...>; rel="next"` headers)
+ */
+type PageIterator[V any] struct {
+	fetch  iterator.Fetch[V]
+	cursor string
+	page   []V
+	value  V
+	err    error
+	last   bool // The current page is the last
+}
+
+// NewPageIterator returns an iterator over the pages returned by `fetch`
+func NewPageIterator[V any](fetch iterator.Fetch[V]) *PageIterator[V] {
+	return &PageIterator[V]{fetch: fetch}
+}
+
+// Next advances to the next item, requesting pages until one has items, or the list or a request ends
+func (it *PageIterator[V]) Next() bool {
+	for len(it.page) == 0 {
+		if it.last || it.err != nil {
+			var zero V
+			it.value = zero
+			return false
+		}
+		items, next, err := it.fetch(it.cursor)
+		if err != nil {
+			it.err = err
+			continue
+		}
+		it.page = items
+		it.last = next == "" || next == it.cursor
+		it.cursor = next
+	}
+	it.value, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Value returns the current item
+func (it *PageIterator[V]) Value() V {
+	return it.value
+}
+
+// Err returns the error which stopped the iteration, if any
+func (it *PageIterator[V]) Err() error {
+	return it.err
+}
+
+// Seq returns the remaining items as an `iterator.Seq`, e.g. to use with `iterator.ForEach` or `query.Apply`
+func (it *PageIterator[V]) Seq() iterator.Seq[V] {
+	return func(yield func(V, error) bool) {
+		for it.Next() {
+			if !yield(it.Value(), nil) {
+				return
+			}
+		}
+		if it.err != nil {
+			var zero V
+			yield(zero, it.err)
+		}
+	}
+}
+
+// CursorPages iterates over a list paginated with opaque cursors, e.g. Google's `pageToken` and `nextPageToken`
+func CursorPages[V any](fetch iterator.Fetch[V]) *PageIterator[V] {
+	return NewPageIterator(fetch)
+}
+
+/*
+ * # Offset Pages
+ * Iterates over a list paginated by offset, requesting `size` items from offset 0, `size`, `2*size`...
+ * - The list ends with a page of fewer than `size` items
+ */
+func OffsetPages[V any](size int, fetch func(offset, limit int) ([]V, error)) *PageIterator[V] {
+	if size <= 0 {
+		size = 100
+	}
+	return NewPageIterator(func(cursor string) ([]V, string, error) {
+		offset := 0
+		if cursor != "" {
+			offset, _ = strconv.Atoi(cursor)
+		}
+		items, err := fetch(offset, size)
+		if err != nil || len(items) < size {
+			return items, "", err
+		}
+		return items, strconv.Itoa(offset + len(items)), nil
+	})
+}
+
+/*
+ * # Link Pages
+ * Iterates over a JSON array served at `url`, sending `query` with the first request, and following the `rel="next"`
+ * link of each response (RFC 8288), as Okta, GitHub and others paginate
+ */
+func LinkPages[V any](c *Client, url string, query interface{}) *PageIterator[V] {
+	return NewPageIterator(func(cursor string) ([]V, string, error) {
+		target, q := url, query
+		if cursor != "" {
+			target, q = cursor, nil
+		}
+		resp, body, err := c.DoRequest(http.MethodGet, target, q, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		var items []V
+		if err := JSONCodec().Unmarshal(body, &items); err != nil {
+			return nil, "", fmt.Errorf("unmarshalling page of %s: %w", target, err)
+		}
+		return items, NextLink(resp.Header), nil
+	})
+}
+
+// NextLink returns the target of the `rel="next"` link of `Link` headers, or "" when there is none
+func NextLink(header http.Header) string {
+	return Link(header, "next")
+}
+
+// Link returns the target of the first link of `Link` headers with the relation `rel`, e.g. `self`, or "" when there is none
+func Link(header http.Header, rel string) string {
+	for _, value := range header.Values("Link") {
+		// Targets are delimited, since they may hold commas and semicolons
+		for {
+			open := strings.Index(value, "<")
+			end := strings.Index(value, ">")
+			if open < 0 || end < open {
+				break
+			}
+			target, params := value[open+1:end], value[end+1:]
+			if next := strings.Index(params, "<"); next >= 0 {
+				params = params[:next]
+			}
+			value = value[end+1+len(params):]
+
+			for _, param := range strings.Split(params, ";") {
+				name, rels, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(name, "rel") {
+					continue
+				}
+				for _, r := range strings.Fields(strings.Trim(strings.TrimSpace(rels), `"`)) {
+					if strings.EqualFold(r, rel) {
+						return target
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
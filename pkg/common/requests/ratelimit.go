@@ -0,0 +1,25 @@
+// pkg/common/requests/ratelimit.go
+package requests
+
+import (
+	"net/http"
+
+	rl "github.com/gemini-oss/rego/pkg/common/ratelimit"
+)
+
+// WithRateLimitBuckets limits the requests of each endpoint family with its bucket in `b`; nil keeps `RateLimiter` alone
+func WithRateLimitBuckets(b *rl.Buckets) Option {
+	return func(c *Client) {
+		c.RateLimits = b
+	}
+}
+
+// rateLimiterOf returns the rate limiter of `req`: its bucket's, or the client's when no bucket matches
+func (c *Client) rateLimiterOf(req *http.Request) *rl.RateLimiter {
+	if c.RateLimits != nil {
+		if limiter := c.RateLimits.Limiter(req); limiter != nil {
+			return limiter
+		}
+	}
+	return c.RateLimiter
+}
@@ -0,0 +1,62 @@
+// pkg/common/requests/reauth.go
+package requests
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+)
+
+// reauth serializes the renewal of the credentials of a client and of its copies (`WithContext`)
+type reauth struct {
+	mutex      sync.RWMutex
+	generation int // Number of renewals, so requests rejected with the same credentials renew them only once
+}
+
+/*
+ * Returns whether the request was rejected for its credentials: a `401 Unauthorized`, or an OAuth 2.0 `invalid_token` error
+ */
+func unauthorized(err error, body []byte) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, rerrors.ErrUnauthorized) || strings.Contains(string(body), "invalid_token")
+}
+
+/*
+ * # Reauthenticate
+ * Renews the credentials of the client with its `Reauthenticate` hook, after a request sent with the credentials of
+ * `generation` was rejected
+ * - When several requests are rejected at once, the credentials are renewed once and every request is retried with them
+ */
+func (c *Client) reauthenticate(generation int) error {
+	if c.auth != nil {
+		c.auth.mutex.Lock()
+		defer c.auth.mutex.Unlock()
+		if c.auth.generation != generation {
+			return nil
+		}
+	}
+
+	c.Log.Warning("Credentials were rejected; reauthenticating")
+	if err := c.Reauthenticate(c); err != nil {
+		return fmt.Errorf("reauthenticating: %w", err)
+	}
+	if c.auth != nil {
+		c.auth.generation++
+	}
+	return nil
+}
+
+// generation returns the number of renewals of the client's credentials
+func (c *Client) generation() int {
+	if c.auth == nil {
+		return 0
+	}
+	c.auth.mutex.RLock()
+	defer c.auth.mutex.RUnlock()
+	return c.auth.generation
+}
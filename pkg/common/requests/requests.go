@@ -2,16 +2,22 @@
 package requests
 
 import (
-	"encoding/json"
+	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/gemini-oss/rego/pkg/common/breaker"
 	"github.com/gemini-oss/rego/pkg/common/cache"
 	"github.com/gemini-oss/rego/pkg/common/config"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
 	"github.com/gemini-oss/rego/pkg/common/log"
 	rl "github.com/gemini-oss/rego/pkg/common/ratelimit"
 	"github.com/gemini-oss/rego/pkg/common/retry"
@@ -56,51 +62,136 @@ var (
  * @param headers Headers
  */
 type Client struct {
-	httpClient  *http.Client
-	BodyType    string
-	Cache       *cache.Cache
-	Headers     Headers
-	Log         *log.Logger
-	RateLimiter *rl.RateLimiter
+	httpClient     *http.Client
+	BodyType       string
+	Cache          *cache.Cache
+	Headers        Headers
+	Log            *log.Logger
+	RateLimiter    *rl.RateLimiter
+	RateLimits     *rl.Buckets                                      // Rate limiters of the endpoint families, set with `WithRateLimitBuckets`; `RateLimiter` limits the requests matching none
+	DryRun         bool                                             // Record mutating requests in `Plan` instead of sending them
+	Plan           *Plan                                            // Mutations recorded while `DryRun` is set
+	IsMutation     func(method, url string) bool                    // Overrides which requests are mutations, e.g. for APIs that read via POST
+	DryRunBody     []byte                                           // Body returned for planned requests, e.g. `{"ok":true}`; defaults to `null`
+	Authorize      func(method, url string, data interface{}) error // Checks each mutating request before it is sent (or planned); an error blocks it
+	Reauthenticate func(c *Client) error                            // Renews the credentials (e.g. `Headers`) after a 401, before the request is retried once; it must not send requests with `c`
+	Subject        string                                           // Subject of the credentials, e.g. the user a service account impersonates, set with `WithSubject`; the `Authorization` header of `Headers` when empty
+	Conditional    bool                                             // Revalidate cached GET responses with their `ETag` or `Last-Modified`, set with `WithConditionalRequests`
+	Singleflight   bool                                             // Collapse identical GET requests in flight into one, set with `WithSingleflight`
+	RetryPolicy    func(err error, attempt int) error               // Decides how a failed request is retried, marking its error with `retry.Permanent` or `retry.After`; the failures of `Retry` are retried when nil
+	Retry          retry.Policy                                     // Attempts and backoff of failed requests, and which are retried, set with `WithRetry`; transient failures are retried `retry.MaxRetries` times when zero
+	NonIdempotent  func(err error) bool                             // Which failures of POST and PATCH requests are retried, set with `WithNonIdempotentRetry`; `RetryUnsent` when nil
+	Breaker        *breaker.Breaker                                 // Circuit breaker consulted before each request, by host, set with `WithCircuitBreaker`; optional
+	Tracer         Tracer                                           // Starts a span for each call, set with `WithTracer`; the tracer of `SetTracer` when nil
+	Timeouts       Timeouts                                         // Time each attempt of a call may take, by kind of operation, set with `WithTimeouts`; `DefaultTimeouts` when zero
+	middleware     []Middleware                                     // Wraps the sending of each request, set with `WithMiddleware` or `Use`
+	UserAgent      string                                           // Suffix of the User-Agent identifying the client, e.g. `okta`, set with `WithUserAgent`
+	ctx            context.Context                                  // Context of every request, set with `WithContext`
+	timeout        time.Duration                                    // Timeout of each attempt of the call the copy is bound to; none when zero
+	auth           *reauth                                          // Renewals of the credentials, shared with the copies of the client
+	life           *lifecycle                                       // Requests in flight, shared with the copies of the client, drained by `Close`
+	flights        *flights                                         // GET requests in flight, shared with the copies of the client, joined by identical ones
 }
 
 /*
  * NewClient
  * @param headers Headers
+ * @param opts ...Option, e.g. `WithMaxIdleConnsPerHost(100)` to tune the transport for bulk syncs
+ * - The proxies of the environment (see `ProxyFromEnvironment`) are used unless `c` has a transport of its own
  * @return *Client
  */
-func NewClient(c *http.Client, headers Headers, rateLimiter *rl.RateLimiter) *Client {
-	encryptionKey := []byte(config.GetEnv("REGO_ENCRYPTION_KEY"))
-	if len(encryptionKey) == 0 {
-		l.Fatal("REGO_ENCRYPTION_KEY is not set")
+func NewClient(c *http.Client, headers Headers, rateLimiter *rl.RateLimiter, opts ...Option) *Client {
+	if c == nil {
+		c = &http.Client{}
 	}
-
-	cache, err := cache.NewCache(encryptionKey, "rego_cache_requests.gob", 1000000)
-	if err != nil {
-		panic(err)
+	client := &Client{
+		httpClient:  c,
+		Headers:     headers,
+		Log:         l,
+		RateLimiter: rateLimiter,
+		auth:        &reauth{},
+		life:        &lifecycle{},
+		flights:     &flights{},
+	}
+	// The proxies of the environment apply to Go's default transport, unless the options set the client's own
+	if p := ProxyFromEnvironment(); p != nil && (c.Transport == nil || c.Transport == http.DefaultTransport) {
+		WithProxy(p)(client)
+	}
+	for _, opt := range opts {
+		opt(client)
 	}
 
-	if c != nil {
-		return &Client{
-			httpClient:  c,
-			Cache:       cache,
-			Headers:     headers,
-			Log:         l,
-			RateLimiter: rateLimiter,
+	if client.Cache == nil {
+		encryptionKey := []byte(config.GetEnv("REGO_ENCRYPTION_KEY"))
+		if len(encryptionKey) == 0 {
+			l.Fatal("REGO_ENCRYPTION_KEY is not set")
 		}
+
+		cache, err := cache.NewCache(encryptionKey, "rego_cache_requests.gob", 1000000)
+		if err != nil {
+			panic(err)
+		}
+		client.Cache = cache
 	}
-	return &Client{
-		httpClient:  &http.Client{},
-		Cache:       cache,
-		Headers:     headers,
-		Log:         l,
-		RateLimiter: rateLimiter,
+	return client
+}
+
+// WithCache caches the client's downloads in `c`, in place of the encrypted file cache opened with `REGO_ENCRYPTION_KEY`
+func WithCache(c *cache.Cache) Option {
+	return func(client *Client) {
+		client.Cache = c
+	}
+}
+
+// WithRetryPolicy decides how failed requests are retried, e.g. to back off longer when the API reports a quota was exceeded
+func WithRetryPolicy(policy func(err error, attempt int) error) Option {
+	return func(c *Client) {
+		c.RetryPolicy = policy
+	}
+}
+
+// WithLogger replaces the client's logger, e.g. with one from `log.NewHandlerLogger` which writes through the consumer's own logging
+func WithLogger(logger *log.Logger) Option {
+	return func(c *Client) {
+		c.Log = logger
+	}
+}
+
+/*
+ * # With Context
+ * Returns a copy of the client whose requests are bound to `ctx`
+ * - Cancelling `ctx` aborts the request in flight, and stops retries, their backoff and the waits of the rate limiter
+ * - The copy shares the headers, cache, rate limiter and dry-run plan of the client, and is closed with it
+ */
+func (c *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("nil context")
+	}
+	cc := *c
+	cc.ctx = ctx
+	return &cc
+}
+
+// Context returns the context of the client's requests; `context.Background()` unless set with `WithContext`
+func (c *Client) Context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
 	}
+	return context.Background()
 }
 
 // UpdateHeaders changes the headers for the HTTP client
 func (c *Client) UpdateContentType(contentType string) {
-	c.Headers["Content-Type"] = contentType
+	c.SetHeader("Content-Type", contentType)
+}
+
+// SetHeader sets a header of the client's requests, and of its copies (`WithContext`), while they may be in flight
+func (c *Client) SetHeader(key, value string) {
+	if c.auth != nil {
+		c.auth.mutex.Lock()
+		defer c.auth.mutex.Unlock()
+	}
+	c.Headers[key] = value
 }
 
 // UpdateHeaders changes the payload body for the HTTP client
@@ -108,6 +199,13 @@ func (c *Client) UpdateBodyType(bodyType string) {
 	c.BodyType = bodyType
 }
 
+// UpdateTransport changes the transport of the HTTP client, e.g. to record or replay its requests in tests
+func (c *Client) UpdateTransport(rt http.RoundTripper) {
+	hc := *c.httpClient
+	hc.Transport = rt
+	c.httpClient = &hc
+}
+
 /*
  * Paginator
  * @param Self string
@@ -128,16 +226,21 @@ type Paginator struct {
  * @return error
  */
 func DecodeJSON(body []byte, result interface{}) error {
-	return json.Unmarshal(body, result)
+	return JSONCodec().Unmarshal(body, result)
 }
 
 func (c *Client) CreateRequest(method string, url string) (*http.Request, error) {
-	req, err := http.NewRequest(method, url, nil)
+	req, err := http.NewRequestWithContext(c.Context(), method, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	// Set headers
+	if c.auth != nil {
+		c.auth.mutex.RLock()
+		defer c.auth.mutex.RUnlock()
+	}
+	req.Header.Set("User-Agent", c.userAgentOf())
 	for key, value := range c.Headers {
 		req.Header.Set(key, value)
 	}
@@ -145,25 +248,21 @@ func (c *Client) CreateRequest(method string, url string) (*http.Request, error)
 	return req, nil
 }
 
+// SetQueryParams adds the parameters of a query struct or map to the URL of a request, encoded like `starstruct.QueryValues`
 func SetQueryParams(req *http.Request, query interface{}) {
 	if query == nil {
 		return
 	}
 
-	q := req.URL.Query()
-	parameters, err := ss.ToMap(query, false)
+	parameters, err := ss.QueryValues(query)
 	if err != nil {
 		return
 	}
 
-	for key, value := range parameters {
-		switch v := value.(type) {
-		case []interface{}:
-			for _, item := range v {
-				q.Add(key, fmt.Sprintf("%v", item))
-			}
-		default:
-			q.Add(key, fmt.Sprintf("%v", value))
+	q := req.URL.Query()
+	for key, values := range parameters {
+		for _, value := range values {
+			q.Add(key, value)
 		}
 	}
 
@@ -174,12 +273,18 @@ func SetJSONPayload(req *http.Request, data interface{}) error {
 	if data == nil {
 		return nil
 	}
-	p, err := ss.ToMap(data, true)
-	if err != nil {
-		return err
+
+	// Some APIs (e.g. Adobe UMAPI) expect a top-level JSON array, which cannot be represented as a map
+	var p interface{} = data
+	if v := reflect.Indirect(reflect.ValueOf(data)); v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		m, err := ss.ToMap(data, true)
+		if err != nil {
+			return err
+		}
+		p = m
 	}
 
-	payload, err := json.Marshal(p)
+	payload, err := JSONCodec().Marshal(p)
 	if err != nil {
 		return fmt.Errorf("marshaling request body: %w", err)
 	}
@@ -233,74 +338,229 @@ func SetXMLPayload(req *http.Request, data interface{}) error {
 	return nil
 }
 
-func (c *Client) DoRequest(method string, url string, query interface{}, data interface{}) (*http.Response, []byte, error) {
-	realTime := retry.RealTime{}
-	return c.doRetry(method, url, query, data, realTime)
+/*
+ * # Do a Request
+ * Sends a request, retrying its transient failures, and returns the response with its body read in full
+ * - Each attempt is bounded by the client's `Timeouts`, unless `opts` set the call's own, e.g. `Timeout(10 * time.Minute)`
+ *   for a slow report, or `Deadline(t)` to bound the call with its retries
+ * - With `Singleflight`, a GET identical to one in flight shares its response instead of being sent
+ */
+func (c *Client) DoRequest(method string, url string, query interface{}, data interface{}, opts ...CallOption) (*http.Response, []byte, error) {
+	done, err := c.begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer done()
+
+	if key, ok := c.flightKey(method, url, query); ok {
+		return c.collapse(key, func() (*http.Response, []byte, error) {
+			return c.doRequest(method, url, query, data, opts)
+		})
+	}
+	return c.doRequest(method, url, query, data, opts)
 }
 
-func (c *Client) doRetry(method string, url string, query interface{}, data interface{}, time retry.Time) (*http.Response, []byte, error) {
+// doRequest sends a request for `DoRequest`, with its retries
+func (c *Client) doRequest(method string, url string, query interface{}, data interface{}, opts []CallOption) (*http.Response, []byte, error) {
+	c, release := c.bind(c.timeoutOf(method), opts)
+	defer release()
+
+	// Policies are checked once, since a denial is not worth retrying
+	if c.Authorize != nil && c.isMutation(method, url) {
+		if err := c.Authorize(method, url, data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	traced, end := c.trace(method, url)
+	resp, body, err := traced.doRetry(method, url, query, data, traced.do, retry.RealTime{})
+	end(resp, err)
+	return resp, body, err
+}
+
+// DoRequestContext sends a request like `DoRequest`, bound to `ctx` in place of the client's context
+func (c *Client) DoRequestContext(ctx context.Context, method string, url string, query interface{}, data interface{}, opts ...CallOption) (*http.Response, []byte, error) {
+	return c.WithContext(ctx).DoRequest(method, url, query, data, opts...)
+}
+
+// doRetry sends a request with `do`, retrying it until it succeeds or `RetryPolicy` gives up
+func (c *Client) doRetry(method string, url string, query interface{}, data interface{}, do sender, time retry.Time) (*http.Response, []byte, error) {
 	var resp *http.Response
 	var body []byte
-	err := retry.Retry(func() error {
+	reauthenticated := false
+	attempt := 0
+	err := c.logAttempts(c.retryPolicy(method), method, url).Retry(c.Context(), func() error {
 		var reqErr error
-		resp, body, reqErr = c.do(method, url, query, data)
+		generation := c.generation()
+		resp, body, reqErr = do(method, url, query, data)
+		if c.Reauthenticate != nil && !reauthenticated && unauthorized(reqErr, body) {
+			reauthenticated = true
+			if err := c.reauthenticate(generation); err != nil {
+				return errors.Join(reqErr, err)
+			}
+			resp, body, reqErr = do(method, url, query, data)
+		}
+		if reqErr != nil && c.RetryPolicy != nil {
+			reqErr = c.RetryPolicy(reqErr, attempt)
+		}
+		attempt++
 		return reqErr
 	}, time)
 
 	return resp, body, err
 }
 
+// sender sends a request once, like `do` or `send`
+type sender func(method string, url string, query interface{}, data interface{}) (*http.Response, []byte, error)
+
+// do sends a request once, reading its response body in full
 func (c *Client) do(method string, url string, query interface{}, data interface{}) (*http.Response, []byte, error) {
+	resp, body, err := c.send(method, url, query, data)
+	if err != nil || body != nil {
+		return resp, body, err
+	}
+	defer resp.Body.Close()
+
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading response body: %w", err)
+	}
+	return resp, body, nil
+}
+
+// send sends a request once, leaving the body of a successful response unread; the body of a failed, planned or revalidated one is returned
+func (c *Client) send(method string, url string, query interface{}, data interface{}) (*http.Response, []byte, error) {
 	// Validate HTTP method
 	validMethods := map[string]bool{
 		"GET": true, "POST": true, "PUT": true, "DELETE": true,
 		"HEAD": true, "OPTIONS": true, "PATCH": true,
 	}
 	if _, valid := validMethods[method]; !valid {
-		return nil, nil, fmt.Errorf("invalid HTTP method: %s", method)
+		return nil, nil, retry.Permanent(fmt.Errorf("invalid HTTP method: %s", method))
 	}
 
+	// A malformed request fails the same way every time, so it is not retried
 	req, err := c.CreateRequest(method, url)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, retry.Permanent(err)
 	}
 
 	SetQueryParams(req, query)
 
 	if err := setPayload(req, data, c.BodyType); err != nil {
-		return nil, nil, err
+		return nil, nil, retry.Permanent(err)
+	}
+
+	if c.DryRun && c.isMutation(method, req.URL.String()) {
+		return c.plan(req, data)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	// Requests the API asked to hold back, or rejected, wait before they are sent
+	if limiter := c.rateLimiterOf(req); limiter != nil {
+		waited := time.Now()
+		err := limiter.WaitHold(c.Context())
+		if tc := callOf(req.Context()); tc != nil {
+			tc.rateLimitWait.Add(int64(time.Since(waited)))
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var report func(error)
+	if c.Breaker != nil {
+		if report, err = c.Breaker.Allow(req.URL.Host); err != nil {
+			return nil, nil, retry.Permanent(err)
+		}
+	}
+
+	tc := callOf(req.Context())
+	if tc != nil {
+		tc.attempts.Add(1)
+	}
+
+	req, release := c.attempt(req)
+	cached := c.revalidate(req)
+	sent := time.Now()
+	resp, err := c.roundTrip(req)
+	err = c.timedOut(req, err)
+	observe(req, resp, sent)
+	c.debug(req, resp, err, sent)
+	if report != nil {
+		report(outcome(resp, err))
+	}
 	if err != nil {
+		release()
 		return nil, nil, err
 	}
-	defer resp.Body.Close()
+	resp.Body = &attemptBody{ReadCloser: resp.Body, client: c, req: req, cancel: release}
+
+	// Update rate limiter if headers are present; cancelling the call stops its wait, which the attempt's timeout does not bound
+	if tc != nil {
+		tc.status.Store(int64(resp.StatusCode))
+	}
+	if limiter := c.rateLimiterOf(req); limiter != nil {
+		limiter.UpdateFromHeaders(resp.Header)
+		waited := time.Now()
+		err := limiter.WaitContext(c.Context())
+		if tc != nil {
+			tc.rateLimitWait.Add(int64(time.Since(waited)))
+		}
+		if err != nil {
+			resp.Body.Close()
+			return nil, nil, err
+		}
+	}
 
-	// Update rate limiter if headers are present
-	if c.RateLimiter != nil {
-		c.RateLimiter.UpdateFromHeaders(resp.Header)
-		c.RateLimiter.Wait()
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return c.notModified(req, resp, cached), cached.Body, nil
+	}
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		c.validate(req, resp)
+		return resp, nil, nil
 	}
 
+	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, nil, fmt.Errorf("reading response body: %w", err)
 	}
-
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return resp, body, nil
-	case http.StatusTooManyRequests:
-		fmt.Println(string(body)) // Will consider logging instead of printing
-	default:
-		return nil, body, fmt.Errorf(string(body))
+	if resp.StatusCode == http.StatusTooManyRequests {
+		c.Log.Warning("Rate limited:", string(body))
+	}
+	if limiter := c.rateLimiterOf(req); limiter != nil {
+		limiter.UpdateFromError(resp.StatusCode, resp.Header, body)
 	}
 
-	return nil, body, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	// Provider packages refine this error with the error code and message of their API
+	apiErr := rerrors.NewAPIError("", resp.StatusCode, "", string(body))
+	apiErr.Body = body
+	apiErr.RetryAfter = retryAfterOf(resp)
+	return nil, body, apiErr
+}
+
+// retryAfterOf returns the time a rejected response asks to wait, from its `Retry-After`, or else the
+// `X-Rate-Limit-Reset` of a `429` (e.g. Okta's); zero when it sets none
+func retryAfterOf(resp *http.Response) time.Duration {
+	if wait := rl.RetryAfter(resp.Header); wait > 0 {
+		return wait
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return 0
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-Rate-Limit-Reset"), 10, 64); err == nil {
+		if wait := time.Until(time.Unix(reset, 0)); wait > 0 {
+			return wait
+		}
+	}
+	return 0
 }
 
 func setPayload(req *http.Request, data interface{}, bodyType string) error {
+	if m, ok := data.(*Multipart); ok {
+		return m.apply(req)
+	}
+
 	switch bodyType {
 	case FormURLEncoded, fmt.Sprintf("%s; charset=utf-8", FormURLEncoded):
 		return SetFormURLEncodedPayload(req, data)
@@ -0,0 +1,141 @@
+// pkg/common/requests/retry.go
+package requests
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+// DefaultRetryStatuses are the statuses of the transient failures which are retried unless the client sets its own
+var DefaultRetryStatuses = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+/*
+ * # With Retry
+ * Retries failed requests with `policy`, e.g. `retry.Policy{MaxAttempts: 3, MaxBackoff: time.Minute}`
+ * - Without `policy.Retryable`, the network failures and the statuses of `DefaultRetryStatuses` are retried
+ * - Malformed requests (e.g. an invalid method or URL) are never retried
+ * - Non-idempotent requests are retried only on the failures of `WithNonIdempotentRetry`
+ * - `policy.OnAttempt` is called after each attempt, e.g. to count retries; the failed ones are also logged at DEBUG
+ */
+func WithRetry(policy retry.Policy) Option {
+	return func(c *Client) {
+		c.Retry = policy
+	}
+}
+
+/*
+ * # Retry Statuses
+ * Returns a `retry.Policy.Retryable` which retries the responses with one of `statuses`, and the requests which failed
+ * without a response, e.g. on a reset connection or a timeout
+ * - Errors reported in a successful response (e.g. by Slack) are retried when rate limited or unavailable
+ * - A cancelled or expired context is not retried
+ */
+func RetryStatuses(statuses ...int) func(err error) bool {
+	return func(err error) bool {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		apiErr, ok := rerrors.AsAPIError(err)
+		if !ok {
+			return true
+		}
+		if apiErr.StatusCode == 0 {
+			return rerrors.Retryable(err)
+		}
+		return slices.Contains(statuses, apiErr.StatusCode)
+	}
+}
+
+/*
+ * # With Non-Idempotent Retry
+ * Decides which failures of non-idempotent requests (POST and PATCH) are retried, on top of the client's policy, since
+ * retrying one the API processed may repeat its effect, e.g. create a user twice
+ * - `RetryUnsent` when not set: only the requests the API could not have processed are retried
+ * - Failures a provider's `RetryPolicy` marks with `retry.After`, e.g. Google's `403 rateLimitExceeded`, are rejected
+ *   unprocessed, so they are retried regardless
+ * - Requests with an `Idempotency-Key` header are retried like idempotent ones
+ */
+func WithNonIdempotentRetry(retryable func(err error) bool) Option {
+	return func(c *Client) {
+		c.NonIdempotent = retryable
+	}
+}
+
+// RetryUnsent reports whether a request failed before the API processed it: it was rejected with a `429` or `503`, or
+// the connection to the API could not be opened
+func RetryUnsent(err error) bool {
+	if errors.Is(err, rerrors.ErrRateLimited) {
+		return true
+	}
+	if apiErr, ok := rerrors.AsAPIError(err); ok && apiErr.StatusCode == http.StatusServiceUnavailable {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// idempotent reports whether requests of `method` may be retried after any failure
+func (c *Client) idempotent(method string) bool {
+	return retry.Idempotent(method) || c.Headers["Idempotency-Key"] != ""
+}
+
+/*
+ * Returns the retry policy of the client's requests of `method`; a `RetryPolicy` decides which failures are retried in
+ * place of the statuses, and `NonIdempotent` narrows them for non-idempotent requests
+ */
+func (c *Client) retryPolicy(method string) retry.Policy {
+	policy := c.Retry
+	if policy.Retryable == nil && c.RetryPolicy == nil {
+		policy.Retryable = RetryStatuses(DefaultRetryStatuses...)
+	}
+	if !c.idempotent(method) {
+		retryable, unsent := policy.Retryable, c.NonIdempotent
+		if unsent == nil {
+			unsent = RetryUnsent
+		}
+		policy.Retryable = func(err error) bool {
+			return (retryable == nil || retryable(err)) && unsent(err)
+		}
+	}
+	return policy
+}
+
+// logAttempts logs the failed attempts of a request at DEBUG, before passing them to the policy's `OnAttempt`
+func (c *Client) logAttempts(policy retry.Policy, method string, url string) retry.Policy {
+	onAttempt := policy.OnAttempt
+	policy.OnAttempt = func(a retry.Attempt) {
+		if a.Err != nil {
+			c.Log.LogAttrs(log.DEBUG, "Attempt failed",
+				slog.String("method", method),
+				slog.String("url", url),
+				slog.Int("attempt", a.Number),
+				slog.Bool("retried", !a.Last),
+				slog.Duration("backoff", a.Backoff),
+				slog.String("error", a.Err.Error()),
+			)
+		}
+		if onAttempt != nil {
+			onAttempt(a)
+		}
+	}
+	return policy
+}
@@ -0,0 +1,148 @@
+// pkg/common/requests/stream.go
+package requests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+/*
+ * # Stream a Request
+ * Sends a request like `DoRequest`, but returns the response with its body unread, so huge responses are decoded as they
+ * arrive rather than held in memory whole
+ * - The caller must close the body
+ * - Failed responses are read in full and retried as with `DoRequest`; a failure while reading the body is not retried
+ */
+func (c *Client) DoStream(method string, url string, query interface{}, data interface{}, opts ...CallOption) (*http.Response, error) {
+	inflight, err := c.begin()
+	if err != nil {
+		return nil, err
+	}
+	c, release := c.bind(resolve(c.Timeouts.Stream, DefaultTimeouts.Stream), opts)
+	done := func() {
+		release()
+		inflight()
+	}
+
+	if c.Authorize != nil && c.isMutation(method, url) {
+		if err := c.Authorize(method, url, data); err != nil {
+			done()
+			return nil, err
+		}
+	}
+
+	traced, end := c.trace(method, url)
+	resp, _, err := traced.doRetry(method, url, query, data, traced.send, retry.RealTime{})
+	end(resp, err)
+	if err != nil {
+		done()
+		return nil, err
+	}
+	// The request stays in flight until its body is closed
+	resp.Body = &inflightBody{ReadCloser: resp.Body, done: done}
+	return resp, nil
+}
+
+// DoStreamContext streams a request like `DoStream`, bound to `ctx` in place of the client's context; cancelling `ctx` also aborts reading the body
+func (c *Client) DoStreamContext(ctx context.Context, method string, url string, query interface{}, data interface{}, opts ...CallOption) (*http.Response, error) {
+	return c.WithContext(ctx).DoStream(method, url, query, data, opts...)
+}
+
+/*
+ * DecodeStream
+ * @param r io.Reader
+ * @param result interface{}
+ * @return error
+ * - An empty body leaves `result` unchanged, as with the empty bodies of deletions and lifecycle operations
+ */
+func DecodeStream(r io.Reader, result interface{}) error {
+	err := JSONCodec().NewDecoder(r).Decode(result)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+/*
+ * # Decode a JSON Array as a Stream
+ * Decodes the elements of a JSON array one at a time, passing each to `fn`, so only one element is held in memory
+ * - An empty body or `null` has no elements
+ * - An error from `fn` stops the decoding and is returned
+ * - With a codec whose decoder cannot read tokens, the array is decoded whole before its elements are passed to `fn`
+ */
+func DecodeArray[E any](r io.Reader, fn func(E) error) error {
+	decoder := JSONCodec().NewDecoder(r)
+	dec, ok := decoder.(tokenDecoder)
+	if !ok {
+		var elements []E
+		if err := decoder.Decode(&elements); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		for _, element := range elements {
+			if err := fn(element); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	token, err := dec.Token()
+	switch {
+	case errors.Is(err, io.EOF), err == nil && token == nil:
+		return nil
+	case err != nil:
+		return err
+	case token != json.Delim('['):
+		return fmt.Errorf("expected a JSON array, got %v", token)
+	}
+
+	for dec.More() {
+		var element E
+		if err := dec.Decode(&element); err != nil {
+			return err
+		}
+		if err := fn(element); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+/*
+ * # Log a Streamed Body
+ * Returns `body`, copying what is read of it to `l` at DEBUG once it is closed, as `DoRequest` callers log theirs
+ * - The body is only buffered when `l` logs DEBUG
+ */
+func LogBody(l *log.Logger, body io.ReadCloser) io.ReadCloser {
+	if l == nil || !l.Enabled(log.DEBUG) {
+		return body
+	}
+	logged := &loggedBody{ReadCloser: body, log: l}
+	logged.reader = io.TeeReader(body, &logged.buffer)
+	return logged
+}
+
+type loggedBody struct {
+	io.ReadCloser
+	reader io.Reader
+	buffer bytes.Buffer
+	log    *log.Logger
+}
+
+func (b *loggedBody) Read(p []byte) (int, error) {
+	return b.reader.Read(p)
+}
+
+func (b *loggedBody) Close() error {
+	b.log.Debug("Response Body:", b.buffer.String())
+	return b.ReadCloser.Close()
+}
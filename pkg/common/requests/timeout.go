@@ -0,0 +1,149 @@
+// pkg/common/requests/timeout.go
+package requests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrTimeout is returned by an attempt which did not complete within its timeout; it is retried like a network failure
+var ErrTimeout = errors.New("requests: attempt timed out")
+
+/*
+ * # Timeouts
+ * Time each attempt of a call may take, from sending the request to reading its response body, by kind of operation
+ * - A zero field uses the timeout of `DefaultTimeouts`, and a negative one removes the limit
+ * - An attempt which times out is retried, while the call's context, or a `Deadline`, bounds the call as a whole
+ * - Rate limit waits and the backoff between retries do not count against the timeout
+ */
+type Timeouts struct {
+	Read     time.Duration // `GET`, `HEAD` and `OPTIONS` requests of `DoRequest`
+	Write    time.Duration // Other requests of `DoRequest`, e.g. creating or updating resources
+	Stream   time.Duration // Requests of `DoStream`, including reading their body, e.g. report exports
+	Download time.Duration // Downloads of `DownloadFile`
+}
+
+// DefaultTimeouts are the timeouts of the clients which do not set theirs with `WithTimeouts`
+var DefaultTimeouts = Timeouts{
+	Read:     time.Minute,
+	Write:    2 * time.Minute,
+	Stream:   30 * time.Minute,
+	Download: 2 * time.Hour,
+}
+
+// WithTimeouts sets the timeouts of the client's calls, e.g. `Timeouts{Read: 10 * time.Second}` for an interactive tool
+func WithTimeouts(t Timeouts) Option {
+	return func(c *Client) {
+		c.Timeouts = t
+	}
+}
+
+// CallOption configures a single call of a client, e.g. `DoRequest`
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	timeout  time.Duration
+	deadline time.Time
+}
+
+// Timeout bounds each attempt of the call to `d`, in place of the client's `Timeouts`; negative removes the limit
+func Timeout(d time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = d
+	}
+}
+
+// Deadline bounds the call as a whole, including its retries, to `t`
+func Deadline(t time.Time) CallOption {
+	return func(o *callOptions) {
+		o.deadline = t
+	}
+}
+
+// resolve returns the timeout of `kind`, the default's when zero, or zero when negative
+func resolve(kind, fallback time.Duration) time.Duration {
+	switch {
+	case kind < 0:
+		return 0
+	case kind == 0:
+		return fallback
+	default:
+		return kind
+	}
+}
+
+// timeoutOf returns the timeout of each attempt of a `DoRequest` with `method`
+func (c *Client) timeoutOf(method string) time.Duration {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return resolve(c.Timeouts.Read, DefaultTimeouts.Read)
+	default:
+		return resolve(c.Timeouts.Write, DefaultTimeouts.Write)
+	}
+}
+
+/*
+ * Returns a copy of the client bound to the options of a call, whose attempts time out after `timeout` unless the
+ * options set their own, and the function releasing it once the call is done
+ */
+func (c *Client) bind(timeout time.Duration, opts []CallOption) (*Client, context.CancelFunc) {
+	o := callOptions{timeout: timeout}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	ctx, cancel := c.Context(), context.CancelFunc(func() {})
+	if !o.deadline.IsZero() {
+		ctx, cancel = context.WithDeadline(ctx, o.deadline)
+	}
+	bound := c.WithContext(ctx)
+	bound.timeout = max(o.timeout, 0)
+	return bound, cancel
+}
+
+/*
+ * Bounds an attempt of `req` by the timeout of the call, returning the request and the function releasing it
+ * - The attempt is released once its response body is closed, so the body of a stream is read within the timeout
+ */
+func (c *Client) attempt(req *http.Request) (*http.Request, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return req, func() {}
+	}
+	ctx, cancel := context.WithTimeoutCause(req.Context(), c.timeout, ErrTimeout)
+	return req.WithContext(ctx), cancel
+}
+
+// timedOut returns `err` as an `ErrTimeout` when the attempt of `req` timed out, rather than the call's context being done
+func (c *Client) timedOut(req *http.Request, err error) error {
+	if err == nil || !errors.Is(context.Cause(req.Context()), ErrTimeout) || c.Context().Err() != nil {
+		return err
+	}
+	return fmt.Errorf("%w after %v: %s %s", ErrTimeout, c.timeout, req.Method, req.URL.Redacted())
+}
+
+// attemptBody releases the attempt of a response once its body is closed, reporting a read which timed out as `ErrTimeout`
+type attemptBody struct {
+	io.ReadCloser
+	client *Client
+	req    *http.Request
+	cancel context.CancelFunc
+}
+
+func (b *attemptBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = b.client.timedOut(b.req, err)
+	}
+	return n, err
+}
+
+func (b *attemptBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,93 @@
+// pkg/common/requests/tls.go
+package requests
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+/*
+ * # With Client Certificate
+ * Presents `cert` to the servers which request one, for mutual TLS, e.g. with the key pair of `tls.LoadX509KeyPair`
+ * - Certificates of several options are all presented, the first matching the server's request being sent
+ */
+func WithClientCertificate(cert tls.Certificate) Option {
+	return tune(func(t *http.Transport) {
+		config := tlsConfig(t)
+		config.Certificates = append(config.Certificates, cert)
+	})
+}
+
+// WithClientCertificateFiles presents the PEM certificate and key of `certFile` and `keyFile`; unreadable files fail each request
+func WithClientCertificateFiles(certFile, keyFile string) Option {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fail(fmt.Errorf("loading client certificate: %w", err))
+	}
+	return WithClientCertificate(cert)
+}
+
+// WithClientCertificatePEM presents the PEM encoded certificate and key, e.g. read from a secret manager; invalid PEM fails each request
+func WithClientCertificatePEM(certPEM, keyPEM []byte) Option {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return fail(fmt.Errorf("loading client certificate: %w", err))
+	}
+	return WithClientCertificate(cert)
+}
+
+/*
+ * # With Root CAs
+ * Verifies the certificates of the servers with `pool` only, in place of the system's roots, e.g. for internal services
+ * signed by a private CA
+ */
+func WithRootCAs(pool *x509.CertPool) Option {
+	return tune(func(t *http.Transport) {
+		tlsConfig(t).RootCAs = pool
+	})
+}
+
+// WithCAFile trusts the PEM certificates of `path` in addition to the system's roots; an unreadable file fails each request
+func WithCAFile(path string) Option {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fail(fmt.Errorf("loading CA certificates: %w", err))
+	}
+	return WithCAPEM(data)
+}
+
+// WithCAPEM trusts the PEM encoded certificates in addition to the system's roots; PEM without a certificate fails each request
+func WithCAPEM(pem []byte) Option {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return fail(errors.New("loading CA certificates: no certificate found in PEM"))
+	}
+	return WithRootCAs(pool)
+}
+
+// tlsConfig returns the TLS configuration of `t`, which `tune` cloned with it, creating one when unset
+func tlsConfig(t *http.Transport) *tls.Config {
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return t.TLSClientConfig
+}
+
+// fail returns an option failing each request of the client with `err`, rather than sending it with a weaker configuration
+func fail(err error) Option {
+	return func(c *Client) {
+		c.Log.Error(err)
+		c.Use(BeforeRequest(func(*http.Request) error {
+			return retry.Permanent(err)
+		}))
+	}
+}
@@ -0,0 +1,130 @@
+// pkg/common/requests/token.go
+package requests
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// TokenRefreshWindow is how long before it expires a bearer token is replaced, so no request is sent with one about to expire
+var TokenRefreshWindow = 5 * time.Minute
+
+/*
+ * # With Token Source
+ * Sends each request with the bearer token of `ts` in its `Authorization` header, in place of a token frozen in `Headers`
+ * - The token is reused until it is within `TokenRefreshWindow` of its expiry, then replaced with another from `ts`
+ * - A token the API rejects is replaced before the request is retried once, unless the client has a `Reauthenticate`
+ *   hook of its own; sources with a `Renew` method (e.g. `auth.RenewableTokenSource`) are asked for a new token
+ * - `ts` may be shared between clients, e.g. an `oauth2.Config.TokenSource` of a service account
+ * - Name the subject of the tokens with `WithSubject`, so responses are shared across restarts; without one, the client
+ *   shares none with other clients
+ */
+func WithTokenSource(ts oauth2.TokenSource) Option {
+	return func(c *Client) {
+		c.UseTokenSource(ts)
+	}
+}
+
+// UseTokenSource sends the client's requests with the bearer tokens of `ts`, like `WithTokenSource`; it must not be called while the client sends requests
+func (c *Client) UseTokenSource(ts oauth2.TokenSource) {
+	b := &bearer{source: ts}
+	c.Use(b.middleware)
+	if c.Subject == "" {
+		id := make([]byte, 8)
+		rand.Read(id)
+		c.Subject = "token:" + hex.EncodeToString(id)
+	}
+	if c.Reauthenticate == nil {
+		c.Reauthenticate = func(*Client) error {
+			_, err := b.renew()
+			return err
+		}
+	}
+}
+
+// renewer is a token source which can replace its token before it expires
+type renewer interface {
+	Renew() (*oauth2.Token, error)
+}
+
+// bearer caches the token of a source for the requests of a client and of its copies
+type bearer struct {
+	mutex     sync.Mutex
+	source    oauth2.TokenSource
+	token     *oauth2.Token
+	refreshAt time.Time // When the token is replaced; `TokenRefreshWindow` before its expiry, or halfway for short-lived ones
+}
+
+func (b *bearer) middleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		token, err := b.get()
+		if err != nil {
+			return nil, fmt.Errorf("fetching token: %w", err)
+		}
+		req.Header.Set("Authorization", token.Type()+" "+token.AccessToken)
+		return next.RoundTrip(req)
+	})
+}
+
+// get returns the cached token, replacing it once it is due
+func (b *bearer) get() (*oauth2.Token, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.fresh() {
+		return b.token, nil
+	}
+
+	token, err := b.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	// Sources which cache their token return it until it has expired; those which can renew it are asked to early
+	if r, ok := b.source.(renewer); ok && b.token != nil && token.AccessToken == b.token.AccessToken {
+		if renewed, err := r.Renew(); err == nil {
+			token = renewed
+		}
+	}
+	b.set(token)
+	return token, nil
+}
+
+// renew replaces the cached token, after the API rejected it
+func (b *bearer) renew() (*oauth2.Token, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var token *oauth2.Token
+	var err error
+	if r, ok := b.source.(renewer); ok {
+		token, err = r.Renew()
+	} else {
+		token, err = b.source.Token()
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.set(token)
+	return token, nil
+}
+
+func (b *bearer) set(token *oauth2.Token) {
+	b.token = token
+	b.refreshAt = time.Time{}
+	if !token.Expiry.IsZero() {
+		b.refreshAt = token.Expiry.Add(-min(TokenRefreshWindow, time.Until(token.Expiry)/2))
+	}
+}
+
+// fresh reports whether the cached token can be sent, and is not due to be replaced
+func (b *bearer) fresh() bool {
+	if b.token == nil || b.token.AccessToken == "" {
+		return false
+	}
+	return b.token.Expiry.IsZero() || time.Now().Before(b.refreshAt)
+}
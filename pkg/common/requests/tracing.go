@@ -0,0 +1,167 @@
+// pkg/common/requests/tracing.go
+package requests
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+/*
+ * # Tracer
+ * Starts a span for each call of a client (`DoRequest` or `DoStream`), including its retries, so slow operations can be
+ * correlated with the APIs they called; an OpenTelemetry tracer is adapted in a few lines:
+ *
+ *	type otelTracer struct{ trace.Tracer }
+ *
+ *	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, requests.Span) {
+ *		ctx, span := t.Tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+ *		return ctx, otelSpan{span}
+ *	}
+ *
+ * - Spans are named after the method and URL template of the call, e.g. `GET /api/v1/users/{id}`
+ * - Their attributes follow OpenTelemetry's HTTP conventions, see the `Attr` constants
+ */
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is the span of a call, ended once the call returns; the span of `DoStream` ends before its body is read
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Attribute is an attribute of a span; its value is a string, an int, a float64 or a bool
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attributes of the spans of each call
+const (
+	AttrMethod        = "http.request.method"       // Method of the request, e.g. `GET`
+	AttrURLTemplate   = "url.template"              // Path of the request, with its identifiers replaced by `{id}`
+	AttrServerAddress = "server.address"            // Host of the API
+	AttrStatusCode    = "http.response.status_code" // Status of the last response, when one was received
+	AttrResendCount   = "http.request.resend_count" // Times the request was retried, or resent after reauthenticating
+	AttrRateLimitWait = "rego.rate_limit.wait"      // Seconds spent waiting on the rate limiter
+)
+
+var tracer atomic.Pointer[Tracer]
+
+// SetTracer sets the tracer of every client without one of its own (see `WithTracer`), e.g. at startup; nil stops tracing
+func SetTracer(t Tracer) {
+	if t == nil {
+		tracer.Store(nil)
+		return
+	}
+	tracer.Store(&t)
+}
+
+// WithTracer traces the client's calls with `t`, in place of the tracer set with `SetTracer`
+func WithTracer(t Tracer) Option {
+	return func(c *Client) {
+		c.Tracer = t
+	}
+}
+
+// tracerOf returns the tracer of the client, if any
+func (c *Client) tracerOf() Tracer {
+	if c.Tracer != nil {
+		return c.Tracer
+	}
+	if t := tracer.Load(); t != nil {
+		return *t
+	}
+	return nil
+}
+
+// call records what happened during a traced call, across its attempts
+type call struct {
+	attempts      atomic.Int64
+	status        atomic.Int64
+	rateLimitWait atomic.Int64 // Nanoseconds
+}
+
+type callKey struct{}
+
+// callOf returns the call traced in `ctx`, or nil
+func callOf(ctx context.Context) *call {
+	tc, _ := ctx.Value(callKey{}).(*call)
+	return tc
+}
+
+/*
+ * Starts the span of a call, returning a copy of the client bound to the span's context, and the function ending it
+ * - Without a tracer, the client itself is returned
+ */
+func (c *Client) trace(method, rawURL string) (*Client, func(resp *http.Response, err error)) {
+	t := c.tracerOf()
+	if t == nil {
+		return c, func(*http.Response, error) {}
+	}
+
+	template, host := URLTemplate(rawURL)
+	ctx, span := t.Start(c.Context(), method+" "+template)
+	tc := &call{}
+	traced := c.WithContext(context.WithValue(ctx, callKey{}, tc))
+
+	return traced, func(resp *http.Response, err error) {
+		attrs := []Attribute{
+			{AttrMethod, method},
+			{AttrURLTemplate, template},
+			{AttrServerAddress, host},
+			{AttrResendCount, int(max(tc.attempts.Load()-1, 0))},
+			{AttrRateLimitWait, time.Duration(tc.rateLimitWait.Load()).Seconds()},
+		}
+		if status := tc.status.Load(); status != 0 {
+			attrs = append(attrs, Attribute{AttrStatusCode, int(status)})
+		}
+		span.SetAttributes(attrs...)
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}
+}
+
+// identifierSegment matches the path segments which are identifiers, rather than part of an API's routes
+var identifierSegment = regexp.MustCompile(`^(\d+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|.*@.*|.*[0-9].*[0-9].*[0-9].*)$`)
+
+// versionSegment matches the path segments naming a version of an API, e.g. `v1` or `directory_v1.1beta1`
+var versionSegment = regexp.MustCompile(`(^|[_.])v\d+(\.\d+)?([a-z]+\d*)?$`)
+
+/*
+ * # URL Template
+ * Returns the path of `rawURL` with its identifiers replaced by `{id}`, e.g. `/api/v1/users/{id}/groups`, and its host
+ * - Identifiers are recognized by their shape: numbers, UUIDs, emails, and opaque IDs with several digits (e.g. Okta's `00u1a2b3c`)
+ * - Custom methods are kept, e.g. `/v4/spreadsheets/{id}:batchUpdate`
+ * - The query is dropped, so the template bounds the cardinality of the spans
+ */
+func URLTemplate(rawURL string) (template string, host string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", ""
+	}
+	segments := strings.Split(u.EscapedPath(), "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		// Custom methods, e.g. Google's `{id}:batchUpdate`, are kept
+		id, verb := segment, ""
+		if j := strings.LastIndex(segment, ":"); j > 0 {
+			id, verb = segment[:j], segment[j:]
+		}
+		if id != "" && !versionSegment.MatchString(id) && identifierSegment.MatchString(id) {
+			segments[i] = "{id}" + verb
+		}
+	}
+	return strings.Join(segments, "/"), u.Host
+}
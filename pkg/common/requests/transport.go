@@ -0,0 +1,212 @@
+// pkg/common/requests/transport.go
+package requests
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Option configures a Client when it is generated with `NewClient`
+type Option func(*Client)
+
+/*
+ * # With Max Idle Connections per Host
+ * Number of idle connections kept open to each host, so bulk syncs against a single API reuse them instead of redialing
+ * - Go defaults to 2; the total idle limit is raised to match when it is lower
+ */
+func WithMaxIdleConnsPerHost(n int) Option {
+	return tune(func(t *http.Transport) {
+		setMaxIdleConnsPerHost(t, n)
+	})
+}
+
+func setMaxIdleConnsPerHost(t *http.Transport, n int) {
+	t.MaxIdleConnsPerHost = n
+	if t.MaxIdleConns != 0 && t.MaxIdleConns < n {
+		t.MaxIdleConns = n
+	}
+}
+
+// WithMaxConnsPerHost limits the connections to each host, including those in use; zero means no limit
+func WithMaxConnsPerHost(n int) Option {
+	return tune(func(t *http.Transport) {
+		t.MaxConnsPerHost = n
+	})
+}
+
+/*
+ * # With HTTP/2
+ * Enables or disables HTTP/2 over TLS
+ * - Enabled, a single connection multiplexes many concurrent requests to the same host
+ * - Disabled, requests are sent over HTTP/1.1 connections, e.g. for APIs whose HTTP/2 support is unreliable
+ */
+func WithHTTP2(enabled bool) Option {
+	return tune(func(t *http.Transport) {
+		setHTTP2(t, enabled)
+	})
+}
+
+func setHTTP2(t *http.Transport, enabled bool) {
+	t.ForceAttemptHTTP2 = enabled
+	if enabled {
+		t.TLSNextProto = nil
+		return
+	}
+
+	t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	if t.TLSClientConfig != nil {
+		// Otherwise `h2` could still be negotiated with servers, which would then not understand HTTP/1.1
+		protos := []string{}
+		for _, p := range t.TLSClientConfig.NextProtos {
+			if p != "h2" {
+				protos = append(protos, p)
+			}
+		}
+		t.TLSClientConfig.NextProtos = protos
+	}
+}
+
+/*
+ * # With Keep-Alive
+ * Interval of the TCP keep-alive probes of new connections
+ * - A negative interval disables keep-alives, so each request opens a new connection
+ */
+func WithKeepAlive(interval time.Duration) Option {
+	return tune(func(t *http.Transport) {
+		setKeepAlive(t, interval)
+	})
+}
+
+func setKeepAlive(t *http.Transport, interval time.Duration) {
+	if interval < 0 {
+		t.DisableKeepAlives = true
+		return
+	}
+	t.DisableKeepAlives = false
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: interval,
+	}
+	t.DialContext = dialer.DialContext
+}
+
+// WithIdleConnTimeout closes connections which have been idle for longer than `d`; zero means no limit
+func WithIdleConnTimeout(d time.Duration) Option {
+	return tune(func(t *http.Transport) {
+		t.IdleConnTimeout = d
+	})
+}
+
+// Protocols of `Transport.Protocol`
+const (
+	HTTP1 = "HTTP/1.1"
+	HTTP2 = "HTTP/2"
+)
+
+/*
+ * # Transport
+ * Settings of the connections of a client, applied together by `WithTransport`, or to the HTTP client of a provider
+ * (including its OAuth 2.0 token requests) by `TransportClient`, e.g. for a large paginated sync against a single host:
+ *
+ * ```go
+ *	requests.Transport{MaxIdleConnsPerHost: 50, IdleConnTimeout: 90 * time.Second, Protocol: requests.HTTP2}
+ * ```
+ *
+ * - The zero value of each field keeps the transport's setting
+ */
+type Transport struct {
+	MaxIdleConnsPerHost int           // Idle connections kept open to each host, see `WithMaxIdleConnsPerHost`
+	MaxConnsPerHost     int           // Connections to each host, including those in use
+	IdleConnTimeout     time.Duration // Time after which idle connections are closed
+	KeepAlive           time.Duration // Interval of the TCP keep-alive probes of new connections
+	DisableKeepAlives   bool          // Opens a new connection for each request
+	Protocol            string        // `HTTP2` or `HTTP1` to force the protocol of TLS connections, see `WithHTTP2`; negotiated when empty
+}
+
+// apply sets the settings of `t`
+func (s Transport) apply(t *http.Transport) {
+	if s.MaxIdleConnsPerHost > 0 {
+		setMaxIdleConnsPerHost(t, s.MaxIdleConnsPerHost)
+	}
+	if s.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = s.MaxConnsPerHost
+	}
+	if s.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = s.IdleConnTimeout
+	}
+	if s.KeepAlive > 0 {
+		setKeepAlive(t, s.KeepAlive)
+	}
+	if s.DisableKeepAlives {
+		t.DisableKeepAlives = true
+	}
+	switch s.Protocol {
+	case HTTP2:
+		setHTTP2(t, true)
+	case HTTP1:
+		setHTTP2(t, false)
+	}
+}
+
+// WithTransport applies the settings of `s` to the client's transport
+func WithTransport(s Transport) Option {
+	return tune(s.apply)
+}
+
+/*
+ * # Transport Client
+ * Returns a copy of `hc` with the settings of `s` applied to its transport; a nil `hc` copies `http.DefaultClient`
+ * - A client whose transport is not an `*http.Transport`, e.g. a test transport, is returned as it is
+ */
+func TransportClient(hc *http.Client, s Transport) *http.Client {
+	return tuneClient(hc, s.apply)
+}
+
+// tuneClient returns a copy of `hc` whose cloned transport is changed by `fn`, or `hc` when it cannot be tuned
+func tuneClient(hc *http.Client, fn func(t *http.Transport)) *http.Client {
+	if hc == nil {
+		hc = &http.Client{}
+	}
+	rt := hc.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return hc
+	}
+	t = t.Clone()
+	fn(t)
+
+	tuned := *hc
+	tuned.Transport = t
+	return &tuned
+}
+
+/*
+ * Returns an option which changes the client's transport
+ * - The transport is cloned, so neither `http.DefaultTransport` nor the transport of a client passed to `NewClient` is changed
+ * - Transports other than `*http.Transport`, e.g. of OAuth clients, cannot be tuned and are left as they are
+ */
+func tune(fn func(t *http.Transport)) Option {
+	return func(c *Client) {
+		hc := *c.httpClient
+		rt := hc.Transport
+		if rt == nil {
+			rt = http.DefaultTransport
+		}
+
+		t, ok := rt.(*http.Transport)
+		if !ok {
+			c.Log.Warningf("Unable to tune transport %T", rt)
+			return
+		}
+		t = t.Clone()
+		fn(t)
+
+		hc.Transport = t
+		c.httpClient = &hc
+	}
+}
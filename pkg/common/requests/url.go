@@ -0,0 +1,49 @@
+// pkg/common/requests/url.go
+package requests
+
+import (
+	"net/url"
+	"strings"
+)
+
+/*
+ * # Escape a Path Segment
+ * Escapes `segment` to be a single segment of a URL path, whatever it contains, e.g. an email or a group name
+ * - `/`, `?`, `#` and `%` are escaped, so an identifier never adds segments, a query or a fragment
+ * - `+` is escaped, as some APIs decode it as a space
+ * - `.` and `..` are escaped, so an identifier never walks up the path
+ */
+func EscapePathSegment(segment string) string {
+	switch segment {
+	case ".":
+		return "%2E"
+	case "..":
+		return "%2E%2E"
+	}
+	return strings.ReplaceAll(url.PathEscape(segment), "+", "%2B")
+}
+
+/*
+ * # Join a URL
+ * Appends `segments` to the path of `base`, each escaped with `EscapePathSegment`
+ * - The query of `base`, if any, is kept after the path
+ * - A `base` which cannot be parsed is joined as a string
+ */
+func JoinURL(base string, segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = EscapePathSegment(segment)
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return strings.Join(append([]string{base}, escaped...), "/")
+	}
+
+	path := strings.Join(append([]string{u.EscapedPath()}, escaped...), "/")
+	if u.Path, err = url.PathUnescape(path); err != nil {
+		return strings.Join(append([]string{base}, escaped...), "/")
+	}
+	u.RawPath = path
+	return u.String()
+}
@@ -0,0 +1,105 @@
+// pkg/common/requests/useragent.go
+package requests
+
+import (
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+)
+
+const module = "github.com/gemini-oss/rego"
+
+/*
+ * # User-Agent
+ * Identifies rego to the APIs it calls, which attribute abuse and diagnose support cases by it (e.g. Okta and Google)
+ * - Each client appends its own suffix, set with `WithUserAgent`, e.g. `rego/v1.2.0 (+https://github.com/gemini-oss/rego) okta`
+ * - A `User-Agent` in the headers of a client replaces it entirely
+ */
+type UserAgent struct {
+	Product string // Name of the product, e.g. `rego` or that of the application embedding it
+	Version string // Version of the product; the version of the rego module when empty
+	Contact string // URL or email of the maintainers of the product
+}
+
+// String formats the User-Agent as `product/version (+contact)`
+func (ua UserAgent) String() string {
+	s := ua.Product
+	if ua.Version != "" {
+		s += "/" + ua.Version
+	}
+	if ua.Contact != "" {
+		s += " (+" + ua.Contact + ")"
+	}
+	return s
+}
+
+// DefaultUserAgent identifies rego, at the version of the module it was built with
+var DefaultUserAgent = UserAgent{Product: "rego", Version: moduleVersion(), Contact: "https://" + module}
+
+var userAgent atomic.Pointer[UserAgent]
+
+// SetUserAgent sets the User-Agent of every client, e.g. at startup; the empty fields of `ua` keep those of `DefaultUserAgent`
+func SetUserAgent(ua UserAgent) {
+	if ua.Product == "" {
+		ua.Product = DefaultUserAgent.Product
+	}
+	if ua.Version == "" {
+		ua.Version = DefaultUserAgent.Version
+	}
+	if ua.Contact == "" {
+		ua.Contact = DefaultUserAgent.Contact
+	}
+	userAgent.Store(&ua)
+}
+
+// CurrentUserAgent returns the User-Agent set with `SetUserAgent`, or `DefaultUserAgent`
+func CurrentUserAgent() UserAgent {
+	if ua := userAgent.Load(); ua != nil {
+		return *ua
+	}
+	return DefaultUserAgent
+}
+
+// WithUserAgent appends `suffixes` to the User-Agent of the client, e.g. the provider it calls or the application using it
+func WithUserAgent(suffixes ...string) Option {
+	return func(client *Client) {
+		for _, suffix := range suffixes {
+			if suffix = strings.TrimSpace(suffix); suffix != "" {
+				client.UserAgent = strings.TrimSpace(client.UserAgent + " " + suffix)
+			}
+		}
+	}
+}
+
+// userAgentOf returns the User-Agent of the requests of `c`
+func (c *Client) userAgentOf() string {
+	ua := CurrentUserAgent().String()
+	if c.UserAgent != "" {
+		ua += " " + c.UserAgent
+	}
+	return ua
+}
+
+// moduleVersion returns the version of the rego module in the build, e.g. `v1.2.0`, or `dev` when built from its source
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	version := info.Main.Version
+	if info.Main.Path != module {
+		version = ""
+		for _, dep := range info.Deps {
+			if dep.Path == module {
+				version = dep.Version
+				if dep.Replace != nil {
+					version = dep.Replace.Version
+				}
+			}
+		}
+	}
+	if version == "" || version == "(devel)" {
+		return "dev"
+	}
+	return version
+}
@@ -2,6 +2,10 @@
 package retry
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gemini-oss/rego/pkg/common/crypt"
@@ -25,34 +29,192 @@ func (RealTime) Sleep(duration time.Duration) {
 
 // BackoffWithJitter returns a duration for exponential backoff with jitter using a secure random source
 func BackoffWithJitter(retryCount int) time.Duration {
-	backoff := MinBackoff * (1 << retryCount)
-	if backoff > MaxBackoff {
-		backoff = MaxBackoff
+	return Backoff(retryCount, MinBackoff*time.Millisecond, MaxBackoff*time.Millisecond)
+}
+
+// Backoff returns a duration for exponential backoff with jitter between `min` and `max`, using a secure random source
+func Backoff(retryCount int, min, max time.Duration) time.Duration {
+	backoff := min << retryCount
+	if backoff > max || backoff < min {
+		backoff = max
 	}
 
-	jitter, err := crypt.SecureRandomInt(backoff)
+	jitter, err := crypt.SecureRandomInt(int(backoff / time.Millisecond))
 	if err != nil {
 		// Handle the error or default to a non-jittered backoff
-		return time.Duration(backoff) * time.Millisecond
+		return backoff
 	}
 
-	// Ensuring jitter is within the MinBackoff and backoff range
-	if jitter < MinBackoff {
-		jitter = MinBackoff
+	// Ensuring jitter is within the min and backoff range
+	if d := time.Duration(jitter) * time.Millisecond; d > min {
+		return d
 	}
+	return min
+}
+
+/*
+ * # Retry Policy
+ * Configures how many times an operation is attempted, how long to back off between attempts, and which failures are retried
+ * - The zero value of each field keeps the default: `MaxRetries` attempts, backing off from `MinBackoff` to `MaxBackoff`
+ *   milliseconds with jitter, retrying every failure
+ * - Errors marked with `Permanent` or `After` take precedence over the policy
+ */
+type Policy struct {
+	MaxAttempts int                  // Attempts of an operation, including the first
+	MinBackoff  time.Duration        // Backoff before the first retry, doubled before each of the next
+	MaxBackoff  time.Duration        // Longest backoff
+	NoJitter    bool                 // Back off exactly; otherwise each backoff is randomized below its exponential value, so clients do not retry in lockstep
+	Retryable   func(err error) bool // Whether a failed attempt is retried, e.g. only on transient failures
+	OnAttempt   func(a Attempt)      // Called after each attempt, e.g. to log the failures which are retried
+}
+
+// Attempt is an attempt of an operation, passed to `Policy.OnAttempt`
+type Attempt struct {
+	Number  int           // Attempt, counted from 1
+	Err     error         // Failure of the attempt; nil when it succeeded
+	Backoff time.Duration // Backoff before the next attempt; zero when there is none
+	Last    bool          // No other attempt follows, as the operation succeeded, failed permanently or ran out of attempts
+}
 
-	return time.Duration(jitter) * time.Millisecond
+// Idempotent reports whether sending a request of `method` several times has the effect of sending it once, so it
+// may be retried after any failure; POST and PATCH are not
+func Idempotent(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// DefaultPolicy retries every failure `MaxRetries` times, backing off from `MinBackoff` to `MaxBackoff` milliseconds with jitter
+var DefaultPolicy = Policy{MaxAttempts: MaxRetries, MinBackoff: MinBackoff * time.Millisecond, MaxBackoff: MaxBackoff * time.Millisecond}
+
+// withDefaults returns the policy, with its zero fields set to those of `DefaultPolicy`
+func (p Policy) withDefaults() Policy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultPolicy.MaxAttempts
+	}
+	if p.MinBackoff <= 0 {
+		p.MinBackoff = DefaultPolicy.MinBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultPolicy.MaxBackoff
+	}
+	if p.MaxBackoff < p.MinBackoff {
+		p.MaxBackoff = p.MinBackoff
+	}
+	return p
+}
+
+// Backoff returns how long the policy backs off before retry `retryCount` (counted from 0)
+func (p Policy) Backoff(retryCount int) time.Duration {
+	p = p.withDefaults()
+	if p.NoJitter {
+		backoff := p.MinBackoff << retryCount
+		if backoff > p.MaxBackoff || backoff < p.MinBackoff {
+			backoff = p.MaxBackoff
+		}
+		return backoff
+	}
+	return Backoff(retryCount, p.MinBackoff, p.MaxBackoff)
+}
+
+// decision overrides how the failed attempt wrapping `err` is retried
+type decision struct {
+	err     error
+	stop    bool
+	backoff time.Duration
+}
+
+func (d *decision) Error() string { return d.err.Error() }
+func (d *decision) Unwrap() error { return d.err }
+
+// Permanent marks an error which is not worth retrying; the retries stop and return it at once
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &decision{err: err, stop: true}
+}
+
+// After marks an error to be retried after `backoff`, in place of the default backoff
+func After(err error, backoff time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &decision{err: err, backoff: backoff}
+}
+
+// decide unwraps the decision of a failed attempt, if any, returning the error, whether to stop, and the backoff
+func (p Policy) decide(err error, retryCount int) (error, bool, time.Duration) {
+	var d *decision
+	if !errors.As(err, &d) {
+		stop := p.Retryable != nil && !p.Retryable(err)
+		return err, stop, p.Backoff(retryCount)
+	}
+	if d.backoff <= 0 {
+		return d.err, d.stop, p.Backoff(retryCount)
+	}
+	return d.err, d.stop, d.backoff
 }
 
 // Retry retries the given operation up to MaxRetries times, with exponential backoff and jitter
-func Retry(operation func() error, time Time) error {
+// - Errors marked with `Permanent` stop the retries, and those marked with `After` set their own backoff
+func Retry(operation func() error, clock Time) error {
+	return DefaultPolicy.Retry(context.Background(), operation, clock)
+}
+
+// RetryContext retries like Retry, stopping as soon as `ctx` is done, including while backing off
+func RetryContext(ctx context.Context, operation func() error, clock Time) error {
+	return DefaultPolicy.Retry(ctx, operation, clock)
+}
+
+// Retry attempts the operation up to `MaxAttempts` times, backing off between attempts, and stopping as soon as `ctx` is done
+func (p Policy) Retry(ctx context.Context, operation func() error, clock Time) error {
+	p = p.withDefaults()
 	var err error
-	for i := 0; i < MaxRetries; i++ {
+	for i := 0; i < p.MaxAttempts; i++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			if err == nil {
+				err = ctxErr
+			}
+			return err
+		}
 		err = operation()
 		if err == nil {
+			p.report(Attempt{Number: i + 1, Last: true})
 			return nil
 		}
-		time.Sleep(BackoffWithJitter(i))
+		var stop bool
+		var backoff time.Duration
+		if err, stop, backoff = p.decide(err, i); stop || ctx.Err() != nil {
+			p.report(Attempt{Number: i + 1, Err: err, Last: true})
+			return err
+		}
+		if i == p.MaxAttempts-1 {
+			p.report(Attempt{Number: i + 1, Err: err, Last: true})
+			return err
+		}
+		p.report(Attempt{Number: i + 1, Err: err, Backoff: backoff})
+
+		if _, ok := clock.(RealTime); !ok {
+			clock.Sleep(backoff)
+			continue
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
 	}
 	return err
 }
+
+// report passes an attempt to `OnAttempt`, when set
+func (p Policy) report(a Attempt) {
+	if p.OnAttempt != nil {
+		p.OnAttempt(a)
+	}
+}
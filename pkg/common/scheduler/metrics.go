@@ -0,0 +1,85 @@
+// pkg/common/scheduler/metrics.go
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Prefix of every metric exposed by the scheduler
+const MetricsPrefix = "rego_scheduler"
+
+/*
+ * # Write Metrics
+ * Writes the status of every job in the Prometheus text exposition format, e.g.
+ *
+ *	rego_scheduler_job_runs_total{job="inventory.refresh"} 42
+ *	rego_scheduler_job_last_success_timestamp_seconds{job="inventory.refresh"} 1.7e+09
+ */
+func (s *Scheduler) WriteMetrics(w io.Writer) error {
+	statuses := s.Status()
+
+	metrics := []struct {
+		name, kind, help string
+		value            func(JobStatus) float64
+	}{
+		{"job_running", "gauge", "Number of runs of the job in progress.", func(j JobStatus) float64 { return float64(j.Running) }},
+		{"job_runs_total", "counter", "Number of completed runs of the job.", func(j JobStatus) float64 { return float64(j.Runs) }},
+		{"job_failures_total", "counter", "Number of runs of the job which failed or timed out.", func(j JobStatus) float64 { return float64(j.Failures) }},
+		{"job_skipped_total", "counter", "Number of runs of the job skipped because the previous run was still in progress.", func(j JobStatus) float64 { return float64(j.Skipped) }},
+		{"job_last_duration_seconds", "gauge", "Duration of the last run of the job.", func(j JobStatus) float64 { return j.LastDuration.Seconds() }},
+		{"job_last_run_timestamp_seconds", "gauge", "Time the last run of the job ended.", func(j JobStatus) float64 { return unix(j.LastEnd) }},
+		{"job_last_success_timestamp_seconds", "gauge", "Time the last successful run of the job ended.", func(j JobStatus) float64 { return unix(j.LastSuccess) }},
+		{"job_last_run_failed", "gauge", "Whether the last run of the job failed (1) or succeeded (0).", func(j JobStatus) float64 { return boolean(j.LastError != "") }},
+		{"job_next_run_timestamp_seconds", "gauge", "Time of the next scheduled run of the job.", func(j JobStatus) float64 { return unix(j.Next) }},
+	}
+
+	var sb strings.Builder
+	for _, m := range metrics {
+		name := MetricsPrefix + "_" + m.name
+		fmt.Fprintf(&sb, "# HELP %s %s\n# TYPE %s %s\n", name, m.help, name, m.kind)
+		for _, j := range statuses {
+			fmt.Fprintf(&sb, "%s{job=%q} %g\n", name, j.Name, m.value(j))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+/*
+ * # Handler
+ * Serves the status of the scheduler:
+ * - `/metrics`: Prometheus metrics of every job
+ * - `/jobs`: JSON status of every job
+ */
+func (s *Scheduler) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		s.WriteMetrics(w)
+	})
+	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.Status())
+	})
+	return mux
+}
+
+func unix(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixNano()) / 1e9
+}
+
+func boolean(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
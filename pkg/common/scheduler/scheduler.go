@@ -0,0 +1,347 @@
+/*
+# Scheduler
+
+This package runs recurring jobs (inventory refreshes, log exports, drift checks, ...) in a long-lived process:
+
+	s := scheduler.New(log.INFO)
+	s.Add(scheduler.Job{
+		Name:     "inventory.refresh",
+		Schedule: scheduler.MustParse("0 * * * *"),
+		Timeout:  10 * time.Minute,
+		Jitter:   2 * time.Minute,
+		Run:      func(ctx context.Context) error { ... },
+	})
+	s.Start()
+	http.ListenAndServe(":9090", s.Handler()) // `/metrics` and `/jobs`
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/scheduler/scheduler.go
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+)
+
+var (
+	ErrDuplicateJob = errors.New("job already exists")
+	ErrUnknownJob   = errors.New("job does not exist")
+	ErrOverlap      = errors.New("job is already running")
+	ErrStopped      = errors.New("scheduler is stopped")
+)
+
+// ### Scheduler Structs
+// ---------------------------------------------------------------------
+
+// JobFunc is the work of a job; it should return promptly once `ctx` is done
+type JobFunc func(ctx context.Context) error
+
+// Job is a unit of work run on a schedule
+type Job struct {
+	Name         string        // Unique name of the job, e.g. `inventory.refresh`
+	Schedule     Schedule      // When the job runs; see `Parse` and `Every`
+	Run          JobFunc       // The work of the job
+	Timeout      time.Duration // Cancels the context of a run after this long; zero for no timeout
+	Jitter       time.Duration // Delays each run by a random duration up to this long, to spread load on providers
+	AllowOverlap bool          // Start a run even while the previous one is still running; skipped by default
+}
+
+// JobStatus is the state and history of a job, exposed by `Status` and the metrics endpoint
+type JobStatus struct {
+	Name         string        `json:"name"`                  // Name of the job
+	Running      int           `json:"running"`               // Number of runs in progress
+	Runs         int           `json:"runs"`                  // Number of completed runs
+	Failures     int           `json:"failures"`              // Number of completed runs which returned an error (or timed out)
+	Skipped      int           `json:"skipped"`               // Number of runs skipped because the previous run was still in progress
+	LastStart    time.Time     `json:"lastStart,omitempty"`   // Time the last run started
+	LastEnd      time.Time     `json:"lastEnd,omitempty"`     // Time the last run ended
+	LastSuccess  time.Time     `json:"lastSuccess,omitempty"` // Time the last successful run ended
+	LastDuration time.Duration `json:"lastDuration"`          // Duration of the last run
+	LastError    string        `json:"lastError,omitempty"`   // Error of the last run, if it failed
+	Next         time.Time     `json:"next,omitempty"`        // Time of the next scheduled run
+}
+
+type Scheduler struct {
+	Log        *log.Logger
+	OnComplete func(JobStatus) error // Called with the status of a job after each of its runs, e.g. to notify of failures; optional
+
+	mutex   sync.Mutex
+	jobs    map[string]*entry
+	started bool
+	stopped bool
+	stop    chan struct{}      // Closed by `Stop` to end the scheduling loops
+	ctx     context.Context    // Parent context of every run; cancelled when `Stop` gives up waiting
+	cancel  context.CancelFunc // Cancels `ctx`
+	loops   sync.WaitGroup     // Scheduling loops
+	runs    sync.WaitGroup     // Runs in progress
+}
+
+type entry struct {
+	job    Job
+	status JobStatus
+}
+
+// END OF SCHEDULER STRUCTS
+//---------------------------------------------------------------------
+
+// New returns a scheduler without any jobs; call `Start` once jobs are added
+func New(verbosity int) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		Log:    log.NewLogger("{scheduler}", verbosity),
+		jobs:   map[string]*entry{},
+		stop:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Add registers a job; jobs added after `Start` are scheduled immediately
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" {
+		return fmt.Errorf("job has no name")
+	}
+	if job.Schedule == nil {
+		return fmt.Errorf("job %s has no schedule", job.Name)
+	}
+	if job.Run == nil {
+		return fmt.Errorf("job %s has nothing to run", job.Name)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stopped {
+		return ErrStopped
+	}
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("%s: %w", job.Name, ErrDuplicateJob)
+	}
+
+	e := &entry{job: job, status: JobStatus{Name: job.Name}}
+	s.jobs[job.Name] = e
+	if s.started {
+		s.loops.Add(1)
+		go s.loop(e)
+	}
+	return nil
+}
+
+// AddFunc registers a job from a schedule expression; see `Parse`
+func (s *Scheduler) AddFunc(name, spec string, run JobFunc) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+	return s.Add(Job{Name: name, Schedule: schedule, Run: run})
+}
+
+// Start schedules every registered job; it returns immediately
+func (s *Scheduler) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.started || s.stopped {
+		return
+	}
+	s.started = true
+
+	for _, e := range s.jobs {
+		s.loops.Add(1)
+		go s.loop(e)
+	}
+	s.Log.Printf("Scheduler started with %d job(s)", len(s.jobs))
+}
+
+/*
+ * # Stop the Scheduler
+ * - No new runs are started, and runs in progress are given until `ctx` is done to finish
+ * - Once `ctx` is done, the contexts of the remaining runs are cancelled and `ctx.Err()` is returned
+ */
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mutex.Lock()
+	if s.stopped {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.stopped = true
+	close(s.stop)
+	s.mutex.Unlock()
+
+	s.loops.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		s.Log.Println("Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		s.Log.Warning("Scheduler stopped before every job finished; cancelled the remaining runs")
+		return ctx.Err()
+	}
+}
+
+// Trigger runs a job now, outside of its schedule; it fails with `ErrOverlap` if the job is running and may not overlap
+func (s *Scheduler) Trigger(name string) error {
+	s.mutex.Lock()
+	e, ok := s.jobs[name]
+	s.mutex.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%s: %w", name, ErrUnknownJob)
+	}
+	if err := s.dispatch(e); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// Status returns the status of every job, sorted by name
+func (s *Scheduler) Status() []JobStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		statuses = append(statuses, e.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Job returns the status of a single job
+func (s *Scheduler) Job(name string) (JobStatus, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e, ok := s.jobs[name]
+	if !ok {
+		return JobStatus{}, false
+	}
+	return e.status, true
+}
+
+// loop waits for each scheduled time of a job and dispatches a run, until the scheduler is stopped
+func (s *Scheduler) loop(e *entry) {
+	defer s.loops.Done()
+
+	for {
+		next := e.job.Schedule.Next(time.Now())
+		if next.IsZero() {
+			s.Log.Warning(fmt.Sprintf("[%s] schedule has no future runs", e.job.Name))
+			return
+		}
+		if e.job.Jitter > 0 {
+			next = next.Add(rand.N(e.job.Jitter))
+		}
+
+		s.mutex.Lock()
+		e.status.Next = next
+		s.mutex.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.dispatch(e)
+		}
+	}
+}
+
+// dispatch starts a run of a job, unless it would overlap a run in progress or the scheduler is stopped
+func (s *Scheduler) dispatch(e *entry) error {
+	s.mutex.Lock()
+	if s.stopped {
+		s.mutex.Unlock()
+		return ErrStopped
+	}
+	if e.status.Running > 0 && !e.job.AllowOverlap {
+		e.status.Skipped++
+		s.mutex.Unlock()
+		s.Log.Warning(fmt.Sprintf("[%s] skipped; the previous run is still in progress", e.job.Name))
+		return ErrOverlap
+	}
+	e.status.Running++
+	e.status.LastStart = time.Now()
+	s.runs.Add(1)
+	s.mutex.Unlock()
+
+	go s.run(e)
+	return nil
+}
+
+// run executes a job with its timeout, and records the outcome
+func (s *Scheduler) run(e *entry) {
+	defer s.runs.Done()
+
+	ctx, cancel := s.ctx, context.CancelFunc(func() {})
+	if e.job.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(s.ctx, e.job.Timeout)
+	}
+	defer cancel()
+
+	start := time.Now()
+	s.Log.Debug(fmt.Sprintf("[%s] started", e.job.Name))
+	err := call(ctx, e.job.Run)
+	if err == nil && ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %s", e.job.Timeout)
+	}
+	end := time.Now()
+
+	s.mutex.Lock()
+	e.status.Running--
+	e.status.Runs++
+	e.status.LastEnd = end
+	e.status.LastDuration = end.Sub(start)
+	e.status.LastError = ""
+	if err != nil {
+		e.status.Failures++
+		e.status.LastError = err.Error()
+	} else {
+		e.status.LastSuccess = end
+	}
+	status := e.status
+	s.mutex.Unlock()
+
+	if err != nil {
+		s.Log.Error(fmt.Sprintf("[%s] failed after %s: %v", e.job.Name, end.Sub(start).Round(time.Millisecond), err))
+	} else {
+		s.Log.Println(fmt.Sprintf("[%s] completed in %s", e.job.Name, end.Sub(start).Round(time.Millisecond)))
+	}
+
+	if s.OnComplete != nil {
+		if err := s.OnComplete(status); err != nil {
+			s.Log.Warning(fmt.Sprintf("[%s] completion hook failed: %v", e.job.Name, err))
+		}
+	}
+}
+
+// call runs a job, converting a panic into an error so one job cannot take down the process
+func call(ctx context.Context, fn JobFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
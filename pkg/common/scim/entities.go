@@ -0,0 +1,179 @@
+/*
+# SCIM - Entities [Structs]
+
+This package contains the resources and messages of the SCIM 2.0 protocol:
+https://datatracker.ietf.org/doc/html/rfc7643
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/scim/entities.go
+package scim
+
+import (
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+// Schema URIs of the resources and messages
+const (
+	UserSchema           = "urn:ietf:params:scim:schemas:core:2.0:User"                 // https://datatracker.ietf.org/doc/html/rfc7643#section-4.1
+	EnterpriseUserSchema = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User" // https://datatracker.ietf.org/doc/html/rfc7643#section-4.3
+	GroupSchema          = "urn:ietf:params:scim:schemas:core:2.0:Group"                // https://datatracker.ietf.org/doc/html/rfc7643#section-4.2
+	ListResponseSchema   = "urn:ietf:params:scim:api:messages:2.0:ListResponse"         // https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2
+	PatchOpSchema        = "urn:ietf:params:scim:api:messages:2.0:PatchOp"              // https://datatracker.ietf.org/doc/html/rfc7644#section-3.5.2
+	ErrorSchema          = "urn:ietf:params:scim:api:messages:2.0:Error"                // https://datatracker.ietf.org/doc/html/rfc7644#section-3.12
+)
+
+// ### SCIM Client Entities
+// ---------------------------------------------------------------------
+type Client struct {
+	BaseURL  string           // BaseURL of the SCIM service, e.g. `https://api.slack.com/scim/v2`
+	Provider string           // Provider is the name of the service reported in errors, e.g. `1password`
+	PageSize int              // PageSize is the number of resources requested per page when listing
+	HTTP     *requests.Client // HTTP is the client used to make HTTP requests; its headers carry the credentials
+	Log      *log.Logger      // Log is the logger used to log messages.
+}
+
+/*
+ * Query Parameters for SCIM list endpoints
+ * https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2
+ */
+type Query struct {
+	Filter             string `url:"filter,omitempty"`             // Filter expression, e.g. built with `Eq("userName", email)`
+	Attributes         string `url:"attributes,omitempty"`         // Comma-separated attributes to return, instead of the defaults
+	ExcludedAttributes string `url:"excludedAttributes,omitempty"` // Comma-separated attributes to leave out, e.g. `members` for large groups
+	SortBy             string `url:"sortBy,omitempty"`             // Attribute to sort by, where supported
+	SortOrder          string `url:"sortOrder,omitempty"`          // `ascending` or `descending`
+	StartIndex         int    `url:"startIndex,omitempty"`         // One-based index of the first result; set by the client while paginating
+	Count              int    `url:"count,omitempty"`              // Number of results per page; set by the client while paginating
+}
+
+// END OF SCIM CLIENT ENTITIES
+//---------------------------------------------------------------------
+
+// ### SCIM Resource Structs
+// ---------------------------------------------------------------------
+
+// User is a SCIM user resource
+// https://datatracker.ietf.org/doc/html/rfc7643#section-4.1
+type User struct {
+	Schemas      []string        `json:"schemas,omitempty"`                                                    // Schemas of the resource; set by the client on create/replace when empty
+	ID           string          `json:"id,omitempty"`                                                         // Identifier assigned by the service
+	ExternalID   string          `json:"externalId,omitempty"`                                                 // Identifier assigned by the provisioning client, e.g. the Okta user ID
+	UserName     string          `json:"userName,omitempty"`                                                   // Unique login, usually the email address
+	Name         *Name           `json:"name,omitempty"`                                                       // Components of the user's name
+	DisplayName  string          `json:"displayName,omitempty"`                                                // Name displayed to other users
+	NickName     string          `json:"nickName,omitempty"`                                                   // Casual name of the user
+	Title        string          `json:"title,omitempty"`                                                      // Job title
+	UserType     string          `json:"userType,omitempty"`                                                   // Relationship to the organization, e.g. `Employee`, `Contractor`
+	Locale       string          `json:"locale,omitempty"`                                                     // Locale, e.g. `en-US`
+	Timezone     string          `json:"timezone,omitempty"`                                                   // Time zone, e.g. `America/New_York`
+	Active       *bool           `json:"active,omitempty"`                                                     // Whether the user can sign in; nil leaves it to the service
+	Emails       []*MultiValue   `json:"emails,omitempty"`                                                     // Email addresses
+	PhoneNumbers []*MultiValue   `json:"phoneNumbers,omitempty"`                                               // Phone numbers
+	Groups       []*MultiValue   `json:"groups,omitempty"`                                                     // Groups of the user; read-only, managed through the groups
+	Enterprise   *EnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"` // Enterprise extension
+	Meta         *Meta           `json:"meta,omitempty"`                                                       // Metadata of the resource
+}
+
+// Name holds the components of a user's name
+type Name struct {
+	Formatted       string `json:"formatted,omitempty"`       // Full name, formatted for display
+	FamilyName      string `json:"familyName,omitempty"`      // Last name
+	GivenName       string `json:"givenName,omitempty"`       // First name
+	MiddleName      string `json:"middleName,omitempty"`      // Middle name
+	HonorificPrefix string `json:"honorificPrefix,omitempty"` // e.g. `Dr.`
+	HonorificSuffix string `json:"honorificSuffix,omitempty"` // e.g. `Jr.`
+}
+
+// EnterpriseUser holds the attributes of the enterprise user extension
+// https://datatracker.ietf.org/doc/html/rfc7643#section-4.3
+type EnterpriseUser struct {
+	EmployeeNumber string   `json:"employeeNumber,omitempty"` // Identifier assigned by the organization
+	CostCenter     string   `json:"costCenter,omitempty"`     // Cost center
+	Organization   string   `json:"organization,omitempty"`   // Organization
+	Division       string   `json:"division,omitempty"`       // Division
+	Department     string   `json:"department,omitempty"`     // Department
+	Manager        *Manager `json:"manager,omitempty"`        // Manager of the user
+}
+
+// Manager references the manager of a user
+type Manager struct {
+	Value       string `json:"value,omitempty"`       // ID of the manager's user resource
+	DisplayName string `json:"displayName,omitempty"` // Display name of the manager; read-only
+}
+
+// MultiValue is an entry of a multi-valued attribute, e.g. an email address or a group member
+// https://datatracker.ietf.org/doc/html/rfc7643#section-2.4
+type MultiValue struct {
+	Value   string `json:"value,omitempty"`   // Value of the entry, e.g. the address, or the ID of the referenced resource
+	Display string `json:"display,omitempty"` // Human-readable name of the entry
+	Type    string `json:"type,omitempty"`    // Label of the entry, e.g. `work`
+	Primary bool   `json:"primary,omitempty"` // Whether this is the preferred entry
+	Ref     string `json:"$ref,omitempty"`    // URI of the referenced resource
+}
+
+// Group is a SCIM group resource
+// https://datatracker.ietf.org/doc/html/rfc7643#section-4.2
+type Group struct {
+	Schemas     []string      `json:"schemas,omitempty"`     // Schemas of the resource; set by the client on create/replace when empty
+	ID          string        `json:"id,omitempty"`          // Identifier assigned by the service
+	ExternalID  string        `json:"externalId,omitempty"`  // Identifier assigned by the provisioning client
+	DisplayName string        `json:"displayName,omitempty"` // Name of the group
+	Members     []*MultiValue `json:"members,omitempty"`     // Members of the group; `Value` is the ID of each user
+	Meta        *Meta         `json:"meta,omitempty"`        // Metadata of the resource
+}
+
+// Meta is the metadata of a resource
+type Meta struct {
+	ResourceType string `json:"resourceType,omitempty"` // `User` or `Group`
+	Created      string `json:"created,omitempty"`      // Time the resource was created
+	LastModified string `json:"lastModified,omitempty"` // Time the resource was last modified
+	Location     string `json:"location,omitempty"`     // URI of the resource
+	Version      string `json:"version,omitempty"`      // ETag of the resource
+}
+
+// END OF SCIM RESOURCE STRUCTS
+//---------------------------------------------------------------------
+
+// ### SCIM Message Structs
+// ---------------------------------------------------------------------
+
+// ListResponse is a page of resources
+// https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2
+type ListResponse[T any] struct {
+	Schemas      []string `json:"schemas"`      // `ListResponseSchema`
+	TotalResults int      `json:"totalResults"` // Number of resources matching the query, across every page
+	StartIndex   int      `json:"startIndex"`   // One-based index of the first resource of the page
+	ItemsPerPage int      `json:"itemsPerPage"` // Number of resources in the page
+	Resources    []T      `json:"Resources"`    // Resources of the page
+}
+
+// PatchOp is a request modifying part of a resource
+// https://datatracker.ietf.org/doc/html/rfc7644#section-3.5.2
+type PatchOp struct {
+	Schemas    []string     `json:"schemas"`    // `PatchOpSchema`
+	Operations []*Operation `json:"Operations"` // Operations, applied in order
+}
+
+// Operation is a single change of a PATCH request; build them with `Add`, `Replace` and `Remove`
+type Operation struct {
+	Op    string      `json:"op"`              // `add`, `replace` or `remove`
+	Path  string      `json:"path,omitempty"`  // Attribute path, e.g. `active` or `members[value eq "2819c223"]`
+	Value interface{} `json:"value,omitempty"` // New value of the attribute; unset for `remove`
+}
+
+// Error is an error response of a SCIM service
+// https://datatracker.ietf.org/doc/html/rfc7644#section-3.12
+type Error struct {
+	Schemas  []string    `json:"schemas"`            // `ErrorSchema`
+	Status   interface{} `json:"status"`             // HTTP status code; a string per the RFC, but some services send a number
+	ScimType string      `json:"scimType,omitempty"` // SCIM error type, e.g. `uniqueness`, `invalidFilter`
+	Detail   string      `json:"detail,omitempty"`   // Human-readable description of the error
+}
+
+// END OF SCIM MESSAGE STRUCTS
+//---------------------------------------------------------------------
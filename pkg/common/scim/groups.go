@@ -0,0 +1,184 @@
+/*
+# SCIM - Groups
+
+This package contains all the methods to provision SCIM groups and their members:
+https://datatracker.ietf.org/doc/html/rfc7644#section-3
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/scim/groups.go
+package scim
+
+import (
+	"fmt"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+)
+
+// GroupClient for chaining methods
+type GroupClient struct {
+	*Client
+}
+
+// Entry point for group-related operations
+func (c *Client) Groups() *GroupClient {
+	return &GroupClient{
+		Client: c,
+	}
+}
+
+/*
+ * # List groups
+ * /Groups
+ * - `q` may be nil to list every group; set `ExcludedAttributes: "members"` to skip the members of large groups
+ * - https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2
+ */
+func (c *GroupClient) ListGroups(q *Query) ([]*Group, error) {
+	url := c.BuildURL(SCIMGroups)
+	return doPaginated[Group](c.Client, url, q)
+}
+
+/*
+ * # Get a group by ID
+ * /Groups/{id}
+ * - https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.1
+ */
+func (c *GroupClient) GetGroup(id string) (*Group, error) {
+	url := c.BuildURL(SCIMGroups, id)
+
+	group, err := do[Group](c.Client, "GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+/*
+ * # Get a group by name
+ * /Groups?filter=displayName eq "{name}"
+ * - Returns an error wrapping `errors.ErrNotFound` when no group matches
+ */
+func (c *GroupClient) GetGroupByName(name string) (*Group, error) {
+	groups, err := c.ListGroups(&Query{Filter: Eq("displayName", name)})
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("group %s: %w", name, rerrors.ErrNotFound)
+	}
+	return groups[0], nil
+}
+
+/*
+ * # Create a group
+ * /Groups
+ * - https://datatracker.ietf.org/doc/html/rfc7644#section-3.3
+ */
+func (c *GroupClient) CreateGroup(group *Group) (*Group, error) {
+	url := c.BuildURL(SCIMGroups)
+
+	if len(group.Schemas) == 0 {
+		group.Schemas = []string{GroupSchema}
+	}
+
+	c.Log.Printf("Creating SCIM group %s", group.DisplayName)
+	created, err := do[Group](c.Client, "POST", url, nil, group)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+/*
+ * # Replace a group
+ * /Groups/{id}
+ * - Replaces the members too; prefer `AddMembers` and `RemoveMembers` to change membership
+ * - https://datatracker.ietf.org/doc/html/rfc7644#section-3.5.1
+ */
+func (c *GroupClient) ReplaceGroup(group *Group) (*Group, error) {
+	url := c.BuildURL(SCIMGroups, group.ID)
+
+	if len(group.Schemas) == 0 {
+		group.Schemas = []string{GroupSchema}
+	}
+
+	c.Log.Printf("Replacing SCIM group %s", group.ID)
+	replaced, err := do[Group](c.Client, "PUT", url, nil, group)
+	if err != nil {
+		return nil, err
+	}
+	return &replaced, nil
+}
+
+/*
+ * # Patch a group
+ * /Groups/{id}
+ * - Returns the updated group, or nil when the service responds without one (`204 No Content`)
+ * - https://datatracker.ietf.org/doc/html/rfc7644#section-3.5.2
+ */
+func (c *GroupClient) PatchGroup(id string, operations ...*Operation) (*Group, error) {
+	url := c.BuildURL(SCIMGroups, id)
+
+	patch := &PatchOp{Schemas: []string{PatchOpSchema}, Operations: operations}
+
+	c.Log.Printf("Patching SCIM group %s", id)
+	group, err := do[*Group](c.Client, "PATCH", url, nil, patch)
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+/*
+ * # Add members to a group
+ * /Groups/{id}
+ * - `userIDs` are the SCIM IDs of the users, not their userNames
+ */
+func (c *GroupClient) AddMembers(groupID string, userIDs ...string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	members := make([]*MultiValue, len(userIDs))
+	for i, id := range userIDs {
+		members[i] = &MultiValue{Value: id}
+	}
+
+	_, err := c.PatchGroup(groupID, Add("members", members))
+	return err
+}
+
+/*
+ * # Remove members from a group
+ * /Groups/{id}
+ * - Sends one `remove` operation per member, with a value filter in its path as defined by RFC 7644
+ */
+func (c *GroupClient) RemoveMembers(groupID string, userIDs ...string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	operations := make([]*Operation, len(userIDs))
+	for i, id := range userIDs {
+		operations[i] = Remove(fmt.Sprintf("members[%s]", Eq("value", id)))
+	}
+
+	_, err := c.PatchGroup(groupID, operations...)
+	return err
+}
+
+/*
+ * # Delete a group
+ * /Groups/{id}
+ * - https://datatracker.ietf.org/doc/html/rfc7644#section-3.6
+ */
+func (c *GroupClient) DeleteGroup(id string) error {
+	url := c.BuildURL(SCIMGroups, id)
+
+	c.Log.Printf("Deleting SCIM group %s", id)
+	_, err := do[interface{}](c.Client, "DELETE", url, nil, nil)
+	return err
+}
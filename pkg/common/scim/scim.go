@@ -0,0 +1,252 @@
+/*
+# SCIM
+
+This package is a generic client for services which provision users and groups over SCIM 2.0 (1Password, Slack
+Enterprise Grid, Zoom, internal applications, ...), so they can be managed without a bespoke package each:
+https://datatracker.ietf.org/doc/html/rfc7644
+
+	s := scim.NewClient("https://api.example.com/scim/v2", token, log.INFO)
+	user, err := s.Users().GetUserByUserName("user@example.com")
+	...
+	err = s.Users().DeactivateUser(user.ID)
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/scim/scim.go
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+const (
+	ContentType = "application/scim+json" // https://datatracker.ietf.org/doc/html/rfc7644#section-8.1
+)
+
+const (
+	SCIMUsers  = "%s/Users"  // https://datatracker.ietf.org/doc/html/rfc7644#section-3.2
+	SCIMGroups = "%s/Groups" // https://datatracker.ietf.org/doc/html/rfc7644#section-3.2
+)
+
+// Number of resources requested per page when `Client.PageSize` is not set
+const DefaultPageSize = 100
+
+// BuildURL builds a URL for a given resource and identifiers.
+func (c *Client) BuildURL(endpoint string, identifiers ...string) string {
+	url := fmt.Sprintf(endpoint, c.BaseURL)
+	for _, id := range identifiers {
+		url = fmt.Sprintf("%s/%s", url, id)
+	}
+	c.Log.Debug("url:", url)
+	return url
+}
+
+/*
+ * # Close the SCIM Client
+ * Waits for the requests in flight, or for `ctx` to be done, then stops the rate limiter
+ * - Requests sent after `Close` fail with `requests.ErrClosed`
+ */
+func (c *Client) Close(ctx context.Context) error {
+	return c.HTTP.Close(ctx)
+}
+
+/*
+  - # Generate SCIM Client
+  - @param baseURL string
+  - @param token string
+  - @param verbosity int
+  - @return *Client
+  - Authenticates with a bearer token; services using other schemes can replace the header:
+
+```go
+
+	s := scim.NewClient("https://scim.example.com/v2", "", log.INFO)
+	s.HTTP.Headers["Authorization"] = "Basic " + credentials
+
+```
+*/
+func NewClient(baseURL, token string, verbosity int) *Client {
+	log := log.NewLogger("{scim}", verbosity)
+
+	headers := requests.Headers{
+		"Authorization": "Bearer " + token,
+		"Accept":        fmt.Sprintf("%s, %s", ContentType, requests.JSON),
+		"Content-Type":  ContentType,
+	}
+
+	httpClient := requests.NewClient(nil, headers, nil, requests.WithUserAgent("scim"))
+	httpClient.BodyType = requests.JSON
+
+	return &Client{
+		BaseURL:  strings.TrimSuffix(baseURL, "/"),
+		Provider: "scim",
+		PageSize: DefaultPageSize,
+		HTTP:     httpClient,
+		Log:      log,
+	}
+}
+
+/*
+ * # Build a filter expression
+ * - String values are quoted and escaped; other values are written as JSON, e.g. `active eq true`
+ * - The `pr` (present) operator takes no value
+ * - https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2.2
+ */
+func Filter(attribute, operator string, value interface{}) string {
+	if operator == "pr" {
+		return fmt.Sprintf("%s pr", attribute)
+	}
+	v, err := json.Marshal(value)
+	if err != nil {
+		v = []byte(fmt.Sprintf("%q", fmt.Sprint(value)))
+	}
+	return fmt.Sprintf("%s %s %s", attribute, operator, v)
+}
+
+// Eq builds a filter matching an attribute equal to a value, e.g. `userName eq "user@example.com"`
+func Eq(attribute string, value interface{}) string {
+	return Filter(attribute, "eq", value)
+}
+
+// And joins filters which must all match
+func And(filters ...string) string {
+	return join("and", filters)
+}
+
+// Or joins filters of which any must match
+func Or(filters ...string) string {
+	return join("or", filters)
+}
+
+func join(operator string, filters []string) string {
+	if len(filters) == 1 {
+		return filters[0]
+	}
+	grouped := make([]string, len(filters))
+	for i, f := range filters {
+		grouped[i] = "(" + f + ")"
+	}
+	return strings.Join(grouped, " "+operator+" ")
+}
+
+// Add returns an operation adding a value to an attribute, e.g. members to a group
+func Add(path string, value interface{}) *Operation {
+	return &Operation{Op: "add", Path: path, Value: value}
+}
+
+// Replace returns an operation replacing the value of an attribute
+func Replace(path string, value interface{}) *Operation {
+	return &Operation{Op: "replace", Path: path, Value: value}
+}
+
+// Remove returns an operation removing an attribute, or the values matching a filtered path
+func Remove(path string) *Operation {
+	return &Operation{Op: "remove", Path: path}
+}
+
+/*
+ * # Classify a SCIM error response
+ * - Records the `scimType` and `detail` of the response on the error
+ * - `uniqueness` errors are reported as conflicts, whatever the status code of the service
+ * - https://datatracker.ietf.org/doc/html/rfc7644#section-3.12
+ */
+func (c *Client) apiError(err error) error {
+	apiErr, ok := rerrors.AsAPIError(err)
+	if !ok {
+		return err
+	}
+	apiErr.Provider = c.Provider
+
+	scimErr := &Error{}
+	if json.Unmarshal([]byte(apiErr.Message), scimErr) != nil || (scimErr.Detail == "" && scimErr.ScimType == "") {
+		return apiErr
+	}
+
+	apiErr.Code = scimErr.ScimType
+	apiErr.Message = scimErr.Detail
+	if scimErr.ScimType == "uniqueness" {
+		apiErr.Class = rerrors.ErrConflict
+	}
+	return apiErr
+}
+
+/*
+ * Perform a generic request to a SCIM service
+ * - Payloads are encoded as they are, so that unset attributes are left out rather than cleared
+ */
+func do[T any](c *Client, method string, url string, query interface{}, data interface{}) (T, error) {
+	var result T
+
+	if data != nil {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return *new(T), fmt.Errorf("marshaling request body: %w", err)
+		}
+		data = json.RawMessage(payload)
+	}
+
+	res, body, err := c.HTTP.DoRequest(method, url, query, data)
+	if err != nil {
+		return *new(T), c.apiError(err)
+	}
+
+	c.Log.Println("Response Status:", res.Status)
+	c.Log.Debug("Response Body:", string(body))
+
+	// Deletions, and some PATCH requests, respond with `204 No Content`
+	if len(body) == 0 {
+		return result, nil
+	}
+
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return *new(T), fmt.Errorf("unmarshalling error: %w", err)
+	}
+
+	return result, nil
+}
+
+/*
+ * Generically perform a paginated request to a SCIM service
+ * - Pages are requested by `startIndex` (one-based) and `count` until `totalResults` are collected, or a page is empty
+ */
+func doPaginated[E any](c *Client, url string, query *Query) ([]*E, error) {
+	results := make([]*E, 0)
+
+	q := Query{}
+	if query != nil {
+		q = *query
+	}
+	if q.Count == 0 {
+		q.Count = c.PageSize
+	}
+	if q.Count <= 0 {
+		q.Count = DefaultPageSize
+	}
+
+	for q.StartIndex = 1; ; {
+		page, err := do[ListResponse[*E]](c, "GET", url, &q, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, page.Resources...)
+
+		if len(page.Resources) == 0 || len(results) >= page.TotalResults {
+			break
+		}
+		q.StartIndex += len(page.Resources)
+	}
+
+	return results, nil
+}
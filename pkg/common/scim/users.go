@@ -0,0 +1,167 @@
+/*
+# SCIM - Users
+
+This package contains all the methods to provision SCIM users:
+https://datatracker.ietf.org/doc/html/rfc7644#section-3
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/scim/users.go
+package scim
+
+import (
+	"fmt"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+)
+
+// UserClient for chaining methods
+type UserClient struct {
+	*Client
+}
+
+// Entry point for user-related operations
+func (c *Client) Users() *UserClient {
+	return &UserClient{
+		Client: c,
+	}
+}
+
+/*
+ * # List users
+ * /Users
+ * - `q` may be nil to list every user, or carry a `Filter`
+ * - https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2
+ */
+func (c *UserClient) ListUsers(q *Query) ([]*User, error) {
+	url := c.BuildURL(SCIMUsers)
+	return doPaginated[User](c.Client, url, q)
+}
+
+/*
+ * # Get a user by ID
+ * /Users/{id}
+ * - https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.1
+ */
+func (c *UserClient) GetUser(id string) (*User, error) {
+	url := c.BuildURL(SCIMUsers, id)
+
+	user, err := do[User](c.Client, "GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+/*
+ * # Get a user by userName
+ * /Users?filter=userName eq "{userName}"
+ * - Returns an error wrapping `errors.ErrNotFound` when no user matches
+ */
+func (c *UserClient) GetUserByUserName(userName string) (*User, error) {
+	users, err := c.ListUsers(&Query{Filter: Eq("userName", userName)})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("user %s: %w", userName, rerrors.ErrNotFound)
+	}
+	return users[0], nil
+}
+
+/*
+ * # Create a user
+ * /Users
+ * - Returns an error wrapping `errors.ErrConflict` when the userName is taken
+ * - https://datatracker.ietf.org/doc/html/rfc7644#section-3.3
+ */
+func (c *UserClient) CreateUser(user *User) (*User, error) {
+	url := c.BuildURL(SCIMUsers)
+
+	if len(user.Schemas) == 0 {
+		user.Schemas = userSchemas(user)
+	}
+
+	c.Log.Printf("Creating SCIM user %s", user.UserName)
+	created, err := do[User](c.Client, "POST", url, nil, user)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+/*
+ * # Replace a user
+ * /Users/{id}
+ * - Attributes which are not set on `user` are cleared by most services; prefer `PatchUser` for partial updates
+ * - https://datatracker.ietf.org/doc/html/rfc7644#section-3.5.1
+ */
+func (c *UserClient) ReplaceUser(user *User) (*User, error) {
+	url := c.BuildURL(SCIMUsers, user.ID)
+
+	if len(user.Schemas) == 0 {
+		user.Schemas = userSchemas(user)
+	}
+
+	c.Log.Printf("Replacing SCIM user %s", user.ID)
+	replaced, err := do[User](c.Client, "PUT", url, nil, user)
+	if err != nil {
+		return nil, err
+	}
+	return &replaced, nil
+}
+
+/*
+ * # Patch a user
+ * /Users/{id}
+ * - Returns the updated user, or nil when the service responds without one (`204 No Content`)
+ * - https://datatracker.ietf.org/doc/html/rfc7644#section-3.5.2
+ */
+func (c *UserClient) PatchUser(id string, operations ...*Operation) (*User, error) {
+	url := c.BuildURL(SCIMUsers, id)
+
+	patch := &PatchOp{Schemas: []string{PatchOpSchema}, Operations: operations}
+
+	c.Log.Printf("Patching SCIM user %s", id)
+	user, err := do[*User](c.Client, "PATCH", url, nil, patch)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+/*
+ * # Deactivate a user
+ * /Users/{id}
+ * - Sets `active` to false, so the user can no longer sign in but keeps their data
+ */
+func (c *UserClient) DeactivateUser(id string) error {
+	_, err := c.PatchUser(id, Replace("active", false))
+	return err
+}
+
+/*
+ * # Delete a user
+ * /Users/{id}
+ * - Some services (e.g. Slack) deactivate rather than delete
+ * - https://datatracker.ietf.org/doc/html/rfc7644#section-3.6
+ */
+func (c *UserClient) DeleteUser(id string) error {
+	url := c.BuildURL(SCIMUsers, id)
+
+	c.Log.Printf("Deleting SCIM user %s", id)
+	_, err := do[interface{}](c.Client, "DELETE", url, nil, nil)
+	return err
+}
+
+// userSchemas returns the schemas of the attributes set on a user
+func userSchemas(user *User) []string {
+	schemas := []string{UserSchema}
+	if user.Enterprise != nil {
+		schemas = append(schemas, EnterpriseUserSchema)
+	}
+	return schemas
+}
@@ -3,11 +3,16 @@ package server
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+)
+
+var (
+	l = log.NewLogger("{server}", log.INFO)
 )
 
 type Handlers []Handler
@@ -35,11 +40,11 @@ func StartServer(addr string, handlers map[string]http.HandlerFunc) {
 	signal.Notify(quit, os.Interrupt)
 	<-quit
 
-	log.Println("Server is shutting down...")
+	l.Println("Server is shutting down...")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		panic(err)
 	}
-	log.Println("Server gracefully stopped")
+	l.Println("Server gracefully stopped")
 }
@@ -0,0 +1,223 @@
+// pkg/common/starstruct/query.go
+package starstruct
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+/*
+ * # Query Values
+ * Encodes a query struct (or map) into URL query parameters, with the options of its `url` tags:
+ *
+ * ```go
+ *	type LogQuery struct {
+ *		Since  time.Time `url:"since,omitempty"`                   // 2024-01-02T15:04:05Z
+ *		Until  time.Time `url:"until,omitempty,unix"`              // 1704207845
+ *		Day    time.Time `url:"day,omitempty" layout:"2006-01-02"` // 2024-01-02
+ *		Fields []string  `url:"fields,comma"`                      // fields=a,b
+ *		Types  []string  `url:"type,omitempty"`                    // type=a&type=b
+ *		Limit  *int      `url:"limit"`                             // Omitted when nil, even without omitempty
+ *	}
+ * ```
+ *
+ * - `omitempty` omits zero values; a non-nil pointer is always encoded, so a pointer sends an explicit `false` or `0`
+ * - Times are formatted as RFC 3339, or as epoch seconds with `unix`, milliseconds with `unixmilli`, or with the
+ *   `layout` tag
+ * - Slices repeat their key by default; `comma`, `space` and `semicolon` join their items, `brackets` suffixes the key
+ *   with `[]`
+ * - Nested structs are encoded as `parent[child]`, and embedded ones as fields of their parent
+ * - Values implementing `encoding.TextMarshaler` (e.g. enums) are encoded with it
+ * - Fields without a `url` tag are named by their `json` tag, or in camelCase, and omitted when zero
+ */
+func QueryValues(query interface{}) (url.Values, error) {
+	values := url.Values{}
+	if query == nil {
+		return values, nil
+	}
+
+	v := reflect.ValueOf(query)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return values, nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			if err := encodeQueryValue(values, fmt.Sprint(key.Interface()), v.MapIndex(key), queryOptions{}); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	case reflect.Struct:
+		return values, encodeQueryStruct(values, "", v)
+	}
+	return nil, fmt.Errorf("expected a struct or map, got %s", v.Kind())
+}
+
+// queryOptions are the options of a field's `url` tag
+type queryOptions struct {
+	omitEmpty bool
+	layout    string // Of its `layout` tag, or `unix`/`unixmilli`
+	separator string // Joins the items of slices; repeated keys when empty
+	brackets  bool
+}
+
+// encodeQueryStruct encodes the fields of a struct, each key prefixed as `prefix[key]` when nested
+func encodeQueryStruct(values url.Values, prefix string, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field, sf := v.Field(i), t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag, tagged := sf.Tag.Lookup("url")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		opts := queryOptions{layout: sf.Tag.Get("layout")}
+		for _, o := range strings.Split(options, ",") {
+			switch o {
+			case "omitempty":
+				opts.omitEmpty = true
+			case "unix", "unixmilli":
+				opts.layout = o
+			case "comma":
+				opts.separator = ","
+			case "space":
+				opts.separator = " "
+			case "semicolon":
+				opts.separator = ";"
+			case "brackets":
+				opts.brackets = true
+			}
+		}
+		if !tagged {
+			// Named and omitted like `ToMap`, which the query structs without `url` tags were encoded with
+			opts.omitEmpty = true
+			if name = getFirstTag(sf.Tag.Get("json")); name == "-" {
+				continue
+			}
+		}
+
+		if sf.Anonymous && name == "" && reflect.Indirect(field).Kind() == reflect.Struct {
+			if field.Kind() == reflect.Ptr && field.IsNil() {
+				continue
+			}
+			if err := encodeQueryStruct(values, prefix, reflect.Indirect(field)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if name == "" {
+			name = camelKey(sf.Name)
+		}
+		if prefix != "" {
+			name = prefix + "[" + name + "]"
+		}
+		if err := encodeQueryValue(values, name, field, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeQueryValue adds the value of a field (or map entry) to `values`
+func encodeQueryValue(values url.Values, key string, v reflect.Value, opts queryOptions) error {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	} else if opts.omitEmpty && v.IsZero() {
+		return nil
+	}
+
+	if v.Type() != timeType && v.Kind() == reflect.Struct && !isTextMarshaler(v) {
+		return encodeQueryStruct(values, key, v)
+	}
+
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8 {
+		if opts.brackets {
+			key += "[]"
+		}
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, err := formatQueryValue(reflect.Indirect(v.Index(i)), opts)
+			if err != nil {
+				return fmt.Errorf("encoding %s: %w", key, err)
+			}
+			items = append(items, item)
+		}
+		if opts.separator != "" {
+			if len(items) > 0 {
+				values.Add(key, strings.Join(items, opts.separator))
+			}
+			return nil
+		}
+		for _, item := range items {
+			values.Add(key, item)
+		}
+		return nil
+	}
+
+	s, err := formatQueryValue(v, opts)
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", key, err)
+	}
+	values.Add(key, s)
+	return nil
+}
+
+// formatQueryValue formats a single value, e.g. an item of a slice
+func formatQueryValue(v reflect.Value, opts queryOptions) (string, error) {
+	if !v.IsValid() {
+		return "", nil
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		switch opts.layout {
+		case "":
+			return t.Format(time.RFC3339), nil
+		case "unix":
+			return strconv.FormatInt(t.Unix(), 10), nil
+		case "unixmilli":
+			return strconv.FormatInt(t.UnixMilli(), 10), nil
+		default:
+			return t.Format(opts.layout), nil
+		}
+	}
+	if isTextMarshaler(v) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		return string(v.Bytes()), nil
+	}
+	return fmt.Sprint(v.Interface()), nil
+}
+
+// isTextMarshaler reports whether a value encodes itself as text
+func isTextMarshaler(v reflect.Value) bool {
+	_, ok := v.Interface().(encoding.TextMarshaler)
+	return ok
+}
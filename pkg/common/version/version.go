@@ -0,0 +1,74 @@
+/*
+# Version
+
+This package pins the clients of the provider packages to a version of their API, e.g. Okta Identity Engine or the
+beta of Google's Directory API, so a breaking migration of the provider can be staged per client rather than hardcoded
+in constants:
+
+```go
+
+	var capabilities = version.Capabilities[Capability, APIVersion]{
+		DEVICES: {OIE},
+	}
+
+	v, err := version.Pin(o.APIVersion, OIE, APIVersions...)
+
+	if err := capabilities.Require(v, DEVICES); err != nil {
+		return nil, err
+	}
+
+```
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/version/version.go
+package version
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gemini-oss/rego/pkg/common/enum"
+)
+
+// ErrUnsupported is matched (with `errors.Is`) by calls to a capability which the pinned version does not serve
+var ErrUnsupported = errors.New("unsupported by the API version")
+
+/*
+ * # Pin a Version
+ * Returns the version named by `v` (ignoring case), or `fallback` when `v` is empty
+ * - A version outside of `versions` fails with an error wrapping `enum.ErrInvalid`
+ */
+func Pin[V ~string](v string, fallback V, versions ...V) (V, error) {
+	if v == "" {
+		return fallback, nil
+	}
+	for _, version := range versions {
+		if strings.EqualFold(string(version), v) {
+			return version, nil
+		}
+	}
+	return "", enum.Check(V(v), versions...)
+}
+
+// Capabilities maps the capabilities of an API to the versions which serve them; a capability which is not mapped is served by every version
+type Capabilities[C ~string, V ~string] map[C][]V
+
+// Supports returns whether version `v` serves capability `c`
+func (cs Capabilities[C, V]) Supports(v V, c C) bool {
+	versions, ok := cs[c]
+	return !ok || slices.Contains(versions, v)
+}
+
+// Require returns an error wrapping `ErrUnsupported`, naming the versions which serve `c`, unless version `v` serves it
+func (cs Capabilities[C, V]) Require(v V, c C) error {
+	if cs.Supports(v, c) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s requires %q, the client is pinned to %q", ErrUnsupported, c, cs[c], v)
+}
@@ -0,0 +1,113 @@
+// pkg/common/webhooks/jamf.go
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// JamfConfig configures a Jamf Pro webhook endpoint
+// - Jamf Pro webhooks support either basic authentication or a custom header
+type JamfConfig struct {
+	Username   string // Basic authentication username
+	Password   string // Basic authentication password
+	AuthHeader string // Name of a custom authentication header
+	Secret     string // Value of the custom authentication header
+}
+
+// JamfWebhook is the body of a Jamf Pro webhook
+type JamfWebhook struct {
+	Webhook struct {
+		ID             int    `json:"id"`             // Identifier of the webhook in Jamf Pro
+		Name           string `json:"name"`           // Name of the webhook
+		WebhookEvent   string `json:"webhookEvent"`   // Type of the event, e.g. `ComputerAdded`, `ComputerCheckIn`, `MobileDeviceEnrolled`
+		EventTimestamp int64  `json:"eventTimestamp"` // Epoch time of the event, in milliseconds
+	} `json:"webhook"`
+	Event json.RawMessage `json:"event"` // The event, whose shape depends on `webhookEvent`
+}
+
+// JamfEvent holds the device fields common to Jamf Pro webhook events
+// - Check-in and inventory events nest the device under `computer`
+type JamfEvent struct {
+	UDID         string     `json:"udid,omitempty"`         // Device UDID
+	SerialNumber string     `json:"serialNumber,omitempty"` // Device serial number
+	DeviceName   string     `json:"deviceName,omitempty"`   // Device name
+	Username     string     `json:"username,omitempty"`     // Assigned user
+	EmailAddress string     `json:"emailAddress,omitempty"` // Email of the assigned user
+	JSSID        int        `json:"jssID,omitempty"`        // Identifier of the device in Jamf Pro
+	Computer     *JamfEvent `json:"computer,omitempty"`     // Nested device, for check-in and inventory events
+}
+
+/*
+ * # Register a Jamf Pro webhook endpoint
+ * - Rejects requests without the configured basic authentication or header
+ * - Fails with `ErrInsecure` without either, unless the server is `Insecure`
+ * - https://developer.jamf.com/developer-guide/docs/webhooks
+ */
+func (s *Server) Jamf(path string, cfg JamfConfig) error {
+	return Register(s, path, Route[JamfWebhook]{
+		Provider:  Jamf,
+		Verifiers: cfg.verifiers(),
+		Events: func(hook *JamfWebhook) ([]*Event, error) {
+			event, err := jamfEvent(hook)
+			if err != nil {
+				return nil, err
+			}
+			return []*Event{event}, nil
+		},
+	})
+}
+
+func (cfg JamfConfig) verifiers() []Verifier {
+	verifiers := []Verifier{}
+	if cfg.Username != "" || cfg.Password != "" {
+		verifiers = append(verifiers, BasicAuth{Username: cfg.Username, Password: cfg.Password})
+	}
+	if cfg.AuthHeader != "" && cfg.Secret != "" {
+		verifiers = append(verifiers, HeaderSecret{Header: cfg.AuthHeader, Secret: cfg.Secret})
+	}
+	return verifiers
+}
+
+// JamfEventFromBody normalizes the body of a Jamf Pro webhook into an event
+func JamfEventFromBody(body []byte) (*Event, error) {
+	hook := &JamfWebhook{}
+	if err := json.Unmarshal(body, hook); err != nil {
+		return nil, err
+	}
+	return jamfEvent(hook)
+}
+
+func jamfEvent(hook *JamfWebhook) (*Event, error) {
+	if hook.Webhook.WebhookEvent == "" {
+		return nil, fmt.Errorf("missing webhook.webhookEvent")
+	}
+
+	inner := &JamfEvent{}
+	if len(hook.Event) > 0 {
+		if err := json.Unmarshal(hook.Event, inner); err != nil {
+			return nil, err
+		}
+	}
+	device := inner
+	if inner.Computer != nil {
+		device = inner.Computer
+	}
+
+	// One webhook fires for many devices within the same millisecond, so the device tells their events apart
+	key := device.UDID
+	if key == "" {
+		key = device.SerialNumber
+	}
+
+	return &Event{
+		ID:       fmt.Sprintf("%d-%d-%s", hook.Webhook.ID, hook.Webhook.EventTimestamp, key),
+		Provider: Jamf,
+		Type:     hook.Webhook.WebhookEvent,
+		Time:     time.UnixMilli(hook.Webhook.EventTimestamp).UTC(),
+		Subjects: subjects(device.SerialNumber, device.EmailAddress),
+		Data:     device,
+		Raw:      hook.Event,
+	}, nil
+}
@@ -0,0 +1,146 @@
+// pkg/common/webhooks/route.go
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrInsecure is returned when a route is registered without a secret to verify its requests, unless the server is `Insecure`
+var ErrInsecure = errors.New("webhooks: route has no secret")
+
+// Default time the IDs of delivered events are remembered, to drop the deliveries providers retry
+const DefaultReplayWindow = time.Hour
+
+// Route is a webhook endpoint whose payloads decode into a `T`
+type Route[T any] struct {
+	Provider  Provider                                     // Provider the webhooks are received from
+	Verifiers []Verifier                                   // Every verifier must accept a request before its body is decoded
+	Answer    func(w http.ResponseWriter, payload *T) bool // Answers handshakes, e.g. URL verification challenges, returning true when it did; optional
+	Events    func(payload *T) ([]*Event, error)           // Normalizes a payload into events
+}
+
+/*
+  - # Register a webhook endpoint
+  - Reads POSTed bodies (bounded by `MaxBodySize`), runs the verifiers of the route, decodes the JSON payload into a
+    `T`, then delivers its events
+  - Events whose ID was delivered within the `ReplayWindow` of the server are dropped
+  - A route without verifiers is refused with `ErrInsecure`, unless the server is `Insecure`
+  - Example:
+
+```go
+
+	err := webhooks.Register(s, "/github", webhooks.Route[PushEvent]{
+		Provider:  "github",
+		Verifiers: []webhooks.Verifier{&webhooks.HMACVerifier{Secret: secret, SignatureHeader: "X-Hub-Signature-256", Version: "sha256"}},
+		Events:    pushEvents,
+	})
+
+```
+*/
+func Register[T any](s *Server, path string, route Route[T]) error {
+	if err := s.secured(path, len(route.Verifiers) > 0); err != nil {
+		return err
+	}
+	s.mux.Handle(path, route.handler(s))
+	return nil
+}
+
+// secured refuses a route without a secret, unless the server is `Insecure`
+func (s *Server) secured(path string, secret bool) error {
+	if secret {
+		return nil
+	}
+	if !s.Insecure {
+		return fmt.Errorf("%w: %s", ErrInsecure, path)
+	}
+	s.Log.Warningf("Accepting unauthenticated webhooks on %s", path)
+	return nil
+}
+
+func (route Route[T]) handler(s *Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := readBody(w, r)
+		if err != nil {
+			s.reject(w, http.StatusBadRequest, "{%s} Failed to read webhook: %v", route.Provider, err)
+			return
+		}
+
+		if err := verify(r, body, route.Verifiers); err != nil {
+			s.reject(w, http.StatusUnauthorized, "{%s} Rejected webhook from %s: %v", route.Provider, r.RemoteAddr, err)
+			return
+		}
+
+		payload := new(T)
+		if err := json.Unmarshal(body, payload); err != nil {
+			s.reject(w, http.StatusBadRequest, "{%s} Failed to decode webhook: %v", route.Provider, err)
+			return
+		}
+
+		if route.Answer != nil && route.Answer(w, payload) {
+			s.Log.Printf("{%s} Answered handshake", route.Provider)
+			return
+		}
+
+		events, err := route.Events(payload)
+		if err != nil {
+			s.reject(w, http.StatusBadRequest, "{%s} Failed to decode webhook: %v", route.Provider, err)
+			return
+		}
+		s.deliver(w, events)
+	}
+}
+
+// verify runs every verifier on a request, returning the first rejection
+func verify(r *http.Request, body []byte, verifiers []Verifier) error {
+	for _, v := range verifiers {
+		if err := v.Verify(r, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replays remembers the IDs of delivered events
+type replays struct {
+	mutex sync.Mutex
+	seen  map[string]time.Time
+}
+
+// replayed records an event, reporting whether it was already delivered within `window`
+func (p *replays) replayed(e *Event, window time.Duration, now time.Time) bool {
+	if e.ID == "" || window <= 0 {
+		return false
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.seen == nil {
+		p.seen = map[string]time.Time{}
+	}
+
+	key := string(e.Provider) + "/" + e.ID
+	if at, ok := p.seen[key]; ok && now.Sub(at) < window {
+		return true
+	}
+	p.seen[key] = now
+
+	// Forget expired IDs once the cache has grown, so it stays bounded by the deliveries of one window
+	if len(p.seen) > 1024 && len(p.seen)%1024 == 0 {
+		for k, at := range p.seen {
+			if now.Sub(at) >= window {
+				delete(p.seen, k)
+			}
+		}
+	}
+	return false
+}
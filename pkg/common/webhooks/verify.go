@@ -0,0 +1,119 @@
+// pkg/common/webhooks/verify.go
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// errNoSecret rejects the requests of a verifier configured without a secret, which anyone could satisfy
+var errNoSecret = errors.New("no secret is configured")
+
+// Verifier authenticates a webhook before its body is decoded, returning why it was rejected
+type Verifier interface {
+	Verify(r *http.Request, body []byte) error
+}
+
+// VerifierFunc adapts a function to a Verifier
+type VerifierFunc func(r *http.Request, body []byte) error
+
+func (f VerifierFunc) Verify(r *http.Request, body []byte) error {
+	return f(r, body)
+}
+
+/*
+ * # HMAC Verifier
+ * Checks a signature header holding the hex HMAC of the body, as signed by Slack, Zoom or GitHub
+ * - With a `TimestampHeader`, the signed message is `{Version}:{timestamp}:{body}`, and timestamps older than `MaxAge`
+ *   are rejected as replays
+ * - With a `Version`, the signature is prefixed with `{Version}=`, e.g. `v0=...`
+ */
+type HMACVerifier struct {
+	Secret          string           // Signing secret of the app or webhook
+	SignatureHeader string           // Header holding the signature, e.g. `X-Slack-Signature`
+	TimestampHeader string           // Header holding the epoch time the request was signed at, if any
+	Version         string           // Version of the signature scheme, e.g. `v0`, if any
+	MaxAge          time.Duration    // Maximum age of a timestamp; unlimited when 0
+	Hash            func() hash.Hash // Hash of the HMAC; SHA-256 when nil
+}
+
+func (v *HMACVerifier) Verify(r *http.Request, body []byte) error {
+	return v.verify(r.Header, body, time.Now())
+}
+
+func (v *HMACVerifier) verify(header http.Header, body []byte, now time.Time) error {
+	if v.Secret == "" {
+		return errNoSecret
+	}
+	h := v.Hash
+	if h == nil {
+		h = sha256.New
+	}
+	mac := hmac.New(h, []byte(v.Secret))
+
+	if v.TimestampHeader != "" {
+		timestamp := header.Get(v.TimestampHeader)
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", v.TimestampHeader, timestamp)
+		}
+		if v.MaxAge > 0 && math.Abs(now.Sub(time.Unix(ts, 0)).Seconds()) > v.MaxAge.Seconds() {
+			return fmt.Errorf("stale %s %q", v.TimestampHeader, timestamp)
+		}
+		fmt.Fprintf(mac, "%s:%s:", v.Version, timestamp)
+	}
+	mac.Write(body)
+
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if v.Version != "" {
+		expected = v.Version + "=" + expected
+	}
+	if !hmac.Equal([]byte(header.Get(v.SignatureHeader)), []byte(expected)) {
+		return fmt.Errorf("invalid %s", v.SignatureHeader)
+	}
+	return nil
+}
+
+// HeaderSecret checks that a header holds a shared secret, as sent by Okta event hooks and Jamf Pro webhooks
+type HeaderSecret struct {
+	Header string // Name of the header, e.g. `Authorization`
+	Secret string // Expected value of the header
+}
+
+func (v HeaderSecret) Verify(r *http.Request, body []byte) error {
+	if v.Secret == "" {
+		return errNoSecret
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(v.Header)), []byte(v.Secret)) != 1 {
+		return fmt.Errorf("invalid %s header", v.Header)
+	}
+	return nil
+}
+
+// BasicAuth checks the basic authentication credentials of a request
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+func (v BasicAuth) Verify(r *http.Request, body []byte) error {
+	if v.Username == "" && v.Password == "" {
+		return errNoSecret
+	}
+	username, password, ok := r.BasicAuth()
+	if !ok ||
+		subtle.ConstantTimeCompare([]byte(username), []byte(v.Username)) != 1 ||
+		subtle.ConstantTimeCompare([]byte(password), []byte(v.Password)) != 1 {
+		return fmt.Errorf("invalid credentials")
+	}
+	return nil
+}
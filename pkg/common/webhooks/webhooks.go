@@ -0,0 +1,306 @@
+/*
+# Webhooks
+
+This package initializes an HTTP server which receives webhooks from providers (Okta event hooks, Slack events,
+Jamf webhooks, Zoom webhooks), verifies them, normalizes their payloads into Events, and dispatches them to registered
+callbacks. Other providers are added with `Register`, from a payload type, verifiers and a normalizer:
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/common/webhooks/webhooks.go
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/events"
+	"github.com/gemini-oss/rego/pkg/common/log"
+)
+
+// Provider is the service a webhook was received from
+type Provider string
+
+const (
+	Jamf  Provider = "jamf"
+	Okta  Provider = "okta"
+	Slack Provider = "slack"
+	Zoom  Provider = "zoom"
+)
+
+// Maximum size of a webhook body
+const MaxBodySize = 1 << 20 // 1 MiB
+
+// Event is a webhook payload normalized across providers
+type Event struct {
+	ID       string          `json:"id"`                 // Unique identifier of the event, as assigned by the provider
+	Provider Provider        `json:"provider"`           // Provider the event was received from
+	Type     string          `json:"type"`               // Provider-specific event type, e.g. `user.lifecycle.deactivate`, `team_join`, `ComputerAdded`
+	Time     time.Time       `json:"time"`               // Time the event occurred
+	Actor    string          `json:"actor,omitempty"`    // Who (or what) caused the event, if known
+	Subjects []string        `json:"subjects,omitempty"` // Identifiers of the users/devices the event is about (emails, user IDs, serial numbers)
+	Data     interface{}     `json:"data,omitempty"`     // Typed provider payload {*OktaLogEvent, *SlackEvent, *JamfEvent, *ZoomEvent}
+	Raw      json.RawMessage `json:"-"`                  // The original payload of the event
+}
+
+// Name returns the fully-qualified name of the event, e.g. `okta.user.lifecycle.deactivate`
+func (e *Event) Name() string {
+	return fmt.Sprintf("%s.%s", e.Provider, e.Type)
+}
+
+// normalized maps provider event names to the normalized types of the events package
+var normalized = map[string]string{
+	"jamf.ComputerAdded":                   events.DeviceEnrolled,
+	"jamf.ComputerCheckIn":                 events.DeviceCheckedIn,
+	"jamf.MobileDeviceCheckIn":             events.DeviceCheckedIn,
+	"jamf.MobileDeviceEnrolled":            events.DeviceEnrolled,
+	"jamf.MobileDeviceUnEnrolled":          events.DeviceUnenrolled,
+	"okta.group.user_membership.add":       events.GroupMemberAdded,
+	"okta.group.user_membership.remove":    events.GroupMemberRemoved,
+	"okta.user.lifecycle.activate":         events.UserActivated,
+	"okta.user.lifecycle.create":           events.UserCreated,
+	"okta.user.lifecycle.deactivate":       events.UserDeactivated,
+	"okta.user.lifecycle.delete.initiated": events.UserDeleted,
+	"okta.user.lifecycle.suspend":          events.UserSuspended,
+	"slack.team_join":                      events.UserCreated,
+	"zoom.user.activated":                  events.UserActivated,
+	"zoom.user.created":                    events.UserCreated,
+	"zoom.user.deactivated":                events.UserDeactivated,
+	"zoom.user.deleted":                    events.UserDeleted,
+}
+
+/*
+ * # Normalize an event for the event bus
+ * - Known provider events are given a normalized type, e.g. `okta.user.lifecycle.deactivate` becomes `user.deactivated`
+ * - Other events keep their fully-qualified name, e.g. `slack.message`
+ * - The webhook event is carried as the data of the normalized event
+ */
+func (e *Event) Normalize() *events.Event {
+	eventType, ok := normalized[e.Name()]
+	if !ok {
+		eventType = e.Name()
+	}
+
+	// Slack reports deactivations as a `user_change` whose user is marked deleted
+	if slack, isSlack := e.Data.(*SlackEvent); isSlack && slack.Type == "user_change" {
+		var user struct {
+			Deleted bool `json:"deleted"`
+		}
+		if json.Unmarshal(slack.User, &user) == nil && user.Deleted {
+			eventType = events.UserDeactivated
+		}
+	}
+
+	return &events.Event{
+		ID:       e.ID,
+		Type:     eventType,
+		Source:   string(e.Provider),
+		Time:     e.Time,
+		Actor:    e.Actor,
+		Subjects: e.Subjects,
+		Data:     e,
+	}
+}
+
+// Callback receives dispatched events
+type Callback func(*Event) error
+
+type subscription struct {
+	pattern  string
+	callback Callback
+}
+
+// Server receives webhooks and dispatches their events
+type Server struct {
+	Addr          string           // Address to listen on, e.g. `:8080`
+	Log           *log.Logger      // Logger for the server
+	Bus           events.Publisher // Receives every event, normalized, in addition to the registered callbacks; optional
+	ReplayWindow  time.Duration    // How long the IDs of delivered events are remembered, to drop redeliveries; disabled when 0
+	Insecure      bool             // Register routes without a secret, accepting unauthenticated webhooks, e.g. in local tests
+	mux           *http.ServeMux
+	mutex         sync.RWMutex
+	subscriptions []subscription
+	replays       replays
+	serving       sync.Mutex // Guards `server` and `closed` between `ListenAndServe` and `Shutdown`
+	server        *http.Server
+	closed        bool
+}
+
+/*
+  - # Generate a Webhook Server
+  - @param addr string
+  - @param verbosity int
+  - @return *Server
+  - Example:
+
+```go
+
+	s := webhooks.NewServer(":8080", log.INFO)
+	err := errors.Join(
+		s.Okta("/okta", webhooks.OktaConfig{Secret: os.Getenv("OKTA_HOOK_SECRET")}),
+		s.Slack("/slack", os.Getenv("SLACK_SIGNING_SECRET")),
+		s.Zoom("/zoom", os.Getenv("ZOOM_WEBHOOK_SECRET_TOKEN")),
+	)
+	s.On("okta.user.lifecycle.deactivate", func(e *webhooks.Event) error {
+		fmt.Println("Deactivated:", e.Subjects)
+		return nil
+	})
+	s.ListenAndServe()
+
+```
+*/
+func NewServer(addr string, verbosity int) *Server {
+	return &Server{
+		Addr:         addr,
+		Log:          log.NewLogger("{webhooks}", verbosity),
+		ReplayWindow: DefaultReplayWindow,
+		mux:          http.NewServeMux(),
+	}
+}
+
+/*
+ * # Register a callback for events
+ * - `*` matches every event
+ * - `okta.*` matches every event from a provider (or any other prefix)
+ * - `okta.user.lifecycle.deactivate` matches a single event type
+ */
+func (s *Server) On(pattern string, callback Callback) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.subscriptions = append(s.subscriptions, subscription{pattern: pattern, callback: callback})
+}
+
+// Handle registers a custom handler on the server's mux
+func (s *Server) Handle(path string, handler http.Handler) {
+	s.mux.Handle(path, handler)
+}
+
+// Dispatch sends events to every matching callback, returning the joined errors of the callbacks
+func (s *Server) Dispatch(events ...*Event) error {
+	s.mutex.RLock()
+	subscriptions := append([]subscription{}, s.subscriptions...)
+	s.mutex.RUnlock()
+
+	var errs []error
+	for _, e := range events {
+		s.Log.Debugf("Dispatching %s [%s]", e.Name(), e.ID)
+		for _, sub := range subscriptions {
+			if !matches(sub.pattern, e) {
+				continue
+			}
+			if err := sub.callback(e); err != nil {
+				s.Log.Errorf("Callback for %s failed on %s: %v", sub.pattern, e.ID, err)
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func matches(pattern string, e *Event) bool {
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasSuffix(pattern, "*"):
+		return strings.HasPrefix(e.Name(), strings.TrimSuffix(pattern, "*"))
+	default:
+		return pattern == e.Name()
+	}
+}
+
+// ServeHTTP serves the registered webhook routes
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe starts receiving webhooks on `Addr`, blocking until the server is shut down; it returns at once after `Shutdown`
+func (s *Server) ListenAndServe() error {
+	s.serving.Lock()
+	if s.closed {
+		s.serving.Unlock()
+		return nil
+	}
+	server := &http.Server{
+		Addr:              s.Addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	s.server = server
+	s.serving.Unlock()
+
+	s.Log.Printf("Listening for webhooks on %s", s.Addr)
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Shutdown gracefully stops the server, waiting for in-flight webhooks to be dispatched
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.serving.Lock()
+	s.closed = true
+	server := s.server
+	s.serving.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
+
+// readBody reads a webhook body, bounded by `MaxBodySize`
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	return io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+}
+
+// deliver dispatches the events of a webhook, publishes them to the bus, and acknowledges it
+// - Callback errors are logged, not returned to the provider, so that it does not retry an accepted delivery
+// - Events already delivered within the `ReplayWindow` are acknowledged without being dispatched again
+func (s *Server) deliver(w http.ResponseWriter, delivered []*Event) {
+	now := time.Now()
+	received := make([]*Event, 0, len(delivered))
+	for _, e := range delivered {
+		if s.replays.replayed(e, s.ReplayWindow, now) {
+			s.Log.Debugf("Dropping replayed %s [%s]", e.Name(), e.ID)
+			continue
+		}
+		received = append(received, e)
+	}
+
+	s.Dispatch(received...)
+	if s.Bus != nil && len(received) > 0 {
+		normalized := make([]*events.Event, 0, len(received))
+		for _, e := range received {
+			normalized = append(normalized, e.Normalize())
+		}
+		if err := s.Bus.Publish(normalized...); err != nil {
+			s.Log.Errorf("Publishing %d events: %v", len(normalized), err)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) reject(w http.ResponseWriter, status int, format string, v ...interface{}) {
+	s.Log.Warningf(format, v...)
+	http.Error(w, http.StatusText(status), status)
+}
+
+func subjects(values ...string) []string {
+	out := []string{}
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+/*
+# DocuSign - Accounts
+
+This package contains all the methods to interact with the DocuSign Accounts and Billing APIs:
+https://developers.docusign.com/docs/esign-rest-api/reference/billing/
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/docusign/accounts.go
+package docusign
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AccountClient for chaining methods
+type AccountClient struct {
+	*Client
+}
+
+// Entry point for account-related operations
+func (c *Client) Accounts() *AccountClient {
+	return &AccountClient{
+		Client: c,
+	}
+}
+
+/*
+ * # Get the billing plan for the account
+ * /restapi/v2.1/accounts/{accountId}/billing_plan
+ * - https://developers.docusign.com/docs/esign-rest-api/reference/billing/billingplans/get/
+ */
+func (c *AccountClient) GetBillingPlan() (*BillingPlanResponse, error) {
+	url := c.BuildURL(DocuSignBillingPlan)
+
+	var cache BillingPlanResponse
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	plan, err := do[BillingPlanResponse](c.Client, "GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetCache(url, plan, 1*time.Hour)
+	return &plan, nil
+}
+
+/*
+ * # List the billing charges for the account
+ * /restapi/v2.1/accounts/{accountId}/billing_charges
+ * - https://developers.docusign.com/docs/esign-rest-api/reference/billing/billingcharges/list/
+ */
+func (c *AccountClient) ListBillingCharges() (*BillingCharges, error) {
+	url := c.BuildURL(DocuSignBillingCharges)
+
+	var cache BillingCharges
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	charges, err := do[BillingCharges](c.Client, "GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetCache(url, charges, 1*time.Hour)
+	return &charges, nil
+}
+
+/*
+ * # Generate a usage report for the account
+ * - Combines the billing plan, billing charges, and user list into a seat/envelope summary
+ */
+func (c *AccountClient) UsageReport() (*UsageReport, error) {
+	plan, err := c.GetBillingPlan()
+	if err != nil {
+		return nil, err
+	}
+
+	charges, err := c.ListBillingCharges()
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := c.Users().ListAllUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UsageReport{
+		Charges: make(map[string]int),
+	}
+
+	if plan.BillingPlan != nil {
+		report.PlanName = plan.BillingPlan.PlanName
+		report.IncludedSeats, _ = strconv.Atoi(plan.BillingPlan.IncludedSeats)
+	}
+
+	for _, charge := range charges.BillingChargeItems {
+		used, _ := strconv.Atoi(charge.UsedQuantity)
+		report.Charges[charge.ChargeType] += used
+	}
+
+	for _, user := range *users {
+		switch strings.ToLower(user.UserStatus) {
+		case "active":
+			report.ActiveUsers++
+			if strings.EqualFold(user.IsAdmin, "true") {
+				report.AdminUsers++
+			}
+		case "activationrequired", "activationsent":
+			report.PendingUsers++
+		case "closed":
+			report.ClosedUsers++
+		}
+	}
+
+	report.AvailableSeats = report.IncludedSeats - report.ActiveUsers - report.PendingUsers
+
+	return report, nil
+}
@@ -0,0 +1,240 @@
+/*
+# DocuSign
+
+This package initializes all the methods for functions which interact with the DocuSign eSignature REST API:
+https://developers.docusign.com/docs/esign-rest-api/reference/
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/docusign/docusign.go
+package docusign
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/config"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/ratelimit"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+var (
+	BaseURL = fmt.Sprintf("%s/restapi/v2.1/accounts/%s", "%s", "%s") // https://developers.docusign.com/docs/esign-rest-api/esign101/concepts/endpoints/
+)
+
+const (
+	DocuSignBillingCharges = "%s/billing_charges"          // https://developers.docusign.com/docs/esign-rest-api/reference/billing/billingcharges/
+	DocuSignBillingPlan    = "%s/billing_plan"             // https://developers.docusign.com/docs/esign-rest-api/reference/billing/billingplans/
+	DocuSignEnvelopes      = "%s/envelopes"                // https://developers.docusign.com/docs/esign-rest-api/reference/envelopes/envelopes/
+	DocuSignTransferRules  = "%s/envelopes/transfer_rules" // https://developers.docusign.com/docs/esign-rest-api/reference/envelopes/envelopetransferrules/
+	DocuSignUsers          = "%s/users"                    // https://developers.docusign.com/docs/esign-rest-api/reference/users/users/
+)
+
+// BuildURL builds a URL for a given resource and identifiers.
+func (c *Client) BuildURL(endpoint string, identifiers ...string) string {
+	url := fmt.Sprintf(endpoint, c.BaseURL)
+	for _, id := range identifiers {
+		url = fmt.Sprintf("%s/%s", url, id)
+	}
+	c.Log.Debug("url:", url)
+	return url
+}
+
+/*
+ * # With Context
+ * Returns a copy of the client whose requests are bound to `ctx`
+ * - Cancelling `ctx` aborts the request in flight, and stops its retries
+ */
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cc := *c
+	cc.HTTP = c.HTTP.WithContext(ctx)
+	return &cc
+}
+
+// Close closes the HTTP client (see `requests.Client.Close`), then flushes the cache (see `cache.Cache.Flush`)
+func (c *Client) Close(ctx context.Context) error {
+	return errors.Join(c.HTTP.Close(ctx), c.Cache.Flush())
+}
+
+// Name returns the name of the service, `docusign`
+func (c *Client) Name() string {
+	return "docusign"
+}
+
+// Endpoint returns the base URL of the API the client sends its requests to
+func (c *Client) Endpoint() string {
+	return c.BaseURL
+}
+
+/*
+ * # Check the Health of the DocuSign Client
+ * Gets the account of the client, so the API is reachable and the token valid
+ * /restapi/v2.1/accounts/{accountId}
+ */
+func (c *Client) HealthCheck(ctx context.Context) error {
+	_, _, err := c.HTTP.DoRequestContext(ctx, "GET", c.BaseURL, nil, nil)
+	return rerrors.WithProvider(err, "docusign")
+}
+
+// UseCache() enables caching for the next method call.
+func (c *Client) UseCache() *Client {
+	c.Cache.Enabled = true
+	return c
+}
+
+/*
+ * SetCache stores a DocuSign API response in the cache
+ */
+func (c *Client) SetCache(key string, value interface{}, duration time.Duration) {
+	// Convert value to a byte slice and cache it
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.Log.Error("Error marshalling cache data:", err)
+		return
+	}
+	c.Cache.Set(key, data, duration)
+}
+
+/*
+ * GetCache retrieves a DocuSign API response from the cache
+ */
+func (c *Client) GetCache(key string, target interface{}) bool {
+	data, found := c.Cache.Get(key)
+	if !found || !c.Cache.Enabled {
+		return false
+	}
+
+	err := json.Unmarshal(data, target)
+	if err != nil {
+		c.Log.Error("Error unmarshalling cache data:", err)
+		return false
+	}
+	return true
+}
+
+/*
+  - # Generate DocuSign Client
+  - @param verbosity int
+  - @return *Client
+  - Example:
+
+```go
+
+	d := docusign.NewClient(log.DEBUG)
+
+```
+*/
+func NewClient(verbosity int) *Client {
+	log := log.NewLogger("{docusign}", verbosity)
+
+	url := config.GetEnv("DOCUSIGN_BASE_URL") // https://{server}.docusign.net
+	if len(url) == 0 {
+		log.Fatal("DOCUSIGN_BASE_URL is not set")
+	}
+	url = strings.TrimSuffix(url, "/")
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = "https://" + url
+	}
+
+	accountID := config.GetEnv("DOCUSIGN_ACCOUNT_ID")
+	if len(accountID) == 0 {
+		log.Fatal("DOCUSIGN_ACCOUNT_ID is not set")
+	}
+
+	token := config.GetEnv("DOCUSIGN_ACCESS_TOKEN")
+	if len(token) == 0 {
+		log.Fatal("DOCUSIGN_ACCESS_TOKEN is not set")
+	}
+
+	headers := requests.Headers{
+		"Authorization": "Bearer " + token,
+		"Accept":        requests.JSON,
+		"Content-Type":  requests.JSON,
+	}
+
+	encryptionKey := []byte(config.GetEnv("REGO_ENCRYPTION_KEY"))
+	if len(encryptionKey) == 0 {
+		log.Fatal("REGO_ENCRYPTION_KEY is not set")
+	}
+
+	cache, err := cache.NewCache(encryptionKey, "rego_cache_docusign.gob", 1000000)
+	if err != nil {
+		panic(err)
+	}
+
+	// https://developers.docusign.com/docs/esign-rest-api/esign101/rules-and-limits/
+	rl := ratelimit.NewRateLimiter(3000, 1*time.Hour, ratelimit.DocuSign)
+	rl.Log.Verbosity = verbosity
+
+	httpClient := requests.NewClient(nil, headers, rl, requests.WithUserAgent("docusign"))
+	httpClient.BodyType = requests.JSON
+
+	return &Client{
+		BaseURL:   fmt.Sprintf(BaseURL, url, accountID),
+		AccountID: accountID,
+		HTTP:      httpClient,
+		Log:       log,
+		Cache:     cache,
+	}
+}
+
+/*
+ * Perform a generic request to the DocuSign API
+ */
+func do[T any](c *Client, method string, url string, query interface{}, data interface{}) (T, error) {
+	var result T
+	res, body, err := c.HTTP.DoRequest(method, url, query, data)
+	if err != nil {
+		return *new(T), rerrors.WithProvider(err, "docusign")
+	}
+
+	c.Log.Println("Response Status:", res.Status)
+	c.Log.Debug("Response Body:", string(body))
+
+	if len(body) == 0 {
+		return result, nil
+	}
+
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return *new(T), fmt.Errorf("unmarshalling error: %w", err)
+	}
+
+	return result, nil
+}
+
+/*
+ * Generically perform a paginated request to the DocuSign API
+ * - DocuSign pages with `start_position`/`count` and reports `endPosition`/`totalSetSize` on each page
+ */
+func doPaginated[T PaginatedResponse[E], E any](c *Client, method string, url string, query PageQuery, data interface{}) (*[]*E, error) {
+	results := make([]*E, 0)
+
+	for {
+		page, err := do[T](c, method, url, query, data)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, page.Elements()...)
+
+		end, total := page.Positions()
+		if len(page.Elements()) == 0 || end+1 >= total {
+			break
+		}
+		query.SetStartPosition(strconv.Itoa(end + 1))
+	}
+
+	return &results, nil
+}
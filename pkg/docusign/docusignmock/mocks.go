@@ -0,0 +1,147 @@
+// Code generated by mockgen from interfaces.go; DO NOT EDIT.
+
+// pkg/docusign/docusignmock/mocks.go
+package docusignmock
+
+import (
+	"sync"
+
+	"github.com/gemini-oss/rego/pkg/docusign"
+)
+
+// Call is a call received by a mock
+type Call struct {
+	Method string        // Name of the method called
+	Args   []interface{} // Arguments of the call
+}
+
+// calls records the calls received by a mock
+type calls struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+func (c *calls) record(method string, args ...interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls received, in order; only those of `method` when set
+func (c *calls) Calls(method ...string) []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	received := []Call{}
+	for _, call := range c.calls {
+		if len(method) == 0 || call.Method == method[0] {
+			received = append(received, call)
+		}
+	}
+	return received
+}
+
+// AccountService is a mock of `docusign.AccountService`; methods without a func return zero values
+type AccountService struct {
+	GetBillingPlanFunc     func() (*docusign.BillingPlanResponse, error)
+	ListBillingChargesFunc func() (*docusign.BillingCharges, error)
+	UsageReportFunc        func() (*docusign.UsageReport, error)
+
+	calls
+}
+
+var _ docusign.AccountService = (*AccountService)(nil)
+
+func (m *AccountService) GetBillingPlan() (r0 *docusign.BillingPlanResponse, r1 error) {
+	m.record("GetBillingPlan")
+	if m.GetBillingPlanFunc != nil {
+		return m.GetBillingPlanFunc()
+	}
+	return
+}
+
+func (m *AccountService) ListBillingCharges() (r0 *docusign.BillingCharges, r1 error) {
+	m.record("ListBillingCharges")
+	if m.ListBillingChargesFunc != nil {
+		return m.ListBillingChargesFunc()
+	}
+	return
+}
+
+func (m *AccountService) UsageReport() (r0 *docusign.UsageReport, r1 error) {
+	m.record("UsageReport")
+	if m.UsageReportFunc != nil {
+		return m.UsageReportFunc()
+	}
+	return
+}
+
+// UserService is a mock of `docusign.UserService`; methods without a func return zero values
+type UserService struct {
+	ListAllUsersFunc      func() (*docusign.Users, error)
+	GetUserFunc           func(string) (*docusign.User, error)
+	GetUserByEmailFunc    func(string) (*docusign.User, error)
+	CloseUsersFunc        func(...string) (*docusign.UserInfoList, error)
+	CloseUserFunc         func(string) error
+	TransferEnvelopesFunc func(string, string, bool) (*docusign.EnvelopeTransferRules, error)
+	OffboardUserFunc      func(string, string) error
+
+	calls
+}
+
+var _ docusign.UserService = (*UserService)(nil)
+
+func (m *UserService) ListAllUsers() (r0 *docusign.Users, r1 error) {
+	m.record("ListAllUsers")
+	if m.ListAllUsersFunc != nil {
+		return m.ListAllUsersFunc()
+	}
+	return
+}
+
+func (m *UserService) GetUser(a0 string) (r0 *docusign.User, r1 error) {
+	m.record("GetUser", a0)
+	if m.GetUserFunc != nil {
+		return m.GetUserFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) GetUserByEmail(a0 string) (r0 *docusign.User, r1 error) {
+	m.record("GetUserByEmail", a0)
+	if m.GetUserByEmailFunc != nil {
+		return m.GetUserByEmailFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) CloseUsers(a0 ...string) (r0 *docusign.UserInfoList, r1 error) {
+	m.record("CloseUsers", a0)
+	if m.CloseUsersFunc != nil {
+		return m.CloseUsersFunc(a0...)
+	}
+	return
+}
+
+func (m *UserService) CloseUser(a0 string) (r0 error) {
+	m.record("CloseUser", a0)
+	if m.CloseUserFunc != nil {
+		return m.CloseUserFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) TransferEnvelopes(a0 string, a1 string, a2 bool) (r0 *docusign.EnvelopeTransferRules, r1 error) {
+	m.record("TransferEnvelopes", a0, a1, a2)
+	if m.TransferEnvelopesFunc != nil {
+		return m.TransferEnvelopesFunc(a0, a1, a2)
+	}
+	return
+}
+
+func (m *UserService) OffboardUser(a0 string, a1 string) (r0 error) {
+	m.record("OffboardUser", a0, a1)
+	if m.OffboardUserFunc != nil {
+		return m.OffboardUserFunc(a0, a1)
+	}
+	return
+}
@@ -0,0 +1,241 @@
+/*
+# DocuSign - Entities [Structs]
+
+This package contains many structs for handling responses from the DocuSign eSignature REST API:
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/docusign/entities.go
+package docusign
+
+import (
+	"strconv"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/normalize"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+// ### DocuSign Client Entities
+// ---------------------------------------------------------------------
+type Client struct {
+	BaseURL   string           // BaseURL is the base URL for DocuSign API requests, scoped to the account.
+	AccountID string           // AccountID is the API account ID (GUID) of the DocuSign account.
+	HTTP      *requests.Client // HTTP is the client used to make HTTP requests.
+	Error     *Error           // Error is the error response from the last request made by the client.
+	Log       *log.Logger      // Log is the logger used to log messages.
+	Cache     *cache.Cache     // Cache is the cache used to store responses from the DocuSign API.
+}
+
+// Error represents the common error response from the DocuSign API.
+type Error struct {
+	ErrorCode string `json:"errorCode,omitempty"` // The code associated with the error condition.
+	Message   string `json:"message,omitempty"`   // A brief message describing the error condition.
+}
+
+// PaginatedResponse is an interface for DocuSign API responses involving pagination
+type PaginatedResponse[E any] interface {
+	Elements() []*E
+	Positions() (end int, total int)
+}
+
+// PageQuery is an interface for query parameters that support DocuSign's `start_position` pagination
+type PageQuery interface {
+	SetStartPosition(string)
+}
+
+// Page contains the pagination properties included with every DocuSign list response.
+type Page struct {
+	EndPosition   string `json:"endPosition,omitempty"`   // The last index position in the result set.
+	NextURI       string `json:"nextUri,omitempty"`       // The URI for the next chunk of records based on the search request.
+	PreviousURI   string `json:"previousUri,omitempty"`   // The URI for the prior chunk of records based on the search request.
+	ResultSetSize string `json:"resultSetSize,omitempty"` // The number of results in this response.
+	StartPosition string `json:"startPosition,omitempty"` // The starting index position of the current result set.
+	TotalSetSize  string `json:"totalSetSize,omitempty"`  // The total number of items in the result set.
+}
+
+func (p Page) Positions() (int, int) {
+	end, _ := strconv.Atoi(p.EndPosition)
+	total, _ := strconv.Atoi(p.TotalSetSize)
+	return end, total
+}
+
+// END OF DOCUSIGN CLIENT ENTITIES
+//---------------------------------------------------------------------
+
+// ### DocuSign User Structs
+// ---------------------------------------------------------------------
+type UserList struct {
+	Users []*User `json:"users,omitempty"` // The users in this page of results.
+	Page
+}
+
+func (u UserList) Elements() []*User {
+	return u.Users
+}
+
+type Users []*User
+
+type User struct {
+	ActivationAccessCode string       `json:"activationAccessCode,omitempty"`  // Access code provided to the user to activate the account.
+	Company              string       `json:"company,omitempty"`               // The name of the user's company.
+	CreatedDateTime      string       `json:"createdDateTime,omitempty"`       // The date and time the user was created.
+	Email                string       `json:"email,omitempty"`                 // The user's email address.
+	ErrorDetails         *Error       `json:"errorDetails,omitempty"`          // Error details, if an operation on this user failed.
+	FirstName            string       `json:"firstName,omitempty"`             // The user's first name.
+	GroupList            []*Group     `json:"groupList,omitempty"`             // The groups the user belongs to.
+	IsAdmin              string       `json:"isAdmin,omitempty"`               // "True" if the user is an account administrator.
+	JobTitle             string       `json:"jobTitle,omitempty"`              // The user's job title.
+	LastLogin            string       `json:"lastLogin,omitempty"`             // The date and time the user last logged in.
+	LastName             string       `json:"lastName,omitempty"`              // The user's last name.
+	LoginStatus          string       `json:"loginStatus,omitempty"`           // Whether the user can log in.
+	PermissionProfileID  string       `json:"permissionProfileId,omitempty"`   // The ID of the user's permission profile.
+	PermissionProfile    string       `json:"permissionProfileName,omitempty"` // The name of the user's permission profile.
+	UserID               string       `json:"userId,omitempty"`                // The user's ID (GUID).
+	UserName             string       `json:"userName,omitempty"`              // The user's full name.
+	UserStatus           string       `json:"userStatus,omitempty"`            // The status of the user {active, activationRequired, activationSent, closed, disabled}
+	UserType             string       `json:"userType,omitempty"`              // The type of user {CompanyUser, ...}
+	Settings             *UserSetting `json:"userSettings,omitempty"`          // A subset of the user's settings.
+}
+
+// AccountStatus returns the normalized status of the user
+func (u *User) AccountStatus() normalize.Status {
+	switch u.UserStatus {
+	case "active":
+		return normalize.StatusActive
+	case "activationRequired", "activationSent":
+		return normalize.StatusPending
+	case "disabled":
+		return normalize.StatusSuspended
+	case "closed":
+		return normalize.StatusDeprovisioned
+	}
+	return normalize.StatusUnknown
+}
+
+type UserSetting struct {
+	AccountManagementGranular map[string]interface{} `json:"accountManagementGranular,omitempty"` // Granular account management permissions.
+	CanManageAccount          string                 `json:"canManageAccount,omitempty"`          // "true" if the user can manage account settings.
+	CanSendEnvelope           string                 `json:"canSendEnvelope,omitempty"`           // "true" if the user can send envelopes.
+	Locale                    string                 `json:"locale,omitempty"`                    // The user's locale.
+}
+
+type Group struct {
+	GroupID   string `json:"groupId,omitempty"`   // The DocuSign group ID.
+	GroupName string `json:"groupName,omitempty"` // The name of the group.
+	GroupType string `json:"groupType,omitempty"` // The group type {adminGroup, customGroup, everyoneGroup}
+}
+
+// UserInfoList is the payload/response for bulk user operations such as closing users.
+type UserInfoList struct {
+	Users []*User `json:"users,omitempty"` // The users to act on.
+}
+
+// END OF DOCUSIGN USER STRUCTS
+//---------------------------------------------------------------------
+
+// ### DocuSign Envelope Structs
+// ---------------------------------------------------------------------
+type EnvelopeList struct {
+	Envelopes []*Envelope `json:"envelopes,omitempty"` // The envelopes in this page of results.
+	Page
+}
+
+func (e EnvelopeList) Elements() []*Envelope {
+	return e.Envelopes
+}
+
+type Envelopes []*Envelope
+
+type Envelope struct {
+	CompletedDateTime normalize.Time `json:"completedDateTime,omitempty"`     // The date and time the envelope was completed.
+	CreatedDateTime   normalize.Time `json:"createdDateTime,omitempty"`       // The date and time the envelope was created.
+	EmailSubject      string         `json:"emailSubject,omitempty"`          // The subject line of the envelope email.
+	EnvelopeID        string         `json:"envelopeId,omitempty"`            // The envelope's ID (GUID).
+	Sender            *User          `json:"sender,omitempty"`                // The sender of the envelope.
+	SentDateTime      normalize.Time `json:"sentDateTime,omitempty"`          // The date and time the envelope was sent.
+	Status            string         `json:"status,omitempty"`                // The status of the envelope {created, sent, delivered, signed, completed, declined, voided}
+	StatusChanged     normalize.Time `json:"statusChangedDateTime,omitempty"` // The date and time the status last changed.
+}
+
+// EnvelopeTransferRules is the payload/response for the envelope transfer rules endpoint.
+type EnvelopeTransferRules struct {
+	EnvelopeTransferRules []*EnvelopeTransferRule `json:"envelopeTransferRules,omitempty"` // The transfer rules.
+	Page
+}
+
+type EnvelopeTransferRule struct {
+	CarbonCopyOriginalOwner string   `json:"carbonCopyOriginalOwner,omitempty"` // "true" to send a copy of transferred envelopes to the original owner.
+	Enabled                 string   `json:"enabled,omitempty"`                 // "true" if the rule is enabled.
+	EnvelopeTransferRuleID  string   `json:"envelopeTransferRuleId,omitempty"`  // The ID of the rule.
+	EventDirection          string   `json:"eventDirection,omitempty"`          // The direction of the transfer {in, out, both}
+	FromGroup               *Group   `json:"fromGroup,omitempty"`               // The group the envelopes are transferred from.
+	FromUser                *User    `json:"fromUser,omitempty"`                // The user the envelopes are transferred from.
+	ModifiedDate            string   `json:"modifiedDate,omitempty"`            // The date the rule was last modified.
+	ModifiedUser            *User    `json:"modifiedUser,omitempty"`            // The user who last modified the rule.
+	ToFolder                *Folder  `json:"toFolder,omitempty"`                // The folder the envelopes are transferred to.
+	ToUser                  *User    `json:"toUser,omitempty"`                  // The user the envelopes are transferred to.
+	FromGroups              []*Group `json:"fromGroups,omitempty"`              // Request only: the groups the envelopes are transferred from.
+	FromUsers               []*User  `json:"fromUsers,omitempty"`               // Request only: the users the envelopes are transferred from.
+}
+
+type Folder struct {
+	FolderID string `json:"folderId,omitempty"` // The ID of the folder.
+	Name     string `json:"name,omitempty"`     // The name of the folder.
+}
+
+// END OF DOCUSIGN ENVELOPE STRUCTS
+//---------------------------------------------------------------------
+
+// ### DocuSign Account Structs
+// ---------------------------------------------------------------------
+type BillingPlanResponse struct {
+	BillingPlan    *BillingPlan   `json:"billingPlan,omitempty"`    // The account's billing plan.
+	PaymentMethod  string         `json:"paymentMethod,omitempty"`  // The payment method used for the account.
+	SuccessorPlans []*BillingPlan `json:"successorPlans,omitempty"` // Plans the account can upgrade to.
+}
+
+type BillingPlan struct {
+	CurrencyCode       string `json:"currencyCode,omitempty"`       // The ISO currency code for the account.
+	IncludedSeats      string `json:"includedSeats,omitempty"`      // The number of seats included in the plan.
+	PaymentCycle       string `json:"paymentCycle,omitempty"`       // The payment cycle {monthly, annually}
+	PerSeatPrice       string `json:"perSeatPrice,omitempty"`       // The per-seat price for the plan.
+	PlanClassification string `json:"planClassification,omitempty"` // The plan's classification.
+	PlanID             string `json:"planId,omitempty"`             // The ID of the plan.
+	PlanName           string `json:"planName,omitempty"`           // The name of the plan.
+}
+
+type BillingCharges struct {
+	BillingChargeItems []*BillingCharge `json:"billingChargeItems,omitempty"` // The charges on the account.
+}
+
+type BillingCharge struct {
+	Blocked             string `json:"blocked,omitempty"`             // "true" if the charge has been blocked.
+	ChargeName          string `json:"chargeName,omitempty"`          // The name of the charge.
+	ChargeType          string `json:"chargeType,omitempty"`          // The type of charge {envelopes, seats}
+	ChargeUnitOfMeasure string `json:"chargeUnitOfMeasure,omitempty"` // The unit of measure for the charge.
+	FirstEffectiveDate  string `json:"firstEffectiveDate,omitempty"`  // The date the charge became effective.
+	IncludedQuantity    string `json:"includedQuantity,omitempty"`    // The quantity included in the plan.
+	LastEffectiveDate   string `json:"lastEffectiveDate,omitempty"`   // The date the charge stops being effective.
+	UnitPrice           string `json:"unitPrice,omitempty"`           // The unit price of the charge.
+	UsedQuantity        string `json:"usedQuantity,omitempty"`        // The quantity used in the current billing period.
+}
+
+// UsageReport summarizes seat and envelope consumption for the account.
+type UsageReport struct {
+	PlanName       string         // The name of the account's billing plan.
+	IncludedSeats  int            // Seats included in the plan.
+	ActiveUsers    int            // Users with an `active` status.
+	PendingUsers   int            // Users who have not yet activated their account.
+	ClosedUsers    int            // Users who have been closed.
+	AdminUsers     int            // Active users who are account administrators.
+	AvailableSeats int            // IncludedSeats minus seats consumed by active and pending users.
+	Charges        map[string]int // Used quantity keyed by charge type (e.g. `envelopes`, `seats`).
+}
+
+// END OF DOCUSIGN ACCOUNT STRUCTS
+//---------------------------------------------------------------------
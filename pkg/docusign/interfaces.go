@@ -0,0 +1,38 @@
+/*
+# DocuSign Interfaces
+
+This package contains the interfaces of each DocuSign resource, which the resource clients satisfy. Code depending on an
+interface rather than the clients can be unit tested with the mocks of `pkg/docusign/docusignmock`
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/docusign/interfaces.go
+package docusign
+
+//go:generate go run ../../cmd/mockgen
+
+// AccountService reads the billing and usage of the account
+type AccountService interface {
+	GetBillingPlan() (*BillingPlanResponse, error)
+	ListBillingCharges() (*BillingCharges, error)
+	UsageReport() (*UsageReport, error)
+}
+
+// UserService manages users and their envelopes
+type UserService interface {
+	ListAllUsers() (*Users, error)
+	GetUser(userID string) (*User, error)
+	GetUserByEmail(email string) (*User, error)
+	CloseUsers(userIDs ...string) (*UserInfoList, error)
+	CloseUser(userID string) error
+	TransferEnvelopes(fromUserID, toUserID string, carbonCopyOriginalOwner bool) (*EnvelopeTransferRules, error)
+	OffboardUser(email string, transferToEmail string) error
+}
+
+var (
+	_ AccountService = (*AccountClient)(nil)
+	_ UserService    = (*UserClient)(nil)
+)
@@ -0,0 +1,216 @@
+/*
+# DocuSign - Users
+
+This package contains all the methods to interact with the DocuSign Users API:
+https://developers.docusign.com/docs/esign-rest-api/reference/users/users/
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/docusign/users.go
+package docusign
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UserClient for chaining methods
+type UserClient struct {
+	*Client
+}
+
+// Entry point for user-related operations
+func (c *Client) Users() *UserClient {
+	return &UserClient{
+		Client: c,
+	}
+}
+
+/*
+ * Query Parameters for Users
+ * https://developers.docusign.com/docs/esign-rest-api/reference/users/users/list/#request
+ */
+type UserQuery struct {
+	AdditionalInfo string `url:"additional_info,omitempty"`     // When "true", the full list of user information is returned for each user.
+	Count          string `url:"count,omitempty"`               // The number of records to return. Maximum: 100.
+	Email          string `url:"email,omitempty"`               // Filters returned user records by full email address.
+	GroupID        string `url:"group_id,omitempty"`            // Filters results based on one or more group IDs.
+	StartPosition  string `url:"start_position,omitempty"`      // The position within the total result set from which to start returning values.
+	Status         string `url:"status,omitempty"`              // Filters results by user account status {ActivationRequired, ActivationSent, Active, Closed, Disabled}
+	UserNameSubstr string `url:"user_name_substring,omitempty"` // Filters results based on a full or partial user name.
+}
+
+func (q *UserQuery) SetStartPosition(start string) {
+	q.StartPosition = start
+}
+
+/*
+ * # List all users in the account
+ * /restapi/v2.1/accounts/{accountId}/users
+ * - https://developers.docusign.com/docs/esign-rest-api/reference/users/users/list/
+ */
+func (c *UserClient) ListAllUsers() (*Users, error) {
+	url := c.BuildURL(DocuSignUsers)
+
+	var cache Users
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	q := &UserQuery{
+		AdditionalInfo: "true",
+		Count:          "100",
+	}
+
+	users, err := doPaginated[UserList](c.Client, "GET", url, q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := Users(*users)
+	c.SetCache(url, result, 30*time.Minute)
+	return &result, nil
+}
+
+/*
+ * # Get a user by ID
+ * /restapi/v2.1/accounts/{accountId}/users/{userId}
+ * - https://developers.docusign.com/docs/esign-rest-api/reference/users/users/get/
+ */
+func (c *UserClient) GetUser(userID string) (*User, error) {
+	url := c.BuildURL(DocuSignUsers, userID)
+
+	var cache User
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	user, err := do[User](c.Client, "GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetCache(url, user, 5*time.Minute)
+	return &user, nil
+}
+
+/*
+ * # Find a user by email address
+ * /restapi/v2.1/accounts/{accountId}/users?email={email}
+ * - https://developers.docusign.com/docs/esign-rest-api/reference/users/users/list/
+ */
+func (c *UserClient) GetUserByEmail(email string) (*User, error) {
+	url := c.BuildURL(DocuSignUsers)
+
+	q := &UserQuery{
+		AdditionalInfo: "true",
+		Email:          email,
+	}
+
+	users, err := doPaginated[UserList](c.Client, "GET", url, q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range *users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+
+	return nil, fmt.Errorf("user with email %s not found", email)
+}
+
+/*
+ * # Close one or more users in the account
+ * - Closed users can no longer log in, but their envelopes and history are retained
+ * /restapi/v2.1/accounts/{accountId}/users
+ * - https://developers.docusign.com/docs/esign-rest-api/reference/users/users/delete/
+ */
+func (c *UserClient) CloseUsers(userIDs ...string) (*UserInfoList, error) {
+	url := c.BuildURL(DocuSignUsers)
+
+	payload := &UserInfoList{}
+	for _, id := range userIDs {
+		payload.Users = append(payload.Users, &User{UserID: id})
+	}
+
+	c.Log.Printf("Closing %d DocuSign user(s)", len(userIDs))
+	closed, err := do[UserInfoList](c.Client, "DELETE", url, nil, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range closed.Users {
+		if user.ErrorDetails != nil && user.ErrorDetails.ErrorCode != "" {
+			return &closed, fmt.Errorf("closing user %s: %s", user.UserID, user.ErrorDetails.Message)
+		}
+	}
+
+	return &closed, nil
+}
+
+/*
+ * # Close a single user in the account
+ * /restapi/v2.1/accounts/{accountId}/users
+ * - https://developers.docusign.com/docs/esign-rest-api/reference/users/users/delete/
+ */
+func (c *UserClient) CloseUser(userID string) error {
+	_, err := c.CloseUsers(userID)
+	return err
+}
+
+/*
+ * # Transfer a user's envelopes to another user
+ * - Creates an envelope transfer rule which moves the envelopes owned by `fromUserID` to `toUserID`
+ * /restapi/v2.1/accounts/{accountId}/envelopes/transfer_rules
+ * - https://developers.docusign.com/docs/esign-rest-api/reference/envelopes/envelopetransferrules/create/
+ */
+func (c *UserClient) TransferEnvelopes(fromUserID, toUserID string, carbonCopyOriginalOwner bool) (*EnvelopeTransferRules, error) {
+	url := c.BuildURL(DocuSignTransferRules)
+
+	payload := &EnvelopeTransferRule{
+		CarbonCopyOriginalOwner: fmt.Sprintf("%t", carbonCopyOriginalOwner),
+		Enabled:                 "true",
+		EventDirection:          "in",
+		FromUsers:               []*User{{UserID: fromUserID}},
+		ToUser:                  &User{UserID: toUserID},
+	}
+
+	c.Log.Printf("Transferring envelopes from %s to %s", fromUserID, toUserID)
+	rules, err := do[EnvelopeTransferRules](c.Client, "POST", url, nil, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rules, nil
+}
+
+/*
+ * # Offboard a user by email
+ * - Transfers the user's envelopes to `transferToEmail` (when provided) and closes the user
+ */
+func (c *UserClient) OffboardUser(email string, transferToEmail string) error {
+	user, err := c.GetUserByEmail(email)
+	if err != nil {
+		return err
+	}
+
+	if transferToEmail != "" {
+		manager, err := c.GetUserByEmail(transferToEmail)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.TransferEnvelopes(user.UserID, manager.UserID, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.CloseUser(user.UserID)
+}
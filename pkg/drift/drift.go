@@ -0,0 +1,644 @@
+/*
+# Drift
+
+This package compares a desired state, declared in a YAML/JSON state file, against the live state of each provider,
+and remediates the differences with approval, in the style of `terraform plan`/`terraform apply`:
+
+	state, _ := drift.LoadState("state.yaml")
+	d := &drift.Detector{Log: log.NewLogger("{drift}", log.INFO), Okta: o, Google: g, Slack: s}
+	plan := d.Plan(state)
+	fmt.Print(plan)
+	err := d.Apply(plan, drift.AutoApprove)
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/drift/drift.go
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/slack"
+)
+
+// Detector reads the live state of the configured providers; sections of a state for other providers are reported as errors
+type Detector struct {
+	Log    *log.Logger
+	Google *google.Client
+	Okta   *okta.Client
+	Slack  *slack.Client
+	Now    func() time.Time // Clock used for report timestamps; defaults to `time.Now`
+
+	slackEmails map[string]string // Emails of Slack users by ID, read once per plan
+}
+
+// Approver decides whether a drift may be remediated
+type Approver func(d *Drift) bool
+
+// AutoApprove remediates every drift
+func AutoApprove(*Drift) bool { return true }
+
+/*
+ * # Load a State File
+ * - `.yaml`/`.yml` files are parsed as YAML (see `parseYAML` for the supported subset); anything else as JSON
+ * - Unknown fields are rejected, so a typo cannot silently drop part of the desired state
+ */
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ParseYAML(data)
+	default:
+		return ParseJSON(data)
+	}
+}
+
+// ParseJSON parses a state declared as JSON
+func ParseJSON(data []byte) (*State, error) {
+	state := &State{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(state); err != nil {
+		return nil, fmt.Errorf("parsing state: %w", err)
+	}
+	return state, nil
+}
+
+// ParseYAML parses a state declared as YAML
+func ParseYAML(data []byte) (*State, error) {
+	value, err := parseYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing state: %w", err)
+	}
+	if value == nil {
+		return &State{}, nil
+	}
+
+	data, err = json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("parsing state: %w", err)
+	}
+	return ParseJSON(data)
+}
+
+/*
+ * # Diff the Desired and Actual States
+ * - `actual` holds the live state of the resources in `desired`, in the same shape; missing resources are skipped
+ * - Group members are compared case-insensitively; members not in the desired state are only removed from exclusive groups
+ * - Members in `Exclude` are never added nor removed
+ * - A group found under a former name is renamed, before its members are changed under its current name
+ * - A user in the desired state of an organizational unit which is not in `actual` is skipped, since its current unit is unknown
+ */
+func Diff(desired, actual *State) []*Drift {
+	drift := []*Drift{}
+	if desired.Okta != nil && actual.Okta != nil {
+		drift = append(drift, diffGroups(Okta, desired.Okta.Groups, actual.Okta.Groups)...)
+	}
+	if desired.Google != nil && actual.Google != nil {
+		drift = append(drift, diffGroups(Google, desired.Google.Groups, actual.Google.Groups)...)
+		drift = append(drift, diffOrgUnits(desired.Google.OrgUnits, actual.Google.OrgUnits)...)
+	}
+	if desired.Slack != nil && actual.Slack != nil {
+		drift = append(drift, diffGroups(Slack, desired.Slack.Usergroups, actual.Slack.Usergroups)...)
+	}
+	return drift
+}
+
+func diffGroups(provider Provider, desired, actual map[string]*Membership) []*Drift {
+	drift := []*Drift{}
+	for _, group := range sortedKeys(desired) {
+		want, have := desired[group], actual[group]
+		if want == nil || have == nil {
+			continue
+		}
+
+		current := lowerSet(have.Members)
+		wanted := lowerSet(want.Members)
+		excluded := lowerSet(want.Exclude)
+
+		former := ""
+		if len(have.FormerNames) > 0 && !strings.EqualFold(have.FormerNames[0], group) {
+			former = have.FormerNames[0]
+			drift = append(drift, &Drift{Provider: provider, Kind: GroupName, Resource: group, Action: Change, Actual: former, Desired: group, Status: Pending})
+		}
+
+		for _, member := range sortedUnique(want.Members) {
+			if key := strings.ToLower(member); !current[key] && !excluded[key] {
+				drift = append(drift, &Drift{Provider: provider, Kind: GroupMembership, Resource: group, Subject: member, Action: Add, Actual: former, Status: Pending})
+			}
+		}
+		if !want.Exclusive {
+			continue
+		}
+		for _, member := range sortedUnique(have.Members) {
+			if key := strings.ToLower(member); !wanted[key] && !excluded[key] {
+				drift = append(drift, &Drift{Provider: provider, Kind: GroupMembership, Resource: group, Subject: member, Action: Remove, Actual: former, Status: Pending})
+			}
+		}
+	}
+	return drift
+}
+
+func diffOrgUnits(desired, actual map[string][]string) []*Drift {
+	current := map[string]string{}
+	for ou, users := range actual {
+		for _, user := range users {
+			current[strings.ToLower(user)] = ou
+		}
+	}
+
+	drift := []*Drift{}
+	for _, ou := range sortedKeys(desired) {
+		for _, user := range sortedUnique(desired[ou]) {
+			have, ok := current[strings.ToLower(user)]
+			if !ok || strings.EqualFold(have, ou) {
+				continue
+			}
+			drift = append(drift, &Drift{Provider: Google, Kind: OrgUnit, Resource: ou, Subject: user, Action: Change, Actual: have, Desired: ou, Status: Pending})
+		}
+	}
+	return drift
+}
+
+/*
+ * # Plan
+ * Resolves the sources of `desired`, reads the live state of every resource in it, and reports how it differs
+ * - Resources which cannot be read (e.g. a group which does not exist) are listed in `Report.Errors`, and the rest are still compared
+ */
+func (d *Detector) Plan(desired *State) *Report {
+	report := &Report{GeneratedAt: d.now()}
+	d.slackEmails = nil
+
+	resolved, errs := d.Resolve(desired)
+	actual, actualErrs := d.Actual(resolved)
+	report.Drift = Diff(resolved, actual)
+	for _, err := range append(errs, actualErrs...) {
+		report.Errors = append(report.Errors, err.Error())
+	}
+
+	d.Log.Printf("Found %d drift(s) and %d error(s)", len(report.Drift), len(report.Errors))
+	return report
+}
+
+// Actual reads the live state of the resources in `desired`
+func (d *Detector) Actual(desired *State) (*State, []error) {
+	actual := &State{}
+	errs := []error{}
+
+	if desired.Okta != nil {
+		if d.Okta == nil {
+			errs = append(errs, fmt.Errorf("okta: no client configured"))
+		} else {
+			actual.Okta = &OktaState{Groups: map[string]*Membership{}}
+			for _, name := range sortedKeys(desired.Okta.Groups) {
+				m, err := actualGroup(name, desired.Okta.Groups[name], d.oktaGroupMembers)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("okta group %s: %w", name, err))
+					continue
+				}
+				actual.Okta.Groups[name] = m
+			}
+		}
+	}
+
+	if desired.Google != nil {
+		if d.Google == nil {
+			errs = append(errs, fmt.Errorf("google: no client configured"))
+		} else {
+			actual.Google = &GoogleState{Groups: map[string]*Membership{}, OrgUnits: map[string][]string{}}
+			for _, group := range sortedKeys(desired.Google.Groups) {
+				m, err := actualGroup(group, desired.Google.Groups[group], d.googleGroupMembers)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("google group %s: %w", group, err))
+					continue
+				}
+				actual.Google.Groups[group] = m
+			}
+
+			for _, ou := range sortedKeys(desired.Google.OrgUnits) {
+				for _, email := range sortedUnique(desired.Google.OrgUnits[ou]) {
+					user, err := d.Google.Users().GetUser(email)
+					if err != nil {
+						errs = append(errs, fmt.Errorf("google user %s: %w", email, err))
+						continue
+					}
+					current := string(user.OrgUnitPath)
+					actual.Google.OrgUnits[current] = append(actual.Google.OrgUnits[current], email)
+				}
+			}
+		}
+	}
+
+	if desired.Slack != nil {
+		if d.Slack == nil {
+			errs = append(errs, fmt.Errorf("slack: no client configured"))
+		} else {
+			actual.Slack = &SlackState{Usergroups: map[string]*Membership{}}
+			for _, handle := range sortedKeys(desired.Slack.Usergroups) {
+				m, err := actualGroup(handle, desired.Slack.Usergroups[handle], d.slackUsergroupMembers)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("slack usergroup %s: %w", handle, err))
+					continue
+				}
+				actual.Slack.Usergroups[handle] = m
+			}
+		}
+	}
+
+	return actual, errs
+}
+
+// actualGroup reads the members of a group, recording the former name it was found under, if any
+func actualGroup(name string, desired *Membership, members func(name string) ([]string, error)) (*Membership, error) {
+	var formerNames []string
+	if desired != nil {
+		formerNames = desired.FormerNames
+	}
+
+	list, found, err := find(name, formerNames, members)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Membership{Members: list}
+	if found != name {
+		m.FormerNames = []string{found}
+	}
+	return m, nil
+}
+
+/*
+ * find looks a group up by its name, or else by one of its former names, returning the name it was found under
+ * - Former names are only tried when the group is not found, so that other errors are not hidden
+ */
+func find[G any](name string, formerNames []string, lookup func(name string) (G, error)) (G, string, error) {
+	group, err := lookup(name)
+	if err == nil || !errors.Is(err, rerrors.ErrNotFound) {
+		return group, name, err
+	}
+	for _, former := range formerNames {
+		if former == "" {
+			continue
+		}
+		if g, formerErr := lookup(former); formerErr == nil {
+			return g, former, nil
+		}
+	}
+	return group, name, err
+}
+
+func (d *Detector) oktaGroupMembers(name string) ([]string, error) {
+	group, err := d.Okta.GetGroupByName(name)
+	if err != nil {
+		return nil, err
+	}
+	users, err := d.Okta.ListGroupMembers(group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []string{}
+	for _, user := range *users {
+		if user.Profile != nil {
+			members = append(members, user.Profile.Login)
+		}
+	}
+	return members, nil
+}
+
+func (d *Detector) googleGroupMembers(group string) ([]string, error) {
+	list, err := d.Google.Groups().ListMembers(group)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []string{}
+	for _, member := range list.Members {
+		members = append(members, member.Email)
+	}
+	return members, nil
+}
+
+// slackUsergroupMembers returns the emails of the members of a user group; members without an email (e.g. bots) are left out
+func (d *Detector) slackUsergroupMembers(handle string) ([]string, error) {
+	usergroup, err := d.Slack.GetUsergroup(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.slackEmails == nil {
+		users, err := d.Slack.ListUsers()
+		if err != nil {
+			return nil, err
+		}
+		d.slackEmails = map[string]string{}
+		for _, user := range users.Members {
+			if user.Profile.Email != "" {
+				d.slackEmails[user.ID] = user.Profile.Email
+			}
+		}
+	}
+
+	members := []string{}
+	for _, id := range usergroup.Users {
+		if email, ok := d.slackEmails[id]; ok {
+			members = append(members, email)
+		}
+	}
+	return members, nil
+}
+
+/*
+ * # Apply
+ * Remediates each pending drift of a report which `approve` accepts, recording its status in the report
+ * - Drifts which were already applied are left as-is, so a partially failed report can be applied again
+ * - Returns the errors of every failed remediation
+ */
+func (d *Detector) Apply(report *Report, approve Approver) error {
+	if approve == nil {
+		return fmt.Errorf("applying drift requires an approver; use `drift.AutoApprove` to approve every change")
+	}
+
+	errs := []error{}
+	for _, drift := range report.Drift {
+		if drift.Status == Applied {
+			continue
+		}
+		if !approve(drift) {
+			drift.Status = Skipped
+			continue
+		}
+
+		if err := d.remediate(drift); err != nil {
+			drift.Status, drift.Error = Failed, err.Error()
+			d.Log.Error(fmt.Sprintf("Failed to %s: %v", drift, err))
+			errs = append(errs, fmt.Errorf("%s: %w", drift, err))
+			continue
+		}
+		drift.Status, drift.Error = Applied, ""
+		d.Log.Printf("Applied: %s", drift)
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Detector) remediate(drift *Drift) error {
+	// Until its rename is applied, a group is still found under its current name
+	var current []string
+	if drift.Kind == GroupMembership && drift.Actual != "" {
+		current = []string{drift.Actual}
+	}
+
+	switch {
+	case drift.Provider == Okta && drift.Kind == GroupMembership:
+		if d.Okta == nil {
+			return fmt.Errorf("no okta client configured")
+		}
+		group, _, err := find(drift.Resource, current, d.Okta.GetGroupByName)
+		if err != nil {
+			return err
+		}
+		user, err := d.Okta.GetUser(drift.Subject)
+		if err != nil {
+			return err
+		}
+		if drift.Action == Remove {
+			return d.Okta.RemoveUserFromGroup(group.ID, user.ID)
+		}
+		return d.Okta.AddUserToGroup(group.ID, user.ID)
+
+	case drift.Provider == Okta && drift.Kind == GroupName:
+		if d.Okta == nil {
+			return fmt.Errorf("no okta client configured")
+		}
+		group, err := d.Okta.GetGroupByName(drift.Actual)
+		if err != nil {
+			return err
+		}
+		profile := group.Profile
+		profile.Name = drift.Desired
+		_, err = d.Okta.UpdateGroup(group.ID, profile)
+		return err
+
+	case drift.Provider == Google && drift.Kind == GroupMembership:
+		if d.Google == nil {
+			return fmt.Errorf("no google client configured")
+		}
+		_, _, err := find(drift.Resource, current, func(group string) (*google.Member, error) {
+			if drift.Action == Remove {
+				return nil, d.Google.Groups().RemoveMember(group, drift.Subject)
+			}
+			return d.Google.Groups().AddMember(group, drift.Subject, "")
+		})
+		return err
+
+	case drift.Provider == Google && drift.Kind == OrgUnit:
+		if d.Google == nil {
+			return fmt.Errorf("no google client configured")
+		}
+		_, err := d.Google.Users().MoveUserToOU(drift.Subject, drift.Desired)
+		return err
+
+	case drift.Provider == Slack && drift.Kind == GroupMembership:
+		if d.Slack == nil {
+			return fmt.Errorf("no slack client configured")
+		}
+		usergroup, _, err := find(drift.Resource, current, d.Slack.GetUsergroup)
+		if err != nil {
+			return err
+		}
+		user, err := d.Slack.LookupUserByEmail(drift.Subject)
+		if err != nil {
+			return err
+		}
+		if drift.Action == Remove {
+			_, err = d.Slack.RemoveUserFromUsergroup(usergroup.ID, user.ID)
+		} else {
+			_, err = d.Slack.AddUserToUsergroup(usergroup.ID, user.ID)
+		}
+		return err
+
+	case drift.Provider == Slack && drift.Kind == GroupName:
+		if d.Slack == nil {
+			return fmt.Errorf("no slack client configured")
+		}
+		usergroup, err := d.Slack.GetUsergroup(drift.Actual)
+		if err != nil {
+			return err
+		}
+		_, err = d.Slack.RenameUsergroup(usergroup.ID, drift.Desired)
+		return err
+	}
+
+	return fmt.Errorf("cannot remediate %s %s drift", drift.Provider, drift.Kind)
+}
+
+func (d *Detector) now() time.Time {
+	if d.Now != nil {
+		return d.Now()
+	}
+	return time.Now()
+}
+
+// String describes the change which remediates the drift, e.g. `add alice@example.com to okta group aws-admins`
+func (d *Drift) String() string {
+	resource := d.resourceType()
+
+	switch {
+	case d.Kind == GroupName:
+		return fmt.Sprintf("rename %s %s %s to %s", d.Provider, resource, d.Actual, d.Desired)
+	case d.Action == Add:
+		return fmt.Sprintf("add %s to %s %s %s", d.Subject, d.Provider, resource, d.Resource)
+	case d.Action == Remove:
+		return fmt.Sprintf("remove %s from %s %s %s", d.Subject, d.Provider, resource, d.Resource)
+	default:
+		return fmt.Sprintf("move %s from %s %s %s to %s", d.Subject, d.Provider, resource, d.Actual, d.Desired)
+	}
+}
+
+// resourceType names the type of the resource, as the provider does
+func (d *Drift) resourceType() string {
+	switch {
+	case d.Kind == OrgUnit:
+		return "org unit"
+	case d.Provider == Slack:
+		return "usergroup"
+	default:
+		return "group"
+	}
+}
+
+// Pending returns the drifts which have not been applied
+func (r *Report) Pending() []*Drift {
+	pending := []*Drift{}
+	for _, drift := range r.Drift {
+		if drift.Status != Applied {
+			pending = append(pending, drift)
+		}
+	}
+	return pending
+}
+
+// InSync reports whether the live state matches the desired state; resources which could not be read count as drift
+func (r *Report) InSync() bool {
+	return len(r.Pending()) == 0 && len(r.Errors) == 0
+}
+
+// String renders the report as a plan, with `+` for additions, `-` for removals and `~` for changes
+func (r *Report) String() string {
+	var sb strings.Builder
+
+	last := ""
+	counts := map[Action]int{}
+	for _, drift := range r.Drift {
+		heading := fmt.Sprintf("%s %s %s", drift.Provider, drift.resourceType(), drift.Resource)
+		if heading != last {
+			fmt.Fprintln(&sb, heading)
+			last = heading
+		}
+
+		status := ""
+		if drift.Status != Pending && drift.Status != "" {
+			status = fmt.Sprintf(" [%s]", drift.Status)
+		}
+		if drift.Error != "" {
+			status = fmt.Sprintf(" [%s: %s]", drift.Status, drift.Error)
+		}
+
+		switch {
+		case drift.Kind == GroupName:
+			fmt.Fprintf(&sb, "  ~ name (%s -> %s)%s\n", drift.Actual, drift.Desired, status)
+		case drift.Action == Add:
+			fmt.Fprintf(&sb, "  + %s%s\n", drift.Subject, status)
+		case drift.Action == Remove:
+			fmt.Fprintf(&sb, "  - %s%s\n", drift.Subject, status)
+		default:
+			fmt.Fprintf(&sb, "  ~ %s (%s -> %s)%s\n", drift.Subject, drift.Actual, drift.Desired, status)
+		}
+		counts[drift.Action]++
+	}
+
+	for _, err := range r.Errors {
+		fmt.Fprintf(&sb, "! %s\n", err)
+	}
+
+	if len(r.Drift) == 0 {
+		fmt.Fprintln(&sb, "No drift: the live state matches the desired state.")
+	} else {
+		fmt.Fprintf(&sb, "Plan: %d to add, %d to remove, %d to change.\n", counts[Add], counts[Remove], counts[Change])
+	}
+	return sb.String()
+}
+
+// Save writes the report to a JSON file, so it can be reviewed and applied later
+func (r *Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// LoadReport reads a report previously written with `Report.Save`
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("unmarshalling report: %w", err)
+	}
+	return report, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lowerSet returns the set of values, lowercased
+func lowerSet(values []string) map[string]bool {
+	set := map[string]bool{}
+	for _, value := range values {
+		set[strings.ToLower(value)] = true
+	}
+	return set
+}
+
+// sortedUnique sorts values, dropping case-insensitive duplicates
+func sortedUnique(values []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, value := range values {
+		if key := strings.ToLower(value); !seen[key] {
+			seen[key] = true
+			unique = append(unique, value)
+		}
+	}
+	sort.Slice(unique, func(i, j int) bool { return strings.ToLower(unique[i]) < strings.ToLower(unique[j]) })
+	return unique
+}
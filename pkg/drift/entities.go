@@ -0,0 +1,146 @@
+/*
+# Drift - Entities [Structs]
+
+This package contains the structs of the desired state and the drift reports compared against it:
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/drift/entities.go
+package drift
+
+import "time"
+
+// ### State Structs
+// ---------------------------------------------------------------------
+
+/*
+ * State is the desired configuration of each provider, as declared in a state file:
+ *
+ *	okta:
+ *	  groups:
+ *	    aws-admins:
+ *	      exclusive: true
+ *	      members: [alice@example.com, bob@example.com]
+ *	google:
+ *	  groups:
+ *	    security@example.com:
+ *	      members:
+ *	        - alice@example.com
+ *	  orgUnits:
+ *	    /Engineering: [alice@example.com]
+ *	slack:
+ *	  usergroups:
+ *	    engineering:
+ *	      exclusive: true
+ *	      formerNames: [eng]
+ *	      exclude: [eng-bot@example.com]
+ *	      sources:
+ *	        - csv: hr-export.csv
+ *	          where:
+ *	            department: Engineering
+ *	        - group: okta:Engineering Contractors
+ */
+type State struct {
+	Okta   *OktaState   `json:"okta,omitempty"`   // Desired state of Okta
+	Google *GoogleState `json:"google,omitempty"` // Desired state of Google Workspace
+	Slack  *SlackState  `json:"slack,omitempty"`  // Desired state of Slack
+}
+
+type OktaState struct {
+	Groups map[string]*Membership `json:"groups,omitempty"` // Members of each group, keyed by group name; members are Okta logins
+}
+
+type GoogleState struct {
+	Groups   map[string]*Membership `json:"groups,omitempty"`   // Members of each group, keyed by group email
+	OrgUnits map[string][]string    `json:"orgUnits,omitempty"` // Users in each organizational unit, keyed by path, e.g. `/Engineering`
+}
+
+type SlackState struct {
+	Usergroups map[string]*Membership `json:"usergroups,omitempty"` // Members of each user group, keyed by handle (without the `@`); members are emails
+}
+
+// Membership is the desired members of a group
+type Membership struct {
+	Members     []string  `json:"members"`               // Members which must be in the group
+	Sources     []*Source `json:"sources,omitempty"`     // Where further members are read from; see `Detector.Resolve`
+	Exclude     []string  `json:"exclude,omitempty"`     // Members which are never added nor removed, e.g. service or break-glass accounts
+	FormerNames []string  `json:"formerNames,omitempty"` // Previous names of the group, which is renamed when found under one of them; in an actual state, the name it was found under
+	Exclusive   bool      `json:"exclusive,omitempty"`   // Whether members not listed must be removed; otherwise they are ignored
+}
+
+// Source is a list of members maintained elsewhere, e.g. an HR export or another group; set either `CSV` or `Group`
+type Source struct {
+	CSV    string            `json:"csv,omitempty"`    // Path of a CSV file with a header row, e.g. an export of the HR system
+	Column string            `json:"column,omitempty"` // Column of the CSV holding the members; defaults to `email`
+	Where  map[string]string `json:"where,omitempty"`  // Only rows whose columns have these values (case-insensitive), e.g. `department: Engineering`
+	Group  string            `json:"group,omitempty"`  // Another group, as `<provider>:<name>`, e.g. `okta:Engineering` or `google:eng@example.com`
+}
+
+// END OF STATE STRUCTS
+//---------------------------------------------------------------------
+
+// ### Drift Structs
+// ---------------------------------------------------------------------
+
+// Provider is the service a resource lives in
+type Provider string
+
+const (
+	Google Provider = "google"
+	Okta   Provider = "okta"
+	Slack  Provider = "slack"
+)
+
+// Kind is the type of resource which drifted
+type Kind string
+
+const (
+	GroupMembership Kind = "group_membership"
+	GroupName       Kind = "group_name"
+	OrgUnit         Kind = "org_unit"
+)
+
+// Action is the change which remediates a drift
+type Action string
+
+const (
+	Add    Action = "add"    // Add the subject to the resource
+	Remove Action = "remove" // Remove the subject from the resource
+	Change Action = "change" // Change an attribute of the subject from `Actual` to `Desired`
+)
+
+// Status is the progress of remediating a drift
+type Status string
+
+const (
+	Pending Status = "pending" // Not yet applied
+	Applied Status = "applied" // Remediated
+	Skipped Status = "skipped" // Not approved
+	Failed  Status = "failed"  // Remediation returned an error
+)
+
+// Drift is a difference between the desired and the actual state, and the change which remediates it
+type Drift struct {
+	Provider Provider `json:"provider" csv:"provider"`         // Service the resource lives in
+	Kind     Kind     `json:"kind" csv:"kind"`                 // Type of the resource
+	Resource string   `json:"resource" csv:"resource"`         // Group name/email, or organizational unit path
+	Subject  string   `json:"subject" csv:"subject"`           // User the drift is about; empty for renames
+	Action   Action   `json:"action" csv:"action"`             // Change which remediates the drift
+	Actual   string   `json:"actual,omitempty" csv:"actual"`   // Current value, for changes; for the members of a group still to be renamed, its current name
+	Desired  string   `json:"desired,omitempty" csv:"desired"` // Desired value, for changes
+	Status   Status   `json:"status" csv:"status"`             // Progress of the remediation
+	Error    string   `json:"error,omitempty" csv:"error"`     // Why the remediation failed
+}
+
+// Report is the drift between a desired state and the live state of each provider; a plan which `Apply` can execute
+type Report struct {
+	GeneratedAt time.Time `json:"generatedAt"`      // Time the live state was read
+	Drift       []*Drift  `json:"drift"`            // Differences found, in a stable order
+	Errors      []string  `json:"errors,omitempty"` // Resources which could not be compared, e.g. a group which does not exist
+}
+
+// END OF DRIFT STRUCTS
+//---------------------------------------------------------------------
@@ -0,0 +1,185 @@
+// pkg/drift/sources.go
+package drift
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+/*
+ * # Resolve the Sources of a State
+ * Returns a copy of `desired` in which the members of each group include those read from its sources
+ * - CSV sources are read from disk, e.g. an export of the HR system; group sources are read live from their provider
+ * - A group whose sources cannot be read is left out of the resolved state and reported as an error, so that an exclusive
+ *   group is never emptied because its source was unavailable
+ */
+func (d *Detector) Resolve(desired *State) (*State, []error) {
+	resolved := &State{}
+	errs := []error{}
+
+	if desired.Okta != nil {
+		resolved.Okta = &OktaState{Groups: d.resolveGroups("okta group", desired.Okta.Groups, &errs)}
+	}
+	if desired.Google != nil {
+		resolved.Google = &GoogleState{Groups: d.resolveGroups("google group", desired.Google.Groups, &errs), OrgUnits: desired.Google.OrgUnits}
+	}
+	if desired.Slack != nil {
+		resolved.Slack = &SlackState{Usergroups: d.resolveGroups("slack usergroup", desired.Slack.Usergroups, &errs)}
+	}
+
+	return resolved, errs
+}
+
+func (d *Detector) resolveGroups(resource string, groups map[string]*Membership, errs *[]error) map[string]*Membership {
+	if groups == nil {
+		return nil
+	}
+
+	resolved := map[string]*Membership{}
+	for _, name := range sortedKeys(groups) {
+		m := groups[name]
+		if m == nil || len(m.Sources) == 0 {
+			resolved[name] = m
+			continue
+		}
+
+		members := append([]string{}, m.Members...)
+		var failed error
+		for _, source := range m.Sources {
+			list, err := d.sourceMembers(source)
+			if err != nil {
+				failed = err
+				break
+			}
+			members = append(members, list...)
+		}
+		if failed != nil {
+			*errs = append(*errs, fmt.Errorf("%s %s: %w", resource, name, failed))
+			continue
+		}
+
+		copied := *m
+		copied.Members = sortedUnique(members)
+		resolved[name] = &copied
+	}
+	return resolved
+}
+
+// sourceMembers reads the members listed by a source
+func (d *Detector) sourceMembers(source *Source) ([]string, error) {
+	switch {
+	case source == nil:
+		return nil, nil
+	case source.CSV != "" && source.Group != "":
+		return nil, fmt.Errorf("source sets both csv %s and group %s", source.CSV, source.Group)
+	case source.CSV != "":
+		return ReadCSV(source.CSV, source.Column, source.Where)
+	case source.Group != "":
+		return d.groupSource(source.Group)
+	default:
+		return nil, errors.New("source sets neither csv nor group")
+	}
+}
+
+// groupSource reads the members of a group named `<provider>:<name>`
+func (d *Detector) groupSource(ref string) ([]string, error) {
+	provider, name, ok := strings.Cut(ref, ":")
+	if !ok || name == "" {
+		return nil, fmt.Errorf("source group %q is not `<provider>:<name>`", ref)
+	}
+
+	switch Provider(strings.ToLower(provider)) {
+	case Okta:
+		if d.Okta == nil {
+			return nil, fmt.Errorf("source group %s: no okta client configured", ref)
+		}
+		return d.oktaGroupMembers(name)
+	case Google:
+		if d.Google == nil {
+			return nil, fmt.Errorf("source group %s: no google client configured", ref)
+		}
+		return d.googleGroupMembers(name)
+	case Slack:
+		if d.Slack == nil {
+			return nil, fmt.Errorf("source group %s: no slack client configured", ref)
+		}
+		return d.slackUsergroupMembers(name)
+	default:
+		return nil, fmt.Errorf("source group %s: unsupported provider %s", ref, provider)
+	}
+}
+
+/*
+ * # Read Members from a CSV File
+ * - The first row is a header; `column` (default `email`) and the columns of `where` are matched case-insensitively
+ * - Only rows whose `where` columns hold the given values (case-insensitive) are read; empty members are skipped
+ */
+func ReadCSV(path, column string, where map[string]string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if column == "" {
+		column = "email"
+	}
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header of %s: %w", path, err)
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	col, ok := index[strings.ToLower(column)]
+	if !ok {
+		return nil, fmt.Errorf("%s has no %s column", path, column)
+	}
+	filters := map[int]string{}
+	for name, value := range where {
+		i, ok := index[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("%s has no %s column", path, name)
+		}
+		filters[i] = value
+	}
+
+	members := []string{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+
+		if !matches(record, filters) || col >= len(record) {
+			continue
+		}
+		if member := strings.TrimSpace(record[col]); member != "" {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+func matches(record []string, filters map[int]string) bool {
+	for i, value := range filters {
+		if i >= len(record) || !strings.EqualFold(strings.TrimSpace(record[i]), value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,287 @@
+// pkg/drift/yaml.go
+package drift
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+ * parseYAML converts the subset of YAML used by state files into values for `encoding/json`
+ * - Block mappings and sequences (including `- key: value` items), indented with spaces
+ * - Plain, single- and double-quoted scalars, flow sequences of scalars (`[a, b]`), and `#` comments
+ * - Anchors, tags, multi-line strings and multiple documents are not supported
+ */
+func parseYAML(data []byte) (interface{}, error) {
+	p := &yamlParser{}
+	for i, text := range strings.Split(string(data), "\n") {
+		text = strings.TrimRight(stripComment(text), " \t\r")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("yaml: line %d: tabs are not allowed for indentation", i+1)
+		}
+		p.lines = append(p.lines, yamlLine{number: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(p.lines) == 0 {
+		return nil, nil
+	}
+
+	value, err := p.block(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return value, nil
+}
+
+type yamlLine struct {
+	number int    // Line number in the file, for errors
+	indent int    // Number of leading spaces
+	text   string // Content without indentation or comments
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	line := p.lines[len(p.lines)-1].number
+	if p.pos < len(p.lines) {
+		line = p.lines[p.pos].number
+	}
+	return fmt.Errorf("yaml: line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// block parses the mapping or sequence starting at the current line
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	if isSequenceItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	items := []interface{}{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		rest := strings.TrimLeft(line.text[1:], " ")
+
+		switch {
+		case rest == "":
+			p.pos++
+			item, err := p.nested(indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		case isMappingEntry(rest):
+			// `- key: value` starts a mapping indented to the column of `key`
+			p.lines[p.pos] = yamlLine{number: line.number, indent: indent + len(line.text) - len(rest), text: rest}
+			item, err := p.mapping(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		default:
+			item, err := scalar(rest)
+			if err != nil {
+				return nil, p.errorf("%v", err)
+			}
+			items = append(items, item)
+			p.pos++
+		}
+	}
+	return items, nil
+}
+
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		line := p.lines[p.pos]
+		if isSequenceItem(line.text) {
+			return nil, p.errorf("unexpected sequence item in a mapping")
+		}
+
+		key, value, ok := splitMappingEntry(line.text)
+		if !ok {
+			return nil, p.errorf("expected `key: value`, got %q", line.text)
+		}
+		if _, exists := m[key]; exists {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		p.pos++
+
+		if value == "" {
+			nested, err := p.nested(indent)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = nested
+			continue
+		}
+
+		v, err := scalar(value)
+		if err != nil {
+			p.pos--
+			return nil, p.errorf("%v", err)
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+// nested parses the block under a key or an empty sequence item; sequences may sit at the same indentation as their key
+func (p *yamlParser) nested(indent int) (interface{}, error) {
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+	next := p.lines[p.pos]
+	if next.indent > indent || (next.indent == indent && isSequenceItem(next.text) && !p.inSequence(indent)) {
+		return p.block(next.indent)
+	}
+	return nil, nil
+}
+
+// inSequence reports whether the line before the current one is an item of a sequence at `indent`
+func (p *yamlParser) inSequence(indent int) bool {
+	for i := p.pos - 1; i >= 0; i-- {
+		if p.lines[i].indent < indent {
+			return false
+		}
+		if p.lines[i].indent == indent {
+			return isSequenceItem(p.lines[i].text)
+		}
+	}
+	return false
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isMappingEntry(text string) bool {
+	_, _, ok := splitMappingEntry(text)
+	return ok
+}
+
+// splitMappingEntry splits `key: value` on the first colon followed by a space (or the end of the line) outside of quotes
+func splitMappingEntry(text string) (key, value string, ok bool) {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return "", "", false
+	}
+
+	end := -1
+	if q := text[0]; q == '"' || q == '\'' {
+		closing := strings.IndexByte(text[1:], q)
+		if closing < 0 {
+			return "", "", false
+		}
+		end = closing + 2
+		if !strings.HasPrefix(text[end:], ":") {
+			return "", "", false
+		}
+	} else {
+		for i := 0; i < len(text); i++ {
+			if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return "", "", false
+		}
+	}
+
+	rawKey := strings.TrimSpace(text[:end])
+	k, err := scalar(rawKey)
+	if err != nil {
+		return "", "", false
+	}
+	key, isString := k.(string)
+	if !isString {
+		key = rawKey
+	}
+	return key, strings.TrimSpace(text[end+1:]), true
+}
+
+// scalar converts a value to a string, number, boolean or nil, or a flow sequence of them
+func scalar(s string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		if len(s) < 2 || !strings.HasSuffix(s, `"`) {
+			return nil, fmt.Errorf("unterminated string %s", s)
+		}
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return nil, fmt.Errorf("unterminated string %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case strings.HasPrefix(s, "["):
+		if !strings.HasSuffix(s, "]") {
+			return nil, fmt.Errorf("unterminated sequence %s", s)
+		}
+		items := []interface{}{}
+		inner := strings.TrimSpace(s[1 : len(s)-1])
+		if inner == "" {
+			return items, nil
+		}
+		for _, part := range strings.Split(inner, ",") {
+			item, err := scalar(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case s == "{}":
+		return map[string]interface{}{}, nil
+	case strings.HasPrefix(s, "{"), strings.HasPrefix(s, "|"), strings.HasPrefix(s, ">"), strings.HasPrefix(s, "&"), strings.HasPrefix(s, "*"), strings.HasPrefix(s, "!"):
+		return nil, fmt.Errorf("unsupported value %s", s)
+	}
+
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	if strings.ContainsAny(s[:1], "+-.0123456789") {
+		if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return f, nil
+		}
+	}
+	return s, nil
+}
+
+// stripComment removes a `#` comment which starts a line or follows whitespace, outside of quoted values
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t[,:", line[i-1]) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
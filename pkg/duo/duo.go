@@ -0,0 +1,295 @@
+/*
+# Duo
+
+This package initializes all the methods for functions which interact with the Duo Admin API:
+https://duo.com/docs/adminapi
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/duo/duo.go
+package duo
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/config"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/ratelimit"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+const (
+	DuoInfoSummary = "%s/admin/v1/info/summary" // https://duo.com/docs/adminapi#retrieve-summary
+	DuoUsers       = "%s/admin/v1/users"        // https://duo.com/docs/adminapi#users
+)
+
+// BuildURL builds a URL for a given resource and identifiers.
+func (c *Client) BuildURL(endpoint string, identifiers ...string) string {
+	url := fmt.Sprintf(endpoint, c.BaseURL)
+	for _, id := range identifiers {
+		url = fmt.Sprintf("%s/%s", url, id)
+	}
+	c.Log.Debug("url:", url)
+	return url
+}
+
+// Close closes the HTTP client (see `requests.Client.Close`), then flushes the cache (see `cache.Cache.Flush`)
+func (c *Client) Close(ctx context.Context) error {
+	return errors.Join(c.HTTP.Close(ctx), c.Cache.Flush())
+}
+
+// Name returns the name of the service, `duo`
+func (c *Client) Name() string {
+	return "duo"
+}
+
+// Endpoint returns the base URL of the API the client sends its requests to
+func (c *Client) Endpoint() string {
+	return c.BaseURL
+}
+
+/*
+ * # Check the Health of the Duo Client
+ * Gets the summary of the account, so the Admin API is reachable and the integration's keys valid
+ * /admin/v1/info/summary
+ */
+func (c *Client) HealthCheck(ctx context.Context) error {
+	_, _, err := c.HTTP.DoRequestContext(ctx, "GET", c.BuildURL(DuoInfoSummary), nil, nil)
+	return rerrors.WithProvider(err, "duo")
+}
+
+// UseCache() enables caching for the next method call.
+func (c *Client) UseCache() *Client {
+	c.Cache.Enabled = true
+	return c
+}
+
+/*
+ * SetCache stores a Duo API response in the cache
+ */
+func (c *Client) SetCache(key string, value interface{}, duration time.Duration) {
+	// Convert value to a byte slice and cache it
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.Log.Error("Error marshalling cache data:", err)
+		return
+	}
+	c.Cache.Set(key, data, duration)
+}
+
+/*
+ * GetCache retrieves a Duo API response from the cache
+ */
+func (c *Client) GetCache(key string, target interface{}) bool {
+	data, found := c.Cache.Get(key)
+	if !found || !c.Cache.Enabled {
+		return false
+	}
+
+	err := json.Unmarshal(data, target)
+	if err != nil {
+		c.Log.Error("Error unmarshalling cache data:", err)
+		return false
+	}
+	return true
+}
+
+/*
+  - # Generate Duo Client
+  - @param verbosity int
+  - @return *Client
+  - Example:
+
+```go
+
+	d := duo.NewClient(log.DEBUG)
+
+```
+*/
+func NewClient(verbosity int) *Client {
+	log := log.NewLogger("{duo}", verbosity)
+
+	var settings struct {
+		Host           string `env:"DUO_API_HOST,required"` // api-xxxxxxxx.duosecurity.com
+		IntegrationKey string `env:"DUO_INTEGRATION_KEY,required"`
+		SecretKey      string `env:"DUO_SECRET_KEY,required"`
+		EncryptionKey  []byte `env:"REGO_ENCRYPTION_KEY,required"`
+	}
+	if err := config.Load(&settings); err != nil {
+		log.Fatal(err)
+	}
+
+	host := settings.Host
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	host = strings.TrimSuffix(host, "/")
+
+	headers := requests.Headers{
+		"Accept": requests.JSON,
+	}
+
+	cache, err := cache.NewCache(settings.EncryptionKey, "rego_cache_duo.gob", 1000000)
+	if err != nil {
+		panic(err)
+	}
+
+	// https://duo.com/docs/adminapi#rate-limiting
+	rl := ratelimit.NewRateLimiter(50, 1*time.Minute)
+	rl.Log.Verbosity = verbosity
+
+	signed := &http.Client{Transport: &signer{integrationKey: settings.IntegrationKey, secretKey: settings.SecretKey, next: http.DefaultTransport}}
+	httpClient := requests.NewClient(signed, headers, rl, requests.WithUserAgent("duo"))
+
+	return &Client{
+		BaseURL: host,
+		HTTP:    httpClient,
+		Log:     log,
+		Cache:   cache,
+	}
+}
+
+/*
+ * signer signs each request with the HMAC-SHA1 scheme of the Admin API
+ * - The signature covers the date, method, host, path and sorted parameters, so it is computed on every attempt
+ * - Only query parameters are signed; the package does not send form bodies
+ * - https://duo.com/docs/adminapi#authentication
+ */
+type signer struct {
+	integrationKey string
+	secretKey      string
+	next           http.RoundTripper
+}
+
+func (s *signer) RoundTrip(req *http.Request) (*http.Response, error) {
+	signed := req.Clone(req.Context())
+
+	date := time.Now().UTC().Format("Mon, 02 Jan 2006 15:04:05 -0000")
+	params := canonicalParams(signed.URL.Query())
+	signed.URL.RawQuery = params
+
+	canon := strings.Join([]string{date, strings.ToUpper(signed.Method), strings.ToLower(signed.URL.Host), signed.URL.Path, params}, "\n")
+	mac := hmac.New(sha1.New, []byte(s.secretKey))
+	mac.Write([]byte(canon))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	signed.Header.Set("Date", date)
+	signed.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(s.integrationKey+":"+signature)))
+	return s.next.RoundTrip(signed)
+}
+
+// canonicalParams encodes parameters sorted by key, escaping spaces as `%20` rather than `+`
+func canonicalParams(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	escape := func(s string) string {
+		return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+	}
+
+	pairs := []string{}
+	for _, key := range keys {
+		list := append([]string{}, values[key]...)
+		sort.Strings(list)
+		for _, value := range list {
+			pairs = append(pairs, escape(key)+"="+escape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+/*
+ * Classify a Duo error response
+ * - Records the numeric `code` and `message` of the response on the error
+ * - https://duo.com/docs/adminapi#response-format
+ */
+func apiError(err error) error {
+	apiErr, ok := rerrors.AsAPIError(err)
+	if !ok {
+		return err
+	}
+	apiErr.Provider = "duo"
+
+	res := &Response[json.RawMessage]{}
+	if json.Unmarshal([]byte(apiErr.Message), res) != nil || res.Stat != "FAIL" {
+		return apiErr
+	}
+
+	apiErr.Code = strconv.Itoa(res.Code)
+	apiErr.Message = res.Message
+	if res.MessageDetail != "" {
+		apiErr.Message = fmt.Sprintf("%s: %s", res.Message, res.MessageDetail)
+	}
+	return apiErr
+}
+
+/*
+ * Perform a generic request to the Duo Admin API
+ * - Every response is wrapped in an envelope whose `stat` is `OK` or `FAIL`
+ */
+func do[T any](c *Client, method string, url string, query interface{}) (*Response[T], error) {
+	res, body, err := c.HTTP.DoRequest(method, url, query, nil)
+	if err != nil {
+		return nil, apiError(err)
+	}
+
+	c.Log.Println("Response Status:", res.Status)
+	c.Log.Debug("Response Body:", string(body))
+
+	result := &Response[T]{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling error: %w", err)
+	}
+	if result.Stat != "OK" {
+		return nil, fmt.Errorf("duo: %d %s", result.Code, result.Message)
+	}
+
+	return result, nil
+}
+
+/*
+ * Generically perform a paginated request to the Duo Admin API
+ * - Pages are requested by `offset` until the response carries no `next_offset`
+ */
+func doPaginated[E any](c *Client, url string, limit int) ([]*E, error) {
+	results := make([]*E, 0)
+
+	q := PageQuery{Limit: limit}
+	for {
+		page, err := do[[]*E](c, "GET", url, &q)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, page.Response...)
+
+		if page.Metadata == nil || page.Metadata.NextOffset == 0 {
+			break
+		}
+		q.Offset = page.Metadata.NextOffset
+	}
+
+	return results, nil
+}
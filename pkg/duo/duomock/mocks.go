@@ -0,0 +1,67 @@
+// Code generated by mockgen from interfaces.go; DO NOT EDIT.
+
+// pkg/duo/duomock/mocks.go
+package duomock
+
+import (
+	"sync"
+
+	"github.com/gemini-oss/rego/pkg/duo"
+)
+
+// Call is a call received by a mock
+type Call struct {
+	Method string        // Name of the method called
+	Args   []interface{} // Arguments of the call
+}
+
+// calls records the calls received by a mock
+type calls struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+func (c *calls) record(method string, args ...interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls received, in order; only those of `method` when set
+func (c *calls) Calls(method ...string) []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	received := []Call{}
+	for _, call := range c.calls {
+		if len(method) == 0 || call.Method == method[0] {
+			received = append(received, call)
+		}
+	}
+	return received
+}
+
+// UserService is a mock of `duo.UserService`; methods without a func return zero values
+type UserService struct {
+	ListAllUsersFunc      func() (*duo.Users, error)
+	GetUserByUsernameFunc func(string) (*duo.User, error)
+
+	calls
+}
+
+var _ duo.UserService = (*UserService)(nil)
+
+func (m *UserService) ListAllUsers() (r0 *duo.Users, r1 error) {
+	m.record("ListAllUsers")
+	if m.ListAllUsersFunc != nil {
+		return m.ListAllUsersFunc()
+	}
+	return
+}
+
+func (m *UserService) GetUserByUsername(a0 string) (r0 *duo.User, r1 error) {
+	m.record("GetUserByUsername", a0)
+	if m.GetUserByUsernameFunc != nil {
+		return m.GetUserByUsernameFunc(a0)
+	}
+	return
+}
@@ -0,0 +1,133 @@
+/*
+# Duo - Entities [Structs]
+
+This package contains many structs for handling responses from the Duo Admin API:
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/duo/entities.go
+package duo
+
+import (
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/normalize"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+// ### Duo Client Entities
+// ---------------------------------------------------------------------
+type Client struct {
+	BaseURL string           // BaseURL is the base URL of the Admin API, e.g. `https://api-xxxxxxxx.duosecurity.com`.
+	HTTP    *requests.Client // HTTP is the client used to make HTTP requests; it signs every request.
+	Log     *log.Logger      // Log is the logger used to log messages.
+	Cache   *cache.Cache     // Cache is the cache used to store responses from the Duo API.
+}
+
+// Response is the envelope of every Duo Admin API response
+// https://duo.com/docs/adminapi#response-format
+type Response[T any] struct {
+	Stat          string    `json:"stat"`                     // `OK` or `FAIL`
+	Response      T         `json:"response,omitempty"`       // The result of the request
+	Metadata      *Metadata `json:"metadata,omitempty"`       // Pagination of list responses
+	Code          int       `json:"code,omitempty"`           // Error code, e.g. `40002`, when `stat` is `FAIL`
+	Message       string    `json:"message,omitempty"`        // Error message
+	MessageDetail string    `json:"message_detail,omitempty"` // Details of the error, e.g. the invalid parameter
+}
+
+// Metadata holds the pagination of a list response
+// https://duo.com/docs/adminapi#response-paging
+type Metadata struct {
+	NextOffset   int `json:"next_offset,omitempty"`   // Offset of the next page; unset on the last page
+	PrevOffset   int `json:"prev_offset,omitempty"`   // Offset of the previous page
+	TotalObjects int `json:"total_objects,omitempty"` // Number of objects across every page
+}
+
+// PageQuery holds the paging parameters of list endpoints
+type PageQuery struct {
+	Limit  int `url:"limit,omitempty"`  // Number of objects per page
+	Offset int `url:"offset,omitempty"` // Offset of the first object of the page
+}
+
+// END OF DUO CLIENT ENTITIES
+//---------------------------------------------------------------------
+
+// ### Duo Users Structs
+// ---------------------------------------------------------------------
+type Users []*User
+
+// https://duo.com/docs/adminapi#users
+type User struct {
+	UserID              string                `json:"user_id,omitempty"`             // The ID of the user.
+	Username            string                `json:"username,omitempty"`            // The username of the user.
+	RealName            string                `json:"realname,omitempty"`            // The full name of the user.
+	Email               string                `json:"email,omitempty"`               // The email address of the user.
+	Status              string                `json:"status,omitempty"`              // `active`, `bypass`, `disabled`, `locked out` or `pending deletion`.
+	IsEnrolled          bool                  `json:"is_enrolled,omitempty"`         // True if the user has a phone, hardware token, U2F token, WebAuthn credential or is bypassed.
+	Created             int64                 `json:"created,omitempty"`             // Time the user was created, as a Unix timestamp.
+	LastLogin           int64                 `json:"last_login,omitempty"`          // Time of the last sign-in, as a Unix timestamp; unset if the user never signed in.
+	Phones              []*Phone              `json:"phones,omitempty"`              // Phones of the user.
+	Tokens              []*Token              `json:"tokens,omitempty"`              // Hardware tokens of the user.
+	U2FTokens           []*U2FToken           `json:"u2ftokens,omitempty"`           // U2F security keys of the user.
+	WebAuthnCredentials []*WebAuthnCredential `json:"webauthncredentials,omitempty"` // WebAuthn credentials of the user (security keys, platform authenticators).
+	Groups              []*Group              `json:"groups,omitempty"`              // Groups of the user.
+}
+
+// AccountStatus returns the normalized status of the user; users in `bypass` sign in without a second factor
+func (u *User) AccountStatus() normalize.Status {
+	switch u.Status {
+	case "active", "bypass":
+		return normalize.StatusActive
+	case "locked out":
+		return normalize.StatusLocked
+	case "disabled":
+		return normalize.StatusSuspended
+	case "pending deletion":
+		return normalize.StatusDeprovisioned
+	}
+	return normalize.StatusUnknown
+}
+
+// https://duo.com/docs/adminapi#phones
+type Phone struct {
+	PhoneID      string   `json:"phone_id,omitempty"`     // The ID of the phone.
+	Number       string   `json:"number,omitempty"`       // The phone number.
+	Name         string   `json:"name,omitempty"`         // Name of the phone.
+	Type         string   `json:"type,omitempty"`         // `mobile`, `landline` or `unknown`.
+	Platform     string   `json:"platform,omitempty"`     // e.g. `Apple iOS`, `Google Android`.
+	Activated    bool     `json:"activated,omitempty"`    // True if Duo Mobile is activated on the phone.
+	Capabilities []string `json:"capabilities,omitempty"` // Factors the phone supports, e.g. `push`, `sms`, `phone`, `mobile_otp`.
+}
+
+// https://duo.com/docs/adminapi#tokens
+type Token struct {
+	TokenID string `json:"token_id,omitempty"` // The ID of the hardware token.
+	Serial  string `json:"serial,omitempty"`   // The serial number of the token.
+	Type    string `json:"type,omitempty"`     // e.g. `h6` (HOTP-6), `yk` (YubiKey AES).
+}
+
+// https://duo.com/docs/adminapi#u2f
+type U2FToken struct {
+	RegistrationID string `json:"registration_id,omitempty"` // The ID of the registration.
+	DateAdded      int64  `json:"date_added,omitempty"`      // Time the token was registered, as a Unix timestamp.
+}
+
+// https://duo.com/docs/adminapi#webauthn-credentials
+type WebAuthnCredential struct {
+	WebAuthnKey    string `json:"webauthnkey,omitempty"`     // The ID of the credential.
+	CredentialName string `json:"credential_name,omitempty"` // Name of the credential.
+	Label          string `json:"label,omitempty"`           // e.g. `Security Key` or `Touch ID`.
+	DateAdded      int64  `json:"date_added,omitempty"`      // Time the credential was registered, as a Unix timestamp.
+}
+
+// https://duo.com/docs/adminapi#groups
+type Group struct {
+	GroupID string `json:"group_id,omitempty"` // The ID of the group.
+	Name    string `json:"name,omitempty"`     // The name of the group.
+}
+
+// END OF DUO USERS STRUCTS
+//---------------------------------------------------------------------
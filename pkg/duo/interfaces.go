@@ -0,0 +1,25 @@
+/*
+# Duo Interfaces
+
+This package contains the interfaces of each Duo resource, which the resource clients satisfy. Code depending on an
+interface rather than the clients can be unit tested with the mocks of `pkg/duo/duomock`
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/duo/interfaces.go
+package duo
+
+//go:generate go run ../../cmd/mockgen
+
+// UserService reads users
+type UserService interface {
+	ListAllUsers() (*Users, error)
+	GetUserByUsername(username string) (*User, error)
+}
+
+var (
+	_ UserService = (*UserClient)(nil)
+)
@@ -0,0 +1,78 @@
+/*
+# Duo - Users
+
+This package contains all the methods to interact with the Duo Admin API for users:
+https://duo.com/docs/adminapi#users
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/duo/users.go
+package duo
+
+import (
+	"fmt"
+	"time"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+)
+
+// UserClient for chaining methods
+type UserClient struct {
+	*Client
+}
+
+// Entry point for user-related operations
+func (c *Client) Users() *UserClient {
+	return &UserClient{
+		Client: c,
+	}
+}
+
+/*
+ * # List all users, with their phones, tokens and WebAuthn credentials
+ * /admin/v1/users
+ * - https://duo.com/docs/adminapi#retrieve-users
+ */
+func (c *UserClient) ListAllUsers() (*Users, error) {
+	url := c.BuildURL(DuoUsers)
+
+	var cache Users
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	users, err := doPaginated[User](c.Client, url, 300)
+	if err != nil {
+		return nil, err
+	}
+
+	result := Users(users)
+	c.SetCache(url, result, 30*time.Minute)
+	return &result, nil
+}
+
+/*
+ * # Get a user by username
+ * /admin/v1/users?username={username}
+ * - https://duo.com/docs/adminapi#retrieve-users
+ */
+func (c *UserClient) GetUserByUsername(username string) (*User, error) {
+	url := c.BuildURL(DuoUsers)
+
+	q := struct {
+		Username string `url:"username"`
+	}{username}
+
+	res, err := do[Users](c.Client, "GET", url, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Response) == 0 {
+		return nil, fmt.Errorf("user %s: %w", username, rerrors.ErrNotFound)
+	}
+
+	return res.Response[0], nil
+}
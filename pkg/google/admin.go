@@ -13,12 +13,15 @@ https://developers.google.com/admin-sdk/reference-overview
 package google
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/gemini-oss/rego/pkg/common/checkpoint"
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/pool"
 	ss "github.com/gemini-oss/rego/pkg/common/starstruct"
 )
 
@@ -61,12 +64,6 @@ func (c *Client) Admin() *AdminClient {
 		Client: c,
 	}
 
-	// https://developers.google.com/admin-sdk/directory/v1/limits
-	ac.HTTP.RateLimiter.Available = 2400
-	ac.HTTP.RateLimiter.Limit = 2400
-	ac.HTTP.RateLimiter.Interval = 1 * time.Minute
-	ac.HTTP.RateLimiter.Log.Verbosity = c.Log.Verbosity
-
 	return ac
 }
 
@@ -82,6 +79,7 @@ type ReportsQuery struct {
 	Filters                        string `url:"filters,omitempty"`                        // The filters query string is a comma-separated list composed of event parameters manipulated by relational operators.
 	MaxResults                     int    `url:"maxResults,omitempty"`                     // Determines how many activity records are shown on each response page.
 	OrgUnitId                      string `url:"orgUnitId,omitempty"`                      // ID of the organizational unit to report on.
+	Parameters                     string `url:"parameters,omitempty"`                     // Usage reports only: comma-separated parameters to return, e.g. `accounts:is_2sv_enrolled`.
 	PageToken                      string `url:"pageToken,omitempty"`                      // The token to specify next page.
 	StartTime                      string `url:"startTime,omitempty"`                      // Sets the beginning of the range of time shown in the report.
 	GroupIdFilter                  string `url:"groupIdFilter,omitempty"`                  // Comma separated group ids (obfuscated) on which user activities are filtered.
@@ -216,41 +214,17 @@ func (c *AdminClient) GetAssignmentsForRole(roleId string, customer *Customer) (
  * https://developers.google.com/admin-sdk/directory/v1/reference/roleAssignments/list
  */
 func (c *AdminClient) GetUsersFromRoleAssignments(sem chan struct{}, roleAssignments []RoleAssignment) ([]*User, error) {
-	// Make a channel for the users and their errors
-	userChannel := make(chan *User, len(roleAssignments))
-	userErrChannel := make(chan error)
-
-	var userWg sync.WaitGroup
-	for _, assignment := range roleAssignments {
-		assign := assignment
-		userWg.Add(1)
-		go func(assign RoleAssignment) {
-			defer userWg.Done()
-			sem <- struct{}{} // Acquire a token
-			user, err := c.Users().GetUser(assign.AssignedTo)
-			if err != nil {
-				userErrChannel <- err
-				return
-			}
-			userChannel <- user
-			<-sem // Release the token
-		}(assign)
-	}
-
-	userWg.Wait()
-	close(userChannel)
-	close(userErrChannel)
-
-	for err := range userErrChannel {
+	// `sem` is shared between the roles of a report, bounding their lookups together
+	results := pool.Map(c.HTTP.Context(), roleAssignments, pool.Options{Workers: cap(sem), RateLimiter: c.HTTP.RateLimiter, StopOnError: true}, func(_ context.Context, assign RoleAssignment) (*User, error) {
+		sem <- struct{}{} // Acquire a token
+		defer func() { <-sem }()
+		return c.Users().GetUser(assign.AssignedTo)
+	})
+	if err := pool.FirstError(results); err != nil {
 		return nil, err
 	}
 
-	users := []*User{}
-	for user := range userChannel {
-		users = append(users, user)
-	}
-
-	return users, nil
+	return pool.Values(results), nil
 }
 
 /*
@@ -264,56 +238,20 @@ func (c *AdminClient) GenerateRoleReport(roleId string, customer *Customer) ([]*
 	// 10 is the maximum number of concurrent requests.
 	sem := make(chan struct{}, 10)
 
-	// WaitGroup to ensure all go routines complete their tasks.
-	var wg sync.WaitGroup
-
 	// Fetch all roles for the provided customer ID
 	roles, err := c.ListAllRoles(customer)
 	if err != nil {
 		return nil, err
 	}
 
-	// Buffered channel to hold the RoleReport result from each goroutine
-	reportsChannel := make(chan *RoleReport, len(roles.Items))
-
-	// Buffered channel to hold any errors that occur while generating the role reports
-	reportsErrChannel := make(chan error)
-
-	// Inner function to generate a RoleReport for a single role. This will be run in a separate goroutine.
-	generateReport := func(role Role) {
-		// Ensure the waitgroup counter is decremented when this function finishes
-		defer wg.Done()
-
-		roleAssignments, err := c.GetAssignmentsForRole(role.RoleID, customer)
-		if err != nil {
-			c.Log.Println("Error getting role assignments:", err)
-			reportsErrChannel <- err
-			return
-		}
-
-		userList, err := c.GetUsersFromRoleAssignments(sem, roleAssignments.Items)
-		if err != nil {
-			c.Log.Println("Error getting role assignments:", err)
-			reportsErrChannel <- err
-			return
-		}
-
-		// Create a report for the role and send it to the reportsChannel
-		roleReport := &RoleReport{
-			Role:  &role,
-			Users: userList,
-		}
-		reportsChannel <- roleReport
-	}
-
 	// If a specific role ID is provided, generate a report only for that role
+	selected := []Role{}
 	if roleId != "" {
-		wg.Add(1)
 		role, err := c.GetRole(roleId, customer)
 		if err != nil {
 			return nil, err
 		}
-		go generateReport(*role)
+		selected = append(selected, *role)
 	} else {
 		for _, role := range roles.Items {
 			// Ignore certain system roles
@@ -321,26 +259,35 @@ func (c *AdminClient) GenerateRoleReport(roleId string, customer *Customer) ([]*
 			case "_GCDS_DIRECTORY_MANAGEMENT_ROLE", "_LDAP_USER_MANAGEMENT_SUPPORT_ROLE", "_LDAP_USER_MANAGEMENT_READONLY_ROLE", "_LDAP_PASSWORD_REBIND_ROLE", "_LDAP_GROUP_MANAGEMENT_READONLY_ROLE":
 				continue
 			default:
-				// For each role, increment the waitgroup counter and launch a goroutine
-				wg.Add(1)
-				go generateReport(role)
+				selected = append(selected, role)
 			}
 		}
 	}
 
-	// Wait for all the goroutines to finish, then close the result and error channels
-	wg.Wait()
-	close(reportsChannel)
-	close(reportsErrChannel)
+	// Generate a RoleReport for each role, stopping at the first failure
+	results := pool.Map(c.HTTP.Context(), selected, pool.Options{StopOnError: true}, func(_ context.Context, role Role) (*RoleReport, error) {
+		roleAssignments, err := c.GetAssignmentsForRole(role.RoleID, customer)
+		if err != nil {
+			c.Log.Error("Error getting role assignments:", err)
+			return nil, err
+		}
 
-	if err, ok := <-reportsErrChannel; ok {
+		userList, err := c.GetUsersFromRoleAssignments(sem, roleAssignments.Items)
+		if err != nil {
+			c.Log.Error("Error getting role assignments:", err)
+			return nil, err
+		}
+
+		return &RoleReport{
+			Role:  &role,
+			Users: userList,
+		}, nil
+	})
+	if err := pool.FirstError(results); err != nil {
 		return nil, err
 	}
 
-	roleReports := []*RoleReport{}
-	for report := range reportsChannel {
-		roleReports = append(roleReports, report)
-	}
+	roleReports := pool.Values(results)
 
 	return roleReports, nil
 }
@@ -358,7 +305,7 @@ func (c *AdminClient) SaveRoleReport(reports []*RoleReport) (*Spreadsheet, error
 	vr.Values = append(vr.Values, headers)
 	for _, role := range reports {
 		for _, user := range role.Users {
-			vr.Values = append(vr.Values, []string{user.Name.FullName, user.PrimaryEmail, role.Role.RoleName, user.LastLoginTime, user.OrgUnitPath, strconv.FormatBool(user.Suspended), strconv.FormatBool(user.Archived)})
+			vr.Values = append(vr.Values, []string{user.Name.FullName, user.PrimaryEmail, role.Role.RoleName, user.LastLoginTime.String(), string(user.OrgUnitPath), strconv.FormatBool(user.Suspended), strconv.FormatBool(user.Archived)})
 		}
 	}
 
@@ -425,6 +372,126 @@ func (c *AdminClient) GetFileOwnership(fileID string) (string, error) {
 	return "", fmt.Errorf("no owner found for file %s", fileID)
 }
 
+/*
+ * # List the Usage Reports of every User for a date
+ * - `date` is `yyyy-mm-dd`; reports are available after a delay of a few days
+ * - `parameters` restricts the parameters returned, e.g. `accounts:is_2sv_enrolled`
+ * /admin/reports/v1/usage/users/all/dates/{date}
+ * https://developers.google.com/admin-sdk/reports/reference/rest/v1/userUsageReport/get
+ */
+func (c *AdminClient) ListUserUsage(date string, parameters ...string) ([]*UsageReport, error) {
+	url := c.BuildURL(AdminReports, nil, "usage", "users", "all", "dates", date)
+
+	var cache []*UsageReport
+	if c.GetCache(url+strings.Join(parameters, ","), &cache) {
+		return cache, nil
+	}
+
+	q := ReportsQuery{
+		MaxResults: 1000,
+		Parameters: strings.Join(parameters, ","),
+	}
+
+	reports := []*UsageReport{}
+	for {
+		page, err := do[UsageReports](c.Client, "GET", url, q, nil)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, page.UsageReports...)
+
+		if page.NextPageToken == "" {
+			break
+		}
+		q.PageToken = page.NextPageToken
+	}
+
+	c.SetCache(url+strings.Join(parameters, ","), reports, 30*time.Minute)
+	return reports, nil
+}
+
+/*
+ * # List the Activities of every User in an application
+ * - `application` is e.g. `token` (OAuth grants to third-party apps), `login` or `admin`
+ * - `q` restricts the activities, e.g. with `EventName` and `StartTime`; pages are requested by the client
+ * /admin/reports/v1/activity/users/all/applications/{applicationName}
+ * https://developers.google.com/admin-sdk/reports/reference/rest/v1/activities/list
+ */
+func (c *AdminClient) ListActivities(application string, q ReportsQuery) ([]Report, error) {
+	return iterator.Collect(c.IterActivities(application, q))
+}
+
+/*
+ * # Iterate the Activities of every User in an application
+ * - Pages are requested as the activities are consumed, so callers can stop early
+ * /admin/reports/v1/activity/users/all/applications/{applicationName}
+ * https://developers.google.com/admin-sdk/reports/reference/rest/v1/activities/list
+ */
+func (c *AdminClient) IterActivities(application string, q ReportsQuery) iterator.Seq[Report] {
+	url := fmt.Sprintf(ReportsActivities, "all", application)
+	c.Log.Debug("url:", url)
+
+	if q.MaxResults == 0 {
+		q.MaxResults = 1000
+	}
+
+	return iterator.Pages(func(pageToken string) ([]Report, string, error) {
+		q.PageToken = pageToken
+		page, err := do[Report](c.Client, "GET", url, q, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Items, page.NextPageToken, nil
+	})
+}
+
+/*
+ * # Tail the Activities of every User in an application
+ * - Iterates the activities after `cp`, advancing `cp` to the newest once every activity was consumed, so a later run
+ *   resumes after it (see `checkpoint.Run`); Google lists the newest activities first, so a run stopped part way
+ *   leaves `cp` unchanged
+ * - The activity at the time of `cp` is skipped by its unique qualifier, the cursor of `cp`
+ * - Google may report activities hours after they occurred; activities reported after a later one was synced are missed
+ * /admin/reports/v1/activity/users/all/applications/{applicationName}
+ * https://developers.google.com/admin-sdk/reports/reference/rest/v1/activities/list
+ */
+func (c *AdminClient) TailActivities(application string, cp *checkpoint.Checkpoint, q ReportsQuery) iterator.Seq[Report] {
+	if !cp.Time.IsZero() {
+		q.StartTime = cp.Time.UTC().Format(time.RFC3339Nano)
+	}
+	if q.EndTime == "" {
+		q.EndTime = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	activities := c.IterActivities(application, q)
+	return func(yield func(Report, error) bool) {
+		newest := *cp
+		completed := true
+		activities(func(activity Report, err error) bool {
+			if err != nil {
+				completed = false
+				return yield(activity, err)
+			}
+
+			at := activity.ID.Time.Time
+			if at.Equal(cp.Time) && activity.ID.UniqueQualifier == cp.Cursor {
+				return true
+			}
+			if !yield(activity, nil) {
+				completed = false
+				return false
+			}
+			if at.After(newest.Time) {
+				newest = checkpoint.Checkpoint{Time: at, Cursor: activity.ID.UniqueQualifier}
+			}
+			return true
+		})
+		if completed {
+			*cp = newest
+		}
+	}
+}
+
 /*
  * Get Root Organization Unit of current customer
  * /admin/directory/v1/customer/{customerId}/orgunits/{orgUnitPath=**}
@@ -460,7 +527,7 @@ func (c *AdminClient) RootOU(customer *Customer) (*OrgUnit, error) {
  * https://developers.google.com/admin-sdk/directory/reference/rest/v1/orgunits/get
  */
 func (c *AdminClient) GetOU(customer *Customer, orgUnitPath string) (*OrgUnit, error) {
-	url := c.BuildURL(DirectoryOrgUnits, customer, strings.TrimPrefix(orgUnitPath, "/"))
+	url := c.BuildURL(DirectoryOrgUnits, customer, strings.Split(strings.TrimPrefix(orgUnitPath, "/"), "/")...)
 
 	var cache OrgUnit
 	if c.GetCache(url, &cache) {
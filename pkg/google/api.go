@@ -95,7 +95,7 @@ func FetchDirectoryEndpoints() (*DirectoryList, *Endpoints, error) {
 		"Content-Type": requests.JSON,
 	}
 
-	httpClient := requests.NewClient(nil, headers, nil)
+	httpClient := requests.NewClient(nil, headers, nil, requests.WithUserAgent("google"))
 	resp, body, err := httpClient.DoRequest("GET", "https://www.googleapis.com/discovery/v1/apis/", nil, nil)
 	if err != nil {
 		return nil, nil, err
@@ -114,7 +114,7 @@ func FetchDirectoryEndpoints() (*DirectoryList, *Endpoints, error) {
 	Endpoints := &Endpoints{}
 
 	for _, item := range googleAPIs.Items {
-		fmt.Println(item.DiscoveryRestUrl)
+		httpClient.Log.Debug(item.DiscoveryRestUrl)
 		switch item.DiscoveryRestUrl {
 		case "https://realtimebidding.googleapis.com/$discovery/rest?version=v1alpha":
 		case "https://poly.googleapis.com/$discovery/rest?version=v1":
@@ -150,16 +150,14 @@ func ReadDiscoveryDirectory() (*DirectoryList, *Endpoints, error) {
 
 	rootPath, err := os.Getwd()
 	if err != nil {
-		fmt.Printf("Error determining root path: %s\n", err)
-		return nil, nil, err
+		return nil, nil, fmt.Errorf("determining root path: %w", err)
 	}
 
 	filePath := filepath.Join(rootPath, "..", "json", "google_directory.json")
 
 	file, err := os.Open(filePath)
 	if err != nil {
-		fmt.Printf("Error opening file: %s\n", err)
-		return nil, nil, err
+		return nil, nil, fmt.Errorf("opening file: %w", err)
 	}
 	defer file.Close()
 
@@ -174,12 +172,12 @@ func ReadDiscoveryDirectory() (*DirectoryList, *Endpoints, error) {
 		"Accept":       requests.JSON,
 		"Content-Type": requests.JSON,
 	}
-	httpClient := requests.NewClient(nil, headers, nil)
+	httpClient := requests.NewClient(nil, headers, nil, requests.WithUserAgent("google"))
 
 	Endpoints := &Endpoints{}
 
 	for _, item := range googleAPIs.Items {
-		fmt.Println(item.DiscoveryRestUrl)
+		httpClient.Log.Debug(item.DiscoveryRestUrl)
 		switch item.DiscoveryRestUrl {
 		// Skip this API as it's not available
 		case "https://realtimebidding.googleapis.com/$discovery/rest?version=v1alpha":
@@ -215,8 +213,7 @@ func ReadDiscoveryDirectory() (*DirectoryList, *Endpoints, error) {
 func SaveEndpoints(data interface{}) error {
 	_, err := endpointsJSON.ReadFile("json/google_endpoints.json")
 	if err != nil {
-		fmt.Printf("Error opening file: %s\n", err)
-		return err
+		return fmt.Errorf("opening file: %w", err)
 	}
 
 	// Get the absolute path of the currently running file
@@ -249,8 +246,7 @@ func OrganizeScopes() error {
 	endpoints := &Endpoints{}
 	file, err := endpointsJSON.ReadFile("json/google_endpoints.json")
 	if err != nil {
-		fmt.Printf("Error opening file: %s\n", err)
-		return err
+		return fmt.Errorf("opening file: %w", err)
 	}
 	_ = json.Unmarshal([]byte(file), &endpoints)
 
@@ -321,8 +317,7 @@ func DedupeScopes(slice []string) []string {
 func LoadScopes(service string) ([]string, error) {
 	file, err := scopesJSON.ReadFile("json/google_scopes.json")
 	if err != nil {
-		fmt.Printf("Error opening file: %s\n", err)
-		return nil, err
+		return nil, fmt.Errorf("opening file: %w", err)
 	}
 
 	as := &AllowedScopes{}
@@ -0,0 +1,99 @@
+/*
+# Google Workspace - Calendar
+
+This package implements logic related to the Google Calendar API:
+https://developers.google.com/calendar/api/v3/reference
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/google/calendar.go
+package google
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+var (
+	CalendarBaseURL = fmt.Sprintf("%s/calendar/v3", BaseURL)                       // https://developers.google.com/calendar/api/v3/reference
+	CalendarEvents  = fmt.Sprintf("%s/calendars/%s/events", CalendarBaseURL, "%s") // https://developers.google.com/calendar/api/v3/reference/events
+)
+
+// CalendarClient for chaining methods
+type CalendarClient struct {
+	*Client
+}
+
+// Entry point for calendar-related operations
+func (c *Client) Calendar() *CalendarClient {
+	return &CalendarClient{
+		Client: c,
+	}
+}
+
+/*
+ * Retrieves an Event from a Calendar
+ * - The client must have access to the calendar, e.g. by impersonating its owner (see `AsUser`)
+ * /calendar/v3/calendars/{calendarId}/events/{eventId}
+ * https://developers.google.com/calendar/api/v3/reference/events/get
+ */
+func (c *CalendarClient) GetEvent(calendarID string, eventID string) (*CalendarEvent, error) {
+	url := fmt.Sprintf(CalendarEvents+"/%s", requests.EscapePathSegment(calendarID), requests.EscapePathSegment(eventID))
+	c.Log.Debug("url:", url)
+
+	event, err := do[CalendarEvent](c.Client, "GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+/*
+ * Invites one or more attendees to an existing Event
+ * - Attendees already on the event are left untouched, and invitations are sent to the new attendees
+ * /calendar/v3/calendars/{calendarId}/events/{eventId}
+ * https://developers.google.com/calendar/api/v3/reference/events/patch
+ */
+func (c *CalendarClient) AddAttendees(calendarID string, eventID string, emails ...string) (*CalendarEvent, error) {
+	event, err := c.GetEvent(calendarID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	attendees := event.Attendees
+	for _, email := range emails {
+		invited := false
+		for _, a := range attendees {
+			if strings.EqualFold(a.Email, email) {
+				invited = true
+				break
+			}
+		}
+		if !invited {
+			attendees = append(attendees, &EventAttendee{Email: email})
+		}
+	}
+
+	url := fmt.Sprintf(CalendarEvents+"/%s", requests.EscapePathSegment(calendarID), requests.EscapePathSegment(eventID))
+	c.Log.Debug("url:", url)
+
+	q := struct {
+		SendUpdates string `url:"sendUpdates"`
+	}{
+		SendUpdates: "all",
+	}
+
+	c.Log.Printf("Inviting %v to event %s", emails, event.Summary)
+	result, err := do[CalendarEvent](c.Client, "PATCH", url, q, map[string]interface{}{"attendees": attendees})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,101 @@
+/*
+# Google Workspace - Data Transfer
+
+This package implements logic related to the Google Admin SDK Data Transfer API:
+https://developers.google.com/admin-sdk/data-transfer/reference/rest
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/google/datatransfer.go
+package google
+
+import (
+	"fmt"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+var (
+	AdminDataTransfer        = fmt.Sprintf("%s/admin/datatransfer/v1", AdminBaseURL) // https://developers.google.com/admin-sdk/data-transfer/reference/rest
+	DataTransferApplications = fmt.Sprintf("%s/applications", AdminDataTransfer)     // https://developers.google.com/admin-sdk/data-transfer/reference/rest/v1/applications
+	DataTransfers            = fmt.Sprintf("%s/transfers", AdminDataTransfer)        // https://developers.google.com/admin-sdk/data-transfer/reference/rest/v1/transfers
+)
+
+const (
+	DriveApplicationID    = "55656082996"  // Drive and Docs
+	CalendarApplicationID = "435070579839" // Calendar
+)
+
+// DataTransferClient for chaining methods
+type DataTransferClient struct {
+	*Client
+}
+
+// Entry point for data transfer operations
+func (c *Client) DataTransfer() *DataTransferClient {
+	return &DataTransferClient{
+		Client: c,
+	}
+}
+
+/*
+ * Transfer a user's data to another user
+ * - If no applications are provided, Drive (shared and private files) and Calendar are transferred
+ * /admin/datatransfer/v1/transfers
+ * https://developers.google.com/admin-sdk/data-transfer/reference/rest/v1/transfers/insert
+ */
+func (c *DataTransferClient) TransferData(oldOwnerID, newOwnerID string, apps ...ApplicationDataTransfer) (*DataTransfer, error) {
+	url := DataTransfers
+	c.Log.Debug("url:", url)
+
+	if len(apps) == 0 {
+		apps = []ApplicationDataTransfer{
+			{
+				ApplicationID: DriveApplicationID,
+				ApplicationTransferParams: []ApplicationTransferParam{
+					{Key: "PRIVACY_LEVEL", Value: []string{"SHARED", "PRIVATE"}},
+				},
+			},
+			{
+				ApplicationID: CalendarApplicationID,
+				ApplicationTransferParams: []ApplicationTransferParam{
+					{Key: "RELEASE_RESOURCES", Value: []string{"TRUE"}},
+				},
+			},
+		}
+	}
+
+	transfer := &DataTransfer{
+		OldOwnerUserID:           oldOwnerID,
+		NewOwnerUserID:           newOwnerID,
+		ApplicationDataTransfers: apps,
+	}
+
+	c.Log.Printf("Transferring data from %s to %s", oldOwnerID, newOwnerID)
+	result, err := do[DataTransfer](c.Client, "POST", url, nil, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+/*
+ * Get the status of a data transfer
+ * /admin/datatransfer/v1/transfers/{dataTransferId}
+ * https://developers.google.com/admin-sdk/data-transfer/reference/rest/v1/transfers/get
+ */
+func (c *DataTransferClient) GetTransfer(transferID string) (*DataTransfer, error) {
+	url := requests.JoinURL(DataTransfers, transferID)
+	c.Log.Debug("url:", url)
+
+	result, err := do[DataTransfer](c.Client, "GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -39,12 +39,6 @@ func (c *Client) Devices() *DeviceClient {
 		},
 	}
 
-	// https://developers.google.com/admin-sdk/directory/v1/limits
-	dc.HTTP.RateLimiter.Available = 2400
-	dc.HTTP.RateLimiter.Limit = 2400
-	dc.HTTP.RateLimiter.Interval = 1 * time.Minute
-	dc.HTTP.RateLimiter.Log.Verbosity = c.Log.Verbosity
-
 	return dc
 }
 
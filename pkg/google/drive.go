@@ -41,12 +41,6 @@ func (c *Client) Drive() *DriveClient {
 		Client: c,
 	}
 
-	// https://developers.google.com/drive/api/guides/limits
-	dc.HTTP.RateLimiter.Available = 12000
-	dc.HTTP.RateLimiter.Limit = 12000
-	dc.HTTP.RateLimiter.Interval = 1 * time.Minute
-	dc.HTTP.RateLimiter.Log.Verbosity = c.Log.Verbosity
-
 	return dc
 }
 
@@ -13,10 +13,14 @@ package google
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/gemini-oss/rego/pkg/common/auth"
 	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/enum"
 	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/normalize"
+	"github.com/gemini-oss/rego/pkg/common/options"
 	"github.com/gemini-oss/rego/pkg/common/requests"
 	"golang.org/x/oauth2/jwt"
 )
@@ -29,6 +33,7 @@ type AuthCredentials struct {
 	CICD        bool     // If true, will use environmental variables
 	Scopes      []string // Scopes to use for OAuth
 	Subject     string   // Subject to impersonate
+	Token       string   // Path of the OAuth token (JSON) of the user, for oauth_client; `GOOGLE_OAUTH_TOKEN` (base64) in CI/CD
 }
 
 type GoogleConfig struct {
@@ -36,6 +41,7 @@ type GoogleConfig struct {
 	Installed auth.OAuthConfig `json:"installed"`
 }
 
+// Client is safe for concurrent use by multiple goroutines, except for `ImpersonateUser`; derive a client per user with `AsUser`
 type Client struct {
 	Auth     AuthCredentials   // Credentials to use for authentication
 	BaseURL  string            // Base URL to use for API calls
@@ -46,6 +52,9 @@ type Client struct {
 	Log      *log.Logger       // Logger
 	Cache    *cache.Cache      // Cache
 	Customer *Customer         // Google Workspace Account
+	Version  APIVersion        // Version of the Directory API the client is pinned to; `DefaultAPIVersion` when empty
+
+	opts *options.Options // Options of `NewClient`, applied to each authorized HTTP client
 }
 
 // Customer represents a Google Workspace account.
@@ -106,6 +115,14 @@ func (e *ErrorDetail) Error() string {
 	return fmt.Sprintf("code: %d, message: %s", e.Code, e.Message)
 }
 
+// reason returns the reason of the first error item, e.g. `rateLimitExceeded`; empty when there is none
+func (e *ErrorDetail) reason() string {
+	if len(e.Errors) == 0 || e.Errors[0] == nil {
+		return ""
+	}
+	return e.Errors[0].Reason
+}
+
 // ErrorItem contains detailed information about an individual error.
 type ErrorItem struct {
 	Domain  string `json:"domain,omitempty"`  // The domain of the error.
@@ -160,6 +177,34 @@ type Report struct {
 	UsageReports  []Report   `json:"usageReports,omitempty"`  // Various application parameter records
 }
 
+// https://developers.google.com/admin-sdk/reports/reference/rest/v1/userUsageReport/get#response-body
+type UsageReports struct {
+	Kind          string         `json:"kind,omitempty"`          // The type of API resource
+	Etag          string         `json:"etag,omitempty"`          // ETag of the resource
+	UsageReports  []*UsageReport `json:"usageReports,omitempty"`  // Usage report of each entity
+	Warnings      []Warning      `json:"warnings,omitempty"`      // Warnings, e.g. that the data of the date is not yet available
+	NextPageToken string         `json:"nextPageToken,omitempty"` // Token to specify next page
+}
+
+// https://developers.google.com/admin-sdk/reports/reference/rest/v1/UsageReports#UsageReport
+type UsageReport struct {
+	Kind       string            `json:"kind,omitempty"`       // The type of API resource
+	Etag       string            `json:"etag,omitempty"`       // ETag of the resource
+	Date       string            `json:"date,omitempty"`       // The date of the report
+	Entity     Entity            `json:"entity,omitempty"`     // The entity the report is about, e.g. a user
+	Parameters []ReportParameter `json:"parameters,omitempty"` // Parameter value pairs, e.g. `accounts:is_2sv_enrolled`
+}
+
+// Parameter returns the parameter of a usage report by name, e.g. `accounts:num_security_keys`
+func (r *UsageReport) Parameter(name string) (ReportParameter, bool) {
+	for _, p := range r.Parameters {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return ReportParameter{}, false
+}
+
 type Event struct {
 	Type       string            `json:"type,omitempty"`       // Type of event
 	Name       string            `json:"name,omitempty"`       // Name of the event
@@ -181,10 +226,10 @@ type ReportParameter struct {
 }
 
 type ActivityID struct {
-	Time            string `json:"time,omitempty"`            // Time of occurrence of the activity
-	UniqueQualifier string `json:"uniqueQualifier,omitempty"` // Unique qualifier if multiple events have the same time
-	ApplicationName string `json:"applicationName,omitempty"` // Application name to which the event belongs
-	CustomerID      string `json:"customerId,omitempty"`      // The unique identifier for a Google Workspace account
+	Time            normalize.Time `json:"time,omitempty"`            // Time of occurrence of the activity
+	UniqueQualifier string         `json:"uniqueQualifier,omitempty"` // Unique qualifier if multiple events have the same time
+	ApplicationName string         `json:"applicationName,omitempty"` // Application name to which the event belongs
+	CustomerID      string         `json:"customerId,omitempty"`      // The unique identifier for a Google Workspace account
 }
 
 type Actor struct {
@@ -1178,9 +1223,10 @@ type User struct {
 	Aliases                    []string       `json:"aliases,omitempty"`                    // User aliases
 	Archived                   bool           `json:"archived,omitempty"`                   // User's archival status
 	ChangePasswordAtNextLogin  bool           `json:"changePasswordAtNextLogin,omitempty"`  // User's change password at next login status
-	CreationTime               string         `json:"creationTime,omitempty"`               // User's creation time
+	CreationTime               normalize.Time `json:"creationTime,omitempty"`               // User's creation time
+	CustomSchemas              CustomSchemas  `json:"customSchemas,omitempty"`              // User's custom schema fields
 	CustomerID                 string         `json:"customerId,omitempty"`                 // User's customer ID
-	DeletionTime               string         `json:"deletionTime,omitempty"`               // User's deletion time
+	DeletionTime               normalize.Time `json:"deletionTime,omitempty"`               // User's deletion time
 	Emails                     []Email        `json:"emails,omitempty"`                     // User's emails
 	Etag                       string         `json:"etag,omitempty"`                       // ETag of the user
 	ExternalIds                []ExternalID   `json:"externalIds,omitempty"`                // User's external IDs
@@ -1197,12 +1243,12 @@ type User struct {
 	IpWhitelisted              bool           `json:"ipWhitelisted,omitempty"`              // User's IP whitelist status
 	Kind                       string         `json:"kind,omitempty"`                       // The type of the API resource
 	Languages                  []Language     `json:"languages,omitempty"`                  // User's languages
-	LastLoginTime              string         `json:"lastLoginTime,omitempty"`              // User's last login time
+	LastLoginTime              normalize.Time `json:"lastLoginTime,omitempty"`              // User's last login time
 	Locations                  []UserLocation `json:"locations,omitempty"`                  // User's locations
 	Name                       UserName       `json:"name,omitempty"`                       // User's name
 	NonEditableAliases         []string       `json:"nonEditableAliases,omitempty"`         // User's non-editable aliases
 	Notes                      Note           `json:"notes,omitempty"`                      // User's notes
-	OrgUnitPath                string         `json:"orgUnitPath,omitempty"`                // User's organizational unit path
+	OrgUnitPath                OrgUnitPath    `json:"orgUnitPath,omitempty"`                // User's organizational unit path
 	Organizations              []Organization `json:"organizations,omitempty"`              // User's organizations
 	Password                   Password       `json:"password,omitempty"`                   // User's password
 	Phones                     []Phone        `json:"phones,omitempty"`                     // A list of the user's phone numbers
@@ -1220,6 +1266,31 @@ type User struct {
 	Websites                   []Website      `json:"websites,omitempty"`                   // The list of the user's websites
 }
 
+// AdminRole returns the administrator role of the user, from its `isAdmin` and `isDelegatedAdmin` flags; empty if it has none
+func (u *User) AdminRole() AdminRole {
+	switch {
+	case u.IsAdmin:
+		return SUPER_ADMIN
+	case u.IsDelegatedAdmin:
+		return DELEGATED_ADMIN
+	}
+	return ""
+}
+
+// AccountStatus returns the normalized status of the user; archived users are suspended
+func (u *User) AccountStatus() normalize.Status {
+	switch {
+	case !u.DeletionTime.IsZero():
+		return normalize.StatusDeprovisioned
+	case u.Suspended || u.Archived:
+		return normalize.StatusSuspended
+	}
+	return normalize.StatusActive
+}
+
+// CustomSchemas holds the values of custom user attributes, by schema name and field name
+type CustomSchemas map[string]map[string]interface{}
+
 type Email struct {
 	Address    string `json:"address,omitempty"`    // The user's email address
 	CustomType string `json:"customType,omitempty"` // The custom value if the email address type is custom
@@ -1338,6 +1409,174 @@ type Website struct {
 // END OF USER STRUCTS
 //-----------------------------------------------------------------------------
 
+// ### Data Transfer Structs
+// ----------------------------------------------------------------------------
+// https://developers.google.com/admin-sdk/data-transfer/reference/rest/v1/transfers
+type DataTransfer struct {
+	ApplicationDataTransfers  []ApplicationDataTransfer `json:"applicationDataTransfers,omitempty"`  // The list of per-application data transfer resources
+	Etag                      string                    `json:"etag,omitempty"`                      // ETag of the resource
+	ID                        string                    `json:"id,omitempty"`                        // The transfer's ID
+	Kind                      string                    `json:"kind,omitempty"`                      // Identifies the resource as a DataTransfer request
+	NewOwnerUserID            string                    `json:"newOwnerUserId,omitempty"`            // ID of the user to whom the data is being transferred
+	OldOwnerUserID            string                    `json:"oldOwnerUserId,omitempty"`            // ID of the user whose data is being transferred
+	OverallTransferStatusCode string                    `json:"overallTransferStatusCode,omitempty"` // Overall transfer status {inProgress, completed, failed, pending}
+	RequestTime               string                    `json:"requestTime,omitempty"`               // The time at which the data transfer was requested
+}
+
+type ApplicationDataTransfer struct {
+	ApplicationID             string                     `json:"applicationId,omitempty"`             // The application's ID
+	ApplicationTransferParams []ApplicationTransferParam `json:"applicationTransferParams,omitempty"` // The transfer parameters for the application
+	ApplicationTransferStatus string                     `json:"applicationTransferStatus,omitempty"` // Read-only. Current status of transfer for this application
+}
+
+type ApplicationTransferParam struct {
+	Key   string   `json:"key,omitempty"`   // The type of the transfer parameter, such as `PRIVACY_LEVEL`
+	Value []string `json:"value,omitempty"` // The value of the transfer parameter, such as `PRIVATE` or `SHARED`
+}
+
+// END OF DATA TRANSFER STRUCTS
+//-----------------------------------------------------------------------------
+
+// ### Gmail Structs
+// ----------------------------------------------------------------------------
+// https://developers.google.com/gmail/api/reference/rest/v1/users.settings/getVacation
+type VacationSettings struct {
+	EnableAutoReply       bool   `json:"enableAutoReply"`                 // Flag that controls whether Gmail automatically replies to messages
+	EndTime               string `json:"endTime,omitempty"`               // An optional end time for sending auto-replies (epoch ms)
+	ResponseBodyHTML      string `json:"responseBodyHtml,omitempty"`      // Response body in HTML format
+	ResponseBodyPlainText string `json:"responseBodyPlainText,omitempty"` // Response body in plain text format
+	ResponseSubject       string `json:"responseSubject,omitempty"`       // Optional text to prepend to the subject line in vacation responses
+	RestrictToContacts    bool   `json:"restrictToContacts,omitempty"`    // Flag that determines whether responses are sent to recipients who are not in the user's list of contacts
+	RestrictToDomain      bool   `json:"restrictToDomain,omitempty"`      // Flag that determines whether responses are sent to recipients who are outside of the user's domain
+	StartTime             string `json:"startTime,omitempty"`             // An optional start time for sending auto-replies (epoch ms)
+}
+
+// https://developers.google.com/gmail/api/reference/rest/v1/users.messages
+type GmailMessage struct {
+	ID       string   `json:"id,omitempty"`       // The immutable ID of the message
+	ThreadID string   `json:"threadId,omitempty"` // The ID of the thread the message belongs to
+	LabelIDs []string `json:"labelIds,omitempty"` // List of IDs of labels applied to this message
+	Raw      string   `json:"raw,omitempty"`      // The entire email message in RFC 2822 format, base64url encoded
+}
+
+// END OF GMAIL STRUCTS
+//-----------------------------------------------------------------------------
+
+// ### Group Structs
+// ----------------------------------------------------------------------------
+// https://developers.google.com/admin-sdk/directory/reference/rest/v1/members
+type Member struct {
+	DeliverySettings string `json:"delivery_settings,omitempty"` // Defines mail delivery preferences of member {ALL_MAIL, DAILY, DIGEST, DISABLED, NONE}
+	Email            string `json:"email,omitempty"`             // The member's email address
+	Etag             string `json:"etag,omitempty"`              // ETag of the resource
+	ID               string `json:"id,omitempty"`                // The unique ID of the group member
+	Kind             string `json:"kind,omitempty"`              // The type of the API resource. Value: `admin#directory#member`
+	Role             string `json:"role,omitempty"`              // The member's role in a group {OWNER, MANAGER, MEMBER}
+	Status           string `json:"status,omitempty"`            // Status of member
+	Type             string `json:"type,omitempty"`              // The type of group member {CUSTOMER, EXTERNAL, GROUP, USER}
+}
+
+// https://developers.google.com/admin-sdk/directory/reference/rest/v1/members/list#response-body
+type Members struct {
+	Etag          string    `json:"etag,omitempty"`          // ETag of the resource
+	Kind          string    `json:"kind,omitempty"`          // Kind of resource this is. Value: `admin#directory#members`
+	Members       []*Member `json:"members,omitempty"`       // A list of member objects
+	NextPageToken string    `json:"nextPageToken,omitempty"` // Token used to access next page of this result
+}
+
+// END OF GROUP STRUCTS
+//-----------------------------------------------------------------------------
+
+// ### Licensing Structs
+// ----------------------------------------------------------------------------
+// https://developers.google.com/admin-sdk/licensing/reference/rest/v1/licenseAssignments
+type LicenseAssignment struct {
+	Etags       string `json:"etags,omitempty"`       // ETag of the resource
+	Kind        string `json:"kind,omitempty"`        // Identifies the resource as a license assignment. Value: `licensing#licenseAssignment`
+	ProductID   string `json:"productId,omitempty"`   // A product's unique identifier, e.g. `Google-Apps`
+	ProductName string `json:"productName,omitempty"` // Display name of the product
+	SelfLink    string `json:"selfLink,omitempty"`    // Link to this page
+	SkuID       string `json:"skuId,omitempty"`       // A product SKU's unique identifier, e.g. `1010020027`
+	SkuName     string `json:"skuName,omitempty"`     // Display name of the product SKU, e.g. `Google Workspace Business Starter`
+	UserID      string `json:"userId,omitempty"`      // The user's current primary email address
+}
+
+// https://developers.google.com/admin-sdk/licensing/reference/rest/v1/licenseAssignments/listForProduct#response-body
+type LicenseAssignments struct {
+	Etag          string               `json:"etag,omitempty"`          // ETag of the resource
+	Items         []*LicenseAssignment `json:"items,omitempty"`         // The license assignments
+	Kind          string               `json:"kind,omitempty"`          // Value: `licensing#licenseAssignmentList`
+	NextPageToken string               `json:"nextPageToken,omitempty"` // Token used to access next page of this result
+}
+
+// END OF LICENSING STRUCTS
+//-----------------------------------------------------------------------------
+
+// ### IAM Structs
+// -----------------------------------------------------------------------------
+
+// https://cloud.google.com/iam/docs/reference/rest/v1/projects.serviceAccounts#ServiceAccount
+type IAMServiceAccount struct {
+	Description    string `json:"description,omitempty"`    // Description of the service account
+	Disabled       bool   `json:"disabled,omitempty"`       // Whether the service account is disabled
+	DisplayName    string `json:"displayName,omitempty"`    // Name of the service account
+	Email          string `json:"email,omitempty"`          // Email address of the service account
+	Etag           string `json:"etag,omitempty"`           // Deprecated
+	Name           string `json:"name,omitempty"`           // Resource name, `projects/{project}/serviceAccounts/{email}`
+	OAuth2ClientID string `json:"oauth2ClientId,omitempty"` // OAuth 2.0 client ID of the service account
+	ProjectID      string `json:"projectId,omitempty"`      // ID of the project that owns the service account
+	UniqueID       string `json:"uniqueId,omitempty"`       // Unique, stable numeric ID of the service account
+}
+
+// https://cloud.google.com/iam/docs/reference/rest/v1/projects.serviceAccounts/list#response-body
+type IAMServiceAccounts struct {
+	Accounts      []*IAMServiceAccount `json:"accounts,omitempty"`      // The service accounts of the project
+	NextPageToken string               `json:"nextPageToken,omitempty"` // Token used to access next page of this result
+}
+
+// https://cloud.google.com/iam/docs/reference/rest/v1/projects.serviceAccounts.keys#ServiceAccountKey
+type ServiceAccountKey struct {
+	Disabled        bool           `json:"disabled,omitempty"`        // Whether the key is disabled
+	KeyAlgorithm    string         `json:"keyAlgorithm,omitempty"`    // e.g. `KEY_ALG_RSA_2048`
+	KeyOrigin       string         `json:"keyOrigin,omitempty"`       // `USER_PROVIDED` or `GOOGLE_PROVIDED`
+	KeyType         string         `json:"keyType,omitempty"`         // `USER_MANAGED` or `SYSTEM_MANAGED`
+	Name            string         `json:"name,omitempty"`            // Resource name, `projects/{project}/serviceAccounts/{email}/keys/{key}`
+	ValidAfterTime  normalize.Time `json:"validAfterTime,omitempty"`  // Time the key was created (RFC 3339)
+	ValidBeforeTime normalize.Time `json:"validBeforeTime,omitempty"` // Time the key expires (RFC 3339); `9999-12-31T23:59:59Z` if it never does
+}
+
+// https://cloud.google.com/iam/docs/reference/rest/v1/projects.serviceAccounts.keys/list#response-body
+type ServiceAccountKeys struct {
+	Keys []*ServiceAccountKey `json:"keys,omitempty"` // The keys of the service account
+}
+
+// END OF IAM STRUCTS
+//-----------------------------------------------------------------------------
+
+// ### Calendar Structs
+// ----------------------------------------------------------------------------
+// https://developers.google.com/calendar/api/v3/reference/events
+type CalendarEvent struct {
+	Attendees   []*EventAttendee `json:"attendees,omitempty"`   // The attendees of the event
+	Description string           `json:"description,omitempty"` // Description of the event
+	HTMLLink    string           `json:"htmlLink,omitempty"`    // An absolute link to this event in the Google Calendar Web UI
+	ID          string           `json:"id,omitempty"`          // Opaque identifier of the event
+	Recurrence  []string         `json:"recurrence,omitempty"`  // List of RRULE, EXRULE, RDATE and EXDATE lines for a recurring event
+	Status      string           `json:"status,omitempty"`      // Status of the event {confirmed, tentative, cancelled}
+	Summary     string           `json:"summary,omitempty"`     // Title of the event
+}
+
+type EventAttendee struct {
+	DisplayName    string `json:"displayName,omitempty"`    // The attendee's name, if available
+	Email          string `json:"email,omitempty"`          // The attendee's email address
+	Optional       bool   `json:"optional,omitempty"`       // Whether this is an optional attendee
+	Organizer      bool   `json:"organizer,omitempty"`      // Whether the attendee is the organizer of the event
+	ResponseStatus string `json:"responseStatus,omitempty"` // The attendee's response status {needsAction, declined, tentative, accepted}
+}
+
+// END OF CALENDAR STRUCTS
+//-----------------------------------------------------------------------------
+
 // ### Device Structs
 // ----------------------------------------------------------------------------
 // https://developers.google.com/admin-sdk/directory/v1/guides/manage-chrome-devices
@@ -1378,12 +1617,12 @@ type ChromeOSDevice struct {
 	EthernetMacAddress       string                `json:"ethernetMacAddress,omitempty"`       // Ethernet MAC address.
 	EthernetMacAddress0      string                `json:"ethernetMacAddress0,omitempty"`      // Secondary Ethernet MAC address.
 	FirmwareVersion          string                `json:"firmwareVersion,omitempty"`          // Firmware version.
-	FirstEnrollmentTime      string                `json:"firstEnrollmentTime,omitempty"`      // First enrollment time.
+	FirstEnrollmentTime      normalize.Time        `json:"firstEnrollmentTime,omitempty"`      // First enrollment time.
 	Kind                     string                `json:"kind,omitempty"`                     // Kind of the resource.
 	LastDeprovisionTimestamp string                `json:"lastDeprovisionTimestamp,omitempty"` // Last deprovision timestamp.
-	LastEnrollmentTime       string                `json:"lastEnrollmentTime,omitempty"`       // Last enrollment time.
+	LastEnrollmentTime       normalize.Time        `json:"lastEnrollmentTime,omitempty"`       // Last enrollment time.
 	LastKnownNetwork         []LastKnownNetwork    `json:"lastKnownNetwork,omitempty"`         // Last known network.
-	LastSync                 string                `json:"lastSync,omitempty"`                 // Last synchronization time.
+	LastSync                 normalize.Time        `json:"lastSync,omitempty"`                 // Last synchronization time.
 	MacAddress               string                `json:"macAddress,omitempty"`               // MAC address.
 	ManufactureDate          string                `json:"manufactureDate,omitempty"`          // Manufacture date of the device.
 	Meid                     string                `json:"meid,omitempty"`                     // MEID or IMEI of the mobile card.
@@ -1816,3 +2055,75 @@ const (
 	ADMIN_VIEW    UserViewType = "admin_view"    // Results include both administrator-only and domain-public fields for the user.
 	DOMAIN_PUBLIC UserViewType = "domain_public" // Results only include fields for the user that are publicly visible to other users
 )
+
+// https://developers.google.com/admin-sdk/directory/reference/rest/v1/orgunits#OrgUnit.FIELDS.org_unit_path
+type OrgUnitPath string
+
+const ROOT_OU OrgUnitPath = "/" // The root organizational unit, which every other one is under
+
+// Contains reports whether `ou` is this organizational unit, or one under it
+func (p OrgUnitPath) Contains(ou OrgUnitPath) bool {
+	return p == ou || p == ROOT_OU || strings.HasPrefix(string(ou), strings.TrimSuffix(string(p), "/")+"/")
+}
+
+// Paths are absolute, so a path missing its leading `/` (e.g. a device query's) fails instead of matching nothing
+func (p OrgUnitPath) MarshalText() ([]byte, error) {
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+	return []byte(p), nil
+}
+
+func (p *OrgUnitPath) UnmarshalText(text []byte) error {
+	if err := OrgUnitPath(text).check(); err != nil {
+		return err
+	}
+	*p = OrgUnitPath(text)
+	return nil
+}
+
+func (p OrgUnitPath) check() error {
+	if p != "" && !strings.HasPrefix(string(p), "/") {
+		return fmt.Errorf("%w: google.OrgUnitPath %q is not absolute", enum.ErrInvalid, string(p))
+	}
+	return nil
+}
+
+// https://support.google.com/a/answer/2405986
+type AdminRole string
+
+const (
+	SUPER_ADMIN     AdminRole = "SUPER_ADMIN"     // Manages every setting of the account, the `isAdmin` flag of a user
+	DELEGATED_ADMIN AdminRole = "DELEGATED_ADMIN" // Manages the settings of their roles, the `isDelegatedAdmin` flag of a user
+)
+
+var adminRoles = []AdminRole{SUPER_ADMIN, DELEGATED_ADMIN}
+
+func (r AdminRole) MarshalText() ([]byte, error)     { return enum.Marshal(r, adminRoles...) }
+func (r *AdminRole) UnmarshalText(text []byte) error { return enum.Unmarshal(r, text, adminRoles...) }
+
+// https://developers.google.com/admin-sdk/directory/reference/rest
+type APIVersion string
+
+const (
+	DIRECTORY_V1   APIVersion = "directory_v1"        // Directory API, at /admin/directory/v1
+	DIRECTORY_BETA APIVersion = "directory_v1.1beta1" // Beta of the Directory API, at /admin/directory/v1.1beta1
+)
+
+// APIVersions is every version of the Directory API a client can be pinned to, with `options.WithAPIVersion`
+var APIVersions = []APIVersion{DIRECTORY_V1, DIRECTORY_BETA}
+
+func (v APIVersion) MarshalText() ([]byte, error)     { return enum.Marshal(v, APIVersions...) }
+func (v *APIVersion) UnmarshalText(text []byte) error { return enum.Unmarshal(v, text, APIVersions...) }
+
+// Path returns the path of the Directory API of the version, e.g. /admin/directory/v1
+func (v APIVersion) Path() string {
+	return "/admin/directory/" + strings.TrimPrefix(string(v), "directory_")
+}
+
+// Capability is an API of Google Workspace which only some versions serve
+type Capability string
+
+const (
+	CHROME_BROWSERS Capability = "chromebrowsers" // https://support.google.com/chrome/a/answer/9681204
+)
@@ -0,0 +1,94 @@
+/*
+# Google Workspace - Gmail
+
+This package implements logic related to the Gmail API:
+https://developers.google.com/gmail/api/reference/rest
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/google/gmail.go
+package google
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+var (
+	GmailBaseURL  = "https://gmail.googleapis.com/gmail/v1"                          // https://developers.google.com/gmail/api/reference/rest
+	GmailVacation = fmt.Sprintf("%s/users/%s/settings/vacation", GmailBaseURL, "%s") // https://developers.google.com/gmail/api/reference/rest/v1/users.settings/updateVacation
+	GmailSend     = fmt.Sprintf("%s/users/%s/messages/send", GmailBaseURL, "%s")     // https://developers.google.com/gmail/api/reference/rest/v1/users.messages/send
+)
+
+// GmailClient for chaining methods
+type GmailClient struct {
+	*Client
+}
+
+// Entry point for Gmail operations
+func (c *Client) Gmail() *GmailClient {
+	return &GmailClient{
+		Client: c,
+	}
+}
+
+/*
+ * Get a user's vacation responder (out-of-office) settings
+ * - The client must be impersonating the user (see `AsUser`)
+ * /gmail/v1/users/{userId}/settings/vacation
+ * https://developers.google.com/gmail/api/reference/rest/v1/users.settings/getVacation
+ */
+func (c *GmailClient) GetVacation(userID string) (*VacationSettings, error) {
+	url := fmt.Sprintf(GmailVacation, requests.EscapePathSegment(userID))
+	c.Log.Debug("url:", url)
+
+	v, err := do[VacationSettings](c.Client, "GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+/*
+ * Update a user's vacation responder (out-of-office) settings
+ * - The client must be impersonating the user (see `AsUser`)
+ * /gmail/v1/users/{userId}/settings/vacation
+ * https://developers.google.com/gmail/api/reference/rest/v1/users.settings/updateVacation
+ */
+func (c *GmailClient) UpdateVacation(userID string, v *VacationSettings) (*VacationSettings, error) {
+	url := fmt.Sprintf(GmailVacation, requests.EscapePathSegment(userID))
+	c.Log.Debug("url:", url)
+
+	result, err := do[VacationSettings](c.Client, "PUT", url, nil, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+/*
+ * Send an email as a user
+ * - `raw` is the whole message in RFC 2822 format, headers included
+ * - The client must be impersonating the user (see `AsUser`), with the `gmail.send` scope
+ * /gmail/v1/users/{userId}/messages/send
+ * https://developers.google.com/gmail/api/reference/rest/v1/users.messages/send
+ */
+func (c *GmailClient) SendMessage(userID string, raw []byte) (*GmailMessage, error) {
+	url := fmt.Sprintf(GmailSend, requests.EscapePathSegment(userID))
+	c.Log.Debug("url:", url)
+
+	message := GmailMessage{Raw: base64.URLEncoding.EncodeToString(raw)}
+	result, err := do[GmailMessage](c.Client, "POST", url, nil, message)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -16,17 +16,27 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/gemini-oss/rego/pkg/common/auth"
 	"github.com/gemini-oss/rego/pkg/common/cache"
 	"github.com/gemini-oss/rego/pkg/common/config"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
 	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/options"
 	"github.com/gemini-oss/rego/pkg/common/ratelimit"
 	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+	"github.com/gemini-oss/rego/pkg/common/version"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/jwt"
 )
 
 const (
@@ -41,12 +51,47 @@ const (
 	JWTTokenURL     = "https://oauth2.googleapis.com/token"
 )
 
+// Backoff of the requests Google throttled, by the reason of the error; variables so tests can shorten them
+// - https://developers.google.com/drive/api/guides/limits#exponential
+var (
+	MinRateLimitBackoff = 1 * time.Second  // `rateLimitExceeded` and `userRateLimitExceeded`
+	MaxRateLimitBackoff = 32 * time.Second // `rateLimitExceeded` and `userRateLimitExceeded`
+	MinQuotaBackoff     = 5 * time.Second  // `quotaExceeded`, e.g. too many concurrent Drive requests
+	MaxQuotaBackoff     = 64 * time.Second // `quotaExceeded`, e.g. too many concurrent Drive requests
+)
+
+/*
+ * DefaultTransport is the setting of the connections of clients without an HTTP client or transport of their own, so
+ * the concurrent requests of a paginated sync reuse their connections to `admin.googleapis.com` rather than redialing
+ */
+var DefaultTransport = requests.Transport{MaxIdleConnsPerHost: 32, IdleConnTimeout: 90 * time.Second}
+
+var (
+	ErrMissingCredential         = errors.New("google: missing credential")                       // The credential of the auth type is not set, or its file cannot be read
+	ErrInvalidServiceAccountJSON = errors.New("google: invalid service account JSON")             // The service account key is not valid base64 or JSON
+	ErrInvalidOAuthClientJSON    = errors.New("google: invalid OAuth client JSON")                // The OAuth client secret is not valid base64 or JSON
+	ErrInvalidOAuthToken         = errors.New("google: invalid OAuth token JSON")                 // The OAuth token of the user is not valid base64 or JSON
+	ErrUnsupportedAuthType       = errors.New("google: unsupported auth type")                    // `AuthCredentials.Type` is none of API_KEY, OAUTH_CLIENT and SERVICE_ACCOUNT
+	ErrTokenGeneration           = errors.New("google: unable to generate an access token")       // The credential was rejected, e.g. a revoked key
+	ErrImpersonationUnsupported  = errors.New("google: impersonation requires a service account") // `AsUser` was called on a client without a JWT config
+)
+
+// DefaultAPIVersion is the version of the Directory API of clients which are not pinned to one
+const DefaultAPIVersion = DIRECTORY_V1
+
+// capabilities are the APIs which only some versions of the Directory API serve
+var capabilities = version.Capabilities[Capability, APIVersion]{
+	CHROME_BROWSERS: {DIRECTORY_BETA},
+}
+
 /*
  * Build a URL for the Google Workspace API
  * @param endpoint string
  * @param customer *Customer
  * @param parameters ...string
  * @return string
+ * - Each parameter is escaped as a single path segment, so emails and group names are safe to pass
+ * - A parameter starting with `:` is a custom method (e.g. `:resolve`), appended to the last segment as is
  */
 func (c *Client) BuildURL(endpoint string, customer *Customer, parameters ...string) string {
 	var url string
@@ -54,7 +99,7 @@ func (c *Client) BuildURL(endpoint string, customer *Customer, parameters ...str
 		if customer == nil {
 			customer = &Customer{}
 		}
-		url = fmt.Sprintf(endpoint, customer.String())
+		url = fmt.Sprintf(endpoint, requests.EscapePathSegment(customer.String()))
 	} else {
 		url = endpoint
 	}
@@ -64,7 +109,7 @@ func (c *Client) BuildURL(endpoint string, customer *Customer, parameters ...str
 			if strings.HasPrefix(param, ":") {
 				url = strings.TrimSuffix(url, "/") + param
 			} else {
-				url = fmt.Sprintf("%s/%s", url, param)
+				url = requests.JoinURL(url, param)
 			}
 		}
 	}
@@ -73,6 +118,60 @@ func (c *Client) BuildURL(endpoint string, customer *Customer, parameters ...str
 	return url
 }
 
+// APIVersion returns the version of the Directory API the client is pinned to
+func (c *Client) APIVersion() APIVersion {
+	if c.Version == "" {
+		return DefaultAPIVersion
+	}
+	return c.Version
+}
+
+// Supports returns whether the version the client is pinned to serves `capability`
+func (c *Client) Supports(capability Capability) bool {
+	return capabilities.Supports(c.APIVersion(), capability)
+}
+
+// Require returns an error wrapping `version.ErrUnsupported` unless the version the client is pinned to serves `capability`
+func (c *Client) Require(capability Capability) error {
+	return capabilities.Require(c.APIVersion(), capability)
+}
+
+/*
+ * # With Context
+ * Returns a copy of the client whose requests are bound to `ctx`
+ * - Cancelling `ctx` stops paginated lists between pages and aborts the request in flight
+ */
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cc := *c
+	cc.HTTP = c.HTTP.WithContext(ctx)
+	return &cc
+}
+
+// Close closes the HTTP client (see `requests.Client.Close`), then flushes the cache (see `cache.Cache.Flush`)
+func (c *Client) Close(ctx context.Context) error {
+	return errors.Join(c.HTTP.Close(ctx), c.Cache.Flush())
+}
+
+// Name returns the name of the service, `google`
+func (c *Client) Name() string {
+	return "google"
+}
+
+// Endpoint returns the base URL of the API the client sends its requests to
+func (c *Client) Endpoint() string {
+	return c.BaseURL
+}
+
+/*
+ * # Check the Health of the Google Client
+ * Gets the Workspace account of the client, so the API is reachable and its credentials authorized
+ * /admin/directory/v1/customers/my_customer
+ */
+func (c *Client) HealthCheck(ctx context.Context) error {
+	_, err := do[Customer](c.WithContext(ctx), "GET", c.BuildURL(DirectoryCustomers, &Customer{ID: "my_customer"}), nil, nil)
+	return err
+}
+
 /*
  * SetCache stores a Google API response in the cache
  */
@@ -82,14 +181,14 @@ func (c *Client) SetCache(key string, value interface{}, duration time.Duration)
 		c.Log.Error("Error marshalling cache data:", err)
 		return
 	}
-	c.Cache.Set(key, data, duration)
+	c.Cache.Set(c.cacheKey(key), data, duration)
 }
 
 /*
  * GetCache retrieves a Google API response from the cache
  */
 func (c *Client) GetCache(key string, target interface{}) bool {
-	data, found := c.Cache.Get(key)
+	data, found := c.Cache.Get(c.cacheKey(key))
 	if !found {
 		return false
 	}
@@ -102,6 +201,14 @@ func (c *Client) GetCache(key string, target interface{}) bool {
 	return true
 }
 
+// cacheKey scopes `key` to the impersonated user, so clients from `AsUser` do not share responses with other subjects
+func (c *Client) cacheKey(key string) string {
+	if c.JWT != nil && c.JWT.Subject != c.Auth.Subject {
+		return c.JWT.Subject + ":" + key
+	}
+	return key
+}
+
 /*
  * # Generate JWT Client/Tokens for Google Workspace
  * @param auth AuthCredentials
@@ -111,68 +218,90 @@ func (c *Client) GetCache(key string, target interface{}) bool {
  * https://developers.google.com/identity/protocols/oauth2/service-account#jwt-auth
  */
 func (c *Client) GenerateJWT(data []byte) (*requests.Client, error) {
-	ctx := context.Background()
+	ctx := c.authContext()
 
 	c.Log.Println("Generating JWT Config")
 	jwtConfig, err := google.JWTConfigFromJSON(data, c.Auth.Scopes...)
-	jwtConfig.Subject = c.Auth.Subject
 	if err != nil {
-		c.Log.Printf("Unable to parse client secret file to config: %v", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidServiceAccountJSON, err)
 	}
+	jwtConfig.Subject = c.Auth.Subject
+	c.JWT = jwtConfig
 	c.Log.Printf("JWT Config Successfully Generated")
 
 	c.Log.Println("Generating JWT Token")
 	t, err := jwtConfig.TokenSource(ctx).Token()
 	if err != nil {
-		c.Log.Printf("Unable to generate token: %v", err)
+		return nil, fmt.Errorf("%w: %w", ErrTokenGeneration, err)
 	}
 	c.Log.Printf("Token Successfully Generated")
 
 	c.Log.Println("Reconfiguring HTTP Client")
-	type contextKey string
-	jwtClient := jwtConfig.Client(context.WithValue(ctx, contextKey("token"), t))
 	headers := requests.Headers{
 		"Accept":        requests.JSON,
 		"Content-Type":  requests.JSON,
 		"Authorization": "Bearer " + t.AccessToken,
 	}
 
-	return requests.NewClient(jwtClient, headers, c.HTTP.RateLimiter), nil
+	return c.tokenHTTP(t, jwtTokens(ctx, jwtConfig), headers, jwtSubject(jwtConfig)), nil
 }
 
-func (c *Client) ImpersonateUser(email string) error {
-	// Update the JWT config to impersonate a new user
-	c.JWT.Subject = email
+/*
+ * # As User
+ * Returns a copy of the client which impersonates `email` with domain-wide delegation, leaving the client unchanged
+ * - The copy keeps the context, base URL and dry-run mode of the client
+ * - Unlike `ImpersonateUser`, it is safe to call while the client is in use by other goroutines
+ */
+func (c *Client) AsUser(email string) (*Client, error) {
+	if c.JWT == nil {
+		return nil, fmt.Errorf("impersonating %s: %w", email, ErrImpersonationUnsupported)
+	}
 
-	// Create a new token for the new user
-	ctx := context.Background()
-	t, err := c.JWT.TokenSource(ctx).Token()
+	// Impersonate the user with a copy of the JWT config
+	jwtConfig := *c.JWT
+	jwtConfig.Subject = email
+
+	// Create a new token for the user
+	ctx := c.authContext()
+	t, err := jwtConfig.TokenSource(ctx).Token()
 	if err != nil {
-		return fmt.Errorf("unable to generate token: %v", err)
+		return nil, fmt.Errorf("%w: %w", ErrTokenGeneration, err)
 	}
 
-	// Create a new HTTP client with the new token
-	type contextKey string
-	jwtClient := c.JWT.Client(context.WithValue(ctx, contextKey("token"), t))
-
-	// Update the headers to use the new token
 	headers := requests.Headers{
 		"Accept":        requests.JSON,
 		"Content-Type":  requests.JSON,
 		"Authorization": "Bearer " + t.AccessToken,
 	}
 
-	// Update the HTTP client of the client object
-	c.HTTP = requests.NewClient(jwtClient, headers, nil)
-	c.HTTP.BodyType = requests.JSON
+	scoped := *c
+	scoped.JWT = &jwtConfig
+	scoped.HTTP = c.tokenHTTP(t, jwtTokens(ctx, &jwtConfig), headers, jwtSubject(&jwtConfig)).WithContext(c.HTTP.Context())
+	scoped.HTTP.BodyType = requests.JSON
+	scoped.HTTP.DryRun, scoped.HTTP.Plan = c.HTTP.DryRun, c.HTTP.Plan
+	return &scoped, nil
+}
 
+/*
+ * # Impersonate User
+ * Switches the client to impersonate `email` with domain-wide delegation
+ * - It swaps the HTTP client in place, so it must not be called while the client is in use by other goroutines; use
+ *   `AsUser` for a scoped copy instead
+ */
+func (c *Client) ImpersonateUser(email string) error {
+	scoped, err := c.AsUser(email)
+	if err != nil {
+		return err
+	}
+	c.JWT, c.HTTP = scoped.JWT, scoped.HTTP
 	return nil
 }
 
 /*
   - # Generate Google Workspace Client
   - @param auth AuthCredentials
-  - @param log *log.Logger
+  - @param verbosity int
+  - @param opts ...options.Option
   - @return *Client
   - @return error
   - Example:
@@ -246,39 +375,78 @@ func (c *Client) ImpersonateUser(email string) error {
 	chrome.Customer, _ = g.MyCustomer()
 
 ```
-*/
-func NewClient(ac AuthCredentials, verbosity int) (*Client, error) {
-	log := log.NewLogger("{google}", verbosity)
 
-	// Look into `Functional Options` patterns for a better way to handle this (and other clients while we're at it)
-	encryptionKey := []byte(config.GetEnv("REGO_ENCRYPTION_KEY"))
-	if len(encryptionKey) == 0 {
-		log.Fatal("REGO_ENCRYPTION_KEY is not set")
+  - Example 5: OAuth client, acting as the user of a token (with a refresh token) from a consent flow
+
+```go
+
+	ac := google.AuthCredentials{
+		Type:        google.OAUTH_CLIENT,
+		Credentials: "client_secret.json",
+		Token:       "token.json",
+		Scopes: []string{
+			"Google Drive API",
+		},
+	}
+	g, err := google.NewClient(ac, log.DEBUG)
+	if errors.Is(err, google.ErrMissingCredential) { ... }
+
+```
+
+  - Example 6: Every API request sent through a proxy, with a shared cache
+
+```go
+
+	g, _ := google.NewClient(ac, log.DEBUG,
+		options.WithHTTPClient(proxied),
+		options.WithBaseURL("https://google-proxy.example.com"),
+		options.WithCache(c),
+	)
+
+```
+*/
+func NewClient(ac AuthCredentials, verbosity int, opts ...options.Option) (*Client, error) {
+	o := options.New(opts...)
+	log := o.Log("{google}", verbosity)
+	if o.HTTPClient == nil && o.Transport == nil {
+		o.HTTPClient = requests.TransportClient(nil, DefaultTransport)
 	}
 
-	cache, err := cache.NewCache(encryptionKey, "rego_cache_google.gob", 1000000)
+	v, err := version.Pin(o.APIVersion, DefaultAPIVersion, APIVersions...)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	// https://developers.google.com/drive/api/guides/limits
-	rl := ratelimit.NewRateLimiter(12000, 75*time.Second)
-	rl.Log.Verbosity = verbosity
+	cache := o.Cache
+	if cache == nil {
+		cache = newCache(log, o.CacheBackend)
+	}
+
+	rl := o.RateLimiter
+	if rl == nil {
+		// https://developers.google.com/drive/api/guides/limits
+		rl = ratelimit.NewRateLimiter(12000, 75*time.Second, ratelimit.Google)
+		rl.Log.Verbosity = verbosity
+	}
+	buckets := o.RateLimits
+	if buckets == nil {
+		buckets = rateLimits(rl, verbosity)
+	}
 
 	c := &Client{
 		Auth:    ac,
 		BaseURL: BaseURL,
 		Log:     log,
 		Cache:   cache,
-		HTTP:    requests.NewClient(nil, nil, rl),
+		HTTP:    requests.NewClient(nil, nil, rl, requests.WithCache(o.Cache), requests.WithRetryPolicy(retryPolicy), requests.WithRetry(o.Retry), requests.WithCircuitBreaker(o.Breaker), requests.WithRateLimitBuckets(buckets), requests.WithSingleflight(), requests.WithUserAgent("google", o.UserAgent)),
+		Version: v,
+		opts:    o,
+	}
+	if o.BaseURL != "" {
+		c.BaseURL = o.BaseURL
 	}
 
 	log.Println("Initializing Google Client")
-	headers := requests.Headers{
-		"Accept":       requests.JSON,
-		"Content-Type": requests.JSON,
-	}
-	// httpClient := requests.NewClient(nil, headers)
 
 	log.Println("Loading Scopes")
 	scopes := []string{}
@@ -302,87 +470,277 @@ func NewClient(ac AuthCredentials, verbosity int) (*Client, error) {
 	switch c.Auth.CICD {
 	case true:
 		log.Println("Detected CICD Environment: Reading Credentials from Environment Variables")
-		switch c.Auth.Type {
-		case API_KEY:
-			headers["Authorization"] = "Bearer " + config.GetEnv("GOOGLE_API_KEY")
-			if len(headers["Authorization"]) <= 7 {
-				return nil, fmt.Errorf("GOOGLE_API_KEY is not set")
-			}
-		case OAUTH_CLIENT:
-			b64 := config.GetEnv("GOOGLE_OAUTH_CLIENT")
-			if len(b64) == 0 {
-				return nil, fmt.Errorf("GOOGLE_OAUTH_CLIENT is not set")
-			}
+	case false:
+		log.Println("Detected Local Environment: Reading Credentials from Arguments")
+	}
 
-			decoded, err := base64.StdEncoding.DecodeString(b64)
-			if err != nil {
-				fmt.Println("decode error:", err)
-				return nil, err
-			}
-			j := &GoogleConfig{}
-			err = json.Unmarshal([]byte(decoded), &j)
-			if err != nil {
-				fmt.Println("unmarshal error:", err)
-				return nil, err
-			}
-		case SERVICE_ACCOUNT:
-			b64 := config.GetEnv("GOOGLE_SERVICE_ACCOUNT")
-			if len(b64) == 0 {
-				return nil, fmt.Errorf("GOOGLE_SERVICE_ACCOUNT is not set")
-			}
+	switch c.Auth.Type {
+	case API_KEY:
+		c.HTTP, err = c.apiKeyClient()
+	case OAUTH_CLIENT:
+		c.HTTP, err = c.oauthClient()
+	case SERVICE_ACCOUNT:
+		log.Println("Service Account Credentials Detected")
+		var key []byte
+		key, err = c.credentialJSON("GOOGLE_SERVICE_ACCOUNT", c.Auth.Credentials, ErrInvalidServiceAccountJSON)
+		if err != nil {
+			return nil, err
+		}
 
-			decoded, err := base64.StdEncoding.DecodeString(b64)
-			if err != nil {
-				fmt.Println("decode error:", err)
-				return nil, err
-			}
+		log.Println("Generating JWT Client")
+		c.HTTP, err = c.GenerateJWT(key)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAuthType, c.Auth.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.HTTP.BodyType = requests.JSON
 
-			c.HTTP, err = c.GenerateJWT(decoded)
-			if err != nil {
-				return nil, err
-			}
-			c.HTTP.BodyType = requests.JSON
+	return c, nil
+}
+
+// newCache opens the encrypted cache of the client, with `REGO_ENCRYPTION_KEY`, storing its items in `backend` when set
+func newCache(log *log.Logger, backend cache.Backend) *cache.Cache {
+	encryptionKey := []byte(config.GetEnv("REGO_ENCRYPTION_KEY"))
+	if len(encryptionKey) == 0 {
+		log.Fatal("REGO_ENCRYPTION_KEY is not set")
+	}
+
+	c, err := cache.NewCache(encryptionKey, "rego_cache_google.gob", 1000000, backend)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+/*
+ * Generate an HTTP client authorized with an API key (`GOOGLE_API_KEY`)
+ */
+func (c *Client) apiKeyClient() (*requests.Client, error) {
+	key := c.Auth.Credentials
+	if c.Auth.CICD {
+		key = config.GetEnv("GOOGLE_API_KEY")
+	}
+	if key == "" {
+		return nil, fmt.Errorf("%w: GOOGLE_API_KEY is not set", ErrMissingCredential)
+	}
+
+	headers := requests.Headers{
+		"Accept":        requests.JSON,
+		"Content-Type":  requests.JSON,
+		"Authorization": "Bearer " + key,
+	}
+	return c.newHTTP(nil, headers), nil
+}
+
+/*
+ * Generate an HTTP client authorized as a user, with an OAuth client (`GOOGLE_OAUTH_CLIENT`) and the user's token (`GOOGLE_OAUTH_TOKEN`)
+ * - The token must include a refresh token, which is used to renew it when it expires
+ */
+func (c *Client) oauthClient() (*requests.Client, error) {
+	secret, err := c.credentialJSON("GOOGLE_OAUTH_CLIENT", c.Auth.Credentials, ErrInvalidOAuthClientJSON)
+	if err != nil {
+		return nil, err
+	}
+	oauth, err := google.ConfigFromJSON(secret, c.Auth.Scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOAuthClientJSON, err)
+	}
+	oauthConfig := auth.OAuthConfig(oauth)
+	c.OAuth = &oauthConfig
+
+	data, err := c.credentialJSON("GOOGLE_OAUTH_TOKEN", c.Auth.Token, ErrInvalidOAuthToken)
+	if err != nil {
+		return nil, err
+	}
+	token := &oauth2.Token{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOAuthToken, err)
+	}
+	if token.AccessToken == "" && token.RefreshToken == "" {
+		return nil, fmt.Errorf("%w: the token has neither an access nor a refresh token", ErrInvalidOAuthToken)
+	}
 
-			return c, nil
+	headers := requests.Headers{
+		"Accept":       requests.JSON,
+		"Content-Type": requests.JSON,
+	}
+	// Each refresh may rotate the refresh token
+	ctx := c.authContext()
+	refresh := token.RefreshToken
+	return c.tokenHTTP(token, func() (*oauth2.Token, error) {
+		t, err := oauth.TokenSource(ctx, &oauth2.Token{RefreshToken: refresh}).Token()
+		if err == nil && t.RefreshToken != "" {
+			refresh = t.RefreshToken
 		}
-	case false:
-		log.Println("Detected Local Environment: Reading Credentials from Arguments")
-		switch c.Auth.Type {
-		case API_KEY:
-			headers["Authorization"] = "Bearer " + c.Auth.Credentials
-			if len(headers["Authorization"]) <= 7 {
-				return nil, fmt.Errorf("GOOGLE_API_KEY is not set")
-			}
-		case OAUTH_CLIENT:
-			file, err := os.ReadFile(c.Auth.Credentials)
-			if err != nil {
-				log.Printf("Error opening file: %s\n", err)
-			}
-			oauth, err := google.ConfigFromJSON(file, c.Auth.Scopes...)
-			if err != nil {
-				log.Printf("Unable to parse client secret file to config: %v", err)
-			}
-			_ = oauth // Will return to this later
-		case SERVICE_ACCOUNT:
-			log.Println("Service Account Credentials Detected")
-
-			log.Println("Loading Service Account Credentials from file")
-			file, err := os.ReadFile(c.Auth.Credentials)
-			if err != nil {
-				log.Printf("Error opening file: %s\n", err)
-			}
+		return t, err
+	}, headers, ""), nil
+}
 
-			log.Println("Generating JWT Client")
-			c.HTTP, err = c.GenerateJWT(file)
-			if err != nil {
-				return nil, err
-			}
+/*
+ * Read a JSON credential: the base64-encoded environment variable `env` in CI/CD, otherwise the file at `path`
+ * - Credentials which are not set, or whose file cannot be read, are reported as `ErrMissingCredential`
+ * - Values which do not decode are reported as `invalid`
+ */
+func (c *Client) credentialJSON(env string, path string, invalid error) ([]byte, error) {
+	if c.Auth.CICD {
+		b64 := config.GetEnv(env)
+		if len(b64) == 0 {
+			return nil, fmt.Errorf("%w: %s is not set", ErrMissingCredential, env)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: decoding %s: %w", invalid, env, err)
+		}
+		return decoded, nil
+	}
+
+	if path == "" {
+		return nil, fmt.Errorf("%w: no file for %s", ErrMissingCredential, env)
+	}
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMissingCredential, err)
+	}
+	return file, nil
+}
+
+/*
+ * Returns the rate limiters of the APIs whose quotas differ from the default, each set once here so the resource
+ * clients (`Admin`, `Drive`, `Sheets`, ...) never change a limiter shared with the others
+ * - https://developers.google.com/admin-sdk/directory/v1/limits
+ * - https://developers.google.com/drive/api/guides/limits
+ * - https://developers.google.com/sheets/api/limits
+ */
+func rateLimits(fallback *ratelimit.RateLimiter, verbosity int) *ratelimit.Buckets {
+	limiter := func(limit int) *ratelimit.RateLimiter {
+		rl := ratelimit.NewRateLimiter(limit, time.Minute, ratelimit.Google)
+		rl.Log.Verbosity = verbosity
+		return rl
+	}
+	return ratelimit.NewBuckets(fallback).
+		Add("/admin/directory", limiter(2400)).
+		Add("/drive", limiter(12000)).
+		Add("/v4/spreadsheets", limiter(60))
+}
+
+/*
+ * Returns the context of the OAuth 2.0 token requests, which are sent with the HTTP client of the options when one is set
+ */
+func (c *Client) authContext() context.Context {
+	ctx := context.Background()
+	if c.opts != nil && c.opts.HTTPClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, c.opts.HTTPClient)
+	}
+	return ctx
+}
 
-			return c, nil
+/*
+ * Returns a requests client sending with `hc`, under the rate limit of the client
+ * - A nil `hc` sends with the HTTP client of the options, if any
+ * - GET responses are revalidated with their `ETag`, so unchanged Directory resources and policies cost a `304`
+ * - When the base URL of the client is not the default, every API request is sent to it instead, keeping its path
+ */
+func (c *Client) newHTTP(hc *http.Client, headers requests.Headers) *requests.Client {
+	opts := c.opts
+	if opts == nil {
+		opts = options.New()
+	}
+	if hc == nil {
+		hc = opts.HTTPClient
+	}
+
+	if c.BaseURL != "" && c.BaseURL != BaseURL {
+		if hc == nil {
+			hc = http.DefaultClient
+		}
+		target, err := url.Parse(c.BaseURL)
+		if err == nil {
+			rewritten := *hc
+			rewritten.Transport = baseURLTransport{target: target, next: hc.Transport}
+			hc = &rewritten
+		} else {
+			c.Log.Warningf("Ignoring invalid base URL %q: %v", c.BaseURL, err)
+		}
+	}
+	if v := c.APIVersion(); v != DefaultAPIVersion {
+		if hc == nil {
+			hc = http.DefaultClient
 		}
+		pinned := *hc
+		pinned.Transport = versionTransport{from: DefaultAPIVersion.Path() + "/", to: v.Path() + "/", next: hc.Transport}
+		hc = &pinned
+	}
+	return requests.NewClient(hc, headers, c.HTTP.RateLimiter, requests.WithRateLimitBuckets(c.HTTP.RateLimits), requests.WithCache(opts.Cache), requests.WithRetryPolicy(retryPolicy), requests.WithRetry(opts.Retry), requests.WithCircuitBreaker(opts.Breaker), requests.WithConditionalRequests(), requests.WithSingleflight(), requests.WithUserAgent("google", opts.UserAgent))
+}
+
+/*
+ * Returns a requests client authorized with `token`, which is reused until shortly before it expires and is then renewed with `mint`
+ * - The token is also renewed when the API rejects it, e.g. after it was revoked or the key of the service account rotated
+ * - Responses are shared between clients of the same `subject` (see `requests.WithSubject`); none are when it is empty
+ */
+func (c *Client) tokenHTTP(token *oauth2.Token, mint func() (*oauth2.Token, error), headers requests.Headers, subject string) *requests.Client {
+	source := auth.NewRenewableTokenSource(token, mint)
+	hc := c.newHTTP(nil, headers)
+	hc.Subject = subject
+	hc.UseTokenSource(source)
+	return hc
+}
+
+// jwtSubject names the service account of `config`, and the user it impersonates, if any
+func jwtSubject(config *jwt.Config) string {
+	return "google:" + config.Email + ":" + config.Subject
+}
+
+// jwtTokens mints tokens of a copy of `config`, so impersonating another user later does not change them
+func jwtTokens(ctx context.Context, config *jwt.Config) func() (*oauth2.Token, error) {
+	cfg := *config
+	return func() (*oauth2.Token, error) {
+		return cfg.TokenSource(ctx).Token()
+	}
+}
+
+// baseURLTransport sends requests to the scheme, host and path prefix of `target`
+type baseURLTransport struct {
+	target *url.URL
+	next   http.RoundTripper
+}
+
+func (t baseURLTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	req.URL.RawPath = strings.TrimSuffix(t.target.EscapedPath(), "/") + req.URL.EscapedPath()
+	req.URL.Path = strings.TrimSuffix(t.target.Path, "/") + req.URL.Path
+	req.Host = t.target.Host
+
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
 	}
+	return next.RoundTrip(req)
+}
+
+// versionTransport sends the requests to the Directory API at the path of the version the client is pinned to
+type versionTransport struct {
+	from string
+	to   string
+	next http.RoundTripper
+}
 
-	return nil, nil
+func (t versionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rest, ok := strings.CutPrefix(req.URL.EscapedPath(), t.from); ok {
+		req = req.Clone(req.Context())
+		req.URL.RawPath = t.to + rest
+		req.URL.Path, _ = url.PathUnescape(req.URL.RawPath)
+	}
+
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return next.RoundTrip(req)
 }
 
 // GoogleAPIResponse is an interface for Google API responses involving pagination
@@ -391,29 +749,106 @@ type GoogleAPIResponse interface {
 	PageToken() string
 }
 
+/*
+ * # Classify a Google error response
+ * - Records the reason and message of the response on the error
+ * - Google throttles with a 403 and a rate limit reason, which is reported as rate limited rather than forbidden
+ * - https://developers.google.com/admin-sdk/directory/v1/limits
+ */
+func apiError(err error) error {
+	apiErr, ok := rerrors.AsAPIError(err)
+	if !ok {
+		return err
+	}
+	apiErr.Provider = "google"
+
+	detail := parseError(apiErr.Message)
+	if detail == nil {
+		return apiErr
+	}
+
+	apiErr.Message = detail.Message
+	apiErr.Code = detail.reason()
+	switch apiErr.Code {
+	case "rateLimitExceeded", "userRateLimitExceeded", "quotaExceeded", "dailyLimitExceeded":
+		apiErr.Class = rerrors.ErrRateLimited
+	case "duplicate":
+		apiErr.Class = rerrors.ErrConflict
+	case "notFound":
+		apiErr.Class = rerrors.ErrNotFound
+	}
+	return apiErr
+}
+
+// parseError returns the details of a Google error response, or nil if `body` is not one
+func parseError(body string) *ErrorDetail {
+	var googleError ErrorResponse
+	if json.Unmarshal([]byte(body), &googleError) != nil {
+		return nil
+	}
+	return googleError.Error
+}
+
+/*
+ * # Retry policy of Google requests
+ * Backs off by the reason of the error, since Google answers throttling, exhausted quotas and denials alike with a 403
+ * - `rateLimitExceeded` and `userRateLimitExceeded` are retried with exponential backoff, up to `MaxRateLimitBackoff`
+ * - `quotaExceeded` is retried with a longer backoff, up to `MaxQuotaBackoff`
+ * - `backendError`, `internalError` and other server errors are retried with the default backoff
+ * - `dailyLimitExceeded` and other client errors, e.g. `forbidden` or `notFound`, fail at once, since retrying cannot help
+ * - https://developers.google.com/admin-sdk/directory/v1/limits
+ */
+func retryPolicy(err error, attempt int) error {
+	apiErr, ok := rerrors.AsAPIError(err)
+	if !ok {
+		return err
+	}
+
+	reason := ""
+	if detail := parseError(apiErr.Message); detail != nil {
+		reason = detail.reason()
+	} else if apiErr.Provider == "google" {
+		reason = apiErr.Code
+	}
+
+	switch reason {
+	case "rateLimitExceeded", "userRateLimitExceeded":
+		return retry.After(err, retry.Backoff(attempt, MinRateLimitBackoff, MaxRateLimitBackoff))
+	case "quotaExceeded":
+		return retry.After(err, retry.Backoff(attempt, MinQuotaBackoff, MaxQuotaBackoff))
+	case "backendError", "internalError":
+		return err
+	case "dailyLimitExceeded":
+		return retry.Permanent(err)
+	}
+
+	switch {
+	case apiErr.StatusCode == http.StatusTooManyRequests:
+		return retry.After(err, retry.Backoff(attempt, MinRateLimitBackoff, MaxRateLimitBackoff))
+	case apiErr.StatusCode == http.StatusRequestTimeout, apiErr.StatusCode >= http.StatusInternalServerError:
+		return err
+	case apiErr.StatusCode >= http.StatusBadRequest:
+		return retry.Permanent(err)
+	}
+	return err
+}
+
 /*
  * Perform a generic request to the Google API
  */
 func do[T any](c *Client, method string, url string, query interface{}, data interface{}) (T, error) {
 	var result T
-	res, body, err := c.HTTP.DoRequest(method, url, query, data)
+	res, err := c.HTTP.DoStream(method, url, query, data)
 	if err != nil {
-		return *new(T), err
+		return *new(T), apiError(err)
 	}
+	body := requests.LogBody(c.Log, res.Body)
+	defer body.Close()
 
 	c.Log.Println("Response Status:", res.Status)
-	c.Log.Debug("Response Body:", string(body))
-
-	if res.StatusCode >= 400 {
-		var googleError ErrorResponse
-		err = json.Unmarshal(body, &googleError)
-		if err != nil {
-			return *new(T), fmt.Errorf("error unmarshalling API error response: %w", err)
-		}
-		return *new(T), googleError.Error
-	}
 
-	err = json.Unmarshal(body, &result)
+	// Deletions respond with an empty body, which leaves the result empty
+	err = requests.DecodeStream(body, &result)
 	if err != nil {
 		return *new(T), fmt.Errorf("unmarshalling error: %w", err)
 	}
@@ -0,0 +1,707 @@
+// Code generated by mockgen from interfaces.go; DO NOT EDIT.
+
+// pkg/google/googlemock/mocks.go
+package googlemock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/checkpoint"
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/google"
+)
+
+// Call is a call received by a mock
+type Call struct {
+	Method string        // Name of the method called
+	Args   []interface{} // Arguments of the call
+}
+
+// calls records the calls received by a mock
+type calls struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+func (c *calls) record(method string, args ...interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls received, in order; only those of `method` when set
+func (c *calls) Calls(method ...string) []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	received := []Call{}
+	for _, call := range c.calls {
+		if len(method) == 0 || call.Method == method[0] {
+			received = append(received, call)
+		}
+	}
+	return received
+}
+
+// AdminService is a mock of `google.AdminService`; methods without a func return zero values
+type AdminService struct {
+	MyCustomerFunc                  func() (*google.Customer, error)
+	ListAllRolesFunc                func(*google.Customer) (*google.Roles, error)
+	GetRoleFunc                     func(string, *google.Customer) (*google.Role, error)
+	ListAllRoleAssignmentsFunc      func(*google.Customer) (*google.RoleAssignment, error)
+	GetAssignmentsForRoleFunc       func(string, *google.Customer) (*google.RoleAssignment, error)
+	GetUsersFromRoleAssignmentsFunc func(chan struct{}, []google.RoleAssignment) ([]*google.User, error)
+	GenerateRoleReportFunc          func(string, *google.Customer) ([]*google.RoleReport, error)
+	SaveRoleReportFunc              func([]*google.RoleReport) (*google.Spreadsheet, error)
+	GetFileOwnershipFunc            func(string) (string, error)
+	ListUserUsageFunc               func(string, ...string) ([]*google.UsageReport, error)
+	ListActivitiesFunc              func(string, google.ReportsQuery) ([]google.Report, error)
+	IterActivitiesFunc              func(string, google.ReportsQuery) iterator.Seq[google.Report]
+	TailActivitiesFunc              func(string, *checkpoint.Checkpoint, google.ReportsQuery) iterator.Seq[google.Report]
+	RootOUFunc                      func(*google.Customer) (*google.OrgUnit, error)
+	GetOUFunc                       func(*google.Customer, string) (*google.OrgUnit, error)
+	CloneOUFunc                     func(*google.Customer, string, string) error
+
+	calls
+}
+
+var _ google.AdminService = (*AdminService)(nil)
+
+func (m *AdminService) MyCustomer() (r0 *google.Customer, r1 error) {
+	m.record("MyCustomer")
+	if m.MyCustomerFunc != nil {
+		return m.MyCustomerFunc()
+	}
+	return
+}
+
+func (m *AdminService) ListAllRoles(a0 *google.Customer) (r0 *google.Roles, r1 error) {
+	m.record("ListAllRoles", a0)
+	if m.ListAllRolesFunc != nil {
+		return m.ListAllRolesFunc(a0)
+	}
+	return
+}
+
+func (m *AdminService) GetRole(a0 string, a1 *google.Customer) (r0 *google.Role, r1 error) {
+	m.record("GetRole", a0, a1)
+	if m.GetRoleFunc != nil {
+		return m.GetRoleFunc(a0, a1)
+	}
+	return
+}
+
+func (m *AdminService) ListAllRoleAssignments(a0 *google.Customer) (r0 *google.RoleAssignment, r1 error) {
+	m.record("ListAllRoleAssignments", a0)
+	if m.ListAllRoleAssignmentsFunc != nil {
+		return m.ListAllRoleAssignmentsFunc(a0)
+	}
+	return
+}
+
+func (m *AdminService) GetAssignmentsForRole(a0 string, a1 *google.Customer) (r0 *google.RoleAssignment, r1 error) {
+	m.record("GetAssignmentsForRole", a0, a1)
+	if m.GetAssignmentsForRoleFunc != nil {
+		return m.GetAssignmentsForRoleFunc(a0, a1)
+	}
+	return
+}
+
+func (m *AdminService) GetUsersFromRoleAssignments(a0 chan struct{}, a1 []google.RoleAssignment) (r0 []*google.User, r1 error) {
+	m.record("GetUsersFromRoleAssignments", a0, a1)
+	if m.GetUsersFromRoleAssignmentsFunc != nil {
+		return m.GetUsersFromRoleAssignmentsFunc(a0, a1)
+	}
+	return
+}
+
+func (m *AdminService) GenerateRoleReport(a0 string, a1 *google.Customer) (r0 []*google.RoleReport, r1 error) {
+	m.record("GenerateRoleReport", a0, a1)
+	if m.GenerateRoleReportFunc != nil {
+		return m.GenerateRoleReportFunc(a0, a1)
+	}
+	return
+}
+
+func (m *AdminService) SaveRoleReport(a0 []*google.RoleReport) (r0 *google.Spreadsheet, r1 error) {
+	m.record("SaveRoleReport", a0)
+	if m.SaveRoleReportFunc != nil {
+		return m.SaveRoleReportFunc(a0)
+	}
+	return
+}
+
+func (m *AdminService) GetFileOwnership(a0 string) (r0 string, r1 error) {
+	m.record("GetFileOwnership", a0)
+	if m.GetFileOwnershipFunc != nil {
+		return m.GetFileOwnershipFunc(a0)
+	}
+	return
+}
+
+func (m *AdminService) ListUserUsage(a0 string, a1 ...string) (r0 []*google.UsageReport, r1 error) {
+	m.record("ListUserUsage", a0, a1)
+	if m.ListUserUsageFunc != nil {
+		return m.ListUserUsageFunc(a0, a1...)
+	}
+	return
+}
+
+func (m *AdminService) ListActivities(a0 string, a1 google.ReportsQuery) (r0 []google.Report, r1 error) {
+	m.record("ListActivities", a0, a1)
+	if m.ListActivitiesFunc != nil {
+		return m.ListActivitiesFunc(a0, a1)
+	}
+	return
+}
+
+func (m *AdminService) IterActivities(a0 string, a1 google.ReportsQuery) (r0 iterator.Seq[google.Report]) {
+	m.record("IterActivities", a0, a1)
+	if m.IterActivitiesFunc != nil {
+		return m.IterActivitiesFunc(a0, a1)
+	}
+	return
+}
+
+func (m *AdminService) TailActivities(a0 string, a1 *checkpoint.Checkpoint, a2 google.ReportsQuery) (r0 iterator.Seq[google.Report]) {
+	m.record("TailActivities", a0, a1, a2)
+	if m.TailActivitiesFunc != nil {
+		return m.TailActivitiesFunc(a0, a1, a2)
+	}
+	return
+}
+
+func (m *AdminService) RootOU(a0 *google.Customer) (r0 *google.OrgUnit, r1 error) {
+	m.record("RootOU", a0)
+	if m.RootOUFunc != nil {
+		return m.RootOUFunc(a0)
+	}
+	return
+}
+
+func (m *AdminService) GetOU(a0 *google.Customer, a1 string) (r0 *google.OrgUnit, r1 error) {
+	m.record("GetOU", a0, a1)
+	if m.GetOUFunc != nil {
+		return m.GetOUFunc(a0, a1)
+	}
+	return
+}
+
+func (m *AdminService) CloneOU(a0 *google.Customer, a1 string, a2 string) (r0 error) {
+	m.record("CloneOU", a0, a1, a2)
+	if m.CloneOUFunc != nil {
+		return m.CloneOUFunc(a0, a1, a2)
+	}
+	return
+}
+
+// CalendarService is a mock of `google.CalendarService`; methods without a func return zero values
+type CalendarService struct {
+	GetEventFunc     func(string, string) (*google.CalendarEvent, error)
+	AddAttendeesFunc func(string, string, ...string) (*google.CalendarEvent, error)
+
+	calls
+}
+
+var _ google.CalendarService = (*CalendarService)(nil)
+
+func (m *CalendarService) GetEvent(a0 string, a1 string) (r0 *google.CalendarEvent, r1 error) {
+	m.record("GetEvent", a0, a1)
+	if m.GetEventFunc != nil {
+		return m.GetEventFunc(a0, a1)
+	}
+	return
+}
+
+func (m *CalendarService) AddAttendees(a0 string, a1 string, a2 ...string) (r0 *google.CalendarEvent, r1 error) {
+	m.record("AddAttendees", a0, a1, a2)
+	if m.AddAttendeesFunc != nil {
+		return m.AddAttendeesFunc(a0, a1, a2...)
+	}
+	return
+}
+
+// DataTransferService is a mock of `google.DataTransferService`; methods without a func return zero values
+type DataTransferService struct {
+	TransferDataFunc func(string, string, ...google.ApplicationDataTransfer) (*google.DataTransfer, error)
+	GetTransferFunc  func(string) (*google.DataTransfer, error)
+
+	calls
+}
+
+var _ google.DataTransferService = (*DataTransferService)(nil)
+
+func (m *DataTransferService) TransferData(a0 string, a1 string, a2 ...google.ApplicationDataTransfer) (r0 *google.DataTransfer, r1 error) {
+	m.record("TransferData", a0, a1, a2)
+	if m.TransferDataFunc != nil {
+		return m.TransferDataFunc(a0, a1, a2...)
+	}
+	return
+}
+
+func (m *DataTransferService) GetTransfer(a0 string) (r0 *google.DataTransfer, r1 error) {
+	m.record("GetTransfer", a0)
+	if m.GetTransferFunc != nil {
+		return m.GetTransferFunc(a0)
+	}
+	return
+}
+
+// DeviceService is a mock of `google.DeviceService`; methods without a func return zero values
+type DeviceService struct {
+	ListAllChromeOSFunc            func(*google.Customer) (*google.ChromeOSDevices, error)
+	ListAllProvisionedChromeOSFunc func(*google.Customer) (*google.ChromeOSDevices, error)
+	ListAllDevicePolicySchemasFunc func(*google.Customer) (*google.PolicySchemas, error)
+	ResolvePolicySchemasFunc       func(*google.Customer, *google.OrgUnit) (*google.ResolvedPolicies, error)
+
+	calls
+}
+
+var _ google.DeviceService = (*DeviceService)(nil)
+
+func (m *DeviceService) ListAllChromeOS(a0 *google.Customer) (r0 *google.ChromeOSDevices, r1 error) {
+	m.record("ListAllChromeOS", a0)
+	if m.ListAllChromeOSFunc != nil {
+		return m.ListAllChromeOSFunc(a0)
+	}
+	return
+}
+
+func (m *DeviceService) ListAllProvisionedChromeOS(a0 *google.Customer) (r0 *google.ChromeOSDevices, r1 error) {
+	m.record("ListAllProvisionedChromeOS", a0)
+	if m.ListAllProvisionedChromeOSFunc != nil {
+		return m.ListAllProvisionedChromeOSFunc(a0)
+	}
+	return
+}
+
+func (m *DeviceService) ListAllDevicePolicySchemas(a0 *google.Customer) (r0 *google.PolicySchemas, r1 error) {
+	m.record("ListAllDevicePolicySchemas", a0)
+	if m.ListAllDevicePolicySchemasFunc != nil {
+		return m.ListAllDevicePolicySchemasFunc(a0)
+	}
+	return
+}
+
+func (m *DeviceService) ResolvePolicySchemas(a0 *google.Customer, a1 *google.OrgUnit) (r0 *google.ResolvedPolicies, r1 error) {
+	m.record("ResolvePolicySchemas", a0, a1)
+	if m.ResolvePolicySchemasFunc != nil {
+		return m.ResolvePolicySchemasFunc(a0, a1)
+	}
+	return
+}
+
+// DriveService is a mock of `google.DriveService`; methods without a func return zero values
+type DriveService struct {
+	GetFileFunc             func(string) (*google.File, error)
+	CreateFileFunc          func(*google.File) (*google.File, error)
+	MoveFileToFolderFunc    func(*google.File, *google.File) error
+	CopyFileToFolderFunc    func(*google.File, *google.File) error
+	GetRootFileListFunc     func() (*google.FileList, error)
+	GetFileListFunc         func(*google.File, *google.DriveFileQuery) (*google.FileList, error)
+	SaveFileListToSheetFunc func(*google.FileList, string, *[]string) error
+	GetFilePathFunc         func(string) (string, error)
+
+	calls
+}
+
+var _ google.DriveService = (*DriveService)(nil)
+
+func (m *DriveService) GetFile(a0 string) (r0 *google.File, r1 error) {
+	m.record("GetFile", a0)
+	if m.GetFileFunc != nil {
+		return m.GetFileFunc(a0)
+	}
+	return
+}
+
+func (m *DriveService) CreateFile(a0 *google.File) (r0 *google.File, r1 error) {
+	m.record("CreateFile", a0)
+	if m.CreateFileFunc != nil {
+		return m.CreateFileFunc(a0)
+	}
+	return
+}
+
+func (m *DriveService) MoveFileToFolder(a0 *google.File, a1 *google.File) (r0 error) {
+	m.record("MoveFileToFolder", a0, a1)
+	if m.MoveFileToFolderFunc != nil {
+		return m.MoveFileToFolderFunc(a0, a1)
+	}
+	return
+}
+
+func (m *DriveService) CopyFileToFolder(a0 *google.File, a1 *google.File) (r0 error) {
+	m.record("CopyFileToFolder", a0, a1)
+	if m.CopyFileToFolderFunc != nil {
+		return m.CopyFileToFolderFunc(a0, a1)
+	}
+	return
+}
+
+func (m *DriveService) GetRootFileList() (r0 *google.FileList, r1 error) {
+	m.record("GetRootFileList")
+	if m.GetRootFileListFunc != nil {
+		return m.GetRootFileListFunc()
+	}
+	return
+}
+
+func (m *DriveService) GetFileList(a0 *google.File, a1 *google.DriveFileQuery) (r0 *google.FileList, r1 error) {
+	m.record("GetFileList", a0, a1)
+	if m.GetFileListFunc != nil {
+		return m.GetFileListFunc(a0, a1)
+	}
+	return
+}
+
+func (m *DriveService) SaveFileListToSheet(a0 *google.FileList, a1 string, a2 *[]string) (r0 error) {
+	m.record("SaveFileListToSheet", a0, a1, a2)
+	if m.SaveFileListToSheetFunc != nil {
+		return m.SaveFileListToSheetFunc(a0, a1, a2)
+	}
+	return
+}
+
+func (m *DriveService) GetFilePath(a0 string) (r0 string, r1 error) {
+	m.record("GetFilePath", a0)
+	if m.GetFilePathFunc != nil {
+		return m.GetFilePathFunc(a0)
+	}
+	return
+}
+
+// GmailService is a mock of `google.GmailService`; methods without a func return zero values
+type GmailService struct {
+	GetVacationFunc    func(string) (*google.VacationSettings, error)
+	UpdateVacationFunc func(string, *google.VacationSettings) (*google.VacationSettings, error)
+	SendMessageFunc    func(string, []byte) (*google.GmailMessage, error)
+
+	calls
+}
+
+var _ google.GmailService = (*GmailService)(nil)
+
+func (m *GmailService) GetVacation(a0 string) (r0 *google.VacationSettings, r1 error) {
+	m.record("GetVacation", a0)
+	if m.GetVacationFunc != nil {
+		return m.GetVacationFunc(a0)
+	}
+	return
+}
+
+func (m *GmailService) UpdateVacation(a0 string, a1 *google.VacationSettings) (r0 *google.VacationSettings, r1 error) {
+	m.record("UpdateVacation", a0, a1)
+	if m.UpdateVacationFunc != nil {
+		return m.UpdateVacationFunc(a0, a1)
+	}
+	return
+}
+
+func (m *GmailService) SendMessage(a0 string, a1 []byte) (r0 *google.GmailMessage, r1 error) {
+	m.record("SendMessage", a0, a1)
+	if m.SendMessageFunc != nil {
+		return m.SendMessageFunc(a0, a1)
+	}
+	return
+}
+
+// GroupService is a mock of `google.GroupService`; methods without a func return zero values
+type GroupService struct {
+	ListMembersFunc  func(string) (*google.Members, error)
+	IterMembersFunc  func(string) iterator.Seq[*google.Member]
+	AddMemberFunc    func(string, string, string) (*google.Member, error)
+	RemoveMemberFunc func(string, string) error
+
+	calls
+}
+
+var _ google.GroupService = (*GroupService)(nil)
+
+func (m *GroupService) ListMembers(a0 string) (r0 *google.Members, r1 error) {
+	m.record("ListMembers", a0)
+	if m.ListMembersFunc != nil {
+		return m.ListMembersFunc(a0)
+	}
+	return
+}
+
+func (m *GroupService) IterMembers(a0 string) (r0 iterator.Seq[*google.Member]) {
+	m.record("IterMembers", a0)
+	if m.IterMembersFunc != nil {
+		return m.IterMembersFunc(a0)
+	}
+	return
+}
+
+func (m *GroupService) AddMember(a0 string, a1 string, a2 string) (r0 *google.Member, r1 error) {
+	m.record("AddMember", a0, a1, a2)
+	if m.AddMemberFunc != nil {
+		return m.AddMemberFunc(a0, a1, a2)
+	}
+	return
+}
+
+func (m *GroupService) RemoveMember(a0 string, a1 string) (r0 error) {
+	m.record("RemoveMember", a0, a1)
+	if m.RemoveMemberFunc != nil {
+		return m.RemoveMemberFunc(a0, a1)
+	}
+	return
+}
+
+// IAMService is a mock of `google.IAMService`; methods without a func return zero values
+type IAMService struct {
+	ListServiceAccountsFunc    func(string) ([]*google.IAMServiceAccount, error)
+	ListServiceAccountKeysFunc func(string) ([]*google.ServiceAccountKey, error)
+
+	calls
+}
+
+var _ google.IAMService = (*IAMService)(nil)
+
+func (m *IAMService) ListServiceAccounts(a0 string) (r0 []*google.IAMServiceAccount, r1 error) {
+	m.record("ListServiceAccounts", a0)
+	if m.ListServiceAccountsFunc != nil {
+		return m.ListServiceAccountsFunc(a0)
+	}
+	return
+}
+
+func (m *IAMService) ListServiceAccountKeys(a0 string) (r0 []*google.ServiceAccountKey, r1 error) {
+	m.record("ListServiceAccountKeys", a0)
+	if m.ListServiceAccountKeysFunc != nil {
+		return m.ListServiceAccountKeysFunc(a0)
+	}
+	return
+}
+
+// LicensingService is a mock of `google.LicensingService`; methods without a func return zero values
+type LicensingService struct {
+	ListAssignmentsFunc func(string, string, string) ([]*google.LicenseAssignment, error)
+
+	calls
+}
+
+var _ google.LicensingService = (*LicensingService)(nil)
+
+func (m *LicensingService) ListAssignments(a0 string, a1 string, a2 string) (r0 []*google.LicenseAssignment, r1 error) {
+	m.record("ListAssignments", a0, a1, a2)
+	if m.ListAssignmentsFunc != nil {
+		return m.ListAssignmentsFunc(a0, a1, a2)
+	}
+	return
+}
+
+// PermissionService is a mock of `google.PermissionService`; methods without a func return zero values
+type PermissionService struct {
+	GetPermissionListFunc    func(string) (*google.PermissionList, error)
+	GetPermissionDetailsFunc func(string, string) (*google.Permission, error)
+	TransferOwnershipFunc    func(string, string) (*google.Permission, error)
+
+	calls
+}
+
+var _ google.PermissionService = (*PermissionService)(nil)
+
+func (m *PermissionService) GetPermissionList(a0 string) (r0 *google.PermissionList, r1 error) {
+	m.record("GetPermissionList", a0)
+	if m.GetPermissionListFunc != nil {
+		return m.GetPermissionListFunc(a0)
+	}
+	return
+}
+
+func (m *PermissionService) GetPermissionDetails(a0 string, a1 string) (r0 *google.Permission, r1 error) {
+	m.record("GetPermissionDetails", a0, a1)
+	if m.GetPermissionDetailsFunc != nil {
+		return m.GetPermissionDetailsFunc(a0, a1)
+	}
+	return
+}
+
+func (m *PermissionService) TransferOwnership(a0 string, a1 string) (r0 *google.Permission, r1 error) {
+	m.record("TransferOwnership", a0, a1)
+	if m.TransferOwnershipFunc != nil {
+		return m.TransferOwnershipFunc(a0, a1)
+	}
+	return
+}
+
+// SheetsService is a mock of `google.SheetsService`; methods without a func return zero values
+type SheetsService struct {
+	VerifySheetValueRangeFunc   func(*google.ValueRange) error
+	GenerateValueRangeFunc      func([]interface{}, string, *[]string) *google.ValueRange
+	CreateSpreadsheetFunc       func(*google.Spreadsheet) (*google.Spreadsheet, error)
+	UpdateSpreadsheetFunc       func(string, *google.ValueRange) error
+	AppendSpreadsheetFunc       func(string, *google.ValueRange) error
+	FormatHeaderAndAutoSizeFunc func(string, *google.Sheet, int, int) error
+	SaveToSheetFunc             func(interface{}, string, string, *[]string) error
+	GetSpreadsheetFunc          func(string) (*google.Spreadsheet, error)
+	ReadSpreadsheetValuesFunc   func(string, string) (*google.ValueRange, error)
+
+	calls
+}
+
+var _ google.SheetsService = (*SheetsService)(nil)
+
+func (m *SheetsService) VerifySheetValueRange(a0 *google.ValueRange) (r0 error) {
+	m.record("VerifySheetValueRange", a0)
+	if m.VerifySheetValueRangeFunc != nil {
+		return m.VerifySheetValueRangeFunc(a0)
+	}
+	return
+}
+
+func (m *SheetsService) GenerateValueRange(a0 []interface{}, a1 string, a2 *[]string) (r0 *google.ValueRange) {
+	m.record("GenerateValueRange", a0, a1, a2)
+	if m.GenerateValueRangeFunc != nil {
+		return m.GenerateValueRangeFunc(a0, a1, a2)
+	}
+	return
+}
+
+func (m *SheetsService) CreateSpreadsheet(a0 *google.Spreadsheet) (r0 *google.Spreadsheet, r1 error) {
+	m.record("CreateSpreadsheet", a0)
+	if m.CreateSpreadsheetFunc != nil {
+		return m.CreateSpreadsheetFunc(a0)
+	}
+	return
+}
+
+func (m *SheetsService) UpdateSpreadsheet(a0 string, a1 *google.ValueRange) (r0 error) {
+	m.record("UpdateSpreadsheet", a0, a1)
+	if m.UpdateSpreadsheetFunc != nil {
+		return m.UpdateSpreadsheetFunc(a0, a1)
+	}
+	return
+}
+
+func (m *SheetsService) AppendSpreadsheet(a0 string, a1 *google.ValueRange) (r0 error) {
+	m.record("AppendSpreadsheet", a0, a1)
+	if m.AppendSpreadsheetFunc != nil {
+		return m.AppendSpreadsheetFunc(a0, a1)
+	}
+	return
+}
+
+func (m *SheetsService) FormatHeaderAndAutoSize(a0 string, a1 *google.Sheet, a2 int, a3 int) (r0 error) {
+	m.record("FormatHeaderAndAutoSize", a0, a1, a2, a3)
+	if m.FormatHeaderAndAutoSizeFunc != nil {
+		return m.FormatHeaderAndAutoSizeFunc(a0, a1, a2, a3)
+	}
+	return
+}
+
+func (m *SheetsService) SaveToSheet(a0 interface{}, a1 string, a2 string, a3 *[]string) (r0 error) {
+	m.record("SaveToSheet", a0, a1, a2, a3)
+	if m.SaveToSheetFunc != nil {
+		return m.SaveToSheetFunc(a0, a1, a2, a3)
+	}
+	return
+}
+
+func (m *SheetsService) GetSpreadsheet(a0 string) (r0 *google.Spreadsheet, r1 error) {
+	m.record("GetSpreadsheet", a0)
+	if m.GetSpreadsheetFunc != nil {
+		return m.GetSpreadsheetFunc(a0)
+	}
+	return
+}
+
+func (m *SheetsService) ReadSpreadsheetValues(a0 string, a1 string) (r0 *google.ValueRange, r1 error) {
+	m.record("ReadSpreadsheetValues", a0, a1)
+	if m.ReadSpreadsheetValuesFunc != nil {
+		return m.ReadSpreadsheetValuesFunc(a0, a1)
+	}
+	return
+}
+
+// UserService is a mock of `google.UserService`; methods without a func return zero values
+type UserService struct {
+	ListAllUsersFunc          func() (*google.Users, error)
+	IterUsersFunc             func(*google.UserQuery) iterator.Seq[*google.User]
+	IterUsersChangedSinceFunc func(time.Time) iterator.Seq[*google.User]
+	SearchUsersFunc           func(*google.UserQuery) (*google.Users, error)
+	GetUserFunc               func(string) (*google.User, error)
+	CreateUserFunc            func(map[string]interface{}) (*google.User, error)
+	UpdateUserFunc            func(string, map[string]interface{}) (*google.User, error)
+	SuspendUserFunc           func(string) (*google.User, error)
+	MoveUserToOUFunc          func(string, string) (*google.User, error)
+
+	calls
+}
+
+var _ google.UserService = (*UserService)(nil)
+
+func (m *UserService) ListAllUsers() (r0 *google.Users, r1 error) {
+	m.record("ListAllUsers")
+	if m.ListAllUsersFunc != nil {
+		return m.ListAllUsersFunc()
+	}
+	return
+}
+
+func (m *UserService) IterUsers(a0 *google.UserQuery) (r0 iterator.Seq[*google.User]) {
+	m.record("IterUsers", a0)
+	if m.IterUsersFunc != nil {
+		return m.IterUsersFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) IterUsersChangedSince(a0 time.Time) (r0 iterator.Seq[*google.User]) {
+	m.record("IterUsersChangedSince", a0)
+	if m.IterUsersChangedSinceFunc != nil {
+		return m.IterUsersChangedSinceFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) SearchUsers(a0 *google.UserQuery) (r0 *google.Users, r1 error) {
+	m.record("SearchUsers", a0)
+	if m.SearchUsersFunc != nil {
+		return m.SearchUsersFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) GetUser(a0 string) (r0 *google.User, r1 error) {
+	m.record("GetUser", a0)
+	if m.GetUserFunc != nil {
+		return m.GetUserFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) CreateUser(a0 map[string]interface{}) (r0 *google.User, r1 error) {
+	m.record("CreateUser", a0)
+	if m.CreateUserFunc != nil {
+		return m.CreateUserFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) UpdateUser(a0 string, a1 map[string]interface{}) (r0 *google.User, r1 error) {
+	m.record("UpdateUser", a0, a1)
+	if m.UpdateUserFunc != nil {
+		return m.UpdateUserFunc(a0, a1)
+	}
+	return
+}
+
+func (m *UserService) SuspendUser(a0 string) (r0 *google.User, r1 error) {
+	m.record("SuspendUser", a0)
+	if m.SuspendUserFunc != nil {
+		return m.SuspendUserFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) MoveUserToOU(a0 string, a1 string) (r0 *google.User, r1 error) {
+	m.record("MoveUserToOU", a0, a1)
+	if m.MoveUserToOUFunc != nil {
+		return m.MoveUserToOUFunc(a0, a1)
+	}
+	return
+}
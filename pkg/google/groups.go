@@ -0,0 +1,132 @@
+/*
+# Google Workspace - Groups
+
+This package implements logic related to the `Members` resource of the Google Admin SDK API:
+https://developers.google.com/admin-sdk/directory/reference/rest/v1/members
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/google/groups.go
+package google
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+// GroupsClient for chaining methods
+type GroupsClient struct {
+	*Client
+}
+
+// Entry point for group-related operations
+func (c *Client) Groups() *GroupsClient {
+	return &GroupsClient{
+		Client: c,
+	}
+}
+
+/*
+ * Query Parameters for Group Members
+ * https://developers.google.com/admin-sdk/directory/reference/rest/v1/members/list#query-parameters
+ */
+type MemberQuery struct {
+	IncludeDerivedMembership bool   `url:"includeDerivedMembership,omitempty"` // Whether to list indirect memberships. Default: false.
+	MaxResults               int    `url:"maxResults,omitempty"`               // Maximum number of results to return. Max allowed value is 200.
+	PageToken                string `url:"pageToken,omitempty"`                // Token to specify next page in the list.
+	Roles                    string `url:"roles,omitempty"`                    // Comma-separated list of roles to filter by {OWNER, MANAGER, MEMBER}
+}
+
+/*
+ * Lists the direct members of a Group
+ * /admin/directory/v1/groups/{groupKey}/members
+ * https://developers.google.com/admin-sdk/directory/reference/rest/v1/members/list
+ */
+func (c *GroupsClient) ListMembers(groupKey string) (*Members, error) {
+	url := fmt.Sprintf(DirectoryMembers, requests.EscapePathSegment(groupKey))
+	c.Log.Debug("url:", url)
+
+	var cache Members
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	items, err := iterator.Collect(c.IterMembers(groupKey))
+	if err != nil {
+		return nil, err
+	}
+	members := Members{Members: items}
+
+	c.SetCache(url, members, 5*time.Minute)
+	return &members, nil
+}
+
+/*
+ * Iterate the Members of a Group
+ * - Pages are requested as the members are consumed, so callers can stop early; results are not cached
+ * /admin/directory/v1/groups/{groupKey}/members
+ * https://developers.google.com/admin-sdk/directory/reference/rest/v1/members/list
+ */
+func (c *GroupsClient) IterMembers(groupKey string) iterator.Seq[*Member] {
+	url := fmt.Sprintf(DirectoryMembers, requests.EscapePathSegment(groupKey))
+	q := MemberQuery{
+		MaxResults: 200,
+	}
+
+	return iterator.Pages(func(pageToken string) ([]*Member, string, error) {
+		q.PageToken = pageToken
+		page, err := do[Members](c.Client, "GET", url, q, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Members, page.NextPageToken, nil
+	})
+}
+
+/*
+ * Adds a User to a Group
+ * - `role` defaults to `MEMBER` when empty {OWNER, MANAGER, MEMBER}
+ * /admin/directory/v1/groups/{groupKey}/members
+ * https://developers.google.com/admin-sdk/directory/reference/rest/v1/members/insert
+ */
+func (c *GroupsClient) AddMember(groupKey string, email string, role string) (*Member, error) {
+	url := fmt.Sprintf(DirectoryMembers, requests.EscapePathSegment(groupKey))
+	c.Log.Debug("url:", url)
+
+	if role == "" {
+		role = "MEMBER"
+	}
+
+	member := &Member{
+		Email: email,
+		Role:  role,
+	}
+
+	c.Log.Printf("Adding %s to Google group %s as %s", email, groupKey, role)
+	result, err := do[Member](c.Client, "POST", url, nil, member)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+/*
+ * Removes a User from a Group
+ * /admin/directory/v1/groups/{groupKey}/members/{memberKey}
+ * https://developers.google.com/admin-sdk/directory/reference/rest/v1/members/delete
+ */
+func (c *GroupsClient) RemoveMember(groupKey string, memberKey string) error {
+	url := fmt.Sprintf(DirectoryMembers+"/%s", requests.EscapePathSegment(groupKey), requests.EscapePathSegment(memberKey))
+	c.Log.Debug("url:", url)
+
+	c.Log.Printf("Removing %s from Google group %s", memberKey, groupKey)
+	_, err := do[interface{}](c.Client, "DELETE", url, nil, nil)
+	return err
+}
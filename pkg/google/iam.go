@@ -0,0 +1,110 @@
+/*
+# Google Cloud - IAM
+
+This package implements logic related to the service accounts of the Identity and Access Management API:
+https://cloud.google.com/iam/docs/reference/rest
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/google/iam.go
+package google
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+var (
+	IAMBaseURL  = "https://iam.googleapis.com/v1"                                        // https://cloud.google.com/iam/docs/reference/rest
+	IAMAccounts = fmt.Sprintf("%s/projects/%s/serviceAccounts", IAMBaseURL, "%s")        // https://cloud.google.com/iam/docs/reference/rest/v1/projects.serviceAccounts
+	IAMKeys     = fmt.Sprintf("%s/projects/-/serviceAccounts/%s/keys", IAMBaseURL, "%s") // https://cloud.google.com/iam/docs/reference/rest/v1/projects.serviceAccounts.keys
+)
+
+// IAMClient for chaining methods
+type IAMClient struct {
+	*Client
+}
+
+// Entry point for IAM-related operations
+func (c *Client) IAM() *IAMClient {
+	return &IAMClient{
+		Client: c,
+	}
+}
+
+/*
+ * Query Parameters for Service Accounts
+ * https://cloud.google.com/iam/docs/reference/rest/v1/projects.serviceAccounts/list#query-parameters
+ */
+type ServiceAccountQuery struct {
+	PageSize  int    `url:"pageSize,omitempty"`  // Maximum number of service accounts to return. Max allowed value is 100.
+	PageToken string `url:"pageToken,omitempty"` // Token to specify next page in the list.
+}
+
+/*
+ * Lists the service accounts of a project
+ * /v1/projects/{project}/serviceAccounts
+ * https://cloud.google.com/iam/docs/reference/rest/v1/projects.serviceAccounts/list
+ */
+func (c *IAMClient) ListServiceAccounts(projectID string) ([]*IAMServiceAccount, error) {
+	url := fmt.Sprintf(IAMAccounts, requests.EscapePathSegment(projectID))
+	c.Log.Debug("url:", url)
+
+	var cache []*IAMServiceAccount
+	if c.GetCache(url, &cache) {
+		return cache, nil
+	}
+
+	q := ServiceAccountQuery{
+		PageSize: 100,
+	}
+
+	accounts := []*IAMServiceAccount{}
+	for {
+		page, err := do[IAMServiceAccounts](c.Client, "GET", url, q, nil)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, page.Accounts...)
+
+		if page.NextPageToken == "" {
+			break
+		}
+		q.PageToken = page.NextPageToken
+	}
+
+	c.SetCache(url, accounts, 30*time.Minute)
+	return accounts, nil
+}
+
+/*
+ * Lists the user-managed keys of a service account, i.e. the keys which can be downloaded and leaked
+ * /v1/projects/-/serviceAccounts/{email}/keys
+ * https://cloud.google.com/iam/docs/reference/rest/v1/projects.serviceAccounts.keys/list
+ */
+func (c *IAMClient) ListServiceAccountKeys(email string) ([]*ServiceAccountKey, error) {
+	url := fmt.Sprintf(IAMKeys, requests.EscapePathSegment(email))
+	c.Log.Debug("url:", url)
+
+	var cache []*ServiceAccountKey
+	if c.GetCache(url, &cache) {
+		return cache, nil
+	}
+
+	q := struct {
+		KeyTypes string `url:"keyTypes"`
+	}{"USER_MANAGED"}
+
+	keys, err := do[ServiceAccountKeys](c.Client, "GET", url, q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetCache(url, keys.Keys, 30*time.Minute)
+	return keys.Keys, nil
+}
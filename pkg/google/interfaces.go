@@ -0,0 +1,148 @@
+/*
+# Google Interfaces
+
+This package contains the interfaces of each Google resource, which the resource clients satisfy. Code depending on an
+interface rather than the clients can be unit tested with the mocks of `pkg/google/googlemock`
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/google/interfaces.go
+package google
+
+import (
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/checkpoint"
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+)
+
+//go:generate go run ../../cmd/mockgen
+
+// AdminService reads the customer, its admin roles, organizational units and reports
+type AdminService interface {
+	MyCustomer() (*Customer, error)
+	ListAllRoles(customer *Customer) (*Roles, error)
+	GetRole(roleId string, customer *Customer) (*Role, error)
+	ListAllRoleAssignments(customer *Customer) (*RoleAssignment, error)
+	GetAssignmentsForRole(roleId string, customer *Customer) (*RoleAssignment, error)
+	GetUsersFromRoleAssignments(sem chan struct{}, roleAssignments []RoleAssignment) ([]*User, error)
+	GenerateRoleReport(roleId string, customer *Customer) ([]*RoleReport, error)
+	SaveRoleReport(reports []*RoleReport) (*Spreadsheet, error)
+	GetFileOwnership(fileID string) (string, error)
+	ListUserUsage(date string, parameters ...string) ([]*UsageReport, error)
+	ListActivities(application string, q ReportsQuery) ([]Report, error)
+	IterActivities(application string, q ReportsQuery) iterator.Seq[Report]
+	TailActivities(application string, cp *checkpoint.Checkpoint, q ReportsQuery) iterator.Seq[Report]
+	RootOU(customer *Customer) (*OrgUnit, error)
+	GetOU(customer *Customer, orgUnitPath string) (*OrgUnit, error)
+	CloneOU(customer *Customer, sourcePath, targetPath string) error
+}
+
+// CalendarService reads and updates calendar events
+type CalendarService interface {
+	GetEvent(calendarID string, eventID string) (*CalendarEvent, error)
+	AddAttendees(calendarID string, eventID string, emails ...string) (*CalendarEvent, error)
+}
+
+// DataTransferService transfers the data of users between owners
+type DataTransferService interface {
+	TransferData(oldOwnerID, newOwnerID string, apps ...ApplicationDataTransfer) (*DataTransfer, error)
+	GetTransfer(transferID string) (*DataTransfer, error)
+}
+
+// DeviceService lists ChromeOS devices and their policies
+type DeviceService interface {
+	ListAllChromeOS(customer *Customer) (*ChromeOSDevices, error)
+	ListAllProvisionedChromeOS(customer *Customer) (*ChromeOSDevices, error)
+	ListAllDevicePolicySchemas(customer *Customer) (*PolicySchemas, error)
+	ResolvePolicySchemas(customer *Customer, ou *OrgUnit) (*ResolvedPolicies, error)
+}
+
+// DriveService manages Drive files and folders
+type DriveService interface {
+	GetFile(driveID string) (*File, error)
+	CreateFile(file *File) (*File, error)
+	MoveFileToFolder(file *File, folder *File) error
+	CopyFileToFolder(file *File, folder *File) error
+	GetRootFileList() (*FileList, error)
+	GetFileList(file *File, q *DriveFileQuery) (*FileList, error)
+	SaveFileListToSheet(fileList *FileList, sheetID string, headers *[]string) error
+	GetFilePath(id string) (string, error)
+}
+
+// GmailService manages the vacation settings and messages of mailboxes
+type GmailService interface {
+	GetVacation(userID string) (*VacationSettings, error)
+	UpdateVacation(userID string, v *VacationSettings) (*VacationSettings, error)
+	SendMessage(userID string, raw []byte) (*GmailMessage, error)
+}
+
+// GroupService manages the members of groups
+type GroupService interface {
+	ListMembers(groupKey string) (*Members, error)
+	IterMembers(groupKey string) iterator.Seq[*Member]
+	AddMember(groupKey string, email string, role string) (*Member, error)
+	RemoveMember(groupKey string, memberKey string) error
+}
+
+// IAMService lists service accounts and their keys
+type IAMService interface {
+	ListServiceAccounts(projectID string) ([]*IAMServiceAccount, error)
+	ListServiceAccountKeys(email string) ([]*ServiceAccountKey, error)
+}
+
+// LicensingService lists license assignments
+type LicensingService interface {
+	ListAssignments(customerID string, productID string, skuID string) ([]*LicenseAssignment, error)
+}
+
+// PermissionService manages the permissions of Drive files
+type PermissionService interface {
+	GetPermissionList(driveID string) (*PermissionList, error)
+	GetPermissionDetails(driveID string, permissionID string) (*Permission, error)
+	TransferOwnership(driveID string, newOwner string) (*Permission, error)
+}
+
+// SheetsService reads and writes spreadsheets
+type SheetsService interface {
+	VerifySheetValueRange(vr *ValueRange) error
+	GenerateValueRange(data []interface{}, sheetName string, headers *[]string) *ValueRange
+	CreateSpreadsheet(s *Spreadsheet) (*Spreadsheet, error)
+	UpdateSpreadsheet(spreadsheetID string, vr *ValueRange) error
+	AppendSpreadsheet(spreadsheetID string, vr *ValueRange) error
+	FormatHeaderAndAutoSize(spreadsheetID string, sheet *Sheet, rows, columns int) error
+	SaveToSheet(data interface{}, sheetID, sheetName string, headers *[]string) error
+	GetSpreadsheet(sheetID string) (*Spreadsheet, error)
+	ReadSpreadsheetValues(sheetID, rangeNotation string) (*ValueRange, error)
+}
+
+// UserService manages users
+type UserService interface {
+	ListAllUsers() (*Users, error)
+	IterUsers(q *UserQuery) iterator.Seq[*User]
+	IterUsersChangedSince(since time.Time) iterator.Seq[*User]
+	SearchUsers(q *UserQuery) (*Users, error)
+	GetUser(userKey string) (*User, error)
+	CreateUser(fields map[string]interface{}) (*User, error)
+	UpdateUser(userKey string, fields map[string]interface{}) (*User, error)
+	SuspendUser(userKey string) (*User, error)
+	MoveUserToOU(userKey string, orgUnitPath string) (*User, error)
+}
+
+var (
+	_ AdminService        = (*AdminClient)(nil)
+	_ CalendarService     = (*CalendarClient)(nil)
+	_ DataTransferService = (*DataTransferClient)(nil)
+	_ DeviceService       = (*DeviceClient)(nil)
+	_ DriveService        = (*DriveClient)(nil)
+	_ GmailService        = (*GmailClient)(nil)
+	_ GroupService        = (*GroupsClient)(nil)
+	_ IAMService          = (*IAMClient)(nil)
+	_ LicensingService    = (*LicensingClient)(nil)
+	_ PermissionService   = (*PermissionsClient)(nil)
+	_ SheetsService       = (*SheetsClient)(nil)
+	_ UserService         = (*UsersClient)(nil)
+)
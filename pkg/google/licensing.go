@@ -0,0 +1,98 @@
+/*
+# Google Workspace - Licensing
+
+This package implements logic related to the Enterprise License Manager API:
+https://developers.google.com/admin-sdk/licensing/reference/rest
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/google/licensing.go
+package google
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+var (
+	LicensingBaseURL  = "https://licensing.googleapis.com/apps/licensing/v1"                    // https://developers.google.com/admin-sdk/licensing/reference/rest
+	LicensingProducts = fmt.Sprintf("%s/product/%s/users", LicensingBaseURL, "%s")              // https://developers.google.com/admin-sdk/licensing/reference/rest/v1/licenseAssignments/listForProduct
+	LicensingSKUs     = fmt.Sprintf("%s/product/%s/sku/%s/users", LicensingBaseURL, "%s", "%s") // https://developers.google.com/admin-sdk/licensing/reference/rest/v1/licenseAssignments/listForProductAndSku
+)
+
+// Product IDs of common Google Workspace subscriptions
+// https://developers.google.com/admin-sdk/licensing/v1/how-tos/products
+const (
+	ProductWorkspace     = "Google-Apps"  // Google Workspace editions
+	ProductVault         = "Google-Vault" // Google Vault
+	ProductVoice         = "101033"       // Google Voice
+	ProductCloudIdentity = "101001"       // Cloud Identity Premium
+)
+
+// LicensingClient for chaining methods
+type LicensingClient struct {
+	*Client
+}
+
+// Entry point for licensing-related operations
+func (c *Client) Licensing() *LicensingClient {
+	return &LicensingClient{
+		Client: c,
+	}
+}
+
+/*
+ * Query Parameters for License Assignments
+ * https://developers.google.com/admin-sdk/licensing/reference/rest/v1/licenseAssignments/listForProduct#query-parameters
+ */
+type LicenseQuery struct {
+	CustomerID string `url:"customerId"`           // The customer's primary domain name or unique identifier
+	MaxResults int    `url:"maxResults,omitempty"` // Maximum number of results to return. Max allowed value is 1000.
+	PageToken  string `url:"pageToken,omitempty"`  // Token to specify next page in the list.
+}
+
+/*
+ * Lists the users assigned licenses of a product, or of a single SKU of it when `skuID` is set
+ * - `customerID` is the primary domain or the customer ID; `my_customer` is not accepted by this API
+ * /apps/licensing/v1/product/{productId}/users
+ * https://developers.google.com/admin-sdk/licensing/reference/rest/v1/licenseAssignments/listForProduct
+ */
+func (c *LicensingClient) ListAssignments(customerID string, productID string, skuID string) ([]*LicenseAssignment, error) {
+	url := fmt.Sprintf(LicensingProducts, requests.EscapePathSegment(productID))
+	if skuID != "" {
+		url = fmt.Sprintf(LicensingSKUs, requests.EscapePathSegment(productID), requests.EscapePathSegment(skuID))
+	}
+	c.Log.Debug("url:", url)
+
+	var cache []*LicenseAssignment
+	if c.GetCache(url, &cache) {
+		return cache, nil
+	}
+
+	q := LicenseQuery{
+		CustomerID: customerID,
+		MaxResults: 1000,
+	}
+
+	assignments := []*LicenseAssignment{}
+	for {
+		page, err := do[LicenseAssignments](c.Client, "GET", url, q, nil)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, page.Items...)
+
+		if page.NextPageToken == "" {
+			break
+		}
+		q.PageToken = page.NextPageToken
+	}
+
+	c.SetCache(url, assignments, 30*time.Minute)
+	return assignments, nil
+}
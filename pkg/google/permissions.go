@@ -27,12 +27,6 @@ func (c *Client) Permissions() *PermissionsClient {
 		Client: c,
 	}
 
-	// https://developers.google.com/drive/api/guides/limits
-	pc.HTTP.RateLimiter.Available = 12000
-	pc.HTTP.RateLimiter.Limit = 12000
-	pc.HTTP.RateLimiter.Interval = 1 * time.Minute
-	pc.HTTP.RateLimiter.Log.Verbosity = c.Log.Verbosity
-
 	return pc
 }
 
@@ -17,6 +17,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/gemini-oss/rego/pkg/common/requests"
 	ss "github.com/gemini-oss/rego/pkg/common/starstruct"
 )
 
@@ -41,12 +42,6 @@ func (c *Client) Sheets() *SheetsClient {
 		Client: c,
 	}
 
-	// https://developers.google.com/sheets/api/limits
-	sc.HTTP.RateLimiter.Available = 60
-	sc.HTTP.RateLimiter.Limit = 60
-	sc.HTTP.RateLimiter.Interval = 1 * time.Minute
-	sc.HTTP.RateLimiter.Log.Verbosity = c.Log.Verbosity
-
 	return sc
 }
 
@@ -167,7 +162,7 @@ func (c *SheetsClient) UpdateSpreadsheet(spreadsheetID string, vr *ValueRange) e
 		return err
 	}
 
-	url := fmt.Sprintf("%s/%s/values/%s", Sheets, spreadsheetID, vr.Range)
+	url := requests.JoinURL(Sheets, spreadsheetID, "values", vr.Range)
 
 	_, err = do[any](c.Client, "PUT", url, q, &vr)
 	if err != nil {
@@ -195,7 +190,7 @@ func (c *SheetsClient) AppendSpreadsheet(spreadsheetID string, vr *ValueRange) e
 		return err
 	}
 
-	url := fmt.Sprintf("%s/%s/values/%s:append", Sheets, spreadsheetID, vr.Range)
+	url := requests.JoinURL(Sheets, spreadsheetID, "values", vr.Range) + ":append"
 
 	_, err = do[any](c.Client, "POST", url, q, &vr)
 	if err != nil {
@@ -210,7 +205,7 @@ func (c *SheetsClient) AppendSpreadsheet(spreadsheetID string, vr *ValueRange) e
  * - Sets the header row to bold and green, and auto-sizes all columns
  */
 func (c *SheetsClient) FormatHeaderAndAutoSize(spreadsheetID string, sheet *Sheet, rows, columns int) error {
-	url := fmt.Sprintf("%s/%s:batchUpdate", Sheets, spreadsheetID)
+	url := requests.JoinURL(Sheets, spreadsheetID) + ":batchUpdate"
 
 	format := &SheetBatchRequest{}
 
@@ -390,7 +385,7 @@ func (c *SheetsClient) prepareAndGenerateValueRange(val reflect.Value, sheetName
  * https://developers.google.com/sheets/api/reference/rest/v4/spreadsheets/get
  */
 func (c *SheetsClient) GetSpreadsheet(sheetID string) (*Spreadsheet, error) {
-	url := fmt.Sprintf(SheetByID, sheetID)
+	url := fmt.Sprintf(SheetByID, requests.EscapePathSegment(sheetID))
 
 	q := SheetValueQuery{
 		IncludeGridData: false,
@@ -421,7 +416,7 @@ func (c *SheetsClient) ReadSpreadsheetValues(sheetID, rangeNotation string) (*Va
 		ValueRenderOption: "FORMATTED_VALUE",
 	}
 
-	url := fmt.Sprintf("%s/%s/values/%s", Sheets, sheetID, rangeNotation)
+	url := requests.JoinURL(Sheets, sheetID, "values", rangeNotation)
 
 	vr, err := do[ValueRange](c.Client, "GET", url, q, nil)
 	if err != nil {
@@ -13,8 +13,16 @@ https://developers.google.com/admin-sdk/directory/reference/rest/v1/users
 package google
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/pool"
+	"github.com/gemini-oss/rego/pkg/common/requests"
 )
 
 // UsersClient for chaining methods
@@ -108,38 +116,137 @@ func (c *UsersClient) ListAllUsers() (*Users, error) {
 		return &cache, nil
 	}
 
-	q := UserQuery{}
+	q := &UserQuery{
+		MaxResults: 500,
+		Projection: BASIC,
+	}
 
-	err := q.ValidateQuery()
+	items, err := iterator.Collect(c.IterUsers(q))
 	if err != nil {
 		return nil, err
 	}
-	q.MaxResults = 500
-	q.Projection = BASIC
+	users := Users{Users: items}
 
-	users, err := do[Users](c.Client, "GET", url, q, nil)
-	if err != nil {
-		return nil, err
+	c.SetCache(url, users, 30*time.Minute)
+	return &users, nil
+}
+
+/*
+ * Iterate the users matching a query
+ * - Pages are requested as the users are consumed, so callers can stop early; results are not cached
+ * /admin/directory/v1/users
+ * https://developers.google.com/admin-sdk/directory/reference/rest/v1/users/list
+ */
+func (c *UsersClient) IterUsers(q *UserQuery) iterator.Seq[*User] {
+	if q == nil {
+		q = &UserQuery{}
+	}
+	query := *q
+	if err := query.ValidateQuery(); err != nil {
+		return iterator.Error[*User](err)
 	}
 
-	for users.NextPageToken != "" {
-		q = UserQuery{
-			Customer:   q.Customer,
-			Domain:     q.Domain,
-			MaxResults: 500,
-			PageToken:  users.NextPageToken,
+	return iterator.Pages(func(pageToken string) ([]*User, string, error) {
+		query.PageToken = pageToken
+		page, err := do[Users](c.Client, "GET", DirectoryUsers, query, nil)
+		if err != nil {
+			return nil, "", err
 		}
+		return page.Users, page.NextPageToken, nil
+	})
+}
 
-		usersPage, err := do[Users](c.Client, "GET", url, q, nil)
-		if err != nil {
-			return nil, err
+/*
+ * # Iterate users in parallel, by organizational unit
+ * Opt-in for very large tenants: the users of each OU of `paths`, e.g. the top-level OUs, are paginated on the next free
+ * worker of `opts`
+ * - Each OU includes its sub-OUs; a user under several of the paths is yielded once, by the deepest of them
+ * - Users outside every path are not listed, e.g. those directly in the root OU unless "/" is one of the paths
+ * - Workers hold back while the client's rate limiter is throttling, unless `opts` sets its own
+ * /admin/directory/v1/users
+ * https://developers.google.com/admin-sdk/directory/v1/guides/search-users
+ */
+func (c *UsersClient) IterUsersByOU(ctx context.Context, paths []OrgUnitPath, opts pool.Options) iterator.Seq[*User] {
+	partitions := []iterator.Seq[*User]{}
+	for _, path := range paths {
+		q := &UserQuery{MaxResults: 500, Projection: BASIC}
+		if path != ROOT_OU {
+			q.Query = fmt.Sprintf("orgUnitPath='%s'", strings.ReplaceAll(string(path), "'", "\\'"))
 		}
-		users.Users = append(users.Users, usersPage.Users...)
-		users.NextPageToken = usersPage.NextPageToken
+		partitions = append(partitions, iterator.Filter(c.IterUsers(q), func(u *User) bool {
+			return deepestOU(paths, u.OrgUnitPath) == path
+		}))
 	}
 
-	c.SetCache(url, users, 30*time.Minute)
-	return &users, nil
+	if opts.RateLimiter == nil {
+		opts.RateLimiter = c.HTTP.RateLimiter
+	}
+	return pool.Merge(ctx, partitions, opts)
+}
+
+// deepestOU returns the deepest of `paths` which contains `ou`, or "" if there is none
+func deepestOU(paths []OrgUnitPath, ou OrgUnitPath) OrgUnitPath {
+	deepest := OrgUnitPath("")
+	for _, path := range paths {
+		if path.Contains(ou) && len(path) > len(deepest) {
+			deepest = path
+		}
+	}
+	return deepest
+}
+
+/*
+ * # Iterate users changed since a time
+ * - The Directory API cannot search users by their update time, so the users are those named by the `USER_SETTINGS`
+ *   events of the Admin audit log (`admin` activities) since `since`, each read once; users deleted since are skipped
+ *   without being read
+ * - Every user when `since` is zero, for delta syncs (see `checkpoint.Delta`)
+ * /admin/reports/v1/activity/users/all/applications/admin
+ * https://developers.google.com/admin-sdk/reports/v1/appendix/activity/admin-user-settings
+ */
+func (c *UsersClient) IterUsersChangedSince(since time.Time) iterator.Seq[*User] {
+	if since.IsZero() {
+		return c.IterUsers(nil)
+	}
+
+	q := ReportsQuery{StartTime: since.UTC().Format(time.RFC3339Nano)}
+	activities := c.Admin().IterActivities("admin", q)
+	return func(yield func(*User, error) bool) {
+		seen := map[string]bool{}
+		emails := []string{}
+		err := iterator.ForEach(activities, func(activity Report) error {
+			for _, event := range activity.Events {
+				if event.Type != "USER_SETTINGS" {
+					continue
+				}
+				for _, p := range event.Parameters {
+					if p.Name != "USER_EMAIL" || p.Value == "" || seen[p.Value] {
+						continue
+					}
+					// Activities are listed newest first, so a user whose latest event is a deletion no longer exists
+					seen[p.Value] = true
+					if event.Name != "DELETE_USER" {
+						emails = append(emails, p.Value)
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for _, email := range emails {
+			user, err := c.GetUser(email)
+			if errors.Is(err, rerrors.ErrNotFound) {
+				continue
+			}
+			if !yield(user, err) || err != nil {
+				return
+			}
+		}
+	}
 }
 
 /*
@@ -170,7 +277,7 @@ func (c *UsersClient) SearchUsers(q *UserQuery) (*Users, error) {
  * https://developers.google.com/admin-sdk/directory/v1/reference/users/get
  */
 func (c *UsersClient) GetUser(userKey string) (*User, error) {
-	url := fmt.Sprintf(DirectoryUsers+"/%s", userKey)
+	url := requests.JoinURL(DirectoryUsers, userKey)
 	c.Log.Debug("url:", url)
 
 	user, err := do[User](c.Client, "GET", url, nil, nil)
@@ -180,3 +287,60 @@ func (c *UsersClient) GetUser(userKey string) (*User, error) {
 
 	return &user, nil
 }
+
+/*
+ * Creates a User
+ * - `fields` must include at least `primaryEmail`, `name` and `password`
+ * /admin/directory/v1/users
+ * https://developers.google.com/admin-sdk/directory/reference/rest/v1/users/insert
+ */
+func (c *UsersClient) CreateUser(fields map[string]interface{}) (*User, error) {
+	url := DirectoryUsers
+	c.Log.Debug("url:", url)
+
+	c.Log.Printf("Creating Google user %v", fields["primaryEmail"])
+	user, err := do[User](c.Client, "POST", url, nil, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+/*
+ * Updates a User's Profile
+ * - Only the fields present in `fields` are modified
+ * /admin/directory/v1/users/{userKey}
+ * https://developers.google.com/admin-sdk/directory/reference/rest/v1/users/patch
+ */
+func (c *UsersClient) UpdateUser(userKey string, fields map[string]interface{}) (*User, error) {
+	url := requests.JoinURL(DirectoryUsers, userKey)
+	c.Log.Debug("url:", url)
+
+	user, err := do[User](c.Client, "PATCH", url, nil, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+/*
+ * Suspends a User
+ * /admin/directory/v1/users/{userKey}
+ * https://developers.google.com/admin-sdk/directory/reference/rest/v1/users/patch
+ */
+func (c *UsersClient) SuspendUser(userKey string) (*User, error) {
+	c.Log.Printf("Suspending Google user %s", userKey)
+	return c.UpdateUser(userKey, map[string]interface{}{"suspended": true})
+}
+
+/*
+ * Moves a User to a different Organizational Unit
+ * /admin/directory/v1/users/{userKey}
+ * https://developers.google.com/admin-sdk/directory/reference/rest/v1/users/patch
+ */
+func (c *UsersClient) MoveUserToOU(userKey string, orgUnitPath string) (*User, error) {
+	c.Log.Printf("Moving Google user %s to %s", userKey, orgUnitPath)
+	return c.UpdateUser(userKey, map[string]interface{}{"orgUnitPath": orgUnitPath})
+}
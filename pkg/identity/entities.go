@@ -0,0 +1,74 @@
+/*
+# Identity - Entities [Structs]
+
+This package contains the structs used to correlate user accounts across providers:
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/identity/entities.go
+package identity
+
+// ### Identity Structs
+// ---------------------------------------------------------------------
+
+// Source is the provider a record was collected from
+type Source string
+
+const (
+	ActiveDirectory Source = "active_directory"
+	Google          Source = "google"
+	Jamf            Source = "jamf"
+	Okta            Source = "okta"
+	Slack           Source = "slack"
+	SnipeIT         Source = "snipeit"
+)
+
+// Attributes compared across sources when looking for mismatches
+const (
+	AttrFirstName  = "first_name"  // Given name
+	AttrLastName   = "last_name"   // Family name
+	AttrTitle      = "title"       // Job title
+	AttrDepartment = "department"  // Department
+	AttrEmployeeID = "employee_id" // Employee ID/number from the HR system
+	AttrStatus     = "status"      // Normalized account status {active, inactive}
+)
+
+const (
+	StatusActive   = "active"   // The account can be used to sign in
+	StatusInactive = "inactive" // The account is suspended, deactivated or deleted
+)
+
+// Record is a single user account in a single provider
+type Record struct {
+	Source     Source            `json:"source"`               // Provider the record was collected from
+	ID         string            `json:"id"`                   // Identifier of the record in its provider
+	Email      string            `json:"email,omitempty"`      // Primary email address
+	Aliases    []string          `json:"aliases,omitempty"`    // Additional email addresses (aliases, UPNs, secondary addresses)
+	EmployeeID string            `json:"employeeId,omitempty"` // Employee ID/number, if the provider stores one
+	Username   string            `json:"username,omitempty"`   // Provider-specific username, e.g. `sAMAccountName`
+	Attributes map[string]string `json:"attributes,omitempty"` // Normalized attributes, keyed by the `Attr*` constants
+	Raw        interface{}       `json:"-"`                    // The original provider object
+}
+
+// Identity is a person, joined across every provider they have an account in
+type Identity struct {
+	Email      string               `json:"email"`                // Primary email address, taken from the IdP when available
+	EmployeeID string               `json:"employeeId,omitempty"` // Employee ID/number, taken from the IdP when available
+	Records    map[Source][]*Record `json:"records"`              // Records for this identity, grouped by source
+	Orphan     bool                 `json:"orphan"`               // True if the identity has no record in the IdP
+	Mismatches []*AttributeMismatch `json:"mismatches,omitempty"` // Attributes which disagree across sources
+}
+
+// AttributeMismatch is an attribute with more than one distinct value across an identity's records
+type AttributeMismatch struct {
+	Attribute string            `json:"attribute"` // Name of the attribute, e.g. `title`
+	Values    map[Source]string `json:"values"`    // Value of the attribute in each source that has it
+}
+
+type Identities []*Identity
+
+// END OF IDENTITY STRUCTS
+//---------------------------------------------------------------------
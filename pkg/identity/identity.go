@@ -0,0 +1,289 @@
+/*
+# Identity
+
+This package correlates user accounts across providers (Okta, Google, Active Directory, Jamf, Slack, Snipe-IT, ...)
+into a single Identity per person. Records are joined on primary email, email aliases and employee ID, and each
+Identity is flagged when it has no record in the identity provider (an orphan) or when its records disagree.
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/identity/identity.go
+package identity
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Correlator joins records from multiple sources into identities
+type Correlator struct {
+	IdP        Source   // The authoritative identity provider; identities without a record from it are orphans
+	Attributes []string // Attributes compared across sources; defaults to every `Attr*` attribute
+	records    []*Record
+}
+
+/*
+  - # Generate an Identity Correlator
+  - @param idp Source
+  - @return *Correlator
+  - Example:
+
+```go
+
+	c := identity.NewCorrelator(identity.Okta)
+	c.Add(identity.FromOkta(oktaUsers)...)
+	c.Add(identity.FromGoogle(googleUsers.Users)...)
+	for _, orphan := range c.Correlate().Orphans() {
+		fmt.Println(orphan.Email, orphan.Sources())
+	}
+
+```
+*/
+func NewCorrelator(idp Source) *Correlator {
+	return &Correlator{
+		IdP: idp,
+		Attributes: []string{
+			AttrFirstName,
+			AttrLastName,
+			AttrTitle,
+			AttrDepartment,
+			AttrEmployeeID,
+			AttrStatus,
+		},
+	}
+}
+
+// Add records to the correlator; records without an email or employee ID cannot be joined and are ignored
+func (c *Correlator) Add(records ...*Record) {
+	for _, r := range records {
+		if r == nil || len(r.keys()) == 0 {
+			continue
+		}
+		c.records = append(c.records, r)
+	}
+}
+
+/*
+ * # Correlate all added records into identities
+ * - Two records belong to the same identity if they share an email address (primary or alias) or an employee ID
+ * - Joins are transitive, e.g. an AD record matching Okta by employee ID and Slack by email joins all three
+ */
+func (c *Correlator) Correlate() Identities {
+	// Union-find over the records, keyed by every email/employee ID they carry
+	parent := make([]int, len(c.records))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	owners := map[string]int{}
+	for i, r := range c.records {
+		for _, key := range r.keys() {
+			if j, ok := owners[key]; ok {
+				parent[find(i)] = find(j)
+			} else {
+				owners[key] = i
+			}
+		}
+	}
+
+	groups := map[int][]*Record{}
+	roots := []int{}
+	for i, r := range c.records {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], r)
+	}
+
+	identities := Identities{}
+	for _, root := range roots {
+		identities = append(identities, c.newIdentity(groups[root]))
+	}
+
+	sort.SliceStable(identities, func(i, j int) bool {
+		return identities[i].Email < identities[j].Email
+	})
+
+	return identities
+}
+
+func (c *Correlator) newIdentity(records []*Record) *Identity {
+	id := &Identity{
+		Records: map[Source][]*Record{},
+	}
+	for _, r := range records {
+		id.Records[r.Source] = append(id.Records[r.Source], r)
+	}
+
+	// Prefer the IdP's view of the person, falling back to the first record with a value
+	ordered := records
+	if idp, ok := id.Records[c.IdP]; ok {
+		ordered = append(slices.Clone(idp), records...)
+	} else {
+		id.Orphan = true
+	}
+	for _, r := range ordered {
+		if id.Email == "" && r.Email != "" {
+			id.Email = normalizeEmail(r.Email)
+		}
+		if id.EmployeeID == "" && r.EmployeeID != "" {
+			id.EmployeeID = r.EmployeeID
+		}
+	}
+
+	id.Mismatches = c.mismatches(id)
+	return id
+}
+
+// mismatches compares each attribute across the sources of an identity
+func (c *Correlator) mismatches(id *Identity) []*AttributeMismatch {
+	mismatches := []*AttributeMismatch{}
+
+	for _, attr := range c.Attributes {
+		values := map[Source]string{}
+		distinct := map[string]struct{}{}
+
+		for _, source := range id.Sources() {
+			for _, r := range id.Records[source] {
+				v := r.Attribute(attr)
+				if v == "" {
+					continue
+				}
+				values[source] = v
+				if attr == AttrEmployeeID {
+					distinct[normalizeEmployeeID(v)] = struct{}{}
+				} else {
+					distinct[normalizeValue(v)] = struct{}{}
+				}
+				break
+			}
+		}
+
+		if len(distinct) > 1 {
+			mismatches = append(mismatches, &AttributeMismatch{
+				Attribute: attr,
+				Values:    values,
+			})
+		}
+	}
+
+	return mismatches
+}
+
+// Attribute returns a normalized attribute of the record, including the employee ID
+func (r *Record) Attribute(name string) string {
+	if name == AttrEmployeeID && r.EmployeeID != "" {
+		return r.EmployeeID
+	}
+	return strings.TrimSpace(r.Attributes[name])
+}
+
+// keys returns the join keys of a record
+func (r *Record) keys() []string {
+	keys := []string{}
+	for _, email := range append([]string{r.Email}, r.Aliases...) {
+		if email = normalizeEmail(email); email != "" {
+			keys = append(keys, "email:"+email)
+		}
+	}
+	if id := normalizeEmployeeID(r.EmployeeID); id != "" {
+		keys = append(keys, "employee:"+id)
+	}
+	return keys
+}
+
+// Sources returns the sources an identity has records in, in alphabetical order
+func (id *Identity) Sources() []Source {
+	sources := []Source{}
+	for source := range id.Records {
+		sources = append(sources, source)
+	}
+	slices.Sort(sources)
+	return sources
+}
+
+// Has reports whether the identity has a record in the given source
+func (id *Identity) Has(source Source) bool {
+	return len(id.Records[source]) > 0
+}
+
+// Missing returns the given sources the identity has no record in
+func (id *Identity) Missing(sources ...Source) []Source {
+	missing := []Source{}
+	for _, source := range sources {
+		if !id.Has(source) {
+			missing = append(missing, source)
+		}
+	}
+	return missing
+}
+
+// Orphans returns the identities without a record in the IdP
+func (ids Identities) Orphans() Identities {
+	return ids.Filter(func(id *Identity) bool { return id.Orphan })
+}
+
+// Mismatched returns the identities whose records disagree on at least one attribute
+func (ids Identities) Mismatched() Identities {
+	return ids.Filter(func(id *Identity) bool { return len(id.Mismatches) > 0 })
+}
+
+// Filter returns the identities matching `keep`
+func (ids Identities) Filter(keep func(*Identity) bool) Identities {
+	filtered := Identities{}
+	for _, id := range ids {
+		if keep(id) {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered
+}
+
+// Find returns the identity with a record matching the email address (primary or alias), or nil
+func (ids Identities) Find(email string) *Identity {
+	key := "email:" + normalizeEmail(email)
+	for _, id := range ids {
+		for _, records := range id.Records {
+			for _, r := range records {
+				if slices.Contains(r.keys(), key) {
+					return id
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func normalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !strings.Contains(email, "@") {
+		return ""
+	}
+	return email
+}
+
+func normalizeEmployeeID(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	// Some systems zero-pad numeric IDs
+	if trimmed := strings.TrimLeft(id, "0"); trimmed != "" && strings.Trim(trimmed, "0123456789") == "" {
+		return trimmed
+	}
+	return id
+}
+
+func normalizeValue(v string) string {
+	return strings.ToLower(strings.Join(strings.Fields(v), " "))
+}
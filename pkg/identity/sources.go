@@ -0,0 +1,223 @@
+/*
+# Identity - Sources
+
+This package converts users from each supported provider into identity records:
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/identity/sources.go
+package identity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gemini-oss/rego/pkg/active_directory"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/jamf"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/slack"
+	"github.com/gemini-oss/rego/pkg/snipeit"
+)
+
+// UF_ACCOUNTDISABLE flag of `userAccountControl`
+// https://learn.microsoft.com/en-us/troubleshoot/windows-server/active-directory/useraccountcontrol-manipulate-account-properties
+const adAccountDisable = 0x2
+
+func status(active bool) string {
+	if active {
+		return StatusActive
+	}
+	return StatusInactive
+}
+
+// FromOkta converts Okta users into records
+func FromOkta(users okta.Users) []*Record {
+	records := []*Record{}
+	for _, u := range users {
+		if u == nil || u.Profile == nil {
+			continue
+		}
+
+		aliases := append([]string{u.Profile.Login, u.Profile.SecondEmail}, u.Profile.Aliases...)
+		records = append(records, &Record{
+			Source:     Okta,
+			ID:         u.ID,
+			Email:      u.Profile.Email,
+			Aliases:    aliases,
+			EmployeeID: u.Profile.EmployeeNumber,
+			Username:   u.Profile.Login,
+			Attributes: map[string]string{
+				AttrFirstName:  u.Profile.FirstName,
+				AttrLastName:   u.Profile.LastName,
+				AttrTitle:      u.Profile.Title,
+				AttrDepartment: u.Profile.Department,
+				AttrStatus:     status(u.Status != okta.DEPROVISIONED && u.Status != okta.SUSPENDED),
+			},
+			Raw: u,
+		})
+	}
+	return records
+}
+
+// FromGoogle converts Google Workspace users into records
+// - The employee ID is read from the `organization` external ID
+func FromGoogle(users []*google.User) []*Record {
+	records := []*Record{}
+	for _, u := range users {
+		if u == nil {
+			continue
+		}
+
+		aliases := append([]string{}, u.Aliases...)
+		aliases = append(aliases, u.NonEditableAliases...)
+		for _, e := range u.Emails {
+			aliases = append(aliases, e.Address)
+		}
+
+		employeeID := ""
+		for _, ext := range u.ExternalIds {
+			if ext.Type == "organization" {
+				employeeID = ext.Value
+				break
+			}
+		}
+
+		title, department := "", ""
+		for _, org := range u.Organizations {
+			if org.Primary || (title == "" && department == "") {
+				title, department = org.Title, org.Department
+			}
+		}
+
+		records = append(records, &Record{
+			Source:     Google,
+			ID:         u.ID,
+			Email:      u.PrimaryEmail,
+			Aliases:    aliases,
+			EmployeeID: employeeID,
+			Attributes: map[string]string{
+				AttrFirstName:  u.Name.GivenName,
+				AttrLastName:   u.Name.FamilyName,
+				AttrTitle:      title,
+				AttrDepartment: department,
+				AttrStatus:     status(!u.Suspended && !u.Archived),
+			},
+			Raw: u,
+		})
+	}
+	return records
+}
+
+// FromActiveDirectory converts Active Directory users into records
+// - `employeeID` is preferred over `employeeNumber`, and the UPN is treated as an alias
+func FromActiveDirectory(users active_directory.Users) []*Record {
+	records := []*Record{}
+	for _, u := range users {
+		if u == nil {
+			continue
+		}
+
+		employeeID := u.EmployeeID
+		if employeeID == "" {
+			employeeID = u.EmployeeNumber
+		}
+
+		records = append(records, &Record{
+			Source:     ActiveDirectory,
+			ID:         u.DistinguishedName,
+			Email:      u.Mail,
+			Aliases:    []string{u.UserPrincipalName},
+			EmployeeID: employeeID,
+			Username:   u.SAMAccountName,
+			Attributes: map[string]string{
+				AttrFirstName:  u.GivenName,
+				AttrLastName:   u.SN,
+				AttrTitle:      u.Title,
+				AttrDepartment: u.Department,
+				AttrStatus:     status(u.UserAccountControl&adAccountDisable == 0),
+			},
+			Raw: u,
+		})
+	}
+	return records
+}
+
+// FromJamf converts the assigned users of Jamf computers into records, one per computer
+// - Requires the `USER_AND_LOCATION` section of the computer inventory
+func FromJamf(computers []*jamf.Computer) []*Record {
+	records := []*Record{}
+	for _, c := range computers {
+		if c == nil || c.UserAndLocation == nil {
+			continue
+		}
+
+		records = append(records, &Record{
+			Source:   Jamf,
+			ID:       fmt.Sprint(c.ID),
+			Email:    c.UserAndLocation.Email,
+			Username: c.UserAndLocation.Username,
+			Attributes: map[string]string{
+				AttrTitle: c.UserAndLocation.Position,
+			},
+			Raw: c,
+		})
+	}
+	return records
+}
+
+// FromSlack converts Slack members into records; bots and app users are skipped
+func FromSlack(members []*slack.Member) []*Record {
+	records := []*Record{}
+	for _, m := range members {
+		if m == nil || m.IsBot || m.IsAppUser || m.ID == "USLACKBOT" {
+			continue
+		}
+
+		records = append(records, &Record{
+			Source:   Slack,
+			ID:       m.ID,
+			Email:    m.Profile.Email,
+			Username: m.Name,
+			Attributes: map[string]string{
+				AttrFirstName: m.Profile.FirstName,
+				AttrLastName:  m.Profile.LastName,
+				AttrTitle:     m.Profile.Title,
+				AttrStatus:    status(!m.Deleted),
+			},
+			Raw: m,
+		})
+	}
+	return records
+}
+
+// FromSnipeIT converts Snipe-IT users into records
+func FromSnipeIT(users []*snipeit.User) []*Record {
+	records := []*Record{}
+	for _, u := range users {
+		if u == nil {
+			continue
+		}
+
+		records = append(records, &Record{
+			Source:     SnipeIT,
+			ID:         strconv.FormatInt(u.ID, 10),
+			Email:      u.Email,
+			EmployeeID: u.EmployeeNum,
+			Username:   u.Username,
+			Attributes: map[string]string{
+				AttrFirstName:  u.FirstName,
+				AttrLastName:   u.LastName,
+				AttrTitle:      u.Jobtitle,
+				AttrDepartment: strings.TrimSpace(u.Department.Name),
+				AttrStatus:     status(u.Activated),
+			},
+			Raw: u,
+		})
+	}
+	return records
+}
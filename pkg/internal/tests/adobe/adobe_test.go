@@ -0,0 +1,149 @@
+/*
+# Adobe - Test
+
+This package runs tests for functions which interact with the Adobe User Management API:
+https://adobe-apiplatform.github.io/umapi-documentation/en/
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/internal/tests/adobe/adobe_test.go
+package adobe_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/adobe"
+	"github.com/gemini-oss/rego/pkg/common/log"
+)
+
+const orgID = "12345@AdobeOrg"
+
+// setupTestServer returns a new test server and a cleanup function
+func setupTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, func()) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ims/token/v3", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"test-token","token_type":"bearer","expires_in":86399}`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("Expected bearer token, got `%s`", r.Header.Get("Authorization"))
+		}
+		if r.Header.Get("X-Api-Key") != "client-id" {
+			t.Errorf("Expected X-Api-Key `client-id`, got `%s`", r.Header.Get("X-Api-Key"))
+		}
+		handler(w, r)
+	})
+
+	server := httptest.NewServer(mux)
+	return server, func() { server.Close() }
+}
+
+// setupTestClient returns a new Adobe client pointed at the test server
+func setupTestClient(t *testing.T, serverURL string) *adobe.Client {
+	t.Setenv("ADOBE_ORG_ID", orgID)
+	t.Setenv("ADOBE_CLIENT_ID", "client-id")
+	t.Setenv("ADOBE_CLIENT_SECRET", "client-secret")
+	t.Setenv("ADOBE_IMS_URL", serverURL)
+	t.Setenv("REGO_ENCRYPTION_KEY", "8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt")
+
+	client := adobe.NewClient(log.DEBUG)
+	client.BaseURL = serverURL
+	return client
+}
+
+func TestListAllUsers(t *testing.T) {
+	server, cleanup := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/" + orgID + "/0":
+			w.Write([]byte(`{"lastPage":false,"result":"success","users":[{"id":"1","email":"a@gemini.com","status":"active"}]}`))
+		case "/users/" + orgID + "/1":
+			w.Write([]byte(`{"lastPage":true,"result":"success","users":[{"id":"2","email":"b@gemini.com","status":"active"}]}`))
+		default:
+			t.Errorf("Unexpected path `%s`", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer cleanup()
+
+	client := setupTestClient(t, server.URL)
+
+	users, err := client.Users().ListAllUsers()
+	if err != nil {
+		t.Fatalf("ListAllUsers() error = %v", err)
+	}
+	if len(*users) != 2 {
+		t.Fatalf("ListAllUsers() returned %d users, want 2", len(*users))
+	}
+	if (*users)[1].Email != "b@gemini.com" {
+		t.Errorf("ListAllUsers() second user = %s, want b@gemini.com", (*users)[1].Email)
+	}
+}
+
+func TestOffboardUser(t *testing.T) {
+	server, cleanup := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/organizations/" + orgID + "/users/a@gemini.com":
+			w.Write([]byte(`{"result":"success","user":{"email":"a@gemini.com","groups":["Acrobat Pro","Creative Cloud"]}}`))
+		case "/action/" + orgID:
+			body, _ := io.ReadAll(r.Body)
+			actions := adobe.Actions{}
+			if err := json.Unmarshal(body, &actions); err != nil {
+				t.Fatalf("Failed to decode payload: %v (%s)", err, string(body))
+			}
+			if len(actions) != 1 || len(actions[0].Do) != 2 {
+				t.Fatalf("Unexpected payload: %s", string(body))
+			}
+			if actions[0].Do[0].Remove == nil || len(actions[0].Do[0].Remove.Group) != 2 {
+				t.Errorf("Expected first command to remove 2 groups, got %s", string(body))
+			}
+			if actions[0].Do[1].RemoveFromOrg == nil {
+				t.Errorf("Expected second command to remove from org, got %s", string(body))
+			}
+			w.Write([]byte(`{"completed":1,"notCompleted":0,"completedInTestMode":0,"result":"success"}`))
+		default:
+			t.Errorf("Unexpected path `%s`", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer cleanup()
+
+	client := setupTestClient(t, server.URL)
+
+	if err := client.Users().OffboardUser("a@gemini.com"); err != nil {
+		t.Errorf("OffboardUser() error = %v", err)
+	}
+}
+
+func TestLicenseReport(t *testing.T) {
+	server, cleanup := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"lastPage":true,"result":"success","groups":[
+			{"groupId":1,"groupName":"Acrobat Pro","type":"PRODUCT_PROFILE","productName":"Acrobat Pro DC","licenseQuota":"10","memberCount":7},
+			{"groupId":2,"groupName":"Creative Cloud","type":"PRODUCT_PROFILE","productName":"Creative Cloud All Apps","licenseQuota":"UNLIMITED","memberCount":3},
+			{"groupId":3,"groupName":"Design","type":"USER_GROUP","memberCount":4}
+		]}`))
+	})
+	defer cleanup()
+
+	client := setupTestClient(t, server.URL)
+
+	report, err := client.Groups().LicenseReport()
+	if err != nil {
+		t.Fatalf("LicenseReport() error = %v", err)
+	}
+	if len(*report) != 2 {
+		t.Fatalf("LicenseReport() returned %d profiles, want 2", len(*report))
+	}
+	if (*report)[0].Available != 3 {
+		t.Errorf("LicenseReport() Acrobat available = %d, want 3", (*report)[0].Available)
+	}
+	if (*report)[1].Quota != -1 {
+		t.Errorf("LicenseReport() Creative Cloud quota = %d, want -1", (*report)[1].Quota)
+	}
+}
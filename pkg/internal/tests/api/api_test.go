@@ -0,0 +1,114 @@
+// pkg/internal/tests/api/api_test.go
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/api"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/orchestrators"
+)
+
+const (
+	readToken     = "read-token-0123456789abcdefghijklmnop"
+	offboardToken = "offboard-token-0123456789abcdefghijklm"
+)
+
+// setupServer returns a server without any service clients
+func setupServer(t *testing.T) *api.Server {
+	t.Helper()
+	c := &orchestrators.Client{Log: log.NewLogger("{orchestrators}", log.ERROR)}
+	s := api.NewServer(":0", c, log.ERROR)
+	if err := s.ParseTokens("reader:" + readToken + ":read ; desk:" + offboardToken + ":read,offboard"); err != nil {
+		t.Fatalf("ParseTokens: %v", err)
+	}
+	return s
+}
+
+func request(s *api.Server, method, path, token, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func TestAuthentication(t *testing.T) {
+	s := setupServer(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"health is public", "GET", "/v1/health", "", http.StatusOK},
+		{"missing token", "GET", "/v1/okta/users", "", http.StatusUnauthorized},
+		{"invalid token", "GET", "/v1/okta/users", "not-a-token", http.StatusUnauthorized},
+		{"service not configured", "GET", "/v1/okta/users", readToken, http.StatusNotImplemented},
+		{"missing scope", "POST", "/v1/offboard", readToken, http.StatusForbidden},
+		{"missing reports scope", "GET", "/v1/reports/access-review", offboardToken, http.StatusForbidden},
+		{"wrong method", "DELETE", "/v1/okta/users", readToken, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := request(s, tt.method, tt.path, tt.token, "{}"); w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestParseTokens(t *testing.T) {
+	s := setupServer(t)
+	for _, spec := range []string{
+		"reader:" + readToken,
+		"reader:" + readToken + ":admin",
+		"reader:short:read",
+		":" + readToken + ":read",
+	} {
+		if err := s.ParseTokens(spec); err == nil {
+			t.Errorf("ParseTokens(%q) succeeded, want an error", spec)
+		}
+	}
+
+	// The secret runs from the first to the last separator, so it may contain one
+	secret := "colon:" + readToken
+	if err := s.ParseTokens("colons:" + secret + ":read"); err != nil {
+		t.Fatalf("ParseTokens with a colon in the secret: %v", err)
+	}
+	if w := request(s, "GET", "/v1/okta/users", secret, ""); w.Code != http.StatusNotImplemented {
+		t.Errorf("status = %d, want 501 for a secret containing a colon", w.Code)
+	}
+}
+
+func TestOffboard(t *testing.T) {
+	s := setupServer(t)
+
+	if w := request(s, "POST", "/v1/offboard", offboardToken, `{"email": "departed@example.com", "unknown": true}`); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown field: status = %d, want 400", w.Code)
+	}
+	if w := request(s, "POST", "/v1/offboard", offboardToken, `{"email": ""}`); w.Code != http.StatusBadRequest {
+		t.Errorf("missing email: status = %d, want 400", w.Code)
+	}
+
+	w := request(s, "POST", "/v1/offboard", offboardToken, `{"email": "departed@example.com", "stepsOnly": true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+
+	response := &api.WorkflowResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if !response.Succeeded || response.Report == nil || response.Report.Subject != "departed@example.com" {
+		t.Errorf("response = %+v, want a successful report for departed@example.com", response)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+# Automox - Test
+
+This package runs tests for functions which interact with the Automox API:
+https://developer.automox.com/openapi/axconsole/overview/
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/internal/tests/automox/automox_test.go
+package automox_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/automox"
+	"github.com/gemini-oss/rego/pkg/common/log"
+)
+
+// setupTestServer returns a new test server and a cleanup function
+func setupTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("o") != "42" {
+			t.Errorf("Expected org `42`, got `%s`", r.URL.Query().Get("o"))
+		}
+		handler(w, r)
+	}))
+	return server, func() { server.Close() }
+}
+
+// setupTestClient returns a new Automox client pointed at the test server
+func setupTestClient(t *testing.T, serverURL string) *automox.Client {
+	t.Setenv("AUTOMOX_BASE_URL", serverURL)
+	t.Setenv("AUTOMOX_ORG_ID", "42")
+	t.Setenv("AUTOMOX_API_KEY", "test-token")
+	t.Setenv("REGO_ENCRYPTION_KEY", "8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt")
+
+	return automox.NewClient(log.DEBUG)
+}
+
+func TestPatchStatus(t *testing.T) {
+	server, cleanup := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/servers" {
+			t.Errorf("Unexpected path `%s`", r.URL.Path)
+		}
+
+		// Serve a full first page so the client requests a second one
+		if r.URL.Query().Get("page") == "" {
+			devices := []map[string]interface{}{}
+			for i := 0; i < 500; i++ {
+				devices = append(devices, map[string]interface{}{"id": i, "compliant": true})
+			}
+			json.NewEncoder(w).Encode(devices)
+			return
+		}
+		w.Write([]byte(`[{"id":500,"name":"mbp-1","serial_number":"C02XYZ","os_name":"macOS","os_version":"14.4","compliant":false,"pending_patches":3,"needs_reboot":true}]`))
+	})
+	defer cleanup()
+
+	client := setupTestClient(t, server.URL)
+
+	statuses, err := client.Devices().PatchStatus()
+	if err != nil {
+		t.Fatalf("PatchStatus() error = %v", err)
+	}
+	if len(statuses) != 501 {
+		t.Fatalf("PatchStatus() returned %d devices, want 501", len(statuses))
+	}
+
+	last := statuses[500]
+	if last.Compliant || last.PendingPatches != 3 || !last.NeedsReboot || last.OS != "macOS 14.4" {
+		t.Errorf("PatchStatus() last device = %+v", last)
+	}
+}
+
+func TestAssignToGroup(t *testing.T) {
+	updated := false
+	server, cleanup := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`{"id":7,"name":"Patch All","policy_type_name":"patch","server_groups":[1]}`))
+		case "PUT":
+			body, _ := io.ReadAll(r.Body)
+			policy := automox.Policy{}
+			if err := json.Unmarshal(body, &policy); err != nil {
+				t.Fatalf("Failed to decode payload: %v", err)
+			}
+			if fmt.Sprint(policy.ServerGroups) != "[1 2]" {
+				t.Errorf("Expected server groups [1 2], got %v", policy.ServerGroups)
+			}
+			updated = true
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	defer cleanup()
+
+	client := setupTestClient(t, server.URL)
+
+	if err := client.Policies().AssignToGroup(7, 2); err != nil {
+		t.Fatalf("AssignToGroup() error = %v", err)
+	}
+	if !updated {
+		t.Error("AssignToGroup() did not update the policy")
+	}
+}
+
+func TestRunPolicyOnDevice(t *testing.T) {
+	server, cleanup := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/policies/7/action" {
+			t.Errorf("Unexpected path `%s`", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		action := automox.PolicyAction{}
+		json.Unmarshal(body, &action)
+		if action.Action != "remediateServer" || action.ServerID != 500 {
+			t.Errorf("Unexpected payload: %s", string(body))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer cleanup()
+
+	client := setupTestClient(t, server.URL)
+
+	if err := client.Policies().RunPolicyOnDevice(7, 500); err != nil {
+		t.Errorf("RunPolicyOnDevice() error = %v", err)
+	}
+}
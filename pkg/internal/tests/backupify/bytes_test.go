@@ -0,0 +1,97 @@
+/*
+# Backupify - Bytes - Test
+
+This package tests the parsing of the storage sizes displayed by Backupify
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/internal/tests/backupify/bytes_test.go
+package backupify_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/backupify"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		size  string
+		units backupify.ByteUnits
+		want  backupify.ByteSize
+	}{
+		{"512 bytes", backupify.Decimal, 512},
+		{"1024", backupify.Decimal, 1024},
+		{"1,024 bytes", backupify.Decimal, 1024},
+		{"12,345,678", backupify.Decimal, 12345678},
+		{"1,024.5 KB", backupify.Decimal, 1024500},
+		{"1.5 KB", backupify.Decimal, 1500},
+		{"1.5 KB", backupify.Binary, 1536},
+		{"2GB", backupify.Decimal, 2e9},
+		{" 3 mb ", backupify.Binary, 3 * 1024 * 1024},
+		{"1 GiB", backupify.Decimal, 1024 * 1024 * 1024},
+		{"1 TB", backupify.Decimal, 1e12},
+	}
+	for _, tt := range tests {
+		got, err := backupify.ParseByteSize(tt.size, tt.units)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseByteSize(%q, %d) = %v, %v; expected %v", tt.size, tt.units, got, err, tt.want)
+		}
+	}
+
+	for _, size := range []string{"", "GB", "1.5 XB", "1.2.3 KB", "1,5 GB", "1234,567", "1,0000", "1,", ",5", "1.5,0 KB", "1.5 KiBB"} {
+		if _, err := backupify.ParseByteSize(size, backupify.Decimal); !errors.Is(err, backupify.ErrInvalidSize) {
+			t.Errorf("ParseByteSize(%q) = %v; expected ErrInvalidSize", size, err)
+		}
+	}
+}
+
+func TestParseByteSizeAllocations(t *testing.T) {
+	for _, size := range []string{"1.5 GB", "1,024 bytes", "2 TiB"} {
+		allocs := testing.AllocsPerRun(100, func() {
+			if _, err := backupify.ParseByteSize(size, backupify.Binary); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("ParseByteSize(%q) allocated %v times; expected none", size, allocs)
+		}
+	}
+}
+
+func TestConvertUsedBytes(t *testing.T) {
+	users := &backupify.Users{Data: []*backupify.User{
+		{Email: "a@example.com", UsedBytes: "1.5 MB"},
+		{Email: "b@example.com", UsedBytes: "unknown"},
+		{Email: "c@example.com"},
+	}}
+
+	err := backupify.ConvertUsedBytes(users, backupify.Binary)
+	if !errors.Is(err, backupify.ErrInvalidSize) {
+		t.Errorf("Expected the invalid size to be reported, got %v", err)
+	}
+	if users.Data[0].UsedBytesFloat != 1.5*1024*1024 || users.Data[1].UsedBytesFloat != 0 {
+		t.Errorf("Unexpected sizes: %v, %v", users.Data[0].UsedBytesFloat, users.Data[1].UsedBytesFloat)
+	}
+}
+
+func BenchmarkConvertUsedBytes(b *testing.B) {
+	units := []string{"bytes", "KB", "MB", "GB", "TB"}
+	users := &backupify.Users{}
+	for i := 0; i < 100000; i++ {
+		users.Data = append(users.Data, &backupify.User{UsedBytes: fmt.Sprintf("%d.%d %s", i%1000, i%100, units[i%len(units)])})
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := backupify.ConvertUsedBytes(users, backupify.Decimal); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
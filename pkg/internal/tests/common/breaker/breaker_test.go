@@ -0,0 +1,123 @@
+// pkg/internal/tests/common/breaker/breaker_test.go
+package breaker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/breaker"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/log"
+)
+
+var errDown = errors.New("connection refused")
+
+// send allows a request to `key` and reports `err` as its outcome
+func send(b *breaker.Breaker, key string, err error) error {
+	report, allowErr := b.Allow(key)
+	if allowErr != nil {
+		return allowErr
+	}
+	report(err)
+	return nil
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker.Breaker {
+	b := breaker.New(threshold, cooldown)
+	b.Log = log.NewLogger("{breaker}", log.ERROR)
+	return b
+}
+
+func TestBreaker(t *testing.T) {
+	b := newBreaker(3, 50*time.Millisecond)
+	transitions := []breaker.State{}
+	b.OnChange = func(key string, from, to breaker.State) { transitions = append(transitions, to) }
+
+	// Successes reset the count of consecutive failures
+	for _, err := range []error{errDown, errDown, nil, errDown, errDown} {
+		if allowErr := send(b, "okta.example.com", err); allowErr != nil {
+			t.Fatalf("Allow() while closed = %v", allowErr)
+		}
+	}
+	if s := b.State("okta.example.com"); s != breaker.CLOSED {
+		t.Fatalf("State() = %s, want closed", s)
+	}
+
+	send(b, "okta.example.com", errDown)
+	if s := b.State("okta.example.com"); s != breaker.OPEN {
+		t.Fatalf("State() after 3 failures = %s, want open", s)
+	}
+	err := send(b, "okta.example.com", nil)
+	if !errors.Is(err, breaker.ErrOpen) || !errors.Is(err, rerrors.ErrUnavailable) || !rerrors.Retryable(err) {
+		t.Errorf("Allow() while open = %v, want ErrOpen and ErrUnavailable", err)
+	}
+
+	// Circuits are per key
+	if err := send(b, "admin.googleapis.com", nil); err != nil {
+		t.Errorf("Allow() of another host = %v", err)
+	}
+
+	// After the cooldown, one probe is let through at a time; a failed probe opens the circuit again
+	time.Sleep(60 * time.Millisecond)
+	if s := b.State("okta.example.com"); s != breaker.HALF_OPEN {
+		t.Fatalf("State() after the cooldown = %s, want half-open", s)
+	}
+	report, err := b.Allow("okta.example.com")
+	if err != nil {
+		t.Fatalf("Allow() of a probe = %v", err)
+	}
+	if _, err := b.Allow("okta.example.com"); !errors.Is(err, breaker.ErrOpen) {
+		t.Errorf("Allow() while probing = %v, want ErrOpen", err)
+	}
+	report(errDown)
+	if s := b.State("okta.example.com"); s != breaker.OPEN {
+		t.Fatalf("State() after a failed probe = %s, want open", s)
+	}
+
+	// A successful probe closes the circuit
+	time.Sleep(60 * time.Millisecond)
+	if err := send(b, "okta.example.com", nil); err != nil {
+		t.Fatalf("Allow() of a probe = %v", err)
+	}
+	if s := b.State("okta.example.com"); s != breaker.CLOSED {
+		t.Errorf("State() after a successful probe = %s, want closed", s)
+	}
+
+	want := []breaker.State{breaker.OPEN, breaker.HALF_OPEN, breaker.OPEN, breaker.HALF_OPEN, breaker.CLOSED}
+	if len(transitions) != len(want) {
+		t.Fatalf("OnChange() transitions = %v, want %v", transitions, want)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Errorf("OnChange() transitions = %v, want %v", transitions, want)
+			break
+		}
+	}
+}
+
+func TestBreakerIgnoresCancellations(t *testing.T) {
+	b := newBreaker(1, time.Minute)
+
+	// A cancelled request says nothing about the host
+	if err := send(b, "okta.example.com", context.Canceled); err != nil {
+		t.Fatal(err)
+	}
+	if s := b.State("okta.example.com"); s != breaker.CLOSED {
+		t.Errorf("State() after a cancellation = %s, want closed", s)
+	}
+
+	// A late outcome, of a request sent before the circuit opened, is ignored
+	report, _ := b.Allow("okta.example.com")
+	send(b, "okta.example.com", errDown)
+	report(nil)
+	if s := b.State("okta.example.com"); s != breaker.OPEN {
+		t.Errorf("State() after a late success = %s, want open", s)
+	}
+
+	b.Reset("okta.example.com")
+	if s := b.State("okta.example.com"); s != breaker.CLOSED {
+		t.Errorf("State() after Reset = %s, want closed", s)
+	}
+}
@@ -0,0 +1,175 @@
+// pkg/internal/tests/common/cache/backend_test.go
+package cache_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/testutil"
+)
+
+var backendKey = []byte("32~Byte-long_passphrase-key-1234")
+
+// backends returns a backend of each kind
+func backends(t *testing.T) map[string]cache.Backend {
+	disk, err := cache.NewDiskBackend(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	redis, err := cache.NewRedisBackend(testutil.NewRedis(t, "").URL(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { redis.Close() })
+	return map[string]cache.Backend{
+		"memory": cache.NewMemoryBackend(),
+		"disk":   disk,
+		"redis":  redis,
+	}
+}
+
+func TestBackendSharedAcrossCaches(t *testing.T) {
+	for name, backend := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			// Two caches of the same name stand for two workers, or a process before and after a restart
+			writer, err := cache.NewCache(backendKey, "rego_cache_okta.gob", backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader, err := cache.NewCache(backendKey, "rego_cache_okta.gob", backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			other, err := cache.NewCache(backendKey, "rego_cache_google.gob", backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			users := []byte(`[{"id":"00u1"}]`)
+			if err := writer.Set("users", users, time.Minute); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			got, found := reader.Get("users")
+			if !found || !bytes.Equal(got, users) {
+				t.Errorf("Get() = %q, %v; want %q, true", got, found, users)
+			}
+			if _, found := other.Get("users"); found {
+				t.Error("Get() found the item of another cache")
+			}
+
+			// Items are stored encrypted
+			item, ok, err := backend.Load(context.Background(), "okta:users")
+			if err != nil || !ok {
+				t.Fatalf("Load() = %v, %v; want the item", ok, err)
+			}
+			if strings.Contains(item.Data, "00u1") {
+				t.Error("the backend holds the item in plaintext")
+			}
+		})
+	}
+}
+
+func TestBackendExpiration(t *testing.T) {
+	for name, backend := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			c, err := cache.NewCache(backendKey, "rego_cache_expiry.gob", backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Set("key", []byte("value"), 100*time.Millisecond); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			time.Sleep(150 * time.Millisecond)
+
+			if _, found := c.Get("key"); found {
+				t.Error("Get() found an expired item")
+			}
+			if _, ok, _ := backend.Load(context.Background(), "expiry:key"); ok {
+				t.Error("the expired item was not removed from the backend")
+			}
+		})
+	}
+}
+
+func TestDiskBackendSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	open := func() *cache.Cache {
+		backend, err := cache.NewDiskBackend(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := cache.NewCache(backendKey, "rego_cache_okta.gob", backend)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	if err := open().Set("users", []byte("alice"), time.Hour); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, found := open().Get("users"); !found || string(got) != "alice" {
+		t.Errorf("Get() after reopening = %q, %v; want alice, true", got, found)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || filepath.Ext(entries[0].Name()) != ".gob" {
+		t.Errorf("the directory holds %v, want one item", entries)
+	}
+}
+
+func TestDefaultBackend(t *testing.T) {
+	backend := cache.NewMemoryBackend()
+	cache.SetDefaultBackend(backend)
+	defer cache.SetDefaultBackend(nil)
+
+	c, err := cache.NewCache(backendKey, "rego_cache_default.gob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set("key", []byte("value"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, ok, _ := backend.Load(context.Background(), "default:key"); !ok {
+		t.Error("the cache did not store its item in the default backend")
+	}
+}
+
+func TestOpenBackend(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{url: "redis://localhost:6379/0", want: "*cache.RedisBackend"},
+		{url: "rediss://:secret@redis.example.com", want: "*cache.RedisBackend"},
+		{url: "file://" + dir, want: "*cache.DiskBackend"},
+		{url: dir, want: "*cache.DiskBackend"},
+		{url: "memory:", want: "*cache.MemoryBackend"},
+		{url: "bolt:///var/cache/rego.db", wantErr: true},
+		{url: "redis://localhost/db", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			backend, err := cache.OpenBackend(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OpenBackend() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := fmt.Sprintf("%T", backend); !tt.wantErr && got != tt.want {
+				t.Errorf("OpenBackend() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
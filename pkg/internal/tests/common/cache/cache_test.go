@@ -322,3 +322,29 @@ func TestCacheExpirationUpdateOnAccess(t *testing.T) {
 		t.Error("Expected the first key to be updated and not evicted, but it was evicted")
 	}
 }
+
+func TestCacheFlush(t *testing.T) {
+	encryptionKey := []byte("32~Byte-long_passphrase-key-1234")
+	tempFile := "temp_cache_flush.gob"
+	defer os.Remove(tempFile)
+
+	c, _ := cache.NewCache(encryptionKey, tempFile)
+	if err := c.Set("flushKey", []byte("flushValue"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	// A cache removed from disk is written back whole
+	os.Remove(tempFile)
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	reopened, _ := cache.NewCache(encryptionKey, tempFile)
+	if value, exists := reopened.Get("flushKey"); !exists || string(value) != "flushValue" {
+		t.Errorf("Get() after Flush = %q, %v, want flushValue", value, exists)
+	}
+
+	var nilCache *cache.Cache
+	if err := nilCache.Flush(); err != nil {
+		t.Errorf("Flush() of a nil cache = %v, want nil", err)
+	}
+}
@@ -0,0 +1,101 @@
+// pkg/internal/tests/common/cache/invalidate_test.go
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+)
+
+// caches returns a cache of each kind, sharing no items
+func caches(t *testing.T) map[string]*cache.Cache {
+	all := map[string]*cache.Cache{}
+	for name, backend := range backends(t) {
+		c, err := cache.NewCache(backendKey, "rego_cache_invalidate.gob", backend)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all[name] = c
+	}
+	c, err := cache.NewCache(backendKey, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all["builtin"] = c
+	return all
+}
+
+const groups = "https://example.okta.com/api/v1/groups"
+
+func TestInvalidateByPrefix(t *testing.T) {
+	for name, c := range caches(t) {
+		t.Run(name, func(t *testing.T) {
+			items := map[string]string{
+				groups:                      "all groups",
+				groups + "/00g1":            "group",
+				groups + "/00g1/users":      "members",
+				groups + "/00g2/users":      "other members",
+				groups + "/00g1?expand=app": "group with apps", // `?` is matched literally, not as a pattern
+			}
+			for key, value := range items {
+				if err := c.Set(key, []byte(value), time.Minute); err != nil {
+					t.Fatalf("Set() error = %v", err)
+				}
+			}
+
+			removed, err := c.InvalidateByPrefix(groups + "/00g1")
+			if err != nil {
+				t.Fatalf("InvalidateByPrefix() error = %v", err)
+			}
+			if removed != 3 {
+				t.Errorf("InvalidateByPrefix() removed %d items, want 3", removed)
+			}
+			for key := range items {
+				_, found := c.Get(key)
+				if want := key == groups || key == groups+"/00g2/users"; found != want {
+					t.Errorf("Get(%q) found = %v, want %v", key, found, want)
+				}
+			}
+
+			if err := c.Delete(groups); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if _, found := c.Get(groups); found {
+				t.Error("Get() found a deleted item")
+			}
+		})
+	}
+}
+
+func TestInvalidateTag(t *testing.T) {
+	for name, c := range caches(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := c.SetTagged("members", []byte("alice, bob"), time.Minute, "group:00g1"); err != nil {
+				t.Fatalf("SetTagged() error = %v", err)
+			}
+			if err := c.SetTagged("alice", []byte("00g1, 00g2"), time.Minute, "group:00g1", "user:alice"); err != nil {
+				t.Fatalf("SetTagged() error = %v", err)
+			}
+			if err := c.SetTagged("bob", []byte("00g1"), time.Minute, "user:bob"); err != nil {
+				t.Fatalf("SetTagged() error = %v", err)
+			}
+			if _, err := c.Fetch("carol", time.Minute, func() ([]byte, error) { return []byte("00g3"), nil }, "user:carol"); err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+
+			removed, err := c.InvalidateTag("group:00g1", "user:carol")
+			if err != nil {
+				t.Fatalf("InvalidateTag() error = %v", err)
+			}
+			if removed != 3 {
+				t.Errorf("InvalidateTag() removed %d items, want 3", removed)
+			}
+			for key, want := range map[string]bool{"members": false, "alice": false, "bob": true, "carol": false} {
+				if _, found := c.Get(key); found != want {
+					t.Errorf("Get(%q) found = %v, want %v", key, found, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// pkg/internal/tests/common/cache/keys_test.go
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/crypt"
+)
+
+var (
+	oldKey = []byte("32~Byte-long_passphrase-key-1234")
+	newKey = []byte("8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt")
+)
+
+func TestPreviousKeys(t *testing.T) {
+	backend := cache.NewMemoryBackend()
+	before, err := cache.NewCache(oldKey, "rego_cache_rotation.gob", backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := before.Set("users", []byte("alice@example.com"), time.Hour); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	// Without the previous key, items encrypted with it cannot be read
+	unkeyed, err := cache.NewCache(newKey, "rego_cache_rotation.gob", backend, cache.PreviousKeys{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, found := unkeyed.Get("users"); found {
+		t.Error("Get() read an item encrypted with another key")
+	}
+
+	after, err := cache.NewCache(newKey, "rego_cache_rotation.gob", backend, cache.PreviousKeys{oldKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, found := after.Get("users"); !found || string(got) != "alice@example.com" {
+		t.Fatalf("Get() = %q, %v; want the item encrypted with the previous key", got, found)
+	}
+
+	// The item was re-encrypted with the new key as it was read
+	item, _, _ := backend.Load(context.Background(), "rotation:users")
+	if _, err := crypt.DecryptAES(item.Data, newKey); err != nil {
+		t.Errorf("the item was not re-encrypted with the new key: %v", err)
+	}
+	if _, found := unkeyed.Get("users"); !found {
+		t.Error("Get() without the previous key missed the re-encrypted item")
+	}
+}
+
+func TestPreviousKeysFromEnvironment(t *testing.T) {
+	t.Setenv("REGO_PREVIOUS_ENCRYPTION_KEYS", string(oldKey))
+
+	backend := cache.NewMemoryBackend()
+	before, err := cache.NewCache(oldKey, "rego_cache_rotation.gob", backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := before.Set("key", []byte("value"), time.Hour); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	after, err := cache.NewCache(newKey, "rego_cache_rotation.gob", backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, found := after.Get("key"); !found {
+		t.Error("Get() missed an item encrypted with a key of REGO_PREVIOUS_ENCRYPTION_KEYS")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	c, err := cache.NewCache(oldKey, true, cache.PreviousKeys{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set("before", []byte("first"), time.Hour); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	if err := c.Rotate([]byte("weak")); err == nil {
+		t.Error("Rotate() accepted an invalid key")
+	}
+	if err := c.Rotate(newKey); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if err := c.Set("after", []byte("second"), time.Hour); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	for key, want := range map[string]string{"before": "first", "after": "second"} {
+		if got, found := c.Get(key); !found || string(got) != want {
+			t.Errorf("Get(%q) = %q, %v; want %q, true", key, got, found, want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// pkg/internal/tests/common/cache/stale_test.go
+package cache_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+)
+
+func TestFetch(t *testing.T) {
+	c, err := cache.NewCache(backendKey, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	refresh := func() ([]byte, error) {
+		calls.Add(1)
+		return []byte("users"), nil
+	}
+	for i := 0; i < 2; i++ {
+		got, err := c.Fetch("users", time.Minute, refresh)
+		if err != nil || string(got) != "users" {
+			t.Fatalf("Fetch() = %q, %v; want users", got, err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("refresh was called %d times, want once", calls.Load())
+	}
+
+	failed := errors.New("listing failed")
+	if _, err := c.Fetch("groups", time.Minute, func() ([]byte, error) { return nil, failed }); !errors.Is(err, failed) {
+		t.Errorf("Fetch() error = %v, want %v", err, failed)
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	for name, backend := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			c, err := cache.NewCache(backendKey, "rego_cache_stale.gob", backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.StaleWhileRevalidate("users", time.Minute)
+			if err := c.Set("users", []byte("v1"), 50*time.Millisecond); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			time.Sleep(100 * time.Millisecond)
+
+			if _, found := c.Get("users"); found {
+				t.Error("Get() found an expired item")
+			}
+
+			// The stale value is served while a single refresh runs in the background
+			release := make(chan struct{})
+			var calls atomic.Int32
+			refresh := func() ([]byte, error) {
+				calls.Add(1)
+				<-release
+				return []byte("v2"), nil
+			}
+			for i := 0; i < 3; i++ {
+				got, err := c.Fetch("users", time.Minute, refresh)
+				if err != nil || string(got) != "v1" {
+					t.Fatalf("Fetch() = %q, %v; want the stale v1", got, err)
+				}
+			}
+			close(release)
+			if err := c.Flush(); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			if calls.Load() != 1 {
+				t.Errorf("refresh was called %d times, want once", calls.Load())
+			}
+
+			got, err := c.Fetch("users", time.Minute, func() ([]byte, error) {
+				t.Error("refresh was called for a fresh value")
+				return nil, nil
+			})
+			if err != nil || string(got) != "v2" {
+				t.Errorf("Fetch() = %q, %v; want the refreshed v2", got, err)
+			}
+		})
+	}
+}
+
+func TestStaleWindowEnds(t *testing.T) {
+	c, err := cache.NewCache(backendKey, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.StaleWhileRevalidate("users", 50*time.Millisecond)
+	if err := c.Set("users", []byte("v1"), 50*time.Millisecond); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := c.Set("groups", []byte("g1"), 50*time.Millisecond); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	// Past its window, or without one, an expired value is refreshed before it is returned
+	for key, want := range map[string]string{"users": "v2", "groups": "g2"} {
+		got, err := c.Fetch(key, time.Minute, func() ([]byte, error) { return []byte(want), nil })
+		if err != nil || string(got) != want {
+			t.Errorf("Fetch(%q) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// pkg/internal/tests/common/checkpoint/checkpoint_test.go
+package checkpoint_test
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/checkpoint"
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/normalize"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/testutil"
+)
+
+var (
+	day      = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	errAbort = errors.New("abort")
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	store := checkpoint.NewFileStore(path)
+
+	if cp, err := store.Load("okta-users"); err != nil || !cp.IsZero() {
+		t.Fatalf("Expected no checkpoint before the first save, got %v (%v)", cp, err)
+	}
+
+	users := checkpoint.Checkpoint{Time: day}
+	logs := checkpoint.Checkpoint{Time: day.Add(time.Hour), Cursor: "uuid"}
+	if err := store.Save("okta-users", users); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save("okta-logs", logs); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new store of the file reads every checkpoint back
+	reopened := checkpoint.NewFileStore(path)
+	for name, want := range map[string]checkpoint.Checkpoint{"okta-users": users, "okta-logs": logs} {
+		if cp, err := reopened.Load(name); err != nil || !cp.Time.Equal(want.Time) || cp.Cursor != want.Cursor {
+			t.Errorf("Load(%s) = %v (%v), want %v", name, cp, err, want)
+		}
+	}
+}
+
+func TestDelta(t *testing.T) {
+	store := checkpoint.NewMemoryStore()
+
+	var since []time.Time
+	sync := func(s time.Time) error {
+		since = append(since, s)
+		return nil
+	}
+
+	before := time.Now().UTC()
+	if err := checkpoint.Delta(store, "users", time.Minute, sync); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := store.Load("users")
+	if !since[0].IsZero() || first.Time.Before(before) {
+		t.Fatalf("Expected a full first sync checkpointed at its start, got since %v and %v", since[0], first.Time)
+	}
+
+	// A failed run is not checkpointed, so the next one starts from the same time
+	if err := checkpoint.Delta(store, "users", time.Minute, func(time.Time) error { return errAbort }); !errors.Is(err, errAbort) {
+		t.Fatalf("Expected the error of the sync, got %v", err)
+	}
+	if cp, _ := store.Load("users"); !cp.Time.Equal(first.Time) {
+		t.Errorf("Expected a failed run to keep the checkpoint, got %v", cp.Time)
+	}
+
+	checkpoint.Delta(store, "users", time.Minute, sync)
+	if want := first.Time.Add(-time.Minute); !since[1].Equal(want) {
+		t.Errorf("Expected the next run since %v, got %v", want, since[1])
+	}
+}
+
+func TestOktaUpdatedSince(t *testing.T) {
+	fake := testutil.NewOkta(t)
+	fake.AddUser(
+		&okta.User{ID: "00u1", Status: "ACTIVE", LastUpdated: day, Profile: &okta.UserProfile{Email: "old@example.com"}},
+		&okta.User{ID: "00u2", Status: "DEPROVISIONED", LastUpdated: day.Add(2 * time.Hour), Profile: &okta.UserProfile{Email: "new@example.com"}},
+	)
+	fake.AddGroup(&okta.Group{ID: "00g1", LastUpdated: day, LastMembershipUpdated: day.Add(3 * time.Hour), Profile: okta.GroupProfile{Name: "Members"}})
+	fake.AddGroup(&okta.Group{ID: "00g2", LastUpdated: day, LastMembershipUpdated: day, Profile: okta.GroupProfile{Name: "Unchanged"}})
+	client := fake.NewClient(t, log.ERROR)
+
+	users, err := iterator.Collect(client.IterUsersUpdatedSince(day.Add(time.Hour)))
+	if err != nil || len(users) != 1 || users[0].ID != "00u2" {
+		t.Errorf("Expected only the user updated since, of any status, got %v (%v)", users, err)
+	}
+	if all, _ := iterator.Collect(client.IterUsersUpdatedSince(time.Time{})); len(all) != 2 {
+		t.Errorf("Expected every user without a checkpoint, got %d", len(all))
+	}
+
+	groups, err := iterator.Collect(client.IterGroupsUpdatedSince(day.Add(time.Hour)))
+	if err != nil || len(groups) != 1 || groups[0].ID != "00g1" {
+		t.Errorf("Expected only the group whose membership changed, got %v (%v)", groups, err)
+	}
+}
+
+func TestTailLogs(t *testing.T) {
+	fake := testutil.NewOkta(t)
+	for i, at := range []time.Duration{0, time.Minute, time.Minute, 2 * time.Minute} {
+		fake.AddLogEvent(&okta.LogEvent{UUID: fmt.Sprintf("e%d", i+1), Published: day.Add(at), EventType: "user.session.start"})
+	}
+	client := fake.NewClient(t, log.ERROR)
+	store := checkpoint.NewMemoryStore()
+
+	// The first run fails on the third event; the events before it are checkpointed
+	var seen []string
+	process := func(stopAt string) func(*okta.LogEvent) error {
+		return func(e *okta.LogEvent) error {
+			if e.UUID == stopAt {
+				return errAbort
+			}
+			seen = append(seen, e.UUID)
+			return nil
+		}
+	}
+	err := checkpoint.Run(store, "okta-logs", func(cp *checkpoint.Checkpoint) error {
+		return iterator.ForEach(client.TailLogs(cp, okta.LogQuery{}), process("e3"))
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("Expected the error of the consumer, got %v", err)
+	}
+	if cp, _ := store.Load("okta-logs"); cp.Cursor != "e2" || !cp.Time.Equal(day.Add(time.Minute)) {
+		t.Fatalf("Expected the checkpoint of the last event processed, got %v", cp)
+	}
+
+	// The next run resumes after e2, though e3 was published at the same time
+	err = checkpoint.Run(store, "okta-logs", func(cp *checkpoint.Checkpoint) error {
+		return iterator.ForEach(client.TailLogs(cp, okta.LogQuery{}), process(""))
+	})
+	if err != nil || !reflect.DeepEqual(seen, []string{"e1", "e2", "e3", "e4"}) {
+		t.Errorf("Expected each event once, got %v (%v)", seen, err)
+	}
+}
+
+func TestGoogleChangedSince(t *testing.T) {
+	fake := testutil.NewGoogle(t)
+	fake.AddUser(&google.User{ID: "1", PrimaryEmail: "changed@example.com"}, &google.User{ID: "2", PrimaryEmail: "unchanged@example.com"})
+	activity := func(at time.Time, qualifier, name, email string) google.Report {
+		return google.Report{
+			ID: google.ActivityID{Time: normalize.NewTime(at), UniqueQualifier: qualifier, ApplicationName: "admin"},
+			Events: []google.Event{{Type: "USER_SETTINGS", Name: name, Parameters: []google.ReportParameter{
+				{Name: "USER_EMAIL", Value: email},
+			}}},
+		}
+	}
+	// Google lists the newest activities first
+	fake.AddActivity("admin",
+		activity(day.Add(3*time.Hour), "q4", "DELETE_USER", "deleted@example.com"),
+		activity(day.Add(2*time.Hour), "q3", "CHANGE_LAST_NAME", "changed@example.com"),
+		activity(day.Add(time.Hour), "q2", "CHANGE_FIRST_NAME", "changed@example.com"),
+		activity(day, "q1", "CHANGE_PASSWORD", "unchanged@example.com"),
+	)
+	client := fake.NewClient(t, log.ERROR)
+
+	users, err := iterator.Collect(client.Users().IterUsersChangedSince(day.Add(time.Minute)))
+	if err != nil || len(users) != 1 || users[0].PrimaryEmail != "changed@example.com" {
+		t.Errorf("Expected only the user changed since, read once, got %v (%v)", users, err)
+	}
+
+	// Tailing resumes after the newest activity
+	cp := checkpoint.Checkpoint{Time: day.Add(2 * time.Hour), Cursor: "q3"}
+	activities, err := iterator.Collect(client.Admin().TailActivities("admin", &cp, google.ReportsQuery{}))
+	if err != nil || len(activities) != 1 || activities[0].ID.UniqueQualifier != "q4" {
+		t.Fatalf("Expected only the activity after the checkpoint, got %v (%v)", activities, err)
+	}
+	if cp.Cursor != "q4" || !cp.Time.Equal(day.Add(3*time.Hour)) {
+		t.Errorf("Expected the checkpoint to advance to the newest activity, got %v", cp)
+	}
+}
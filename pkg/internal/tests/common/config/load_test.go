@@ -0,0 +1,95 @@
+// pkg/internal/tests/common/config/load_test.go
+package config_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/config"
+)
+
+type settings struct {
+	Host        string        `env:"TEST_HOST,required"`
+	Token       []byte        `env:"TEST_TOKEN,required"`
+	Timeout     time.Duration `env:"TEST_TIMEOUT" default:"30s"`
+	Concurrency int           `env:"TEST_CONCURRENCY" default:"4"`
+	Debug       bool          `env:"TEST_DEBUG"`
+	Scopes      []string      `env:"TEST_SCOPES"`
+	Sandbox     struct {
+		Host string `env:"HOST"`
+	} `envPrefix:"TEST_SANDBOX_"`
+	ignored string
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("TEST_HOST", "api.example.com")
+	t.Setenv("TEST_TOKEN", "secret")
+	t.Setenv("TEST_CONCURRENCY", "8")
+	t.Setenv("TEST_DEBUG", "true")
+	t.Setenv("TEST_SCOPES", "users.read, groups.read,")
+	t.Setenv("TEST_SANDBOX_HOST", "sandbox.example.com")
+
+	var s settings
+	if err := config.Load(&s); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if s.Host != "api.example.com" || string(s.Token) != "secret" || s.Timeout != 30*time.Second || s.Concurrency != 8 || !s.Debug {
+		t.Errorf("Load() = %+v", s)
+	}
+	if len(s.Scopes) != 2 || s.Scopes[0] != "users.read" || s.Scopes[1] != "groups.read" {
+		t.Errorf("Load() Scopes = %q, want [users.read groups.read]", s.Scopes)
+	}
+	if s.Sandbox.Host != "sandbox.example.com" {
+		t.Errorf("Load() Sandbox.Host = %q, want the variable of the prefix", s.Sandbox.Host)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Setenv("TEST_HOST", "")
+	t.Setenv("TEST_TOKEN", "")
+	t.Setenv("TEST_TIMEOUT", "forever")
+	t.Setenv("TEST_CONCURRENCY", "eight")
+
+	var s settings
+	err := config.Load(&s)
+	if !errors.Is(err, config.ErrMissing) || !errors.Is(err, config.ErrInvalid) {
+		t.Fatalf("Load() error = %v, want missing and invalid variables", err)
+	}
+	for _, name := range []string{"TEST_HOST", "TEST_TOKEN", "TEST_TIMEOUT", "TEST_CONCURRENCY"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Load() error = %v, want %s reported", err, name)
+		}
+	}
+	if strings.Contains(err.Error(), "forever") {
+		t.Errorf("Load() error = %v, repeats an invalid value", err)
+	}
+
+	if err := config.Load(s); err == nil {
+		t.Error("Load() accepted a struct which is not a pointer")
+	}
+}
+
+type validated struct {
+	Min int `env:"TEST_MIN" default:"10"`
+	Max int `env:"TEST_MAX" default:"5"`
+}
+
+func (v *validated) Validate() error {
+	if v.Min > v.Max {
+		return errors.New("TEST_MIN is greater than TEST_MAX")
+	}
+	return nil
+}
+
+func TestLoadValidate(t *testing.T) {
+	if err := config.LoadPrefix(&validated{}, ""); err == nil || !strings.Contains(err.Error(), "greater") {
+		t.Errorf("Load() error = %v, want the error of Validate", err)
+	}
+
+	t.Setenv("APP_TEST_MAX", "20")
+	if err := config.LoadPrefix(&validated{}, "APP_"); err != nil {
+		t.Errorf("LoadPrefix() error = %v", err)
+	}
+}
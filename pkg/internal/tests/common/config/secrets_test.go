@@ -0,0 +1,109 @@
+// pkg/internal/tests/common/config/secrets_test.go
+package config_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/config"
+	"golang.org/x/oauth2"
+)
+
+func TestAWSSecretsManager(t *testing.T) {
+	reads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auth, "/us-east-1/secretsmanager/aws4_request") {
+			t.Errorf("Authorization = %q, want a signature of the secretsmanager service", auth)
+		}
+		if target := r.Header.Get("X-Amz-Target"); target != "secretsmanager.GetSecretValue" {
+			t.Errorf("X-Amz-Target = %q", target)
+		}
+		if token := r.Header.Get("X-Amz-Security-Token"); token != "session" {
+			t.Errorf("X-Amz-Security-Token = %q, want the session token of the environment", token)
+		}
+		var input map[string]string
+		json.NewDecoder(r.Body).Decode(&input)
+		if input["SecretId"] != "rego/okta" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"__type": "ResourceNotFoundException", "message": "Secrets Manager can't find the specified secret."})
+			return
+		}
+		reads++
+		json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"OKTA_API_TOKEN": "00abc", "OKTA_CONCURRENCY": 4}`})
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY")
+	t.Setenv("AWS_SESSION_TOKEN", "session")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	sm := &config.AWSSecretsManager{SecretID: "rego/okta", Endpoint: server.URL, HTTP: server.Client()}
+	for key, want := range map[string]string{"OKTA_API_TOKEN": "00abc", "OKTA_CONCURRENCY": "4"} {
+		value, found, err := sm.Lookup(context.Background(), key)
+		if err != nil || !found || value != want {
+			t.Errorf("Lookup(%q) = %q, %v, %v; want %q", key, value, found, err, want)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("read the secret %d times, want it cached after 1", reads)
+	}
+
+	missing := &config.AWSSecretsManager{SecretID: "rego/missing", Endpoint: server.URL, HTTP: server.Client()}
+	if _, _, err := missing.Lookup(context.Background(), "OKTA_API_TOKEN"); err == nil || !strings.Contains(err.Error(), "ResourceNotFoundException") {
+		t.Errorf("Lookup() error = %v, want the error of the service", err)
+	}
+}
+
+func TestGCPSecretManager(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer ya29.token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/v1/projects/my-project/secrets/rego/versions/latest:access" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]string{"message": "Secret not found"}})
+			return
+		}
+		data := base64.StdEncoding.EncodeToString([]byte(`{"GOOGLE_API_KEY": "AIza"}`))
+		json.NewEncoder(w).Encode(map[string]interface{}{"payload": map[string]string{"data": data}})
+	}))
+	defer server.Close()
+
+	sm := &config.GCPSecretManager{
+		Name:        "projects/my-project/secrets/rego",
+		TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "ya29.token"}),
+		Endpoint:    server.URL,
+		HTTP:        server.Client(),
+	}
+	value, found, err := sm.Lookup(context.Background(), "GOOGLE_API_KEY")
+	if err != nil || !found || value != "AIza" {
+		t.Errorf("Lookup() = %q, %v, %v; want the field of the secret", value, found, err)
+	}
+
+	sm.Name = "projects/my-project/secrets/missing/versions/2"
+	sm.TTL = -1 // Read again at once
+	if _, _, err := sm.Lookup(context.Background(), "GOOGLE_API_KEY"); err == nil || !strings.Contains(err.Error(), "Secret not found") {
+		t.Errorf("Lookup() error = %v, want the error of the API", err)
+	}
+}
+
+func TestProvidersFromEnvironment(t *testing.T) {
+	t.Setenv("REGO_GCP_SECRET", "my-project/rego")
+	if _, err := config.GCPSecretManagerFromEnvironment(); err == nil {
+		t.Error("GCPSecretManagerFromEnvironment() accepted a name without projects/ and secrets/")
+	}
+
+	t.Setenv("REGO_AWS_SECRET_ID", "rego/okta")
+	sm, err := config.AWSSecretsManagerFromEnvironment()
+	if err != nil || sm == nil || sm.SecretID != "rego/okta" {
+		t.Errorf("AWSSecretsManagerFromEnvironment() = %+v, %v", sm, err)
+	}
+}
@@ -0,0 +1,175 @@
+// pkg/internal/tests/common/config/vault_test.go
+package config_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/config"
+)
+
+// fakeVault serves an AppRole login, token renewal and a KV v2 secret
+type fakeVault struct {
+	mu     sync.Mutex
+	lease  int64 // Seconds of the tokens' leases
+	logins int
+	renews int
+	reads  int
+	token  string
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	reply := func(body interface{}) {
+		json.NewEncoder(w).Encode(body)
+	}
+	auth := func() map[string]interface{} {
+		return map[string]interface{}{"auth": map[string]interface{}{
+			"client_token": f.token, "lease_duration": f.lease, "renewable": true,
+		}}
+	}
+
+	if r.URL.Path == "/v1/auth/approle/login" {
+		var credentials map[string]string
+		json.NewDecoder(r.Body).Decode(&credentials)
+		if credentials["role_id"] != "role" || credentials["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			reply(map[string][]string{"errors": {"invalid role or secret ID"}})
+			return
+		}
+		f.logins++
+		f.token = "s.token"
+		reply(auth())
+		return
+	}
+
+	if r.Header.Get("X-Vault-Token") != f.token || f.token == "" {
+		w.WriteHeader(http.StatusForbidden)
+		reply(map[string][]string{"errors": {"permission denied"}})
+		return
+	}
+	switch r.URL.Path {
+	case "/v1/auth/token/renew-self":
+		f.renews++
+		reply(auth())
+	case "/v1/secret/data/rego/okta":
+		f.reads++
+		reply(map[string]interface{}{"data": map[string]interface{}{
+			"data": map[string]interface{}{
+				"OKTA_API_TOKEN":         "00abc",
+				"GOOGLE_SERVICE_ACCOUNT": map[string]string{"type": "service_account"},
+			},
+		}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newVault(t *testing.T, f *fakeVault) *config.Vault {
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return &config.Vault{
+		Addr:     server.URL,
+		Mount:    "secret",
+		Path:     "rego/okta",
+		RoleID:   "role",
+		SecretID: "secret",
+		HTTP:     server.Client(),
+	}
+}
+
+func TestVaultLookup(t *testing.T) {
+	f := &fakeVault{lease: 3600}
+	vault := newVault(t, f)
+
+	for _, test := range []struct {
+		key   string
+		value string
+		found bool
+	}{
+		{"OKTA_API_TOKEN", "00abc", true},
+		{"GOOGLE_SERVICE_ACCOUNT", `{"type":"service_account"}`, true},
+		{"MISSING", "", false},
+	} {
+		value, found, err := vault.Lookup(context.Background(), test.key)
+		if err != nil {
+			t.Fatalf("Lookup(%q) error = %v", test.key, err)
+		}
+		if value != test.value || found != test.found {
+			t.Errorf("Lookup(%q) = %q, %v; want %q, %v", test.key, value, found, test.value, test.found)
+		}
+	}
+	if f.logins != 1 || f.reads != 1 {
+		t.Errorf("logged in %d times and read %d times, want the secret cached after 1 of each", f.logins, f.reads)
+	}
+}
+
+func TestVaultRenewsLease(t *testing.T) {
+	f := &fakeVault{lease: 1}
+	vault := newVault(t, f)
+	vault.TTL = time.Millisecond
+
+	if _, _, err := vault.Lookup(context.Background(), "OKTA_API_TOKEN"); err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	time.Sleep(700 * time.Millisecond) // Past two thirds of the lease
+	if _, _, err := vault.Lookup(context.Background(), "OKTA_API_TOKEN"); err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if f.logins != 1 || f.renews != 1 || f.reads != 2 {
+		t.Errorf("logins = %d, renewals = %d, reads = %d; want 1, 1, 2", f.logins, f.renews, f.reads)
+	}
+
+	// A revoked token is replaced by logging in again
+	f.mu.Lock()
+	f.token = "s.rotated"
+	f.mu.Unlock()
+	time.Sleep(2 * time.Millisecond)
+	if value, _, err := vault.Lookup(context.Background(), "OKTA_API_TOKEN"); err != nil || value != "00abc" {
+		t.Fatalf("Lookup() = %q, %v; want the secret after logging in again", value, err)
+	}
+	if f.logins != 2 {
+		t.Errorf("logins = %d, want 2", f.logins)
+	}
+}
+
+func TestGetEnvFromProvider(t *testing.T) {
+	config.SetProviders(newVault(t, &fakeVault{lease: 3600}))
+	defer config.SetProviders()
+
+	if value := config.GetEnv("OKTA_API_TOKEN"); value != "00abc" {
+		t.Errorf("GetEnv() = %q, want the secret of the provider", value)
+	}
+
+	// The environment overrides the provider
+	t.Setenv("OKTA_API_TOKEN", "00local")
+	if value := config.GetEnv("OKTA_API_TOKEN"); value != "00local" {
+		t.Errorf("GetEnv() = %q, want the variable of the environment", value)
+	}
+}
+
+func TestVaultFromEnvironment(t *testing.T) {
+	t.Setenv("REGO_VAULT_PATH", "secret/rego/okta")
+	t.Setenv("VAULT_ADDR", "https://vault.example.com:8200")
+	t.Setenv("VAULT_TOKEN", "s.token")
+
+	vault, err := config.VaultFromEnvironment()
+	if err != nil {
+		t.Fatalf("VaultFromEnvironment() error = %v", err)
+	}
+	if vault.Mount != "secret" || vault.Path != "rego/okta" || vault.Token != "s.token" {
+		t.Errorf("VaultFromEnvironment() = %+v", vault)
+	}
+
+	t.Setenv("REGO_VAULT_PATH", "secret")
+	if _, err := config.VaultFromEnvironment(); err == nil {
+		t.Error("VaultFromEnvironment() accepted a path without a secret")
+	}
+}
@@ -0,0 +1,82 @@
+// pkg/internal/tests/common/crypt/envelope_test.go
+package crypt_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/crypt"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := crypt.NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := crypt.Encrypt([]byte("token"), key, []byte("okta"))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	if plaintext, err := crypt.Decrypt(ciphertext, key, []byte("okta")); err != nil || string(plaintext) != "token" {
+		t.Errorf("Decrypt() = %q, %v, want the plaintext", plaintext, err)
+	}
+	if _, err := crypt.Decrypt(ciphertext, key, []byte("google")); err == nil {
+		t.Error("Decrypt() accepted other additional data")
+	}
+	if _, err := crypt.Decrypt(ciphertext[:4], key, nil); !errors.Is(err, crypt.ErrInvalidCiphertext) {
+		t.Errorf("Decrypt() error = %v, want ErrInvalidCiphertext", err)
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	salt, _ := crypt.NewSalt()
+	for _, kdf := range []crypt.KDF{crypt.Argon2ID, crypt.Scrypt} {
+		a, err := crypt.DeriveKey([]byte("passphrase"), salt, kdf)
+		if err != nil || len(a) != crypt.KeySize {
+			t.Fatalf("DeriveKey(%d) = %d bytes, %v", kdf, len(a), err)
+		}
+		if b, _ := crypt.DeriveKey([]byte("passphrase"), salt, kdf); !bytes.Equal(a, b) {
+			t.Errorf("DeriveKey(%d) is not deterministic", kdf)
+		}
+	}
+	if _, err := crypt.DeriveKey([]byte("passphrase"), salt, 0); err == nil {
+		t.Error("DeriveKey() accepted an unknown KDF")
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	master, _ := crypt.NewKey()
+	wrappers := map[string]crypt.KeyWrapper{
+		"master":     crypt.MasterKey(master),
+		"passphrase": crypt.PassphraseKey{Passphrase: []byte("8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt"), KDF: crypt.Scrypt},
+	}
+	for name, kek := range wrappers {
+		t.Run(name, func(t *testing.T) {
+			envelope, err := crypt.Seal([]byte("session"), kek)
+			if err != nil {
+				t.Fatalf("Seal() error = %v", err)
+			}
+			if plaintext, err := crypt.Open(envelope, kek); err != nil || string(plaintext) != "session" {
+				t.Errorf("Open() = %q, %v, want the plaintext", plaintext, err)
+			}
+
+			// The wrapped data key is authenticated with the ciphertext
+			tampered := bytes.Clone(envelope)
+			tampered[len(tampered)-1] ^= 1
+			if _, err := crypt.Open(tampered, kek); err == nil {
+				t.Error("Open() accepted a modified envelope")
+			}
+			if _, err := crypt.Open([]byte("session"), kek); !errors.Is(err, crypt.ErrInvalidCiphertext) {
+				t.Errorf("Open() error = %v, want ErrInvalidCiphertext", err)
+			}
+		})
+	}
+
+	other, _ := crypt.NewKey()
+	envelope, _ := crypt.Seal([]byte("session"), crypt.MasterKey(master))
+	if _, err := crypt.Open(envelope, crypt.MasterKey(other)); err == nil {
+		t.Error("Open() accepted another master key")
+	}
+}
@@ -0,0 +1,92 @@
+// pkg/internal/tests/common/diff/diff_test.go
+package diff_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/diff"
+	"github.com/gemini-oss/rego/pkg/okta"
+)
+
+func TestCompare(t *testing.T) {
+	before := &okta.User{
+		Status: "ACTIVE",
+		Profile: &okta.UserProfile{
+			Login:      "alice@example.com",
+			Department: "Engineering",
+			Title:      "Engineer",
+		},
+	}
+	after := &okta.User{
+		Status: "SUSPENDED",
+		Profile: &okta.UserProfile{
+			Login:      "alice@example.com",
+			Department: "Sales",
+		},
+	}
+
+	changes := diff.Compare(before, after, diff.Options{Ignore: []string{"status"}})
+	if got, want := changes.Paths(), []string{"profile.department", "profile.title"}; !slices.Equal(got, want) {
+		t.Fatalf("Paths() = %v, want %v", got, want)
+	}
+	if changes[0].Op != diff.Changed || changes[1].Op != diff.Removed {
+		t.Errorf("ops = %s, %s; want change, remove", changes[0].Op, changes[1].Op)
+	}
+
+	want := "~ profile.department: \"Engineering\" -> \"Sales\"\n- profile.title: \"Engineer\"\n"
+	if changes.String() != want {
+		t.Errorf("String() = %q, want %q", changes.String(), want)
+	}
+
+	if !diff.Compare(before, before, diff.Options{}).Empty() {
+		t.Errorf("Compare() of identical resources reported changes")
+	}
+}
+
+func TestCompareLists(t *testing.T) {
+	before := map[string]interface{}{"groups": []string{"eng", "admins"}}
+	after := map[string]interface{}{"groups": []string{"eng", "sales", "all"}}
+
+	changes := diff.Values(before, after, diff.Options{})
+	if got, want := changes.Paths(), []string{"groups[1]", "groups[2]"}; !slices.Equal(got, want) {
+		t.Errorf("Paths() = %v, want %v", got, want)
+	}
+
+	partial := diff.Values(before, after, diff.Options{Partial: true})
+	if got, want := partial.Paths(), []string{"groups"}; !slices.Equal(got, want) {
+		t.Errorf("Paths() with Partial = %v, want %v", got, want)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	before := map[string]interface{}{
+		"credentials":   map[string]interface{}{"password": map[string]interface{}{"value": "hunter2"}},
+		"client_secret": "old",
+		"ssn":           "123-45-6789",
+	}
+	after := map[string]interface{}{
+		"credentials":   map[string]interface{}{"password": map[string]interface{}{"value": "correct horse"}},
+		"client_secret": "new",
+		"ssn":           "987-65-4321",
+	}
+
+	changes := diff.Values(before, after, diff.Options{Sensitive: []string{"ssn"}})
+	if len(changes) != 3 {
+		t.Fatalf("Values() = %d changes, want 3", len(changes))
+	}
+	for _, change := range changes {
+		if !change.Redacted || change.Before != diff.Redacted || change.After != diff.Redacted {
+			t.Errorf("change %s was not redacted: %+v", change.Path, change)
+		}
+	}
+	if out := changes.String(); strings.Contains(out, "hunter2") || strings.Contains(out, "new") {
+		t.Errorf("String() leaks a sensitive value: %s", out)
+	}
+
+	redacted := diff.Redact(after, diff.Options{}).(map[string]interface{})
+	if redacted["client_secret"] != diff.Redacted || redacted["ssn"] != "987-65-4321" {
+		t.Errorf("Redact() = %v", redacted)
+	}
+}
@@ -0,0 +1,78 @@
+// pkg/internal/tests/common/enum/enum_test.go
+package enum_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/backupify"
+	"github.com/gemini-oss/rego/pkg/common/enum"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		into    interface{}
+		wantErr bool
+	}{
+		{"Okta user status", `{"status":"ACTIVE","transitioningToStatus":"DEPROVISIONED"}`, &okta.User{}, false},
+		{"Okta user status typo", `{"status":"ACTVE"}`, &okta.User{}, true},
+		{"Okta app status", `{"status":"INACTIVE"}`, &okta.Application{}, false},
+		{"Okta app status in lowercase", `{"status":"active"}`, &okta.Application{}, false},
+		{"Okta group type", `{"type":"APP_GROUP"}`, &okta.Group{}, false},
+		{"Okta factor type", `{"factorType":"token:software:totp"}`, &okta.Factor{}, false},
+		{"Okta factor type typo", `{"factorType":"totp"}`, &okta.Factor{}, true},
+		{"Google org unit", `{"orgUnitPath":"/Sales/EMEA"}`, &google.User{}, false},
+		{"Google relative org unit", `{"orgUnitPath":"Sales"}`, &google.User{}, true},
+		{"Backupify app type", `{"appType":"GoogleTeamDrives"}`, &backupify.User{}, false},
+		{"Backupify app type typo", `{"appType":"GoogleDrives"}`, &backupify.User{}, true},
+		{"Absent values", `{}`, &okta.User{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := json.Unmarshal([]byte(tt.payload), tt.into)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, enum.ErrInvalid) {
+				t.Errorf("Unmarshal() error = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	if _, err := json.Marshal(&okta.User{Status: "ACTVE"}); !errors.Is(err, enum.ErrInvalid) {
+		t.Errorf("Marshal() error = %v, want ErrInvalid", err)
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"status": okta.SUSPENDED, "appType": backupify.SharedDrive})
+	if err != nil || string(data) != `{"appType":"GoogleTeamDrives","status":"SUSPENDED"}` {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+}
+
+func TestAdminRole(t *testing.T) {
+	tests := []struct {
+		user *google.User
+		want google.AdminRole
+	}{
+		{&google.User{IsAdmin: true, IsDelegatedAdmin: true}, google.SUPER_ADMIN},
+		{&google.User{IsDelegatedAdmin: true}, google.DELEGATED_ADMIN},
+		{&google.User{}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.user.AdminRole(); got != tt.want {
+			t.Errorf("AdminRole() = %q, want %q", got, tt.want)
+		}
+	}
+
+	if !google.ROOT_OU.Contains("/Sales") || !google.OrgUnitPath("/Sales").Contains("/Sales/EMEA") || google.OrgUnitPath("/Sales").Contains("/SalesOps") {
+		t.Errorf("Contains() does not match the organizational units under a path")
+	}
+}
@@ -1,2 +1,73 @@
 // pkg/internal/tests/common/errors/errors_test.go
 package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+)
+
+func TestClassOf(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusNotFound, rerrors.ErrNotFound},
+		{http.StatusConflict, rerrors.ErrConflict},
+		{http.StatusForbidden, rerrors.ErrForbidden},
+		{http.StatusUnauthorized, rerrors.ErrUnauthorized},
+		{http.StatusTooManyRequests, rerrors.ErrRateLimited},
+		{http.StatusBadRequest, rerrors.ErrInvalid},
+		{http.StatusBadGateway, rerrors.ErrUnavailable},
+		{http.StatusOK, nil},
+		{http.StatusTeapot, nil},
+	}
+	for _, tt := range tests {
+		if got := rerrors.ClassOf(tt.status); got != tt.want {
+			t.Errorf("ClassOf(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	err := fmt.Errorf("deactivating user: %w", rerrors.WithProvider(rerrors.NewAPIError("", http.StatusNotFound, "", "Not found"), "okta"))
+
+	if !errors.Is(err, rerrors.ErrNotFound) || errors.Is(err, rerrors.ErrConflict) {
+		t.Errorf("errors.Is() does not match the class of %v", err)
+	}
+	if got := rerrors.Class(err); got != rerrors.ErrNotFound {
+		t.Errorf("Class() = %v, want ErrNotFound", got)
+	}
+	if rerrors.Retryable(err) {
+		t.Errorf("Retryable() = true for a missing resource")
+	}
+	if want := "deactivating user: okta: 404 - Not found"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	slack := &rerrors.APIError{Provider: "slack", Code: "ratelimited", Class: rerrors.ErrRateLimited}
+	if want := "slack: ratelimited"; slack.Error() != want || !rerrors.Retryable(slack) {
+		t.Errorf("Error() = %q, want %q and retryable", slack.Error(), want)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	limited := rerrors.NewAPIError("okta", http.StatusTooManyRequests, "E0000047", "API call exceeded rate limit")
+	limited.RetryAfter = 30 * time.Second
+
+	var apiErr *rerrors.APIError
+	err := fmt.Errorf("listing users: %w", limited)
+	if !errors.As(err, &apiErr) || apiErr.Code != "E0000047" {
+		t.Errorf("errors.As() did not find the API error of %v", err)
+	}
+	if wait, ok := rerrors.RetryAfter(err); !ok || wait != 30*time.Second {
+		t.Errorf("RetryAfter() = %v, %v, want 30s", wait, ok)
+	}
+	if _, ok := rerrors.RetryAfter(rerrors.NewAPIError("okta", http.StatusNotFound, "", "")); ok {
+		t.Error("RetryAfter() reported a wait for an error without one")
+	}
+}
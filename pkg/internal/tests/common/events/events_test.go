@@ -0,0 +1,161 @@
+// pkg/internal/tests/common/events/events_test.go
+package events_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/events"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/webhooks"
+	"github.com/gemini-oss/rego/pkg/orchestrators"
+)
+
+// recorder collects the types of the events handed to a subscriber
+type recorder struct {
+	mutex sync.Mutex
+	types []string
+}
+
+func (r *recorder) handle(e *events.Event) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.types = append(r.types, e.Type)
+	return nil
+}
+
+func (r *recorder) got() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string{}, r.types...)
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{"*", true},
+		{"user.*", true},
+		{"user.deactivated", true},
+		{"user.created", false},
+		{"device.*", false},
+	}
+	for _, tt := range tests {
+		if got := events.Matches(tt.pattern, events.UserDeactivated); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	bus := events.NewBus(log.ERROR)
+	bus.QueueSize = 1 // Exercise backpressure
+
+	all, users := &recorder{}, &recorder{}
+	if _, err := bus.Subscribe("all", "*", all.handle); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if _, err := bus.Subscribe("users", "user.*", users.handle); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	bus.Subscribe("failing", "*", func(e *events.Event) error { return errors.New("unavailable") })
+	bus.Subscribe("panicking", "*", func(e *events.Event) error { panic("boom") })
+
+	e := &events.Event{Type: events.UserDeactivated, Source: "okta"}
+	if err := bus.Publish(e, &events.Event{Type: events.DeviceEnrolled}, &events.Event{Type: events.UserCreated}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if e.ID == "" || e.Time.IsZero() {
+		t.Errorf("Publish() did not fill in the ID and time: %+v", e)
+	}
+
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if want := []string{events.UserDeactivated, events.DeviceEnrolled, events.UserCreated}; !slices.Equal(all.got(), want) {
+		t.Errorf("all received %v, want %v", all.got(), want)
+	}
+	if want := []string{events.UserDeactivated, events.UserCreated}; !slices.Equal(users.got(), want) {
+		t.Errorf("users received %v, want %v", users.got(), want)
+	}
+
+	if err := bus.Publish(e); !errors.Is(err, events.ErrClosed) {
+		t.Errorf("Publish() after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	bus := events.NewBus(log.ERROR)
+	defer bus.Close(context.Background())
+
+	r := &recorder{}
+	sub, _ := bus.Subscribe("users", "*", r.handle)
+	bus.Publish(&events.Event{Type: events.UserCreated})
+	sub.Unsubscribe()
+	bus.Publish(&events.Event{Type: events.UserDeactivated})
+
+	deadline := time.Now().Add(time.Second)
+	for len(r.got()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if want := []string{events.UserCreated}; !slices.Equal(r.got(), want) {
+		t.Errorf("received %v, want %v", r.got(), want)
+	}
+}
+
+func TestCloseTimeout(t *testing.T) {
+	bus := events.NewBus(log.ERROR)
+	release := make(chan struct{})
+	defer close(release)
+	bus.Subscribe("slow", "*", func(e *events.Event) error {
+		<-release
+		return nil
+	})
+	bus.Publish(&events.Event{Type: events.UserCreated})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := bus.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() = %v, want a deadline error", err)
+	}
+}
+
+func TestNormalizeWebhooks(t *testing.T) {
+	tests := []struct {
+		event *webhooks.Event
+		want  string
+	}{
+		{&webhooks.Event{Provider: webhooks.Okta, Type: "user.lifecycle.deactivate"}, events.UserDeactivated},
+		{&webhooks.Event{Provider: webhooks.Jamf, Type: "MobileDeviceEnrolled"}, events.DeviceEnrolled},
+		{&webhooks.Event{Provider: webhooks.Slack, Type: "team_join"}, events.UserCreated},
+		{&webhooks.Event{Provider: webhooks.Slack, Type: "user_change", Data: &webhooks.SlackEvent{Type: "user_change", User: []byte(`{"id":"U1","deleted":true}`)}}, events.UserDeactivated},
+		{&webhooks.Event{Provider: webhooks.Slack, Type: "message"}, "slack.message"},
+	}
+	for _, tt := range tests {
+		e := tt.event.Normalize()
+		if e.Type != tt.want || e.Source != string(tt.event.Provider) || e.Data != tt.event {
+			t.Errorf("Normalize(%s) = %s from %s, want %s", tt.event.Name(), e.Type, e.Source, tt.want)
+		}
+	}
+}
+
+func TestWorkflowEvents(t *testing.T) {
+	bus := events.NewBus(log.ERROR)
+	r := &recorder{}
+	bus.Subscribe("workflows", "*", r.handle)
+
+	c := &orchestrators.Client{Log: log.NewLogger("{orchestrators}", log.ERROR), Events: bus}
+	c.RunWorkflow("offboarding", "departed@example.com", []orchestrators.Step{{Name: "noop", Run: func() (string, error) { return "", nil }}}, orchestrators.WorkflowOptions{})
+	c.RunWorkflow("offboarding", "departed@example.com", []orchestrators.Step{{Name: "noop"}}, orchestrators.WorkflowOptions{DryRun: true})
+	c.RunWorkflow("audit", "departed@example.com", []orchestrators.Step{{Name: "fail", Run: func() (string, error) { return "", errors.New("down") }}}, orchestrators.WorkflowOptions{})
+
+	bus.Close(context.Background())
+	if want := []string{events.WorkflowCompleted, events.UserOffboarded, events.WorkflowFailed}; !slices.Equal(r.got(), want) {
+		t.Errorf("received %v, want %v", r.got(), want)
+	}
+}
@@ -0,0 +1,270 @@
+// pkg/internal/tests/common/exporters/exporters_test.go
+package exporters_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/exporters"
+)
+
+type Location struct {
+	Building string `json:"building"`
+	Floor    int    `json:"floor"`
+}
+
+type User struct {
+	ID       int               `json:"id"`
+	Email    string            `csv:"Email" json:"email"`
+	Active   bool              `json:"active"`
+	Created  time.Time         `json:"created"`
+	Groups   []string          `json:"groups,omitempty"`
+	Location *Location         `json:"location,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+func testUsers() []*User {
+	return []*User{
+		{
+			ID:       1,
+			Email:    "ada@example.com",
+			Active:   true,
+			Created:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Groups:   []string{"eng", "admins"},
+			Location: &Location{Building: "HQ", Floor: 3},
+			Labels:   map[string]string{"team": "core"},
+			Secret:   "hunter2",
+		},
+		{ID: 2, Email: "grace@example.com"},
+	}
+}
+
+func TestNewTable(t *testing.T) {
+	table, err := exporters.NewTable(testUsers())
+	if err != nil {
+		t.Fatalf("NewTable() error = %v", err)
+	}
+
+	headers := []string{"id", "Email", "active", "created", "groups", "location.building", "location.floor", "labels"}
+	if !slices.Equal(table.Headers, headers) {
+		t.Errorf("Headers = %v, want %v", table.Headers, headers)
+	}
+	if len(table.Rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(table.Rows))
+	}
+
+	row := table.Rows[0]
+	if row[0].Kind != exporters.KindNumber || row[2].Kind != exporters.KindBool || row[3].Kind != exporters.KindTime {
+		t.Errorf("Expected typed cells, got %+v", row)
+	}
+	if row[4].String() != "eng; admins" || row[7].String() != `{"team":"core"}` {
+		t.Errorf("Unexpected slice/map formatting: %q, %q", row[4].String(), row[7].String())
+	}
+
+	// Nil pointers and zero times become empty cells
+	if table.Rows[1][5].String() != "" || table.Rows[1][3].String() != "" {
+		t.Errorf("Expected empty cells for nil/zero values, got %+v", table.Rows[1])
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := exporters.WriteCSV(&buf, testUsers()); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected a header and 2 rows, got %d records", len(records))
+	}
+	if got := strings.Join(records[1][:4], ","); got != "1,ada@example.com,true,2024-01-02T03:04:05Z" {
+		t.Errorf("Unexpected first row: %s", got)
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Error("Expected fields tagged `-` to be skipped")
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	err := exporters.WriteXLSX(&buf,
+		exporters.Sheet{Name: "Users", Data: testUsers()},
+		exporters.Sheet{Name: "Users", Data: testUsers()[:1]},
+		exporters.Sheet{Name: "Locations: <all>", Data: []Location{{Building: "HQ & Annex", Floor: 1}}},
+	)
+	if err != nil {
+		t.Fatalf("WriteXLSX() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Output is not a valid zip archive: %v", err)
+	}
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "xl/workbook.xml", "xl/styles.xml", "xl/worksheets/sheet3.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Missing %s", name)
+		}
+	}
+
+	workbook := files["xl/workbook.xml"]
+	for _, name := range []string{`name="Users"`, `name="Users (2)"`, `name="Locations- &lt;all&gt;"`} {
+		if !strings.Contains(workbook, name) {
+			t.Errorf("Expected workbook to contain %s, got %s", name, workbook)
+		}
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, `<c r="A2" s="0"><v>1</v></c>`) || !strings.Contains(sheet, `<c r="C2" s="0" t="b"><v>1</v></c>`) {
+		t.Errorf("Expected typed number and boolean cells, got %s", sheet)
+	}
+	if !strings.Contains(files["xl/worksheets/sheet3.xml"], "HQ &amp; Annex") {
+		t.Error("Expected cell text to be XML-escaped")
+	}
+}
+
+func TestExport(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := exporters.Export(filepath.Join(dir, "report.txt"), exporters.Sheet{Data: testUsers()}); err == nil {
+		t.Error("Expected an error for an unsupported extension")
+	}
+
+	err := exporters.Export(filepath.Join(dir, "report.csv"),
+		exporters.Sheet{Name: "users", Data: testUsers()},
+		exporters.Sheet{Name: "locations", Data: []Location{{Building: "HQ"}}},
+	)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "report-*.csv"))
+	if len(matches) != 2 {
+		t.Errorf("Expected one CSV file per sheet, got %v", matches)
+	}
+}
+
+type Device struct {
+	Serial   string    `csv:"Serial Number,order=2"`
+	Model    string    `csv:"Model"`
+	Owner    Location  `csv:"owner,order=3"`
+	Email    string    `csv:"Email,order=1"`
+	LastSeen time.Time `csv:"Last Seen"`
+}
+
+func TestColumnOrder(t *testing.T) {
+	table, err := exporters.NewTable([]Device{{Serial: "C02", Email: "ada@example.com"}})
+	if err != nil {
+		t.Fatalf("NewTable() error = %v", err)
+	}
+
+	headers := []string{"Email", "Serial Number", "owner.building", "owner.floor", "Model", "Last Seen"}
+	if !slices.Equal(table.Headers, headers) {
+		t.Errorf("Headers = %v, want %v", table.Headers, headers)
+	}
+	if table.Rows[0][0].String() != "ada@example.com" || table.Rows[0][1].String() != "C02" {
+		t.Errorf("Expected the cells to follow their columns, got %+v", table.Rows[0])
+	}
+}
+
+func TestWrite(t *testing.T) {
+	devices := []*Device{{Serial: "C02", Model: "MacBook Pro", Email: "ada@example.com"}}
+
+	var buf bytes.Buffer
+	if err := exporters.Write(&buf, exporters.CSV, devices); err != nil {
+		t.Fatalf("Write(CSV) error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "Email,Serial Number,owner.building,owner.floor,Model,Last Seen\nada@example.com,C02,,0,MacBook Pro,\n") {
+		t.Errorf("Write(CSV) = %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := exporters.Write(&buf, exporters.XLSX, devices); err != nil {
+		t.Fatalf("Write(XLSX) error = %v", err)
+	}
+	if _, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		t.Errorf("Write(XLSX) is not a valid zip archive: %v", err)
+	}
+
+	if err := exporters.Write(&buf, exporters.Format("pdf"), devices); err == nil {
+		t.Error("Write() accepted an unsupported format")
+	}
+}
+
+type Account struct {
+	Name    string  `csv:"Name"`
+	Balance float64 `csv:"Balance"`
+}
+
+func TestFormulaInjection(t *testing.T) {
+	accounts := []Account{
+		{Name: `=HYPERLINK("http://evil.example","x")`, Balance: -5},
+		{Name: "+1"},
+		{Name: "-2"},
+		{Name: "@SUM(A1)"},
+		{Name: "Ada = Lovelace"},
+	}
+
+	var buf bytes.Buffer
+	if err := exporters.Write(&buf, exporters.CSV, accounts); err != nil {
+		t.Fatalf("Write(CSV) error = %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	names := []string{}
+	for _, record := range records[1:] {
+		names = append(names, record[0])
+	}
+	want := []string{`'=HYPERLINK("http://evil.example","x")`, "'+1", "'-2", "'@SUM(A1)", "Ada = Lovelace"}
+	if !slices.Equal(names, want) {
+		t.Errorf("Names = %q, want %q", names, want)
+	}
+	// Numbers are not text, so their sign is kept
+	if records[1][1] != "-5" {
+		t.Errorf("Balance = %q, want -5", records[1][1])
+	}
+
+	buf.Reset()
+	if err := exporters.Write(&buf, exporters.XLSX, accounts); err != nil {
+		t.Fatalf("Write(XLSX) error = %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Write(XLSX) is not a valid zip archive: %v", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		rc, _ := f.Open()
+		sheet, _ := io.ReadAll(rc)
+		rc.Close()
+		for _, text := range []string{`>&#39;=HYPERLINK(`, `>&#39;+1<`, `>&#39;-2<`, `>&#39;@SUM(A1)<`, `>Ada = Lovelace<`, `<v>-5</v>`} {
+			if !strings.Contains(string(sheet), text) {
+				t.Errorf("Expected the worksheet to contain %s, got %s", text, sheet)
+			}
+		}
+	}
+}
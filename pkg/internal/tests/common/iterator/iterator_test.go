@@ -0,0 +1,284 @@
+/*
+# Iterator - Test
+
+This package tests the lazy sequences of paginated list endpoints, alone and through the fake provider APIs
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/internal/tests/common/iterator/iterator_test.go
+package iterator_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/backupify"
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/testutil"
+)
+
+// cancelAfterFirst cancels the context at the first item of a sequence, returning the error ending the sequence
+func cancelAfterFirst[V any](seq func(ctx context.Context) iterator.Seq[V]) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	return iterator.ForEach(seq(ctx), func(V) error {
+		cancel()
+		return nil
+	})
+}
+
+// numbers returns a sequence of 0..total-1 in pages of `size`, counting the pages fetched
+func numbers(total, size int, fetched *int, failAt int) iterator.Seq[int] {
+	return iterator.Pages(func(cursor string) ([]int, string, error) {
+		start, _ := strconv.Atoi(cursor)
+		*fetched++
+		if *fetched == failAt {
+			return nil, "", errors.New("page failed")
+		}
+
+		items := []int{}
+		for i := start; i < start+size && i < total; i++ {
+			items = append(items, i)
+		}
+		if start+size >= total {
+			return items, "", nil
+		}
+		return items, strconv.Itoa(start + size), nil
+	})
+}
+
+func TestPages(t *testing.T) {
+	fetched := 0
+	items, err := iterator.Collect(numbers(25, 10, &fetched, 0))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(items) != 25 || items[24] != 24 || fetched != 3 {
+		t.Errorf("Expected 25 items over 3 pages, got %d over %d", len(items), fetched)
+	}
+
+	// Stopping early requests no further pages
+	fetched = 0
+	seen := 0
+	numbers(25, 10, &fetched, 0)(func(i int, err error) bool {
+		seen++
+		return i < 4
+	})
+	if seen != 5 || fetched != 1 {
+		t.Errorf("Expected to stop on the first page, saw %d items over %d pages", seen, fetched)
+	}
+
+	fetched = 0
+	items, err = iterator.Collect(iterator.Take(numbers(25, 10, &fetched, 0), 12))
+	if err != nil || len(items) != 12 || fetched != 2 {
+		t.Errorf("Expected 12 items over 2 pages, got %d over %d (%v)", len(items), fetched, err)
+	}
+
+	fetched = 0
+	items, _ = iterator.Collect(iterator.Filter(numbers(25, 10, &fetched, 0), func(i int) bool { return i%5 == 0 }))
+	if fmt.Sprint(items) != "[0 5 10 15 20]" {
+		t.Errorf("Unexpected filtered items: %v", items)
+	}
+
+	// An error ends the sequence, after the items of the previous pages
+	fetched = 0
+	count := 0
+	err = iterator.ForEach(numbers(25, 10, &fetched, 2), func(int) error {
+		count++
+		return nil
+	})
+	if err == nil || count != 10 {
+		t.Errorf("Expected the error of the second page after 10 items, got %v after %d", err, count)
+	}
+	if _, err := iterator.Collect(iterator.Error[int](errors.New("invalid query"))); err == nil {
+		t.Error("Expected the error of the sequence")
+	}
+}
+
+func TestOnPage(t *testing.T) {
+	fetched := 0
+	sizes := []int{}
+	err := iterator.OnPage(numbers(25, 10, &fetched, 0), 8, func(page []int) error {
+		sizes = append(sizes, len(page))
+		return nil
+	})
+	if err != nil || fmt.Sprint(sizes) != "[8 8 8 1]" {
+		t.Errorf("Expected pages of 8, got %v (%v)", sizes, err)
+	}
+
+	// The items before an error are still passed on, and the error is returned
+	fetched = 0
+	sizes = []int{}
+	err = iterator.OnPage(numbers(25, 10, &fetched, 2), 4, func(page []int) error {
+		sizes = append(sizes, len(page))
+		return nil
+	})
+	if err == nil || fmt.Sprint(sizes) != "[4 4 2]" {
+		t.Errorf("Expected the first 10 items then the error, got %v (%v)", sizes, err)
+	}
+
+	// An error of the callback stops the sequence
+	fetched = 0
+	calls := 0
+	err = iterator.OnPage(numbers(25, 10, &fetched, 0), 10, func(page []int) error {
+		calls++
+		return errors.New("write failed")
+	})
+	if err == nil || calls != 1 || fetched != 1 {
+		t.Errorf("Expected to stop after the first page, got %d calls over %d pages (%v)", calls, fetched, err)
+	}
+}
+
+func TestOktaIterators(t *testing.T) {
+	o := testutil.NewOkta(t)
+	for i := 0; i < 450; i++ {
+		o.AddUser(&okta.User{ID: fmt.Sprintf("00u%03d", i), Status: "ACTIVE", Profile: &okta.UserProfile{Email: fmt.Sprintf("user%d@example.com", i)}})
+	}
+
+	c := o.NewClient(t, log.INFO)
+	seen := 0
+	err := iterator.ForEach(c.IterAllUsers(), func(u *okta.User) error {
+		seen++
+		if u.ID == "00u249" {
+			return errors.New("found")
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "found" || seen != 250 {
+		t.Fatalf("Expected to stop at the 250th user, got %v after %d", err, seen)
+	}
+	if requests := o.Requests(); len(requests) != 2 {
+		t.Errorf("Expected only the first 2 pages to be requested, got %d requests", len(requests))
+	}
+
+	users, err := iterator.Collect(c.IterActiveUsers())
+	if err != nil || len(users) != 450 {
+		t.Errorf("Expected 450 active users, got %d (%v)", len(users), err)
+	}
+
+	// Each page is processed before the next one is requested
+	before := len(o.Requests())
+	pages := []string{}
+	err = iterator.OnPage(c.IterAllUsers(), 200, func(page []*okta.User) error {
+		pages = append(pages, fmt.Sprintf("%d users after %d requests", len(page), len(o.Requests())-before))
+		return nil
+	})
+	if err != nil || fmt.Sprint(pages) != "[200 users after 1 requests 200 users after 2 requests 50 users after 3 requests]" {
+		t.Errorf("Unexpected pages: %v (%v)", pages, err)
+	}
+}
+
+func TestGoogleIterators(t *testing.T) {
+	g := testutil.NewGoogle(t)
+	for i := 0; i < 600; i++ {
+		g.AddUser(&google.User{ID: fmt.Sprint(i), PrimaryEmail: fmt.Sprintf("user%d@example.com", i)})
+	}
+
+	c := g.NewClient(t, log.INFO)
+	users, err := iterator.Collect(c.Users().IterUsers(&google.UserQuery{MaxResults: 250}))
+	if err != nil || len(users) != 600 {
+		t.Fatalf("Expected 600 users, got %d (%v)", len(users), err)
+	}
+
+	if _, err := iterator.Collect(c.Users().IterUsers(&google.UserQuery{Customer: "my_customer", Domain: "example.com"})); err == nil {
+		t.Error("Expected the invalid query to be rejected")
+	}
+}
+
+func TestBackupifyIterators(t *testing.T) {
+	b := testutil.NewBackupify(t)
+	for i := 1; i <= 101; i++ {
+		b.AddUser(backupify.GoogleDrive, &backupify.User{ID: i, Email: fmt.Sprintf("user%d@example.com", i), UsedBytes: "1 KB"})
+	}
+
+	c := b.NewClient(t, log.INFO)
+	emails := map[string]bool{}
+	err := iterator.ForEach(c.Users().IterUsers(backupify.GoogleDrive), func(u *backupify.User) error {
+		if emails[u.Email] {
+			return fmt.Errorf("%s was yielded twice", u.Email)
+		}
+		if u.UsedBytesFloat != 1000 {
+			return fmt.Errorf("%s uses %v bytes", u.Email, u.UsedBytesFloat)
+		}
+		emails[u.Email] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(emails) != 101 {
+		t.Errorf("Expected 101 users, got %d", len(emails))
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	o := testutil.NewOkta(t)
+	g := testutil.NewGoogle(t)
+	b := testutil.NewBackupify(t)
+	for i := 0; i < 450; i++ {
+		o.AddUser(&okta.User{ID: fmt.Sprintf("00u%03d", i), Status: "ACTIVE", Profile: &okta.UserProfile{Email: fmt.Sprintf("user%d@example.com", i)}})
+		g.AddUser(&google.User{ID: fmt.Sprint(i), PrimaryEmail: fmt.Sprintf("user%d@example.com", i)})
+		b.AddUser(backupify.GoogleDrive, &backupify.User{ID: i + 1, Email: fmt.Sprintf("user%d@example.com", i), UsedBytes: "1 KB"})
+	}
+	oc, gc, bc := o.NewClient(t, log.INFO), g.NewClient(t, log.INFO), b.NewClient(t, log.INFO)
+	tokens := len(g.Requests())
+
+	// The pages after the one being processed are not requested
+	tests := []struct {
+		name   string
+		server *testutil.Server
+		before int
+		err    error
+	}{
+		{"Okta", o.Server, 0, cancelAfterFirst(func(ctx context.Context) iterator.Seq[*okta.User] { return oc.WithContext(ctx).IterAllUsers() })},
+		{"Google", g.Server, tokens, cancelAfterFirst(func(ctx context.Context) iterator.Seq[*google.User] {
+			return gc.WithContext(ctx).Users().IterUsers(&google.UserQuery{MaxResults: 100})
+		})},
+		{"Backupify", b.Server, 0, cancelAfterFirst(func(ctx context.Context) iterator.Seq[*backupify.User] {
+			return bc.WithContext(ctx).Users().IterUsers(backupify.GoogleDrive)
+		})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, context.Canceled) {
+				t.Errorf("Expected the sequence to end with context.Canceled, got %v", tt.err)
+			}
+			if requests := len(tt.server.Requests()) - tt.before; requests != 1 {
+				t.Errorf("Expected only the first page to be requested, got %d requests", requests)
+			}
+		})
+	}
+
+	// The request in flight is aborted, and not retried
+	o.Fail(testutil.Fault{Method: "GET", Path: "/api/v1/users", Delay: time.Second, Times: 1})
+	before := len(o.Requests())
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := iterator.Collect(oc.WithContext(ctx).IterAllUsers())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the request to be aborted promptly, took %v", elapsed)
+	}
+	if requests := len(o.Requests()) - before; requests != 1 {
+		t.Errorf("Expected a single request, got %d", requests)
+	}
+
+	// The client itself is not bound to the context
+	if users, err := iterator.Collect(oc.IterAllUsers()); err != nil || len(users) != 450 {
+		t.Errorf("Expected 450 users, got %d (%v)", len(users), err)
+	}
+}
@@ -0,0 +1,81 @@
+// pkg/internal/tests/common/log/levels_test.go
+package log_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+)
+
+func TestSetLevel(t *testing.T) {
+	defer log.SetLevels("")
+
+	messages := []string{}
+	handler := log.HandlerFunc(func(level int, prefix, message string) { messages = append(messages, prefix+" "+message) })
+	okta := log.NewHandlerLogger("{okta}", log.INFO, handler)
+	google := log.NewHandlerLogger("{google}", log.INFO, handler)
+
+	log.SetLevel("okta", log.DEBUG)
+	log.SetLevel("*", log.ERROR)
+	okta.Debug("Listing users")
+	google.Print("Listing users")
+	google.Error("Unable to list users")
+
+	log.ResetLevel("okta")
+	okta.Debug("Hidden")
+	okta.Error("Unable to list groups")
+
+	want := []string{"{okta} Listing users", "{google} Unable to list users", "{okta} Unable to list groups"}
+	if len(messages) != len(want) {
+		t.Fatalf("Expected %q, got %q", want, messages)
+	}
+	for i := range want {
+		if messages[i] != want[i] {
+			t.Errorf("Message %d = %q, want %q", i, messages[i], want[i])
+		}
+	}
+}
+
+func TestSetLevels(t *testing.T) {
+	defer log.SetLevels("")
+
+	if err := log.SetLevels("okta=debug, google=WARN\n# comment\nrequests=0"); err != nil {
+		t.Fatalf("SetLevels() error = %v", err)
+	}
+	levels := log.Levels()
+	if levels["okta"] != log.DEBUG || levels["google"] != log.WARNING || levels["requests"] != log.TRACE {
+		t.Errorf("Levels() = %v", levels)
+	}
+
+	if err := log.SetLevels("okta=LOUD"); err == nil {
+		t.Error("SetLevels() accepted an invalid level")
+	}
+	if log.Levels()["okta"] != log.DEBUG {
+		t.Error("SetLevels() changed the levels despite an invalid entry")
+	}
+}
+
+func TestReloadOnSIGHUP(t *testing.T) {
+	defer log.SetLevels("")
+
+	path := filepath.Join(t.TempDir(), "log_levels")
+	if err := os.WriteFile(path, []byte("okta=TRACE\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stop := log.ReloadOnSIGHUP(path, func(err error) { t.Error(err) })
+	defer stop()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if level, ok := log.Levels()["okta"]; ok && level == log.TRACE {
+			return
+		}
+	}
+	t.Errorf("Levels() = %v, want the levels of the file after SIGHUP", log.Levels())
+}
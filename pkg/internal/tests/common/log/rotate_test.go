@@ -0,0 +1,114 @@
+// pkg/internal/tests/common/log/rotate_test.go
+package log_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	f := &log.RotatingFile{Path: filepath.Join(dir, "rego.log"), MaxSize: 64, MaxBackups: 2, Compress: true}
+
+	l := log.NewLogger("{test}", log.INFO)
+	l.Color = false
+	l.SetRotatingFile(f)
+	for i := 0; i < 4; i++ {
+		l.Printf("entry %d %s", i, strings.Repeat("x", 20))
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	current, err := os.ReadFile(f.Path)
+	if err != nil || !strings.Contains(string(current), "entry 3") || strings.Contains(string(current), "entry 2") {
+		t.Errorf("Expected the last entry alone in the file, got %q (%v)", current, err)
+	}
+
+	rotated, _ := filepath.Glob(filepath.Join(dir, "rego-*.log.gz"))
+	if len(rotated) != 2 {
+		t.Fatalf("Expected the 2 newest rotated files to be kept, compressed, got %q", rotated)
+	}
+	zr, err := gzip.NewReader(openFile(t, rotated[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(zr)
+	if !strings.Contains(string(data), "entry 2") {
+		t.Errorf("Expected the newest rotated file to hold entry 2, got %q", data)
+	}
+}
+
+func TestRotatingFileKeepsEveryEntry(t *testing.T) {
+	dir := t.TempDir()
+	f := &log.RotatingFile{Path: filepath.Join(dir, "burst.log"), MaxSize: 10}
+
+	// Entries rotated within the same millisecond get files of their own
+	want := 0
+	for i := 0; i < 5; i++ {
+		n, _ := f.Write([]byte(strings.Repeat(string(rune('a'+i)), 16) + "\n"))
+		want += n
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "burst*.log"))
+	got := 0
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		got += len(data)
+	}
+	if len(files) != 5 || got != want {
+		t.Errorf("Expected 5 files holding the %d bytes written, got %d files holding %d bytes", want, len(files), got)
+	}
+}
+
+func TestRotatingFileInterval(t *testing.T) {
+	dir := t.TempDir()
+	f := &log.RotatingFile{Path: filepath.Join(dir, "daemon.log"), Interval: 20 * time.Millisecond}
+	defer f.Close()
+
+	f.Write([]byte("before\n"))
+	time.Sleep(30 * time.Millisecond)
+	f.Write([]byte("after\n"))
+
+	rotated, _ := filepath.Glob(filepath.Join(dir, "daemon-*.log"))
+	if len(rotated) != 1 {
+		t.Fatalf("Expected the file to be rotated once its interval passed, got %q", rotated)
+	}
+	if data, _ := os.ReadFile(rotated[0]); string(data) != "before\n" {
+		t.Errorf("Rotated file = %q, want the entry written before", data)
+	}
+}
+
+func openFile(t *testing.T, path string) *os.File {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+func TestSetDefaultFile(t *testing.T) {
+	f := &log.RotatingFile{Path: filepath.Join(t.TempDir(), "rego.log")}
+	log.SetDefaultFile(f)
+	defer log.SetDefaultFile(nil)
+
+	l := log.NewLogger("{test}", log.INFO)
+	l.Color = false
+	l.Println("to the default file")
+	f.Close()
+
+	if data, _ := os.ReadFile(f.Path); !strings.Contains(string(data), "to the default file") {
+		t.Errorf("Expected a new logger to write to the default file, got %q", data)
+	}
+}
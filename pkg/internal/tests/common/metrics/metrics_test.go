@@ -0,0 +1,77 @@
+// pkg/internal/tests/common/metrics/metrics_test.go
+package metrics_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/metrics"
+)
+
+func TestPrometheus(t *testing.T) {
+	p := metrics.NewPrometheus()
+	p.Buckets = []float64{0.1, 1}
+
+	p.Add(metrics.RequestsTotal, 1, metrics.Label{Name: "status", Value: "200"}, metrics.Label{Name: "host", Value: "example.okta.com"})
+	p.Add(metrics.RequestsTotal, 2, metrics.Label{Name: "host", Value: "example.okta.com"}, metrics.Label{Name: "status", Value: "200"})
+	p.Observe(metrics.RateLimitWaitDuration, 0.05, metrics.Label{Name: "limiter", Value: "queue"})
+	p.Observe(metrics.RateLimitWaitDuration, 0.5, metrics.Label{Name: "limiter", Value: "queue"})
+	p.Observe(metrics.RateLimitWaitDuration, 5, metrics.Label{Name: "limiter", Value: "queue"})
+
+	var sb strings.Builder
+	if err := p.WriteMetrics(&sb); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# HELP rego_requests_total Number of requests sent to the APIs.\n",
+		"# TYPE rego_requests_total counter\n",
+		`rego_requests_total{host="example.okta.com",status="200"} 3` + "\n",
+		"# TYPE rego_ratelimit_wait_seconds histogram\n",
+		`rego_ratelimit_wait_seconds_bucket{limiter="queue",le="0.1"} 1` + "\n",
+		`rego_ratelimit_wait_seconds_bucket{limiter="queue",le="1"} 2` + "\n",
+		`rego_ratelimit_wait_seconds_bucket{limiter="queue",le="+Inf"} 3` + "\n",
+		`rego_ratelimit_wait_seconds_sum{limiter="queue"} 5.55` + "\n",
+		`rego_ratelimit_wait_seconds_count{limiter="queue"} 3` + "\n",
+	} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("WriteMetrics() is missing %q:\n%s", want, sb.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") || !strings.Contains(rec.Body.String(), "rego_requests_total") {
+		t.Errorf("ServeHTTP() = %q, %q", rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+}
+
+func TestCacheMetrics(t *testing.T) {
+	p := metrics.NewPrometheus()
+	metrics.SetRecorder(p)
+	defer metrics.SetRecorder(nil)
+
+	c, err := cache.NewCache([]byte("8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt"), true, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Get("key")
+	if err := c.Set("key", []byte("value"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	c.Get("key")
+	c.Get("key")
+
+	var sb strings.Builder
+	p.WriteMetrics(&sb)
+	for _, want := range []string{
+		`rego_cache_hits_total{cache="memory"} 2`,
+		`rego_cache_misses_total{cache="memory"} 1`,
+	} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("WriteMetrics() is missing %q:\n%s", want, sb.String())
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// pkg/internal/tests/common/normalize/normalize_test.go
+package normalize_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/exporters"
+	"github.com/gemini-oss/rego/pkg/common/normalize"
+	"github.com/gemini-oss/rego/pkg/duo"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+)
+
+func TestParse(t *testing.T) {
+	want := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2024-05-01T10:00:00Z", want},
+		{"2024-05-01T10:00:00.000Z", want},
+		{"2024-05-01T12:00:00.000+0200", want},
+		{"2024-05-01 10:00:00", want},
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"", time.Time{}},
+		{"1970-01-01T00:00:00.000Z", time.Time{}}, // Google accounts which never signed in
+	}
+	for _, tt := range tests {
+		got, err := normalize.Parse(tt.value)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("Parse(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := normalize.Parse("yesterday"); err == nil {
+		t.Error("Parse() of an unrecognized timestamp should fail")
+	}
+}
+
+func TestTimeJSON(t *testing.T) {
+	var u google.User
+	if err := json.Unmarshal([]byte(`{"creationTime":"2024-05-01T10:00:00.000Z","lastLoginTime":"1970-01-01T00:00:00.000Z"}`), &u); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if !u.CreationTime.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("CreationTime = %v", u.CreationTime)
+	}
+	if !u.LastLoginTime.IsZero() {
+		t.Errorf("LastLoginTime = %v, want the zero time", u.LastLoginTime)
+	}
+
+	b, err := json.Marshal(struct {
+		Created normalize.Time `json:"created"`
+		Deleted normalize.Time `json:"deleted"`
+	}{Created: u.CreationTime})
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	if want := `{"created":"2024-05-01T10:00:00Z","deleted":null}`; string(b) != want {
+		t.Errorf("Marshal() = %s, want %s", b, want)
+	}
+}
+
+func TestTimeExport(t *testing.T) {
+	table, err := exporters.NewTable([]*google.User{
+		{PrimaryEmail: "ada@example.com", LastLoginTime: normalize.NewTime(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))},
+	})
+	if err != nil {
+		t.Fatalf("NewTable() error: %v", err)
+	}
+
+	for i, header := range table.Headers {
+		if header != "lastLoginTime" {
+			continue
+		}
+		if cell := table.Rows[0][i]; cell.Kind != exporters.KindTime {
+			t.Errorf("lastLoginTime cell = %+v, want a time", cell)
+		}
+		return
+	}
+	t.Errorf("NewTable() headers %v have no lastLoginTime column", table.Headers)
+}
+
+func TestAccountStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		got  normalize.Status
+		want normalize.Status
+	}{
+		{"okta active", (&okta.User{Status: "ACTIVE"}).AccountStatus(), normalize.StatusActive},
+		{"okta password expired", (&okta.User{Status: "PASSWORD_EXPIRED"}).AccountStatus(), normalize.StatusActive},
+		{"okta staged", (&okta.User{Status: "STAGED"}).AccountStatus(), normalize.StatusPending},
+		{"okta locked out", (&okta.User{Status: "LOCKED_OUT"}).AccountStatus(), normalize.StatusLocked},
+		{"okta deprovisioned", (&okta.User{Status: "DEPROVISIONED"}).AccountStatus(), normalize.StatusDeprovisioned},
+		{"okta unknown", (&okta.User{Status: "NEW"}).AccountStatus(), normalize.StatusUnknown},
+		{"google active", (&google.User{}).AccountStatus(), normalize.StatusActive},
+		{"google archived", (&google.User{Archived: true}).AccountStatus(), normalize.StatusSuspended},
+		{"duo bypass", (&duo.User{Status: "bypass"}).AccountStatus(), normalize.StatusActive},
+		{"duo disabled", (&duo.User{Status: "disabled"}).AccountStatus(), normalize.StatusSuspended},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: AccountStatus() = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if !normalize.StatusDeprovisioned.Disabled() || normalize.StatusLocked.Disabled() {
+		t.Error("Disabled() should only hold for suspended and deprovisioned accounts")
+	}
+}
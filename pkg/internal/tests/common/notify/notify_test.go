@@ -0,0 +1,182 @@
+// pkg/internal/tests/common/notify/notify_test.go
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/notify"
+	"github.com/gemini-oss/rego/pkg/common/scheduler"
+)
+
+// recorder collects the messages it is sent
+type recorder struct {
+	messages []*notify.Message
+}
+
+func (r *recorder) Notify(_ context.Context, m *notify.Message) error {
+	r.messages = append(r.messages, m)
+	return nil
+}
+
+// capture serves a single endpoint, recording the JSON bodies posted to it
+func capture(t *testing.T, response string) (*httptest.Server, *[]map[string]interface{}) {
+	bodies := []map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body := map[string]interface{}{}
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("body is not JSON: %s", data)
+		}
+		body["_authorization"] = r.Header.Get("Authorization")
+		bodies = append(bodies, body)
+		w.Write([]byte(response))
+	}))
+	return server, &bodies
+}
+
+func TestRender(t *testing.T) {
+	data := struct {
+		Group   string
+		Members []string
+	}{"engineering", []string{"a@example.com", "b@example.com"}}
+
+	m, err := notify.Render(notify.Template{
+		Title:    "Drift in {{upper .Group}}",
+		Body:     "{{join .Members \", \"}}",
+		Severity: notify.Warning,
+		Fields:   []notify.Field{{Name: "Members", Value: "{{len .Members}}"}},
+	}, data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if m.Title != "Drift in ENGINEERING" || m.Body != "a@example.com, b@example.com" || m.Severity != notify.Warning || m.Fields[0].Value != "2" {
+		t.Errorf("Render() = %+v", m)
+	}
+
+	if _, err := notify.Render(notify.Template{Title: "{{.Missing}"}, data); err == nil {
+		t.Error("Render() of an invalid template succeeded")
+	}
+}
+
+func TestAtLeastAndAll(t *testing.T) {
+	all, failures := &recorder{}, &recorder{}
+	n := notify.All(all, notify.AtLeast(notify.Failure, failures))
+
+	for _, s := range []notify.Severity{notify.Info, notify.Success, notify.Failure} {
+		n.Notify(context.Background(), &notify.Message{Title: string(s), Severity: s})
+	}
+	if len(all.messages) != 3 || len(failures.messages) != 1 || failures.messages[0].Title != "failure" {
+		t.Errorf("All received %d, AtLeast(Failure) received %d", len(all.messages), len(failures.messages))
+	}
+}
+
+func TestSlack(t *testing.T) {
+	server, bodies := capture(t, `{"ok":false,"error":"channel_not_found"}`)
+	defer server.Close()
+
+	m := &notify.Message{Title: "job failed", Body: "boom", Severity: notify.Failure, Fields: []notify.Field{{Name: "Runs", Value: "3"}}}
+	if err := notify.NewSlack(notify.SlackConfig{WebhookURL: server.URL}).Notify(context.Background(), m); err != nil {
+		t.Fatalf("Notify() through a webhook error = %v", err)
+	}
+	err := notify.NewSlack(notify.SlackConfig{Token: "xoxb-1", Channel: "#it", BaseURL: server.URL}).Notify(context.Background(), m)
+	if err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("Notify() through the Web API error = %v, want channel_not_found", err)
+	}
+
+	webhook, api := (*bodies)[0], (*bodies)[1]
+	if blocks := webhook["blocks"].([]interface{}); len(blocks) != 3 || webhook["channel"] != nil {
+		t.Errorf("webhook payload = %v", webhook)
+	}
+	if api["channel"] != "#it" || api["_authorization"] != "Bearer xoxb-1" {
+		t.Errorf("Web API payload = %v", api)
+	}
+}
+
+func TestPagerDutyTriggersAndResolves(t *testing.T) {
+	server, bodies := capture(t, `{"status":"success"}`)
+	defer server.Close()
+
+	pd := notify.NewPagerDuty(notify.PagerDutyConfig{RoutingKey: "key", URL: server.URL})
+	hook := notify.JobHook(pd, false)
+	hook(scheduler.JobStatus{Name: "inventory.refresh", Runs: 1, Failures: 1, LastError: "timed out"})
+	hook(scheduler.JobStatus{Name: "inventory.refresh", Runs: 2, Failures: 1})
+
+	if len(*bodies) != 2 {
+		t.Fatalf("sent %d events, want 2", len(*bodies))
+	}
+	trigger, resolve := (*bodies)[0], (*bodies)[1]
+	payload := trigger["payload"].(map[string]interface{})
+	if trigger["event_action"] != "trigger" || trigger["dedup_key"] != "scheduler/inventory.refresh" || payload["severity"] != "error" || payload["summary"] != "inventory.refresh failed" {
+		t.Errorf("trigger = %v", trigger)
+	}
+	if resolve["event_action"] != "resolve" || resolve["dedup_key"] != trigger["dedup_key"] || resolve["payload"] != nil {
+		t.Errorf("resolve = %v", resolve)
+	}
+}
+
+func TestJobHookFailuresOnly(t *testing.T) {
+	r := &recorder{}
+	hook := notify.JobHook(r, true)
+	hook(scheduler.JobStatus{Name: "ok", Runs: 1, LastDuration: time.Second})
+	hook(scheduler.JobStatus{Name: "broken", Runs: 1, Failures: 1, LastError: "boom"})
+
+	if len(r.messages) != 1 || r.messages[0].Title != "broken failed" || r.messages[0].Body != "boom" || r.messages[0].Severity != notify.Failure {
+		t.Errorf("messages = %+v", r.messages)
+	}
+}
+
+func TestEmail(t *testing.T) {
+	var from string
+	var raw []byte
+	e := notify.NewEmail(notify.EmailConfig{
+		From: "rego@example.com",
+		To:   []string{"it@example.com", "sec@example.com"},
+		Send: func(_ context.Context, f string, r []byte) error {
+			from, raw = f, r
+			return nil
+		},
+	})
+
+	m := &notify.Message{Title: "offboarding failed for ünïcode@example.com", Body: "okta.deactivate: boom", Severity: notify.Failure, Link: "https://example.com/r/1"}
+	if err := e.Notify(context.Background(), m); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	message := string(raw)
+	for _, want := range []string{
+		"To: it@example.com, sec@example.com\r\n",
+		"Subject: =?utf-8?q?[FAILURE]_offboarding_failed_for_=C3=BCn=C3=AFcode@example.com?=\r\n",
+		"Content-Transfer-Encoding: quoted-printable\r\n\r\n",
+		"okta.deactivate: boom\r\n",
+		"https://example.com/r/1",
+	} {
+		if !strings.Contains(message, want) {
+			t.Errorf("email does not contain %q:\n%s", want, message)
+		}
+	}
+	if from != "rego@example.com" {
+		t.Errorf("sent from %s", from)
+	}
+}
+
+func TestGraph(t *testing.T) {
+	server, bodies := capture(t, ``)
+	defer server.Close()
+
+	g := notify.NewGraph(notify.GraphConfig{Token: "token", From: "rego@example.com", To: []string{"it@example.com"}, BaseURL: server.URL})
+	if err := g.Notify(context.Background(), &notify.Message{Title: "report ready"}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	message := (*bodies)[0]["message"].(map[string]interface{})
+	if message["subject"] != "report ready" || (*bodies)[0]["_authorization"] != "Bearer token" {
+		t.Errorf("payload = %v", (*bodies)[0])
+	}
+}
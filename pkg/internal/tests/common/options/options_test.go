@@ -0,0 +1,224 @@
+// pkg/internal/tests/common/options/options_test.go
+package options_test
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/backupify"
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/enum"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/options"
+	"github.com/gemini-oss/rego/pkg/common/ratelimit"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/version"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/testutil"
+)
+
+// counter counts the requests sent through it
+type counter struct {
+	n    int64
+	next http.RoundTripper
+}
+
+func (c *counter) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt64(&c.n, 1)
+	return c.next.RoundTrip(req)
+}
+
+func memoryCache(t *testing.T) *cache.Cache {
+	t.Helper()
+	c, err := cache.NewCache([]byte(testutil.EncryptionKey), true, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestNew(t *testing.T) {
+	o := options.New(options.WithBaseURL("https://a.example.com"), nil, options.WithBaseURL("https://b.example.com"))
+	if o.BaseURL != "https://b.example.com" {
+		t.Errorf("Expected the last option to apply, got %s", o.BaseURL)
+	}
+	if l := o.Log("{test}", log.DEBUG); l == nil || l.Verbosity != log.DEBUG {
+		t.Errorf("Expected a new logger at the verbosity, got %v", l)
+	}
+
+	logger := log.NewHandlerLogger("{test}", log.ERROR, log.HandlerFunc(func(int, string, string) {}))
+	if l := options.New(options.WithLogger(logger)).Log("{test}", log.DEBUG); l != logger {
+		t.Error("Expected the logger of the options")
+	}
+}
+
+func TestProxy(t *testing.T) {
+	p := &requests.Proxy{HTTPS: "http://proxy.example.com:3128"}
+	o := options.New(options.WithProxy(p))
+	transport, ok := o.HTTPClient.Transport.(*http.Transport)
+	if !ok || transport.Proxy == nil {
+		t.Fatalf("Expected the HTTP client to be proxied, got %+v", o.HTTPClient)
+	}
+	req, _ := http.NewRequest("GET", "https://example.okta.com/api/v1/users", nil)
+	if u, err := transport.Proxy(req); err != nil || u == nil || u.Host != "proxy.example.com:3128" {
+		t.Errorf("Proxy() = %v, %v", u, err)
+	}
+	if o.HTTPClient.Transport == http.DefaultTransport {
+		t.Error("Expected the default transport to be cloned, rather than changed")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	p := &requests.Proxy{HTTPS: "http://proxy.example.com:3128"}
+	o := options.New(options.WithProxy(p), options.WithTransport(requests.Transport{MaxIdleConnsPerHost: 50, Protocol: requests.HTTP1}))
+	transport, ok := o.HTTPClient.Transport.(*http.Transport)
+	if !ok || transport.MaxIdleConnsPerHost != 50 || transport.ForceAttemptHTTP2 {
+		t.Fatalf("Expected the settings to be applied to the HTTP client, got %+v", o.HTTPClient)
+	}
+	if transport.Proxy == nil {
+		t.Error("Expected the HTTP client to keep its proxy")
+	}
+}
+
+func TestProviderOptions(t *testing.T) {
+	// Neither the org, the node nor the encryption key are read from the environment
+	for _, env := range []string{"OKTA_ORG_NAME", "OKTA_BASE_URL", "BACKUPIFY_NODE_URL", "BACKUPIFY_CUSTOMER_ID", "REGO_ENCRYPTION_KEY"} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("OKTA_API_TOKEN", "testutil")
+	t.Setenv("BACKUPIFY_EXPORT_TOKEN", testutil.BackupifyExportToken)
+	t.Setenv("BACKUPIFY_PHPSESSID", testutil.BackupifySession)
+
+	fakeOkta := testutil.NewOkta(t)
+	fakeBackupify := testutil.NewBackupify(t)
+
+	sent := &counter{next: http.DefaultTransport}
+	rl := ratelimit.NewRateLimiter()
+	c := memoryCache(t)
+	logger := log.NewHandlerLogger("{custom}", log.ERROR, log.HandlerFunc(func(int, string, string) {}))
+
+	o := okta.NewClient(log.INFO,
+		options.WithBaseURL(fakeOkta.URL+"/api/v1"),
+		options.WithHTTPClient(&http.Client{Transport: sent}),
+		options.WithRateLimit(rl),
+		options.WithCache(c),
+		options.WithLogger(logger),
+	)
+	if o.BaseURL != fakeOkta.URL+"/api/v1" || o.Cache != c || o.Log != logger || o.HTTP.RateLimiter != rl {
+		t.Errorf("Expected the options to configure the Okta client, got %+v", o)
+	}
+	if _, err := o.ListAllUsers(); err != nil {
+		t.Fatalf("ListAllUsers: %v", err)
+	}
+
+	b := backupify.NewClient(log.INFO,
+		options.WithBaseURL(fakeBackupify.URL+"/"+testutil.BackupifyCustomerID),
+		options.WithHTTPClient(&http.Client{Transport: sent}),
+		options.WithCache(c),
+	)
+	if b.BaseURL != fakeBackupify.URL+"/"+testutil.BackupifyCustomerID || b.Cache != c {
+		t.Errorf("Expected the options to configure the Backupify client, got %+v", b)
+	}
+	if _, err := b.Users().GetAllUsers(backupify.GoogleDrive); err != nil {
+		t.Fatalf("GetAllUsers: %v", err)
+	}
+
+	if n := atomic.LoadInt64(&sent.n); n != 2 {
+		t.Errorf("Expected both requests to be sent with the HTTP client of the options, got %d", n)
+	}
+}
+
+func TestGoogleBaseURL(t *testing.T) {
+	g := testutil.NewGoogle(t)
+	g.AddUser(&google.User{ID: "1", PrimaryEmail: "user1@example.com"})
+
+	// The fake serves every Google host, so each request is sent to its base URL
+	c := g.NewClient(t, log.INFO)
+	if c.BaseURL != g.URL {
+		t.Errorf("Expected the base URL of the fake, got %s", c.BaseURL)
+	}
+	users, err := c.Users().ListAllUsers()
+	if err != nil || len(users.Users) != 1 {
+		t.Fatalf("Expected 1 user, got %v (%v)", users, err)
+	}
+	if r := g.Requests(); r[len(r)-1].Path != "/admin/directory/v1/users" {
+		t.Errorf("Expected the path of the endpoint to be kept, got %s", r[len(r)-1].Path)
+	}
+}
+
+func TestOktaAPIVersion(t *testing.T) {
+	fake := testutil.NewOkta(t)
+	org := okta.OrgConfig{Token: "testutil"}
+	base := options.WithBaseURL(fake.URL + "/api/v1")
+
+	o, err := okta.NewOrgClient(org, log.ERROR, base, options.WithCache(memoryCache(t)))
+	if err != nil || o.APIVersion() != okta.DefaultAPIVersion || !o.Supports(okta.DEVICES) {
+		t.Fatalf("Expected a client of the default version, got %v (%v)", o, err)
+	}
+
+	classic, err := okta.NewOrgClient(org, log.ERROR, base, options.WithCache(memoryCache(t)), options.WithAPIVersion(okta.CLASSIC))
+	if err != nil || classic.Version != okta.CLASSIC {
+		t.Fatalf("Expected a client pinned to CLASSIC, got %v (%v)", classic, err)
+	}
+	if _, err := classic.ListAllDevices(); !errors.Is(err, version.ErrUnsupported) {
+		t.Errorf("ListAllDevices() = %v, want ErrUnsupported", err)
+	}
+	if n := len(fake.Requests()); n != 0 {
+		t.Errorf("Expected no request for an unsupported API, got %d", n)
+	}
+
+	if _, err := okta.NewOrgClient(org, log.ERROR, base, options.WithAPIVersion("v2")); !errors.Is(err, enum.ErrInvalid) {
+		t.Errorf("NewOrgClient() = %v, want ErrInvalid for an unknown version", err)
+	}
+}
+
+func TestGoogleAPIVersion(t *testing.T) {
+	for _, env := range []string{"GOOGLE_API_KEY", "GOOGLE_OAUTH_CLIENT", "GOOGLE_OAUTH_TOKEN", "GOOGLE_SERVICE_ACCOUNT"} {
+		t.Setenv(env, "")
+	}
+	g := testutil.NewGoogle(t)
+	ac := google.AuthCredentials{Type: google.API_KEY, Credentials: "testutil", Scopes: []string{"https://www.googleapis.com/auth/admin.directory.user"}}
+
+	c, err := google.NewClient(ac, log.ERROR,
+		options.WithHTTPClient(g.Server.Server.Client()),
+		options.WithBaseURL(g.URL),
+		options.WithCache(memoryCache(t)),
+		options.WithAPIVersion(google.DIRECTORY_BETA),
+	)
+	if err != nil || c.APIVersion() != google.DIRECTORY_BETA || !c.Supports(google.CHROME_BROWSERS) {
+		t.Fatalf("Expected a client pinned to the beta, got %v (%v)", c, err)
+	}
+
+	// The fake only serves v1, so the request fails; its path is what is checked
+	c.Users().GetUser("first+tag@example.com")
+	if r := g.Requests(); len(r) == 0 || r[len(r)-1].Path != "/admin/directory/v1.1beta1/users/first+tag@example.com" {
+		t.Errorf("Expected the request to be sent to the beta, got %v", r)
+	}
+
+	if _, err := google.NewClient(ac, log.ERROR, options.WithCache(memoryCache(t)), options.WithAPIVersion("directory_v2")); !errors.Is(err, enum.ErrInvalid) {
+		t.Errorf("NewClient() = %v, want ErrInvalid for an unknown version", err)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	fake := testutil.NewOkta(t)
+	o, err := okta.NewOrgClient(okta.OrgConfig{Token: "testutil"}, log.ERROR,
+		options.WithBaseURL(fake.URL+"/api/v1"),
+		options.WithCache(memoryCache(t)),
+		options.WithUserAgent("offboarding/1.2"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.ListAllUsers(); err != nil {
+		t.Fatalf("ListAllUsers: %v", err)
+	}
+
+	want := requests.CurrentUserAgent().String() + " okta offboarding/1.2"
+	if r := fake.Requests(); len(r) == 0 || r[0].Header.Get("User-Agent") != want {
+		t.Errorf("Expected the User-Agent %q, got %v", want, r)
+	}
+}
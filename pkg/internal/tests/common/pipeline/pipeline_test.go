@@ -0,0 +1,324 @@
+// pkg/internal/tests/common/pipeline/pipeline_test.go
+package pipeline_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/pipeline"
+)
+
+// bucket is an in-memory object store speaking just enough of the S3, GCS and Azure APIs
+type bucket struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+	parts   map[string]map[string][]byte // Staged parts by upload (or blob), then by part number (or block ID)
+	aborted int
+}
+
+func newBucket() *bucket {
+	return &bucket{objects: map[string][]byte{}, parts: map[string]map[string][]byte{}}
+}
+
+func (b *bucket) stage(upload, part string, data []byte) {
+	if b.parts[upload] == nil {
+		b.parts[upload] = map[string][]byte{}
+	}
+	b.parts[upload][part] = data
+}
+
+func checkMD5(r *http.Request, data []byte) bool {
+	sum := md5.Sum(data)
+	return r.Header.Get("Content-MD5") == base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// s3 serves `/<bucket>/<key>` path-style
+func (b *bucket) s3(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			t.Errorf("request is not signed: %q", r.Header.Get("Authorization"))
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/rego/")
+		body, _ := io.ReadAll(r.Body)
+		q := r.URL.Query()
+
+		switch {
+		case r.Method == http.MethodPost && q.Has("uploads"):
+			fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", key)
+		case r.Method == http.MethodPut && q.Has("partNumber"):
+			if !checkMD5(r, body) {
+				http.Error(w, "BadDigest", http.StatusBadRequest)
+				return
+			}
+			b.stage(q.Get("uploadId"), q.Get("partNumber"), body)
+			sum := md5.Sum(body)
+			w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+		case r.Method == http.MethodPost && q.Has("uploadId"):
+			complete := struct {
+				Parts []struct {
+					PartNumber int
+				} `xml:"Part"`
+			}{}
+			xml.Unmarshal(body, &complete)
+			object, digests := []byte{}, []byte{}
+			for _, p := range complete.Parts {
+				data := b.parts[q.Get("uploadId")][fmt.Sprint(p.PartNumber)]
+				sum := md5.Sum(data)
+				object = append(object, data...)
+				digests = append(digests, sum[:]...)
+			}
+			b.objects[key] = object
+			sum := md5.Sum(digests)
+			fmt.Fprintf(w, `<CompleteMultipartUploadResult><ETag>"%s-%d"</ETag></CompleteMultipartUploadResult>`, hex.EncodeToString(sum[:]), len(complete.Parts))
+		case r.Method == http.MethodDelete:
+			b.aborted++
+			delete(b.parts, q.Get("uploadId"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}
+}
+
+// gcs serves resumable uploads, one session per key
+func (b *bucket) gcs(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.Method == http.MethodPost && r.URL.Query().Get("uploadType") == "resumable":
+			w.Header().Set("Location", fmt.Sprintf("http://%s/session/%s", r.Host, r.URL.Query().Get("name")))
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/session/"):
+			key := strings.TrimPrefix(r.URL.Path, "/session/")
+			b.objects[key] = append(b.objects[key], body...)
+			if strings.HasSuffix(r.Header.Get("Content-Range"), "/*") {
+				w.WriteHeader(http.StatusPermanentRedirect)
+				return
+			}
+			sum := md5.Sum(b.objects[key])
+			json.NewEncoder(w).Encode(map[string]string{
+				"generation": "1",
+				"size":       fmt.Sprint(len(b.objects[key])),
+				"md5Hash":    base64.StdEncoding.EncodeToString(sum[:]),
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}
+}
+
+// azure serves block blobs under `/<container>/<blob>`
+func (b *bucket) azure(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		if r.URL.Query().Get("sig") != "secret" {
+			t.Errorf("request is not authorized: %s", r.URL)
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/rego/")
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Query().Get("comp") {
+		case "block":
+			if !checkMD5(r, body) {
+				http.Error(w, "Md5Mismatch", http.StatusBadRequest)
+				return
+			}
+			b.stage(key, r.URL.Query().Get("blockid"), body)
+		case "blocklist":
+			list := struct {
+				Latest []string `xml:"Latest"`
+			}{}
+			xml.Unmarshal(body, &list)
+			object := []byte{}
+			for _, id := range list.Latest {
+				object = append(object, b.parts[key][id]...)
+			}
+			b.objects[key] = object
+			w.Header().Set("ETag", `"0x1"`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func content(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func TestRunUploadsToEachStore(t *testing.T) {
+	large := content(pipeline.MinPartSize*2 + 1000)
+	now := time.Date(2024, 8, 15, 0, 0, 0, 0, time.UTC)
+
+	stores := map[string]func(b *bucket) (pipeline.Store, func()){
+		"s3": func(b *bucket) (pipeline.Store, func()) {
+			server := httptest.NewServer(b.s3(t))
+			store, err := pipeline.NewS3(pipeline.S3Config{Bucket: "rego", Region: "us-east-1", Endpoint: server.URL, AccessKeyID: "AKID", SecretAccessKey: "secret"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return store, server.Close
+		},
+		"gcs": func(b *bucket) (pipeline.Store, func()) {
+			server := httptest.NewServer(b.gcs(t))
+			store, err := pipeline.NewGCS(pipeline.GCSConfig{Bucket: "rego", Endpoint: server.URL, Token: "token"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return store, server.Close
+		},
+		"azure": func(b *bucket) (pipeline.Store, func()) {
+			server := httptest.NewServer(b.azure(t))
+			store, err := pipeline.NewAzure(pipeline.AzureConfig{Account: "rego", Container: "rego", Endpoint: server.URL, SAS: "?sv=2021&sig=secret"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return store, server.Close
+		},
+	}
+
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			b := newBucket()
+			store, stop := open(b)
+			defer stop()
+
+			p := pipeline.New(store, pipeline.Options{Prefix: "exports", PartSize: pipeline.MinPartSize, Now: func() time.Time { return now }}, log.ERROR)
+			manifest, err := p.Run(context.Background(),
+				pipeline.FromReader("large.zip", "application/zip", bytes.NewReader(large)),
+				pipeline.FromJSONLines("logs.jsonl", func(_ context.Context, emit func(v interface{}) error) error {
+					for i := 0; i < 3; i++ {
+						if err := emit(map[string]int{"n": i}); err != nil {
+							return err
+						}
+					}
+					return nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			if len(manifest.Objects) != 2 {
+				t.Fatalf("manifest has %d objects, want 2", len(manifest.Objects))
+			}
+			zip := manifest.Objects[0]
+			if zip.Key != "exports/large.zip" || zip.Parts != 3 || zip.Size != int64(len(large)) || zip.ContentType != "application/zip" {
+				t.Errorf("Objects[0] = %+v", zip)
+			}
+			if !bytes.Equal(b.objects["exports/large.zip"], large) {
+				t.Errorf("stored %d bytes, want %d", len(b.objects["exports/large.zip"]), len(large))
+			}
+			if err := zip.Verify(bytes.NewReader(b.objects["exports/large.zip"])); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+			if got := string(b.objects["exports/logs.jsonl"]); got != "{\"n\":0}\n{\"n\":1}\n{\"n\":2}\n" {
+				t.Errorf("logs.jsonl = %q", got)
+			}
+
+			written := pipeline.Manifest{}
+			if err := json.Unmarshal(b.objects["exports/manifest.json"], &written); err != nil {
+				t.Fatalf("manifest.json: %v", err)
+			}
+			keys := []string{}
+			for _, o := range written.Objects {
+				keys = append(keys, o.Key)
+			}
+			sort.Strings(keys)
+			if strings.Join(keys, ",") != "exports/large.zip,exports/logs.jsonl" || written.Store != store.Location() {
+				t.Errorf("manifest.json = %+v", written)
+			}
+		})
+	}
+}
+
+func TestRunRecordsFailures(t *testing.T) {
+	b := newBucket()
+	server := httptest.NewServer(b.s3(t))
+	defer server.Close()
+	store, _ := pipeline.NewS3(pipeline.S3Config{Bucket: "rego", Region: "us-east-1", Endpoint: server.URL, AccessKeyID: "AKID", SecretAccessKey: "secret"})
+
+	truncated := pipeline.FromReader("truncated.zip", "", strings.NewReader("short"))
+	truncated.Size = 100
+
+	p := pipeline.New(store, pipeline.Options{}, log.ERROR)
+	manifest, err := p.Run(context.Background(), truncated, pipeline.FromReader("ok.txt", "text/plain", strings.NewReader("ok")))
+	if err == nil || !strings.Contains(err.Error(), "read 5 bytes, expected 100") {
+		t.Fatalf("Run() error = %v, want the size mismatch", err)
+	}
+
+	if manifest.Objects[0].Error == "" || manifest.Objects[1].Error != "" {
+		t.Errorf("manifest errors = %q, %q", manifest.Objects[0].Error, manifest.Objects[1].Error)
+	}
+	if _, ok := b.objects["truncated.zip"]; ok || b.aborted != 1 {
+		t.Errorf("truncated upload was not aborted (aborted %d)", b.aborted)
+	}
+	if string(b.objects["ok.txt"]) != "ok" || b.objects["manifest.json"] == nil {
+		t.Errorf("other uploads were not completed: %v", b.objects)
+	}
+}
+
+// failingStore fails every part, cancelling the run on the first one
+type failingStore struct {
+	cancel context.CancelFunc
+	parts  int
+}
+
+func (s *failingStore) Location() string { return "failing://" }
+
+func (s *failingStore) Begin(ctx context.Context, key, contentType string) (pipeline.Upload, error) {
+	return s, nil
+}
+
+func (s *failingStore) Part(ctx context.Context, number int, data []byte, last bool) error {
+	s.parts++
+	s.cancel()
+	return fmt.Errorf("part %d failed", number)
+}
+
+func (s *failingStore) Complete(ctx context.Context) (string, error) { return "", nil }
+
+func (s *failingStore) Abort(ctx context.Context) error { return nil }
+
+type noSleep struct{}
+
+func (noSleep) Sleep(time.Duration) {}
+
+func TestRunStopsRetryingWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := &failingStore{cancel: cancel}
+
+	p := pipeline.New(store, pipeline.Options{NoManifest: true, RetrySleep: noSleep{}}, log.ERROR)
+	if _, err := p.Run(ctx, pipeline.FromReader("export.zip", "", strings.NewReader("data"))); err == nil {
+		t.Fatal("Run() succeeded, want the failure of the part")
+	}
+	if store.parts != 1 {
+		t.Errorf("Part was attempted %d times, want no retries once the run is cancelled", store.parts)
+	}
+}
@@ -0,0 +1,137 @@
+// pkg/internal/tests/common/policy/policy_test.go
+package policy_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/exporters"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/policy"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+func onHold(_ context.Context, subject string) (bool, error) {
+	return subject == "held@example.com", nil
+}
+
+func TestLegalHold(t *testing.T) {
+	e := policy.NewEngine(log.DEBUG)
+	e.Add("legal-hold", policy.LegalHold(onHold))
+
+	err := e.Check(context.Background(), &policy.Input{Action: "workflow.offboarding", Subject: "held@example.com"})
+	if !errors.Is(err, policy.ErrDenied) || !strings.Contains(err.Error(), "legal-hold: held@example.com is on legal hold") {
+		t.Errorf("Expected offboarding to be denied, got %v", err)
+	}
+	if err := e.Check(context.Background(), &policy.Input{Action: "workflow.offboarding", Subject: "free@example.com"}); err != nil {
+		t.Errorf("Expected offboarding to be allowed, got %v", err)
+	}
+	if err := e.Check(context.Background(), &policy.Input{Action: "workflow.onboarding", Subject: "held@example.com"}); err != nil {
+		t.Errorf("Expected onboarding to be allowed, got %v", err)
+	}
+}
+
+func TestProtectedGroups(t *testing.T) {
+	e := policy.NewEngine(log.DEBUG)
+	e.Add("admins", policy.ProtectedGroups(policy.Warn, "00gADMIN"))
+	e.Add("no-deletes", policy.Predicate(policy.Deny, "deletes are disabled", func(in *policy.Input) bool {
+		return in.Method == http.MethodDelete
+	}))
+
+	result := e.Evaluate(context.Background(), &policy.Input{Action: policy.ActionRequest, Method: "PUT", URL: "https://example.okta.com/api/v1/groups/00gadmin/users/00u1"})
+	if result.Effect != policy.Warn || len(result.Decisions) != 1 || result.Decisions[0].Policy != "admins" {
+		t.Errorf("Expected the group add to be flagged, got %+v", result)
+	}
+	result = e.Evaluate(context.Background(), &policy.Input{Action: policy.ActionRequest, Method: "PUT", URL: "https://example.okta.com/api/v1/groups/00gOTHER/users/00u1"})
+	if result.Effect != policy.Allow {
+		t.Errorf("Expected the group add to be allowed, got %+v", result)
+	}
+
+	// Only mutations are authorized, once each
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	t.Setenv("REGO_ENCRYPTION_KEY", "8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt")
+	c := requests.NewClient(server.Client(), requests.Headers{}, nil)
+	c.Authorize = e.Authorizer("okta")
+	if _, _, err := c.DoRequest("DELETE", server.URL+"/api/v1/users/00u1", nil, nil); !errors.Is(err, policy.ErrDenied) {
+		t.Errorf("Expected the delete to be denied, got %v", err)
+	}
+	if _, _, err := c.DoRequest("GET", server.URL+"/api/v1/users/00u1", nil, nil); err != nil {
+		t.Errorf("Expected the read to be sent, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 request to be sent, got %d", calls)
+	}
+}
+
+func TestFailOpen(t *testing.T) {
+	e := policy.NewEngine(log.DEBUG)
+	e.Add("broken", policy.Func(func(context.Context, *policy.Input) (*policy.Decision, error) {
+		return nil, errors.New("unreachable")
+	}))
+
+	in := &policy.Input{Action: policy.ActionReport}
+	if result := e.Evaluate(context.Background(), in); result.Effect != policy.Deny {
+		t.Errorf("Expected a failed policy to deny, got %s", result.Effect)
+	}
+	e.FailOpen = true
+	if result := e.Evaluate(context.Background(), in); result.Effect != policy.Warn {
+		t.Errorf("Expected a failed policy to warn when failing open, got %s", result.Effect)
+	}
+}
+
+func TestOPA(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/data/rego/authz" || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Unexpected request: %s %s", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		body := struct {
+			Input struct {
+				Subject string                 `json:"subject"`
+				Data    map[string]interface{} `json:"data"`
+			} `json:"input"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch {
+		case body.Input.Data["Users"] != nil:
+			w.Write([]byte(`{"result": {"deny": ["report includes SSNs"]}}`))
+		case body.Input.Subject == "flagged":
+			w.Write([]byte(`{"result": {"allow": true, "warn": ["review me"]}}`))
+		case body.Input.Subject == "forbidden":
+			w.Write([]byte(`{"result": false}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	opa, err := policy.NewOPA(policy.OPAConfig{URL: server.URL, Path: "rego.authz", Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := policy.NewEngine(log.DEBUG)
+	e.Add("opa", opa)
+
+	err = e.CheckReport(context.Background(), "Users", []exporters.Sheet{{Name: "Users", Data: []string{"123-45-6789"}}})
+	if !errors.Is(err, policy.ErrDenied) || !strings.Contains(err.Error(), "report includes SSNs") {
+		t.Errorf("Expected the report to be denied, got %v", err)
+	}
+
+	tests := map[string]policy.Effect{"flagged": policy.Warn, "forbidden": policy.Deny, "undefined": policy.Allow}
+	for subject, expected := range tests {
+		if result := e.Evaluate(context.Background(), &policy.Input{Action: policy.ActionReport, Subject: subject}); result.Effect != expected {
+			t.Errorf("Expected %s to %s, got %s", subject, expected, result.Effect)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// pkg/internal/tests/common/pool/pool_test.go
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/pool"
+)
+
+func TestMapBoundsWorkers(t *testing.T) {
+	items := make([]int, 50)
+	for i := range items {
+		items[i] = i
+	}
+
+	var running, peak atomic.Int32
+	results := pool.Map(context.Background(), items, pool.Options{Workers: 4}, func(_ context.Context, n int) (string, error) {
+		now := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if now <= p || peak.CompareAndSwap(p, now) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		if n%10 == 3 {
+			return "", fmt.Errorf("item %d failed", n)
+		}
+		return fmt.Sprint(n), nil
+	})
+
+	if p := peak.Load(); p > 4 {
+		t.Errorf("peak concurrency = %d, want at most 4", p)
+	}
+	for i, r := range results {
+		if r.Index != i || r.Item != i {
+			t.Fatalf("results[%d] = %+v, want results in input order", i, r)
+		}
+		if (r.Err != nil) != (i%10 == 3) {
+			t.Errorf("results[%d].Err = %v", i, r.Err)
+		}
+	}
+	if got := len(pool.Values(results)); got != 45 {
+		t.Errorf("Values() = %d, want 45", got)
+	}
+	if err := pool.FirstError(results); err == nil || err.Error() != "item 3 failed" {
+		t.Errorf("FirstError() = %v, want item 3 failed", err)
+	}
+}
+
+func TestStopOnError(t *testing.T) {
+	items := make([]int, 100)
+	for i := range items {
+		items[i] = i
+	}
+
+	boom := errors.New("boom")
+	var ran atomic.Int32
+	results := pool.Map(context.Background(), items, pool.Options{Workers: 2, StopOnError: true}, func(_ context.Context, n int) (int, error) {
+		ran.Add(1)
+		if n == 0 {
+			return 0, boom
+		}
+		time.Sleep(time.Millisecond)
+		return n, nil
+	})
+
+	if n := ran.Load(); n >= 100 {
+		t.Errorf("%d items ran, want the rest cancelled after the first error", n)
+	}
+	if last := results[len(results)-1]; !errors.Is(last.Err, boom) {
+		t.Errorf("unprocessed item error = %v, want the cause of the cancellation", last.Err)
+	}
+}
+
+func TestEachCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var ran atomic.Int32
+	err := pool.Each(ctx, []string{"a", "b", "c"}, pool.Options{}, func(_ context.Context, s string) error {
+		ran.Add(1)
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || ran.Load() != 0 {
+		t.Errorf("Each() on a cancelled context = %v after %d items, want context.Canceled and none run", err, ran.Load())
+	}
+}
+
+func TestGroupJoinsErrorsAndPanics(t *testing.T) {
+	g, _ := pool.NewGroup(context.Background(), pool.Options{Workers: 3})
+	g.Go(func(ctx context.Context) error { return errors.New("first") })
+	g.Go(func(ctx context.Context) error { panic("second") })
+	g.Go(func(ctx context.Context) error { return nil })
+
+	err := g.Wait()
+	if err == nil || !strings.Contains(err.Error(), "first") || !strings.Contains(err.Error(), "panic: second") {
+		t.Errorf("Wait() = %v, want both errors", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	seq := func(from, to int) iterator.Seq[int] {
+		return func(yield func(int, error) bool) {
+			for n := from; n < to; n++ {
+				if !yield(n, nil) {
+					return
+				}
+			}
+		}
+	}
+
+	got, err := iterator.Collect(pool.Merge(context.Background(), []iterator.Seq[int]{seq(0, 10), seq(10, 20), seq(20, 30)}, pool.Options{Workers: 2}))
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	sort.Ints(got)
+	if len(got) != 30 || got[0] != 0 || got[29] != 29 {
+		t.Errorf("Merge() = %v, want 0..29 once each", got)
+	}
+
+	failing := func(yield func(int, error) bool) {
+		if yield(-1, nil) {
+			yield(0, errors.New("page failed"))
+		}
+	}
+	_, err = iterator.Collect(pool.Merge(context.Background(), []iterator.Seq[int]{seq(0, 10), failing}, pool.Options{Workers: 2}))
+	if err == nil || !strings.Contains(err.Error(), "page failed") {
+		t.Errorf("Merge() error = %v, want the partition's error", err)
+	}
+}
+
+func TestMergeOrdered(t *testing.T) {
+	// Later partitions finish first, and are yielded after the earlier ones regardless
+	slow := func(from, to int, delay time.Duration) iterator.Seq[int] {
+		return func(yield func(int, error) bool) {
+			time.Sleep(delay)
+			for n := from; n < to; n++ {
+				if !yield(n, nil) {
+					return
+				}
+			}
+		}
+	}
+	seqs := []iterator.Seq[int]{slow(0, 1500, 30*time.Millisecond), slow(1500, 3000, 10*time.Millisecond), slow(3000, 3100, 0)}
+
+	got, err := iterator.Collect(pool.Merge(context.Background(), seqs, pool.Options{Workers: 2, Ordered: true}))
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if len(got) != 3100 || !sort.IntsAreSorted(got) {
+		t.Errorf("Merge() = %d items, sorted: %v; want 0..3099 in order", len(got), sort.IntsAreSorted(got))
+	}
+
+	failing := func(yield func(int, error) bool) {
+		yield(0, errors.New("page failed"))
+	}
+	_, err = iterator.Collect(pool.Merge(context.Background(), []iterator.Seq[int]{slow(0, 10, 0), failing, slow(10, 20, 0)}, pool.Options{Workers: 1, Ordered: true}))
+	if err == nil || !strings.Contains(err.Error(), "page failed") {
+		t.Errorf("Merge() error = %v, want the partition's error", err)
+	}
+}
+
+func TestPages(t *testing.T) {
+	const total = 95
+	var inflight, peak atomic.Int32
+	fetch := func(ctx context.Context, offset, limit int) ([]int, error) {
+		peak.Store(max(peak.Load(), inflight.Add(1)))
+		defer inflight.Add(-1)
+		// Later pages respond first
+		time.Sleep(time.Duration(10-offset/limit) * time.Millisecond)
+		items := []int{}
+		for n := offset; n < min(offset+limit, total); n++ {
+			items = append(items, n)
+		}
+		return items, nil
+	}
+
+	got, err := iterator.Collect(pool.Pages(context.Background(), 10, pool.Options{Workers: 4}, fetch))
+	if err != nil {
+		t.Fatalf("Pages() error = %v", err)
+	}
+	if len(got) != total || !sort.IntsAreSorted(got) {
+		t.Errorf("Pages() = %v, want 0..%d in order", got, total-1)
+	}
+	if peak.Load() < 2 || peak.Load() > 4 {
+		t.Errorf("%d pages in flight at once, want up to 4", peak.Load())
+	}
+
+	failure := errors.New("page failed")
+	count := 0
+	err = iterator.ForEach(pool.Pages(context.Background(), 10, pool.Options{Workers: 3}, func(ctx context.Context, offset, limit int) ([]int, error) {
+		if offset == 30 {
+			return nil, failure
+		}
+		return fetch(ctx, offset, limit)
+	}), func(int) error {
+		count++
+		return nil
+	})
+	if !errors.Is(err, failure) || count != 30 {
+		t.Errorf("Pages() yielded %d items then %v, want 30 items then the failure", count, err)
+	}
+}
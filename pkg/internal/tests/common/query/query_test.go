@@ -0,0 +1,184 @@
+// pkg/internal/tests/common/query/query_test.go
+package query_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/options"
+	"github.com/gemini-oss/rego/pkg/common/query"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/testutil"
+)
+
+type account struct {
+	ID      string    `json:"id"`
+	Status  string    `json:"status"`
+	Logins  int       `json:"logins"`
+	Created time.Time `json:"created"`
+	Profile *profile  `json:"profile,omitempty"`
+}
+
+type profile struct {
+	Email  string   `json:"email"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+var (
+	day      = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	accounts = []*account{
+		{ID: "1", Status: "ACTIVE", Logins: 5, Created: day, Profile: &profile{Email: "b@example.com", Groups: []string{"admins"}}},
+		{ID: "2", Status: "SUSPENDED", Logins: 0, Created: day.AddDate(0, 1, 0), Profile: &profile{Email: "a@example.com"}},
+		{ID: "3", Status: "ACTIVE", Logins: 12, Created: day.AddDate(0, 2, 0), Profile: &profile{Email: "c@example.org"}},
+		{ID: "4", Status: "ACTIVE", Logins: 1, Created: day.AddDate(0, 3, 0)},
+	}
+)
+
+func ids(items []*account) []string {
+	out := []string{}
+	for _, a := range items {
+		out = append(out, a.ID)
+	}
+	return out
+}
+
+func TestMatch(t *testing.T) {
+	email := query.Field("profile.email")
+	tests := []struct {
+		name string
+		p    query.Predicate
+		want []string
+	}{
+		{"Eq", query.Field("status").Eq("ACTIVE"), []string{"1", "3", "4"}},
+		{"Ne", query.Field("status").Ne("ACTIVE"), []string{"2"}},
+		{"Number", query.Field("logins").Gt(1), []string{"1", "3"}},
+		{"Time", query.Field("created").Ge(day.AddDate(0, 2, 0)), []string{"3", "4"}},
+		{"TimeEq", query.Field("created").Eq("2024-01-01T00:00:00.000Z"), []string{"1"}},
+		{"StartsWith", email.StartsWith("a"), []string{"2"}},
+		{"Contains", email.Contains(".org"), []string{"3"}},
+		{"Present", email.Present(), []string{"1", "2", "3"}},
+		{"List", query.Field("profile.groups").Eq("admins"), []string{"1"}},
+		{"Or", query.Or(query.Field("id").Eq("2"), query.Field("id").Eq("4")), []string{"2", "4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, a := range accounts {
+				if tt.p.Match(a) {
+					got = append(got, a.ID)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s matched %v, want %v", tt.p.Expression(), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	got, err := query.ApplySlice(accounts, query.New(
+		query.Where(query.Field("status").Eq("ACTIVE")),
+		query.OrderByDesc("logins"),
+		query.Limit(2),
+		query.Select("id", "profile.email"),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids(got), []string{"3", "1"}) {
+		t.Fatalf("Expected the 2 active accounts with the most logins, got %v", ids(got))
+	}
+	if got[0].Status != "" || got[0].Logins != 0 || got[0].Profile.Email != "c@example.org" || got[0].Profile.Groups != nil {
+		t.Errorf("Expected only the selected fields, got %+v %+v", got[0], got[0].Profile)
+	}
+
+	// Items missing the field of the order sort last
+	got, _ = query.ApplySlice(accounts, query.New(query.OrderBy("profile.email")))
+	if !reflect.DeepEqual(ids(got), []string{"2", "1", "3", "4"}) {
+		t.Errorf("Expected the accounts by email, got %v", ids(got))
+	}
+	if accounts[0].Status != "ACTIVE" {
+		t.Error("Expected the items of the list to be left unchanged")
+	}
+}
+
+func TestParse(t *testing.T) {
+	predicates := []query.Predicate{
+		query.Field("status").Eq("ACTIVE"),
+		query.Or(query.Field("profile.email").StartsWith("a"), query.Field("profile.login").Present()),
+		query.Field("logins").Ge(3),
+	}
+	expr := query.Expression(predicates)
+	if want := `status eq "ACTIVE" and (profile.email sw "a" or profile.login pr) and logins ge 3`; expr != want {
+		t.Errorf("Expression() = %s, want %s", expr, want)
+	}
+
+	parsed, err := query.Parse(expr)
+	if err != nil {
+		t.Fatalf("Parse(%s): %v", expr, err)
+	}
+	if query.Expression(parsed) != expr {
+		t.Errorf("Expected the expression to round-trip, got %s", query.Expression(parsed))
+	}
+
+	if parsed, err := query.Parse(`status eq "ACTIVE" or status eq "STAGED"`); err != nil || len(parsed) != 1 || len(parsed[0].Any) != 2 {
+		t.Errorf("Expected a disjunction, got %v (%v)", parsed, err)
+	}
+	for _, invalid := range []string{`status eq`, `status is "ACTIVE"`, `a pr and b pr or c pr`, `status eq "ACTIVE`} {
+		if _, err := query.Parse(invalid); err == nil {
+			t.Errorf("Parse(%s) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestOktaListUsers(t *testing.T) {
+	fake := testutil.NewOkta(t)
+	for _, u := range []*okta.User{
+		{ID: "00u1", Status: "ACTIVE", Profile: &okta.UserProfile{Login: "b@example.com", Email: "b@example.com", Department: "IT"}},
+		{ID: "00u2", Status: "SUSPENDED", Profile: &okta.UserProfile{Login: "a@example.com", Email: "a@example.com", Department: "IT"}},
+		{ID: "00u3", Status: "ACTIVE", Profile: &okta.UserProfile{Login: "a2@example.com", Email: "a2@example.com", Department: "Sales"}},
+		{ID: "00u4", Status: "ACTIVE", Profile: &okta.UserProfile{Login: "c@example.com", Email: "c@example.com", Department: "IT"}},
+	} {
+		fake.AddUser(u)
+	}
+
+	c, err := cache.NewCache([]byte(testutil.EncryptionKey), true, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OKTA_API_TOKEN", "testutil")
+	client := okta.NewClient(log.ERROR, options.WithBaseURL(fake.URL+"/api/v1"), options.WithCache(c))
+
+	users, err := client.ListUsers(
+		query.Where(okta.UserStatus.Eq("ACTIVE"), query.Field("profile.department").Contains("I")),
+		query.OrderBy(okta.UserEmail),
+		query.Select("id", "profile.email"),
+	)
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	got := []string{}
+	for _, u := range *users {
+		got = append(got, u.ID)
+		if u.Status != "" || u.Profile.Login != "" {
+			t.Errorf("Expected only the selected fields, got %+v", u)
+		}
+	}
+	if !reflect.DeepEqual(got, []string{"00u1", "00u4"}) {
+		t.Errorf("Expected the active IT users by email, got %v", got)
+	}
+
+	// The status is searched and the order sorted by Okta; `co` is applied client-side
+	r := fake.Requests()[0]
+	if search := r.Query.Get("search"); !strings.HasSuffix(search, `and status eq "ACTIVE"`) || strings.Contains(search, "department") {
+		t.Errorf("Expected the status to be pushed down, got search %s", search)
+	}
+	if sortBy := r.Query.Get("sortBy"); sortBy != "profile.email" {
+		t.Errorf("Expected the order to be pushed down, got sortBy %q", sortBy)
+	}
+}
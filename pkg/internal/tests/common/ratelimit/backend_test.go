@@ -0,0 +1,111 @@
+// pkg/internal/tests/common/ratelimit/backend_test.go
+package ratelimit_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/ratelimit"
+	"github.com/gemini-oss/rego/pkg/testutil"
+)
+
+func TestSharedWindow(t *testing.T) {
+	server := testutil.NewRedis(t, "secret")
+	backends := map[string]func() ratelimit.Backend{
+		"memory": func() ratelimit.Backend { return ratelimit.NewMemoryBackend() },
+		"redis": func() ratelimit.Backend {
+			backend, err := ratelimit.NewRedisBackend(server.URL(2))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return backend
+		},
+	}
+	for name, newBackend := range backends {
+		t.Run(name, func(t *testing.T) {
+			// Two workers share a window of 20 requests, which holds them back after 18
+			backend := newBackend()
+			workers := []*ratelimit.RateLimiter{
+				ratelimit.NewRateLimiter(20, time.Minute, backend, "okta:"+name),
+				ratelimit.NewRateLimiter(20, time.Minute, backend, "okta:"+name),
+			}
+			for _, rl := range workers {
+				defer rl.Stop()
+			}
+			ctx := context.Background()
+			for i := 0; i < 18; i++ {
+				if wait, _ := backend.Take(ctx, "okta:"+name, 18, time.Minute); wait != 0 {
+					t.Fatalf("request %d held back for %v", i, wait)
+				}
+			}
+			short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+			if err := workers[1].WaitContext(short); err == nil {
+				t.Error("WaitContext() returned with the shared window exhausted")
+			}
+
+			// A window reported by the API, e.g. after another process's requests, is kept when higher
+			if err := backend.Update(ctx, "okta:"+name+":reported", 5, time.Now().Add(time.Minute)); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			backend.Update(ctx, "okta:"+name+":reported", 3, time.Time{})
+			for i := 0; i < 3; i++ {
+				backend.Take(ctx, "okta:"+name+":reported", 8, time.Minute)
+			}
+			if wait, err := backend.Take(ctx, "okta:"+name+":reported", 8, time.Minute); err != nil || wait <= 0 {
+				t.Errorf("Take() = %v (%v) after 5 reported and 3 taken of 8, want a wait until the reset", wait, err)
+			}
+		})
+	}
+}
+
+func TestRateLimiterUpdatesBackend(t *testing.T) {
+	backend := ratelimit.NewMemoryBackend()
+	rl := ratelimit.NewRateLimiter(100, time.Minute, ratelimit.Okta, backend)
+	defer rl.Stop()
+
+	headers := http.Header{}
+	headers.Set("X-Rate-Limit-Limit", "100")
+	headers.Set("X-Rate-Limit-Remaining", "15")
+	headers.Set("X-Rate-Limit-Reset", strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+	rl.UpdateFromHeaders(headers)
+
+	// 85 of the 90 requests allowed before the headroom were used, by any process
+	for i := 0; i < 5; i++ {
+		if wait, _ := backend.Take(context.Background(), "okta", 90, time.Minute); wait != 0 {
+			t.Fatalf("request %d held back", i)
+		}
+	}
+	if wait, _ := backend.Take(context.Background(), "okta", 90, time.Minute); wait == 0 {
+		t.Error("Take() allowed a request beyond the window reported by the API")
+	}
+}
+
+func TestBackendFailure(t *testing.T) {
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := l.Addr().String()
+	l.Close()
+
+	backend, err := ratelimit.NewRedisBackend("redis://" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Client.Timeout = 100 * time.Millisecond
+	rl := ratelimit.NewRateLimiter(100, time.Minute, backend)
+	defer rl.Stop()
+
+	// Requests are counted locally while the backend is unreachable
+	if err := rl.WaitContext(context.Background()); err != nil || rl.Available != 99 {
+		t.Errorf("WaitContext() = %v with %d available, want the request counted locally", err, rl.Available)
+	}
+
+	for _, bad := range []string{"http://localhost", "redis://localhost/db"} {
+		if _, err := ratelimit.NewRedisBackend(bad); err == nil {
+			t.Errorf("NewRedisBackend(%q) succeeded, want an error", bad)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// pkg/internal/tests/common/ratelimit/buckets_test.go
+package ratelimit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/ratelimit"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+func TestBuckets(t *testing.T) {
+	fallback := ratelimit.NewRateLimiter(600, time.Minute)
+	logs := ratelimit.NewRateLimiter(120, time.Minute)
+	users := ratelimit.NewRateLimiter(1000, time.Minute)
+	user := ratelimit.NewRateLimiter(2000, time.Minute)
+	create := ratelimit.NewRateLimiter(500, time.Minute)
+	buckets := ratelimit.NewBuckets(fallback).
+		Add("/api/v1/logs", logs).
+		Add("/api/v1/users", users).
+		Add("/api/v1/users/*", user).
+		Add("POST /api/v1/users", create)
+	defer buckets.Stop()
+
+	tests := []struct {
+		method string
+		url    string
+		want   string
+	}{
+		{"GET", "https://x.okta.com/api/v1/logs?since=2024-01-01", "/api/v1/logs"},
+		{"GET", "https://x.okta.com/api/v1/users", "/api/v1/users"},
+		{"POST", "https://x.okta.com/api/v1/users", "POST /api/v1/users"},
+		{"GET", "https://x.okta.com/api/v1/users/00u1", "/api/v1/users/*"},
+		{"GET", "https://x.okta.com/api/v1/users/00u1/groups", "/api/v1/users/*"},
+		{"GET", "https://x.okta.com/api/v1/groups", ""},
+		{"GET", "https://x.okta.com/api/v1/userstats", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			if got, _ := buckets.For(req); got != tt.want {
+				t.Errorf("For() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if buckets.Limiter(httptest.NewRequest("GET", "/api/v1/groups", nil)) != fallback {
+		t.Error("Limiter() of an unmatched request is not the default rate limiter")
+	}
+}
+
+func TestBucketsTrackEachQuota(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remaining := 900
+		if r.URL.Path == "/api/v1/logs" {
+			remaining = 50
+		}
+		w.Header().Set("X-Rate-Limit-Remaining", strconv.Itoa(remaining))
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	fallback := ratelimit.NewRateLimiter(1000, time.Minute)
+	logs := ratelimit.NewRateLimiter(120, time.Minute)
+	buckets := ratelimit.NewBuckets(nil).Add("/api/v1/logs", logs)
+	client := requests.NewClient(nil, nil, fallback, requests.WithRateLimitBuckets(buckets))
+	defer client.Close(context.Background())
+
+	for _, path := range []string{"/api/v1/logs", "/api/v1/users"} {
+		if _, _, err := client.DoRequest("GET", server.URL+path, nil, nil); err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+	}
+	// The remaining requests of each response update its own bucket only, which then counts the request
+	if logs.Available != 49 || fallback.Available != 899 {
+		t.Errorf("available = %d of logs and %d of the rest, want 49 and 899", logs.Available, fallback.Available)
+	}
+}
@@ -0,0 +1,117 @@
+// pkg/internal/tests/common/ratelimit/profile_test.go
+package ratelimit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/ratelimit"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+func TestProfileHeaders(t *testing.T) {
+	reset := time.Now().Add(time.Minute).Unix()
+	tests := []struct {
+		name    string
+		profile ratelimit.Profile
+		headers map[string]string
+		limit   int
+		avail   int
+		reset   int64
+	}{
+		{"okta headers", ratelimit.Profile{}, map[string]string{"X-Rate-Limit-Limit": "600", "X-Rate-Limit-Remaining": "42", "X-Rate-Limit-Reset": strconv.FormatInt(reset, 10)}, 600, 42, reset},
+		{"x-ratelimit headers", ratelimit.Profile{}, map[string]string{"X-RateLimit-Limit": "3000", "X-RateLimit-Remaining": "7", "X-RateLimit-Reset": strconv.FormatInt(reset, 10)}, 3000, 7, reset},
+		{"ietf headers", ratelimit.IETF, map[string]string{"RateLimit-Limit": "100", "RateLimit-Remaining": "9", "RateLimit-Reset": "60"}, 100, 9, reset},
+		{"declared headers", ratelimit.Profile{Remaining: "X-Quota-Left", Reset: "X-Quota-Reset", ResetFormat: ratelimit.DELTA_SECONDS}, map[string]string{"X-Quota-Left": "3", "X-Quota-Reset": "60"}, 10, 3, reset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := ratelimit.NewRateLimiter(10, time.Minute, tt.profile)
+			defer rl.Stop()
+			headers := http.Header{}
+			for name, value := range tt.headers {
+				headers.Set(name, value)
+			}
+			rl.UpdateFromHeaders(headers)
+			if limit, available, reset := rl.Window(); limit != tt.limit || available != tt.avail || reset < tt.reset-1 || reset > tt.reset+1 {
+				t.Errorf("limit %d, available %d, reset %d; want %d, %d, %d", limit, available, reset, tt.limit, tt.avail, tt.reset)
+			}
+		})
+	}
+}
+
+func TestProfileHolds(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile ratelimit.Profile
+		status  int
+		headers http.Header
+		body    string
+		want    time.Duration
+	}{
+		{"retry after", ratelimit.Profile{}, http.StatusTooManyRequests, http.Header{"Retry-After": {"3"}}, "", 3 * time.Second},
+		{"too many requests", ratelimit.Profile{QuotaBackoff: 2 * time.Second}, http.StatusTooManyRequests, nil, "", 2 * time.Second},
+		{"google quota error", ratelimit.Google, http.StatusForbidden, nil, `{"error":{"code":403,"errors":[{"reason":"userRateLimitExceeded"}]}}`, time.Second},
+		{"other error", ratelimit.Google, http.StatusForbidden, nil, `{"error":{"code":403,"errors":[{"reason":"forbidden"}]}}`, 0},
+		{"success", ratelimit.Profile{}, http.StatusOK, nil, `{"reason":"rateLimitExceeded"}`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := ratelimit.NewRateLimiter(100, time.Minute, tt.profile)
+			defer rl.Stop()
+			rl.UpdateFromHeaders(tt.headers)
+			rl.UpdateFromError(tt.status, tt.headers, []byte(tt.body))
+			if got := rl.Throttled(); got > tt.want || got < tt.want-100*time.Millisecond {
+				t.Errorf("Throttled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Repeated rejections double the hold
+	rl := ratelimit.NewRateLimiter(100, time.Minute, ratelimit.Profile{QuotaBackoff: 100 * time.Millisecond})
+	defer rl.Stop()
+	for range 3 {
+		rl.UpdateFromError(http.StatusTooManyRequests, nil, nil)
+	}
+	if got := rl.Throttled(); got < 350*time.Millisecond {
+		t.Errorf("Throttled() = %v after 3 rejections, want 400ms", got)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := rl.WaitHold(ctx); err == nil {
+		t.Error("WaitHold() returned before the hold or the context ended")
+	}
+}
+
+func TestClientHonorsHolds(t *testing.T) {
+	var sent []time.Time
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, time.Now())
+		if count.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	rl := ratelimit.NewRateLimiter(100, time.Minute)
+	client := requests.NewClient(nil, nil, rl)
+	defer client.Close(context.Background())
+
+	client.DoRequest("GET", server.URL, nil, nil)
+	if _, _, err := client.DoRequest("GET", server.URL, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The request after the rejection waited for its Retry-After, whichever retries were sent in between
+	if last := sent[len(sent)-1]; last.Sub(sent[0]) < 900*time.Millisecond {
+		t.Errorf("request sent %v after the rejection, want after its Retry-After", last.Sub(sent[0]))
+	}
+}
@@ -0,0 +1,149 @@
+// pkg/internal/tests/common/ratelimit/queue_test.go
+package ratelimit_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/ratelimit"
+)
+
+// limitedServer allows `limit` requests per window of `window`, answering the others with a 429
+type limitedServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	used      int
+	resets    time.Time
+	inFlight  int
+	maxFlight int
+	limited   int
+	served    int
+}
+
+func newLimitedServer(t *testing.T, limit int, window time.Duration, delay time.Duration) *limitedServer {
+	s := &limitedServer{limit: limit, window: window}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		now := time.Now()
+		if now.After(s.resets) {
+			s.used = 0
+			s.resets = now.Add(s.window)
+		}
+		s.used++
+		s.inFlight++
+		s.maxFlight = max(s.maxFlight, s.inFlight)
+		remaining := max(s.limit-s.used, 0)
+		throttled := s.used > s.limit
+		if throttled {
+			s.limited++
+		} else {
+			s.served++
+		}
+		w.Header().Set("X-Rate-Limit-Limit", strconv.Itoa(s.limit))
+		w.Header().Set("X-Rate-Limit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(s.resets.Unix(), 10))
+		s.mu.Unlock()
+
+		time.Sleep(delay)
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+		if throttled {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		io.WriteString(w, `{"status":"ok"}`)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestQueueSmoothsBursts(t *testing.T) {
+	server := newLimitedServer(t, 4, time.Second, 0)
+	hc := ratelimit.NewQueue(1, nil).Client(nil)
+
+	// Twice the limit of a window; the second half is held until the window resets
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := hc.Get(server.URL)
+			if err != nil {
+				t.Errorf("Get: %v", err)
+				return
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if server.limited != 0 || server.served != 8 {
+		t.Errorf("Expected 8 requests served without a 429, got %d served and %d limited", server.served, server.limited)
+	}
+}
+
+func TestQueueConcurrency(t *testing.T) {
+	server := newLimitedServer(t, 100, time.Minute, 20*time.Millisecond)
+	hc := ratelimit.NewQueue(3, nil).Client(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := hc.Get(server.URL)
+			if err != nil {
+				t.Errorf("Get: %v", err)
+				return
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if server.maxFlight > 3 {
+		t.Errorf("Expected at most 3 requests in flight, got %d", server.maxFlight)
+	}
+}
+
+func TestQueueHoldsAfter429(t *testing.T) {
+	server := newLimitedServer(t, 1, time.Second, 0)
+	q := ratelimit.NewQueue(0, func(r *http.Request) string { return r.URL.Path })
+	hc := q.Client(nil)
+
+	get := func(path string) int {
+		resp, err := hc.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Get(%s): %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// The server shares one window, which the first bucket exhausts before the second learns of it
+	if status := get("/a"); status != http.StatusOK {
+		t.Fatalf("Expected the first request to succeed, got %d", status)
+	}
+	if status := get("/b"); status != http.StatusTooManyRequests {
+		t.Fatalf("Expected the second bucket to be rate limited, got %d", status)
+	}
+
+	// The limited bucket holds its next request until the window resets, rather than failing again
+	start := time.Now()
+	if status := get("/b"); status != http.StatusOK {
+		t.Errorf("Expected the held request to succeed, got %d", status)
+	}
+	if time.Since(start) < 500*time.Millisecond {
+		t.Errorf("Expected the request to be held until the window reset, sent after %v", time.Since(start))
+	}
+}
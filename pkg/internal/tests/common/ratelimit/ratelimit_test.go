@@ -194,3 +194,34 @@ func TestRateLimiterDecrementBehavior(t *testing.T) {
 		t.Errorf("Expected Available to decrement, got %d", rl.Available)
 	}
 }
+
+func TestThrottled(t *testing.T) {
+	rl := ratelimit.NewRateLimiter(100, 1*time.Minute)
+	defer rl.Stop()
+	rl.ResetTimestamp = time.Now().Add(1 * time.Minute).Unix()
+
+	if wait := rl.Throttled(); wait != 0 {
+		t.Errorf("Expected no throttling with the full limit available, got %v", wait)
+	}
+	if rl.Available != 100 {
+		t.Errorf("Expected Throttled not to count a request, but available is %d", rl.Available)
+	}
+
+	rl.Available = 5
+	if wait := rl.Throttled(); wait <= 0 {
+		t.Errorf("Expected throttling with 5 of 100 requests available, got %v", wait)
+	}
+}
+
+func TestRateLimiterStop(t *testing.T) {
+	rl := ratelimit.NewRateLimiter(10, 5*time.Second)
+
+	// Clients sharing a rate limiter each stop it when closed
+	rl.Stop()
+	rl.Stop()
+
+	var zero ratelimit.RateLimiter
+	zero.Stop()
+
+	rl.Log.Delete()
+}
@@ -0,0 +1,99 @@
+// pkg/internal/tests/common/requests/batch_test.go
+package requests_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/pool"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+func TestBatch(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		id := strings.TrimPrefix(r.URL.Path, "/users/")
+		if id == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"id":%q}`, id)
+	}))
+	defer server.Close()
+
+	batch := []requests.BatchRequest{}
+	for _, id := range []string{"1", "2", "missing", "4", "5", "6"} {
+		batch = append(batch, requests.BatchRequest{Method: "GET", URL: server.URL + "/users/" + id})
+	}
+	c := requests.NewClient(nil, nil, nil, requests.WithRetry(retry.Policy{MaxAttempts: 1}))
+
+	type user struct {
+		ID string `json:"id"`
+	}
+	results := requests.Batch[user](c, batch, pool.Options{Workers: 2})
+	if len(results) != len(batch) {
+		t.Fatalf("Batch() returned %d results, want %d", len(results), len(batch))
+	}
+	for i, r := range results {
+		if r.Index != i || r.Request.URL != batch[i].URL {
+			t.Errorf("result %d = %+v, want the result of its request", i, r)
+		}
+		if i == 2 {
+			if !errors.Is(r.Err, rerrors.ErrNotFound) {
+				t.Errorf("result %d error = %v, want ErrNotFound", i, r.Err)
+			}
+			continue
+		}
+		if r.Err != nil || r.Value.ID != strings.TrimPrefix(batch[i].URL, server.URL+"/users/") || r.Response.StatusCode != http.StatusOK {
+			t.Errorf("result %d = %+v, want the decoded user", i, r)
+		}
+	}
+	if peak.Load() > 2 {
+		t.Errorf("Batch() sent %d requests at once, want at most 2", peak.Load())
+	}
+
+	err := requests.BatchErr(results)
+	if !errors.Is(err, rerrors.ErrNotFound) || !strings.Contains(err.Error(), "request 2 (GET ") {
+		t.Errorf("BatchErr() = %v, want the failed request", err)
+	}
+	if raw := requests.Batch[[]byte](c, batch[:1], pool.Options{}); string(raw[0].Value) != `{"id":"1"}` {
+		t.Errorf("Batch[[]byte]() = %s, want the body as it is", raw[0].Value)
+	}
+}
+
+func TestBatchStopOnError(t *testing.T) {
+	var sent atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	batch := make([]requests.BatchRequest, 10)
+	for i := range batch {
+		batch[i] = requests.BatchRequest{Method: "POST", URL: server.URL, Data: map[string]int{"n": i}}
+	}
+	c := requests.NewClient(nil, nil, nil)
+
+	results := requests.Batch[[]byte](c, batch, pool.Options{Workers: 1, StopOnError: true})
+	if sent.Load() != 1 {
+		t.Errorf("Batch() sent %d requests, want the first failure to stop the batch", sent.Load())
+	}
+	if last := results[len(results)-1]; last.Response != nil || !errors.Is(last.Err, rerrors.ErrInvalid) {
+		t.Errorf("last result = %+v, want the request not sent, with the error which stopped the batch", last)
+	}
+}
@@ -0,0 +1,51 @@
+// pkg/internal/tests/common/requests/breaker_test.go
+package requests_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/breaker"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	b := breaker.New(3, time.Minute)
+	b.Log = log.NewLogger("{breaker}", log.ERROR)
+	fast := retry.Policy{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	count := 0
+	c := requests.NewClient(statusClient(&count, 503, 503, 503, 503, 503), nil, nil, requests.WithRetry(fast), requests.WithCircuitBreaker(b))
+
+	// The retries of the first request open the circuit, which rejects the rest without backing off
+	_, _, err := c.DoRequest("GET", "http://okta.example.com/api/v1/users", nil, nil)
+	if !errors.Is(err, breaker.ErrOpen) {
+		t.Errorf("DoRequest() error = %v, want ErrOpen", err)
+	}
+	if count != 3 {
+		t.Errorf("DoRequest() sent %d requests, want 3", count)
+	}
+	if s := b.State("okta.example.com"); s != breaker.OPEN {
+		t.Errorf("State() = %s, want open", s)
+	}
+
+	if _, _, err := c.DoRequest("GET", "http://okta.example.com/api/v1/groups", nil, nil); !errors.Is(err, breaker.ErrOpen) {
+		t.Errorf("DoRequest() error = %v, want ErrOpen", err)
+	}
+	if count != 3 {
+		t.Errorf("DoRequest() sent %d requests while open, want none", count)
+	}
+
+	// Client errors are served by the host, so they do not count against it
+	count = 0
+	c = requests.NewClient(statusClient(&count, 404, 404, 404, 404), nil, nil, requests.WithCircuitBreaker(b))
+	for range 4 {
+		c.DoRequest("GET", "http://example.com/missing", nil, nil)
+	}
+	if s := b.State("example.com"); s != breaker.CLOSED || count != 4 {
+		t.Errorf("State() after 404s = %s (%d requests), want closed", s, count)
+	}
+}
@@ -0,0 +1,148 @@
+// pkg/internal/tests/common/requests/codec_test.go
+package requests_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/okta"
+)
+
+// countingCodec counts the payloads it handles; its decoder cannot read tokens, like those of some alternative codecs
+type countingCodec struct {
+	requests.StdCodec
+	calls *atomic.Int32
+}
+
+type plainDecoder struct {
+	dec *json.Decoder
+}
+
+func (d plainDecoder) Decode(v interface{}) error { return d.dec.Decode(v) }
+func (d plainDecoder) More() bool                 { return d.dec.More() }
+
+func (c countingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.calls.Add(1)
+	return c.StdCodec.Marshal(v)
+}
+
+func (c countingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.calls.Add(1)
+	return c.StdCodec.Unmarshal(data, v)
+}
+
+func (c countingCodec) NewDecoder(r io.Reader) requests.Decoder {
+	c.calls.Add(1)
+	return plainDecoder{json.NewDecoder(r)}
+}
+
+func TestSetCodec(t *testing.T) {
+	var calls atomic.Int32
+	requests.SetCodec(countingCodec{calls: &calls})
+	t.Cleanup(func() { requests.SetCodec(nil) })
+
+	var field struct{ Field string }
+	if err := requests.DecodeJSON([]byte(`{"field":"value"}`), &field); err != nil || field.Field != "value" {
+		t.Errorf("DecodeJSON() = %v, %v", field, err)
+	}
+	if err := requests.DecodeStream(bytes.NewBufferString(`{"field":"stream"}`), &field); err != nil || field.Field != "stream" {
+		t.Errorf("DecodeStream() = %v, %v", field, err)
+	}
+
+	// Without tokens, the array is decoded whole
+	sum := 0
+	err := requests.DecodeArray(bytes.NewBufferString(`[1, 2, 3]`), func(n int) error {
+		sum += n
+		return nil
+	})
+	if err != nil || sum != 6 {
+		t.Errorf("DecodeArray() sum = %d, %v", sum, err)
+	}
+
+	req, _ := http.NewRequest("POST", "http://gemini.com", nil)
+	if err := requests.SetJSONPayload(req, map[string]string{"field": "value"}); err != nil {
+		t.Errorf("SetJSONPayload() error: %v", err)
+	}
+
+	if calls.Load() != 4 {
+		t.Errorf("Expected the codec to handle 4 payloads, got %d", calls.Load())
+	}
+	if _, ok := requests.JSONCodec().(countingCodec); !ok {
+		t.Errorf("JSONCodec() = %T, want the codec set", requests.JSONCodec())
+	}
+	requests.SetCodec(nil)
+	if _, ok := requests.JSONCodec().(requests.StdCodec); !ok {
+		t.Errorf("JSONCodec() = %T, want StdCodec after a nil codec", requests.JSONCodec())
+	}
+}
+
+// usersPage is a page of Okta users, as listed by the Users API
+func usersPage(b *testing.B, size int) []byte {
+	b.Helper()
+	users := okta.Users{}
+	for i := 0; i < size; i++ {
+		users = append(users, &okta.User{
+			ID:          fmt.Sprintf("00u%017d", i),
+			Status:      okta.ACTIVE,
+			Created:     time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+			LastLogin:   time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC),
+			LastUpdated: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC),
+			Profile: &okta.UserProfile{
+				Login:      fmt.Sprintf("user%d@example.com", i),
+				Email:      fmt.Sprintf("user%d@example.com", i),
+				FirstName:  "First",
+				LastName:   "Last",
+				Department: "Engineering",
+				Title:      "Engineer",
+			},
+		})
+	}
+	data, err := json.Marshal(users)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return data
+}
+
+func BenchmarkDecodeJSON(b *testing.B) {
+	page := usersPage(b, 200)
+	b.SetBytes(int64(len(page)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var users okta.Users
+		if err := requests.DecodeJSON(page, &users); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeStream(b *testing.B) {
+	page := usersPage(b, 200)
+	b.SetBytes(int64(len(page)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var users okta.Users
+		if err := requests.DecodeStream(bytes.NewReader(page), &users); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeArray(b *testing.B) {
+	page := usersPage(b, 200)
+	b.SetBytes(int64(len(page)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := requests.DecodeArray(bytes.NewReader(page), func(u *okta.User) error { return nil })
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
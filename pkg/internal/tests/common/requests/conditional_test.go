@@ -0,0 +1,172 @@
+// pkg/internal/tests/common/requests/conditional_test.go
+package requests_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"golang.org/x/oauth2"
+)
+
+// etagServer serves `body` with an ETag of its version, counting the requests answered with a `304`
+func etagServer(t *testing.T, body *string, version *string) (*httptest.Server, *int) {
+	var mu sync.Mutex
+	notModified := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		etag := `"` + *version + `"`
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		io.WriteString(w, *body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &notModified
+}
+
+func TestConditionalRequests(t *testing.T) {
+	body, version := `{"field":"value"}`, "v1"
+	server, notModified := etagServer(t, &body, &version)
+
+	c, err := cache.NewCache([]byte("8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt"), true, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := requests.NewClient(nil, requests.Headers{"Authorization": "Bearer token"}, nil, requests.WithCache(c), requests.WithConditionalRequests())
+
+	tests := []struct {
+		name        string
+		change      func()
+		want        string
+		notModified int
+		revalidated bool
+	}{
+		{"First Request", func() {}, `{"field":"value"}`, 0, false},
+		{"Unchanged", func() {}, `{"field":"value"}`, 1, true},
+		{"Changed", func() { body, version = `{"field":"changed"}`, "v2" }, `{"field":"changed"}`, 1, false},
+		{"Unchanged Again", func() {}, `{"field":"changed"}`, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.change()
+			resp, got, err := client.DoRequest("GET", server.URL, nil, nil)
+			if err != nil {
+				t.Fatalf("DoRequest() error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("DoRequest() body = %s, want %s", got, tt.want)
+			}
+			if *notModified != tt.notModified {
+				t.Errorf("Expected %d responses not modified, got %d", tt.notModified, *notModified)
+			}
+			if revalidated := resp.Header.Get(requests.NotModifiedHeader) == "true"; revalidated != tt.revalidated {
+				t.Errorf("DoRequest() %s = %q", requests.NotModifiedHeader, resp.Header.Get(requests.NotModifiedHeader))
+			}
+		})
+	}
+
+	// Streamed responses are revalidated as well, and other credentials never see the cached response
+	resp, err := client.DoStream("GET", server.URL, nil, nil)
+	if err != nil {
+		t.Fatalf("DoStream() error: %v", err)
+	}
+	resp.Body.Close()
+	if *notModified != 3 {
+		t.Errorf("Expected the stream to be revalidated, got %d responses not modified", *notModified)
+	}
+	client.SetHeader("Authorization", "Bearer other")
+	if _, _, err := client.DoRequest("GET", server.URL, nil, nil); err != nil || *notModified != 3 {
+		t.Errorf("Expected other credentials to request the full body, got %d responses not modified (%v)", *notModified, err)
+	}
+}
+
+func TestConditionalRequestsRefresh(t *testing.T) {
+	body, version := `{"field":"value"}`, "v1"
+	server, notModified := etagServer(t, &body, &version)
+
+	backend := cache.NewMemoryBackend()
+	c, err := cache.NewCache([]byte("8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt"), backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := requests.NewClient(nil, nil, nil, requests.WithCache(c), requests.WithConditionalRequests())
+
+	// expires returns when the cached response of the server expires
+	expires := func() time.Time {
+		var at time.Time
+		backend.Scan(context.Background(), "", func(key string, item cache.CacheItem) bool {
+			if strings.Contains(key, "conditional:") {
+				at = item.Expires
+			}
+			return true
+		})
+		return at
+	}
+
+	if _, _, err := client.DoRequest("GET", server.URL, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	first := expires()
+	if first.IsZero() {
+		t.Fatal("Expected the response to be cached")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if _, got, err := client.DoRequest("GET", server.URL, nil, nil); err != nil || string(got) != body || *notModified != 1 {
+		t.Fatalf("DoRequest() = %s, %v after %d responses not modified, want the cached body", got, err, *notModified)
+	}
+	if refreshed := expires(); !refreshed.After(first) {
+		t.Errorf("Expected the 304 to keep the cached response longer, expires %v then %v", first, refreshed)
+	}
+}
+
+func TestConditionalRequestsSubject(t *testing.T) {
+	body, version := `{"field":"value"}`, "v1"
+	server, notModified := etagServer(t, &body, &version)
+
+	c, err := cache.NewCache([]byte("8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt"), cache.NewMemoryBackend())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each client is sent a token of its own, as after a restart
+	newClient := func(token string, opts ...requests.Option) *requests.Client {
+		opts = append(opts, requests.WithCache(c), requests.WithConditionalRequests(), requests.WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})))
+		return requests.NewClient(nil, nil, nil, opts...)
+	}
+
+	tests := []struct {
+		name        string
+		client      *requests.Client
+		notModified int
+	}{
+		{"First Token", newClient("first", requests.WithSubject("okta:example")), 0},
+		{"Renewed Token", newClient("second", requests.WithSubject("okta:example")), 1},
+		{"Other Subject", newClient("third", requests.WithSubject("okta:other")), 1},
+		{"No Subject", newClient("fourth"), 1},
+		{"No Subject Again", newClient("fourth"), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got, err := tt.client.DoRequest("GET", server.URL, nil, nil); err != nil || string(got) != body {
+				t.Fatalf("DoRequest() = %s, %v", got, err)
+			}
+			if *notModified != tt.notModified {
+				t.Errorf("Expected %d responses not modified, got %d", tt.notModified, *notModified)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// pkg/internal/tests/common/requests/context_test.go
+package requests_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/ratelimit"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+func TestDoRequestContext(t *testing.T) {
+	var requestCount int
+	hc := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			requestCount++
+			if err := req.Context().Err(); err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"ok":true}`)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+	c := requests.NewClient(hc, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, body, err := c.DoRequestContext(ctx, "GET", "http://gemini.com", nil, nil); err != nil || string(body) != `{"ok":true}` {
+		t.Fatalf("DoRequestContext() = %s, %v", body, err)
+	}
+
+	cancel()
+	requestCount = 0
+	if _, _, err := c.DoRequestContext(ctx, "GET", "http://gemini.com", nil, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("DoRequestContext() with a cancelled context = %v, want Canceled", err)
+	}
+	if requestCount > 1 {
+		t.Errorf("DoRequestContext() with a cancelled context sent %d requests, want no retries", requestCount)
+	}
+	if _, err := c.DoStreamContext(ctx, "GET", "http://gemini.com", nil, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("DoStreamContext() with a cancelled context = %v, want Canceled", err)
+	}
+
+	// The client itself is left unbound
+	if _, _, err := c.DoRequest("GET", "http://gemini.com", nil, nil); err != nil {
+		t.Errorf("DoRequest() after a cancelled DoRequestContext = %v", err)
+	}
+}
+
+func TestDoRequestContextStopsRateLimiterWait(t *testing.T) {
+	// Exhausted until the next minute, so every request waits on the rate limiter
+	rl := &ratelimit.RateLimiter{
+		Limit:          10,
+		ResetTimestamp: time.Now().Add(time.Minute).Unix(),
+		Log:            log.NewLogger("{ratelimit}", log.INFO),
+	}
+	c := requests.NewClient(mockHTTPClient(`{"ok":true}`, http.StatusOK, nil), nil, rl)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, _, err := c.DoRequestContext(ctx, "GET", "http://gemini.com", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("DoRequestContext() while rate limited = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("DoRequestContext() waited %v on the rate limiter after its context was done", elapsed)
+	}
+}
@@ -0,0 +1,31 @@
+// pkg/internal/tests/common/requests/debug_test.go
+package requests_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+func TestDebugLogsAreRedacted(t *testing.T) {
+	var buf bytes.Buffer
+	count := 0
+	c := requests.NewClient(statusClient(&count), requests.Headers{"Authorization": "SSWS 00aBcDeFgHiJkLmNoP"}, nil)
+	c.Log = log.NewLogger("{test}", log.DEBUG)
+	defer c.Log.Delete()
+	c.Log.SetOutput(&buf)
+
+	if _, _, err := c.DoRequest("GET", "https://gemini.com/users?api_key=abc123", nil, nil); err != nil {
+		t.Fatalf("DoRequest() error: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "Request: GET") || !strings.Contains(output, "Response: 200") {
+		t.Errorf("Expected the request and response to be logged, got %q", output)
+	}
+	if strings.Contains(output, "00aBcDeFgHiJkLmNoP") || strings.Contains(output, "abc123") {
+		t.Errorf("Expected secrets to be redacted, got %q", output)
+	}
+}
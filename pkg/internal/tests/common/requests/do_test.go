@@ -0,0 +1,64 @@
+// pkg/internal/tests/common/requests/do_test.go
+package requests_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+func TestDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/1":
+			fmt.Fprint(w, `{"id":"1","name":"Ada"}`)
+		case "/invalid":
+			fmt.Fprint(w, `{"id":`)
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	c := requests.NewClient(nil, nil, nil, requests.WithRetry(retry.Policy{MaxAttempts: 1}))
+
+	type user struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	u, err := requests.Do[*user](c, "GET", server.URL+"/users/1", nil, nil)
+	if err != nil || u == nil || u.ID != "1" || u.Name != "Ada" {
+		t.Fatalf("Do() = %+v, %v, want the decoded user", u, err)
+	}
+
+	raw, err := requests.Do[[]byte](c, "GET", server.URL+"/users/1", nil, nil)
+	if err != nil || string(raw) != `{"id":"1","name":"Ada"}` {
+		t.Errorf("Do[[]byte]() = %q, %v, want the body as it is", raw, err)
+	}
+
+	if u, err := requests.Do[*user](c, "DELETE", server.URL+"/empty", nil, nil); err != nil || u != nil {
+		t.Errorf("Do() of an empty body = %+v, %v, want a zero value", u, err)
+	}
+
+	if _, err := requests.Do[user](c, "GET", server.URL+"/missing", nil, nil); !errors.Is(err, rerrors.ErrNotFound) {
+		t.Errorf("Do() error = %v, want ErrNotFound", err)
+	}
+
+	if _, err := requests.Do[user](c, "GET", server.URL+"/invalid", nil, nil); err == nil {
+		t.Error("Do() decoded an invalid body")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := requests.DoContext[user](ctx, c, "GET", server.URL+"/users/1", nil, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("DoContext() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,199 @@
+// pkg/internal/tests/common/requests/downloader_test.go
+package requests_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+// rangeServer serves `content`, honoring `Range` unless `ignoreRange`, and cutting its first `cut` responses short
+func rangeServer(t *testing.T, content []byte, ignoreRange bool, cut int32, digest bool) (*httptest.Server, *[]string) {
+	var cuts atomic.Int32
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		if digest {
+			sum := sha256.Sum256(content)
+			w.Header().Set("Repr-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(sum[:])+":")
+		}
+		start := 0
+		if spec := r.Header.Get("Range"); spec != "" && !ignoreRange {
+			fmt.Sscanf(spec, "bytes=%d-", &start)
+			if start >= len(content) {
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", len(content)))
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+			w.Header().Set("Content-Length", fmt.Sprint(len(content)-start))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		}
+		body := content[start:]
+		if cuts.Add(1) <= cut {
+			// Sends half of the body, then drops the connection
+			w.Write(body[:len(body)/2])
+			w.(http.Flusher).Flush()
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &ranges
+}
+
+func TestDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 256*1024) // 4 MiB
+	sum := sha256.Sum256(content)
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+	fast := requests.WithRetry(retry.Policy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	tests := []struct {
+		name        string
+		ignoreRange bool
+		cut         int32
+		digest      bool
+		checksum    string
+		offset      int
+		wantRanges  []string
+		wantSum     string
+		wantErr     error
+	}{
+		{"whole content", false, 0, false, "", 0, []string{""}, "", nil},
+		{"verified from its checksum", false, 0, false, checksum, 0, []string{""}, checksum, nil},
+		{"verified from its digest header", false, 0, true, "", 0, []string{""}, checksum, nil},
+		{"resumed after a dropped connection", false, 1, true, "", 0, []string{"", fmt.Sprintf("bytes=%d-", len(content)/2)}, checksum, nil},
+		{"resumed from a partial file", false, 0, false, checksum, len(content) / 4, []string{fmt.Sprintf("bytes=%d-", len(content)/4)}, checksum, nil},
+		{"resumed when the range is ignored", true, 0, false, checksum, len(content) / 4, []string{fmt.Sprintf("bytes=%d-", len(content)/4)}, checksum, nil},
+		{"already complete", false, 0, false, checksum, len(content), []string{fmt.Sprintf("bytes=%d-", len(content))}, checksum, nil},
+		{"checksum mismatch", false, 0, false, "sha256:" + strings.Repeat("00", 32), 0, []string{""}, "", requests.ErrChecksumMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, ranges := rangeServer(t, content, tt.ignoreRange, tt.cut, tt.digest)
+			path := filepath.Join(t.TempDir(), "export", "content.bin")
+			if tt.offset > 0 {
+				os.MkdirAll(filepath.Dir(path), 0755)
+				os.WriteFile(path, content[:tt.offset], 0600)
+			}
+
+			var reports []requests.Progress
+			client := requests.NewClient(nil, nil, nil, fast)
+			result, err := client.DownloadToFile(requests.Download{
+				URL:      server.URL,
+				Checksum: tt.checksum,
+				Progress: func(p requests.Progress) { reports = append(reports, p) },
+			}, path)
+
+			if fmt.Sprint(*ranges) != fmt.Sprint(tt.wantRanges) {
+				t.Errorf("ranges requested = %q, want %q", *ranges, tt.wantRanges)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				if _, err := os.Stat(path); !os.IsNotExist(err) {
+					t.Errorf("file not removed after a %v", tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, _ := os.ReadFile(path)
+			if !bytes.Equal(got, content) {
+				t.Errorf("downloaded %d bytes, which do not match the content of %d bytes", len(got), len(content))
+			}
+			// A file whose range was ignored is rewritten from its first byte
+			written := len(content) - tt.offset
+			if tt.ignoreRange {
+				written = len(content)
+			}
+			if result.Written != int64(written) || result.Size != int64(len(content)) || result.Checksum != tt.wantSum {
+				t.Errorf("result = %+v, want %d bytes written of %d, checksum %q", result, written, len(content), tt.wantSum)
+			}
+			if len(reports) == 0 || reports[len(reports)-1].Received != int64(len(content)) || reports[len(reports)-1].Percent() != 100 {
+				t.Fatalf("last progress = %+v, want the whole content", reports)
+			}
+			if tt.offset == 0 && len(reports) < 4 {
+				t.Errorf("progress reported %d times, want once per MiB", len(reports))
+			}
+		})
+	}
+}
+
+func TestDownloadToWriter(t *testing.T) {
+	content := []byte(strings.Repeat("rego", 1000))
+	server, ranges := rangeServer(t, content, false, 0, true)
+
+	var buf bytes.Buffer
+	buf.Write(content[:100])
+	client := requests.NewClient(nil, nil, nil)
+	result, err := client.Download(requests.Download{URL: server.URL, Offset: 100}, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), content) || (*ranges)[0] != "bytes=100-" {
+		t.Errorf("resumed with %q to %d bytes, want the content", *ranges, buf.Len())
+	}
+	// The bytes the writer already held cannot be hashed
+	if result.Written != int64(len(content)-100) || result.Checksum != "" {
+		t.Errorf("result = %+v, want %d bytes written, unverified", result, len(content)-100)
+	}
+}
+
+func TestDownloadIgnoredRange(t *testing.T) {
+	content := []byte("NEWCONTENT")
+	sum := sha256.Sum256(content)
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+	server, _ := rangeServer(t, content, true, 0, false)
+	client := requests.NewClient(nil, nil, nil)
+
+	// A partial file which does not prefix the content is replaced, not kept
+	path := filepath.Join(t.TempDir(), "content.bin")
+	os.WriteFile(path, []byte("OLD"), 0600)
+	result, err := client.DownloadToFile(requests.Download{URL: server.URL, Checksum: checksum}, path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, content) || result.Checksum != checksum {
+		t.Errorf("file = %q with checksum %q, want %q verified", got, result.Checksum, content)
+	}
+
+	// The bytes a writer held cannot be compared, so the download fails
+	buf := bytes.NewBufferString("OLD")
+	if _, err := client.Download(requests.Download{URL: server.URL, Offset: 3, Checksum: checksum}, buf); err == nil {
+		t.Errorf("Download() to a writer = %q, want an error", buf)
+	}
+
+	// The bytes written by an earlier attempt are compared with the content resent
+	cut, _ := rangeServer(t, content, true, 1, false)
+	fast := requests.WithRetry(retry.Policy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	buf.Reset()
+	result, err = requests.NewClient(nil, nil, nil, fast).Download(requests.Download{URL: cut.URL, Checksum: checksum}, buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), content) || result.Checksum != checksum {
+		t.Errorf("writer = %q with checksum %q, want %q verified", buf, result.Checksum, content)
+	}
+}
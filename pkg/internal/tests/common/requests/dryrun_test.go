@@ -0,0 +1,164 @@
+// pkg/internal/tests/common/requests/dryrun_test.go
+package requests_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+// dryRunServer serves a user resource, recording every request which reaches it
+func dryRunServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var mutex sync.Mutex
+	received := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		received = append(received, r.Method+" "+r.URL.Path)
+		mutex.Unlock()
+
+		w.Header().Set("Content-Type", requests.JSON)
+		io.WriteString(w, `{"id":"00u1","status":"ACTIVE","profile":{"department":"Engineering","title":"Engineer"}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &received
+}
+
+func TestDryRunRecordsMutations(t *testing.T) {
+	server, received := dryRunServer(t)
+
+	client := requests.NewClient(nil, requests.Headers{"Content-Type": requests.JSON}, nil)
+	client.BodyType = requests.JSON
+	plan := client.EnableDryRun(nil)
+
+	// Reads are still sent
+	_, body, err := client.DoRequest("GET", server.URL+"/users/00u1", nil, nil)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	if !strings.Contains(string(body), "Engineering") {
+		t.Errorf("GET body = %s, want the user", body)
+	}
+
+	// Mutations are planned instead
+	resp, body, err := client.DoRequest("POST", server.URL+"/users/00u1/lifecycle/deactivate", map[string]string{"sendEmail": "false"}, nil)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(requests.DryRunHeader) != "true" {
+		t.Errorf("POST response = %d %v, want a synthetic 200", resp.StatusCode, resp.Header)
+	}
+	if string(body) != "null" {
+		t.Errorf("POST body = %s, want null", body)
+	}
+
+	payload := map[string]interface{}{
+		"profile": map[string]interface{}{"department": "Security", "title": "Engineer"},
+	}
+	if _, _, err := client.DoRequest("PATCH", server.URL+"/users/00u1", nil, payload); err != nil {
+		t.Fatalf("PATCH: %v", err)
+	}
+	if _, _, err := client.DoRequest("DELETE", server.URL+"/users/00u1/sessions", nil, nil); err != nil {
+		t.Fatalf("DELETE: %v", err)
+	}
+
+	// Only the reads (including the one for the PATCH diff) reached the server
+	want := []string{"GET /users/00u1", "GET /users/00u1"}
+	if strings.Join(*received, ",") != strings.Join(want, ",") {
+		t.Errorf("server received %v, want %v", *received, want)
+	}
+
+	planned := plan.Requests()
+	if len(planned) != 3 {
+		t.Fatalf("plan has %d requests, want 3: %s", len(planned), plan)
+	}
+	if planned[0].Method != "POST" || !strings.HasSuffix(planned[0].URL, "/lifecycle/deactivate?sendEmail=false") {
+		t.Errorf("planned[0] = %s %s, want the deactivation with its query", planned[0].Method, planned[0].URL)
+	}
+
+	patch := planned[1]
+	if len(patch.Diff) != 1 {
+		t.Fatalf("PATCH diff = %v, want one change", patch.Diff)
+	}
+	change := patch.Diff[0]
+	if change.Field != "profile.department" || change.Before != "Engineering" || change.After != "Security" {
+		t.Errorf("PATCH diff = %+v, want profile.department Engineering -> Security", change)
+	}
+
+	if planned[2].Method != "DELETE" || planned[2].Payload != nil {
+		t.Errorf("planned[2] = %s %v, want a DELETE without payload", planned[2].Method, planned[2].Payload)
+	}
+
+	if !strings.Contains(plan.String(), `profile.department  "Engineering"  ->  "Security"`) {
+		t.Errorf("plan.String() = %q, want the field change", plan.String())
+	}
+	if _, err := json.Marshal(plan); err != nil {
+		t.Errorf("json.Marshal(plan): %v", err)
+	}
+}
+
+func TestDryRunOverrides(t *testing.T) {
+	server, received := dryRunServer(t)
+
+	client := requests.NewClient(nil, requests.Headers{"Content-Type": requests.JSON}, nil)
+	client.IsMutation = func(method, url string) bool {
+		return !strings.Contains(url, "/get-")
+	}
+	client.DryRunBody = []byte(`{"ok":true}`)
+	plan := client.EnableDryRun(requests.NewPlan())
+
+	if _, _, err := client.DoRequest("POST", server.URL+"/api/get-policy", nil, nil); err != nil {
+		t.Fatalf("POST read: %v", err)
+	}
+	_, body, err := client.DoRequest("POST", server.URL+"/api/create-policy", nil, nil)
+	if err != nil {
+		t.Fatalf("POST mutation: %v", err)
+	}
+	if string(body) != `{"ok":true}` {
+		t.Errorf("body = %s, want the DryRunBody", body)
+	}
+
+	if len(*received) != 1 || plan.Len() != 1 {
+		t.Errorf("server received %v and plan has %d request(s), want 1 of each", *received, plan.Len())
+	}
+
+	// Once disabled, mutations are sent again
+	client.DisableDryRun()
+	if _, _, err := client.DoRequest("POST", server.URL+"/api/create-policy", nil, nil); err != nil {
+		t.Fatalf("POST after DisableDryRun: %v", err)
+	}
+	if len(*received) != 2 || plan.Len() != 1 {
+		t.Errorf("server received %v and plan has %d request(s), want 2 and 1", *received, plan.Len())
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := map[string]interface{}{
+		"status":  "ACTIVE",
+		"profile": map[string]interface{}{"title": "Engineer", "manager": "jane"},
+	}
+	after := map[string]interface{}{
+		"status":   "ACTIVE",
+		"profile":  map[string]interface{}{"title": "Manager"},
+		"settings": map[string]interface{}{"mfa": true},
+	}
+
+	changes := requests.Diff(before, after)
+	if len(changes) != 2 {
+		t.Fatalf("Diff() = %d changes, want 2", len(changes))
+	}
+	if changes[0].Field != "profile.title" || changes[0].Before != "Engineer" || changes[0].After != "Manager" {
+		t.Errorf("changes[0] = %+v, want profile.title", changes[0])
+	}
+	if changes[1].Field != "settings.mfa" || changes[1].Before != nil || changes[1].After != true {
+		t.Errorf("changes[1] = %+v, want settings.mfa from unset", changes[1])
+	}
+}
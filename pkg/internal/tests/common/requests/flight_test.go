@@ -0,0 +1,130 @@
+// pkg/internal/tests/common/requests/flight_test.go
+package requests_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+// slowServer answers each request with its path and query after `delay`, counting the requests it received
+func slowServer(t *testing.T, delay time.Duration) (*httptest.Server, *atomic.Int32) {
+	hits := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(delay)
+		fmt.Fprintf(w, `{"url":%q}`, r.URL.RequestURI())
+	}))
+	t.Cleanup(server.Close)
+	return server, hits
+}
+
+func TestSingleflight(t *testing.T) {
+	server, hits := slowServer(t, 200*time.Millisecond)
+
+	c, err := cache.NewCache([]byte("8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt"), true, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := requests.NewClient(nil, requests.Headers{"Authorization": "Bearer token"}, nil, requests.WithCache(c), requests.WithSingleflight())
+
+	tests := []struct {
+		name  string
+		query []interface{}
+		hits  int32
+	}{
+		{"Identical", []interface{}{map[string]string{"q": "a"}, map[string]string{"q": "a"}, map[string]string{"q": "a"}}, 1},
+		{"Different Queries", []interface{}{map[string]string{"q": "a"}, map[string]string{"q": "b"}}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits.Store(0)
+			bodies := make([][]byte, len(tt.query))
+			var wg sync.WaitGroup
+			for i, q := range tt.query {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, body, err := client.DoRequest(http.MethodGet, server.URL+"/users", q, nil)
+					if err != nil {
+						t.Errorf("DoRequest() error = %v", err)
+					}
+					bodies[i] = body
+				}()
+			}
+			wg.Wait()
+
+			if got := hits.Load(); got != tt.hits {
+				t.Errorf("Server received %d requests, want %d", got, tt.hits)
+			}
+			for i, body := range bodies {
+				want := fmt.Sprintf(`{"url":"/users?q=%s"}`, tt.query[i].(map[string]string)["q"])
+				if string(body) != want {
+					t.Errorf("Caller %d got %s, want %s", i, body, want)
+				}
+			}
+		})
+	}
+
+	// Requests are only collapsed while in flight
+	for i := 0; i < 2; i++ {
+		client.DoRequest(http.MethodGet, server.URL+"/users", nil, nil)
+	}
+	if got := hits.Load(); got != 4 {
+		t.Errorf("Server received %d requests, want sequential requests to be sent", got)
+	}
+}
+
+func TestSingleflightCancel(t *testing.T) {
+	server, hits := slowServer(t, 200*time.Millisecond)
+
+	c, err := cache.NewCache([]byte("8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt"), true, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := requests.NewClient(nil, nil, nil, requests.WithCache(c), requests.WithSingleflight())
+
+	leader, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := client.DoRequestContext(leader, http.MethodGet, server.URL, nil, nil)
+		errs <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// A caller whose context is done stops waiting for the shared request
+	expired, stop := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer stop()
+	if _, _, err := client.DoRequestContext(expired, http.MethodGet, server.URL, nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("DoRequestContext() error = %v, want the deadline of its context", err)
+	}
+
+	// A caller which joined a request that was cancelled sends its own
+	done := make(chan []byte, 1)
+	go func() {
+		_, body, _ := client.DoRequest(http.MethodGet, server.URL, nil, nil)
+		done <- body
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("Cancelled request error = %v, want context.Canceled", err)
+	}
+	if body := <-done; string(body) != `{"url":"/"}` {
+		t.Errorf("Joined request got %s, want its own response", body)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("Server received %d requests, want 2", got)
+	}
+}
@@ -0,0 +1,93 @@
+// pkg/internal/tests/common/requests/lifecycle_test.go
+package requests_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+// blockingClient returns a client whose requests are held by the transport until `release` is closed
+func blockingClient(t *testing.T) (c *requests.Client, sent <-chan struct{}, release chan struct{}) {
+	t.Helper()
+	started := make(chan struct{}, 1)
+	release = make(chan struct{})
+	hc := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			started <- struct{}{}
+			<-release
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"ok":true}`)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	ca, err := cache.NewCache([]byte("8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt"), true, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return requests.NewClient(hc, nil, nil, requests.WithCache(ca)), started, release
+}
+
+func TestCloseDrainsRequests(t *testing.T) {
+	c, sent, release := blockingClient(t)
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := c.WithContext(context.Background()).DoRequest("GET", "http://gemini.com", nil, nil)
+		done <- err
+	}()
+	<-sent
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() with a request in flight = %v, want DeadlineExceeded", err)
+	}
+	if _, _, err := c.DoRequest("GET", "http://gemini.com", nil, nil); !errors.Is(err, requests.ErrClosed) {
+		t.Errorf("DoRequest() after Close = %v, want ErrClosed", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("DoRequest() in flight = %v, want it to finish", err)
+	}
+	if err := c.Close(context.Background()); err != nil {
+		t.Errorf("Close() once drained = %v", err)
+	}
+}
+
+func TestCloseWaitsForStreams(t *testing.T) {
+	c, sent, release := blockingClient(t)
+	close(release)
+	go func() { <-sent }()
+
+	resp, err := c.DoStream("GET", "http://gemini.com", nil, nil)
+	if err != nil {
+		t.Fatalf("DoStream() error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() with an open stream = %v, want DeadlineExceeded", err)
+	}
+
+	resp.Body.Close()
+	resp.Body.Close()
+	if err := c.Close(context.Background()); err != nil {
+		t.Errorf("Close() once the stream is closed = %v", err)
+	}
+	if _, err := c.DoStream("GET", "http://gemini.com", nil, nil); !errors.Is(err, requests.ErrClosed) {
+		t.Errorf("DoStream() after Close = %v, want ErrClosed", err)
+	}
+}
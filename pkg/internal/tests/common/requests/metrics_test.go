@@ -0,0 +1,39 @@
+// pkg/internal/tests/common/requests/metrics_test.go
+package requests_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/metrics"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+func TestRequestMetrics(t *testing.T) {
+	p := metrics.NewPrometheus()
+	metrics.SetRecorder(p)
+	defer metrics.SetRecorder(nil)
+
+	count := 0
+	c := requests.NewClient(statusClient(&count, 0, http.StatusServiceUnavailable), nil, nil,
+		requests.WithRetry(retry.Policy{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}))
+	if _, _, err := c.DoRequest("GET", "http://gemini.com/users", nil, nil); err != nil {
+		t.Fatalf("DoRequest() error: %v", err)
+	}
+
+	var sb strings.Builder
+	p.WriteMetrics(&sb)
+	for _, want := range []string{
+		`rego_requests_total{host="gemini.com",method="GET",status="error"} 1`,
+		`rego_requests_total{host="gemini.com",method="GET",status="503"} 1`,
+		`rego_requests_total{host="gemini.com",method="GET",status="200"} 1`,
+		`rego_request_duration_seconds_count{host="gemini.com",method="GET"} 3`,
+	} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("WriteMetrics() is missing %q:\n%s", want, sb.String())
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// pkg/internal/tests/common/requests/middleware_test.go
+package requests_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+// tracing returns a middleware appending `name` to `trace` before and after each request
+func tracing(trace *[]string, name string) requests.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return requests.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			*trace = append(*trace, "before "+name)
+			resp, err := next.RoundTrip(req)
+			*trace = append(*trace, "after "+name)
+			return resp, err
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var signatures []string
+	hc := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			signatures = append(signatures, req.Header.Get("X-Signature"))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"ok":true}`)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	trace := []string{}
+	c := requests.NewClient(hc, nil, nil, requests.WithMiddleware(tracing(&trace, "log"), tracing(&trace, "metrics")))
+	c.Use(requests.BeforeRequest(func(req *http.Request) error {
+		req.Header.Set("X-Signature", "signed:"+req.URL.Path)
+		return nil
+	}))
+	c.Use(requests.AfterResponse(func(req *http.Request, resp *http.Response, err error) (*http.Response, error) {
+		resp.Body = io.NopCloser(strings.NewReader(`{"ok":"rewritten"}`))
+		return resp, err
+	}))
+
+	_, body, err := c.DoRequest("GET", "http://gemini.com/users", nil, nil)
+	if err != nil {
+		t.Fatalf("DoRequest() error: %v", err)
+	}
+	if string(body) != `{"ok":"rewritten"}` {
+		t.Errorf("DoRequest() body = %s, want the response of AfterResponse", body)
+	}
+	if len(signatures) != 1 || signatures[0] != "signed:/users" {
+		t.Errorf("Sent signatures = %v, want the one of BeforeRequest", signatures)
+	}
+	if got, want := strings.Join(trace, ", "), "before log, before metrics, after metrics, after log"; got != want {
+		t.Errorf("Middleware order = %s, want %s", got, want)
+	}
+}
+
+func TestMiddlewareErrors(t *testing.T) {
+	count := 0
+	fast := retry.Policy{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	// Every attempt of a retried request goes through the middleware
+	attempts := 0
+	c := requests.NewClient(statusClient(&count, 503, 503), nil, nil, requests.WithRetry(fast), requests.WithMiddleware(
+		requests.BeforeRequest(func(req *http.Request) error {
+			attempts++
+			return nil
+		}),
+	))
+	if _, _, err := c.DoRequest("GET", "http://gemini.com", nil, nil); err != nil || attempts != 3 {
+		t.Errorf("DoRequest() = %v after %d attempts, want success after 3", err, attempts)
+	}
+
+	// A permanent error of a middleware stops the request before it is sent
+	errUnsigned := errors.New("no signing key")
+	count = 0
+	c = requests.NewClient(statusClient(&count), nil, nil, requests.WithRetry(fast), requests.WithMiddleware(
+		requests.BeforeRequest(func(req *http.Request) error {
+			return retry.Permanent(errUnsigned)
+		}),
+	))
+	if _, _, err := c.DoRequest("GET", "http://gemini.com", nil, nil); !errors.Is(err, errUnsigned) || count != 0 {
+		t.Errorf("DoRequest() = %v after %d requests, want errUnsigned without sending", err, count)
+	}
+}
@@ -0,0 +1,101 @@
+// pkg/internal/tests/common/requests/multipart_test.go
+package requests_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+func TestMultipart(t *testing.T) {
+	content := bytes.Repeat([]byte("package"), 100000)
+	path := filepath.Join(t.TempDir(), "installer.pkg")
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var attempts atomic.Int32
+	var lengths []int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lengths = append(lengths, r.ContentLength)
+		// The first upload fails after it was read, so it is resent
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("parsing the form: %v", err)
+		}
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if got := r.FormValue("channels"); got != "C123" {
+			t.Errorf("field channels = %q, want C123", got)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("reading the file: %v", err)
+		}
+		defer file.Close()
+		got, _ := io.ReadAll(file)
+		if header.Filename != "installer.pkg" || !bytes.Equal(got, content) {
+			t.Errorf("file %q of %d bytes, want installer.pkg of %d bytes", header.Filename, len(got), len(content))
+		}
+		note, _, _ := r.FormFile("note")
+		if got, _ := io.ReadAll(note); string(got) != "hello" {
+			t.Errorf("note = %q, want hello", got)
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client := requests.NewClient(nil, map[string]string{"Content-Type": requests.JSON}, nil,
+		requests.WithRetry(retry.Policy{MaxAttempts: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}))
+	client.BodyType = requests.JSON
+
+	upload := requests.NewMultipart().
+		Field("channels", "C123").
+		File("file", path).
+		Bytes("note", "note.txt", []byte("hello"))
+	_, body, err := client.DoRequest("POST", server.URL, nil, upload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(body) != `{"ok":true}` || attempts.Load() != 2 {
+		t.Errorf("body = %s after %d attempts, want the upload to be retried once", body, attempts.Load())
+	}
+	for _, length := range lengths {
+		if length <= int64(len(content)) {
+			t.Errorf("Content-Length = %d, want the length of the whole body", length)
+		}
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		before := attempts.Load()
+		_, _, err := client.DoRequest("POST", server.URL, nil, requests.NewMultipart().File("file", path+".missing"))
+		if !errors.Is(err, os.ErrNotExist) || attempts.Load() != before {
+			t.Errorf("error = %v, want the missing file to fail before sending", err)
+		}
+	})
+
+	t.Run("reader is not resent", func(t *testing.T) {
+		attempts.Store(0)
+		lengths = nil
+		upload := requests.NewMultipart().Field("channels", "C123").Reader("file", "installer.pkg", bytes.NewReader(content))
+		_, _, err := client.DoRequest("POST", server.URL, nil, upload)
+		if err == nil || !strings.Contains(err.Error(), "cannot be read again") || attempts.Load() != 1 {
+			t.Errorf("error = %v after %d attempts, want the retry to fail", err, attempts.Load())
+		}
+		if len(lengths) != 1 || lengths[0] != -1 {
+			t.Errorf("Content-Length = %v, want a chunked upload", lengths)
+		}
+	})
+}
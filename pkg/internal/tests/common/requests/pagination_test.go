@@ -0,0 +1,122 @@
+// pkg/internal/tests/common/requests/pagination_test.go
+package requests_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+// drain returns the items of an iterator, and its error
+func drain[V any](it requests.Iterator[V]) ([]V, error) {
+	items := []V{}
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+	return items, it.Err()
+}
+
+func TestNextLink(t *testing.T) {
+	tests := []struct {
+		name  string
+		links []string
+		want  string
+	}{
+		{"none", nil, ""},
+		{"okta", []string{`<https://x.okta.com/api/v1/users?limit=2>; rel="self"`, `<https://x.okta.com/api/v1/users?after=00u2&limit=2>; rel="next"`}, "https://x.okta.com/api/v1/users?after=00u2&limit=2"},
+		{"one header", []string{`<https://api.github.com/x?page=1>; rel="prev", <https://api.github.com/x?page=3>; rel="next"`}, "https://api.github.com/x?page=3"},
+		{"commas in the target", []string{`<https://x.okta.com/api/v1/users?search=a,b&after=1>; rel="next"`}, "https://x.okta.com/api/v1/users?search=a,b&after=1"},
+		{"several relations", []string{`<https://x/2>; title="page"; rel="next last"`}, "https://x/2"},
+		{"no next", []string{`<https://x/1>; rel="self"`}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requests.NextLink(http.Header{"Link": tt.links}); got != tt.want {
+				t.Errorf("NextLink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLink(t *testing.T) {
+	header := http.Header{"Link": {`<https://x.okta.com/api/v1/users?limit=2>; rel="self"`, `<https://x.okta.com/api/v1/users?after=00u2&limit=2>; rel="next"`}}
+	if got := requests.Link(header, "self"); got != "https://x.okta.com/api/v1/users?limit=2" {
+		t.Errorf(`Link("self") = %q, want the self link`, got)
+	}
+	if got := requests.Link(header, "prev"); got != "" {
+		t.Errorf(`Link("prev") = %q, want none`, got)
+	}
+}
+
+func TestOffsetPages(t *testing.T) {
+	var offsets []int
+	it := requests.OffsetPages(2, func(offset, limit int) ([]int, error) {
+		offsets = append(offsets, offset)
+		items := []int{}
+		for i := offset; i < min(offset+limit, 5); i++ {
+			items = append(items, i)
+		}
+		return items, nil
+	})
+	items, err := drain[int](it)
+	if err != nil || !reflect.DeepEqual(items, []int{0, 1, 2, 3, 4}) || !reflect.DeepEqual(offsets, []int{0, 2, 4}) {
+		t.Errorf("items = %v from offsets %v (%v), want 0-4 from 0, 2 and 4", items, offsets, err)
+	}
+}
+
+func TestCursorPages(t *testing.T) {
+	failure := errors.New("page failed")
+	pages := map[string][]string{"": {"a", "b"}, "2": {}, "3": {"c"}}
+	next := map[string]string{"": "2", "2": "3", "3": "4"}
+	it := requests.CursorPages(func(cursor string) ([]string, string, error) {
+		if cursor == "4" {
+			return nil, "", failure
+		}
+		return pages[cursor], next[cursor], nil
+	})
+
+	// Empty pages are skipped, and the items before a failure are kept
+	items, err := iterator.Collect(iterator.Take(it.Seq(), 2))
+	if err != nil || !reflect.DeepEqual(items, []string{"a", "b"}) {
+		t.Errorf("first items = %v (%v), want a and b", items, err)
+	}
+	rest, err := drain[string](it)
+	if !errors.Is(err, failure) || !reflect.DeepEqual(rest, []string{"c"}) {
+		t.Errorf("remaining items = %v (%v), want c then the failure", rest, err)
+	}
+	if it.Next() {
+		t.Error("Next() = true after a failure")
+	}
+}
+
+func TestLinkPages(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if r.URL.Query().Get("limit") != "2" {
+			t.Errorf("page %d requested without its query: %s", page, r.URL)
+		}
+		if page < 2 {
+			w.Header().Add("Link", fmt.Sprintf(`<%s/users?limit=2&page=%d>; rel="next"`, server.URL, page+1))
+		}
+		fmt.Fprintf(w, `[{"id":"%d-a"},{"id":"%d-b"}]`, page, page)
+	}))
+	defer server.Close()
+
+	type user struct {
+		ID string `json:"id"`
+	}
+	client := requests.NewClient(nil, nil, nil)
+	items, err := drain[user](requests.LinkPages[user](client, server.URL+"/users", map[string]string{"limit": "2"}))
+	want := []user{{"0-a"}, {"0-b"}, {"1-a"}, {"1-b"}, {"2-a"}, {"2-b"}}
+	if err != nil || !reflect.DeepEqual(items, want) {
+		t.Errorf("items = %v (%v), want %v", items, err, want)
+	}
+}
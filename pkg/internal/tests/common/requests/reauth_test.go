@@ -0,0 +1,96 @@
+// pkg/internal/tests/common/requests/reauth_test.go
+package requests_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/auth"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"golang.org/x/oauth2"
+)
+
+// tokenServer accepts only the bearer token `valid` returns, counting the requests it rejects
+func tokenServer(t *testing.T, valid func() string) (*httptest.Server, *int64) {
+	t.Helper()
+	var rejected int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", requests.JSON)
+		if r.Header.Get("Authorization") != "Bearer "+valid() {
+			atomic.AddInt64(&rejected, 1)
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_token"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &rejected
+}
+
+func TestReauthenticate(t *testing.T) {
+	server, rejected := tokenServer(t, func() string { return "rotated" })
+
+	// The token was rotated before it expired; every request renews it at most once
+	var minted int64
+	source := auth.NewRenewableTokenSource(&oauth2.Token{AccessToken: "revoked"}, func() (*oauth2.Token, error) {
+		atomic.AddInt64(&minted, 1)
+		return &oauth2.Token{AccessToken: "rotated"}, nil
+	})
+	client := requests.NewClient(nil, requests.Headers{"Authorization": "Bearer revoked"}, nil)
+	client.Reauthenticate = source.Reauthenticate
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := client.DoRequest("GET", server.URL, nil, nil); err != nil {
+				t.Errorf("DoRequest: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if atomic.LoadInt64(&minted) != 1 {
+		t.Errorf("Expected a single renewal, got %d", minted)
+	}
+	if atomic.LoadInt64(rejected) > 10 {
+		t.Errorf("Expected each request to be rejected at most once, got %d rejections", *rejected)
+	}
+	if token, _ := source.Token(); token.AccessToken != "rotated" {
+		t.Errorf("Expected the renewed token to be reused, got %s", token.AccessToken)
+	}
+}
+
+func TestReauthenticateFails(t *testing.T) {
+	server, rejected := tokenServer(t, func() string { return "valid" })
+
+	// Without new credentials, the request is not resent, and fails with the rejection and the renewal error
+	calls := 0
+	client := requests.NewClient(nil, requests.Headers{"Authorization": "Bearer revoked"}, nil)
+	client.Reauthenticate = func(c *requests.Client) error {
+		calls++
+		return fmt.Errorf("no new credentials")
+	}
+
+	// The deadline ends the backoff before the next attempt
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, _, err := client.WithContext(ctx).DoRequest("GET", server.URL, nil, nil)
+	if !errors.Is(err, rerrors.ErrUnauthorized) || !strings.Contains(err.Error(), "no new credentials") {
+		t.Errorf("Expected the request to be unauthorized, got %v", err)
+	}
+	if calls != 1 || atomic.LoadInt64(rejected) != 1 {
+		t.Errorf("Expected a single rejection and renewal, got %d renewals and %d rejections", calls, *rejected)
+	}
+}
@@ -3,11 +3,14 @@ package requests_test
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
 	"github.com/gemini-oss/rego/pkg/common/log"
 	"github.com/gemini-oss/rego/pkg/common/ratelimit"
 	"github.com/gemini-oss/rego/pkg/common/requests"
@@ -374,3 +377,41 @@ func TestRetryLogic(t *testing.T) {
 		t.Errorf("DoRequest() expected 4 total requests, got %d", requestCount)
 	}
 }
+
+func TestDoRequestErrorClass(t *testing.T) {
+	client := requests.NewClient(mockHTTPClient(`{"error":"missing"}`, http.StatusNotFound, nil), nil, nil)
+
+	_, body, err := client.DoRequest("GET", "http://gemini.com", nil, nil)
+	if !errors.Is(err, rerrors.ErrNotFound) {
+		t.Fatalf("DoRequest() error = %v, want ErrNotFound", err)
+	}
+	if apiErr, ok := rerrors.AsAPIError(err); !ok || apiErr.StatusCode != http.StatusNotFound || apiErr.Message != string(body) {
+		t.Errorf("DoRequest() error = %#v, want an API error carrying the status and body", err)
+	}
+}
+
+func TestSetHeaderConcurrent(t *testing.T) {
+	client := requests.NewClient(mockHTTPClient(`{}`, http.StatusOK, nil), requests.Headers{"Accept": requests.JSON}, nil)
+
+	// Headers set while other requests are in flight, e.g. by `UpdateContentType`, apply to the following requests
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.UpdateContentType(requests.FormURLEncoded)
+		}()
+		go func() {
+			defer wg.Done()
+			if _, _, err := client.DoRequest("GET", "http://gemini.com", nil, nil); err != nil {
+				t.Errorf("DoRequest() error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	req, err := client.CreateRequest("GET", "http://gemini.com")
+	if err != nil || req.Header.Get("Content-Type") != requests.FormURLEncoded || req.Header.Get("Accept") != requests.JSON {
+		t.Errorf("CreateRequest() headers = %v (%v), want the content type set concurrently", req.Header, err)
+	}
+}
@@ -0,0 +1,179 @@
+// pkg/internal/tests/common/requests/retry_test.go
+package requests_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+// statusClient responds with `statuses` in turn, then with 200, counting the requests
+func statusClient(count *int, statuses ...int) *http.Client {
+	return &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			status := http.StatusOK
+			if *count < len(statuses) {
+				status = statuses[*count]
+			}
+			*count++
+			if status == 0 {
+				return nil, errors.New("connection reset by peer")
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(bytes.NewBufferString(http.StatusText(status))),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	fast := retry.Policy{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	tests := []struct {
+		name     string
+		policy   retry.Policy
+		statuses []int
+		want     int // Requests sent
+		wantErr  error
+	}{
+		{"server error", fast, []int{http.StatusBadGateway, http.StatusServiceUnavailable}, 3, nil},
+		{"network error", fast, []int{0}, 2, nil},
+		{"rate limited", fast, []int{http.StatusTooManyRequests}, 2, nil},
+		{"not found", fast, []int{http.StatusNotFound}, 1, rerrors.ErrNotFound},
+		{"forbidden", fast, []int{http.StatusForbidden}, 1, rerrors.ErrForbidden},
+		{"max attempts", retry.Policy{MaxAttempts: 2, MinBackoff: time.Millisecond}, []int{500, 500, 500}, 2, rerrors.ErrUnavailable},
+		{"custom statuses", retry.Policy{MinBackoff: time.Millisecond, Retryable: requests.RetryStatuses(http.StatusNotFound)}, []int{http.StatusNotFound}, 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count := 0
+			c := requests.NewClient(statusClient(&count, tt.statuses...), nil, nil, requests.WithRetry(tt.policy))
+
+			_, _, err := c.DoRequest("GET", "http://gemini.com", nil, nil)
+			if (tt.wantErr == nil) != (err == nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("DoRequest() error = %v, want %v", err, tt.wantErr)
+			}
+			if count != tt.want {
+				t.Errorf("DoRequest() sent %d requests, want %d", count, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryMalformedRequest(t *testing.T) {
+	count := 0
+	c := requests.NewClient(statusClient(&count), nil, nil)
+
+	start := time.Now()
+	if _, _, err := c.DoRequest("GET", ":", nil, nil); err == nil {
+		t.Error("DoRequest() with an invalid URL succeeded")
+	}
+	if _, _, err := c.DoRequest("FETCH", "http://gemini.com", nil, nil); err == nil {
+		t.Error("DoRequest() with an invalid method succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > retry.MinBackoff*time.Millisecond {
+		t.Errorf("DoRequest() backed off %v on malformed requests, want no retries", elapsed)
+	}
+	if count != 0 {
+		t.Errorf("DoRequest() sent %d malformed requests", count)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	fast := retry.Policy{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	tests := []struct {
+		name     string
+		method   string
+		headers  requests.Headers
+		opts     []requests.Option
+		statuses []int
+		want     int // Requests sent
+	}{
+		{"post server error", "POST", nil, nil, []int{http.StatusInternalServerError}, 1},
+		{"post rate limited", "POST", nil, nil, []int{http.StatusTooManyRequests}, 2},
+		{"post unavailable", "POST", nil, nil, []int{http.StatusServiceUnavailable}, 2},
+		{"patch gateway timeout", "PATCH", nil, nil, []int{http.StatusGatewayTimeout}, 1},
+		{"put server error", "PUT", nil, nil, []int{http.StatusInternalServerError}, 2},
+		{"idempotency key", "POST", requests.Headers{"Idempotency-Key": "1"}, nil, []int{http.StatusInternalServerError}, 2},
+		{"custom", "POST", nil, []requests.Option{requests.WithNonIdempotentRetry(func(error) bool { return true })}, []int{http.StatusBadGateway}, 2},
+		{"provider rate limit", "POST", nil, []requests.Option{requests.WithRetryPolicy(func(err error, attempt int) error {
+			if errors.Is(err, rerrors.ErrForbidden) {
+				return retry.After(err, time.Millisecond) // e.g. Google's 403 rateLimitExceeded
+			}
+			return err
+		})}, []int{http.StatusForbidden}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count := 0
+			opts := append([]requests.Option{requests.WithRetry(fast)}, tt.opts...)
+			c := requests.NewClient(statusClient(&count, tt.statuses...), tt.headers, nil, opts...)
+
+			c.DoRequest(tt.method, "http://gemini.com", nil, nil)
+			if count != tt.want {
+				t.Errorf("DoRequest(%s) sent %d requests, want %d", tt.method, count, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryOnAttempt(t *testing.T) {
+	var attempts []retry.Attempt
+	count := 0
+	c := requests.NewClient(statusClient(&count, http.StatusBadGateway), nil, nil, requests.WithRetry(retry.Policy{
+		MinBackoff: time.Millisecond,
+		OnAttempt:  func(a retry.Attempt) { attempts = append(attempts, a) },
+	}))
+
+	if _, _, err := c.DoRequest("GET", "http://gemini.com", nil, nil); err != nil {
+		t.Fatalf("DoRequest() error = %v", err)
+	}
+	if len(attempts) != 2 || !errors.Is(attempts[0].Err, rerrors.ErrUnavailable) || attempts[1].Err != nil {
+		t.Errorf("attempts = %+v, want the failure then the success", attempts)
+	}
+}
+
+func TestAPIErrorDetails(t *testing.T) {
+	reset := time.Now().Add(time.Minute).Unix()
+	tests := []struct {
+		name   string
+		header http.Header
+		status int
+		want   time.Duration // Least wait reported
+	}{
+		{"retry after", http.Header{"Retry-After": {"120"}}, http.StatusServiceUnavailable, 2 * time.Minute},
+		{"rate limit reset", http.Header{"X-Rate-Limit-Reset": {fmt.Sprint(reset)}}, http.StatusTooManyRequests, 30 * time.Second},
+		{"none", http.Header{}, http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := requests.NewClient(&http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: tt.status, Header: tt.header, Body: io.NopCloser(bytes.NewBufferString(`{"error":"details"}`))}, nil
+			})}, nil, nil, requests.WithRetry(retry.Policy{MaxAttempts: 1}))
+
+			_, _, err := c.DoRequest("GET", "http://gemini.com", nil, nil)
+			var apiErr *rerrors.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("DoRequest() error = %v, want an APIError", err)
+			}
+			if string(apiErr.Body) != `{"error":"details"}` {
+				t.Errorf("APIError.Body = %s, want the body of the response", apiErr.Body)
+			}
+			wait, ok := rerrors.RetryAfter(err)
+			if ok != (tt.want > 0) || wait < tt.want || wait > tt.want*2 {
+				t.Errorf("RetryAfter() = %v, %v, want about %v", wait, ok, tt.want)
+			}
+		})
+	}
+}
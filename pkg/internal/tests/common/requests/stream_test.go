@@ -0,0 +1,127 @@
+// pkg/internal/tests/common/requests/stream_test.go
+package requests_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+func TestDoStream(t *testing.T) {
+	var requestCount int
+	mockClient := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			requestCount++
+			if requestCount == 1 {
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Body:       io.NopCloser(bytes.NewBufferString("Unavailable")),
+					Header:     make(http.Header),
+				}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`[{"id":"1"},{"id":"2"}]`)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	c := requests.NewClient(mockClient, nil, nil)
+	resp, err := c.DoStream("GET", "http://gemini.com", nil, nil)
+	if err != nil {
+		t.Fatalf("DoStream() error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	ids := []string{}
+	err = requests.DecodeArray(resp.Body, func(item struct{ ID string }) error {
+		ids = append(ids, item.ID)
+		return nil
+	})
+	if err != nil || strings.Join(ids, ",") != "1,2" {
+		t.Errorf("DecodeArray() = %v (%v), want [1 2]", ids, err)
+	}
+	if requestCount != 2 {
+		t.Errorf("DoStream() expected 2 total requests, got %d", requestCount)
+	}
+
+	c = requests.NewClient(mockHTTPClient(`{"error":"missing"}`, http.StatusNotFound, nil), nil, nil)
+	if _, err := c.DoStream("GET", "http://gemini.com", nil, nil); !errors.Is(err, rerrors.ErrNotFound) {
+		t.Errorf("DoStream() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestDecodeArray(t *testing.T) {
+	stop := errors.New("stop")
+	tests := []struct {
+		name    string
+		body    string
+		stopAt  int
+		want    int
+		wantErr bool
+	}{
+		{"Array", `[1, 2, 3]`, 0, 3, false},
+		{"Empty Array", `[]`, 0, 0, false},
+		{"Empty Body", ``, 0, 0, false},
+		{"Null", `null`, 0, 0, false},
+		{"Object", `{"field":"value"}`, 0, 0, true},
+		{"Truncated", `[1, 2,`, 0, 2, true},
+		{"Stopped", `[1, 2, 3]`, 2, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := 0
+			err := requests.DecodeArray(strings.NewReader(tt.body), func(n int) error {
+				got++
+				if got == tt.stopAt {
+					return stop
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeArray() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DecodeArray() decoded %d elements, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeStream(t *testing.T) {
+	type SampleStruct struct {
+		Field string `json:"field"`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{"Valid JSON", `{"field":"value"}`, "value", false},
+		{"Empty Body", ``, "", false},
+		{"Invalid JSON", `{"field":}`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result SampleStruct
+			err := requests.DecodeStream(strings.NewReader(tt.body), &result)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeStream() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result.Field != tt.want {
+				t.Errorf("DecodeStream() = %q, want %q", result.Field, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// pkg/internal/tests/common/requests/timeout_test.go
+package requests_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+// slowClient returns a client whose first `slow` requests take `delay`, or until they are cancelled
+func slowClient(count *atomic.Int32, slow int32, delay time.Duration) *http.Client {
+	return &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if count.Add(1) <= slow {
+				select {
+				case <-time.After(delay):
+				case <-req.Context().Done():
+					return nil, req.Context().Err()
+				}
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+}
+
+// blockingBody is a body whose reads block until the request is cancelled
+type blockingBody struct{ ctx context.Context }
+
+func (b blockingBody) Read([]byte) (int, error) {
+	<-b.ctx.Done()
+	return 0, b.ctx.Err()
+}
+
+func (b blockingBody) Close() error { return nil }
+
+func TestTimeouts(t *testing.T) {
+	fast := requests.WithRetry(retry.Policy{MaxAttempts: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	short := requests.WithTimeouts(requests.Timeouts{Read: 20 * time.Millisecond})
+
+	tests := []struct {
+		name    string
+		slow    int32
+		opts    []requests.CallOption
+		within  time.Duration // Deadline of the call, from its start
+		want    int32         // Requests sent
+		wantErr error
+	}{
+		{"retried after a timeout", 1, nil, 0, 2, nil},
+		{"every attempt timed out", 2, nil, 0, 2, requests.ErrTimeout},
+		{"call timeout", 2, []requests.CallOption{requests.Timeout(time.Second)}, 0, 1, nil},
+		{"deadline", 2, []requests.CallOption{requests.Timeout(-1)}, 20 * time.Millisecond, 1, context.DeadlineExceeded},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var count atomic.Int32
+			c := requests.NewClient(slowClient(&count, tc.slow, 100*time.Millisecond), nil, nil, fast, short)
+			opts := tc.opts
+			if tc.within > 0 {
+				opts = append(opts, requests.Deadline(time.Now().Add(tc.within)))
+			}
+			_, _, err := c.DoRequest("GET", "http://gemini.com/users", nil, nil, opts...)
+			if !errors.Is(err, tc.wantErr) || (tc.wantErr == nil && err != nil) {
+				t.Errorf("DoRequest() error = %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantErr != requests.ErrTimeout && errors.Is(err, requests.ErrTimeout) {
+				t.Errorf("DoRequest() error = %v, want the call's deadline rather than a timeout", err)
+			}
+			if count.Load() != tc.want {
+				t.Errorf("Expected %d requests, got %d", tc.want, count.Load())
+			}
+		})
+	}
+}
+
+func TestStreamTimeout(t *testing.T) {
+	hc := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: blockingBody{req.Context()}, Header: make(http.Header)}, nil
+		}),
+	}
+	c := requests.NewClient(hc, nil, nil, requests.WithTimeouts(requests.Timeouts{Read: time.Millisecond, Stream: 20 * time.Millisecond}))
+
+	resp, err := c.DoStream("GET", "http://gemini.com/reports", nil, nil)
+	if err != nil {
+		t.Fatalf("DoStream() error: %v", err)
+	}
+	defer resp.Body.Close()
+	if _, err := io.ReadAll(resp.Body); !errors.Is(err, requests.ErrTimeout) {
+		t.Errorf("Reading the stream = %v, want ErrTimeout", err)
+	}
+}
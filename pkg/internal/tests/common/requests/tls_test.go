@@ -0,0 +1,97 @@
+// pkg/internal/tests/common/requests/tls_test.go
+package requests_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+// clientCertificate returns a self-signed client certificate and its key, PEM encoded
+func clientCertificate(t *testing.T) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "rego"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestMutualTLS(t *testing.T) {
+	certPEM, keyPEM := clientCertificate(t)
+	clients := x509.NewCertPool()
+	clients.AppendCertsFromPEM(certPEM)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"client":"` + r.TLS.PeerCertificates[0].Subject.CommonName + `"}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clients}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+	serverPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	once := requests.WithRetry(retry.Policy{MaxAttempts: 1})
+	tests := []struct {
+		name    string
+		opts    []requests.Option
+		wantErr string
+	}{
+		{"untrusted server", []requests.Option{requests.WithClientCertificatePEM(certPEM, keyPEM)}, "certificate"},
+		{"no client certificate", []requests.Option{requests.WithCAPEM(serverPEM)}, "certificate"},
+		{"missing files", []requests.Option{requests.WithCAPEM(serverPEM), requests.WithClientCertificateFiles(filepath.Join(t.TempDir(), "client.pem"), "client.key")}, "loading client certificate"},
+		{"invalid CA", []requests.Option{requests.WithCAPEM([]byte("not a certificate")), requests.WithClientCertificatePEM(certPEM, keyPEM)}, "loading CA certificates"},
+		{"mutual TLS", []requests.Option{requests.WithCAPEM(serverPEM), requests.WithClientCertificatePEM(certPEM, keyPEM)}, ""},
+		{"root CAs", []requests.Option{requests.WithRootCAs(x509.NewCertPool()), requests.WithClientCertificatePEM(certPEM, keyPEM)}, "certificate"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := requests.NewClient(nil, nil, nil, append(tc.opts, once)...)
+			_, body, err := c.DoRequest("GET", server.URL, nil, nil)
+			if tc.wantErr == "" {
+				if err != nil || string(body) != `{"client":"rego"}` {
+					t.Errorf("DoRequest() = %s, %v", body, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("DoRequest() error = %v, want it to contain %q", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// pkg/internal/tests/common/requests/token_test.go
+package requests_test
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/auth"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"golang.org/x/oauth2"
+)
+
+// mintTokens returns a source minting `token-1`, `token-2`, ... each valid for `lifetime`
+func mintTokens(lifetime time.Duration) (*auth.RenewableTokenSource, *atomic.Int32) {
+	minted := &atomic.Int32{}
+	return auth.NewRenewableTokenSource(nil, func() (*oauth2.Token, error) {
+		n := minted.Add(1)
+		return &oauth2.Token{AccessToken: fmt.Sprintf("token-%d", n), Expiry: time.Now().Add(lifetime)}, nil
+	}), minted
+}
+
+func TestWithTokenSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		lifetime time.Duration
+		wait     time.Duration
+		want     string
+		minted   int32
+	}{
+		{"Reused", time.Hour, 0, "token-1", 1},
+		{"Refreshed Before Expiry", 300 * time.Millisecond, 200 * time.Millisecond, "token-2", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, minted := mintTokens(tt.lifetime)
+			valid := "token-1"
+			server, rejected := tokenServer(t, func() string { return valid })
+			client := requests.NewClient(nil, requests.Headers{"Authorization": "Bearer frozen"}, nil, requests.WithTokenSource(source))
+
+			if _, _, err := client.DoRequest("GET", server.URL, nil, nil); err != nil {
+				t.Fatalf("DoRequest: %v", err)
+			}
+			time.Sleep(tt.wait)
+			valid = tt.want
+			if _, _, err := client.DoRequest("GET", server.URL, nil, nil); err != nil {
+				t.Fatalf("DoRequest: %v", err)
+			}
+
+			if got := minted.Load(); got != tt.minted {
+				t.Errorf("Minted %d tokens, want %d", got, tt.minted)
+			}
+			if *rejected != 0 {
+				t.Errorf("Expected no request to be rejected, got %d", *rejected)
+			}
+		})
+	}
+}
+
+func TestWithTokenSourceRevoked(t *testing.T) {
+	source, minted := mintTokens(time.Hour)
+	server, rejected := tokenServer(t, func() string { return "token-2" })
+	client := requests.NewClient(nil, nil, nil, requests.WithTokenSource(source))
+
+	// The first token was revoked before it expired; it is renewed, and the request retried with the next
+	if _, _, err := client.DoRequest("GET", server.URL, nil, nil); err != nil {
+		t.Fatalf("DoRequest: %v", err)
+	}
+	if minted.Load() != 2 || *rejected != 1 {
+		t.Errorf("Minted %d tokens with %d rejections, want 2 tokens and 1 rejection", minted.Load(), *rejected)
+	}
+}
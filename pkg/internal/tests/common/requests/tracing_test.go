@@ -0,0 +1,139 @@
+// pkg/internal/tests/common/requests/tracing_test.go
+package requests_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+// recorder is a tracer keeping the spans it started
+type recorder struct {
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+	ctx   context.Context
+}
+
+type spanKey struct{}
+
+func (r *recorder) Start(ctx context.Context, name string) (context.Context, requests.Span) {
+	span := &recordedSpan{name: name, attrs: map[string]interface{}{}}
+	span.ctx = context.WithValue(ctx, spanKey{}, span)
+	r.spans = append(r.spans, span)
+	return span.ctx, span
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...requests.Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) { s.err = err }
+func (s *recordedSpan) End()                  { s.ended = true }
+
+func TestTracing(t *testing.T) {
+	tracer := &recorder{}
+	fast := retry.Policy{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	// The span's context reaches the transport, e.g. to propagate it in headers
+	var sent context.Context
+	count := 0
+	hc := statusClient(&count, 503)
+	next := hc.Transport
+	hc.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req.Context()
+		return next.RoundTrip(req)
+	})
+
+	c := requests.NewClient(hc, nil, nil, requests.WithRetry(fast), requests.WithTracer(tracer))
+	if _, _, err := c.DoRequest("GET", "https://example.okta.com/api/v1/users/00u1a2b3c4D5e6F7g8h9/groups?limit=200", nil, nil); err != nil {
+		t.Fatalf("DoRequest() error: %v", err)
+	}
+	if len(tracer.spans) != 1 {
+		t.Fatalf("DoRequest() started %d spans, want 1", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != "GET /api/v1/users/{id}/groups" || !span.ended || span.err != nil {
+		t.Errorf("Span = %q (ended %v, error %v), want an ended GET /api/v1/users/{id}/groups", span.name, span.ended, span.err)
+	}
+	want := map[string]interface{}{
+		requests.AttrMethod:        "GET",
+		requests.AttrURLTemplate:   "/api/v1/users/{id}/groups",
+		requests.AttrServerAddress: "example.okta.com",
+		requests.AttrStatusCode:    200,
+		requests.AttrResendCount:   1,
+	}
+	for key, value := range want {
+		if span.attrs[key] != value {
+			t.Errorf("Span attribute %s = %v, want %v", key, span.attrs[key], value)
+		}
+	}
+	if _, ok := span.attrs[requests.AttrRateLimitWait].(float64); !ok {
+		t.Errorf("Span attribute %s = %v, want seconds", requests.AttrRateLimitWait, span.attrs[requests.AttrRateLimitWait])
+	}
+	if sent == nil || sent.Value(spanKey{}) != span {
+		t.Error("The request was not sent with the context of its span")
+	}
+
+	// Failures are recorded on the span
+	count = 0
+	c = requests.NewClient(statusClient(&count, 404), nil, nil, requests.WithTracer(tracer))
+	c.DoRequest("DELETE", "https://admin.googleapis.com/admin/directory/v1/users/first%2Btag@example.com", nil, nil)
+	span = tracer.spans[1]
+	if span.name != "DELETE /admin/directory/v1/users/{id}" || !errors.Is(span.err, rerrors.ErrNotFound) || span.attrs[requests.AttrStatusCode] != 404 {
+		t.Errorf("Span = %q (error %v, status %v), want a failed DELETE /admin/directory/v1/users/{id}", span.name, span.err, span.attrs[requests.AttrStatusCode])
+	}
+}
+
+func TestSetTracer(t *testing.T) {
+	tracer := &recorder{}
+	requests.SetTracer(tracer)
+	t.Cleanup(func() { requests.SetTracer(nil) })
+
+	count := 0
+	c := requests.NewClient(statusClient(&count), nil, nil)
+	resp, err := c.DoStream("GET", "http://gemini.com/v4/spreadsheets/1BxiMVs0XRA5nFMdKvBdBZjgmUUqptlbs74OgvE2upms:batchUpdate", nil, nil)
+	if err != nil {
+		t.Fatalf("DoStream() error: %v", err)
+	}
+	resp.Body.Close()
+	if len(tracer.spans) != 1 || tracer.spans[0].name != "GET /v4/spreadsheets/{id}:batchUpdate" {
+		t.Errorf("DoStream() spans = %v, want GET /v4/spreadsheets/{id}:batchUpdate", tracer.spans)
+	}
+
+	requests.SetTracer(nil)
+	c.DoRequest("GET", "http://gemini.com", nil, nil)
+	if len(tracer.spans) != 1 {
+		t.Errorf("DoRequest() started a span after SetTracer(nil)")
+	}
+}
+
+func TestURLTemplate(t *testing.T) {
+	tests := []struct {
+		url, want string
+	}{
+		{"https://example.okta.com/api/v1/users", "/api/v1/users"},
+		{"https://example.okta.com/api/v1/groups/00g1a2b3c4D5e6F7g8h9/users/00u9h8g7f6e5d4c3b2a1", "/api/v1/groups/{id}/users/{id}"},
+		{"https://admin.googleapis.com/admin/directory/v1.1beta1/users/jane@example.com", "/admin/directory/v1.1beta1/users/{id}"},
+		{"https://jamf.example.com/JSSResource/computers/id/1234", "/JSSResource/computers/id/{id}"},
+		{"https://api.example.com/v2/devices/3f2504e0-4f89-11d3-9a0c-0305e82c3301/oauth2", "/v2/devices/{id}/oauth2"},
+	}
+	for _, tt := range tests {
+		if got, _ := requests.URLTemplate(tt.url); got != tt.want {
+			t.Errorf("URLTemplate(%s) = %s, want %s", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// pkg/internal/tests/common/requests/transport_test.go
+package requests_test
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+// connServer counts the connections opened to it, and the highest number of requests served at once
+func connServer(t *testing.T, delay time.Duration) (*httptest.Server, *int64, *int64) {
+	t.Helper()
+
+	var conns, active, peak int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&active, 1)
+		for {
+			p := atomic.LoadInt64(&peak)
+			if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(delay)
+		atomic.AddInt64(&active, -1)
+
+		w.Header().Set("Content-Type", requests.JSON)
+		io.WriteString(w, `{"proto":"`+r.Proto+`"}`)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt64(&conns, 1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+
+	return server, &conns, &peak
+}
+
+func TestTransportOptions(t *testing.T) {
+	server, conns, peak := connServer(t, 20*time.Millisecond)
+
+	// Connections are limited per host, and idle ones are reused
+	client := requests.NewClient(nil, requests.Headers{}, nil, requests.WithMaxConnsPerHost(2), requests.WithMaxIdleConnsPerHost(2))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := client.DoRequest("GET", server.URL, nil, nil); err != nil {
+				t.Errorf("DoRequest: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if atomic.LoadInt64(peak) > 2 || atomic.LoadInt64(conns) > 2 {
+		t.Errorf("Expected at most 2 connections, got %d serving %d requests at once", *conns, *peak)
+	}
+
+	// Without keep-alives, each request opens a connection
+	atomic.StoreInt64(conns, 0)
+	client = requests.NewClient(nil, requests.Headers{}, nil, requests.WithKeepAlive(-1))
+	for i := 0; i < 3; i++ {
+		if _, _, err := client.DoRequest("GET", server.URL, nil, nil); err != nil {
+			t.Fatalf("DoRequest: %v", err)
+		}
+	}
+	if atomic.LoadInt64(conns) != 3 {
+		t.Errorf("Expected 3 connections, got %d", *conns)
+	}
+}
+
+func TestTransportHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Proto)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name    string
+		enabled bool
+		want    string
+	}{
+		{"Enabled", true, "HTTP/2.0"},
+		{"Disabled", false, "HTTP/1.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The options tune a copy of the server's transport, which trusts its certificate
+			client := requests.NewClient(server.Client(), requests.Headers{}, nil, requests.WithHTTP2(tt.enabled))
+			_, body, err := client.DoRequest("GET", server.URL, nil, nil)
+			if err != nil {
+				t.Fatalf("DoRequest: %v", err)
+			}
+			if string(body) != tt.want {
+				t.Errorf("Proto = %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransportSettings(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Proto)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	for _, protocol := range []string{requests.HTTP1, requests.HTTP2} {
+		t.Run(protocol, func(t *testing.T) {
+			settings := requests.Transport{MaxIdleConnsPerHost: 8, IdleConnTimeout: time.Minute, Protocol: protocol}
+			client := requests.NewClient(server.Client(), requests.Headers{}, nil, requests.WithTransport(settings))
+			_, body, err := client.DoRequest("GET", server.URL, nil, nil)
+			if err != nil {
+				t.Fatalf("DoRequest: %v", err)
+			}
+			if want := map[string]string{requests.HTTP1: "HTTP/1.1", requests.HTTP2: "HTTP/2.0"}[protocol]; string(body) != want {
+				t.Errorf("Proto = %s, want %s", body, want)
+			}
+		})
+	}
+
+	// The HTTP client of a provider is copied, leaving the original as it was
+	original := server.Client()
+	tuned := requests.TransportClient(original, requests.Transport{MaxIdleConnsPerHost: 8, DisableKeepAlives: true})
+	transport, ok := tuned.Transport.(*http.Transport)
+	if !ok || transport.MaxIdleConnsPerHost != 8 || !transport.DisableKeepAlives {
+		t.Errorf("TransportClient() transport = %+v, want the settings applied", tuned.Transport)
+	}
+	if original.Transport.(*http.Transport).DisableKeepAlives {
+		t.Error("TransportClient() changed the transport of the original client")
+	}
+
+	custom := &http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) { return nil, nil })}
+	if requests.TransportClient(custom, requests.Transport{MaxConnsPerHost: 1}) != custom {
+		t.Error("TransportClient() replaced a transport it cannot tune")
+	}
+}
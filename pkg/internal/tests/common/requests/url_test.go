@@ -0,0 +1,80 @@
+// pkg/internal/tests/common/requests/url_test.go
+package requests_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+// identifiers are the edge cases of the identifiers joined into URLs, seeding the fuzz tests
+var identifiers = []string{
+	"user@example.com",
+	"first+tag@example.com",
+	"team#1@example.com",
+	"Sales / EMEA",
+	"a?b=c&d=e",
+	"..",
+	".",
+	"100%",
+	"naïve café",
+	"",
+}
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		segments []string
+		want     string
+	}{
+		{"plain", "https://example.okta.com/api/v1/users", []string{"00u1", "groups"}, "https://example.okta.com/api/v1/users/00u1/groups"},
+		{"plus", "https://example.okta.com/api/v1/users", []string{"first+tag@example.com"}, "https://example.okta.com/api/v1/users/first%2Btag@example.com"},
+		{"fragment", "https://example.okta.com/api/v1/users", []string{"team#1@example.com"}, "https://example.okta.com/api/v1/users/team%231@example.com"},
+		{"slash", "https://admin.googleapis.com/groups", []string{"Sales / EMEA", "members"}, "https://admin.googleapis.com/groups/Sales%20%2F%20EMEA/members"},
+		{"query", "https://example.okta.com/api/v1/users", []string{"a?b=c"}, "https://example.okta.com/api/v1/users/a%3Fb=c"},
+		{"dot-dot", "https://example.okta.com/api/v1/users", []string{"..", "sessions"}, "https://example.okta.com/api/v1/users/%2E%2E/sessions"},
+		{"base query", "https://example.okta.com/api/v1/users?limit=200", []string{"00u1"}, "https://example.okta.com/api/v1/users/00u1?limit=200"},
+		{"escaped base", "https://sheets.googleapis.com/v4/spreadsheets/a%2Fb", []string{"values"}, "https://sheets.googleapis.com/v4/spreadsheets/a%2Fb/values"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requests.JoinURL(tt.base, tt.segments...); got != tt.want {
+				t.Errorf("JoinURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// FuzzJoinURL checks that any identifier is joined as exactly one path segment, leaving the host and query untouched
+func FuzzJoinURL(f *testing.F) {
+	for _, id := range identifiers {
+		f.Add(id)
+	}
+	f.Fuzz(func(t *testing.T, id string) {
+		base := "https://example.okta.com/api/v1/users?limit=200"
+		joined := requests.JoinURL(base, id, "groups")
+
+		u, err := url.Parse(joined)
+		if err != nil {
+			t.Fatalf("JoinURL(%q) = %s, which does not parse: %v", id, joined, err)
+		}
+		if u.Host != "example.okta.com" || u.RawQuery != "limit=200" || u.Fragment != "" {
+			t.Fatalf("JoinURL(%q) = %s, changing the host, query or fragment", id, joined)
+		}
+
+		segments := strings.Split(u.EscapedPath(), "/")
+		if len(segments) != 6 {
+			t.Fatalf("JoinURL(%q) = %s, with %d segments, want 6", id, joined, len(segments))
+		}
+		got, err := url.PathUnescape(segments[4])
+		if err != nil || got != id {
+			t.Fatalf("JoinURL(%q) segment = %q (%v), want the identifier", id, got, err)
+		}
+		if segments[5] != "groups" {
+			t.Fatalf("JoinURL(%q) = %s, want the identifier followed by groups", id, joined)
+		}
+	})
+}
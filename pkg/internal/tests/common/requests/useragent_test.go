@@ -0,0 +1,44 @@
+// pkg/internal/tests/common/requests/useragent_test.go
+package requests_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+func TestUserAgent(t *testing.T) {
+	t.Cleanup(func() { requests.SetUserAgent(requests.DefaultUserAgent) })
+
+	base := requests.DefaultUserAgent.String()
+	if !strings.HasPrefix(base, "rego/") || !strings.HasSuffix(base, "(+https://github.com/gemini-oss/rego)") {
+		t.Errorf("DefaultUserAgent = %s, want rego/<version> (+<repository>)", base)
+	}
+
+	tests := []struct {
+		name    string
+		ua      requests.UserAgent
+		headers requests.Headers
+		opts    []requests.Option
+		want    string
+	}{
+		{"default", requests.DefaultUserAgent, nil, nil, base},
+		{"suffixes", requests.DefaultUserAgent, nil, []requests.Option{requests.WithUserAgent("okta", "", "offboarding/1.2")}, base + " okta offboarding/1.2"},
+		{"product", requests.UserAgent{Product: "acme-sync", Version: "2.0.0"}, nil, []requests.Option{requests.WithUserAgent("google")}, "acme-sync/2.0.0 (+https://github.com/gemini-oss/rego) google"},
+		{"header", requests.DefaultUserAgent, requests.Headers{"User-Agent": "custom/1.0"}, []requests.Option{requests.WithUserAgent("okta")}, "custom/1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.SetUserAgent(tt.ua)
+			c := requests.NewClient(nil, tt.headers, nil, tt.opts...)
+			req, err := c.CreateRequest("GET", "https://example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := req.Header.Get("User-Agent"); got != tt.want {
+				t.Errorf("User-Agent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
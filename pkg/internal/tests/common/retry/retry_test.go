@@ -2,6 +2,8 @@
 package retry_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -54,8 +56,8 @@ func TestRetryTimingAndJitter(t *testing.T) {
 	_ = retry.Retry(operation, &mockTime)
 	sleepDurations := mockTime.GetSleepDurations()
 
-	if len(sleepDurations) != retry.MaxRetries {
-		t.Fatalf("Expected %d retry attempts, got %d", retry.MaxRetries, len(sleepDurations))
+	if len(sleepDurations) != retry.MaxRetries-1 {
+		t.Fatalf("Expected %d backoffs between attempts, got %d", retry.MaxRetries-1, len(sleepDurations))
 	}
 
 	minBackoff := time.Duration(retry.MinBackoff) * time.Millisecond
@@ -86,7 +88,155 @@ func TestExceedingMaxRetries(t *testing.T) {
 	}
 
 	sleepDurations := mockTime.GetSleepDurations()
-	if len(sleepDurations) != retry.MaxRetries {
-		t.Errorf("Expected %d retries, but got %d", retry.MaxRetries, len(sleepDurations))
+	if len(sleepDurations) != retry.MaxRetries-1 {
+		t.Errorf("Expected %d backoffs between attempts, but got %d", retry.MaxRetries-1, len(sleepDurations))
+	}
+}
+
+func TestRetryContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mockTime := MockTime{}
+	attempts := 0
+
+	// Cancelling during an attempt ends the retries with its error
+	err := retry.RetryContext(ctx, func() error {
+		attempts++
+		if attempts == 2 {
+			cancel()
+		}
+		return fmt.Errorf("temporary error")
+	}, &mockTime)
+	if err == nil || attempts != 2 {
+		t.Errorf("Expected to stop after 2 attempts with an error, got %d attempts (%v)", attempts, err)
+	}
+
+	// A done context is not attempted
+	attempts = 0
+	err = retry.RetryContext(ctx, func() error {
+		attempts++
+		return nil
+	}, &mockTime)
+	if !errors.Is(err, context.Canceled) || attempts != 0 {
+		t.Errorf("Expected context.Canceled without attempts, got %d attempts (%v)", attempts, err)
+	}
+
+	// The backoff of real time is interrupted
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	retry.RetryContext(ctx, func() error { return fmt.Errorf("temporary error") }, retry.RealTime{})
+	if elapsed := time.Since(start); elapsed > retry.MinBackoff*time.Millisecond {
+		t.Errorf("Expected the backoff to be interrupted, took %v", elapsed)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	mockTime := MockTime{}
+	policy := retry.Policy{MaxAttempts: 4, MinBackoff: 10 * time.Millisecond, MaxBackoff: 25 * time.Millisecond, NoJitter: true}
+
+	attempts := 0
+	err := policy.Retry(context.Background(), func() error {
+		attempts++
+		return fmt.Errorf("temporary error")
+	}, &mockTime)
+	if err == nil || attempts != 4 {
+		t.Errorf("Expected to stop after 4 attempts with an error, got %d attempts (%v)", attempts, err)
+	}
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 25 * time.Millisecond}
+	got := mockTime.GetSleepDurations()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected backoffs %v, got %v", want, got)
+	}
+
+	// Failures which are not retryable stop at once, unless marked with `After`
+	errPermanent := errors.New("bad request")
+	policy.Retryable = func(err error) bool { return !errors.Is(err, errPermanent) }
+	attempts = 0
+	err = policy.Retry(context.Background(), func() error {
+		attempts++
+		return errPermanent
+	}, &MockTime{})
+	if !errors.Is(err, errPermanent) || attempts != 1 {
+		t.Errorf("Expected to stop after 1 attempt, got %d attempts (%v)", attempts, err)
+	}
+
+	attempts = 0
+	_ = policy.Retry(context.Background(), func() error {
+		attempts++
+		return retry.After(errPermanent, time.Millisecond)
+	}, &MockTime{})
+	if attempts != 4 {
+		t.Errorf("Expected `After` to retry 4 attempts, got %d", attempts)
+	}
+
+	// The zero policy is the default one
+	if d := (retry.Policy{}).Backoff(10); d < retry.MinBackoff*time.Millisecond || d > retry.MaxBackoff*time.Millisecond {
+		t.Errorf("Expected the default backoff within [%dms, %dms], got %v", retry.MinBackoff, retry.MaxBackoff, d)
+	}
+}
+
+func TestOnAttempt(t *testing.T) {
+	var attempts []retry.Attempt
+	policy := retry.Policy{MaxAttempts: 3, OnAttempt: func(a retry.Attempt) { attempts = append(attempts, a) }}
+
+	failures := 0
+	err := policy.Retry(context.Background(), func() error {
+		if failures++; failures < 3 {
+			return errors.New("unavailable")
+		}
+		return nil
+	}, &MockTime{})
+	if err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if len(attempts) != 3 {
+		t.Fatalf("OnAttempt was called %d times, want once per attempt", len(attempts))
+	}
+	for i, a := range attempts[:2] {
+		if a.Number != i+1 || a.Err == nil || a.Last || a.Backoff <= 0 {
+			t.Errorf("attempt %d = %+v, want a failure which is retried", i+1, a)
+		}
+	}
+	if last := attempts[2]; last.Err != nil || !last.Last || last.Backoff != 0 {
+		t.Errorf("last attempt = %+v, want a success", last)
+	}
+
+	attempts = nil
+	policy.Retry(context.Background(), func() error { return retry.Permanent(errors.New("invalid")) }, &MockTime{})
+	if len(attempts) != 1 || !attempts[0].Last || attempts[0].Err == nil {
+		t.Errorf("attempts = %+v, want a single permanent failure", attempts)
+	}
+}
+
+func TestIdempotent(t *testing.T) {
+	for method, want := range map[string]bool{"GET": true, "put": true, "DELETE": true, "HEAD": true, "POST": false, "PATCH": false} {
+		if got := retry.Idempotent(method); got != want {
+			t.Errorf("Idempotent(%s) = %v, want %v", method, got, want)
+		}
+	}
+}
+
+func TestNoBackoffAfterLastAttempt(t *testing.T) {
+	clock := MockTime{}
+	attempts := 0
+
+	policy := retry.Policy{MaxAttempts: 2}
+	err := policy.Retry(context.Background(), func() error {
+		attempts++
+		return retry.After(errors.New("rate limited"), time.Second)
+	}, &clock)
+	if err == nil {
+		t.Fatal("Retry() error = nil, want the last failure")
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+
+	var slept time.Duration
+	for _, d := range clock.GetSleepDurations() {
+		slept += d
+	}
+	if slept != time.Second {
+		t.Errorf("slept %v, want %v between the two attempts and nothing after the last", slept, time.Second)
 	}
 }
@@ -0,0 +1,250 @@
+// pkg/internal/tests/common/scheduler/scheduler_test.go
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/scheduler"
+)
+
+func TestParseNext(t *testing.T) {
+	// Thursday, 15 August 2024
+	from := time.Date(2024, time.August, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.August, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.August, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 6 * * mon-fri", time.Date(2024, time.August, 16, 6, 0, 0, 0, time.UTC)},
+		{"30 9-17/4 * * *", time.Date(2024, time.August, 15, 13, 30, 0, 0, time.UTC)},
+		{"0 0 1 jan,jul *", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2024, time.August, 18, 12, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2024, time.August, 16, 0, 0, 0, 0, time.UTC)}, // Friday or the 13th
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.August, 16, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.August, 15, 11, 0, 0, 0, time.UTC)},
+		{"@every 90m", time.Date(2024, time.August, 15, 11, 37, 30, 0, time.UTC)},
+		{"TZ=America/New_York 0 9 * * *", time.Date(2024, time.August, 15, 13, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := scheduler.Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.spec, err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@every soon",
+		"TZ=Nowhere/Special * * * * *",
+	} {
+		if _, err := scheduler.Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+// never is a schedule which only runs when triggered
+type never struct{}
+
+func (never) Next(time.Time) time.Time { return time.Now().Add(24 * time.Hour) }
+
+func waitFor(t *testing.T, s *scheduler.Scheduler, name string, done func(scheduler.JobStatus) bool) scheduler.JobStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if status, _ := s.Job(name); done(status) {
+			return status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	status, _ := s.Job(name)
+	t.Fatalf("timed out waiting for %s: %+v", name, status)
+	return status
+}
+
+func TestOverlapPrevention(t *testing.T) {
+	s := scheduler.New(log.ERROR)
+	release := make(chan struct{})
+	s.Add(scheduler.Job{
+		Name:     "slow",
+		Schedule: never{},
+		Run: func(ctx context.Context) error {
+			<-release
+			return nil
+		},
+	})
+
+	if err := s.Trigger("slow"); err != nil {
+		t.Fatalf("Trigger: %v", err)
+	}
+	waitFor(t, s, "slow", func(j scheduler.JobStatus) bool { return j.Running == 1 })
+
+	if err := s.Trigger("slow"); !errors.Is(err, scheduler.ErrOverlap) {
+		t.Errorf("Trigger while running = %v, want ErrOverlap", err)
+	}
+	close(release)
+
+	status := waitFor(t, s, "slow", func(j scheduler.JobStatus) bool { return j.Runs == 1 })
+	if status.Skipped != 1 || status.Failures != 0 || status.LastSuccess.IsZero() {
+		t.Errorf("status = %+v, want 1 successful run and 1 skipped", status)
+	}
+
+	if err := s.Trigger("missing"); !errors.Is(err, scheduler.ErrUnknownJob) {
+		t.Errorf("Trigger(missing) = %v, want ErrUnknownJob", err)
+	}
+	if err := s.Add(scheduler.Job{Name: "slow", Schedule: never{}, Run: func(context.Context) error { return nil }}); !errors.Is(err, scheduler.ErrDuplicateJob) {
+		t.Errorf("Add(duplicate) = %v, want ErrDuplicateJob", err)
+	}
+}
+
+func TestTimeoutAndPanic(t *testing.T) {
+	s := scheduler.New(log.ERROR)
+	s.Add(scheduler.Job{
+		Name:     "stuck",
+		Schedule: never{},
+		Timeout:  20 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+	})
+	s.Add(scheduler.Job{
+		Name:     "broken",
+		Schedule: never{},
+		Run: func(ctx context.Context) error {
+			panic("boom")
+		},
+	})
+
+	s.Trigger("stuck")
+	s.Trigger("broken")
+
+	stuck := waitFor(t, s, "stuck", func(j scheduler.JobStatus) bool { return j.Runs == 1 })
+	if stuck.Failures != 1 || !strings.Contains(stuck.LastError, "timed out") {
+		t.Errorf("stuck = %+v, want a timeout failure", stuck)
+	}
+	broken := waitFor(t, s, "broken", func(j scheduler.JobStatus) bool { return j.Runs == 1 })
+	if broken.Failures != 1 || !strings.Contains(broken.LastError, "boom") {
+		t.Errorf("broken = %+v, want the panic as a failure", broken)
+	}
+}
+
+func TestScheduledRunsAndStop(t *testing.T) {
+	s := scheduler.New(log.ERROR)
+	var runs atomic.Int32
+	s.Add(scheduler.Job{
+		Name:     "tick",
+		Schedule: scheduler.Every(time.Second),
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	})
+	s.Start()
+
+	status := waitFor(t, s, "tick", func(j scheduler.JobStatus) bool { return j.Runs >= 1 })
+	if status.Next.IsZero() {
+		t.Errorf("status.Next is not set: %+v", status)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	after := runs.Load()
+	if err := s.Trigger("tick"); !errors.Is(err, scheduler.ErrStopped) {
+		t.Errorf("Trigger after Stop = %v, want ErrStopped", err)
+	}
+	if runs.Load() != after {
+		t.Error("job ran after the scheduler was stopped")
+	}
+}
+
+func TestStopCancelsRuns(t *testing.T) {
+	s := scheduler.New(log.ERROR)
+	cancelled := make(chan struct{})
+	s.Add(scheduler.Job{
+		Name:     "long",
+		Schedule: never{},
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			close(cancelled)
+			return ctx.Err()
+		},
+	})
+	s.Trigger("long")
+	waitFor(t, s, "long", func(j scheduler.JobStatus) bool { return j.Running == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop = %v, want DeadlineExceeded", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("the run was not cancelled")
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	s := scheduler.New(log.ERROR)
+	s.Add(scheduler.Job{Name: "ok", Schedule: never{}, Run: func(context.Context) error { return nil }})
+	s.Add(scheduler.Job{Name: "fails", Schedule: never{}, Run: func(context.Context) error { return errors.New("nope") }})
+	s.Trigger("ok")
+	s.Trigger("fails")
+	waitFor(t, s, "ok", func(j scheduler.JobStatus) bool { return j.Runs == 1 })
+	waitFor(t, s, "fails", func(j scheduler.JobStatus) bool { return j.Runs == 1 })
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		"# TYPE rego_scheduler_job_runs_total counter",
+		`rego_scheduler_job_runs_total{job="ok"} 1`,
+		`rego_scheduler_job_failures_total{job="fails"} 1`,
+		`rego_scheduler_job_last_run_failed{job="fails"} 1`,
+		`rego_scheduler_job_last_run_failed{job="ok"} 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/jobs", nil))
+	if !strings.Contains(rec.Body.String(), `"lastError":"nope"`) {
+		t.Errorf("/jobs = %s, want the last error", rec.Body.String())
+	}
+}
@@ -0,0 +1,165 @@
+// pkg/internal/tests/common/scim/scim_test.go
+package scim_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/scim"
+)
+
+// setupTestClient returns a new SCIM client pointed at the test server
+func setupTestClient(t *testing.T, handler http.HandlerFunc) *scim.Client {
+	t.Helper()
+	t.Setenv("REGO_ENCRYPTION_KEY", "8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		w.Header().Set("Content-Type", scim.ContentType)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return scim.NewClient(server.URL+"/", "test-token", log.ERROR)
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{scim.Eq("userName", `a"b@example.com`), `userName eq "a\"b@example.com"`},
+		{scim.Filter("active", "eq", true), `active eq true`},
+		{scim.Filter("title", "pr", nil), `title pr`},
+		{scim.And(scim.Eq("userName", "a"), scim.Filter("active", "eq", true)), `(userName eq "a") and (active eq true)`},
+		{scim.Or(scim.Eq("displayName", "x")), `displayName eq "x"`},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("filter = %s, want %s", tt.got, tt.want)
+		}
+	}
+}
+
+func TestListUsersPaginates(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Users" {
+			t.Errorf("Unexpected path `%s`", r.URL.Path)
+		}
+		if r.URL.Query().Get("filter") != `active eq true` {
+			t.Errorf("filter = %q", r.URL.Query().Get("filter"))
+		}
+
+		start, _ := strconv.Atoi(r.URL.Query().Get("startIndex"))
+		count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+		page := scim.ListResponse[*scim.User]{Schemas: []string{scim.ListResponseSchema}, TotalResults: 5, StartIndex: start}
+		for i := start; i < start+count && i <= 5; i++ {
+			page.Resources = append(page.Resources, &scim.User{ID: strconv.Itoa(i), UserName: fmt.Sprintf("user%d@example.com", i)})
+		}
+		page.ItemsPerPage = len(page.Resources)
+		json.NewEncoder(w).Encode(page)
+	})
+	client.PageSize = 2
+
+	users, err := client.Users().ListUsers(&scim.Query{Filter: scim.Filter("active", "eq", true)})
+	if err != nil {
+		t.Fatalf("ListUsers() error = %v", err)
+	}
+	if len(users) != 5 {
+		t.Fatalf("ListUsers() returned %d users, want 5", len(users))
+	}
+	for i, user := range users {
+		if user.ID != strconv.Itoa(i+1) {
+			t.Errorf("users[%d].ID = %s, want %d", i, user.ID, i+1)
+		}
+	}
+}
+
+func TestCreateUserOmitsUnsetAttributes(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/Users" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Content-Type") != scim.ContentType {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		payload := map[string]interface{}{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatalf("decoding payload: %v", err)
+		}
+		for _, unset := range []string{"id", "active", "groups", "meta"} {
+			if _, ok := payload[unset]; ok {
+				t.Errorf("payload sets %s: %s", unset, body)
+			}
+		}
+		if _, ok := payload[scim.EnterpriseUserSchema]; !ok {
+			t.Errorf("payload lacks the enterprise extension: %s", body)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"abc","userName":"new@example.com","active":true}`))
+	})
+
+	user, err := client.Users().CreateUser(&scim.User{
+		UserName:   "new@example.com",
+		Name:       &scim.Name{GivenName: "New", FamilyName: "User"},
+		Enterprise: &scim.EnterpriseUser{Department: "Engineering"},
+	})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if user.ID != "abc" || user.Active == nil || !*user.Active {
+		t.Errorf("CreateUser() = %+v", user)
+	}
+}
+
+func TestPatchOperations(t *testing.T) {
+	var got []*scim.PatchOp
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			t.Errorf("Unexpected method %s", r.Method)
+		}
+		patch := &scim.PatchOp{}
+		json.NewDecoder(r.Body).Decode(patch)
+		got = append(got, patch)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	if err := client.Users().DeactivateUser("u1"); err != nil {
+		t.Fatalf("DeactivateUser() error = %v", err)
+	}
+	if err := client.Groups().AddMembers("g1", "u1", "u2"); err != nil {
+		t.Fatalf("AddMembers() error = %v", err)
+	}
+	if err := client.Groups().RemoveMembers("g1", "u3"); err != nil {
+		t.Fatalf("RemoveMembers() error = %v", err)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("sent %d patches, want 3", len(got))
+	}
+	if op := got[0].Operations[0]; op.Op != "replace" || op.Path != "active" || op.Value != false {
+		t.Errorf("deactivate operation = %+v", op)
+	}
+	if op := got[1].Operations[0]; op.Op != "add" || op.Path != "members" || len(op.Value.([]interface{})) != 2 {
+		t.Errorf("add members operation = %+v", op)
+	}
+	if op := got[2].Operations[0]; op.Op != "remove" || op.Path != `members[value eq "u3"]` {
+		t.Errorf("remove members operation = %+v", op)
+	}
+	for _, patch := range got {
+		if len(patch.Schemas) != 1 || patch.Schemas[0] != scim.PatchOpSchema {
+			t.Errorf("patch schemas = %v", patch.Schemas)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// pkg/internal/tests/common/starstruct/query_test.go
+package starstruct_test
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/starstruct"
+)
+
+type status string
+
+func (s status) MarshalText() ([]byte, error) { return []byte("STATUS_" + string(s)), nil }
+
+type Page struct {
+	Limit int `url:"limit,omitempty"`
+}
+
+type logQuery struct {
+	Page
+	Since  time.Time `url:"since,omitempty"`
+	Until  time.Time `url:"until,omitempty,unix"`
+	Millis time.Time `url:"millis,omitempty,unixmilli"`
+	Day    time.Time `url:"day,omitempty" layout:"2006-01-02"`
+	Zero   time.Time `url:"zero,omitempty"`
+	Fields []string  `url:"fields,comma"`
+	Types  []string  `url:"type"`
+	IDs    []int     `url:"id,brackets"`
+	Active bool      `url:"active"`
+	Expand *bool     `url:"expand,omitempty"`
+	Cursor *string   `url:"cursor"`
+	Status status    `url:"status,omitempty"`
+	Filter struct {  // Nested as filter[name]
+		Name string `url:"name"`
+	} `url:"filter"`
+	Search   string `json:"search,omitempty"`
+	SortBy   string
+	Empty    string `json:"empty"`
+	Ignored  string `url:"-"`
+	internal string
+}
+
+func TestQueryValues(t *testing.T) {
+	at := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	expand := false
+	q := logQuery{
+		Page:   Page{Limit: 200},
+		Since:  at,
+		Until:  at,
+		Millis: at,
+		Day:    at,
+		Fields: []string{"id", "name"},
+		Types:  []string{"a", "b"},
+		IDs:    []int{1, 2},
+		Expand: &expand,
+		Status: "ACTIVE",
+		Search: `status eq "ACTIVE"`,
+		SortBy: "id",
+	}
+	q.Filter.Name = "ada"
+
+	got, err := starstruct.QueryValues(&q)
+	if err != nil {
+		t.Fatalf("QueryValues() error = %v", err)
+	}
+	want := url.Values{
+		"limit":        {"200"},
+		"since":        {"2024-01-02T15:04:05Z"},
+		"until":        {"1704207845"},
+		"millis":       {"1704207845000"},
+		"day":          {"2024-01-02"},
+		"fields":       {"id,name"},
+		"type":         {"a", "b"},
+		"id[]":         {"1", "2"},
+		"active":       {"false"},
+		"expand":       {"false"},
+		"status":       {"STATUS_ACTIVE"},
+		"filter[name]": {"ada"},
+		"search":       {`status eq "ACTIVE"`},
+		"sortBy":       {"id"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryValues() = %v, want %v", got, want)
+	}
+
+	m, err := starstruct.QueryValues(map[string]interface{}{"q": "ada", "type": []string{"a", "b"}})
+	if err != nil || m.Encode() != "q=ada&type=a&type=b" {
+		t.Errorf("QueryValues() of a map = %q, %v", m.Encode(), err)
+	}
+
+	if v, err := starstruct.QueryValues((*logQuery)(nil)); err != nil || len(v) != 0 {
+		t.Errorf("QueryValues(nil) = %v, %v, want no values", v, err)
+	}
+	if _, err := starstruct.QueryValues("limit=1"); err == nil {
+		t.Error("QueryValues() accepted a string")
+	}
+}
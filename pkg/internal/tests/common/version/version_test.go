@@ -0,0 +1,59 @@
+// pkg/internal/tests/common/version/version_test.go
+package version_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/enum"
+	"github.com/gemini-oss/rego/pkg/common/version"
+)
+
+type apiVersion string
+type capability string
+
+const (
+	V1   apiVersion = "v1"
+	BETA apiVersion = "beta"
+)
+
+var capabilities = version.Capabilities[capability, apiVersion]{
+	"preview": {BETA},
+}
+
+func TestPin(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		want    apiVersion
+		wantErr error
+	}{
+		{"default", "", V1, nil},
+		{"pinned", "beta", BETA, nil},
+		{"case", "BETA", BETA, nil},
+		{"unknown", "v2", "", enum.ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := version.Pin(tt.version, V1, V1, BETA)
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Pin(%q) = %q, %v, want %q, %v", tt.version, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	if !capabilities.Supports(BETA, "preview") || capabilities.Supports(V1, "preview") {
+		t.Error("Expected only the beta to serve the preview")
+	}
+	if !capabilities.Supports(V1, "users") {
+		t.Error("Expected every version to serve a capability which is not mapped")
+	}
+	if err := capabilities.Require(V1, "preview"); !errors.Is(err, version.ErrUnsupported) {
+		t.Errorf("Require() = %v, want ErrUnsupported", err)
+	}
+	if err := capabilities.Require(BETA, "preview"); err != nil {
+		t.Errorf("Require() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,306 @@
+// pkg/internal/tests/common/webhooks/webhooks_test.go
+package webhooks_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/webhooks"
+)
+
+func setupServer(t *testing.T) (*webhooks.Server, *[]*webhooks.Event) {
+	t.Helper()
+	s := webhooks.NewServer(":0", log.ERROR)
+	err := errors.Join(
+		s.Okta("/okta", webhooks.OktaConfig{Secret: "okta-secret"}),
+		s.Slack("/slack", "slack-secret"),
+		s.Jamf("/jamf", webhooks.JamfConfig{Username: "jamf", Password: "hunter2"}),
+	)
+	if err != nil {
+		t.Fatalf("Registering the routes: %v", err)
+	}
+
+	received := &[]*webhooks.Event{}
+	s.On("*", func(e *webhooks.Event) error {
+		*received = append(*received, e)
+		return nil
+	})
+	return s, received
+}
+
+func serve(s *webhooks.Server, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func TestOktaVerification(t *testing.T) {
+	s, _ := setupServer(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/okta", nil)
+	r.Header.Set("Authorization", "okta-secret")
+	r.Header.Set("X-Okta-Verification-Challenge", "abc123")
+
+	w := serve(s, r)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"verification":"abc123"`) {
+		t.Errorf("Unexpected verification response: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestOktaEventHook(t *testing.T) {
+	s, received := setupServer(t)
+
+	body := `{"eventType":"com.okta.event_hook","eventId":"d1","data":{"events":[
+		{"uuid":"e1","published":"2024-05-01T10:00:00Z","eventType":"user.lifecycle.deactivate",
+		 "actor":{"id":"00u0","type":"User","alternateId":"admin@example.com"},
+		 "target":[{"id":"00u1","type":"User","alternateId":"ada@example.com"}]}]}}`
+
+	r := httptest.NewRequest(http.MethodPost, "/okta", strings.NewReader(body))
+	if w := serve(s, r); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected an unauthenticated delivery to be rejected, got %d", w.Code)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/okta", strings.NewReader(body))
+	r.Header.Set("Authorization", "okta-secret")
+	if w := serve(s, r); w.Code != http.StatusOK {
+		t.Fatalf("Expected the delivery to be accepted, got %d", w.Code)
+	}
+
+	if len(*received) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(*received))
+	}
+	e := (*received)[0]
+	if e.Name() != "okta.user.lifecycle.deactivate" || e.Actor != "admin@example.com" || !slices.Equal(e.Subjects, []string{"ada@example.com"}) {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+}
+
+func signSlack(r *http.Request, secret, body string, ts time.Time) {
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	r.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	r.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestSlackEvents(t *testing.T) {
+	s, received := setupServer(t)
+
+	challenge := `{"type":"url_verification","challenge":"xyz"}`
+	r := httptest.NewRequest(http.MethodPost, "/slack", strings.NewReader(challenge))
+	signSlack(r, "slack-secret", challenge, time.Now())
+	if w := serve(s, r); w.Body.String() != "xyz" {
+		t.Errorf("Expected the challenge to be echoed, got %q", w.Body.String())
+	}
+
+	body := `{"type":"event_callback","event_id":"Ev1","event_time":1714557600,"event":{"type":"team_join","user":{"id":"U123","name":"ada"}}}`
+
+	r = httptest.NewRequest(http.MethodPost, "/slack", strings.NewReader(body))
+	signSlack(r, "wrong-secret", body, time.Now())
+	if w := serve(s, r); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected an invalid signature to be rejected, got %d", w.Code)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/slack", strings.NewReader(body))
+	signSlack(r, "slack-secret", body, time.Now().Add(-time.Hour))
+	if w := serve(s, r); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a stale timestamp to be rejected, got %d", w.Code)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/slack", strings.NewReader(body))
+	signSlack(r, "slack-secret", body, time.Now())
+	if w := serve(s, r); w.Code != http.StatusOK {
+		t.Fatalf("Expected the event to be accepted, got %d", w.Code)
+	}
+
+	if len(*received) != 1 || (*received)[0].Name() != "slack.team_join" || !slices.Equal((*received)[0].Subjects, []string{"U123"}) {
+		t.Errorf("Unexpected events: %+v", *received)
+	}
+}
+
+func TestJamfWebhook(t *testing.T) {
+	s, received := setupServer(t)
+
+	var computerEvents []string
+	s.On("jamf.Computer*", func(e *webhooks.Event) error {
+		computerEvents = append(computerEvents, e.Type)
+		return nil
+	})
+
+	body := `{"webhook":{"id":7,"name":"checkin","webhookEvent":"ComputerCheckIn","eventTimestamp":1714557600000},
+		"event":{"computer":{"udid":"UDID-1","serialNumber":"C02ABC","emailAddress":"ada@example.com"}}}`
+
+	r := httptest.NewRequest(http.MethodPost, "/jamf", strings.NewReader(body))
+	r.SetBasicAuth("jamf", "wrong")
+	if w := serve(s, r); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected invalid credentials to be rejected, got %d", w.Code)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/jamf", strings.NewReader(body))
+	r.SetBasicAuth("jamf", "hunter2")
+	if w := serve(s, r); w.Code != http.StatusOK {
+		t.Fatalf("Expected the webhook to be accepted, got %d", w.Code)
+	}
+
+	if len(*received) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(*received))
+	}
+	e := (*received)[0]
+	if e.ID != "7-1714557600000-UDID-1" || !e.Time.Equal(time.UnixMilli(1714557600000)) || !slices.Equal(e.Subjects, []string{"C02ABC", "ada@example.com"}) {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+	if !slices.Equal(computerEvents, []string{"ComputerCheckIn"}) {
+		t.Errorf("Expected the prefix subscription to match, got %v", computerEvents)
+	}
+
+	// Another device checking in within the same millisecond is not a replay
+	body = strings.Replace(body, "UDID-1", "UDID-2", 1)
+	r = httptest.NewRequest(http.MethodPost, "/jamf", strings.NewReader(body))
+	r.SetBasicAuth("jamf", "hunter2")
+	if w := serve(s, r); w.Code != http.StatusOK {
+		t.Fatalf("Expected the webhook to be accepted, got %d", w.Code)
+	}
+	if len(*received) != 2 || (*received)[1].ID != "7-1714557600000-UDID-2" {
+		t.Errorf("Expected the second device to be dispatched, got %+v", *received)
+	}
+}
+
+func signZoom(r *http.Request, secret, body string, ts time.Time) {
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	r.Header.Set("x-zm-request-timestamp", timestamp)
+	r.Header.Set("x-zm-signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestZoomWebhook(t *testing.T) {
+	s, received := setupServer(t)
+	if err := s.Zoom("/zoom", "zoom-secret"); err != nil {
+		t.Fatalf("Registering the route: %v", err)
+	}
+
+	validation := `{"event":"endpoint.url_validation","event_ts":1714557600000,"payload":{"plainToken":"plain"}}`
+	r := httptest.NewRequest(http.MethodPost, "/zoom", strings.NewReader(validation))
+	signZoom(r, "zoom-secret", validation, time.Now())
+	mac := hmac.New(sha256.New, []byte("zoom-secret"))
+	mac.Write([]byte("plain"))
+	if w := serve(s, r); !strings.Contains(w.Body.String(), `"encryptedToken":"`+hex.EncodeToString(mac.Sum(nil))+`"`) {
+		t.Errorf("Unexpected validation response: %d %s", w.Code, w.Body.String())
+	}
+
+	body := `{"event":"user.deactivated","event_ts":1714557600000,"payload":{"account_id":"A1","operator":"admin@example.com",
+		"object":{"id":"Z1","email":"ada@example.com"}}}`
+
+	r = httptest.NewRequest(http.MethodPost, "/zoom", strings.NewReader(body))
+	signZoom(r, "zoom-secret", body, time.Now().Add(-time.Hour))
+	if w := serve(s, r); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a stale timestamp to be rejected, got %d", w.Code)
+	}
+
+	// The redelivery is acknowledged, but not dispatched again
+	for i := 0; i < 2; i++ {
+		r = httptest.NewRequest(http.MethodPost, "/zoom", strings.NewReader(body))
+		signZoom(r, "zoom-secret", body, time.Now())
+		if w := serve(s, r); w.Code != http.StatusOK {
+			t.Fatalf("Expected the webhook to be accepted, got %d", w.Code)
+		}
+	}
+
+	if len(*received) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(*received))
+	}
+	e := (*received)[0]
+	if e.Name() != "zoom.user.deactivated" || e.Actor != "admin@example.com" || !slices.Equal(e.Subjects, []string{"ada@example.com"}) {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+	if n := e.Normalize(); n.Type != "user.deactivated" {
+		t.Errorf("Expected the event to normalize to user.deactivated, got %s", n.Type)
+	}
+}
+
+type pushEvent struct {
+	ID   string `json:"id"`
+	Repo string `json:"repo"`
+}
+
+func TestRegister(t *testing.T) {
+	s, received := setupServer(t)
+	err := webhooks.Register(s, "/github", webhooks.Route[pushEvent]{
+		Provider:  "github",
+		Verifiers: []webhooks.Verifier{&webhooks.HMACVerifier{Secret: "gh-secret", SignatureHeader: "X-Hub-Signature-256", Version: "sha256"}},
+		Events: func(p *pushEvent) ([]*webhooks.Event, error) {
+			return []*webhooks.Event{{ID: p.ID, Provider: "github", Type: "push", Subjects: []string{p.Repo}}}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Registering the route: %v", err)
+	}
+
+	body := `{"id":"P1","repo":"rego"}`
+	mac := hmac.New(sha256.New, []byte("gh-secret"))
+	mac.Write([]byte(body))
+
+	r := httptest.NewRequest(http.MethodPost, "/github", strings.NewReader(body))
+	r.Header.Set("X-Hub-Signature-256", "sha256=deadbeef")
+	if w := serve(s, r); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected an invalid signature to be rejected, got %d", w.Code)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/github", strings.NewReader(body))
+	r.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	if w := serve(s, r); w.Code != http.StatusOK {
+		t.Fatalf("Expected the webhook to be accepted, got %d", w.Code)
+	}
+
+	if len(*received) != 1 || (*received)[0].Name() != "github.push" || !slices.Equal((*received)[0].Subjects, []string{"rego"}) {
+		t.Errorf("Unexpected events: %+v", *received)
+	}
+}
+
+func TestInsecureRoutes(t *testing.T) {
+	s := webhooks.NewServer(":0", log.ERROR)
+	for name, err := range map[string]error{
+		"okta":       s.Okta("/okta", webhooks.OktaConfig{}),
+		"slack":      s.Slack("/slack", ""),
+		"jamf":       s.Jamf("/jamf", webhooks.JamfConfig{AuthHeader: "X-Jamf-Token"}),
+		"zoom":       s.Zoom("/zoom", ""),
+		"unverified": webhooks.Register(s, "/custom", webhooks.Route[pushEvent]{Provider: "custom"}),
+	} {
+		if !errors.Is(err, webhooks.ErrInsecure) {
+			t.Errorf("Registering %s without a secret error = %v, want ErrInsecure", name, err)
+		}
+	}
+
+	// A verifier without a secret rejects every request, even if it was registered
+	body := `{"id":"P1"}`
+	v := &webhooks.HMACVerifier{SignatureHeader: "X-Signature"}
+	mac := hmac.New(sha256.New, nil)
+	mac.Write([]byte(body))
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("X-Signature", hex.EncodeToString(mac.Sum(nil)))
+	if err := v.Verify(r, []byte(body)); err == nil {
+		t.Error("Expected a signature made with an empty secret to be rejected")
+	}
+
+	// Unauthenticated routes are only accepted when the server is explicitly insecure
+	s = webhooks.NewServer(":0", log.ERROR)
+	s.Insecure = true
+	if err := s.Okta("/okta", webhooks.OktaConfig{}); err != nil {
+		t.Errorf("Registering on an insecure server error = %v", err)
+	}
+	r = httptest.NewRequest(http.MethodPost, "/okta", strings.NewReader(`{"data":{"events":[]}}`))
+	if w := serve(s, r); w.Code != http.StatusOK {
+		t.Errorf("Expected the insecure route to accept the delivery, got %d", w.Code)
+	}
+}
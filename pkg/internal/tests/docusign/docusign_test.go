@@ -0,0 +1,139 @@
+/*
+# DocuSign - Test
+
+This package runs tests for functions which interact with the DocuSign eSignature REST API:
+https://developers.docusign.com/docs/esign-rest-api/reference/
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/internal/tests/docusign/docusign_test.go
+package docusign_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/docusign"
+)
+
+const accountID = "00000000-aaaa-bbbb-cccc-000000000000"
+
+// setupTestServer returns a new test server and a cleanup function
+func setupTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, func()) {
+	server := httptest.NewServer(handler)
+	return server, func() { server.Close() }
+}
+
+// setupTestClient returns a new DocuSign client pointed at the test server
+func setupTestClient(t *testing.T, serverURL string) *docusign.Client {
+	t.Setenv("DOCUSIGN_BASE_URL", serverURL)
+	t.Setenv("DOCUSIGN_ACCOUNT_ID", accountID)
+	t.Setenv("DOCUSIGN_ACCESS_TOKEN", "test-token")
+	t.Setenv("REGO_ENCRYPTION_KEY", "8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt")
+
+	return docusign.NewClient(log.DEBUG)
+}
+
+func TestListAllUsers(t *testing.T) {
+	usersPath := "/restapi/v2.1/accounts/" + accountID + "/users"
+	server, cleanup := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != usersPath {
+			t.Errorf("Expected path `%s`, got `%s`", usersPath, r.URL.Path)
+		}
+		switch r.URL.Query().Get("start_position") {
+		case "":
+			w.Write([]byte(`{"users":[{"userId":"1","email":"a@gemini.com","userStatus":"Active"}],"startPosition":"0","endPosition":"0","totalSetSize":"2"}`))
+		case "1":
+			w.Write([]byte(`{"users":[{"userId":"2","email":"b@gemini.com","userStatus":"Closed"}],"startPosition":"1","endPosition":"1","totalSetSize":"2"}`))
+		default:
+			t.Errorf("Unexpected start_position `%s`", r.URL.Query().Get("start_position"))
+		}
+	})
+	defer cleanup()
+
+	client := setupTestClient(t, server.URL)
+
+	users, err := client.Users().ListAllUsers()
+	if err != nil {
+		t.Fatalf("ListAllUsers() error = %v", err)
+	}
+	if len(*users) != 2 {
+		t.Fatalf("ListAllUsers() returned %d users, want 2", len(*users))
+	}
+	if (*users)[1].UserID != "2" {
+		t.Errorf("ListAllUsers() second user ID = %s, want 2", (*users)[1].UserID)
+	}
+}
+
+func TestCloseUsers(t *testing.T) {
+	server, cleanup := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			t.Errorf("Expected method DELETE, got %s", r.Method)
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		payload := docusign.UserInfoList{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatalf("Failed to decode payload: %v", err)
+		}
+		if len(payload.Users) != 1 || payload.Users[0].UserID != "1" {
+			t.Errorf("Unexpected payload: %s", string(body))
+		}
+
+		w.Write([]byte(`{"users":[{"userId":"1","userStatus":"closed"}]}`))
+	})
+	defer cleanup()
+
+	client := setupTestClient(t, server.URL)
+
+	if err := client.Users().CloseUser("1"); err != nil {
+		t.Errorf("CloseUser() error = %v", err)
+	}
+}
+
+func TestUsageReport(t *testing.T) {
+	base := "/restapi/v2.1/accounts/" + accountID
+	responses := map[string]string{
+		base + "/billing_plan":    `{"billingPlan":{"planName":"Business Pro","includedSeats":"5"}}`,
+		base + "/billing_charges": `{"billingChargeItems":[{"chargeType":"envelopes","usedQuantity":"42"},{"chargeType":"seats","usedQuantity":"3"}]}`,
+		base + "/users":           `{"users":[{"userId":"1","userStatus":"Active","isAdmin":"True"},{"userId":"2","userStatus":"ActivationSent"},{"userId":"3","userStatus":"Closed"}],"endPosition":"2","totalSetSize":"3"}`,
+	}
+
+	server, cleanup := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			t.Errorf("No mock response for path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(response))
+	})
+	defer cleanup()
+
+	client := setupTestClient(t, server.URL)
+
+	report, err := client.Accounts().UsageReport()
+	if err != nil {
+		t.Fatalf("UsageReport() error = %v", err)
+	}
+
+	if report.IncludedSeats != 5 || report.ActiveUsers != 1 || report.PendingUsers != 1 || report.ClosedUsers != 1 {
+		t.Errorf("UsageReport() = %+v", report)
+	}
+	if report.AdminUsers != 1 {
+		t.Errorf("UsageReport() AdminUsers = %d, want 1", report.AdminUsers)
+	}
+	if report.AvailableSeats != 3 {
+		t.Errorf("UsageReport() AvailableSeats = %d, want 3", report.AvailableSeats)
+	}
+	if report.Charges["envelopes"] != 42 {
+		t.Errorf("UsageReport() envelope usage = %d, want 42", report.Charges["envelopes"])
+	}
+}
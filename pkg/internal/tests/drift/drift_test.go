@@ -0,0 +1,285 @@
+// pkg/internal/tests/drift/drift_test.go
+package drift_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/drift"
+)
+
+const stateYAML = `
+# Desired state of the IT SaaS configuration
+okta:
+  groups:
+    aws-admins:
+      exclusive: true
+      members: [alice@example.com, "bob@example.com"]
+    Everyone:
+      members:
+      - alice@example.com   # indented sequences are optional
+google:
+  groups:
+    security@example.com:
+      members:
+        - Alice@example.com
+        - 'carol@example.com'
+  orgUnits:
+    /Engineering:
+      - alice@example.com
+    "/Sales # EMEA":
+      - dave@example.com
+`
+
+func TestParseYAML(t *testing.T) {
+	state, err := drift.ParseYAML([]byte(stateYAML))
+	if err != nil {
+		t.Fatalf("ParseYAML: %v", err)
+	}
+
+	want := &drift.State{
+		Okta: &drift.OktaState{Groups: map[string]*drift.Membership{
+			"aws-admins": {Exclusive: true, Members: []string{"alice@example.com", "bob@example.com"}},
+			"Everyone":   {Members: []string{"alice@example.com"}},
+		}},
+		Google: &drift.GoogleState{
+			Groups: map[string]*drift.Membership{
+				"security@example.com": {Members: []string{"Alice@example.com", "carol@example.com"}},
+			},
+			OrgUnits: map[string][]string{
+				"/Engineering":  {"alice@example.com"},
+				"/Sales # EMEA": {"dave@example.com"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(state, want) {
+		t.Errorf("ParseYAML() = %+v, want %+v", state, want)
+	}
+}
+
+func TestParseInvalidState(t *testing.T) {
+	for name, input := range map[string]string{
+		"unknown field":  "okta:\n  group:\n    admins:\n      members: [a]\n",
+		"wrong type":     "okta:\n  groups:\n    admins:\n      members: yes\n",
+		"bad indent":     "okta:\n  groups:\n    admins:\n      members: [a]\n   extra: true\n",
+		"duplicate key":  "okta:\n  groups: {}\n  groups: {}\n",
+		"tab indent":     "okta:\n\tgroups: {}\n",
+		"block scalar":   "okta: |\n  text\n",
+		"unterminated":   "okta:\n  groups:\n    \"admins: {}\n",
+		"mapping in seq": "google:\n  orgUnits:\n    /Eng:\n      - a\n      b: c\n",
+	} {
+		if _, err := drift.ParseYAML([]byte(input)); err == nil {
+			t.Errorf("%s: ParseYAML succeeded, want an error", name)
+		}
+	}
+
+	if _, err := drift.ParseJSON([]byte(`{"okta": {"groups": {}}, "github": {}}`)); err == nil {
+		t.Error("ParseJSON accepted an unknown provider")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	desired, err := drift.ParseYAML([]byte(stateYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := &drift.State{
+		Okta: &drift.OktaState{Groups: map[string]*drift.Membership{
+			"aws-admins": {Members: []string{"ALICE@example.com", "mallory@example.com"}},
+			"Everyone":   {Members: []string{"alice@example.com", "zed@example.com"}},
+		}},
+		Google: &drift.GoogleState{
+			Groups: map[string]*drift.Membership{
+				"security@example.com": {Members: []string{"alice@example.com", "eve@example.com"}},
+			},
+			OrgUnits: map[string][]string{
+				"/":             {"alice@example.com"},
+				"/Sales # EMEA": {"dave@example.com"},
+			},
+		},
+	}
+
+	got := []string{}
+	for _, d := range drift.Diff(desired, actual) {
+		got = append(got, d.String())
+		if d.Status != drift.Pending {
+			t.Errorf("%s has status %s, want pending", d, d.Status)
+		}
+	}
+	want := []string{
+		"add bob@example.com to okta group aws-admins",
+		"remove mallory@example.com from okta group aws-admins",
+		"add carol@example.com to google group security@example.com",
+		"move alice@example.com from google org unit / to /Engineering",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestReport(t *testing.T) {
+	report := &drift.Report{
+		Drift: []*drift.Drift{
+			{Provider: drift.Okta, Kind: drift.GroupMembership, Resource: "aws-admins", Subject: "bob@example.com", Action: drift.Add, Status: drift.Pending},
+			{Provider: drift.Okta, Kind: drift.GroupMembership, Resource: "aws-admins", Subject: "mallory@example.com", Action: drift.Remove, Status: drift.Pending},
+			{Provider: drift.Google, Kind: drift.OrgUnit, Resource: "/Engineering", Subject: "alice@example.com", Action: drift.Change, Actual: "/", Desired: "/Engineering", Status: drift.Pending},
+		},
+		Errors: []string{"okta group Missing: group Missing not found"},
+	}
+
+	plan := report.String()
+	for _, want := range []string{
+		"okta group aws-admins\n  + bob@example.com\n  - mallory@example.com\n",
+		"google org unit /Engineering\n  ~ alice@example.com (/ -> /Engineering)\n",
+		"! okta group Missing",
+		"Plan: 1 to add, 1 to remove, 1 to change.",
+	} {
+		if !strings.Contains(plan, want) {
+			t.Errorf("plan is missing %q:\n%s", want, plan)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := report.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := drift.LoadReport(path)
+	if err != nil {
+		t.Fatalf("LoadReport: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Drift, report.Drift) {
+		t.Errorf("LoadReport() = %+v, want %+v", loaded.Drift, report.Drift)
+	}
+	if loaded.InSync() {
+		t.Error("InSync() = true, want false")
+	}
+}
+
+func TestApply(t *testing.T) {
+	d := &drift.Detector{Log: log.NewLogger("{drift}", log.ERROR)}
+	report := &drift.Report{
+		Drift: []*drift.Drift{
+			{Provider: drift.Okta, Kind: drift.GroupMembership, Resource: "aws-admins", Subject: "bob@example.com", Action: drift.Add, Status: drift.Pending},
+			{Provider: drift.Google, Kind: drift.GroupMembership, Resource: "security@example.com", Subject: "carol@example.com", Action: drift.Add, Status: drift.Pending},
+			{Provider: drift.Google, Kind: drift.OrgUnit, Resource: "/Engineering", Subject: "alice@example.com", Action: drift.Change, Status: drift.Applied},
+		},
+	}
+
+	if err := d.Apply(report, nil); err == nil {
+		t.Error("Apply without an approver succeeded")
+	}
+
+	// Only Okta changes are approved, and no clients are configured, so the approved change fails
+	err := d.Apply(report, func(d *drift.Drift) bool { return d.Provider == drift.Okta })
+	if err == nil {
+		t.Fatal("Apply succeeded without an Okta client")
+	}
+
+	statuses := []drift.Status{}
+	for _, d := range report.Drift {
+		statuses = append(statuses, d.Status)
+	}
+	if want := []drift.Status{drift.Failed, drift.Skipped, drift.Applied}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+	if report.Drift[0].Error == "" {
+		t.Error("the failed drift has no error")
+	}
+	if len(report.Pending()) != 2 {
+		t.Errorf("Pending() = %d drifts, want 2", len(report.Pending()))
+	}
+}
+
+func TestResolveSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hr.csv")
+	hr := "Name,Email,Department\nAlice,alice@example.com,Engineering\nBob,bob@example.com,Sales\nCarol, carol@example.com ,engineering\nDave,,Engineering\n"
+	if err := os.WriteFile(path, []byte(hr), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := drift.ParseYAML([]byte(`
+slack:
+  usergroups:
+    engineering:
+      members: [zed@example.com]
+      sources:
+        - csv: ` + path + `
+          where:
+            department: Engineering
+    broken:
+      exclusive: true
+      sources:
+        - group: github:engineering
+`))
+	if err != nil {
+		t.Fatalf("ParseYAML: %v", err)
+	}
+
+	d := &drift.Detector{Log: log.NewLogger("{drift}", log.ERROR)}
+	resolved, errs := d.Resolve(state)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "slack usergroup broken") {
+		t.Errorf("Resolve() errors = %v, want one for the broken usergroup", errs)
+	}
+	if _, ok := resolved.Slack.Usergroups["broken"]; ok {
+		t.Error("Resolve() kept a usergroup whose source failed")
+	}
+
+	want := []string{"alice@example.com", "carol@example.com", "zed@example.com"}
+	if got := resolved.Slack.Usergroups["engineering"].Members; !reflect.DeepEqual(got, want) {
+		t.Errorf("resolved members = %v, want %v", got, want)
+	}
+	if len(state.Slack.Usergroups["engineering"].Members) != 1 {
+		t.Error("Resolve() modified the desired state")
+	}
+
+	if _, err := drift.ReadCSV(path, "login", nil); err == nil {
+		t.Error("ReadCSV() accepted a missing column")
+	}
+}
+
+func TestDiffRenamesAndExclusions(t *testing.T) {
+	desired := &drift.State{
+		Okta: &drift.OktaState{Groups: map[string]*drift.Membership{
+			"aws-admins": {
+				Exclusive:   true,
+				FormerNames: []string{"aws-admin"},
+				Exclude:     []string{"breakglass@example.com", "svc@example.com"},
+				Members:     []string{"alice@example.com", "svc@example.com"},
+			},
+		}},
+	}
+	actual := &drift.State{
+		Okta: &drift.OktaState{Groups: map[string]*drift.Membership{
+			"aws-admins": {FormerNames: []string{"aws-admin"}, Members: []string{"BreakGlass@example.com", "mallory@example.com"}},
+		}},
+	}
+
+	report := &drift.Report{Drift: drift.Diff(desired, actual)}
+	got := []string{}
+	for _, d := range report.Drift {
+		got = append(got, d.String())
+	}
+	want := []string{
+		"rename okta group aws-admin to aws-admins",
+		"add alice@example.com to okta group aws-admins",
+		"remove mallory@example.com from okta group aws-admins",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Diff() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if report.Drift[1].Actual != "aws-admin" {
+		t.Errorf("membership drift Actual = %q, want the current name of the group", report.Drift[1].Actual)
+	}
+
+	plan := report.String()
+	for _, want := range []string{"okta group aws-admins\n  ~ name (aws-admin -> aws-admins)\n", "Plan: 1 to add, 1 to remove, 1 to change."} {
+		if !strings.Contains(plan, want) {
+			t.Errorf("plan is missing %q:\n%s", want, plan)
+		}
+	}
+}
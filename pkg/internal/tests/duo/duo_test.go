@@ -0,0 +1,116 @@
+/*
+# Duo - Test
+
+This package runs tests for functions which interact with the Duo Admin API:
+https://duo.com/docs/adminapi
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/internal/tests/duo/duo_test.go
+package duo_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/duo"
+)
+
+const (
+	integrationKey = "DIWJ8X6AEYOR5OMC6TQ1"
+	secretKey      = "Zh5eGmUq9zpfQnyUIu5OL9iWoMMv5ZNmk3zLJ4Ep"
+)
+
+// verify checks the signature of a request, computed independently of the package
+func verify(t *testing.T, r *http.Request) {
+	t.Helper()
+	date := r.Header.Get("Date")
+	if date == "" {
+		t.Fatal("request has no Date header")
+	}
+
+	canon := strings.Join([]string{date, r.Method, strings.ToLower(r.Host), r.URL.Path, r.URL.RawQuery}, "\n")
+	mac := hmac.New(sha1.New, []byte(secretKey))
+	mac.Write([]byte(canon))
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte(integrationKey+":"+hex.EncodeToString(mac.Sum(nil))))
+
+	if got := r.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %s, want %s", got, want)
+	}
+}
+
+// setupTestClient returns a new Duo client pointed at a test server
+func setupTestClient(t *testing.T, handler http.HandlerFunc) *duo.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verify(t, r)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("DUO_API_HOST", server.URL)
+	t.Setenv("DUO_INTEGRATION_KEY", integrationKey)
+	t.Setenv("DUO_SECRET_KEY", secretKey)
+	t.Setenv("REGO_ENCRYPTION_KEY", "8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt")
+
+	return duo.NewClient(log.ERROR)
+}
+
+func TestListAllUsersPaginates(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/admin/v1/users" {
+			t.Errorf("Unexpected path `%s`", r.URL.Path)
+		}
+		switch r.URL.Query().Get("offset") {
+		case "":
+			fmt.Fprint(w, `{"stat":"OK","response":[{"user_id":"U1","username":"one@example.com","phones":[{"capabilities":["push","sms"],"activated":true}]}],"metadata":{"next_offset":1,"total_objects":2}}`)
+		case "1":
+			fmt.Fprint(w, `{"stat":"OK","response":[{"user_id":"U2","email":"two@example.com","webauthncredentials":[{"label":"Security Key"}]}],"metadata":{"prev_offset":0,"total_objects":2}}`)
+		default:
+			t.Errorf("Unexpected offset %s", r.URL.Query().Get("offset"))
+		}
+	})
+
+	users, err := client.Users().ListAllUsers()
+	if err != nil {
+		t.Fatalf("ListAllUsers() error = %v", err)
+	}
+	if len(*users) != 2 {
+		t.Fatalf("ListAllUsers() returned %d users, want 2", len(*users))
+	}
+	if u := (*users)[0]; len(u.Phones) != 1 || u.Phones[0].Capabilities[1] != "sms" {
+		t.Errorf("users[0] = %+v", u)
+	}
+	if u := (*users)[1]; len(u.WebAuthnCredentials) != 1 || u.WebAuthnCredentials[0].Label != "Security Key" {
+		t.Errorf("users[1] = %+v", u)
+	}
+}
+
+func TestGetUserByUsernameNotFound(t *testing.T) {
+	client := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("username"); got != "first last" {
+			t.Errorf("username = %q", got)
+		}
+		if !strings.Contains(r.URL.RawQuery, "first%20last") {
+			t.Errorf("query %s does not escape spaces as %%20", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"stat":"OK","response":[]}`)
+	})
+
+	_, err := client.Users().GetUserByUsername("first last")
+	if !errors.Is(err, rerrors.ErrNotFound) {
+		t.Errorf("GetUserByUsername() error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,50 @@
+/*
+# Golden Payloads - Test
+
+This package unmarshals payloads captured from each provider into rego's structs, failing when a field of the payload
+would be silently dropped, so schema drift is caught before users hit nil data. Payloads live in `testdata/<provider>`;
+when a provider adds a field, capture a fresh payload and either model the field or list it as ignored below
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/internal/tests/golden/golden_test.go
+package golden_test
+
+import (
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/backupify"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/testutil"
+)
+
+func TestOkta(t *testing.T) {
+	users := okta.Users{}
+	testutil.Golden(t, "testdata/okta/users.json", &users)
+	if len(users) != 2 || users[0].Profile.ManagerId != "00ub0oNGTSWTBKOLGLNQ" {
+		t.Errorf("Unexpected users: %+v", users)
+	}
+
+	groups := okta.Groups{}
+	testutil.Golden(t, "testdata/okta/groups.json", &groups)
+}
+
+func TestGoogle(t *testing.T) {
+	users := google.Users{}
+	testutil.Golden(t, "testdata/google/users.json", &users)
+	if len(users.Users) != 2 || !users.Users[1].Suspended {
+		t.Errorf("Unexpected users: %+v", users)
+	}
+}
+
+func TestBackupify(t *testing.T) {
+	users := backupify.Users{}
+	testutil.Golden(t, "testdata/backupify/users.json", &users)
+	if users.RecordsTotal != 2 || users.Map()["jane.doe@example.com"].UsedBytes != "524288" {
+		t.Errorf("Unexpected users: %+v", users)
+	}
+}
@@ -0,0 +1,145 @@
+// pkg/internal/tests/google/auth_test.go
+package google_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/testutil"
+)
+
+// authEnv clears the credentials of every auth type, so each case sets only its own
+func authEnv(t *testing.T) {
+	t.Helper()
+	if os.Getenv("REGO_ENCRYPTION_KEY") == "" {
+		t.Setenv("REGO_ENCRYPTION_KEY", testutil.EncryptionKey)
+	}
+	for _, env := range []string{"GOOGLE_API_KEY", "GOOGLE_OAUTH_CLIENT", "GOOGLE_OAUTH_TOKEN", "GOOGLE_SERVICE_ACCOUNT"} {
+		t.Setenv(env, "")
+	}
+}
+
+// serviceAccount returns the key of a service account whose tokens are issued by `tokenURL`
+func serviceAccount(t *testing.T, tokenURL string) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "rego",
+		"private_key_id": "rego",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"client_email":   "rego@rego.iam.gserviceaccount.com",
+		"token_uri":      tokenURL,
+	})
+	return account
+}
+
+func b64(data string) string {
+	return base64.StdEncoding.EncodeToString([]byte(data))
+}
+
+func TestNewClientAuthErrors(t *testing.T) {
+	rejected := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant","error_description":"Invalid JWT Signature."}`))
+	}))
+	defer rejected.Close()
+
+	scopes := []string{"https://www.googleapis.com/auth/admin.directory.user"}
+	oauthClient := `{"installed":{"client_id":"rego.apps.googleusercontent.com","client_secret":"secret","auth_uri":"https://accounts.google.com/o/oauth2/auth","token_uri":"https://oauth2.googleapis.com/token","redirect_uris":["http://localhost"]}}`
+
+	tests := []struct {
+		name string
+		ac   google.AuthCredentials
+		env  map[string]string
+		want error
+	}{
+		{"Unsupported Type", google.AuthCredentials{Type: "password", CICD: true}, nil, google.ErrUnsupportedAuthType},
+		{"Missing Type", google.AuthCredentials{CICD: true}, nil, google.ErrUnsupportedAuthType},
+		{"API Key Not Set", google.AuthCredentials{Type: google.API_KEY, CICD: true}, nil, google.ErrMissingCredential},
+		{"Local API Key Not Set", google.AuthCredentials{Type: google.API_KEY}, nil, google.ErrMissingCredential},
+		{"OAuth Client Not Set", google.AuthCredentials{Type: google.OAUTH_CLIENT, CICD: true}, nil, google.ErrMissingCredential},
+		{"OAuth Client Not Base64", google.AuthCredentials{Type: google.OAUTH_CLIENT, CICD: true}, map[string]string{"GOOGLE_OAUTH_CLIENT": "{not base64"}, google.ErrInvalidOAuthClientJSON},
+		{"OAuth Client Not JSON", google.AuthCredentials{Type: google.OAUTH_CLIENT, CICD: true}, map[string]string{"GOOGLE_OAUTH_CLIENT": b64("not json")}, google.ErrInvalidOAuthClientJSON},
+		{"OAuth Token Not Set", google.AuthCredentials{Type: google.OAUTH_CLIENT, CICD: true}, map[string]string{"GOOGLE_OAUTH_CLIENT": b64(oauthClient)}, google.ErrMissingCredential},
+		{"OAuth Token Empty", google.AuthCredentials{Type: google.OAUTH_CLIENT, CICD: true}, map[string]string{"GOOGLE_OAUTH_CLIENT": b64(oauthClient), "GOOGLE_OAUTH_TOKEN": b64(`{}`)}, google.ErrInvalidOAuthToken},
+		{"Service Account Not Set", google.AuthCredentials{Type: google.SERVICE_ACCOUNT, CICD: true}, nil, google.ErrMissingCredential},
+		{"Service Account Not JSON", google.AuthCredentials{Type: google.SERVICE_ACCOUNT, CICD: true}, map[string]string{"GOOGLE_SERVICE_ACCOUNT": b64(`{"type":`)}, google.ErrInvalidServiceAccountJSON},
+		{"Service Account File Missing", google.AuthCredentials{Type: google.SERVICE_ACCOUNT, Credentials: filepath.Join(t.TempDir(), "missing.json")}, nil, google.ErrMissingCredential},
+		{"Service Account Rejected", google.AuthCredentials{Type: google.SERVICE_ACCOUNT, CICD: true}, map[string]string{"GOOGLE_SERVICE_ACCOUNT": b64(string(serviceAccount(t, rejected.URL)))}, google.ErrTokenGeneration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			tt.ac.Scopes = scopes
+
+			c, err := google.NewClient(tt.ac, log.ERROR)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("NewClient() error = %v, want %v", err, tt.want)
+			}
+			if c != nil {
+				t.Errorf("NewClient() = %v, want no client with an error", c)
+			}
+		})
+	}
+}
+
+func TestNewClientAuthTypes(t *testing.T) {
+	authEnv(t)
+	scopes := []string{"https://www.googleapis.com/auth/admin.directory.user"}
+
+	t.Run("API Key", func(t *testing.T) {
+		t.Setenv("GOOGLE_API_KEY", "ya29.key")
+		c, err := google.NewClient(google.AuthCredentials{Type: google.API_KEY, CICD: true, Scopes: scopes}, log.ERROR)
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		if c.HTTP.Headers["Authorization"] != "Bearer ya29.key" {
+			t.Errorf("Authorization = %q, want the API key", c.HTTP.Headers["Authorization"])
+		}
+	})
+
+	t.Run("OAuth Client", func(t *testing.T) {
+		dir := t.TempDir()
+		secret := filepath.Join(dir, "client_secret.json")
+		token := filepath.Join(dir, "token.json")
+		os.WriteFile(secret, []byte(`{"web":{"client_id":"rego.apps.googleusercontent.com","client_secret":"secret","auth_uri":"https://accounts.google.com/o/oauth2/auth","token_uri":"https://oauth2.googleapis.com/token","redirect_uris":["http://localhost"]}}`), 0600)
+		os.WriteFile(token, []byte(`{"access_token":"ya29.user","token_type":"Bearer","refresh_token":"1//refresh"}`), 0600)
+
+		c, err := google.NewClient(google.AuthCredentials{Type: google.OAUTH_CLIENT, Credentials: secret, Token: token, Scopes: scopes}, log.ERROR)
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		if c.OAuth == nil || (*c.OAuth).ClientID != "rego.apps.googleusercontent.com" {
+			t.Errorf("Expected the OAuth config of the client secret, got %v", c.OAuth)
+		}
+	})
+
+	t.Run("Service Account", func(t *testing.T) {
+		g := testutil.NewGoogle(t)
+		c := g.NewClient(t, log.ERROR)
+		if c.JWT == nil || c.JWT.Email != "rego@testutil.iam.gserviceaccount.com" {
+			t.Errorf("Expected the JWT config of the service account, got %v", c.JWT)
+		}
+	})
+}
@@ -15,6 +15,8 @@ package google_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/gemini-oss/rego/pkg/common/log"
@@ -79,3 +81,32 @@ func TestNewClient(t *testing.T) {
 		t.Fatalf("Expected scope to be 'https://www.googleapis.com/auth/userinfo.email', got %v", c.Auth.Scopes[0])
 	}
 }
+
+// FuzzBuildURL checks that any parameter, e.g. an email with `+` or a group name, is a single path segment of the URL
+func FuzzBuildURL(f *testing.F) {
+	for _, id := range []string{"user@example.com", "first+tag@example.com", "team#1@example.com", "Sales / EMEA", "a?b=c", "..", "100%"} {
+		f.Add(id)
+	}
+	c := &google.Client{Log: log.NewLogger("{google}", log.ERROR)}
+	f.Fuzz(func(t *testing.T, id string) {
+		if id == "" || strings.HasPrefix(id, ":") {
+			t.Skip("empty parameters are skipped, and those starting with `:` are custom methods")
+		}
+		built := c.BuildURL(google.DriveFiles, nil, id, "permissions")
+
+		u, err := url.Parse(built)
+		if err != nil {
+			t.Fatalf("BuildURL(%q) = %s, which does not parse: %v", id, built, err)
+		}
+		if u.Host != "www.googleapis.com" || u.RawQuery != "" || u.Fragment != "" {
+			t.Fatalf("BuildURL(%q) = %s, changing the host, query or fragment", id, built)
+		}
+		segments := strings.Split(strings.TrimPrefix(u.EscapedPath(), "/drive/v3/files/"), "/")
+		if len(segments) != 2 || segments[1] != "permissions" {
+			t.Fatalf("BuildURL(%q) = %s, want the parameter as one segment", id, built)
+		}
+		if got, err := url.PathUnescape(segments[0]); err != nil || got != id {
+			t.Fatalf("BuildURL(%q) segment = %q (%v), want the parameter", id, got, err)
+		}
+	})
+}
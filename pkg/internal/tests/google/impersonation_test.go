@@ -0,0 +1,75 @@
+// pkg/internal/tests/google/impersonation_test.go
+package google_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/testutil"
+)
+
+func TestAsUser(t *testing.T) {
+	authEnv(t)
+	g := testutil.NewGoogle(t)
+	g.AddUser(&google.User{ID: "1", PrimaryEmail: "user1@example.com"})
+	c := g.NewClient(t, log.ERROR)
+
+	// The shared client keeps listing users as the service account while its copies impersonate each user
+	emails := []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"}
+	var wg sync.WaitGroup
+	for _, email := range emails {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Users().ListAllUsers(); err != nil {
+				t.Errorf("ListAllUsers: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			scoped, err := c.AsUser(email)
+			if err != nil {
+				t.Errorf("AsUser(%s): %v", email, err)
+				return
+			}
+			if scoped.JWT.Subject != email {
+				t.Errorf("Expected the copy to impersonate %s, got %s", email, scoped.JWT.Subject)
+			}
+			if _, err := scoped.Users().ListAllUsers(); err != nil {
+				t.Errorf("ListAllUsers as %s: %v", email, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if c.JWT.Subject != "" || c.HTTP.Headers["Authorization"] != "Bearer "+testutil.GoogleToken {
+		t.Errorf("Expected the shared client to be unchanged, got subject %q and %s", c.JWT.Subject, c.HTTP.Headers["Authorization"])
+	}
+
+	sent := map[string]int{}
+	for _, r := range g.Requests() {
+		if r.Path == "/admin/directory/v1/users" {
+			sent[r.Header.Get("Authorization")]++
+		}
+	}
+	// Each subject caches its own responses, so every copy sends its request once with its own token
+	if sent["Bearer "+testutil.GoogleToken] == 0 || len(sent) != len(emails)+1 {
+		t.Errorf("Expected requests from the service account and each user, got %v", sent)
+	}
+	for _, email := range emails {
+		if token := fmt.Sprintf("Bearer %s.%s", testutil.GoogleToken, email); sent[token] != 1 {
+			t.Errorf("Expected 1 request with %s, got %d", token, sent[token])
+		}
+	}
+}
+
+func TestAsUserUnsupported(t *testing.T) {
+	_, err := (&google.Client{}).AsUser("user@example.com")
+	if !errors.Is(err, google.ErrImpersonationUnsupported) {
+		t.Errorf("Expected ErrImpersonationUnsupported, got %v", err)
+	}
+}
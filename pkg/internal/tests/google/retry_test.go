@@ -0,0 +1,78 @@
+// pkg/internal/tests/google/retry_test.go
+package google_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/testutil"
+)
+
+// reasonBody renders a Google error with `reason`
+func reasonBody(status int, reason string) string {
+	data, _ := json.Marshal(google.ErrorResponse{Error: &google.ErrorDetail{
+		Code:    status,
+		Message: reason,
+		Errors:  []*google.ErrorItem{{Domain: "usageLimits", Reason: reason, Message: reason}},
+	}})
+	return string(data)
+}
+
+func TestRetryByReason(t *testing.T) {
+	authEnv(t)
+	for _, v := range []*time.Duration{&google.MinRateLimitBackoff, &google.MaxRateLimitBackoff, &google.MinQuotaBackoff, &google.MaxQuotaBackoff} {
+		saved := *v
+		*v = time.Millisecond
+		t.Cleanup(func() { *v = saved })
+	}
+
+	tests := []struct {
+		name     string
+		status   int
+		reason   string
+		times    int
+		requests int   // Requests sent for the user, including retries
+		wantErr  error // Class of the error, or nil when the retries succeed
+	}{
+		{"user rate limit", http.StatusForbidden, "userRateLimitExceeded", 2, 3, nil},
+		{"rate limit", http.StatusTooManyRequests, "rateLimitExceeded", 1, 2, nil},
+		{"quota", http.StatusForbidden, "quotaExceeded", 1, 2, nil},
+		{"backend", http.StatusServiceUnavailable, "backendError", 1, 2, nil},
+		{"daily limit", http.StatusForbidden, "dailyLimitExceeded", 1, 1, rerrors.ErrRateLimited},
+		{"forbidden", http.StatusForbidden, "forbidden", 1, 1, rerrors.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := testutil.NewGoogle(t)
+			g.AddUser(&google.User{ID: "1", PrimaryEmail: "user1@example.com"})
+			c := g.NewClient(t, log.ERROR)
+
+			path := "/admin/directory/v1/users/user1@example.com"
+			g.Fail(testutil.Fault{Method: "GET", Path: path, Status: tt.status, Body: reasonBody(tt.status, tt.reason), Times: tt.times})
+
+			_, err := c.Users().GetUser("user1@example.com")
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("Expected the retries to succeed, got %v", err)
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+			}
+
+			sent := 0
+			for _, r := range g.Requests() {
+				if r.Path == path {
+					sent++
+				}
+			}
+			if sent != tt.requests {
+				t.Errorf("Expected %d requests, got %d", tt.requests, sent)
+			}
+		})
+	}
+}
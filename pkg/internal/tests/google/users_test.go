@@ -0,0 +1,95 @@
+// pkg/internal/tests/google/users_test.go
+package google_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/pool"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/testutil"
+)
+
+func TestIterUsersByOU(t *testing.T) {
+	authEnv(t)
+	g := testutil.NewGoogle(t)
+	g.AddUser(
+		&google.User{ID: "1", PrimaryEmail: "root@example.com", OrgUnitPath: "/"},
+		&google.User{ID: "2", PrimaryEmail: "sales@example.com", OrgUnitPath: "/Sales"},
+		&google.User{ID: "3", PrimaryEmail: "emea@example.com", OrgUnitPath: "/Sales/EMEA"},
+		&google.User{ID: "4", PrimaryEmail: "eng@example.com", OrgUnitPath: "/Engineering"},
+		&google.User{ID: "5", PrimaryEmail: "salesops@example.com", OrgUnitPath: "/SalesOps"},
+	)
+	c := g.NewClient(t, log.ERROR)
+
+	tests := []struct {
+		name  string
+		paths []google.OrgUnitPath
+		want  []string
+	}{
+		{"top-level", []google.OrgUnitPath{"/Sales", "/Engineering"}, []string{"2", "3", "4"}},
+		{"nested", []google.OrgUnitPath{"/Sales", "/Sales/EMEA"}, []string{"2", "3"}},
+		{"root", []google.OrgUnitPath{google.ROOT_OU, "/Sales"}, []string{"1", "2", "3", "4", "5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := iterator.Collect(c.Users().IterUsersByOU(context.Background(), tt.paths, pool.Options{Workers: 2}))
+			if err != nil {
+				t.Fatalf("IterUsersByOU() error = %v", err)
+			}
+			ids := []string{}
+			for _, u := range users {
+				ids = append(ids, u.ID)
+			}
+			sort.Strings(ids)
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected users %v, got %v", tt.want, ids)
+			}
+		})
+	}
+}
+
+func TestGetUserRevalidates(t *testing.T) {
+	authEnv(t)
+	g := testutil.NewGoogle(t)
+	g.AddUser(&google.User{ID: "1", PrimaryEmail: "user1@example.com", OrgUnitPath: "/Sales"})
+	c := g.NewClient(t, log.ERROR)
+
+	for i := 0; i < 2; i++ {
+		u, err := c.Users().GetUser("user1@example.com")
+		if err != nil || u.OrgUnitPath != "/Sales" {
+			t.Fatalf("GetUser() = %v, %v", u, err)
+		}
+	}
+
+	revalidated := 0
+	for _, r := range g.Requests() {
+		if r.Path == "/admin/directory/v1/users/user1@example.com" && r.Header.Get("If-None-Match") != "" {
+			revalidated++
+		}
+	}
+	if revalidated != 1 {
+		t.Errorf("Expected the second request to be conditional, got %d conditional requests", revalidated)
+	}
+}
+
+func TestGetUserEscapesKey(t *testing.T) {
+	authEnv(t)
+	g := testutil.NewGoogle(t)
+	g.AddUser(
+		&google.User{ID: "1", PrimaryEmail: "first+tag@example.com"},
+		&google.User{ID: "2", PrimaryEmail: "first tag@example.com"},
+	)
+	c := g.NewClient(t, log.ERROR)
+
+	for key, want := range map[string]string{"first+tag@example.com": "1", "first tag@example.com": "2"} {
+		u, err := c.Users().GetUser(key)
+		if err != nil || u.ID != want {
+			t.Errorf("GetUser(%q) = %v, %v, want user %s", key, u, err, want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+/*
+# Identity - Test
+
+This package runs tests for the cross-provider identity correlation
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/internal/tests/identity/identity_test.go
+package identity_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/active_directory"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/identity"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/slack"
+)
+
+func setupCorrelator() *identity.Correlator {
+	c := identity.NewCorrelator(identity.Okta)
+
+	c.Add(identity.FromOkta(okta.Users{
+		{
+			ID:     "00u1",
+			Status: "ACTIVE",
+			Profile: &okta.UserProfile{
+				Email:          "ada@example.com",
+				Login:          "ada@example.com",
+				EmployeeNumber: "00042",
+				FirstName:      "Ada",
+				LastName:       "Lovelace",
+				Title:          "Engineer",
+			},
+		},
+		{
+			ID:     "00u2",
+			Status: "DEPROVISIONED",
+			Profile: &okta.UserProfile{
+				Email:     "grace@example.com",
+				Login:     "grace@example.com",
+				FirstName: "Grace",
+				LastName:  "Hopper",
+			},
+		},
+	})...)
+
+	c.Add(identity.FromGoogle([]*google.User{
+		{
+			ID:           "g1",
+			PrimaryEmail: "Ada@Example.com",
+			Name:         google.UserName{GivenName: "Ada", FamilyName: "Lovelace"},
+			ExternalIds:  []google.ExternalID{{Type: "organization", Value: "42"}},
+		},
+		{
+			ID:           "g2",
+			PrimaryEmail: "grace@example.com",
+			Name:         google.UserName{GivenName: "Grace", FamilyName: "Hopper"},
+		},
+	})...)
+
+	// Joined to Ada by employee ID only
+	c.Add(identity.FromActiveDirectory(active_directory.Users{
+		{
+			DistinguishedName: "CN=Ada,DC=example,DC=com",
+			Mail:              "alovelace@corp.example.com",
+			EmployeeID:        "42",
+			GivenName:         "Ada",
+			SN:                "Lovelace",
+			Title:             "Senior Engineer",
+		},
+	})...)
+
+	c.Add(identity.FromSlack([]*slack.Member{
+		{ID: "U1", Profile: slack.Profile{Email: "alovelace@corp.example.com"}},
+		{ID: "U2", Profile: slack.Profile{Email: "mallory@example.com"}},
+		{ID: "B1", IsBot: true, Profile: slack.Profile{Email: "bot@example.com"}},
+	})...)
+
+	return c
+}
+
+func TestCorrelate(t *testing.T) {
+	identities := setupCorrelator().Correlate()
+
+	if len(identities) != 3 {
+		t.Fatalf("Expected 3 identities, got %d", len(identities))
+	}
+
+	ada := identities.Find("alovelace@corp.example.com")
+	if ada == nil {
+		t.Fatal("Expected to find Ada by her AD/Slack email")
+	}
+	if ada.Email != "ada@example.com" || ada.EmployeeID != "00042" {
+		t.Errorf("Expected the IdP email and employee ID, got %s/%s", ada.Email, ada.EmployeeID)
+	}
+
+	sources := []identity.Source{identity.ActiveDirectory, identity.Google, identity.Okta, identity.Slack}
+	if !slices.Equal(ada.Sources(), sources) {
+		t.Errorf("Expected sources %v, got %v", sources, ada.Sources())
+	}
+	if ada.Orphan {
+		t.Error("Expected Ada not to be an orphan")
+	}
+}
+
+func TestCorrelateOrphans(t *testing.T) {
+	orphans := setupCorrelator().Correlate().Orphans()
+
+	if len(orphans) != 1 || orphans[0].Email != "mallory@example.com" {
+		t.Fatalf("Expected only mallory to be an orphan, got %v", orphans)
+	}
+	if missing := orphans[0].Missing(identity.Okta, identity.Slack); !slices.Equal(missing, []identity.Source{identity.Okta}) {
+		t.Errorf("Expected mallory to be missing from Okta only, got %v", missing)
+	}
+}
+
+func TestCorrelateMismatches(t *testing.T) {
+	identities := setupCorrelator().Correlate()
+
+	ada := identities.Find("ada@example.com")
+	if len(ada.Mismatches) != 1 || ada.Mismatches[0].Attribute != identity.AttrTitle {
+		t.Fatalf("Expected a single title mismatch for Ada (employee IDs are zero-padded), got %+v", ada.Mismatches)
+	}
+	if ada.Mismatches[0].Values[identity.ActiveDirectory] != "Senior Engineer" {
+		t.Errorf("Unexpected mismatch values: %v", ada.Mismatches[0].Values)
+	}
+
+	grace := identities.Find("grace@example.com")
+	if len(grace.Mismatches) != 1 || grace.Mismatches[0].Attribute != identity.AttrStatus {
+		t.Fatalf("Expected a status mismatch for Grace (deprovisioned in Okta, active in Google), got %+v", grace.Mismatches)
+	}
+
+	if len(identities.Mismatched()) != 2 {
+		t.Errorf("Expected 2 mismatched identities, got %d", len(identities.Mismatched()))
+	}
+}
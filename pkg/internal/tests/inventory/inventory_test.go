@@ -0,0 +1,115 @@
+/*
+# Inventory - Test
+
+This package runs tests for the unified device inventory
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/internal/tests/inventory/inventory_test.go
+package inventory_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/automox"
+	"github.com/gemini-oss/rego/pkg/common/normalize"
+	"github.com/gemini-oss/rego/pkg/inventory"
+	"github.com/gemini-oss/rego/pkg/jamf"
+	"github.com/gemini-oss/rego/pkg/okta"
+)
+
+const crowdstrike inventory.Source = "crowdstrike"
+
+func setupAggregator() *inventory.Aggregator {
+	a := inventory.NewAggregator()
+	a.Roles[crowdstrike] = []inventory.Role{inventory.RoleEDR}
+
+	a.Add(inventory.FromJamf([]*jamf.Computer{
+		{
+			ID:   "1",
+			UDID: "udid-mac-1",
+			General: &jamf.General{
+				Name:            "ada-mbp",
+				LastContactTime: normalize.NewTime(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)),
+			},
+			Hardware: &jamf.Hardware{
+				SerialNumber: "c02abc",
+				MacAddress:   "AA:BB:CC:00:11:22",
+				Make:         "Apple",
+				Model:        "MacBook Pro",
+			},
+			UserAndLocation: &jamf.UserAndLocation{Email: "ada@example.com"},
+		},
+	})...)
+
+	// Joined to the Jamf computer by serial number (case-insensitive)
+	a.Add(inventory.FromAutomox(automox.Devices{
+		{ID: 10, SerialNumber: "C02ABC", Name: "ADA-MBP.local", LastRefreshTime: normalize.NewTime(time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC))},
+		{ID: 11, SerialNumber: "WIN123", Name: "grace-pc", Details: &automox.DeviceDetails{Vendor: "Dell"}},
+	})...)
+
+	// Joined to the Jamf computer by UDID only
+	a.Add(inventory.FromOkta(okta.Devices{
+		{ID: "guo1", Profile: &okta.DeviceProfile{UDID: "UDID-MAC-1", Platform: "MACOS"}},
+	})...)
+
+	// Joined to the Dell by MAC address only; records with only placeholder identifiers are dropped
+	a.Add(
+		&inventory.DeviceRecord{Source: crowdstrike, ID: "cs1", SerialNumber: "System Serial Number", MACAddresses: []string{"de-ad-be-ef-00-01"}},
+		&inventory.DeviceRecord{Source: crowdstrike, ID: "cs2", SerialNumber: "System Serial Number", MACAddresses: []string{"00:00:00:00:00:00"}, Hostname: "vm"},
+		&inventory.DeviceRecord{Source: inventory.Tenable, ID: "t1", SerialNumber: "WIN123", MACAddresses: []string{"DEADBEEF0001"}},
+	)
+
+	return a
+}
+
+func TestAggregate(t *testing.T) {
+	devices := setupAggregator().Aggregate()
+
+	if len(devices) != 2 {
+		t.Fatalf("Expected 2 devices, got %d", len(devices))
+	}
+
+	mac := devices.Find("c02abc")
+	if mac == nil {
+		t.Fatal("Expected to find the Mac by serial number")
+	}
+
+	sources := []inventory.Source{inventory.Automox, inventory.Jamf, inventory.Okta}
+	if !slices.Equal(mac.Sources(), sources) {
+		t.Errorf("Expected sources %v, got %v", sources, mac.Sources())
+	}
+	if mac.Hostname != "ada-mbp" || mac.Provenance["hostname"] != inventory.Jamf {
+		t.Errorf("Expected the hostname to come from Jamf, got %q from %q", mac.Hostname, mac.Provenance["hostname"])
+	}
+	if mac.LastSeen.Day() != 2 || mac.Provenance["lastSeen"] != inventory.Automox {
+		t.Errorf("Expected the most recent check-in from Automox, got %v from %q", mac.LastSeen, mac.Provenance["lastSeen"])
+	}
+	if !slices.Equal(mac.MACAddresses, []string{"aabbcc001122"}) {
+		t.Errorf("Expected normalized MAC addresses, got %v", mac.MACAddresses)
+	}
+
+	dell := devices.Find("WIN123")
+	if dell == nil || len(dell.Records[crowdstrike]) != 1 || len(dell.Records[inventory.Tenable]) != 1 {
+		t.Fatalf("Expected the Dell to be joined with CrowdStrike by MAC address, got %+v", dell)
+	}
+}
+
+func TestAggregateReports(t *testing.T) {
+	devices := setupAggregator().Aggregate()
+
+	unmanaged := devices.Unmanaged()
+	if len(unmanaged) != 1 || unmanaged[0].SerialNumber != "WIN123" {
+		t.Errorf("Expected only the Dell to be unmanaged, got %v", unmanaged)
+	}
+
+	missing := devices.MissingEDR()
+	if len(missing) != 1 || missing[0].SerialNumber != "C02ABC" {
+		t.Errorf("Expected only the Mac to be missing EDR, got %v", missing)
+	}
+}
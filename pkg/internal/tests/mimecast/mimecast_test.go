@@ -0,0 +1,129 @@
+/*
+# Mimecast - Test
+
+This package runs tests for functions which interact with the Mimecast API 2.0:
+https://developer.services.mimecast.com/
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/internal/tests/mimecast/mimecast_test.go
+package mimecast_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/mimecast"
+)
+
+// setupTestServer returns a new test server and a cleanup function
+func setupTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, func()) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"test-token","token_type":"Bearer","expires_in":1800}`))
+	})
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("Expected bearer token, got `%s`", r.Header.Get("Authorization"))
+		}
+		if r.Method != "POST" {
+			t.Errorf("Expected method POST, got %s", r.Method)
+		}
+		handler(w, r)
+	})
+
+	server := httptest.NewServer(mux)
+	return server, func() { server.Close() }
+}
+
+// setupTestClient returns a new Mimecast client pointed at the test server
+func setupTestClient(t *testing.T, serverURL string) *mimecast.Client {
+	t.Setenv("MIMECAST_BASE_URL", serverURL)
+	t.Setenv("MIMECAST_CLIENT_ID", "client-id")
+	t.Setenv("MIMECAST_CLIENT_SECRET", "client-secret")
+	t.Setenv("REGO_ENCRYPTION_KEY", "8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt")
+
+	return mimecast.NewClient(log.DEBUG)
+}
+
+func TestListHeldMessages(t *testing.T) {
+	server, cleanup := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req := mimecast.Request[mimecast.HeldMessageQuery]{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatalf("Failed to decode payload: %v", err)
+		}
+		if len(req.Data) != 1 || !req.Data[0].Admin {
+			t.Errorf("Unexpected payload: %s", string(body))
+		}
+
+		switch req.Meta.Pagination.PageToken {
+		case "":
+			w.Write([]byte(`{"meta":{"status":200,"pagination":{"pageSize":1,"next":"page-2"}},"data":[{"id":"1","subject":"Invoice"}],"fail":[]}`))
+		case "page-2":
+			w.Write([]byte(`{"meta":{"status":200,"pagination":{"pageSize":1}},"data":[{"id":"2","subject":"Hello"}],"fail":[]}`))
+		default:
+			t.Errorf("Unexpected page token `%s`", req.Meta.Pagination.PageToken)
+		}
+	})
+	defer cleanup()
+
+	client := setupTestClient(t, server.URL)
+
+	messages, err := client.Messages().ListHeldMessages(nil)
+	if err != nil {
+		t.Fatalf("ListHeldMessages() error = %v", err)
+	}
+	if len(*messages) != 2 {
+		t.Fatalf("ListHeldMessages() returned %d messages, want 2", len(*messages))
+	}
+	if (*messages)[1].ID != "2" {
+		t.Errorf("ListHeldMessages() second message ID = %s, want 2", (*messages)[1].ID)
+	}
+}
+
+func TestReleaseHeldMessagesFailure(t *testing.T) {
+	server, cleanup := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/gateway/hold-release" {
+			t.Errorf("Unexpected path `%s`", r.URL.Path)
+		}
+		w.Write([]byte(`{"meta":{"status":200},"data":[],"fail":[{"key":{"id":"1"},"errors":[{"code":"err_validation_invalid_id","message":"invalid id","retryable":false}]}]}`))
+	})
+	defer cleanup()
+
+	client := setupTestClient(t, server.URL)
+
+	if err := client.Messages().ReleaseHeldMessages("1"); err == nil {
+		t.Error("ReleaseHeldMessages() expected an error for a failed item")
+	}
+}
+
+func TestURLLogs(t *testing.T) {
+	server, cleanup := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"meta":{"status":200},"data":[{"clickLogs":[
+			{"userEmailAddress":"a@gemini.com","url":"https://bad.example","scanResult":"malicious","action":"block"},
+			{"userEmailAddress":"b@gemini.com","url":"https://good.example","scanResult":"clean","action":"allow"}
+		]}],"fail":[]}`))
+	})
+	defer cleanup()
+
+	client := setupTestClient(t, server.URL)
+
+	logs, err := client.TTP().URLLogs(nil)
+	if err != nil {
+		t.Fatalf("URLLogs() error = %v", err)
+	}
+	if len(*logs) != 2 {
+		t.Fatalf("URLLogs() returned %d logs, want 2", len(*logs))
+	}
+	if (*logs)[0].ScanResult != "malicious" {
+		t.Errorf("URLLogs() first scan result = %s, want malicious", (*logs)[0].ScanResult)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+# Mock Generator - Test
+
+This package tests the generated mocks of the client interfaces, and that they are up to date with the interfaces
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/internal/tests/mockgen/mockgen_test.go
+package mockgen_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/google/googlemock"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/okta/oktamock"
+)
+
+// deactivate is orchestration logic as an application would write it, against the interfaces
+func deactivate(users okta.UserService, groups okta.GroupService, login, groupID string) error {
+	user, err := users.GetUser(login)
+	if err != nil {
+		return err
+	}
+	if err := groups.RemoveUserFromGroup(groupID, user.ID); err != nil {
+		return err
+	}
+	return users.DeactivateUser(user.ID)
+}
+
+func TestMocks(t *testing.T) {
+	users := &oktamock.UserService{
+		GetUserFunc: func(id string) (*okta.User, error) { return &okta.User{ID: "00u1", Status: "ACTIVE"}, nil },
+	}
+	groups := &oktamock.GroupService{
+		RemoveUserFromGroupFunc: func(groupID, userID string) error { return errors.New("group not found") },
+	}
+
+	if err := deactivate(users, groups, "user@example.com", "00g1"); err == nil {
+		t.Fatal("Expected the error of the mock")
+	}
+	if calls := groups.Calls("RemoveUserFromGroup"); len(calls) != 1 || calls[0].Args[1] != "00u1" {
+		t.Errorf("Expected the user to be removed from the group, got %v", calls)
+	}
+	if calls := users.Calls(); len(calls) != 1 || calls[0].Method != "GetUser" {
+		t.Errorf("Expected the user not to be deactivated, got %v", calls)
+	}
+
+	// Methods without a func return zero values
+	drive := &googlemock.DriveService{}
+	if file, err := drive.GetFile("1abc"); file != nil || err != nil {
+		t.Errorf("Expected zero values, got %v, %v", file, err)
+	}
+}
+
+func TestMocksUpToDate(t *testing.T) {
+	root, err := filepath.Abs("../../../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+
+	tmp := t.TempDir()
+	mockgen := filepath.Join(tmp, "mockgen")
+	build := exec.Command("go", "build", "-o", mockgen, "./cmd/mockgen")
+	build.Dir = root
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("Building mockgen: %v\n%s", err, out)
+	}
+
+	sources, _ := filepath.Glob(filepath.Join(root, "pkg", "*", "interfaces.go"))
+	if len(sources) == 0 {
+		t.Fatal("Expected packages to declare interfaces")
+	}
+	for _, source := range sources {
+		mocks, _ := filepath.Glob(filepath.Join(filepath.Dir(source), "*mock", "mocks.go"))
+		if len(mocks) != 1 {
+			t.Errorf("Expected the mocks of %s to be generated", source)
+			continue
+		}
+
+		out := filepath.Join(tmp, filepath.Base(filepath.Dir(mocks[0])), "mocks.go")
+		if output, err := exec.Command(mockgen, "-source", source, "-out", out).CombinedOutput(); err != nil {
+			t.Errorf("Generating the mocks of %s: %v\n%s", source, err, output)
+			continue
+		}
+
+		want, _ := os.ReadFile(out)
+		got, _ := os.ReadFile(mocks[0])
+		if !bytes.Equal(withoutPath(got), withoutPath(want)) {
+			t.Errorf("%s is out of date; run `make generate`", mocks[0])
+		}
+	}
+}
+
+// withoutPath drops the path comment of a generated file, which depends on where it is written
+func withoutPath(src []byte) []byte {
+	lines := bytes.SplitN(src, []byte("\n"), 4)
+	if len(lines) < 4 {
+		return src
+	}
+	return lines[3]
+}
@@ -15,6 +15,8 @@ package okta_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/gemini-oss/rego/pkg/common/log"
@@ -42,3 +44,29 @@ func setupTestClient(serverURL string) *okta.Client {
 
 	return client
 }
+
+// FuzzBuildURL checks that any identifier, e.g. a login with `+` or `#`, is a single path segment of the URL
+func FuzzBuildURL(f *testing.F) {
+	for _, id := range []string{"00u1abcd", "first+tag@example.com", "team#1@example.com", "a/b", "a?b=c", "..", "100%", ""} {
+		f.Add(id)
+	}
+	c := &okta.Client{BaseURL: "https://example.okta.com/api/v1"}
+	f.Fuzz(func(t *testing.T, id string) {
+		built := c.BuildURL(okta.OktaUsers, id, "lifecycle", "deactivate")
+
+		u, err := url.Parse(built)
+		if err != nil {
+			t.Fatalf("BuildURL(%q) = %s, which does not parse: %v", id, built, err)
+		}
+		if u.Host != "example.okta.com" || u.RawQuery != "" || u.Fragment != "" {
+			t.Fatalf("BuildURL(%q) = %s, changing the host, query or fragment", id, built)
+		}
+		segments := strings.Split(strings.TrimPrefix(u.EscapedPath(), "/api/v1/users/"), "/")
+		if len(segments) != 3 || segments[1] != "lifecycle" || segments[2] != "deactivate" {
+			t.Fatalf("BuildURL(%q) = %s, want the identifier as one segment", id, built)
+		}
+		if got, err := url.PathUnescape(segments[0]); err != nil || got != id {
+			t.Fatalf("BuildURL(%q) segment = %q (%v), want the identifier", id, got, err)
+		}
+	})
+}
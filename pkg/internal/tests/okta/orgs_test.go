@@ -0,0 +1,144 @@
+// pkg/internal/tests/okta/orgs_test.go
+package okta_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/options"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/testutil"
+	"golang.org/x/oauth2"
+)
+
+func memoryCache(t *testing.T) *cache.Cache {
+	t.Helper()
+	c, err := cache.NewCache([]byte(testutil.EncryptionKey), true, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestProfile(t *testing.T) {
+	t.Setenv("OKTA_ORG_NAME", "example")
+	t.Setenv("OKTA_BASE_URL", "okta.com")
+	t.Setenv("OKTA_API_TOKEN", "prod")
+	t.Setenv("OKTA_SANDBOX_ORG_NAME", "https://example.oktapreview.com")
+	t.Setenv("OKTA_SANDBOX_BASE_URL", ".oktapreview.com/")
+	t.Setenv("OKTA_SANDBOX_API_TOKEN", "sandbox")
+
+	tests := []struct {
+		profile string
+		token   string
+		url     string
+	}{
+		{"", "prod", "https://example.okta.com/api/v1"},
+		{okta.DefaultProfile, "prod", "https://example.okta.com/api/v1"},
+		{"sandbox", "sandbox", "https://example.oktapreview.com/api/v1"},
+	}
+
+	for _, tt := range tests {
+		org := okta.Profile(tt.profile)
+		if org.Token != tt.token || org.URL() != tt.url {
+			t.Errorf("Profile(%q) = %s with token %s, want %s with %s", tt.profile, org.URL(), org.Token, tt.url, tt.token)
+		}
+	}
+}
+
+func TestNewOrgClientErrors(t *testing.T) {
+	t.Setenv("REGO_ENCRYPTION_KEY", testutil.EncryptionKey)
+	t.Setenv("OKTA_PREVIEW_ORG_NAME", "example")
+	t.Setenv("OKTA_PREVIEW_BASE_URL", "")
+	t.Setenv("OKTA_PREVIEW_API_TOKEN", "")
+
+	tests := []struct {
+		name string
+		org  okta.OrgConfig
+		want string
+	}{
+		{"Profile", okta.Profile("preview"), "OKTA_PREVIEW_BASE_URL is not set"},
+		{"Name", okta.OrgConfig{BaseDomain: "okta.com", Token: "token"}, "Name is not set"},
+		{"Token", okta.OrgConfig{Name: "example", BaseDomain: "okta.com"}, "Token is not set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := okta.NewOrgClient(tt.org, log.ERROR)
+			if !errors.Is(err, okta.ErrIncompleteOrg) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewOrgClient() error = %v, want %s", err, tt.want)
+			}
+			if c != nil {
+				t.Errorf("NewOrgClient() = %v, want no client with an error", c)
+			}
+		})
+	}
+}
+
+func TestNewOrgClients(t *testing.T) {
+	t.Setenv("REGO_ENCRYPTION_KEY", testutil.EncryptionKey)
+	prod, sandbox := testutil.NewOkta(t), testutil.NewOkta(t)
+	prod.AddUser(&okta.User{ID: "00uprod", Status: "ACTIVE", Profile: &okta.UserProfile{Email: "prod@example.com"}})
+	sandbox.AddUser(&okta.User{ID: "00usandbox", Status: "ACTIVE", Profile: &okta.UserProfile{Email: "sandbox@example.com"}})
+
+	// An API token, and an OAuth 2.0 service app
+	prodClient, err := okta.NewOrgClient(okta.OrgConfig{Name: "example", BaseDomain: "okta.com", Token: "prod"}, log.ERROR,
+		options.WithBaseURL(prod.URL+"/api/v1"), options.WithCache(memoryCache(t)))
+	if err != nil {
+		t.Fatalf("NewOrgClient() error = %v", err)
+	}
+	sandboxClient, err := okta.NewOrgClient(okta.OrgConfig{
+		Name:        "example",
+		BaseDomain:  "oktapreview.com",
+		TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "sandbox", TokenType: "Bearer"}),
+	}, log.ERROR, options.WithBaseURL(sandbox.URL+"/api/v1"), options.WithCache(memoryCache(t)))
+	if err != nil {
+		t.Fatalf("NewOrgClient() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		client *okta.Client
+		fake   *testutil.Okta
+		id     string
+		auth   string
+	}{
+		{"Production", prodClient, prod, "00uprod", "SSWS prod"},
+		{"Sandbox", sandboxClient, sandbox, "00usandbox", "Bearer sandbox"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := tt.client.ListAllUsers()
+			if err != nil || len(*users) != 1 || (*users)[0].ID != tt.id {
+				t.Fatalf("Expected only the user of the org, got %v (%v)", users, err)
+			}
+			if auth := tt.fake.Requests()[0].Header.Get("Authorization"); auth != tt.auth {
+				t.Errorf("Authorization = %q, want %q", auth, tt.auth)
+			}
+		})
+	}
+}
+
+func TestBucket(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://example.okta.com/api/v1/users?limit=200", "/api/v1/users"},
+		{"https://example.okta.com/api/v1/users/00u1abcd", "/api/v1/users/{id}"},
+		{"https://example.okta.com/api/v1/users/00u1abcd/groups", "/api/v1/users/{id}/groups"},
+		{"https://example.okta.com/api/v1/groups/00g1abcd/users/00u1abcd", "/api/v1/groups/{id}/users/{id}"},
+		{"https://example.okta.com/api/v1/logs?since=2024-01-01T00:00:00Z", "/api/v1/logs"},
+	}
+	for _, tt := range tests {
+		r, _ := http.NewRequest("GET", tt.url, nil)
+		if got := okta.Bucket(r); got != tt.want {
+			t.Errorf("Bucket(%s) = %s, want %s", tt.url, got, tt.want)
+		}
+	}
+}
@@ -13,7 +13,16 @@ https://developer.okta.com/docs/api/openapi/okta-management/management/tag/User/
 package okta_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/pool"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/testutil"
 )
 
 // Test ListAllUsers
@@ -116,3 +125,61 @@ func TestGetUser(t *testing.T) {
 		t.Errorf("Expected user ID `1`, got `%s`", user.ID)
 	}
 }
+
+// Test IterAllUsersParallel
+func TestIterAllUsersParallel(t *testing.T) {
+	t.Setenv("REGO_ENCRYPTION_KEY", testutil.EncryptionKey)
+	fake := testutil.NewOkta(t)
+	logins := []string{"alice", "bob", "dave", "hank", "larry", "mallory", "peggy", "trent", "victor", "zed"}
+	for i, login := range logins {
+		status := okta.ACTIVE
+		if i%3 == 0 {
+			status = okta.SUSPENDED
+		}
+		fake.AddUser(&okta.User{ID: fmt.Sprint(i), Status: status, Profile: &okta.UserProfile{Login: login + "@example.com"}})
+	}
+	client := fake.NewClient(t, log.ERROR)
+
+	users, err := iterator.Collect(client.IterAllUsersParallel(context.Background(), pool.Options{Workers: 4}))
+	if err != nil {
+		t.Fatalf("Expected no error, got `%v`", err)
+	}
+	seen := map[string]int{}
+	for _, u := range users {
+		seen[u.ID]++
+	}
+
+	if len(seen) != len(logins) {
+		t.Errorf("Expected `%d` users, got `%d`", len(logins), len(seen))
+	}
+	for id, n := range seen {
+		if n != 1 {
+			t.Errorf("Expected user `%s` once, got `%d` times", id, n)
+		}
+	}
+	// One request per status, and per range of ACTIVE logins
+	if n := len(fake.Requests()); n != 7+len(okta.LoginBounds)+1 {
+		t.Errorf("Expected `%d` requests, got `%d`", 7+len(okta.LoginBounds)+1, n)
+	}
+}
+
+// Test that a cancelled context stops a whole operation, including its concurrent lookups
+func TestListAllUsersCancelled(t *testing.T) {
+	t.Setenv("REGO_ENCRYPTION_KEY", testutil.EncryptionKey)
+	fake := testutil.NewOkta(t)
+	fake.AddUser(&okta.User{ID: "1", Status: okta.ACTIVE, Profile: &okta.UserProfile{Login: "alice@example.com"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := fake.NewClient(t, log.ERROR).WithContext(ctx)
+
+	if _, err := client.ListAllUsers(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected `context.Canceled`, got `%v`", err)
+	}
+	if _, err := client.GenerateRoleReport(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected `context.Canceled`, got `%v`", err)
+	}
+	if n := len(fake.Requests()); n != 0 {
+		t.Errorf("Expected no requests, got `%d`", n)
+	}
+}
@@ -0,0 +1,223 @@
+/*
+# Orchestrators - Offboarding Test
+
+This package runs tests for the offboarding workflow and its step runner
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/internal/tests/orchestrators/offboarding_test.go
+package orchestrators_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/notify"
+	"github.com/gemini-oss/rego/pkg/common/policy"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/docusign"
+	"github.com/gemini-oss/rego/pkg/orchestrators"
+	"github.com/gemini-oss/rego/pkg/slack"
+)
+
+// setupTestClient returns an orchestrator without any service clients, so only custom steps are run
+func setupTestClient() *orchestrators.Client {
+	return &orchestrators.Client{
+		Log: log.NewLogger("{orchestrators}", log.INFO),
+	}
+}
+
+// flakyStep returns a step which fails `failures` times before succeeding
+func flakyStep(name string, failures int) (orchestrators.Step, *int) {
+	calls := 0
+	return orchestrators.Step{
+		Name: name,
+		Run: func() (string, error) {
+			calls++
+			if calls <= failures {
+				return "", fmt.Errorf("attempt %d failed", calls)
+			}
+			return "done", nil
+		},
+	}, &calls
+}
+
+func TestRunWorkflowRetries(t *testing.T) {
+	c := setupTestClient()
+
+	step, calls := flakyStep("flaky", 2)
+	report := c.RunWorkflow("test", "user@example.com", []orchestrators.Step{step}, orchestrators.WorkflowOptions{
+		Retries:    2,
+		RetryDelay: time.Millisecond,
+	})
+
+	if !report.Succeeded() {
+		t.Fatalf("Expected workflow to succeed, got %s", report)
+	}
+	if *calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", *calls)
+	}
+
+	result := report.Result("flaky")
+	if result.Attempts != 3 || result.Status != orchestrators.StepSucceeded || result.Detail != "done" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestRunWorkflowContinuesOnError(t *testing.T) {
+	c := setupTestClient()
+
+	failing, _ := flakyStep("failing", 10)
+	passing, calls := flakyStep("passing", 0)
+	report := c.RunWorkflow("test", "user@example.com", []orchestrators.Step{failing, passing}, orchestrators.WorkflowOptions{
+		Retries:    1,
+		RetryDelay: time.Millisecond,
+	})
+
+	if report.Succeeded() {
+		t.Fatal("Expected workflow to fail")
+	}
+	if *calls != 1 {
+		t.Errorf("Expected the passing step to run once, got %d", *calls)
+	}
+
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Step != "failing" || failed[0].Attempts != 2 {
+		t.Errorf("Unexpected failures: %+v", failed)
+	}
+	if failed[0].Error != "attempt 2 failed" {
+		t.Errorf("Expected the error of the final attempt, got %q", failed[0].Error)
+	}
+}
+
+func TestRunWorkflowNotifies(t *testing.T) {
+	c := setupTestClient()
+	messages := []*notify.Message{}
+	c.Notifier = notify.NotifierFunc(func(_ context.Context, m *notify.Message) error {
+		messages = append(messages, m)
+		return nil
+	})
+
+	failing, _ := flakyStep("okta.deactivate", 10)
+	passing, _ := flakyStep("google.suspend", 0)
+	c.RunWorkflow("offboarding", "user@example.com", []orchestrators.Step{failing, passing}, orchestrators.WorkflowOptions{})
+	c.RunWorkflow("offboarding", "user@example.com", []orchestrators.Step{passing}, orchestrators.WorkflowOptions{DryRun: true})
+
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 notification (none for dry runs), got %d", len(messages))
+	}
+	m := messages[0]
+	if m.Title != "offboarding failed for user@example.com" || m.Severity != notify.Failure {
+		t.Errorf("Unexpected notification: %+v", m)
+	}
+	if m.Body != "okta.deactivate: attempt 1 failed" || m.Fields[0].Value != "2 (1 failed)" {
+		t.Errorf("Unexpected summary: %q %+v", m.Body, m.Fields)
+	}
+}
+
+func TestRunWorkflowDeniedByPolicy(t *testing.T) {
+	c := setupTestClient()
+	c.Policy = policy.NewEngine(log.INFO)
+	c.Policy.Add("legal-hold", policy.LegalHold(func(_ context.Context, subject string) (bool, error) {
+		return subject == "held@example.com", nil
+	}))
+
+	step, calls := flakyStep("okta.deactivate", 0)
+	report := c.RunWorkflow("offboarding", "held@example.com", []orchestrators.Step{step}, orchestrators.WorkflowOptions{})
+
+	if *calls != 0 {
+		t.Errorf("Expected no step to run, got %d call(s)", *calls)
+	}
+	if report.Succeeded() || report.Result("policy") == nil || !strings.Contains(report.Result("policy").Error, "legal hold") {
+		t.Errorf("Expected the workflow to be denied, got %s", report)
+	}
+	if result := report.Result("okta.deactivate"); result.Status != orchestrators.StepSkipped || result.Detail != "blocked by policy" {
+		t.Errorf("Expected the step to be blocked, got %+v", result)
+	}
+
+	c.RunWorkflow("offboarding", "free@example.com", []orchestrators.Step{step}, orchestrators.WorkflowOptions{})
+	if *calls != 1 {
+		t.Errorf("Expected the step to run for a subject not on hold, got %d call(s)", *calls)
+	}
+}
+
+func TestRunWorkflowStopOnError(t *testing.T) {
+	c := setupTestClient()
+
+	failing, _ := flakyStep("failing", 10)
+	passing, calls := flakyStep("passing", 0)
+	report := c.RunWorkflow("test", "user@example.com", []orchestrators.Step{failing, passing}, orchestrators.WorkflowOptions{
+		StopOnError: true,
+	})
+
+	if *calls != 0 {
+		t.Errorf("Expected the second step not to run, got %d call(s)", *calls)
+	}
+	if status := report.Result("passing").Status; status != orchestrators.StepSkipped {
+		t.Errorf("Expected second step to be skipped, got %s", status)
+	}
+}
+
+func TestOffboardCustomSteps(t *testing.T) {
+	c := setupTestClient()
+
+	zoom, zoomCalls := flakyStep("zoom.deactivate", 0)
+	other, otherCalls := flakyStep("other", 0)
+	cfg := &orchestrators.OffboardingConfig{
+		Steps: []orchestrators.Step{zoom, other},
+		Skip:  []string{"other"},
+	}
+
+	report := c.Offboard("user@example.com", cfg)
+
+	if report.Workflow != "offboarding" || report.Subject != "user@example.com" {
+		t.Errorf("Unexpected report header: %s/%s", report.Workflow, report.Subject)
+	}
+	if len(report.Results) != 1 {
+		t.Fatalf("Expected only the custom step to run, got %d result(s)", len(report.Results))
+	}
+	if *zoomCalls != 1 || *otherCalls != 0 {
+		t.Errorf("Expected zoom to run once and other to be skipped, got %d/%d", *zoomCalls, *otherCalls)
+	}
+	if !strings.Contains(report.String(), "zoom.deactivate") {
+		t.Errorf("Expected report to include the zoom step:\n%s", report)
+	}
+}
+
+func TestOffboardingStepsDocuSign(t *testing.T) {
+	c := setupTestClient()
+	// Steps are only built, never run
+	c.DocuSign = &docusign.Client{}
+
+	steps := c.OffboardingSteps("user@example.com", &orchestrators.OffboardingConfig{TransferTo: "manager@example.com"})
+	if len(steps) != 1 || steps[0].Name != "docusign.close" || !strings.Contains(steps[0].Description, "manager@example.com") {
+		t.Errorf("Expected a DocuSign step transferring to the manager, got %+v", steps)
+	}
+
+	steps = c.OffboardingSteps("user@example.com", &orchestrators.OffboardingConfig{Skip: []string{"docusign.close"}})
+	if len(steps) != 0 {
+		t.Errorf("Expected the DocuSign step to be skipped, got %+v", steps)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	c := setupTestClient()
+	c.Slack = &slack.Client{HTTP: requests.NewClient(nil, nil, nil)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bound := c.WithContext(ctx)
+	if bound.Slack.HTTP.Context() != ctx || bound.Okta != nil {
+		t.Errorf("Expected the configured clients to be bound to the context, got %+v", bound)
+	}
+	if c.Slack.HTTP.Context() == ctx {
+		t.Error("Expected the orchestrator to be left unchanged")
+	}
+}
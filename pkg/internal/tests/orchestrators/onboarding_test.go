@@ -0,0 +1,161 @@
+/*
+# Orchestrators - Onboarding Test
+
+This package runs tests for the onboarding workflow, its templates, dry runs and resumption
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/internal/tests/orchestrators/onboarding_test.go
+package orchestrators_test
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/orchestrators"
+)
+
+func testHire() *orchestrators.NewHire {
+	return &orchestrators.NewHire{
+		FirstName:  "Ada",
+		LastName:   "Lovelace",
+		Email:      "ada@example.com",
+		Title:      "Engineering Manager",
+		Department: "Engineering",
+	}
+}
+
+func testTemplates() map[string]*orchestrators.OnboardingTemplate {
+	return map[string]*orchestrators.OnboardingTemplate{
+		"engineering": {
+			OktaGroups:   []string{"Everyone", "Engineering"},
+			GoogleOU:     "/Engineering",
+			GoogleGroups: []string{"eng@example.com"},
+		},
+		"Engineering Manager": {
+			OktaGroups:      []string{"Engineering", "Managers"},
+			GoogleGroups:    []string{"managers@example.com"},
+			SlackUsergroups: []string{"eng-managers"},
+		},
+		"Contractors": {
+			GoogleOU: "/Contractors",
+		},
+	}
+}
+
+func TestOnboardingTemplateMerge(t *testing.T) {
+	cfg := &orchestrators.OnboardingConfig{Templates: testTemplates()}
+
+	template, err := cfg.Template(testHire())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(template.OktaGroups, []string{"Everyone", "Engineering", "Managers"}) {
+		t.Errorf("Unexpected Okta groups: %v", template.OktaGroups)
+	}
+	if template.GoogleOU != "/Engineering" {
+		t.Errorf("Expected OU `/Engineering`, got %s", template.GoogleOU)
+	}
+	if len(template.GoogleGroups) != 2 || len(template.SlackUsergroups) != 1 {
+		t.Errorf("Unexpected groups: %v, %v", template.GoogleGroups, template.SlackUsergroups)
+	}
+
+	hire := testHire()
+	hire.Templates = []string{"contractors"}
+	template, err = cfg.Template(hire)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if template.GoogleOU != "/Contractors" {
+		t.Errorf("Expected explicit template to override the OU, got %s", template.GoogleOU)
+	}
+
+	hire.Templates = []string{"Missing"}
+	if _, err := cfg.Template(hire); err == nil {
+		t.Error("Expected an error for a missing template")
+	}
+}
+
+func TestOnboardDryRun(t *testing.T) {
+	c := setupTestClient()
+	// Clients are never called during a dry run
+	c.Okta = &okta.Client{}
+	c.Google = &google.Client{}
+
+	cfg := &orchestrators.OnboardingConfig{
+		Templates:       testTemplates(),
+		WorkflowOptions: orchestrators.WorkflowOptions{DryRun: true},
+	}
+
+	report, err := c.Onboard(testHire(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []string{}
+	for _, result := range report.Results {
+		if result.Status != orchestrators.StepPlanned || result.Attempts != 0 || result.Detail == "" {
+			t.Errorf("Unexpected dry run result: %+v", result)
+		}
+		steps = append(steps, result.Step)
+	}
+
+	expected := []string{"okta.create", "okta.groups", "google.create", "google.groups"}
+	if !slices.Equal(steps, expected) {
+		t.Errorf("Expected steps %v, got %v", expected, steps)
+	}
+}
+
+func TestOnboardResume(t *testing.T) {
+	c := setupTestClient()
+
+	first, firstCalls := flakyStep("first", 0)
+	second, secondCalls := flakyStep("second", 1)
+	cfg := &orchestrators.OnboardingConfig{
+		Templates: testTemplates(),
+		Steps:     []orchestrators.Step{first, second},
+	}
+
+	report, err := c.Onboard(testHire(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Succeeded() {
+		t.Fatal("Expected the first run to fail")
+	}
+
+	// Persist and reload the report, as a caller resuming in a later process would
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := report.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	previous, err := orchestrators.LoadReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Resume = previous
+	cfg.RetryDelay = time.Millisecond
+	report, err = c.Onboard(testHire(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !report.Succeeded() {
+		t.Fatalf("Expected the resumed run to succeed:\n%s", report)
+	}
+	if *firstCalls != 1 {
+		t.Errorf("Expected the completed step not to run again, got %d call(s)", *firstCalls)
+	}
+	if *secondCalls != 2 {
+		t.Errorf("Expected the failed step to run again, got %d call(s)", *secondCalls)
+	}
+}
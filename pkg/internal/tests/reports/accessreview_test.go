@@ -0,0 +1,197 @@
+// pkg/internal/tests/reports/accessreview_test.go
+package reports_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/exporters"
+	"github.com/gemini-oss/rego/pkg/common/normalize"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/reports"
+	"github.com/gemini-oss/rego/pkg/slack"
+)
+
+var now = time.Date(2024, time.August, 15, 12, 0, 0, 0, time.UTC)
+
+func newReview() *reports.AccessReview {
+	return reports.NewAccessReview(reports.AccessReviewConfig{
+		PrivilegedGroups: []string{"*-admins", "Security"},
+		Collector:        "rego-reviewer@example.com",
+		Now:              func() time.Time { return now },
+	})
+}
+
+func TestNewAccessReviewDefaults(t *testing.T) {
+	r := newReview()
+	if r.Period != "2024-Q3" {
+		t.Errorf("Period = %s, want 2024-Q3", r.Period)
+	}
+	if r.DormantDays != reports.DefaultDormantDays {
+		t.Errorf("DormantDays = %d, want %d", r.DormantDays, reports.DefaultDormantDays)
+	}
+	if !r.GeneratedAt.Equal(now) {
+		t.Errorf("GeneratedAt = %v, want %v", r.GeneratedAt, now)
+	}
+}
+
+func TestPrivileged(t *testing.T) {
+	r := newReview()
+	for group, want := range map[string]bool{
+		"aws-admins":  true,
+		"AWS-Admins":  true,
+		"security":    true,
+		"Engineering": false,
+		"admins":      false,
+	} {
+		if got := r.Privileged(group); got != want {
+			t.Errorf("Privileged(%q) = %v, want %v", group, got, want)
+		}
+	}
+}
+
+func TestDormantAccounts(t *testing.T) {
+	r := newReview()
+	users := okta.Users{
+		{ID: "recent", Status: "ACTIVE", LastLogin: now.AddDate(0, 0, -10), Profile: &okta.UserProfile{Email: "recent@example.com"}},
+		{ID: "stale", Status: "ACTIVE", LastLogin: now.AddDate(0, 0, -120), Profile: &okta.UserProfile{Email: "stale@example.com"}},
+		{ID: "never", Status: "ACTIVE", Created: now.AddDate(-1, 0, 0), Profile: &okta.UserProfile{Email: "never@example.com"}},
+		{ID: "new", Status: "ACTIVE", Created: now.AddDate(0, 0, -5), Profile: &okta.UserProfile{Email: "new@example.com"}},
+		{ID: "gone", Status: "DEPROVISIONED", LastLogin: now.AddDate(-2, 0, 0), Profile: &okta.UserProfile{Email: "gone@example.com"}},
+	}
+	r.AddAccounts(reports.FromOktaUsers(users)...)
+
+	r.AddAccounts(reports.FromGoogleUsers([]*google.User{
+		{ID: "g1", PrimaryEmail: "stale@example.com", LastLoginTime: normalize.NewTime(now.AddDate(0, -6, 0))},
+		{ID: "g2", PrimaryEmail: "never@example.com", CreationTime: normalize.NewTime(now.AddDate(0, 0, -200))},
+		{ID: "g3", PrimaryEmail: "suspended@example.com", Suspended: true},
+	})...)
+
+	dormant := map[string]*reports.DormantAccount{}
+	for _, d := range r.Dormant {
+		dormant[string(d.Source)+":"+d.UserID] = d
+	}
+	if len(dormant) != 4 {
+		t.Fatalf("got %d dormant accounts, want 4: %v", len(dormant), dormant)
+	}
+
+	if d := dormant["okta:stale"]; d == nil || d.DaysInactive != 120 || d.NeverLoggedIn {
+		t.Errorf("okta:stale = %+v, want 120 days inactive", d)
+	}
+	if d := dormant["okta:never"]; d == nil || !d.NeverLoggedIn || d.DaysInactive < 365 {
+		t.Errorf("okta:never = %+v, want never logged in since creation", d)
+	}
+	if d := dormant["google:g2"]; d == nil || !d.NeverLoggedIn || d.DaysInactive != 200 {
+		t.Errorf("google:g2 = %+v, want never logged in for 200 days", d)
+	}
+	if dormant["google:g1"] == nil {
+		t.Error("google:g1 should be dormant")
+	}
+	for _, id := range []string{"okta:recent", "okta:new", "okta:gone", "google:g3"} {
+		if dormant[id] != nil {
+			t.Errorf("%s should not be dormant", id)
+		}
+	}
+}
+
+func TestAdminsAppsAndGroups(t *testing.T) {
+	r := newReview()
+
+	roles := okta.RoleReports{
+		{
+			Role:  &okta.Role{Label: "Super Administrator", AssignmentType: "USER"},
+			Users: &okta.Users{{ID: "00u1", Status: "ACTIVE", Profile: &okta.UserProfile{Email: "alice@example.com"}}},
+		},
+	}
+	r.AddAdmins(reports.FromOktaRoles(roles, now)...)
+	r.AddAdmins(reports.FromGoogleAdmins([]*google.User{
+		{ID: "g1", PrimaryEmail: "alice@example.com", IsAdmin: true},
+		{ID: "g2", PrimaryEmail: "bob@example.com", IsDelegatedAdmin: true},
+		{ID: "g3", PrimaryEmail: "carol@example.com"},
+	}, now)...)
+	r.AddAdmins(reports.FromSlackAdmins([]slack.Member{
+		{ID: "U1", IsAdmin: true, IsOwner: true, Profile: slack.Profile{Email: "alice@example.com"}},
+		{ID: "U2", Profile: slack.Profile{Email: "carol@example.com"}},
+	}, now)...)
+
+	if len(r.Admins) != 4 {
+		t.Fatalf("got %d admins, want 4", len(r.Admins))
+	}
+	roleOf := map[string]string{}
+	for _, a := range r.Admins {
+		roleOf[string(a.Source)+":"+a.Email] = a.Role
+	}
+	for key, want := range map[string]string{
+		"okta:alice@example.com":   "Super Administrator",
+		"google:alice@example.com": "Super Administrator",
+		"google:bob@example.com":   "Delegated Administrator",
+		"slack:alice@example.com":  "Workspace Owner",
+	} {
+		if roleOf[key] != want {
+			t.Errorf("role of %s = %q, want %q", key, roleOf[key], want)
+		}
+	}
+
+	app := &okta.Application{ID: "0oa1", Label: "AWS"}
+	r.AddApps(reports.FromOktaAppUsers(app, okta.Users{{ID: "00u1", Scope: "GROUP", Status: "PROVISIONED"}}, map[string]string{"00u1": "alice@example.com"}, now)...)
+	if len(r.Apps) != 1 || r.Apps[0].Email != "alice@example.com" || r.Apps[0].App != "AWS" || r.Apps[0].Scope != "GROUP" {
+		t.Errorf("Apps = %+v, want alice's AWS assignment", r.Apps)
+	}
+
+	members := okta.Users{{ID: "00u1", Profile: &okta.UserProfile{Email: "alice@example.com"}}}
+	r.AddGroupMembers(reports.FromOktaGroupMembers(&okta.Group{ID: "00g1", Profile: okta.GroupProfile{Name: "aws-admins"}}, members, now)...)
+	r.AddGroupMembers(reports.FromOktaGroupMembers(&okta.Group{ID: "00g2", Profile: okta.GroupProfile{Name: "Everyone"}}, members, now)...)
+	if len(r.Groups) != 1 || r.Groups[0].Group != "aws-admins" {
+		t.Errorf("Groups = %+v, want only the aws-admins membership", r.Groups)
+	}
+}
+
+func TestAccessReviewExport(t *testing.T) {
+	r := newReview()
+	r.AddAdmins(&reports.AdminRole{Source: reports.Okta, UserID: "00u1", Email: "alice@example.com", Role: "Super Administrator"})
+	r.AddEvidence(reports.Okta, reports.DatasetAdmins, 1, now, nil)
+	r.AddEvidence(reports.Google, reports.DatasetAdmins, 0, now, os.ErrPermission)
+
+	if r.Complete() {
+		t.Error("Complete() = true, want false after a failed collection")
+	}
+
+	sheets := r.Sheets()
+	names := []string{}
+	for _, s := range sheets {
+		names = append(names, s.Name)
+	}
+	if strings.Join(names, ",") != "Evidence,Admin Roles,App Assignments,Privileged Groups,Dormant Accounts" {
+		t.Errorf("sheets = %v", names)
+	}
+
+	var buf bytes.Buffer
+	if err := exporters.WriteCSV(&buf, r.Evidence); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	evidence := buf.String()
+	if !strings.Contains(evidence, "collected_at") || !strings.Contains(evidence, "rego-reviewer@example.com") || !strings.Contains(evidence, "permission denied") {
+		t.Errorf("evidence CSV = %q, want timestamps, collector and errors", evidence)
+	}
+
+	buf.Reset()
+	if err := exporters.WriteCSV(&buf, r.Admins); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	if !strings.Contains(buf.String(), now.Format(time.RFC3339)) {
+		t.Errorf("admins CSV = %q, want the evidence timestamp defaulted to the review clock", buf.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "review.xlsx")
+	if err := r.Export(path); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		t.Errorf("Export did not write %s: %v", path, err)
+	}
+}
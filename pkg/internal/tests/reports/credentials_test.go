@@ -0,0 +1,100 @@
+// pkg/internal/tests/reports/credentials_test.go
+package reports_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/events"
+	"github.com/gemini-oss/rego/pkg/common/normalize"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/reports"
+)
+
+// recorder is an events.Publisher which records the events it is given
+type recorder []*events.Event
+
+func (r *recorder) Publish(e ...*events.Event) error {
+	*r = append(*r, e...)
+	return nil
+}
+
+func TestFromGoogleServiceAccountKeys(t *testing.T) {
+	account := &google.IAMServiceAccount{Email: "sync@project.iam.gserviceaccount.com", DisplayName: "Sync"}
+	credentials := reports.FromGoogleServiceAccountKeys(account, []*google.ServiceAccountKey{
+		{Name: "projects/p/serviceAccounts/sync@project.iam.gserviceaccount.com/keys/abc123", ValidAfterTime: normalize.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), ValidBeforeTime: normalize.NewTime(time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC))},
+		{Name: "projects/p/serviceAccounts/sync@project.iam.gserviceaccount.com/keys/def456", ValidAfterTime: normalize.NewTime(time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)), ValidBeforeTime: normalize.NewTime(time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC))},
+	}, now)
+
+	if len(credentials) != 2 {
+		t.Fatalf("FromGoogleServiceAccountKeys() returned %d credentials, want 2", len(credentials))
+	}
+	if c := credentials[0]; c.ID != "abc123" || c.Owner != account.Email || !c.Expires.IsZero() {
+		t.Errorf("credentials[0] = %+v, want a key which never expires", c)
+	}
+	if c := credentials[1]; c.Expires.IsZero() || c.Kind != reports.KindServiceAccountKey {
+		t.Errorf("credentials[1] = %+v", c)
+	}
+}
+
+func TestCredentialMonitor(t *testing.T) {
+	m := reports.NewCredentialMonitor(reports.CredentialPolicy{Now: func() time.Time { return now }})
+	if err := m.Evaluate(); err == nil {
+		t.Error("Evaluate() without credentials succeeded, want an error")
+	}
+
+	day := 24 * time.Hour
+	m.AddCredentials(
+		&reports.Credential{Source: reports.Google, ID: "fresh", Owner: "a", Created: now.Add(-10 * day)},
+		&reports.Credential{Source: reports.Google, ID: "old", Owner: "b", Created: now.Add(-200 * day)},
+		&reports.Credential{Source: reports.Okta, ID: "expiring", Owner: "c", Created: now.Add(-200 * day), Expires: now.Add(3 * day)},
+		&reports.Credential{Source: reports.Okta, ID: "expired", Owner: "d", Created: now.Add(-20 * day), Expires: now.Add(-day)},
+		&reports.Credential{Source: reports.Google, ID: "disabled", Owner: "e", Created: now.Add(-400 * day), Disabled: true},
+	)
+	if err := m.Evaluate(); err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+
+	statuses := map[string]string{}
+	for _, c := range m.Credentials {
+		statuses[c.ID] = c.Status
+	}
+	for id, want := range map[string]string{
+		"fresh":    reports.CredentialOK,
+		"old":      reports.CredentialStale,
+		"expiring": reports.CredentialExpiring,
+		"expired":  reports.CredentialExpired,
+		"disabled": reports.CredentialDisabled,
+	} {
+		if statuses[id] != want {
+			t.Errorf("status of %s = %s, want %s", id, statuses[id], want)
+		}
+	}
+
+	want := []string{"expired", "expiring", "old"}
+	if len(m.Alerts) != len(want) {
+		t.Fatalf("Alerts = %d, want %d", len(m.Alerts), len(want))
+	}
+	for i, id := range want {
+		if m.Alerts[i].ID != id {
+			t.Errorf("Alerts[%d] = %s, want %s", i, m.Alerts[i].ID, id)
+		}
+	}
+	if m.Alerts[2].AgeDays != 200 || m.Alerts[1].DaysToExpiry != 3 {
+		t.Errorf("AgeDays = %d, DaysToExpiry = %d", m.Alerts[2].AgeDays, m.Alerts[1].DaysToExpiry)
+	}
+
+	published := &recorder{}
+	if err := m.Alert(published); err != nil {
+		t.Fatalf("Alert() error = %v", err)
+	}
+	types := []string{events.CredentialExpired, events.CredentialExpiring, events.CredentialStale}
+	if len(*published) != len(types) {
+		t.Fatalf("Alert() published %d events, want %d", len(*published), len(types))
+	}
+	for i, e := range *published {
+		if e.Type != types[i] || e.Data.(*reports.Credential) != m.Alerts[i] {
+			t.Errorf("event %d = %+v", i, e)
+		}
+	}
+}
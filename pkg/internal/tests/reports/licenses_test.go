@@ -0,0 +1,87 @@
+// pkg/internal/tests/reports/licenses_test.go
+package reports_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/reports"
+)
+
+func newReconciliation() *reports.LicenseReconciliation {
+	return reports.NewLicenseReconciliation(reports.LicenseConfig{
+		Prices: map[string]float64{
+			"Google Workspace Business Plus": 18,
+			"zoom":                           15,
+		},
+		Now: func() time.Time { return now },
+	})
+}
+
+func TestReconcileRequiresIdentities(t *testing.T) {
+	r := newReconciliation()
+	r.AddSeats(&reports.Seat{Source: reports.Google, Product: "Google Workspace Business Plus", Email: "a@example.com"})
+	if err := r.Reconcile(); err == nil {
+		t.Error("Reconcile() without identities succeeded, want an error")
+	}
+}
+
+func TestReconcileFlagsReclaimableSeats(t *testing.T) {
+	r := newReconciliation()
+	r.AddIdentities(
+		&reports.Account{Source: reports.Okta, Email: "Active@example.com", Status: "ACTIVE", Active: true},
+		&reports.Account{Source: reports.Okta, Email: "gone@example.com", Status: "DEPROVISIONED"},
+		// An active account elsewhere keeps the identity active
+		&reports.Account{Source: reports.Google, Email: "active@example.com", Status: "SUSPENDED"},
+	)
+	r.AddSeats(
+		&reports.Seat{Source: reports.Google, Product: "Google Workspace Business Plus", Email: "active@example.com"},
+		&reports.Seat{Source: reports.Google, Product: "Google Workspace Business Plus", Email: "gone@example.com"},
+		&reports.Seat{Source: reports.Okta, Product: "Zoom", Email: "stranger@example.com"},
+		&reports.Seat{Source: reports.Adobe, Product: "Creative Cloud", Email: "gone@example.com"},
+	)
+	if err := r.Reconcile(); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if len(r.Reclaimable) != 3 {
+		t.Fatalf("Reclaimable = %d seats, want 3", len(r.Reclaimable))
+	}
+	reasons := map[string]string{}
+	for _, s := range r.Reclaimable {
+		reasons[string(s.Source)+"/"+s.Email] = s.Reason
+		if s.CollectedAt != now {
+			t.Errorf("%s CollectedAt = %v, want %v", s.Email, s.CollectedAt, now)
+		}
+	}
+	for seat, want := range map[string]string{
+		"google/gone@example.com":   reports.ReasonDeactivated,
+		"okta/stranger@example.com": reports.ReasonNoIdentity,
+		"adobe/gone@example.com":    reports.ReasonDeactivated,
+	} {
+		if reasons[seat] != want {
+			t.Errorf("reason of %s = %q, want %q", seat, reasons[seat], want)
+		}
+	}
+
+	if got := r.MonthlyReclaimable(); got != 33 {
+		t.Errorf("MonthlyReclaimable() = %v, want 33", got)
+	}
+	if got := r.AnnualReclaimable(); got != 396 {
+		t.Errorf("AnnualReclaimable() = %v, want 396", got)
+	}
+
+	summaries := map[string]*reports.LicenseSummary{}
+	for _, s := range r.Summary {
+		summaries[s.Product] = s
+	}
+	if s := summaries["Google Workspace Business Plus"]; s == nil || s.Seats != 2 || s.Reclaimable != 1 || s.MonthlyCost != 36 || s.ReclaimableCost != 18 {
+		t.Errorf("Google summary = %+v", s)
+	}
+	if s := summaries["Creative Cloud"]; s == nil || !s.Unpriced {
+		t.Errorf("Creative Cloud summary = %+v, want unpriced", s)
+	}
+	if s := summaries["Zoom"]; s == nil || s.Unpriced {
+		t.Errorf("Zoom summary = %+v, want priced", s)
+	}
+}
@@ -0,0 +1,92 @@
+// pkg/internal/tests/reports/mfa_test.go
+package reports_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/duo"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/reports"
+)
+
+func TestFromFactorSources(t *testing.T) {
+	user := &okta.User{ID: "00u1", Profile: &okta.UserProfile{Email: "a@example.com"}}
+	factors := reports.FromOktaFactors(user, okta.Factors{
+		{FactorType: "push", Status: "ACTIVE"},
+		{FactorType: "webauthn", Status: "ACTIVE"},
+		{FactorType: "sms", Status: "PENDING_ACTIVATION"},
+	}, now)
+	if len(factors) != 2 || factors[0].Type != reports.FactorPush || factors[1].Type != reports.FactorWebAuthn || !factors[1].PhishingResistant {
+		t.Errorf("FromOktaFactors() = %+v", factors)
+	}
+
+	usage := []*google.UsageReport{
+		{Entity: google.Entity{UserEmail: "a@example.com"}, Parameters: []google.ReportParameter{{Name: reports.Google2SVEnrolled, BoolValue: true}, {Name: reports.GoogleSecurityKeys, IntValue: "1"}}},
+		{Entity: google.Entity{UserEmail: "b@example.com"}, Parameters: []google.ReportParameter{{Name: reports.Google2SVEnrolled, BoolValue: true}}},
+		{Entity: google.Entity{UserEmail: "c@example.com"}, Parameters: []google.ReportParameter{{Name: reports.Google2SVEnrolled}}},
+	}
+	factors = reports.FromGoogleUsage(usage, now)
+	if len(factors) != 2 || factors[0].Type != reports.FactorSecurityKey || factors[1].Type != reports.FactorTwoStep || factors[1].PhishingResistant {
+		t.Errorf("FromGoogleUsage() = %+v", factors)
+	}
+
+	factors = reports.FromDuoUsers(duo.Users{
+		{UserID: "D1", Username: "a@example.com", Status: "active", Phones: []*duo.Phone{{Capabilities: []string{"push", "sms"}}}, U2FTokens: []*duo.U2FToken{{}}},
+		{UserID: "D2", Email: "b@example.com", Status: "bypass", Tokens: []*duo.Token{{Type: "yk"}}},
+	}, now)
+	// Push is only a factor once Duo Mobile is activated, and bypassed users have no factor
+	if len(factors) != 2 || factors[0].Type != reports.FactorSMS || factors[1].Type != reports.FactorU2F || factors[0].Email != "a@example.com" {
+		t.Errorf("FromDuoUsers() = %+v", factors)
+	}
+}
+
+func TestMFAEvaluate(t *testing.T) {
+	r := reports.NewMFAReport(reports.MFAConfig{Now: func() time.Time { return now }})
+	if err := r.Evaluate(); err == nil {
+		t.Error("Evaluate() without accounts succeeded, want an error")
+	}
+
+	r.AddAccounts(
+		&reports.Account{Source: reports.Okta, Email: "Strong@example.com", Active: true},
+		&reports.Account{Source: reports.Google, Email: "strong@example.com", Active: true},
+		&reports.Account{Source: reports.Okta, Email: "weak@example.com", Active: true},
+		&reports.Account{Source: reports.Okta, Email: "none@example.com", Active: true},
+		&reports.Account{Source: reports.Google, Email: "admin@example.com", Active: true},
+		&reports.Account{Source: reports.Okta, Email: "gone@example.com", Active: false},
+	)
+	r.AddAdmins(&reports.AdminRole{Source: reports.Google, Email: "admin@example.com", Role: "Super Administrator"})
+	r.AddFactors(
+		&reports.EnrolledFactor{Source: reports.Okta, Email: "strong@example.com", Type: reports.FactorPush},
+		&reports.EnrolledFactor{Source: reports.Duo, Email: "STRONG@example.com", Type: reports.FactorWebAuthn, PhishingResistant: true},
+		&reports.EnrolledFactor{Source: reports.Okta, Email: "weak@example.com", Type: reports.FactorSMS},
+	)
+	if err := r.Evaluate(); err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+
+	if len(r.Users) != 4 {
+		t.Fatalf("Users = %d, want 4 (inactive accounts are skipped)", len(r.Users))
+	}
+	postures := map[string]*reports.MFAPosture{}
+	for _, p := range r.Users {
+		postures[p.Email] = p
+	}
+	if p := postures["Strong@example.com"]; p == nil || p.Finding != "" || p.Sources != "google, okta" || p.Factors != "push, webauthn" {
+		t.Errorf("strong posture = %+v", p)
+	}
+
+	want := []string{reports.FindingAdminWithoutMFA, reports.FindingNoMFA, reports.FindingNotPhishingResistant}
+	if len(r.Findings) != len(want) {
+		t.Fatalf("Findings = %d, want %d", len(r.Findings), len(want))
+	}
+	for i, finding := range want {
+		if r.Findings[i].Finding != finding {
+			t.Errorf("Findings[%d] = %s (%s), want %s", i, r.Findings[i].Finding, r.Findings[i].Email, finding)
+		}
+	}
+	if r.Findings[0].AdminRoles != "google: Super Administrator" {
+		t.Errorf("AdminRoles = %q", r.Findings[0].AdminRoles)
+	}
+}
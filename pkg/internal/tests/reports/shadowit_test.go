@@ -0,0 +1,108 @@
+// pkg/internal/tests/reports/shadowit_test.go
+package reports_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/normalize"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/reports"
+)
+
+func newShadowIT() *reports.ShadowITReport {
+	return reports.NewShadowITReport(reports.ShadowITConfig{
+		Sanctioned: []string{"Zoom*"},
+		ScopeRisk:  map[string]int{"custom.read": 1},
+		Now:        func() time.Time { return now },
+	})
+}
+
+func TestScopeScore(t *testing.T) {
+	r := newShadowIT()
+	for scope, want := range map[string]int{
+		"openid": 1,
+		"https://www.googleapis.com/auth/userinfo.email":                1,
+		"https://www.googleapis.com/auth/drive.readonly":                2,
+		"https://www.googleapis.com/auth/drive":                         3,
+		"https://mail.google.com/":                                      3,
+		"https://www.googleapis.com/auth/admin.directory.user.readonly": 3,
+		"okta.users.manage":                                             3,
+		"custom.read":                                                   1,
+		"unknown.scope":                                                 2,
+	} {
+		if got := r.ScopeScore(scope); got != want {
+			t.Errorf("ScopeScore(%s) = %d, want %d", scope, got, want)
+		}
+	}
+}
+
+func TestShadowITEvaluate(t *testing.T) {
+	r := newShadowIT()
+	if err := r.Evaluate(); err == nil {
+		t.Error("Evaluate() without grants succeeded, want an error")
+	}
+
+	earlier := now.Add(-48 * time.Hour)
+	r.AddGrants(reports.FromOktaGrantEvents(okta.LogEvents{
+		{
+			EventType:    reports.OktaConsentGrant,
+			Published:    earlier,
+			Actor:        &okta.LogEntity{ID: "00u1", Type: "User", AlternateID: "a@example.com"},
+			Outcome:      &okta.LogOutcome{Result: "SUCCESS"},
+			Target:       []*okta.LogEntity{{ID: "0oa1", Type: "AppInstance", DisplayName: "Notes AI"}},
+			DebugContext: &okta.DebugContext{DebugData: map[string]interface{}{"grantedScopes": "openid,email"}},
+		},
+		{
+			EventType: reports.OktaConsentGrant,
+			Actor:     &okta.LogEntity{ID: "00u2", Type: "User", AlternateID: "b@example.com"},
+			Outcome:   &okta.LogOutcome{Result: "FAILURE"},
+			Target:    []*okta.LogEntity{{ID: "0oa1", Type: "AppInstance", DisplayName: "Notes AI"}},
+		},
+	})...)
+
+	authorize := func(email, app, client string, scopes ...string) google.Report {
+		return google.Report{
+			ID:    google.ActivityID{Time: normalize.NewTime(now)},
+			Actor: google.Actor{Email: email},
+			Events: []google.Event{{Name: reports.GoogleTokenAuthorize, Parameters: []google.ReportParameter{
+				{Name: "app_name", Value: app},
+				{Name: "client_id", Value: client},
+				{Name: "scope", MultiValue: scopes},
+			}}},
+		}
+	}
+	r.AddGrants(reports.FromGoogleTokenActivities([]google.Report{
+		authorize("a@example.com", "Notes AI", "123.apps.googleusercontent.com", "https://www.googleapis.com/auth/drive"),
+		authorize("a@example.com", "Notes AI", "123.apps.googleusercontent.com", "https://www.googleapis.com/auth/drive", "openid"),
+		authorize("b@example.com", "Zoom", "456.apps.googleusercontent.com", "https://www.googleapis.com/auth/calendar"),
+	})...)
+
+	if len(r.Grants) != 3 {
+		t.Fatalf("Grants = %d, want 3 (repeated grants are merged, failed grants skipped)", len(r.Grants))
+	}
+	if err := r.Evaluate(); err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+
+	for _, g := range r.Grants {
+		if g.Source == reports.Google && g.Email == "a@example.com" && (g.Events != 2 || g.Scopes != "https://www.googleapis.com/auth/drive openid") {
+			t.Errorf("merged grant = %+v", g)
+		}
+	}
+
+	if len(r.Apps) != 2 {
+		t.Fatalf("Apps = %d, want 2", len(r.Apps))
+	}
+	notes, zoom := r.Apps[0], r.Apps[1]
+	if notes.App != "Notes AI" || notes.Sources != "google, okta" || notes.Users != 1 || notes.Risk != reports.RiskHigh || notes.Sanctioned {
+		t.Errorf("Apps[0] = %+v", notes)
+	}
+	if !notes.FirstSeen.Equal(earlier) || !notes.LastSeen.Equal(now) {
+		t.Errorf("Apps[0] seen %v - %v, want %v - %v", notes.FirstSeen, notes.LastSeen, earlier, now)
+	}
+	if zoom.App != "Zoom" || !zoom.Sanctioned {
+		t.Errorf("Apps[1] = %+v, want the sanctioned app last", zoom)
+	}
+}
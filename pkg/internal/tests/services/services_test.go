@@ -0,0 +1,117 @@
+// pkg/internal/tests/services/services_test.go
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+	"github.com/gemini-oss/rego/pkg/services"
+	"github.com/gemini-oss/rego/pkg/slack"
+	"github.com/gemini-oss/rego/pkg/testutil"
+)
+
+type fakeClient struct {
+	name     string
+	health   error
+	closeErr error
+	closed   bool
+}
+
+func (f *fakeClient) Name() string                          { return f.name }
+func (f *fakeClient) Endpoint() string                      { return "https://" + f.name + ".example.com" }
+func (f *fakeClient) HealthCheck(ctx context.Context) error { return f.health }
+func (f *fakeClient) Close(ctx context.Context) error {
+	f.closed = true
+	return f.closeErr
+}
+
+func TestRegistry(t *testing.T) {
+	down := errors.New("connection refused")
+	okta := &fakeClient{name: "okta"}
+	jamf := &fakeClient{name: "jamf", health: down, closeErr: errors.New("flush failed")}
+
+	r := services.NewRegistry()
+	for _, c := range []*fakeClient{okta, jamf} {
+		if err := r.Register(c); err != nil {
+			t.Fatalf("Register(%s) error = %v", c.name, err)
+		}
+	}
+	if err := r.Register(&fakeClient{name: "okta"}); !errors.Is(err, services.ErrDuplicate) {
+		t.Errorf("Register() of a duplicate error = %v, want ErrDuplicate", err)
+	}
+
+	if names := r.Names(); !reflect.DeepEqual(names, []string{"jamf", "okta"}) {
+		t.Errorf("Names() = %q, want [jamf okta]", names)
+	}
+	if c, ok := r.Get("okta"); !ok || c != okta {
+		t.Errorf("Get(okta) = %v, %v, want the registered client", c, ok)
+	}
+	if _, ok := r.Get("slack"); ok {
+		t.Error("Get(slack) found a service which is not registered")
+	}
+
+	health := r.HealthCheck(context.Background())
+	if len(health) != 2 || health["okta"] != nil || !errors.Is(health["jamf"], down) {
+		t.Errorf("HealthCheck() = %v, want okta healthy and jamf down", health)
+	}
+
+	err := r.Close(context.Background())
+	if !okta.closed || !jamf.closed {
+		t.Error("Close() left a client open")
+	}
+	if err == nil || err.Error() != "jamf: flush failed" {
+		t.Errorf("Close() error = %v, want the error of jamf", err)
+	}
+}
+
+func TestNewEmpty(t *testing.T) {
+	r, err := services.New(services.Config{Verbosity: log.INFO})
+	if err != nil || len(r.Clients()) != 0 {
+		t.Errorf("New() of no services = %v, %v, want an empty registry", r.Names(), err)
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/auth.test" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer valid" {
+			fmt.Fprint(w, `{"ok":false,"error":"invalid_auth"}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true}`)
+	}))
+	defer server.Close()
+
+	client := func(token string) *slack.Client {
+		c, err := cache.NewCache([]byte(testutil.EncryptionKey), true, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		headers := requests.Headers{"Authorization": "Bearer " + token}
+		return &slack.Client{BaseURL: server.URL, HTTP: requests.NewClient(nil, headers, nil, requests.WithCache(c), requests.WithRetry(retry.Policy{MaxAttempts: 1}))}
+	}
+
+	var c services.ServiceClient = client("valid")
+	if c.Name() != "slack" || c.Endpoint() != server.URL {
+		t.Errorf("Name(), Endpoint() = %q, %q, want slack and the base URL", c.Name(), c.Endpoint())
+	}
+	if err := c.HealthCheck(context.Background()); err != nil {
+		t.Errorf("HealthCheck() error = %v", err)
+	}
+	if err := client("revoked").HealthCheck(context.Background()); !errors.Is(err, rerrors.ErrUnauthorized) {
+		t.Errorf("HealthCheck() error = %v, want ErrUnauthorized", err)
+	}
+}
@@ -0,0 +1,557 @@
+// pkg/internal/tests/storage/driver_test.go
+package storage_test
+
+import (
+	"cmp"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+/*
+ * # Fake SQL Driver
+ * An in-memory `database/sql` driver which understands the subset of SQLite the store issues, so the store is tested
+ * without linking a SQLite driver into the module:
+ * - `CREATE TABLE` (columns and `AUTOINCREMENT`), `CREATE INDEX` (ignored)
+ * - `INSERT INTO t (columns) VALUES (?, ...)`, `UPDATE t SET column = ? WHERE ...`
+ * - `SELECT columns FROM t WHERE ... ORDER BY columns`, with `=`, `<`, `<=`, `>`, `>=`, `IS NULL`, `AND`, `OR` and parentheses
+ * - Each DSN is a separate database; a transaction is rolled back by restoring the tables it started with
+ */
+func init() {
+	sql.Register("fakesql", &fakeDriver{databases: map[string]*fakeDB{}})
+}
+
+type fakeDriver struct {
+	mutex     sync.Mutex
+	databases map[string]*fakeDB
+}
+
+func (d *fakeDriver) Open(dsn string) (driver.Conn, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	db, ok := d.databases[dsn]
+	if !ok {
+		db = &fakeDB{tables: map[string]*fakeTable{}}
+		d.databases[dsn] = db
+	}
+	return &fakeConn{db: db}, nil
+}
+
+type fakeDB struct {
+	mutex  sync.Mutex
+	tables map[string]*fakeTable
+}
+
+type fakeTable struct {
+	columns []string
+	serial  string // Column assigned by AUTOINCREMENT, if any
+	next    int64
+	rows    [][]driver.Value
+}
+
+// clone copies the tables of the database, for rolling back a transaction
+func (db *fakeDB) clone() map[string]*fakeTable {
+	tables := make(map[string]*fakeTable, len(db.tables))
+	for name, t := range db.tables {
+		c := *t
+		c.rows = make([][]driver.Value, len(t.rows))
+		for i, row := range t.rows {
+			c.rows[i] = slices.Clone(row)
+		}
+		tables[name] = &c
+	}
+	return tables
+}
+
+type fakeConn struct {
+	db     *fakeDB
+	backup map[string]*fakeTable
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.mutex.Lock()
+	defer c.db.mutex.Unlock()
+	c.backup = c.db.clone()
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.backup = nil
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.db.mutex.Lock()
+	defer c.db.mutex.Unlock()
+	if c.backup != nil {
+		c.db.tables, c.backup = c.backup, nil
+	}
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.conn.db
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	p := &parser{tokens: lex(s.query), args: args}
+	switch {
+	case p.accept("CREATE", "TABLE"):
+		return db.create(p)
+	case p.accept("CREATE", "INDEX"):
+		return driver.RowsAffected(0), nil
+	case p.accept("INSERT", "INTO"):
+		return db.insert(p)
+	case p.accept("UPDATE"):
+		return db.update(p)
+	}
+	return nil, fmt.Errorf("fakesql: unsupported statement %q", s.query)
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	db := s.conn.db
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	p := &parser{tokens: lex(s.query), args: args}
+	if !p.accept("SELECT") {
+		return nil, fmt.Errorf("fakesql: unsupported query %q", s.query)
+	}
+	return db.query(p)
+}
+
+func (db *fakeDB) create(p *parser) (driver.Result, error) {
+	p.accept("IF", "NOT", "EXISTS")
+	name := p.next()
+	if _, ok := db.tables[name]; ok {
+		return driver.RowsAffected(0), nil
+	}
+
+	t := &fakeTable{next: 1}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	for {
+		column := p.next()
+		t.columns = append(t.columns, column)
+		// Skip the type and constraints of the column
+		for depth := 0; p.peek() != ""; p.next() {
+			token := p.peek()
+			if depth == 0 && (token == "," || token == ")") {
+				break
+			}
+			switch {
+			case token == "(":
+				depth++
+			case token == ")":
+				depth--
+			case strings.EqualFold(token, "AUTOINCREMENT"):
+				t.serial = column
+			}
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	db.tables[name] = t
+	return driver.RowsAffected(0), nil
+}
+
+func (db *fakeDB) insert(p *parser) (driver.Result, error) {
+	t, err := db.table(p.next())
+	if err != nil {
+		return nil, err
+	}
+	columns := p.list()
+	if err := p.expect("VALUES"); err != nil {
+		return nil, err
+	}
+	values := p.list()
+	if len(columns) != len(values) {
+		return nil, fmt.Errorf("fakesql: %d columns but %d values", len(columns), len(values))
+	}
+
+	row := make([]driver.Value, len(t.columns))
+	for i, column := range columns {
+		j := slices.Index(t.columns, column)
+		if j < 0 {
+			return nil, fmt.Errorf("fakesql: no such column: %s", column)
+		}
+		if row[j], err = p.value(values[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	var id int64
+	if t.serial != "" {
+		id = t.next
+		t.next++
+		row[slices.Index(t.columns, t.serial)] = id
+	}
+	t.rows = append(t.rows, row)
+	return &fakeResult{id: id, affected: 1}, nil
+}
+
+func (db *fakeDB) update(p *parser) (driver.Result, error) {
+	t, err := db.table(p.next())
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("SET"); err != nil {
+		return nil, err
+	}
+	name := p.next()
+	column := slices.Index(t.columns, name)
+	if column < 0 {
+		return nil, fmt.Errorf("fakesql: no such column: %s", name)
+	}
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	value, err := p.value(p.next())
+	if err != nil {
+		return nil, err
+	}
+	where, err := p.where(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var affected int64
+	for _, row := range t.rows {
+		if where(row) {
+			row[column] = value
+			affected++
+		}
+	}
+	return &fakeResult{affected: affected}, nil
+}
+
+func (db *fakeDB) query(p *parser) (driver.Rows, error) {
+	var columns []string
+	for {
+		columns = append(columns, p.next())
+		if !p.accept(",") {
+			break
+		}
+	}
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	t, err := db.table(p.next())
+	if err != nil {
+		return nil, err
+	}
+	where, err := p.where(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var order []int
+	if p.accept("ORDER", "BY") {
+		for {
+			name := p.next()
+			i := slices.Index(t.columns, name)
+			if i < 0 {
+				return nil, fmt.Errorf("fakesql: no such column: %s", name)
+			}
+			order = append(order, i)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	indices := make([]int, len(columns))
+	for i, column := range columns {
+		if indices[i] = slices.Index(t.columns, column); indices[i] < 0 {
+			return nil, fmt.Errorf("fakesql: no such column: %s", column)
+		}
+	}
+
+	matched := [][]driver.Value{}
+	for _, row := range t.rows {
+		if where(row) {
+			matched = append(matched, row)
+		}
+	}
+	slices.SortStableFunc(matched, func(a, b []driver.Value) int {
+		for _, i := range order {
+			if c := compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	rows := &fakeRows{columns: columns}
+	for _, row := range matched {
+		out := make([]driver.Value, len(indices))
+		for i, j := range indices {
+			out[i] = row[j]
+		}
+		rows.rows = append(rows.rows, out)
+	}
+	return rows, nil
+}
+
+func (db *fakeDB) table(name string) (*fakeTable, error) {
+	t, ok := db.tables[name]
+	if !ok {
+		return nil, fmt.Errorf("fakesql: no such table: %s", name)
+	}
+	return t, nil
+}
+
+type fakeResult struct {
+	id       int64
+	affected int64
+}
+
+func (r *fakeResult) LastInsertId() (int64, error) { return r.id, nil }
+func (r *fakeResult) RowsAffected() (int64, error) { return r.affected, nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// compare orders values as SQLite does: NULL first, then integers, then text
+func compare(a, b driver.Value) int {
+	rank := func(v driver.Value) int {
+		switch v.(type) {
+		case nil:
+			return 0
+		case int64:
+			return 1
+		default:
+			return 2
+		}
+	}
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra - rb
+	}
+	switch a := a.(type) {
+	case int64:
+		return cmp.Compare(a, b.(int64))
+	case string:
+		return strings.Compare(a, b.(string))
+	}
+	return 0
+}
+
+// ### Parser
+// ---------------------------------------------------------------------
+
+// lex splits a statement into identifiers, placeholders, punctuation and operators
+func lex(query string) []string {
+	tokens := []string{}
+	for i := 0; i < len(query); {
+		switch c := rune(query[i]); {
+		case unicode.IsSpace(c):
+			i++
+		case c == '<' || c == '>':
+			if i+1 < len(query) && query[i+1] == '=' {
+				tokens = append(tokens, query[i:i+2])
+				i += 2
+			} else {
+				tokens = append(tokens, query[i:i+1])
+				i++
+			}
+		case strings.ContainsRune("(),?=", c):
+			tokens = append(tokens, query[i:i+1])
+			i++
+		default:
+			j := i
+			for j < len(query) && !unicode.IsSpace(rune(query[j])) && !strings.ContainsRune("(),?=<>", rune(query[j])) {
+				j++
+			}
+			tokens = append(tokens, query[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
+
+type parser struct {
+	tokens []string
+	args   []driver.Value
+	arg    int // Next placeholder
+}
+
+func (p *parser) peek() string {
+	if len(p.tokens) == 0 {
+		return ""
+	}
+	return p.tokens[0]
+}
+
+func (p *parser) next() string {
+	token := p.peek()
+	if token != "" {
+		p.tokens = p.tokens[1:]
+	}
+	return token
+}
+
+// accept consumes the keywords if the statement continues with them
+func (p *parser) accept(keywords ...string) bool {
+	if len(p.tokens) < len(keywords) {
+		return false
+	}
+	for i, keyword := range keywords {
+		if !strings.EqualFold(p.tokens[i], keyword) {
+			return false
+		}
+	}
+	p.tokens = p.tokens[len(keywords):]
+	return true
+}
+
+func (p *parser) expect(keyword string) error {
+	if !p.accept(keyword) {
+		return fmt.Errorf("fakesql: expected %s, found %q", keyword, p.peek())
+	}
+	return nil
+}
+
+// list parses a parenthesized list of tokens, e.g. `(a, b)` or `(?, ?)`
+func (p *parser) list() []string {
+	items := []string{}
+	if !p.accept("(") {
+		return items
+	}
+	for !p.accept(")") && p.peek() != "" {
+		items = append(items, p.next())
+		p.accept(",")
+	}
+	return items
+}
+
+// value returns the argument of a placeholder
+func (p *parser) value(token string) (driver.Value, error) {
+	if token != "?" {
+		return nil, fmt.Errorf("fakesql: only placeholders are supported as values, found %q", token)
+	}
+	if p.arg >= len(p.args) {
+		return nil, fmt.Errorf("fakesql: missing argument %d", p.arg+1)
+	}
+	v := p.args[p.arg]
+	p.arg++
+	return v, nil
+}
+
+type predicate func(row []driver.Value) bool
+
+// where parses an optional `WHERE` clause into a predicate; without one, every row matches
+func (p *parser) where(t *fakeTable) (predicate, error) {
+	if !p.accept("WHERE") {
+		return func([]driver.Value) bool { return true }, nil
+	}
+	return p.or(t)
+}
+
+func (p *parser) or(t *fakeTable) (predicate, error) {
+	left, err := p.and(t)
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("OR") {
+		right, err := p.and(t)
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row []driver.Value) bool { return l(row) || right(row) }
+	}
+	return left, nil
+}
+
+func (p *parser) and(t *fakeTable) (predicate, error) {
+	left, err := p.comparison(t)
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("AND") {
+		right, err := p.comparison(t)
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row []driver.Value) bool { return l(row) && right(row) }
+	}
+	return left, nil
+}
+
+func (p *parser) comparison(t *fakeTable) (predicate, error) {
+	if p.accept("(") {
+		inner, err := p.or(t)
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	}
+
+	name := p.next()
+	column := slices.Index(t.columns, name)
+	if column < 0 {
+		return nil, fmt.Errorf("fakesql: no such column: %s", name)
+	}
+	if p.accept("IS", "NULL") {
+		return func(row []driver.Value) bool { return row[column] == nil }, nil
+	}
+
+	op := p.next()
+	value, err := p.value(p.next())
+	if err != nil {
+		return nil, err
+	}
+	test, ok := map[string]func(int) bool{
+		"=":  func(c int) bool { return c == 0 },
+		"<":  func(c int) bool { return c < 0 },
+		"<=": func(c int) bool { return c <= 0 },
+		">":  func(c int) bool { return c > 0 },
+		">=": func(c int) bool { return c >= 0 },
+	}[op]
+	if !ok {
+		return nil, fmt.Errorf("fakesql: unsupported operator %q", op)
+	}
+	// Comparisons with NULL are never true
+	return func(row []driver.Value) bool {
+		return row[column] != nil && value != nil && test(compare(row[column], value))
+	}, nil
+}
@@ -0,0 +1,165 @@
+// pkg/internal/tests/storage/storage_test.go
+package storage_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/inventory"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/storage"
+)
+
+func TestRecords(t *testing.T) {
+	devices := inventory.Devices{
+		{SerialNumber: "C02XL0ABC", Hostname: "laptop-1"},
+		{UDID: "0000-1111", Hostname: "laptop-2"},
+		{MACAddresses: []string{"aa:bb:cc:dd:ee:ff"}},
+		{Hostname: "laptop-4"},
+		{},
+		nil,
+	}
+
+	keys := []string{}
+	for _, r := range storage.FromDevices(devices) {
+		keys = append(keys, r.Key)
+	}
+	if want := []string{"C02XL0ABC", "0000-1111", "aa:bb:cc:dd:ee:ff", "laptop-4"}; !slices.Equal(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+
+	records := storage.FromOktaUsers(okta.Users{{ID: "00u1", Status: "ACTIVE", Profile: &okta.UserProfile{Login: "alice@example.com"}}})
+	if len(records) != 1 || records[0].Key != "00u1" {
+		t.Fatalf("FromOktaUsers() = %+v", records)
+	}
+	user := &okta.User{}
+	if err := records[0].Decode(user); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if user.Status != "ACTIVE" || user.Profile.Login != "alice@example.com" {
+		t.Errorf("Decode() = %+v, want the original user", user)
+	}
+}
+
+// openStore opens a store with a SQLite driver linked into the test binary, or else with the fake driver
+func openStore(t *testing.T) *storage.Store {
+	t.Helper()
+	for _, driver := range []string{"sqlite", "sqlite3", "fakesql"} {
+		if slices.Contains(sql.Drivers(), driver) {
+			s, err := storage.Open(driver, filepath.Join(t.TempDir(), "rego.db"), log.ERROR)
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			t.Cleanup(func() { s.Close() })
+			return s
+		}
+	}
+	t.Fatal("no SQL driver is registered")
+	return nil
+}
+
+func TestSnapshotHistory(t *testing.T) {
+	s := openStore(t)
+
+	clock := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	s.Now = func() time.Time { return clock }
+
+	member := func(id string, status okta.Status) *okta.User {
+		return &okta.User{ID: id, Status: status, Profile: &okta.UserProfile{Login: id + "@example.com"}}
+	}
+
+	// June: alice and bob; July: bob changes, carol joins, alice leaves
+	june, err := s.Snapshot(storage.OktaGroupMembers, "00g1", storage.FromOktaUsers(okta.Users{member("alice", "ACTIVE"), member("bob", "ACTIVE")}))
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if june.Created != 2 || june.Records != 2 {
+		t.Errorf("june = %+v, want 2 created", june)
+	}
+
+	clock = clock.AddDate(0, 1, 0)
+	july, err := s.Snapshot(storage.OktaGroupMembers, "00g1", storage.FromOktaUsers(okta.Users{member("bob", "SUSPENDED"), member("carol", "ACTIVE")}))
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if july.Created != 1 || july.Updated != 1 || july.Deleted != 1 {
+		t.Errorf("july = %+v, want 1 created, 1 updated and 1 deleted", july)
+	}
+
+	clock = clock.AddDate(0, 0, 1)
+	unchanged, err := s.Snapshot(storage.OktaGroupMembers, "00g1", storage.FromOktaUsers(okta.Users{member("bob", "SUSPENDED"), member("carol", "ACTIVE")}))
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if unchanged.Created+unchanged.Updated+unchanged.Deleted != 0 {
+		t.Errorf("unchanged = %+v, want no changes", unchanged)
+	}
+
+	keys := func(records []*storage.Record) []string {
+		k := []string{}
+		for _, r := range records {
+			k = append(k, r.Key)
+		}
+		return k
+	}
+
+	mid := time.Date(2024, time.June, 15, 0, 0, 0, 0, time.UTC)
+	inJune, err := s.AsOf(storage.OktaGroupMembers, "00g1", mid)
+	if err != nil {
+		t.Fatalf("AsOf: %v", err)
+	}
+	if got := keys(inJune); !slices.Equal(got, []string{"alice", "bob"}) {
+		t.Errorf("AsOf(June) = %v, want alice and bob", got)
+	}
+
+	// The same instants in another time zone select the same records
+	eastern := time.FixedZone("EST", -5*60*60)
+	if inJune, err := s.AsOf(storage.OktaGroupMembers, "00g1", mid.In(eastern)); err != nil || !slices.Equal(keys(inJune), []string{"alice", "bob"}) {
+		t.Errorf("AsOf(June, EST) = %v, %v; want alice and bob", keys(inJune), err)
+	}
+	july1 := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)
+	if inJuly, err := s.AsOf(storage.OktaGroupMembers, "00g1", july1.In(eastern)); err != nil || !slices.Equal(keys(inJuly), []string{"bob", "carol"}) {
+		t.Errorf("AsOf(July 1, EST) = %v, %v; want bob and carol", keys(inJuly), err)
+	}
+
+	current, err := s.Current(storage.OktaGroupMembers, "00g1")
+	if err != nil {
+		t.Fatalf("Current: %v", err)
+	}
+	if got := keys(current); !slices.Equal(got, []string{"bob", "carol"}) {
+		t.Errorf("Current() = %v, want bob and carol", got)
+	}
+
+	before, err := s.AsOf(storage.OktaGroupMembers, "00g1", mid.AddDate(-1, 0, 0))
+	if err != nil || len(before) != 0 {
+		t.Errorf("AsOf(before the first snapshot) = %v, %v; want nothing", keys(before), err)
+	}
+
+	history, err := s.History(storage.OktaGroupMembers, "bob")
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) != 2 || history[0].Type != storage.Created || history[1].Type != storage.Updated || history[1].Before == nil {
+		t.Errorf("History(bob) = %+v, want created then updated", history)
+	}
+
+	changes, err := s.Changes(storage.OktaGroupMembers, "00g1", mid, time.Time{})
+	if err != nil {
+		t.Fatalf("Changes: %v", err)
+	}
+	if len(changes) != 3 {
+		t.Errorf("Changes(since June) = %d, want 3", len(changes))
+	}
+	if changes, err := s.Changes(storage.OktaGroupMembers, "00g1", july1.In(eastern), july1.Add(time.Nanosecond).In(eastern)); err != nil || len(changes) != 3 {
+		t.Errorf("Changes(July 1, EST) = %d, %v; want 3", len(changes), err)
+	}
+
+	snapshots, err := s.Snapshots(storage.OktaGroupMembers)
+	if err != nil || len(snapshots) != 3 {
+		t.Errorf("Snapshots() = %d, %v; want 3", len(snapshots), err)
+	}
+}
@@ -0,0 +1,184 @@
+/*
+# Test Utilities - Test
+
+This package tests the fake provider APIs, through the clients of each provider
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/internal/tests/testutil/testutil_test.go
+package testutil_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/backupify"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/testutil"
+)
+
+func TestOktaPagination(t *testing.T) {
+	o := testutil.NewOkta(t)
+	for i := 0; i < 450; i++ {
+		status := okta.ACTIVE
+		if i%10 == 0 {
+			status = okta.DEPROVISIONED
+		}
+		o.AddUser(&okta.User{ID: fmt.Sprintf("00u%03d", i), Status: status, Profile: &okta.UserProfile{Email: fmt.Sprintf("user%d@example.com", i)}})
+	}
+
+	c := o.NewClient(t, log.INFO)
+	users, err := c.ListAllUsers()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(*users) != 450 {
+		t.Errorf("Expected 450 users over 3 pages, got %d", len(*users))
+	}
+
+	// Both lists are cached under the same URL, so the active users are listed by a fresh client
+	active, err := o.NewClient(t, log.INFO).ListActiveUsers()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(*active) != 405 {
+		t.Errorf("Expected 405 active users, got %d", len(*active))
+	}
+
+	requests := o.Requests()
+	if len(requests) != 6 || requests[1].Query.Get("after") != "00u199" {
+		t.Errorf("Expected pages to follow the `after` cursor, got %d requests", len(requests))
+	}
+}
+
+func TestOktaMutationsAndFaults(t *testing.T) {
+	o := testutil.NewOkta(t)
+	o.AddUser(&okta.User{ID: "00u1", Status: "ACTIVE", Profile: &okta.UserProfile{Login: "user@example.com"}})
+	o.AddGroup(&okta.Group{ID: "00g1", Profile: okta.GroupProfile{Name: "Engineering"}})
+
+	// The first attempt fails, and the client retries
+	o.Fail(testutil.Fault{Method: "PUT", Path: "/api/v1/groups/*", Status: http.StatusServiceUnavailable, Times: 1})
+
+	c := o.NewClient(t, log.INFO)
+	if err := c.AddUserToGroup("00g1", "00u1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if members := o.Members("00g1"); len(members) != 1 || members[0] != "00u1" {
+		t.Errorf("Expected 00u1 to be added, got %v", members)
+	}
+	if err := c.DeactivateUser("user@example.com"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if status := o.User("00u1").Status; status != "DEPROVISIONED" {
+		t.Errorf("Expected the user to be deactivated, got %s", status)
+	}
+
+	// Rate limit headers count down, and requests beyond the limit are throttled
+	o.RateLimit = len(o.Requests()) + 1
+	resp, err := http.Get(o.URL + "/api/v1/users/00u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("X-Rate-Limit-Remaining") != "0" || resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the last request of the window to succeed, got %s with %s remaining", resp.Status, resp.Header.Get("X-Rate-Limit-Remaining"))
+	}
+	resp, err = http.Get(o.URL + "/api/v1/users/00u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+		t.Errorf("Expected the request to be throttled, got %s", resp.Status)
+	}
+}
+
+func TestGoogle(t *testing.T) {
+	g := testutil.NewGoogle(t)
+	for i := 0; i < 600; i++ {
+		g.AddUser(&google.User{ID: fmt.Sprint(i), PrimaryEmail: fmt.Sprintf("user%d@example.com", i)})
+	}
+	g.AddMember("eng@example.com", &google.Member{Email: "user1@example.com"})
+
+	c := g.NewClient(t, log.INFO)
+	users, err := c.Users().ListAllUsers()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(users.Users) != 600 {
+		t.Errorf("Expected 600 users over 2 pages, got %d", len(users.Users))
+	}
+
+	if _, err := c.Users().SuspendUser("user2@example.com"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if u := g.User("2"); !u.Suspended || u.PrimaryEmail != "user2@example.com" {
+		t.Errorf("Expected the user to be suspended, got %+v", u)
+	}
+
+	if _, err := c.Groups().AddMember("eng@example.com", "user2@example.com", ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := c.Groups().RemoveMember("eng@example.com", "user1@example.com"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if members := g.Members("eng@example.com"); len(members) != 1 || members[0] != "user2@example.com" {
+		t.Errorf("Unexpected members: %v", members)
+	}
+
+	for _, r := range g.Requests()[1:] {
+		if r.Header.Get("Authorization") != "Bearer "+testutil.GoogleToken {
+			t.Errorf("Expected %s %s to use the issued token", r.Method, r.Path)
+		}
+	}
+}
+
+func TestBackupify(t *testing.T) {
+	b := testutil.NewBackupify(t)
+	for i := 1; i <= 100; i++ {
+		b.AddUser(backupify.GoogleDrive, &backupify.User{ID: i, Email: fmt.Sprintf("user%d@example.com", i), UsedBytes: "1024"})
+	}
+	b.AddUser(backupify.GoogleDrive, &backupify.User{ID: 101, Email: "snap@example.com", Snapshots: []backupify.Snapshot{{ID: 7, Date: "2024-01-02 03:04"}}})
+
+	c := b.NewClient(t, log.INFO)
+	users, err := c.Users().GetAllUsers(backupify.GoogleDrive)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(users.Data) != 101 || len(users.Map()) != 101 {
+		t.Errorf("Expected 101 distinct users, got %d", len(users.Data))
+	}
+
+	user := users.Map()["snap@example.com"]
+	dates, err := c.Snapshots().GetSnapshotDates(backupify.GoogleDrive, user)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len((*dates)["2024-01-02"]) != 1 {
+		t.Errorf("Expected the snapshot to be grouped by date, got %v", *dates)
+	}
+
+	exports, err := c.Exports().ExportUser(user)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(*exports) != 1 || b.Exports()[(*exports)[0].ResponseData.ID].Status != "started" {
+		t.Errorf("Expected an export to be started, got %+v", b.Exports())
+	}
+
+	// Requests without the session are rejected
+	resp, err := http.Post(b.URL+"/"+testutil.BackupifyCustomerID+"/getActivities", "application/x-www-form-urlencoded", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected the request to be rejected, got %s", resp.Status)
+	}
+}
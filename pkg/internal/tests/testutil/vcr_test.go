@@ -0,0 +1,72 @@
+// pkg/internal/tests/testutil/vcr_test.go
+package testutil_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/testutil"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	fixture := filepath.Join(t.TempDir(), "okta_users.json")
+
+	o := testutil.NewOkta(t)
+	for i := 0; i < 250; i++ {
+		o.AddUser(&okta.User{ID: fmt.Sprintf("00u%03d", i), Status: "ACTIVE", Profile: &okta.UserProfile{Email: fmt.Sprintf("user%d@example.com", i)}})
+	}
+	o.Fail(testutil.Fault{Method: "GET", Path: "/api/v1/users", Status: http.StatusServiceUnavailable, Times: 1})
+
+	rec := testutil.NewRecorder(t, fixture, testutil.Record)
+	rec.Redact("user7@example.com")
+	c := o.NewClient(t, log.INFO)
+	c.HTTP.UpdateTransport(rec)
+	recorded, err := c.ListAllUsers()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, secret := range []string{"SSWS", "user7@example.com"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Expected %q to be scrubbed from the fixture", secret)
+		}
+	}
+
+	// Replay without the API: the failed request and both pages are served from the fixture
+	o.Close()
+	replay := testutil.NewRecorder(t, fixture, testutil.Replay)
+	c = o.NewClient(t, log.INFO)
+	c.HTTP.UpdateTransport(replay)
+	replayed, err := c.ListAllUsers()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(replay.Interactions()) != 3 {
+		t.Errorf("Expected the failure and 2 pages to be recorded, got %d interactions", len(replay.Interactions()))
+	}
+	if len(*replayed) != len(*recorded) || (*replayed)[249].ID != "00u249" {
+		t.Errorf("Expected the replay to match the recording, got %d users", len(*replayed))
+	}
+	if unplayed := replay.Unplayed(); len(unplayed) != 0 {
+		t.Errorf("Expected every interaction to be replayed, got %d unplayed", len(unplayed))
+	}
+
+	req, _ := http.NewRequest("GET", o.URL+"/api/v1/groups", nil)
+	if _, err := replay.RoundTrip(req); !errors.Is(err, testutil.ErrNotRecorded) {
+		t.Errorf("Expected unrecorded requests to fail, got %v", err)
+	}
+}
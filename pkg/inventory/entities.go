@@ -0,0 +1,88 @@
+/*
+# Inventory - Entities [Structs]
+
+This package contains the structs used to aggregate devices across providers:
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/inventory/entities.go
+package inventory
+
+import (
+	"time"
+)
+
+// ### Inventory Structs
+// ---------------------------------------------------------------------
+
+// Source is the provider a device record was collected from
+type Source string
+
+const (
+	Automox        Source = "automox"
+	GoogleChromeOS Source = "google_chromeos"
+	Jamf           Source = "jamf"
+	Okta           Source = "okta"
+	SnipeIT        Source = "snipeit"
+
+	// Sources without a client in rego; records are built by the caller
+	Intune  Source = "intune"
+	Kandji  Source = "kandji"
+	Meraki  Source = "meraki"
+	Tenable Source = "tenable"
+)
+
+// Role is the capability a source provides for a device
+type Role string
+
+const (
+	RoleAsset    Role = "asset"    // Asset management (e.g. Snipe-IT)
+	RoleEDR      Role = "edr"      // Endpoint detection and response (e.g. CrowdStrike, SentinelOne)
+	RoleIdP      Role = "idp"      // Identity provider device trust (e.g. Okta Devices)
+	RoleMDM      Role = "mdm"      // Mobile device management (e.g. Jamf, Kandji, Intune)
+	RoleNetwork  Role = "network"  // Network presence (e.g. Meraki)
+	RolePatch    Role = "patch"    // Patch management (e.g. Automox)
+	RoleScanning Role = "scanning" // Vulnerability scanning (e.g. Tenable)
+)
+
+// DeviceRecord is a single device in a single source
+type DeviceRecord struct {
+	Source       Source      `json:"source"`                 // Provider the record was collected from
+	ID           string      `json:"id"`                     // Identifier of the record in its provider
+	SerialNumber string      `json:"serialNumber,omitempty"` // Hardware serial number
+	MACAddresses []string    `json:"macAddresses,omitempty"` // Hardware (MAC) addresses of the device's interfaces
+	UDID         string      `json:"udid,omitempty"`         // Hardware UUID/UDID
+	Hostname     string      `json:"hostname,omitempty"`     // Hostname or device name
+	Manufacturer string      `json:"manufacturer,omitempty"` // Hardware manufacturer
+	Model        string      `json:"model,omitempty"`        // Hardware model
+	OS           string      `json:"os,omitempty"`           // Operating system name or platform
+	OSVersion    string      `json:"osVersion,omitempty"`    // Operating system version
+	User         string      `json:"user,omitempty"`         // Email (or username) of the assigned user
+	LastSeen     time.Time   `json:"lastSeen,omitempty"`     // Last time the device checked in with the source
+	Raw          interface{} `json:"-"`                      // The original provider object
+}
+
+// Device is a physical device, merged across every source that knows about it
+type Device struct {
+	SerialNumber string                     `json:"serialNumber,omitempty"` // Hardware serial number
+	MACAddresses []string                   `json:"macAddresses,omitempty"` // Every MAC address reported for the device
+	UDID         string                     `json:"udid,omitempty"`         // Hardware UUID/UDID
+	Hostname     string                     `json:"hostname,omitempty"`     // Hostname or device name
+	Manufacturer string                     `json:"manufacturer,omitempty"` // Hardware manufacturer
+	Model        string                     `json:"model,omitempty"`        // Hardware model
+	OS           string                     `json:"os,omitempty"`           // Operating system name or platform
+	OSVersion    string                     `json:"osVersion,omitempty"`    // Operating system version
+	User         string                     `json:"user,omitempty"`         // Email (or username) of the assigned user
+	LastSeen     time.Time                  `json:"lastSeen,omitempty"`     // Most recent check-in across all sources
+	Roles        []Role                     `json:"roles,omitempty"`        // Capabilities covering the device, e.g. `mdm`, `edr`
+	Records      map[Source][]*DeviceRecord `json:"records"`                // Records for this device, grouped by source
+	Provenance   map[string]Source          `json:"provenance"`             // The source each merged field was taken from, keyed by JSON field name
+}
+
+type Devices []*Device
+
+// END OF INVENTORY STRUCTS
+//---------------------------------------------------------------------
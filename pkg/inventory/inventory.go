@@ -0,0 +1,286 @@
+/*
+# Inventory
+
+This package aggregates device records from multiple sources (Jamf, Okta Devices, Google ChromeOS, Automox, Snipe-IT,
+and any caller-supplied source such as Kandji, Intune, Meraki or Tenable) into a single normalized Device per piece of
+hardware. Records are merged on serial number, MAC address and UDID, and every merged field keeps track of the source
+it came from, enabling reports such as "unmanaged devices" and "devices missing EDR".
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/inventory/inventory.go
+package inventory
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Aggregator merges device records from multiple sources
+type Aggregator struct {
+	Roles    map[Source][]Role // Capabilities provided by each source; register EDR and other custom sources here
+	Priority []Source          // Order in which sources are trusted when their values disagree; unlisted sources come last
+	records  []*DeviceRecord
+}
+
+// Placeholder serial numbers reported by virtual machines and white-box hardware
+var invalidSerials = []string{
+	"",
+	"0",
+	"default string",
+	"none",
+	"not specified",
+	"system serial number",
+	"to be filled by o.e.m.",
+	"unknown",
+}
+
+/*
+  - # Generate a Device Aggregator
+  - @return *Aggregator
+  - Example:
+
+```go
+
+	a := inventory.NewAggregator()
+	a.Roles["crowdstrike"] = []inventory.Role{inventory.RoleEDR}
+	a.Add(inventory.FromJamf(computers)...)
+	a.Add(inventory.FromAutomox(devices)...)
+	a.Add(crowdstrikeRecords...)
+
+	devices := a.Aggregate()
+	fmt.Println(len(devices.Unmanaged()), "unmanaged devices")
+	fmt.Println(len(devices.MissingEDR()), "devices missing EDR")
+
+```
+*/
+func NewAggregator() *Aggregator {
+	return &Aggregator{
+		Roles: map[Source][]Role{
+			Automox:        {RolePatch},
+			GoogleChromeOS: {RoleMDM},
+			Intune:         {RoleMDM},
+			Jamf:           {RoleMDM},
+			Kandji:         {RoleMDM},
+			Meraki:         {RoleNetwork},
+			Okta:           {RoleIdP},
+			SnipeIT:        {RoleAsset},
+			Tenable:        {RoleScanning},
+		},
+		Priority: []Source{Jamf, Kandji, Intune, GoogleChromeOS, Automox, Okta, Tenable, Meraki, SnipeIT},
+	}
+}
+
+// Add records to the aggregator; records without a serial number, MAC address or UDID cannot be merged and are ignored
+func (a *Aggregator) Add(records ...*DeviceRecord) {
+	for _, r := range records {
+		if r == nil || len(r.keys()) == 0 {
+			continue
+		}
+		a.records = append(a.records, r)
+	}
+}
+
+/*
+ * # Aggregate all added records into devices
+ * - Two records belong to the same device if they share a serial number, a MAC address or a UDID
+ * - Merged fields are taken from the highest priority source that has a value
+ */
+func (a *Aggregator) Aggregate() Devices {
+	// Union-find over the records, keyed by every hardware identifier they carry
+	parent := make([]int, len(a.records))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	owners := map[string]int{}
+	for i, r := range a.records {
+		for _, key := range r.keys() {
+			if j, ok := owners[key]; ok {
+				parent[find(i)] = find(j)
+			} else {
+				owners[key] = i
+			}
+		}
+	}
+
+	groups := map[int][]*DeviceRecord{}
+	roots := []int{}
+	for i, r := range a.records {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], r)
+	}
+
+	devices := Devices{}
+	for _, root := range roots {
+		devices = append(devices, a.newDevice(groups[root]))
+	}
+
+	sort.SliceStable(devices, func(i, j int) bool {
+		return devices[i].SerialNumber < devices[j].SerialNumber
+	})
+
+	return devices
+}
+
+func (a *Aggregator) newDevice(records []*DeviceRecord) *Device {
+	d := &Device{
+		Records:    map[Source][]*DeviceRecord{},
+		Provenance: map[string]Source{},
+	}
+
+	sorted := slices.Clone(records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return a.rank(sorted[i].Source) < a.rank(sorted[j].Source)
+	})
+
+	for _, r := range sorted {
+		d.Records[r.Source] = append(d.Records[r.Source], r)
+
+		for _, role := range a.Roles[r.Source] {
+			if !slices.Contains(d.Roles, role) {
+				d.Roles = append(d.Roles, role)
+			}
+		}
+
+		if validSerial(r.SerialNumber) {
+			d.set("serialNumber", &d.SerialNumber, strings.ToUpper(strings.TrimSpace(r.SerialNumber)), r.Source)
+		}
+		d.set("udid", &d.UDID, strings.ToUpper(strings.TrimSpace(r.UDID)), r.Source)
+		d.set("hostname", &d.Hostname, r.Hostname, r.Source)
+		d.set("manufacturer", &d.Manufacturer, r.Manufacturer, r.Source)
+		d.set("model", &d.Model, r.Model, r.Source)
+		d.set("os", &d.OS, r.OS, r.Source)
+		d.set("osVersion", &d.OSVersion, r.OSVersion, r.Source)
+		d.set("user", &d.User, r.User, r.Source)
+
+		for _, mac := range r.MACAddresses {
+			if mac = normalizeMAC(mac); mac != "" && !slices.Contains(d.MACAddresses, mac) {
+				d.MACAddresses = append(d.MACAddresses, mac)
+			}
+		}
+
+		if r.LastSeen.After(d.LastSeen) {
+			d.LastSeen = r.LastSeen
+			d.Provenance["lastSeen"] = r.Source
+		}
+	}
+
+	slices.Sort(d.Roles)
+	return d
+}
+
+// set assigns a merged field if it has not been set by a higher priority source
+func (d *Device) set(field string, target *string, value string, source Source) {
+	value = strings.TrimSpace(value)
+	if *target != "" || value == "" {
+		return
+	}
+	*target = value
+	d.Provenance[field] = source
+}
+
+func (a *Aggregator) rank(source Source) int {
+	if i := slices.Index(a.Priority, source); i >= 0 {
+		return i
+	}
+	return len(a.Priority)
+}
+
+// keys returns the merge keys of a record
+func (r *DeviceRecord) keys() []string {
+	keys := []string{}
+	if validSerial(r.SerialNumber) {
+		keys = append(keys, "serial:"+strings.ToUpper(strings.TrimSpace(r.SerialNumber)))
+	}
+	for _, mac := range r.MACAddresses {
+		if mac = normalizeMAC(mac); mac != "" {
+			keys = append(keys, "mac:"+mac)
+		}
+	}
+	if udid := strings.ToUpper(strings.TrimSpace(r.UDID)); udid != "" {
+		keys = append(keys, "udid:"+udid)
+	}
+	return keys
+}
+
+// Sources returns the sources a device has records in, in alphabetical order
+func (d *Device) Sources() []Source {
+	sources := []Source{}
+	for source := range d.Records {
+		sources = append(sources, source)
+	}
+	slices.Sort(sources)
+	return sources
+}
+
+// Has reports whether any of the device's sources provide the role
+func (d *Device) Has(role Role) bool {
+	return slices.Contains(d.Roles, role)
+}
+
+// Unmanaged returns the devices which are not enrolled in any MDM
+func (devices Devices) Unmanaged() Devices {
+	return devices.Missing(RoleMDM)
+}
+
+// MissingEDR returns the devices without an EDR agent
+// - EDR sources must be registered in `Aggregator.Roles`; rego has no built-in EDR source
+func (devices Devices) MissingEDR() Devices {
+	return devices.Missing(RoleEDR)
+}
+
+// Missing returns the devices not covered by the role
+func (devices Devices) Missing(role Role) Devices {
+	return devices.Filter(func(d *Device) bool { return !d.Has(role) })
+}
+
+// Filter returns the devices matching `keep`
+func (devices Devices) Filter(keep func(*Device) bool) Devices {
+	filtered := Devices{}
+	for _, d := range devices {
+		if keep(d) {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// Find returns the device with the serial number, or nil
+func (devices Devices) Find(serial string) *Device {
+	serial = strings.ToUpper(strings.TrimSpace(serial))
+	for _, d := range devices {
+		if d.SerialNumber == serial {
+			return d
+		}
+	}
+	return nil
+}
+
+func validSerial(serial string) bool {
+	return !slices.Contains(invalidSerials, strings.ToLower(strings.TrimSpace(serial)))
+}
+
+// normalizeMAC strips separators from a MAC address, e.g. `AA:BB:CC:DD:EE:FF` -> `aabbccddeeff`
+func normalizeMAC(mac string) string {
+	mac = strings.ToLower(strings.NewReplacer(":", "", "-", "", ".", "", " ", "").Replace(mac))
+	if len(mac) != 12 || strings.Trim(mac, "0") == "" || strings.Trim(mac, "0123456789abcdef") != "" {
+		return ""
+	}
+	return mac
+}
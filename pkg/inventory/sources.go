@@ -0,0 +1,194 @@
+/*
+# Inventory - Sources
+
+This package converts devices from each supported provider into device records:
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/inventory/sources.go
+package inventory
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/automox"
+	"github.com/gemini-oss/rego/pkg/common/normalize"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/jamf"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/snipeit"
+)
+
+// ParseTime parses a provider timestamp, returning the zero time if it is empty or unrecognized (see `normalize.Parse`)
+func ParseTime(value string) time.Time {
+	t, _ := normalize.Parse(value)
+	return t
+}
+
+// FromJamf converts Jamf computers into device records
+// - Requires the `GENERAL`, `HARDWARE`, `OPERATING_SYSTEM` and `USER_AND_LOCATION` sections of the computer inventory
+func FromJamf(computers []*jamf.Computer) []*DeviceRecord {
+	records := []*DeviceRecord{}
+	for _, c := range computers {
+		if c == nil {
+			continue
+		}
+
+		r := &DeviceRecord{
+			Source: Jamf,
+			ID:     fmt.Sprint(c.ID),
+			UDID:   c.UDID,
+			Raw:    c,
+		}
+		if c.General != nil {
+			r.Hostname = c.General.Name
+			r.OS = c.General.Platform
+			r.LastSeen = c.General.LastContactTime.Time
+		}
+		if c.Hardware != nil {
+			r.SerialNumber = c.Hardware.SerialNumber
+			r.MACAddresses = []string{c.Hardware.MacAddress, c.Hardware.AltMacAddress}
+			r.Manufacturer = c.Hardware.Make
+			r.Model = c.Hardware.Model
+		}
+		if c.OperatingSystem != nil {
+			if c.OperatingSystem.Name != "" {
+				r.OS = c.OperatingSystem.Name
+			}
+			r.OSVersion = c.OperatingSystem.Version
+		}
+		if c.UserAndLocation != nil {
+			r.User = c.UserAndLocation.Email
+		}
+
+		records = append(records, r)
+	}
+	return records
+}
+
+// FromOkta converts Okta devices into device records
+// - The user is only populated when the devices were listed with `expand=user`
+func FromOkta(devices okta.Devices) []*DeviceRecord {
+	records := []*DeviceRecord{}
+	for _, d := range devices {
+		if d == nil || d.Profile == nil {
+			continue
+		}
+
+		r := &DeviceRecord{
+			Source:       Okta,
+			ID:           d.ID,
+			SerialNumber: d.Profile.SerialNumber,
+			UDID:         d.Profile.UDID,
+			Hostname:     d.Profile.DisplayName,
+			Manufacturer: d.Profile.Manufacturer,
+			Model:        d.Profile.Model,
+			OS:           d.Profile.Platform,
+			OSVersion:    d.Profile.OSVersion,
+			LastSeen:     d.LastUpdated,
+			Raw:          d,
+		}
+		if d.Embedded != nil && d.Embedded.DeviceUsers != nil {
+			for _, du := range *d.Embedded.DeviceUsers {
+				if du != nil && du.User != nil && du.User.Profile != nil {
+					r.User = du.User.Profile.Email
+					break
+				}
+			}
+		}
+
+		records = append(records, r)
+	}
+	return records
+}
+
+// FromGoogleChromeOS converts Google ChromeOS devices into device records
+func FromGoogleChromeOS(devices []*google.ChromeOSDevice) []*DeviceRecord {
+	records := []*DeviceRecord{}
+	for _, d := range devices {
+		if d == nil {
+			continue
+		}
+
+		records = append(records, &DeviceRecord{
+			Source:       GoogleChromeOS,
+			ID:           d.DeviceId,
+			SerialNumber: d.SerialNumber,
+			MACAddresses: []string{d.MacAddress, d.EthernetMacAddress, d.EthernetMacAddress0},
+			Model:        d.Model,
+			OS:           "ChromeOS",
+			OSVersion:    d.OsVersion,
+			User:         d.AnnotatedUser,
+			LastSeen:     d.LastSync.Time,
+			Raw:          d,
+		})
+	}
+	return records
+}
+
+// FromAutomox converts Automox devices into device records
+func FromAutomox(devices automox.Devices) []*DeviceRecord {
+	records := []*DeviceRecord{}
+	for _, d := range devices {
+		if d == nil {
+			continue
+		}
+
+		r := &DeviceRecord{
+			Source:       Automox,
+			ID:           strconv.Itoa(d.ID),
+			SerialNumber: d.SerialNumber,
+			Hostname:     d.Name,
+			OS:           d.OSName,
+			OSVersion:    d.OSVersion,
+			User:         d.LastLoggedInUser,
+			LastSeen:     d.LastRefreshTime.Time,
+			Raw:          d,
+		}
+		if d.Details != nil {
+			if r.SerialNumber == "" {
+				r.SerialNumber = d.Details.Serial
+			}
+			r.Manufacturer = d.Details.Vendor
+			r.Model = d.Details.Model
+		}
+
+		records = append(records, r)
+	}
+	return records
+}
+
+// FromSnipeIT converts Snipe-IT hardware into device records
+func FromSnipeIT(hardware []*snipeit.Hardware) []*DeviceRecord {
+	records := []*DeviceRecord{}
+	for _, h := range hardware {
+		if h == nil {
+			continue
+		}
+
+		r := &DeviceRecord{
+			Source:       SnipeIT,
+			ID:           strconv.Itoa(h.ID),
+			SerialNumber: h.Serial,
+			Hostname:     h.Name,
+			Raw:          h,
+		}
+		if h.Manufacturer != nil {
+			r.Manufacturer = h.Manufacturer.Name
+		}
+		if h.Model != nil {
+			r.Model = h.Model.Name
+		}
+		if h.AssignedTo != nil {
+			r.User = h.AssignedTo.Email
+		}
+
+		records = append(records, r)
+	}
+	return records
+}
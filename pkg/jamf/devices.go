@@ -167,6 +167,27 @@ func (dc *DeviceClient) ListAllComputers() (*Computers, error) {
 	return computers, nil
 }
 
+/*
+ * # Get Computers assigned to a user
+ * /api/v1/computers-inventory?filter=userAndLocation.email=="{email}"
+ * - https://developer.jamf.com/jamf-pro/reference/get_v1-computers-inventory
+ */
+func (dc *DeviceClient) ListComputersByUser(email string) (*Computers, error) {
+	url := dc.client.BuildURL(ComputersInventory)
+
+	q := &DeviceQuery{
+		Sections: []string{
+			Section.General,
+			Section.UserAndLocation,
+		},
+		Page:     0,
+		PageSize: 100,
+		Filter:   fmt.Sprintf(`userAndLocation.email=="%s"`, email),
+	}
+
+	return doConcurrent[Computers](dc.client, "GET", url, q, nil)
+}
+
 /*
  * # Get Computer Details
  * /api/v1/computers-inventory-detail/{id}
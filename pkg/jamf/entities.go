@@ -6,6 +6,7 @@ import (
 
 	"github.com/gemini-oss/rego/pkg/common/cache"
 	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/normalize"
 	"github.com/gemini-oss/rego/pkg/common/requests"
 )
 
@@ -157,15 +158,15 @@ type Attachment struct {
 }
 
 type Certificate struct {
-	CertificateStatus string `json:"certificateStatus,omitempty"` // Status of the certificate.
-	CommonName        string `json:"commonName,omitempty"`        // Common name of the certificate.
-	ExpirationDate    string `json:"expirationDate,omitempty"`    // Expiration date of the certificate.
-	Identity          bool   `json:"identity,omitempty"`          // Indicates if the certificate is an identity certificate.
-	IssuedDate        string `json:"issuedDate,omitempty"`        // Issued date of the certificate.
-	LifecycleStatus   string `json:"lifecycleStatus,omitempty"`   // Lifecycle status of the certificate.
-	SerialNumber      string `json:"serialNumber,omitempty"`      // Serial number of the certificate.
-	Sha1Fingerprint   string `json:"sha1Fingerprint,omitempty"`   // SHA1 fingerprint of the certificate.
-	SubjectName       string `json:"subjectName,omitempty"`       // Subject name of the certificate.
+	CertificateStatus string         `json:"certificateStatus,omitempty"` // Status of the certificate.
+	CommonName        string         `json:"commonName,omitempty"`        // Common name of the certificate.
+	ExpirationDate    normalize.Time `json:"expirationDate,omitempty"`    // Expiration date of the certificate.
+	Identity          bool           `json:"identity,omitempty"`          // Indicates if the certificate is an identity certificate.
+	IssuedDate        normalize.Time `json:"issuedDate,omitempty"`        // Issued date of the certificate.
+	LifecycleStatus   string         `json:"lifecycleStatus,omitempty"`   // Lifecycle status of the certificate.
+	SerialNumber      string         `json:"serialNumber,omitempty"`      // Serial number of the certificate.
+	Sha1Fingerprint   string         `json:"sha1Fingerprint,omitempty"`   // SHA1 fingerprint of the certificate.
+	SubjectName       string         `json:"subjectName,omitempty"`       // Subject name of the certificate.
 }
 
 // ContentCaching represents content caching information of a computer.
@@ -287,21 +288,21 @@ type General struct {
 	EnrolledViaAutomatedDeviceEnrollment bool                 `json:"enrolledViaAutomatedDeviceEnrollment"` // Indicates if enrolled via automated device enrollment.
 	EnrollmentMethod                     EnrollmentMethod     `json:"enrollmentMethod"`                     // Method of enrollment.
 	ExtensionAttributes                  []ExtensionAttribute `json:"extensionAttributes"`                  // List of extension attributes.
-	InitialEntryDate                     string               `json:"initialEntryDate"`                     // Date of initial entry.
+	InitialEntryDate                     normalize.Time       `json:"initialEntryDate"`                     // Date of initial entry.
 	ItunesStoreAccountActive             bool                 `json:"itunesStoreAccountActive"`             // Indicates if iTunes Store account is active.
 	JamfBinaryVersion                    string               `json:"jamfBinaryVersion"`                    // Version of the Jamf binary.
-	LastContactTime                      string               `json:"lastContactTime"`                      // Time of last contact.
-	LastEnrolledDate                     string               `json:"lastEnrolledDate"`                     // Date of last enrollment.
+	LastContactTime                      normalize.Time       `json:"lastContactTime"`                      // Time of last contact.
+	LastEnrolledDate                     normalize.Time       `json:"lastEnrolledDate"`                     // Date of last enrollment.
 	LastIpAddress                        string               `json:"lastIpAddress"`                        // Last known IP address.
 	LastReportedIp                       string               `json:"lastReportedIp"`                       // Last reported IP address.
-	LastCloudBackupDate                  string               `json:"lastCloudBackupDate"`                  // Date of last cloud backup.
+	LastCloudBackupDate                  normalize.Time       `json:"lastCloudBackupDate"`                  // Date of last cloud backup.
 	ManagementID                         string               `json:"managementId"`                         // Management ID.
 	MDMCapable                           MDMCapable           `json:"mdmCapable"`                           // MDM capability information.
-	MdmProfileExpiration                 string               `json:"mdmProfileExpiration"`                 // Expiration of the MDM profile.
+	MdmProfileExpiration                 normalize.Time       `json:"mdmProfileExpiration"`                 // Expiration of the MDM profile.
 	Name                                 string               `json:"name"`                                 // Name of the computer.
 	Platform                             string               `json:"platform"`                             // Platform of the computer (e.g., Mac).
 	RemoteManagement                     RemoteManagement     `json:"remoteManagement"`                     // Remote management information.
-	ReportDate                           string               `json:"reportDate"`                           // Date of report.
+	ReportDate                           normalize.Time       `json:"reportDate"`                           // Date of report.
 	Site                                 Site                 `json:"site"`                                 // Site information.
 	Supervised                           bool                 `json:"supervised"`                           // Indicates if the device is supervised.
 	UserApprovedMDM                      bool                 `json:"userApprovedMdm"`                      // Indicates if MDM is user-approved.
@@ -442,17 +443,17 @@ type Printer struct {
 type Purchasing struct {
 	AppleCareID         string               `json:"appleCareId"`         // AppleCare ID.
 	ExtensionAttributes []ExtensionAttribute `json:"extensionAttributes"` // List of extension attributes.
-	LeaseDate           string               `json:"leaseDate"`           // Date of the lease.
+	LeaseDate           normalize.Time       `json:"leaseDate"`           // Date of the lease.
 	Leased              bool                 `json:"leased"`              // Indicates if the computer is leased.
 	LifeExpectancy      int                  `json:"lifeExpectancy"`      // Expected life expectancy in years.
-	PoDate              string               `json:"poDate"`              // Purchase order date.
+	PoDate              normalize.Time       `json:"poDate"`              // Purchase order date.
 	PoNumber            string               `json:"poNumber"`            // Purchase order number.
 	PurchasePrice       string               `json:"purchasePrice"`       // Purchase price.
 	Purchased           bool                 `json:"purchased"`           // Indicates if the computer is purchased.
 	PurchasingAccount   string               `json:"purchasingAccount"`   // Account used for purchasing.
 	PurchasingContact   string               `json:"purchasingContact"`   // Contact for purchasing.
 	Vendor              string               `json:"vendor"`              // Vendor from where the computer is purchased.
-	WarrantyDate        string               `json:"warrantyDate"`        // Date of warranty expiration.
+	WarrantyDate        normalize.Time       `json:"warrantyDate"`        // Date of warranty expiration.
 }
 
 // Security represents the security settings of the computer.
@@ -578,6 +579,31 @@ type ManagementResponse struct {
 	UnprocessedUDIDs *UDIDsNotProcessed `json:"udidsNotProcessed,omitempty"` // UDIDs that were not processed, if any.
 }
 
+// MDMCommand represents a request to send an MDM command to one or more devices.
+type MDMCommand struct {
+	ClientData  []*MDMClientData `json:"clientData"`  // The devices to send the command to.
+	CommandData *MDMCommandData  `json:"commandData"` // The command to send.
+}
+
+// MDMClientData identifies a device by its management ID.
+type MDMClientData struct {
+	ManagementID string `json:"managementId"` // Management ID of the device.
+}
+
+// MDMCommandData represents the payload of an MDM command.
+type MDMCommandData struct {
+	CommandType string `json:"commandType"`           // The type of command {DEVICE_LOCK, ERASE_DEVICE, ...}
+	Message     string `json:"message,omitempty"`     // Message displayed on the lock screen.
+	PhoneNumber string `json:"phoneNumber,omitempty"` // Phone number displayed on the lock screen.
+	PIN         string `json:"pin,omitempty"`         // Six-digit PIN required to unlock the device.
+}
+
+// MDMCommandResponse represents a queued MDM command.
+type MDMCommandResponse struct {
+	ID   string `json:"id,omitempty"`   // The UUID of the command.
+	Href string `json:"href,omitempty"` // The link to the command.
+}
+
 // UDIDsNotProcessed represents a list of UDIDs that were not processed.
 type UDIDsNotProcessed struct {
 	UDIDs []string `json:"udids"` // List of UDIDs that were not processed.
@@ -0,0 +1,59 @@
+/*
+# Jamf Interfaces
+
+This package contains the interfaces of each Jamf resource, which the `Client` satisfies. Code depending on an
+interface rather than the `Client` can be unit tested with the mocks of `pkg/jamf/jamfmock`
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC., see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/jamf/interfaces.go
+package jamf
+
+//go:generate go run ../../cmd/mockgen
+
+// ConfigurationProfileService manages macOS configuration profiles
+type ConfigurationProfileService interface {
+	ListAllConfigurationProfiles() (*OSXConfigurationProfiles, error)
+	GetConfigurationProfileDetails(id string) (*OSXConfigurationProfile, error)
+	UpdateConfigurationProfile(id string) (*OSXConfigurationProfile, error)
+}
+
+// DeviceService lists computers, computer groups and mobile devices
+type DeviceService interface {
+	ListAllComputers() (*Computers, error)
+	ListComputersByUser(email string) (*Computers, error)
+	GetComputerDetails(id string) (*Computer, error)
+	ListAllComputerGroups() (*[]GroupMembership, error)
+	ListAllMobileDevices() (*MobileDevices, error)
+}
+
+// ManagementService sends management and MDM commands to devices
+type ManagementService interface {
+	RenewMDMProfile(udids []string) (*ManagementResponse, error)
+	RepairManagementFramework(id string) (string, error)
+	SendMDMCommand(cmd *MDMCommand) (*[]MDMCommandResponse, error)
+	LockComputer(managementID, pin, message string) (*[]MDMCommandResponse, error)
+}
+
+// UserService reads users
+type UserService interface {
+	ListAllUsers() (*Users, error)
+	GetUser(id string) (*Users, error)
+	GetUserByEmail(email string) (*Users, error)
+}
+
+// VersionService reads the version of the server
+type VersionService interface {
+	GetJamfVersion() (string, error)
+}
+
+var (
+	_ ConfigurationProfileService = (*Client)(nil)
+	_ DeviceService               = (*DeviceClient)(nil)
+	_ ManagementService           = (*Client)(nil)
+	_ UserService                 = (*Client)(nil)
+	_ VersionService              = (*Client)(nil)
+)
@@ -14,16 +14,19 @@ This package initializes all the methods for functions which interact with the J
 package jamf
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/gemini-oss/rego/pkg/common/cache"
 	"github.com/gemini-oss/rego/pkg/common/config"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
 	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/pool"
 	"github.com/gemini-oss/rego/pkg/common/requests"
 )
 
@@ -46,6 +49,42 @@ func (c *Client) BuildURL(endpoint string, identifiers ...string) string {
 	return url
 }
 
+/*
+ * # With Context
+ * Returns a copy of the client whose requests are bound to `ctx`
+ * - Cancelling `ctx` aborts the request in flight, and stops its retries
+ */
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cc := *c
+	cc.HTTP = c.HTTP.WithContext(ctx)
+	return &cc
+}
+
+// Close closes the HTTP client (see `requests.Client.Close`), then flushes the cache (see `cache.Cache.Flush`)
+func (c *Client) Close(ctx context.Context) error {
+	return errors.Join(c.HTTP.Close(ctx), c.Cache.Flush())
+}
+
+// Name returns the name of the service, `jamf`
+func (c *Client) Name() string {
+	return "jamf"
+}
+
+// Endpoint returns the base URL of the API the client sends its requests to
+func (c *Client) Endpoint() string {
+	return c.BaseURL
+}
+
+/*
+ * # Check the Health of the Jamf Client
+ * Gets the version of the Jamf Pro server, so it is reachable and its credentials valid
+ * /api/v1/jamf-pro-version
+ */
+func (c *Client) HealthCheck(ctx context.Context) error {
+	_, _, err := c.HTTP.DoRequestContext(ctx, "GET", c.BuildURL(JamfProVersion), nil, nil)
+	return rerrors.WithProvider(err, "jamf")
+}
+
 // BuildClassicURL builds a URL for a given resource and identifiers.
 func (c *Client) BuildClassicURL(endpoint string, identifiers ...string) string {
 	url := fmt.Sprintf(endpoint, c.ClassicURL)
@@ -111,7 +150,7 @@ func GetToken(baseURL string) (*JamfToken, error) {
 		"Content-Type":  requests.JSON,
 	}
 
-	hc := requests.NewClient(nil, headers, nil)
+	hc := requests.NewClient(nil, headers, nil, requests.WithUserAgent("jamf"))
 	_, body, err := hc.DoRequest("POST", url, nil, nil)
 	if err != nil {
 		return nil, err
@@ -170,7 +209,7 @@ func NewClient(verbosity int) *Client {
 	return &Client{
 		BaseURL:    BaseURL,
 		ClassicURL: ClassicURL,
-		HTTP:       requests.NewClient(nil, headers, nil),
+		HTTP:       requests.NewClient(nil, headers, nil, requests.WithUserAgent("jamf"), requests.WithSingleflight()),
 		Log:        log.NewLogger("{jamf}", verbosity),
 		Cache:      cache,
 	}
@@ -189,7 +228,7 @@ func do[T any](c *Client, method string, url string, query interface{}, data int
 	var result T
 	res, body, err := c.HTTP.DoRequest(method, url, query, data)
 	if err != nil {
-		return *new(T), err
+		return *new(T), rerrors.WithProvider(err, "jamf")
 	}
 
 	c.Log.Println("Response Status:", res.Status)
@@ -219,53 +258,28 @@ func doConcurrent[T JamfAPIResponse](c *Client, method string, url string, q *De
 		return &firstPage, nil
 	}
 
-	// Create a channel to collect results from each goroutine
-	resultsCh := make(chan *T, totalPages)
-	errCh := make(chan error, totalPages)
-
-	// Use a buffered channel as a semaphore to limit concurrent requests.
-	sem := make(chan struct{}, 10)
-	var wg sync.WaitGroup
-
-	// Start goroutines for each page
+	pages := []int{}
 	for i := (q.Page + 1); i < totalPages; i++ {
-		wg.Add(1)
-		go func(p int) {
-			// Release one semaphore resource when the goroutine completes
-			defer wg.Done()
-
-			sem <- struct{}{} // acquire one semaphore resource
-
-			// Create a new query with the current page
-			q := *q
-			c.Log.Println("Query:", q)
-			q.Page = p
-
-			result, err := do[T](c, method, url, q, data)
-			if err != nil {
-				errCh <- err
-				return
-			}
-
-			resultsCh <- &result
-			<-sem // release one semaphore resource
-		}(i)
+		pages = append(pages, i)
 	}
 
-	// Wait for all goroutines to complete
-	wg.Wait()
-	close(resultsCh)
-	close(errCh)
+	// Fetch the remaining pages, stopping at the first failure
+	fetched := pool.Map(c.HTTP.Context(), pages, pool.Options{Workers: 10, RateLimiter: c.HTTP.RateLimiter, StopOnError: true}, func(_ context.Context, p int) (T, error) {
+		// Create a new query with the current page
+		q := *q
+		c.Log.Println("Query:", q)
+		q.Page = p
 
-	// Check for errors
-	if len(errCh) > 0 {
-		return nil, <-errCh
+		return do[T](c, method, url, q, data)
+	})
+	if err := pool.FirstError(fetched); err != nil {
+		return nil, err
 	}
 
-	// Combine results from all pages
+	// Combine results from all pages, in order
 	results := firstPage
-	for result := range resultsCh {
-		results.Append(result)
+	for _, result := range fetched {
+		results.Append(&result.Value)
 	}
 
 	return &results, nil
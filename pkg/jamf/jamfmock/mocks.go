@@ -0,0 +1,225 @@
+// Code generated by mockgen from interfaces.go; DO NOT EDIT.
+
+// pkg/jamf/jamfmock/mocks.go
+package jamfmock
+
+import (
+	"sync"
+
+	"github.com/gemini-oss/rego/pkg/jamf"
+)
+
+// Call is a call received by a mock
+type Call struct {
+	Method string        // Name of the method called
+	Args   []interface{} // Arguments of the call
+}
+
+// calls records the calls received by a mock
+type calls struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+func (c *calls) record(method string, args ...interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls received, in order; only those of `method` when set
+func (c *calls) Calls(method ...string) []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	received := []Call{}
+	for _, call := range c.calls {
+		if len(method) == 0 || call.Method == method[0] {
+			received = append(received, call)
+		}
+	}
+	return received
+}
+
+// ConfigurationProfileService is a mock of `jamf.ConfigurationProfileService`; methods without a func return zero values
+type ConfigurationProfileService struct {
+	ListAllConfigurationProfilesFunc   func() (*jamf.OSXConfigurationProfiles, error)
+	GetConfigurationProfileDetailsFunc func(string) (*jamf.OSXConfigurationProfile, error)
+	UpdateConfigurationProfileFunc     func(string) (*jamf.OSXConfigurationProfile, error)
+
+	calls
+}
+
+var _ jamf.ConfigurationProfileService = (*ConfigurationProfileService)(nil)
+
+func (m *ConfigurationProfileService) ListAllConfigurationProfiles() (r0 *jamf.OSXConfigurationProfiles, r1 error) {
+	m.record("ListAllConfigurationProfiles")
+	if m.ListAllConfigurationProfilesFunc != nil {
+		return m.ListAllConfigurationProfilesFunc()
+	}
+	return
+}
+
+func (m *ConfigurationProfileService) GetConfigurationProfileDetails(a0 string) (r0 *jamf.OSXConfigurationProfile, r1 error) {
+	m.record("GetConfigurationProfileDetails", a0)
+	if m.GetConfigurationProfileDetailsFunc != nil {
+		return m.GetConfigurationProfileDetailsFunc(a0)
+	}
+	return
+}
+
+func (m *ConfigurationProfileService) UpdateConfigurationProfile(a0 string) (r0 *jamf.OSXConfigurationProfile, r1 error) {
+	m.record("UpdateConfigurationProfile", a0)
+	if m.UpdateConfigurationProfileFunc != nil {
+		return m.UpdateConfigurationProfileFunc(a0)
+	}
+	return
+}
+
+// DeviceService is a mock of `jamf.DeviceService`; methods without a func return zero values
+type DeviceService struct {
+	ListAllComputersFunc      func() (*jamf.Computers, error)
+	ListComputersByUserFunc   func(string) (*jamf.Computers, error)
+	GetComputerDetailsFunc    func(string) (*jamf.Computer, error)
+	ListAllComputerGroupsFunc func() (*[]jamf.GroupMembership, error)
+	ListAllMobileDevicesFunc  func() (*jamf.MobileDevices, error)
+
+	calls
+}
+
+var _ jamf.DeviceService = (*DeviceService)(nil)
+
+func (m *DeviceService) ListAllComputers() (r0 *jamf.Computers, r1 error) {
+	m.record("ListAllComputers")
+	if m.ListAllComputersFunc != nil {
+		return m.ListAllComputersFunc()
+	}
+	return
+}
+
+func (m *DeviceService) ListComputersByUser(a0 string) (r0 *jamf.Computers, r1 error) {
+	m.record("ListComputersByUser", a0)
+	if m.ListComputersByUserFunc != nil {
+		return m.ListComputersByUserFunc(a0)
+	}
+	return
+}
+
+func (m *DeviceService) GetComputerDetails(a0 string) (r0 *jamf.Computer, r1 error) {
+	m.record("GetComputerDetails", a0)
+	if m.GetComputerDetailsFunc != nil {
+		return m.GetComputerDetailsFunc(a0)
+	}
+	return
+}
+
+func (m *DeviceService) ListAllComputerGroups() (r0 *[]jamf.GroupMembership, r1 error) {
+	m.record("ListAllComputerGroups")
+	if m.ListAllComputerGroupsFunc != nil {
+		return m.ListAllComputerGroupsFunc()
+	}
+	return
+}
+
+func (m *DeviceService) ListAllMobileDevices() (r0 *jamf.MobileDevices, r1 error) {
+	m.record("ListAllMobileDevices")
+	if m.ListAllMobileDevicesFunc != nil {
+		return m.ListAllMobileDevicesFunc()
+	}
+	return
+}
+
+// ManagementService is a mock of `jamf.ManagementService`; methods without a func return zero values
+type ManagementService struct {
+	RenewMDMProfileFunc           func([]string) (*jamf.ManagementResponse, error)
+	RepairManagementFrameworkFunc func(string) (string, error)
+	SendMDMCommandFunc            func(*jamf.MDMCommand) (*[]jamf.MDMCommandResponse, error)
+	LockComputerFunc              func(string, string, string) (*[]jamf.MDMCommandResponse, error)
+
+	calls
+}
+
+var _ jamf.ManagementService = (*ManagementService)(nil)
+
+func (m *ManagementService) RenewMDMProfile(a0 []string) (r0 *jamf.ManagementResponse, r1 error) {
+	m.record("RenewMDMProfile", a0)
+	if m.RenewMDMProfileFunc != nil {
+		return m.RenewMDMProfileFunc(a0)
+	}
+	return
+}
+
+func (m *ManagementService) RepairManagementFramework(a0 string) (r0 string, r1 error) {
+	m.record("RepairManagementFramework", a0)
+	if m.RepairManagementFrameworkFunc != nil {
+		return m.RepairManagementFrameworkFunc(a0)
+	}
+	return
+}
+
+func (m *ManagementService) SendMDMCommand(a0 *jamf.MDMCommand) (r0 *[]jamf.MDMCommandResponse, r1 error) {
+	m.record("SendMDMCommand", a0)
+	if m.SendMDMCommandFunc != nil {
+		return m.SendMDMCommandFunc(a0)
+	}
+	return
+}
+
+func (m *ManagementService) LockComputer(a0 string, a1 string, a2 string) (r0 *[]jamf.MDMCommandResponse, r1 error) {
+	m.record("LockComputer", a0, a1, a2)
+	if m.LockComputerFunc != nil {
+		return m.LockComputerFunc(a0, a1, a2)
+	}
+	return
+}
+
+// UserService is a mock of `jamf.UserService`; methods without a func return zero values
+type UserService struct {
+	ListAllUsersFunc   func() (*jamf.Users, error)
+	GetUserFunc        func(string) (*jamf.Users, error)
+	GetUserByEmailFunc func(string) (*jamf.Users, error)
+
+	calls
+}
+
+var _ jamf.UserService = (*UserService)(nil)
+
+func (m *UserService) ListAllUsers() (r0 *jamf.Users, r1 error) {
+	m.record("ListAllUsers")
+	if m.ListAllUsersFunc != nil {
+		return m.ListAllUsersFunc()
+	}
+	return
+}
+
+func (m *UserService) GetUser(a0 string) (r0 *jamf.Users, r1 error) {
+	m.record("GetUser", a0)
+	if m.GetUserFunc != nil {
+		return m.GetUserFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) GetUserByEmail(a0 string) (r0 *jamf.Users, r1 error) {
+	m.record("GetUserByEmail", a0)
+	if m.GetUserByEmailFunc != nil {
+		return m.GetUserByEmailFunc(a0)
+	}
+	return
+}
+
+// VersionService is a mock of `jamf.VersionService`; methods without a func return zero values
+type VersionService struct {
+	GetJamfVersionFunc func() (string, error)
+
+	calls
+}
+
+var _ jamf.VersionService = (*VersionService)(nil)
+
+func (m *VersionService) GetJamfVersion() (r0 string, r1 error) {
+	m.record("GetJamfVersion")
+	if m.GetJamfVersionFunc != nil {
+		return m.GetJamfVersionFunc()
+	}
+	return
+}
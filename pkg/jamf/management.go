@@ -16,6 +16,8 @@ package jamf
 import (
 	"encoding/json"
 	"fmt"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
 )
 
 var (
@@ -23,6 +25,7 @@ var (
 	V1_MDM              = fmt.Sprintf("%s/mdm", V1)                       // /api/v1/mdm
 	RenewProfile        = fmt.Sprintf("%s/renew-profile", V1_MDM)         // /api/v1/mdm/renew-profile
 	V2_MDM              = fmt.Sprintf("%s/mdm", V2)                       // /api/v2/mdm
+	MDMCommands         = fmt.Sprintf("%s/commands", V2_MDM)              // /api/v2/mdm/commands
 )
 
 /*
@@ -41,7 +44,7 @@ func (c *Client) RenewMDMProfile(udids []string) (*ManagementResponse, error) {
 
 	res, body, err := c.HTTP.DoRequest("POST", url, nil, payload)
 	if err != nil {
-		return nil, err
+		return nil, rerrors.WithProvider(err, "jamf")
 	}
 	c.Log.Println("Response Status:", res.Status)
 	c.Log.Debugf(string(body))
@@ -64,7 +67,7 @@ func (c *Client) RepairManagementFramework(id string) (string, error) {
 
 	res, body, err := c.HTTP.DoRequest("POST", url, nil, nil)
 	if err != nil {
-		return "", err
+		return "", rerrors.WithProvider(err, "jamf")
 	}
 	c.Log.Println("Response Status:", res.Status)
 	c.Log.Debugf(string(body))
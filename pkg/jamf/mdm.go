@@ -18,3 +18,41 @@ package jamf
  * /api/v1/mdm/renew-profile
  * - https://developer.jamf.com/jamf-pro/reference/post_v1-mdm-renew-profile
  */
+
+/*
+ * # Send an MDM Command
+ * /api/v2/mdm/commands
+ * - https://developer.jamf.com/jamf-pro/reference/post_v2-mdm-commands
+ */
+func (c *Client) SendMDMCommand(cmd *MDMCommand) (*[]MDMCommandResponse, error) {
+	url := c.BuildURL(MDMCommands)
+
+	res, err := do[[]MDMCommandResponse](c, "POST", url, nil, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+/*
+ * # Lock a Computer
+ * - `pin` must be six digits and is required to unlock the computer
+ * /api/v2/mdm/commands
+ * - https://developer.jamf.com/jamf-pro/reference/post_v2-mdm-commands
+ */
+func (c *Client) LockComputer(managementID, pin, message string) (*[]MDMCommandResponse, error) {
+	cmd := &MDMCommand{
+		ClientData: []*MDMClientData{
+			{ManagementID: managementID},
+		},
+		CommandData: &MDMCommandData{
+			CommandType: "DEVICE_LOCK",
+			Message:     message,
+			PIN:         pin,
+		},
+	}
+
+	c.Log.Printf("Locking computer %s", managementID)
+	return c.SendMDMCommand(cmd)
+}
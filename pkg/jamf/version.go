@@ -15,6 +15,8 @@ package jamf
 
 import (
 	"fmt"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
 )
 
 var (
@@ -31,7 +33,7 @@ func (c *Client) GetJamfVersion() (string, error) {
 
 	res, body, err := c.HTTP.DoRequest("GET", url, nil, nil)
 	if err != nil {
-		return "", err
+		return "", rerrors.WithProvider(err, "jamf")
 	}
 	c.Log.Println("Response Status:", res.Status)
 	c.Log.Debugf("Jamf Version Response: %s", string(body))
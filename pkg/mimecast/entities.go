@@ -0,0 +1,311 @@
+/*
+# Mimecast - Entities [Structs]
+
+This package contains many structs for handling responses from the Mimecast API 2.0:
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/mimecast/entities.go
+package mimecast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+// ### Mimecast Client Entities
+// ---------------------------------------------------------------------
+type Client struct {
+	BaseURL string           // BaseURL is the base URL for Mimecast API requests.
+	HTTP    *requests.Client // HTTP is the client used to make HTTP requests.
+	Log     *log.Logger      // Log is the logger used to log messages.
+	Cache   *cache.Cache     // Cache is the cache used to store responses from the Mimecast API.
+}
+
+type TokenRequest struct {
+	ClientID     string `url:"client_id"`     // The client ID of the API 2.0 application.
+	ClientSecret string `url:"client_secret"` // The client secret of the API 2.0 application.
+	GrantType    string `url:"grant_type"`    // Always `client_credentials`.
+}
+
+type Token struct {
+	AccessToken string `json:"access_token,omitempty"` // The bearer token.
+	ExpiresIn   int    `json:"expires_in,omitempty"`   // Lifetime of the token in seconds.
+	TokenType   string `json:"token_type,omitempty"`   // Always `Bearer`.
+}
+
+// Request is the envelope every Mimecast request body is wrapped in.
+type Request[D any] struct {
+	Meta *RequestMeta `json:"meta,omitempty"` // Pagination settings for the request.
+	Data []*D         `json:"data"`           // The request payload(s).
+}
+
+type RequestMeta struct {
+	Pagination *Pagination `json:"pagination,omitempty"` // Pagination settings for the request.
+}
+
+type Pagination struct {
+	PageSize   int    `json:"pageSize,omitempty"`   // The number of results to request/returned.
+	PageToken  string `json:"pageToken,omitempty"`  // Request only: the token of the page to fetch.
+	Next       string `json:"next,omitempty"`       // Response only: the token of the next page.
+	Previous   string `json:"previous,omitempty"`   // Response only: the token of the previous page.
+	TotalCount int    `json:"totalCount,omitempty"` // Response only: the total number of results.
+}
+
+// Response is the envelope every Mimecast response body is wrapped in.
+type Response[D any] struct {
+	Meta struct {
+		Status     int         `json:"status,omitempty"`     // The HTTP status of the request.
+		Pagination *Pagination `json:"pagination,omitempty"` // Pagination details of the response.
+	} `json:"meta,omitempty"`
+	Data []*D      `json:"data,omitempty"` // The response payload(s).
+	Fail []*Failed `json:"fail,omitempty"` // Items which could not be processed.
+}
+
+func (r Response[D]) Elements() []*D {
+	return r.Data
+}
+
+func (r Response[D]) NextToken() string {
+	if r.Meta.Pagination == nil {
+		return ""
+	}
+	return r.Meta.Pagination.Next
+}
+
+// Failed returns an error describing every failure reported in the response, if any.
+func (r Response[D]) Failed() error {
+	if len(r.Fail) == 0 {
+		return nil
+	}
+
+	msgs := []string{}
+	for _, f := range r.Fail {
+		for _, e := range f.Errors {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", e.Code, e.Message))
+		}
+	}
+	return fmt.Errorf("mimecast request failed: %s", strings.Join(msgs, "; "))
+}
+
+type Failed struct {
+	Key    map[string]interface{} `json:"key,omitempty"`    // The request item which failed.
+	Errors []*Error               `json:"errors,omitempty"` // The errors for the item.
+}
+
+type Error struct {
+	Code      string `json:"code,omitempty"`      // The error code.
+	Message   string `json:"message,omitempty"`   // A description of the error.
+	Retryable bool   `json:"retryable,omitempty"` // Whether the request can be retried.
+}
+
+// PaginatedResponse is an interface for Mimecast API responses involving pagination
+type PaginatedResponse[E any] interface {
+	Elements() []*E
+	NextToken() string
+	Failed() error
+}
+
+// EmailAddress is the address/display-name pair used throughout the Mimecast API.
+type EmailAddress struct {
+	DisplayableName string `json:"displayableName,omitempty"` // The display name of the address.
+	EmailAddress    string `json:"emailAddress,omitempty"`    // The email address.
+}
+
+// END OF MIMECAST CLIENT ENTITIES
+//---------------------------------------------------------------------
+
+// ### Mimecast Sender Structs
+// ---------------------------------------------------------------------
+type ManagedSenderRequest struct {
+	Sender string `json:"sender"` // The sender email address.
+	To     string `json:"to"`     // The recipient email address the entry applies to.
+	Action string `json:"action"` // The action to take {permit, block}
+}
+
+type ManagedSender struct {
+	ID     string `json:"id,omitempty"`     // The ID of the managed sender entry.
+	Sender string `json:"sender,omitempty"` // The sender email address.
+	To     string `json:"to,omitempty"`     // The recipient email address.
+	Type   string `json:"type,omitempty"`   // The type of entry {permit, block}
+}
+
+type BlockedSenderQuery struct {
+	ID string `json:"id,omitempty"` // The ID of a specific policy to return.
+}
+
+type BlockedSenderPolicies []*BlockedSenderPolicy
+
+type BlockedSenderPolicy struct {
+	ID     string  `json:"id,omitempty"`     // The ID of the policy.
+	Option string  `json:"option,omitempty"` // The policy option {no_action, block_sender}
+	Policy *Policy `json:"policy,omitempty"` // The policy definition.
+}
+
+type Policy struct {
+	Bidirectional bool          `json:"bidirectional,omitempty"` // Whether the policy applies in both directions.
+	Comment       string        `json:"comment,omitempty"`       // A comment for the policy.
+	Description   string        `json:"description,omitempty"`   // The description of the policy.
+	Enabled       bool          `json:"enabled,omitempty"`       // Whether the policy is enabled.
+	From          *PolicyTarget `json:"from,omitempty"`          // The sender the policy applies to.
+	FromDate      string        `json:"fromDate,omitempty"`      // The date the policy becomes active.
+	FromPart      string        `json:"fromPart,omitempty"`      // The part of the message to match the sender against {envelope_from, header_from, both}
+	Override      bool          `json:"override,omitempty"`      // Whether the policy overrides other policies.
+	To            *PolicyTarget `json:"to,omitempty"`            // The recipient the policy applies to.
+	ToDate        string        `json:"toDate,omitempty"`        // The date the policy expires.
+	ToEternal     bool          `json:"toEternal,omitempty"`     // Whether the policy never expires.
+	FromEternal   bool          `json:"fromEternal,omitempty"`   // Whether the policy has always been active.
+}
+
+type PolicyTarget struct {
+	Type         string `json:"type,omitempty"`         // The type of target {everyone, internal_addresses, external_addresses, email_domain, individual_email_address, ...}
+	EmailAddress string `json:"emailAddress,omitempty"` // The email address, when type is `individual_email_address`.
+	EmailDomain  string `json:"emailDomain,omitempty"`  // The domain, when type is `email_domain`.
+	GroupID      string `json:"groupId,omitempty"`      // The group ID, when type is `address_attribute_value` or `profile_group`.
+}
+
+type PolicyID struct {
+	ID string `json:"id"` // The ID of the policy.
+}
+
+// END OF MIMECAST SENDER STRUCTS
+//---------------------------------------------------------------------
+
+// ### Mimecast Held Message Structs
+// ---------------------------------------------------------------------
+type HeldMessageQuery struct {
+	Admin    bool      `json:"admin"`              // Return messages held for all users rather than only the caller.
+	Start    string    `json:"start,omitempty"`    // The earliest date to return, in ISO 8601 format.
+	End      string    `json:"end,omitempty"`      // The latest date to return, in ISO 8601 format.
+	SearchBy *SearchBy `json:"searchBy,omitempty"` // Filter the results by a field.
+}
+
+type SearchBy struct {
+	FieldName string `json:"fieldName,omitempty"` // The field to search {all, subject, sender, recipient, reason_code}
+	Value     string `json:"value,omitempty"`     // The value to search for.
+}
+
+type HeldMessages []*HeldMessage
+
+type HeldMessage struct {
+	DateReceived   string        `json:"dateReceived,omitempty"`   // The date the message was received.
+	From           *EmailAddress `json:"from,omitempty"`           // The envelope sender of the message.
+	FromHeader     *EmailAddress `json:"fromHeader,omitempty"`     // The header sender of the message.
+	HasAttachments bool          `json:"hasAttachments,omitempty"` // Whether the message has attachments.
+	ID             string        `json:"id,omitempty"`             // The ID of the held message.
+	PolicyInfo     string        `json:"policyInfo,omitempty"`     // The policy which caused the message to be held.
+	Reason         string        `json:"reason,omitempty"`         // The reason the message was held.
+	ReasonCode     string        `json:"reasonCode,omitempty"`     // The reason code.
+	ReasonID       string        `json:"reasonId,omitempty"`       // The reason ID.
+	Route          string        `json:"route,omitempty"`          // The route of the message {inbound, outbound, internal}
+	Size           int           `json:"size,omitempty"`           // The size of the message in bytes.
+	Subject        string        `json:"subject,omitempty"`        // The subject of the message.
+	To             *EmailAddress `json:"to,omitempty"`             // The recipient of the message.
+}
+
+type HoldRelease struct {
+	ID      string `json:"id"`                // The ID of the held message.
+	Release bool   `json:"release,omitempty"` // Response only: whether the message was released.
+}
+
+type HoldReject struct {
+	IDs        []string `json:"ids"`                  // The IDs of the held messages.
+	Message    string   `json:"message,omitempty"`    // A message to return to the sender.
+	ReasonType string   `json:"reasonType,omitempty"` // The reason for rejection {MESSAGE_CONTAINS_UNDESIRABLE_CONTENT, MESSAGE_CONTAINS_CONFIDENTIAL_INFORMATION, REVIEWER_DISAPPROVES_OF_CONTENT, INAPPROPRIATE_COMMUNICATION, MESSAGE_GOES_AGAINST_EMAIL_POLICIES}
+	Notify     bool     `json:"notify,omitempty"`     // Whether to notify the sender.
+}
+
+type HoldRejectResult struct {
+	ID     string `json:"id,omitempty"`     // The ID of the held message.
+	Reject bool   `json:"reject,omitempty"` // Whether the message was rejected.
+}
+
+// END OF MIMECAST HELD MESSAGE STRUCTS
+//---------------------------------------------------------------------
+
+// ### Mimecast TTP Structs
+// ---------------------------------------------------------------------
+type TTPLogQuery struct {
+	From       string `json:"from,omitempty"`       // The earliest date to return, in ISO 8601 format.
+	To         string `json:"to,omitempty"`         // The latest date to return, in ISO 8601 format.
+	Route      string `json:"route,omitempty"`      // Filter by route {all, inbound, outbound, internal}
+	ScanResult string `json:"scanResult,omitempty"` // Filter by scan result {all, clean, malicious} (URL logs)
+	Result     string `json:"result,omitempty"`     // Filter by result {all, safe, malicious, timeout, error, unsafe} (attachment logs)
+}
+
+type ClickLogList struct {
+	Response[ClickLogs]
+}
+
+// Elements flattens the click logs of every data item in the page.
+func (l ClickLogList) Elements() []*ClickLog {
+	logs := []*ClickLog{}
+	for _, d := range l.Data {
+		logs = append(logs, d.ClickLogs...)
+	}
+	return logs
+}
+
+type ClickLogs struct {
+	ClickLogs []*ClickLog `json:"clickLogs,omitempty"` // The URL click logs.
+}
+
+type ClickLog struct {
+	Action              string `json:"action,omitempty"`              // The action taken {allow, block, warn}
+	AdminOverride       string `json:"adminOverride,omitempty"`       // The admin override applied, if any.
+	Category            string `json:"category,omitempty"`            // The category of the URL.
+	Date                string `json:"date,omitempty"`                // The date of the click.
+	MessageID           string `json:"messageId,omitempty"`           // The Message-ID of the message containing the URL.
+	Route               string `json:"route,omitempty"`               // The route of the message.
+	ScanResult          string `json:"scanResult,omitempty"`          // The scan result {clean, malicious, unknown}
+	SendingIP           string `json:"sendingIp,omitempty"`           // The IP address of the sender.
+	Subject             string `json:"subject,omitempty"`             // The subject of the message.
+	TTPDefinition       string `json:"ttpDefinition,omitempty"`       // The TTP URL Protect definition applied.
+	URL                 string `json:"url,omitempty"`                 // The URL clicked.
+	UserAwarenessAction string `json:"userAwarenessAction,omitempty"` // The user awareness action taken.
+	UserEmail           string `json:"userEmailAddress,omitempty"`    // The user who clicked the URL.
+	UserOverride        string `json:"userOverride,omitempty"`        // The user override applied, if any.
+}
+
+type AttachmentLogList struct {
+	Response[AttachmentLogs]
+}
+
+// Elements flattens the attachment logs of every data item in the page.
+func (l AttachmentLogList) Elements() []*AttachmentLog {
+	logs := []*AttachmentLog{}
+	for _, d := range l.Data {
+		logs = append(logs, d.AttachmentLogs...)
+	}
+	return logs
+}
+
+type AttachmentLogs struct {
+	AttachmentLogs []*AttachmentLog `json:"attachmentLogs,omitempty"` // The attachment protect logs.
+}
+
+type AttachmentLog struct {
+	ActionTriggered  string `json:"actionTriggered,omitempty"`  // The action triggered by the scan.
+	Date             string `json:"date,omitempty"`             // The date of the scan.
+	Definition       string `json:"definition,omitempty"`       // The TTP Attachment Protect definition applied.
+	Details          string `json:"details,omitempty"`          // Details of the scan result.
+	FileHash         string `json:"fileHash,omitempty"`         // The hash of the attachment.
+	FileName         string `json:"fileName,omitempty"`         // The name of the attachment.
+	FileType         string `json:"fileType,omitempty"`         // The type of the attachment.
+	MessageID        string `json:"messageId,omitempty"`        // The Message-ID of the message containing the attachment.
+	RecipientAddress string `json:"recipientAddress,omitempty"` // The recipient of the message.
+	Result           string `json:"result,omitempty"`           // The scan result {safe, malicious, timeout, error, unsafe}
+	Route            string `json:"route,omitempty"`            // The route of the message.
+	SenderAddress    string `json:"senderAddress,omitempty"`    // The sender of the message.
+	Subject          string `json:"subject,omitempty"`          // The subject of the message.
+}
+
+// END OF MIMECAST TTP STRUCTS
+//---------------------------------------------------------------------
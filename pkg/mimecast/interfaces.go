@@ -0,0 +1,44 @@
+/*
+# Mimecast Interfaces
+
+This package contains the interfaces of each Mimecast resource, which the resource clients satisfy. Code depending on an
+interface rather than the clients can be unit tested with the mocks of `pkg/mimecast/mimecastmock`
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/mimecast/interfaces.go
+package mimecast
+
+//go:generate go run ../../cmd/mockgen
+
+// MessageService manages held messages
+type MessageService interface {
+	ListHeldMessages(q *HeldMessageQuery) (*HeldMessages, error)
+	ReleaseHeldMessages(ids ...string) error
+	RejectHeldMessages(reject *HoldReject) error
+}
+
+// SenderService manages permitted and blocked senders
+type SenderService interface {
+	PermitOrBlockSender(sender, to, action string) (*ManagedSender, error)
+	PermitSender(sender, to string) (*ManagedSender, error)
+	BlockSender(sender, to string) (*ManagedSender, error)
+	ListBlockedSenderPolicies() (*BlockedSenderPolicies, error)
+	CreateBlockedSenderPolicy(policy *BlockedSenderPolicy) (*BlockedSenderPolicy, error)
+	DeleteBlockedSenderPolicy(id string) error
+}
+
+// TTPService reads the logs of Targeted Threat Protection
+type TTPService interface {
+	URLLogs(q *TTPLogQuery) (*[]*ClickLog, error)
+	AttachmentLogs(q *TTPLogQuery) (*[]*AttachmentLog, error)
+}
+
+var (
+	_ MessageService = (*MessageClient)(nil)
+	_ SenderService  = (*SenderClient)(nil)
+	_ TTPService     = (*TTPClient)(nil)
+)
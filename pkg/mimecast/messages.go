@@ -0,0 +1,112 @@
+/*
+# Mimecast - Held Messages
+
+This package contains all the methods to interact with Mimecast held messages:
+https://developer.services.mimecast.com/docs/emailsecurity/1/overview
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/mimecast/messages.go
+package mimecast
+
+import (
+	"fmt"
+)
+
+// MessageClient for chaining methods
+type MessageClient struct {
+	*Client
+}
+
+// Entry point for held message operations
+func (c *Client) Messages() *MessageClient {
+	return &MessageClient{
+		Client: c,
+	}
+}
+
+/*
+ * # List held messages
+ * - When `q` is nil, all messages held for the organization are returned
+ * /api/gateway/get-hold-message-list
+ * - https://developer.services.mimecast.com/docs/emailsecurity/1/routes/api/gateway/get-hold-message-list/post
+ */
+func (c *MessageClient) ListHeldMessages(q *HeldMessageQuery) (*HeldMessages, error) {
+	url := c.BuildURL(MimecastHeldMessages)
+
+	if q == nil {
+		q = &HeldMessageQuery{Admin: true}
+	}
+
+	messages, err := doPaginated[Response[HeldMessage]](c.Client, url, q)
+	if err != nil {
+		return nil, err
+	}
+
+	result := HeldMessages(*messages)
+	return &result, nil
+}
+
+/*
+ * # Release one or more held messages to their recipients
+ * /api/gateway/hold-release
+ * - https://developer.services.mimecast.com/docs/emailsecurity/1/routes/api/gateway/hold-release/post
+ */
+func (c *MessageClient) ReleaseHeldMessages(ids ...string) error {
+	url := c.BuildURL(MimecastHoldRelease)
+
+	payload := &Request[HoldRelease]{}
+	for _, id := range ids {
+		payload.Data = append(payload.Data, &HoldRelease{ID: id})
+	}
+
+	c.Log.Printf("Releasing %d held message(s)", len(ids))
+	res, err := do[Response[HoldRelease]](c.Client, url, payload)
+	if err != nil {
+		return err
+	}
+	if err := res.Failed(); err != nil {
+		return err
+	}
+
+	for _, r := range res.Data {
+		if !r.Release {
+			return fmt.Errorf("held message %s was not released", r.ID)
+		}
+	}
+
+	return nil
+}
+
+/*
+ * # Reject one or more held messages
+ * /api/gateway/hold-reject
+ * - https://developer.services.mimecast.com/docs/emailsecurity/1/routes/api/gateway/hold-reject/post
+ */
+func (c *MessageClient) RejectHeldMessages(reject *HoldReject) error {
+	url := c.BuildURL(MimecastHoldReject)
+
+	payload := &Request[HoldReject]{
+		Data: []*HoldReject{reject},
+	}
+
+	c.Log.Printf("Rejecting %d held message(s)", len(reject.IDs))
+	res, err := do[Response[HoldRejectResult]](c.Client, url, payload)
+	if err != nil {
+		return err
+	}
+	if err := res.Failed(); err != nil {
+		return err
+	}
+
+	for _, r := range res.Data {
+		if !r.Reject {
+			return fmt.Errorf("held message %s was not rejected", r.ID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,296 @@
+/*
+# Mimecast
+
+This package initializes all the methods for functions which interact with the Mimecast API 2.0:
+https://developer.services.mimecast.com/
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/mimecast/mimecast.go
+package mimecast
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/config"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/ratelimit"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+)
+
+const (
+	BaseURL = "https://api.services.mimecast.com" // https://developer.services.mimecast.com/api-overview
+)
+
+const (
+	MimecastAccessToken         = "%s/oauth/token"                              // https://developer.services.mimecast.com/api-overview#authentication
+	MimecastBlockedSenderCreate = "%s/api/policy/blockedsenders/create-policy"  // https://developer.services.mimecast.com/docs/securitypolicymanagement/1/routes/api/policy/blockedsenders/create-policy/post
+	MimecastBlockedSenderDelete = "%s/api/policy/blockedsenders/delete-policy"  // https://developer.services.mimecast.com/docs/securitypolicymanagement/1/routes/api/policy/blockedsenders/delete-policy/post
+	MimecastBlockedSenderPolicy = "%s/api/policy/blockedsenders/get-policy"     // https://developer.services.mimecast.com/docs/securitypolicymanagement/1/routes/api/policy/blockedsenders/get-policy/post
+	MimecastHeldMessages        = "%s/api/gateway/get-hold-message-list"        // https://developer.services.mimecast.com/docs/emailsecurity/1/routes/api/gateway/get-hold-message-list/post
+	MimecastHoldRelease         = "%s/api/gateway/hold-release"                 // https://developer.services.mimecast.com/docs/emailsecurity/1/routes/api/gateway/hold-release/post
+	MimecastHoldReject          = "%s/api/gateway/hold-reject"                  // https://developer.services.mimecast.com/docs/emailsecurity/1/routes/api/gateway/hold-reject/post
+	MimecastManagedSender       = "%s/api/managedsender/permit-or-block-sender" // https://developer.services.mimecast.com/docs/emailsecurity/1/routes/api/managedsender/permit-or-block-sender/post
+	MimecastTTPAttachmentLogs   = "%s/api/ttp/attachment/get-logs"              // https://developer.services.mimecast.com/docs/threatsintel/1/routes/api/ttp/attachment/get-logs/post
+	MimecastTTPURLLogs          = "%s/api/ttp/url/get-logs"                     // https://developer.services.mimecast.com/docs/threatsintel/1/routes/api/ttp/url/get-logs/post
+)
+
+// BuildURL builds a URL for a given resource and identifiers.
+func (c *Client) BuildURL(endpoint string, identifiers ...string) string {
+	url := fmt.Sprintf(endpoint, c.BaseURL)
+	for _, id := range identifiers {
+		url = fmt.Sprintf("%s/%s", url, id)
+	}
+	c.Log.Debug("url:", url)
+	return url
+}
+
+// Close closes the HTTP client (see `requests.Client.Close`), then flushes the cache (see `cache.Cache.Flush`)
+func (c *Client) Close(ctx context.Context) error {
+	return errors.Join(c.HTTP.Close(ctx), c.Cache.Flush())
+}
+
+// Name returns the name of the service, `mimecast`
+func (c *Client) Name() string {
+	return "mimecast"
+}
+
+// Endpoint returns the base URL of the API the client sends its requests to
+func (c *Client) Endpoint() string {
+	return c.BaseURL
+}
+
+/*
+ * # Check the Health of the Mimecast Client
+ * Lists a held message, so the API is reachable and the application authorized
+ * /api/gateway/get-hold-message-list
+ */
+func (c *Client) HealthCheck(ctx context.Context) error {
+	req := &Request[HeldMessageQuery]{
+		Meta: &RequestMeta{Pagination: &Pagination{PageSize: 1}},
+		Data: []*HeldMessageQuery{{Admin: true}},
+	}
+	_, body, err := c.HTTP.DoRequestContext(ctx, "POST", c.BuildURL(MimecastHeldMessages), nil, req)
+	if err != nil {
+		return rerrors.WithProvider(err, "mimecast")
+	}
+
+	var result Response[HeldMessage]
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("unmarshalling held messages: %w", err)
+	}
+	return result.Failed()
+}
+
+// UseCache() enables caching for the next method call.
+func (c *Client) UseCache() *Client {
+	c.Cache.Enabled = true
+	return c
+}
+
+/*
+ * SetCache stores a Mimecast API response in the cache
+ */
+func (c *Client) SetCache(key string, value interface{}, duration time.Duration) {
+	// Convert value to a byte slice and cache it
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.Log.Error("Error marshalling cache data:", err)
+		return
+	}
+	c.Cache.Set(key, data, duration)
+}
+
+/*
+ * GetCache retrieves a Mimecast API response from the cache
+ */
+func (c *Client) GetCache(key string, target interface{}) bool {
+	data, found := c.Cache.Get(key)
+	if !found || !c.Cache.Enabled {
+		return false
+	}
+
+	err := json.Unmarshal(data, target)
+	if err != nil {
+		c.Log.Error("Error unmarshalling cache data:", err)
+		return false
+	}
+	return true
+}
+
+/*
+ * # Create a new Mimecast API 2.0 access token using client credentials
+ * /oauth/token
+ * - https://developer.services.mimecast.com/api-overview#authentication
+ */
+func GetToken(baseURL, clientID, clientSecret string) (*Token, error) {
+	url := fmt.Sprintf(MimecastAccessToken, baseURL)
+
+	headers := requests.Headers{
+		"Accept":       requests.JSON,
+		"Content-Type": requests.FormURLEncoded,
+	}
+
+	hc := requests.NewClient(nil, headers, nil, requests.WithUserAgent("mimecast"))
+	hc.BodyType = requests.FormURLEncoded
+
+	payload := TokenRequest{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		GrantType:    "client_credentials",
+	}
+
+	_, body, err := hc.DoRequest("POST", url, nil, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &Token{}
+	err = json.Unmarshal(body, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+/*
+  - # Generate Mimecast Client
+  - @param verbosity int
+  - @return *Client
+  - Example:
+
+```go
+
+	m := mimecast.NewClient(log.DEBUG)
+
+```
+*/
+func NewClient(verbosity int) *Client {
+	log := log.NewLogger("{mimecast}", verbosity)
+
+	url := config.GetEnv("MIMECAST_BASE_URL")
+	if len(url) == 0 {
+		url = BaseURL
+	}
+	url = strings.TrimSuffix(url, "/")
+
+	clientID := config.GetEnv("MIMECAST_CLIENT_ID")
+	if len(clientID) == 0 {
+		log.Fatal("MIMECAST_CLIENT_ID is not set")
+	}
+
+	clientSecret := config.GetEnv("MIMECAST_CLIENT_SECRET")
+	if len(clientSecret) == 0 {
+		log.Fatal("MIMECAST_CLIENT_SECRET is not set")
+	}
+
+	token, err := GetToken(url, clientID, clientSecret)
+	if err != nil {
+		log.Fatal(fmt.Sprintf("Failed to generate Mimecast token: %v", err))
+	}
+
+	headers := requests.Headers{
+		"Authorization": "Bearer " + token.AccessToken,
+		"Accept":        requests.JSON,
+		"Content-Type":  requests.JSON,
+	}
+
+	encryptionKey := []byte(config.GetEnv("REGO_ENCRYPTION_KEY"))
+	if len(encryptionKey) == 0 {
+		log.Fatal("REGO_ENCRYPTION_KEY is not set")
+	}
+
+	cache, err := cache.NewCache(encryptionKey, "rego_cache_mimecast.gob", 1000000)
+	if err != nil {
+		panic(err)
+	}
+
+	// https://developer.services.mimecast.com/api-overview#rate-limiting
+	rl := ratelimit.NewRateLimiter(50, 1*time.Minute)
+	rl.Log.Verbosity = verbosity
+
+	httpClient := requests.NewClient(nil, headers, rl, requests.WithUserAgent("mimecast"))
+	httpClient.BodyType = requests.JSON
+	httpClient.IsMutation = isMutation
+
+	return &Client{
+		BaseURL: url,
+		HTTP:    httpClient,
+		Log:     log,
+		Cache:   cache,
+	}
+}
+
+/*
+ * Perform a generic request to the Mimecast API
+ * - Every Mimecast endpoint is a POST which wraps its payload in `data` and may report per-item failures in `fail`
+ */
+func do[T any](c *Client, url string, data interface{}) (T, error) {
+	var result T
+	res, body, err := c.HTTP.DoRequest("POST", url, nil, data)
+	if err != nil {
+		return *new(T), rerrors.WithProvider(err, "mimecast")
+	}
+
+	c.Log.Println("Response Status:", res.Status)
+	c.Log.Debug("Response Body:", string(body))
+
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return *new(T), fmt.Errorf("unmarshalling error: %w", err)
+	}
+
+	return result, nil
+}
+
+/*
+ * Generically perform a paginated request to the Mimecast API
+ * - Mimecast paginates with `meta.pagination.pageToken` in the request and `meta.pagination.next` in the response
+ */
+func doPaginated[T PaginatedResponse[E], E any, D any](c *Client, url string, data *D) (*[]*E, error) {
+	results := make([]*E, 0)
+
+	req := &Request[D]{
+		Meta: &RequestMeta{
+			Pagination: &Pagination{PageSize: 500},
+		},
+		Data: []*D{data},
+	}
+
+	for {
+		p, err := do[T](c, url, req)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.Failed(); err != nil {
+			return nil, err
+		}
+
+		results = append(results, p.Elements()...)
+
+		next := p.NextToken()
+		if next == "" {
+			break
+		}
+		req.Meta.Pagination.PageToken = next
+	}
+
+	return &results, nil
+}
+
+// isMutation reports whether a request changes state; every Mimecast endpoint is a POST, so reads are identified by their path
+func isMutation(method, url string) bool {
+	path := url[strings.LastIndex(url, "/")+1:]
+	return method != "GET" && !strings.HasPrefix(path, "get-")
+}
@@ -0,0 +1,164 @@
+// Code generated by mockgen from interfaces.go; DO NOT EDIT.
+
+// pkg/mimecast/mimecastmock/mocks.go
+package mimecastmock
+
+import (
+	"sync"
+
+	"github.com/gemini-oss/rego/pkg/mimecast"
+)
+
+// Call is a call received by a mock
+type Call struct {
+	Method string        // Name of the method called
+	Args   []interface{} // Arguments of the call
+}
+
+// calls records the calls received by a mock
+type calls struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+func (c *calls) record(method string, args ...interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls received, in order; only those of `method` when set
+func (c *calls) Calls(method ...string) []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	received := []Call{}
+	for _, call := range c.calls {
+		if len(method) == 0 || call.Method == method[0] {
+			received = append(received, call)
+		}
+	}
+	return received
+}
+
+// MessageService is a mock of `mimecast.MessageService`; methods without a func return zero values
+type MessageService struct {
+	ListHeldMessagesFunc    func(*mimecast.HeldMessageQuery) (*mimecast.HeldMessages, error)
+	ReleaseHeldMessagesFunc func(...string) error
+	RejectHeldMessagesFunc  func(*mimecast.HoldReject) error
+
+	calls
+}
+
+var _ mimecast.MessageService = (*MessageService)(nil)
+
+func (m *MessageService) ListHeldMessages(a0 *mimecast.HeldMessageQuery) (r0 *mimecast.HeldMessages, r1 error) {
+	m.record("ListHeldMessages", a0)
+	if m.ListHeldMessagesFunc != nil {
+		return m.ListHeldMessagesFunc(a0)
+	}
+	return
+}
+
+func (m *MessageService) ReleaseHeldMessages(a0 ...string) (r0 error) {
+	m.record("ReleaseHeldMessages", a0)
+	if m.ReleaseHeldMessagesFunc != nil {
+		return m.ReleaseHeldMessagesFunc(a0...)
+	}
+	return
+}
+
+func (m *MessageService) RejectHeldMessages(a0 *mimecast.HoldReject) (r0 error) {
+	m.record("RejectHeldMessages", a0)
+	if m.RejectHeldMessagesFunc != nil {
+		return m.RejectHeldMessagesFunc(a0)
+	}
+	return
+}
+
+// SenderService is a mock of `mimecast.SenderService`; methods without a func return zero values
+type SenderService struct {
+	PermitOrBlockSenderFunc       func(string, string, string) (*mimecast.ManagedSender, error)
+	PermitSenderFunc              func(string, string) (*mimecast.ManagedSender, error)
+	BlockSenderFunc               func(string, string) (*mimecast.ManagedSender, error)
+	ListBlockedSenderPoliciesFunc func() (*mimecast.BlockedSenderPolicies, error)
+	CreateBlockedSenderPolicyFunc func(*mimecast.BlockedSenderPolicy) (*mimecast.BlockedSenderPolicy, error)
+	DeleteBlockedSenderPolicyFunc func(string) error
+
+	calls
+}
+
+var _ mimecast.SenderService = (*SenderService)(nil)
+
+func (m *SenderService) PermitOrBlockSender(a0 string, a1 string, a2 string) (r0 *mimecast.ManagedSender, r1 error) {
+	m.record("PermitOrBlockSender", a0, a1, a2)
+	if m.PermitOrBlockSenderFunc != nil {
+		return m.PermitOrBlockSenderFunc(a0, a1, a2)
+	}
+	return
+}
+
+func (m *SenderService) PermitSender(a0 string, a1 string) (r0 *mimecast.ManagedSender, r1 error) {
+	m.record("PermitSender", a0, a1)
+	if m.PermitSenderFunc != nil {
+		return m.PermitSenderFunc(a0, a1)
+	}
+	return
+}
+
+func (m *SenderService) BlockSender(a0 string, a1 string) (r0 *mimecast.ManagedSender, r1 error) {
+	m.record("BlockSender", a0, a1)
+	if m.BlockSenderFunc != nil {
+		return m.BlockSenderFunc(a0, a1)
+	}
+	return
+}
+
+func (m *SenderService) ListBlockedSenderPolicies() (r0 *mimecast.BlockedSenderPolicies, r1 error) {
+	m.record("ListBlockedSenderPolicies")
+	if m.ListBlockedSenderPoliciesFunc != nil {
+		return m.ListBlockedSenderPoliciesFunc()
+	}
+	return
+}
+
+func (m *SenderService) CreateBlockedSenderPolicy(a0 *mimecast.BlockedSenderPolicy) (r0 *mimecast.BlockedSenderPolicy, r1 error) {
+	m.record("CreateBlockedSenderPolicy", a0)
+	if m.CreateBlockedSenderPolicyFunc != nil {
+		return m.CreateBlockedSenderPolicyFunc(a0)
+	}
+	return
+}
+
+func (m *SenderService) DeleteBlockedSenderPolicy(a0 string) (r0 error) {
+	m.record("DeleteBlockedSenderPolicy", a0)
+	if m.DeleteBlockedSenderPolicyFunc != nil {
+		return m.DeleteBlockedSenderPolicyFunc(a0)
+	}
+	return
+}
+
+// TTPService is a mock of `mimecast.TTPService`; methods without a func return zero values
+type TTPService struct {
+	URLLogsFunc        func(*mimecast.TTPLogQuery) (*[]*mimecast.ClickLog, error)
+	AttachmentLogsFunc func(*mimecast.TTPLogQuery) (*[]*mimecast.AttachmentLog, error)
+
+	calls
+}
+
+var _ mimecast.TTPService = (*TTPService)(nil)
+
+func (m *TTPService) URLLogs(a0 *mimecast.TTPLogQuery) (r0 *[]*mimecast.ClickLog, r1 error) {
+	m.record("URLLogs", a0)
+	if m.URLLogsFunc != nil {
+		return m.URLLogsFunc(a0)
+	}
+	return
+}
+
+func (m *TTPService) AttachmentLogs(a0 *mimecast.TTPLogQuery) (r0 *[]*mimecast.AttachmentLog, r1 error) {
+	m.record("AttachmentLogs", a0)
+	if m.AttachmentLogsFunc != nil {
+		return m.AttachmentLogsFunc(a0)
+	}
+	return
+}
@@ -0,0 +1,149 @@
+/*
+# Mimecast - Senders
+
+This package contains all the methods to interact with Mimecast managed senders and blocked sender policies:
+https://developer.services.mimecast.com/docs/emailsecurity/1/overview
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/mimecast/senders.go
+package mimecast
+
+import (
+	"fmt"
+	"time"
+)
+
+// SenderClient for chaining methods
+type SenderClient struct {
+	*Client
+}
+
+// Entry point for sender-related operations
+func (c *Client) Senders() *SenderClient {
+	return &SenderClient{
+		Client: c,
+	}
+}
+
+/*
+ * # Permit or block a sender for a recipient
+ * - `action` must be one of {permit, block}
+ * /api/managedsender/permit-or-block-sender
+ * - https://developer.services.mimecast.com/docs/emailsecurity/1/routes/api/managedsender/permit-or-block-sender/post
+ */
+func (c *SenderClient) PermitOrBlockSender(sender, to, action string) (*ManagedSender, error) {
+	url := c.BuildURL(MimecastManagedSender)
+
+	if action != "permit" && action != "block" {
+		return nil, fmt.Errorf("invalid action %q: must be one of {permit, block}", action)
+	}
+
+	payload := &Request[ManagedSenderRequest]{
+		Data: []*ManagedSenderRequest{{Sender: sender, To: to, Action: action}},
+	}
+
+	c.Log.Printf("Setting managed sender %s -> %s to %s", sender, to, action)
+	res, err := do[Response[ManagedSender]](c.Client, url, payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := res.Failed(); err != nil {
+		return nil, err
+	}
+	if len(res.Data) == 0 {
+		return nil, fmt.Errorf("no managed sender returned for %s", sender)
+	}
+
+	return res.Data[0], nil
+}
+
+/*
+ * # Permit a sender for a recipient
+ * /api/managedsender/permit-or-block-sender
+ * - https://developer.services.mimecast.com/docs/emailsecurity/1/routes/api/managedsender/permit-or-block-sender/post
+ */
+func (c *SenderClient) PermitSender(sender, to string) (*ManagedSender, error) {
+	return c.PermitOrBlockSender(sender, to, "permit")
+}
+
+/*
+ * # Block a sender for a recipient
+ * /api/managedsender/permit-or-block-sender
+ * - https://developer.services.mimecast.com/docs/emailsecurity/1/routes/api/managedsender/permit-or-block-sender/post
+ */
+func (c *SenderClient) BlockSender(sender, to string) (*ManagedSender, error) {
+	return c.PermitOrBlockSender(sender, to, "block")
+}
+
+/*
+ * # List all blocked sender policies
+ * /api/policy/blockedsenders/get-policy
+ * - https://developer.services.mimecast.com/docs/securitypolicymanagement/1/routes/api/policy/blockedsenders/get-policy/post
+ */
+func (c *SenderClient) ListBlockedSenderPolicies() (*BlockedSenderPolicies, error) {
+	url := c.BuildURL(MimecastBlockedSenderPolicy)
+
+	var cache BlockedSenderPolicies
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	policies, err := doPaginated[Response[BlockedSenderPolicy]](c.Client, url, &BlockedSenderQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := BlockedSenderPolicies(*policies)
+	c.SetCache(url, result, 5*time.Minute)
+	return &result, nil
+}
+
+/*
+ * # Create a blocked sender policy
+ * /api/policy/blockedsenders/create-policy
+ * - https://developer.services.mimecast.com/docs/securitypolicymanagement/1/routes/api/policy/blockedsenders/create-policy/post
+ */
+func (c *SenderClient) CreateBlockedSenderPolicy(policy *BlockedSenderPolicy) (*BlockedSenderPolicy, error) {
+	url := c.BuildURL(MimecastBlockedSenderCreate)
+
+	payload := &Request[BlockedSenderPolicy]{
+		Data: []*BlockedSenderPolicy{policy},
+	}
+
+	res, err := do[Response[BlockedSenderPolicy]](c.Client, url, payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := res.Failed(); err != nil {
+		return nil, err
+	}
+	if len(res.Data) == 0 {
+		return nil, fmt.Errorf("no policy returned")
+	}
+
+	return res.Data[0], nil
+}
+
+/*
+ * # Delete a blocked sender policy
+ * /api/policy/blockedsenders/delete-policy
+ * - https://developer.services.mimecast.com/docs/securitypolicymanagement/1/routes/api/policy/blockedsenders/delete-policy/post
+ */
+func (c *SenderClient) DeleteBlockedSenderPolicy(id string) error {
+	url := c.BuildURL(MimecastBlockedSenderDelete)
+
+	payload := &Request[PolicyID]{
+		Data: []*PolicyID{{ID: id}},
+	}
+
+	res, err := do[Response[PolicyID]](c.Client, url, payload)
+	if err != nil {
+		return err
+	}
+
+	return res.Failed()
+}
@@ -0,0 +1,55 @@
+/*
+# Mimecast - Targeted Threat Protection
+
+This package contains all the methods to retrieve Mimecast Targeted Threat Protection (TTP) logs:
+https://developer.services.mimecast.com/docs/threatsintel/1/overview
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/mimecast/ttp.go
+package mimecast
+
+// TTPClient for chaining methods
+type TTPClient struct {
+	*Client
+}
+
+// Entry point for TTP-related operations
+func (c *Client) TTP() *TTPClient {
+	return &TTPClient{
+		Client: c,
+	}
+}
+
+/*
+ * # Get TTP URL Protect click logs
+ * /api/ttp/url/get-logs
+ * - https://developer.services.mimecast.com/docs/threatsintel/1/routes/api/ttp/url/get-logs/post
+ */
+func (c *TTPClient) URLLogs(q *TTPLogQuery) (*[]*ClickLog, error) {
+	url := c.BuildURL(MimecastTTPURLLogs)
+
+	if q == nil {
+		q = &TTPLogQuery{Route: "all", ScanResult: "all"}
+	}
+
+	return doPaginated[ClickLogList](c.Client, url, q)
+}
+
+/*
+ * # Get TTP Attachment Protect logs
+ * /api/ttp/attachment/get-logs
+ * - https://developer.services.mimecast.com/docs/threatsintel/1/routes/api/ttp/attachment/get-logs/post
+ */
+func (c *TTPClient) AttachmentLogs(q *TTPLogQuery) (*[]*AttachmentLog, error) {
+	url := c.BuildURL(MimecastTTPAttachmentLogs)
+
+	if q == nil {
+		q = &TTPLogQuery{Route: "all", Result: "all"}
+	}
+
+	return doPaginated[AttachmentLogList](c.Client, url, q)
+}
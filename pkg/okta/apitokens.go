@@ -0,0 +1,39 @@
+/*
+# Okta API Tokens
+
+This package contains all the methods to interact with the Okta API Tokens API:
+https://developer.okta.com/docs/api/openapi/okta-management/management/tag/ApiToken/#tag/ApiToken
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/okta/apitokens.go
+package okta
+
+import (
+	"time"
+)
+
+/*
+ * # List the metadata of every active API token
+ * /api/v1/api-tokens
+ * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/ApiToken/#tag/ApiToken/operation/listApiTokens
+ */
+func (c *Client) ListAPITokens() (*APITokens, error) {
+	url := c.BuildURL(OktaAPITokens)
+
+	var cache APITokens
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	tokens, err := doPaginated[APITokens](c, "GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetCache(url, tokens, 30*time.Minute)
+	return tokens, nil
+}
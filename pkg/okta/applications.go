@@ -14,6 +14,8 @@ package okta
 
 import (
 	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/iterator"
 )
 
 /*
@@ -55,6 +57,19 @@ func (c *Client) ListAllApplications() (*Applications, error) {
 	return applications, nil
 }
 
+/*
+ * # Iterate all Applications
+ * /api/v1/apps
+ * - Pages are requested as the applications are consumed, so callers can stop early; results are not cached
+ */
+func (c *Client) IterAllApplications() iterator.Seq[*Application] {
+	q := AppQuery{
+		IncludeNonDeleted: false,
+	}
+
+	return iterate[*Application](c, "GET", c.BuildURL(OktaApps), q, nil)
+}
+
 /*
  * # List all Application Users
  * Retrieves all users assigned to an application
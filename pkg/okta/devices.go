@@ -14,6 +14,8 @@ package okta
 
 import (
 	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/iterator"
 )
 
 /*
@@ -48,10 +50,14 @@ type DeviceQuery struct {
 /*
  * # List All Devices
  * Lists all devices with pagination support.
+ * - Devices are only served by Identity Engine orgs; a client pinned to `CLASSIC` fails with `version.ErrUnsupported`
  * /api/v1/devices
  * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Device/#tag/Device/operation/listDevices
  */
 func (c *Client) ListAllDevices() (*Devices, error) {
+	if err := c.Require(DEVICES); err != nil {
+		return nil, err
+	}
 	url := c.BuildURL(OktaDevices)
 
 	var cache Devices
@@ -68,6 +74,18 @@ func (c *Client) ListAllDevices() (*Devices, error) {
 	return devices, nil
 }
 
+/*
+ * # Iterate all Devices
+ * /api/v1/devices
+ * - Pages are requested as the devices are consumed, so callers can stop early; results are not cached
+ */
+func (c *Client) IterAllDevices() iterator.Seq[*Device] {
+	if err := c.Require(DEVICES); err != nil {
+		return iterator.Error[*Device](err)
+	}
+	return iterate[*Device](c, "GET", c.BuildURL(OktaDevices), nil, nil)
+}
+
 /*
  * # List Devices (Queried)
  * Query devices with pagination support.
@@ -75,6 +93,9 @@ func (c *Client) ListAllDevices() (*Devices, error) {
  * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Device/#tag/Device/operation/listDevices
  */
 func (c *Client) ListDevices(q DeviceQuery) (*Devices, error) {
+	if err := c.Require(DEVICES); err != nil {
+		return nil, err
+	}
 	url := c.BuildURL(OktaDevices)
 
 	var cache Devices
@@ -97,6 +118,9 @@ func (c *Client) ListDevices(q DeviceQuery) (*Devices, error) {
  * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Device/#tag/Device/operation/listDevices
  */
 func (c *Client) ListUsersForDevice(deviceID string) (*DeviceUsers, error) {
+	if err := c.Require(DEVICES); err != nil {
+		return nil, err
+	}
 	url := c.BuildURL(OktaDevices, deviceID, "users")
 
 	var cache DeviceUsers
@@ -12,22 +12,37 @@ This package contains many structs for handling responses from the Okta API:
 package okta
 
 import (
-	"strings"
+	"net/http"
 	"time"
 
 	"github.com/gemini-oss/rego/pkg/common/cache"
+	"github.com/gemini-oss/rego/pkg/common/enum"
 	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/normalize"
 	"github.com/gemini-oss/rego/pkg/common/requests"
+	"golang.org/x/oauth2"
 )
 
 // ### Okta Client Entities
 // ---------------------------------------------------------------------
+// Client is safe for concurrent use by multiple goroutines; derive per-call settings with `WithContext`
 type Client struct {
 	BaseURL string           // BaseURL is the base URL for Okta API requests.
 	HTTP    *requests.Client // HTTPClient is the client used to make HTTP requests.
 	Error   *Error           // Error is the error response from the last request made by the client.
 	Log     *log.Logger      // Log is the logger used to log messages.
 	Cache   *cache.Cache     // Cache is the cache used to store responses from the Okta API.
+	Version APIVersion       // Version is the version of the Okta API the client is pinned to; `DefaultAPIVersion` when empty.
+}
+
+// OrgConfig is the config of an Okta org, read from the environment with `Profile` or set directly
+type OrgConfig struct {
+	Profile     string             // Profile the config was read from, if any, e.g. `SANDBOX`
+	Name        string             // Name of the org, e.g. `example` of example.okta.com
+	BaseDomain  string             // Domain of the org, e.g. `okta.com`, or `oktapreview.com` for a preview (sandbox) org
+	Token       string             // API token of the org
+	TokenSource oauth2.TokenSource // Access tokens of an OAuth 2.0 service app, in place of the API token; asked for another when one is rejected
+	Concurrency int                // Requests in flight at once, below the org's concurrent rate limit; `DefaultConcurrency` when zero
 }
 
 type Error struct {
@@ -45,6 +60,7 @@ type ErrorCause struct {
 type Links struct {
 	AccessPolicy           Link   `json:"accessPolicy,omitempty"`           // AccessPolicy is a link to the access policy.
 	Activate               Link   `json:"activate,omitempty"`               // Activate is a link to activate the user.
+	Apps                   Link   `json:"apps,omitempty"`                   // Apps is a link to the group's applications.
 	ChangePassword         Link   `json:"changePassword,omitempty"`         // ChangePassword is a link to change the user's password.
 	ChangeRecoveryQuestion Link   `json:"changeRecoveryQuestion,omitempty"` // ChangeRecoveryQuestion is a link to change the user's recovery question.
 	Deactivate             Link   `json:"deactivate,omitempty"`             // Deactivate is a link to deactivate the user.
@@ -65,6 +81,7 @@ type Link struct {
 	Hints  Hints  `json:"hints,omitempty"`  // Hints is a list of hints for the link.
 	Href   string `json:"href,omitempty"`   // Href is the URL for the link.
 	Method string `json:"method,omitempty"` // Method is the HTTP method for the link.
+	Name   string `json:"name,omitempty"`   // Name is the name of the link, e.g. the size of a logo.
 	Type   string `json:"type,omitempty"`   // Type is the type of link.
 }
 
@@ -86,23 +103,21 @@ type OktaPage struct {
 	Links         []string `json:"links"`
 }
 
+// HasNextPage records the `self` and `next` links of the `Link` headers of a response, reporting whether there is a next page
 func (p *OktaPage) HasNextPage(links []string) bool {
-	for _, link := range links {
-		rawLink := strings.Split(link, ";")[0]
-		rawLink = strings.Trim(rawLink, "<>")
-
-		if strings.Contains(link, `rel="self"`) {
-			p.Self = rawLink
-		}
-		if strings.Contains(link, `rel="next"`) {
-			p.NextPageLink = rawLink
-			p.Paged = true
-			return true
-		}
+	header := http.Header{"Link": links}
+	if self := requests.Link(header, "self"); self != "" {
+		p.Self = self
+	}
+	next := requests.NextLink(header)
+	if next == "" {
+		return false
 	}
-	return false
+	p.NextPageLink, p.Paged = next, true
+	return true
 }
 
+// NextPage returns the `next` link of the `Link` headers of a response, or "" on the last page
 func (p *OktaPage) NextPage(links []string) string {
 	if p.HasNextPage(links) {
 		return p.NextPageLink
@@ -137,6 +152,26 @@ type Struct[T any] interface {
 // END OF OKTA CLIENT ENTITIES
 //---------------------------------------------------------------------
 
+// ### Okta API Token Structs
+// ---------------------------------------------------------------------
+type APITokens []*APIToken
+
+// https://developer.okta.com/docs/api/openapi/okta-management/management/tag/ApiToken/#tag/ApiToken/operation/listApiTokens
+type APIToken struct {
+	ClientName  string    `json:"clientName,omitempty"`  // The client name of the token, e.g. `Okta API`.
+	Created     time.Time `json:"created,omitempty"`     // The timestamp when the token was created.
+	ExpiresAt   time.Time `json:"expiresAt,omitempty"`   // The timestamp when the token expires, unless it is used before then.
+	ID          string    `json:"id,omitempty"`          // The ID of the token.
+	LastUpdated time.Time `json:"lastUpdated,omitempty"` // The timestamp when the token was last updated, e.g. last used.
+	Links       *Links    `json:"_links,omitempty"`      // Links related to the token.
+	Name        string    `json:"name,omitempty"`        // The name of the token.
+	TokenWindow string    `json:"tokenWindow,omitempty"` // The idle duration after which the token expires, as an ISO 8601 duration, e.g. `P30D`.
+	UserID      string    `json:"userId,omitempty"`      // The ID of the user who created the token.
+}
+
+// END OF OKTA API TOKEN STRUCTS
+//---------------------------------------------------------------------
+
 // ### Okta Application Structs
 // ---------------------------------------------------------------------
 type Applications []*Application
@@ -151,7 +186,7 @@ type Application struct {
 	Licensing     Licensing           `json:"licensing,omitempty"`     // The licensing of the application.
 	Profile       ApplicationProfile  `json:"profile,omitempty"`       // The profile of the application.
 	SignOnMode    string              `json:"signOnMode,omitempty"`    // The sign-on mode of the application.
-	Status        string              `json:"status,omitempty"`        // The status of the application.
+	Status        AppStatus           `json:"status,omitempty"`        // The status of the application.
 	Visibility    Visibility          `json:"visibility,omitempty"`    // The visibility of the application.
 	Embedded      ApplicationEmbedded `json:"_embedded,omitempty"`     // The users assigned to the application.
 	Links         Links               `json:"_links,omitempty"`        // Links related to the application.
@@ -208,9 +243,9 @@ type AppLink struct {
 type Devices []*Device
 
 type Device struct {
-	Created             string          `json:"created,omitempty"`             // The timestamp when the device was created.
+	Created             time.Time       `json:"created,omitempty"`             // The timestamp when the device was created.
 	ID                  string          `json:"id,omitempty"`                  // The unique key for the device.
-	LastUpdated         string          `json:"lastUpdated,omitempty"`         // The timestamp when the device was last updated.
+	LastUpdated         time.Time       `json:"lastUpdated,omitempty"`         // The timestamp when the device was last updated.
 	Links               *Link           `json:"_links,omitempty"`              // A set of key/value pairs that provide additional information about the device.
 	Profile             *DeviceProfile  `json:"profile,omitempty"`             // The device profile.
 	ResourceAlternate   interface{}     `json:"resourceAlternateId,omitempty"` // The alternate ID of the device.
@@ -254,6 +289,79 @@ type DeviceEmbedded struct {
 // END OF OKTA DEVICE STRUCTS
 //---------------------------------------------------------------------
 
+// ### Okta Factor Structs
+// ---------------------------------------------------------------------
+type Factors []*Factor
+
+// https://developer.okta.com/docs/api/openapi/okta-management/management/tag/UserFactor/#tag/UserFactor/operation/listFactors
+type Factor struct {
+	Created     time.Time              `json:"created,omitempty"`     // The timestamp when the factor was enrolled.
+	FactorType  FactorType             `json:"factorType,omitempty"`  // The type of the factor, e.g. `push`, `sms`, `token:software:totp`, `webauthn`.
+	ID          string                 `json:"id,omitempty"`          // The ID of the factor.
+	LastUpdated time.Time              `json:"lastUpdated,omitempty"` // The timestamp when the factor was last updated.
+	Links       *Links                 `json:"_links,omitempty"`      // Links related to the factor.
+	Profile     map[string]interface{} `json:"profile,omitempty"`     // Attributes of the factor, which depend on its type.
+	Provider    string                 `json:"provider,omitempty"`    // The provider of the factor, e.g. `OKTA`, `GOOGLE`, `FIDO`.
+	Status      string                 `json:"status,omitempty"`      // The status of the factor, e.g. `ACTIVE`, `PENDING_ACTIVATION`.
+	VendorName  string                 `json:"vendorName,omitempty"`  // The name of the vendor of the factor.
+}
+
+// END OF OKTA FACTOR STRUCTS
+//---------------------------------------------------------------------
+
+// ### Okta System Log Structs
+// ---------------------------------------------------------------------
+type LogEvents []*LogEvent
+
+// https://developer.okta.com/docs/reference/api/system-log/#logevent-object
+type LogEvent struct {
+	UUID           string        `json:"uuid,omitempty"`           // Unique identifier of the event.
+	Published      time.Time     `json:"published,omitempty"`      // The timestamp when the event was published.
+	EventType      string        `json:"eventType,omitempty"`      // The type of the event, e.g. `app.oauth2.as.consent.grant`.
+	Version        string        `json:"version,omitempty"`        // The version of the event type.
+	Severity       string        `json:"severity,omitempty"`       // The severity of the event {DEBUG, INFO, WARN, ERROR}.
+	DisplayMessage string        `json:"displayMessage,omitempty"` // Human-readable description of the event.
+	Actor          *LogEntity    `json:"actor,omitempty"`          // The entity which performed the action.
+	Client         *LogClient    `json:"client,omitempty"`         // The client which requested the action.
+	Outcome        *LogOutcome   `json:"outcome,omitempty"`        // The outcome of the action.
+	Target         []*LogEntity  `json:"target,omitempty"`         // The entities the action was performed on.
+	DebugContext   *DebugContext `json:"debugContext,omitempty"`   // Additional details, which depend on the event type.
+}
+
+// https://developer.okta.com/docs/reference/api/system-log/#actor-object
+type LogEntity struct {
+	ID          string                 `json:"id,omitempty"`          // The ID of the entity.
+	Type        string                 `json:"type,omitempty"`        // The type of the entity, e.g. `User`, `AppInstance`.
+	AlternateID string                 `json:"alternateId,omitempty"` // Alternate identifier, e.g. the login of a user.
+	DisplayName string                 `json:"displayName,omitempty"` // Display name of the entity.
+	DetailEntry map[string]interface{} `json:"detailEntry,omitempty"` // Details of the entity.
+}
+
+// https://developer.okta.com/docs/reference/api/system-log/#client-object
+type LogClient struct {
+	IPAddress string `json:"ipAddress,omitempty"` // The IP address of the client.
+	Zone      string `json:"zone,omitempty"`      // The network zone of the client.
+	UserAgent *struct {
+		RawUserAgent string `json:"rawUserAgent,omitempty"` // The raw user agent of the client.
+		OS           string `json:"os,omitempty"`           // The operating system of the client.
+		Browser      string `json:"browser,omitempty"`      // The browser of the client.
+	} `json:"userAgent,omitempty"`
+}
+
+// https://developer.okta.com/docs/reference/api/system-log/#outcome-object
+type LogOutcome struct {
+	Result string `json:"result,omitempty"` // The result of the action {SUCCESS, FAILURE, SKIPPED, ALLOW, DENY, CHALLENGE, UNKNOWN}.
+	Reason string `json:"reason,omitempty"` // The reason for the result.
+}
+
+// https://developer.okta.com/docs/reference/api/system-log/#debugcontext-object
+type DebugContext struct {
+	DebugData map[string]interface{} `json:"debugData,omitempty"` // Debug data of the event, e.g. the requested scopes.
+}
+
+// END OF OKTA SYSTEM LOG STRUCTS
+//---------------------------------------------------------------------
+
 // ### Okta Roles Structs
 // ---------------------------------------------------------------------
 type RolesList struct {
@@ -319,15 +427,33 @@ type User struct {
 	LastUpdated           time.Time        `json:"lastUpdated,omitempty"`           // The timestamp when the user was last updated.
 	PasswordChanged       time.Time        `json:"passwordChanged,omitempty"`       // The timestamp when the user's password was last changed.
 	Profile               *UserProfile     `json:"profile,omitempty"`               // The user's profile.
+	RealmID               string           `json:"realmId,omitempty"`               // The ID of the realm the user belongs to.
 	Scope                 string           `json:"scope,omitempty"`                 // The user's assignment to an application [Individually,group assigned] {"USER","GROUP"}
-	Status                string           `json:"status,omitempty"`                // The status of the user.
+	Status                Status           `json:"status,omitempty"`                // The status of the user.
 	StatusChanged         time.Time        `json:"statusChanged,omitempty"`         // The timestamp when the user's status was last changed.
-	TransitioningToStatus string           `json:"transitioningToStatus,omitempty"` // The status that the user is transitioning to.
+	TransitioningToStatus Status           `json:"transitioningToStatus,omitempty"` // The status that the user is transitioning to.
 	Type                  *UserType        `json:"type,omitempty"`                  // The type of the user.
 	Embedded              *UserEmbedded    `json:"_embedded,omitempty"`             // Embedded properties, to be revisited.
 	Links                 *Links           `json:"_links,omitempty"`                // Links related to the user.
 }
 
+// AccountStatus returns the normalized status of the user; users whose password expired can still sign in, to change it
+func (u *User) AccountStatus() normalize.Status {
+	switch u.Status {
+	case ACTIVE, PASSWORD_EXPIRED:
+		return normalize.StatusActive
+	case STAGED, PROVISIONED:
+		return normalize.StatusPending
+	case LOCKED_OUT, RECOVERY:
+		return normalize.StatusLocked
+	case SUSPENDED:
+		return normalize.StatusSuspended
+	case DEPROVISIONED:
+		return normalize.StatusDeprovisioned
+	}
+	return normalize.StatusUnknown
+}
+
 type UserCredentials struct {
 	Password         *PasswordCredentials `json:"password,omitempty"`          // The user's password credentials.
 	Provider         *Provider            `json:"provider,omitempty"`          // The user's provider credentials.
@@ -430,7 +556,7 @@ type Group struct {
 	LastUpdated           time.Time     `json:"lastUpdated,omitempty"`           // The last time the user group was updated.
 	ObjectClass           []string      `json:"objectClass,omitempty"`           // Array of object classes.
 	Profile               GroupProfile  `json:"profile,omitempty"`               // The profile of the user group.
-	Type                  string        `json:"type,omitempty"`                  // The type of the user group.
+	Type                  GroupType     `json:"type,omitempty"`                  // The type of the user group.
 	Embedded              GroupEmbedded `json:"_embedded,omitempty"`             // Embedded properties, to be revisited.
 	Links                 Links         `json:"_links,omitempty"`                // Links related to the user group.
 }
@@ -485,3 +611,101 @@ type GroupCondition struct {
 
 // END OF OKTA Group STRUCTS
 //---------------------------------------------------------------------
+
+// ### Okta Enums
+// ---------------------------------------------------------------------
+
+// https://developer.okta.com/docs/api/openapi/okta-management/management/tag/User/#tag/User/operation/listUsers!c=200&path=status&t=response
+type Status string
+
+const (
+	STAGED           Status = "STAGED"           // Created, but not yet activated
+	PROVISIONED      Status = "PROVISIONED"      // Activated, but the user has not yet set a password
+	ACTIVE           Status = "ACTIVE"           // Able to sign in
+	RECOVERY         Status = "RECOVERY"         // Recovering their password
+	LOCKED_OUT       Status = "LOCKED_OUT"       // Locked out after too many failed sign-ins
+	PASSWORD_EXPIRED Status = "PASSWORD_EXPIRED" // Must change their expired password at the next sign-in
+	SUSPENDED        Status = "SUSPENDED"        // Unable to sign in, until unsuspended
+	DEPROVISIONED    Status = "DEPROVISIONED"    // Deactivated
+)
+
+// Statuses is every status of a user, in the order of their lifecycle
+var Statuses = []Status{STAGED, PROVISIONED, ACTIVE, RECOVERY, LOCKED_OUT, PASSWORD_EXPIRED, SUSPENDED, DEPROVISIONED}
+
+func (s Status) MarshalText() ([]byte, error)     { return enum.Marshal(s, Statuses...) }
+func (s *Status) UnmarshalText(text []byte) error { return enum.Unmarshal(s, text, Statuses...) }
+
+// https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Application/#tag/Application/operation/listApplications!c=200&path=status&t=response
+type AppStatus string
+
+const (
+	APP_ACTIVE   AppStatus = "ACTIVE"   // Assignable, and available to its users
+	APP_INACTIVE AppStatus = "INACTIVE" // Deactivated
+)
+
+var appStatuses = []AppStatus{APP_ACTIVE, APP_INACTIVE}
+
+func (s AppStatus) MarshalText() ([]byte, error)     { return enum.Marshal(s, appStatuses...) }
+func (s *AppStatus) UnmarshalText(text []byte) error { return enum.Unmarshal(s, text, appStatuses...) }
+
+// https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Group/#tag/Group/operation/listGroups!c=200&path=type&t=response
+type GroupType string
+
+const (
+	OKTA_GROUP GroupType = "OKTA_GROUP" // Managed in Okta
+	APP_GROUP  GroupType = "APP_GROUP"  // Imported from an application, e.g. Active Directory
+	BUILT_IN   GroupType = "BUILT_IN"   // Managed by Okta, e.g. `Everyone`
+)
+
+var groupTypes = []GroupType{OKTA_GROUP, APP_GROUP, BUILT_IN}
+
+func (t GroupType) MarshalText() ([]byte, error)     { return enum.Marshal(t, groupTypes...) }
+func (t *GroupType) UnmarshalText(text []byte) error { return enum.Unmarshal(t, text, groupTypes...) }
+
+// https://developer.okta.com/docs/api/openapi/okta-management/management/tag/UserFactor/#tag/UserFactor/operation/listFactors!c=200&path=factorType&t=response
+type FactorType string
+
+const (
+	CALL                FactorType = "call"                // Voice call with a one-time passcode
+	EMAIL               FactorType = "email"               // Email with a one-time passcode
+	PUSH                FactorType = "push"                // Okta Verify push notification
+	QUESTION            FactorType = "question"            // Security question
+	SIGNED_NONCE        FactorType = "signed_nonce"        // Okta Verify FastPass
+	SMS                 FactorType = "sms"                 // Text message with a one-time passcode
+	TOKEN               FactorType = "token"               // Third-party token, e.g. RSA SecurID
+	TOKEN_HARDWARE      FactorType = "token:hardware"      // Hardware one-time passcode, e.g. YubiKey OTP
+	TOKEN_HOTP          FactorType = "token:hotp"          // Custom HOTP authenticator
+	TOKEN_SOFTWARE_TOTP FactorType = "token:software:totp" // Authenticator app, e.g. Okta Verify or Google Authenticator
+	U2F                 FactorType = "u2f"                 // U2F security key
+	WEB                 FactorType = "web"                 // Duo
+	WEBAUTHN            FactorType = "webauthn"            // WebAuthn authenticator, e.g. a security key or biometrics
+)
+
+var factorTypes = []FactorType{CALL, EMAIL, PUSH, QUESTION, SIGNED_NONCE, SMS, TOKEN, TOKEN_HARDWARE, TOKEN_HOTP, TOKEN_SOFTWARE_TOTP, U2F, WEB, WEBAUTHN}
+
+func (t FactorType) MarshalText() ([]byte, error)     { return enum.Marshal(t, factorTypes...) }
+func (t *FactorType) UnmarshalText(text []byte) error { return enum.Unmarshal(t, text, factorTypes...) }
+
+// https://developer.okta.com/docs/concepts/oie-intro/
+type APIVersion string
+
+const (
+	CLASSIC APIVersion = "v1"  // Management API of a Classic Engine org, at /api/v1
+	OIE     APIVersion = "oie" // Management API of an Identity Engine org, at /api/v1, with the APIs only Identity Engine serves
+)
+
+// APIVersions is every version of the Okta API a client can be pinned to, with `options.WithAPIVersion`
+var APIVersions = []APIVersion{CLASSIC, OIE}
+
+func (v APIVersion) MarshalText() ([]byte, error)     { return enum.Marshal(v, APIVersions...) }
+func (v *APIVersion) UnmarshalText(text []byte) error { return enum.Unmarshal(v, text, APIVersions...) }
+
+// Capability is an API of Okta which only some versions serve
+type Capability string
+
+const (
+	DEVICES Capability = "devices" // https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Device/
+)
+
+// END OF OKTA ENUMS
+//---------------------------------------------------------------------
@@ -0,0 +1,39 @@
+/*
+# Okta Factors
+
+This package contains all the methods to interact with the Okta User Factors API:
+https://developer.okta.com/docs/api/openapi/okta-management/management/tag/UserFactor/#tag/UserFactor
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/okta/factors.go
+package okta
+
+import (
+	"time"
+)
+
+/*
+ * # List all Factors enrolled by a User
+ * /api/v1/users/{userId}/factors
+ * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/UserFactor/#tag/UserFactor/operation/listFactors
+ */
+func (c *Client) ListUserFactors(userID string) (*Factors, error) {
+	url := c.BuildURL(OktaUsers, userID, "factors")
+
+	var cache Factors
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	factors, err := do[Factors](c, "GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetCache(url, factors, 15*time.Minute)
+	return &factors, nil
+}
@@ -13,7 +13,14 @@ https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Group
 package okta
 
 import (
+	"fmt"
+	"strings"
 	"time"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/pool"
+	"github.com/gemini-oss/rego/pkg/common/requests"
 )
 
 /*
@@ -52,6 +59,38 @@ func (c *Client) ListAllGroups() (*Groups, error) {
 	return groups, nil
 }
 
+/*
+ * # Iterate All Groups
+ * /api/v1/groups
+ * - Pages are requested as the groups are consumed, so callers can stop early; results are not cached
+ */
+func (c *Client) IterAllGroups() iterator.Seq[*Group] {
+	q := GroupParameters{
+		Limit: 10000,
+	}
+
+	return iterate[*Group](c, "GET", c.BuildURL(OktaGroups), q, nil)
+}
+
+/*
+ * # Iterate groups updated since a time
+ * /api/v1/groups
+ * - Groups whose profile or membership changed after `since`, for delta syncs (see `checkpoint.Delta`); every group
+ *   when `since` is zero
+ */
+func (c *Client) IterGroupsUpdatedSince(since time.Time) iterator.Seq[*Group] {
+	if since.IsZero() {
+		return c.IterAllGroups()
+	}
+
+	t := searchTime(since)
+	q := GroupParameters{
+		Limit:  10000,
+		Search: fmt.Sprintf(`lastUpdated gt "%s" or lastMembershipUpdated gt "%s"`, t, t),
+	}
+	return iterate[*Group](c, "GET", c.BuildURL(OktaGroups), q, nil)
+}
+
 /*
  * # Get Group by ID
  * /api/v1/groups/{groupId}
@@ -75,6 +114,166 @@ func (c *Client) GetGroup(groupID string) (*Group, error) {
 	return &group, nil
 }
 
+/*
+ * # Get Group by Name
+ * - Okta's `q` parameter is a prefix search, so results are filtered down to an exact (case-insensitive) match
+ * /api/v1/groups?q={name}
+ * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Group/#tag/Group/operation/listGroups
+ */
+func (c *Client) GetGroupByName(name string) (*Group, error) {
+	url := c.BuildURL(OktaGroups)
+
+	q := GroupParameters{
+		Q:     name,
+		Limit: 200,
+	}
+
+	groups, err := do[Groups](c, "GET", url, q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		if strings.EqualFold(group.Profile.Name, name) {
+			return group, nil
+		}
+	}
+
+	return nil, fmt.Errorf("group %s: %w", name, rerrors.ErrNotFound)
+}
+
+/*
+ * # Update a Group's Profile
+ * /api/v1/groups/{groupId}
+ * - Replaces the whole profile, so unchanged attributes (e.g. the description) must be set too
+ * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Group/#tag/Group/operation/replaceGroup
+ */
+func (c *Client) UpdateGroup(groupID string, profile GroupProfile) (*Group, error) {
+	url := c.BuildURL(OktaGroups, groupID)
+
+	c.Log.Printf("Updating Okta group %s", groupID)
+	body := struct {
+		Profile GroupProfile `json:"profile"`
+	}{
+		Profile: profile,
+	}
+
+	group, err := do[Group](c, "PUT", url, nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// The group, and the list of every group, were cached with the previous profile
+	c.invalidate(url)
+	if err := c.Cache.Delete(c.BuildURL(OktaGroups)); err != nil {
+		c.Log.Warning("Error invalidating cache data:", err)
+	}
+	return &group, nil
+}
+
+/*
+ * # List All Members of a Group
+ * /api/v1/groups/{groupId}/users
+ * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Group/#tag/Group/operation/listGroupUsers
+ */
+func (c *Client) ListGroupMembers(groupID string) (*Users, error) {
+	c.Log.Printf("Getting members of group %s", groupID)
+	url := c.BuildURL(OktaGroups, groupID, "users")
+
+	var cache Users
+	if c.GetCache(url, &cache) {
+		return &cache, nil
+	}
+
+	q := GroupParameters{
+		Limit: 1000,
+	}
+
+	members, err := doPaginated[Users](c, "GET", url, q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetCache(url, members, 5*time.Minute)
+	return members, nil
+}
+
+/*
+ * # Iterate All Members of a Group
+ * /api/v1/groups/{groupId}/users
+ * - Pages are requested as the members are consumed, so callers can stop early; results are not cached
+ */
+func (c *Client) IterGroupMembers(groupID string) iterator.Seq[*User] {
+	q := GroupParameters{
+		Limit: 1000,
+	}
+
+	return iterate[*User](c, "GET", c.BuildURL(OktaGroups, groupID, "users"), q, nil)
+}
+
+/*
+ * # Assign a User to a Group
+ * /api/v1/groups/{groupId}/users/{userId}
+ * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Group/#tag/Group/operation/assignUserToGroup
+ */
+func (c *Client) AddUserToGroup(groupID string, userID string) error {
+	url := c.BuildURL(OktaGroups, groupID, "users", userID)
+
+	c.Log.Printf("Adding Okta user %s to group %s", userID, groupID)
+	_, err := do[interface{}](c, "PUT", url, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	// The cached members of the group, and groups of the user, no longer hold
+	c.invalidate(c.BuildURL(OktaGroups, groupID), c.BuildURL(OktaUsers, userID))
+	return nil
+}
+
+/*
+ * # Unassign a User from a Group
+ * /api/v1/groups/{groupId}/users/{userId}
+ * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Group/#tag/Group/operation/unassignUserFromGroup
+ */
+func (c *Client) RemoveUserFromGroup(groupID string, userID string) error {
+	url := c.BuildURL(OktaGroups, groupID, "users", userID)
+
+	c.Log.Printf("Removing Okta user %s from group %s", userID, groupID)
+	_, err := do[interface{}](c, "DELETE", url, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	// The cached members of the group, and groups of the user, no longer hold
+	c.invalidate(c.BuildURL(OktaGroups, groupID), c.BuildURL(OktaUsers, userID))
+	return nil
+}
+
+/*
+ * # Assign Users to a Group
+ * /api/v1/groups/{groupId}/users/{userId}
+ * - Assigns each user on `opts.Workers` workers, returning the errors of the users which could not be assigned
+ * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Group/#tag/Group/operation/assignUserToGroup
+ */
+func (c *Client) AddUsersToGroup(groupID string, userIDs []string, opts pool.Options) error {
+	batch := make([]requests.BatchRequest, len(userIDs))
+	for i, userID := range userIDs {
+		batch[i] = requests.BatchRequest{Method: "PUT", URL: c.BuildURL(OktaGroups, groupID, "users", userID)}
+	}
+
+	c.Log.Printf("Adding %d Okta users to group %s", len(userIDs), groupID)
+	results := requests.Batch[[]byte](c.HTTP, batch, opts)
+
+	// The cached members of the group, and groups of the users, no longer hold
+	prefixes := []string{c.BuildURL(OktaGroups, groupID)}
+	for i := range results {
+		results[i].Err = apiError(results[i].Err)
+		prefixes = append(prefixes, c.BuildURL(OktaUsers, userIDs[i]))
+	}
+	c.invalidate(prefixes...)
+	return requests.BatchErr(results)
+}
+
 /*
  * # List All Group Rules
  * /api/v1/groups/rules
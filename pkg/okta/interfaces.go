@@ -0,0 +1,113 @@
+/*
+# Okta Interfaces
+
+This package contains the interfaces of each Okta resource, which the `Client` satisfies. Code depending on an interface
+rather than the `Client` can be unit tested with the mocks of `pkg/okta/oktamock`
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC., see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/okta/interfaces.go
+package okta
+
+import (
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/checkpoint"
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/pipeline"
+	"github.com/gemini-oss/rego/pkg/common/query"
+)
+
+//go:generate go run ../../cmd/mockgen
+
+// APITokenService lists the API tokens of the organization
+type APITokenService interface {
+	ListAPITokens() (*APITokens, error)
+}
+
+// ApplicationService manages applications and their assignments
+type ApplicationService interface {
+	ListAllApplications() (*Applications, error)
+	IterAllApplications() iterator.Seq[*Application]
+	ListAllApplicationUsers(appID string) (*Users, error)
+	GetApplicationUser(appID string, userID string) (*User, error)
+	ConvertApplicationAssignment(appID string, userID string) (*User, error)
+}
+
+// DeviceService lists devices and their users
+type DeviceService interface {
+	ListAllDevices() (*Devices, error)
+	IterAllDevices() iterator.Seq[*Device]
+	ListDevices(q DeviceQuery) (*Devices, error)
+	ListUsersForDevice(deviceID string) (*DeviceUsers, error)
+	ListManagedDevices() (*Devices, error)
+}
+
+// FactorService lists the factors enrolled by users
+type FactorService interface {
+	ListUserFactors(userID string) (*Factors, error)
+}
+
+// GroupService manages groups, their members and rules
+type GroupService interface {
+	ListAllGroups() (*Groups, error)
+	IterAllGroups() iterator.Seq[*Group]
+	IterGroupsUpdatedSince(since time.Time) iterator.Seq[*Group]
+	GetGroup(groupID string) (*Group, error)
+	GetGroupByName(name string) (*Group, error)
+	UpdateGroup(groupID string, profile GroupProfile) (*Group, error)
+	ListGroupMembers(groupID string) (*Users, error)
+	IterGroupMembers(groupID string) iterator.Seq[*User]
+	AddUserToGroup(groupID string, userID string) error
+	RemoveUserFromGroup(groupID string, userID string) error
+	ListAllGroupRules() (*GroupRules, error)
+}
+
+// LogService reads the System Log
+type LogService interface {
+	ListLogs(q LogQuery) (*LogEvents, error)
+	IterLogs(q LogQuery) iterator.Seq[*LogEvent]
+	TailLogs(cp *checkpoint.Checkpoint, q LogQuery) iterator.Seq[*LogEvent]
+	LogArchive(key string, q LogQuery) *pipeline.Source
+}
+
+// RoleService reads admin roles and their assignments
+type RoleService interface {
+	ListAllRoles() (*RolesList, error)
+	GenerateRoleReport() (*RoleReports, error)
+	GetRole(roleID string) (*Role, error)
+	GetUserRoles(userID string) (*Roles, error)
+	ListAllUsersWithRoleAssignments() (*Users, error)
+}
+
+// UserService manages users and their lifecycle
+type UserService interface {
+	ListAllUsers() (*Users, error)
+	ListActiveUsers() (*Users, error)
+	IterAllUsers() iterator.Seq[*User]
+	IterActiveUsers() iterator.Seq[*User]
+	ListUsers(opts ...query.Option) (*Users, error)
+	IterUsers(opts ...query.Option) iterator.Seq[*User]
+	IterUsersUpdatedSince(since time.Time) iterator.Seq[*User]
+	GetUser(userID string) (*User, error)
+	CreateUser(profile *UserProfile, groupIDs []string, activate bool) (*User, error)
+	UpdateUser(userID string, u *User) (*User, error)
+	DeactivateUser(userID string) error
+	ClearUserSessions(userID string) error
+	GetUserAppLinks(userID string) (*AppLinks, error)
+	GetUserGroups(userID string) (*Groups, error)
+}
+
+var (
+	_ APITokenService    = (*Client)(nil)
+	_ ApplicationService = (*Client)(nil)
+	_ DeviceService      = (*Client)(nil)
+	_ FactorService      = (*Client)(nil)
+	_ GroupService       = (*Client)(nil)
+	_ LogService         = (*Client)(nil)
+	_ RoleService        = (*Client)(nil)
+	_ UserService        = (*Client)(nil)
+)
@@ -0,0 +1,123 @@
+/*
+# Okta System Log
+
+This package contains all the methods to interact with the Okta System Log API:
+https://developer.okta.com/docs/api/openapi/okta-management/management/tag/SystemLog/#tag/SystemLog
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/okta/logs.go
+package okta
+
+import (
+	"context"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/checkpoint"
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/pipeline"
+)
+
+/*
+ * Query Parameters for the System Log
+ * https://developer.okta.com/docs/api/openapi/okta-management/management/tag/SystemLog/#tag/SystemLog/operation/listLogEvents
+ */
+type LogQuery struct {
+	Since     string `url:"since,omitempty"`     // Lower bound of the published time of the events (ISO 8601); Okta defaults to 7 days ago.
+	Until     string `url:"until,omitempty"`     // Upper bound of the published time of the events (ISO 8601).
+	Filter    string `url:"filter,omitempty"`    // Filter expression, e.g. `eventType eq "app.oauth2.as.consent.grant"`.
+	Q         string `url:"q,omitempty"`         // Keywords to search for in the events.
+	SortOrder string `url:"sortOrder,omitempty"` // `ASCENDING` or `DESCENDING`.
+	Limit     int    `url:"limit,omitempty"`     // Number of events per page; at most 1000.
+}
+
+/*
+ * # List System Log events
+ * /api/v1/logs
+ * - `Until` defaults to now: without it, the System Log is a polling stream whose pages never end
+ * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/SystemLog/#tag/SystemLog/operation/listLogEvents
+ */
+func (c *Client) ListLogs(q LogQuery) (*LogEvents, error) {
+	events, err := iterator.Collect(c.IterLogs(q))
+	if err != nil {
+		return nil, err
+	}
+
+	logs := LogEvents(events)
+	return &logs, nil
+}
+
+/*
+ * # Iterate System Log events
+ * /api/v1/logs
+ * - Pages are requested as the events are consumed, so callers can stop early
+ * - `Until` defaults to now, as with `ListLogs`
+ */
+func (c *Client) IterLogs(q LogQuery) iterator.Seq[*LogEvent] {
+	if q.Until == "" {
+		q.Until = time.Now().UTC().Format(time.RFC3339)
+	}
+	if q.Limit == 0 {
+		q.Limit = 1000
+	}
+
+	return iterate[*LogEvent](c, "GET", c.BuildURL(OktaLogs), q, nil)
+}
+
+/*
+ * # Tail System Log events
+ * /api/v1/logs
+ * - Iterates the events published after `cp`, oldest first, advancing `cp` past each event the consumer accepts, so a
+ *   later run resumes after the last event processed (see `checkpoint.Run`)
+ * - Events published at the time of `cp` are skipped up to its cursor, the UUID of the last event processed
+ * - `Until` defaults to now, as with `ListLogs`
+ */
+func (c *Client) TailLogs(cp *checkpoint.Checkpoint, q LogQuery) iterator.Seq[*LogEvent] {
+	if !cp.Time.IsZero() {
+		q.Since = cp.Time.UTC().Format(time.RFC3339Nano)
+	}
+	q.SortOrder = "ASCENDING"
+
+	events := c.IterLogs(q)
+	return func(yield func(*LogEvent, error) bool) {
+		skipping := cp.Cursor != ""
+		events(func(event *LogEvent, err error) bool {
+			if err != nil {
+				return yield(event, err)
+			}
+			if skipping && event.Published.Equal(cp.Time) {
+				skipping = event.UUID != cp.Cursor
+				return true
+			}
+			skipping = false
+
+			if !yield(event, nil) {
+				return false
+			}
+			if !event.Published.Before(cp.Time) {
+				*cp = checkpoint.Checkpoint{Time: event.Published, Cursor: event.UUID}
+			}
+			return true
+		})
+	}
+}
+
+/*
+ * # Archive System Log events
+ * Streams the events matching `q` into object storage with `pipeline.Pipeline`, as JSON lines under `key`
+ * - Events are written page by page, so an archive of any length is never held in memory
+ * - `Until` defaults to now, as with `ListLogs`
+ */
+func (c *Client) LogArchive(key string, q LogQuery) *pipeline.Source {
+	return pipeline.FromJSONLines(key, func(ctx context.Context, emit func(v interface{}) error) error {
+		return iterator.ForEach(c.WithContext(ctx).IterLogs(q), func(event *LogEvent) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return emit(event)
+		})
+	})
+}
@@ -13,47 +13,124 @@ https://developer.okta.com/docs/api/
 package okta
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
+	"github.com/gemini-oss/rego/pkg/common/auth"
 	"github.com/gemini-oss/rego/pkg/common/cache"
 	"github.com/gemini-oss/rego/pkg/common/config"
-	"github.com/gemini-oss/rego/pkg/common/log"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/options"
 	"github.com/gemini-oss/rego/pkg/common/ratelimit"
 	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/version"
+	"golang.org/x/oauth2"
 )
 
 var (
 	BaseURL = fmt.Sprintf("https://%s.%s.com/api/v1", "%s", "%s") // https://developer.okta.com/docs/api/#versioning
+
+	ErrIncompleteOrg = errors.New("incomplete Okta org config") // A setting the client requires is missing from the config of its org
 )
 
+// DefaultProfile is the profile of `NewClient`, whose environment variables are not prefixed
+const DefaultProfile = "DEFAULT"
+
+// DefaultConcurrency is the number of requests a client keeps in flight at once, below the concurrent rate limit of every edition
+// https://developer.okta.com/docs/reference/rl-additional-limits/#concurrent-rate-limits
+const DefaultConcurrency = 15
+
 const (
+	OktaAPITokens  = "%s/api-tokens"   // https://developer.okta.com/docs/api/openapi/okta-management/management/tag/ApiToken/
 	OktaApps       = "%s/apps"         // https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Application/
 	OktaGroups     = "%s/groups"       // https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Group/
 	OktaGroupRules = "%s/groups/rules" // https://developer.okta.com/docs/api/openapi/okta-management/management/tag/GroupRule/
 	OktaDevices    = "%s/devices"      // https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Device/
 	OktaUsers      = "%s/users"        // https://developer.okta.com/docs/api/openapi/okta-management/management/tag/User/
+	OktaLogs       = "%s/logs"         // https://developer.okta.com/docs/api/openapi/okta-management/management/tag/SystemLog/
 	OktaIAM        = "%s/iam"          // https://developer.okta.com/docs/api/openapi/okta-management/management/tag/RoleAssignment/
 	OktaRoles      = "%s/iam/roles"    // https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Role/
 )
 
-// BuildURL builds a URL for a given resource and identifiers.
+// DefaultAPIVersion is the version of the Okta API of clients which are not pinned to one; orgs created since 2022 run Identity Engine
+const DefaultAPIVersion = OIE
+
+// capabilities are the APIs which only some versions of the Okta API serve
+var capabilities = version.Capabilities[Capability, APIVersion]{
+	DEVICES: {OIE},
+}
+
+// BuildURL builds a URL for a given resource and identifiers, escaping each identifier as a single path segment.
 func (c *Client) BuildURL(endpoint string, identifiers ...string) string {
-	url := fmt.Sprintf(endpoint, c.BaseURL)
-	for _, id := range identifiers {
-		url = fmt.Sprintf("%s/%s", url, id)
+	return requests.JoinURL(fmt.Sprintf(endpoint, c.BaseURL), identifiers...)
+}
+
+// APIVersion returns the version of the Okta API the client is pinned to
+func (c *Client) APIVersion() APIVersion {
+	if c.Version == "" {
+		return DefaultAPIVersion
 	}
-	return url
+	return c.Version
+}
+
+// Supports returns whether the version the client is pinned to serves `capability`
+func (c *Client) Supports(capability Capability) bool {
+	return capabilities.Supports(c.APIVersion(), capability)
+}
+
+// Require returns an error wrapping `version.ErrUnsupported` unless the version the client is pinned to serves `capability`
+func (c *Client) Require(capability Capability) error {
+	return capabilities.Require(c.APIVersion(), capability)
 }
 
 // UseCache() enables caching for the next method call.
 func (c *Client) UseCache() *Client {
-	c.Cache.Enabled = true
+	c.Cache.Enable()
 	return c
 }
 
+/*
+ * # With Context
+ * Returns a copy of the client whose requests are bound to `ctx`
+ * - Cancelling `ctx` stops paginated lists between pages and aborts the request in flight
+ */
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cc := *c
+	cc.HTTP = c.HTTP.WithContext(ctx)
+	return &cc
+}
+
+// Close closes the HTTP client (see `requests.Client.Close`), then flushes the cache (see `cache.Cache.Flush`)
+func (c *Client) Close(ctx context.Context) error {
+	return errors.Join(c.HTTP.Close(ctx), c.Cache.Flush())
+}
+
+// Name returns the name of the service, `okta`
+func (c *Client) Name() string {
+	return "okta"
+}
+
+// Endpoint returns the base URL of the API the client sends its requests to
+func (c *Client) Endpoint() string {
+	return c.BaseURL
+}
+
+/*
+ * # Check the Health of the Okta Client
+ * Gets the user of the API token, so the org is reachable and the token valid
+ * /api/v1/users/me
+ */
+func (c *Client) HealthCheck(ctx context.Context) error {
+	_, err := do[*User](c.WithContext(ctx), "GET", c.BuildURL(OktaUsers, "me"), nil, nil)
+	return err
+}
+
 /*
  * SetCache stores an Okta API response in the cache
  */
@@ -84,9 +161,45 @@ func (c *Client) GetCache(key string, target interface{}) bool {
 	return true
 }
 
+// invalidate removes the cached responses of the URLs starting with `prefixes`, once a mutation made them stale
+func (c *Client) invalidate(prefixes ...string) {
+	for _, prefix := range prefixes {
+		if _, err := c.Cache.InvalidateByPrefix(prefix); err != nil {
+			c.Log.Warning("Error invalidating cache data:", err)
+		}
+	}
+}
+
+/*
+ * # Cached
+ * Returns the value of `key` in the cache, or fetches it with `fetch` and caches it for `duration`
+ * - A key the cache serves stale (see `cache.StaleWhileRevalidate`) is returned at once once it expired, and fetched
+ *   again in the background
+ */
+func cached[T any](c *Client, key string, duration time.Duration, fetch func() (*T, error)) (*T, error) {
+	data, err := c.Cache.Fetch(key, duration, func() ([]byte, error) {
+		value, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(value)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(T)
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("unmarshalling cache data: %w", err)
+	}
+	return result, nil
+}
+
 /*
   - # Generate Okta Client
-  - @param logger *log.Logger
+  - Generates a client of the org of the default profile (`OKTA_ORG_NAME`, `OKTA_BASE_URL` and `OKTA_API_TOKEN`), exiting when it is incomplete
+  - @param verbosity int
+  - @param opts ...options.Option
   - @return *Client
   - Example:
 
@@ -94,67 +207,251 @@ func (c *Client) GetCache(key string, target interface{}) bool {
 
 	o := okta.NewClient(log.DEBUG)
 
+	// Or against a sandbox, with an in-memory cache
+	o := okta.NewClient(log.DEBUG,
+		options.WithBaseURL("https://example.oktapreview.com/api/v1"),
+		options.WithCache(c),
+	)
+
 ```
 */
-func NewClient(verbosity int) *Client {
-	log := log.NewLogger("{okta}", verbosity)
-
-	org_name := config.GetEnv("OKTA_ORG_NAME") // {ORG_NAME}.okta.com
-	//org_name := config.GetEnv("OKTA_SANDBOX_ORG_NAME")
-	if len(org_name) == 0 {
-		log.Fatal("OKTA_ORG_NAME is not set")
+func NewClient(verbosity int, opts ...options.Option) *Client {
+	c, err := NewOrgClient(Profile(DefaultProfile), verbosity, opts...)
+	if err != nil {
+		options.New(opts...).Log("{okta}", verbosity).Fatal(err)
 	}
+	return c
+}
 
-	org_name = strings.TrimPrefix(org_name, "https://")
-	org_name = strings.TrimPrefix(org_name, "http://")
-	org_name = strings.TrimSuffix(org_name, ".okta.com")
+/*
+  - # Generate Okta Client of an Org
+  - Generates a client of any org, so clients of several orgs (e.g. production and its preview sandbox) can coexist in one process
+  - @param org OrgConfig
+  - @param verbosity int
+  - @param opts ...options.Option
+  - @return *Client
+  - @return error
+  - Example:
 
-	base := config.GetEnv("OKTA_BASE_URL") // {ORG_NAME}.{BASE_URL}
-	//base := config.GetEnv("OKTA_SANDBOX_BASE_URL") // oktapreview.com
-	if len(base) == 0 {
-		log.Fatal("OKTA_BASE_URL is not set")
-	}
+```go
 
-	base = strings.Trim(base, "./")
-	base = strings.TrimSuffix(base, ".com")
+	prod := okta.NewClient(log.INFO)
+	sandbox, err := okta.NewOrgClient(okta.Profile("SANDBOX"), log.INFO) // OKTA_SANDBOX_ORG_NAME, OKTA_SANDBOX_BASE_URL, ...
+
+	// Or with an OAuth 2.0 service app, rather than an API token
+	preview, err := okta.NewOrgClient(okta.OrgConfig{
+		Name:        "example",
+		BaseDomain:  "oktapreview.com",
+		TokenSource: cc.TokenSource(ctx),
+	}, log.INFO)
 
-	token := config.GetEnv("OKTA_API_TOKEN")
-	//token := config.GetEnv("OKTA_SANDBOX_API_TOKEN")
-	if len(token) == 0 {
-		log.Fatal("OKTA_API_TOKEN is not set")
+```
+*/
+func NewOrgClient(org OrgConfig, verbosity int, opts ...options.Option) (*Client, error) {
+	o := options.New(opts...)
+	log := o.Log("{okta}", verbosity)
+
+	v, err := version.Pin(o.APIVersion, DefaultAPIVersion, APIVersions...)
+	if err != nil {
+		return nil, err
 	}
-	BaseURL := fmt.Sprintf(BaseURL, org_name, base)
 
-	headers := requests.Headers{
-		"Authorization": "SSWS " + token,
-		"Accept":        requests.JSON,
-		"Content-Type":  requests.JSON,
+	BaseURL := o.BaseURL
+	if BaseURL == "" {
+		if org.Name == "" {
+			return nil, org.incomplete("ORG_NAME", "Name")
+		}
+		if org.BaseDomain == "" {
+			return nil, org.incomplete("BASE_URL", "BaseDomain")
+		}
+		BaseURL = org.URL()
+	}
+	if org.Token == "" && org.TokenSource == nil {
+		return nil, org.incomplete("API_TOKEN", "Token")
 	}
-	httpClient := requests.NewClient(nil, headers, nil)
-	httpClient.BodyType = requests.JSON
 
-	// Look into `Functional Options` patterns for a better way to handle this (and other clients while we're at it)
-	encryptionKey := []byte(config.GetEnv("REGO_ENCRYPTION_KEY"))
-	if len(encryptionKey) == 0 {
-		log.Fatal("REGO_ENCRYPTION_KEY is not set")
+	cache := o.Cache
+	if cache == nil {
+		var err error
+		cache, err = newCache(org.cacheFile(), o.CacheBackend)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	cache, err := cache.NewCache(encryptionKey, "rego_cache_okta.gob", 1000000)
-	if err != nil {
-		panic(err)
+	headers := requests.Headers{
+		"Accept":       requests.JSON,
+		"Content-Type": requests.JSON,
+	}
+	hc := o.HTTPClient
+	var reauthenticate func(c *requests.Client) error
+	var tokens oauth2.TokenSource
+	switch {
+	case org.TokenSource != nil:
+		tokens = auth.NewRenewableTokenSource(nil, org.TokenSource.Token)
+	case org.Profile != "":
+		headers["Authorization"] = "SSWS " + org.Token
+		reauthenticate = org.rotateToken
+	default:
+		headers["Authorization"] = "SSWS " + org.Token
 	}
 
 	// https://developer.okta.com/docs/reference/rl-best-practices/
-	rl := ratelimit.NewRateLimiter()
-	rl.ResetHeaders = true
-	rl.Log.Verbosity = verbosity
+	concurrency := org.Concurrency
+	if concurrency == 0 {
+		concurrency = DefaultConcurrency
+	}
+	queue := ratelimit.NewQueue(concurrency, Bucket)
+	queue.Log.Verbosity = verbosity
+	hc = queue.Client(hc)
+
+	httpClient := requests.NewClient(hc, headers, o.RateLimiter, requests.WithCache(cache), requests.WithUserAgent("okta", o.UserAgent), requests.WithRetry(o.Retry), requests.WithCircuitBreaker(o.Breaker), requests.WithRateLimitBuckets(o.RateLimits), requests.WithSingleflight())
+	httpClient.BodyType = requests.JSON
+	httpClient.Reauthenticate = reauthenticate
+	if tokens != nil {
+		httpClient.Subject = "okta:" + BaseURL
+		httpClient.UseTokenSource(tokens)
+	}
 
 	return &Client{
 		BaseURL: BaseURL,
 		HTTP:    httpClient,
 		Log:     log,
 		Cache:   cache,
+		Version: v,
+	}, nil
+}
+
+/*
+ * # Org Profile
+ * Reads the config of an org from the environment variables of a named profile, e.g. `SANDBOX`:
+ * - `OKTA_SANDBOX_ORG_NAME`, `OKTA_SANDBOX_BASE_URL` and `OKTA_SANDBOX_API_TOKEN`
+ * - `OKTA_SANDBOX_CONCURRENCY` optionally sets the requests in flight at once, e.g. for an org with a higher concurrent limit
+ * - The default profile reads `OKTA_ORG_NAME`, `OKTA_BASE_URL` and `OKTA_API_TOKEN`
+ */
+func Profile(name string) OrgConfig {
+	name = strings.ToUpper(name)
+	if name == "" {
+		name = DefaultProfile
 	}
+	org := OrgConfig{Profile: name}
+	org.Name = config.GetEnv(org.env("ORG_NAME"))       // {ORG_NAME}.okta.com
+	org.BaseDomain = config.GetEnv(org.env("BASE_URL")) // {ORG_NAME}.{BASE_URL}, e.g. oktapreview.com
+	org.Token = config.GetEnv(org.env("API_TOKEN"))
+	org.Concurrency = config.GetEnvAsInt(org.env("CONCURRENCY"))
+	return org
+}
+
+// URL returns the base URL of the org's API
+func (org OrgConfig) URL() string {
+	base := strings.Trim(org.BaseDomain, "./")
+	base = strings.TrimSuffix(base, ".com")
+
+	// The name may be given as the org's host, e.g. example.oktapreview.com
+	name := strings.TrimPrefix(org.Name, "https://")
+	name = strings.TrimPrefix(name, "http://")
+	name = strings.TrimSuffix(name, ".okta.com")
+	name = strings.TrimSuffix(name, "."+base+".com")
+
+	return fmt.Sprintf(BaseURL, name, base)
+}
+
+/*
+ * # Rate Limit Bucket
+ * Names the rate limit bucket of a request, by its path with the IDs of resources replaced, e.g. `/api/v1/users/{id}/groups`
+ * - Okta limits each endpoint separately, within one-minute windows; this approximates its buckets, whose limits are
+ *   learned from the rate limit headers of their responses
+ * - https://developer.okta.com/docs/reference/rl-global-mgmt/
+ */
+func Bucket(r *http.Request) string {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	// Paths alternate between resources and their IDs after `/api/v1`, e.g. /api/v1/groups/{id}/users/{id}
+	for i := 3; i < len(segments); i += 2 {
+		segments[i] = "{id}"
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// env returns the environment variable of a setting of the org's profile
+func (org OrgConfig) env(setting string) string {
+	if org.Profile == DefaultProfile {
+		return "OKTA_" + setting
+	}
+	return fmt.Sprintf("OKTA_%s_%s", org.Profile, setting)
+}
+
+// incomplete reports a missing setting, by its environment variable when the org was read from a profile
+func (org OrgConfig) incomplete(setting string, field string) error {
+	if org.Profile != "" {
+		return fmt.Errorf("%w: %s is not set", ErrIncompleteOrg, org.env(setting))
+	}
+	return fmt.Errorf("%w: %s is not set", ErrIncompleteOrg, field)
+}
+
+// rotateToken sends the API token of the org's profile, once it has been rotated in the environment
+func (org OrgConfig) rotateToken(c *requests.Client) error {
+	token := config.GetEnv(org.env("API_TOKEN"))
+	if token == "" || c.Headers["Authorization"] == "SSWS "+token {
+		return fmt.Errorf("the API token was rejected, and %s holds no other", org.env("API_TOKEN"))
+	}
+	c.Headers["Authorization"] = "SSWS " + token
+	return nil
+}
+
+// cacheFile returns the name of the org's cache, so the caches of several orgs are not written to the same file
+func (org OrgConfig) cacheFile() string {
+	switch {
+	case org.Profile != "" && org.Profile != DefaultProfile:
+		return fmt.Sprintf("rego_cache_okta_%s.gob", strings.ToLower(org.Profile))
+	case org.Profile == "" && org.Name != "":
+		host := strings.TrimPrefix(org.URL(), "https://")
+		return fmt.Sprintf("rego_cache_okta_%s.gob", strings.TrimSuffix(host, "/api/v1"))
+	}
+	return "rego_cache_okta.gob"
+}
+
+// newCache opens an encrypted cache, with `REGO_ENCRYPTION_KEY`, storing its items in `backend` when set
+func newCache(file string, backend cache.Backend) (*cache.Cache, error) {
+	encryptionKey := []byte(config.GetEnv("REGO_ENCRYPTION_KEY"))
+	if len(encryptionKey) == 0 {
+		return nil, errors.New("REGO_ENCRYPTION_KEY is not set")
+	}
+
+	c, err := cache.NewCache(encryptionKey, file, 1000000, backend)
+	if err != nil {
+		return nil, fmt.Errorf("opening cache: %w", err)
+	}
+	return c, nil
+}
+
+/*
+ * # Classify an Okta error response
+ * - Records the `errorCode` and `errorSummary` of the response on the error
+ * - Okta rejects duplicate logins with a 400, which is reported as a conflict rather than an invalid request
+ * - https://developer.okta.com/docs/reference/error-codes/
+ */
+func apiError(err error) error {
+	apiErr, ok := rerrors.AsAPIError(err)
+	if !ok {
+		return err
+	}
+	apiErr.Provider = "okta"
+
+	oktaErr := &Error{}
+	if json.Unmarshal([]byte(apiErr.Message), oktaErr) != nil || oktaErr.ErrorCode == "" {
+		return apiErr
+	}
+
+	apiErr.Code = oktaErr.ErrorCode
+	apiErr.Message = oktaErr.ErrorSummary
+	for _, cause := range oktaErr.ErrorCauses {
+		apiErr.Message += ": " + cause.ErrorSummary
+		if strings.Contains(cause.ErrorSummary, "already exists") {
+			apiErr.Class = rerrors.ErrConflict
+		}
+	}
+	return apiErr
 }
 
 /*
@@ -163,15 +460,17 @@ func NewClient(verbosity int) *Client {
 func do[T any](c *Client, method string, url string, query interface{}, data interface{}) (T, error) {
 	var result T
 
-	res, body, err := c.HTTP.DoRequest(method, url, query, data)
+	res, err := c.HTTP.DoStream(method, url, query, data)
 	if err != nil {
-		return *new(T), err
+		return *new(T), apiError(err)
 	}
+	body := requests.LogBody(c.Log, res.Body)
+	defer body.Close()
 
 	c.Log.Println("Response Status:", res.Status)
-	c.Log.Debug("Response Body:", string(body))
 
-	err = json.Unmarshal(body, &result)
+	// Lifecycle operations respond with an empty body, which leaves the result empty
+	err = requests.DecodeStream(body, &result)
 	if err != nil {
 		return *new(T), fmt.Errorf("unmarshalling error: %w", err)
 	}
@@ -183,37 +482,46 @@ func do[T any](c *Client, method string, url string, query interface{}, data int
  * Generically perform a paginated request to the Okta API for a slice
  */
 func doPaginated[T Slice[E], E any](c *Client, method, url string, query interface{}, data interface{}) (*T, error) {
-	var emptySlice T = make([]E, 0)
-	results := PagedSlice[T, E]{
-		Results:  &emptySlice,
-		OktaPage: &OktaPage{},
+	items, err := iterator.Collect(iterate[E](c, method, url, query, data))
+	if err != nil {
+		return nil, err
 	}
 
-	for {
-		res, body, err := c.HTTP.DoRequest(method, url, query, data)
-		if err != nil {
-			return nil, err
-		}
+	results := T(items)
+	return &results, nil
+}
 
-		c.Log.Println("Response Status:", res.Status)
-		c.Log.Debug("Response Body:", string(body))
+/*
+ * Lazily iterate the items of a paginated request to the Okta API
+ * - Each page is requested as the previous one is consumed, following the `next` link of the response
+ */
+func iterate[E any](c *Client, method, url string, query interface{}, data interface{}) iterator.Seq[E] {
+	return iterator.Pages(func(next string) ([]E, string, error) {
+		target, q := url, query
+		if next != "" {
+			target, q = next, nil
+		}
 
-		var page []E
-		err = json.Unmarshal(body, &page)
+		res, err := c.HTTP.DoStream(method, target, q, data)
 		if err != nil {
-			return nil, fmt.Errorf("unmarshalling error: %w", err)
+			return nil, "", apiError(err)
 		}
+		body := requests.LogBody(c.Log, res.Body)
+		defer body.Close()
 
-		*results.Results = append(*results.Results, page...)
+		c.Log.Println("Response Status:", res.Status)
 
-		url = results.NextPage(res.Header.Values("Link"))
-		query = nil
-		if url == "" {
-			break
+		// Items are decoded one at a time, so a page of System Log events is never held as raw JSON as well
+		var items []E
+		err = requests.DecodeArray(body, func(item E) error {
+			items = append(items, item)
+			return nil
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("unmarshalling error: %w", err)
 		}
-	}
-
-	return results.Results, nil
+		return items, requests.NextLink(res.Header), nil
+	})
 }
 
 /*
@@ -227,16 +535,17 @@ func doPaginatedStruct[T Struct[T]](c *Client, method, url string, query interfa
 	}
 
 	for {
-		res, body, err := c.HTTP.DoRequest(method, url, query, data)
+		res, err := c.HTTP.DoStream(method, url, query, data)
 		if err != nil {
-			return nil, err
+			return nil, apiError(err)
 		}
+		body := requests.LogBody(c.Log, res.Body)
 
 		c.Log.Println("Response Status:", res.Status)
-		c.Log.Debug("Response Body:", string(body))
 
 		var page T
-		err = json.Unmarshal(body, &page)
+		err = requests.DecodeStream(body, &page)
+		body.Close()
 		if err != nil {
 			return nil, fmt.Errorf("unmarshalling error: %w", err)
 		}
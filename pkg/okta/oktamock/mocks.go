@@ -0,0 +1,524 @@
+// Code generated by mockgen from interfaces.go; DO NOT EDIT.
+
+// pkg/okta/oktamock/mocks.go
+package oktamock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/checkpoint"
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/pipeline"
+	"github.com/gemini-oss/rego/pkg/common/query"
+	"github.com/gemini-oss/rego/pkg/okta"
+)
+
+// Call is a call received by a mock
+type Call struct {
+	Method string        // Name of the method called
+	Args   []interface{} // Arguments of the call
+}
+
+// calls records the calls received by a mock
+type calls struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+func (c *calls) record(method string, args ...interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls received, in order; only those of `method` when set
+func (c *calls) Calls(method ...string) []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	received := []Call{}
+	for _, call := range c.calls {
+		if len(method) == 0 || call.Method == method[0] {
+			received = append(received, call)
+		}
+	}
+	return received
+}
+
+// APITokenService is a mock of `okta.APITokenService`; methods without a func return zero values
+type APITokenService struct {
+	ListAPITokensFunc func() (*okta.APITokens, error)
+
+	calls
+}
+
+var _ okta.APITokenService = (*APITokenService)(nil)
+
+func (m *APITokenService) ListAPITokens() (r0 *okta.APITokens, r1 error) {
+	m.record("ListAPITokens")
+	if m.ListAPITokensFunc != nil {
+		return m.ListAPITokensFunc()
+	}
+	return
+}
+
+// ApplicationService is a mock of `okta.ApplicationService`; methods without a func return zero values
+type ApplicationService struct {
+	ListAllApplicationsFunc          func() (*okta.Applications, error)
+	IterAllApplicationsFunc          func() iterator.Seq[*okta.Application]
+	ListAllApplicationUsersFunc      func(string) (*okta.Users, error)
+	GetApplicationUserFunc           func(string, string) (*okta.User, error)
+	ConvertApplicationAssignmentFunc func(string, string) (*okta.User, error)
+
+	calls
+}
+
+var _ okta.ApplicationService = (*ApplicationService)(nil)
+
+func (m *ApplicationService) ListAllApplications() (r0 *okta.Applications, r1 error) {
+	m.record("ListAllApplications")
+	if m.ListAllApplicationsFunc != nil {
+		return m.ListAllApplicationsFunc()
+	}
+	return
+}
+
+func (m *ApplicationService) IterAllApplications() (r0 iterator.Seq[*okta.Application]) {
+	m.record("IterAllApplications")
+	if m.IterAllApplicationsFunc != nil {
+		return m.IterAllApplicationsFunc()
+	}
+	return
+}
+
+func (m *ApplicationService) ListAllApplicationUsers(a0 string) (r0 *okta.Users, r1 error) {
+	m.record("ListAllApplicationUsers", a0)
+	if m.ListAllApplicationUsersFunc != nil {
+		return m.ListAllApplicationUsersFunc(a0)
+	}
+	return
+}
+
+func (m *ApplicationService) GetApplicationUser(a0 string, a1 string) (r0 *okta.User, r1 error) {
+	m.record("GetApplicationUser", a0, a1)
+	if m.GetApplicationUserFunc != nil {
+		return m.GetApplicationUserFunc(a0, a1)
+	}
+	return
+}
+
+func (m *ApplicationService) ConvertApplicationAssignment(a0 string, a1 string) (r0 *okta.User, r1 error) {
+	m.record("ConvertApplicationAssignment", a0, a1)
+	if m.ConvertApplicationAssignmentFunc != nil {
+		return m.ConvertApplicationAssignmentFunc(a0, a1)
+	}
+	return
+}
+
+// DeviceService is a mock of `okta.DeviceService`; methods without a func return zero values
+type DeviceService struct {
+	ListAllDevicesFunc     func() (*okta.Devices, error)
+	IterAllDevicesFunc     func() iterator.Seq[*okta.Device]
+	ListDevicesFunc        func(okta.DeviceQuery) (*okta.Devices, error)
+	ListUsersForDeviceFunc func(string) (*okta.DeviceUsers, error)
+	ListManagedDevicesFunc func() (*okta.Devices, error)
+
+	calls
+}
+
+var _ okta.DeviceService = (*DeviceService)(nil)
+
+func (m *DeviceService) ListAllDevices() (r0 *okta.Devices, r1 error) {
+	m.record("ListAllDevices")
+	if m.ListAllDevicesFunc != nil {
+		return m.ListAllDevicesFunc()
+	}
+	return
+}
+
+func (m *DeviceService) IterAllDevices() (r0 iterator.Seq[*okta.Device]) {
+	m.record("IterAllDevices")
+	if m.IterAllDevicesFunc != nil {
+		return m.IterAllDevicesFunc()
+	}
+	return
+}
+
+func (m *DeviceService) ListDevices(a0 okta.DeviceQuery) (r0 *okta.Devices, r1 error) {
+	m.record("ListDevices", a0)
+	if m.ListDevicesFunc != nil {
+		return m.ListDevicesFunc(a0)
+	}
+	return
+}
+
+func (m *DeviceService) ListUsersForDevice(a0 string) (r0 *okta.DeviceUsers, r1 error) {
+	m.record("ListUsersForDevice", a0)
+	if m.ListUsersForDeviceFunc != nil {
+		return m.ListUsersForDeviceFunc(a0)
+	}
+	return
+}
+
+func (m *DeviceService) ListManagedDevices() (r0 *okta.Devices, r1 error) {
+	m.record("ListManagedDevices")
+	if m.ListManagedDevicesFunc != nil {
+		return m.ListManagedDevicesFunc()
+	}
+	return
+}
+
+// FactorService is a mock of `okta.FactorService`; methods without a func return zero values
+type FactorService struct {
+	ListUserFactorsFunc func(string) (*okta.Factors, error)
+
+	calls
+}
+
+var _ okta.FactorService = (*FactorService)(nil)
+
+func (m *FactorService) ListUserFactors(a0 string) (r0 *okta.Factors, r1 error) {
+	m.record("ListUserFactors", a0)
+	if m.ListUserFactorsFunc != nil {
+		return m.ListUserFactorsFunc(a0)
+	}
+	return
+}
+
+// GroupService is a mock of `okta.GroupService`; methods without a func return zero values
+type GroupService struct {
+	ListAllGroupsFunc          func() (*okta.Groups, error)
+	IterAllGroupsFunc          func() iterator.Seq[*okta.Group]
+	IterGroupsUpdatedSinceFunc func(time.Time) iterator.Seq[*okta.Group]
+	GetGroupFunc               func(string) (*okta.Group, error)
+	GetGroupByNameFunc         func(string) (*okta.Group, error)
+	UpdateGroupFunc            func(string, okta.GroupProfile) (*okta.Group, error)
+	ListGroupMembersFunc       func(string) (*okta.Users, error)
+	IterGroupMembersFunc       func(string) iterator.Seq[*okta.User]
+	AddUserToGroupFunc         func(string, string) error
+	RemoveUserFromGroupFunc    func(string, string) error
+	ListAllGroupRulesFunc      func() (*okta.GroupRules, error)
+
+	calls
+}
+
+var _ okta.GroupService = (*GroupService)(nil)
+
+func (m *GroupService) ListAllGroups() (r0 *okta.Groups, r1 error) {
+	m.record("ListAllGroups")
+	if m.ListAllGroupsFunc != nil {
+		return m.ListAllGroupsFunc()
+	}
+	return
+}
+
+func (m *GroupService) IterAllGroups() (r0 iterator.Seq[*okta.Group]) {
+	m.record("IterAllGroups")
+	if m.IterAllGroupsFunc != nil {
+		return m.IterAllGroupsFunc()
+	}
+	return
+}
+
+func (m *GroupService) IterGroupsUpdatedSince(a0 time.Time) (r0 iterator.Seq[*okta.Group]) {
+	m.record("IterGroupsUpdatedSince", a0)
+	if m.IterGroupsUpdatedSinceFunc != nil {
+		return m.IterGroupsUpdatedSinceFunc(a0)
+	}
+	return
+}
+
+func (m *GroupService) GetGroup(a0 string) (r0 *okta.Group, r1 error) {
+	m.record("GetGroup", a0)
+	if m.GetGroupFunc != nil {
+		return m.GetGroupFunc(a0)
+	}
+	return
+}
+
+func (m *GroupService) GetGroupByName(a0 string) (r0 *okta.Group, r1 error) {
+	m.record("GetGroupByName", a0)
+	if m.GetGroupByNameFunc != nil {
+		return m.GetGroupByNameFunc(a0)
+	}
+	return
+}
+
+func (m *GroupService) UpdateGroup(a0 string, a1 okta.GroupProfile) (r0 *okta.Group, r1 error) {
+	m.record("UpdateGroup", a0, a1)
+	if m.UpdateGroupFunc != nil {
+		return m.UpdateGroupFunc(a0, a1)
+	}
+	return
+}
+
+func (m *GroupService) ListGroupMembers(a0 string) (r0 *okta.Users, r1 error) {
+	m.record("ListGroupMembers", a0)
+	if m.ListGroupMembersFunc != nil {
+		return m.ListGroupMembersFunc(a0)
+	}
+	return
+}
+
+func (m *GroupService) IterGroupMembers(a0 string) (r0 iterator.Seq[*okta.User]) {
+	m.record("IterGroupMembers", a0)
+	if m.IterGroupMembersFunc != nil {
+		return m.IterGroupMembersFunc(a0)
+	}
+	return
+}
+
+func (m *GroupService) AddUserToGroup(a0 string, a1 string) (r0 error) {
+	m.record("AddUserToGroup", a0, a1)
+	if m.AddUserToGroupFunc != nil {
+		return m.AddUserToGroupFunc(a0, a1)
+	}
+	return
+}
+
+func (m *GroupService) RemoveUserFromGroup(a0 string, a1 string) (r0 error) {
+	m.record("RemoveUserFromGroup", a0, a1)
+	if m.RemoveUserFromGroupFunc != nil {
+		return m.RemoveUserFromGroupFunc(a0, a1)
+	}
+	return
+}
+
+func (m *GroupService) ListAllGroupRules() (r0 *okta.GroupRules, r1 error) {
+	m.record("ListAllGroupRules")
+	if m.ListAllGroupRulesFunc != nil {
+		return m.ListAllGroupRulesFunc()
+	}
+	return
+}
+
+// LogService is a mock of `okta.LogService`; methods without a func return zero values
+type LogService struct {
+	ListLogsFunc   func(okta.LogQuery) (*okta.LogEvents, error)
+	IterLogsFunc   func(okta.LogQuery) iterator.Seq[*okta.LogEvent]
+	TailLogsFunc   func(*checkpoint.Checkpoint, okta.LogQuery) iterator.Seq[*okta.LogEvent]
+	LogArchiveFunc func(string, okta.LogQuery) *pipeline.Source
+
+	calls
+}
+
+var _ okta.LogService = (*LogService)(nil)
+
+func (m *LogService) ListLogs(a0 okta.LogQuery) (r0 *okta.LogEvents, r1 error) {
+	m.record("ListLogs", a0)
+	if m.ListLogsFunc != nil {
+		return m.ListLogsFunc(a0)
+	}
+	return
+}
+
+func (m *LogService) IterLogs(a0 okta.LogQuery) (r0 iterator.Seq[*okta.LogEvent]) {
+	m.record("IterLogs", a0)
+	if m.IterLogsFunc != nil {
+		return m.IterLogsFunc(a0)
+	}
+	return
+}
+
+func (m *LogService) TailLogs(a0 *checkpoint.Checkpoint, a1 okta.LogQuery) (r0 iterator.Seq[*okta.LogEvent]) {
+	m.record("TailLogs", a0, a1)
+	if m.TailLogsFunc != nil {
+		return m.TailLogsFunc(a0, a1)
+	}
+	return
+}
+
+func (m *LogService) LogArchive(a0 string, a1 okta.LogQuery) (r0 *pipeline.Source) {
+	m.record("LogArchive", a0, a1)
+	if m.LogArchiveFunc != nil {
+		return m.LogArchiveFunc(a0, a1)
+	}
+	return
+}
+
+// RoleService is a mock of `okta.RoleService`; methods without a func return zero values
+type RoleService struct {
+	ListAllRolesFunc                    func() (*okta.RolesList, error)
+	GenerateRoleReportFunc              func() (*okta.RoleReports, error)
+	GetRoleFunc                         func(string) (*okta.Role, error)
+	GetUserRolesFunc                    func(string) (*okta.Roles, error)
+	ListAllUsersWithRoleAssignmentsFunc func() (*okta.Users, error)
+
+	calls
+}
+
+var _ okta.RoleService = (*RoleService)(nil)
+
+func (m *RoleService) ListAllRoles() (r0 *okta.RolesList, r1 error) {
+	m.record("ListAllRoles")
+	if m.ListAllRolesFunc != nil {
+		return m.ListAllRolesFunc()
+	}
+	return
+}
+
+func (m *RoleService) GenerateRoleReport() (r0 *okta.RoleReports, r1 error) {
+	m.record("GenerateRoleReport")
+	if m.GenerateRoleReportFunc != nil {
+		return m.GenerateRoleReportFunc()
+	}
+	return
+}
+
+func (m *RoleService) GetRole(a0 string) (r0 *okta.Role, r1 error) {
+	m.record("GetRole", a0)
+	if m.GetRoleFunc != nil {
+		return m.GetRoleFunc(a0)
+	}
+	return
+}
+
+func (m *RoleService) GetUserRoles(a0 string) (r0 *okta.Roles, r1 error) {
+	m.record("GetUserRoles", a0)
+	if m.GetUserRolesFunc != nil {
+		return m.GetUserRolesFunc(a0)
+	}
+	return
+}
+
+func (m *RoleService) ListAllUsersWithRoleAssignments() (r0 *okta.Users, r1 error) {
+	m.record("ListAllUsersWithRoleAssignments")
+	if m.ListAllUsersWithRoleAssignmentsFunc != nil {
+		return m.ListAllUsersWithRoleAssignmentsFunc()
+	}
+	return
+}
+
+// UserService is a mock of `okta.UserService`; methods without a func return zero values
+type UserService struct {
+	ListAllUsersFunc          func() (*okta.Users, error)
+	ListActiveUsersFunc       func() (*okta.Users, error)
+	IterAllUsersFunc          func() iterator.Seq[*okta.User]
+	IterActiveUsersFunc       func() iterator.Seq[*okta.User]
+	ListUsersFunc             func(...query.Option) (*okta.Users, error)
+	IterUsersFunc             func(...query.Option) iterator.Seq[*okta.User]
+	IterUsersUpdatedSinceFunc func(time.Time) iterator.Seq[*okta.User]
+	GetUserFunc               func(string) (*okta.User, error)
+	CreateUserFunc            func(*okta.UserProfile, []string, bool) (*okta.User, error)
+	UpdateUserFunc            func(string, *okta.User) (*okta.User, error)
+	DeactivateUserFunc        func(string) error
+	ClearUserSessionsFunc     func(string) error
+	GetUserAppLinksFunc       func(string) (*okta.AppLinks, error)
+	GetUserGroupsFunc         func(string) (*okta.Groups, error)
+
+	calls
+}
+
+var _ okta.UserService = (*UserService)(nil)
+
+func (m *UserService) ListAllUsers() (r0 *okta.Users, r1 error) {
+	m.record("ListAllUsers")
+	if m.ListAllUsersFunc != nil {
+		return m.ListAllUsersFunc()
+	}
+	return
+}
+
+func (m *UserService) ListActiveUsers() (r0 *okta.Users, r1 error) {
+	m.record("ListActiveUsers")
+	if m.ListActiveUsersFunc != nil {
+		return m.ListActiveUsersFunc()
+	}
+	return
+}
+
+func (m *UserService) IterAllUsers() (r0 iterator.Seq[*okta.User]) {
+	m.record("IterAllUsers")
+	if m.IterAllUsersFunc != nil {
+		return m.IterAllUsersFunc()
+	}
+	return
+}
+
+func (m *UserService) IterActiveUsers() (r0 iterator.Seq[*okta.User]) {
+	m.record("IterActiveUsers")
+	if m.IterActiveUsersFunc != nil {
+		return m.IterActiveUsersFunc()
+	}
+	return
+}
+
+func (m *UserService) ListUsers(a0 ...query.Option) (r0 *okta.Users, r1 error) {
+	m.record("ListUsers", a0)
+	if m.ListUsersFunc != nil {
+		return m.ListUsersFunc(a0...)
+	}
+	return
+}
+
+func (m *UserService) IterUsers(a0 ...query.Option) (r0 iterator.Seq[*okta.User]) {
+	m.record("IterUsers", a0)
+	if m.IterUsersFunc != nil {
+		return m.IterUsersFunc(a0...)
+	}
+	return
+}
+
+func (m *UserService) IterUsersUpdatedSince(a0 time.Time) (r0 iterator.Seq[*okta.User]) {
+	m.record("IterUsersUpdatedSince", a0)
+	if m.IterUsersUpdatedSinceFunc != nil {
+		return m.IterUsersUpdatedSinceFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) GetUser(a0 string) (r0 *okta.User, r1 error) {
+	m.record("GetUser", a0)
+	if m.GetUserFunc != nil {
+		return m.GetUserFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) CreateUser(a0 *okta.UserProfile, a1 []string, a2 bool) (r0 *okta.User, r1 error) {
+	m.record("CreateUser", a0, a1, a2)
+	if m.CreateUserFunc != nil {
+		return m.CreateUserFunc(a0, a1, a2)
+	}
+	return
+}
+
+func (m *UserService) UpdateUser(a0 string, a1 *okta.User) (r0 *okta.User, r1 error) {
+	m.record("UpdateUser", a0, a1)
+	if m.UpdateUserFunc != nil {
+		return m.UpdateUserFunc(a0, a1)
+	}
+	return
+}
+
+func (m *UserService) DeactivateUser(a0 string) (r0 error) {
+	m.record("DeactivateUser", a0)
+	if m.DeactivateUserFunc != nil {
+		return m.DeactivateUserFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) ClearUserSessions(a0 string) (r0 error) {
+	m.record("ClearUserSessions", a0)
+	if m.ClearUserSessionsFunc != nil {
+		return m.ClearUserSessionsFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) GetUserAppLinks(a0 string) (r0 *okta.AppLinks, r1 error) {
+	m.record("GetUserAppLinks", a0)
+	if m.GetUserAppLinksFunc != nil {
+		return m.GetUserAppLinksFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) GetUserGroups(a0 string) (r0 *okta.Groups, r1 error) {
+	m.record("GetUserGroups", a0)
+	if m.GetUserGroupsFunc != nil {
+		return m.GetUserGroupsFunc(a0)
+	}
+	return
+}
@@ -13,9 +13,11 @@ https://developer.okta.com/docs/api/openapi/okta-management/management/tag/Role/
 package okta
 
 import (
+	"context"
 	"fmt"
-	"sync"
 	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/pool"
 )
 
 /*
@@ -53,78 +55,38 @@ func (c *Client) GenerateRoleReport() (*RoleReports, error) {
 	}
 
 	roleReports := &RoleReports{}
-	rolesMap := make(map[*Role]map[*User]struct{})
 
 	users, err := c.ListActiveUsers()
 	if err != nil {
 		return nil, err
 	}
 
-	var rolesMutex sync.Mutex
-	var rolesErrMutex sync.Mutex
-	var rolesErrors []error
-
-	sem := make(chan struct{}, 10)
-	var wg sync.WaitGroup
-
-	for _, user := range *users {
-		wg.Add(1)
-
-		go func(user *User) {
-			defer wg.Done()
-
-			sem <- struct{}{}
-			roles, err := c.GetUserRoles(user.ID)
-			if err != nil {
-				rolesErrMutex.Lock()
-				rolesErrors = append(rolesErrors, err)
-				rolesErrMutex.Unlock()
-				<-sem
-				return
-			}
-
-			userRoles := make(map[Role]map[*User]struct{})
-			for _, role := range *roles {
-				if userRoles[*role] == nil {
-					userRoles[*role] = make(map[*User]struct{})
-				}
-				userRoles[*role][user] = struct{}{}
-			}
+	results := pool.Map(c.HTTP.Context(), *users, pool.Options{Workers: 10, RateLimiter: c.HTTP.RateLimiter}, func(_ context.Context, user *User) (*Roles, error) {
+		return c.GetUserRoles(user.ID)
+	})
 
-			rolesMutex.Lock()
-			// Add user roles to rolesMap
-			for role, users := range userRoles {
-				if rolesMap[&role] == nil {
-					rolesMap[&role] = make(map[*User]struct{})
-				}
-				for user := range users {
-					rolesMap[&role][user] = struct{}{}
-				}
+	var rolesErrors []error
+	reports := make(map[Role]*RoleReport)
+	for _, result := range results {
+		if result.Err != nil {
+			rolesErrors = append(rolesErrors, result.Err)
+			continue
+		}
+		for _, role := range *result.Value {
+			report, ok := reports[*role]
+			if !ok {
+				report = &RoleReport{Role: role, Users: &Users{}}
+				reports[*role] = report
+				*roleReports = append(*roleReports, report)
 			}
-			rolesMutex.Unlock()
-			<-sem // release semaphore
-		}(user)
+			*report.Users = append(*report.Users, result.Item)
+		}
 	}
 
-	wg.Wait()
-	close(sem)
-
 	if len(rolesErrors) > 0 {
 		return nil, fmt.Errorf("error generating role report: %v", rolesErrors)
 	}
 
-	// Add roles to roleReports
-	for role, userSet := range rolesMap {
-		var users Users
-		for user := range userSet {
-			users = append(users, user)
-		}
-		*roleReports = append(*roleReports, &RoleReport{
-			Role:  role,
-			Users: &users,
-		})
-	}
-
 	c.SetCache(cacheKey, roleReports, 60*time.Minute)
 	return roleReports, nil
 }
@@ -13,7 +13,31 @@ https://developer.okta.com/docs/api/openapi/okta-management/management/tag/User/
 package okta
 
 import (
+	"context"
+	"strconv"
 	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/iterator"
+	"github.com/gemini-oss/rego/pkg/common/pool"
+	"github.com/gemini-oss/rego/pkg/common/query"
+)
+
+const (
+	allUsersSearch    = `status eq "STAGED" or status eq "PROVISIONED" or status eq "ACTIVE" or status eq "RECOVERY" or status eq "LOCKED_OUT" or status eq "PASSWORD_EXPIRED" or status eq "SUSPENDED" or status eq "DEPROVISIONED"`
+	activeUsersSearch = `status eq "ACTIVE"`
+)
+
+// LoginBounds split ACTIVE users, usually most of an org, into ranges of their login for `IterAllUsersParallel`
+var LoginBounds = []string{"d", "h", "l", "p", "t"}
+
+// Fields of users for `ListUsers` and `IterUsers`; any other profile attribute is a `query.Field`, e.g. `profile.department`
+var (
+	UserID          query.Field = "id"
+	UserStatus      query.Field = "status"
+	UserCreated     query.Field = "created"
+	UserLastUpdated query.Field = "lastUpdated"
+	UserLogin       query.Field = "profile.login"
+	UserEmail       query.Field = "profile.email"
 )
 
 /*
@@ -33,10 +57,29 @@ type UserQuery struct {
  * # Get all users, regardless of status
  * /api/v1/users
  * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/User/#tag/User/operation/listUsers
+ * - The list is served stale while it is listed again in the background, once the cache allows its key, e.g.
+ *   `c.Cache.StaleWhileRevalidate(c.BuildURL(okta.OktaUsers), time.Hour)`
  */
 func (c *Client) ListAllUsers() (*Users, error) {
 	url := c.BuildURL(OktaUsers)
 
+	return cached(c, url, 30*time.Minute, func() (*Users, error) {
+		q := &UserQuery{
+			Limit:  `200`,
+			Search: allUsersSearch,
+		}
+		return doPaginated[Users](c, "GET", url, q, nil)
+	})
+}
+
+/*
+ * # List all ACTIVE users
+ * /api/v1/users
+ * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/User/#tag/User/operation/listUsers
+ */
+func (c *Client) ListActiveUsers() (*Users, error) {
+	url := c.BuildURL(OktaUsers)
+
 	var cache Users
 	if c.GetCache(url, &cache) {
 		return &cache, nil
@@ -44,7 +87,7 @@ func (c *Client) ListAllUsers() (*Users, error) {
 
 	q := &UserQuery{
 		Limit:  `200`,
-		Search: `status eq "STAGED" or status eq "PROVISIONED" or status eq "ACTIVE" or status eq "RECOVERY" or status eq "LOCKED_OUT" or status eq "PASSWORD_EXPIRED" or status eq "SUSPENDED" or status eq "DEPROVISIONED"`,
+		Search: activeUsersSearch,
 	}
 
 	users, err := doPaginated[Users](c, "GET", url, q, nil)
@@ -57,30 +100,131 @@ func (c *Client) ListAllUsers() (*Users, error) {
 }
 
 /*
- * # List all ACTIVE users
+ * # Iterate all users
  * /api/v1/users
- * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/User/#tag/User/operation/listUsers
+ * - Pages are requested as the users are consumed, so callers can stop early; results are not cached
  */
-func (c *Client) ListActiveUsers() (*Users, error) {
-	url := c.BuildURL(OktaUsers)
-
-	var cache Users
-	if c.GetCache(url, &cache) {
-		return &cache, nil
+func (c *Client) IterAllUsers() iterator.Seq[*User] {
+	q := &UserQuery{
+		Limit:  `200`,
+		Search: allUsersSearch,
 	}
 
+	return iterate[*User](c, "GET", c.BuildURL(OktaUsers), q, nil)
+}
+
+/*
+ * # Iterate all ACTIVE users
+ * /api/v1/users
+ * - Pages are requested as the users are consumed, so callers can stop early; results are not cached
+ */
+func (c *Client) IterActiveUsers() iterator.Seq[*User] {
 	q := &UserQuery{
 		Limit:  `200`,
-		Search: `status eq "ACTIVE"`,
+		Search: activeUsersSearch,
 	}
 
-	users, err := doPaginated[Users](c, "GET", url, q, nil)
+	return iterate[*User](c, "GET", c.BuildURL(OktaUsers), q, nil)
+}
+
+/*
+ * # List users matching a query
+ * /api/v1/users
+ * - See `IterUsers`; results are not cached
+ */
+func (c *Client) ListUsers(opts ...query.Option) (*Users, error) {
+	users, err := iterator.Collect(c.IterUsers(opts...))
 	if err != nil {
 		return nil, err
 	}
+	u := Users(users)
+	return &u, nil
+}
 
-	c.SetCache(url, users, 30*time.Minute)
-	return users, nil
+/*
+ * # Iterate users matching a query
+ * /api/v1/users
+ * - Predicates are pushed down to the `search` expression, except `co`, which Okta supports only for some attributes;
+ *   a single ascending order is pushed down to `sortBy`
+ * - Field selection, and what cannot be pushed down, is applied client-side
+ * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/User/#tag/User/operation/listUsers
+ */
+func (c *Client) IterUsers(opts ...query.Option) iterator.Seq[*User] {
+	remote, local := query.New(opts...).Split(func(p query.Predicate) bool {
+		return p.Op != query.Contains
+	})
+
+	q := &UserQuery{
+		Limit:  `200`,
+		Search: "(" + allUsersSearch + ")",
+	}
+	if len(remote) > 0 {
+		q.Search += " and " + query.Expression(remote)
+	}
+	if len(local.Order) == 1 && !local.Order[0].Desc {
+		q.SortBy = string(local.Order[0].Field)
+		local.Order = nil
+	}
+	if len(local.Where) == 0 && len(local.Order) == 0 && local.Limit > 0 && local.Limit < 200 {
+		q.Limit = strconv.Itoa(local.Limit)
+	}
+
+	return query.Apply(iterate[*User](c, "GET", c.BuildURL(OktaUsers), q, nil), local)
+}
+
+/*
+ * # Iterate all users in parallel
+ * /api/v1/users
+ * - Opt-in for very large orgs: the users are partitioned by status, and ACTIVE users further by ranges of their login
+ *   split at `LoginBounds`, then each partition is paginated on the next free worker of `opts`
+ * - The partitions are disjoint, and the ranges open-ended, so every user is yielded exactly once, though not in order
+ *   unless `opts.Ordered` is set, which yields them by status, then by range of their login
+ * - Workers hold back while the client's rate limiter is throttling, unless `opts` sets its own
+ */
+func (c *Client) IterAllUsersParallel(ctx context.Context, opts pool.Options) iterator.Seq[*User] {
+	partitions := []iterator.Seq[*User]{}
+	for _, status := range Statuses {
+		if status != ACTIVE {
+			partitions = append(partitions, c.IterUsers(query.Where(UserStatus.Eq(status))))
+			continue
+		}
+
+		lower := ""
+		for _, upper := range append(append([]string{}, LoginBounds...), "") {
+			where := []query.Predicate{UserStatus.Eq(status)}
+			if lower != "" {
+				where = append(where, UserLogin.Ge(lower))
+			}
+			if upper != "" {
+				where = append(where, UserLogin.Lt(upper))
+			}
+			partitions = append(partitions, c.IterUsers(query.Where(where...)))
+			lower = upper
+		}
+	}
+
+	if opts.RateLimiter == nil {
+		opts.RateLimiter = c.HTTP.RateLimiter
+	}
+	return pool.Merge(ctx, partitions, opts)
+}
+
+/*
+ * # Iterate users updated since a time
+ * /api/v1/users
+ * - Users of every status whose `lastUpdated` is after `since`, for delta syncs (see `checkpoint.Delta`); every user
+ *   when `since` is zero
+ */
+func (c *Client) IterUsersUpdatedSince(since time.Time) iterator.Seq[*User] {
+	if since.IsZero() {
+		return c.IterUsers()
+	}
+	return c.IterUsers(query.Where(UserLastUpdated.Gt(searchTime(since))))
+}
+
+// searchTime formats `t` as the timestamps of Okta's search expressions, e.g. `2024-01-01T00:00:00.000Z`
+func searchTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
 }
 
 /*
@@ -105,6 +249,37 @@ func (c *Client) GetUser(userID string) (*User, error) {
 	return &user, nil
 }
 
+/*
+ * # Create a user
+ * - When `activate` is true, the user is activated immediately and sent an activation email
+ * /api/v1/users
+ * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/User/#tag/User/operation/createUser
+ */
+func (c *Client) CreateUser(profile *UserProfile, groupIDs []string, activate bool) (*User, error) {
+	url := c.BuildURL(OktaUsers)
+
+	q := struct {
+		Activate bool `url:"activate"`
+	}{
+		Activate: activate,
+	}
+
+	payload := map[string]interface{}{
+		"profile": profile,
+	}
+	if len(groupIDs) > 0 {
+		payload["groupIds"] = groupIDs
+	}
+
+	c.Log.Printf("Creating Okta user %s", profile.Login)
+	user, err := do[User](c, "POST", url, q, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
 /*
  * # Update a user's properties by ID
  * /api/v1/users/{userId}
@@ -122,6 +297,39 @@ func (c *Client) UpdateUser(userID string, u *User) (*User, error) {
 	return &user, nil
 }
 
+/*
+ * # Deactivate a user
+ * - Deactivation is permanent; the user's sessions are also revoked
+ * /api/v1/users/{userId}/lifecycle/deactivate
+ * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/UserLifecycle/#tag/UserLifecycle/operation/deactivateUser
+ */
+func (c *Client) DeactivateUser(userID string) error {
+	url := c.BuildURL(OktaUsers, userID, "lifecycle", "deactivate")
+
+	c.Log.Printf("Deactivating Okta user %s", userID)
+	_, err := do[interface{}](c, "POST", url, nil, nil)
+	return err
+}
+
+/*
+ * # Revoke all of a user's sessions, including OAuth tokens
+ * /api/v1/users/{userId}/sessions
+ * - https://developer.okta.com/docs/api/openapi/okta-management/management/tag/UserSessions/#tag/UserSessions/operation/revokeUserSessions
+ */
+func (c *Client) ClearUserSessions(userID string) error {
+	url := c.BuildURL(OktaUsers, userID, "sessions")
+
+	q := struct {
+		OAuthTokens bool `url:"oauthTokens"`
+	}{
+		OAuthTokens: true,
+	}
+
+	c.Log.Printf("Clearing sessions for Okta user %s", userID)
+	_, err := do[interface{}](c, "DELETE", url, q, nil)
+	return err
+}
+
 /*
  * # Get all Assigned Application Links for a User
  * /api/v1/users/{userId}/appLinks
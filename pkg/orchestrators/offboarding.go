@@ -0,0 +1,369 @@
+/*
+# Orchestrators - Offboarding
+
+This package contains a cross-provider offboarding workflow. Given a user's email address, it deprovisions the user
+in every configured service and produces a per-step report.
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC., see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/orchestrators/offboarding.go
+package orchestrators
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/gemini-oss/rego/pkg/backupify"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+)
+
+// ### Offboarding Structs
+// ---------------------------------------------------------------------
+type OffboardingConfig struct {
+	// Google
+	SuspendedOU        string // Organizational unit to move the user into, e.g. `/Suspended Users`; empty to skip
+	TransferTo         string // Email of the user who receives the Drive/Calendar data and DocuSign envelopes; empty to skip
+	OutOfOfficeSubject string // Subject of the vacation responder
+	OutOfOfficeMessage string // Body of the vacation responder; empty to skip
+
+	// Jamf
+	LockPIN     string // Six digit PIN used to lock the user's computers; empty to skip
+	LockMessage string // Message displayed on the locked computers
+
+	// Backupify
+	BackupifyApps []backupify.AppType // Applications to export from Backupify, e.g. `backupify.GoogleDrive`
+
+	// Snipe-IT
+	CheckinNote string // Note attached to each asset checked in from the user
+
+	Skip  []string // Names of default steps to skip, e.g. `jamf.lock`
+	Steps []Step   // Additional steps appended to the default sequence (e.g. Zoom, or any other service without a client)
+
+	WorkflowOptions
+}
+
+// END OF OFFBOARDING STRUCTS
+//---------------------------------------------------------------------
+
+/*
+ * # Offboard a user across every configured service
+ * - Okta: clear sessions, deactivate
+ * - Google: out-of-office, data transfer, suspend, move to the suspended OU
+ * - Backupify: export the user's backups
+ * - Jamf: lock the user's computers
+ * - DocuSign: transfer envelopes to `TransferTo`, close
+ * - Slack: deactivate
+ * - Snipe-IT: check in the user's assets, deactivate
+ * Services without a client on the orchestrator are left out of the sequence.
+ */
+func (c *Client) Offboard(email string, cfg *OffboardingConfig) *Report {
+	if cfg == nil {
+		cfg = &OffboardingConfig{}
+	}
+
+	c.Log.Println("Offboarding", email)
+	report := c.RunWorkflow("offboarding", email, c.OffboardingSteps(email, cfg), cfg.WorkflowOptions)
+	c.Log.Println(report.String())
+
+	return report
+}
+
+/*
+ * # Build the offboarding sequence for a user
+ * - Returned separately from `Offboard` so callers can inspect, reorder or extend the steps before running them
+ */
+func (c *Client) OffboardingSteps(email string, cfg *OffboardingConfig) []Step {
+	steps := []Step{}
+
+	if c.Okta != nil {
+		steps = append(steps, c.oktaOffboardingSteps(email)...)
+	}
+	if c.Google != nil {
+		steps = append(steps, c.googleOffboardingSteps(email, cfg)...)
+	}
+	if c.Backupify != nil {
+		for _, app := range cfg.BackupifyApps {
+			steps = append(steps, c.backupifyExportStep(email, app))
+		}
+	}
+	if c.Jamf != nil && cfg.LockPIN != "" {
+		steps = append(steps, c.jamfLockStep(email, cfg))
+	}
+	if c.DocuSign != nil {
+		steps = append(steps, c.docuSignOffboardingStep(email, cfg))
+	}
+	if c.Slack != nil {
+		steps = append(steps, c.slackOffboardingStep(email))
+	}
+	if c.SnipeIT != nil {
+		steps = append(steps, c.snipeITOffboardingSteps(email, cfg)...)
+	}
+
+	steps = append(steps, cfg.Steps...)
+
+	return slices.DeleteFunc(steps, func(s Step) bool {
+		return slices.Contains(cfg.Skip, s.Name)
+	})
+}
+
+func (c *Client) oktaOffboardingSteps(email string) []Step {
+	return []Step{
+		{
+			Name:        "okta.clear_sessions",
+			Description: fmt.Sprintf("revoke all Okta sessions and OAuth tokens for %s", email),
+			Run: func() (string, error) {
+				user, err := c.Okta.GetUser(email)
+				if err != nil {
+					return "", err
+				}
+				if err := c.Okta.ClearUserSessions(user.ID); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("cleared sessions for %s", user.ID), nil
+			},
+		},
+		{
+			Name:        "okta.deactivate",
+			Description: fmt.Sprintf("deactivate %s in Okta", email),
+			Run: func() (string, error) {
+				user, err := c.Okta.GetUser(email)
+				if errors.Is(err, rerrors.ErrNotFound) {
+					return "no Okta account", nil
+				}
+				if err != nil {
+					return "", err
+				}
+				if user.Status == okta.DEPROVISIONED {
+					return "already deactivated", nil
+				}
+				if err := c.Okta.DeactivateUser(user.ID); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("deactivated %s", user.ID), nil
+			},
+		},
+	}
+}
+
+func (c *Client) googleOffboardingSteps(email string, cfg *OffboardingConfig) []Step {
+	steps := []Step{}
+
+	// Gmail rejects requests for suspended users, so the responder has to be set first
+	if cfg.OutOfOfficeMessage != "" {
+		steps = append(steps, Step{
+			Name:        "google.out_of_office",
+			Description: fmt.Sprintf("enable the Gmail vacation responder for %s", email),
+			Run: func() (string, error) {
+				err := c.asGoogleUser(email, func(g *google.Client) error {
+					_, err := g.Gmail().UpdateVacation(email, &google.VacationSettings{
+						EnableAutoReply:       true,
+						ResponseSubject:       cfg.OutOfOfficeSubject,
+						ResponseBodyPlainText: cfg.OutOfOfficeMessage,
+					})
+					return err
+				})
+				if err != nil {
+					return "", err
+				}
+				return "enabled vacation responder", nil
+			},
+		})
+	}
+
+	if cfg.TransferTo != "" {
+		steps = append(steps, Step{
+			Name:        "google.transfer_data",
+			Description: fmt.Sprintf("transfer Drive and Calendar data from %s to %s", email, cfg.TransferTo),
+			Run: func() (string, error) {
+				user, err := c.Google.Users().GetUser(email)
+				if err != nil {
+					return "", err
+				}
+				recipient, err := c.Google.Users().GetUser(cfg.TransferTo)
+				if err != nil {
+					return "", err
+				}
+
+				transfer, err := c.Google.DataTransfer().TransferData(user.ID, recipient.ID)
+				if err != nil {
+					return "", err
+				}
+				if transfer == nil {
+					return fmt.Sprintf("requested transfer to %s", cfg.TransferTo), nil
+				}
+				return fmt.Sprintf("transfer %s to %s is %s", transfer.ID, cfg.TransferTo, transfer.OverallTransferStatusCode), nil
+			},
+		})
+	}
+
+	steps = append(steps, Step{
+		Name:        "google.suspend",
+		Description: fmt.Sprintf("suspend %s in Google Workspace", email),
+		Run: func() (string, error) {
+			if _, err := c.Google.Users().SuspendUser(email); err != nil {
+				return "", err
+			}
+			return "suspended", nil
+		},
+	})
+
+	if cfg.SuspendedOU != "" {
+		steps = append(steps, Step{
+			Name:        "google.move_ou",
+			Description: fmt.Sprintf("move %s to %s", email, cfg.SuspendedOU),
+			Run: func() (string, error) {
+				if _, err := c.Google.Users().MoveUserToOU(email, cfg.SuspendedOU); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("moved to %s", cfg.SuspendedOU), nil
+			},
+		})
+	}
+
+	return steps
+}
+
+func (c *Client) backupifyExportStep(email string, app backupify.AppType) Step {
+	return Step{
+		Name:        fmt.Sprintf("backupify.export.%s", app),
+		Description: fmt.Sprintf("export %s backups for %s", app, email),
+		Run: func() (string, error) {
+			user, err := c.Backupify.Users().GetUserByEmail(app, email)
+			if err != nil {
+				return "", err
+			}
+			if len(user.Snapshots) == 0 {
+				return "", fmt.Errorf("no %s snapshots found for %s", app, email)
+			}
+
+			exports, err := c.Backupify.Exports().ExportUser(user)
+			if err != nil {
+				return "", err
+			}
+			if exports == nil {
+				return "requested export(s)", nil
+			}
+			return fmt.Sprintf("requested %d export(s)", len(*exports)), nil
+		},
+	}
+}
+
+func (c *Client) jamfLockStep(email string, cfg *OffboardingConfig) Step {
+	return Step{
+		Name:        "jamf.lock",
+		Description: fmt.Sprintf("lock every Jamf computer assigned to %s", email),
+		Run: func() (string, error) {
+			computers, err := c.Jamf.Devices().ListComputersByUser(email)
+			if err != nil {
+				return "", err
+			}
+			if computers.Results == nil || len(*computers.Results) == 0 {
+				return "no computers assigned", nil
+			}
+
+			locked := 0
+			for _, computer := range *computers.Results {
+				if computer.General == nil || computer.General.ManagementID == "" {
+					continue
+				}
+				if _, err := c.Jamf.LockComputer(computer.General.ManagementID, cfg.LockPIN, cfg.LockMessage); err != nil {
+					return "", fmt.Errorf("locking %s: %w", computer.General.Name, err)
+				}
+				locked++
+			}
+			return fmt.Sprintf("locked %d computer(s)", locked), nil
+		},
+	}
+}
+
+func (c *Client) docuSignOffboardingStep(email string, cfg *OffboardingConfig) Step {
+	description := fmt.Sprintf("close %s in DocuSign", email)
+	if cfg.TransferTo != "" {
+		description = fmt.Sprintf("transfer DocuSign envelopes from %s to %s, and close the user", email, cfg.TransferTo)
+	}
+	return Step{
+		Name:        "docusign.close",
+		Description: description,
+		Run: func() (string, error) {
+			if err := c.DocuSign.Users().OffboardUser(email, cfg.TransferTo); err != nil {
+				return "", err
+			}
+			if cfg.TransferTo != "" {
+				return fmt.Sprintf("transferred envelopes to %s and closed", cfg.TransferTo), nil
+			}
+			return "closed", nil
+		},
+	}
+}
+
+func (c *Client) slackOffboardingStep(email string) Step {
+	return Step{
+		Name:        "slack.deactivate",
+		Description: fmt.Sprintf("deactivate %s in Slack", email),
+		Run: func() (string, error) {
+			member, err := c.Slack.LookupUserByEmail(email)
+			if errors.Is(err, rerrors.ErrNotFound) {
+				return "no Slack account", nil
+			}
+			if err != nil {
+				return "", err
+			}
+			if member.Deleted {
+				return "already deactivated", nil
+			}
+			if err := c.Slack.DeactivateUser(member.ID); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("deactivated %s", member.ID), nil
+		},
+	}
+}
+
+func (c *Client) snipeITOffboardingSteps(email string, cfg *OffboardingConfig) []Step {
+	return []Step{
+		{
+			Name:        "snipeit.checkin",
+			Description: fmt.Sprintf("check in every Snipe-IT asset assigned to %s", email),
+			Run: func() (string, error) {
+				user, err := c.SnipeIT.Users().GetUserByEmail(email)
+				if err != nil {
+					return "", err
+				}
+				assets, err := c.SnipeIT.Users().GetUserAssets(user.ID)
+				if err != nil {
+					return "", err
+				}
+				if assets.Rows == nil {
+					return "no assets assigned", nil
+				}
+
+				for _, asset := range *assets.Rows {
+					if err := c.SnipeIT.Assets().CheckinAsset(asset.ID, cfg.CheckinNote); err != nil {
+						return "", fmt.Errorf("checking in %s: %w", asset.AssetTag, err)
+					}
+				}
+				return fmt.Sprintf("checked in %d asset(s)", len(*assets.Rows)), nil
+			},
+		},
+		{
+			Name:        "snipeit.deactivate",
+			Description: fmt.Sprintf("deactivate %s in Snipe-IT", email),
+			Run: func() (string, error) {
+				user, err := c.SnipeIT.Users().GetUserByEmail(email)
+				if err != nil {
+					return "", err
+				}
+				if err := c.SnipeIT.Users().DeactivateUser(user.ID); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("deactivated %d", user.ID), nil
+			},
+		},
+	}
+}
@@ -0,0 +1,431 @@
+/*
+# Orchestrators - Onboarding
+
+This package contains a cross-provider onboarding workflow. Given a new hire and a set of role/department templates,
+it provisions the user in every configured service and produces a per-step report which can be used to resume a
+partially failed run.
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC., see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/orchestrators/onboarding.go
+package orchestrators
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gemini-oss/rego/pkg/common/crypt"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+)
+
+// ### Onboarding Structs
+// ---------------------------------------------------------------------
+type NewHire struct {
+	FirstName      string   `json:"firstName"`                // First name of the new hire
+	LastName       string   `json:"lastName"`                 // Last name of the new hire
+	Email          string   `json:"email"`                    // Primary (work) email address; used as the Okta login and Google primary email
+	PersonalEmail  string   `json:"personalEmail,omitempty"`  // Personal email address, stored as the Okta secondary email
+	Title          string   `json:"title,omitempty"`          // Job title (role); used to select a template
+	Department     string   `json:"department,omitempty"`     // Department; used to select a template
+	Manager        string   `json:"manager,omitempty"`        // Email of the new hire's manager
+	EmployeeNumber string   `json:"employeeNumber,omitempty"` // Employee number/ID from the HR system
+	Templates      []string `json:"templates,omitempty"`      // Additional templates to apply, beyond the role and department templates
+	AssetTags      []string `json:"assetTags,omitempty"`      // Snipe-IT asset tags to check out to the new hire
+}
+
+// OnboardingTemplate describes the access granted to everyone in a role or department
+type OnboardingTemplate struct {
+	OktaGroups      []string          `json:"oktaGroups,omitempty"`      // Names of the Okta groups to assign
+	GoogleOU        string            `json:"googleOU,omitempty"`        // Organizational unit for the Google account, e.g. `/Engineering`
+	GoogleGroups    []string          `json:"googleGroups,omitempty"`    // Emails of the Google groups to join
+	SlackUsergroups []string          `json:"slackUsergroups,omitempty"` // Handles of the Slack user groups to join, e.g. `engineering`
+	CalendarInvites []*CalendarInvite `json:"calendarInvites,omitempty"` // Recurring events the new hire is invited to
+}
+
+// CalendarInvite identifies an existing Google Calendar event
+type CalendarInvite struct {
+	Organizer  string `json:"organizer"`            // Email of the event organizer; impersonated to edit the event
+	CalendarID string `json:"calendarId,omitempty"` // Calendar containing the event; defaults to the organizer's primary calendar
+	EventID    string `json:"eventId"`              // ID of the event
+}
+
+type OnboardingConfig struct {
+	Templates    map[string]*OnboardingTemplate // Templates keyed by role (title), department, or any other name referenced by `NewHire.Templates`
+	ActivateOkta bool                           // Activate the Okta user immediately, sending the activation email
+	CheckoutNote string                         // Note attached to each asset checked out to the new hire
+
+	Skip  []string // Names of default steps to skip, e.g. `slack.usergroups`
+	Steps []Step   // Additional steps appended to the default sequence
+
+	WorkflowOptions
+}
+
+// END OF ONBOARDING STRUCTS
+//---------------------------------------------------------------------
+
+/*
+ * # Load onboarding templates from a JSON file
+ * - The file is an object of template names to templates, e.g. `{"Engineering": {"oktaGroups": ["Engineering"]}}`
+ */
+func LoadOnboardingTemplates(path string) (map[string]*OnboardingTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := map[string]*OnboardingTemplate{}
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("unmarshalling templates: %w", err)
+	}
+	return templates, nil
+}
+
+/*
+ * # Resolve the template for a new hire
+ * - Merges the department template, the role (title) template and any explicitly requested templates, in that order
+ * - Template names are matched case-insensitively; duplicate entries are removed
+ */
+func (cfg *OnboardingConfig) Template(hire *NewHire) (*OnboardingTemplate, error) {
+	names := []string{}
+	if hire.Department != "" {
+		names = append(names, hire.Department)
+	}
+	if hire.Title != "" {
+		names = append(names, hire.Title)
+	}
+
+	merged := &OnboardingTemplate{}
+	matched := 0
+	for _, name := range names {
+		if t := cfg.lookupTemplate(name); t != nil {
+			merged.merge(t)
+			matched++
+		}
+	}
+
+	// Explicitly requested templates must exist
+	for _, name := range hire.Templates {
+		t := cfg.lookupTemplate(name)
+		if t == nil {
+			return nil, fmt.Errorf("onboarding template %s not found", name)
+		}
+		merged.merge(t)
+		matched++
+	}
+
+	if matched == 0 {
+		return nil, fmt.Errorf("no onboarding template found for %s (department: %q, title: %q)", hire.Email, hire.Department, hire.Title)
+	}
+
+	return merged, nil
+}
+
+func (cfg *OnboardingConfig) lookupTemplate(name string) *OnboardingTemplate {
+	for key, t := range cfg.Templates {
+		if strings.EqualFold(key, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+func (t *OnboardingTemplate) merge(other *OnboardingTemplate) {
+	if other.GoogleOU != "" {
+		t.GoogleOU = other.GoogleOU
+	}
+	t.OktaGroups = appendUnique(t.OktaGroups, other.OktaGroups...)
+	t.GoogleGroups = appendUnique(t.GoogleGroups, other.GoogleGroups...)
+	t.SlackUsergroups = appendUnique(t.SlackUsergroups, other.SlackUsergroups...)
+	for _, invite := range other.CalendarInvites {
+		if !slices.ContainsFunc(t.CalendarInvites, func(i *CalendarInvite) bool { return *i == *invite }) {
+			t.CalendarInvites = append(t.CalendarInvites, invite)
+		}
+	}
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+/*
+ * # Onboard a new hire across every configured service
+ * - Okta: create the user, assign template groups
+ * - Google: create the user in the template OU, join template groups, accept calendar invites
+ * - Slack: join template user groups
+ * - Snipe-IT: check out the new hire's assets
+ * Services without a client on the orchestrator are left out of the sequence.
+ *
+ * Set `cfg.DryRun` to preview the steps, and pass the report of a failed run as `cfg.Resume` to retry only the steps
+ * which did not succeed (e.g. Slack, once the user has been provisioned there by SCIM).
+ */
+func (c *Client) Onboard(hire *NewHire, cfg *OnboardingConfig) (*Report, error) {
+	if cfg == nil {
+		cfg = &OnboardingConfig{}
+	}
+
+	steps, err := c.OnboardingSteps(hire, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Log.Println("Onboarding", hire.Email)
+	report := c.RunWorkflow("onboarding", hire.Email, steps, cfg.WorkflowOptions)
+	c.Log.Println(report.String())
+
+	return report, nil
+}
+
+/*
+ * # Build the onboarding sequence for a new hire
+ * - Returned separately from `Onboard` so callers can inspect, reorder or extend the steps before running them
+ */
+func (c *Client) OnboardingSteps(hire *NewHire, cfg *OnboardingConfig) ([]Step, error) {
+	if hire.Email == "" || hire.FirstName == "" || hire.LastName == "" {
+		return nil, fmt.Errorf("new hire requires a first name, last name and email")
+	}
+
+	template, err := cfg.Template(hire)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := []Step{}
+
+	if c.Okta != nil {
+		steps = append(steps, c.oktaOnboardingSteps(hire, template, cfg)...)
+	}
+	if c.Google != nil {
+		steps = append(steps, c.googleOnboardingSteps(hire, template)...)
+	}
+	if c.Slack != nil && len(template.SlackUsergroups) > 0 {
+		steps = append(steps, c.slackOnboardingStep(hire, template))
+	}
+	if c.SnipeIT != nil && len(hire.AssetTags) > 0 {
+		steps = append(steps, c.snipeITOnboardingStep(hire, cfg))
+	}
+
+	steps = append(steps, cfg.Steps...)
+
+	return slices.DeleteFunc(steps, func(s Step) bool {
+		return slices.Contains(cfg.Skip, s.Name)
+	}), nil
+}
+
+func (c *Client) oktaOnboardingSteps(hire *NewHire, template *OnboardingTemplate, cfg *OnboardingConfig) []Step {
+	steps := []Step{
+		{
+			Name:        "okta.create",
+			Description: fmt.Sprintf("create %s in Okta (activate: %t)", hire.Email, cfg.ActivateOkta),
+			Run: func() (string, error) {
+				if user, err := c.Okta.GetUser(hire.Email); err == nil {
+					return fmt.Sprintf("already exists as %s", user.ID), nil
+				}
+
+				user, err := c.Okta.CreateUser(&okta.UserProfile{
+					Department:     hire.Department,
+					DisplayName:    fmt.Sprintf("%s %s", hire.FirstName, hire.LastName),
+					Email:          hire.Email,
+					EmployeeNumber: hire.EmployeeNumber,
+					FirstName:      hire.FirstName,
+					LastName:       hire.LastName,
+					Login:          hire.Email,
+					SecondEmail:    hire.PersonalEmail,
+					Title:          hire.Title,
+				}, nil, cfg.ActivateOkta)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("created %s (%s)", user.ID, user.Status), nil
+			},
+		},
+	}
+
+	if len(template.OktaGroups) > 0 {
+		steps = append(steps, Step{
+			Name:        "okta.groups",
+			Description: fmt.Sprintf("assign %s to Okta groups %v", hire.Email, template.OktaGroups),
+			Run: func() (string, error) {
+				user, err := c.Okta.GetUser(hire.Email)
+				if err != nil {
+					return "", err
+				}
+
+				for _, name := range template.OktaGroups {
+					group, err := c.Okta.GetGroupByName(name)
+					if err != nil {
+						return "", err
+					}
+					if err := c.Okta.AddUserToGroup(group.ID, user.ID); err != nil {
+						return "", fmt.Errorf("assigning %s: %w", name, err)
+					}
+				}
+				return fmt.Sprintf("assigned %d group(s)", len(template.OktaGroups)), nil
+			},
+		})
+	}
+
+	return steps
+}
+
+func (c *Client) googleOnboardingSteps(hire *NewHire, template *OnboardingTemplate) []Step {
+	orgUnit := template.GoogleOU
+	if orgUnit == "" {
+		orgUnit = "/"
+	}
+
+	steps := []Step{
+		{
+			Name:        "google.create",
+			Description: fmt.Sprintf("create %s in Google Workspace under %s", hire.Email, orgUnit),
+			Run: func() (string, error) {
+				if user, err := c.Google.Users().GetUser(hire.Email); err == nil {
+					return fmt.Sprintf("already exists as %s", user.ID), nil
+				}
+
+				// The password is never shared; new hires sign in through SSO or reset it on first login
+				password, err := randomPassword(32)
+				if err != nil {
+					return "", err
+				}
+
+				user, err := c.Google.Users().CreateUser(map[string]interface{}{
+					"primaryEmail": hire.Email,
+					"name": map[string]string{
+						"givenName":  hire.FirstName,
+						"familyName": hire.LastName,
+					},
+					"password":                  password,
+					"changePasswordAtNextLogin": true,
+					"orgUnitPath":               orgUnit,
+				})
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("created %s in %s", user.ID, orgUnit), nil
+			},
+		},
+	}
+
+	if len(template.GoogleGroups) > 0 {
+		steps = append(steps, Step{
+			Name:        "google.groups",
+			Description: fmt.Sprintf("add %s to Google groups %v", hire.Email, template.GoogleGroups),
+			Run: func() (string, error) {
+				for _, group := range template.GoogleGroups {
+					if _, err := c.Google.Groups().AddMember(group, hire.Email, "MEMBER"); err != nil {
+						// Re-running after a partial failure should not fail on groups which were already joined
+						if errors.Is(err, rerrors.ErrConflict) {
+							continue
+						}
+						return "", fmt.Errorf("adding to %s: %w", group, err)
+					}
+				}
+				return fmt.Sprintf("joined %d group(s)", len(template.GoogleGroups)), nil
+			},
+		})
+	}
+
+	if len(template.CalendarInvites) > 0 {
+		steps = append(steps, Step{
+			Name:        "google.calendar",
+			Description: fmt.Sprintf("invite %s to %d calendar event(s)", hire.Email, len(template.CalendarInvites)),
+			Run: func() (string, error) {
+				for _, invite := range template.CalendarInvites {
+					calendarID := invite.CalendarID
+					if calendarID == "" {
+						calendarID = "primary"
+					}
+
+					err := c.asGoogleUser(invite.Organizer, func(g *google.Client) error {
+						_, err := g.Calendar().AddAttendees(calendarID, invite.EventID, hire.Email)
+						return err
+					})
+					if err != nil {
+						return "", fmt.Errorf("inviting to %s: %w", invite.EventID, err)
+					}
+				}
+				return fmt.Sprintf("invited to %d event(s)", len(template.CalendarInvites)), nil
+			},
+		})
+	}
+
+	return steps
+}
+
+func (c *Client) slackOnboardingStep(hire *NewHire, template *OnboardingTemplate) Step {
+	return Step{
+		Name:        "slack.usergroups",
+		Description: fmt.Sprintf("add %s to Slack user groups %v", hire.Email, template.SlackUsergroups),
+		Run: func() (string, error) {
+			// Slack accounts are usually provisioned by Okta, so this step may need to be resumed later
+			member, err := c.Slack.LookupUserByEmail(hire.Email)
+			if err != nil {
+				return "", err
+			}
+
+			for _, handle := range template.SlackUsergroups {
+				usergroup, err := c.Slack.GetUsergroup(handle)
+				if err != nil {
+					return "", err
+				}
+				if _, err := c.Slack.AddUserToUsergroup(usergroup.ID, member.ID); err != nil {
+					return "", err
+				}
+			}
+			return fmt.Sprintf("joined %d user group(s)", len(template.SlackUsergroups)), nil
+		},
+	}
+}
+
+func (c *Client) snipeITOnboardingStep(hire *NewHire, cfg *OnboardingConfig) Step {
+	return Step{
+		Name:        "snipeit.checkout",
+		Description: fmt.Sprintf("check out assets %v to %s", hire.AssetTags, hire.Email),
+		Run: func() (string, error) {
+			user, err := c.SnipeIT.Users().GetUserByEmail(hire.Email)
+			if err != nil {
+				return "", err
+			}
+
+			for _, tag := range hire.AssetTags {
+				asset, err := c.SnipeIT.Assets().GetAssetByTag(tag)
+				if err != nil {
+					return "", err
+				}
+				if err := c.SnipeIT.Assets().CheckoutAsset(asset.ID, user.ID, cfg.CheckoutNote); err != nil {
+					return "", err
+				}
+			}
+			return fmt.Sprintf("checked out %d asset(s)", len(hire.AssetTags)), nil
+		},
+	}
+}
+
+// randomPassword generates a password from a secure random source
+func randomPassword(length int) (string, error) {
+	const charset = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789!@#$%^&*-_=+"
+
+	var sb strings.Builder
+	for i := 0; i < length; i++ {
+		n, err := crypt.SecureRandomInt(len(charset))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(charset[n])
+	}
+	return sb.String(), nil
+}
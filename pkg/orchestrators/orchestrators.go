@@ -12,24 +12,167 @@ This package contains some functions involving practical examples of multi-servi
 package orchestrators
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/gemini-oss/rego/pkg/active_directory"
+	"github.com/gemini-oss/rego/pkg/backupify"
+	"github.com/gemini-oss/rego/pkg/common/events"
 	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/notify"
+	"github.com/gemini-oss/rego/pkg/common/policy"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/docusign"
 	"github.com/gemini-oss/rego/pkg/google"
 	"github.com/gemini-oss/rego/pkg/jamf"
 	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/slack"
 	"github.com/gemini-oss/rego/pkg/snipeit"
 )
 
 type Client struct {
 	Log             *log.Logger
 	ActiveDirectory *active_directory.Client
+	Backupify       *backupify.Client
+	DocuSign        *docusign.Client
 	Google          *google.Client
 	Jamf            *jamf.Client
 	Okta            *okta.Client
+	Slack           *slack.Client
 	SnipeIT         *snipeit.Client
+	Plan            *requests.Plan   // Mutations recorded by the clients while in dry-run mode
+	Events          events.Publisher // Receives the outcome of every workflow which is run; optional
+	Notifier        notify.Notifier  // Receives a summary of every workflow which is run; optional
+	NotifyTemplate  *notify.Template // Template of the summaries; `WorkflowTemplate` when nil
+	Policy          *policy.Engine   // Checks every workflow before it runs; optional
+}
+
+/*
+ * # Enable Dry-Run Mode
+ * Puts every configured client into dry-run mode, recording their mutations in a shared plan instead of sending them
+ * - Unlike `WorkflowOptions.DryRun`, steps still run: reads are sent, so the plan reflects the actual state of each service
+ * - The requests planned by each step are attached to its result in the report
+ * - Active Directory is not an HTTP client and is left as-is
+ */
+func (c *Client) EnableDryRun(plan *requests.Plan) *requests.Plan {
+	if plan == nil {
+		plan = requests.NewPlan()
+	}
+	c.Plan = plan
+
+	for _, hc := range c.httpClients() {
+		hc.EnableDryRun(plan)
+	}
+	return plan
+}
+
+/*
+ * # Enforce Policies
+ * Checks every workflow, and every mutating request of the configured clients, against the policies of `engine`
+ * - A denied workflow runs none of its steps; a denied request fails its step
+ * - Active Directory is not an HTTP client and is left as-is
+ */
+func (c *Client) EnforcePolicy(engine *policy.Engine) {
+	c.Policy = engine
+	for provider, hc := range c.httpClients() {
+		hc.Authorize = engine.Authorizer(provider)
+	}
+}
+
+/*
+ * # With Context
+ * Returns a copy of the orchestrator whose clients send their requests bound to `ctx`, e.g. the context of an API request
+ * - Cancelling `ctx` aborts the requests of the steps running with the copy
+ * - Active Directory is not an HTTP client and is left as-is
+ */
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cc := *c
+	if c.Backupify != nil {
+		cc.Backupify = c.Backupify.WithContext(ctx)
+	}
+	if c.DocuSign != nil {
+		cc.DocuSign = c.DocuSign.WithContext(ctx)
+	}
+	if c.Google != nil {
+		cc.Google = c.Google.WithContext(ctx)
+	}
+	if c.Jamf != nil {
+		cc.Jamf = c.Jamf.WithContext(ctx)
+	}
+	if c.Okta != nil {
+		cc.Okta = c.Okta.WithContext(ctx)
+	}
+	if c.Slack != nil {
+		cc.Slack = c.Slack.WithContext(ctx)
+	}
+	if c.SnipeIT != nil {
+		cc.SnipeIT = c.SnipeIT.WithContext(ctx)
+	}
+	return &cc
+}
+
+/*
+ * # Close the Clients
+ * Closes every configured client, waiting for their requests in flight until `ctx` is done, and writes their caches to disk
+ * - Every client is closed even when another fails; their errors are joined
+ * - Stop the scheduler (or server) running workflows first, so no step is cut short by a closed client
+ */
+func (c *Client) Close(ctx context.Context) error {
+	var errs []error
+	if c.ActiveDirectory != nil {
+		errs = append(errs, c.ActiveDirectory.Close(ctx))
+	}
+	if c.Backupify != nil {
+		errs = append(errs, c.Backupify.Close(ctx))
+	}
+	if c.DocuSign != nil {
+		errs = append(errs, c.DocuSign.Close(ctx))
+	}
+	if c.Google != nil {
+		errs = append(errs, c.Google.Close(ctx))
+	}
+	if c.Jamf != nil {
+		errs = append(errs, c.Jamf.Close(ctx))
+	}
+	if c.Okta != nil {
+		errs = append(errs, c.Okta.Close(ctx))
+	}
+	if c.Slack != nil {
+		errs = append(errs, c.Slack.Close(ctx))
+	}
+	if c.SnipeIT != nil {
+		errs = append(errs, c.SnipeIT.Close(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// httpClients returns the HTTP clients of every configured service, by name
+func (c *Client) httpClients() map[string]*requests.Client {
+	clients := map[string]*requests.Client{}
+	if c.Backupify != nil {
+		clients["backupify"] = c.Backupify.HTTP
+	}
+	if c.DocuSign != nil {
+		clients["docusign"] = c.DocuSign.HTTP
+	}
+	if c.Google != nil {
+		clients["google"] = c.Google.HTTP
+	}
+	if c.Jamf != nil {
+		clients["jamf"] = c.Jamf.HTTP
+	}
+	if c.Okta != nil {
+		clients["okta"] = c.Okta.HTTP
+	}
+	if c.Slack != nil {
+		clients["slack"] = c.Slack.HTTP
+	}
+	if c.SnipeIT != nil {
+		clients["snipeit"] = c.SnipeIT.HTTP
+	}
+	return clients
 }
 
 /*
@@ -70,7 +213,7 @@ func (c *Client) OktaRoleReportToGoogleSheet() error {
 
 	for _, report := range *roleReports {
 		for _, user := range *report.Users {
-			vr.Values = append(vr.Values, []string{user.ID, user.Profile.Email, user.Profile.Login, user.Status, report.Role.ID, report.Role.Label, report.Role.AssignmentType, user.LastLogin.String()})
+			vr.Values = append(vr.Values, []string{user.ID, user.Profile.Email, user.Profile.Login, string(user.Status), report.Role.ID, report.Role.Label, report.Role.AssignmentType, user.LastLogin.String()})
 		}
 	}
 
@@ -151,3 +294,16 @@ func (c *Client) ADReportToGoogleSheet(group string) error {
 
 	return nil
 }
+
+/*
+ * Run `fn` with a copy of the Google client which impersonates `email`
+ * - Required for user-scoped APIs such as Gmail settings and Calendar events
+ * - The shared client is left unchanged, so concurrent steps keep their own subject
+ */
+func (c *Client) asGoogleUser(email string, fn func(g *google.Client) error) error {
+	g, err := c.Google.AsUser(email)
+	if err != nil {
+		return err
+	}
+	return fn(g)
+}
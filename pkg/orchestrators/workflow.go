@@ -0,0 +1,384 @@
+/*
+# Orchestrators - Workflow
+
+This package contains a small step runner used by the multi-service orchestrations (offboarding, onboarding, etc.)
+Each step is executed in order with retries, and its outcome is recorded in a final report.
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC., see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/orchestrators/workflow.go
+package orchestrators
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/events"
+	"github.com/gemini-oss/rego/pkg/common/notify"
+	"github.com/gemini-oss/rego/pkg/common/policy"
+	"github.com/gemini-oss/rego/pkg/common/requests"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+// ### Workflow Structs
+// ---------------------------------------------------------------------
+
+// Step is a single unit of work in a workflow
+type Step struct {
+	Name        string                 // Name of the step, e.g. `okta.deactivate`
+	Description string                 // What the step will do; reported instead of running the step during a dry run
+	Run         func() (string, error) // Performs the step, returning a short description of what was done
+}
+
+type StepStatus string
+
+const (
+	StepSucceeded StepStatus = "succeeded" // The step completed successfully
+	StepFailed    StepStatus = "failed"    // The step failed after exhausting all attempts
+	StepSkipped   StepStatus = "skipped"   // The step was not executed
+	StepPlanned   StepStatus = "planned"   // The step would have been executed, but the workflow was a dry run
+)
+
+type StepResult struct {
+	Step     string        `json:"step"`              // Name of the step
+	Status   StepStatus    `json:"status"`            // Outcome of the step {succeeded, failed, skipped, planned}
+	Detail   string        `json:"detail,omitempty"`  // Description of what was done (or why it was skipped)
+	Error    string        `json:"error,omitempty"`   // Error from the final attempt, if the step failed
+	Attempts int           `json:"attempts"`          // Number of attempts made
+	Started  time.Time     `json:"started,omitempty"` // Time the first attempt started
+	Duration time.Duration `json:"duration"`          // Total time spent on the step, including retries
+
+	Requests []*requests.PlannedRequest `json:"requests,omitempty"` // Mutations the step would have made, when the clients are in dry-run mode
+}
+
+type WorkflowOptions struct {
+	Retries     int           // Number of additional attempts for a failed step
+	RetryDelay  time.Duration // Delay between attempts; exponential backoff with jitter when zero
+	StopOnError bool          // Skip all remaining steps after the first failure
+	DryRun      bool          // Report the steps that would run without executing any of them
+	Resume      *Report       // A previous report of the same workflow; steps which already succeeded are not run again
+}
+
+type Report struct {
+	Workflow string        `json:"workflow"` // Name of the workflow, e.g. `offboarding`
+	Subject  string        `json:"subject"`  // Who/what the workflow ran against, e.g. an email address
+	Started  time.Time     `json:"started"`  // Time the workflow started
+	Finished time.Time     `json:"finished"` // Time the workflow finished
+	Results  []*StepResult `json:"results"`  // Per-step results, in execution order
+}
+
+// END OF WORKFLOW STRUCTS
+//---------------------------------------------------------------------
+
+// Succeeded reports whether no step in the workflow failed
+func (r *Report) Succeeded() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns the results of all failed steps
+func (r *Report) Failed() []*StepResult {
+	failed := []*StepResult{}
+	for _, result := range r.Results {
+		if result.Status == StepFailed {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Result returns the result of the named step, or nil if it is not part of the report
+func (r *Report) Result(step string) *StepResult {
+	for _, result := range r.Results {
+		if result.Step == step {
+			return result
+		}
+	}
+	return nil
+}
+
+// String renders the report as a human-readable table
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s report for %s (%s)\n", r.Workflow, r.Subject, r.Finished.Sub(r.Started).Round(time.Millisecond))
+
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STEP\tSTATUS\tATTEMPTS\tDETAIL")
+	for _, result := range r.Results {
+		detail := result.Detail
+		if result.Error != "" {
+			detail = result.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", result.Step, result.Status, result.Attempts, detail)
+		for _, r := range result.Requests {
+			fmt.Fprintf(w, "\t\t\t[dry run] %s %s\n", r.Method, r.URL)
+		}
+	}
+	w.Flush()
+
+	return sb.String()
+}
+
+// Save writes the report to a JSON file, so a partially failed workflow can be resumed later
+func (r *Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// LoadReport reads a report previously written with `Report.Save`
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("unmarshalling report: %w", err)
+	}
+	return report, nil
+}
+
+/*
+ * # Run a workflow
+ * - Executes each step in order, retrying failed steps up to `opts.Retries` times
+ * - When `opts.StopOnError` is set, every step after the first failure is marked as skipped
+ * - When `opts.DryRun` is set, no step is executed and each is reported as planned
+ * - When `opts.Resume` is set, steps which succeeded in that report are carried over instead of being run again
+ * - When `Client.Policy` is set, the workflow is checked first; if it is denied, a failed `policy` result is recorded and
+ *   every step is skipped
+ */
+func (c *Client) RunWorkflow(workflow, subject string, steps []Step, opts WorkflowOptions) *Report {
+	report := &Report{
+		Workflow: workflow,
+		Subject:  subject,
+		Started:  time.Now(),
+	}
+
+	if opts.Resume != nil && (opts.Resume.Workflow != workflow || opts.Resume.Subject != subject) {
+		c.Log.Warning(fmt.Sprintf("ignoring %s report for %s; it does not match %s for %s", opts.Resume.Workflow, opts.Resume.Subject, workflow, subject))
+		opts.Resume = nil
+	}
+
+	blocked := c.checkPolicy(workflow, subject, steps)
+	if blocked != nil {
+		report.Results = append(report.Results, blocked)
+	}
+
+	halted := false
+	for _, step := range steps {
+		if opts.Resume != nil {
+			if previous := opts.Resume.Result(step.Name); previous != nil && previous.Status == StepSucceeded {
+				c.Log.Println(fmt.Sprintf("[%s] already completed: %s", step.Name, previous.Detail))
+				report.Results = append(report.Results, previous)
+				continue
+			}
+		}
+
+		if blocked != nil {
+			report.Results = append(report.Results, &StepResult{
+				Step:   step.Name,
+				Status: StepSkipped,
+				Detail: "blocked by policy",
+			})
+			continue
+		}
+
+		if opts.DryRun {
+			report.Results = append(report.Results, &StepResult{
+				Step:   step.Name,
+				Status: StepPlanned,
+				Detail: step.Description,
+			})
+			continue
+		}
+
+		if halted {
+			report.Results = append(report.Results, &StepResult{
+				Step:   step.Name,
+				Status: StepSkipped,
+				Detail: "previous step failed",
+			})
+			continue
+		}
+
+		result := c.runStep(step, opts)
+		report.Results = append(report.Results, result)
+
+		if result.Status == StepFailed && opts.StopOnError {
+			halted = true
+		}
+	}
+
+	report.Finished = time.Now()
+	if !opts.DryRun {
+		c.publish(report)
+		c.notify(report)
+	}
+	return report
+}
+
+// checkPolicy checks a workflow against the policy engine, returning a failed result if it is denied
+func (c *Client) checkPolicy(workflow, subject string, steps []Step) *StepResult {
+	if c.Policy == nil {
+		return nil
+	}
+
+	names := make([]string, len(steps))
+	for i, step := range steps {
+		names[i] = step.Name
+	}
+	err := c.Policy.Check(context.Background(), &policy.Input{
+		Action:  policy.ActionWorkflow + workflow,
+		Subject: subject,
+		Data:    map[string]interface{}{"steps": names},
+	})
+	if err == nil {
+		return nil
+	}
+
+	c.Log.Error(err)
+	return &StepResult{
+		Step:    "policy",
+		Status:  StepFailed,
+		Detail:  "workflow denied",
+		Error:   err.Error(),
+		Started: time.Now(),
+	}
+}
+
+// WorkflowTemplate renders the summary of a workflow sent to `Client.Notifier`
+var WorkflowTemplate = notify.Template{
+	Title: `{{.Workflow}} {{if .Succeeded}}succeeded{{else}}failed{{end}} for {{.Subject}}`,
+	Body: `{{range .Failed}}{{.Step}}: {{.Error}}
+{{end}}`,
+	Fields: []notify.Field{
+		{Name: "Steps", Value: `{{len .Results}} ({{len .Failed}} failed)`},
+		{Name: "Duration", Value: `{{round (.Finished.Sub .Started)}}`},
+	},
+}
+
+// notify sends the summary of a workflow to the notifier, if there is one
+func (c *Client) notify(report *Report) {
+	if c.Notifier == nil {
+		return
+	}
+
+	tmpl := WorkflowTemplate
+	if c.NotifyTemplate != nil {
+		tmpl = *c.NotifyTemplate
+	}
+	m, err := notify.Render(tmpl, report)
+	if err != nil {
+		c.Log.Errorf("Rendering the summary of %s for %s: %v", report.Workflow, report.Subject, err)
+		return
+	}
+	m.Source = "orchestrators"
+	m.DedupKey = fmt.Sprintf("orchestrators/%s/%s", report.Workflow, report.Subject)
+	m.Severity = notify.Success
+	if !report.Succeeded() {
+		m.Severity = notify.Failure
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := c.Notifier.Notify(ctx, m); err != nil {
+		c.Log.Errorf("Notifying the outcome of %s for %s: %v", report.Workflow, report.Subject, err)
+	}
+}
+
+// lifecycleEvents are published when the workflow of the same name succeeds
+var lifecycleEvents = map[string]string{
+	"onboarding":  events.UserOnboarded,
+	"offboarding": events.UserOffboarded,
+}
+
+// publish announces the outcome of a workflow on the event bus, if there is one
+// - Successful onboarding and offboarding are also announced as `user.onboarded` and `user.offboarded`
+func (c *Client) publish(report *Report) {
+	if c.Events == nil {
+		return
+	}
+
+	outcome := &events.Event{
+		Type:     events.WorkflowCompleted,
+		Source:   "orchestrators",
+		Time:     report.Finished.UTC(),
+		Subjects: []string{report.Subject},
+		Data:     report,
+	}
+	published := []*events.Event{outcome}
+	if !report.Succeeded() {
+		outcome.Type = events.WorkflowFailed
+	} else if lifecycle, ok := lifecycleEvents[report.Workflow]; ok {
+		published = append(published, &events.Event{
+			Type:     lifecycle,
+			Source:   "orchestrators",
+			Time:     report.Finished.UTC(),
+			Subjects: []string{report.Subject},
+			Data:     report,
+		})
+	}
+
+	if err := c.Events.Publish(published...); err != nil {
+		c.Log.Errorf("Publishing the outcome of %s for %s: %v", report.Workflow, report.Subject, err)
+	}
+}
+
+// runStep executes a single step with retries
+func (c *Client) runStep(step Step, opts WorkflowOptions) *StepResult {
+	result := &StepResult{
+		Step:    step.Name,
+		Started: time.Now(),
+	}
+
+	for attempt := 0; attempt <= opts.Retries; attempt++ {
+		if attempt > 0 {
+			delay := opts.RetryDelay
+			if delay == 0 {
+				delay = retry.BackoffWithJitter(attempt - 1)
+			}
+			time.Sleep(delay)
+		}
+
+		result.Attempts++
+		planned := 0
+		if c.Plan != nil {
+			planned = c.Plan.Len()
+		}
+		detail, err := step.Run()
+		if c.Plan != nil {
+			result.Requests = c.Plan.Since(planned)
+		}
+		if err == nil {
+			result.Status = StepSucceeded
+			result.Detail = detail
+			result.Error = ""
+			break
+		}
+
+		result.Status = StepFailed
+		result.Error = err.Error()
+		c.Log.Warning(fmt.Sprintf("[%s] attempt %d/%d failed: %v", step.Name, attempt+1, opts.Retries+1, err))
+	}
+
+	result.Duration = time.Since(result.Started)
+
+	if result.Status == StepSucceeded {
+		c.Log.Println(fmt.Sprintf("[%s] %s", step.Name, result.Detail))
+	} else {
+		c.Log.Error(fmt.Sprintf("[%s] failed after %d attempt(s): %s", step.Name, result.Attempts, result.Error))
+	}
+
+	return result
+}
@@ -0,0 +1,350 @@
+/*
+# Reports - Access Review
+
+This package generates the artifacts of a quarterly access review:
+  - Administrative role holders per provider
+  - Application assignments per user
+  - Privileged group membership
+  - Dormant accounts
+
+Each row, and each dataset in the evidence sheet, carries the time it was collected.
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/reports/accessreview.go
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/exporters"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/slack"
+)
+
+// Default number of days without a sign-in before an account is dormant
+const DefaultDormantDays = 90
+
+// Names of the datasets of an access review
+const (
+	DatasetAdmins   = "admin_roles"
+	DatasetApps     = "app_assignments"
+	DatasetGroups   = "privileged_groups"
+	DatasetAccounts = "accounts"
+)
+
+/*
+ * # New Access Review
+ * - Rows are added with the `Add*` methods (or collected from a client with the `Collect*` methods)
+ * - `Sheets`, `Export` and `SaveToGoogleSheet` emit the artifacts
+ */
+func NewAccessReview(cfg AccessReviewConfig) *AccessReview {
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	if cfg.DormantDays <= 0 {
+		cfg.DormantDays = DefaultDormantDays
+	}
+
+	now := cfg.Now()
+	if cfg.Period == "" {
+		cfg.Period = Quarter(now)
+	}
+
+	r := &AccessReview{
+		Period:      cfg.Period,
+		DormantDays: cfg.DormantDays,
+		Admins:      []*AdminRole{},
+		Apps:        []*AppAssignment{},
+		Groups:      []*GroupMembership{},
+		Dormant:     []*DormantAccount{},
+		config:      cfg,
+	}
+	r.Report = newReport(r, now, cfg.Collector)
+	return r
+}
+
+// Quarter returns the quarter a time falls in, e.g. `2024-Q3`
+func Quarter(t time.Time) string {
+	return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+}
+
+// AddAdmins adds administrative role holders to the review
+func (r *AccessReview) AddAdmins(admins ...*AdminRole) {
+	for _, a := range admins {
+		if a.CollectedAt.IsZero() {
+			a.CollectedAt = r.config.Now()
+		}
+		r.Admins = append(r.Admins, a)
+	}
+}
+
+// AddApps adds application assignments to the review
+func (r *AccessReview) AddApps(apps ...*AppAssignment) {
+	for _, a := range apps {
+		if a.CollectedAt.IsZero() {
+			a.CollectedAt = r.config.Now()
+		}
+		r.Apps = append(r.Apps, a)
+	}
+}
+
+// AddGroupMembers adds the members of a group to the review, if the group is privileged
+func (r *AccessReview) AddGroupMembers(members ...*GroupMembership) {
+	for _, m := range members {
+		if !r.Privileged(m.Group) {
+			continue
+		}
+		if m.CollectedAt.IsZero() {
+			m.CollectedAt = r.config.Now()
+		}
+		r.Groups = append(r.Groups, m)
+	}
+}
+
+// Privileged reports whether a group name matches one of the configured privileged group patterns
+func (r *AccessReview) Privileged(group string) bool {
+	name := strings.ToLower(group)
+	for _, pattern := range r.config.PrivilegedGroups {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * # Add Accounts
+ * Evaluates accounts for dormancy; active accounts are dormant when their last sign-in (or creation, if they never
+ * signed in) is older than `DormantDays`
+ */
+func (r *AccessReview) AddAccounts(accounts ...*Account) {
+	now := r.config.Now()
+	for _, a := range accounts {
+		if !a.Active {
+			continue
+		}
+
+		since := a.LastLogin
+		if since.IsZero() {
+			since = a.Created
+		}
+
+		// Without a sign-in or creation time, the account cannot be shown to be in use
+		days := -1
+		if !since.IsZero() {
+			days = int(now.Sub(since).Hours() / 24)
+			if days < r.DormantDays {
+				continue
+			}
+		}
+
+		r.Dormant = append(r.Dormant, &DormantAccount{
+			Source:        a.Source,
+			UserID:        a.UserID,
+			Email:         a.Email,
+			Status:        a.Status,
+			Created:       a.Created,
+			LastLogin:     a.LastLogin,
+			NeverLoggedIn: a.LastLogin.IsZero(),
+			DaysInactive:  days,
+			CollectedAt:   now,
+		})
+	}
+}
+
+// order sorts every section by source, then email, so reviews of consecutive periods can be compared
+func (r *AccessReview) order() {
+	sort.SliceStable(r.Admins, func(i, j int) bool {
+		return less(r.Admins[i].Source, r.Admins[j].Source, r.Admins[i].Email+r.Admins[i].Role, r.Admins[j].Email+r.Admins[j].Role)
+	})
+	sort.SliceStable(r.Apps, func(i, j int) bool {
+		return less(r.Apps[i].Source, r.Apps[j].Source, r.Apps[i].Email+r.Apps[i].App, r.Apps[j].Email+r.Apps[j].App)
+	})
+	sort.SliceStable(r.Groups, func(i, j int) bool {
+		return less(r.Groups[i].Source, r.Groups[j].Source, r.Groups[i].Group+r.Groups[i].Email, r.Groups[j].Group+r.Groups[j].Email)
+	})
+	sort.SliceStable(r.Dormant, func(i, j int) bool {
+		return less(r.Dormant[i].Source, r.Dormant[j].Source, r.Dormant[i].Email, r.Dormant[j].Email)
+	})
+}
+
+func less(a, b Source, x, y string) bool {
+	if a != b {
+		return a < b
+	}
+	return strings.ToLower(x) < strings.ToLower(y)
+}
+
+/*
+ * # Sheets
+ * Returns the artifacts of the review, one sheet per section, preceded by the evidence of each dataset
+ */
+func (r *AccessReview) Sheets() []exporters.Sheet {
+	r.order()
+	return []exporters.Sheet{
+		{Name: "Evidence", Data: r.Evidence},
+		{Name: "Admin Roles", Data: r.Admins},
+		{Name: "App Assignments", Data: r.Apps},
+		{Name: "Privileged Groups", Data: r.Groups},
+		{Name: "Dormant Accounts", Data: r.Dormant},
+	}
+}
+
+// Title returns the title of the review, e.g. `Access Review 2024-Q3`
+func (r *AccessReview) Title() string {
+	return fmt.Sprintf("Access Review %s", r.Period)
+}
+
+/*
+ * # Collect from Okta
+ * - Admin roles of every active user
+ * - Application assignments, for every active application
+ * - Members of every group matching `PrivilegedGroups`
+ * - Sign-in activity of every user
+ * Failures are recorded in the evidence, and the remaining datasets are still collected.
+ */
+func (r *AccessReview) CollectOkta(c *okta.Client) error {
+	errs := []error{}
+	record := func(dataset string, records int, at time.Time, err error) {
+		r.AddEvidence(Okta, dataset, records, at, err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("okta %s: %w", dataset, err))
+		}
+	}
+
+	at := r.config.Now()
+	users, err := c.ListAllUsers()
+	if err != nil {
+		record(DatasetAccounts, 0, at, err)
+		return fmt.Errorf("okta %s: %w", DatasetAccounts, err)
+	}
+	accounts := FromOktaUsers(*users)
+	r.AddAccounts(accounts...)
+	record(DatasetAccounts, len(accounts), at, nil)
+
+	at = r.config.Now()
+	roles, err := c.GenerateRoleReport()
+	admins := []*AdminRole{}
+	if err == nil {
+		admins = FromOktaRoles(*roles, at)
+		r.AddAdmins(admins...)
+	}
+	record(DatasetAdmins, len(admins), at, err)
+
+	at = r.config.Now()
+	apps, err := r.collectOktaApps(c, *users, at)
+	record(DatasetApps, apps, at, err)
+
+	at = r.config.Now()
+	groups, err := r.collectOktaGroups(c, at)
+	record(DatasetGroups, groups, at, err)
+
+	return errors.Join(errs...)
+}
+
+func (r *AccessReview) collectOktaApps(c *okta.Client, users okta.Users, at time.Time) (int, error) {
+	apps, err := c.ListAllApplications()
+	if err != nil {
+		return 0, err
+	}
+
+	emails := map[string]string{}
+	for _, u := range users {
+		if u != nil && u.Profile != nil {
+			emails[u.ID] = u.Profile.Email
+		}
+	}
+
+	count := 0
+	for _, app := range *apps {
+		if app.Status != okta.APP_ACTIVE {
+			continue
+		}
+		appUsers, err := c.ListAllApplicationUsers(app.ID)
+		if err != nil {
+			return count, fmt.Errorf("listing users of %s: %w", app.Label, err)
+		}
+		assignments := FromOktaAppUsers(app, *appUsers, emails, at)
+		r.AddApps(assignments...)
+		count += len(assignments)
+	}
+	return count, nil
+}
+
+func (r *AccessReview) collectOktaGroups(c *okta.Client, at time.Time) (int, error) {
+	if len(r.config.PrivilegedGroups) == 0 {
+		return 0, nil
+	}
+
+	groups, err := c.ListAllGroups()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, group := range *groups {
+		if !r.Privileged(group.Profile.Name) {
+			continue
+		}
+		members, err := c.ListGroupMembers(group.ID)
+		if err != nil {
+			return count, fmt.Errorf("listing members of %s: %w", group.Profile.Name, err)
+		}
+		memberships := FromOktaGroupMembers(group, *members, at)
+		r.AddGroupMembers(memberships...)
+		count += len(memberships)
+	}
+	return count, nil
+}
+
+/*
+ * # Collect from Google Workspace
+ * - Super and delegated administrators
+ * - Sign-in activity of every user
+ */
+func (r *AccessReview) CollectGoogle(c *google.Client) error {
+	at := r.config.Now()
+	users, err := c.Users().ListAllUsers()
+	if err != nil {
+		r.AddEvidence(Google, DatasetAccounts, 0, at, err)
+		r.AddEvidence(Google, DatasetAdmins, 0, at, err)
+		return fmt.Errorf("google %s: %w", DatasetAccounts, err)
+	}
+
+	accounts := FromGoogleUsers(users.Users)
+	r.AddAccounts(accounts...)
+	r.AddEvidence(Google, DatasetAccounts, len(accounts), at, nil)
+
+	admins := FromGoogleAdmins(users.Users, at)
+	r.AddAdmins(admins...)
+	r.AddEvidence(Google, DatasetAdmins, len(admins), at, nil)
+	return nil
+}
+
+/*
+ * # Collect from Slack
+ * - Workspace owners and admins
+ */
+func (r *AccessReview) CollectSlack(c *slack.Client) error {
+	at := r.config.Now()
+	users, err := c.ListUsers()
+	if err != nil {
+		r.AddEvidence(Slack, DatasetAdmins, 0, at, err)
+		return fmt.Errorf("slack %s: %w", DatasetAdmins, err)
+	}
+
+	admins := FromSlackAdmins(users.Members, at)
+	r.AddAdmins(admins...)
+	r.AddEvidence(Slack, DatasetAdmins, len(admins), at, nil)
+	return nil
+}
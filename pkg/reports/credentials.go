@@ -0,0 +1,254 @@
+/*
+# Reports - Credential Monitor
+
+This package enumerates the credentials which grant programmatic access to each provider, reports their age and expiry,
+and alerts on those which break the rotation policy:
+  - Google Cloud service account keys (user-managed), of the given projects
+  - Okta API tokens
+Credentials of providers without a client (e.g. GitHub App installations) can be added with `AddCredentials`.
+
+	m := reports.NewCredentialMonitor(reports.CredentialPolicy{MaxAge: 90 * 24 * time.Hour})
+	err := m.CollectOkta(o)
+	...
+	err = m.Evaluate()
+	err = m.Alert(bus)
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/reports/credentials.go
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/events"
+	"github.com/gemini-oss/rego/pkg/common/exporters"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+)
+
+// Default thresholds of a credential policy
+const (
+	DefaultCredentialMaxAge = 90 * 24 * time.Hour
+	DefaultExpiryWarning    = 14 * 24 * time.Hour
+)
+
+// Name of the dataset of credentials
+const DatasetCredentials = "credentials"
+
+// credentialSeverity orders the statuses, most severe first
+var credentialSeverity = map[string]int{
+	CredentialExpired:  0,
+	CredentialExpiring: 1,
+	CredentialStale:    2,
+	CredentialOK:       3,
+	CredentialDisabled: 4,
+}
+
+// credentialEvents maps the statuses which are alerted on to their event type
+var credentialEvents = map[string]string{
+	CredentialExpired:  events.CredentialExpired,
+	CredentialExpiring: events.CredentialExpiring,
+	CredentialStale:    events.CredentialStale,
+}
+
+/*
+ * # New Credential Monitor
+ * - Credentials are added with `AddCredentials` (or the `Collect*` methods)
+ * - `Evaluate` checks them against the policy; `Alert` publishes the alerts, and `Sheets`, `Export` and
+ *   `SaveToGoogleSheet` emit the report
+ */
+func NewCredentialMonitor(policy CredentialPolicy) *CredentialMonitor {
+	if policy.MaxAge <= 0 {
+		policy.MaxAge = DefaultCredentialMaxAge
+	}
+	if policy.ExpiryWarning <= 0 {
+		policy.ExpiryWarning = DefaultExpiryWarning
+	}
+	if policy.Now == nil {
+		policy.Now = time.Now
+	}
+
+	m := &CredentialMonitor{
+		Credentials: []*Credential{},
+		Alerts:      []*Credential{},
+		policy:      policy,
+	}
+	m.Report = newReport(m, policy.Now(), policy.Collector)
+	return m
+}
+
+// AddCredentials adds credentials to the monitor
+func (m *CredentialMonitor) AddCredentials(credentials ...*Credential) {
+	for _, c := range credentials {
+		if c == nil {
+			continue
+		}
+		if c.CollectedAt.IsZero() {
+			c.CollectedAt = m.policy.Now()
+		}
+		m.Credentials = append(m.Credentials, c)
+	}
+}
+
+/*
+ * # Evaluate
+ * Sets the age, days to expiry and status of each credential, and collects the alerts
+ * - A disabled credential is never alerted on
+ * - An expired or expiring credential is reported as such even when it is also stale
+ */
+func (m *CredentialMonitor) Evaluate() error {
+	if len(m.Credentials) == 0 {
+		return errors.New("no credentials to evaluate")
+	}
+
+	now := m.policy.Now()
+	m.Alerts = []*Credential{}
+	for _, c := range m.Credentials {
+		c.AgeDays = 0
+		if !c.Created.IsZero() {
+			c.AgeDays = int(now.Sub(c.Created).Hours() / 24)
+		}
+		c.DaysToExpiry = 0
+		if !c.Expires.IsZero() {
+			c.DaysToExpiry = int(c.Expires.Sub(now).Hours() / 24)
+		}
+
+		switch {
+		case c.Disabled:
+			c.Status = CredentialDisabled
+		case !c.Expires.IsZero() && !c.Expires.After(now):
+			c.Status = CredentialExpired
+		case !c.Expires.IsZero() && c.Expires.Sub(now) <= m.policy.ExpiryWarning:
+			c.Status = CredentialExpiring
+		case !c.Created.IsZero() && now.Sub(c.Created) > m.policy.MaxAge:
+			c.Status = CredentialStale
+		default:
+			c.Status = CredentialOK
+		}
+
+		if _, ok := credentialEvents[c.Status]; ok {
+			m.Alerts = append(m.Alerts, c)
+		}
+	}
+
+	m.order()
+	return nil
+}
+
+/*
+ * # Alert
+ * Publishes an event for each alert, e.g. `credential.stale`, with the credential as its data
+ * - Subscribers decide how to notify, e.g. a Slack message to the owner of the credential
+ */
+func (m *CredentialMonitor) Alert(p events.Publisher) error {
+	alerts := []*events.Event{}
+	for _, c := range m.Alerts {
+		subject := c.Owner
+		if subject == "" {
+			subject = c.ID
+		}
+		alerts = append(alerts, &events.Event{
+			Type:     credentialEvents[c.Status],
+			Source:   string(c.Source),
+			Time:     m.GeneratedAt,
+			Subjects: []string{subject},
+			Data:     c,
+		})
+	}
+	if len(alerts) == 0 {
+		return nil
+	}
+	return p.Publish(alerts...)
+}
+
+// order sorts the credentials by source, then owner; and the alerts by status, most severe first
+func (m *CredentialMonitor) order() {
+	sort.SliceStable(m.Credentials, func(i, j int) bool {
+		return less(m.Credentials[i].Source, m.Credentials[j].Source, m.Credentials[i].Owner+m.Credentials[i].ID, m.Credentials[j].Owner+m.Credentials[j].ID)
+	})
+	sort.SliceStable(m.Alerts, func(i, j int) bool {
+		if a, b := credentialSeverity[m.Alerts[i].Status], credentialSeverity[m.Alerts[j].Status]; a != b {
+			return a < b
+		}
+		return strings.ToLower(m.Alerts[i].Owner) < strings.ToLower(m.Alerts[j].Owner)
+	})
+}
+
+/*
+ * # Sheets
+ * Returns the sections of the report: the alerts, every credential, and the evidence
+ */
+func (m *CredentialMonitor) Sheets() []exporters.Sheet {
+	m.order()
+	return []exporters.Sheet{
+		{Name: "Alerts", Data: m.Alerts},
+		{Name: "Credentials", Data: m.Credentials},
+		{Name: "Evidence", Data: m.Evidence},
+	}
+}
+
+// Title returns the title of the report, e.g. `Credential Expiry 2024-08-15`
+func (m *CredentialMonitor) Title() string {
+	return fmt.Sprintf("Credential Expiry %s", m.GeneratedAt.Format("2006-01-02"))
+}
+
+/*
+ * # Collect from Google Cloud
+ * - The user-managed keys of every service account of each project
+ * - A project whose service accounts cannot be listed is recorded in the evidence, and the other projects are still collected
+ */
+func (m *CredentialMonitor) CollectGoogle(c *google.Client, projectIDs ...string) error {
+	at := m.policy.Now()
+	count := 0
+	errs := []error{}
+	for _, project := range projectIDs {
+		accounts, err := c.IAM().ListServiceAccounts(project)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("project %s: %w", project, err))
+			continue
+		}
+		for _, account := range accounts {
+			keys, err := c.IAM().ListServiceAccountKeys(account.Email)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("service account %s: %w", account.Email, err))
+				continue
+			}
+			credentials := FromGoogleServiceAccountKeys(account, keys, at)
+			m.AddCredentials(credentials...)
+			count += len(credentials)
+		}
+	}
+
+	err := errors.Join(errs...)
+	m.AddEvidence(Google, DatasetCredentials, count, at, err)
+	if err != nil {
+		return fmt.Errorf("google %s: %w", DatasetCredentials, err)
+	}
+	return nil
+}
+
+/*
+ * # Collect from Okta
+ * - Every active API token
+ */
+func (m *CredentialMonitor) CollectOkta(c *okta.Client) error {
+	at := m.policy.Now()
+	tokens, err := c.ListAPITokens()
+	if err != nil {
+		m.AddEvidence(Okta, DatasetCredentials, 0, at, err)
+		return fmt.Errorf("okta %s: %w", DatasetCredentials, err)
+	}
+
+	credentials := FromOktaAPITokens(*tokens, at)
+	m.AddCredentials(credentials...)
+	m.AddEvidence(Okta, DatasetCredentials, len(credentials), at, nil)
+	return nil
+}
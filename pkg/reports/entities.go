@@ -0,0 +1,391 @@
+/*
+# Reports - Entities [Structs]
+
+This package contains the structs of the compliance reports generated across providers:
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/reports/entities.go
+package reports
+
+import "time"
+
+// ### Report Structs
+// ---------------------------------------------------------------------
+
+// Source is the provider a row of a report was collected from
+type Source string
+
+const (
+	ActiveDirectory Source = "active_directory"
+	Adobe           Source = "adobe"
+	Duo             Source = "duo"
+	Google          Source = "google"
+	Jamf            Source = "jamf"
+	Okta            Source = "okta"
+	Slack           Source = "slack"
+	SnipeIT         Source = "snipeit"
+)
+
+// Evidence records when and from where a dataset of a report was collected, for auditors
+type Evidence struct {
+	Source      Source    `json:"source" csv:"source"`                 // Provider the dataset was collected from
+	Dataset     string    `json:"dataset" csv:"dataset"`               // Name of the dataset, e.g. `admin_roles`
+	Records     int       `json:"records" csv:"records"`               // Number of rows collected
+	CollectedAt time.Time `json:"collectedAt" csv:"collected_at"`      // Time the dataset was collected
+	Error       string    `json:"error,omitempty" csv:"error"`         // Why the dataset is incomplete, if collection failed
+	Collector   string    `json:"collector,omitempty" csv:"collector"` // Who/what collected the dataset, e.g. a service account
+}
+
+// Report is embedded in every report: when it was generated, and the evidence of each dataset
+type Report struct {
+	GeneratedAt time.Time   `json:"generatedAt"` // Time the report was started
+	Evidence    []*Evidence `json:"evidence"`    // When and from where each dataset was collected
+
+	collector string    // Recorded in the evidence of each dataset
+	artifacts artifacts // The report embedding this one
+}
+
+// END OF REPORT STRUCTS
+//---------------------------------------------------------------------
+
+// ### Access Review Structs
+// ---------------------------------------------------------------------
+
+// AccessReviewConfig controls what an access review considers privileged or dormant
+type AccessReviewConfig struct {
+	Period           string           // Review period, e.g. `2024-Q3`; defaults to the current quarter
+	DormantDays      int              // Days without a sign-in before an active account is dormant; defaults to 90
+	PrivilegedGroups []string         // Case-insensitive glob patterns of privileged group names, e.g. `*-admins`
+	Collector        string           // Recorded in the evidence of each dataset, e.g. the service account running the review
+	Now              func() time.Time // Clock used for evidence timestamps and dormancy; defaults to `time.Now`
+}
+
+// AdminRole is an administrative role held by an account
+type AdminRole struct {
+	Source         Source    `json:"source" csv:"source"`                   // Provider the role is held in
+	UserID         string    `json:"userId" csv:"user_id"`                  // Identifier of the account in its provider
+	Email          string    `json:"email" csv:"email"`                     // Email address of the account
+	Role           string    `json:"role" csv:"role"`                       // Name of the role, e.g. `Super Administrator`
+	AssignmentType string    `json:"assignmentType" csv:"assignment_type"`  // How the role is assigned, e.g. `USER` or `GROUP`
+	Status         string    `json:"status,omitempty" csv:"account_status"` // Status of the account
+	CollectedAt    time.Time `json:"collectedAt" csv:"collected_at"`        // Evidence timestamp
+}
+
+// AppAssignment is an application assigned to an account
+type AppAssignment struct {
+	Source      Source    `json:"source" csv:"source"`            // Provider the assignment is managed in
+	UserID      string    `json:"userId" csv:"user_id"`           // Identifier of the account in its provider
+	Email       string    `json:"email" csv:"email"`              // Email address of the account
+	AppID       string    `json:"appId" csv:"app_id"`             // Identifier of the application
+	App         string    `json:"app" csv:"app"`                  // Name of the application
+	Scope       string    `json:"scope" csv:"scope"`              // How the app is assigned, e.g. `USER` or `GROUP`
+	Status      string    `json:"status" csv:"status"`            // Status of the assignment
+	CollectedAt time.Time `json:"collectedAt" csv:"collected_at"` // Evidence timestamp
+}
+
+// GroupMembership is an account's membership of a privileged group
+type GroupMembership struct {
+	Source      Source    `json:"source" csv:"source"`                   // Provider the group is managed in
+	GroupID     string    `json:"groupId" csv:"group_id"`                // Identifier of the group
+	Group       string    `json:"group" csv:"group"`                     // Name of the group
+	UserID      string    `json:"userId" csv:"user_id"`                  // Identifier of the account in its provider
+	Email       string    `json:"email" csv:"email"`                     // Email address of the account
+	Status      string    `json:"status,omitempty" csv:"account_status"` // Status of the account
+	CollectedAt time.Time `json:"collectedAt" csv:"collected_at"`        // Evidence timestamp
+}
+
+// Account is the sign-in activity of an account, evaluated for dormancy
+type Account struct {
+	Source    Source    // Provider the account is in
+	UserID    string    // Identifier of the account in its provider
+	Email     string    // Email address of the account
+	Status    string    // Status of the account, as reported by the provider
+	Active    bool      // True if the account can sign in; only active accounts can be dormant
+	Created   time.Time // Time the account was created; zero if unknown
+	LastLogin time.Time // Time of the last sign-in; zero if the account never signed in
+}
+
+// DormantAccount is an active account without a recent sign-in
+type DormantAccount struct {
+	Source        Source    `json:"source" csv:"source"`                  // Provider the account is in
+	UserID        string    `json:"userId" csv:"user_id"`                 // Identifier of the account in its provider
+	Email         string    `json:"email" csv:"email"`                    // Email address of the account
+	Status        string    `json:"status" csv:"account_status"`          // Status of the account
+	Created       time.Time `json:"created,omitempty" csv:"created"`      // Time the account was created
+	LastLogin     time.Time `json:"lastLogin,omitempty" csv:"last_login"` // Time of the last sign-in
+	NeverLoggedIn bool      `json:"neverLoggedIn" csv:"never_logged_in"`  // True if the account never signed in
+	DaysInactive  int       `json:"daysInactive" csv:"days_inactive"`     // Days since the last sign-in (or creation); -1 if neither is known
+	CollectedAt   time.Time `json:"collectedAt" csv:"collected_at"`       // Evidence timestamp
+}
+
+// AccessReview is the set of artifacts produced for a periodic access review
+type AccessReview struct {
+	Report
+	Period      string             `json:"period"`      // Review period, e.g. `2024-Q3`
+	DormantDays int                `json:"dormantDays"` // Days without a sign-in before an active account is dormant
+	Admins      []*AdminRole       `json:"admins"`      // Administrative role holders per provider
+	Apps        []*AppAssignment   `json:"apps"`        // Application assignments per account
+	Groups      []*GroupMembership `json:"groups"`      // Privileged group memberships
+	Dormant     []*DormantAccount  `json:"dormant"`     // Active accounts without a recent sign-in
+
+	config AccessReviewConfig
+}
+
+// END OF ACCESS REVIEW STRUCTS
+//---------------------------------------------------------------------
+
+// ### License Reconciliation Structs
+// ---------------------------------------------------------------------
+
+// LicenseConfig prices the seats of a license reconciliation
+type LicenseConfig struct {
+	Prices    map[string]float64 // Monthly price of a seat, keyed by product (case-insensitive), e.g. `Google Workspace Business Plus`
+	Currency  string             // Currency of the prices; defaults to `USD`
+	Collector string             // Recorded in the evidence of each dataset, e.g. the service account running the report
+	Now       func() time.Time   // Clock used for evidence timestamps; defaults to `time.Now`
+}
+
+// Seat is a paid license held by an account
+type Seat struct {
+	Source      Source    `json:"source" csv:"source"`                   // Provider the seat is licensed in
+	Product     string    `json:"product" csv:"product"`                 // Licensed product, e.g. `Google Workspace Business Plus` or `Zoom`
+	UserID      string    `json:"userId" csv:"user_id"`                  // Identifier of the account in its provider
+	Email       string    `json:"email" csv:"email"`                     // Email address of the account
+	Status      string    `json:"status,omitempty" csv:"account_status"` // Status of the account in its provider, if known
+	MonthlyCost float64   `json:"monthlyCost" csv:"monthly_cost"`        // Cost of the seat; taken from `LicenseConfig.Prices` when zero
+	CollectedAt time.Time `json:"collectedAt" csv:"collected_at"`        // Evidence timestamp
+}
+
+// Reasons a seat can be reclaimed
+const (
+	ReasonDeactivated = "deactivated" // The identity holding the seat can no longer sign in
+	ReasonNoIdentity  = "no_identity" // No identity has the email address of the seat
+)
+
+// ReclaimableSeat is a seat held by a deactivated or nonexistent identity
+type ReclaimableSeat struct {
+	Source         Source    `json:"source" csv:"source"`                            // Provider the seat is licensed in
+	Product        string    `json:"product" csv:"product"`                          // Licensed product
+	UserID         string    `json:"userId" csv:"user_id"`                           // Identifier of the account in its provider
+	Email          string    `json:"email" csv:"email"`                              // Email address of the account
+	Reason         string    `json:"reason" csv:"reason"`                            // Why the seat can be reclaimed {deactivated, no_identity}
+	IdentityStatus string    `json:"identityStatus,omitempty" csv:"identity_status"` // Status of the matching identity, if any
+	MonthlyCost    float64   `json:"monthlyCost" csv:"monthly_cost"`                 // Cost of the seat
+	CollectedAt    time.Time `json:"collectedAt" csv:"collected_at"`                 // Evidence timestamp of the seat
+}
+
+// LicenseSummary is the seat count and spend of a single product
+type LicenseSummary struct {
+	Source          Source  `json:"source" csv:"source"`                    // Provider the product is licensed in
+	Product         string  `json:"product" csv:"product"`                  // Licensed product
+	Seats           int     `json:"seats" csv:"seats"`                      // Seats assigned
+	Reclaimable     int     `json:"reclaimable" csv:"reclaimable"`          // Seats held by deactivated or nonexistent identities
+	MonthlyCost     float64 `json:"monthlyCost" csv:"monthly_cost"`         // Cost of every seat
+	ReclaimableCost float64 `json:"reclaimableCost" csv:"reclaimable_cost"` // Cost of the reclaimable seats
+	Unpriced        bool    `json:"unpriced,omitempty" csv:"unpriced"`      // True if the product has no price, so its costs are understated
+}
+
+// LicenseReconciliation joins the seats of every provider against the identity list
+type LicenseReconciliation struct {
+	Report
+	Currency    string             `json:"currency"`    // Currency of every cost
+	Seats       []*Seat            `json:"seats"`       // Every seat collected
+	Reclaimable []*ReclaimableSeat `json:"reclaimable"` // Seats held by deactivated or nonexistent identities; set by `Reconcile`
+	Summary     []*LicenseSummary  `json:"summary"`     // Seats and spend per product; set by `Reconcile`
+
+	identities map[string]*Account
+	config     LicenseConfig
+}
+
+// END OF LICENSE RECONCILIATION STRUCTS
+//---------------------------------------------------------------------
+
+// ### MFA Posture Structs
+// ---------------------------------------------------------------------
+
+// MFAConfig controls how the MFA posture report is collected
+type MFAConfig struct {
+	Collector string           // Recorded in the evidence of each dataset, e.g. the service account running the report
+	Now       func() time.Time // Clock used for evidence timestamps; defaults to `time.Now`
+}
+
+// Normalized types of the factors enrolled across providers
+const (
+	FactorWebAuthn      = "webauthn"       // FIDO2/WebAuthn credential, e.g. a security key or a platform authenticator
+	FactorU2F           = "u2f"            // FIDO U2F security key
+	FactorSecurityKey   = "security_key"   // Security key registered for Google 2-Step Verification
+	FactorFastPass      = "fastpass"       // Okta Verify FastPass (signed nonce)
+	FactorPush          = "push"           // Push notification to a mobile app
+	FactorTOTP          = "totp"           // Time-based one-time passcode from an app
+	FactorHardwareToken = "hardware_token" // One-time passcode from a hardware token
+	FactorSMS           = "sms"            // One-time passcode sent by SMS
+	FactorVoice         = "voice"          // Phone call
+	FactorEmail         = "email"          // One-time passcode sent by email
+	FactorQuestion      = "question"       // Security question
+	FactorTwoStep       = "2sv"            // Google 2-Step Verification of an unknown method
+	FactorOther         = "other"          // Any other factor
+)
+
+// Findings of the MFA posture of a user
+const (
+	FindingAdminWithoutMFA      = "admin_without_mfa"      // An administrator without any factor
+	FindingNoMFA                = "no_mfa"                 // A user without any factor
+	FindingNotPhishingResistant = "not_phishing_resistant" // A user whose factors can all be phished, e.g. push or SMS
+)
+
+// EnrolledFactor is a factor enrolled by an account
+type EnrolledFactor struct {
+	Source            Source    `json:"source" csv:"source"`                        // Provider the factor is enrolled in
+	UserID            string    `json:"userId" csv:"user_id"`                       // Identifier of the account in its provider
+	Email             string    `json:"email" csv:"email"`                          // Email address of the account
+	Type              string    `json:"type" csv:"type"`                            // Normalized type of the factor, e.g. `webauthn` or `sms`
+	Detail            string    `json:"detail,omitempty" csv:"detail"`              // Type of the factor as reported by the provider, e.g. `token:software:totp`
+	PhishingResistant bool      `json:"phishingResistant" csv:"phishing_resistant"` // True if the factor is bound to the origin it authenticates to
+	CollectedAt       time.Time `json:"collectedAt" csv:"collected_at"`             // Evidence timestamp
+}
+
+// MFAPosture is the factors enrolled by a user across every provider
+type MFAPosture struct {
+	Email             string `json:"email" csv:"email"`                          // Email address of the user
+	Sources           string `json:"sources" csv:"sources"`                      // Providers the user has an active account in, e.g. `google, okta`
+	Factors           string `json:"factors" csv:"factors"`                      // Normalized types of the enrolled factors, e.g. `push, webauthn`
+	PhishingResistant bool   `json:"phishingResistant" csv:"phishing_resistant"` // True if any factor is phishing-resistant
+	AdminRoles        string `json:"adminRoles,omitempty" csv:"admin_roles"`     // Administrative roles of the user, e.g. `okta: Super Administrator`
+	Finding           string `json:"finding,omitempty" csv:"finding"`            // Most severe finding, if any
+}
+
+// MFAReport aggregates the enrolled factors of each active user across identity providers
+type MFAReport struct {
+	Report
+	Users    []*MFAPosture     `json:"users"`    // Posture of every active user; set by `Evaluate`
+	Findings []*MFAPosture     `json:"findings"` // Users with a finding, most severe first; set by `Evaluate`
+	Factors  []*EnrolledFactor `json:"factors"`  // Every factor collected
+	Admins   []*AdminRole      `json:"admins"`   // Every administrative role collected
+
+	accounts map[string][]*Account
+	config   MFAConfig
+}
+
+// END OF MFA POSTURE STRUCTS
+//---------------------------------------------------------------------
+
+// ### Credential Monitor Structs
+// ---------------------------------------------------------------------
+
+// CredentialPolicy sets the thresholds credentials are alerted on
+type CredentialPolicy struct {
+	MaxAge        time.Duration    // Age after which a credential must be rotated; defaults to 90 days
+	ExpiryWarning time.Duration    // How long before its expiry a credential is alerted on; defaults to 14 days
+	Collector     string           // Recorded in the evidence of each dataset, e.g. the service account running the monitor
+	Now           func() time.Time // Clock used for ages and evidence timestamps; defaults to `time.Now`
+}
+
+// Kinds of credentials
+const (
+	KindServiceAccountKey = "service_account_key" // Google Cloud service account key
+	KindAPIToken          = "api_token"           // API token, e.g. an Okta SSWS token
+	KindAppInstallation   = "app_installation"    // Installation of an app with its own credentials, e.g. a GitHub App
+)
+
+// Status of a credential against the policy, most severe first
+const (
+	CredentialExpired  = "expired"  // The credential has expired
+	CredentialExpiring = "expiring" // The credential expires within `ExpiryWarning`
+	CredentialStale    = "stale"    // The credential is older than `MaxAge`
+	CredentialOK       = "ok"       // The credential complies with the policy
+	CredentialDisabled = "disabled" // The credential is disabled, so it cannot be used
+)
+
+// Credential is a key, token or installation which grants programmatic access to a provider
+type Credential struct {
+	Source       Source    `json:"source" csv:"source"`               // Provider the credential grants access to
+	Kind         string    `json:"kind" csv:"kind"`                   // Kind of the credential, e.g. `service_account_key`
+	ID           string    `json:"id" csv:"id"`                       // Identifier of the credential in its provider
+	Name         string    `json:"name,omitempty" csv:"name"`         // Name of the credential, if it has one
+	Owner        string    `json:"owner,omitempty" csv:"owner"`       // Who the credential acts as, e.g. a service account email or a user ID
+	Created      time.Time `json:"created" csv:"created"`             // Time the credential was created; zero if unknown
+	Expires      time.Time `json:"expires,omitempty" csv:"expires"`   // Time the credential expires; zero if it never does
+	Disabled     bool      `json:"disabled,omitempty" csv:"disabled"` // True if the credential cannot be used
+	AgeDays      int       `json:"ageDays" csv:"age_days"`            // Days since the credential was created; set by `Evaluate`
+	DaysToExpiry int       `json:"daysToExpiry" csv:"days_to_expiry"` // Days until the credential expires, if it does; set by `Evaluate`
+	Status       string    `json:"status" csv:"status"`               // Status against the policy; set by `Evaluate`
+	CollectedAt  time.Time `json:"collectedAt" csv:"collected_at"`    // Evidence timestamp
+}
+
+// CredentialMonitor reports the age and expiry of the credentials of every provider, against a policy
+type CredentialMonitor struct {
+	Report
+	Credentials []*Credential `json:"credentials"` // Every credential collected
+	Alerts      []*Credential `json:"alerts"`      // Credentials which are stale, expiring or expired, most severe first; set by `Evaluate`
+
+	policy CredentialPolicy
+}
+
+// END OF CREDENTIAL MONITOR STRUCTS
+//---------------------------------------------------------------------
+
+// ### Shadow IT Structs
+// ---------------------------------------------------------------------
+
+// ShadowITConfig controls which grants are mined, and how they are scored
+type ShadowITConfig struct {
+	Lookback   time.Duration    // How far back grant events are mined; defaults to 30 days
+	Sanctioned []string         // Case-insensitive glob patterns of the names or client IDs of approved apps, e.g. `Zoom*`
+	ScopeRisk  map[string]int   // Risk score of scopes, overriding or extending the defaults; keys are glob patterns
+	Collector  string           // Recorded in the evidence of each dataset, e.g. the service account running the report
+	Now        func() time.Time // Clock used for the lookback and evidence timestamps; defaults to `time.Now`
+}
+
+// Risk levels of OAuth scopes and apps, by score
+const (
+	RiskLow    = "low"    // Score 1: sign-in and basic profile, e.g. `openid`, `email`
+	RiskMedium = "medium" // Score 2: read access to user data, or a scope without a known score
+	RiskHigh   = "high"   // Score 3: write access to user data, or any access to mail, files or the organization
+)
+
+// OAuthGrant is an OAuth grant of a user to an app, aggregated across the grant events of the lookback
+type OAuthGrant struct {
+	Source     Source    `json:"source" csv:"source"`         // Provider the grant was issued by
+	UserID     string    `json:"userId" csv:"user_id"`        // Identifier of the user in the provider
+	Email      string    `json:"email" csv:"email"`           // Email address of the user
+	App        string    `json:"app" csv:"app"`               // Name of the app
+	ClientID   string    `json:"clientId" csv:"client_id"`    // OAuth client ID of the app
+	Scopes     string    `json:"scopes" csv:"scopes"`         // Granted scopes, space-separated
+	Score      int       `json:"score" csv:"score"`           // Highest risk score of the scopes; set by `Evaluate`
+	Risk       string    `json:"risk" csv:"risk"`             // Risk level of the score; set by `Evaluate`
+	Sanctioned bool      `json:"sanctioned" csv:"sanctioned"` // True if the app is approved; set by `Evaluate`
+	Events     int       `json:"events" csv:"events"`         // Number of grant events
+	FirstSeen  time.Time `json:"firstSeen" csv:"first_seen"`  // Time of the first grant event
+	LastSeen   time.Time `json:"lastSeen" csv:"last_seen"`    // Time of the last grant event
+}
+
+// ShadowApp is an app granted OAuth access by users, consolidated across providers
+type ShadowApp struct {
+	App        string    `json:"app" csv:"app"`               // Name of the app
+	ClientID   string    `json:"clientId" csv:"client_id"`    // OAuth client ID of the app
+	Sources    string    `json:"sources" csv:"sources"`       // Providers the app was granted access by, e.g. `google, okta`
+	Users      int       `json:"users" csv:"users"`           // Number of users who granted access
+	Scopes     string    `json:"scopes" csv:"scopes"`         // Every scope granted, space-separated
+	Score      int       `json:"score" csv:"score"`           // Highest risk score of the scopes
+	Risk       string    `json:"risk" csv:"risk"`             // Risk level of the score
+	Sanctioned bool      `json:"sanctioned" csv:"sanctioned"` // True if the app is approved
+	FirstSeen  time.Time `json:"firstSeen" csv:"first_seen"`  // Time of the first grant event
+	LastSeen   time.Time `json:"lastSeen" csv:"last_seen"`    // Time of the last grant event
+}
+
+// ShadowITReport inventories the OAuth grants of users to third-party apps
+type ShadowITReport struct {
+	Report
+	Since  time.Time     `json:"since"`  // Start of the lookback
+	Apps   []*ShadowApp  `json:"apps"`   // Apps granted access, unsanctioned and riskiest first; set by `Evaluate`
+	Grants []*OAuthGrant `json:"grants"` // Grants of each user to each app
+
+	config ShadowITConfig
+}
+
+// END OF SHADOW IT STRUCTS
+//---------------------------------------------------------------------
@@ -0,0 +1,341 @@
+/*
+# Reports - License Reconciliation
+
+This package joins the paid seats of each provider against the list of identities, and flags the seats which can be
+reclaimed, because they are held by deactivated or nonexistent identities:
+  - Google Workspace licenses, from the Enterprise License Manager API
+  - Okta application assignments, for applications licensed per seat (e.g. Zoom, Salesforce)
+  - Adobe product profiles
+Seats of any other provider (e.g. a Zoom or Salesforce license export) can be added with `AddSeats`.
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/reports/licenses.go
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/adobe"
+	"github.com/gemini-oss/rego/pkg/common/exporters"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+)
+
+// Names of the datasets of a license reconciliation
+const (
+	DatasetIdentities = "identities"
+	DatasetSeats      = "license_seats"
+)
+
+/*
+ * # New License Reconciliation
+ * - Identities are added with `AddIdentities` (or `CollectOkta`), and seats with `AddSeats` (or the `Collect*` methods)
+ * - `Reconcile` flags the reclaimable seats; `Sheets`, `Export` and `SaveToGoogleSheet` emit the report
+ */
+func NewLicenseReconciliation(cfg LicenseConfig) *LicenseReconciliation {
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	if cfg.Currency == "" {
+		cfg.Currency = "USD"
+	}
+
+	prices := map[string]float64{}
+	for product, price := range cfg.Prices {
+		prices[strings.ToLower(product)] = price
+	}
+	cfg.Prices = prices
+
+	r := &LicenseReconciliation{
+		Currency:    cfg.Currency,
+		Seats:       []*Seat{},
+		Reclaimable: []*ReclaimableSeat{},
+		Summary:     []*LicenseSummary{},
+		identities:  map[string]*Account{},
+		config:      cfg,
+	}
+	r.Report = newReport(r, cfg.Now(), cfg.Collector)
+	return r
+}
+
+/*
+ * # Add Identities
+ * Adds the accounts which seats are matched against, by email address (case-insensitive)
+ * - An email with several accounts (e.g. in Okta and Google) is active if any of them is
+ */
+func (r *LicenseReconciliation) AddIdentities(accounts ...*Account) {
+	for _, a := range accounts {
+		if a == nil || a.Email == "" {
+			continue
+		}
+		key := strings.ToLower(a.Email)
+		if existing, ok := r.identities[key]; ok && existing.Active {
+			continue
+		}
+		r.identities[key] = a
+	}
+}
+
+// AddSeats adds seats to the report, pricing those without a cost from `LicenseConfig.Prices`
+func (r *LicenseReconciliation) AddSeats(seats ...*Seat) {
+	for _, s := range seats {
+		if s == nil {
+			continue
+		}
+		if s.CollectedAt.IsZero() {
+			s.CollectedAt = r.config.Now()
+		}
+		if s.MonthlyCost == 0 {
+			s.MonthlyCost = r.config.Prices[strings.ToLower(s.Product)]
+		}
+		r.Seats = append(r.Seats, s)
+	}
+}
+
+/*
+ * # Reconcile
+ * Flags each seat held by an identity which cannot sign in, or by an email without any identity, and summarizes the
+ * seats and spend of each product
+ * - Can be called again after adding seats or identities
+ */
+func (r *LicenseReconciliation) Reconcile() error {
+	if len(r.identities) == 0 {
+		return errors.New("no identities to reconcile seats against")
+	}
+
+	r.Reclaimable = []*ReclaimableSeat{}
+	summaries := map[string]*LicenseSummary{}
+	for _, s := range r.Seats {
+		key := string(s.Source) + "\x00" + strings.ToLower(s.Product)
+		summary, ok := summaries[key]
+		if !ok {
+			_, priced := r.config.Prices[strings.ToLower(s.Product)]
+			summary = &LicenseSummary{Source: s.Source, Product: s.Product, Unpriced: !priced}
+			summaries[key] = summary
+		}
+		summary.Seats++
+		summary.MonthlyCost += s.MonthlyCost
+		if s.MonthlyCost != 0 {
+			summary.Unpriced = false
+		}
+
+		reclaimable := r.reclaimable(s)
+		if reclaimable == nil {
+			continue
+		}
+		r.Reclaimable = append(r.Reclaimable, reclaimable)
+		summary.Reclaimable++
+		summary.ReclaimableCost += s.MonthlyCost
+	}
+
+	r.Summary = make([]*LicenseSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		r.Summary = append(r.Summary, summary)
+	}
+	r.order()
+	return nil
+}
+
+// reclaimable returns the reclaimable seat for a seat, or nil if its identity is active
+func (r *LicenseReconciliation) reclaimable(s *Seat) *ReclaimableSeat {
+	seat := &ReclaimableSeat{
+		Source:      s.Source,
+		Product:     s.Product,
+		UserID:      s.UserID,
+		Email:       s.Email,
+		MonthlyCost: s.MonthlyCost,
+		CollectedAt: s.CollectedAt,
+	}
+
+	identity, ok := r.identities[strings.ToLower(s.Email)]
+	switch {
+	case !ok:
+		seat.Reason = ReasonNoIdentity
+	case !identity.Active:
+		seat.Reason = ReasonDeactivated
+		seat.IdentityStatus = identity.Status
+	default:
+		return nil
+	}
+	return seat
+}
+
+// MonthlyReclaimable returns the monthly cost of the reclaimable seats
+func (r *LicenseReconciliation) MonthlyReclaimable() float64 {
+	total := 0.0
+	for _, s := range r.Reclaimable {
+		total += s.MonthlyCost
+	}
+	return total
+}
+
+// AnnualReclaimable estimates the yearly spend which reclaiming the seats would save
+func (r *LicenseReconciliation) AnnualReclaimable() float64 {
+	return r.MonthlyReclaimable() * 12
+}
+
+// order sorts every section by source, then product and email, so reports of consecutive months can be compared
+func (r *LicenseReconciliation) order() {
+	sort.SliceStable(r.Seats, func(i, j int) bool {
+		return less(r.Seats[i].Source, r.Seats[j].Source, r.Seats[i].Product+"\x00"+r.Seats[i].Email, r.Seats[j].Product+"\x00"+r.Seats[j].Email)
+	})
+	sort.SliceStable(r.Reclaimable, func(i, j int) bool {
+		return less(r.Reclaimable[i].Source, r.Reclaimable[j].Source, r.Reclaimable[i].Product+"\x00"+r.Reclaimable[i].Email, r.Reclaimable[j].Product+"\x00"+r.Reclaimable[j].Email)
+	})
+	sort.SliceStable(r.Summary, func(i, j int) bool {
+		return less(r.Summary[i].Source, r.Summary[j].Source, r.Summary[i].Product, r.Summary[j].Product)
+	})
+}
+
+/*
+ * # Sheets
+ * Returns the sections of the report: the summary per product, the reclaimable seats, every seat, and the evidence
+ */
+func (r *LicenseReconciliation) Sheets() []exporters.Sheet {
+	r.order()
+	return []exporters.Sheet{
+		{Name: "Summary", Data: r.Summary},
+		{Name: "Reclaimable Seats", Data: r.Reclaimable},
+		{Name: "Seats", Data: r.Seats},
+		{Name: "Evidence", Data: r.Evidence},
+	}
+}
+
+// Title returns the title of the report, e.g. `License Reconciliation 2024-09`
+func (r *LicenseReconciliation) Title() string {
+	return fmt.Sprintf("License Reconciliation %s", r.GeneratedAt.Format("2006-01"))
+}
+
+/*
+ * # Collect from Okta
+ * - Every user, as the identities seats are matched against
+ * - The assignments of each active application whose label matches one of `apps` (case-insensitive glob patterns,
+ *   e.g. `Zoom`, `Salesforce*`), as seats of that application
+ */
+func (r *LicenseReconciliation) CollectOkta(c *okta.Client, apps ...string) error {
+	at := r.config.Now()
+	users, err := c.ListAllUsers()
+	if err != nil {
+		r.AddEvidence(Okta, DatasetIdentities, 0, at, err)
+		return fmt.Errorf("okta %s: %w", DatasetIdentities, err)
+	}
+	accounts := FromOktaUsers(*users)
+	r.AddIdentities(accounts...)
+	r.AddEvidence(Okta, DatasetIdentities, len(accounts), at, nil)
+
+	if len(apps) == 0 {
+		return nil
+	}
+
+	at = r.config.Now()
+	count, err := r.collectOktaSeats(c, *users, apps, at)
+	r.AddEvidence(Okta, DatasetSeats, count, at, err)
+	if err != nil {
+		return fmt.Errorf("okta %s: %w", DatasetSeats, err)
+	}
+	return nil
+}
+
+func (r *LicenseReconciliation) collectOktaSeats(c *okta.Client, users okta.Users, apps []string, at time.Time) (int, error) {
+	list, err := c.ListAllApplications()
+	if err != nil {
+		return 0, err
+	}
+
+	emails := map[string]string{}
+	for _, u := range users {
+		if u != nil && u.Profile != nil {
+			emails[u.ID] = u.Profile.Email
+		}
+	}
+
+	count := 0
+	for _, app := range *list {
+		if app.Status != okta.APP_ACTIVE || !matchesAny(app.Label, apps) {
+			continue
+		}
+		appUsers, err := c.ListAllApplicationUsers(app.ID)
+		if err != nil {
+			return count, fmt.Errorf("listing users of %s: %w", app.Label, err)
+		}
+		seats := FromOktaAppSeats(app, *appUsers, emails, at)
+		r.AddSeats(seats...)
+		count += len(seats)
+	}
+	return count, nil
+}
+
+// matchesAny reports whether a name matches one of the case-insensitive glob patterns
+func matchesAny(name string, patterns []string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * # Collect from Google Workspace
+ * - The license assignments of each product, e.g. `google.ProductWorkspace`; defaults to Google Workspace
+ * - `customerID` is the primary domain or the customer ID
+ */
+func (r *LicenseReconciliation) CollectGoogle(c *google.Client, customerID string, products ...string) error {
+	if len(products) == 0 {
+		products = []string{google.ProductWorkspace}
+	}
+
+	at := r.config.Now()
+	count := 0
+	errs := []error{}
+	for _, product := range products {
+		assignments, err := c.Licensing().ListAssignments(customerID, product, "")
+		if err != nil {
+			errs = append(errs, fmt.Errorf("product %s: %w", product, err))
+			continue
+		}
+		seats := FromGoogleLicenses(assignments, at)
+		r.AddSeats(seats...)
+		count += len(seats)
+	}
+
+	err := errors.Join(errs...)
+	r.AddEvidence(Google, DatasetSeats, count, at, err)
+	if err != nil {
+		return fmt.Errorf("google %s: %w", DatasetSeats, err)
+	}
+	return nil
+}
+
+/*
+ * # Collect from Adobe
+ * - One seat per product for each user in one of its product profiles
+ */
+func (r *LicenseReconciliation) CollectAdobe(c *adobe.Client) error {
+	at := r.config.Now()
+	profiles, err := c.Groups().ListProductProfiles()
+	if err != nil {
+		r.AddEvidence(Adobe, DatasetSeats, 0, at, err)
+		return fmt.Errorf("adobe %s: %w", DatasetSeats, err)
+	}
+	users, err := c.Users().ListAllUsers()
+	if err != nil {
+		r.AddEvidence(Adobe, DatasetSeats, 0, at, err)
+		return fmt.Errorf("adobe %s: %w", DatasetSeats, err)
+	}
+
+	seats := FromAdobeSeats(*users, *profiles, at)
+	r.AddSeats(seats...)
+	r.AddEvidence(Adobe, DatasetSeats, len(seats), at, nil)
+	return nil
+}
@@ -0,0 +1,342 @@
+/*
+# Reports - MFA Posture
+
+This package aggregates the factors each user has enrolled across identity providers, and highlights:
+  - administrators without any factor
+  - users without any factor
+  - users whose factors can all be phished (e.g. push, SMS or one-time passcodes)
+Factors are collected from Okta, from the 2-Step Verification status in the Google Workspace usage reports, and from the
+devices of Duo users.
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/reports/mfa.go
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/exporters"
+	"github.com/gemini-oss/rego/pkg/common/pool"
+	"github.com/gemini-oss/rego/pkg/duo"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+)
+
+// Name of the dataset of enrolled factors
+const DatasetFactors = "mfa_factors"
+
+// Google usage reports are published after a delay, so the posture is read from this many days ago by default
+const GoogleUsageDelayDays = 3
+
+// severity orders the findings, most severe first
+var severity = map[string]int{
+	FindingAdminWithoutMFA:      0,
+	FindingNoMFA:                1,
+	FindingNotPhishingResistant: 2,
+}
+
+/*
+ * # New MFA Report
+ * - Accounts, admin roles and factors are added with the `Add*` methods (or collected with the `Collect*` methods)
+ * - `Evaluate` builds the posture of each user; `Sheets`, `Export` and `SaveToGoogleSheet` emit the report
+ */
+func NewMFAReport(cfg MFAConfig) *MFAReport {
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	r := &MFAReport{
+		Users:    []*MFAPosture{},
+		Findings: []*MFAPosture{},
+		Factors:  []*EnrolledFactor{},
+		Admins:   []*AdminRole{},
+		accounts: map[string][]*Account{},
+		config:   cfg,
+	}
+	r.Report = newReport(r, cfg.Now(), cfg.Collector)
+	return r
+}
+
+// AddAccounts adds the identity provider accounts of the users to evaluate; users are matched by email (case-insensitive)
+func (r *MFAReport) AddAccounts(accounts ...*Account) {
+	for _, a := range accounts {
+		if a == nil || a.Email == "" {
+			continue
+		}
+		key := strings.ToLower(a.Email)
+		r.accounts[key] = append(r.accounts[key], a)
+	}
+}
+
+// AddAdmins adds the administrative roles of the users
+func (r *MFAReport) AddAdmins(admins ...*AdminRole) {
+	for _, a := range admins {
+		if a != nil {
+			r.Admins = append(r.Admins, a)
+		}
+	}
+}
+
+// AddFactors adds the factors enrolled by the users
+func (r *MFAReport) AddFactors(factors ...*EnrolledFactor) {
+	for _, f := range factors {
+		if f != nil {
+			r.Factors = append(r.Factors, f)
+		}
+	}
+}
+
+/*
+ * # Evaluate
+ * Builds the posture of each user with an active account, from the factors enrolled in every provider
+ * - A user is phishing-resistant if any factor is, since they can be required to use it
+ * - Can be called again after adding rows
+ */
+func (r *MFAReport) Evaluate() error {
+	if len(r.accounts) == 0 {
+		return errors.New("no accounts to evaluate")
+	}
+
+	factors := map[string][]*EnrolledFactor{}
+	for _, f := range r.Factors {
+		key := strings.ToLower(f.Email)
+		factors[key] = append(factors[key], f)
+	}
+	admins := map[string][]*AdminRole{}
+	for _, a := range r.Admins {
+		key := strings.ToLower(a.Email)
+		admins[key] = append(admins[key], a)
+	}
+
+	r.Users = []*MFAPosture{}
+	r.Findings = []*MFAPosture{}
+	for email, accounts := range r.accounts {
+		sources := []string{}
+		for _, a := range accounts {
+			if a.Active {
+				sources = append(sources, string(a.Source))
+			}
+		}
+		if len(sources) == 0 {
+			continue
+		}
+
+		posture := &MFAPosture{Email: accounts[0].Email, Sources: joinUnique(sources, ", ")}
+
+		types := []string{}
+		for _, f := range factors[email] {
+			types = append(types, f.Type)
+			posture.PhishingResistant = posture.PhishingResistant || f.PhishingResistant
+		}
+		posture.Factors = joinUnique(types, ", ")
+
+		roles := []string{}
+		for _, a := range admins[email] {
+			roles = append(roles, fmt.Sprintf("%s: %s", a.Source, a.Role))
+		}
+		posture.AdminRoles = joinUnique(roles, "; ")
+
+		switch {
+		case len(types) == 0 && len(roles) > 0:
+			posture.Finding = FindingAdminWithoutMFA
+		case len(types) == 0:
+			posture.Finding = FindingNoMFA
+		case !posture.PhishingResistant:
+			posture.Finding = FindingNotPhishingResistant
+		}
+
+		r.Users = append(r.Users, posture)
+		if posture.Finding != "" {
+			r.Findings = append(r.Findings, posture)
+		}
+	}
+
+	r.order()
+	return nil
+}
+
+// joinUnique joins the sorted, distinct values
+func joinUnique(values []string, sep string) string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	sort.Strings(unique)
+	return strings.Join(unique, sep)
+}
+
+// order sorts the users by email, the findings by severity, and the rows by source, so reports can be compared
+func (r *MFAReport) order() {
+	sort.SliceStable(r.Users, func(i, j int) bool {
+		return strings.ToLower(r.Users[i].Email) < strings.ToLower(r.Users[j].Email)
+	})
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		if a, b := severity[r.Findings[i].Finding], severity[r.Findings[j].Finding]; a != b {
+			return a < b
+		}
+		return strings.ToLower(r.Findings[i].Email) < strings.ToLower(r.Findings[j].Email)
+	})
+	sort.SliceStable(r.Factors, func(i, j int) bool {
+		return less(r.Factors[i].Source, r.Factors[j].Source, r.Factors[i].Email+r.Factors[i].Type, r.Factors[j].Email+r.Factors[j].Type)
+	})
+	sort.SliceStable(r.Admins, func(i, j int) bool {
+		return less(r.Admins[i].Source, r.Admins[j].Source, r.Admins[i].Email+r.Admins[i].Role, r.Admins[j].Email+r.Admins[j].Role)
+	})
+}
+
+/*
+ * # Sheets
+ * Returns the sections of the report: the findings, the posture of every user, the factors, the admin roles and the evidence
+ */
+func (r *MFAReport) Sheets() []exporters.Sheet {
+	r.order()
+	return []exporters.Sheet{
+		{Name: "Findings", Data: r.Findings},
+		{Name: "Users", Data: r.Users},
+		{Name: "Factors", Data: r.Factors},
+		{Name: "Admin Roles", Data: r.Admins},
+		{Name: "Evidence", Data: r.Evidence},
+	}
+}
+
+// Title returns the title of the report, e.g. `MFA Posture 2024-08-15`
+func (r *MFAReport) Title() string {
+	return fmt.Sprintf("MFA Posture %s", r.GeneratedAt.Format("2006-01-02"))
+}
+
+/*
+ * # Collect from Okta
+ * - Every user, and the admin roles of the active users
+ * - The active factors of every active user
+ * Failures are recorded in the evidence, and the remaining datasets are still collected.
+ */
+func (r *MFAReport) CollectOkta(c *okta.Client) error {
+	errs := []error{}
+	record := func(dataset string, records int, at time.Time, err error) {
+		r.AddEvidence(Okta, dataset, records, at, err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("okta %s: %w", dataset, err))
+		}
+	}
+
+	at := r.config.Now()
+	users, err := c.ListAllUsers()
+	if err != nil {
+		record(DatasetAccounts, 0, at, err)
+		return fmt.Errorf("okta %s: %w", DatasetAccounts, err)
+	}
+	accounts := FromOktaUsers(*users)
+	r.AddAccounts(accounts...)
+	record(DatasetAccounts, len(accounts), at, nil)
+
+	at = r.config.Now()
+	roles, err := c.GenerateRoleReport()
+	admins := []*AdminRole{}
+	if err == nil {
+		admins = FromOktaRoles(*roles, at)
+		r.AddAdmins(admins...)
+	}
+	record(DatasetAdmins, len(admins), at, err)
+
+	at = r.config.Now()
+	factors, err := r.collectOktaFactors(c, *users, at)
+	record(DatasetFactors, factors, at, err)
+
+	return errors.Join(errs...)
+}
+
+func (r *MFAReport) collectOktaFactors(c *okta.Client, users okta.Users, at time.Time) (int, error) {
+	active := okta.Users{}
+	for _, u := range users {
+		if u != nil && u.AccountStatus().Active() {
+			active = append(active, u)
+		}
+	}
+
+	results := pool.Map(c.HTTP.Context(), active, pool.Options{Workers: 10, RateLimiter: c.HTTP.RateLimiter}, func(_ context.Context, user *okta.User) (*okta.Factors, error) {
+		return c.ListUserFactors(user.ID)
+	})
+
+	count := 0
+	errs := []error{}
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", result.Item.ID, result.Err))
+			continue
+		}
+		factors := FromOktaFactors(result.Item, *result.Value, at)
+		r.AddFactors(factors...)
+		count += len(factors)
+	}
+	return count, errors.Join(errs...)
+}
+
+/*
+ * # Collect from Google Workspace
+ * - Every user, and the super and delegated administrators
+ * - The 2-Step Verification status of every user, from the usage report of `date` (`yyyy-mm-dd`); defaults to
+ *   `GoogleUsageDelayDays` ago, the most recent report reliably available
+ */
+func (r *MFAReport) CollectGoogle(c *google.Client, date string) error {
+	at := r.config.Now()
+	users, err := c.Users().ListAllUsers()
+	if err != nil {
+		r.AddEvidence(Google, DatasetAccounts, 0, at, err)
+		r.AddEvidence(Google, DatasetAdmins, 0, at, err)
+		return fmt.Errorf("google %s: %w", DatasetAccounts, err)
+	}
+
+	accounts := FromGoogleUsers(users.Users)
+	r.AddAccounts(accounts...)
+	r.AddEvidence(Google, DatasetAccounts, len(accounts), at, nil)
+
+	admins := FromGoogleAdmins(users.Users, at)
+	r.AddAdmins(admins...)
+	r.AddEvidence(Google, DatasetAdmins, len(admins), at, nil)
+
+	if date == "" {
+		date = at.AddDate(0, 0, -GoogleUsageDelayDays).Format("2006-01-02")
+	}
+	at = r.config.Now()
+	usage, err := c.Admin().ListUserUsage(date, Google2SVEnrolled, GoogleSecurityKeys)
+	if err != nil {
+		r.AddEvidence(Google, DatasetFactors, 0, at, err)
+		return fmt.Errorf("google %s: %w", DatasetFactors, err)
+	}
+
+	factors := FromGoogleUsage(usage, at)
+	r.AddFactors(factors...)
+	r.AddEvidence(Google, DatasetFactors, len(factors), at, nil)
+	return nil
+}
+
+/*
+ * # Collect from Duo
+ * - The phones, hardware tokens, U2F tokens and WebAuthn credentials of every user
+ */
+func (r *MFAReport) CollectDuo(c *duo.Client) error {
+	at := r.config.Now()
+	users, err := c.Users().ListAllUsers()
+	if err != nil {
+		r.AddEvidence(Duo, DatasetFactors, 0, at, err)
+		return fmt.Errorf("duo %s: %w", DatasetFactors, err)
+	}
+
+	factors := FromDuoUsers(*users, at)
+	r.AddFactors(factors...)
+	r.AddEvidence(Duo, DatasetFactors, len(factors), at, nil)
+	return nil
+}
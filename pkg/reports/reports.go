@@ -0,0 +1,136 @@
+/*
+# Reports
+
+This package generates compliance reports from data collected across providers, and emits them as
+CSV/XLSX files (via `pkg/common/exporters`) or Google Sheets.
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/reports/reports.go
+package reports
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/exporters"
+	"github.com/gemini-oss/rego/pkg/google"
+)
+
+/*
+ * # Save to Google Sheet
+ * Creates a spreadsheet with one tab per sheet, writes each sheet as a table, and formats its header row
+ * - Values are written as text (times in RFC 3339), so they are not reinterpreted by Sheets
+ */
+func SaveToGoogleSheet(g *google.Client, title string, sheets ...exporters.Sheet) (*google.Spreadsheet, error) {
+	tables := make([]*exporters.Table, len(sheets))
+	spreadsheet := &google.Spreadsheet{
+		Properties: &google.SpreadsheetProperties{
+			Title: title,
+		},
+	}
+	for i, sheet := range sheets {
+		table, err := exporters.NewTable(sheet.Data)
+		if err != nil {
+			return nil, fmt.Errorf("sheet %s: %w", sheet.Name, err)
+		}
+		tables[i] = table
+		spreadsheet.Sheets = append(spreadsheet.Sheets, google.Sheet{
+			Properties: &google.SheetProperties{
+				Title: sheet.Name,
+				Index: i,
+			},
+		})
+	}
+
+	created, err := g.Sheets().CreateSpreadsheet(spreadsheet)
+	if err != nil {
+		return nil, err
+	}
+	if len(created.Sheets) != len(sheets) {
+		return created, fmt.Errorf("spreadsheet %s was created with %d of %d sheets", created.SpreadsheetID, len(created.Sheets), len(sheets))
+	}
+
+	for i, table := range tables {
+		vr := &google.ValueRange{
+			Range:          url.PathEscape(fmt.Sprintf("'%s'!A1", strings.ReplaceAll(sheets[i].Name, "'", "''"))),
+			MajorDimension: "ROWS",
+			Values:         [][]string{table.Headers},
+		}
+		for _, row := range table.Rows {
+			values := make([]string, len(row))
+			for j, cell := range row {
+				values[j] = cell.String()
+			}
+			vr.Values = append(vr.Values, values)
+		}
+
+		if err := g.Sheets().UpdateSpreadsheet(created.SpreadsheetID, vr); err != nil {
+			return created, fmt.Errorf("writing sheet %s: %w", sheets[i].Name, err)
+		}
+		if len(table.Headers) == 0 {
+			continue
+		}
+		if err := g.Sheets().FormatHeaderAndAutoSize(created.SpreadsheetID, &created.Sheets[i], len(vr.Values), len(table.Headers)); err != nil {
+			return created, fmt.Errorf("formatting sheet %s: %w", sheets[i].Name, err)
+		}
+	}
+
+	return created, nil
+}
+
+// artifacts is implemented by every report embedding a `Report`, which titles and emits its own sheets
+type artifacts interface {
+	Title() string
+	Sheets() []exporters.Sheet
+}
+
+// newReport returns the `Report` embedded in a, started at generatedAt
+func newReport(a artifacts, generatedAt time.Time, collector string) Report {
+	return Report{
+		GeneratedAt: generatedAt,
+		Evidence:    []*Evidence{},
+		collector:   collector,
+		artifacts:   a,
+	}
+}
+
+// AddEvidence records that a dataset was collected; a failed collection is recorded with its error
+func (r *Report) AddEvidence(source Source, dataset string, records int, collectedAt time.Time, err error) {
+	e := &Evidence{
+		Source:      source,
+		Dataset:     dataset,
+		Records:     records,
+		CollectedAt: collectedAt,
+		Collector:   r.collector,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	r.Evidence = append(r.Evidence, e)
+}
+
+// Complete reports whether every dataset was collected without error
+func (r *Report) Complete() bool {
+	for _, e := range r.Evidence {
+		if e.Error != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Export writes the report to an `.xlsx` workbook, or to one `.csv` file per section
+func (r *Report) Export(path string) error {
+	return exporters.Export(path, r.artifacts.Sheets()...)
+}
+
+// SaveToGoogleSheet writes the report to a new spreadsheet, one tab per section
+func (r *Report) SaveToGoogleSheet(g *google.Client) (*google.Spreadsheet, error) {
+	return SaveToGoogleSheet(g, fmt.Sprintf("%s (generated %s)", r.artifacts.Title(), r.GeneratedAt.UTC().Format(time.RFC3339)), r.artifacts.Sheets()...)
+}
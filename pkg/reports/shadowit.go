@@ -0,0 +1,380 @@
+/*
+# Reports - Shadow IT
+
+This package mines the grant events of identity providers to inventory the OAuth grants of users to third-party apps,
+scores the granted scopes by risk, and consolidates the apps across providers:
+  - Okta System Log consent events (`app.oauth2.as.consent.grant`)
+  - Google Workspace token audit events (`authorize`)
+Apps matching `Sanctioned` are reported as approved; the others are the shadow IT to review.
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/reports/shadowit.go
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/exporters"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+)
+
+// Name of the dataset of OAuth grants
+const DatasetGrants = "oauth_grants"
+
+// How far back grant events are mined when `ShadowITConfig.Lookback` is not set
+const DefaultLookback = 30 * 24 * time.Hour
+
+// Score of a scope without a known score
+const unknownScopeScore = 2
+
+// googleScopePrefix is stripped from Google scopes before they are scored, e.g. `https://www.googleapis.com/auth/drive`
+const googleScopePrefix = "https://www.googleapis.com/auth/"
+
+/*
+ * DefaultScopeRisk scores common scopes; keys are case-insensitive glob patterns, and the highest matching score wins
+ * - Google scopes are matched without the `https://www.googleapis.com/auth/` prefix
+ */
+var DefaultScopeRisk = map[string]int{
+	// Sign-in, basic profile and app-specific data
+	"openid":        1,
+	"email":         1,
+	"profile":       1,
+	"userinfo.*":    1,
+	"plus.me":       1,
+	"drive.appdata": 1,
+	"drive.install": 1,
+
+	// Read access to user data
+	"*.readonly":     2,
+	"gmail.metadata": 2,
+	"drive.metadata": 2,
+	"drive.file":     2,
+	"calendar.*":     2,
+	"contacts.*":     2,
+	"spreadsheets*":  2,
+	"documents*":     2,
+	"okta.*.read":    2,
+	"offline_access": 2,
+
+	// Write access to user data, or any access to mail, files or the organization
+	"https://mail.google.com/": 3,
+	"gmail.modify":             3,
+	"gmail.compose":            3,
+	"gmail.send":               3,
+	"gmail.insert":             3,
+	"gmail.settings.*":         3,
+	"drive":                    3,
+	"calendar":                 3,
+	"contacts":                 3,
+	"admin.*":                  3,
+	"apps.*":                   3,
+	"cloud-platform":           3,
+	"okta.*.manage":            3,
+}
+
+/*
+ * # New Shadow IT Report
+ * - Grants are added with `AddGrants` (or the `Collect*` methods)
+ * - `Evaluate` scores the grants and consolidates the apps; `Sheets`, `Export` and `SaveToGoogleSheet` emit the report
+ */
+func NewShadowITReport(cfg ShadowITConfig) *ShadowITReport {
+	if cfg.Lookback <= 0 {
+		cfg.Lookback = DefaultLookback
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	now := cfg.Now()
+	r := &ShadowITReport{
+		Since:  now.Add(-cfg.Lookback),
+		Apps:   []*ShadowApp{},
+		Grants: []*OAuthGrant{},
+		config: cfg,
+	}
+	r.Report = newReport(r, now, cfg.Collector)
+	return r
+}
+
+// grantKey identifies the grant of a user to an app in a provider
+func grantKey(g *OAuthGrant) string {
+	app := g.ClientID
+	if app == "" {
+		app = g.App
+	}
+	return strings.ToLower(string(g.Source) + "\x00" + g.Email + "\x00" + app)
+}
+
+// appKey identifies an app across providers, by name when it has one
+func appKey(g *OAuthGrant) string {
+	if g.App != "" {
+		return strings.ToLower(g.App)
+	}
+	return strings.ToLower(g.ClientID)
+}
+
+/*
+ * # Add Grants
+ * Adds grants to the report, merging those of the same user to the same app in a provider: their scopes are combined,
+ * their events counted, and their first and last times kept
+ */
+func (r *ShadowITReport) AddGrants(grants ...*OAuthGrant) {
+	index := map[string]*OAuthGrant{}
+	for _, g := range r.Grants {
+		index[grantKey(g)] = g
+	}
+
+	for _, g := range grants {
+		if g == nil {
+			continue
+		}
+		if g.Events == 0 {
+			g.Events = 1
+		}
+
+		existing, ok := index[grantKey(g)]
+		if !ok {
+			g.Scopes = joinUnique(strings.Fields(g.Scopes), " ")
+			index[grantKey(g)] = g
+			r.Grants = append(r.Grants, g)
+			continue
+		}
+
+		existing.Scopes = joinUnique(append(strings.Fields(existing.Scopes), strings.Fields(g.Scopes)...), " ")
+		existing.Events += g.Events
+		if !g.FirstSeen.IsZero() && (existing.FirstSeen.IsZero() || g.FirstSeen.Before(existing.FirstSeen)) {
+			existing.FirstSeen = g.FirstSeen
+		}
+		if g.LastSeen.After(existing.LastSeen) {
+			existing.LastSeen = g.LastSeen
+		}
+		if existing.App == "" {
+			existing.App = g.App
+		}
+	}
+}
+
+// wildcard reports whether a value matches a case-insensitive pattern, in which `*` matches any characters
+func wildcard(pattern, value string) bool {
+	if !strings.Contains(pattern, "*") {
+		return strings.EqualFold(pattern, value)
+	}
+	expr := "(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	ok, _ := regexp.MatchString(expr, value)
+	return ok
+}
+
+// highestScore returns the highest score of the patterns matching a scope, or zero if none match
+func highestScore(risks map[string]int, scope string) int {
+	score := 0
+	for pattern, s := range risks {
+		if s > score && wildcard(pattern, scope) {
+			score = s
+		}
+	}
+	return score
+}
+
+/*
+ * # Score a Scope
+ * Returns the risk score of a scope, from 1 (low) to 3 (high)
+ * - Scores of `ShadowITConfig.ScopeRisk` take precedence over `DefaultScopeRisk`
+ * - A scope without a known score is scored as medium
+ */
+func (r *ShadowITReport) ScopeScore(scope string) int {
+	name := strings.TrimPrefix(scope, googleScopePrefix)
+	if score := highestScore(r.config.ScopeRisk, name); score > 0 {
+		return score
+	}
+	if score := highestScore(DefaultScopeRisk, name); score > 0 {
+		return score
+	}
+	return unknownScopeScore
+}
+
+// riskLevel returns the risk level of a score
+func riskLevel(score int) string {
+	switch {
+	case score >= 3:
+		return RiskHigh
+	case score == 2:
+		return RiskMedium
+	default:
+		return RiskLow
+	}
+}
+
+// sanctioned reports whether the name or client ID of an app matches one of the sanctioned patterns
+func (r *ShadowITReport) sanctioned(app, clientID string) bool {
+	for _, pattern := range r.config.Sanctioned {
+		if (app != "" && wildcard(pattern, app)) || (clientID != "" && wildcard(pattern, clientID)) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * # Evaluate
+ * Scores each grant by its riskiest scope, and consolidates the grants into apps across providers
+ * - Apps are matched by name, so an app granted access through both Okta and Google is reported once
+ * - Can be called again after adding grants
+ */
+func (r *ShadowITReport) Evaluate() error {
+	if len(r.Grants) == 0 {
+		return errors.New("no grants to evaluate")
+	}
+
+	type consolidated struct {
+		app     *ShadowApp
+		sources []string
+		clients []string
+		scopes  []string
+		users   map[string]bool
+	}
+	apps := map[string]*consolidated{}
+	order := []string{}
+
+	for _, g := range r.Grants {
+		g.Score = 0
+		for _, scope := range strings.Fields(g.Scopes) {
+			if s := r.ScopeScore(scope); s > g.Score {
+				g.Score = s
+			}
+		}
+		if g.Score == 0 {
+			g.Score = unknownScopeScore
+		}
+		g.Risk = riskLevel(g.Score)
+		g.Sanctioned = r.sanctioned(g.App, g.ClientID)
+
+		key := appKey(g)
+		c, ok := apps[key]
+		if !ok {
+			c = &consolidated{app: &ShadowApp{App: g.App, FirstSeen: g.FirstSeen}, users: map[string]bool{}}
+			apps[key] = c
+			order = append(order, key)
+		}
+		c.sources = append(c.sources, string(g.Source))
+		if g.ClientID != "" {
+			c.clients = append(c.clients, g.ClientID)
+		}
+		c.scopes = append(c.scopes, strings.Fields(g.Scopes)...)
+		c.users[strings.ToLower(g.Email)] = true
+		if g.Score > c.app.Score {
+			c.app.Score = g.Score
+		}
+		c.app.Sanctioned = c.app.Sanctioned || g.Sanctioned
+		if !g.FirstSeen.IsZero() && (c.app.FirstSeen.IsZero() || g.FirstSeen.Before(c.app.FirstSeen)) {
+			c.app.FirstSeen = g.FirstSeen
+		}
+		if g.LastSeen.After(c.app.LastSeen) {
+			c.app.LastSeen = g.LastSeen
+		}
+	}
+
+	r.Apps = make([]*ShadowApp, 0, len(apps))
+	for _, key := range order {
+		c := apps[key]
+		c.app.Sources = joinUnique(c.sources, ", ")
+		c.app.ClientID = joinUnique(c.clients, ", ")
+		c.app.Scopes = joinUnique(c.scopes, " ")
+		c.app.Users = len(c.users)
+		c.app.Risk = riskLevel(c.app.Score)
+		r.Apps = append(r.Apps, c.app)
+	}
+
+	r.order()
+	return nil
+}
+
+// order sorts the apps unsanctioned and riskiest first, then by users; and the grants by source, then email
+func (r *ShadowITReport) order() {
+	sort.SliceStable(r.Apps, func(i, j int) bool {
+		a, b := r.Apps[i], r.Apps[j]
+		switch {
+		case a.Sanctioned != b.Sanctioned:
+			return !a.Sanctioned
+		case a.Score != b.Score:
+			return a.Score > b.Score
+		case a.Users != b.Users:
+			return a.Users > b.Users
+		}
+		return strings.ToLower(a.App) < strings.ToLower(b.App)
+	})
+	sort.SliceStable(r.Grants, func(i, j int) bool {
+		return less(r.Grants[i].Source, r.Grants[j].Source, r.Grants[i].Email+r.Grants[i].App, r.Grants[j].Email+r.Grants[j].App)
+	})
+}
+
+/*
+ * # Sheets
+ * Returns the sections of the report: the consolidated apps, the grants of each user, and the evidence
+ */
+func (r *ShadowITReport) Sheets() []exporters.Sheet {
+	r.order()
+	return []exporters.Sheet{
+		{Name: "Apps", Data: r.Apps},
+		{Name: "Grants", Data: r.Grants},
+		{Name: "Evidence", Data: r.Evidence},
+	}
+}
+
+// Title returns the title of the report, e.g. `Shadow IT 2024-08-15`
+func (r *ShadowITReport) Title() string {
+	return fmt.Sprintf("Shadow IT %s", r.GeneratedAt.Format("2006-01-02"))
+}
+
+/*
+ * # Collect from Okta
+ * - The consent events of the lookback, from the System Log
+ */
+func (r *ShadowITReport) CollectOkta(c *okta.Client) error {
+	at := r.config.Now()
+	events, err := c.ListLogs(okta.LogQuery{
+		Since:  r.Since.UTC().Format(time.RFC3339),
+		Until:  at.UTC().Format(time.RFC3339),
+		Filter: fmt.Sprintf("eventType eq %q", OktaConsentGrant),
+	})
+	if err != nil {
+		r.AddEvidence(Okta, DatasetGrants, 0, at, err)
+		return fmt.Errorf("okta %s: %w", DatasetGrants, err)
+	}
+
+	grants := FromOktaGrantEvents(*events)
+	r.AddGrants(grants...)
+	r.AddEvidence(Okta, DatasetGrants, len(grants), at, nil)
+	return nil
+}
+
+/*
+ * # Collect from Google Workspace
+ * - The `authorize` token audit events of the lookback, from the Reports API
+ */
+func (r *ShadowITReport) CollectGoogle(c *google.Client) error {
+	at := r.config.Now()
+	activities, err := c.Admin().ListActivities("token", google.ReportsQuery{
+		EventName: GoogleTokenAuthorize,
+		StartTime: r.Since.UTC().Format(time.RFC3339),
+		EndTime:   at.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		r.AddEvidence(Google, DatasetGrants, 0, at, err)
+		return fmt.Errorf("google %s: %w", DatasetGrants, err)
+	}
+
+	grants := FromGoogleTokenActivities(activities)
+	r.AddGrants(grants...)
+	r.AddEvidence(Google, DatasetGrants, len(grants), at, nil)
+	return nil
+}
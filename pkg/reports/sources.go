@@ -0,0 +1,608 @@
+/*
+# Reports - Sources
+
+This package converts provider objects into the rows of the compliance reports:
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/reports/sources.go
+package reports
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/adobe"
+	"github.com/gemini-oss/rego/pkg/duo"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/slack"
+)
+
+// FromOktaUsers converts Okta users into accounts; only `ACTIVE` and `PASSWORD_EXPIRED` users can sign in (see
+// `okta.User.AccountStatus`)
+func FromOktaUsers(users okta.Users) []*Account {
+	accounts := []*Account{}
+	for _, u := range users {
+		if u == nil {
+			continue
+		}
+
+		email := ""
+		if u.Profile != nil {
+			email = u.Profile.Email
+		}
+		accounts = append(accounts, &Account{
+			Source:    Okta,
+			UserID:    u.ID,
+			Email:     email,
+			Status:    string(u.Status),
+			Active:    u.AccountStatus().Active(),
+			Created:   u.Created,
+			LastLogin: u.LastLogin,
+		})
+	}
+	return accounts
+}
+
+// FromOktaRoles converts an Okta role report into admin role holders
+func FromOktaRoles(reports okta.RoleReports, at time.Time) []*AdminRole {
+	admins := []*AdminRole{}
+	for _, report := range reports {
+		if report == nil || report.Role == nil || report.Users == nil {
+			continue
+		}
+
+		for _, u := range *report.Users {
+			if u == nil {
+				continue
+			}
+			email := ""
+			if u.Profile != nil {
+				email = u.Profile.Email
+			}
+			admins = append(admins, &AdminRole{
+				Source:         Okta,
+				UserID:         u.ID,
+				Email:          email,
+				Role:           report.Role.Label,
+				AssignmentType: report.Role.AssignmentType,
+				Status:         string(u.Status),
+				CollectedAt:    at,
+			})
+		}
+	}
+	return admins
+}
+
+// FromOktaAppUsers converts the users assigned to an Okta application into app assignments
+// - Application users only carry the user's ID, so `emails` maps user IDs to email addresses
+func FromOktaAppUsers(app *okta.Application, users okta.Users, emails map[string]string, at time.Time) []*AppAssignment {
+	assignments := []*AppAssignment{}
+	for _, u := range users {
+		if u == nil {
+			continue
+		}
+		assignments = append(assignments, &AppAssignment{
+			Source:      Okta,
+			UserID:      u.ID,
+			Email:       emails[u.ID],
+			AppID:       app.ID,
+			App:         app.Label,
+			Scope:       u.Scope,
+			Status:      string(u.Status),
+			CollectedAt: at,
+		})
+	}
+	return assignments
+}
+
+// FromOktaGroupMembers converts the members of an Okta group into group memberships
+func FromOktaGroupMembers(group *okta.Group, members okta.Users, at time.Time) []*GroupMembership {
+	memberships := []*GroupMembership{}
+	for _, u := range members {
+		if u == nil {
+			continue
+		}
+		email := ""
+		if u.Profile != nil {
+			email = u.Profile.Email
+		}
+		memberships = append(memberships, &GroupMembership{
+			Source:      Okta,
+			GroupID:     group.ID,
+			Group:       group.Profile.Name,
+			UserID:      u.ID,
+			Email:       email,
+			Status:      string(u.Status),
+			CollectedAt: at,
+		})
+	}
+	return memberships
+}
+
+// FromGoogleUsers converts Google Workspace users into accounts; suspended and archived users cannot sign in
+func FromGoogleUsers(users []*google.User) []*Account {
+	accounts := []*Account{}
+	for _, u := range users {
+		if u == nil {
+			continue
+		}
+
+		status := "active"
+		switch {
+		case u.Archived:
+			status = "archived"
+		case u.Suspended:
+			status = "suspended"
+		}
+		accounts = append(accounts, &Account{
+			Source:    Google,
+			UserID:    u.ID,
+			Email:     u.PrimaryEmail,
+			Status:    status,
+			Active:    u.AccountStatus().Active(),
+			Created:   u.CreationTime.Time,
+			LastLogin: u.LastLoginTime.Time,
+		})
+	}
+	return accounts
+}
+
+// FromGoogleAdmins converts the super and delegated administrators among Google Workspace users into admin role holders
+func FromGoogleAdmins(users []*google.User, at time.Time) []*AdminRole {
+	admins := []*AdminRole{}
+	for _, u := range users {
+		if u == nil || u.AdminRole() == "" {
+			continue
+		}
+
+		role := "Delegated Administrator"
+		if u.AdminRole() == google.SUPER_ADMIN {
+			role = "Super Administrator"
+		}
+		status := "active"
+		if u.Suspended {
+			status = "suspended"
+		}
+		admins = append(admins, &AdminRole{
+			Source:         Google,
+			UserID:         u.ID,
+			Email:          u.PrimaryEmail,
+			Role:           role,
+			AssignmentType: "USER",
+			Status:         status,
+			CollectedAt:    at,
+		})
+	}
+	return admins
+}
+
+// FromSlackAdmins converts the owners and admins among Slack members into admin role holders
+func FromSlackAdmins(members []slack.Member, at time.Time) []*AdminRole {
+	admins := []*AdminRole{}
+	for _, m := range members {
+		role := ""
+		switch {
+		case m.IsPrimaryOwner:
+			role = "Primary Owner"
+		case m.IsOwner:
+			role = "Workspace Owner"
+		case m.IsAdmin:
+			role = "Workspace Admin"
+		default:
+			continue
+		}
+
+		status := "active"
+		if m.Deleted {
+			status = "deactivated"
+		}
+		admins = append(admins, &AdminRole{
+			Source:         Slack,
+			UserID:         m.ID,
+			Email:          m.Profile.Email,
+			Role:           role,
+			AssignmentType: "USER",
+			Status:         status,
+			CollectedAt:    at,
+		})
+	}
+	return admins
+}
+
+// FromGoogleLicenses converts Google license assignments into seats, named after their SKU
+func FromGoogleLicenses(assignments []*google.LicenseAssignment, at time.Time) []*Seat {
+	seats := []*Seat{}
+	for _, a := range assignments {
+		if a == nil {
+			continue
+		}
+
+		product := a.SkuName
+		if product == "" {
+			product = a.ProductName
+		}
+		seats = append(seats, &Seat{
+			Source:      Google,
+			Product:     product,
+			UserID:      a.UserID,
+			Email:       a.UserID,
+			CollectedAt: at,
+		})
+	}
+	return seats
+}
+
+// FromOktaAppSeats converts the users assigned to an Okta application into seats of the application
+// - Application users only carry the user's ID, so `emails` maps user IDs to email addresses
+func FromOktaAppSeats(app *okta.Application, users okta.Users, emails map[string]string, at time.Time) []*Seat {
+	seats := []*Seat{}
+	for _, u := range users {
+		if u == nil {
+			continue
+		}
+		seats = append(seats, &Seat{
+			Source:      Okta,
+			Product:     app.Label,
+			UserID:      u.ID,
+			Email:       emails[u.ID],
+			Status:      string(u.Status),
+			CollectedAt: at,
+		})
+	}
+	return seats
+}
+
+// FromAdobeSeats converts the product profiles of Adobe users into seats, one per product
+// - `profiles` are the product profiles of the organization; other groups of a user are not licenses
+func FromAdobeSeats(users []*adobe.User, profiles []*adobe.Group, at time.Time) []*Seat {
+	products := map[string]string{}
+	for _, p := range profiles {
+		if p == nil {
+			continue
+		}
+		products[p.GroupName] = p.ProductName
+		if p.ProductName == "" {
+			products[p.GroupName] = p.GroupName
+		}
+	}
+
+	seats := []*Seat{}
+	for _, u := range users {
+		if u == nil {
+			continue
+		}
+
+		// A user in several profiles of the same product holds a single seat of it
+		held := map[string]bool{}
+		for _, group := range u.Groups {
+			product, ok := products[group]
+			if !ok || held[product] {
+				continue
+			}
+			held[product] = true
+			seats = append(seats, &Seat{
+				Source:      Adobe,
+				Product:     product,
+				UserID:      u.ID,
+				Email:       u.Email,
+				Status:      string(u.Status),
+				CollectedAt: at,
+			})
+		}
+	}
+	return seats
+}
+
+// oktaFactorTypes normalizes the `factorType` of Okta factors
+var oktaFactorTypes = map[okta.FactorType]string{
+	okta.WEBAUTHN:            FactorWebAuthn,
+	okta.U2F:                 FactorU2F,
+	okta.SIGNED_NONCE:        FactorFastPass,
+	okta.PUSH:                FactorPush,
+	okta.TOKEN_SOFTWARE_TOTP: FactorTOTP,
+	okta.TOKEN_HOTP:          FactorTOTP,
+	okta.TOKEN_HARDWARE:      FactorHardwareToken,
+	okta.TOKEN:               FactorHardwareToken,
+	okta.SMS:                 FactorSMS,
+	okta.CALL:                FactorVoice,
+	okta.EMAIL:               FactorEmail,
+	okta.QUESTION:            FactorQuestion,
+}
+
+// phishingResistant reports whether a normalized factor type is bound to the origin it authenticates to
+func phishingResistant(factorType string) bool {
+	switch factorType {
+	case FactorWebAuthn, FactorU2F, FactorSecurityKey, FactorFastPass:
+		return true
+	}
+	return false
+}
+
+func newFactor(source Source, userID, email, factorType, detail string, at time.Time) *EnrolledFactor {
+	return &EnrolledFactor{
+		Source:            source,
+		UserID:            userID,
+		Email:             email,
+		Type:              factorType,
+		Detail:            detail,
+		PhishingResistant: phishingResistant(factorType),
+		CollectedAt:       at,
+	}
+}
+
+// FromOktaFactors converts the active factors of an Okta user into enrolled factors
+func FromOktaFactors(user *okta.User, factors okta.Factors, at time.Time) []*EnrolledFactor {
+	enrolled := []*EnrolledFactor{}
+	if user == nil {
+		return enrolled
+	}
+
+	email := ""
+	if user.Profile != nil {
+		email = user.Profile.Email
+	}
+	for _, f := range factors {
+		if f == nil || f.Status != "ACTIVE" {
+			continue
+		}
+		factorType, ok := oktaFactorTypes[f.FactorType]
+		if !ok {
+			factorType = FactorOther
+		}
+		enrolled = append(enrolled, newFactor(Okta, user.ID, email, factorType, string(f.FactorType), at))
+	}
+	return enrolled
+}
+
+// Parameters of the Google user usage report read by `FromGoogleUsage`
+const (
+	Google2SVEnrolled  = "accounts:is_2sv_enrolled"
+	GoogleSecurityKeys = "accounts:num_security_keys"
+)
+
+/*
+ * # FromGoogleUsage converts the 2-Step Verification status of Google users into enrolled factors
+ * - The usage report only tells whether a user is enrolled, and how many security keys they registered, so a user
+ *   enrolled without security keys is reported with the `2sv` factor
+ */
+func FromGoogleUsage(reports []*google.UsageReport, at time.Time) []*EnrolledFactor {
+	enrolled := []*EnrolledFactor{}
+	for _, r := range reports {
+		if r == nil {
+			continue
+		}
+
+		keys := 0
+		if p, ok := r.Parameter(GoogleSecurityKeys); ok {
+			keys, _ = strconv.Atoi(p.IntValue)
+		}
+		if keys > 0 {
+			enrolled = append(enrolled, newFactor(Google, r.Entity.ProfileID, r.Entity.UserEmail, FactorSecurityKey, GoogleSecurityKeys, at))
+			continue
+		}
+		if p, ok := r.Parameter(Google2SVEnrolled); ok && p.BoolValue {
+			enrolled = append(enrolled, newFactor(Google, r.Entity.ProfileID, r.Entity.UserEmail, FactorTwoStep, Google2SVEnrolled, at))
+		}
+	}
+	return enrolled
+}
+
+// duoCapabilities normalizes the capabilities of Duo phones
+var duoCapabilities = map[string]string{
+	"push":       FactorPush,
+	"mobile_otp": FactorTOTP,
+	"sms":        FactorSMS,
+	"phone":      FactorVoice,
+}
+
+/*
+ * # FromDuoUsers converts the devices of Duo users into enrolled factors
+ * - Users in `bypass` status are not prompted for a factor, and `disabled` users cannot sign in, so neither has any
+ * - Duo users are matched by email, or by username when it is an email address
+ */
+func FromDuoUsers(users duo.Users, at time.Time) []*EnrolledFactor {
+	enrolled := []*EnrolledFactor{}
+	for _, u := range users {
+		if u == nil || u.Status == "bypass" || u.Status == "disabled" {
+			continue
+		}
+
+		email := u.Email
+		if email == "" && strings.Contains(u.Username, "@") {
+			email = u.Username
+		}
+		add := func(factorType, detail string) {
+			enrolled = append(enrolled, newFactor(Duo, u.UserID, email, factorType, detail, at))
+		}
+
+		for _, p := range u.Phones {
+			if p == nil {
+				continue
+			}
+			for _, capability := range p.Capabilities {
+				if factorType, ok := duoCapabilities[capability]; ok && (factorType != FactorPush || p.Activated) {
+					add(factorType, "phone:"+capability)
+				}
+			}
+		}
+		for _, t := range u.Tokens {
+			if t != nil {
+				add(FactorHardwareToken, "token:"+t.Type)
+			}
+		}
+		for range u.U2FTokens {
+			add(FactorU2F, "u2ftoken")
+		}
+		for _, w := range u.WebAuthnCredentials {
+			if w != nil {
+				add(FactorWebAuthn, "webauthn:"+w.Label)
+			}
+		}
+	}
+	return enrolled
+}
+
+// neverExpires is the expiry Google reports for service account keys which never expire
+const neverExpires = 9999
+
+// FromGoogleServiceAccountKeys converts the user-managed keys of a Google Cloud service account into credentials
+func FromGoogleServiceAccountKeys(account *google.IAMServiceAccount, keys []*google.ServiceAccountKey, at time.Time) []*Credential {
+	credentials := []*Credential{}
+	if account == nil {
+		return credentials
+	}
+
+	for _, k := range keys {
+		if k == nil {
+			continue
+		}
+
+		expires := k.ValidBeforeTime.Time
+		if expires.Year() >= neverExpires {
+			expires = time.Time{}
+		}
+		credentials = append(credentials, &Credential{
+			Source:      Google,
+			Kind:        KindServiceAccountKey,
+			ID:          k.Name[strings.LastIndex(k.Name, "/")+1:],
+			Name:        account.DisplayName,
+			Owner:       account.Email,
+			Created:     k.ValidAfterTime.Time,
+			Expires:     expires,
+			Disabled:    k.Disabled || account.Disabled,
+			CollectedAt: at,
+		})
+	}
+	return credentials
+}
+
+// FromOktaAPITokens converts Okta API tokens into credentials; a token expires once unused for its token window
+func FromOktaAPITokens(tokens okta.APITokens, at time.Time) []*Credential {
+	credentials := []*Credential{}
+	for _, t := range tokens {
+		if t == nil {
+			continue
+		}
+		credentials = append(credentials, &Credential{
+			Source:      Okta,
+			Kind:        KindAPIToken,
+			ID:          t.ID,
+			Name:        t.Name,
+			Owner:       t.UserID,
+			Created:     t.Created,
+			Expires:     t.ExpiresAt,
+			CollectedAt: at,
+		})
+	}
+	return credentials
+}
+
+// OktaConsentGrant is the System Log event of a user granting an app consent to scopes
+const OktaConsentGrant = "app.oauth2.as.consent.grant"
+
+// splitScopes splits a list of scopes, separated by spaces or commas, or given as a JSON array
+func splitScopes(value interface{}) []string {
+	scopes := []string{}
+	switch v := value.(type) {
+	case string:
+		scopes = strings.FieldsFunc(v, func(r rune) bool { return r == ' ' || r == ',' })
+	case []interface{}:
+		for _, s := range v {
+			if s, ok := s.(string); ok && s != "" {
+				scopes = append(scopes, s)
+			}
+		}
+	case []string:
+		scopes = append(scopes, v...)
+	}
+	return scopes
+}
+
+/*
+ * # FromOktaGrantEvents converts successful Okta consent events into OAuth grants, one per event
+ * - The app is the `AppInstance` target of the event, and the scopes are read from its debug data
+ */
+func FromOktaGrantEvents(events okta.LogEvents) []*OAuthGrant {
+	grants := []*OAuthGrant{}
+	for _, e := range events {
+		if e == nil || e.Actor == nil || (e.Outcome != nil && e.Outcome.Result != "SUCCESS") {
+			continue
+		}
+
+		grant := &OAuthGrant{
+			Source:    Okta,
+			UserID:    e.Actor.ID,
+			Email:     e.Actor.AlternateID,
+			Events:    1,
+			FirstSeen: e.Published,
+			LastSeen:  e.Published,
+		}
+		for _, t := range e.Target {
+			if t != nil && t.Type == "AppInstance" {
+				grant.App = t.DisplayName
+				grant.ClientID = t.ID
+				break
+			}
+		}
+		if grant.App == "" && grant.ClientID == "" {
+			continue
+		}
+
+		if e.DebugContext != nil {
+			for _, key := range []string{"grantedScopes", "scopes", "requestedScopes"} {
+				if scopes := splitScopes(e.DebugContext.DebugData[key]); len(scopes) > 0 {
+					grant.Scopes = strings.Join(scopes, " ")
+					break
+				}
+			}
+		}
+		grants = append(grants, grant)
+	}
+	return grants
+}
+
+// GoogleTokenAuthorize is the token audit event of a user authorizing an app
+const GoogleTokenAuthorize = "authorize"
+
+/*
+ * # FromGoogleTokenActivities converts Google token audit activities into OAuth grants, one per `authorize` event
+ * - https://developers.google.com/admin-sdk/reports/v1/appendix/activity/token
+ */
+func FromGoogleTokenActivities(activities []google.Report) []*OAuthGrant {
+	grants := []*OAuthGrant{}
+	for _, a := range activities {
+		at := a.ID.Time.Time
+		for _, e := range a.Events {
+			if e.Name != GoogleTokenAuthorize {
+				continue
+			}
+
+			grant := &OAuthGrant{
+				Source:    Google,
+				UserID:    a.Actor.ProfileID,
+				Email:     a.Actor.Email,
+				Events:    1,
+				FirstSeen: at,
+				LastSeen:  at,
+			}
+			for _, p := range e.Parameters {
+				switch p.Name {
+				case "app_name":
+					grant.App = p.Value
+				case "client_id":
+					grant.ClientID = p.Value
+				case "scope":
+					grant.Scopes = strings.Join(p.MultiValue, " ")
+				}
+			}
+			grants = append(grants, grant)
+		}
+	}
+	return grants
+}
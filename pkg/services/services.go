@@ -0,0 +1,258 @@
+/*
+# Services
+
+This package constructs the clients of every configured service from a single config, so orchestration code can check,
+iterate over and close "all configured services" without naming each one:
+
+	r, err := services.New(services.ConfigFromEnv(log.INFO))
+	defer r.Close(ctx)
+	for name, err := range r.HealthCheck(ctx) { ... }
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/services/services.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gemini-oss/rego/pkg/active_directory"
+	"github.com/gemini-oss/rego/pkg/adobe"
+	"github.com/gemini-oss/rego/pkg/automox"
+	"github.com/gemini-oss/rego/pkg/backupify"
+	"github.com/gemini-oss/rego/pkg/common/config"
+	"github.com/gemini-oss/rego/pkg/docusign"
+	"github.com/gemini-oss/rego/pkg/duo"
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/jamf"
+	"github.com/gemini-oss/rego/pkg/mimecast"
+	"github.com/gemini-oss/rego/pkg/okta"
+	"github.com/gemini-oss/rego/pkg/slack"
+	"github.com/gemini-oss/rego/pkg/snipeit"
+)
+
+// ErrDuplicate is returned when a client is registered under the name of another
+var ErrDuplicate = errors.New("service already registered")
+
+/*
+ * # ServiceClient
+ * Implemented by the client of every provider
+ * - The base URL is returned by `Endpoint`, as the clients hold it in their `BaseURL` field
+ */
+type ServiceClient interface {
+	Name() string                          // Name of the service, e.g. `okta`, as recorded on the errors of its client
+	Endpoint() string                      // Base URL of the API the client sends its requests to
+	HealthCheck(ctx context.Context) error // Sends a cheap authenticated request, returning its error
+	Close(ctx context.Context) error       // Waits for the requests in flight, then flushes the cache
+}
+
+var (
+	_ ServiceClient = (*active_directory.Client)(nil)
+	_ ServiceClient = (*adobe.Client)(nil)
+	_ ServiceClient = (*automox.Client)(nil)
+	_ ServiceClient = (*backupify.Client)(nil)
+	_ ServiceClient = (*docusign.Client)(nil)
+	_ ServiceClient = (*duo.Client)(nil)
+	_ ServiceClient = (*google.Client)(nil)
+	_ ServiceClient = (*jamf.Client)(nil)
+	_ ServiceClient = (*mimecast.Client)(nil)
+	_ ServiceClient = (*okta.Client)(nil)
+	_ ServiceClient = (*slack.Client)(nil)
+	_ ServiceClient = (*snipeit.Client)(nil)
+)
+
+/*
+ * # Config
+ * The services a registry constructs the clients of, each from the variables its `NewClient` reads
+ * - The constructors exit when a variable they require is missing, so only set the services which are configured, or
+ *   use `ConfigFromEnv`
+ */
+type Config struct {
+	Verbosity       int                     // Log level of the clients
+	ActiveDirectory bool                    // `AD_LDAP_SERVER`, `AD_USERNAME`, ...
+	Adobe           bool                    // `ADOBE_CLIENT_ID`, `ADOBE_CLIENT_SECRET`, ...
+	Automox         bool                    // `AUTOMOX_API_KEY`, `AUTOMOX_ORG_ID`, ...
+	Backupify       bool                    // `BACKUPIFY_NODE_URL`, `BACKUPIFY_PHPSESSID`, ...
+	DocuSign        bool                    // `DOCUSIGN_ACCESS_TOKEN`, `DOCUSIGN_ACCOUNT_ID`, ...
+	Duo             bool                    // `DUO_API_HOST`, `DUO_INTEGRATION_KEY`, ...
+	Google          *google.AuthCredentials // Credentials of the Google client; not configured when nil
+	Jamf            bool                    // `JSS_URL`, `JSS_USERNAME`, ...
+	Mimecast        bool                    // `MIMECAST_CLIENT_ID`, `MIMECAST_CLIENT_SECRET`, ...
+	Okta            bool                    // `OKTA_API_TOKEN`, `OKTA_ORG_NAME`, ...
+	Slack           bool                    // `SLACK_API_TOKEN`, `SLACK_SIGNING_SECRET`
+	SnipeIT         bool                    // `SNIPEIT_TOKEN`, `SNIPEIT_URL`
+}
+
+/*
+ * # Config from the Environment
+ * Configures every service whose credential variable is set, in the environment or a config provider
+ * - Google is configured as a service account impersonating `GOOGLE_SUBJECT`, with the Admin SDK scopes; replace
+ *   `Config.Google` for others
+ */
+func ConfigFromEnv(verbosity int) Config {
+	set := func(name string) bool { return config.GetEnv(name) != "" }
+
+	cfg := Config{
+		Verbosity:       verbosity,
+		ActiveDirectory: set("AD_LDAP_SERVER"),
+		Adobe:           set("ADOBE_CLIENT_ID"),
+		Automox:         set("AUTOMOX_API_KEY"),
+		Backupify:       set("BACKUPIFY_NODE_URL"),
+		DocuSign:        set("DOCUSIGN_ACCESS_TOKEN"),
+		Duo:             set("DUO_INTEGRATION_KEY"),
+		Jamf:            set("JSS_URL"),
+		Mimecast:        set("MIMECAST_CLIENT_ID"),
+		Okta:            set("OKTA_API_TOKEN"),
+		Slack:           set("SLACK_API_TOKEN"),
+		SnipeIT:         set("SNIPEIT_TOKEN"),
+	}
+	if set("GOOGLE_SERVICE_ACCOUNT") {
+		cfg.Google = &google.AuthCredentials{
+			CICD:    true,
+			Type:    google.SERVICE_ACCOUNT,
+			Scopes:  []string{"Admin SDK API"},
+			Subject: config.GetEnv("GOOGLE_SUBJECT"),
+		}
+	}
+	return cfg
+}
+
+// Registry holds the clients of the configured services, by name
+type Registry struct {
+	mu      sync.RWMutex
+	clients map[string]ServiceClient
+}
+
+// NewRegistry returns an empty registry, for clients constructed elsewhere (see `Register`)
+func NewRegistry() *Registry {
+	return &Registry{clients: map[string]ServiceClient{}}
+}
+
+/*
+ * # New Registry
+ * Constructs the client of every service set in `cfg`
+ * - The clients constructed are closed when another fails
+ */
+func New(cfg Config) (*Registry, error) {
+	r := NewRegistry()
+	constructors := []struct {
+		enabled bool
+		new     func() (ServiceClient, error)
+	}{
+		{cfg.ActiveDirectory, func() (ServiceClient, error) { return active_directory.NewClient(cfg.Verbosity), nil }},
+		{cfg.Adobe, func() (ServiceClient, error) { return adobe.NewClient(cfg.Verbosity), nil }},
+		{cfg.Automox, func() (ServiceClient, error) { return automox.NewClient(cfg.Verbosity), nil }},
+		{cfg.Backupify, func() (ServiceClient, error) { return backupify.NewClient(cfg.Verbosity), nil }},
+		{cfg.DocuSign, func() (ServiceClient, error) { return docusign.NewClient(cfg.Verbosity), nil }},
+		{cfg.Duo, func() (ServiceClient, error) { return duo.NewClient(cfg.Verbosity), nil }},
+		{cfg.Google != nil, func() (ServiceClient, error) { return google.NewClient(*cfg.Google, cfg.Verbosity) }},
+		{cfg.Jamf, func() (ServiceClient, error) { return jamf.NewClient(cfg.Verbosity), nil }},
+		{cfg.Mimecast, func() (ServiceClient, error) { return mimecast.NewClient(cfg.Verbosity), nil }},
+		{cfg.Okta, func() (ServiceClient, error) { return okta.NewClient(cfg.Verbosity), nil }},
+		{cfg.Slack, func() (ServiceClient, error) { return slack.NewClient(cfg.Verbosity), nil }},
+		{cfg.SnipeIT, func() (ServiceClient, error) { return snipeit.NewClient(cfg.Verbosity), nil }},
+	}
+
+	for _, c := range constructors {
+		if !c.enabled {
+			continue
+		}
+		client, err := c.new()
+		if err == nil {
+			err = r.Register(client)
+		}
+		if err != nil {
+			return nil, errors.Join(err, r.Close(context.Background()))
+		}
+	}
+	return r, nil
+}
+
+// Register adds a client to the registry, e.g. of a service rego has no package for
+func (r *Registry) Register(c ServiceClient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.clients[c.Name()]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicate, c.Name())
+	}
+	r.clients[c.Name()] = c
+	return nil
+}
+
+// Get returns the client of the service `name`, e.g. `okta`
+func (r *Registry) Get(name string) (ServiceClient, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.clients[name]
+	return c, ok
+}
+
+// Names returns the names of the registered services, sorted
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.clients))
+	for name := range r.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Clients returns the registered clients, sorted by name
+func (r *Registry) Clients() []ServiceClient {
+	clients := []ServiceClient{}
+	for _, name := range r.Names() {
+		if c, ok := r.Get(name); ok {
+			clients = append(clients, c)
+		}
+	}
+	return clients
+}
+
+/*
+ * # Check the Health of the Services
+ * Checks every registered client at once, returning the error of each by name; nil for the healthy ones
+ */
+func (r *Registry) HealthCheck(ctx context.Context) map[string]error {
+	clients := r.Clients()
+	errs := make([]error, len(clients))
+
+	var wg sync.WaitGroup
+	for i, c := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = c.HealthCheck(ctx)
+		}()
+	}
+	wg.Wait()
+
+	health := make(map[string]error, len(clients))
+	for i, c := range clients {
+		health[c.Name()] = errs[i]
+	}
+	return health
+}
+
+/*
+ * # Close the Services
+ * Closes every registered client, waiting for their requests in flight until `ctx` is done
+ * - Every client is closed even when another fails; their errors are joined, each with its service
+ */
+func (r *Registry) Close(ctx context.Context) error {
+	var errs []error
+	for _, c := range r.Clients() {
+		if err := c.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
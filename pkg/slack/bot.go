@@ -24,6 +24,8 @@ import (
 	"net/url"
 	"strconv"
 	"time"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
 )
 
 func (c *Client) EventHandler(w http.ResponseWriter, r *http.Request) {
@@ -33,14 +35,14 @@ func (c *Client) EventHandler(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	c.Log.Println(string(body))
 	if err != nil {
-		c.Log.Printf("Error reading request body: %v", err)
+		c.Log.Errorf("Error reading request body: %v", err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
 	// Verify the request
 	if !c.VerifyRequest(r, body) {
-		c.Log.Printf("Failed to verify request")
+		c.Log.Warning("Failed to verify request")
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -52,7 +54,7 @@ func (c *Client) EventHandler(w http.ResponseWriter, r *http.Request) {
 	cb := &EventCallback{}
 	err = json.Unmarshal(body, &cb)
 	if err != nil {
-		c.Log.Printf("Error unmarshalling request body: %v", err)
+		c.Log.Errorf("Error unmarshalling request body: %v", err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
@@ -63,7 +65,7 @@ func (c *Client) EventHandler(w http.ResponseWriter, r *http.Request) {
 		challenge := &SlackChallenge{}
 		err := json.Unmarshal(body, &challenge)
 		if err != nil {
-			c.Log.Printf("Error decoding challenge: %v", err)
+			c.Log.Errorf("Error decoding challenge: %v", err)
 			http.Error(w, "Invalid request", http.StatusBadRequest)
 			return
 		}
@@ -72,7 +74,7 @@ func (c *Client) EventHandler(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/plain")
 		err = tmpl.Execute(w, challenge.Challenge)
 		if err != nil {
-			c.Log.Printf("Error executing template: %v", err)
+			c.Log.Errorf("Error executing template: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 		}
 
@@ -96,14 +98,14 @@ func (c *Client) CommandHandler(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	c.Log.Println(string(body))
 	if err != nil {
-		c.Log.Printf("Error reading request body: %v", err)
+		c.Log.Errorf("Error reading request body: %v", err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
 	// Verify the request
 	if !c.VerifyRequest(r, body) {
-		c.Log.Printf("Failed to verify request")
+		c.Log.Warning("Failed to verify request")
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
@@ -150,7 +152,7 @@ func (c *Client) GetBotID() (string, error) {
 
 	res, body, err := c.HTTP.DoRequest("POST", url, nil, p)
 	if err != nil {
-		return "", err
+		return "", rerrors.WithProvider(err, "slack")
 	}
 	c.Log.Println("Response Status:", res.Status)
 	c.Log.Debug("Response Body:", string(body))
@@ -180,7 +182,7 @@ func (c *Client) SendMessage(e *Event, m *SlackMessage) error {
 
 	res, body, err := c.HTTP.DoRequest("POST", url, nil, message)
 	if err != nil {
-		return err
+		return rerrors.WithProvider(err, "slack")
 	}
 	c.Log.Println("Response Status:", res.Status)
 	c.Log.Debug("Response Body:", string(body))
@@ -204,7 +206,7 @@ func (c *Client) SendReply(e *Event, m *SlackMessage) error {
 
 	res, body, err := c.HTTP.DoRequest("POST", url, nil, reply)
 	if err != nil {
-		return err
+		return rerrors.WithProvider(err, "slack")
 	}
 	c.Log.Println("Response Status:", res.Status)
 	c.Log.Debug("Response Body:", string(body))
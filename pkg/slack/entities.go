@@ -14,6 +14,7 @@ package slack
 import (
 	"github.com/gemini-oss/rego/pkg/common/cache"
 	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/normalize"
 	"github.com/gemini-oss/rego/pkg/common/requests"
 )
 
@@ -290,6 +291,14 @@ type Users struct {
 	ResponseMetadata Metadata `json:"response_metadata,omitempty"` // Metadata for the response.
 }
 
+// UserLookup represents the response from the Slack users.lookupByEmail method.
+// https://api.slack.com/methods/users.lookupByEmail
+type UserLookup struct {
+	Error string `json:"error,omitempty"` // Error code, if the request failed.
+	OK    bool   `json:"ok"`              // Response status.
+	User  Member `json:"user,omitempty"`  // The user matching the email address.
+}
+
 // Member represents a member in the Slack users.list method response.
 type Member struct {
 	Color             string  `json:"color,omitempty"`               // Member's color code.
@@ -313,6 +322,14 @@ type Member struct {
 	Updated           int64   `json:"updated,omitempty"`             // Timestamp for when the member was updated.
 }
 
+// AccountStatus returns the normalized status of the member; Slack deactivates members by deleting them
+func (m *Member) AccountStatus() normalize.Status {
+	if m.Deleted {
+		return normalize.StatusDeprovisioned
+	}
+	return normalize.StatusActive
+}
+
 // Profile represents a member's profile in the Slack users.list method response.
 type Profile struct {
 	AvatarHash            string `json:"avatar_hash,omitempty"`             // Avatar hash.
@@ -346,3 +363,39 @@ type Metadata struct {
 
 // END OF SLACK USER STRUCTS
 //---------------------------------------------------------------------
+
+// ### Slack User Group Structs
+// ---------------------------------------------------------------------
+type UsergroupList struct {
+	Error      string       `json:"error,omitempty"`      // Error code, if the request failed.
+	OK         bool         `json:"ok"`                   // Response status.
+	Usergroups []*Usergroup `json:"usergroups,omitempty"` // The user groups in the workspace.
+}
+
+type UsergroupUsers struct {
+	Error string   `json:"error,omitempty"` // Error code, if the request failed.
+	OK    bool     `json:"ok"`              // Response status.
+	Users []string `json:"users,omitempty"` // IDs of the users in the user group.
+}
+
+type UsergroupResponse struct {
+	Error     string    `json:"error,omitempty"`     // Error code, if the request failed.
+	OK        bool      `json:"ok"`                  // Response status.
+	Usergroup Usergroup `json:"usergroup,omitempty"` // The updated user group.
+}
+
+// Usergroup represents a Slack user group (e.g. `@engineering`).
+type Usergroup struct {
+	DateDelete  int64    `json:"date_delete,omitempty"` // Timestamp of when the user group was disabled, 0 if enabled.
+	Description string   `json:"description,omitempty"` // Description of the user group.
+	Handle      string   `json:"handle,omitempty"`      // Mention handle of the user group, without the `@`.
+	ID          string   `json:"id,omitempty"`          // ID of the user group.
+	IsExternal  bool     `json:"is_external,omitempty"` // Whether the user group is external.
+	Name        string   `json:"name,omitempty"`        // Name of the user group.
+	TeamID      string   `json:"team_id,omitempty"`     // ID of the team the user group belongs to.
+	UserCount   int      `json:"user_count,omitempty"`  // Number of users in the user group.
+	Users       []string `json:"users,omitempty"`       // IDs of the users in the user group (when requested).
+}
+
+// END OF SLACK USER GROUP STRUCTS
+//---------------------------------------------------------------------
@@ -0,0 +1,46 @@
+/*
+# Slack Interfaces
+
+This package contains the interfaces of each Slack resource, which the `Client` satisfies. Code depending on an
+interface rather than the `Client` can be unit tested with the mocks of `pkg/slack/slackmock`
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC., see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/slack/interfaces.go
+package slack
+
+//go:generate go run ../../cmd/mockgen
+
+// MessageService sends messages as the bot
+type MessageService interface {
+	GetBotID() (string, error)
+	SendMessage(e *Event, m *SlackMessage) error
+	SendReply(e *Event, m *SlackMessage) error
+}
+
+// UsergroupService manages user groups and their members
+type UsergroupService interface {
+	ListUsergroups() (*[]*Usergroup, error)
+	GetUsergroup(handle string) (*Usergroup, error)
+	AddUserToUsergroup(usergroupID string, userID string) (*Usergroup, error)
+	RemoveUserFromUsergroup(usergroupID string, userID string) (*Usergroup, error)
+	ListUsergroupMembers(usergroupID string) ([]string, error)
+	RenameUsergroup(usergroupID string, handle string) (*Usergroup, error)
+}
+
+// UserService manages users
+type UserService interface {
+	ListUsers() (*Users, error)
+	GetUserChannels(userID string) (*UserChannels, error)
+	LookupUserByEmail(email string) (*Member, error)
+	DeactivateUser(userID string) error
+}
+
+var (
+	_ MessageService   = (*Client)(nil)
+	_ UsergroupService = (*Client)(nil)
+	_ UserService      = (*Client)(nil)
+)
@@ -13,15 +13,21 @@ https://api.slack.com/web
 package slack
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/gemini-oss/rego/pkg/common/config"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
 	"github.com/gemini-oss/rego/pkg/common/log"
 	"github.com/gemini-oss/rego/pkg/common/requests"
 )
 
 const (
-	BaseURL = "https://slack.com/api" // https://slack.com/api/METHOD_FAMILY.method?pretty=1
+	BaseURL = "https://slack.com/api"         // https://slack.com/api/METHOD_FAMILY.method?pretty=1
+	SCIMURL = "https://api.slack.com/scim/v2" // https://api.slack.com/admins/scim2
 )
 
 // BuildURL builds a URL for a given resource and identifiers.
@@ -33,6 +39,53 @@ func (c *Client) BuildURL(endpoint string, identifiers ...string) string {
 	return url
 }
 
+/*
+ * # With Context
+ * Returns a copy of the client whose requests are bound to `ctx`
+ * - Cancelling `ctx` aborts the request in flight, and stops its retries
+ */
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cc := *c
+	cc.HTTP = c.HTTP.WithContext(ctx)
+	return &cc
+}
+
+// Close closes the HTTP client (see `requests.Client.Close`), then flushes the cache (see `cache.Cache.Flush`)
+func (c *Client) Close(ctx context.Context) error {
+	return errors.Join(c.HTTP.Close(ctx), c.Cache.Flush())
+}
+
+// Name returns the name of the service, `slack`
+func (c *Client) Name() string {
+	return "slack"
+}
+
+// Endpoint returns the base URL of the API the client sends its requests to
+func (c *Client) Endpoint() string {
+	return c.BaseURL
+}
+
+/*
+ * # Check the Health of the Slack Client
+ * Tests the token of the client, which Slack reports invalid in a successful response
+ * /api/auth.test
+ */
+func (c *Client) HealthCheck(ctx context.Context) error {
+	_, body, err := c.HTTP.DoRequestContext(ctx, "POST", c.BuildURL("%s/auth.test"), nil, nil)
+	if err != nil {
+		return rerrors.WithProvider(err, "slack")
+	}
+
+	var result Error
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("unmarshalling auth test: %w", err)
+	}
+	if !result.Ok {
+		return apiError(result.Error)
+	}
+	return nil
+}
+
 /*
   - # Generate Slack Client
   - @param log *log.Logger
@@ -75,11 +128,59 @@ func NewClient(verbosity int) *Client {
 		"Content-Type":  fmt.Sprintf("%s; charset=utf-8", requests.JSON),
 	}
 
+	httpClient := requests.NewClient(nil, headers, nil, requests.WithUserAgent("slack"))
+	httpClient.IsMutation = isMutation
+	httpClient.DryRunBody = []byte(`{"ok":true}`)
+
 	return &Client{
 		BaseURL:       BaseURL,
-		HTTP:          requests.NewClient(nil, headers, nil),
+		HTTP:          httpClient,
 		Log:           log,
 		Token:         token,
 		SigningSecret: signingSecret,
 	}
 }
+
+// isMutation reports whether a request changes state; Web API methods are often called with POST, so reads are identified by name
+func isMutation(method, url string) bool {
+	if method == "GET" {
+		return false
+	}
+	if !strings.HasPrefix(url, BaseURL) {
+		return true
+	}
+
+	name, _, _ := strings.Cut(url[strings.LastIndex(url, "/")+1:], "?")
+	for _, read := range []string{".list", ".info", ".lookupByEmail", ".history", ".replies", "auth.test"} {
+		if strings.HasSuffix(name, read) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+ * # Classify a Slack error
+ * Slack reports most failures in a successful response, as `{"ok": false, "error": "<code>"}`
+ * - https://api.slack.com/web#evaluating_responses
+ */
+func apiError(code string) *rerrors.APIError {
+	apiErr := &rerrors.APIError{Provider: "slack", Code: code, Message: strings.ReplaceAll(code, "_", " ")}
+	switch {
+	case code == "ratelimited":
+		apiErr.Class = rerrors.ErrRateLimited
+	case strings.HasSuffix(code, "_not_found"), code == "no_such_subteam":
+		apiErr.Class = rerrors.ErrNotFound
+	case code == "not_authed", code == "invalid_auth", code == "token_revoked", code == "token_expired", code == "account_inactive":
+		apiErr.Class = rerrors.ErrUnauthorized
+	case code == "missing_scope", code == "not_allowed_token_type", code == "no_permission", code == "restricted_action", code == "permission_denied":
+		apiErr.Class = rerrors.ErrForbidden
+	case strings.HasPrefix(code, "already_"), code == "name_already_exists", code == "handle_already_exists":
+		apiErr.Class = rerrors.ErrConflict
+	case code == "invalid_arguments", code == "invalid_users", code == "invalid_cursor":
+		apiErr.Class = rerrors.ErrInvalid
+	case code == "internal_error", code == "fatal_error", code == "service_unavailable", code == "request_timeout":
+		apiErr.Class = rerrors.ErrUnavailable
+	}
+	return apiErr
+}
@@ -0,0 +1,182 @@
+// Code generated by mockgen from interfaces.go; DO NOT EDIT.
+
+// pkg/slack/slackmock/mocks.go
+package slackmock
+
+import (
+	"sync"
+
+	"github.com/gemini-oss/rego/pkg/slack"
+)
+
+// Call is a call received by a mock
+type Call struct {
+	Method string        // Name of the method called
+	Args   []interface{} // Arguments of the call
+}
+
+// calls records the calls received by a mock
+type calls struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+func (c *calls) record(method string, args ...interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls received, in order; only those of `method` when set
+func (c *calls) Calls(method ...string) []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	received := []Call{}
+	for _, call := range c.calls {
+		if len(method) == 0 || call.Method == method[0] {
+			received = append(received, call)
+		}
+	}
+	return received
+}
+
+// MessageService is a mock of `slack.MessageService`; methods without a func return zero values
+type MessageService struct {
+	GetBotIDFunc    func() (string, error)
+	SendMessageFunc func(*slack.Event, *slack.SlackMessage) error
+	SendReplyFunc   func(*slack.Event, *slack.SlackMessage) error
+
+	calls
+}
+
+var _ slack.MessageService = (*MessageService)(nil)
+
+func (m *MessageService) GetBotID() (r0 string, r1 error) {
+	m.record("GetBotID")
+	if m.GetBotIDFunc != nil {
+		return m.GetBotIDFunc()
+	}
+	return
+}
+
+func (m *MessageService) SendMessage(a0 *slack.Event, a1 *slack.SlackMessage) (r0 error) {
+	m.record("SendMessage", a0, a1)
+	if m.SendMessageFunc != nil {
+		return m.SendMessageFunc(a0, a1)
+	}
+	return
+}
+
+func (m *MessageService) SendReply(a0 *slack.Event, a1 *slack.SlackMessage) (r0 error) {
+	m.record("SendReply", a0, a1)
+	if m.SendReplyFunc != nil {
+		return m.SendReplyFunc(a0, a1)
+	}
+	return
+}
+
+// UsergroupService is a mock of `slack.UsergroupService`; methods without a func return zero values
+type UsergroupService struct {
+	ListUsergroupsFunc          func() (*[]*slack.Usergroup, error)
+	GetUsergroupFunc            func(string) (*slack.Usergroup, error)
+	AddUserToUsergroupFunc      func(string, string) (*slack.Usergroup, error)
+	RemoveUserFromUsergroupFunc func(string, string) (*slack.Usergroup, error)
+	ListUsergroupMembersFunc    func(string) ([]string, error)
+	RenameUsergroupFunc         func(string, string) (*slack.Usergroup, error)
+
+	calls
+}
+
+var _ slack.UsergroupService = (*UsergroupService)(nil)
+
+func (m *UsergroupService) ListUsergroups() (r0 *[]*slack.Usergroup, r1 error) {
+	m.record("ListUsergroups")
+	if m.ListUsergroupsFunc != nil {
+		return m.ListUsergroupsFunc()
+	}
+	return
+}
+
+func (m *UsergroupService) GetUsergroup(a0 string) (r0 *slack.Usergroup, r1 error) {
+	m.record("GetUsergroup", a0)
+	if m.GetUsergroupFunc != nil {
+		return m.GetUsergroupFunc(a0)
+	}
+	return
+}
+
+func (m *UsergroupService) AddUserToUsergroup(a0 string, a1 string) (r0 *slack.Usergroup, r1 error) {
+	m.record("AddUserToUsergroup", a0, a1)
+	if m.AddUserToUsergroupFunc != nil {
+		return m.AddUserToUsergroupFunc(a0, a1)
+	}
+	return
+}
+
+func (m *UsergroupService) RemoveUserFromUsergroup(a0 string, a1 string) (r0 *slack.Usergroup, r1 error) {
+	m.record("RemoveUserFromUsergroup", a0, a1)
+	if m.RemoveUserFromUsergroupFunc != nil {
+		return m.RemoveUserFromUsergroupFunc(a0, a1)
+	}
+	return
+}
+
+func (m *UsergroupService) ListUsergroupMembers(a0 string) (r0 []string, r1 error) {
+	m.record("ListUsergroupMembers", a0)
+	if m.ListUsergroupMembersFunc != nil {
+		return m.ListUsergroupMembersFunc(a0)
+	}
+	return
+}
+
+func (m *UsergroupService) RenameUsergroup(a0 string, a1 string) (r0 *slack.Usergroup, r1 error) {
+	m.record("RenameUsergroup", a0, a1)
+	if m.RenameUsergroupFunc != nil {
+		return m.RenameUsergroupFunc(a0, a1)
+	}
+	return
+}
+
+// UserService is a mock of `slack.UserService`; methods without a func return zero values
+type UserService struct {
+	ListUsersFunc         func() (*slack.Users, error)
+	GetUserChannelsFunc   func(string) (*slack.UserChannels, error)
+	LookupUserByEmailFunc func(string) (*slack.Member, error)
+	DeactivateUserFunc    func(string) error
+
+	calls
+}
+
+var _ slack.UserService = (*UserService)(nil)
+
+func (m *UserService) ListUsers() (r0 *slack.Users, r1 error) {
+	m.record("ListUsers")
+	if m.ListUsersFunc != nil {
+		return m.ListUsersFunc()
+	}
+	return
+}
+
+func (m *UserService) GetUserChannels(a0 string) (r0 *slack.UserChannels, r1 error) {
+	m.record("GetUserChannels", a0)
+	if m.GetUserChannelsFunc != nil {
+		return m.GetUserChannelsFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) LookupUserByEmail(a0 string) (r0 *slack.Member, r1 error) {
+	m.record("LookupUserByEmail", a0)
+	if m.LookupUserByEmailFunc != nil {
+		return m.LookupUserByEmailFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) DeactivateUser(a0 string) (r0 error) {
+	m.record("DeactivateUser", a0)
+	if m.DeactivateUserFunc != nil {
+		return m.DeactivateUserFunc(a0)
+	}
+	return
+}
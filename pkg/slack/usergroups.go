@@ -0,0 +1,182 @@
+/*
+# Slack - User Groups
+
+This package contains the methods for managing Slack user groups with the Slack Web API:
+https://api.slack.com/methods#usergroups
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC., see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/slack/usergroups.go
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
+)
+
+// https://api.slack.com/methods/usergroups.list
+func (c *Client) ListUsergroups() (*[]*Usergroup, error) {
+	list := &UsergroupList{}
+	url := c.BuildURL("%s/usergroups.list")
+
+	q := struct {
+		IncludeUsers bool `url:"include_users"`
+	}{
+		IncludeUsers: true,
+	}
+
+	res, body, err := c.HTTP.DoRequest("GET", url, q, nil)
+	if err != nil {
+		return nil, rerrors.WithProvider(err, "slack")
+	}
+	c.Log.Println("Response Status:", res.Status)
+	c.Log.Debug("Response Body:", string(body))
+
+	err = json.Unmarshal(body, &list)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling usergroups: %w", err)
+	}
+
+	if !list.OK {
+		return nil, fmt.Errorf("listing usergroups: %w", apiError(list.Error))
+	}
+
+	return &list.Usergroups, nil
+}
+
+// Finds a user group by its handle (e.g. `engineering`, without the `@`) or name
+func (c *Client) GetUsergroup(handle string) (*Usergroup, error) {
+	usergroups, err := c.ListUsergroups()
+	if err != nil {
+		return nil, err
+	}
+
+	handle = strings.TrimPrefix(handle, "@")
+	for _, ug := range *usergroups {
+		if strings.EqualFold(ug.Handle, handle) || strings.EqualFold(ug.Name, handle) {
+			return ug, nil
+		}
+	}
+
+	return nil, fmt.Errorf("usergroup %s: %w", handle, rerrors.ErrNotFound)
+}
+
+// Adds a user to a user group, keeping its existing members
+// https://api.slack.com/methods/usergroups.users.update
+func (c *Client) AddUserToUsergroup(usergroupID string, userID string) (*Usergroup, error) {
+	users, err := c.ListUsergroupMembers(usergroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(users, userID) {
+		users = append(users, userID)
+	}
+
+	c.Log.Printf("Adding Slack user %s to usergroup %s", userID, usergroupID)
+	return c.updateUsergroupMembers(usergroupID, users)
+}
+
+// Removes a user from a user group, keeping its other members; Slack rejects removing the last member
+// https://api.slack.com/methods/usergroups.users.update
+func (c *Client) RemoveUserFromUsergroup(usergroupID string, userID string) (*Usergroup, error) {
+	users, err := c.ListUsergroupMembers(usergroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	users = slices.DeleteFunc(users, func(id string) bool { return id == userID })
+
+	c.Log.Printf("Removing Slack user %s from usergroup %s", userID, usergroupID)
+	return c.updateUsergroupMembers(usergroupID, users)
+}
+
+// Lists the IDs of the members of a user group
+// https://api.slack.com/methods/usergroups.users.list
+func (c *Client) ListUsergroupMembers(usergroupID string) ([]string, error) {
+	members := &UsergroupUsers{}
+	url := c.BuildURL("%s/usergroups.users.list")
+
+	q := struct {
+		Usergroup string `url:"usergroup"`
+	}{
+		Usergroup: usergroupID,
+	}
+
+	_, body, err := c.HTTP.DoRequest("GET", url, q, nil)
+	if err != nil {
+		return nil, rerrors.WithProvider(err, "slack")
+	}
+
+	err = json.Unmarshal(body, &members)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling usergroup members: %w", err)
+	}
+
+	if !members.OK {
+		return nil, fmt.Errorf("listing members of %s: %w", usergroupID, apiError(members.Error))
+	}
+
+	return members.Users, nil
+}
+
+// Replaces the members of a user group
+// https://api.slack.com/methods/usergroups.users.update
+func (c *Client) updateUsergroupMembers(usergroupID string, users []string) (*Usergroup, error) {
+	params := struct {
+		Usergroup string `url:"usergroup"`
+		Users     string `url:"users"`
+	}{
+		Usergroup: usergroupID,
+		Users:     strings.Join(users, ","),
+	}
+
+	return c.updateUsergroup("%s/usergroups.users.update", usergroupID, params)
+}
+
+// Changes the handle (and name) of a user group
+// https://api.slack.com/methods/usergroups.update
+func (c *Client) RenameUsergroup(usergroupID string, handle string) (*Usergroup, error) {
+	params := struct {
+		Usergroup string `url:"usergroup"`
+		Handle    string `url:"handle"`
+		Name      string `url:"name"`
+	}{
+		Usergroup: usergroupID,
+		Handle:    strings.TrimPrefix(handle, "@"),
+		Name:      strings.TrimPrefix(handle, "@"),
+	}
+
+	c.Log.Printf("Renaming Slack usergroup %s to %s", usergroupID, params.Handle)
+	return c.updateUsergroup("%s/usergroups.update", usergroupID, params)
+}
+
+func (c *Client) updateUsergroup(endpoint string, usergroupID string, params interface{}) (*Usergroup, error) {
+	update := &UsergroupResponse{}
+	url := c.BuildURL(endpoint)
+
+	res, body, err := c.HTTP.DoRequest("POST", url, params, nil)
+	if err != nil {
+		return nil, rerrors.WithProvider(err, "slack")
+	}
+	c.Log.Println("Response Status:", res.Status)
+	c.Log.Debug("Response Body:", string(body))
+
+	err = json.Unmarshal(body, &update)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling usergroup: %w", err)
+	}
+
+	if !update.OK {
+		return nil, fmt.Errorf("updating usergroup %s: %w", usergroupID, apiError(update.Error))
+	}
+
+	return &update.Usergroup, nil
+}
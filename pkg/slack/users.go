@@ -16,6 +16,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
 	"github.com/gemini-oss/rego/pkg/common/requests"
 )
 
@@ -46,7 +47,7 @@ func (c *Client) ListUsers() (*Users, error) {
 
 	res, body, err := c.HTTP.DoRequest("POST", url, nil, p)
 	if err != nil {
-		return nil, err
+		return nil, rerrors.WithProvider(err, "slack")
 	}
 	c.Log.Println("Response Status:", res.Status)
 	c.Log.Debug("Response Body:", string(body))
@@ -73,7 +74,7 @@ func (c *Client) GetUserChannels(userID string) (*UserChannels, error) {
 
 	res, body, err := c.HTTP.DoRequest("GET", url, q, nil)
 	if err != nil {
-		return nil, err
+		return nil, rerrors.WithProvider(err, "slack")
 	}
 	c.Log.Println("Response Status:", res.Status)
 	c.Log.Debug("Response Body:", string(body))
@@ -85,3 +86,48 @@ func (c *Client) GetUserChannels(userID string) (*UserChannels, error) {
 
 	return user_channels, nil
 }
+
+// https://api.slack.com/methods/users.lookupByEmail
+func (c *Client) LookupUserByEmail(email string) (*Member, error) {
+	lookup := &UserLookup{}
+	url := c.BuildURL("%s/users.lookupByEmail")
+
+	q := struct {
+		Email string `url:"email"`
+	}{
+		Email: email,
+	}
+
+	res, body, err := c.HTTP.DoRequest("GET", url, q, nil)
+	if err != nil {
+		return nil, rerrors.WithProvider(err, "slack")
+	}
+	c.Log.Println("Response Status:", res.Status)
+	c.Log.Debug("Response Body:", string(body))
+
+	err = json.Unmarshal(body, &lookup)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling user: %w", err)
+	}
+
+	if !lookup.OK {
+		return nil, fmt.Errorf("looking up %s: %w", email, apiError(lookup.Error))
+	}
+
+	return &lookup.User, nil
+}
+
+// Deactivates a user via the SCIM API (requires an admin token with the `admin` scope)
+// https://api.slack.com/admins/scim2#delete-users-id
+func (c *Client) DeactivateUser(userID string) error {
+	url := fmt.Sprintf("%s/Users/%s", SCIMURL, userID)
+
+	c.Log.Printf("Deactivating Slack user %s", userID)
+	res, _, err := c.HTTP.DoRequest("DELETE", url, nil, nil)
+	if err != nil {
+		return rerrors.WithProvider(err, "slack")
+	}
+	c.Log.Println("Response Status:", res.Status)
+
+	return nil
+}
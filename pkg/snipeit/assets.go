@@ -13,6 +13,8 @@ https://developer.okta.com/docs/api/
 package snipeit
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 )
 
@@ -115,3 +117,72 @@ func (c *AssetClient) GetAllAssets() (*HardwareList, error) {
 	c.SetCache(url, assets, 5*time.Minute)
 	return assets, nil
 }
+
+/*
+ * Check in a Hardware Asset
+ * /api/v1/hardware/{id}/checkin
+ * - https://snipe-it.readme.io/reference/hardware-checkin
+ */
+func (c *AssetClient) CheckinAsset(assetID int, note string) error {
+	url := c.BuildURL(Assets, strconv.Itoa(assetID), "checkin")
+
+	payload := map[string]interface{}{
+		"note": note,
+	}
+
+	c.Log.Printf("Checking in Snipe-IT asset %d", assetID)
+	res, err := do[ActionResponse](c.Client, "POST", url, nil, payload)
+	if err != nil {
+		return err
+	}
+	if res.Status != "success" {
+		return fmt.Errorf("checking in asset %d: %v", assetID, res.Messages)
+	}
+
+	return nil
+}
+
+/*
+ * Get a Hardware Asset by its Asset Tag
+ * /api/v1/hardware/bytag/{asset_tag}
+ * - https://snipe-it.readme.io/reference/hardwarebytag
+ */
+func (c *AssetClient) GetAssetByTag(tag string) (*Hardware, error) {
+	url := c.BuildURL(Assets, "bytag", tag)
+
+	asset, err := do[Hardware](c.Client, "GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if asset.ID == 0 {
+		return nil, fmt.Errorf("asset with tag %s not found", tag)
+	}
+
+	return &asset, nil
+}
+
+/*
+ * Check out a Hardware Asset to a User
+ * /api/v1/hardware/{id}/checkout
+ * - https://snipe-it.readme.io/reference/hardware-checkout
+ */
+func (c *AssetClient) CheckoutAsset(assetID int, userID int64, note string) error {
+	url := c.BuildURL(Assets, strconv.Itoa(assetID), "checkout")
+
+	payload := map[string]interface{}{
+		"checkout_to_type": "user",
+		"assigned_user":    userID,
+		"note":             note,
+	}
+
+	c.Log.Printf("Checking out Snipe-IT asset %d to user %d", assetID, userID)
+	res, err := do[ActionResponse](c.Client, "POST", url, nil, payload)
+	if err != nil {
+		return err
+	}
+	if res.Status != "success" {
+		return fmt.Errorf("checking out asset %d: %v", assetID, res.Messages)
+	}
+
+	return nil
+}
@@ -76,6 +76,13 @@ type QueryInterface interface {
 	SetOffset(int)
 }
 
+// ActionResponse represents the response from SnipeIT for write operations (checkin, update, etc.)
+type ActionResponse struct {
+	Status   string      `json:"status,omitempty"`   // The status of the operation {success, error}
+	Messages interface{} `json:"messages,omitempty"` // A message (or map of validation messages) describing the result.
+	Payload  interface{} `json:"payload,omitempty"`  // The affected resource.
+}
+
 // END OF SNIPEIT CLIENT STRUCTS
 //---------------------------------------------------------------------
 
@@ -0,0 +1,47 @@
+/*
+# Snipe-IT Interfaces
+
+This package contains the interfaces of each Snipe-IT resource, which the resource clients satisfy. Code depending on an
+interface rather than the clients can be unit tested with the mocks of `pkg/snipeit/snipeitmock`
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC., see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/snipeit/interfaces.go
+package snipeit
+
+//go:generate go run ../../cmd/mockgen
+
+// AccessoryService lists accessories
+type AccessoryService interface {
+	GetAllAccessories() (*AccessoryList, error)
+}
+
+// AssetService manages assets and their checkouts
+type AssetService interface {
+	GetAllAssets() (*HardwareList, error)
+	CheckinAsset(assetID int, note string) error
+	GetAssetByTag(tag string) (*Hardware, error)
+	CheckoutAsset(assetID int, userID int64, note string) error
+}
+
+// LocationService lists locations
+type LocationService interface {
+	GetAllLocations() (*LocationList, error)
+}
+
+// UserService manages users and their assets
+type UserService interface {
+	GetUserByEmail(email string) (*User, error)
+	GetUserAssets(userID int64) (*HardwareList, error)
+	DeactivateUser(userID int64) error
+}
+
+var (
+	_ AccessoryService = (*AccessoryClient)(nil)
+	_ AssetService     = (*AssetClient)(nil)
+	_ LocationService  = (*LocationClient)(nil)
+	_ UserService      = (*UserClient)(nil)
+)
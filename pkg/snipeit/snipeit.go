@@ -13,7 +13,9 @@ https://developer.okta.com/docs/api/
 package snipeit
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -21,7 +23,9 @@ import (
 
 	"github.com/gemini-oss/rego/pkg/common/cache"
 	"github.com/gemini-oss/rego/pkg/common/config"
+	rerrors "github.com/gemini-oss/rego/pkg/common/errors"
 	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/pool"
 	"github.com/gemini-oss/rego/pkg/common/ratelimit"
 	"github.com/gemini-oss/rego/pkg/common/requests"
 )
@@ -63,6 +67,42 @@ func (c *Client) BuildURL(endpoint string, identifiers ...string) string {
 	return url
 }
 
+/*
+ * # With Context
+ * Returns a copy of the client whose requests are bound to `ctx`
+ * - Cancelling `ctx` aborts the request in flight, and stops its retries
+ */
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cc := *c
+	cc.HTTP = c.HTTP.WithContext(ctx)
+	return &cc
+}
+
+// Close closes the HTTP client (see `requests.Client.Close`), then flushes the cache (see `cache.Cache.Flush`)
+func (c *Client) Close(ctx context.Context) error {
+	return errors.Join(c.HTTP.Close(ctx), c.Cache.Flush())
+}
+
+// Name returns the name of the service, `snipeit`
+func (c *Client) Name() string {
+	return "snipeit"
+}
+
+// Endpoint returns the base URL of the API the client sends its requests to
+func (c *Client) Endpoint() string {
+	return c.BaseURL
+}
+
+/*
+ * # Check the Health of the SnipeIT Client
+ * Gets the user of the API token, so the server is reachable and the token valid
+ * /api/v1/users/me
+ */
+func (c *Client) HealthCheck(ctx context.Context) error {
+	_, _, err := c.HTTP.DoRequestContext(ctx, "GET", c.BuildURL(Users, "me"), nil, nil)
+	return rerrors.WithProvider(err, "snipeit")
+}
+
 /*
  * SetCache stores a SnipeIT API response in the cache
  */
@@ -131,9 +171,12 @@ func NewClient(verbosity int) *Client {
 	// https://snipe-it.readme.io/reference/api-throttling
 	rl := ratelimit.NewRateLimiter(120, 1*time.Minute)
 
+	httpClient := requests.NewClient(nil, headers, rl, requests.WithUserAgent("snipeit"))
+	httpClient.DryRunBody = []byte(`{"status":"success"}`)
+
 	return &Client{
 		BaseURL: BaseURL,
-		HTTP:    requests.NewClient(nil, headers, rl),
+		HTTP:    httpClient,
 		Log:     log,
 		Cache:   cache,
 	}
@@ -153,7 +196,7 @@ func do[T any](c *Client, method string, url string, query interface{}, data int
 	var result T
 	res, body, err := c.HTTP.DoRequest(method, url, query, data)
 	if err != nil {
-		return *new(T), err
+		return *new(T), rerrors.WithProvider(err, "snipeit")
 	}
 
 	c.Log.Println("Response Status:", res.Status)
@@ -177,21 +220,18 @@ func doConcurrent[T PaginatedResponse[E], E any](c *Client, method, url string,
 		return nil, err
 	}
 
-	// Init concurrency control
-	sem := make(chan struct{}, 10)
-	var wg sync.WaitGroup
-	var resultsMutex sync.Mutex
-
 	// Initialize offset and limit based on the query interface.
 	offset := query.GetOffset()
 	limit := query.GetLimit()
 
-	// Function to fetch each page concurrently.
-	fetchPage := func(offset int) {
-		defer wg.Done()
-		sem <- struct{}{}
-		defer func() { <-sem }()
+	offsets := []int{}
+	for nextOffset := offset + limit; nextOffset < results.TotalCount(); nextOffset += limit {
+		offsets = append(offsets, nextOffset)
+	}
 
+	// Fetch the remaining pages concurrently.
+	var resultsMutex sync.Mutex
+	pool.Each(c.HTTP.Context(), offsets, pool.Options{Workers: 10, RateLimiter: c.HTTP.RateLimiter}, func(_ context.Context, offset int) error {
 		q := query.Copy()
 		q.SetOffset(offset)
 		q.SetLimit(limit)
@@ -199,20 +239,14 @@ func doConcurrent[T PaginatedResponse[E], E any](c *Client, method, url string,
 		page, err := do[T](c, method, url, q, data)
 		if err != nil {
 			c.Log.Error("Error fetching page:", err)
-			return
+			return err
 		}
 
 		resultsMutex.Lock()
 		results.Append(page.Elements())
 		resultsMutex.Unlock()
-	}
-
-	// Start fetching remaining pages.
-	for nextOffset := offset + limit; nextOffset < results.TotalCount(); nextOffset += limit {
-		wg.Add(1)
-		go fetchPage(nextOffset)
-	}
-	wg.Wait()
+		return nil
+	})
 
 	return &results, nil
 }
@@ -0,0 +1,154 @@
+// Code generated by mockgen from interfaces.go; DO NOT EDIT.
+
+// pkg/snipeit/snipeitmock/mocks.go
+package snipeitmock
+
+import (
+	"sync"
+
+	"github.com/gemini-oss/rego/pkg/snipeit"
+)
+
+// Call is a call received by a mock
+type Call struct {
+	Method string        // Name of the method called
+	Args   []interface{} // Arguments of the call
+}
+
+// calls records the calls received by a mock
+type calls struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+func (c *calls) record(method string, args ...interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls received, in order; only those of `method` when set
+func (c *calls) Calls(method ...string) []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	received := []Call{}
+	for _, call := range c.calls {
+		if len(method) == 0 || call.Method == method[0] {
+			received = append(received, call)
+		}
+	}
+	return received
+}
+
+// AccessoryService is a mock of `snipeit.AccessoryService`; methods without a func return zero values
+type AccessoryService struct {
+	GetAllAccessoriesFunc func() (*snipeit.AccessoryList, error)
+
+	calls
+}
+
+var _ snipeit.AccessoryService = (*AccessoryService)(nil)
+
+func (m *AccessoryService) GetAllAccessories() (r0 *snipeit.AccessoryList, r1 error) {
+	m.record("GetAllAccessories")
+	if m.GetAllAccessoriesFunc != nil {
+		return m.GetAllAccessoriesFunc()
+	}
+	return
+}
+
+// AssetService is a mock of `snipeit.AssetService`; methods without a func return zero values
+type AssetService struct {
+	GetAllAssetsFunc  func() (*snipeit.HardwareList, error)
+	CheckinAssetFunc  func(int, string) error
+	GetAssetByTagFunc func(string) (*snipeit.Hardware, error)
+	CheckoutAssetFunc func(int, int64, string) error
+
+	calls
+}
+
+var _ snipeit.AssetService = (*AssetService)(nil)
+
+func (m *AssetService) GetAllAssets() (r0 *snipeit.HardwareList, r1 error) {
+	m.record("GetAllAssets")
+	if m.GetAllAssetsFunc != nil {
+		return m.GetAllAssetsFunc()
+	}
+	return
+}
+
+func (m *AssetService) CheckinAsset(a0 int, a1 string) (r0 error) {
+	m.record("CheckinAsset", a0, a1)
+	if m.CheckinAssetFunc != nil {
+		return m.CheckinAssetFunc(a0, a1)
+	}
+	return
+}
+
+func (m *AssetService) GetAssetByTag(a0 string) (r0 *snipeit.Hardware, r1 error) {
+	m.record("GetAssetByTag", a0)
+	if m.GetAssetByTagFunc != nil {
+		return m.GetAssetByTagFunc(a0)
+	}
+	return
+}
+
+func (m *AssetService) CheckoutAsset(a0 int, a1 int64, a2 string) (r0 error) {
+	m.record("CheckoutAsset", a0, a1, a2)
+	if m.CheckoutAssetFunc != nil {
+		return m.CheckoutAssetFunc(a0, a1, a2)
+	}
+	return
+}
+
+// LocationService is a mock of `snipeit.LocationService`; methods without a func return zero values
+type LocationService struct {
+	GetAllLocationsFunc func() (*snipeit.LocationList, error)
+
+	calls
+}
+
+var _ snipeit.LocationService = (*LocationService)(nil)
+
+func (m *LocationService) GetAllLocations() (r0 *snipeit.LocationList, r1 error) {
+	m.record("GetAllLocations")
+	if m.GetAllLocationsFunc != nil {
+		return m.GetAllLocationsFunc()
+	}
+	return
+}
+
+// UserService is a mock of `snipeit.UserService`; methods without a func return zero values
+type UserService struct {
+	GetUserByEmailFunc func(string) (*snipeit.User, error)
+	GetUserAssetsFunc  func(int64) (*snipeit.HardwareList, error)
+	DeactivateUserFunc func(int64) error
+
+	calls
+}
+
+var _ snipeit.UserService = (*UserService)(nil)
+
+func (m *UserService) GetUserByEmail(a0 string) (r0 *snipeit.User, r1 error) {
+	m.record("GetUserByEmail", a0)
+	if m.GetUserByEmailFunc != nil {
+		return m.GetUserByEmailFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) GetUserAssets(a0 int64) (r0 *snipeit.HardwareList, r1 error) {
+	m.record("GetUserAssets", a0)
+	if m.GetUserAssetsFunc != nil {
+		return m.GetUserAssetsFunc(a0)
+	}
+	return
+}
+
+func (m *UserService) DeactivateUser(a0 int64) (r0 error) {
+	m.record("DeactivateUser", a0)
+	if m.DeactivateUserFunc != nil {
+		return m.DeactivateUserFunc(a0)
+	}
+	return
+}
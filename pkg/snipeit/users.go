@@ -0,0 +1,103 @@
+/*
+# SnipeIT - Users
+
+This package initializes all the methods for functions which interact with the SnipeIT Users endpoints:
+https://snipe-it.readme.io/reference/users
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC., see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/snipeit/users.go
+package snipeit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// UserClient for chaining methods
+type UserClient struct {
+	*Client
+}
+
+// Entry point for user-related operations
+func (c *Client) Users() *UserClient {
+	uc := &UserClient{
+		Client: c,
+	}
+
+	return uc
+}
+
+/*
+ * Find a User in Snipe-IT by email address
+ * /api/v1/users?email={email}
+ * - https://snipe-it.readme.io/reference/users
+ */
+func (c *UserClient) GetUserByEmail(email string) (*User, error) {
+	url := c.BuildURL(Users)
+
+	q := struct {
+		Email string `url:"email"`
+	}{
+		Email: email,
+	}
+
+	users, err := do[UserList](c.Client, "GET", url, q, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if users.Rows != nil {
+		for _, user := range *users.Rows {
+			if strings.EqualFold(user.Email, email) {
+				return user, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("user with email %s not found", email)
+}
+
+/*
+ * List the Hardware Assets checked out to a User
+ * /api/v1/users/{id}/assets
+ * - https://snipe-it.readme.io/reference/usersidassets
+ */
+func (c *UserClient) GetUserAssets(userID int64) (*HardwareList, error) {
+	url := c.BuildURL(Users, strconv.FormatInt(userID, 10), "assets")
+
+	assets, err := do[HardwareList](c.Client, "GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &assets, nil
+}
+
+/*
+ * Deactivate a User so they can no longer log in or be assigned assets
+ * /api/v1/users/{id}
+ * - https://snipe-it.readme.io/reference/usersid-2
+ */
+func (c *UserClient) DeactivateUser(userID int64) error {
+	url := c.BuildURL(Users, strconv.FormatInt(userID, 10))
+
+	payload := map[string]interface{}{
+		"activated": false,
+	}
+
+	c.Log.Printf("Deactivating Snipe-IT user %d", userID)
+	res, err := do[ActionResponse](c.Client, "PATCH", url, nil, payload)
+	if err != nil {
+		return err
+	}
+	if res.Status != "success" {
+		return fmt.Errorf("deactivating user %d: %v", userID, res.Messages)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+# Storage - Entities [Structs]
+
+This package contains the structs of the snapshots and change history kept by the storage layer:
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/storage/entities.go
+package storage
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ### Storage Structs
+// ---------------------------------------------------------------------
+
+// Dataset is the type of resource stored, named `<provider>.<resource>`
+type Dataset string
+
+const (
+	GoogleGroupMembers Dataset = "google.group_members" // Scoped by group email
+	GoogleUsers        Dataset = "google.users"
+	InventoryDevices   Dataset = "inventory.devices"
+	OktaGroupMembers   Dataset = "okta.group_members" // Scoped by group ID
+	OktaUsers          Dataset = "okta.users"
+)
+
+// Record is a version of a resource, valid from one snapshot until the snapshot which changed or removed it
+type Record struct {
+	Key       string          `json:"key"`               // Identifier of the resource within its dataset, e.g. a user ID
+	Data      json.RawMessage `json:"data"`              // The resource, as JSON
+	ValidFrom time.Time       `json:"validFrom"`         // Time of the snapshot which first saw this version
+	ValidTo   *time.Time      `json:"validTo,omitempty"` // Time of the snapshot which changed or removed it; nil while current
+}
+
+// ChangeType is how a resource changed between two snapshots
+type ChangeType string
+
+const (
+	Created ChangeType = "created"
+	Updated ChangeType = "updated"
+	Deleted ChangeType = "deleted"
+)
+
+// Change is a resource which was created, updated or deleted between two snapshots
+type Change struct {
+	Dataset Dataset         `json:"dataset"`          // Dataset of the resource
+	Scope   string          `json:"scope,omitempty"`  // Scope of the snapshot, e.g. a group ID
+	Key     string          `json:"key"`              // Identifier of the resource
+	Type    ChangeType      `json:"type"`             // How the resource changed
+	Before  json.RawMessage `json:"before,omitempty"` // The resource before the change; empty when created
+	After   json.RawMessage `json:"after,omitempty"`  // The resource after the change; empty when deleted
+	At      time.Time       `json:"at"`               // Time of the snapshot which saw the change
+}
+
+// Snapshot summarizes one call to `Store.Snapshot`
+type Snapshot struct {
+	ID      int64     `json:"id"`              // Sequential identifier of the snapshot
+	Dataset Dataset   `json:"dataset"`         // Dataset which was snapshotted
+	Scope   string    `json:"scope,omitempty"` // Scope of the snapshot, e.g. a group ID
+	TakenAt time.Time `json:"takenAt"`         // Time the snapshot was taken
+	Records int       `json:"records"`         // Number of resources in the snapshot
+	Created int       `json:"created"`         // Number of resources which were not in the previous snapshot
+	Updated int       `json:"updated"`         // Number of resources which changed since the previous snapshot
+	Deleted int       `json:"deleted"`         // Number of resources in the previous snapshot which are gone
+}
+
+// END OF STORAGE STRUCTS
+//---------------------------------------------------------------------
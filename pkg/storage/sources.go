@@ -0,0 +1,91 @@
+// pkg/storage/sources.go
+package storage
+
+import (
+	"encoding/json"
+
+	"github.com/gemini-oss/rego/pkg/google"
+	"github.com/gemini-oss/rego/pkg/inventory"
+	"github.com/gemini-oss/rego/pkg/okta"
+)
+
+// NewRecord marshals a resource into a record
+func NewRecord(key string, v interface{}) (*Record, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &Record{Key: key, Data: data}, nil
+}
+
+/*
+ * # Convert Resources to Records
+ * - `key` returns the identifier of each resource; resources without one are skipped
+ * - Resources which cannot be marshalled are skipped, since provider structs always marshal
+ */
+func Records[T any](items []T, key func(T) string) []*Record {
+	records := []*Record{}
+	for _, item := range items {
+		k := key(item)
+		if k == "" {
+			continue
+		}
+		r, err := NewRecord(k, item)
+		if err != nil {
+			continue
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+// FromOktaUsers keys Okta users (or the members of an Okta group) by user ID
+func FromOktaUsers(users okta.Users) []*Record {
+	return Records(users, func(u *okta.User) string {
+		if u == nil {
+			return ""
+		}
+		return u.ID
+	})
+}
+
+// FromGoogleUsers keys Google users by user ID
+func FromGoogleUsers(users []*google.User) []*Record {
+	return Records(users, func(u *google.User) string {
+		if u == nil {
+			return ""
+		}
+		return u.ID
+	})
+}
+
+// FromGoogleMembers keys the members of a Google group by member ID, or by email for members without one
+func FromGoogleMembers(members []*google.Member) []*Record {
+	return Records(members, func(m *google.Member) string {
+		if m == nil {
+			return ""
+		}
+		if m.ID != "" {
+			return m.ID
+		}
+		return m.Email
+	})
+}
+
+// FromDevices keys aggregated devices by serial number, falling back to their UDID, first MAC address or hostname
+func FromDevices(devices inventory.Devices) []*Record {
+	return Records(devices, func(d *inventory.Device) string {
+		switch {
+		case d == nil:
+			return ""
+		case d.SerialNumber != "":
+			return d.SerialNumber
+		case d.UDID != "":
+			return d.UDID
+		case len(d.MACAddresses) > 0:
+			return d.MACAddresses[0]
+		default:
+			return d.Hostname
+		}
+	})
+}
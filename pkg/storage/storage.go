@@ -0,0 +1,427 @@
+/*
+# Storage
+
+This package snapshots resources pulled from providers (users, devices, group memberships) into SQLite, keeping every
+version of each resource with the time range it was valid, so the state at any point in time and the changes between
+snapshots can be queried without an external database:
+
+	import _ "modernc.org/sqlite" // or any other SQLite driver for `database/sql`
+
+	store, _ := storage.Open("sqlite", "rego.db", log.INFO)
+	members, _ := o.ListGroupMembers(groupID)
+	store.Snapshot(storage.OktaGroupMembers, groupID, storage.FromOktaUsers(*members))
+
+	// Who was in the group last month?
+	records, _ := store.AsOf(storage.OktaGroupMembers, groupID, time.Now().AddDate(0, -1, 0))
+
+The package only uses `database/sql`, so the SQLite driver is chosen (and linked) by the caller.
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/storage/storage.go
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/diff"
+	"github.com/gemini-oss/rego/pkg/common/log"
+)
+
+// Timestamps are stored as fixed-width UTC text, so they compare correctly as strings in any SQLite driver; every bound of
+// a query is converted to UTC too
+const timeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS rego_snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		dataset TEXT NOT NULL,
+		scope TEXT NOT NULL,
+		taken_at TEXT NOT NULL,
+		records INTEGER NOT NULL,
+		created INTEGER NOT NULL,
+		updated INTEGER NOT NULL,
+		deleted INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS rego_records (
+		dataset TEXT NOT NULL,
+		scope TEXT NOT NULL,
+		key TEXT NOT NULL,
+		data TEXT NOT NULL,
+		hash TEXT NOT NULL,
+		valid_from TEXT NOT NULL,
+		valid_to TEXT
+	)`,
+	`CREATE INDEX IF NOT EXISTS rego_records_scope ON rego_records (dataset, scope, valid_from)`,
+	`CREATE INDEX IF NOT EXISTS rego_records_key ON rego_records (dataset, key, valid_from)`,
+	`CREATE TABLE IF NOT EXISTS rego_changes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		dataset TEXT NOT NULL,
+		scope TEXT NOT NULL,
+		key TEXT NOT NULL,
+		change TEXT NOT NULL,
+		before TEXT,
+		after TEXT,
+		changed_at TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS rego_changes_scope ON rego_changes (dataset, scope, changed_at)`,
+	`CREATE INDEX IF NOT EXISTS rego_changes_key ON rego_changes (dataset, key, changed_at)`,
+}
+
+type Store struct {
+	DB  *sql.DB
+	Log *log.Logger
+	Now func() time.Time // Clock used for snapshot timestamps; defaults to `time.Now`
+}
+
+/*
+ * # Open a Store
+ * Opens a database with a registered `database/sql` driver (e.g. `sqlite` or `sqlite3`) and creates the tables
+ * - @param driver Name of the SQLite driver
+ * - @param dsn Data source name of the database, usually a file path
+ */
+func Open(driver, dsn string, verbosity int) (*Store, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := New(db, verbosity)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// New creates a store on an open SQLite database, creating the tables if they do not exist
+func New(db *sql.DB, verbosity int) (*Store, error) {
+	s := &Store{
+		DB:  db,
+		Log: log.NewLogger("{storage}", verbosity),
+	}
+
+	for _, statement := range schema {
+		if _, err := db.Exec(statement); err != nil {
+			return nil, fmt.Errorf("creating tables: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.DB.Close()
+}
+
+/*
+ * # Take a Snapshot
+ * Records the current state of a dataset (or of one scope of it, e.g. the members of one group)
+ * - Resources which are new or changed get a new version; resources missing from `records` are marked deleted
+ * - Unchanged resources are not rewritten, so frequent snapshots of a stable dataset are cheap
+ * - Records are compared by their JSON, so volatile fields (e.g. a last check-in time) produce a new version each time
+ */
+func (s *Store) Snapshot(dataset Dataset, scope string, records []*Record) (*Snapshot, error) {
+	now := s.now()
+	at := now.Format(timeFormat)
+	snapshot := &Snapshot{Dataset: dataset, Scope: scope, TakenAt: now}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	type version struct {
+		data []byte
+		hash string
+	}
+	current := map[string]version{}
+	rows, err := tx.Query(`SELECT key, data, hash FROM rego_records WHERE dataset = ? AND scope = ? AND valid_to IS NULL`, string(dataset), scope)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var key, data, hash string
+		if err := rows.Scan(&key, &data, &hash); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		current[key] = version{data: []byte(data), hash: hash}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, r := range records {
+		if r == nil || seen[r.Key] {
+			continue
+		}
+		seen[r.Key] = true
+		snapshot.Records++
+
+		hash := digest(r.Data)
+		before, exists := current[r.Key]
+		if exists && before.hash == hash {
+			continue
+		}
+
+		change := Created
+		if exists {
+			change = Updated
+			if err := s.expire(tx, dataset, scope, r.Key, at); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := tx.Exec(`INSERT INTO rego_records (dataset, scope, key, data, hash, valid_from) VALUES (?, ?, ?, ?, ?, ?)`,
+			string(dataset), scope, r.Key, string(r.Data), hash, at); err != nil {
+			return nil, err
+		}
+		if err := s.recordChange(tx, dataset, scope, r.Key, change, before.data, r.Data, at); err != nil {
+			return nil, err
+		}
+
+		if change == Created {
+			snapshot.Created++
+		} else {
+			snapshot.Updated++
+		}
+	}
+
+	for key, before := range current {
+		if seen[key] {
+			continue
+		}
+		if err := s.expire(tx, dataset, scope, key, at); err != nil {
+			return nil, err
+		}
+		if err := s.recordChange(tx, dataset, scope, key, Deleted, before.data, nil, at); err != nil {
+			return nil, err
+		}
+		snapshot.Deleted++
+	}
+
+	result, err := tx.Exec(`INSERT INTO rego_snapshots (dataset, scope, taken_at, records, created, updated, deleted) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		string(dataset), scope, at, snapshot.Records, snapshot.Created, snapshot.Updated, snapshot.Deleted)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.ID, err = result.LastInsertId(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.Log.Printf("Snapshot of %s %s: %d record(s), %d created, %d updated, %d deleted", dataset, scope, snapshot.Records, snapshot.Created, snapshot.Updated, snapshot.Deleted)
+	return snapshot, nil
+}
+
+// expire closes the current version of a resource
+func (s *Store) expire(tx *sql.Tx, dataset Dataset, scope, key, at string) error {
+	_, err := tx.Exec(`UPDATE rego_records SET valid_to = ? WHERE dataset = ? AND scope = ? AND key = ? AND valid_to IS NULL`, at, string(dataset), scope, key)
+	return err
+}
+
+func (s *Store) recordChange(tx *sql.Tx, dataset Dataset, scope, key string, change ChangeType, before, after []byte, at string) error {
+	_, err := tx.Exec(`INSERT INTO rego_changes (dataset, scope, key, change, before, after, changed_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		string(dataset), scope, key, string(change), nullable(before), nullable(after), at)
+	return err
+}
+
+/*
+ * # Point-in-Time Query
+ * Returns the resources of a dataset scope as they were at `at`, sorted by key
+ * - e.g. `AsOf(storage.OktaGroupMembers, groupID, lastMonth)` lists who was in a group last month
+ * - Times before the first snapshot return no records
+ */
+func (s *Store) AsOf(dataset Dataset, scope string, at time.Time) ([]*Record, error) {
+	t := at.UTC().Format(timeFormat)
+	return s.records(`SELECT key, data, valid_from, valid_to FROM rego_records
+		WHERE dataset = ? AND scope = ? AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)
+		ORDER BY key`, string(dataset), scope, t, t)
+}
+
+// Current returns the resources of a dataset scope as of its latest snapshot, sorted by key
+func (s *Store) Current(dataset Dataset, scope string) ([]*Record, error) {
+	return s.records(`SELECT key, data, valid_from, valid_to FROM rego_records
+		WHERE dataset = ? AND scope = ? AND valid_to IS NULL
+		ORDER BY key`, string(dataset), scope)
+}
+
+// Versions returns every version of one resource across all scopes, oldest first
+func (s *Store) Versions(dataset Dataset, key string) ([]*Record, error) {
+	return s.records(`SELECT key, data, valid_from, valid_to FROM rego_records
+		WHERE dataset = ? AND key = ?
+		ORDER BY valid_from, scope`, string(dataset), key)
+}
+
+func (s *Store) records(query string, args ...interface{}) ([]*Record, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []*Record{}
+	for rows.Next() {
+		var (
+			r         Record
+			data      string
+			validFrom string
+			validTo   sql.NullString
+		)
+		if err := rows.Scan(&r.Key, &data, &validFrom, &validTo); err != nil {
+			return nil, err
+		}
+		r.Data = json.RawMessage(data)
+		if r.ValidFrom, err = time.Parse(timeFormat, validFrom); err != nil {
+			return nil, err
+		}
+		if validTo.Valid {
+			t, err := time.Parse(timeFormat, validTo.String)
+			if err != nil {
+				return nil, err
+			}
+			r.ValidTo = &t
+		}
+		records = append(records, &r)
+	}
+	return records, rows.Err()
+}
+
+/*
+ * # Change History
+ * Returns the changes to a dataset scope seen by snapshots taken in [since, until), oldest first
+ * - A zero `until` means now
+ */
+func (s *Store) Changes(dataset Dataset, scope string, since, until time.Time) ([]*Change, error) {
+	if until.IsZero() {
+		until = s.now().Add(time.Nanosecond)
+	}
+	return s.changes(`SELECT dataset, scope, key, change, before, after, changed_at FROM rego_changes
+		WHERE dataset = ? AND scope = ? AND changed_at >= ? AND changed_at < ?
+		ORDER BY id`, string(dataset), scope, since.UTC().Format(timeFormat), until.UTC().Format(timeFormat))
+}
+
+// History returns every change to one resource across all scopes, oldest first; e.g. every group a user joined or left
+func (s *Store) History(dataset Dataset, key string) ([]*Change, error) {
+	return s.changes(`SELECT dataset, scope, key, change, before, after, changed_at FROM rego_changes
+		WHERE dataset = ? AND key = ?
+		ORDER BY id`, string(dataset), key)
+}
+
+func (s *Store) changes(query string, args ...interface{}) ([]*Change, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []*Change{}
+	for rows.Next() {
+		var (
+			c             Change
+			dataset, kind string
+			before, after sql.NullString
+			at            string
+		)
+		if err := rows.Scan(&dataset, &c.Scope, &c.Key, &kind, &before, &after, &at); err != nil {
+			return nil, err
+		}
+		c.Dataset, c.Type = Dataset(dataset), ChangeType(kind)
+		if before.Valid {
+			c.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			c.After = json.RawMessage(after.String)
+		}
+		if c.At, err = time.Parse(timeFormat, at); err != nil {
+			return nil, err
+		}
+		changes = append(changes, &c)
+	}
+	return changes, rows.Err()
+}
+
+// Snapshots returns the snapshots taken of a dataset (every scope), oldest first
+func (s *Store) Snapshots(dataset Dataset) ([]*Snapshot, error) {
+	rows, err := s.DB.Query(`SELECT id, dataset, scope, taken_at, records, created, updated, deleted FROM rego_snapshots
+		WHERE dataset = ? ORDER BY id`, string(dataset))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := []*Snapshot{}
+	for rows.Next() {
+		var (
+			snapshot Snapshot
+			dataset  string
+			at       string
+		)
+		if err := rows.Scan(&snapshot.ID, &dataset, &snapshot.Scope, &at, &snapshot.Records, &snapshot.Created, &snapshot.Updated, &snapshot.Deleted); err != nil {
+			return nil, err
+		}
+		snapshot.Dataset = Dataset(dataset)
+		if snapshot.TakenAt, err = time.Parse(timeFormat, at); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+func (s *Store) now() time.Time {
+	if s.Now != nil {
+		return s.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+// Decode unmarshals the resource of a record, e.g. into an `okta.User`
+func (r *Record) Decode(v interface{}) error {
+	return json.Unmarshal(r.Data, v)
+}
+
+// Diff returns the fields which changed, with sensitive fields redacted, e.g. for an audit log of the dataset
+func (c *Change) Diff(opts diff.Options) diff.Changes {
+	var before, after interface{}
+	if len(c.Before) > 0 {
+		before = c.Before
+	}
+	if len(c.After) > 0 {
+		after = c.After
+	}
+	return diff.Values(before, after, opts)
+}
+
+// digest hashes the JSON of a record, ignoring insignificant whitespace
+func digest(data []byte) string {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		compact.Reset()
+		compact.Write(data)
+	}
+	sum := sha256.Sum256(compact.Bytes())
+	return hex.EncodeToString(sum[:])
+}
+
+func nullable(data []byte) interface{} {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
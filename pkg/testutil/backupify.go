@@ -0,0 +1,246 @@
+// pkg/testutil/backupify.go
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/backupify"
+	"github.com/gemini-oss/rego/pkg/common/options"
+)
+
+// Credentials accepted by the fake Backupify
+const (
+	BackupifyCustomerID  = "12345"
+	BackupifySession     = "testutil"
+	BackupifyExportToken = "testutil-export"
+)
+
+// ### Backupify Structs
+// ---------------------------------------------------------------------
+
+// Backupify is a fake Backupify WebUI, serving the protected users, their snapshots, activities and exports
+type Backupify struct {
+	*Server
+
+	mutex      sync.Mutex
+	users      map[backupify.AppType][]*backupify.User
+	activities map[backupify.AppType]*backupify.Activities
+	exports    map[int]*backupify.ResponseData // Exports started, by ID
+	content    map[int][]byte                  // Content of the exports, by ID
+	started    int                             // Number of exports started
+}
+
+// END OF BACKUPIFY STRUCTS
+//---------------------------------------------------------------------
+
+/*
+ * # Fake Backupify
+ * Returns a fake Backupify WebUI for the customer `BackupifyCustomerID`
+ * - Requests without the session cookie `PHPSESSID=BackupifySession` are rejected, as with an expired session
+ * - Users paginate through the `start` and `length` offsets of DataTables
+ * - Exports started with `BackupifyExportToken` can be downloaded (as seeded with `SetExport`) and deleted
+ */
+func NewBackupify(t testing.TB) *Backupify {
+	b := &Backupify{
+		users:      map[backupify.AppType][]*backupify.User{},
+		activities: map[backupify.AppType]*backupify.Activities{},
+		exports:    map[int]*backupify.ResponseData{},
+		content:    map[int][]byte{},
+	}
+	b.Server = NewServer(t, backupifyError)
+
+	prefix := "/" + BackupifyCustomerID
+	b.Handle("POST", prefix+"/customerServices", b.session(b.listUsers))
+	b.Handle("POST", prefix+"/getActivities", b.session(b.getActivities))
+	b.Handle("POST", prefix+"/serviceSnaps", b.session(b.getSnapshots))
+	b.Handle("POST", prefix+"/restoreExportAction", b.session(b.startExport))
+	b.Handle("GET", prefix+"/download", b.session(b.download))
+	b.Handle("HEAD", prefix+"/download", b.session(b.download))
+	b.Handle("POST", prefix+"/delete", b.session(b.deleteExport))
+	return b
+}
+
+/*
+ * # Backupify Client
+ * Returns a Backupify client of the fake
+ * - Sets the environment variables `backupify.NewClient` requires for the duration of the test
+ */
+func (b *Backupify) NewClient(t testing.TB, verbosity int) *backupify.Client {
+	setenv(t, map[string]string{
+		"BACKUPIFY_EXPORT_TOKEN": BackupifyExportToken,
+		"BACKUPIFY_PHPSESSID":    BackupifySession,
+	})
+
+	return backupify.NewClient(verbosity,
+		options.WithBaseURL(fmt.Sprintf("%s/%s", b.URL, BackupifyCustomerID)),
+		options.WithCache(memoryCache(t)),
+	)
+}
+
+// AddUser seeds the protected users of an application
+func (b *Backupify) AddUser(appType backupify.AppType, users ...*backupify.User) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.users[appType] = append(b.users[appType], users...)
+}
+
+// SetActivities seeds the activities of an application
+func (b *Backupify) SetActivities(appType backupify.AppType, activities *backupify.Activities) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.activities[appType] = activities
+}
+
+// SetExport seeds a completed export, downloaded with its content
+func (b *Backupify) SetExport(id int, appType backupify.AppType, content []byte) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.exports[id] = &backupify.ResponseData{Action: "Export", AppType: appType, CustomerId: customerID(), ID: id, Status: "completed"}
+	b.content[id] = content
+}
+
+// Exports returns the exports started or seeded, and not deleted, by ID
+func (b *Backupify) Exports() map[int]backupify.ResponseData {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	exports := map[int]backupify.ResponseData{}
+	for id, e := range b.exports {
+		exports[id] = *e
+	}
+	return exports
+}
+
+// backupifyError renders an error the way the WebUI answers its XHR requests
+func backupifyError(status int, message string) string {
+	data, _ := json.Marshal(map[string]interface{}{"success": false, "message": message})
+	return string(data)
+}
+
+func customerID() int {
+	id, _ := strconv.Atoi(BackupifyCustomerID)
+	return id
+}
+
+// session rejects requests without the session cookie
+func (b *Backupify) session(next HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, p Params) {
+		cookie, err := r.Cookie("PHPSESSID")
+		if err != nil || cookie.Value != BackupifySession {
+			b.Error(w, http.StatusUnauthorized, "Your session has expired. Please log in again.")
+			return
+		}
+		next(w, r, p)
+	}
+}
+
+// listUsers answers a DataTables request with the users in the requested range
+func (b *Backupify) listUsers(w http.ResponseWriter, r *http.Request, _ Params) {
+	start, _ := strconv.Atoi(r.FormValue("start"))
+	length, _ := strconv.Atoi(r.FormValue("length"))
+	draw, _ := strconv.Atoi(r.FormValue("draw"))
+
+	b.mutex.Lock()
+	users := append([]*backupify.User{}, b.users[backupify.AppType(r.FormValue("appType"))]...)
+	b.mutex.Unlock()
+
+	from, to := page(start, length, len(users))
+	writeJSON(w, http.StatusOK, backupify.Users{
+		Draw:            draw,
+		Data:            users[from:to],
+		RecordsTotal:    len(users),
+		RecordsFiltered: len(users),
+	})
+}
+
+func (b *Backupify) getActivities(w http.ResponseWriter, r *http.Request, _ Params) {
+	b.mutex.Lock()
+	activities := b.activities[backupify.AppType(r.FormValue("appType"))]
+	b.mutex.Unlock()
+
+	if activities == nil {
+		activities = &backupify.Activities{}
+	}
+	writeJSON(w, http.StatusOK, backupify.ActivitiesResponse{Activities: *activities})
+}
+
+// getSnapshots groups the snapshots of a user by date
+func (b *Backupify) getSnapshots(w http.ResponseWriter, r *http.Request, _ Params) {
+	id, _ := strconv.Atoi(r.FormValue("serviceId"))
+	appType := backupify.AppType(r.FormValue("appType"))
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, u := range b.users[appType] {
+		if u.ID != id {
+			continue
+		}
+		snapshots := backupify.Snapshots{}
+		for _, s := range u.Snapshots {
+			date := strings.Fields(s.Date + " undated")[0]
+			snapshots[date] = append(snapshots[date], s)
+		}
+		writeJSON(w, http.StatusOK, snapshots)
+		return
+	}
+	b.Error(w, http.StatusNotFound, fmt.Sprintf("Service %d not found", id))
+}
+
+// startExport starts an export of a snapshot, which completes immediately with empty content
+func (b *Backupify) startExport(w http.ResponseWriter, r *http.Request, _ Params) {
+	if r.FormValue("token") != BackupifyExportToken {
+		b.Error(w, http.StatusForbidden, "Invalid export token")
+		return
+	}
+	if r.FormValue("snapshotId") == "" {
+		b.Error(w, http.StatusBadRequest, "No snapshot selected")
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.started++
+	id := 1000 + b.started
+	data := &backupify.ResponseData{Action: "Export", AppType: backupify.AppType(r.FormValue("appType")), CustomerId: customerID(), ID: id, Status: "started"}
+	b.exports[id] = data
+	b.content[id] = []byte{}
+	writeJSON(w, http.StatusOK, backupify.Export{ResponseData: *data, Status: "success"})
+}
+
+func (b *Backupify) download(w http.ResponseWriter, r *http.Request, _ Params) {
+	id, _ := strconv.Atoi(r.URL.Query().Get("id"))
+
+	b.mutex.Lock()
+	content, ok := b.content[id]
+	b.mutex.Unlock()
+	if !ok {
+		b.Error(w, http.StatusNotFound, fmt.Sprintf("Export %d not found", id))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="export-%d.zip"`, id))
+	w.Write(content)
+}
+
+// deleteExport deletes an export; the WebUI passes its parameters in the query
+func (b *Backupify) deleteExport(w http.ResponseWriter, r *http.Request, _ Params) {
+	id, _ := strconv.Atoi(r.URL.Query().Get("id"))
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	data, ok := b.exports[id]
+	if !ok {
+		b.Error(w, http.StatusNotFound, fmt.Sprintf("Export %d not found", id))
+		return
+	}
+	delete(b.exports, id)
+	delete(b.content, id)
+	writeJSON(w, http.StatusOK, backupify.Export{ResponseData: *data, Status: "deleted"})
+}
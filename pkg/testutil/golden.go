@@ -0,0 +1,175 @@
+// pkg/testutil/golden.go
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var unmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+/*
+ * # Golden Payload
+ * Unmarshals a payload captured from a provider into `v`, failing the test when:
+ * - The payload does not unmarshal, e.g. a field changed type
+ * - Fields of the payload have no field in `v`, so `encoding/json` would silently drop them
+ * - `ignore` lists the paths of fields dropped on purpose, e.g. `[].profile.customAttribute`; a trailing `*` matches a prefix
+ */
+func Golden(t testing.TB, path string, v interface{}, ignore ...string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Reading golden payload: %v", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("%s does not unmarshal into %T: %v", path, v, err)
+	}
+
+	unknown, err := UnknownFields(data, v)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	for _, field := range unknown {
+		if !ignored(field, ignore) {
+			t.Errorf("%s: %T has no field for `%s`, which would be dropped", path, v, field)
+		}
+	}
+}
+
+/*
+ * # Unknown Fields
+ * Returns the paths of the fields of a JSON payload which `v` has no field for, e.g. `users[].customSchemas`
+ * - Arrays are written `[]` and map keys `{}`, so each unknown field is reported once
+ * - Fields of maps, interfaces and types with their own `UnmarshalJSON` are not inspected
+ * - Null fields are not reported, since no data is lost
+ */
+func UnknownFields(data []byte, v interface{}) ([]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var payload interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	found := map[string]bool{}
+	walk("", payload, reflect.TypeOf(v), found)
+
+	unknown := []string{}
+	for path := range found {
+		unknown = append(unknown, path)
+	}
+	sort.Strings(unknown)
+	return unknown, nil
+}
+
+// walk compares a decoded payload with the type it is unmarshaled into
+func walk(path string, payload interface{}, t reflect.Type, found map[string]bool) {
+	if payload == nil || t == nil {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(unmarshaler) || reflect.PointerTo(t).Implements(unmarshaler) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := payload.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		for key, value := range object {
+			field, ok := fields[key]
+			if !ok {
+				// encoding/json falls back to a case-insensitive match
+				for name, f := range fields {
+					if strings.EqualFold(name, key) {
+						field, ok = f, true
+						break
+					}
+				}
+			}
+			if !ok {
+				if value != nil {
+					found[join(path, key)] = true
+				}
+				continue
+			}
+			walk(join(path, key), value, field, found)
+		}
+	case reflect.Slice, reflect.Array:
+		if items, ok := payload.([]interface{}); ok {
+			for _, item := range items {
+				walk(path+"[]", item, t.Elem(), found)
+			}
+		}
+	case reflect.Map:
+		if object, ok := payload.(map[string]interface{}); ok {
+			for _, value := range object {
+				walk(join(path, "{}"), value, t.Elem(), found)
+			}
+		}
+	}
+}
+
+// jsonFields returns the types of the fields of a struct by JSON name, including those of embedded structs
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for n, et := range jsonFields(ft) {
+				if _, ok := fields[n]; !ok {
+					fields[n] = et
+				}
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return fmt.Sprintf("%s.%s", path, key)
+}
+
+func ignored(field string, ignore []string) bool {
+	for _, i := range ignore {
+		if prefix, ok := strings.CutSuffix(i, "*"); ok && strings.HasPrefix(field, prefix) {
+			return true
+		}
+		if field == i {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,436 @@
+// pkg/testutil/google.go
+package testutil
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/options"
+	"github.com/gemini-oss/rego/pkg/google"
+)
+
+// GooglePageSize is the page size of Google lists when the request sets no `maxResults`
+const GooglePageSize = 100
+
+// GoogleToken is the access token issued by the fake token endpoint; a token impersonating a user ends with `.<email>`
+const GoogleToken = "ya29.testutil"
+
+// ### Google Structs
+// ---------------------------------------------------------------------
+
+// Google is a fake Google Workspace tenant, serving the Directory users and group members, and the Reports activities
+type Google struct {
+	*Server
+
+	mutex      sync.Mutex
+	users      []*google.User
+	members    map[string][]*google.Member // Members of each group, by group key
+	activities map[string][]google.Report  // Activities of each application, e.g. `token`
+}
+
+// END OF GOOGLE STRUCTS
+//---------------------------------------------------------------------
+
+/*
+ * # Fake Google Workspace
+ * Returns a fake Google Workspace tenant, with an OAuth 2.0 token endpoint for service accounts at `/token`
+ * - Lists paginate through `nextPageToken`, honoring `maxResults`
+ * - Activities are listed in the order they were seeded, within [`startTime`, `endTime`]
+ * - Errors use Google's format, with reasons `notFound`, `duplicate` and `rateLimitExceeded`
+ * - Users and their lists carry an `ETag`, and are answered with a `304` when `If-None-Match` matches it
+ * - Google sends no rate limit headers; requests beyond `RateLimit` are answered with a `429`
+ */
+func NewGoogle(t testing.TB) *Google {
+	g := &Google{members: map[string][]*google.Member{}, activities: map[string][]google.Report{}}
+	g.Server = NewServer(t, googleError)
+
+	g.Handle("POST", "/token", g.token)
+	g.Handle("GET", "/admin/directory/v1/users", g.listUsers)
+	g.Handle("POST", "/admin/directory/v1/users", g.createUser)
+	g.Handle("GET", "/admin/directory/v1/users/{key}", g.getUser)
+	g.Handle("PATCH", "/admin/directory/v1/users/{key}", g.updateUser)
+	g.Handle("GET", "/admin/directory/v1/groups/{group}/members", g.listMembers)
+	g.Handle("POST", "/admin/directory/v1/groups/{group}/members", g.addMember)
+	g.Handle("DELETE", "/admin/directory/v1/groups/{group}/members/{member}", g.removeMember)
+	g.Handle("GET", "/admin/reports/v1/activity/users/all/applications/{application}", g.listActivities)
+	return g
+}
+
+/*
+ * # Google Client
+ * Returns a Google client of the fake tenant, authorized as a service account whose tokens are issued by the fake
+ * - Sets `GOOGLE_SERVICE_ACCOUNT` for the duration of the test
+ * - Requests to every Google host are sent to the fake
+ */
+func (g *Google) NewClient(t testing.TB, verbosity int, scopes ...string) *google.Client {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating service account key: %v", err)
+	}
+	account, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "testutil",
+		"private_key_id": "testutil",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"client_email":   "rego@testutil.iam.gserviceaccount.com",
+		"token_uri":      g.URL + "/token",
+	})
+	setenv(t, map[string]string{"GOOGLE_SERVICE_ACCOUNT": base64.StdEncoding.EncodeToString(account)})
+
+	if len(scopes) == 0 {
+		scopes = []string{"https://www.googleapis.com/auth/admin.directory.user"}
+	}
+	c, err := google.NewClient(google.AuthCredentials{Type: google.SERVICE_ACCOUNT, CICD: true, Scopes: scopes}, verbosity,
+		options.WithHTTPClient(g.Server.Server.Client()),
+		options.WithBaseURL(g.URL),
+		options.WithCache(memoryCache(t)),
+	)
+	if err != nil {
+		t.Fatalf("creating google client: %v", err)
+	}
+	return c
+}
+
+// AddUser seeds users
+func (g *Google) AddUser(users ...*google.User) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.users = append(g.users, users...)
+}
+
+// AddMember seeds the members of a group
+func (g *Google) AddMember(groupKey string, members ...*google.Member) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.members[groupKey] = append(g.members[groupKey], members...)
+}
+
+// AddActivity seeds the activities of an application, e.g. `token` or `login`
+func (g *Google) AddActivity(application string, activities ...google.Report) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.activities[application] = append(g.activities[application], activities...)
+}
+
+// User returns a copy of the current state of a user, or nil if it does not exist
+func (g *Google) User(key string) *google.User {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if u := g.user(key); u != nil {
+		copied := *u
+		return &copied
+	}
+	return nil
+}
+
+// Members returns the emails of the current members of a group
+func (g *Google) Members(groupKey string) []string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	emails := []string{}
+	for _, m := range g.members[groupKey] {
+		emails = append(emails, m.Email)
+	}
+	return emails
+}
+
+// googleError renders an error in Google's format
+func googleError(status int, message string) string {
+	reason := map[int]string{
+		http.StatusBadRequest:      "invalid",
+		http.StatusUnauthorized:    "authError",
+		http.StatusForbidden:       "forbidden",
+		http.StatusNotFound:        "notFound",
+		http.StatusConflict:        "duplicate",
+		http.StatusTooManyRequests: "rateLimitExceeded",
+	}[status]
+	if reason == "" {
+		reason = "backendError"
+	}
+	data, _ := json.Marshal(google.ErrorResponse{Error: &google.ErrorDetail{
+		Code:    status,
+		Message: message,
+		Errors:  []*google.ErrorItem{{Domain: "global", Reason: reason, Message: message}},
+	}})
+	return string(data)
+}
+
+func (g *Google) token(w http.ResponseWriter, r *http.Request, _ Params) {
+	if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || r.FormValue("assertion") == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	token := GoogleToken
+	if sub := assertionSubject(r.FormValue("assertion")); sub != "" {
+		token += "." + sub
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": token, "token_type": "Bearer", "expires_in": 3600})
+}
+
+// assertionSubject returns the user impersonated by a JWT assertion, if any
+func assertionSubject(assertion string) string {
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Sub string `json:"sub"`
+	}
+	json.Unmarshal(payload, &claims)
+	return claims.Sub
+}
+
+func (g *Google) listUsers(w http.ResponseWriter, r *http.Request, _ Params) {
+	q := r.URL.Query()
+	if q.Get("customer") == "" && q.Get("domain") == "" {
+		g.Error(w, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	g.mutex.Lock()
+	users := []*google.User{}
+	for _, u := range g.users {
+		if googleQuery(q.Get("query"), u) {
+			users = append(users, u)
+		}
+	}
+	g.mutex.Unlock()
+
+	start, end, next := googlePage(r, len(users))
+	writeTagged(w, r, google.Users{Kind: "admin#directory#users", Users: users[start:end], NextPageToken: next})
+}
+
+// googleQuery matches a user against the `email:`, `isSuspended=` and `orgUnitPath=` terms of a search; other terms are ignored
+// https://developers.google.com/admin-sdk/directory/v1/guides/search-users
+func googleQuery(query string, u *google.User) bool {
+	for _, term := range strings.Fields(query) {
+		switch {
+		case strings.HasPrefix(term, "email:"):
+			prefix := strings.Trim(strings.TrimSuffix(strings.TrimPrefix(term, "email:"), "*"), `'"`)
+			if !strings.HasPrefix(strings.ToLower(u.PrimaryEmail), strings.ToLower(prefix)) {
+				return false
+			}
+		case strings.HasPrefix(term, "isSuspended="):
+			if strconv.FormatBool(u.Suspended) != strings.TrimPrefix(term, "isSuspended=") {
+				return false
+			}
+		case strings.HasPrefix(term, "orgUnitPath="):
+			// The OU's sub-OUs match as well
+			path := google.OrgUnitPath(strings.Trim(strings.TrimPrefix(term, "orgUnitPath="), `'"`))
+			if !path.Contains(u.OrgUnitPath) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (g *Google) createUser(w http.ResponseWriter, r *http.Request, _ Params) {
+	u := &google.User{}
+	if err := json.NewDecoder(r.Body).Decode(u); err != nil || u.PrimaryEmail == "" {
+		g.Error(w, http.StatusBadRequest, "Invalid Input: primary_user_email")
+		return
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.user(u.PrimaryEmail) != nil {
+		g.Error(w, http.StatusConflict, "Entity already exists.")
+		return
+	}
+	u.ID = fmt.Sprintf("1%020d", len(g.users)+1)
+	u.Kind = "admin#directory#user"
+	g.users = append(g.users, u)
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (g *Google) getUser(w http.ResponseWriter, r *http.Request, p Params) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	u := g.user(p["key"])
+	if u == nil {
+		g.Error(w, http.StatusNotFound, "Resource Not Found: userKey")
+		return
+	}
+	writeTagged(w, r, u)
+}
+
+// writeTagged writes `v` with an `ETag` of its content, or answers `304 Not Modified` when it matches `If-None-Match`
+func writeTagged(w http.ResponseWriter, r *http.Request, v interface{}) {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+// updateUser patches the fields of the body onto the user
+func (g *Google) updateUser(w http.ResponseWriter, r *http.Request, p Params) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	u := g.user(p["key"])
+	if u == nil {
+		g.Error(w, http.StatusNotFound, "Resource Not Found: userKey")
+		return
+	}
+
+	patch := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		g.Error(w, http.StatusBadRequest, "Invalid JSON payload received.")
+		return
+	}
+	current := map[string]interface{}{}
+	data, _ := json.Marshal(u)
+	json.Unmarshal(data, &current)
+	for field, value := range patch {
+		current[field] = value
+	}
+
+	patched := &google.User{}
+	data, _ = json.Marshal(current)
+	if err := json.Unmarshal(data, patched); err != nil {
+		g.Error(w, http.StatusBadRequest, fmt.Sprintf("Invalid Input: %v", err))
+		return
+	}
+	*u = *patched
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (g *Google) listMembers(w http.ResponseWriter, r *http.Request, p Params) {
+	g.mutex.Lock()
+	members, ok := g.members[p["group"]]
+	members = append([]*google.Member{}, members...)
+	g.mutex.Unlock()
+	if !ok {
+		g.Error(w, http.StatusNotFound, "Resource Not Found: groupKey")
+		return
+	}
+
+	start, end, next := googlePage(r, len(members))
+	writeJSON(w, http.StatusOK, google.Members{Kind: "admin#directory#members", Members: members[start:end], NextPageToken: next})
+}
+
+func (g *Google) addMember(w http.ResponseWriter, r *http.Request, p Params) {
+	m := &google.Member{}
+	if err := json.NewDecoder(r.Body).Decode(m); err != nil || m.Email == "" {
+		g.Error(w, http.StatusBadRequest, "Missing required field: memberKey")
+		return
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	for _, existing := range g.members[p["group"]] {
+		if strings.EqualFold(existing.Email, m.Email) {
+			g.Error(w, http.StatusConflict, "Member already exists.")
+			return
+		}
+	}
+	m.Kind = "admin#directory#member"
+	if m.Role == "" {
+		m.Role = "MEMBER"
+	}
+	m.Status = "ACTIVE"
+	m.Type = "USER"
+	g.members[p["group"]] = append(g.members[p["group"]], m)
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (g *Google) removeMember(w http.ResponseWriter, r *http.Request, p Params) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	members := []*google.Member{}
+	for _, m := range g.members[p["group"]] {
+		if !strings.EqualFold(m.Email, p["member"]) && m.ID != p["member"] {
+			members = append(members, m)
+		}
+	}
+	if len(members) == len(g.members[p["group"]]) {
+		g.Error(w, http.StatusNotFound, "Resource Not Found: memberKey")
+		return
+	}
+	g.members[p["group"]] = members
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (g *Google) listActivities(w http.ResponseWriter, r *http.Request, p Params) {
+	eventName := r.URL.Query().Get("eventName")
+	from, _ := time.Parse(time.RFC3339Nano, r.URL.Query().Get("startTime"))
+	to, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("endTime"))
+	if err != nil {
+		to = time.Now()
+	}
+
+	g.mutex.Lock()
+	activities := []google.Report{}
+	for _, a := range g.activities[p["application"]] {
+		if at := a.ID.Time; !at.IsZero() && (at.Before(from) || at.After(to)) {
+			continue
+		}
+		for _, e := range a.Events {
+			if eventName == "" || e.Name == eventName {
+				activities = append(activities, a)
+				break
+			}
+		}
+	}
+	g.mutex.Unlock()
+
+	start, end, next := googlePage(r, len(activities))
+	writeJSON(w, http.StatusOK, google.Report{Kind: "admin#reports#activities", Items: activities[start:end], NextPageToken: next})
+}
+
+func (g *Google) user(key string) *google.User {
+	for _, u := range g.users {
+		if u.ID == key || strings.EqualFold(u.PrimaryEmail, key) {
+			return u
+		}
+	}
+	return nil
+}
+
+/*
+ * # Google Pagination
+ * Returns the bounds of the page requested by `pageToken` and `maxResults`, and the token of the next page
+ * https://developers.google.com/admin-sdk/directory/v1/guides/manage-users#get_all_users
+ */
+func googlePage(r *http.Request, total int) (int, int, string) {
+	q := r.URL.Query()
+	size, err := strconv.Atoi(q.Get("maxResults"))
+	if err != nil || size <= 0 {
+		size = GooglePageSize
+	}
+
+	offset := 0
+	if token := q.Get("pageToken"); token != "" {
+		decoded, _ := base64.RawURLEncoding.DecodeString(token)
+		offset, _ = strconv.Atoi(strings.TrimPrefix(string(decoded), "offset:"))
+	}
+
+	start, end := page(offset, size, total)
+	next := ""
+	if end < total {
+		next = base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("offset:%d", end)))
+	}
+	return start, end, next
+}
@@ -0,0 +1,420 @@
+// pkg/testutil/okta.go
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/options"
+	"github.com/gemini-oss/rego/pkg/common/query"
+	"github.com/gemini-oss/rego/pkg/okta"
+)
+
+// OktaPageSize is the page size of Okta lists when the request sets no `limit`
+const OktaPageSize = 200
+
+// ### Okta Structs
+// ---------------------------------------------------------------------
+
+// Okta is a fake Okta org, serving the users, groups, group memberships and applications of `/api/v1`
+type Okta struct {
+	*Server
+
+	mutex   sync.Mutex
+	users   []*okta.User
+	groups  []*okta.Group
+	members map[string][]string // IDs of the members of each group, by group ID
+	apps    []*okta.Application
+	logs    []*okta.LogEvent
+}
+
+// END OF OKTA STRUCTS
+//---------------------------------------------------------------------
+
+/*
+ * # Fake Okta
+ * Returns a fake Okta org, limited to 600 requests per minute like Okta's `/api/v1/users`
+ * https://developer.okta.com/docs/reference/rl-global-mgmt/
+ * - Lists paginate through `Link` headers with an `after` cursor, honoring `limit`
+ * - User and group lists filter on the SCIM expression of `search`; user lists sort by `sortBy`
+ * - The System Log lists the events published in [`since`, `until`), oldest first unless `sortOrder` is `DESCENDING`
+ * - Errors use Okta's format, e.g. `E0000007` for resources which do not exist
+ */
+func NewOkta(t testing.TB) *Okta {
+	o := &Okta{members: map[string][]string{}}
+	o.Server = NewServer(t, oktaError)
+	o.RateLimit = 600
+	o.limited = func(w http.ResponseWriter, limit, remaining int, reset time.Time) {
+		w.Header().Set("X-Rate-Limit-Limit", strconv.Itoa(limit))
+		w.Header().Set("X-Rate-Limit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	}
+
+	o.Handle("GET", "/api/v1/users", o.listUsers)
+	o.Handle("GET", "/api/v1/users/{id}", o.getUser)
+	o.Handle("POST", "/api/v1/users/{id}/lifecycle/{action}", o.lifecycle)
+	o.Handle("DELETE", "/api/v1/users/{id}/sessions", o.clearSessions)
+	o.Handle("GET", "/api/v1/users/{id}/groups", o.userGroups)
+	o.Handle("GET", "/api/v1/groups", o.listGroups)
+	o.Handle("GET", "/api/v1/groups/{id}", o.getGroup)
+	o.Handle("GET", "/api/v1/groups/{id}/users", o.listMembers)
+	o.Handle("PUT", "/api/v1/groups/{id}/users/{user}", o.addMember)
+	o.Handle("DELETE", "/api/v1/groups/{id}/users/{user}", o.removeMember)
+	o.Handle("GET", "/api/v1/apps", o.listApps)
+	o.Handle("GET", "/api/v1/logs", o.listLogs)
+	return o
+}
+
+/*
+ * # Okta Client
+ * Returns an Okta client of the fake org
+ * - Sets the environment variables `okta.NewClient` requires for the duration of the test
+ */
+func (o *Okta) NewClient(t testing.TB, verbosity int) *okta.Client {
+	setenv(t, map[string]string{"OKTA_API_TOKEN": "testutil"})
+	return okta.NewClient(verbosity, options.WithBaseURL(o.URL+"/api/v1"), options.WithCache(memoryCache(t)))
+}
+
+// AddUser seeds users
+func (o *Okta) AddUser(users ...*okta.User) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.users = append(o.users, users...)
+}
+
+// AddLogEvent seeds System Log events
+func (o *Okta) AddLogEvent(events ...*okta.LogEvent) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.logs = append(o.logs, events...)
+}
+
+// AddGroup seeds a group with its members, by user ID
+func (o *Okta) AddGroup(g *okta.Group, members ...string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.groups = append(o.groups, g)
+	o.members[g.ID] = append(o.members[g.ID], members...)
+}
+
+// AddApp seeds applications
+func (o *Okta) AddApp(apps ...*okta.Application) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.apps = append(o.apps, apps...)
+}
+
+// User returns a copy of the current state of a user, or nil if it does not exist
+func (o *Okta) User(id string) *okta.User {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if u := o.user(id); u != nil {
+		copied := *u
+		return &copied
+	}
+	return nil
+}
+
+// Members returns the IDs of the current members of a group
+func (o *Okta) Members(groupID string) []string {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return append([]string{}, o.members[groupID]...)
+}
+
+// oktaError renders an error in Okta's format
+func oktaError(status int, message string) string {
+	code := map[int]string{
+		http.StatusBadRequest:      "E0000001",
+		http.StatusUnauthorized:    "E0000011",
+		http.StatusForbidden:       "E0000006",
+		http.StatusNotFound:        "E0000007",
+		http.StatusTooManyRequests: "E0000047",
+	}[status]
+	if code == "" {
+		code = "E0000009"
+	}
+	data, _ := json.Marshal(okta.Error{ErrorCode: code, ErrorSummary: message, ErrorId: fmt.Sprintf("testutil%d", status), ErrorCauses: []okta.ErrorCause{}})
+	return string(data)
+}
+
+func (o *Okta) listUsers(w http.ResponseWriter, r *http.Request, _ Params) {
+	q := r.URL.Query()
+	search, err := query.Parse(q.Get("search"))
+	if q.Get("search") != "" && err != nil {
+		o.Error(w, http.StatusBadRequest, "Invalid search criteria: "+err.Error())
+		return
+	}
+
+	o.mutex.Lock()
+	users := []*okta.User{}
+	for _, u := range o.users {
+		if matches(u, search) {
+			users = append(users, u)
+		}
+	}
+	o.mutex.Unlock()
+
+	if sortBy := q.Get("sortBy"); sortBy != "" {
+		users, _ = query.ApplySlice(users, query.New(query.OrderBy(query.Field(sortBy))))
+	}
+
+	oktaPage(w, r, users, func(u *okta.User) string { return u.ID })
+}
+
+// matches returns whether `item` matches every predicate
+func matches(item interface{}, predicates []query.Predicate) bool {
+	for _, p := range predicates {
+		if !p.Match(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func (o *Okta) getUser(w http.ResponseWriter, r *http.Request, p Params) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	u := o.user(p["id"])
+	if u == nil {
+		o.Error(w, http.StatusNotFound, "Not found: Resource not found: "+p["id"]+" (User)")
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (o *Okta) lifecycle(w http.ResponseWriter, r *http.Request, p Params) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	u := o.user(p["id"])
+	if u == nil {
+		o.Error(w, http.StatusNotFound, "Not found: Resource not found: "+p["id"]+" (User)")
+		return
+	}
+
+	status := map[string]okta.Status{
+		"activate":   okta.ACTIVE,
+		"deactivate": okta.DEPROVISIONED,
+		"suspend":    okta.SUSPENDED,
+		"unsuspend":  okta.ACTIVE,
+		"unlock":     okta.ACTIVE,
+	}[p["action"]]
+	if status == "" {
+		o.Error(w, http.StatusNotFound, "Not found: Resource not found: "+p["action"]+" (Lifecycle)")
+		return
+	}
+	u.Status = status
+	u.StatusChanged = time.Now().UTC()
+	writeJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (o *Okta) clearSessions(w http.ResponseWriter, r *http.Request, p Params) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.user(p["id"]) == nil {
+		o.Error(w, http.StatusNotFound, "Not found: Resource not found: "+p["id"]+" (User)")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (o *Okta) userGroups(w http.ResponseWriter, r *http.Request, p Params) {
+	o.mutex.Lock()
+	groups := []*okta.Group{}
+	for _, g := range o.groups {
+		for _, id := range o.members[g.ID] {
+			if id == p["id"] {
+				groups = append(groups, g)
+			}
+		}
+	}
+	o.mutex.Unlock()
+
+	oktaPage(w, r, groups, func(g *okta.Group) string { return g.ID })
+}
+
+func (o *Okta) listGroups(w http.ResponseWriter, r *http.Request, _ Params) {
+	q := strings.ToLower(r.URL.Query().Get("q"))
+	search, err := query.Parse(r.URL.Query().Get("search"))
+	if r.URL.Query().Get("search") != "" && err != nil {
+		o.Error(w, http.StatusBadRequest, "Invalid search criteria: "+err.Error())
+		return
+	}
+
+	o.mutex.Lock()
+	groups := []*okta.Group{}
+	for _, g := range o.groups {
+		if strings.HasPrefix(strings.ToLower(g.Profile.Name), q) && matches(g, search) {
+			groups = append(groups, g)
+		}
+	}
+	o.mutex.Unlock()
+
+	oktaPage(w, r, groups, func(g *okta.Group) string { return g.ID })
+}
+
+func (o *Okta) listLogs(w http.ResponseWriter, r *http.Request, _ Params) {
+	q := r.URL.Query()
+	since, _ := time.Parse(time.RFC3339Nano, q.Get("since"))
+	until, err := time.Parse(time.RFC3339Nano, q.Get("until"))
+	if err != nil {
+		until = time.Now()
+	}
+
+	o.mutex.Lock()
+	events := []*okta.LogEvent{}
+	for _, e := range o.logs {
+		if !e.Published.Before(since) && e.Published.Before(until) {
+			events = append(events, e)
+		}
+	}
+	o.mutex.Unlock()
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if q.Get("sortOrder") == "DESCENDING" {
+			return events[i].Published.After(events[j].Published)
+		}
+		return events[i].Published.Before(events[j].Published)
+	})
+	oktaPage(w, r, events, func(e *okta.LogEvent) string { return e.UUID })
+}
+
+func (o *Okta) getGroup(w http.ResponseWriter, r *http.Request, p Params) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	g := o.group(p["id"])
+	if g == nil {
+		o.Error(w, http.StatusNotFound, "Not found: Resource not found: "+p["id"]+" (UserGroup)")
+		return
+	}
+	writeJSON(w, http.StatusOK, g)
+}
+
+func (o *Okta) listMembers(w http.ResponseWriter, r *http.Request, p Params) {
+	o.mutex.Lock()
+	if o.group(p["id"]) == nil {
+		o.mutex.Unlock()
+		o.Error(w, http.StatusNotFound, "Not found: Resource not found: "+p["id"]+" (UserGroup)")
+		return
+	}
+	users := []*okta.User{}
+	for _, id := range o.members[p["id"]] {
+		if u := o.user(id); u != nil {
+			users = append(users, u)
+		}
+	}
+	o.mutex.Unlock()
+
+	oktaPage(w, r, users, func(u *okta.User) string { return u.ID })
+}
+
+func (o *Okta) addMember(w http.ResponseWriter, r *http.Request, p Params) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.group(p["id"]) == nil || o.user(p["user"]) == nil {
+		o.Error(w, http.StatusNotFound, "Not found: Resource not found: "+p["id"]+" (UserGroup)")
+		return
+	}
+
+	// Adding an existing member succeeds without changes
+	for _, id := range o.members[p["id"]] {
+		if id == p["user"] {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	o.members[p["id"]] = append(o.members[p["id"]], p["user"])
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (o *Okta) removeMember(w http.ResponseWriter, r *http.Request, p Params) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.group(p["id"]) == nil {
+		o.Error(w, http.StatusNotFound, "Not found: Resource not found: "+p["id"]+" (UserGroup)")
+		return
+	}
+
+	members := []string{}
+	for _, id := range o.members[p["id"]] {
+		if id != p["user"] {
+			members = append(members, id)
+		}
+	}
+	o.members[p["id"]] = members
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (o *Okta) listApps(w http.ResponseWriter, r *http.Request, _ Params) {
+	o.mutex.Lock()
+	apps := append([]*okta.Application{}, o.apps...)
+	o.mutex.Unlock()
+
+	oktaPage(w, r, apps, func(a *okta.Application) string { return a.ID })
+}
+
+func (o *Okta) user(id string) *okta.User {
+	for _, u := range o.users {
+		if u.ID == id || (u.Profile != nil && (strings.EqualFold(u.Profile.Login, id) || strings.EqualFold(u.Profile.Email, id))) {
+			return u
+		}
+	}
+	return nil
+}
+
+func (o *Okta) group(id string) *okta.Group {
+	for _, g := range o.groups {
+		if g.ID == id {
+			return g
+		}
+	}
+	return nil
+}
+
+/*
+ * # Okta Pagination
+ * Writes the page of `items` after the `after` cursor, linking to the next page like Okta does
+ * https://developer.okta.com/docs/api/#pagination
+ */
+func oktaPage[T any](w http.ResponseWriter, r *http.Request, items []T, id func(T) string) {
+	q := r.URL.Query()
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = OktaPageSize
+	}
+
+	offset := 0
+	if after := q.Get("after"); after != "" {
+		for i, item := range items {
+			if id(item) == after {
+				offset = i + 1
+			}
+		}
+	}
+	start, end := page(offset, limit, len(items))
+
+	self := *r.URL
+	self.Scheme, self.Host = "http", r.Host
+	w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="self"`, self.String()))
+	if end < len(items) {
+		q.Set("after", id(items[end-1]))
+		q.Set("limit", strconv.Itoa(limit))
+		self.RawQuery = q.Encode()
+		w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="next"`, self.String()))
+	}
+	writeJSON(w, http.StatusOK, items[start:end])
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,264 @@
+// pkg/testutil/redis.go
+package testutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ### Redis Structs
+// ---------------------------------------------------------------------
+
+// Redis is a fake Redis server, with the commands of the Redis backends of `cache` and `ratelimit`
+type Redis struct {
+	net.Listener
+	Password string // Password clients authenticate with; none when empty
+
+	mutex    sync.Mutex
+	values   map[string]string
+	expiries map[string]time.Time
+}
+
+// END OF REDIS STRUCTS
+//---------------------------------------------------------------------
+
+/*
+ * # Fake Redis
+ * Returns a started Redis server speaking RESP, closed when the test finishes
+ * - Keys hold strings, which `INCR` and `DECR` count as integers; every database shares them
+ * - `MULTI` queues commands until `EXEC` runs them at once
+ * - `SCAN` returns every key matching its pattern, a prefix followed by `*`, in one batch
+ * - `EVAL` has no Lua: it runs the update script of `ratelimit.RedisBackend` in Go, raising a counter and moving its
+ *   expiry
+ */
+func NewRedis(t testing.TB, password string) *Redis {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Redis{Listener: l, Password: password, values: map[string]string{}, expiries: map[string]time.Time{}}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go r.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { l.Close() })
+	return r
+}
+
+// URL returns the URL of database `db` of the server, with its password, e.g. `redis://:secret@127.0.0.1:6379/2`
+func (r *Redis) URL(db int) string {
+	if r.Password == "" {
+		return fmt.Sprintf("redis://%s/%d", r.Addr(), db)
+	}
+	return fmt.Sprintf("redis://:%s@%s/%d", r.Password, r.Addr(), db)
+}
+
+func (r *Redis) serve(conn net.Conn) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	authenticated := r.Password == ""
+	var queued [][]string
+	inMulti := false
+	for {
+		command, err := readRedisCommand(rd)
+		if err != nil {
+			return
+		}
+		name := strings.ToUpper(command[0])
+		switch {
+		case name == "AUTH":
+			authenticated = command[len(command)-1] == r.Password
+			if authenticated {
+				io.WriteString(conn, "+OK\r\n")
+			} else {
+				io.WriteString(conn, "-WRONGPASS invalid password\r\n")
+			}
+		case !authenticated:
+			io.WriteString(conn, "-NOAUTH Authentication required.\r\n")
+		case name == "MULTI":
+			inMulti = true
+			io.WriteString(conn, "+OK\r\n")
+		case name == "EXEC":
+			r.mutex.Lock()
+			replies := fmt.Sprintf("*%d\r\n", len(queued))
+			for _, c := range queued {
+				replies += r.apply(c)
+			}
+			r.mutex.Unlock()
+			queued, inMulti = nil, false
+			io.WriteString(conn, replies)
+		case inMulti:
+			queued = append(queued, command)
+			io.WriteString(conn, "+QUEUED\r\n")
+		default:
+			r.mutex.Lock()
+			reply := r.apply(command)
+			r.mutex.Unlock()
+			io.WriteString(conn, reply)
+		}
+	}
+}
+
+// apply runs a command, returning its reply
+func (r *Redis) apply(c []string) string {
+	name := strings.ToUpper(c[0])
+	switch name {
+	case "SELECT":
+		return "+OK\r\n"
+	case "SCAN":
+		return r.scan(c[3])
+	case "EVAL":
+		// EVAL script 1 key used reset
+		return r.raise(c[3], c[4], c[5])
+	}
+
+	key := c[1]
+	r.expire(key)
+	value, exists := r.values[key]
+	switch name {
+	case "GET":
+		if !exists {
+			return "$-1\r\n"
+		}
+		return bulk(value)
+	case "SET":
+		options := strings.ToUpper(strings.Join(c[3:], " "))
+		if strings.Contains(options, "NX") && exists {
+			return "$-1\r\n"
+		}
+		r.values[key] = c[2]
+		if !strings.Contains(options, "KEEPTTL") {
+			delete(r.expiries, key)
+		}
+		for i := 3; i+1 < len(c); i++ {
+			n, _ := strconv.ParseInt(c[i+1], 10, 64)
+			switch strings.ToUpper(c[i]) {
+			case "PX":
+				r.expiries[key] = time.Now().Add(time.Duration(n) * time.Millisecond)
+			case "PXAT":
+				r.expiries[key] = time.UnixMilli(n)
+			}
+		}
+		return "+OK\r\n"
+	case "INCR", "DECR":
+		n, _ := strconv.Atoi(value)
+		if name == "INCR" {
+			n++
+		} else {
+			n--
+		}
+		r.values[key] = strconv.Itoa(n)
+		return fmt.Sprintf(":%d\r\n", n)
+	case "PTTL":
+		expiry, ok := r.expiries[key]
+		switch {
+		case !exists:
+			return ":-2\r\n"
+		case !ok:
+			return ":-1\r\n"
+		}
+		return fmt.Sprintf(":%d\r\n", time.Until(expiry).Milliseconds())
+	case "PEXPIRE", "PEXPIREAT":
+		if !exists {
+			return ":0\r\n"
+		}
+		n, _ := strconv.ParseInt(c[2], 10, 64)
+		if name == "PEXPIRE" {
+			r.expiries[key] = time.Now().Add(time.Duration(n) * time.Millisecond)
+		} else {
+			r.expiries[key] = time.UnixMilli(n)
+		}
+		return ":1\r\n"
+	case "DEL":
+		delete(r.values, key)
+		delete(r.expiries, key)
+		if exists {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	}
+	return "-ERR unknown command\r\n"
+}
+
+// expire deletes `key` once its expiry has passed
+func (r *Redis) expire(key string) {
+	if expiry, ok := r.expiries[key]; ok && !time.Now().Before(expiry) {
+		delete(r.values, key)
+		delete(r.expiries, key)
+	}
+}
+
+// raise raises the counter of `key` to `used`, and moves its expiry to `reset` (Unix milliseconds) unless it is 0
+// - Without a reset, only an existing counter is raised
+func (r *Redis) raise(key, used, reset string) string {
+	r.expire(key)
+	value, exists := r.values[key]
+	current, _ := strconv.Atoi(value)
+	n, _ := strconv.Atoi(used)
+	at, _ := strconv.ParseInt(reset, 10, 64)
+	if !exists && at == 0 {
+		return ":0\r\n"
+	}
+	if !exists || n > current {
+		r.values[key] = used
+	}
+	if at != 0 {
+		r.expiries[key] = time.UnixMilli(at)
+	}
+	return ":1\r\n"
+}
+
+// scan replies to a `SCAN` of every key matching `pattern`, a prefix followed by `*`, in one batch
+func (r *Redis) scan(pattern string) string {
+	prefix := strings.NewReplacer(`\*`, "*", `\?`, "?", `\[`, "[", `\]`, "]", `\\`, `\`).Replace(strings.TrimSuffix(pattern, "*"))
+	var keys []string
+	for key := range r.values {
+		if expiry, ok := r.expiries[key]; ok && !time.Now().Before(expiry) {
+			continue
+		}
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, bulk(key))
+		}
+	}
+	return fmt.Sprintf("*2\r\n$1\r\n0\r\n*%d\r\n%s", len(keys), strings.Join(keys, ""))
+}
+
+// bulk encodes a bulk string reply
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+// readRedisCommand reads a command sent as an array of bulk strings
+func readRedisCommand(rd *bufio.Reader) ([]string, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	command := make([]string, n)
+	for i := range command {
+		header, err := rd.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(rd, arg); err != nil {
+			return nil, err
+		}
+		command[i] = string(arg[:size])
+	}
+	return command, nil
+}
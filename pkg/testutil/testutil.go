@@ -0,0 +1,326 @@
+/*
+# Test Utilities
+
+This package provides `httptest`-based fakes of the Okta, Google Workspace and Backupify APIs, so rego's own tests (and
+anything built on rego) can run without live credentials:
+  - Resources are seeded in memory and mutated by the requests the fakes receive
+  - Lists paginate the way each API does (Okta `Link` headers, Google page tokens, Backupify DataTables offsets)
+  - Responses carry rate limit headers, and requests beyond `RateLimit` are throttled
+  - Failures are injected with `Fail`, e.g. a `503` on the next two reads of a path
+  - Interactions with live APIs are recorded to fixtures by a `Recorder`, with their secrets scrubbed, and replayed in CI
+  - Payloads captured from providers are checked with `Golden` for fields rego's structs would silently drop
+  - A fake Redis server stands in for the backends shared by caches and rate limiters across processes
+
+	o := testutil.NewOkta(t)
+	o.AddUser(&okta.User{ID: "00u1", Status: "ACTIVE", Profile: &okta.UserProfile{Email: "user@example.com"}})
+	o.Fail(testutil.Fault{Method: "GET", Path: "/api/v1/users", Status: 503, Times: 1})
+
+	client := o.NewClient(t, log.DEBUG)
+	users, err := client.ListAllUsers()
+
+:Copyright: (c) 2024 by Gemini Space Station, LLC, see AUTHORS for more info
+:License: See the LICENSE file for details
+:Author: Anthony Dardano <anthony.dardano@gemini.com>
+*/
+
+// pkg/testutil/testutil.go
+package testutil
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gemini-oss/rego/pkg/common/cache"
+)
+
+// EncryptionKey is set as `REGO_ENCRYPTION_KEY` by the fakes when it is not already set, since every client requires one
+const EncryptionKey = "8jCcfHzjg*8mXD8qWjj9mk*QNZnVsMRt"
+
+// ### Server Structs
+// ---------------------------------------------------------------------
+
+// Fault is a failure injected into the responses of a server
+type Fault struct {
+	Method string        // Method of the requests to fail; any method when empty
+	Path   string        // Path (or path prefix, when ending in `*`) of the requests to fail; any path when empty
+	Status int           // Status of the failed responses; `500 Internal Server Error` when zero
+	Body   string        // Body of the failed responses; the error format of the API when empty
+	Header http.Header   // Headers of the failed responses, e.g. `Retry-After`
+	Times  int           // Number of requests to fail; every matching request when zero
+	Delay  time.Duration // Delay before responding, e.g. to trigger client timeouts
+}
+
+// Request is a request received by a server
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Params holds the values of the `{placeholders}` of a route
+type Params map[string]string
+
+// HandlerFunc handles the requests of a route
+type HandlerFunc func(w http.ResponseWriter, r *http.Request, p Params)
+
+// Server is a fake API
+type Server struct {
+	*httptest.Server
+	RateLimit  int           // Number of requests allowed per `RateWindow`; unlimited when zero
+	RateWindow time.Duration // Window of the rate limit; one minute when zero
+
+	mutex     sync.Mutex
+	routes    []route
+	faults    []*Fault
+	requests  []Request
+	used      int       // Requests made in the current window
+	resets    time.Time // End of the current window
+	errorBody func(status int, message string) string
+	limited   func(w http.ResponseWriter, limit, remaining int, reset time.Time)
+}
+
+type route struct {
+	method   string
+	segments []string
+	handler  HandlerFunc
+}
+
+// END OF SERVER STRUCTS
+//---------------------------------------------------------------------
+
+/*
+ * # New Server
+ * Returns a started server without any routes, closed when the test finishes
+ * - `errorBody` renders errors in the format of the API; plain text when nil
+ */
+func NewServer(t testing.TB, errorBody func(status int, message string) string) *Server {
+	s := &Server{errorBody: errorBody}
+	if s.errorBody == nil {
+		s.errorBody = func(status int, message string) string { return message }
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Handle routes requests to a handler; path segments in braces, e.g. `/users/{id}`, are passed as params
+func (s *Server) Handle(method, pattern string, h HandlerFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.routes = append(s.routes, route{method: method, segments: split(pattern), handler: h})
+}
+
+// Fail injects a failure into the responses of the server
+func (s *Server) Fail(f Fault) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// Requests returns the requests received so far, in order
+func (s *Server) Requests() []Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Request{}, s.requests...)
+}
+
+// Client returns an HTTP client which sends every request to the server, whatever its host, for clients with fixed hosts
+func (s *Server) Client() *http.Client {
+	target, _ := url.Parse(s.URL)
+	return &http.Client{Transport: rewrite{target: target, next: s.Server.Client().Transport}}
+}
+
+// Error writes an error in the format of the API
+func (s *Server) Error(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	io.WriteString(w, s.errorBody(status, message))
+}
+
+// serve records the request, then applies the rate limit, faults and routes, in that order
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+	s.mutex.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Header: r.Header.Clone(), Body: body})
+	throttled := s.throttle(w)
+	fault := s.fault(r)
+	routes := s.routes
+	s.mutex.Unlock()
+
+	if throttled {
+		s.Error(w, http.StatusTooManyRequests, "API call exceeded rate limit due to too many requests.")
+		return
+	}
+	if fault != nil {
+		time.Sleep(fault.Delay)
+		for name, values := range fault.Header {
+			w.Header()[name] = values
+		}
+		status := fault.Status
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		if fault.Body != "" {
+			w.WriteHeader(status)
+			io.WriteString(w, fault.Body)
+			return
+		}
+		s.Error(w, status, http.StatusText(status))
+		return
+	}
+
+	segments := split(r.URL.EscapedPath())
+	for _, rt := range routes {
+		if rt.method != r.Method {
+			continue
+		}
+		if params, ok := match(rt.segments, segments); ok {
+			rt.handler(w, r, params)
+			return
+		}
+	}
+	s.Error(w, http.StatusNotFound, "Not found: "+r.URL.Path)
+}
+
+// throttle counts the request against the rate limit, writing the rate limit headers; it reports whether the limit is exceeded
+func (s *Server) throttle(w http.ResponseWriter) bool {
+	if s.RateLimit == 0 {
+		return false
+	}
+	window := s.RateWindow
+	if window == 0 {
+		window = time.Minute
+	}
+
+	now := time.Now()
+	if now.After(s.resets) {
+		s.used = 0
+		s.resets = now.Add(window)
+	}
+	s.used++
+
+	remaining := s.RateLimit - s.used
+	if remaining < 0 {
+		remaining = 0
+	}
+	if s.limited != nil {
+		s.limited(w, s.RateLimit, remaining, s.resets)
+	}
+	if s.used > s.RateLimit {
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(s.resets).Seconds())+1))
+		return true
+	}
+	return false
+}
+
+// fault returns the first fault matching the request, consuming one of its times
+func (s *Server) fault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(f.Path, "*"); ok {
+			if !strings.HasPrefix(r.URL.Path, prefix) {
+				continue
+			}
+		} else if f.Path != "" && f.Path != r.URL.Path {
+			continue
+		}
+
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// split splits a path into its segments
+func split(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+// match matches the segments of a path against those of a pattern
+func match(pattern, segments []string) (Params, bool) {
+	if len(pattern) != len(segments) {
+		return nil, false
+	}
+	params := Params{}
+	for i, p := range pattern {
+		if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+			value, err := url.PathUnescape(segments[i])
+			if err != nil {
+				return nil, false
+			}
+			params[strings.Trim(p, "{}")] = value
+			continue
+		}
+		if p != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// page returns the bounds of a page of `size` items starting at `offset`, within `total` items
+func page(offset, size, total int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > total {
+		offset = total
+	}
+	end := offset + size
+	if size <= 0 || end > total {
+		end = total
+	}
+	return offset, end
+}
+
+// rewrite sends every request to a target server, keeping its path and query
+type rewrite struct {
+	target *url.URL
+	next   http.RoundTripper
+}
+
+func (rt rewrite) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = rt.target.Scheme
+	req.URL.Host = rt.target.Host
+	req.Host = rt.target.Host
+	return rt.next.RoundTrip(req)
+}
+
+// setenv sets the environment variables a client requires, for the duration of a test
+func setenv(t testing.TB, env map[string]string) {
+	if os.Getenv("REGO_ENCRYPTION_KEY") == "" {
+		env["REGO_ENCRYPTION_KEY"] = EncryptionKey
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+// memoryCache returns an empty in-memory cache, so a client of a fake does not read responses cached on disk by others
+func memoryCache(t testing.TB) *cache.Cache {
+	c, err := cache.NewCache([]byte(os.Getenv("REGO_ENCRYPTION_KEY")), true, 1000000)
+	if err != nil {
+		t.Fatalf("testutil: creating cache: %v", err)
+	}
+	return c
+}
@@ -0,0 +1,370 @@
+// pkg/testutil/vcr.go
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gemini-oss/rego/pkg/common/config"
+	"github.com/gemini-oss/rego/pkg/common/log"
+	"github.com/gemini-oss/rego/pkg/common/retry"
+)
+
+// Redacted replaces the secrets scrubbed from fixtures
+const Redacted = "REDACTED"
+
+// RecordEnv is the environment variable which switches recorders created with `EnvMode` to recording
+const RecordEnv = "REGO_RECORD"
+
+// Mode is whether a recorder records or replays
+type Mode int
+
+const (
+	Replay Mode = iota // Serve the interactions of the fixture, failing on requests it does not hold
+	Record             // Send requests to the API, saving the interactions to the fixture when the test finishes
+)
+
+// ErrNotRecorded is returned when a replayed request has no recorded interaction
+var ErrNotRecorded = errors.New("testutil: no recorded interaction")
+
+// sensitive matches the names of headers, query parameters and fields which hold secrets, e.g. `apiToken` but not `passwordChanged`
+var sensitive = regexp.MustCompile(`(?i)(authorization|token|secret|password|passwd|api[-_]?key|private[-_]?key|cookie|sess(ion)?[-_]?id|signature|credentials?|assertion|^code)$`)
+
+// ### Recorder Structs
+// ---------------------------------------------------------------------
+
+// Interaction is a request and the response the API gave it
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request of an interaction, with its secrets scrubbed
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response of an interaction, with its secrets scrubbed
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Cassette is the content of a fixture file
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Recorder is an `http.RoundTripper` which records the interactions of a client with an API into a fixture, or replays them
+type Recorder struct {
+	Mode Mode              // Whether the recorder records or replays
+	Next http.RoundTripper // Transport of recorded requests; `http.DefaultTransport` when nil
+
+	path     string
+	mutex    sync.Mutex
+	cassette Cassette
+	used     []bool   // Interactions already replayed, so repeated requests (pages, retries) replay in order
+	secrets  []string // Literal values scrubbed wherever they appear
+}
+
+// END OF RECORDER STRUCTS
+//---------------------------------------------------------------------
+
+// EnvMode returns `Record` when `REGO_RECORD` is true, and `Replay` otherwise, e.g. in CI
+func EnvMode() Mode {
+	switch strings.ToLower(config.GetEnv(RecordEnv)) {
+	case "1", "true", "yes":
+		return Record
+	}
+	return Replay
+}
+
+/*
+ * # New Recorder
+ * Returns a recorder of the fixture at `path`, e.g. `testdata/okta_users.json`
+ * - `Record`: requests are sent, and the interactions are saved to `path` when the test finishes
+ * - `Replay`: the interactions of `path` are served in order; the test fails if `path` does not exist
+ * - Secrets are scrubbed before interactions are saved or matched:
+ *   - Headers, query parameters and JSON or form fields named like secrets (`Authorization`, `token`, `password`, ...)
+ *   - The credentials masked in the logs (see `log.SetRedactor`)
+ *   - The values of environment variables named like secrets, e.g. `OKTA_API_TOKEN`
+ *   - Any value passed to `Redact`
+ */
+func NewRecorder(t testing.TB, path string, mode Mode) *Recorder {
+	r := &Recorder{Mode: mode, path: path}
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if len(value) >= 8 && sensitive.MatchString(name) {
+			r.secrets = append(r.secrets, value)
+		}
+	}
+
+	switch mode {
+	case Record:
+		t.Cleanup(func() {
+			if err := r.Save(); err != nil {
+				t.Errorf("testutil: saving fixture: %v", err)
+			}
+		})
+	case Replay:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("testutil: no fixture to replay (record it with %s=true): %v", RecordEnv, err)
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			t.Fatalf("testutil: decoding fixture %s: %v", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r
+}
+
+// Redact scrubs literal values, e.g. tenant names, from the fixture
+func (r *Recorder) Redact(secrets ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, s := range secrets {
+		if s != "" {
+			r.secrets = append(r.secrets, s)
+		}
+	}
+}
+
+// Client returns an HTTP client which records or replays through the recorder
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Interactions returns the interactions recorded, or those of the fixture when replaying
+func (r *Recorder) Interactions() []*Interaction {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]*Interaction{}, r.cassette.Interactions...)
+}
+
+// Unplayed returns the interactions of the fixture which were not replayed, e.g. to check a test sent every request it was recorded with
+func (r *Recorder) Unplayed() []*Interaction {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var unplayed []*Interaction
+	for i, in := range r.cassette.Interactions {
+		if i < len(r.used) && !r.used[i] {
+			unplayed = append(unplayed, in)
+		}
+	}
+	return unplayed
+}
+
+// RoundTrip records or replays a request
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recorded := r.scrubRequest(req, body)
+
+	if r.Mode == Replay {
+		return r.replay(req, recorded)
+	}
+
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	in := &Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			Status: resp.StatusCode,
+			Header: r.scrubHeader(resp.Header),
+			Body:   r.scrubBody(resp.Header.Get("Content-Type"), data),
+		},
+	}
+	r.mutex.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+	r.mutex.Unlock()
+	return resp, nil
+}
+
+// Save writes the recorded interactions to the fixture
+func (r *Recorder) Save() error {
+	if r.Mode != Record {
+		return nil
+	}
+	r.mutex.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+// replay serves the first unused interaction matching the request
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || in.Request.Method != recorded.Method || in.Request.URL != recorded.URL || in.Request.Body != recorded.Body {
+			continue
+		}
+		r.used[i] = true
+
+		header := in.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+			StatusCode:    in.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	// Replaying the request again would fail the same way, so it is not retried
+	return nil, retry.Permanent(fmt.Errorf("%w for %s %s", ErrNotRecorded, recorded.Method, recorded.URL))
+}
+
+// readBody reads the body of a request, leaving it readable by the transport
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func (r *Recorder) scrubRequest(req *http.Request, body []byte) RecordedRequest {
+	u := *req.URL
+	query := u.Query()
+	for key := range query {
+		if sensitive.MatchString(key) {
+			query.Set(key, Redacted)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return RecordedRequest{
+		Method: req.Method,
+		URL:    r.scrub(u.String()),
+		Header: r.scrubHeader(req.Header),
+		Body:   r.scrubBody(req.Header.Get("Content-Type"), body),
+	}
+}
+
+func (r *Recorder) scrubHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	scrubbed := http.Header{}
+	for name, values := range h {
+		for _, v := range values {
+			if sensitive.MatchString(name) {
+				v = Redacted
+			}
+			scrubbed.Add(name, r.scrub(v))
+		}
+	}
+	return scrubbed
+}
+
+// scrubBody scrubs the fields of JSON and form bodies named like secrets, then the literal secrets of any body
+func (r *Recorder) scrubBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var data interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber() // Keeps large IDs intact
+	switch {
+	case json.Valid(body) && decoder.Decode(&data) == nil:
+		if scrubbed, err := json.Marshal(scrubJSON(data)); err == nil {
+			body = scrubbed
+		}
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			for key := range form {
+				if sensitive.MatchString(key) {
+					form.Set(key, Redacted)
+				}
+			}
+			body = []byte(form.Encode())
+		}
+	}
+	return r.scrub(string(body))
+}
+
+func scrubJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if _, ok := value.(string); ok && sensitive.MatchString(key) {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = scrubJSON(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = scrubJSON(value)
+		}
+	}
+	return v
+}
+
+// scrub replaces the literal secrets, longest first so overlapping secrets are fully replaced, then masks what the logs do
+func (r *Recorder) scrub(s string) string {
+	r.mutex.Lock()
+	secrets := append([]string{}, r.secrets...)
+	r.mutex.Unlock()
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Redacted)
+		if escaped := url.QueryEscape(secret); escaped != secret {
+			s = strings.ReplaceAll(s, escaped, Redacted)
+		}
+	}
+	return log.Redact(s)
+}